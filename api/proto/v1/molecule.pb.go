@@ -163,6 +163,24 @@ type BatchSimilaritySearchItem struct {
 	Error        string                  `json:"error,omitempty"`
 }
 
+// StreamSimilaritySearchRequest is one query on a StreamSimilaritySearch stream.
+type StreamSimilaritySearchRequest struct {
+	QueryId string                   `json:"query_id,omitempty"`
+	Query   *SimilaritySearchRequest `json:"query,omitempty"`
+}
+
+// StreamSimilaritySearchResponse is one hit, or the terminal message, for a
+// query on a StreamSimilaritySearch stream.
+type StreamSimilaritySearchResponse struct {
+	QueryId   string            `json:"query_id,omitempty"`
+	Sequence  uint32            `json:"sequence,omitempty"`
+	Hit       *SimilarityResult `json:"hit,omitempty"`
+	Done      bool              `json:"done,omitempty"`
+	HitCount  uint32            `json:"hit_count,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	ErrorCode string            `json:"error_code,omitempty"`
+}
+
 // AssessPatentabilityRequest is the request for AssessPatentability.
 type AssessPatentabilityRequest struct {
 	MoleculeId           string   `json:"molecule_id,omitempty"`
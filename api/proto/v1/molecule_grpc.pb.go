@@ -29,6 +29,10 @@ type MoleculeServiceClient interface {
 	// concurrently and streams results as they complete. Partial failures (one
 	// molecule's search fails) are reported per-item; the stream is not aborted.
 	BatchSimilaritySearch(ctx context.Context, in *BatchSimilaritySearchRequest, opts ...grpc.CallOption) (MoleculeService_BatchSimilaritySearchClient, error)
+	// StreamSimilaritySearch accepts queries incrementally on the request
+	// stream and streams hits back as each search completes. Per-query errors
+	// are reported in-band; the stream is not aborted.
+	StreamSimilaritySearch(ctx context.Context, opts ...grpc.CallOption) (MoleculeService_StreamSimilaritySearchClient, error)
 	// AssessPatentability evaluates novelty, inventive step, and utility for a
 	// registered molecule against the patent prior-art corpus.
 	AssessPatentability(ctx context.Context, in *AssessPatentabilityRequest, opts ...grpc.CallOption) (*AssessPatentabilityResponse, error)
@@ -124,6 +128,37 @@ func (x *moleculeServiceBatchSimilaritySearchClient) Recv() (*BatchSimilaritySea
 	return m, nil
 }
 
+func (c *moleculeServiceClient) StreamSimilaritySearch(ctx context.Context, opts ...grpc.CallOption) (MoleculeService_StreamSimilaritySearchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MoleculeService_ServiceDesc.Streams[1], "/keyip.v1.MoleculeService/StreamSimilaritySearch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &moleculeServiceStreamSimilaritySearchClient{stream}
+	return x, nil
+}
+
+type MoleculeService_StreamSimilaritySearchClient interface {
+	Send(*StreamSimilaritySearchRequest) error
+	Recv() (*StreamSimilaritySearchResponse, error)
+	grpc.ClientStream
+}
+
+type moleculeServiceStreamSimilaritySearchClient struct {
+	grpc.ClientStream
+}
+
+func (x *moleculeServiceStreamSimilaritySearchClient) Send(m *StreamSimilaritySearchRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *moleculeServiceStreamSimilaritySearchClient) Recv() (*StreamSimilaritySearchResponse, error) {
+	m := new(StreamSimilaritySearchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *moleculeServiceClient) AssessPatentability(ctx context.Context, in *AssessPatentabilityRequest, opts ...grpc.CallOption) (*AssessPatentabilityResponse, error) {
 	out := new(AssessPatentabilityResponse)
 	err := c.cc.Invoke(ctx, "/keyip.v1.MoleculeService/AssessPatentability", in, out, opts...)
@@ -176,6 +211,10 @@ type MoleculeServiceServer interface {
 	// concurrently and streams results as they complete. Partial failures (one
 	// molecule's search fails) are reported per-item; the stream is not aborted.
 	BatchSimilaritySearch(*BatchSimilaritySearchRequest, MoleculeService_BatchSimilaritySearchServer) error
+	// StreamSimilaritySearch accepts queries incrementally on the request
+	// stream and streams hits back as each search completes. Per-query errors
+	// are reported in-band; the stream is not aborted.
+	StreamSimilaritySearch(MoleculeService_StreamSimilaritySearchServer) error
 	// AssessPatentability evaluates novelty, inventive step, and utility for a
 	// registered molecule against the patent prior-art corpus.
 	AssessPatentability(context.Context, *AssessPatentabilityRequest) (*AssessPatentabilityResponse, error)
@@ -210,6 +249,9 @@ func (UnimplementedMoleculeServiceServer) SimilaritySearch(context.Context, *Sim
 func (UnimplementedMoleculeServiceServer) BatchSimilaritySearch(*BatchSimilaritySearchRequest, MoleculeService_BatchSimilaritySearchServer) error {
 	return status.Errorf(codes.Unimplemented, "method BatchSimilaritySearch not implemented")
 }
+func (UnimplementedMoleculeServiceServer) StreamSimilaritySearch(MoleculeService_StreamSimilaritySearchServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSimilaritySearch not implemented")
+}
 func (UnimplementedMoleculeServiceServer) AssessPatentability(context.Context, *AssessPatentabilityRequest) (*AssessPatentabilityResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AssessPatentability not implemented")
 }
@@ -346,6 +388,32 @@ func (x *moleculeServiceBatchSimilaritySearchServer) Send(m *BatchSimilaritySear
 	return x.ServerStream.SendMsg(m)
 }
 
+func _MoleculeService_StreamSimilaritySearch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MoleculeServiceServer).StreamSimilaritySearch(&moleculeServiceStreamSimilaritySearchServer{stream})
+}
+
+type MoleculeService_StreamSimilaritySearchServer interface {
+	Send(*StreamSimilaritySearchResponse) error
+	Recv() (*StreamSimilaritySearchRequest, error)
+	grpc.ServerStream
+}
+
+type moleculeServiceStreamSimilaritySearchServer struct {
+	grpc.ServerStream
+}
+
+func (x *moleculeServiceStreamSimilaritySearchServer) Send(m *StreamSimilaritySearchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *moleculeServiceStreamSimilaritySearchServer) Recv() (*StreamSimilaritySearchRequest, error) {
+	m := new(StreamSimilaritySearchRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _MoleculeService_AssessPatentability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AssessPatentabilityRequest)
 	if err := dec(in); err != nil {
@@ -468,6 +536,12 @@ var MoleculeService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _MoleculeService_BatchSimilaritySearch_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamSimilaritySearch",
+			Handler:       _MoleculeService_StreamSimilaritySearch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "molecule.proto",
 }
@@ -2,9 +2,20 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/lifecycle"
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	domainPatent "github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/strategy_gpt"
+	httpmw "github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
 )
 
 // Adapters for HealthHandler
@@ -31,3 +42,161 @@ func (a *redisHealthAdapter) Name() string {
 func (a *redisHealthAdapter) Check(ctx context.Context) error {
 	return a.client.GetUnderlyingClient().Ping(ctx).Err()
 }
+
+// intelligenceLoggerAdapter implements common.Logger on top of logging.Logger
+// so intelligence-layer components write to the server log.
+type intelligenceLoggerAdapter struct {
+	logger logging.Logger
+}
+
+func (a *intelligenceLoggerAdapter) fields(keysAndValues []interface{}) []logging.Field {
+	fields := make([]logging.Field, 0, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields = append(fields, logging.Any(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]))
+	}
+	return fields
+}
+
+func (a *intelligenceLoggerAdapter) Info(msg string, keysAndValues ...interface{}) {
+	a.logger.Info(msg, a.fields(keysAndValues)...)
+}
+
+func (a *intelligenceLoggerAdapter) Warn(msg string, keysAndValues ...interface{}) {
+	a.logger.Warn(msg, a.fields(keysAndValues)...)
+}
+
+func (a *intelligenceLoggerAdapter) Debug(msg string, keysAndValues ...interface{}) {
+	a.logger.Debug(msg, a.fields(keysAndValues)...)
+}
+
+func (a *intelligenceLoggerAdapter) Error(msg string, keysAndValues ...interface{}) {
+	a.logger.Error(msg, a.fields(keysAndValues)...)
+}
+
+// httpTenantResolver and httpUserResolver read the caller that the HTTP auth
+// middleware stored in the request context, for the LLM backend wrappers.
+func httpTenantResolver(ctx context.Context) (string, bool) {
+	tenantID := httpmw.ContextGetTenantID(ctx)
+	return tenantID, tenantID != ""
+}
+
+func httpUserResolver(ctx context.Context) (string, bool) {
+	userID := httpmw.ContextGetUserID(ctx)
+	return userID, userID != ""
+}
+
+// lifecycleCacheAdapter implements the single-key CachePort used by the
+// lifecycle application services on top of a redis.Cache.
+type lifecycleCacheAdapter struct {
+	cache redis.Cache
+}
+
+func (a *lifecycleCacheAdapter) Get(ctx context.Context, key string, value interface{}) error {
+	return a.cache.Get(ctx, key, value)
+}
+
+func (a *lifecycleCacheAdapter) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return a.cache.Set(ctx, key, value, expiration)
+}
+
+func (a *lifecycleCacheAdapter) Delete(ctx context.Context, key string) error {
+	return a.cache.Delete(ctx, key)
+}
+
+// patentValueAdapter serves annuity cost optimisation with the value score
+// stored on the patent record.
+type patentValueAdapter struct {
+	repo domainPatent.PatentRepository
+}
+
+func (a *patentValueAdapter) GetValueScore(ctx context.Context, patentID string) (float64, error) {
+	id, err := uuid.Parse(patentID)
+	if err != nil {
+		return 0, err
+	}
+	p, err := a.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return p.GetValueScore(), nil
+}
+
+// unconfiguredExchangeRates is used while no exchange rate source is wired
+// into the server. Same-currency amounts never reach it; any conversion
+// fails with an explicit error rather than an invented rate.
+type unconfiguredExchangeRates struct{}
+
+func (unconfiguredExchangeRates) GetRate(ctx context.Context, from, to lifecycle.Currency) (float64, error) {
+	return 0, fmt.Errorf("no exchange rate source configured for %s->%s", from, to)
+}
+
+// newEmbedder builds the embedding client, fronted by the content-addressed
+// embedding cache when llm.embedding_cache is enabled. It returns nil when no
+// LLM provider is configured.
+func newEmbedder(cfg *config.Config, backend common.ModelBackend, redisClient *redis.Client, logger logging.Logger) common.Embedder {
+	ec := common.NewEmbeddingClient(cfg, backend)
+	if ec == nil {
+		return nil
+	}
+	cacheCfg := cfg.LLM.EmbeddingCache
+	if !cacheCfg.Enabled || cacheCfg.Dir == "" {
+		return ec
+	}
+	local, err := common.NewFileEmbeddingStore(cacheCfg.Dir)
+	if err != nil {
+		logger.Warn("embedding cache disabled", logging.Err(err))
+		return ec
+	}
+	opts := []common.EmbeddingCacheOption{common.WithEmbeddingCacheLogger(&intelligenceLoggerAdapter{logger: logger})}
+	if cacheCfg.Redis && redisClient != nil {
+		if remote, err := common.NewRedisEmbeddingStore(redis.NewRedisCache(redisClient, logger), cacheCfg.RedisTTL); err == nil {
+			opts = append(opts, common.WithEmbeddingCacheTier(remote))
+		}
+	}
+	cached, err := common.NewEmbeddingCache(ec, local, opts...)
+	if err != nil {
+		logger.Warn("embedding cache disabled", logging.Err(err))
+		return ec
+	}
+	id, version := ec.EmbeddingModel()
+	logger.Info("embedding cache enabled", logging.String("dir", cacheCfg.Dir), logging.String("model", common.EmbeddingModelTag(id, version)))
+	return cached
+}
+
+// newServingClient connects to the model serving cluster behind a priority
+// scheduler, so requests users wait on are served ahead of batch and
+// background jobs. It returns nil when intelligence.serving has no endpoint.
+func newServingClient(cfg config.ModelServingConfig, logger logging.Logger) (common.ServingClient, error) {
+	if cfg.Endpoint == "" {
+		return nil, nil
+	}
+	intLogger := &intelligenceLoggerAdapter{logger: logger}
+	opts := []common.ServingOption{common.WithServingLogger(intLogger)}
+	if cfg.Timeout > 0 {
+		opts = append(opts, common.WithRequestTimeout(cfg.Timeout))
+	}
+	client, err := common.NewHTTPServingClient(cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, err := common.NewServingScheduler(client, common.WithSchedulerLogger(intLogger))
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return scheduler, nil
+}
+
+// newRegistryPromptManager builds the report PromptManager on the prompt
+// registry at path. Like the read-only `keyip prompt` commands, it uses the
+// built-in prompts without creating the file when it does not exist.
+func newRegistryPromptManager(path string) (strategy_gpt.PromptManager, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return strategy_gpt.NewPromptManagerWithRegistry(nil, strategy_gpt.NewPromptRegistry())
+	}
+	registry, err := strategy_gpt.OpenPromptRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	return strategy_gpt.NewPromptManagerWithRegistry(nil, registry)
+}
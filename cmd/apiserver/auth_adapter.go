@@ -0,0 +1,78 @@
+// auth_adapter.go — request authentication for apiserver.
+// Adapts the local JWT service to the HTTP auth middleware and sets up API
+// key authentication with per-key scopes and rate limits.
+package main
+
+import (
+	"time"
+
+	appauth "github.com/turtacn/KeyIP-Intelligence/internal/application/auth"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	h "github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/handlers"
+	httpmw "github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+)
+
+// authRequiredPaths reject requests without credentials. The rest of /api/
+// stays open to anonymous callers, but credentials sent there are still
+// checked and identify the caller.
+var authRequiredPaths = []string{
+	"/api/v1/api-keys",
+	"/api/v1/comments",
+	"/api/v1/comment-threads",
+	"/api/v1/saved-searches",
+	"/api/v1/usage",
+	"/api/v1/admin",
+	"/api/v1/ws",
+}
+
+// jwtTokenValidator adapts the local JWT service to httpmw.TokenValidator.
+type jwtTokenValidator struct {
+	svc *appauth.Service
+}
+
+func (v jwtTokenValidator) ValidateToken(token string) (*httpmw.Claims, error) {
+	tc, err := v.svc.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens without an expiry are treated as already expired.
+	claims := &httpmw.Claims{
+		UserID:    tc.UserID,
+		TenantID:  tc.TenantID,
+		Roles:     tc.Roles,
+		ExpiresAt: time.Now(),
+	}
+	if tc.ExpiresAt != nil {
+		claims.ExpiresAt = tc.ExpiresAt.Time
+	}
+	if tc.IssuedAt != nil {
+		claims.IssuedAt = tc.IssuedAt.Time
+	}
+	return claims, nil
+}
+
+// newAuthMiddleware authenticates /api/ requests by JWT or API key. API keys
+// may also be sent as Bearer tokens; their requests are limited to the
+// scopes the key holds and rate limited per key by keyLimiter.
+func newAuthMiddleware(
+	authSvc *appauth.Service,
+	apiKeySvc appauth.APIKeyService,
+	keyLimiter httpmw.RateLimiter,
+	logger logging.Logger,
+) *httpmw.AuthMiddleware {
+	return httpmw.NewAuthMiddleware(
+		jwtTokenValidator{svc: authSvc},
+		h.NewAPIKeyAuthenticator(apiKeySvc),
+		httpmw.AuthConfig{
+			SkipPaths:          []string{"/api/v1/auth"},
+			APIKeyBearerPrefix: user.APIKeyTokenPrefix,
+			APIKeyScopeRules:   httpmw.DefaultAPIKeyScopeRules(),
+			APIKeyRateLimiter:  keyLimiter,
+			RequiredPaths:      authRequiredPaths,
+		},
+		logger,
+	)
+}
+
+//Personal.AI order the ending
@@ -7,6 +7,7 @@ import (
 	"context"
 
 	chemextractor "github.com/turtacn/KeyIP-Intelligence/internal/intelligence/chem_extractor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 )
 
 // minimalEntityResolver provides a no-op entity resolution for entities
@@ -42,12 +43,21 @@ func (r *minimalEntityResolver) ResolveByType(ctx context.Context, text string,
 	return r.Resolve(ctx, raw)
 }
 
-// newMinimalChemExtractor constructs a ChemicalExtractor using only the
-// built-in regex patterns (CAS, SMILES, formula, Markush). NER is
-// disabled to avoid model dependencies. Entity resolution is identity-only.
-func newMinimalChemExtractor() (chemextractor.ChemicalExtractor, error) {
+// newMinimalChemExtractor constructs a ChemicalExtractor using the
+// built-in regex patterns (CAS, SMILES, formula, Markush), adding NER when
+// nerBackend serves the NER model. Entity resolution is identity-only.
+func newMinimalChemExtractor(nerBackend common.ModelBackend) (chemextractor.ChemicalExtractor, error) {
+	var nerModel chemextractor.NERModel
+	if nerBackend != nil {
+		m, err := chemextractor.NewNERModel(nerBackend, chemextractor.DefaultNERModelConfig(), nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		nerModel = m
+	}
+
 	config := chemextractor.ExtractorConfig{
-		EnableNER:              false, // no NER model available
+		EnableNER:              nerModel != nil,
 		EnableDictionaryLookup: false,
 		MinConfidence:          0.5,
 		ContextWindowSize:      80,
@@ -56,7 +66,7 @@ func newMinimalChemExtractor() (chemextractor.ChemicalExtractor, error) {
 	}
 
 	return chemextractor.NewChemicalExtractor(
-		nil,                              // nerModel — disabled
+		nerModel,                         // nerModel — nil without model serving
 		&minimalEntityResolver{},         // resolver — identity
 		nil,                              // validator — not required
 		nil,                              // dictionary — disabled
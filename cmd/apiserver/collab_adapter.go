@@ -0,0 +1,75 @@
+// collab_adapter.go — saved search wiring for apiserver.
+// Builds the saved search service behind the REST handler and relays the
+// worker's saved search hits from Kafka to this instance's WebSocket clients.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	app_patent "github.com/turtacn/KeyIP-Intelligence/internal/application/patent"
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	pg_repos "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres/repositories"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	h "github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/handlers"
+)
+
+// newSavedSearchService builds the saved search service for the REST API.
+// As in the worker, only patent text searches have a backend. Hits of runs
+// started here are pushed straight to this instance's WebSocket clients.
+func newSavedSearchService(
+	conn *postgres.Connection,
+	patentSvc app_patent.Service,
+	memberRepo collabdomain.MemberRepository,
+	notifier collaboration.HitNotifier,
+	logger logging.Logger,
+) collaboration.SavedSearchService {
+	executors := map[collabdomain.SavedSearchKind]collaboration.SearchExecutor{
+		collabdomain.SavedSearchPatent: collaboration.NewPatentTextExecutor(patentSvc),
+	}
+	return collaboration.NewSavedSearchService(
+		pg_repos.NewPostgresSavedSearchRepo(conn, logger),
+		memberRepo,
+		nil,
+		executors,
+		notifier,
+		logger,
+	)
+}
+
+// startSavedSearchRelay consumes the notification topic and forwards saved
+// search hits to ws. Every instance holds its own WebSocket clients, so each
+// one joins a consumer group of its own and starts at the newest offset.
+func startSavedSearchRelay(ctx context.Context, cfg config.KafkaConfig, ws *h.WSHandler, logger logging.Logger) (*kafka.Consumer, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := kafka.NewConsumer(kafka.ConsumerConfig{
+		Brokers:           cfg.Brokers,
+		GroupID:           fmt.Sprintf("%s-ws-%s", cfg.ConsumerGroup, host),
+		Topics:            []string{kafka.TopicNotification},
+		AutoOffsetReset:   "latest",
+		SessionTimeout:    cfg.SessionTimeout,
+		HeartbeatInterval: cfg.HeartbeatInterval,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := consumer.Subscribe(kafka.TopicNotification, ws.ForwardSavedSearchHits); err != nil {
+		consumer.Close()
+		return nil, err
+	}
+	if err := consumer.Start(ctx); err != nil {
+		consumer.Close()
+		return nil, err
+	}
+	return consumer, nil
+}
+
+//Personal.AI order the ending
@@ -12,9 +12,11 @@ import (
 	"syscall"
 	"time"
 
+	app_assignee "github.com/turtacn/KeyIP-Intelligence/internal/application/assignee"
 	appauth "github.com/turtacn/KeyIP-Intelligence/internal/application/auth"
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/infringement"
+	app_inventor "github.com/turtacn/KeyIP-Intelligence/internal/application/inventor"
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/lifecycle"
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/molecule"
 	app_patent "github.com/turtacn/KeyIP-Intelligence/internal/application/patent"
@@ -22,6 +24,7 @@ import (
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/portfolio"
 	"github.com/turtacn/KeyIP-Intelligence/internal/application/reporting"
 	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	domainLifecycle "github.com/turtacn/KeyIP-Intelligence/internal/domain/lifecycle"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/neo4j"
 	neo4j_repos "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/neo4j/repositories"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/datasource"
@@ -226,6 +229,21 @@ func main() {
 	lifecycleSvc := lifecycle.NewRealTrackingService(lifecycleRepo, logger)
 	portfolioRepo := pg_repos.NewPostgresPortfolioRepo(pgConn, logger)
 	portfolioSvc := portfolio.NewService(portfolioRepo, logger)
+	valuationSvc := portfolio.NewValuationService(nil, nil, patentRepo, portfolioRepo,
+		portfolio.NewValuationAssessmentRepository(portfolioRepo), nil, nil, nil, logger, nil, nil, nil)
+
+	// Annuity and deadline services back the gRPC LifecycleService. The
+	// cache falls back to process memory when redis is unavailable.
+	lifecycleCache := &lifecycleCacheAdapter{cache: redis.NewMemoryCache()}
+	if redisClient != nil {
+		lifecycleCache = &lifecycleCacheAdapter{cache: redis.NewRedisCache(redisClient, logger)}
+	}
+	lifecycleLogger := &intelligenceLoggerAdapter{logger: logger}
+	lifecycleDomainSvc := domainLifecycle.NewService(lifecycleRepo, nil, nil, domainLifecycle.NewJurisdictionRegistry())
+	annuitySvc := lifecycle.NewAnnuityService(lifecycleDomainSvc, lifecycleRepo, patentRepo, unconfiguredExchangeRates{},
+		&patentValueAdapter{repo: patentRepo}, lifecycleCache, lifecycleLogger, lifecycle.AnnuityServiceConfig{})
+	deadlineSvc := lifecycle.NewDeadlineService(lifecycleDomainSvc, lifecycleRepo, patentRepo, lifecycleCache, lifecycleLogger)
+	assigneeSvc := app_assignee.NewService(pg_repos.NewPostgresAssigneeRepo(pgConn, logger), app_assignee.Config{}, logger)
 
 	// Auth service (local JWT-based, no Keycloak required)
 	jwtSecret := os.Getenv("KEYIP_JWT_SECRET")
@@ -258,16 +276,119 @@ func main() {
 	dashboardHandler := h.NewDashboardHandler(patentSvc, logger)
 
 	authHandler := h.NewAuthHandler(authSvc, logger)
+	apiKeySvc := appauth.NewAPIKeyService(userRepo, logger)
+	apiKeyHandler := h.NewAPIKeyHandler(apiKeySvc, logger)
+	apiKeyLimiter := httpmw.NewTierRateLimiter(httpmw.DefaultRateLimitConfig())
+	shutdownSteps = append(shutdownSteps, shutdownStep{name: "api-key-rate-limiter", close: apiKeyLimiter.Stop})
+	authMw := newAuthMiddleware(authSvc, apiKeySvc, apiKeyLimiter, logger)
 	collaborationWorkspaceSvc := collaboration.NewMinimalWorkspaceService(logger)
 	collaborationSharingSvc := collaboration.NewMinimalSharingService(logger)
 	collaborationHandler := h.NewCollaborationHandler(collaborationWorkspaceSvc, collaborationSharingSvc, logger)
 
+	// Comments push activity over WebSocket to the workspace's members only.
+	memberRepo := pg_repos.NewPostgresMemberRepo(pgConn, logger)
+	wsHandler := h.NewWSHandler(memberRepo, logger)
+	commentSvc := collaboration.NewCommentService(
+		pg_repos.NewPostgresCommentRepo(pgConn, logger),
+		memberRepo,
+		nil,
+		collaboration.NewUserRepoDirectory(userRepo),
+		pg_repos.NewPostgresNotificationRepo(pgConn, logger),
+		wsHandler,
+		logger,
+	)
+	commentHandler := h.NewCommentHandler(commentSvc, logger)
+	savedSearchHandler := h.NewSavedSearchHandler(newSavedSearchService(pgConn, patentSvc, memberRepo, wsHandler, logger), logger)
+
+	// Scheduled saved search runs happen in the worker, which publishes new
+	// hits on the notification topic for this instance to relay.
+	if len(cfg.Messaging.Kafka.Brokers) > 0 {
+		relay, err := startSavedSearchRelay(context.Background(), cfg.Messaging.Kafka, wsHandler, logger)
+		if err != nil {
+			logger.Warn("saved search relay disabled, scheduled hits will not reach WebSocket clients", logging.Err(err))
+		} else {
+			shutdownSteps = append(shutdownSteps, shutdownStep{name: "kafka-saved-search-relay", close: func() { relay.Close() }})
+		}
+	}
+	assigneeHandler := h.NewAssigneeHandler(assigneeSvc, logger)
+
 	// --- LLM Backend (config-driven: primary=Anthropic, fallback=DeepSeek) ---
 	aiBackend, llmErr := common.NewLLMBackend(cfg)
 	if llmErr != nil {
 		logger.Warn("LLM backend init failed, AI features disabled", logging.Err(llmErr))
 		aiBackend = nil
 	}
+	// The guardrail wraps the provider directly, so cached responses are
+	// already restored and redaction placeholders never reach the cache key.
+	if aiBackend != nil && cfg.LLM.Guardrail.Enabled {
+		guardLogger := &intelligenceLoggerAdapter{logger: logger}
+		guarded, guardErr := func() (common.ModelBackend, error) {
+			policies, err := common.RedactionPoliciesFromConfig(cfg.LLM.Guardrail)
+			if err != nil {
+				return nil, err
+			}
+			guard, err := common.NewRedactionGuard(common.RedactionGuardConfig{
+				Provider: cfg.LLM.Primary.Provider,
+				External: true,
+			}, policies, common.WithRedactionLogger(guardLogger), common.WithRedactionTenantResolver(httpTenantResolver))
+			if err != nil {
+				return nil, err
+			}
+			return common.NewRedactingBackend(aiBackend, guard)
+		}()
+		if guardErr != nil {
+			logger.Error("LLM guardrail misconfigured, AI features disabled", logging.Err(guardErr))
+			aiBackend = nil
+		} else {
+			aiBackend = guarded
+			logger.Info("LLM redaction guardrail enabled", logging.Int("tenant_policies", len(cfg.LLM.Guardrail.Tenants)))
+		}
+	}
+	// Metering sits inside the cache so cache hits are not billed.
+	var usageHandler *h.UsageHandler
+	if aiBackend != nil && cfg.LLM.Usage.Enabled {
+		usageSvc, usageErr := newUsageService(cfg.LLM.Usage, pgConn, logger)
+		if usageErr != nil {
+			logger.Error("LLM usage ledger misconfigured, AI features disabled", logging.Err(usageErr))
+			aiBackend = nil
+		} else if metered, err := common.NewMeteredBackend(aiBackend, usageSvc, cfg.LLM.Primary.Provider,
+			common.WithMeterTenantResolver(httpTenantResolver),
+			common.WithMeterUserResolver(httpUserResolver),
+			common.WithMeterLogger(&intelligenceLoggerAdapter{logger: logger}),
+		); err != nil {
+			logger.Error("LLM usage metering failed, AI features disabled", logging.Err(err))
+			aiBackend = nil
+		} else {
+			aiBackend = metered
+			usageHandler = h.NewUsageHandler(usageSvc, logger)
+			logger.Info("LLM usage ledger enabled", logging.Int("priced_models", len(cfg.LLM.Usage.Prices)))
+		}
+	}
+	// Responses are cached per tenant; callers without one, such as
+	// anonymous requests, always reach the backend.
+	if aiBackend != nil && redisClient != nil && cfg.LLM.Cache.Enabled {
+		cacheCfg := cfg.LLM.Cache
+		cacheOpts := []common.CachedBackendOption{common.WithCacheTenantResolver(httpTenantResolver)}
+		if cacheCfg.SimilarityThreshold > 0 && cfg.LLM.Primary.EmbeddingModelName != "" {
+			if ec := newEmbedder(cfg, aiBackend, redisClient, logger); ec != nil {
+				cacheOpts = append(cacheOpts, common.WithCacheEmbedder(ec))
+			}
+		}
+		cached, cacheErr := common.NewCachedBackend(aiBackend, redis.NewRedisCache(redisClient, logger), common.ResponseCacheConfig{
+			TTL:                 cacheCfg.TTL,
+			ModelVersion:        cfg.LLM.Primary.ModelName,
+			SimilarityThreshold: cacheCfg.SimilarityThreshold,
+			MaxSemanticEntries:  cacheCfg.MaxSemanticEntries,
+			InputCostPer1K:      cacheCfg.InputCostPer1K,
+			OutputCostPer1K:     cacheCfg.OutputCostPer1K,
+		}, cacheOpts...)
+		if cacheErr != nil {
+			logger.Warn("LLM response cache disabled", logging.Err(cacheErr))
+		} else {
+			aiBackend = cached
+			logger.Info("LLM response cache enabled", logging.Float64("similarity_threshold", cacheCfg.SimilarityThreshold))
+		}
+	}
 	var aiHandler *h.AIHandler
 	if aiBackend != nil {
 		aiHandler = h.NewAIHandler(aiBackend, logger)
@@ -279,18 +400,42 @@ func main() {
 	// Uses the same aiBackend for LLM inference; RAG config defaults
 	// to enabled with a similarity threshold of 0.70.
 	strategyCfg := strategy_gpt.NewStrategyGPTConfig()
-	promptMgr, _ := strategy_gpt.NewPromptManager(nil) // nil config → defaults
+	// Report prompts follow the active versions in the prompt registry;
+	// activations made with `keyip prompt activate` apply after a restart.
+	promptMgr, err := newRegistryPromptManager(cfg.Intelligence.StrategyGPT.PromptRegistry)
+	if err != nil {
+		logger.Error("failed to load prompt registry, using built-in report prompts",
+			logging.String("path", cfg.Intelligence.StrategyGPT.PromptRegistry), logging.Err(err))
+		promptMgr, _ = strategy_gpt.NewPromptManager(nil) // nil config → defaults
+	}
 	// RAG engine requires VectorStore + Embedder + Chunker;
 	// for minimal deployment we pass nil RAGEngine (generator degrades gracefully).
 	sgLogger := reporting.NewCommonLoggerAdapter(nil)
-	sgReportGenerator, err := strategy_gpt.NewReportGenerator(
-		aiBackend,
-		promptMgr,
-		nil, // RAGEngine — nil means RAG retrieval is skipped
-		strategyCfg,
-		common.NewNoopIntelligenceMetrics(),
-		sgLogger,
-	)
+	// Citations in generated reports are checked against the patent corpus
+	// and flagged when they do not exist.
+	var sgReportGenerator strategy_gpt.ReportGenerator
+	groundingVerifier, err := strategy_gpt.NewGroundingVerifier(patentRepo, strategy_gpt.GroundingConfig{Policy: strategy_gpt.GroundingFlag})
+	if err != nil {
+		logger.Warn("citation grounding init failed, generating reports without citation checks", logging.Err(err))
+		sgReportGenerator, err = strategy_gpt.NewReportGenerator(
+			aiBackend,
+			promptMgr,
+			nil, // RAGEngine — nil means RAG retrieval is skipped
+			strategyCfg,
+			common.NewNoopIntelligenceMetrics(),
+			sgLogger,
+		)
+	} else {
+		sgReportGenerator, err = strategy_gpt.NewReportGeneratorWithGrounding(
+			aiBackend,
+			promptMgr,
+			nil, // RAGEngine — nil means RAG retrieval is skipped
+			strategyCfg,
+			common.NewNoopIntelligenceMetrics(),
+			sgLogger,
+			groundingVerifier,
+		)
+	}
 	if err != nil {
 		logger.Warn("StrategyGPT ReportGenerator init failed, reports will be unavailable", logging.Err(err))
 	}
@@ -312,12 +457,34 @@ func main() {
 		logger.Info("StrategyGPT reporting engine initialized")
 	}
 
+	// --- Dead letter queue admin (replay re-publishes through the producer) ---
+	var dlqHandler *h.DLQHandler
+	if kafkaProducer != nil {
+		dlqSource, err := kafka.NewDLQSource(kafka.ConsumerConfig{Brokers: cfg.Messaging.Kafka.Brokers})
+		if err != nil {
+			logger.Warn("DLQ admin init failed, DLQ endpoints disabled", logging.Err(err))
+		} else {
+			shutdownSteps = append(shutdownSteps, shutdownStep{name: "kafka-dlq", close: func() { dlqSource.Close() }})
+			dlqHandler = h.NewDLQHandler(kafka.NewDLQManager(dlqSource, kafkaProducer, logger), logger)
+		}
+	}
+
+	// --- Model serving — in-house model inference, queued by priority ---
+	var nerBackend common.ModelBackend
+	servingClient, err := newServingClient(cfg.Intelligence.Serving, logger)
+	if err != nil {
+		logger.Warn("model serving disabled", logging.Err(err))
+	} else if servingClient != nil {
+		shutdownSteps = append(shutdownSteps, shutdownStep{name: "model-serving", close: func() { servingClient.Close() }})
+		nerBackend, _ = common.NewServingBackend(servingClient, nil)
+	}
+
 	// --- ChemExtractor — regex-based chemical entity extraction ---
-	chemExtractor, err := newMinimalChemExtractor()
+	chemExtractor, err := newMinimalChemExtractor(nerBackend)
 	if err != nil {
 		logger.Warn("ChemExtractor init failed", logging.Err(err))
 	} else {
-		logger.Info("ChemExtractor initialized (regex-based, NER disabled)")
+		logger.Info("ChemExtractor initialized", logging.Bool("ner", nerBackend != nil))
 		_ = chemExtractor // available for patent_mining.ChemExtractionService when storage is ready
 	}
 
@@ -335,6 +502,14 @@ func main() {
 	}
 	_ = kgRepo // TODO: wire to tasks
 
+	// Inventor disambiguation publishes to the graph only when Neo4j is up.
+	var inventorGraph app_inventor.GraphWriter
+	if kgRepo != nil {
+		inventorGraph = kgRepo
+	}
+	inventorSvc := app_inventor.NewService(pg_repos.NewPostgresInventorRepo(pgConn, logger), inventorGraph, app_inventor.Config{}, logger)
+	inventorHandler := h.NewInventorHandler(inventorSvc, logger)
+
 	// 3. OpenSearch Indexer
 	var osIndexer *search_os.Indexer
 	if osClient != nil {
@@ -405,11 +580,20 @@ func main() {
 		PortfolioHandler:      portfolioHandler,
 		LifecycleHandler:      lifecycleHandler,
 		AuthHandler:           authHandler,
+		APIKeyHandler:         apiKeyHandler,
 		AIHandler:             aiHandler,
 		CollaborationHandler:  collaborationHandler,
+		CommentHandler:        commentHandler,
+		SavedSearchHandler:    savedSearchHandler,
 		HealthHandler:         healthHandler,
 		ReportHandler:         reportHandler,
 		DashboardHandler:      dashboardHandler,
+		DLQHandler:            dlqHandler,
+		UsageHandler:          usageHandler,
+		AssigneeHandler:       assigneeHandler,
+		InventorHandler:       inventorHandler,
+		WSHandler:             wsHandler,
+		AuthMiddleware:      authMw,
 		CORSMiddleware:      corsMw,
 		Logger:              logger,
 		MetricsCollector:    metrics,
@@ -450,6 +634,9 @@ func main() {
 
 	// Register gRPC services - only the available vertical slices
 	pb.RegisterMoleculeServiceServer(grpcSrv, services.NewMoleculeServiceServer(moleculeRepo, similaritySvc, logger))
+	pb.RegisterPortfolioServiceServer(grpcSrv, services.NewPortfolioServiceServer(portfolioSvc, valuationSvc, logger))
+	pb.RegisterReportServiceServer(grpcSrv, services.NewReportServiceServer(ftoSvc, portfolioReportSvc, logger))
+	pb.RegisterLifecycleServiceServer(grpcSrv, services.NewLifecycleServiceServer(annuitySvc, deadlineSvc, logger))
 
 	// Start HTTP Server
 	go func() {
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 )
 
 func TestLoadConfig_NonExistentPathReturnsDefault(t *testing.T) {
@@ -154,6 +156,22 @@ func TestRedisHealthAdapter_CheckWithNilClient(t *testing.T) {
 
 // --- Global Placeholders Test ---
 
+func TestNewServingClient(t *testing.T) {
+	logger := logging.NewNopLogger()
+
+	client, err := newServingClient(config.ModelServingConfig{}, logger)
+	require.NoError(t, err)
+	assert.Nil(t, client, "no endpoint means no model serving")
+
+	client, err = newServingClient(config.ModelServingConfig{Endpoint: "http://localhost:8500"}, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	assert.IsType(t, &common.ServingScheduler{}, client, "model calls are scheduled by priority")
+
+	_, err = newServingClient(config.ModelServingConfig{Endpoint: "localhost:8500"}, logger)
+	assert.Error(t, err)
+}
+
 func TestGlobalPlaceholderVarsCompile(t *testing.T) {
 	// This test verifies that the package-level placeholder variables
 	// exist and can be referenced (ensuring they compile)
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	appauth "github.com/turtacn/KeyIP-Intelligence/internal/application/auth"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/reporting"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/strategy_gpt"
+	h "github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/handlers"
+)
+
+// These tests drive FTO report requests through the apiserver's auth
+// middleware and LLM tenant resolvers, so the LLM backend wrappers see the
+// tenant from the caller's JWT.
+
+const testPassword = "correct horse"
+
+// signInUserRepo serves the lookups made by sign-in.
+type signInUserRepo struct {
+	user.UserRepository
+	users map[string]*user.User
+	orgs  map[uuid.UUID][]*user.Organization
+}
+
+func (r *signInUserRepo) GetByEmailForAuth(_ context.Context, email string) (*user.User, error) {
+	u, ok := r.users[email]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return u, nil
+}
+
+func (r *signInUserRepo) UpdateLoginInfo(context.Context, uuid.UUID, string) error { return nil }
+
+func (r *signInUserRepo) GetUserOrganizations(_ context.Context, id uuid.UUID) ([]*user.Organization, error) {
+	return r.orgs[id], nil
+}
+
+// countingBackend answers every call with a fixed report and counts calls.
+type countingBackend struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (b *countingBackend) Predict(context.Context, *common.PredictRequest) (*common.PredictResponse, error) {
+	b.mu.Lock()
+	b.calls++
+	b.mu.Unlock()
+	return &common.PredictResponse{Outputs: map[string][]byte{
+		"text": []byte(`{"executive_summary":"No blocking patents found."}`),
+	}}, nil
+}
+
+func (b *countingBackend) PredictStream(context.Context, *common.PredictRequest) (<-chan *common.PredictResponse, error) {
+	return nil, assert.AnError
+}
+func (b *countingBackend) Healthy(context.Context) error { return nil }
+func (b *countingBackend) Close() error                  { return nil }
+
+func (b *countingBackend) Calls() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls
+}
+
+// recordingMeter records usage events and never rejects a call.
+type recordingMeter struct {
+	mu     sync.Mutex
+	events []*common.UsageEvent
+}
+
+func (m *recordingMeter) Authorize(context.Context, string) error { return nil }
+
+func (m *recordingMeter) Record(_ context.Context, event *common.UsageEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *recordingMeter) Events() []*common.UsageEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*common.UsageEvent(nil), m.events...)
+}
+
+// tenantTestServer serves the report routes behind the apiserver's auth
+// middleware, with reports generated through backend.
+type tenantTestServer struct {
+	handler http.Handler
+	auth    *appauth.Service
+	repo    *signInUserRepo
+}
+
+func newTenantTestServer(t *testing.T, backend common.ModelBackend) *tenantTestServer {
+	t.Helper()
+	logger := logging.NewNopLogger()
+	repo := &signInUserRepo{
+		users: make(map[string]*user.User),
+		orgs:  make(map[uuid.UUID][]*user.Organization),
+	}
+	authSvc := appauth.NewService(appauth.ServiceConfig{JWTSecret: "test-secret"}, repo, logger)
+
+	promptMgr, err := strategy_gpt.NewPromptManager(nil)
+	require.NoError(t, err)
+	gen, err := strategy_gpt.NewReportGenerator(backend, promptMgr, nil, nil, nil, nil)
+	require.NoError(t, err)
+	reports := h.NewReportHandler(reporting.NewStrategyFTOReportService(gen, nil), nil, nil, nil, logger)
+
+	mux := http.NewServeMux()
+	reports.RegisterRoutes(mux)
+	authMw := newAuthMiddleware(authSvc, nil, nil, logger)
+	return &tenantTestServer{handler: authMw.Authenticate()(mux), auth: authSvc, repo: repo}
+}
+
+// addUser registers a user in org (none when org is uuid.Nil) and returns
+// the user's access token.
+func (s *tenantTestServer) addUser(t *testing.T, email string, org uuid.UUID) (string, *user.User) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	require.NoError(t, err)
+	u := &user.User{ID: uuid.New(), Email: email, PasswordHash: string(hash), Status: "active"}
+	s.repo.users[email] = u
+	if org != uuid.Nil {
+		s.repo.orgs[u.ID] = []*user.Organization{{ID: org}}
+	}
+	resp, err := s.auth.SignIn(context.Background(), appauth.SignInRequest{Email: email, Password: testPassword})
+	require.NoError(t, err)
+	return resp.AccessToken, u
+}
+
+// generateReport requests an FTO report as the token's user and waits for
+// generation to finish.
+func (s *tenantTestServer) generateReport(t *testing.T, token string) h.ReportStatusResponse {
+	t.Helper()
+	body := `{"target_smiles":"c1ccccc1","jurisdiction":"CN","format":"pdf"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reports/fto", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	var accepted struct {
+		Data struct {
+			ReportID string `json:"report_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &accepted))
+
+	var status h.ReportStatusResponse
+	var polled struct {
+		Data *h.ReportStatusResponse `json:"data"`
+	}
+	require.Eventually(t, func() bool {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/"+accepted.Data.ReportID+"/status", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		s.handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			return false
+		}
+		polled.Data = &h.ReportStatusResponse{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &polled); err != nil {
+			return false
+		}
+		status = *polled.Data
+		return status.Status == string(reporting.StatusCompleted) || status.Status == string(reporting.StatusFailed)
+	}, 5*time.Second, 10*time.Millisecond)
+	return status
+}
+
+func TestReportGeneration_EnforcesTenantGuardrail(t *testing.T) {
+	blocked, open := uuid.New(), uuid.New()
+	policies, err := common.NewStaticRedactionPolicies(&common.RedactionPolicy{}, map[string]*common.RedactionPolicy{
+		blocked.String(): {BlockExternal: true},
+	})
+	require.NoError(t, err)
+	guard, err := common.NewRedactionGuard(common.RedactionGuardConfig{Provider: "anthropic", External: true},
+		policies, common.WithRedactionTenantResolver(httpTenantResolver))
+	require.NoError(t, err)
+	provider := &countingBackend{}
+	backend, err := common.NewRedactingBackend(provider, guard)
+	require.NoError(t, err)
+	srv := newTenantTestServer(t, backend)
+
+	blockedToken, _ := srv.addUser(t, "alice@blocked.example", blocked)
+	status := srv.generateReport(t, blockedToken)
+	assert.Equal(t, string(reporting.StatusFailed), status.Status)
+	assert.Contains(t, status.Error, "tenant policy")
+	assert.Zero(t, provider.Calls(), "a blocked tenant's prompt must not reach the provider")
+
+	openToken, _ := srv.addUser(t, "bob@open.example", open)
+	status = srv.generateReport(t, openToken)
+	assert.Equal(t, string(reporting.StatusCompleted), status.Status, status.Error)
+	assert.Equal(t, 1, provider.Calls())
+}
+
+func TestReportGeneration_MetersJWTTenant(t *testing.T) {
+	org := uuid.New()
+	meter := &recordingMeter{}
+	backend, err := common.NewMeteredBackend(&countingBackend{}, meter, "anthropic",
+		common.WithMeterTenantResolver(httpTenantResolver),
+		common.WithMeterUserResolver(httpUserResolver),
+	)
+	require.NoError(t, err)
+	srv := newTenantTestServer(t, backend)
+
+	token, u := srv.addUser(t, "alice@acme.example", org)
+	status := srv.generateReport(t, token)
+	require.Equal(t, string(reporting.StatusCompleted), status.Status, status.Error)
+
+	events := meter.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, org.String(), events[0].TenantID)
+	assert.Equal(t, u.ID.String(), events[0].UserID)
+}
+
+func TestReportGeneration_CachesPerTenant(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+	client, err := redis.NewClient(&redis.RedisConfig{Mode: "standalone", Addr: mr.Addr()}, logging.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	provider := &countingBackend{}
+	backend, err := common.NewCachedBackend(provider, redis.NewRedisCache(client, logging.NewNopLogger()),
+		common.ResponseCacheConfig{TTL: time.Hour}, common.WithCacheTenantResolver(httpTenantResolver))
+	require.NoError(t, err)
+	srv := newTenantTestServer(t, backend)
+
+	org := uuid.New()
+	alice, _ := srv.addUser(t, "alice@acme.example", org)
+	bob, _ := srv.addUser(t, "bob@acme.example", org)
+	for _, token := range []string{alice, bob} {
+		status := srv.generateReport(t, token)
+		require.Equal(t, string(reporting.StatusCompleted), status.Status, status.Error)
+	}
+	assert.Equal(t, 1, provider.Calls(), "the second identical report prompt is served from the tenant's cache")
+
+	other, _ := srv.addUser(t, "carol@other.example", uuid.New())
+	status := srv.generateReport(t, other)
+	require.Equal(t, string(reporting.StatusCompleted), status.Status, status.Error)
+	assert.Equal(t, 2, provider.Calls(), "other tenants do not share the cache")
+}
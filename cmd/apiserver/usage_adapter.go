@@ -0,0 +1,44 @@
+// usage_adapter.go — LLM usage ledger wiring for apiserver.
+// Converts the llm.usage configuration into the usage service that meters
+// every LLM call and enforces tenant budgets.
+package main
+
+import (
+	app_usage "github.com/turtacn/KeyIP-Intelligence/internal/application/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	usagedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	pg_repos "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres/repositories"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+)
+
+// newUsageService builds the usage service from configuration. Budget
+// alerts go to the server log.
+func newUsageService(cfg config.LLMUsageConfig, conn *postgres.Connection, logger logging.Logger) (app_usage.Service, error) {
+	prices := make(map[string]usagedomain.ModelPrice, len(cfg.Prices))
+	for model, p := range cfg.Prices {
+		prices[model] = usagedomain.ModelPrice{InputPer1K: p.InputPer1K, OutputPer1K: p.OutputPer1K}
+	}
+	table, err := usagedomain.NewPriceTable(prices)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := usagedomain.NewBudget(usagedomain.DefaultTenantID, cfg.DefaultBudget.SoftLimitUSD, cfg.DefaultBudget.HardLimitUSD); err != nil {
+		return nil, err
+	}
+	tenants := make(map[string]app_usage.Limits, len(cfg.Tenants))
+	for tenantID, b := range cfg.Tenants {
+		if _, err := usagedomain.NewBudget(tenantID, b.SoftLimitUSD, b.HardLimitUSD); err != nil {
+			return nil, err
+		}
+		tenants[tenantID] = app_usage.Limits{SoftLimitUSD: b.SoftLimitUSD, HardLimitUSD: b.HardLimitUSD}
+	}
+
+	return app_usage.NewService(pg_repos.NewPostgresLLMUsageRepo(conn, logger), app_usage.Config{
+		Prices:        table,
+		DefaultBudget: app_usage.Limits{SoftLimitUSD: cfg.DefaultBudget.SoftLimitUSD, HardLimitUSD: cfg.DefaultBudget.HardLimitUSD},
+		TenantBudgets: tenants,
+	}, nil, logger), nil
+}
+
+//Personal.AI order the ending
@@ -13,6 +13,7 @@ import (
 	"github.com/turtacn/KeyIP-Intelligence/internal/config"
 	domainMol "github.com/turtacn/KeyIP-Intelligence/internal/domain/molecule"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/search/hnsw"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/search/milvus"
 	apperrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
@@ -209,23 +210,33 @@ func round4(v float64) float64 {
 }
 
 // ============================================================================
-// milvusVectorStore — adapts the Milvus Searcher to patent_mining.VectorStore.
-// Degrades gracefully when Milvus is not configured or unreachable.
+// milvusVectorStore — adapts a vector Searcher to patent_mining.VectorStore.
+// Uses Milvus when configured, otherwise the embedded HNSW index under
+// search.local.index_dir. Degrades gracefully when neither is available.
 // ============================================================================
 
+// vectorSearcher is the part of milvus.Searcher and hnsw.Searcher used here.
+type vectorSearcher interface {
+	Search(ctx context.Context, req common.VectorSearchRequest) (*common.VectorSearchResult, error)
+}
+
 type milvusVectorStore struct {
-	searcher        *milvus.Searcher
+	searcher        vectorSearcher
 	logger          logging.Logger
 	collectionName  string
 	vectorFieldName string
 }
 
 // newMilvusVectorStore attempts to initialise a Milvus-backed VectorStore.
-// If the config does not specify a Milvus address, or the connection fails,
-// the store logs a warning and SearchByVector will return a helpful error.
+// If the config does not specify a Milvus address it falls back to the
+// embedded index; if neither is available, or the connection fails, the
+// store logs a warning and SearchByVector will return a helpful error.
 func newMilvusVectorStore(cfg *config.Config, logger logging.Logger) *milvusVectorStore {
 	milCfg := cfg.Search.Milvus
 	if milCfg.Address == "" {
+		if cfg.Search.Local.IndexDir != "" {
+			return newLocalVectorStore(cfg.Search.Local, logger)
+		}
 		logger.Warn("Milvus address not configured; vector search requires --server <addr>")
 		return &milvusVectorStore{logger: logger}
 	}
@@ -253,9 +264,30 @@ func newMilvusVectorStore(cfg *config.Config, logger logging.Logger) *milvusVect
 	}
 }
 
+// newLocalVectorStore opens the embedded HNSW index. Collections are built
+// offline into the same layout as the Milvus deployment.
+func newLocalVectorStore(localCfg config.LocalVectorConfig, logger logging.Logger) *milvusVectorStore {
+	searcher, err := hnsw.NewSearcher(hnsw.SearcherConfig{
+		Dir:            localCfg.IndexDir,
+		M:              localCfg.M,
+		EfConstruction: localCfg.EfConstruction,
+		EfSearch:       localCfg.EfSearch,
+	}, logger)
+	if err != nil {
+		logger.Warn("Local vector index unavailable, vector search disabled", logging.Err(err))
+		return &milvusVectorStore{logger: logger}
+	}
+	return &milvusVectorStore{
+		searcher:        searcher,
+		logger:          logger,
+		collectionName:  "molecules",
+		vectorFieldName: "embedding",
+	}
+}
+
 func (s *milvusVectorStore) SearchByVector(ctx context.Context, vector []float64, threshold float64, maxResults int, filters map[string]string) ([]patent_mining.SimilarityHit, error) {
 	if s.searcher == nil {
-		return nil, apperrors.NewMsg("vector search requires connection to the KeyIP API server or a local Milvus instance; use --server <addr>")
+		return nil, apperrors.NewMsg("vector search requires connection to the KeyIP API server, a local Milvus instance or search.local.index_dir; use --server <addr>")
 	}
 
 	filterExpr := buildFilterExpr(filters)
@@ -14,6 +14,8 @@
 //   * 实现基础设施初始化：PostgreSQL、Neo4j、Redis、OpenSearch、Milvus、Kafka Consumer、MinIO
 //   * 实现 Worker Pool：可配置并发数的 goroutine 池，使用 `errgroup` 管理并发
 //   * 实现 Topic 路由：根据 Kafka topic 将消息分发到对应的 Handler
+//   * 实现申请人回填：按 --assignee-backfill-interval 周期将专利原始申请人名称解析为规范申请人 ID 并写回
+//   * 实现优先级调度（scheduler.go）：每个 topic 独立消费与有界队列（队列满时暂停该 topic 拉取），加权优先级通道保证告警不排在批量回填之后，并按处理延迟自适应 topic 并发
 //   * 实现 Handler 注册：
 //     - patent.document.parse → 专利文档解析 Handler
 //     - molecule.fingerprint.compute → 分子指纹计算 Handler
@@ -56,11 +58,19 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	appassignee "github.com/turtacn/KeyIP-Intelligence/internal/application/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	apppatent "github.com/turtacn/KeyIP-Intelligence/internal/application/patent"
 	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/prometheus"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	pgconn "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres/repositories"
 	neo4jdriver "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/neo4j"
 	redisclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
 	minioclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/storage/minio"
@@ -77,6 +87,12 @@ const (
 	defaultHealthPort       = 8081
 	defaultHandlerTimeout   = 5 * time.Minute
 	maxRetries              = 3
+
+	defaultSavedSearchInterval      = time.Minute
+	defaultAssigneeBackfillInterval = time.Hour
+	// assigneeBackfillMaxNames bounds one backfill pass so a large import
+	// is worked off over several intervals.
+	assigneeBackfillMaxNames = 5000
 )
 
 // Well-known Kafka topics for async processing.
@@ -94,6 +110,8 @@ func main() {
 	configPath := flag.String("config", defaultWorkerConfigPath, "path to configuration file")
 	workerCount := flag.Int("workers", 0, "number of concurrent workers (default: CPU*2)")
 	topicFilter := flag.String("topics", "", "comma-separated list of topics to consume (default: all)")
+	savedSearchInterval := flag.Duration("saved-search-interval", defaultSavedSearchInterval, "how often to check for due saved searches (0 disables)")
+	assigneeBackfillInterval := flag.Duration("assignee-backfill-interval", defaultAssigneeBackfillInterval, "how often to resolve unresolved patent assignee names (0 disables)")
 	flag.Parse()
 
 	// Load configuration
@@ -155,6 +173,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Initialize tracing. Without it the global propagator is a no-op and
+	// traceparent headers are neither read nor written.
+	if cfg.Monitoring.Tracing.Enabled {
+		tp, err := tracing.SetupTracerProvider(context.Background(), tracing.Config{
+			ServiceName: "keyip-worker",
+			Environment: cfg.Monitoring.Tracing.Environment,
+			SampleRate:  cfg.Monitoring.Tracing.SampleRate,
+		})
+		if err != nil {
+			logger.Error("failed to initialize tracing", logging.Err(err))
+			os.Exit(1)
+		}
+		defer tracing.Shutdown(context.Background(), tp)
+	}
+
 	// Initialize infrastructure
 	infra, err := initWorkerInfrastructure(cfg, logger)
 	if err != nil {
@@ -164,7 +197,7 @@ func main() {
 	defer infra.Close()
 
 	// Initialize intelligence layer
-	modelRegistry, err := initWorkerIntelligence(cfg, logger)
+	modelRegistry, err := initWorkerIntelligence(cfg, infra, logger)
 	if err != nil {
 		logger.Error("failed to initialize intelligence layer", logging.Err(err))
 		os.Exit(1)
@@ -200,21 +233,35 @@ func main() {
 	// Build handler registry with all dependencies
 	handlerRegistry := buildHandlerRegistry(cfg, infra, modelRegistry, eventProducer, logger)
 
-	// Create Kafka consumer config from app config
-	consumerCfg := kafkaclient.ConsumerConfig{
-		Brokers:           cfg.Messaging.Kafka.Brokers,
-		GroupID:           cfg.Messaging.Kafka.ConsumerGroup,
-		Topics:            topics,
-		AutoOffsetReset:   cfg.Messaging.Kafka.AutoOffsetReset,
-		SessionTimeout:    cfg.Messaging.Kafka.SessionTimeout,
-		HeartbeatInterval: cfg.Messaging.Kafka.HeartbeatInterval,
+	// One consumer per topic, so a full queue pauses only that topic's
+	// partitions while the others keep flowing.
+	consumers := make(map[string]*kafkaclient.Consumer, len(topics))
+	for _, topic := range topics {
+		consumerCfg := kafkaclient.ConsumerConfig{
+			Brokers:           cfg.Messaging.Kafka.Brokers,
+			GroupID:           cfg.Messaging.Kafka.ConsumerGroup,
+			Topics:            []string{topic},
+			AutoOffsetReset:   cfg.Messaging.Kafka.AutoOffsetReset,
+			SessionTimeout:    cfg.Messaging.Kafka.SessionTimeout,
+			HeartbeatInterval: cfg.Messaging.Kafka.HeartbeatInterval,
+			ManualCommit:      true,
+		}
+		consumer, err := kafkaclient.NewConsumer(consumerCfg, logger)
+		if err != nil {
+			logger.Error("failed to create Kafka consumer", logging.String("topic", topic), logging.Err(err))
+			os.Exit(1)
+		}
+		defer consumer.Close()
+		consumers[topic] = consumer
 	}
-	consumer, err := kafkaclient.NewConsumer(consumerCfg, logger)
-	if err != nil {
-		logger.Error("failed to create Kafka consumer", logging.Err(err))
-		os.Exit(1)
+
+	// Priority lanes between the consumers and the workers
+	sched := newLaneScheduler(defaultLanes, numWorkers, metricsCollector)
+	committers := make(map[string]offsetCommitter, len(consumers))
+	for topic, consumer := range consumers {
+		committers[topic] = consumer
 	}
-	defer consumer.Close()
+	offsets := newOffsetTracker(committers, logger)
 
 	// Context for graceful shutdown and error propagation
 	// errgroup creates a context that is cancelled when any goroutine returns a non-nil error
@@ -227,21 +274,44 @@ func main() {
 	// Start health check server
 	healthSrv := startHealthServer(cfg, logger, metricsCollector, &shuttingDown)
 
-	// Message channel
-	msgChan := make(chan *common.Message, numWorkers*2)
-
 	// Spawn workers using errgroup
 	for i := 0; i < numWorkers; i++ {
 		workerID := i
 		g.Go(func() error {
-			return workerLoop(ctx, workerID, msgChan, handlerRegistry, dlqProducer, logger)
+			return workerLoop(ctx, workerID, sched, offsets, handlerRegistry, dlqProducer, logger)
 		})
 	}
 
-	// Spawn consumer loop using errgroup
+	// Re-run due saved searches and publish their new hits
+	if *savedSearchInterval > 0 {
+		if savedSearchSvc := buildSavedSearchService(infra, eventProducer, logger); savedSearchSvc != nil {
+			g.Go(func() error {
+				return savedSearchLoop(ctx, savedSearchSvc, *savedSearchInterval, logger.With(logging.String("component", "saved_search")))
+			})
+		}
+	}
+
+	// Resolve raw assignee names on patents to canonical assignees
+	if *assigneeBackfillInterval > 0 {
+		if assigneeSvc := buildAssigneeService(infra, logger); assigneeSvc != nil {
+			g.Go(func() error {
+				return assigneeBackfillLoop(ctx, assigneeSvc, *assigneeBackfillInterval, logger.With(logging.String("component", "assignee_backfill")))
+			})
+		}
+	}
+
+	// Spawn one consumer loop per topic using errgroup
+	lagSources := make(map[string]consumerLagSource, len(consumers))
+	for topic, consumer := range consumers {
+		topic, consumer := topic, consumer
+		lagSources[topic] = consumer
+		g.Go(func() error {
+			return consumerLoop(ctx, consumer, topic, sched, offsets, logger)
+		})
+	}
 	g.Go(func() error {
-		defer close(msgChan)
-		return consumerLoop(ctx, consumer, msgChan, logger)
+		sched.reportConsumerLag(ctx, lagSources, consumerLagInterval)
+		return nil
 	})
 
 	logger.Info("worker pool started", logging.Int("workers", numWorkers))
@@ -306,9 +376,13 @@ type workerInfrastructure struct {
 	minio      *minioclient.MinIOClient
 	opensearch *opensearchclient.Client
 	milvus     *milvusclient.Client
+	serving    intcommon.ServingClient
 }
 
 func (w *workerInfrastructure) Close() {
+	if w.serving != nil {
+		w.serving.Close()
+	}
 	if w.milvus != nil {
 		w.milvus.Close()
 	}
@@ -431,14 +505,39 @@ func initWorkerInfrastructure(cfg *config.Config, logger logging.Logger) (*worke
 	return infra, nil
 }
 
-func initWorkerIntelligence(cfg *config.Config, logger logging.Logger) (intcommon.ModelRegistry, error) {
+func initWorkerIntelligence(cfg *config.Config, infra *workerInfrastructure, logger logging.Logger) (intcommon.ModelRegistry, error) {
 	// Create a model loader and registry
 	// For now, use a noop implementation until full intelligence layer is wired
 	loader := intcommon.NewNoopModelLoader()
 	metrics := intcommon.NewNoopIntelligenceMetrics()
 	logAdapter := intcommon.NewNoopLogger()
 
-	registry, err := intcommon.NewModelRegistry(loader, metrics, logAdapter)
+	// Shadow, canary and A/B results survive worker restarts.
+	experiments := repositories.NewPostgresModelExperimentRepo(infra.pg, logger)
+	opts := []intcommon.RegistryOption{intcommon.WithExperimentStore(experiments)}
+
+	// Shadow candidates run on the serving cluster. Without one, models
+	// still resolve but shadow experiments cannot be started.
+	if endpoint := cfg.Intelligence.Serving.Endpoint; endpoint != "" {
+		servingOpts := []intcommon.ServingOption{intcommon.WithServingLogger(logAdapter)}
+		if cfg.Intelligence.Serving.Timeout > 0 {
+			servingOpts = append(servingOpts, intcommon.WithRequestTimeout(cfg.Intelligence.Serving.Timeout))
+		}
+		client, err := intcommon.NewHTTPServingClient(endpoint, servingOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("model serving: %w", err)
+		}
+		// Requests are queued by priority and micro-batched per model.
+		serving, err := intcommon.NewServingScheduler(client, intcommon.WithSchedulerLogger(logAdapter))
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("model serving: %w", err)
+		}
+		infra.serving = serving
+		opts = append(opts, intcommon.WithModelInvoker(intcommon.NewServingModelInvoker(serving)))
+	}
+
+	registry, err := intcommon.NewModelRegistry(loader, metrics, logAdapter, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("model registry: %w", err)
 	}
@@ -1210,29 +1309,37 @@ func startHealthServer(cfg *config.Config, logger logging.Logger, metrics promet
 	return srv
 }
 
+// workerLoop handles messages from the scheduler until ctx is done or the
+// scheduler is closed and drained. A message interrupted by shutdown is not
+// marked done, so its offset stays uncommitted and it is delivered again.
 func workerLoop(
 	ctx context.Context,
 	workerID int,
-	msgChan <-chan *common.Message,
+	sched *laneScheduler,
+	offsets *offsetTracker,
 	handlers map[string]MessageHandler,
 	dlqProducer *kafkaclient.Producer,
 	logger logging.Logger,
 ) error {
 	for {
-		select {
-		case <-ctx.Done():
+		sm, ok := sched.Next(ctx)
+		if !ok {
 			logger.Info("worker stopping", logging.Int("worker_id", workerID))
 			return nil
-		case msg, ok := <-msgChan:
-			if !ok {
-				// Channel closed
-				return nil
-			}
-			processMessage(ctx, workerID, msg, handlers, dlqProducer, logger)
+		}
+		err := processMessage(ctx, workerID, sm.Msg, handlers, dlqProducer, logger)
+		elapsed := time.Since(sm.dispatched)
+		sched.metrics.handled(sm.Msg.Topic, elapsed, err)
+		sched.Done(sm, elapsed)
+		if err == nil || ctx.Err() == nil {
+			offsets.Done(context.WithoutCancel(ctx), sm.Msg)
 		}
 	}
 }
 
+// processMessage runs the topic's handler with retries, sending the message
+// to the DLQ once they are exhausted. It returns the last handler error, or
+// nil on success and for topics without a handler.
 func processMessage(
 	ctx context.Context,
 	workerID int,
@@ -1240,14 +1347,21 @@ func processMessage(
 	handlers map[string]MessageHandler,
 	dlqProducer *kafkaclient.Producer,
 	logger logging.Logger,
-) {
+) error {
+	// Continue the producer's trace; follow-up events published by the
+	// handler inherit this span through ctx.
+	ctx, span := tracing.StartConsumerSpan(ctx, msg, tracing.OperationProcess)
+	defer span.End()
+	span.SetAttributes(attribute.Int("worker.id", workerID))
+	logger = logger.WithContext(ctx)
+
 	handler, ok := handlers[msg.Topic]
 	if !ok {
 		logger.Warn("no handler for topic",
 			logging.String("topic", msg.Topic),
 			logging.Int("worker_id", workerID),
 		)
-		return
+		return nil
 	}
 
 	// Process with timeout
@@ -1259,7 +1373,7 @@ func processMessage(
 		// Check context before processing
 		if err := ctx.Err(); err != nil {
 			logger.Warn("context cancelled during processing", logging.Err(err))
-			return
+			return err
 		}
 
 		if err := handler.Handle(handlerCtx, msg); err != nil {
@@ -1274,71 +1388,200 @@ func processMessage(
 			case <-time.After(time.Duration(1<<uint(attempt)) * time.Second):
 				continue
 			case <-ctx.Done():
-				return
+				return ctx.Err()
 			}
 		}
-		// Success - offset is auto-committed by the consumer
+		// Success - the worker loop commits the offset
 		logger.Debug("message processed successfully",
 			logging.String("topic", msg.Topic),
 			logging.Int("worker_id", workerID),
 		)
-		return
+		return nil
 	}
 
 	// Max retries exceeded - send to DLQ
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
 	logger.Error("max retries exceeded, sending to DLQ",
 		logging.String("topic", msg.Topic),
 		logging.Err(lastErr),
 	)
-	dlqMsg := &common.ProducerMessage{
-		Topic: msg.Topic + ".dlq",
-		Key:   msg.Key,
-		Value: msg.Value,
-		Headers: map[string]string{
-			"original_topic":     msg.Topic,
-			"original_partition": fmt.Sprintf("%d", msg.Partition),
-			"original_offset":    fmt.Sprintf("%d", msg.Offset),
-			"error":              lastErr.Error(),
-		},
-	}
+	dlqMsg := kafkaclient.NewDeadLetterMessage(kafkaclient.DeadLetterTopic(msg.Topic), msg, lastErr, maxRetries+1, time.Now())
 	if err := dlqProducer.Publish(ctx, dlqMsg); err != nil {
 		logger.Error("failed to send to DLQ", logging.Err(err))
 	}
+	return lastErr
 }
 
+// consumerLoop feeds topic's messages into the scheduler until ctx is done.
+// Enqueue blocks while the topic's queue is full, holding back further
+// fetches from its partitions. Offsets are committed by the workers through
+// offsets, not when a message is enqueued.
 func consumerLoop(
 	ctx context.Context,
 	consumer *kafkaclient.Consumer,
-	msgChan chan<- *common.Message,
+	topic string,
+	sched *laneScheduler,
+	offsets *offsetTracker,
 	logger logging.Logger,
 ) error {
-	// handler puts messages onto channel
 	handler := func(msgCtx context.Context, msg *common.Message) error {
+		offsets.Track(msg)
+		return sched.Enqueue(msgCtx, msg)
+	}
+	if err := consumer.Subscribe(topic, handler); err != nil {
+		return err
+	}
+
+	// Start returns once the consumer's fetch loop is running
+	if err := consumer.Start(ctx); err != nil {
+		logger.Error("consumer start error", logging.String("topic", topic), logging.Err(err))
+		return err
+	}
+
+	<-ctx.Done()
+	logger.Info("consumer loop stopping", logging.String("topic", topic),
+		logging.String("lane", sched.LaneOf(topic)))
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Saved search scheduler
+// ---------------------------------------------------------------------------
+
+// eventPublisher is the part of the Kafka producer the saved search notifier
+// needs.
+type eventPublisher interface {
+	Publish(ctx context.Context, msg *common.ProducerMessage) error
+}
+
+// kafkaHitNotifier publishes new saved search hits on the notification topic:
+// one notification.send event per recipient for channel delivery, and one
+// saved_search.new_hits event that the API server relays to WebSocket clients.
+type kafkaHitNotifier struct {
+	producer eventPublisher
+}
+
+func (n *kafkaHitNotifier) NotifyNewHits(ctx context.Context, event *collaboration.SavedSearchHitsEvent) error {
+	titles := make([]string, 0, len(event.NewHits))
+	for _, hit := range event.NewHits {
+		if hit.Title != "" {
+			titles = append(titles, hit.Title)
+		} else {
+			titles = append(titles, hit.ID)
+		}
+	}
+	subject := fmt.Sprintf("%d new results for saved search %q", len(event.NewHits), event.SearchName)
+	body := strings.Join(titles, "\n")
+
+	for _, recipient := range event.Recipients {
+		if err := n.publish(ctx, "notification.send", kafkaclient.NotificationPayload{
+			RecipientID: recipient,
+			Channel:     "in_app",
+			Subject:     subject,
+			Body:        body,
+			Priority:    "normal",
+		}); err != nil {
+			return err
+		}
+	}
+	return n.publish(ctx, collaboration.SavedSearchHitsEventType, event)
+}
+
+func (n *kafkaHitNotifier) publish(ctx context.Context, eventType string, payload interface{}) error {
+	env, err := kafkaclient.NewEventEnvelope(eventType, "worker", payload)
+	if err != nil {
+		return err
+	}
+	msg, err := env.ToMessage(kafkaclient.TopicNotification)
+	if err != nil {
+		return err
+	}
+	return n.producer.Publish(ctx, msg)
+}
+
+// buildSavedSearchService wires the saved search service for scheduled runs.
+// Only patent text searches have a backend in the worker; structure and
+// knowledge-graph searches are recorded as failed runs until one is wired.
+func buildSavedSearchService(infra *workerInfrastructure, producer eventPublisher, logger logging.Logger) collaboration.SavedSearchService {
+	if infra == nil || infra.pg == nil {
+		return nil
+	}
+	patentSvc := apppatent.NewService(repositories.NewPostgresPatentRepo(infra.pg, logger), logger)
+	executors := map[collabdomain.SavedSearchKind]collaboration.SearchExecutor{
+		collabdomain.SavedSearchPatent: collaboration.NewPatentTextExecutor(patentSvc),
+	}
+	return collaboration.NewSavedSearchService(
+		repositories.NewPostgresSavedSearchRepo(infra.pg, logger),
+		repositories.NewPostgresMemberRepo(infra.pg, logger),
+		nil,
+		executors,
+		&kafkaHitNotifier{producer: producer},
+		logger,
+	)
+}
+
+// savedSearchLoop re-runs due saved searches every interval until ctx is
+// cancelled. Run errors are logged and never stop the worker.
+func savedSearchLoop(
+	ctx context.Context,
+	svc collaboration.SavedSearchService,
+	interval time.Duration,
+	logger logging.Logger,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
 		select {
-		case msgChan <- msg:
-			return nil
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-msgCtx.Done():
-			return msgCtx.Err()
+			logger.Info("saved search loop stopping")
+			return nil
+		case <-ticker.C:
+			n, err := svc.RunDue(ctx, time.Now().UTC(), collaboration.DefaultDueSearchBatch)
+			if err != nil {
+				logger.Error("saved search run failed", logging.Err(err))
+				continue
+			}
+			if n > 0 {
+				logger.Info("saved searches run", logging.Int("count", n))
+			}
 		}
 	}
+}
 
-	for _, topic := range allTopics {
-		consumer.Subscribe(topic, handler)
+// buildAssigneeService wires the assignee resolution service used by the
+// backfill loop.
+func buildAssigneeService(infra *workerInfrastructure, logger logging.Logger) appassignee.Service {
+	if infra == nil || infra.pg == nil {
+		return nil
 	}
+	return appassignee.NewService(repositories.NewPostgresAssigneeRepo(infra.pg, logger), appassignee.Config{}, logger)
+}
 
-	// Start the consumer - it will process messages via its internal handlers
-	// Assuming Consumer.Start blocks until error or context cancel
-	if err := consumer.Start(ctx); err != nil {
-		if err != context.Canceled {
-			logger.Error("consumer start error", logging.Err(err))
-			return err
+// assigneeBackfillLoop resolves the assignee names of unresolved patents
+// every interval until ctx is cancelled. Names queued for review are
+// retried on later passes once a reviewer has decided.
+func assigneeBackfillLoop(
+	ctx context.Context,
+	svc appassignee.Service,
+	interval time.Duration,
+	logger logging.Logger,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("assignee backfill loop stopping")
+			return nil
+		case <-ticker.C:
+			if _, err := svc.Backfill(ctx, appassignee.BackfillOptions{MaxNames: assigneeBackfillMaxNames}); err != nil && ctx.Err() == nil {
+				logger.Error("assignee backfill failed", logging.Err(err))
+			}
 		}
 	}
-
-	logger.Info("consumer loop stopping")
-	return nil
 }
 
 //Personal.AI order the ending
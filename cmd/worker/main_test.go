@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	appassignee "github.com/turtacn/KeyIP-Intelligence/internal/application/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestAllTopics(t *testing.T) {
@@ -61,109 +68,141 @@ func (h *testHandler) Handle(ctx context.Context, msg *common.Message) error {
 	return nil
 }
 
+type countingHandler struct {
+	topic string
+	count *atomic.Int32
+}
+
+func (h *countingHandler) Topic() string { return h.topic }
+
+func (h *countingHandler) Handle(ctx context.Context, msg *common.Message) error {
+	h.count.Add(1)
+	return nil
+}
+
 // --- workerLoop Tests ---
 
 func TestWorkerLoop_ContextCancelled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Pre-cancel the context
 
-	err := workerLoop(ctx, 0, make(chan *common.Message), nil, nil, logging.NewNopLogger())
+	err := workerLoop(ctx, 0, newLaneScheduler(defaultLanes, 1, nil), nil, nil, nil, logging.NewNopLogger())
 	assert.NoError(t, err, "should exit cleanly when context is cancelled")
 }
 
-func TestWorkerLoop_ChannelClosed(t *testing.T) {
-	ch := make(chan *common.Message)
-	close(ch)
+func TestWorkerLoop_SchedulerClosed(t *testing.T) {
+	sched := newLaneScheduler(defaultLanes, 1, nil)
+	sched.Close()
 
-	err := workerLoop(context.Background(), 0, ch, nil, nil, logging.NewNopLogger())
-	assert.NoError(t, err, "should exit cleanly when message channel is closed")
+	err := workerLoop(context.Background(), 0, sched, nil, nil, nil, logging.NewNopLogger())
+	assert.NoError(t, err, "should exit cleanly when the scheduler is closed")
 }
 
 func TestWorkerLoop_NilHandlersMap(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	ch := make(chan *common.Message, 1)
-	ch <- &common.Message{Topic: "unknown.topic"}
-	// Close channel after sending so the loop will exit
-	close(ch)
-	cancel() // Also cancel to ensure clean exit
+	sched := newLaneScheduler(defaultLanes, 1, nil)
+	require.NoError(t, sched.Enqueue(context.Background(), &common.Message{Topic: "unknown.topic"}))
+	// Close after enqueueing so the loop drains and exits
+	sched.Close()
 
-	err := workerLoop(ctx, 0, ch, nil, nil, logging.NewNopLogger())
+	err := workerLoop(context.Background(), 0, sched, nil, nil, nil, logging.NewNopLogger())
 	assert.NoError(t, err, "should handle nil handlers map gracefully")
+	assert.Equal(t, 0, sched.queued())
 }
 
 func TestWorkerLoop_ProcessesMessageSuccessfully(t *testing.T) {
 	logger := logging.NewNopLogger()
+	var handled atomic.Int32
 	handlers := map[string]MessageHandler{
-		"test.topic": &testHandler{topic: "test.topic"},
+		"test.topic": &countingHandler{topic: "test.topic", count: &handled},
 	}
-	msgChan := make(chan *common.Message, 1)
+	sched := newLaneScheduler(defaultLanes, 1, nil)
 
-	msgChan <- &common.Message{
+	require.NoError(t, sched.Enqueue(context.Background(), &common.Message{
 		Topic:     "test.topic",
 		Partition: 0,
 		Offset:    100,
 		Key:       []byte("test-key"),
 		Value:     []byte("test-value"),
-	}
+	}))
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- workerLoop(context.Background(), 1, msgChan, handlers, nil, logger)
+		errCh <- workerLoop(context.Background(), 1, sched, nil, handlers, nil, logger)
 	}()
 
-	// Give time for the message to be processed
-	time.Sleep(50 * time.Millisecond)
-	close(msgChan)
+	sched.Close()
 
 	select {
 	case err := <-errCh:
 		assert.NoError(t, err, "workerLoop should complete cleanly")
+		assert.Equal(t, int32(1), handled.Load())
 	case <-time.After(2 * time.Second):
-		t.Fatal("workerLoop did not exit within timeout after channel close")
+		t.Fatal("workerLoop did not exit within timeout after scheduler close")
 	}
 }
 
 func TestWorkerLoop_MultipleMessages(t *testing.T) {
 	logger := logging.NewNopLogger()
+	var handled atomic.Int32
 	handlers := make(map[string]MessageHandler)
 	for _, topic := range allTopics {
-		handlers[topic] = &testHandler{topic: topic}
+		handlers[topic] = &countingHandler{topic: topic, count: &handled}
 	}
-	msgChan := make(chan *common.Message, len(allTopics))
+	sched := newLaneScheduler(defaultLanes, 1, nil)
 
 	// Send one message per topic
 	for i, topic := range allTopics {
-		msgChan <- &common.Message{
+		require.NoError(t, sched.Enqueue(context.Background(), &common.Message{
 			Topic:     topic,
 			Partition: i,
 			Offset:    int64(i * 100),
-		}
+		}))
 	}
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- workerLoop(context.Background(), 1, msgChan, handlers, nil, logger)
+		errCh <- workerLoop(context.Background(), 1, sched, nil, handlers, nil, logger)
 	}()
 
-	time.Sleep(100 * time.Millisecond)
-	close(msgChan)
+	sched.Close()
 
 	select {
 	case err := <-errCh:
 		assert.NoError(t, err, "workerLoop should process all messages cleanly")
+		assert.Equal(t, int32(len(allTopics)), handled.Load())
 	case <-time.After(2 * time.Second):
 		t.Fatal("workerLoop did not exit within timeout")
 	}
 }
 
+func TestWorkerLoop_CommitsHandledMessages(t *testing.T) {
+	var handled atomic.Int32
+	handlers := map[string]MessageHandler{
+		"alert.trigger": &countingHandler{topic: "alert.trigger", count: &handled},
+	}
+	c := &recordingCommitter{}
+	offsets := newOffsetTracker(map[string]offsetCommitter{"alert.trigger": c}, logging.NewNopLogger())
+	sched := newLaneScheduler(defaultLanes, 1, nil)
+	for i := 0; i < 3; i++ {
+		msg := &common.Message{Topic: "alert.trigger", Offset: int64(i)}
+		offsets.Track(msg)
+		require.NoError(t, sched.Enqueue(context.Background(), msg))
+	}
+	sched.Close()
+
+	require.NoError(t, workerLoop(context.Background(), 0, sched, offsets, handlers, nil, logging.NewNopLogger()))
+	assert.Equal(t, int32(3), handled.Load())
+	assert.Equal(t, []int64{0, 1, 2}, c.offsets)
+}
+
 func TestWorkerLoop_RespectsContextCancellationWhileIdle(t *testing.T) {
 	logger := logging.NewNopLogger()
 	ctx, cancel := context.WithCancel(context.Background())
-	msgChan := make(chan *common.Message) // unbuffered, will block
+	sched := newLaneScheduler(defaultLanes, 1, nil) // empty, will block
 
 	errCh := make(chan error, 1)
 	go func() {
-		errCh <- workerLoop(ctx, 0, msgChan, nil, nil, logger)
+		errCh <- workerLoop(ctx, 0, sched, nil, nil, nil, logger)
 	}()
 
 	// Cancel while worker is waiting for messages
@@ -227,6 +266,39 @@ func TestProcessMessage_WithCancelledContext(t *testing.T) {
 	processMessage(ctx, 0, msg, handlers, nil, logger)
 }
 
+type ctxCapturingHandler struct {
+	testHandler
+	ctx context.Context
+}
+
+func (h *ctxCapturingHandler) Handle(ctx context.Context, msg *common.Message) error {
+	h.ctx = ctx
+	return nil
+}
+
+func TestProcessMessage_ContinuesProducerTrace(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	upstream := &common.ProducerMessage{Topic: "patent.new", Value: []byte("{}")}
+	_, publishSpan := tracing.StartPublishSpan(context.Background(), upstream)
+	publishSpan.End()
+
+	handler := &ctxCapturingHandler{testHandler: testHandler{topic: "patent.new"}}
+	msg := &common.Message{Topic: "patent.new", Offset: 7, Headers: upstream.Headers}
+	processMessage(context.Background(), 3, msg, map[string]MessageHandler{"patent.new": handler}, nil, logging.NewNopLogger())
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	process := spans[1]
+	assert.Equal(t, "patent.new process", process.Name)
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), process.Parent.SpanID())
+
+	// Events the handler publishes become children of the process span.
+	require.NotNil(t, handler.ctx)
+	assert.Equal(t, process.SpanContext.SpanID(), trace.SpanContextFromContext(handler.ctx).SpanID())
+	assert.Equal(t, process.SpanContext.TraceID().String(), logging.TraceIDFromContext(handler.ctx))
+}
+
 // --- workerInfrastructure Tests ---
 
 func TestWorkerInfrastructure_CloseNilSafe(t *testing.T) {
@@ -240,3 +312,112 @@ func TestWorkerInfrastructure_CloseMultipleTimes(t *testing.T) {
 	infra.Close()
 	infra.Close() // Second close should not panic
 }
+
+// --- Saved search Tests ---
+
+type recordingPublisher struct {
+	msgs []*common.ProducerMessage
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msg *common.ProducerMessage) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func TestKafkaHitNotifier_PublishesPerRecipientAndHitsEvent(t *testing.T) {
+	pub := &recordingPublisher{}
+	n := &kafkaHitNotifier{producer: pub}
+
+	err := n.NotifyNewHits(context.Background(), &collaboration.SavedSearchHitsEvent{
+		SearchID:   "s-1",
+		SearchName: "OLED hosts",
+		Recipients: []string{"u-1", "u-2"},
+		NewHits:    []collaboration.SearchHit{{ID: "p-1", Title: "CN1 host material"}, {ID: "p-2"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, pub.msgs, 3)
+
+	var types []string
+	for _, msg := range pub.msgs {
+		assert.Equal(t, kafkaclient.TopicNotification, msg.Topic)
+		types = append(types, msg.Headers["event_type"])
+	}
+	assert.Equal(t, []string{"notification.send", "notification.send", collaboration.SavedSearchHitsEventType}, types)
+
+	env, err := kafkaclient.MessageToEventEnvelope(&common.Message{Value: pub.msgs[1].Value})
+	require.NoError(t, err)
+	var payload kafkaclient.NotificationPayload
+	require.NoError(t, env.DecodePayload(&payload))
+	assert.Equal(t, "u-2", payload.RecipientID)
+	assert.Contains(t, payload.Subject, "2 new results")
+	assert.Equal(t, "CN1 host material\np-2", payload.Body)
+}
+
+func TestBuildSavedSearchService_NoDatabase(t *testing.T) {
+	assert.Nil(t, buildSavedSearchService(nil, nil, logging.NewNopLogger()))
+	assert.Nil(t, buildSavedSearchService(&workerInfrastructure{}, nil, logging.NewNopLogger()))
+}
+
+type countingSavedSearchService struct {
+	collaboration.SavedSearchService
+	runs atomic.Int32
+}
+
+func (s *countingSavedSearchService) RunDue(context.Context, time.Time, int) (int, error) {
+	s.runs.Add(1)
+	return 0, nil
+}
+
+func TestSavedSearchLoop_RunsUntilCancelled(t *testing.T) {
+	svc := &countingSavedSearchService{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- savedSearchLoop(ctx, svc, 5*time.Millisecond, logging.NewNopLogger())
+	}()
+
+	require.Eventually(t, func() bool { return svc.runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("saved search loop did not stop")
+	}
+}
+
+func TestBuildAssigneeService_NoDatabase(t *testing.T) {
+	assert.Nil(t, buildAssigneeService(nil, logging.NewNopLogger()))
+	assert.Nil(t, buildAssigneeService(&workerInfrastructure{}, logging.NewNopLogger()))
+}
+
+type countingAssigneeService struct {
+	appassignee.Service
+	runs     atomic.Int32
+	maxNames atomic.Int32
+}
+
+func (s *countingAssigneeService) Backfill(_ context.Context, opts appassignee.BackfillOptions) (*appassignee.BackfillResult, error) {
+	s.runs.Add(1)
+	s.maxNames.Store(int32(opts.MaxNames))
+	return &appassignee.BackfillResult{}, nil
+}
+
+func TestAssigneeBackfillLoop_RunsUntilCancelled(t *testing.T) {
+	svc := &countingAssigneeService{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- assigneeBackfillLoop(ctx, svc, 5*time.Millisecond, logging.NewNopLogger())
+	}()
+
+	require.Eventually(t, func() bool { return svc.runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("assignee backfill loop did not stop")
+	}
+	assert.Equal(t, int32(assigneeBackfillMaxNames), svc.maxNames.Load())
+}
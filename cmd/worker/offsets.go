@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// Offset commits
+//
+// Consumers run with ManualCommit, so handing a message to the scheduler
+// does not commit it. A worker marks the message done once it is handled,
+// and the partition's offset advances only past messages that are all done.
+// A crash therefore redelivers whatever was still queued or in flight
+// instead of losing it.
+
+// offsetCommitter commits a handled message's offset; kafka.Consumer
+// implements it.
+type offsetCommitter interface {
+	Commit(ctx context.Context, msg *common.Message) error
+}
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// partitionOffsets holds a partition's uncommitted messages in fetch order.
+// mu is held across commits so they reach the broker in offset order.
+type partitionOffsets struct {
+	mu      sync.Mutex
+	pending []*common.Message
+	done    map[*common.Message]bool // every pending message, true once done
+}
+
+// offsetTracker commits, per partition, the longest run of done messages.
+// A nil tracker commits nothing.
+type offsetTracker struct {
+	mu         sync.Mutex
+	committers map[string]offsetCommitter // by topic
+	partitions map[partitionKey]*partitionOffsets
+	logger     logging.Logger
+}
+
+func newOffsetTracker(committers map[string]offsetCommitter, logger logging.Logger) *offsetTracker {
+	return &offsetTracker{
+		committers: committers,
+		partitions: make(map[partitionKey]*partitionOffsets),
+		logger:     logger,
+	}
+}
+
+// partition returns the state for msg's partition, creating it if needed.
+func (t *offsetTracker) partition(msg *common.Message) *partitionOffsets {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[*common.Message]bool)}
+		t.partitions[key] = p
+	}
+	return p
+}
+
+// Track records msg as fetched. Call it before msg is enqueued; tracking
+// the same message again is a no-op.
+func (t *offsetTracker) Track(msg *common.Message) {
+	if t == nil {
+		return
+	}
+	p := t.partition(msg)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.done[msg]; ok {
+		return
+	}
+	if n := len(p.pending); n > 0 && msg.Offset <= p.pending[n-1].Offset {
+		// After a rebalance the partition restarts from its last commit;
+		// messages tracked before it will be delivered again.
+		p.pending = nil
+		p.done = make(map[*common.Message]bool)
+	}
+	p.pending = append(p.pending, msg)
+	p.done[msg] = false
+}
+
+// Done marks msg handled and commits the partition up to the last message
+// before the first one still pending.
+func (t *offsetTracker) Done(ctx context.Context, msg *common.Message) {
+	if t == nil {
+		return
+	}
+	p := t.partition(msg)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.done[msg]; !ok {
+		return // dropped by a rebalance
+	}
+	p.done[msg] = true
+
+	var last *common.Message
+	for len(p.pending) > 0 && p.done[p.pending[0]] {
+		last = p.pending[0]
+		delete(p.done, last)
+		p.pending = p.pending[1:]
+	}
+	if last == nil {
+		return
+	}
+	committer, ok := t.committers[msg.Topic]
+	if !ok {
+		return
+	}
+	if err := committer.Commit(ctx, last); err != nil {
+		t.logger.Error("offset commit failed",
+			logging.String("topic", last.Topic),
+			logging.Int("partition", last.Partition),
+			logging.Int64("offset", last.Offset),
+			logging.Err(err))
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+type recordingCommitter struct {
+	offsets []int64
+}
+
+func (c *recordingCommitter) Commit(ctx context.Context, msg *common.Message) error {
+	c.offsets = append(c.offsets, msg.Offset)
+	return nil
+}
+
+func trackedMessages(tr *offsetTracker, topic string, partition int, offsets ...int64) []*common.Message {
+	msgs := make([]*common.Message, len(offsets))
+	for i, off := range offsets {
+		msgs[i] = &common.Message{Topic: topic, Partition: partition, Offset: off}
+		tr.Track(msgs[i])
+	}
+	return msgs
+}
+
+func TestOffsetTracker_CommitsOnlyFinishedPrefix(t *testing.T) {
+	c := &recordingCommitter{}
+	tr := newOffsetTracker(map[string]offsetCommitter{"patent.new": c}, logging.NewNopLogger())
+	msgs := trackedMessages(tr, "patent.new", 0, 10, 11, 12)
+	other := trackedMessages(tr, "patent.new", 1, 3)
+
+	tr.Done(context.Background(), msgs[1])
+	assert.Empty(t, c.offsets, "offset 10 is still in flight")
+
+	tr.Done(context.Background(), other[0])
+	tr.Done(context.Background(), msgs[0])
+	assert.Equal(t, []int64{3, 11}, c.offsets)
+
+	tr.Done(context.Background(), msgs[2])
+	assert.Equal(t, []int64{3, 11, 12}, c.offsets)
+}
+
+func TestOffsetTracker_RedeliveryAfterRebalance(t *testing.T) {
+	c := &recordingCommitter{}
+	tr := newOffsetTracker(map[string]offsetCommitter{"alert.trigger": c}, logging.NewNopLogger())
+	before := trackedMessages(tr, "alert.trigger", 0, 5, 6)
+
+	// The partition comes back from its last commit.
+	after := trackedMessages(tr, "alert.trigger", 0, 5)
+
+	tr.Done(context.Background(), before[1])
+	tr.Done(context.Background(), before[0])
+	assert.Empty(t, c.offsets, "messages from before the rebalance are not committed")
+
+	tr.Done(context.Background(), after[0])
+	assert.Equal(t, []int64{5}, c.offsets)
+}
+
+func TestOffsetTracker_TrackTwice(t *testing.T) {
+	c := &recordingCommitter{}
+	tr := newOffsetTracker(map[string]offsetCommitter{"patent.new": c}, logging.NewNopLogger())
+	msgs := trackedMessages(tr, "patent.new", 0, 1)
+	tr.Track(msgs[0])
+
+	tr.Done(context.Background(), msgs[0])
+	assert.Equal(t, []int64{1}, c.offsets)
+}
+
+func TestOffsetTracker_Nil(t *testing.T) {
+	var tr *offsetTracker
+	msg := &common.Message{Topic: "patent.new"}
+	tr.Track(msg)
+	tr.Done(context.Background(), msg)
+}
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/prometheus"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// Lane scheduling
+//
+// Every topic has its own Kafka consumer and a bounded queue. When a queue is
+// full the consumer's handler blocks in Enqueue, which pauses fetching for
+// that topic's partitions only. Workers pull from the queues through weighted
+// priority lanes: lanes are picked by smooth weighted round-robin, each lane
+// may occupy at most MaxShare of the workers, and a lane's reserved workers
+// stay idle for it rather than being taken by other lanes. Within a lane,
+// each topic's concurrency is capped by a limit that adapts to handler
+// latency, so a slow handler cannot hold more workers than it can use.
+
+const (
+	laneCritical = "critical"
+	laneStandard = "standard"
+	laneBulk     = "bulk"
+
+	// Unknown topics (e.g. passed with --topics) are scheduled here.
+	defaultLane = laneStandard
+
+	latencyEWMAAlpha     = 0.2
+	limitDecreaseFactor  = 0.9
+	consumerLagInterval  = 15 * time.Second
+	minTopicConcurrency  = 1
+	defaultLaneQueueSize = 128
+)
+
+// workerLatencyBuckets span sub-second alert handling up to long bulk
+// queue waits.
+var workerLatencyBuckets = []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900}
+
+// errSchedulerClosed is returned by Enqueue once the scheduler is closed.
+var errSchedulerClosed = errors.New("scheduler closed")
+
+// laneConfig describes a priority lane.
+type laneConfig struct {
+	Name string
+	// Weight is the lane's share of dispatches when several lanes have work.
+	Weight int
+	// MaxShare caps the fraction of workers the lane may occupy.
+	MaxShare float64
+	// Reserve is the fraction of workers held back for this lane; other
+	// lanes cannot use them while the lane is below its reservation.
+	Reserve float64
+	// QueueSize bounds each topic's queue; a full queue pauses consumption.
+	QueueSize int
+	// TargetLatency drives the adaptive per-topic concurrency limit.
+	TargetLatency time.Duration
+	Topics        []string
+}
+
+// defaultLanes keeps alerts and deadlines ahead of bulk backfills: the
+// critical lane has the highest weight and a reservation, while bulk topics
+// can never hold more than half of the workers.
+var defaultLanes = []laneConfig{
+	{
+		Name:          laneCritical,
+		Weight:        8,
+		MaxShare:      1.0,
+		Reserve:       0.25,
+		QueueSize:     256,
+		TargetLatency: 2 * time.Second,
+		Topics:        []string{"alert.trigger", "deadline.approaching"},
+	},
+	{
+		Name:          laneStandard,
+		Weight:        4,
+		MaxShare:      0.75,
+		QueueSize:     defaultLaneQueueSize,
+		TargetLatency: 10 * time.Second,
+		Topics:        []string{"patent.status_changed", "infrastructure.health"},
+	},
+	{
+		Name:          laneBulk,
+		Weight:        1,
+		MaxShare:      0.5,
+		QueueSize:     64,
+		TargetLatency: 30 * time.Second,
+		Topics:        []string{"patent.new", "molecule.indexed", "report.generate"},
+	},
+}
+
+// adaptiveLimit is a per-topic concurrency limit. It shrinks multiplicatively
+// while the latency EWMA is above target and grows by about one slot per
+// limit's worth of completions otherwise.
+type adaptiveLimit struct {
+	limit  float64
+	min    float64
+	max    float64
+	target time.Duration
+	ewma   float64 // seconds
+}
+
+func newAdaptiveLimit(min, max int, target time.Duration) *adaptiveLimit {
+	if max < min {
+		max = min
+	}
+	return &adaptiveLimit{limit: float64(max), min: float64(min), max: float64(max), target: target}
+}
+
+func (a *adaptiveLimit) observe(d time.Duration) {
+	if a.ewma == 0 {
+		a.ewma = d.Seconds()
+	} else {
+		a.ewma = latencyEWMAAlpha*d.Seconds() + (1-latencyEWMAAlpha)*a.ewma
+	}
+	if a.target > 0 && a.ewma > a.target.Seconds() {
+		a.limit = math.Max(a.min, a.limit*limitDecreaseFactor)
+	} else {
+		a.limit = math.Min(a.max, a.limit+1/a.limit)
+	}
+}
+
+func (a *adaptiveLimit) current() int {
+	return int(math.Max(a.min, math.Floor(a.limit)))
+}
+
+// scheduledMessage is a message handed to a worker by the scheduler.
+type scheduledMessage struct {
+	Msg        *common.Message
+	topic      *topicQueue
+	dispatched time.Time
+}
+
+type queuedMessage struct {
+	msg      *common.Message
+	enqueued time.Time
+}
+
+type topicQueue struct {
+	name     string
+	lane     *lane
+	queue    []queuedMessage
+	capacity int
+	inFlight int
+	limit    *adaptiveLimit
+}
+
+type lane struct {
+	cfg      laneConfig
+	topics   []*topicQueue
+	next     int // round-robin cursor over topics
+	inFlight int
+	cap      int
+	reserved int
+	current  int // smooth weighted round-robin state
+}
+
+// laneScheduler dispatches queued messages to workers by lane priority.
+type laneScheduler struct {
+	mu       sync.Mutex
+	changed  chan struct{} // closed and replaced on every state change
+	lanes    []*lane
+	topics   map[string]*topicQueue
+	workers  int
+	inFlight int
+	closed   bool
+	metrics  *schedulerMetrics
+	now      func() time.Time
+}
+
+// newLaneScheduler creates a scheduler for the given number of workers.
+// metrics may be nil.
+func newLaneScheduler(lanes []laneConfig, workers int, metrics prometheus.MetricsCollector) *laneScheduler {
+	if workers < 1 {
+		workers = 1
+	}
+	s := &laneScheduler{
+		changed: make(chan struct{}),
+		topics:  make(map[string]*topicQueue),
+		workers: workers,
+		metrics: newSchedulerMetrics(metrics),
+		now:     time.Now,
+	}
+	for _, cfg := range lanes {
+		l := &lane{
+			cfg:      cfg,
+			cap:      clampWorkers(int(math.Ceil(cfg.MaxShare*float64(workers))), 1, workers),
+			reserved: clampWorkers(int(math.Ceil(cfg.Reserve*float64(workers))), 0, workers-1),
+		}
+		if l.cfg.Weight < 1 {
+			l.cfg.Weight = 1
+		}
+		if l.cfg.QueueSize < 1 {
+			l.cfg.QueueSize = defaultLaneQueueSize
+		}
+		s.lanes = append(s.lanes, l)
+		for _, topic := range cfg.Topics {
+			s.addTopic(topic, l)
+		}
+	}
+	return s
+}
+
+func clampWorkers(n, lo, hi int) int {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
+
+// addTopic registers topic with l. Callers hold s.mu or own s exclusively.
+func (s *laneScheduler) addTopic(topic string, l *lane) *topicQueue {
+	tq := &topicQueue{
+		name:     topic,
+		lane:     l,
+		capacity: l.cfg.QueueSize,
+		limit:    newAdaptiveLimit(minTopicConcurrency, l.cap, l.cfg.TargetLatency),
+	}
+	l.topics = append(l.topics, tq)
+	s.topics[topic] = tq
+	s.metrics.setLimit(tq)
+	return tq
+}
+
+// topicQueue returns the queue for topic, placing unknown topics in the
+// default lane. Callers hold s.mu.
+func (s *laneScheduler) topicQueue(topic string) *topicQueue {
+	if tq, ok := s.topics[topic]; ok {
+		return tq
+	}
+	for _, l := range s.lanes {
+		if l.cfg.Name == defaultLane {
+			return s.addTopic(topic, l)
+		}
+	}
+	if len(s.lanes) == 0 {
+		s.lanes = append(s.lanes, &lane{
+			cfg: laneConfig{Name: defaultLane, Weight: 1, MaxShare: 1, QueueSize: defaultLaneQueueSize},
+			cap: s.workers,
+		})
+	}
+	return s.addTopic(topic, s.lanes[len(s.lanes)-1])
+}
+
+// LaneOf returns the lane name topic is scheduled in.
+func (s *laneScheduler) LaneOf(topic string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.topicQueue(topic).lane.cfg.Name
+}
+
+// notify wakes every goroutine waiting for a state change. Callers hold s.mu.
+func (s *laneScheduler) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Enqueue adds msg to its topic's queue, blocking while the queue is full.
+// Blocking here stalls the topic's consumer, which applies backpressure to
+// the broker for that topic alone.
+func (s *laneScheduler) Enqueue(ctx context.Context, msg *common.Message) error {
+	s.mu.Lock()
+	tq := s.topicQueue(msg.Topic)
+	blocked := false
+	for !s.closed && len(tq.queue) >= tq.capacity {
+		if !blocked {
+			blocked = true
+			s.metrics.backpressure(tq)
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.mu.Lock()
+	}
+	defer s.mu.Unlock()
+	if s.closed {
+		return errSchedulerClosed
+	}
+	tq.queue = append(tq.queue, queuedMessage{msg: msg, enqueued: s.now()})
+	s.metrics.setDepth(tq)
+	s.notify()
+	return nil
+}
+
+// Next blocks until a message may be dispatched and returns it. It returns
+// false when ctx is done, or when the scheduler is closed and drained.
+// Every returned message must be released with Done.
+func (s *laneScheduler) Next(ctx context.Context) (*scheduledMessage, bool) {
+	s.mu.Lock()
+	for {
+		if sm := s.dispatch(); sm != nil {
+			s.mu.Unlock()
+			return sm, true
+		}
+		if s.closed && s.queued() == 0 {
+			s.mu.Unlock()
+			return nil, false
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, false
+		}
+		s.mu.Lock()
+	}
+}
+
+// Done records that sm finished after d and frees its worker slot.
+func (s *laneScheduler) Done(sm *scheduledMessage, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tq := sm.topic
+	tq.inFlight--
+	tq.lane.inFlight--
+	s.inFlight--
+	tq.limit.observe(d)
+	s.metrics.setInFlight(tq)
+	s.metrics.setLimit(tq)
+	s.notify()
+}
+
+// Close stops accepting messages. Queued messages are still dispatched.
+func (s *laneScheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.notify()
+	}
+}
+
+func (s *laneScheduler) queued() int {
+	n := 0
+	for _, tq := range s.topics {
+		n += len(tq.queue)
+	}
+	return n
+}
+
+// dispatch picks the next message by smooth weighted round-robin over the
+// lanes that can run one. Callers hold s.mu.
+func (s *laneScheduler) dispatch() *scheduledMessage {
+	if s.inFlight >= s.workers {
+		return nil
+	}
+	var best *lane
+	var bestTopic *topicQueue
+	total := 0
+	for _, l := range s.lanes {
+		tq := s.eligibleTopic(l)
+		if tq == nil {
+			continue
+		}
+		l.current += l.cfg.Weight
+		total += l.cfg.Weight
+		if best == nil || l.current > best.current {
+			best, bestTopic = l, tq
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	best.current -= total
+	best.next = (indexOf(best.topics, bestTopic) + 1) % len(best.topics)
+
+	qm := bestTopic.queue[0]
+	bestTopic.queue[0] = queuedMessage{}
+	bestTopic.queue = bestTopic.queue[1:]
+	bestTopic.inFlight++
+	best.inFlight++
+	s.inFlight++
+
+	now := s.now()
+	s.metrics.dispatched(bestTopic, qm, now)
+	s.notify()
+	return &scheduledMessage{Msg: qm.msg, topic: bestTopic, dispatched: now}
+}
+
+// eligibleTopic returns the topic of l to dispatch from next, or nil when l
+// is at its share, would eat into another lane's reservation, or has no
+// topic below its concurrency limit with queued work.
+func (s *laneScheduler) eligibleTopic(l *lane) *topicQueue {
+	if l.inFlight >= l.cap {
+		return nil
+	}
+	held := 0
+	for _, other := range s.lanes {
+		if other != l && other.inFlight < other.reserved {
+			held += other.reserved - other.inFlight
+		}
+	}
+	if s.workers-s.inFlight <= held {
+		return nil
+	}
+	for i := range l.topics {
+		tq := l.topics[(l.next+i)%len(l.topics)]
+		if len(tq.queue) > 0 && tq.inFlight < tq.limit.current() {
+			return tq
+		}
+	}
+	return nil
+}
+
+func indexOf(topics []*topicQueue, tq *topicQueue) int {
+	for i, t := range topics {
+		if t == tq {
+			return i
+		}
+	}
+	return 0
+}
+
+// ---------------------------------------------------------------------------
+// Metrics
+// ---------------------------------------------------------------------------
+
+// schedulerMetrics exports queue depth, lag and concurrency. A nil
+// *schedulerMetrics records nothing.
+type schedulerMetrics struct {
+	queueDepth       prometheus.GaugeVec
+	inFlight         prometheus.GaugeVec
+	concurrencyLimit prometheus.GaugeVec
+	queueWait        prometheus.HistogramVec
+	messageLag       prometheus.GaugeVec
+	consumerLag      prometheus.GaugeVec
+	handlerDuration  prometheus.HistogramVec
+	backpressureHits prometheus.CounterVec
+}
+
+func newSchedulerMetrics(c prometheus.MetricsCollector) *schedulerMetrics {
+	if c == nil {
+		return nil
+	}
+	return &schedulerMetrics{
+		queueDepth: c.RegisterGauge("worker_queue_depth",
+			"Messages waiting in the worker's per-topic queue.", "lane", "topic"),
+		inFlight: c.RegisterGauge("worker_in_flight",
+			"Messages currently being handled.", "lane", "topic"),
+		concurrencyLimit: c.RegisterGauge("worker_concurrency_limit",
+			"Adaptive per-topic concurrency limit.", "topic"),
+		queueWait: c.RegisterHistogram("worker_queue_wait_seconds",
+			"Time a message waited in the worker queue before dispatch.",
+			workerLatencyBuckets, "lane", "topic"),
+		messageLag: c.RegisterGauge("worker_message_lag_seconds",
+			"Age of the last dispatched message relative to its Kafka timestamp.", "topic"),
+		consumerLag: c.RegisterGauge("worker_consumer_lag",
+			"Messages between the consumer position and the partition high water mark.", "topic"),
+		handlerDuration: c.RegisterHistogram("worker_handler_duration_seconds",
+			"Message handling time including retries.",
+			workerLatencyBuckets, "topic", "status"),
+		backpressureHits: c.RegisterCounter("worker_backpressure_total",
+			"Times a full queue paused consumption of a topic.", "topic"),
+	}
+}
+
+func (m *schedulerMetrics) setDepth(tq *topicQueue) {
+	if m == nil {
+		return
+	}
+	m.queueDepth.WithLabelValues(tq.lane.cfg.Name, tq.name).Set(float64(len(tq.queue)))
+}
+
+func (m *schedulerMetrics) setInFlight(tq *topicQueue) {
+	if m == nil {
+		return
+	}
+	m.inFlight.WithLabelValues(tq.lane.cfg.Name, tq.name).Set(float64(tq.inFlight))
+}
+
+func (m *schedulerMetrics) setLimit(tq *topicQueue) {
+	if m == nil {
+		return
+	}
+	m.concurrencyLimit.WithLabelValues(tq.name).Set(float64(tq.limit.current()))
+}
+
+func (m *schedulerMetrics) backpressure(tq *topicQueue) {
+	if m == nil {
+		return
+	}
+	m.backpressureHits.WithLabelValues(tq.name).Inc()
+}
+
+func (m *schedulerMetrics) dispatched(tq *topicQueue, qm queuedMessage, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.setDepth(tq)
+	m.setInFlight(tq)
+	m.queueWait.WithLabelValues(tq.lane.cfg.Name, tq.name).Observe(now.Sub(qm.enqueued).Seconds())
+	if !qm.msg.Timestamp.IsZero() {
+		m.messageLag.WithLabelValues(tq.name).Set(now.Sub(qm.msg.Timestamp).Seconds())
+	}
+}
+
+func (m *schedulerMetrics) handled(topic string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = "failed"
+	}
+	m.handlerDuration.WithLabelValues(topic, status).Observe(d.Seconds())
+}
+
+// consumerLagSource is satisfied by *kafkaclient.Consumer.
+type consumerLagSource interface {
+	GetMetrics() *kafkaclient.ConsumerMetrics
+}
+
+// reportConsumerLag samples each topic consumer's lag until ctx is done.
+func (s *laneScheduler) reportConsumerLag(ctx context.Context, consumers map[string]consumerLagSource, interval time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for topic, c := range consumers {
+			s.metrics.consumerLag.WithLabelValues(topic).Set(float64(c.GetMetrics().Lag.Load()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/prometheus"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+func enqueueN(t *testing.T, s *laneScheduler, topic string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		require.NoError(t, s.Enqueue(context.Background(), &common.Message{Topic: topic, Offset: int64(i)}))
+	}
+}
+
+// tryNext returns the next dispatchable message, or nil if none is
+// dispatchable right now.
+func tryNext(s *laneScheduler) *scheduledMessage {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	sm, _ := s.Next(ctx)
+	return sm
+}
+
+func TestLaneScheduler_AlertsBypassBulkBacklog(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 4, nil)
+	enqueueN(t, s, "patent.new", 50)
+
+	// Bulk is capped at half the workers even with nothing else queued.
+	for i := 0; i < 2; i++ {
+		require.NotNil(t, tryNext(s))
+	}
+	assert.Nil(t, tryNext(s), "bulk lane must not occupy more than its share")
+
+	enqueueN(t, s, "alert.trigger", 1)
+	sm := tryNext(s)
+	require.NotNil(t, sm)
+	assert.Equal(t, "alert.trigger", sm.Msg.Topic)
+}
+
+func TestLaneScheduler_ReservesWorkersForCriticalLane(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 4, nil)
+	enqueueN(t, s, "patent.status_changed", 10)
+	enqueueN(t, s, "molecule.indexed", 10)
+
+	dispatched := 0
+	for tryNext(s) != nil {
+		dispatched++
+	}
+	assert.Equal(t, 3, dispatched, "one of four workers stays reserved for alerts")
+
+	enqueueN(t, s, "deadline.approaching", 1)
+	sm := tryNext(s)
+	require.NotNil(t, sm)
+	assert.Equal(t, laneCritical, s.LaneOf(sm.Msg.Topic))
+}
+
+func TestLaneScheduler_WeightedShare(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 100, nil)
+	enqueueN(t, s, "alert.trigger", 60)
+	enqueueN(t, s, "report.generate", 60)
+
+	counts := map[string]int{}
+	for i := 0; i < 45; i++ {
+		sm := tryNext(s)
+		require.NotNil(t, sm)
+		counts[sm.Msg.Topic]++
+		s.Done(sm, time.Millisecond)
+	}
+	assert.Equal(t, 40, counts["alert.trigger"])
+	assert.Equal(t, 5, counts["report.generate"])
+}
+
+func TestLaneScheduler_RoundRobinWithinLane(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 8, nil)
+	enqueueN(t, s, "patent.new", 5)
+	enqueueN(t, s, "molecule.indexed", 5)
+
+	first, second := tryNext(s), tryNext(s)
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.NotEqual(t, first.Msg.Topic, second.Msg.Topic)
+}
+
+func TestLaneScheduler_EnqueueBlocksWhenFull(t *testing.T) {
+	collector, err := prometheus.NewMetricsCollector(prometheus.CollectorConfig{Namespace: "test"}, logging.NewNopLogger())
+	require.NoError(t, err)
+	lanes := []laneConfig{{Name: laneBulk, Weight: 1, MaxShare: 1, QueueSize: 2, Topics: []string{"patent.new"}}}
+	s := newLaneScheduler(lanes, 1, collector)
+	enqueueN(t, s, "patent.new", 2)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Enqueue(context.Background(), &common.Message{Topic: "patent.new"})
+	}()
+	select {
+	case <-done:
+		t.Fatal("Enqueue should block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NotNil(t, tryNext(s))
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue did not resume after a dequeue")
+	}
+
+	rec := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `test_worker_backpressure_total{topic="patent.new"} 1`)
+	assert.Contains(t, rec.Body.String(), `test_worker_queue_depth{lane="bulk",topic="patent.new"} 2`)
+	assert.Contains(t, rec.Body.String(), `test_worker_in_flight{lane="bulk",topic="patent.new"} 1`)
+}
+
+func TestLaneScheduler_EnqueueCancelled(t *testing.T) {
+	lanes := []laneConfig{{Name: laneBulk, Weight: 1, MaxShare: 1, QueueSize: 1, Topics: []string{"patent.new"}}}
+	s := newLaneScheduler(lanes, 1, nil)
+	enqueueN(t, s, "patent.new", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Enqueue(ctx, &common.Message{Topic: "patent.new"}), context.DeadlineExceeded)
+}
+
+func TestLaneScheduler_Close(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 2, nil)
+	enqueueN(t, s, "alert.trigger", 1)
+	s.Close()
+
+	assert.ErrorIs(t, s.Enqueue(context.Background(), &common.Message{Topic: "alert.trigger"}), errSchedulerClosed)
+
+	sm, ok := s.Next(context.Background())
+	require.True(t, ok, "queued messages are still dispatched after Close")
+	s.Done(sm, time.Millisecond)
+	_, ok = s.Next(context.Background())
+	assert.False(t, ok)
+}
+
+func TestLaneScheduler_UnknownTopicUsesDefaultLane(t *testing.T) {
+	s := newLaneScheduler(defaultLanes, 2, nil)
+	assert.Equal(t, defaultLane, s.LaneOf("custom.topic"))
+	assert.Equal(t, laneBulk, s.LaneOf("patent.new"))
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	l := newAdaptiveLimit(1, 8, time.Second)
+	assert.Equal(t, 8, l.current())
+
+	for i := 0; i < 50; i++ {
+		l.observe(5 * time.Second)
+	}
+	assert.Equal(t, 1, l.current(), "slow handlers shrink the limit to its minimum")
+
+	for i := 0; i < 200; i++ {
+		l.observe(10 * time.Millisecond)
+	}
+	assert.Equal(t, 8, l.current(), "fast handlers grow the limit back to its maximum")
+}
+
+func TestLaneScheduler_AdaptiveLimitCapsTopic(t *testing.T) {
+	lanes := []laneConfig{{Name: laneBulk, Weight: 1, MaxShare: 1, QueueSize: 30, TargetLatency: time.Second, Topics: []string{"patent.new"}}}
+	s := newLaneScheduler(lanes, 4, nil)
+	enqueueN(t, s, "patent.new", 30)
+
+	// Slow completions shrink the limit from 4 to 1.
+	for i := 0; i < 20; i++ {
+		sm := tryNext(s)
+		require.NotNil(t, sm)
+		s.Done(sm, 10*time.Second)
+	}
+	require.NotNil(t, tryNext(s))
+	assert.Nil(t, tryNext(s), "a slow topic is held to its adaptive limit")
+}
+
+type fakeLagSource struct{ lag int64 }
+
+func (f fakeLagSource) GetMetrics() *kafkaclient.ConsumerMetrics {
+	m := &kafkaclient.ConsumerMetrics{}
+	m.Lag.Store(f.lag)
+	return m
+}
+
+func TestLaneScheduler_ReportConsumerLag(t *testing.T) {
+	collector, err := prometheus.NewMetricsCollector(prometheus.CollectorConfig{Namespace: "test"}, logging.NewNopLogger())
+	require.NoError(t, err)
+	s := newLaneScheduler(defaultLanes, 2, collector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.reportConsumerLag(ctx, map[string]consumerLagSource{"patent.new": fakeLagSource{lag: 42}}, time.Hour)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		collector.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		return strings.Contains(rec.Body.String(), `test_worker_consumer_lag{topic="patent.new"} 42`)
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,429 @@
+// ---
+// internal/application/assignee/service.go
+//
+// 功能定位: 申请人/专利权人实体解析应用服务，将专利上的原始申请人名称解析为规范申请人 ID，
+//   供竞争对手追踪、专利星座图与引用分析按公司（而非原始字符串）聚合。
+//
+// 核心实现:
+//   - Service 接口: Resolve / ListReviews / ApproveReview / RejectReview / SetParent / Hierarchy / Backfill / GroupPatentIDs
+//   - 解析顺序: 规则归一化 → 精确别名（含人工维护别名表）→ 分块召回 + 两两打分
+//     （≥ 自动合并阈值直接合并，≥ 复核阈值进入复核队列，否则新建申请人）
+//   - 复核队列: 待复核名称不写回专利，批准后成为候选申请人别名，拒绝则新建申请人
+//   - 回填: 按原始名称分批解析，并将 assignee_id 写回尚未解析的专利
+//   - 集团查询: GroupPatentIDs 按精确别名定位申请人，并合并其全部子公司的专利，供竞争对手对比使用
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+// ---
+
+package assignee
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	domain "github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const (
+	// DefaultCandidateLimit is the number of blocked candidates scored per name.
+	DefaultCandidateLimit = 50
+	// DefaultBackfillBatch is the number of distinct names resolved per batch.
+	DefaultBackfillBatch = 200
+	// DefaultReviewPageSize is the review page size for non-positive limits.
+	DefaultReviewPageSize = 50
+	maxReviewPageSize     = 500
+)
+
+// MatchMethod says how Resolve arrived at its answer.
+type MatchMethod string
+
+const (
+	// MatchAlias is an exact hit on a normalised alias, curated or learned.
+	MatchAlias MatchMethod = "alias"
+	// MatchFuzzy merged the name into a high-scoring candidate.
+	MatchFuzzy MatchMethod = "fuzzy"
+	// MatchReview queued the name for review; it has no assignee yet.
+	MatchReview MatchMethod = "review"
+	// MatchNew created a new assignee for the name.
+	MatchNew MatchMethod = "new"
+)
+
+// Resolution is the outcome of resolving one raw name. AssigneeID is
+// uuid.Nil when Method is MatchReview.
+type Resolution struct {
+	RawName        string      `json:"raw_name"`
+	NormalizedName string      `json:"normalized_name"`
+	AssigneeID     uuid.UUID   `json:"assignee_id"`
+	CanonicalName  string      `json:"canonical_name,omitempty"`
+	Method         MatchMethod `json:"method"`
+	Score          float64     `json:"score"`
+	ReviewID       *uuid.UUID  `json:"review_id,omitempty"`
+}
+
+// Resolved reports whether the name maps to an assignee.
+func (r *Resolution) Resolved() bool {
+	return r.AssigneeID != uuid.Nil
+}
+
+// HierarchyView describes an assignee's place in its corporate group.
+type HierarchyView struct {
+	Assignee       *domain.Assignee `json:"assignee"`
+	Ancestors      []uuid.UUID      `json:"ancestors"`
+	UltimateParent uuid.UUID        `json:"ultimate_parent"`
+	Descendants    []uuid.UUID      `json:"descendants"`
+}
+
+// BackfillOptions bounds a backfill run. MaxNames 0 means no limit.
+type BackfillOptions struct {
+	BatchSize int
+	MaxNames  int
+}
+
+// BackfillResult summarises a backfill run.
+type BackfillResult struct {
+	Names          int   `json:"names"`
+	Matched        int   `json:"matched"`
+	Created        int   `json:"created"`
+	QueuedReview   int   `json:"queued_review"`
+	Failed         int   `json:"failed"`
+	PatentsUpdated int64 `json:"patents_updated"`
+}
+
+// Service resolves assignee names and manages the merge review queue.
+type Service interface {
+	Resolve(ctx context.Context, rawName string) (*Resolution, error)
+	ListReviews(ctx context.Context, status domain.ReviewStatus, limit, offset int) ([]*domain.MergeReview, int64, error)
+	ApproveReview(ctx context.Context, reviewID uuid.UUID, reviewer string) (*domain.MergeReview, error)
+	RejectReview(ctx context.Context, reviewID uuid.UUID, reviewer string) (*domain.MergeReview, error)
+	SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error
+	Hierarchy(ctx context.Context, id uuid.UUID) (*HierarchyView, error)
+	Backfill(ctx context.Context, opts BackfillOptions) (*BackfillResult, error)
+	// GroupPatentIDs returns the IDs of patents held by the assignee that
+	// name resolves to and by its subsidiaries. Only exact alias matches
+	// count and nothing is created; found is false when name is unknown.
+	GroupPatentIDs(ctx context.Context, name string, limit int) (ids []string, found bool, err error)
+}
+
+// Config tunes the resolver thresholds. Zero values use the domain defaults.
+type Config struct {
+	AutoMergeThreshold float64
+	ReviewThreshold    float64
+	CandidateLimit     int
+}
+
+type service struct {
+	repo   domain.Repository
+	cfg    Config
+	logger logging.Logger
+}
+
+// NewService creates an assignee resolution service.
+func NewService(repo domain.Repository, cfg Config, logger logging.Logger) Service {
+	if cfg.AutoMergeThreshold <= 0 {
+		cfg.AutoMergeThreshold = domain.DefaultAutoMergeThreshold
+	}
+	if cfg.ReviewThreshold <= 0 || cfg.ReviewThreshold > cfg.AutoMergeThreshold {
+		cfg.ReviewThreshold = domain.DefaultReviewThreshold
+	}
+	if cfg.CandidateLimit <= 0 {
+		cfg.CandidateLimit = DefaultCandidateLimit
+	}
+	return &service{repo: repo, cfg: cfg, logger: logger}
+}
+
+func (s *service) Resolve(ctx context.Context, rawName string) (*Resolution, error) {
+	rawName = strings.TrimSpace(rawName)
+	res := &Resolution{RawName: rawName, NormalizedName: domain.Normalize(rawName)}
+	if res.NormalizedName == "" {
+		return nil, pkgerrors.NewValidationError("name", "assignee name is empty after normalisation")
+	}
+
+	existing, err := s.repo.FindByAlias(ctx, res.NormalizedName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		res.AssigneeID, res.CanonicalName, res.Method, res.Score = existing.ID, existing.CanonicalName, MatchAlias, 1
+		return res, nil
+	}
+
+	if pending, err := s.repo.FindPendingReview(ctx, res.NormalizedName); err != nil {
+		return nil, err
+	} else if pending != nil {
+		res.Method, res.Score, res.ReviewID = MatchReview, pending.Score, &pending.ID
+		return res, nil
+	}
+
+	candidates, err := s.repo.FindCandidates(ctx, domain.BlockingKeys(res.NormalizedName), s.cfg.CandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if ranked := domain.RankCandidates(res.NormalizedName, candidates); len(ranked) > 0 {
+		best := ranked[0]
+		switch {
+		case best.Score >= s.cfg.AutoMergeThreshold:
+			if err := s.addAlias(ctx, best.Assignee.ID, rawName, domain.AliasFuzzy, best.Score); err != nil {
+				return nil, err
+			}
+			res.AssigneeID, res.CanonicalName, res.Method, res.Score = best.Assignee.ID, best.Assignee.CanonicalName, MatchFuzzy, best.Score
+			return res, nil
+		case best.Score >= s.cfg.ReviewThreshold:
+			review := domain.NewMergeReview(rawName, best)
+			if err := s.repo.CreateReview(ctx, review); err != nil {
+				return nil, err
+			}
+			s.logger.Info("assignee merge queued for review",
+				logging.String("name", rawName),
+				logging.String("candidate", best.Assignee.CanonicalName),
+				logging.Float64("score", best.Score))
+			res.Method, res.Score, res.ReviewID = MatchReview, best.Score, &review.ID
+			return res, nil
+		}
+	}
+
+	created, err := s.createAssignee(ctx, rawName)
+	if err != nil {
+		return nil, err
+	}
+	res.AssigneeID, res.CanonicalName, res.Method = created.ID, created.CanonicalName, MatchNew
+	return res, nil
+}
+
+func (s *service) createAssignee(ctx context.Context, name string) (*domain.Assignee, error) {
+	a, err := domain.NewAssignee(name, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, a); err != nil {
+		return nil, err
+	}
+	if err := s.addAlias(ctx, a.ID, name, domain.AliasCanonical, 1); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *service) addAlias(ctx context.Context, id uuid.UUID, name string, source domain.AliasSource, confidence float64) error {
+	alias, err := domain.NewAlias(id, name, source, confidence)
+	if err != nil {
+		return err
+	}
+	return s.repo.AddAlias(ctx, alias)
+}
+
+func (s *service) ListReviews(ctx context.Context, status domain.ReviewStatus, limit, offset int) ([]*domain.MergeReview, int64, error) {
+	if status == "" {
+		status = domain.ReviewPending
+	}
+	if !status.IsValid() {
+		return nil, 0, pkgerrors.NewValidationError("status", "status must be pending, approved or rejected")
+	}
+	if limit <= 0 {
+		limit = DefaultReviewPageSize
+	}
+	if limit > maxReviewPageSize {
+		limit = maxReviewPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.ListReviews(ctx, status, limit, offset)
+}
+
+func (s *service) ApproveReview(ctx context.Context, reviewID uuid.UUID, reviewer string) (*domain.MergeReview, error) {
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := review.Approve(reviewer); err != nil {
+		return nil, err
+	}
+	if err := s.addAlias(ctx, review.CandidateID, review.RawName, domain.AliasReview, review.Score); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateReview(ctx, review); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.AssignPatents(ctx, review.RawName, review.CandidateID); err != nil {
+		return nil, err
+	}
+	s.logger.Info("assignee merge approved",
+		logging.String("review_id", review.ID.String()),
+		logging.String("name", review.RawName),
+		logging.String("reviewer", reviewer))
+	return review, nil
+}
+
+func (s *service) RejectReview(ctx context.Context, reviewID uuid.UUID, reviewer string) (*domain.MergeReview, error) {
+	review, err := s.repo.GetReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != domain.ReviewPending {
+		// Reject reports the conflict without changing the review.
+		return nil, review.Reject(reviewer, uuid.Nil)
+	}
+	created, err := s.createAssignee(ctx, review.RawName)
+	if err != nil {
+		return nil, err
+	}
+	if err := review.Reject(reviewer, created.ID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateReview(ctx, review); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.AssignPatents(ctx, review.RawName, created.ID); err != nil {
+		return nil, err
+	}
+	s.logger.Info("assignee merge rejected",
+		logging.String("review_id", review.ID.String()),
+		logging.String("name", review.RawName),
+		logging.String("reviewer", reviewer))
+	return review, nil
+}
+
+func (s *service) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if parentID != nil {
+		if _, err := s.repo.GetByID(ctx, *parentID); err != nil {
+			return err
+		}
+		all, err := s.repo.ListAll(ctx)
+		if err != nil {
+			return err
+		}
+		if err := domain.NewHierarchy(all).ValidateParent(id, *parentID); err != nil {
+			return err
+		}
+	}
+	return s.repo.SetParent(ctx, id, parentID)
+}
+
+func (s *service) Hierarchy(ctx context.Context, id uuid.UUID) (*HierarchyView, error) {
+	a, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h := domain.NewHierarchy(all)
+	return &HierarchyView{
+		Assignee:       a,
+		Ancestors:      emptyIfNil(h.Ancestors(id)),
+		UltimateParent: h.UltimateParent(id),
+		Descendants:    emptyIfNil(h.Descendants(id)),
+	}, nil
+}
+
+func (s *service) GroupPatentIDs(ctx context.Context, name string, limit int) ([]string, bool, error) {
+	normalized := domain.Normalize(name)
+	if normalized == "" {
+		return nil, false, nil
+	}
+	a, err := s.repo.FindByAlias(ctx, normalized)
+	if err != nil || a == nil {
+		return nil, false, err
+	}
+	all, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	group := append([]uuid.UUID{a.ID}, domain.NewHierarchy(all).Descendants(a.ID)...)
+	ids, err := s.repo.ListPatentIDs(ctx, group, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	return ids, true, nil
+}
+
+func emptyIfNil(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
+
+// Backfill resolves the assignee names of patents without an assignee ID
+// and writes the IDs back. Names that fail or wait for review are skipped
+// and picked up again by the next run.
+func (s *service) Backfill(ctx context.Context, opts BackfillOptions) (*BackfillResult, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBackfillBatch
+	}
+	result := &BackfillResult{}
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		limit := opts.BatchSize
+		if opts.MaxNames > 0 {
+			if remaining := opts.MaxNames - result.Names; remaining <= 0 {
+				break
+			} else if remaining < limit {
+				limit = remaining
+			}
+		}
+		names, err := s.repo.ListUnresolvedNames(ctx, cursor, limit)
+		if err != nil {
+			return result, err
+		}
+		if len(names) == 0 {
+			break
+		}
+		for _, name := range names {
+			result.Names++
+			s.backfillName(ctx, name, result)
+		}
+		cursor = names[len(names)-1]
+		if len(names) < limit {
+			break
+		}
+	}
+	s.logger.Info("assignee backfill completed",
+		logging.Int("names", result.Names),
+		logging.Int("matched", result.Matched),
+		logging.Int("created", result.Created),
+		logging.Int("queued_review", result.QueuedReview),
+		logging.Int("failed", result.Failed),
+		logging.Int64("patents_updated", result.PatentsUpdated))
+	return result, nil
+}
+
+func (s *service) backfillName(ctx context.Context, name string, result *BackfillResult) {
+	res, err := s.Resolve(ctx, name)
+	if err != nil {
+		if !pkgerrors.IsValidation(err) {
+			s.logger.Warn("failed to resolve assignee", logging.String("name", name), logging.Err(err))
+		}
+		result.Failed++
+		return
+	}
+	switch res.Method {
+	case MatchReview:
+		result.QueuedReview++
+		return
+	case MatchNew:
+		result.Created++
+	default:
+		result.Matched++
+	}
+	n, err := s.repo.AssignPatents(ctx, name, res.AssigneeID)
+	if err != nil {
+		s.logger.Warn("failed to assign patents", logging.String("name", name), logging.Err(err))
+		result.Failed++
+		return
+	}
+	result.PatentsUpdated += n
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,464 @@
+package assignee
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type memPatent struct {
+	id         string
+	name       string
+	assigneeID uuid.UUID
+}
+
+// memAssigneeRepo is an in-memory domain.Repository.
+type memAssigneeRepo struct {
+	mu        sync.Mutex
+	assignees map[uuid.UUID]*domain.Assignee
+	aliases   map[string]*domain.Alias
+	reviews   map[uuid.UUID]*domain.MergeReview
+	patents   []*memPatent
+}
+
+func newMemAssigneeRepo() *memAssigneeRepo {
+	return &memAssigneeRepo{
+		assignees: map[uuid.UUID]*domain.Assignee{},
+		aliases:   map[string]*domain.Alias{},
+		reviews:   map[uuid.UUID]*domain.MergeReview{},
+	}
+}
+
+func (r *memAssigneeRepo) Create(_ context.Context, a *domain.Assignee) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *a
+	r.assignees[a.ID] = &cp
+	return nil
+}
+
+func (r *memAssigneeRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Assignee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.assignees[id]
+	if !ok {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, "assignee not found")
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (r *memAssigneeRepo) ListAll(context.Context) ([]*domain.Assignee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.Assignee
+	for _, a := range r.assignees {
+		cp := *a
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (r *memAssigneeRepo) SetParent(_ context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.assignees[id].ParentID = parentID
+	return nil
+}
+
+func (r *memAssigneeRepo) AddAlias(_ context.Context, alias *domain.Alias) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.aliases[alias.NormalizedName]; !ok {
+		r.aliases[alias.NormalizedName] = alias
+	}
+	return nil
+}
+
+func (r *memAssigneeRepo) FindByAlias(_ context.Context, normalizedName string) (*domain.Assignee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alias, ok := r.aliases[normalizedName]
+	if !ok {
+		return nil, nil
+	}
+	cp := *r.assignees[alias.AssigneeID]
+	return &cp, nil
+}
+
+func (r *memAssigneeRepo) FindCandidates(_ context.Context, keys []string, limit int) ([]*domain.Assignee, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	want := map[string]bool{}
+	for _, k := range keys {
+		want[k] = true
+	}
+	matched := map[uuid.UUID]bool{}
+	for _, alias := range r.aliases {
+		for _, k := range alias.BlockingKeys {
+			if want[k] {
+				matched[alias.AssigneeID] = true
+			}
+		}
+	}
+	var out []*domain.Assignee
+	for id := range matched {
+		cp := *r.assignees[id]
+		for _, alias := range r.aliases {
+			if alias.AssigneeID == id {
+				cp.Aliases = append(cp.Aliases, alias.NormalizedName)
+			}
+		}
+		out = append(out, &cp)
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *memAssigneeRepo) CreateReview(_ context.Context, rv *domain.MergeReview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *rv
+	r.reviews[rv.ID] = &cp
+	return nil
+}
+
+func (r *memAssigneeRepo) GetReview(_ context.Context, id uuid.UUID) (*domain.MergeReview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rv, ok := r.reviews[id]
+	if !ok {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, "merge review not found")
+	}
+	cp := *rv
+	return &cp, nil
+}
+
+func (r *memAssigneeRepo) FindPendingReview(_ context.Context, normalizedName string) (*domain.MergeReview, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rv := range r.reviews {
+		if rv.NormalizedName == normalizedName && rv.Status == domain.ReviewPending {
+			cp := *rv
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memAssigneeRepo) ListReviews(_ context.Context, status domain.ReviewStatus, limit, offset int) ([]*domain.MergeReview, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.MergeReview
+	for _, rv := range r.reviews {
+		if rv.Status == status {
+			cp := *rv
+			out = append(out, &cp)
+		}
+	}
+	total := int64(len(out))
+	if offset >= len(out) {
+		return nil, total, nil
+	}
+	out = out[offset:]
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, total, nil
+}
+
+func (r *memAssigneeRepo) UpdateReview(_ context.Context, rv *domain.MergeReview) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *rv
+	r.reviews[rv.ID] = &cp
+	return nil
+}
+
+func (r *memAssigneeRepo) ListUnresolvedNames(_ context.Context, after string, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := map[string]bool{}
+	var names []string
+	for _, p := range r.patents {
+		if p.assigneeID == uuid.Nil && p.name > after && !seen[p.name] {
+			seen[p.name] = true
+			names = append(names, p.name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > limit {
+		names = names[:limit]
+	}
+	return names, nil
+}
+
+func (r *memAssigneeRepo) AssignPatents(_ context.Context, rawName string, assigneeID uuid.UUID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, p := range r.patents {
+		if p.name == rawName && p.assigneeID == uuid.Nil {
+			p.assigneeID = assigneeID
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *memAssigneeRepo) ListPatentIDs(_ context.Context, assigneeIDs []uuid.UUID, limit int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	want := map[uuid.UUID]bool{}
+	for _, id := range assigneeIDs {
+		want[id] = true
+	}
+	var ids []string
+	for _, p := range r.patents {
+		if want[p.assigneeID] && len(ids) < limit {
+			ids = append(ids, p.id)
+		}
+	}
+	return ids, nil
+}
+
+// seed creates an assignee with its canonical alias and extra curated aliases.
+func (r *memAssigneeRepo) seed(t *testing.T, name string, aliases ...string) *domain.Assignee {
+	t.Helper()
+	a, err := domain.NewAssignee(name, "")
+	require.NoError(t, err)
+	require.NoError(t, r.Create(context.Background(), a))
+	for i, n := range append([]string{name}, aliases...) {
+		source := domain.AliasCurated
+		if i == 0 {
+			source = domain.AliasCanonical
+		}
+		alias, err := domain.NewAlias(a.ID, n, source, 1)
+		require.NoError(t, err)
+		require.NoError(t, r.AddAlias(context.Background(), alias))
+	}
+	return a
+}
+
+func newTestService(repo domain.Repository) Service {
+	return NewService(repo, Config{}, logging.NewNopLogger())
+}
+
+func TestResolve_ExactAlias(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	display := repo.seed(t, "Samsung Display Co., Ltd.")
+	svc := newTestService(repo)
+
+	for _, name := range []string{"SAMSUNG DISPLAY CO LTD", "三星显示有限公司"} {
+		res, err := svc.Resolve(context.Background(), name)
+		require.NoError(t, err)
+		assert.Equal(t, MatchAlias, res.Method, name)
+		assert.Equal(t, display.ID, res.AssigneeID, name)
+	}
+}
+
+func TestResolve_FuzzyMergeLearnsAlias(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	display := repo.seed(t, "Samsung Display Co., Ltd.")
+	svc := newTestService(repo)
+
+	res, err := svc.Resolve(context.Background(), "Samsung Displays Co")
+	require.NoError(t, err)
+	assert.Equal(t, MatchFuzzy, res.Method)
+	assert.Equal(t, display.ID, res.AssigneeID)
+	assert.GreaterOrEqual(t, res.Score, domain.DefaultAutoMergeThreshold)
+
+	again, err := svc.Resolve(context.Background(), "SAMSUNG DISPLAYS")
+	require.NoError(t, err)
+	assert.Equal(t, MatchAlias, again.Method)
+}
+
+func TestResolve_LowConfidenceQueuesReview(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	repo.seed(t, "Universal Display Corporation")
+	svc := newTestService(repo)
+
+	res, err := svc.Resolve(context.Background(), "Universal Displays Technology")
+	require.NoError(t, err)
+	require.Equal(t, MatchReview, res.Method, "score %.3f", res.Score)
+	assert.False(t, res.Resolved())
+	require.NotNil(t, res.ReviewID)
+
+	// A second sighting reuses the pending review.
+	again, err := svc.Resolve(context.Background(), "UNIVERSAL DISPLAYS TECHNOLOGY INC")
+	require.NoError(t, err)
+	assert.Equal(t, *res.ReviewID, *again.ReviewID)
+	assert.Len(t, repo.reviews, 1)
+}
+
+func TestResolve_UnrelatedCreatesAssignee(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	repo.seed(t, "LG Display Co., Ltd.")
+	svc := newTestService(repo)
+
+	res, err := svc.Resolve(context.Background(), "LG Chem, Ltd.")
+	require.NoError(t, err)
+	assert.Equal(t, MatchNew, res.Method)
+	assert.Equal(t, "LG Chem, Ltd.", res.CanonicalName)
+	assert.Len(t, repo.assignees, 2)
+
+	_, err = svc.Resolve(context.Background(), " , ")
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+func queueReview(t *testing.T, repo *memAssigneeRepo, svc Service) (*domain.Assignee, uuid.UUID) {
+	t.Helper()
+	candidate := repo.seed(t, "Universal Display Corporation")
+	repo.patents = append(repo.patents, &memPatent{id: "p1", name: "Universal Displays Technology"})
+	res, err := svc.Resolve(context.Background(), "Universal Displays Technology")
+	require.NoError(t, err)
+	require.Equal(t, MatchReview, res.Method)
+	return candidate, *res.ReviewID
+}
+
+func TestApproveReview(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	svc := newTestService(repo)
+	candidate, reviewID := queueReview(t, repo, svc)
+
+	review, err := svc.ApproveReview(context.Background(), reviewID, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewApproved, review.Status)
+	assert.Equal(t, candidate.ID, repo.patents[0].assigneeID)
+
+	res, err := svc.Resolve(context.Background(), "Universal Displays Technology")
+	require.NoError(t, err)
+	assert.Equal(t, MatchAlias, res.Method)
+	assert.Equal(t, candidate.ID, res.AssigneeID)
+
+	_, err = svc.ApproveReview(context.Background(), reviewID, "u-2")
+	assert.True(t, pkgerrors.IsConflict(err))
+}
+
+func TestRejectReview(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	svc := newTestService(repo)
+	candidate, reviewID := queueReview(t, repo, svc)
+
+	review, err := svc.RejectReview(context.Background(), reviewID, "u-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReviewRejected, review.Status)
+	require.NotNil(t, review.ResolvedAssigneeID)
+	assert.NotEqual(t, candidate.ID, *review.ResolvedAssigneeID)
+	assert.Equal(t, *review.ResolvedAssigneeID, repo.patents[0].assigneeID)
+
+	_, err = svc.RejectReview(context.Background(), reviewID, "u-1")
+	assert.True(t, pkgerrors.IsConflict(err))
+}
+
+func TestListReviews_Validation(t *testing.T) {
+	svc := newTestService(newMemAssigneeRepo())
+	_, _, err := svc.ListReviews(context.Background(), "merged", 10, 0)
+	assert.True(t, pkgerrors.IsValidation(err))
+
+	reviews, total, err := svc.ListReviews(context.Background(), "", 0, -1)
+	require.NoError(t, err)
+	assert.Empty(t, reviews)
+	assert.Zero(t, total)
+}
+
+func TestSetParentAndHierarchy(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	electronics := repo.seed(t, "Samsung Electronics Co., Ltd.")
+	display := repo.seed(t, "Samsung Display Co., Ltd.")
+	svc := newTestService(repo)
+
+	require.NoError(t, svc.SetParent(context.Background(), display.ID, &electronics.ID))
+	err := svc.SetParent(context.Background(), electronics.ID, &display.ID)
+	assert.ErrorIs(t, err, domain.ErrHierarchyCycle)
+
+	view, err := svc.Hierarchy(context.Background(), electronics.ID)
+	require.NoError(t, err)
+	assert.Equal(t, electronics.ID, view.UltimateParent)
+	assert.Equal(t, []uuid.UUID{display.ID}, view.Descendants)
+	assert.NotNil(t, view.Ancestors)
+
+	missing := uuid.New()
+	assert.True(t, pkgerrors.IsNotFound(svc.SetParent(context.Background(), display.ID, &missing)))
+}
+
+func TestBackfill(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	display := repo.seed(t, "Samsung Display Co., Ltd.", "三星显示有限公司")
+	repo.seed(t, "Universal Display Corporation")
+	repo.patents = []*memPatent{
+		{id: "p1", name: "三星显示有限公司"},
+		{id: "p2", name: "SAMSUNG DISPLAY CO LTD"},
+		{id: "p3", name: "SAMSUNG DISPLAY CO LTD"},
+		{id: "p4", name: "Universal Displays Technology"},
+		{id: "p5", name: "Duksan Neolux Co., Ltd."},
+		{id: "p6", name: "---"},
+	}
+	svc := newTestService(repo)
+
+	result, err := svc.Backfill(context.Background(), BackfillOptions{BatchSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Names)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.QueuedReview)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, int64(4), result.PatentsUpdated)
+	for _, p := range repo.patents[:3] {
+		assert.Equal(t, display.ID, p.assigneeID, p.id)
+	}
+	assert.Equal(t, uuid.Nil, repo.patents[3].assigneeID)
+}
+
+func TestBackfill_MaxNames(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	repo.patents = []*memPatent{{id: "p1", name: "Alpha Materials"}, {id: "p2", name: "Beta Optics"}, {id: "p3", name: "Gamma Chemical"}}
+	svc := newTestService(repo)
+
+	result, err := svc.Backfill(context.Background(), BackfillOptions{BatchSize: 10, MaxNames: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Names)
+	assert.Equal(t, uuid.Nil, repo.patents[2].assigneeID)
+}
+
+func TestGroupPatentIDs_IncludesSubsidiaries(t *testing.T) {
+	repo := newMemAssigneeRepo()
+	electronics := repo.seed(t, "Samsung Electronics Co., Ltd.")
+	display := repo.seed(t, "Samsung Display Co., Ltd.")
+	display.ParentID = &electronics.ID
+	repo.assignees[display.ID].ParentID = &electronics.ID
+	repo.patents = []*memPatent{
+		{id: "p1", name: "Samsung Electronics", assigneeID: electronics.ID},
+		{id: "p2", name: "Samsung Display", assigneeID: display.ID},
+		{id: "p3", name: "LG Display"},
+	}
+	svc := newTestService(repo)
+
+	ids, found, err := svc.GroupPatentIDs(context.Background(), "SAMSUNG ELECTRONICS CO LTD", 100)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.ElementsMatch(t, []string{"p1", "p2"}, ids)
+
+	ids, found, err = svc.GroupPatentIDs(context.Background(), "Samsung Display", 100)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []string{"p2"}, ids)
+
+	_, found, err = svc.GroupPatentIDs(context.Background(), "Samsung", 100)
+	require.NoError(t, err)
+	assert.False(t, found, "group lookup must not fuzzy-match or create assignees")
+	assert.Len(t, repo.assignees, 2)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,425 @@
+// API key application service: issue, scope, rotate and revoke long-lived
+// credentials for CI and other machine clients, and authenticate requests
+// made with them. Scopes are keycloak permissions; a key can never carry a
+// permission its issuer does not hold.
+
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/auth/keycloak"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MaxAPIKeyRotationGrace bounds how long a rotated key keeps working next to
+// its replacement.
+const MaxAPIKeyRotationGrace = 7 * 24 * time.Hour
+
+// lastUsedWriteInterval throttles last_used_at updates so that a busy key
+// does not write its row on every request.
+const lastUsedWriteInterval = time.Minute
+
+// APIKeyStore is the subset of user.UserRepository the API key service needs.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *user.APIKey) error
+	GetAPIKeyByID(ctx context.Context, id uuid.UUID) (*user.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*user.APIKey, error)
+	GetAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*user.APIKey, error)
+	UpdateAPIKey(ctx context.Context, key *user.APIKey) error
+	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, ip string) error
+	IncrementAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error)
+	GetAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error)
+	GetUserPermissions(ctx context.Context, userID uuid.UUID, orgID *uuid.UUID) ([]string, error)
+}
+
+// IssueAPIKeyRequest is the input for issuing a key. Roles are the caller's
+// token roles; together with the caller's stored role permissions they bound
+// the scopes that may be granted. TenantID is the caller's authenticated
+// tenant, which the key then acts for.
+type IssueAPIKeyRequest struct {
+	UserID        string          `json:"-"`
+	TenantID      string          `json:"-"`
+	Roles         []string        `json:"-"`
+	Name          string          `json:"name"`
+	Scopes        []string        `json:"scopes"`
+	Tier          user.APIKeyTier `json:"tier,omitempty"`
+	ExpiresInDays int             `json:"expires_in_days,omitempty"`
+}
+
+// UpdateAPIKeyScopesRequest replaces the scopes of a key.
+type UpdateAPIKeyScopesRequest struct {
+	KeyID  string   `json:"-"`
+	UserID string   `json:"-"`
+	Roles  []string `json:"-"`
+	Scopes []string `json:"scopes"`
+}
+
+// RotateAPIKeyRequest replaces a key with a new secret. With a grace period
+// the old key keeps working until it elapses; otherwise it is revoked at once.
+type RotateAPIKeyRequest struct {
+	KeyID       string        `json:"-"`
+	UserID      string        `json:"-"`
+	GracePeriod time.Duration `json:"-"`
+}
+
+// IssuedAPIKey carries a newly created key. Secret is only ever returned here.
+type IssuedAPIKey struct {
+	Key    *user.APIKey `json:"key"`
+	Secret string       `json:"secret"`
+}
+
+// APIKeyUsage reports a key's request count for the current month.
+type APIKeyUsage struct {
+	KeyID     string    `json:"key_id"`
+	Period    time.Time `json:"period"`
+	Used      int64     `json:"used"`
+	Quota     int64     `json:"quota"`
+	Remaining int64     `json:"remaining"`
+	RateLimit int       `json:"rate_limit"`
+}
+
+// APIKeyService manages API keys.
+type APIKeyService interface {
+	Issue(ctx context.Context, req *IssueAPIKeyRequest) (*IssuedAPIKey, error)
+	List(ctx context.Context, userID string) ([]*user.APIKey, error)
+	Get(ctx context.Context, keyID, userID string) (*user.APIKey, error)
+	UpdateScopes(ctx context.Context, req *UpdateAPIKeyScopesRequest) (*user.APIKey, error)
+	Rotate(ctx context.Context, req *RotateAPIKeyRequest) (*IssuedAPIKey, error)
+	Revoke(ctx context.Context, keyID, userID string) error
+	Usage(ctx context.Context, keyID, userID string) (*APIKeyUsage, error)
+	// Authenticate resolves a plaintext key, records the request against the
+	// monthly quota and returns the key. Exhausted quotas yield
+	// ErrCodeTooManyRequests.
+	Authenticate(ctx context.Context, secret, clientIP string) (*user.APIKey, error)
+}
+
+type apiKeyServiceImpl struct {
+	store  APIKeyStore
+	roles  keycloak.RolePermissionMapping
+	logger logging.Logger
+	now    func() time.Time
+}
+
+// NewAPIKeyService creates an APIKeyService backed by store.
+func NewAPIKeyService(store APIKeyStore, logger logging.Logger) APIKeyService {
+	return &apiKeyServiceImpl{
+		store:  store,
+		roles:  keycloak.DefaultRolePermissionMapping(),
+		logger: logger,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// APIKeyScopes lists the permissions that may be granted to API keys.
+func APIKeyScopes() []string {
+	perms := keycloak.DefaultRolePermissionMapping()[keycloak.RoleSuperAdmin]
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		out = append(out, string(p))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *apiKeyServiceImpl) Issue(ctx context.Context, req *IssueAPIKeyRequest) (*IssuedAPIKey, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "request cannot be nil")
+	}
+	uid, err := parseAPIKeyUserID(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresInDays < 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "expires_in_days cannot be negative")
+	}
+	var orgID *uuid.UUID
+	if req.TenantID != "" {
+		id, err := uuid.Parse(req.TenantID)
+		if err != nil {
+			return nil, errors.New(errors.ErrCodeValidation, "invalid tenant id")
+		}
+		orgID = &id
+	}
+
+	held, err := s.heldPermissions(ctx, uid, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if !held.allows(string(keycloak.PermAPIKeyCreate)) {
+		return nil, errors.New(errors.ErrCodeForbidden, "not allowed to create API keys")
+	}
+	if err := s.checkScopes(req.Scopes, held); err != nil {
+		return nil, err
+	}
+	if req.Tier != "" && req.Tier != user.APIKeyTierFree && !held.allows(string(keycloak.PermAPIRateConfig)) {
+		return nil, errors.New(errors.ErrCodeForbidden, "not allowed to issue keys above the free tier")
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := s.now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+	key, secret, err := user.NewAPIKey(uid, req.Name, req.Scopes, req.Tier, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	key.OrganizationID = orgID
+	if err := s.store.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("API key issued",
+		logging.String("key_id", key.ID.String()),
+		logging.String("user_id", uid.String()),
+		logging.String("tier", string(key.Tier)))
+	return &IssuedAPIKey{Key: key, Secret: secret}, nil
+}
+
+func (s *apiKeyServiceImpl) List(ctx context.Context, userID string) ([]*user.APIKey, error) {
+	uid, err := parseAPIKeyUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetAPIKeysByUser(ctx, uid)
+}
+
+func (s *apiKeyServiceImpl) Get(ctx context.Context, keyID, userID string) (*user.APIKey, error) {
+	return s.ownedKey(ctx, keyID, userID)
+}
+
+func (s *apiKeyServiceImpl) UpdateScopes(ctx context.Context, req *UpdateAPIKeyScopesRequest) (*user.APIKey, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "request cannot be nil")
+	}
+	key, err := s.ownedKey(ctx, req.KeyID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !key.IsUsable(s.now()) {
+		return nil, errors.New(errors.ErrCodeConflict, "API key is revoked or expired")
+	}
+	held, err := s.heldPermissions(ctx, key.UserID, req.Roles)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkScopes(req.Scopes, held); err != nil {
+		return nil, err
+	}
+	if err := key.SetScopes(req.Scopes); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (s *apiKeyServiceImpl) Rotate(ctx context.Context, req *RotateAPIKeyRequest) (*IssuedAPIKey, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "request cannot be nil")
+	}
+	if req.GracePeriod < 0 || req.GracePeriod > MaxAPIKeyRotationGrace {
+		return nil, errors.New(errors.ErrCodeValidation, "grace period must be between 0 and 7 days")
+	}
+	old, err := s.ownedKey(ctx, req.KeyID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !old.IsUsable(now) {
+		return nil, errors.New(errors.ErrCodeConflict, "API key is revoked or expired")
+	}
+
+	key, secret, err := user.NewAPIKey(old.UserID, old.Name, old.Scopes, old.Tier, old.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	key.OrganizationID = old.OrganizationID
+	key.RateLimit = old.RateLimit
+	key.MonthlyQuota = old.MonthlyQuota
+	key.RotatedFromID = &old.ID
+	if err := s.store.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	if req.GracePeriod > 0 {
+		old.ExpireBy(now.Add(req.GracePeriod))
+	} else {
+		old.Revoke(now)
+	}
+	if err := s.store.UpdateAPIKey(ctx, old); err != nil {
+		return nil, err
+	}
+	s.logger.Info("API key rotated",
+		logging.String("key_id", key.ID.String()),
+		logging.String("rotated_from", old.ID.String()))
+	return &IssuedAPIKey{Key: key, Secret: secret}, nil
+}
+
+func (s *apiKeyServiceImpl) Revoke(ctx context.Context, keyID, userID string) error {
+	key, err := s.ownedKey(ctx, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	key.Revoke(s.now())
+	if err := s.store.UpdateAPIKey(ctx, key); err != nil {
+		return err
+	}
+	s.logger.Info("API key revoked", logging.String("key_id", key.ID.String()))
+	return nil
+}
+
+func (s *apiKeyServiceImpl) Usage(ctx context.Context, keyID, userID string) (*APIKeyUsage, error) {
+	key, err := s.ownedKey(ctx, keyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	period := user.UsagePeriod(s.now())
+	used, err := s.store.GetAPIKeyUsage(ctx, key.ID, period)
+	if err != nil {
+		return nil, err
+	}
+	u := &APIKeyUsage{
+		KeyID:     key.ID.String(),
+		Period:    period,
+		Used:      used,
+		Quota:     key.MonthlyQuota,
+		Remaining: -1,
+		RateLimit: key.RateLimit,
+	}
+	if key.MonthlyQuota > 0 {
+		u.Remaining = key.MonthlyQuota - used
+		if u.Remaining < 0 {
+			u.Remaining = 0
+		}
+	}
+	return u, nil
+}
+
+func (s *apiKeyServiceImpl) Authenticate(ctx context.Context, secret, clientIP string) (*user.APIKey, error) {
+	if !strings.HasPrefix(secret, user.APIKeyTokenPrefix) {
+		return nil, errors.New(errors.ErrCodeUnauthorized, "invalid API key")
+	}
+	key, err := s.store.GetAPIKeyByHash(ctx, user.HashAPIKey(secret))
+	if err != nil {
+		if errors.IsCode(err, errors.ErrCodeNotFound) {
+			return nil, errors.New(errors.ErrCodeUnauthorized, "invalid API key")
+		}
+		return nil, err
+	}
+	now := s.now()
+	if !key.IsUsable(now) {
+		return nil, errors.New(errors.ErrCodeUnauthorized, "API key is revoked or expired")
+	}
+
+	used, err := s.store.IncrementAPIKeyUsage(ctx, key.ID, user.UsagePeriod(now))
+	if err != nil {
+		return nil, err
+	}
+	if key.QuotaExceeded(used) {
+		return nil, errors.New(errors.ErrCodeTooManyRequests, "monthly API key quota exhausted")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedWriteInterval || key.LastUsedIP != clientIP {
+		if err := s.store.UpdateAPIKeyLastUsed(ctx, key.ID, clientIP); err != nil {
+			s.logger.Warn("failed to record API key use", logging.Err(err), logging.String("key_id", key.ID.String()))
+		} else {
+			key.LastUsedAt = &now
+			key.LastUsedIP = clientIP
+		}
+	}
+	return key, nil
+}
+
+// ownedKey loads a key and hides keys of other users behind NotFound.
+func (s *apiKeyServiceImpl) ownedKey(ctx context.Context, keyID, userID string) (*user.APIKey, error) {
+	uid, err := parseAPIKeyUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeValidation, "invalid API key id")
+	}
+	key, err := s.store.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.UserID != uid {
+		return nil, errors.New(errors.ErrCodeNotFound, "API key not found")
+	}
+	return key, nil
+}
+
+// permissionSet holds granted permissions; entries may be "*" or
+// "<resource>:*" wildcards as stored on database roles.
+type permissionSet map[string]bool
+
+func (p permissionSet) allows(perm string) bool {
+	if p["*"] || p[perm] {
+		return true
+	}
+	if i := strings.Index(perm, ":"); i > 0 {
+		return p[perm[:i]+":*"]
+	}
+	return false
+}
+
+func (s *apiKeyServiceImpl) heldPermissions(ctx context.Context, uid uuid.UUID, roles []string) (permissionSet, error) {
+	held := permissionSet{}
+	for _, r := range roles {
+		if keycloak.Role(r) == keycloak.RoleSuperAdmin {
+			held["*"] = true
+		}
+		for _, p := range s.roles[keycloak.Role(r)] {
+			held[string(p)] = true
+		}
+	}
+	perms, err := s.store.GetUserPermissions(ctx, uid, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range perms {
+		held[p] = true
+	}
+	return held, nil
+}
+
+func (s *apiKeyServiceImpl) checkScopes(scopes []string, held permissionSet) error {
+	known := make(map[string]bool)
+	for _, p := range s.roles[keycloak.RoleSuperAdmin] {
+		known[string(p)] = true
+	}
+	for _, scope := range user.NormalizeAPIKeyScopes(scopes) {
+		if !known[scope] {
+			return errors.New(errors.ErrCodeValidation, "unknown API key scope: "+scope)
+		}
+		if !held.allows(scope) {
+			return errors.New(errors.ErrCodeForbidden, "cannot grant a scope you do not hold: "+scope)
+		}
+	}
+	return nil
+}
+
+func parseAPIKeyUserID(userID string) (uuid.UUID, error) {
+	if userID == "" {
+		return uuid.Nil, errors.New(errors.ErrCodeUnauthorized, "user not authenticated")
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, errors.New(errors.ErrCodeValidation, "invalid user id")
+	}
+	return uid, nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type memAPIKeyStore struct {
+	keys     map[uuid.UUID]*user.APIKey
+	usage    map[uuid.UUID]int64
+	perms    []string
+	lastUsed int
+}
+
+func newMemAPIKeyStore(perms ...string) *memAPIKeyStore {
+	return &memAPIKeyStore{
+		keys:  make(map[uuid.UUID]*user.APIKey),
+		usage: make(map[uuid.UUID]int64),
+		perms: perms,
+	}
+}
+
+func (m *memAPIKeyStore) CreateAPIKey(_ context.Context, k *user.APIKey) error {
+	k.ID = uuid.New()
+	cp := *k
+	m.keys[k.ID] = &cp
+	return nil
+}
+
+func (m *memAPIKeyStore) GetAPIKeyByID(_ context.Context, id uuid.UUID) (*user.APIKey, error) {
+	k, ok := m.keys[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "API key not found")
+	}
+	cp := *k
+	return &cp, nil
+}
+
+func (m *memAPIKeyStore) GetAPIKeyByHash(_ context.Context, hash string) (*user.APIKey, error) {
+	for _, k := range m.keys {
+		if k.KeyHash == hash {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "API key not found")
+}
+
+func (m *memAPIKeyStore) GetAPIKeysByUser(_ context.Context, uid uuid.UUID) ([]*user.APIKey, error) {
+	var out []*user.APIKey
+	for _, k := range m.keys {
+		if k.UserID == uid {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (m *memAPIKeyStore) UpdateAPIKey(_ context.Context, k *user.APIKey) error {
+	cp := *k
+	m.keys[k.ID] = &cp
+	return nil
+}
+
+func (m *memAPIKeyStore) UpdateAPIKeyLastUsed(_ context.Context, id uuid.UUID, ip string) error {
+	m.lastUsed++
+	now := time.Now()
+	m.keys[id].LastUsedAt = &now
+	m.keys[id].LastUsedIP = ip
+	return nil
+}
+
+func (m *memAPIKeyStore) IncrementAPIKeyUsage(_ context.Context, id uuid.UUID, _ time.Time) (int64, error) {
+	m.usage[id]++
+	return m.usage[id], nil
+}
+
+func (m *memAPIKeyStore) GetAPIKeyUsage(_ context.Context, id uuid.UUID, _ time.Time) (int64, error) {
+	return m.usage[id], nil
+}
+
+func (m *memAPIKeyStore) GetUserPermissions(context.Context, uuid.UUID, *uuid.UUID) ([]string, error) {
+	return m.perms, nil
+}
+
+func newTestAPIKeyService(store APIKeyStore) *apiKeyServiceImpl {
+	return NewAPIKeyService(store, logging.NewNopLogger()).(*apiKeyServiceImpl)
+}
+
+func issueTestKey(t *testing.T, svc APIKeyService, uid uuid.UUID) *IssuedAPIKey {
+	t.Helper()
+	issued, err := svc.Issue(context.Background(), &IssueAPIKeyRequest{
+		UserID: uid.String(),
+		Roles:  []string{"api_user"},
+		Name:   "ci",
+		Scopes: []string{"patent:read"},
+	})
+	require.NoError(t, err)
+	return issued
+}
+
+func TestAPIKeyService_Issue(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create", "patent:*")
+	svc := newTestAPIKeyService(store)
+	uid := uuid.New()
+
+	issued, err := svc.Issue(context.Background(), &IssueAPIKeyRequest{
+		UserID:        uid.String(),
+		Name:          "ci",
+		Scopes:        []string{"patent:read", "patent:export"},
+		ExpiresInDays: 30,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, issued.Secret)
+	assert.Equal(t, user.HashAPIKey(issued.Secret), store.keys[issued.Key.ID].KeyHash)
+	require.NotNil(t, issued.Key.ExpiresAt)
+	assert.Equal(t, user.APIKeyTierFree, issued.Key.Tier)
+}
+
+func TestAPIKeyService_Issue_ScopeChecks(t *testing.T) {
+	uid := uuid.New()
+	cases := []struct {
+		name  string
+		perms []string
+		roles []string
+		req   IssueAPIKeyRequest
+		code  errors.ErrorCode
+	}{
+		{
+			name:  "missing create permission",
+			perms: []string{"patent:read"},
+			req:   IssueAPIKeyRequest{Name: "ci", Scopes: []string{"patent:read"}},
+			code:  errors.ErrCodeForbidden,
+		},
+		{
+			name:  "scope not held",
+			perms: []string{"api:key_create", "patent:read"},
+			req:   IssueAPIKeyRequest{Name: "ci", Scopes: []string{"patent:delete"}},
+			code:  errors.ErrCodeForbidden,
+		},
+		{
+			name:  "unknown scope",
+			perms: []string{"*"},
+			req:   IssueAPIKeyRequest{Name: "ci", Scopes: []string{"patent:everything"}},
+			code:  errors.ErrCodeValidation,
+		},
+		{
+			name:  "paid tier without rate config",
+			perms: []string{"api:key_create", "patent:read"},
+			req:   IssueAPIKeyRequest{Name: "ci", Scopes: []string{"patent:read"}, Tier: user.APIKeyTierEnterprise},
+			code:  errors.ErrCodeForbidden,
+		},
+		{
+			name:  "unauthenticated",
+			perms: []string{"*"},
+			req:   IssueAPIKeyRequest{Name: "ci", Scopes: []string{"patent:read"}},
+			code:  errors.ErrCodeUnauthorized,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := newTestAPIKeyService(newMemAPIKeyStore(tc.perms...))
+			req := tc.req
+			req.Roles = tc.roles
+			if tc.code != errors.ErrCodeUnauthorized {
+				req.UserID = uid.String()
+			}
+			_, err := svc.Issue(context.Background(), &req)
+			require.Error(t, err)
+			assert.True(t, errors.IsCode(err, tc.code), "got %v", err)
+		})
+	}
+}
+
+func TestAPIKeyService_Issue_RoleMapping(t *testing.T) {
+	svc := newTestAPIKeyService(newMemAPIKeyStore())
+	_, err := svc.Issue(context.Background(), &IssueAPIKeyRequest{
+		UserID: uuid.New().String(),
+		Roles:  []string{"super_admin"},
+		Name:   "ci",
+		Scopes: []string{"graph:admin"},
+		Tier:   user.APIKeyTierProfessional,
+	})
+	require.NoError(t, err)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create")
+	svc := newTestAPIKeyService(store)
+	uid := uuid.New()
+	issued := issueTestKey(t, svc, uid)
+
+	key, err := svc.Authenticate(context.Background(), issued.Secret, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, issued.Key.ID, key.ID)
+	assert.Equal(t, 1, store.lastUsed)
+
+	_, err = svc.Authenticate(context.Background(), issued.Secret, "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.lastUsed, "last-used writes are throttled")
+
+	_, err = svc.Authenticate(context.Background(), "kip_unknown", "10.0.0.1")
+	assert.True(t, errors.IsCode(err, errors.ErrCodeUnauthorized))
+	_, err = svc.Authenticate(context.Background(), "not-a-key", "10.0.0.1")
+	assert.True(t, errors.IsCode(err, errors.ErrCodeUnauthorized))
+}
+
+func TestAPIKeyService_IssueForTenant(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create")
+	svc := newTestAPIKeyService(store)
+	uid, orgID := uuid.New(), uuid.New()
+
+	issued, err := svc.Issue(context.Background(), &IssueAPIKeyRequest{
+		UserID:   uid.String(),
+		TenantID: orgID.String(),
+		Roles:    []string{"api_user"},
+		Name:     "ci",
+		Scopes:   []string{"patent:read"},
+	})
+	require.NoError(t, err)
+
+	key, err := svc.Authenticate(context.Background(), issued.Secret, "10.0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, key.OrganizationID)
+	assert.Equal(t, orgID, *key.OrganizationID)
+
+	_, err = svc.Issue(context.Background(), &IssueAPIKeyRequest{UserID: uid.String(), TenantID: "acme", Name: "ci"})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestAPIKeyService_Authenticate_Quota(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create")
+	svc := newTestAPIKeyService(store)
+	issued := issueTestKey(t, svc, uuid.New())
+	store.keys[issued.Key.ID].MonthlyQuota = 2
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.Authenticate(context.Background(), issued.Secret, "")
+		require.NoError(t, err)
+	}
+	_, err := svc.Authenticate(context.Background(), issued.Secret, "")
+	assert.True(t, errors.IsCode(err, errors.ErrCodeTooManyRequests))
+
+	usage, err := svc.Usage(context.Background(), issued.Key.ID.String(), issued.Key.UserID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), usage.Used)
+	assert.Equal(t, int64(0), usage.Remaining)
+}
+
+func TestAPIKeyService_Rotate(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create")
+	svc := newTestAPIKeyService(store)
+	uid := uuid.New()
+	old := issueTestKey(t, svc, uid)
+
+	rotated, err := svc.Rotate(context.Background(), &RotateAPIKeyRequest{
+		KeyID:       old.Key.ID.String(),
+		UserID:      uid.String(),
+		GracePeriod: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, old.Secret, rotated.Secret)
+	require.NotNil(t, rotated.Key.RotatedFromID)
+	assert.Equal(t, old.Key.ID, *rotated.Key.RotatedFromID)
+	assert.Equal(t, old.Key.Scopes, rotated.Key.Scopes)
+
+	// Both keys work during the grace period.
+	_, err = svc.Authenticate(context.Background(), old.Secret, "")
+	assert.NoError(t, err)
+	_, err = svc.Authenticate(context.Background(), rotated.Secret, "")
+	assert.NoError(t, err)
+	require.NotNil(t, store.keys[old.Key.ID].ExpiresAt)
+
+	// Without a grace period the old key is revoked immediately.
+	again, err := svc.Rotate(context.Background(), &RotateAPIKeyRequest{KeyID: rotated.Key.ID.String(), UserID: uid.String()})
+	require.NoError(t, err)
+	_, err = svc.Authenticate(context.Background(), rotated.Secret, "")
+	assert.True(t, errors.IsCode(err, errors.ErrCodeUnauthorized))
+	_, err = svc.Authenticate(context.Background(), again.Secret, "")
+	assert.NoError(t, err)
+
+	_, err = svc.Rotate(context.Background(), &RotateAPIKeyRequest{KeyID: again.Key.ID.String(), UserID: uid.String(), GracePeriod: 8 * 24 * time.Hour})
+	assert.True(t, errors.IsCode(err, errors.ErrCodeValidation))
+}
+
+func TestAPIKeyService_RevokeAndOwnership(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create")
+	svc := newTestAPIKeyService(store)
+	uid := uuid.New()
+	issued := issueTestKey(t, svc, uid)
+	keyID := issued.Key.ID.String()
+
+	err := svc.Revoke(context.Background(), keyID, uuid.New().String())
+	assert.True(t, errors.IsCode(err, errors.ErrCodeNotFound))
+
+	require.NoError(t, svc.Revoke(context.Background(), keyID, uid.String()))
+	require.NoError(t, svc.Revoke(context.Background(), keyID, uid.String()))
+	_, err = svc.Authenticate(context.Background(), issued.Secret, "")
+	assert.True(t, errors.IsCode(err, errors.ErrCodeUnauthorized))
+
+	_, err = svc.UpdateScopes(context.Background(), &UpdateAPIKeyScopesRequest{KeyID: keyID, UserID: uid.String(), Scopes: []string{"patent:read"}})
+	assert.True(t, errors.IsCode(err, errors.ErrCodeConflict))
+}
+
+func TestAPIKeyService_UpdateScopes(t *testing.T) {
+	store := newMemAPIKeyStore("api:key_create", "graph:read")
+	svc := newTestAPIKeyService(store)
+	uid := uuid.New()
+	issued := issueTestKey(t, svc, uid)
+
+	key, err := svc.UpdateScopes(context.Background(), &UpdateAPIKeyScopesRequest{
+		KeyID:  issued.Key.ID.String(),
+		UserID: uid.String(),
+		Roles:  []string{"api_user"},
+		Scopes: []string{"graph:read", "analysis:read"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analysis:read", "graph:read"}, key.Scopes)
+
+	_, err = svc.UpdateScopes(context.Background(), &UpdateAPIKeyScopesRequest{
+		KeyID:  issued.Key.ID.String(),
+		UserID: uid.String(),
+		Scopes: []string{"analysis:read"},
+	})
+	assert.True(t, errors.IsCode(err, errors.ErrCodeForbidden))
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	scopes := APIKeyScopes()
+	assert.Contains(t, scopes, "patent:read")
+	assert.Contains(t, scopes, "api:key_create")
+}
+
+//Personal.AI order the ending
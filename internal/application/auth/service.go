@@ -55,6 +55,9 @@ type TokenClaims struct {
 	Username string   `json:"preferred_username"`
 	Name     string   `json:"name"`
 	Roles    []string `json:"roles"`
+	// TenantID is the organization the user acts for; empty for users
+	// outside any organization.
+	TenantID string `json:"tenant_id,omitempty"`
 }
 
 // NewService creates a new auth Service.
@@ -105,6 +108,17 @@ func (s *Service) SignIn(ctx context.Context, req SignInRequest) (*SignInRespons
 		s.logger.Warn("failed to update last login", logging.Err(updateErr))
 	}
 
+	// Tenant budgets and LLM policies key off the token's tenant, so a user
+	// whose organization cannot be resolved is not signed in without one.
+	orgs, err := s.userRepo.GetUserOrganizations(ctx, u.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to resolve user organization")
+	}
+	var tenantID string
+	if len(orgs) > 0 {
+		tenantID = orgs[0].ID.String()
+	}
+
 	now := time.Now()
 	expiresAt := now.Add(s.jwtTTL)
 	claims := TokenClaims{
@@ -120,6 +134,7 @@ func (s *Service) SignIn(ctx context.Context, req SignInRequest) (*SignInRespons
 		Username: u.Username,
 		Name:     u.DisplayName,
 		Roles:    []string{"user"},
+		TenantID: tenantID,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -0,0 +1,594 @@
+// ---
+// internal/application/collaboration/comment.go
+//
+// 功能定位: 评论与批注应用服务，编排评论线程的创建、回复、编辑、解决/重开、删除与查询。
+//   评论可锚定到专利、权利要求（可选字符区间）、分子或报告章节。
+//
+// 核心实现:
+//   - CommentService 接口: Create / Reply / Edit / Resolve / Reopen / Delete / ListThreads / GetThread
+//   - 权限: 成员须对锚定资源具备 Read 权限，并对 ResourceComment 具备相应操作权限，
+//     统一经 PermissionPolicy.CheckAccess 判定；作者可编辑、删除自己的评论并解决自己发起的线程
+//   - @提及: 解析 @username，经 UserDirectory 解析为用户 ID，仅通知有权阅读评论的工作空间成员
+//   - 实时推送: 通过 ActivityPublisher 发布 ActivityRecord（不含评论正文），由 WebSocket 广播
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+// ---
+
+package collaboration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	userdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Comment activity action types, published as ActivityRecord.ActionType.
+const (
+	CommentActionCreated  = "comment_created"
+	CommentActionReplied  = "comment_replied"
+	CommentActionEdited   = "comment_edited"
+	CommentActionResolved = "comment_resolved"
+	CommentActionReopened = "comment_reopened"
+	CommentActionDeleted  = "comment_deleted"
+)
+
+// deletedCommentPlaceholder replaces the body of soft-deleted comments that
+// are still returned to keep thread structure intact.
+const deletedCommentPlaceholder = "[deleted]"
+
+// CreateCommentRequest is the input DTO for starting a comment thread.
+type CreateCommentRequest struct {
+	WorkspaceID string                     `json:"workspace_id"`
+	AuthorID    string                     `json:"author_id"`
+	Anchor      collabdomain.CommentAnchor `json:"anchor"`
+	Content     string                     `json:"content"`
+}
+
+func (r *CreateCommentRequest) Validate() error {
+	if strings.TrimSpace(r.WorkspaceID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "workspace_id is required")
+	}
+	if strings.TrimSpace(r.AuthorID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "author_id is required")
+	}
+	if err := r.Anchor.Validate(); err != nil {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, err.Error())
+	}
+	return nil
+}
+
+// ReplyCommentRequest is the input DTO for replying to a comment.
+type ReplyCommentRequest struct {
+	ParentID string `json:"parent_id"`
+	AuthorID string `json:"author_id"`
+	Content  string `json:"content"`
+}
+
+func (r *ReplyCommentRequest) Validate() error {
+	if strings.TrimSpace(r.ParentID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "parent_id is required")
+	}
+	if strings.TrimSpace(r.AuthorID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "author_id is required")
+	}
+	return nil
+}
+
+// EditCommentRequest is the input DTO for editing a comment.
+type EditCommentRequest struct {
+	CommentID string `json:"comment_id"`
+	EditorID  string `json:"editor_id"`
+	Content   string `json:"content"`
+}
+
+func (r *EditCommentRequest) Validate() error {
+	if strings.TrimSpace(r.CommentID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "comment_id is required")
+	}
+	if strings.TrimSpace(r.EditorID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "editor_id is required")
+	}
+	return nil
+}
+
+// ListCommentThreadsRequest is the input DTO for listing the threads on a
+// resource. ClaimNumber, when set, narrows claim anchors to one claim.
+type ListCommentThreadsRequest struct {
+	WorkspaceID    string                  `json:"workspace_id"`
+	UserID         string                  `json:"user_id"`
+	AnchorType     collabdomain.AnchorType `json:"anchor_type"`
+	ResourceID     string                  `json:"resource_id"`
+	ClaimNumber    int                     `json:"claim_number,omitempty"`
+	SectionID      string                  `json:"section_id,omitempty"`
+	UnresolvedOnly bool                    `json:"unresolved_only,omitempty"`
+}
+
+func (r *ListCommentThreadsRequest) Validate() error {
+	if strings.TrimSpace(r.WorkspaceID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "workspace_id is required")
+	}
+	if strings.TrimSpace(r.UserID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "user_id is required")
+	}
+	if strings.TrimSpace(r.ResourceID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "resource_id is required")
+	}
+	switch r.AnchorType {
+	case collabdomain.AnchorPatent, collabdomain.AnchorClaim, collabdomain.AnchorMolecule, collabdomain.AnchorReportSection:
+	default:
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, fmt.Sprintf("invalid anchor_type: %s", r.AnchorType))
+	}
+	if r.ClaimNumber < 0 {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "claim_number must be non-negative")
+	}
+	return nil
+}
+
+// UserDirectory resolves @mention handles to user IDs.
+type UserDirectory interface {
+	ResolveUsername(ctx context.Context, username string) (string, error)
+}
+
+// ActivityPublisher delivers workspace activity to real-time subscribers.
+type ActivityPublisher interface {
+	PublishActivity(ctx context.Context, record *collabdomain.ActivityRecord) error
+}
+
+// CommentService defines the application-level comment operations.
+type CommentService interface {
+	Create(ctx context.Context, req *CreateCommentRequest) (*collabdomain.Comment, error)
+	Reply(ctx context.Context, req *ReplyCommentRequest) (*collabdomain.Comment, error)
+	Edit(ctx context.Context, req *EditCommentRequest) (*collabdomain.Comment, error)
+	Resolve(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error)
+	Reopen(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error)
+	Delete(ctx context.Context, commentID, userID string) error
+	ListThreads(ctx context.Context, req *ListCommentThreadsRequest) ([]*collabdomain.CommentThread, error)
+	GetThread(ctx context.Context, threadID, userID string) (*collabdomain.CommentThread, error)
+}
+
+type commentServiceImpl struct {
+	commentRepo   collabdomain.CommentRepository
+	memberRepo    collabdomain.MemberRepository
+	policy        collabdomain.PermissionPolicy
+	users         UserDirectory
+	notifications collabdomain.NotificationRepository
+	activity      ActivityPublisher
+	logger        logging.Logger
+}
+
+// NewCommentService constructs a CommentService. users, notifications and
+// activity are optional: without users mentions are not resolved, without
+// notifications they are not delivered, and without activity nothing is
+// pushed in real time.
+func NewCommentService(
+	commentRepo collabdomain.CommentRepository,
+	memberRepo collabdomain.MemberRepository,
+	policy collabdomain.PermissionPolicy,
+	users UserDirectory,
+	notifications collabdomain.NotificationRepository,
+	activity ActivityPublisher,
+	logger logging.Logger,
+) CommentService {
+	if policy == nil {
+		policy = collabdomain.NewPermissionPolicy()
+	}
+	return &commentServiceImpl{
+		commentRepo:   commentRepo,
+		memberRepo:    memberRepo,
+		policy:        policy,
+		users:         users,
+		notifications: notifications,
+		activity:      activity,
+		logger:        logger,
+	}
+}
+
+func (s *commentServiceImpl) Create(ctx context.Context, req *CreateCommentRequest) (*collabdomain.Comment, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, req.WorkspaceID, req.AuthorID, req.Anchor, collabdomain.ActionCreate); err != nil {
+		return nil, err
+	}
+
+	c, err := collabdomain.NewComment(req.WorkspaceID, req.AuthorID, req.Anchor, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	c.SetMentions(s.resolveMentions(ctx, c))
+
+	if err := s.commentRepo.Save(ctx, c); err != nil {
+		s.logger.Error("failed to save comment", logging.Err(err), logging.String("workspace_id", c.WorkspaceID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to save comment")
+	}
+
+	s.notifyMentions(ctx, c, c.Mentions)
+	s.publish(ctx, c, req.AuthorID, CommentActionCreated)
+	return c, nil
+}
+
+func (s *commentServiceImpl) Reply(ctx context.Context, req *ReplyCommentRequest) (*collabdomain.Comment, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	parent, err := s.load(ctx, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, parent.WorkspaceID, req.AuthorID, parent.Anchor, collabdomain.ActionCreate); err != nil {
+		return nil, err
+	}
+
+	reply, err := collabdomain.NewReply(parent, req.AuthorID, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	reply.SetMentions(s.resolveMentions(ctx, reply))
+
+	if err := s.commentRepo.Save(ctx, reply); err != nil {
+		s.logger.Error("failed to save reply", logging.Err(err), logging.String("thread_id", reply.ThreadID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to save comment")
+	}
+
+	s.notifyMentions(ctx, reply, reply.Mentions)
+	s.publish(ctx, reply, req.AuthorID, CommentActionReplied)
+	return reply, nil
+}
+
+func (s *commentServiceImpl) Edit(ctx context.Context, req *EditCommentRequest) (*collabdomain.Comment, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	c, err := s.load(ctx, req.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, c.WorkspaceID, req.EditorID, c.Anchor, collabdomain.ActionRead); err != nil {
+		return nil, err
+	}
+	if err := c.Edit(req.EditorID, req.Content); err != nil {
+		return nil, err
+	}
+
+	previous := make(map[string]bool, len(c.Mentions))
+	for _, id := range c.Mentions {
+		previous[id] = true
+	}
+	c.SetMentions(s.resolveMentions(ctx, c))
+	var added []string
+	for _, id := range c.Mentions {
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+
+	if err := s.commentRepo.Save(ctx, c); err != nil {
+		s.logger.Error("failed to save comment", logging.Err(err), logging.String("comment_id", c.ID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to save comment")
+	}
+
+	s.notifyMentions(ctx, c, added)
+	s.publish(ctx, c, req.EditorID, CommentActionEdited)
+	return c, nil
+}
+
+func (s *commentServiceImpl) Resolve(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error) {
+	return s.setResolved(ctx, threadID, userID, true)
+}
+
+func (s *commentServiceImpl) Reopen(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error) {
+	return s.setResolved(ctx, threadID, userID, false)
+}
+
+// setResolved resolves or reopens the thread containing threadID. The thread
+// starter may always do so; anyone else needs update permission on comments.
+func (s *commentServiceImpl) setResolved(ctx context.Context, threadID, userID string, resolved bool) (*collabdomain.Comment, error) {
+	if strings.TrimSpace(threadID) == "" || strings.TrimSpace(userID) == "" {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "thread_id and user_id are required")
+	}
+
+	root, err := s.load(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	if !root.IsRoot() {
+		if root, err = s.load(ctx, root.ThreadID); err != nil {
+			return nil, err
+		}
+	}
+
+	action := collabdomain.ActionUpdate
+	if root.AuthorID == userID {
+		action = collabdomain.ActionRead
+	}
+	if _, err := s.authorize(ctx, root.WorkspaceID, userID, root.Anchor, action); err != nil {
+		return nil, err
+	}
+
+	activity := CommentActionResolved
+	if resolved {
+		err = root.Resolve(userID)
+	} else {
+		err = root.Reopen()
+		activity = CommentActionReopened
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Save(ctx, root); err != nil {
+		s.logger.Error("failed to save comment thread", logging.Err(err), logging.String("thread_id", root.ID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to save comment")
+	}
+
+	s.publish(ctx, root, userID, activity)
+	return root, nil
+}
+
+func (s *commentServiceImpl) Delete(ctx context.Context, commentID, userID string) error {
+	if strings.TrimSpace(commentID) == "" || strings.TrimSpace(userID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "comment_id and user_id are required")
+	}
+
+	c, err := s.load(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	action := collabdomain.ActionDelete
+	if c.AuthorID == userID {
+		action = collabdomain.ActionRead
+	}
+	if _, err := s.authorize(ctx, c.WorkspaceID, userID, c.Anchor, action); err != nil {
+		return err
+	}
+	if err := c.MarkDeleted(); err != nil {
+		return err
+	}
+
+	if err := s.commentRepo.Save(ctx, c); err != nil {
+		s.logger.Error("failed to delete comment", logging.Err(err), logging.String("comment_id", c.ID))
+		return pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to delete comment")
+	}
+
+	s.publish(ctx, c, userID, CommentActionDeleted)
+	return nil
+}
+
+func (s *commentServiceImpl) ListThreads(ctx context.Context, req *ListCommentThreadsRequest) ([]*collabdomain.CommentThread, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	anchor := collabdomain.CommentAnchor{Type: req.AnchorType, ResourceID: req.ResourceID}
+	if _, err := s.authorize(ctx, req.WorkspaceID, req.UserID, anchor, collabdomain.ActionRead); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindByAnchor(ctx, req.WorkspaceID, req.AnchorType, req.ResourceID, req.UnresolvedOnly)
+	if err != nil {
+		s.logger.Error("failed to list comments", logging.Err(err), logging.String("resource_id", req.ResourceID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to list comments")
+	}
+
+	threads := collabdomain.BuildThreads(comments)
+	result := make([]*collabdomain.CommentThread, 0, len(threads))
+	for _, t := range threads {
+		if req.ClaimNumber > 0 && t.Root.Anchor.ClaimNumber != req.ClaimNumber {
+			continue
+		}
+		if req.SectionID != "" && t.Root.Anchor.SectionID != req.SectionID {
+			continue
+		}
+		if t = redactThread(t); t != nil {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (s *commentServiceImpl) GetThread(ctx context.Context, threadID, userID string) (*collabdomain.CommentThread, error) {
+	if strings.TrimSpace(threadID) == "" || strings.TrimSpace(userID) == "" {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "thread_id and user_id are required")
+	}
+
+	comments, err := s.commentRepo.FindThread(ctx, threadID)
+	if err != nil {
+		s.logger.Error("failed to load comment thread", logging.Err(err), logging.String("thread_id", threadID))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, "failed to load comment thread")
+	}
+	threads := collabdomain.BuildThreads(comments)
+	if len(threads) == 0 {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("comment thread %s not found", threadID))
+	}
+	t := threads[0]
+	if _, err := s.authorize(ctx, t.Root.WorkspaceID, userID, t.Root.Anchor, collabdomain.ActionRead); err != nil {
+		return nil, err
+	}
+	if t = redactThread(t); t == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("comment thread %s not found", threadID))
+	}
+	return t, nil
+}
+
+// authorize checks that userID is a workspace member who can read the
+// anchored resource and perform action on comments.
+func (s *commentServiceImpl) authorize(
+	ctx context.Context,
+	workspaceID, userID string,
+	anchor collabdomain.CommentAnchor,
+	action collabdomain.Action,
+) (*collabdomain.MemberPermission, error) {
+	member, err := s.memberRepo.FindByWorkspaceAndUser(ctx, workspaceID, userID)
+	if err != nil || member == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeForbidden, "user is not a member of the workspace")
+	}
+	if ok, reason := s.policy.CheckAccess(member, anchor.ProtectedResource(), collabdomain.ActionRead); !ok {
+		s.logger.Warn("comment access denied",
+			logging.String("user_id", userID),
+			logging.String("resource", string(anchor.ProtectedResource())),
+			logging.String("reason", reason))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeForbidden, reason)
+	}
+	if ok, reason := s.policy.CheckAccess(member, collabdomain.ResourceComment, action); !ok {
+		s.logger.Warn("comment action denied",
+			logging.String("user_id", userID),
+			logging.String("action", string(action)),
+			logging.String("reason", reason))
+		return nil, pkgerrors.New(pkgerrors.ErrCodeForbidden, reason)
+	}
+	return member, nil
+}
+
+func (s *commentServiceImpl) load(ctx context.Context, id string) (*collabdomain.Comment, error) {
+	c, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil || c == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("comment %s not found", id))
+	}
+	if c.IsDeleted() {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("comment %s not found", id))
+	}
+	return c, nil
+}
+
+// resolveMentions maps the @handles in c to the IDs of workspace members who
+// may read comments on c's anchor. Unknown handles and the author are
+// skipped.
+func (s *commentServiceImpl) resolveMentions(ctx context.Context, c *collabdomain.Comment) []string {
+	if s.users == nil {
+		return nil
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, handle := range collabdomain.ExtractMentions(c.Content) {
+		userID, err := s.users.ResolveUsername(ctx, handle)
+		if err != nil || userID == "" || userID == c.AuthorID || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if _, err := s.authorize(ctx, c.WorkspaceID, userID, c.Anchor, collabdomain.ActionRead); err != nil {
+			continue
+		}
+		ids = append(ids, userID)
+	}
+	return ids
+}
+
+// notifyMentions stores a mention notification for each user. Failures are
+// logged; the comment itself has already been saved.
+func (s *commentServiceImpl) notifyMentions(ctx context.Context, c *collabdomain.Comment, userIDs []string) {
+	if s.notifications == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		if err := s.notifications.Save(ctx, collabdomain.NewMentionNotification(c, userID)); err != nil {
+			s.logger.Warn("failed to save mention notification",
+				logging.Err(err),
+				logging.String("comment_id", c.ID),
+				logging.String("user_id", userID))
+		}
+	}
+}
+
+// publish emits an activity record for c. The record carries identifiers
+// only; subscribers fetch the comment through the permission-checked API.
+func (s *commentServiceImpl) publish(ctx context.Context, c *collabdomain.Comment, actorID, actionType string) {
+	if s.activity == nil {
+		return
+	}
+	meta := map[string]string{
+		"comment_id": c.ID,
+		"thread_id":  c.ThreadID,
+	}
+	if c.Anchor.ClaimNumber > 0 {
+		meta["claim_number"] = fmt.Sprintf("%d", c.Anchor.ClaimNumber)
+	}
+	if c.Anchor.HasSpan() {
+		meta["span"] = fmt.Sprintf("%d-%d", c.Anchor.SpanStart, c.Anchor.SpanEnd)
+	}
+	if c.Anchor.SectionID != "" {
+		meta["section_id"] = c.Anchor.SectionID
+	}
+
+	record := &collabdomain.ActivityRecord{
+		ID:          uuid.New().String(),
+		WorkspaceID: c.WorkspaceID,
+		ActorID:     actorID,
+		ActionType:  actionType,
+		Description: strings.ReplaceAll(actionType, "_", " ") + " on " + strings.ReplaceAll(string(c.Anchor.Type), "_", " "),
+		TargetType:  string(c.Anchor.Type),
+		TargetID:    c.Anchor.ResourceID,
+		Metadata:    meta,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.activity.PublishActivity(ctx, record); err != nil {
+		s.logger.Warn("failed to publish comment activity", logging.Err(err), logging.String("comment_id", c.ID))
+	}
+}
+
+// redactThread hides the body of deleted comments. A deleted root is kept as
+// a placeholder while it still has live replies; otherwise the thread is
+// dropped and nil is returned.
+func redactThread(t *collabdomain.CommentThread) *collabdomain.CommentThread {
+	replies := make([]*collabdomain.Comment, 0, len(t.Replies))
+	for _, r := range t.Replies {
+		if !r.IsDeleted() {
+			replies = append(replies, r)
+		}
+	}
+	root := t.Root
+	if root.IsDeleted() {
+		if len(replies) == 0 {
+			return nil
+		}
+		redacted := *root
+		redacted.Content = deletedCommentPlaceholder
+		redacted.Mentions = nil
+		root = &redacted
+	}
+	return &collabdomain.CommentThread{Root: root, Replies: replies}
+}
+
+// userRepoDirectory resolves mentions against the user repository.
+type userRepoDirectory struct {
+	repo userdomain.UserRepository
+}
+
+// NewUserRepoDirectory adapts a UserRepository to UserDirectory.
+func NewUserRepoDirectory(repo userdomain.UserRepository) UserDirectory {
+	return &userRepoDirectory{repo: repo}
+}
+
+func (d *userRepoDirectory) ResolveUsername(ctx context.Context, username string) (string, error) {
+	u, err := d.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	if u == nil {
+		return "", pkgerrors.New(pkgerrors.ErrCodeNotFound, "user not found")
+	}
+	return u.ID.String(), nil
+}
+
+//Personal.AI order the ending
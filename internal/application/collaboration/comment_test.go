@@ -0,0 +1,371 @@
+package collaboration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// --- In-memory fakes for comment tests ---
+
+type memCommentRepo struct {
+	mu       sync.Mutex
+	comments map[string]*collabdomain.Comment
+	order    []string
+	saveErr  error
+}
+
+func newMemCommentRepo() *memCommentRepo {
+	return &memCommentRepo{comments: map[string]*collabdomain.Comment{}}
+}
+
+func (r *memCommentRepo) Save(ctx context.Context, c *collabdomain.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	if _, ok := r.comments[c.ID]; !ok {
+		r.order = append(r.order, c.ID)
+	}
+	cp := *c
+	r.comments[c.ID] = &cp
+	return nil
+}
+
+func (r *memCommentRepo) FindByID(ctx context.Context, id string) (*collabdomain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.comments[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (r *memCommentRepo) FindThread(ctx context.Context, threadID string) ([]*collabdomain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*collabdomain.Comment
+	for _, id := range r.order {
+		if c := r.comments[id]; c.ThreadID == threadID {
+			cp := *c
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+
+func (r *memCommentRepo) FindByAnchor(ctx context.Context, workspaceID string, anchorType collabdomain.AnchorType, resourceID string, unresolvedOnly bool) ([]*collabdomain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*collabdomain.Comment
+	for _, id := range r.order {
+		c := r.comments[id]
+		if c.WorkspaceID != workspaceID || c.Anchor.Type != anchorType || c.Anchor.ResourceID != resourceID {
+			continue
+		}
+		if unresolvedOnly && r.comments[c.ThreadID].IsResolved {
+			continue
+		}
+		cp := *c
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+type commentMemberRepo struct {
+	mockMemberRepo
+	members map[string]*collabdomain.MemberPermission
+}
+
+func (m *commentMemberRepo) FindByWorkspaceAndUser(ctx context.Context, workspaceID, userID string) (*collabdomain.MemberPermission, error) {
+	mp, ok := m.members[workspaceID+"/"+userID]
+	if !ok {
+		return nil, errors.New("not a member")
+	}
+	return mp, nil
+}
+
+func activeMember(workspaceID, userID string, role collabdomain.Role) *collabdomain.MemberPermission {
+	now := time.Now().UTC()
+	return &collabdomain.MemberPermission{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+		IsActive:    true,
+		AcceptedAt:  &now,
+	}
+}
+
+type mapUserDirectory map[string]string
+
+func (d mapUserDirectory) ResolveUsername(ctx context.Context, username string) (string, error) {
+	if id, ok := d[username]; ok {
+		return id, nil
+	}
+	return "", errors.New("unknown user")
+}
+
+type recordingNotifications struct {
+	saved []*collabdomain.Notification
+}
+
+func (r *recordingNotifications) Save(ctx context.Context, n *collabdomain.Notification) error {
+	r.saved = append(r.saved, n)
+	return nil
+}
+
+type recordingActivity struct {
+	records []*collabdomain.ActivityRecord
+}
+
+func (r *recordingActivity) PublishActivity(ctx context.Context, rec *collabdomain.ActivityRecord) error {
+	r.records = append(r.records, rec)
+	return nil
+}
+
+type commentFixture struct {
+	svc      CommentService
+	repo     *memCommentRepo
+	notifier *recordingNotifications
+	activity *recordingActivity
+}
+
+// newCommentFixture sets up workspace ws1 with an attorney (alice), an
+// analyst (bob), a viewer (carol) and a manager (dave). Eve is not a member.
+func newCommentFixture() *commentFixture {
+	members := &commentMemberRepo{members: map[string]*collabdomain.MemberPermission{}}
+	for _, m := range []*collabdomain.MemberPermission{
+		activeMember("ws1", "alice", collabdomain.RoleAttorney),
+		activeMember("ws1", "bob", collabdomain.RoleAnalyst),
+		activeMember("ws1", "carol", collabdomain.RoleViewer),
+		activeMember("ws1", "dave", collabdomain.RoleManager),
+	} {
+		members.members[m.WorkspaceID+"/"+m.UserID] = m
+	}
+	users := mapUserDirectory{"alice": "alice", "bob": "bob", "carol": "carol", "dave": "dave", "eve": "eve"}
+
+	f := &commentFixture{
+		repo:     newMemCommentRepo(),
+		notifier: &recordingNotifications{},
+		activity: &recordingActivity{},
+	}
+	f.svc = NewCommentService(f.repo, members, nil, users, f.notifier, f.activity, &mockWsLogger{})
+	return f
+}
+
+func claimChartAnchor() collabdomain.CommentAnchor {
+	return collabdomain.CommentAnchor{Type: collabdomain.AnchorClaim, ResourceID: "pat-1", ClaimNumber: 1, SpanStart: 0, SpanEnd: 24}
+}
+
+func assertErrCode(t *testing.T, err error, code pkgerrors.ErrorCode) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected error with code %v, got nil", code)
+	}
+	if !pkgerrors.IsCode(err, code) {
+		t.Fatalf("expected error code %v, got %v", code, err)
+	}
+}
+
+// --- Tests ---
+
+func TestCommentService_CreateWithMentions(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	c, err := f.svc.Create(ctx, &CreateCommentRequest{
+		WorkspaceID: "ws1",
+		AuthorID:    "alice",
+		Anchor:      claimChartAnchor(),
+		Content:     "@bob does D1 disclose this span? cc @eve @alice @nobody",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(c.Mentions) != 1 || c.Mentions[0] != "bob" {
+		t.Fatalf("mentions = %v, want [bob]", c.Mentions)
+	}
+	if len(f.notifier.saved) != 1 || f.notifier.saved[0].UserID != "bob" {
+		t.Fatalf("notifications = %+v", f.notifier.saved)
+	}
+	if n := f.notifier.saved[0]; n.Type != collabdomain.NotificationTypeCommentMention || n.SenderID != "alice" {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+	if len(f.activity.records) != 1 {
+		t.Fatalf("activity = %d records", len(f.activity.records))
+	}
+	rec := f.activity.records[0]
+	if rec.ActionType != CommentActionCreated || rec.TargetID != "pat-1" || rec.Metadata["claim_number"] != "1" || rec.Metadata["span"] != "0-24" {
+		t.Fatalf("unexpected activity %+v", rec)
+	}
+}
+
+func TestCommentService_CreatePermission(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	_, err := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "carol", Anchor: claimChartAnchor(), Content: "hi"})
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+
+	_, err = f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "eve", Anchor: claimChartAnchor(), Content: "hi"})
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+
+	_, err = f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "alice", Anchor: collabdomain.CommentAnchor{Type: collabdomain.AnchorClaim, ResourceID: "pat-1"}, Content: "hi"})
+	assertErrCode(t, err, pkgerrors.ErrCodeValidation)
+
+	_, err = f.svc.Create(ctx, nil)
+	assertErrCode(t, err, pkgerrors.ErrCodeValidation)
+}
+
+func TestCommentService_ReplyAndThread(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	root, _ := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "alice", Anchor: claimChartAnchor(), Content: "root"})
+	reply, err := f.svc.Reply(ctx, &ReplyCommentRequest{ParentID: root.ID, AuthorID: "bob", Content: "@alice yes, paragraph 12"})
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if reply.ThreadID != root.ID || reply.Anchor != root.Anchor {
+		t.Fatalf("reply not in thread: %+v", reply)
+	}
+
+	thread, err := f.svc.GetThread(ctx, root.ID, "carol")
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if thread.Root.ID != root.ID || len(thread.Replies) != 1 {
+		t.Fatalf("thread = %+v", thread)
+	}
+
+	_, err = f.svc.Reply(ctx, &ReplyCommentRequest{ParentID: root.ID, AuthorID: "carol", Content: "me too"})
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+
+	_, err = f.svc.GetThread(ctx, root.ID, "eve")
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+}
+
+func TestCommentService_ResolveReopen(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	root, _ := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "bob", Anchor: claimChartAnchor(), Content: "root"})
+	reply, _ := f.svc.Reply(ctx, &ReplyCommentRequest{ParentID: root.ID, AuthorID: "alice", Content: "reply"})
+
+	// Viewers can neither resolve nor reopen someone else's thread.
+	_, err := f.svc.Resolve(ctx, root.ID, "carol")
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+
+	// Resolving via a reply resolves the root.
+	resolved, err := f.svc.Resolve(ctx, reply.ID, "alice")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if !resolved.IsResolved || resolved.ID != root.ID || resolved.ResolvedBy != "alice" {
+		t.Fatalf("resolved = %+v", resolved)
+	}
+
+	open, _ := f.svc.ListThreads(ctx, &ListCommentThreadsRequest{WorkspaceID: "ws1", UserID: "bob", AnchorType: collabdomain.AnchorClaim, ResourceID: "pat-1", UnresolvedOnly: true})
+	if len(open) != 0 {
+		t.Fatalf("expected no open threads, got %d", len(open))
+	}
+
+	// The analyst started the thread, so may reopen it without update permission.
+	reopened, err := f.svc.Reopen(ctx, root.ID, "bob")
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if reopened.IsResolved {
+		t.Fatal("thread still resolved")
+	}
+	_, err = f.svc.Reopen(ctx, root.ID, "bob")
+	assertErrCode(t, err, pkgerrors.ErrCodeConflict)
+
+	last := f.activity.records[len(f.activity.records)-1]
+	if last.ActionType != CommentActionReopened {
+		t.Fatalf("last activity = %s", last.ActionType)
+	}
+}
+
+func TestCommentService_EditNotifiesNewMentionsOnly(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	c, _ := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "alice", Anchor: claimChartAnchor(), Content: "@bob check"})
+	f.notifier.saved = nil
+
+	edited, err := f.svc.Edit(ctx, &EditCommentRequest{CommentID: c.ID, EditorID: "alice", Content: "@bob @dave check"})
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	if edited.EditedAt == nil || len(edited.Mentions) != 2 {
+		t.Fatalf("edited = %+v", edited)
+	}
+	if len(f.notifier.saved) != 1 || f.notifier.saved[0].UserID != "dave" {
+		t.Fatalf("notifications = %+v", f.notifier.saved)
+	}
+
+	_, err = f.svc.Edit(ctx, &EditCommentRequest{CommentID: c.ID, EditorID: "dave", Content: "mine now"})
+	assertErrCode(t, err, pkgerrors.ErrCodeForbidden)
+}
+
+func TestCommentService_DeleteAndList(t *testing.T) {
+	f := newCommentFixture()
+	ctx := context.Background()
+
+	a, _ := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "alice", Anchor: claimChartAnchor(), Content: "claim 1"})
+	_, _ = f.svc.Reply(ctx, &ReplyCommentRequest{ParentID: a.ID, AuthorID: "bob", Content: "reply"})
+	claim2 := claimChartAnchor()
+	claim2.ClaimNumber = 2
+	b, _ := f.svc.Create(ctx, &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "bob", Anchor: claim2, Content: "claim 2"})
+
+	// Analysts may delete their own comments but not other people's.
+	assertErrCode(t, f.svc.Delete(ctx, a.ID, "bob"), pkgerrors.ErrCodeForbidden)
+	if err := f.svc.Delete(ctx, b.ID, "bob"); err != nil {
+		t.Fatalf("Delete own: %v", err)
+	}
+	// Managers may delete anyone's comment.
+	if err := f.svc.Delete(ctx, a.ID, "dave"); err != nil {
+		t.Fatalf("Delete as manager: %v", err)
+	}
+
+	threads, err := f.svc.ListThreads(ctx, &ListCommentThreadsRequest{WorkspaceID: "ws1", UserID: "carol", AnchorType: collabdomain.AnchorClaim, ResourceID: "pat-1"})
+	if err != nil {
+		t.Fatalf("ListThreads: %v", err)
+	}
+	if len(threads) != 1 {
+		t.Fatalf("threads = %d, want 1", len(threads))
+	}
+	if threads[0].Root.Content != deletedCommentPlaceholder || len(threads[0].Replies) != 1 {
+		t.Fatalf("thread = %+v", threads[0])
+	}
+
+	byClaim, _ := f.svc.ListThreads(ctx, &ListCommentThreadsRequest{WorkspaceID: "ws1", UserID: "carol", AnchorType: collabdomain.AnchorClaim, ResourceID: "pat-1", ClaimNumber: 2})
+	if len(byClaim) != 0 {
+		t.Fatalf("claim 2 threads = %d, want 0", len(byClaim))
+	}
+
+	_, err = f.svc.Reply(ctx, &ReplyCommentRequest{ParentID: b.ID, AuthorID: "alice", Content: "gone"})
+	assertErrCode(t, err, pkgerrors.ErrCodeNotFound)
+}
+
+func TestCommentService_SaveFailure(t *testing.T) {
+	f := newCommentFixture()
+	f.repo.saveErr = errors.New("db down")
+
+	_, err := f.svc.Create(context.Background(), &CreateCommentRequest{WorkspaceID: "ws1", AuthorID: "alice", Anchor: claimChartAnchor(), Content: "@bob hi"})
+	assertErrCode(t, err, pkgerrors.ErrCodeInternal)
+	if len(f.notifier.saved) != 0 || len(f.activity.records) != 0 {
+		t.Fatal("side effects emitted for unsaved comment")
+	}
+}
+
+//Personal.AI order the ending
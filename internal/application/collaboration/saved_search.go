@@ -0,0 +1,552 @@
+// ---
+// internal/application/collaboration/saved_search.go
+//
+// 功能定位: 保存检索应用服务，管理专利文本检索、结构/相似度检索与知识图谱检索的命名保存、
+//   工作空间共享与定时重跑；重跑结果与上次结果比对，仅推送新增命中。
+//
+// 核心实现:
+//   - SavedSearchService 接口: Create / Get / List / Update / Delete / Share / Unshare / Run / RunDue
+//   - SearchExecutor: 按 SavedSearchKind 注册的检索执行端口，查询体原样保存，由执行器解析
+//   - HitNotifier: 新增命中通知端口，Worker 发布到通知 topic，API 侧经 WebSocket 广播
+//   - 权限: 仅所有者可修改/删除/共享；共享检索对工作空间成员可见，
+//     须通过 PermissionPolicy.CheckAccess 对 ResourcePatent 的 Read 校验
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+// ---
+
+package collaboration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// DefaultDueSearchBatch is the number of due searches RunDue processes when
+// the caller passes a non-positive limit.
+const DefaultDueSearchBatch = 50
+
+// SearchHit is a single result of a saved search run.
+type SearchHit struct {
+	ID    string  `json:"id"`
+	Title string  `json:"title,omitempty"`
+	Score float64 `json:"score,omitempty"`
+}
+
+// SearchExecutor runs the stored query of one saved search kind.
+type SearchExecutor interface {
+	Execute(ctx context.Context, query json.RawMessage) ([]SearchHit, error)
+}
+
+// SearchExecutorFunc adapts a function to SearchExecutor.
+type SearchExecutorFunc func(ctx context.Context, query json.RawMessage) ([]SearchHit, error)
+
+// Execute calls f.
+func (f SearchExecutorFunc) Execute(ctx context.Context, query json.RawMessage) ([]SearchHit, error) {
+	return f(ctx, query)
+}
+
+// SavedSearchHitsEventType is the event type used when a SavedSearchHitsEvent
+// is published on the notification topic.
+const SavedSearchHitsEventType = "saved_search.new_hits"
+
+// SavedSearchHitsEvent describes the new hits found by one run.
+type SavedSearchHitsEvent struct {
+	SearchID    string                       `json:"search_id"`
+	SearchName  string                       `json:"search_name"`
+	Kind        collabdomain.SavedSearchKind `json:"kind"`
+	OwnerID     string                       `json:"owner_id"`
+	WorkspaceID string                       `json:"workspace_id,omitempty"`
+	Recipients  []string                     `json:"recipients"`
+	NewHits     []SearchHit                  `json:"new_hits"`
+	TotalHits   int                          `json:"total_hits"`
+	RunAt       time.Time                    `json:"run_at"`
+}
+
+// HitNotifier delivers new-hit events.
+type HitNotifier interface {
+	NotifyNewHits(ctx context.Context, event *SavedSearchHitsEvent) error
+}
+
+// MultiHitNotifier fans an event out to several notifiers, returning the
+// first error after trying all of them.
+type MultiHitNotifier []HitNotifier
+
+// NotifyNewHits implements HitNotifier.
+func (m MultiHitNotifier) NotifyNewHits(ctx context.Context, event *SavedSearchHitsEvent) error {
+	var firstErr error
+	for _, n := range m {
+		if n == nil {
+			continue
+		}
+		if err := n.NotifyNewHits(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CreateSavedSearchRequest is the input DTO for saving a search. A non-empty
+// WorkspaceID shares the search with that workspace immediately.
+type CreateSavedSearchRequest struct {
+	OwnerID     string                       `json:"owner_id"`
+	WorkspaceID string                       `json:"workspace_id,omitempty"`
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	Kind        collabdomain.SavedSearchKind `json:"kind"`
+	Query       json.RawMessage              `json:"query"`
+	Schedule    collabdomain.SearchSchedule  `json:"schedule"`
+}
+
+func (r *CreateSavedSearchRequest) Validate() error {
+	if strings.TrimSpace(r.OwnerID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "owner_id is required")
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "name is required")
+	}
+	if !r.Kind.IsValid() {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, fmt.Sprintf("invalid kind: %s", r.Kind))
+	}
+	return nil
+}
+
+// UpdateSavedSearchRequest is the input DTO for changing a saved search. A
+// nil Query keeps the current query; an empty Schedule keeps the current
+// schedule.
+type UpdateSavedSearchRequest struct {
+	SearchID    string                      `json:"search_id"`
+	UserID      string                      `json:"user_id"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description,omitempty"`
+	Query       json.RawMessage             `json:"query,omitempty"`
+	Schedule    collabdomain.SearchSchedule `json:"schedule,omitempty"`
+}
+
+func (r *UpdateSavedSearchRequest) Validate() error {
+	if strings.TrimSpace(r.SearchID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "search_id is required")
+	}
+	if strings.TrimSpace(r.UserID) == "" {
+		return pkgerrors.New(pkgerrors.ErrCodeValidation, "user_id is required")
+	}
+	return nil
+}
+
+// SavedSearchRunResult is the outcome of running a saved search.
+type SavedSearchRunResult struct {
+	Search  *collabdomain.SavedSearch `json:"search"`
+	Hits    []SearchHit               `json:"hits"`
+	NewHits []SearchHit               `json:"new_hits"`
+}
+
+// SavedSearchService defines the application service for saved searches.
+type SavedSearchService interface {
+	Create(ctx context.Context, req *CreateSavedSearchRequest) (*collabdomain.SavedSearch, error)
+	Get(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error)
+	// List returns the user's own searches, or with workspaceID set, the
+	// searches shared with that workspace.
+	List(ctx context.Context, userID, workspaceID string) ([]*collabdomain.SavedSearch, error)
+	Update(ctx context.Context, req *UpdateSavedSearchRequest) (*collabdomain.SavedSearch, error)
+	Delete(ctx context.Context, searchID, userID string) error
+	Share(ctx context.Context, searchID, workspaceID, userID string) (*collabdomain.SavedSearch, error)
+	Unshare(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error)
+	// Run executes a search now on behalf of userID.
+	Run(ctx context.Context, searchID, userID string) (*SavedSearchRunResult, error)
+	// RunDue executes up to limit scheduled searches that are due at now and
+	// returns how many were run.
+	RunDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type savedSearchServiceImpl struct {
+	repo       collabdomain.SavedSearchRepository
+	memberRepo collabdomain.MemberRepository
+	policy     collabdomain.PermissionPolicy
+	executors  map[collabdomain.SavedSearchKind]SearchExecutor
+	notifier   HitNotifier
+	logger     logging.Logger
+}
+
+// NewSavedSearchService constructs a SavedSearchService. Only kinds with an
+// executor can be saved. Without memberRepo sharing is unavailable and only
+// owners are notified. notifier may be nil.
+func NewSavedSearchService(
+	repo collabdomain.SavedSearchRepository,
+	memberRepo collabdomain.MemberRepository,
+	policy collabdomain.PermissionPolicy,
+	executors map[collabdomain.SavedSearchKind]SearchExecutor,
+	notifier HitNotifier,
+	logger logging.Logger,
+) SavedSearchService {
+	if policy == nil {
+		policy = collabdomain.NewPermissionPolicy()
+	}
+	return &savedSearchServiceImpl{
+		repo:       repo,
+		memberRepo: memberRepo,
+		policy:     policy,
+		executors:  executors,
+		notifier:   notifier,
+		logger:     logger,
+	}
+}
+
+func (s *savedSearchServiceImpl) Create(ctx context.Context, req *CreateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if exec, ok := s.executors[req.Kind]; !ok || exec == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, fmt.Sprintf("%s searches are not supported", req.Kind))
+	}
+
+	search, err := collabdomain.NewSavedSearch(req.OwnerID, req.Name, req.Kind, req.Query, req.Schedule)
+	if err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, err.Error())
+	}
+	search.Description = req.Description
+	if req.WorkspaceID != "" {
+		if err := s.authorizeWorkspace(ctx, req.WorkspaceID, req.OwnerID); err != nil {
+			return nil, err
+		}
+		_ = search.ShareWith(req.WorkspaceID)
+	}
+
+	// Establish the baseline so the first scheduled run only reports hits
+	// that appeared after the search was saved. A failure here is not fatal.
+	if hits, err := s.execute(ctx, search); err == nil {
+		search.RecordRun(time.Now().UTC(), hitIDs(hits))
+	} else {
+		s.logger.Warn("saved search baseline run failed",
+			logging.Err(err),
+			logging.String("search_id", search.ID))
+	}
+
+	if err := s.repo.Save(ctx, search); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to save search: %v", err))
+	}
+	s.logger.Info("saved search created",
+		logging.String("search_id", search.ID),
+		logging.String("owner_id", search.OwnerID),
+		logging.String("kind", string(search.Kind)))
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) Get(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error) {
+	search, err := s.load(ctx, searchID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeRead(ctx, search, userID); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) List(ctx context.Context, userID, workspaceID string) ([]*collabdomain.SavedSearch, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "user_id is required")
+	}
+	var (
+		searches []*collabdomain.SavedSearch
+		err      error
+	)
+	if workspaceID == "" {
+		searches, err = s.repo.FindByOwner(ctx, userID)
+	} else {
+		if err := s.authorizeWorkspace(ctx, workspaceID, userID); err != nil {
+			return nil, err
+		}
+		searches, err = s.repo.FindByWorkspace(ctx, workspaceID)
+	}
+	if err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to list saved searches: %v", err))
+	}
+	return searches, nil
+}
+
+func (s *savedSearchServiceImpl) Update(ctx context.Context, req *UpdateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+	if req == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "request must not be nil")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	search, err := s.loadOwned(ctx, req.SearchID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := req.Query
+	if query == nil {
+		query = search.Query
+	}
+	schedule := req.Schedule
+	if schedule == "" {
+		schedule = search.Schedule
+	}
+	if err := search.Update(req.Name, req.Description, search.Kind, query, schedule); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, err.Error())
+	}
+	if err := s.repo.Save(ctx, search); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to save search: %v", err))
+	}
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) Delete(ctx context.Context, searchID, userID string) error {
+	if _, err := s.loadOwned(ctx, searchID, userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, searchID); err != nil {
+		return pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to delete search: %v", err))
+	}
+	return nil
+}
+
+func (s *savedSearchServiceImpl) Share(ctx context.Context, searchID, workspaceID, userID string) (*collabdomain.SavedSearch, error) {
+	if strings.TrimSpace(workspaceID) == "" {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "workspace_id is required")
+	}
+	search, err := s.loadOwned(ctx, searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeWorkspace(ctx, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	_ = search.ShareWith(workspaceID)
+	if err := s.repo.Save(ctx, search); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to save search: %v", err))
+	}
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) Unshare(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error) {
+	search, err := s.loadOwned(ctx, searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	search.Unshare()
+	if err := s.repo.Save(ctx, search); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to save search: %v", err))
+	}
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) Run(ctx context.Context, searchID, userID string) (*SavedSearchRunResult, error) {
+	search, err := s.Get(ctx, searchID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, search, userID, time.Now().UTC())
+}
+
+func (s *savedSearchServiceImpl) RunDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		limit = DefaultDueSearchBatch
+	}
+	due, err := s.repo.FindDue(ctx, now, limit)
+	if err != nil {
+		return 0, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to load due searches: %v", err))
+	}
+
+	ran := 0
+	for _, search := range due {
+		if err := ctx.Err(); err != nil {
+			return ran, err
+		}
+		if !search.IsDue(now) {
+			continue
+		}
+		if _, err := s.run(ctx, search, "", now); err != nil {
+			s.logger.Warn("scheduled saved search failed",
+				logging.Err(err),
+				logging.String("search_id", search.ID))
+		}
+		ran++
+	}
+	return ran, nil
+}
+
+// run executes search, records the outcome at now and notifies new hits.
+// actorID is the user who triggered the run, or empty for scheduled runs; the
+// actor is not notified of hits they are about to see in the response.
+func (s *savedSearchServiceImpl) run(ctx context.Context, search *collabdomain.SavedSearch, actorID string, now time.Time) (*SavedSearchRunResult, error) {
+	hits, execErr := s.execute(ctx, search)
+	if execErr != nil {
+		search.RecordFailure(now, execErr)
+		if err := s.repo.Save(ctx, search); err != nil {
+			s.logger.Warn("failed to record saved search failure", logging.Err(err), logging.String("search_id", search.ID))
+		}
+		return nil, execErr
+	}
+
+	fresh := search.RecordRun(now, hitIDs(hits))
+	if err := s.repo.Save(ctx, search); err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeInternal, fmt.Sprintf("failed to save search: %v", err))
+	}
+
+	newHits := selectHits(hits, fresh)
+	if len(newHits) > 0 {
+		s.notify(ctx, search, newHits, len(hits), actorID, now)
+	}
+	return &SavedSearchRunResult{Search: search, Hits: hits, NewHits: newHits}, nil
+}
+
+func (s *savedSearchServiceImpl) execute(ctx context.Context, search *collabdomain.SavedSearch) ([]SearchHit, error) {
+	exec, ok := s.executors[search.Kind]
+	if !ok || exec == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotImplemented, fmt.Sprintf("no executor for %s searches", search.Kind))
+	}
+	hits, err := exec.Execute(ctx, search.Query)
+	if err != nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeExternalService, fmt.Sprintf("%s search failed: %v", search.Kind, err))
+	}
+	return hits, nil
+}
+
+func (s *savedSearchServiceImpl) notify(ctx context.Context, search *collabdomain.SavedSearch, newHits []SearchHit, total int, actorID string, now time.Time) {
+	if s.notifier == nil {
+		return
+	}
+	recipients := s.recipients(ctx, search, actorID)
+	if len(recipients) == 0 {
+		return
+	}
+	event := &SavedSearchHitsEvent{
+		SearchID:    search.ID,
+		SearchName:  search.Name,
+		Kind:        search.Kind,
+		OwnerID:     search.OwnerID,
+		WorkspaceID: search.WorkspaceID,
+		Recipients:  recipients,
+		NewHits:     newHits,
+		TotalHits:   total,
+		RunAt:       now,
+	}
+	if err := s.notifier.NotifyNewHits(ctx, event); err != nil {
+		s.logger.Warn("failed to notify saved search hits",
+			logging.Err(err),
+			logging.String("search_id", search.ID))
+	}
+}
+
+// recipients returns the owner plus, for shared searches, every active
+// workspace member allowed to read results, minus the actor.
+func (s *savedSearchServiceImpl) recipients(ctx context.Context, search *collabdomain.SavedSearch, actorID string) []string {
+	seen := map[string]bool{actorID: true}
+	var out []string
+	add := func(userID string) {
+		if userID != "" && !seen[userID] {
+			seen[userID] = true
+			out = append(out, userID)
+		}
+	}
+	add(search.OwnerID)
+
+	if search.IsShared() && s.memberRepo != nil {
+		members, err := s.memberRepo.FindByWorkspaceID(ctx, search.WorkspaceID)
+		if err != nil {
+			s.logger.Warn("failed to load workspace members for saved search",
+				logging.Err(err),
+				logging.String("workspace_id", search.WorkspaceID))
+			return out
+		}
+		for _, m := range members {
+			if ok, _ := s.policy.CheckAccess(m, collabdomain.ResourcePatent, collabdomain.ActionRead); ok {
+				add(m.UserID)
+			}
+		}
+	}
+	return out
+}
+
+func (s *savedSearchServiceImpl) load(ctx context.Context, id string) (*collabdomain.SavedSearch, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeValidation, "search_id is required")
+	}
+	search, err := s.repo.FindByID(ctx, id)
+	if err != nil || search == nil {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("saved search %s not found", id))
+	}
+	return search, nil
+}
+
+func (s *savedSearchServiceImpl) loadOwned(ctx context.Context, id, userID string) (*collabdomain.SavedSearch, error) {
+	search, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if search.OwnerID != userID {
+		if s.authorizeRead(ctx, search, userID) != nil {
+			return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("saved search %s not found", id))
+		}
+		return nil, pkgerrors.New(pkgerrors.ErrCodeForbidden, "only the owner can modify a saved search")
+	}
+	return search, nil
+}
+
+// authorizeRead allows the owner, and workspace members who can read patents
+// when the search is shared. Searches the user cannot see are reported as
+// not found.
+func (s *savedSearchServiceImpl) authorizeRead(ctx context.Context, search *collabdomain.SavedSearch, userID string) error {
+	if search.OwnerID == userID {
+		return nil
+	}
+	if search.IsShared() && s.authorizeWorkspace(ctx, search.WorkspaceID, userID) == nil {
+		return nil
+	}
+	return pkgerrors.New(pkgerrors.ErrCodeNotFound, fmt.Sprintf("saved search %s not found", search.ID))
+}
+
+func (s *savedSearchServiceImpl) authorizeWorkspace(ctx context.Context, workspaceID, userID string) error {
+	if s.memberRepo == nil {
+		return pkgerrors.New(pkgerrors.ErrCodeForbidden, "workspace membership cannot be verified")
+	}
+	member, err := s.memberRepo.FindByWorkspaceAndUser(ctx, workspaceID, userID)
+	if err != nil || member == nil {
+		return pkgerrors.New(pkgerrors.ErrCodeForbidden, "user is not a member of the workspace")
+	}
+	if ok, reason := s.policy.CheckAccess(member, collabdomain.ResourcePatent, collabdomain.ActionRead); !ok {
+		return pkgerrors.New(pkgerrors.ErrCodeForbidden, reason)
+	}
+	return nil
+}
+
+func hitIDs(hits []SearchHit) []string {
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+// selectHits returns the hits whose IDs are in ids, preserving hit order.
+func selectHits(hits []SearchHit, ids []string) []SearchHit {
+	if len(ids) == 0 {
+		return nil
+	}
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	out := make([]SearchHit, 0, len(ids))
+	for _, h := range hits {
+		if want[h.ID] {
+			out = append(out, h)
+			delete(want, h.ID)
+		}
+	}
+	return out
+}
+
+//Personal.AI order the ending
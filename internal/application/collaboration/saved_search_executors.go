@@ -0,0 +1,165 @@
+// ---
+// internal/application/collaboration/saved_search_executors.go
+//
+// 功能定位: 保存检索执行器适配，将专利高级检索、结构相似度检索与知识图谱实体检索
+//   适配为 SearchExecutor，查询体按各检索接口的请求格式保存。
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+// ---
+
+package collaboration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/patent"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/patent_mining"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/query"
+)
+
+// Saved queries are re-run without user interaction, so every executor caps
+// the result window it diffs against.
+const savedSearchMaxHits = 200
+
+// patentTextQuery is the stored form of a patent text search. It mirrors the
+// body of POST /api/v1/patents/search/advanced.
+type patentTextQuery struct {
+	Title          string   `json:"title,omitempty"`
+	Abstract       string   `json:"abstract,omitempty"`
+	Applicant      string   `json:"applicant,omitempty"`
+	Inventor       string   `json:"inventor,omitempty"`
+	IPCCode        string   `json:"ipc_code,omitempty"`
+	Jurisdiction   string   `json:"jurisdiction,omitempty"`
+	FilingDateFrom string   `json:"filing_date_from,omitempty"`
+	FilingDateTo   string   `json:"filing_date_to,omitempty"`
+	Keywords       []string `json:"keywords,omitempty"`
+	PageSize       int      `json:"page_size,omitempty"`
+}
+
+// NewPatentTextExecutor runs saved patent text queries through the patent
+// service's advanced search.
+func NewPatentTextExecutor(svc patent.Service) SearchExecutor {
+	return SearchExecutorFunc(func(ctx context.Context, raw json.RawMessage) ([]SearchHit, error) {
+		var q patentTextQuery
+		if err := json.Unmarshal(raw, &q); err != nil {
+			return nil, fmt.Errorf("decode patent query: %w", err)
+		}
+		if q.PageSize <= 0 || q.PageSize > savedSearchMaxHits {
+			q.PageSize = savedSearchMaxHits
+		}
+		res, err := svc.AdvancedSearch(ctx, &patent.AdvancedSearchInput{
+			Title:          q.Title,
+			Abstract:       q.Abstract,
+			Applicant:      q.Applicant,
+			Inventor:       q.Inventor,
+			IPCCode:        q.IPCCode,
+			Jurisdiction:   q.Jurisdiction,
+			FilingDateFrom: q.FilingDateFrom,
+			FilingDateTo:   q.FilingDateTo,
+			Keywords:       q.Keywords,
+			Page:           1,
+			PageSize:       q.PageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hits := make([]SearchHit, 0, len(res.Patents))
+		for _, p := range res.Patents {
+			title := p.Title
+			if p.PublicationNo != "" {
+				title = p.PublicationNo + " " + title
+			}
+			hits = append(hits, SearchHit{ID: p.ID, Title: title})
+		}
+		return hits, nil
+	})
+}
+
+// NewStructureExecutor runs saved structure similarity queries. The stored
+// query is a patent_mining.SearchByStructureRequest.
+func NewStructureExecutor(svc patent_mining.SimilaritySearchService) SearchExecutor {
+	return SearchExecutorFunc(func(ctx context.Context, raw json.RawMessage) ([]SearchHit, error) {
+		var req patent_mining.SearchByStructureRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return nil, fmt.Errorf("decode structure query: %w", err)
+		}
+		if req.MaxResults <= 0 || req.MaxResults > savedSearchMaxHits {
+			req.MaxResults = savedSearchMaxHits
+		}
+		res, err := svc.SearchByStructure(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		hits := make([]SearchHit, 0, len(res.Hits))
+		for _, h := range res.Hits {
+			title := h.Name
+			if title == "" {
+				title = h.PatentNum
+			}
+			if title == "" {
+				title = h.SMILES
+			}
+			hits = append(hits, SearchHit{ID: h.ID, Title: title, Score: h.Score})
+		}
+		return hits, nil
+	})
+}
+
+// kgQuery is the stored form of a knowledge-graph entity search.
+type kgQuery struct {
+	EntityType query.EntityType `json:"entity_type"`
+	Filters    map[string]struct {
+		Operator query.FilterOperator `json:"operator"`
+		Value    interface{}          `json:"value"`
+	} `json:"filters,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	SortBy    string `json:"sort_by,omitempty"`
+	SortOrder string `json:"sort_order,omitempty"`
+}
+
+// NewKnowledgeGraphExecutor runs saved knowledge-graph entity queries.
+func NewKnowledgeGraphExecutor(svc query.KGSearchService) SearchExecutor {
+	return SearchExecutorFunc(func(ctx context.Context, raw json.RawMessage) ([]SearchHit, error) {
+		var q kgQuery
+		if err := json.Unmarshal(raw, &q); err != nil {
+			return nil, fmt.Errorf("decode knowledge graph query: %w", err)
+		}
+		req := &query.EntitySearchRequest{
+			EntityType: q.EntityType,
+			Limit:      q.Limit,
+			SortBy:     q.SortBy,
+			SortOrder:  q.SortOrder,
+		}
+		if req.Limit <= 0 || req.Limit > savedSearchMaxHits {
+			req.Limit = savedSearchMaxHits
+		}
+		if len(q.Filters) > 0 {
+			req.Filters = make(map[string]query.FilterCondition, len(q.Filters))
+			for k, f := range q.Filters {
+				req.Filters[k] = query.FilterCondition{Operator: f.Operator, Value: f.Value}
+			}
+		}
+		res, err := svc.SearchEntities(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		hits := make([]SearchHit, 0, len(res.Entities))
+		for _, e := range res.Entities {
+			hits = append(hits, SearchHit{ID: e.Node.ID, Title: kgNodeTitle(e.Node)})
+		}
+		return hits, nil
+	})
+}
+
+func kgNodeTitle(n query.GraphNode) string {
+	for _, key := range []string{"name", "title", "patent_number"} {
+		if v, ok := n.Properties[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return string(n.Type)
+}
+
+//Personal.AI order the ending
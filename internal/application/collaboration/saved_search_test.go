@@ -0,0 +1,371 @@
+package collaboration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type memSavedSearchRepo struct {
+	mu       sync.Mutex
+	searches map[string]*collabdomain.SavedSearch
+}
+
+func newMemSavedSearchRepo() *memSavedSearchRepo {
+	return &memSavedSearchRepo{searches: map[string]*collabdomain.SavedSearch{}}
+}
+
+func (r *memSavedSearchRepo) Save(ctx context.Context, s *collabdomain.SavedSearch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *s
+	r.searches[s.ID] = &cp
+	return nil
+}
+
+func (r *memSavedSearchRepo) FindByID(ctx context.Context, id string) (*collabdomain.SavedSearch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.searches[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *memSavedSearchRepo) filter(keep func(*collabdomain.SavedSearch) bool) []*collabdomain.SavedSearch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*collabdomain.SavedSearch
+	for _, s := range r.searches {
+		if keep(s) {
+			cp := *s
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (r *memSavedSearchRepo) FindByOwner(ctx context.Context, ownerID string) ([]*collabdomain.SavedSearch, error) {
+	return r.filter(func(s *collabdomain.SavedSearch) bool { return s.OwnerID == ownerID }), nil
+}
+
+func (r *memSavedSearchRepo) FindByWorkspace(ctx context.Context, workspaceID string) ([]*collabdomain.SavedSearch, error) {
+	return r.filter(func(s *collabdomain.SavedSearch) bool { return s.WorkspaceID == workspaceID }), nil
+}
+
+func (r *memSavedSearchRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]*collabdomain.SavedSearch, error) {
+	out := r.filter(func(s *collabdomain.SavedSearch) bool { return s.IsDue(now) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *memSavedSearchRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.searches, id)
+	return nil
+}
+
+// scriptedExecutor returns successive result sets, repeating the last one.
+type scriptedExecutor struct {
+	runs [][]SearchHit
+	err  error
+	n    int
+}
+
+func (e *scriptedExecutor) Execute(ctx context.Context, query json.RawMessage) ([]SearchHit, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	i := e.n
+	if i >= len(e.runs) {
+		i = len(e.runs) - 1
+	}
+	e.n++
+	return e.runs[i], nil
+}
+
+type recordingHitNotifier struct {
+	events []*SavedSearchHitsEvent
+}
+
+func (r *recordingHitNotifier) NotifyNewHits(ctx context.Context, e *SavedSearchHitsEvent) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+type savedSearchFixture struct {
+	repo     *memSavedSearchRepo
+	exec     *scriptedExecutor
+	notifier *recordingHitNotifier
+	svc      SavedSearchService
+}
+
+// newSavedSearchFixture sets up workspace ws1 with alice (attorney), bob
+// (analyst) and an inactive member, carol. eve is not a member.
+func newSavedSearchFixture() *savedSearchFixture {
+	inactive := activeMember("ws1", "carol", collabdomain.RoleViewer)
+	inactive.IsActive = false
+	members := &commentMemberRepo{members: map[string]*collabdomain.MemberPermission{}}
+	for _, m := range []*collabdomain.MemberPermission{
+		activeMember("ws1", "alice", collabdomain.RoleAttorney),
+		activeMember("ws1", "bob", collabdomain.RoleAnalyst),
+		inactive,
+	} {
+		members.members[m.WorkspaceID+"/"+m.UserID] = m
+	}
+	members.findByWorkspaceIDFn = func(ctx context.Context, workspaceID string) ([]*collabdomain.MemberPermission, error) {
+		var out []*collabdomain.MemberPermission
+		for _, m := range members.members {
+			if m.WorkspaceID == workspaceID {
+				out = append(out, m)
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].UserID < out[j].UserID })
+		return out, nil
+	}
+
+	f := &savedSearchFixture{
+		repo: newMemSavedSearchRepo(),
+		exec: &scriptedExecutor{runs: [][]SearchHit{
+			{{ID: "p1", Title: "CN1"}, {ID: "p2", Title: "CN2"}},
+			{{ID: "p3", Title: "CN3"}, {ID: "p1", Title: "CN1"}, {ID: "p2", Title: "CN2"}},
+		}},
+		notifier: &recordingHitNotifier{},
+	}
+	f.svc = NewSavedSearchService(f.repo, members, nil,
+		map[collabdomain.SavedSearchKind]SearchExecutor{collabdomain.SavedSearchPatent: f.exec},
+		f.notifier, &mockWsLogger{})
+	return f
+}
+
+func (f *savedSearchFixture) create(t *testing.T, owner, workspaceID string) *collabdomain.SavedSearch {
+	t.Helper()
+	s, err := f.svc.Create(context.Background(), &CreateSavedSearchRequest{
+		OwnerID:     owner,
+		WorkspaceID: workspaceID,
+		Name:        "blue TADF emitters",
+		Kind:        collabdomain.SavedSearchPatent,
+		Query:       json.RawMessage(`{"keywords":["TADF","blue"]}`),
+		Schedule:    collabdomain.ScheduleDaily,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return s
+}
+
+func TestSavedSearch_CreateEstablishesBaseline(t *testing.T) {
+	f := newSavedSearchFixture()
+	s := f.create(t, "alice", "")
+	if s.LastRunAt == nil || s.ResultCount != 2 {
+		t.Fatalf("expected baseline run with 2 results, got %+v", s)
+	}
+	if len(f.notifier.events) != 0 {
+		t.Fatalf("baseline must not notify, got %d events", len(f.notifier.events))
+	}
+}
+
+func TestSavedSearch_CreateValidation(t *testing.T) {
+	f := newSavedSearchFixture()
+	ctx := context.Background()
+	_, err := f.svc.Create(ctx, &CreateSavedSearchRequest{OwnerID: "alice", Name: "x", Kind: "portfolio", Query: json.RawMessage(`{}`)})
+	if !pkgerrors.IsValidation(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	_, err = f.svc.Create(ctx, &CreateSavedSearchRequest{OwnerID: "alice", Name: "x", Kind: collabdomain.SavedSearchPatent, Query: json.RawMessage(`{`)})
+	if !pkgerrors.IsValidation(err) {
+		t.Fatalf("expected validation error for bad query, got %v", err)
+	}
+	_, err = f.svc.Create(ctx, &CreateSavedSearchRequest{OwnerID: "eve", WorkspaceID: "ws1", Name: "x", Kind: collabdomain.SavedSearchPatent, Query: json.RawMessage(`{}`)})
+	if !pkgerrors.IsForbidden(err) {
+		t.Fatalf("non-member must not share into ws1, got %v", err)
+	}
+}
+
+func TestSavedSearch_RunDueNotifiesOnlyNewHits(t *testing.T) {
+	f := newSavedSearchFixture()
+	s := f.create(t, "alice", "ws1")
+	ctx := context.Background()
+
+	now := time.Now().Add(25 * time.Hour)
+	ran, err := f.svc.RunDue(ctx, now, 0)
+	if err != nil || ran != 1 {
+		t.Fatalf("RunDue = %d, %v", ran, err)
+	}
+	if len(f.notifier.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(f.notifier.events))
+	}
+	ev := f.notifier.events[0]
+	if len(ev.NewHits) != 1 || ev.NewHits[0].ID != "p3" || ev.TotalHits != 3 {
+		t.Fatalf("unexpected hits: %+v", ev)
+	}
+	if ev.SearchID != s.ID || ev.WorkspaceID != "ws1" {
+		t.Fatalf("unexpected event identity: %+v", ev)
+	}
+	if got := ev.Recipients; len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Fatalf("recipients = %v, want owner and active members", got)
+	}
+
+	// Nothing is due again until the next interval, and identical results
+	// produce no event.
+	if ran, _ := f.svc.RunDue(ctx, now, 0); ran != 0 {
+		t.Fatalf("search ran twice in one interval")
+	}
+	ran, _ = f.svc.RunDue(ctx, now.Add(25*time.Hour), 0)
+	if ran != 1 || len(f.notifier.events) != 1 {
+		t.Fatalf("unchanged results must not notify: ran=%d events=%d", ran, len(f.notifier.events))
+	}
+}
+
+func TestSavedSearch_RunFailureIsRecorded(t *testing.T) {
+	f := newSavedSearchFixture()
+	s := f.create(t, "alice", "")
+	f.exec.err = errors.New("opensearch down")
+
+	if _, err := f.svc.Run(context.Background(), s.ID, "alice"); err == nil {
+		t.Fatal("expected run error")
+	}
+	stored, _ := f.repo.FindByID(context.Background(), s.ID)
+	if stored.LastError == "" || stored.ResultCount != 2 {
+		t.Fatalf("failure must be recorded without losing the baseline: %+v", stored)
+	}
+}
+
+func TestSavedSearch_ManualRunSkipsActor(t *testing.T) {
+	f := newSavedSearchFixture()
+	s := f.create(t, "alice", "ws1")
+
+	res, err := f.svc.Run(context.Background(), s.ID, "bob")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(res.NewHits) != 1 || len(res.Hits) != 3 {
+		t.Fatalf("unexpected run result: %+v", res)
+	}
+	if got := f.notifier.events[0].Recipients; len(got) != 1 || got[0] != "alice" {
+		t.Fatalf("recipients = %v, want only the owner", got)
+	}
+}
+
+func TestSavedSearch_Visibility(t *testing.T) {
+	f := newSavedSearchFixture()
+	ctx := context.Background()
+	private := f.create(t, "alice", "")
+
+	if _, err := f.svc.Get(ctx, private.ID, "bob"); !pkgerrors.IsNotFound(err) {
+		t.Fatalf("private search visible to bob: %v", err)
+	}
+	if _, err := f.svc.Share(ctx, private.ID, "ws1", "bob"); !pkgerrors.IsNotFound(err) {
+		t.Fatalf("bob must not share alice's search: %v", err)
+	}
+
+	shared, err := f.svc.Share(ctx, private.ID, "ws1", "alice")
+	if err != nil || shared.WorkspaceID != "ws1" {
+		t.Fatalf("Share: %v", err)
+	}
+	if _, err := f.svc.Get(ctx, private.ID, "bob"); err != nil {
+		t.Fatalf("shared search hidden from bob: %v", err)
+	}
+	if _, err := f.svc.Get(ctx, private.ID, "carol"); err == nil {
+		t.Fatal("inactive member can read shared search")
+	}
+	if _, err := f.svc.Update(ctx, &UpdateSavedSearchRequest{SearchID: private.ID, UserID: "bob", Name: "mine"}); !pkgerrors.IsForbidden(err) {
+		t.Fatalf("member edited owner's search: %v", err)
+	}
+	if err := f.svc.Delete(ctx, private.ID, "bob"); !pkgerrors.IsForbidden(err) {
+		t.Fatalf("member deleted owner's search: %v", err)
+	}
+
+	list, err := f.svc.List(ctx, "bob", "ws1")
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List(ws1) = %d, %v", len(list), err)
+	}
+	if _, err := f.svc.List(ctx, "eve", "ws1"); !pkgerrors.IsForbidden(err) {
+		t.Fatalf("non-member listed ws1: %v", err)
+	}
+
+	if _, err := f.svc.Unshare(ctx, private.ID, "alice"); err != nil {
+		t.Fatalf("Unshare: %v", err)
+	}
+	if list, _ := f.svc.List(ctx, "bob", "ws1"); len(list) != 0 {
+		t.Fatalf("unshared search still listed")
+	}
+}
+
+func TestSavedSearch_UpdateAndDelete(t *testing.T) {
+	f := newSavedSearchFixture()
+	ctx := context.Background()
+	s := f.create(t, "alice", "")
+
+	updated, err := f.svc.Update(ctx, &UpdateSavedSearchRequest{
+		SearchID: s.ID,
+		UserID:   "alice",
+		Name:     "renamed",
+		Schedule: collabdomain.ScheduleManual,
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Name != "renamed" || updated.NextRunAt != nil || updated.LastRunAt == nil {
+		t.Fatalf("unexpected update: %+v", updated)
+	}
+
+	if err := f.svc.Delete(ctx, s.ID, "alice"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := f.svc.Get(ctx, s.ID, "alice"); !pkgerrors.IsNotFound(err) {
+		t.Fatalf("deleted search still readable: %v", err)
+	}
+}
+
+func TestSavedSearch_MissingExecutor(t *testing.T) {
+	f := newSavedSearchFixture()
+	ctx := context.Background()
+	_, err := f.svc.Create(ctx, &CreateSavedSearchRequest{
+		OwnerID: "alice",
+		Name:    "graph",
+		Kind:    collabdomain.SavedSearchKnowledgeGraph,
+		Query:   json.RawMessage(`{"entity_type":"Company"}`),
+	})
+	if !pkgerrors.IsValidation(err) {
+		t.Fatalf("kinds without an executor must be rejected, got %v", err)
+	}
+
+	// Searches stored before their executor was removed fail to run.
+	s, err := collabdomain.NewSavedSearch("alice", "graph", collabdomain.SavedSearchKnowledgeGraph,
+		json.RawMessage(`{"entity_type":"Company"}`), "")
+	if err != nil {
+		t.Fatalf("NewSavedSearch: %v", err)
+	}
+	if err := f.repo.Save(ctx, s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := f.svc.Run(ctx, s.ID, "alice"); !pkgerrors.IsCode(err, pkgerrors.ErrCodeNotImplemented) {
+		t.Fatalf("expected not implemented, got %v", err)
+	}
+}
+
+func TestMultiHitNotifier(t *testing.T) {
+	a, b := &recordingHitNotifier{}, &recordingHitNotifier{}
+	if err := (MultiHitNotifier{a, nil, b}).NotifyNewHits(context.Background(), &SavedSearchHitsEvent{SearchID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.events) != 1 || len(b.events) != 1 {
+		t.Fatal("event not fanned out")
+	}
+}
+
+//Personal.AI order the ending
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
@@ -232,7 +233,7 @@ func (s *competitorTrackingServiceImpl) TrackCompetitor(ctx context.Context, req
 	competitor := &TrackedCompetitor{
 		ID:              generateCompetitorID(req.Name, req.WatchlistID, now),
 		Name:            req.Name,
-		Aliases:         req.Aliases,
+		Aliases:         dedupeAliases(req.Name, req.Aliases),
 		Status:          CompetitorStatusActive,
 		WatchlistID:     req.WatchlistID,
 		TechnologyAreas: req.TechnologyAreas,
@@ -581,6 +582,23 @@ func (s *competitorTrackingServiceImpl) ComparePortfolios(ctx context.Context, c
 	return comparison, nil
 }
 
+// dedupeAliases drops aliases that normalise to the competitor name or to an
+// earlier alias, so "LG Display Co., Ltd." and "LG DISPLAY CO LTD" are kept
+// once.
+func dedupeAliases(name string, aliases []string) []string {
+	seen := map[string]bool{assignee.Normalize(name): true}
+	var out []string
+	for _, a := range aliases {
+		key := assignee.Normalize(a)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, a)
+	}
+	return out
+}
+
 // generateCompetitorID produces a unique competitor identifier.
 func generateCompetitorID(name, watchlistID string, ts time.Time) string {
 	data := fmt.Sprintf("%s:%s:%d", name, watchlistID, ts.UnixNano())
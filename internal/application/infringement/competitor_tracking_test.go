@@ -143,6 +143,23 @@ func TestTrackCompetitor_Success(t *testing.T) {
 	}
 }
 
+func TestTrackCompetitor_DedupesAliasVariants(t *testing.T) {
+	repo := newMockCompetitorRepository()
+	svc := newTestCompetitorTrackingService(repo)
+
+	competitor, err := svc.TrackCompetitor(context.Background(), &TrackCompetitorRequest{
+		Name:        "LG Display Co., Ltd.",
+		Aliases:     []string{"LG DISPLAY CO LTD", "乐金显示有限公司", "LG Philips LCD", "lg philips lcd co."},
+		WatchlistID: "WL-001",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(competitor.Aliases) != 1 || competitor.Aliases[0] != "LG Philips LCD" {
+		t.Errorf("expected only the distinct alias LG Philips LCD, got %v", competitor.Aliases)
+	}
+}
+
 func TestTrackCompetitor_NilRequest(t *testing.T) {
 	repo := newMockCompetitorRepository()
 	svc := newTestCompetitorTrackingService(repo)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	intcommon "github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 	common "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
 )
@@ -646,6 +647,9 @@ func (s *monitoringServiceImpl) RunScan(ctx context.Context, watchlistID string)
 
 // RunScheduledScans finds all watchlists due for scanning and runs them.
 func (s *monitoringServiceImpl) RunScheduledScans(ctx context.Context) (int, error) {
+	// Nobody waits on scheduled scans, so their model calls queue behind
+	// interactive and batch work.
+	ctx = intcommon.ContextWithPriority(ctx, intcommon.PriorityBackground)
 	now := time.Now().UTC()
 	dueWatchlists, err := s.watchlistRepo.FindDueForScan(ctx, now)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	intcommon "github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
 )
 
@@ -94,8 +95,9 @@ func (m *mockWatchlistRepository) FindDueForScan(ctx context.Context, before tim
 // --- Mock ScanResultRepository ---
 
 type mockScanResultRepository struct {
-	mu      sync.Mutex
-	results map[string][]*ScanResult
+	mu         sync.Mutex
+	results    map[string][]*ScanResult
+	priorities []intcommon.RequestPriority // scheduling priority of each Save
 }
 
 func newMockScanResultRepository() *mockScanResultRepository {
@@ -108,6 +110,7 @@ func (m *mockScanResultRepository) Save(ctx context.Context, result *ScanResult)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.results[result.WatchlistID] = append(m.results[result.WatchlistID], result)
+	m.priorities = append(m.priorities, intcommon.PriorityFromContext(ctx))
 	return nil
 }
 
@@ -560,7 +563,8 @@ func TestRunScan_NotFound(t *testing.T) {
 
 func TestRunScheduledScans_Success(t *testing.T) {
 	wlRepo := newMockWatchlistRepository()
-	svc := newTestMonitoringService(wlRepo, newMockScanResultRepository(), newMockAlertServiceForMonitoring())
+	srRepo := newMockScanResultRepository()
+	svc := newTestMonitoringService(wlRepo, srRepo, newMockAlertServiceForMonitoring())
 
 	// Create watchlist with NextScanAt in the past.
 	created, _ := svc.CreateWatchlist(context.Background(), &CreateWatchlistRequest{
@@ -581,6 +585,9 @@ func TestRunScheduledScans_Success(t *testing.T) {
 	if count != 1 {
 		t.Errorf("expected 1 scan run, got %d", count)
 	}
+	if len(srRepo.priorities) != 1 || srRepo.priorities[0] != intcommon.PriorityBackground {
+		t.Errorf("expected the scheduled scan to run at background priority, got %v", srRepo.priorities)
+	}
 }
 
 func TestGetScanHistory_Success(t *testing.T) {
@@ -0,0 +1,419 @@
+// ---
+// internal/application/inventor/service.go
+//
+// 功能定位: 发明人消歧与发明人网络分析应用服务，将专利上的发明人署名聚类为稳定的发明人 ID，
+//   供竞争情报团队追踪关键化学家在竞争对手之间的流动与合作网络。
+//
+// 核心实现:
+//   - Service 接口: Disambiguate / Get / Mobility / Network
+//   - 消歧: 分页读取全部发明人署名 → 姓名分块 + 多证据打分聚类（姓名变体、共同发明人重合、
+//     申请人、地域、CPC 相似度）→ 写回 patent_inventors.inventor_id，复用上次的 ID 保持稳定
+//   - 图谱发布: 经 GraphWriter（KnowledgeGraphRepository）按 id 合并 Inventor 节点、
+//     INVENTED 与 CO_INVENTED_WITH 关系；完整运行后按 generation 清理被合并或拆分掉的旧节点
+//   - 流动分析: 按竞争对手名称找到相关发明人，检测其申请人任职段之间的跳槽
+//   - 合作网络: 以单个发明人为中心或以申请人为范围构建共同发明网络，按连接强度排序识别关键发明人
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+// ---
+
+package inventor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "github.com/turtacn/KeyIP-Intelligence/internal/domain/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const (
+	// DefaultPageSize is the number of patents whose mentions are read per
+	// page during disambiguation.
+	DefaultPageSize = 1000
+	// DefaultMaxInventors bounds the inventors loaded per analytics query.
+	DefaultMaxInventors = 2000
+	// DefaultNetworkSize is the node limit of a network for non-positive
+	// limits.
+	DefaultNetworkSize = 100
+	maxNetworkSize     = 1000
+	maxCompetitors     = 20
+	graphBatchSize     = 500
+
+	// Graph labels and relationship types written for inventors.
+	InventorLabel      = "Inventor"
+	PatentLabel        = "Patent"
+	InventedRel        = "INVENTED"
+	CoInventedRel      = "CO_INVENTED_WITH"
+	graphDateFormat    = "2006-01-02"
+	generationProperty = "generation"
+)
+
+// GraphWriter publishes inventors to the knowledge graph. The Neo4j
+// KnowledgeGraphRepository satisfies it.
+type GraphWriter interface {
+	UpsertNodes(ctx context.Context, label string, nodes []map[string]interface{}) (int64, error)
+	UpsertRelations(ctx context.Context, fromLabel, relType, toLabel string, rows []map[string]interface{}) (int64, error)
+	PruneGeneration(ctx context.Context, label string, generation string) (int64, error)
+}
+
+// RunOptions bounds a disambiguation run. MaxMentions 0 means no limit; a
+// run stopped by it does not prune the graph, since inventors outside the
+// mentions read would look stale.
+type RunOptions struct {
+	PageSize    int
+	MaxMentions int
+}
+
+// RunResult summarises a disambiguation run.
+type RunResult struct {
+	Generation       string `json:"generation"`
+	Mentions         int    `json:"mentions"`
+	Inventors        int    `json:"inventors"`
+	NewInventors     int    `json:"new_inventors"`
+	AssignmentsSaved int64  `json:"assignments_saved"`
+	Complete         bool   `json:"complete"`
+	GraphNodes       int64  `json:"graph_nodes"`
+	GraphRelations   int64  `json:"graph_relations"`
+	GraphPruned      int64  `json:"graph_pruned"`
+}
+
+// MobilityQuery asks for moves into or out of the named competitors.
+type MobilityQuery struct {
+	Competitors     []string
+	Since           time.Time
+	MinStintPatents int
+}
+
+// NetworkQuery selects a collaboration network: the ego network of
+// InventorID, or the inventors of Assignee. Exactly one must be set.
+type NetworkQuery struct {
+	InventorID *uuid.UUID
+	Assignee   string
+	MinShared  int
+	Limit      int
+}
+
+// Service disambiguates inventors and answers mobility and network queries.
+type Service interface {
+	Disambiguate(ctx context.Context, opts RunOptions) (*RunResult, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Inventor, error)
+	Mobility(ctx context.Context, q MobilityQuery) ([]*domain.Move, error)
+	Network(ctx context.Context, q NetworkQuery) (*domain.Network, error)
+}
+
+// Config tunes the service. Zero values use the defaults.
+type Config struct {
+	Disambiguation domain.Config
+	MaxInventors   int
+}
+
+type service struct {
+	repo         domain.Repository
+	graph        GraphWriter
+	disambiguate *domain.Disambiguator
+	cfg          Config
+	logger       logging.Logger
+}
+
+// NewService creates an inventor service. graph may be nil, in which case
+// inventors are only recorded on patent mentions.
+func NewService(repo domain.Repository, graph GraphWriter, cfg Config, logger logging.Logger) Service {
+	if cfg.MaxInventors <= 0 {
+		cfg.MaxInventors = DefaultMaxInventors
+	}
+	return &service{
+		repo:         repo,
+		graph:        graph,
+		disambiguate: domain.NewDisambiguator(cfg.Disambiguation),
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+func (s *service) Disambiguate(ctx context.Context, opts RunOptions) (*RunResult, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	result := &RunResult{Generation: uuid.NewString(), Complete: true}
+
+	var mentions []*domain.Mention
+	cursor := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		page, err := s.repo.ListMentions(ctx, cursor, opts.PageSize)
+		if err != nil {
+			return result, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		mentions = append(mentions, page...)
+		cursor = page[len(page)-1].PatentID
+		if opts.MaxMentions > 0 && len(mentions) >= opts.MaxMentions {
+			result.Complete = false
+			break
+		}
+	}
+	result.Mentions = len(mentions)
+
+	inventors := s.disambiguate.Cluster(mentions)
+	result.Inventors = len(inventors)
+	for _, inv := range inventors {
+		if !carriesID(inv) {
+			result.NewInventors++
+		}
+	}
+
+	saved, err := s.repo.SaveAssignments(ctx, domain.Assignments(inventors))
+	result.AssignmentsSaved = saved
+	if err != nil {
+		return result, err
+	}
+
+	if s.graph != nil {
+		if err := s.publish(ctx, inventors, result); err != nil {
+			return result, err
+		}
+	}
+
+	s.logger.Info("inventor disambiguation completed",
+		logging.Int("mentions", result.Mentions),
+		logging.Int("inventors", result.Inventors),
+		logging.Int("new_inventors", result.NewInventors),
+		logging.Int64("assignments_saved", result.AssignmentsSaved),
+		logging.Bool("complete", result.Complete))
+	return result, nil
+}
+
+// carriesID reports whether any mention already carried the inventor's ID.
+func carriesID(inv *domain.Inventor) bool {
+	for _, m := range inv.Mentions {
+		if m.InventorID != nil && *m.InventorID == inv.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// publish merges inventors, their patents and collaborations into the
+// graph, tagging everything with the run's generation.
+func (s *service) publish(ctx context.Context, inventors []*domain.Inventor, result *RunResult) error {
+	gen := result.Generation
+	var nodes, patents, invented, coInvented []map[string]interface{}
+	seenPatents := make(map[uuid.UUID]bool)
+	for _, inv := range inventors {
+		nodes = append(nodes, inventorNode(inv, gen))
+		for _, m := range inv.Mentions {
+			if !seenPatents[m.PatentID] {
+				seenPatents[m.PatentID] = true
+				patents = append(patents, map[string]interface{}{"id": m.PatentID.String(), "patent_number": m.PatentNumber})
+			}
+			invented = append(invented, map[string]interface{}{
+				"from_id":    inv.ID.String(),
+				"to_id":      m.PatentID.String(),
+				"properties": map[string]interface{}{"sequence": m.Sequence, "name": m.Name, generationProperty: gen},
+			})
+		}
+	}
+	for _, e := range domain.BuildNetwork(inventors, 1).Edges {
+		coInvented = append(coInvented, map[string]interface{}{
+			"from_id":    e.From.String(),
+			"to_id":      e.To.String(),
+			"properties": map[string]interface{}{"shared_patents": e.SharedPatents, generationProperty: gen},
+		})
+	}
+
+	for _, batch := range chunk(nodes) {
+		n, err := s.graph.UpsertNodes(ctx, InventorLabel, batch)
+		if err != nil {
+			return err
+		}
+		result.GraphNodes += n
+	}
+	for _, batch := range chunk(patents) {
+		if _, err := s.graph.UpsertNodes(ctx, PatentLabel, batch); err != nil {
+			return err
+		}
+	}
+	for _, batch := range chunk(invented) {
+		n, err := s.graph.UpsertRelations(ctx, InventorLabel, InventedRel, PatentLabel, batch)
+		if err != nil {
+			return err
+		}
+		result.GraphRelations += n
+	}
+	for _, batch := range chunk(coInvented) {
+		n, err := s.graph.UpsertRelations(ctx, InventorLabel, CoInventedRel, InventorLabel, batch)
+		if err != nil {
+			return err
+		}
+		result.GraphRelations += n
+	}
+
+	if !result.Complete {
+		return nil
+	}
+	pruned, err := s.graph.PruneGeneration(ctx, InventorLabel, gen)
+	if err != nil {
+		return err
+	}
+	result.GraphPruned = pruned
+	return nil
+}
+
+func inventorNode(inv *domain.Inventor, gen string) map[string]interface{} {
+	node := map[string]interface{}{
+		"id":               inv.ID.String(),
+		"name":             inv.CanonicalName,
+		"normalized_name":  inv.NormalizedName,
+		"variants":         inv.Variants,
+		"assignees":        inv.Assignees,
+		"countries":        inv.Countries,
+		"cpc_subclasses":   inv.CPCSubclasses,
+		"patent_count":     inv.PatentCount,
+		generationProperty: gen,
+	}
+	if inv.FirstFiled != nil {
+		node["first_filed"] = inv.FirstFiled.Format(graphDateFormat)
+	}
+	if inv.LastFiled != nil {
+		node["last_filed"] = inv.LastFiled.Format(graphDateFormat)
+	}
+	return node
+}
+
+func chunk(rows []map[string]interface{}) [][]map[string]interface{} {
+	var out [][]map[string]interface{}
+	for start := 0; start < len(rows); start += graphBatchSize {
+		out = append(out, rows[start:min(start+graphBatchSize, len(rows))])
+	}
+	return out
+}
+
+func (s *service) Get(ctx context.Context, id uuid.UUID) (*domain.Inventor, error) {
+	mentions, err := s.repo.ListMentionsByInventors(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	inventors := domain.Group(mentions)
+	if len(inventors) == 0 {
+		return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, "inventor not found")
+	}
+	return inventors[0], nil
+}
+
+func (s *service) Mobility(ctx context.Context, q MobilityQuery) ([]*domain.Move, error) {
+	var competitors []string
+	for _, c := range q.Competitors {
+		if c = strings.TrimSpace(c); c != "" {
+			competitors = append(competitors, c)
+		}
+	}
+	if len(competitors) == 0 {
+		return nil, pkgerrors.NewValidationError("competitors", "at least one competitor is required")
+	}
+	if len(competitors) > maxCompetitors {
+		return nil, pkgerrors.NewValidationError("competitors", "too many competitors")
+	}
+
+	ids, err := s.inventorIDsOf(ctx, competitors)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := s.repo.ListMentionsByInventors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	moves := domain.DetectMoves(domain.Group(mentions), domain.MobilityOptions{
+		Competitors:     competitors,
+		Since:           q.Since,
+		MinStintPatents: q.MinStintPatents,
+	})
+	if moves == nil {
+		moves = []*domain.Move{}
+	}
+	return moves, nil
+}
+
+// inventorIDsOf collects the distinct inventors of the named assignees, up
+// to MaxInventors.
+func (s *service) inventorIDsOf(ctx context.Context, assignees []string) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	var out []uuid.UUID
+	for _, a := range assignees {
+		remaining := s.cfg.MaxInventors - len(out)
+		if remaining <= 0 {
+			break
+		}
+		ids, err := s.repo.FindInventorIDsByAssignee(ctx, a, remaining)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *service) Network(ctx context.Context, q NetworkQuery) (*domain.Network, error) {
+	q.Assignee = strings.TrimSpace(q.Assignee)
+	if (q.InventorID == nil) == (q.Assignee == "") {
+		return nil, pkgerrors.NewValidationError("query", "exactly one of inventor_id or assignee is required")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultNetworkSize
+	}
+	q.Limit = min(q.Limit, maxNetworkSize)
+
+	var mentions []*domain.Mention
+	var err error
+	if q.InventorID != nil {
+		mentions, err = s.repo.ListCoMentions(ctx, []uuid.UUID{*q.InventorID})
+		if err == nil && len(mentions) == 0 {
+			return nil, pkgerrors.New(pkgerrors.ErrCodeNotFound, "inventor not found")
+		}
+	} else {
+		var ids []uuid.UUID
+		ids, err = s.inventorIDsOf(ctx, []string{q.Assignee})
+		if err == nil {
+			mentions, err = s.repo.ListMentionsByInventors(ctx, ids)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return truncateNetwork(domain.BuildNetwork(domain.Group(mentions), q.MinShared), q.Limit), nil
+}
+
+// truncateNetwork keeps the limit strongest nodes and the edges between
+// them.
+func truncateNetwork(net *domain.Network, limit int) *domain.Network {
+	if len(net.Nodes) <= limit {
+		return net
+	}
+	net.Nodes = net.Nodes[:limit]
+	kept := make(map[uuid.UUID]bool, limit)
+	for _, n := range net.Nodes {
+		kept[n.InventorID] = true
+	}
+	edges := net.Edges[:0]
+	for _, e := range net.Edges {
+		if kept[e.From] && kept[e.To] {
+			edges = append(edges, e)
+		}
+	}
+	net.Edges = edges
+	return net
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,327 @@
+package inventor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domain "github.com/turtacn/KeyIP-Intelligence/internal/domain/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// memInventorRepo is an in-memory domain.Repository.
+type memInventorRepo struct {
+	mu       sync.Mutex
+	mentions []*domain.Mention
+}
+
+type filing struct {
+	assignee  string
+	filed     string
+	inventors []string
+}
+
+func newMemInventorRepo(t *testing.T, filings ...filing) *memInventorRepo {
+	t.Helper()
+	repo := &memInventorRepo{}
+	for i, f := range filings {
+		id := uuid.NewSHA1(uuid.NameSpaceURL, []byte{byte(i)})
+		filed, err := time.Parse("2006-01-02", f.filed)
+		require.NoError(t, err)
+		for seq, name := range f.inventors {
+			var co []string
+			for j, other := range f.inventors {
+				if j != seq {
+					co = append(co, other)
+				}
+			}
+			repo.mentions = append(repo.mentions, &domain.Mention{
+				PatentID:     id,
+				PatentNumber: "US" + string(rune('A'+i)),
+				Sequence:     seq + 1,
+				Name:         name,
+				AssigneeName: f.assignee,
+				Country:      "US",
+				CPCCodes:     []string{"H10K85/30"},
+				FilingDate:   &filed,
+				CoInventors:  co,
+			})
+		}
+	}
+	sort.SliceStable(repo.mentions, func(i, j int) bool {
+		return repo.mentions[i].PatentID.String() < repo.mentions[j].PatentID.String()
+	})
+	return repo
+}
+
+func clone(m *domain.Mention) *domain.Mention {
+	cp := *m
+	if m.InventorID != nil {
+		id := *m.InventorID
+		cp.InventorID = &id
+	}
+	return &cp
+}
+
+func (r *memInventorRepo) ListMentions(_ context.Context, after uuid.UUID, limit int) ([]*domain.Mention, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.Mention
+	patents := 0
+	var last uuid.UUID
+	for _, m := range r.mentions {
+		if m.PatentID.String() <= after.String() {
+			continue
+		}
+		if m.PatentID != last {
+			if patents == limit {
+				break
+			}
+			patents++
+			last = m.PatentID
+		}
+		out = append(out, clone(m))
+	}
+	return out, nil
+}
+
+func (r *memInventorRepo) ListMentionsByInventors(_ context.Context, ids []uuid.UUID) ([]*domain.Mention, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	want := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	var out []*domain.Mention
+	for _, m := range r.mentions {
+		if m.InventorID != nil && want[*m.InventorID] {
+			out = append(out, clone(m))
+		}
+	}
+	return out, nil
+}
+
+func (r *memInventorRepo) ListCoMentions(ctx context.Context, ids []uuid.UUID) ([]*domain.Mention, error) {
+	own, _ := r.ListMentionsByInventors(ctx, ids)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	patents := map[uuid.UUID]bool{}
+	for _, m := range own {
+		patents[m.PatentID] = true
+	}
+	var out []*domain.Mention
+	for _, m := range r.mentions {
+		if patents[m.PatentID] {
+			out = append(out, clone(m))
+		}
+	}
+	return out, nil
+}
+
+func (r *memInventorRepo) FindInventorIDsByAssignee(_ context.Context, name string, limit int) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := map[uuid.UUID]bool{}
+	var out []uuid.UUID
+	for _, m := range r.mentions {
+		if m.InventorID == nil || seen[*m.InventorID] || len(out) == limit {
+			continue
+		}
+		if strings.Contains(strings.ToLower(m.AssigneeName), strings.ToLower(name)) {
+			seen[*m.InventorID] = true
+			out = append(out, *m.InventorID)
+		}
+	}
+	return out, nil
+}
+
+func (r *memInventorRepo) SaveAssignments(_ context.Context, assignments []domain.Assignment) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, a := range assignments {
+		for _, m := range r.mentions {
+			if m.PatentID == a.PatentID && m.Sequence == a.Sequence && (m.InventorID == nil || *m.InventorID != a.InventorID) {
+				id := a.InventorID
+				m.InventorID = &id
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// recordingGraph records what the service publishes.
+type recordingGraph struct {
+	nodes     map[string][]map[string]interface{}
+	relations map[string][]map[string]interface{}
+	pruned    []string
+}
+
+func newRecordingGraph() *recordingGraph {
+	return &recordingGraph{nodes: map[string][]map[string]interface{}{}, relations: map[string][]map[string]interface{}{}}
+}
+
+func (g *recordingGraph) UpsertNodes(_ context.Context, label string, nodes []map[string]interface{}) (int64, error) {
+	g.nodes[label] = append(g.nodes[label], nodes...)
+	return int64(len(nodes)), nil
+}
+
+func (g *recordingGraph) UpsertRelations(_ context.Context, _, relType, _ string, rows []map[string]interface{}) (int64, error) {
+	g.relations[relType] = append(g.relations[relType], rows...)
+	return int64(len(rows)), nil
+}
+
+func (g *recordingGraph) PruneGeneration(_ context.Context, label string, generation string) (int64, error) {
+	g.pruned = append(g.pruned, label+":"+generation)
+	return 0, nil
+}
+
+// udcToMerck is a small field: Wolohan and Dyatkin move from Universal
+// Display to Merck together, Xia stays.
+func udcToMerck(t *testing.T) *memInventorRepo {
+	return newMemInventorRepo(t,
+		filing{"Universal Display Corporation", "2015-02-01", []string{"Peter Wolohan", "Alexey Dyatkin", "Chuanjun Xia"}},
+		filing{"Universal Display Corp", "2016-06-01", []string{"Peter Wolohan", "Alexey Dyatkin"}},
+		filing{"Universal Display Corporation", "2017-03-01", []string{"Chuanjun Xia", "Alexey Dyatkin"}},
+		filing{"Merck Patent GmbH", "2019-09-01", []string{"Peter Wolohan", "Alexey B. Dyatkin"}},
+		filing{"Merck Patent GmbH", "2020-09-01", []string{"P. Wolohan", "Alexey Dyatkin"}},
+	)
+}
+
+func newTestService(repo domain.Repository, graph GraphWriter) Service {
+	return NewService(repo, graph, Config{}, logging.NewNopLogger())
+}
+
+func TestDisambiguate_AssignsAndPublishes(t *testing.T) {
+	repo := udcToMerck(t)
+	graph := newRecordingGraph()
+	svc := newTestService(repo, graph)
+
+	result, err := svc.Disambiguate(context.Background(), RunOptions{PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 11, result.Mentions)
+	assert.Equal(t, 3, result.Inventors)
+	assert.Equal(t, 3, result.NewInventors)
+	assert.Equal(t, int64(11), result.AssignmentsSaved)
+	assert.True(t, result.Complete)
+
+	assert.Len(t, graph.nodes[InventorLabel], 3)
+	assert.Len(t, graph.nodes[PatentLabel], 5)
+	assert.Len(t, graph.relations[InventedRel], 11)
+	assert.Len(t, graph.relations[CoInventedRel], 3)
+	assert.Equal(t, []string{InventorLabel + ":" + result.Generation}, graph.pruned)
+	for _, n := range graph.nodes[InventorLabel] {
+		assert.Equal(t, result.Generation, n["generation"])
+	}
+
+	// A second run keeps every ID and writes nothing new.
+	again, err := svc.Disambiguate(context.Background(), RunOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, again.NewInventors)
+	assert.Zero(t, again.AssignmentsSaved)
+}
+
+func TestDisambiguate_PartialRunDoesNotPrune(t *testing.T) {
+	repo := udcToMerck(t)
+	graph := newRecordingGraph()
+	svc := newTestService(repo, graph)
+
+	result, err := svc.Disambiguate(context.Background(), RunOptions{PageSize: 1, MaxMentions: 2})
+	require.NoError(t, err)
+	assert.False(t, result.Complete)
+	assert.Less(t, result.Mentions, 11)
+	assert.Empty(t, graph.pruned)
+}
+
+func TestDisambiguate_WithoutGraph(t *testing.T) {
+	svc := newTestService(udcToMerck(t), nil)
+	result, err := svc.Disambiguate(context.Background(), RunOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, result.GraphNodes)
+}
+
+func TestGet(t *testing.T) {
+	repo := udcToMerck(t)
+	svc := newTestService(repo, nil)
+	_, err := svc.Disambiguate(context.Background(), RunOptions{})
+	require.NoError(t, err)
+
+	var id uuid.UUID
+	for _, m := range repo.mentions {
+		if m.Name == "P. Wolohan" {
+			id = *m.InventorID
+		}
+	}
+	inv, err := svc.Get(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "Peter Wolohan", inv.CanonicalName)
+	assert.Equal(t, 4, inv.PatentCount)
+
+	_, err = svc.Get(context.Background(), uuid.New())
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+func TestMobility(t *testing.T) {
+	repo := udcToMerck(t)
+	svc := newTestService(repo, nil)
+	_, err := svc.Disambiguate(context.Background(), RunOptions{})
+	require.NoError(t, err)
+
+	moves, err := svc.Mobility(context.Background(), MobilityQuery{Competitors: []string{"Merck"}})
+	require.NoError(t, err)
+	require.Len(t, moves, 2)
+	names := []string{moves[0].InventorName, moves[1].InventorName}
+	assert.ElementsMatch(t, []string{"Peter Wolohan", "Alexey Dyatkin"}, names)
+	for _, m := range moves {
+		assert.Equal(t, "Merck Patent GmbH", m.To)
+	}
+
+	_, err = svc.Mobility(context.Background(), MobilityQuery{Competitors: []string{" "}})
+	assert.True(t, pkgerrors.IsValidation(err))
+}
+
+func TestNetwork(t *testing.T) {
+	repo := udcToMerck(t)
+	svc := newTestService(repo, nil)
+	_, err := svc.Disambiguate(context.Background(), RunOptions{})
+	require.NoError(t, err)
+
+	var xia uuid.UUID
+	for _, m := range repo.mentions {
+		if m.Name == "Chuanjun Xia" {
+			xia = *m.InventorID
+		}
+	}
+
+	ego, err := svc.Network(context.Background(), NetworkQuery{InventorID: &xia})
+	require.NoError(t, err)
+	assert.Len(t, ego.Nodes, 3)
+	assert.Equal(t, "Alexey Dyatkin", ego.Nodes[0].Name, "Dyatkin collaborates most")
+
+	merck, err := svc.Network(context.Background(), NetworkQuery{Assignee: "Merck", MinShared: 3})
+	require.NoError(t, err)
+	require.Len(t, merck.Edges, 1)
+	assert.Equal(t, 4, merck.Edges[0].SharedPatents)
+
+	top, err := svc.Network(context.Background(), NetworkQuery{Assignee: "Universal Display", Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, top.Nodes, 1)
+	assert.Empty(t, top.Edges)
+
+	_, err = svc.Network(context.Background(), NetworkQuery{})
+	assert.True(t, pkgerrors.IsValidation(err))
+	missing := uuid.New()
+	_, err = svc.Network(context.Background(), NetworkQuery{InventorID: &missing})
+	assert.True(t, pkgerrors.IsNotFound(err))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,114 @@
+package portfolio
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	domainportfolio "github.com/turtacn/KeyIP-Intelligence/internal/domain/portfolio"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// assessmentValuationMethod prefixes the valuation_method of rows written by
+// the assessment store, so they can be told apart from other valuations.
+const assessmentValuationMethod = "assessment:"
+
+// valuationAssessmentRepository stores assessment records as rows of the
+// patent valuation history kept by the portfolio repository.
+type valuationAssessmentRepository struct {
+	repo domainportfolio.PortfolioRepository
+}
+
+// NewValuationAssessmentRepository returns an AssessmentRepository backed by
+// the portfolio repository's valuation history. Records are addressed by
+// patent and portfolio; the valuation history has no lookup by record ID.
+func NewValuationAssessmentRepository(repo domainportfolio.PortfolioRepository) AssessmentRepository {
+	return &valuationAssessmentRepository{repo: repo}
+}
+
+func (r *valuationAssessmentRepository) Save(ctx context.Context, record *AssessmentRecord) error {
+	if record == nil {
+		return errors.NewValidation("assessment record is nil")
+	}
+	v := &domainportfolio.Valuation{
+		PatentID:        record.PatentID,
+		TechnicalScore:  record.DimensionScores[DimensionTechnicalValue],
+		LegalScore:      record.DimensionScores[DimensionLegalValue],
+		MarketScore:     record.DimensionScores[DimensionCommercialValue],
+		StrategicScore:  record.DimensionScores[DimensionStrategicValue],
+		CompositeScore:  record.OverallScore,
+		Tier:            domainportfolio.ValuationTier(record.Tier),
+		Currency:        "USD",
+		ValuationMethod: assessmentValuationMethod + string(record.AssessorType),
+		ScoringDetails:  map[string]any{"assessment_id": record.ID},
+		ValidFrom:       record.AssessedAt,
+	}
+	if v.ValidFrom.IsZero() {
+		v.ValidFrom = time.Now().UTC()
+	}
+	if record.PortfolioID != "" {
+		portfolioID := record.PortfolioID
+		v.PortfolioID = &portfolioID
+	}
+	return r.repo.CreateValuation(ctx, v)
+}
+
+func (r *valuationAssessmentRepository) FindByID(ctx context.Context, id string) (*AssessmentRecord, error) {
+	return nil, errors.New(errors.ErrCodeNotImplemented, "assessment lookup by ID is not supported by the valuation history")
+}
+
+func (r *valuationAssessmentRepository) FindByIDs(ctx context.Context, ids []string) ([]*AssessmentRecord, error) {
+	return nil, errors.New(errors.ErrCodeNotImplemented, "assessment lookup by ID is not supported by the valuation history")
+}
+
+func (r *valuationAssessmentRepository) FindByPatentID(ctx context.Context, patentID string, limit, offset int) ([]*AssessmentRecord, error) {
+	valuations, err := r.repo.GetValuationHistory(ctx, patentID, limit+offset)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= len(valuations) {
+		return []*AssessmentRecord{}, nil
+	}
+	return valuationsToAssessments(valuations[offset:]), nil
+}
+
+func (r *valuationAssessmentRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*AssessmentRecord, error) {
+	valuations, err := r.repo.GetValuationsByPortfolio(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	return valuationsToAssessments(valuations), nil
+}
+
+func valuationsToAssessments(valuations []*domainportfolio.Valuation) []*AssessmentRecord {
+	records := make([]*AssessmentRecord, 0, len(valuations))
+	for _, v := range valuations {
+		if v == nil {
+			continue
+		}
+		record := &AssessmentRecord{
+			ID:           v.ID,
+			PatentID:     v.PatentID,
+			OverallScore: v.CompositeScore,
+			Tier:         PatentTier(v.Tier),
+			DimensionScores: map[AssessmentDimension]float64{
+				DimensionTechnicalValue:  v.TechnicalScore,
+				DimensionLegalValue:      v.LegalScore,
+				DimensionCommercialValue: v.MarketScore,
+				DimensionStrategicValue:  v.StrategicScore,
+			},
+			AssessedAt:   v.ValidFrom,
+			AssessorType: AssessorAI,
+		}
+		if v.PortfolioID != nil {
+			record.PortfolioID = *v.PortfolioID
+		}
+		if method, ok := strings.CutPrefix(v.ValuationMethod, assessmentValuationMethod); ok && method != "" {
+			record.AssessorType = AssessorType(method)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+//Personal.AI order the ending
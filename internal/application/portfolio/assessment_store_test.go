@@ -0,0 +1,86 @@
+package portfolio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainportfolio "github.com/turtacn/KeyIP-Intelligence/internal/domain/portfolio"
+)
+
+// valuationHistoryRepo records valuations so the assessment store can be
+// exercised end to end.
+type valuationHistoryRepo struct {
+	mockPortfolioRepoConstellation
+	saved []*domainportfolio.Valuation
+}
+
+func (m *valuationHistoryRepo) CreateValuation(ctx context.Context, v *domainportfolio.Valuation) error {
+	m.saved = append(m.saved, v)
+	return nil
+}
+
+func (m *valuationHistoryRepo) GetValuationHistory(ctx context.Context, patentID string, limit int) ([]*domainportfolio.Valuation, error) {
+	if limit < len(m.saved) {
+		return m.saved[:limit], nil
+	}
+	return m.saved, nil
+}
+
+func (m *valuationHistoryRepo) GetValuationsByPortfolio(ctx context.Context, portfolioID string) ([]*domainportfolio.Valuation, error) {
+	return m.saved, nil
+}
+
+func TestValuationAssessmentRepository_RoundTrip(t *testing.T) {
+	repo := &valuationHistoryRepo{}
+	store := NewValuationAssessmentRepository(repo)
+	ctx := context.Background()
+
+	assessedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []string{"a1", "a2"} {
+		err := store.Save(ctx, &AssessmentRecord{
+			ID:           id,
+			PatentID:     "p1",
+			PortfolioID:  "pf1",
+			OverallScore: 82,
+			Tier:         TierA,
+			DimensionScores: map[AssessmentDimension]float64{
+				DimensionTechnicalValue: 90,
+				DimensionLegalValue:     75,
+			},
+			AssessedAt:   assessedAt,
+			AssessorType: AssessorHybrid,
+		})
+		if err != nil {
+			t.Fatalf("Save(%s): %v", id, err)
+		}
+	}
+	if v := repo.saved[0]; v.ValuationMethod != "assessment:hybrid" || v.PortfolioID == nil || *v.PortfolioID != "pf1" {
+		t.Errorf("unexpected stored valuation %+v", v)
+	}
+
+	records, err := store.FindByPatentID(ctx, "p1", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record after the offset, got %d", len(records))
+	}
+	r := records[0]
+	if r.AssessorType != AssessorHybrid || r.OverallScore != 82 || r.DimensionScores[DimensionTechnicalValue] != 90 ||
+		r.PortfolioID != "pf1" || !r.AssessedAt.Equal(assessedAt) {
+		t.Errorf("unexpected record %+v", r)
+	}
+
+	if records, _ := store.FindByPortfolioID(ctx, "pf1"); len(records) != 2 {
+		t.Errorf("expected two records for the portfolio, got %d", len(records))
+	}
+	if _, err := store.FindByID(ctx, "a1"); err == nil {
+		t.Error("lookup by ID should report that it is unsupported")
+	}
+	if err := store.Save(ctx, nil); err == nil {
+		t.Error("expected error for nil record")
+	}
+}
+
+//Personal.AI order the ending
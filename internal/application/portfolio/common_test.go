@@ -389,6 +389,19 @@ func (m *mockPatentRepoConstellation) SearchByAssigneeName(ctx context.Context,
 	return patents, int64(len(patents)), nil
 }
 
+func (m *mockPatentRepoConstellation) FindByIDs(ctx context.Context, ids []string) ([]*domainpatent.Patent, error) {
+	if m.findErr != nil {
+		return nil, m.findErr
+	}
+	var out []*domainpatent.Patent
+	for _, id := range ids {
+		if p, ok := m.byIDs[id]; ok {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
 func (m *mockPatentRepoConstellation) WithTx(ctx context.Context, fn func(domainpatent.PatentRepository) error) error {
 	return nil
 }
@@ -16,6 +16,8 @@
 //   - White spaces are low-density regions without patent coverage
 //   - Competitor comparison marks overlap zones (competition) and exclusive zones (differentiation)
 //   - Supports filtering by tech domain, filing year, legal status
+//   - Competitors and assignee filters are matched on normalised assignee names; with an
+//     AssigneeGroupSource, a competitor covers its canonical assignee and all subsidiaries
 // - Dependencies: domain/portfolio, domain/molecule, domain/patent, intelligence/molpatent_gnn,
 //   pkg/errors, pkg/types/common
 // - Depended by: interfaces/http/handlers/portfolio_handler
@@ -34,6 +36,7 @@ import (
 
 	"github.com/google/uuid"
 
+	domainassignee "github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
 	domainmol "github.com/turtacn/KeyIP-Intelligence/internal/domain/molecule"
 	domainpatent "github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
 	domainportfolio "github.com/turtacn/KeyIP-Intelligence/internal/domain/portfolio"
@@ -297,16 +300,27 @@ type ConstellationService interface {
 // Service Implementation
 // -----------------------------------------------------------------------
 
+// AssigneeGroupSource finds the patents of the canonical assignee a name
+// resolves to, including its subsidiaries. found is false for unknown names.
+// The assignee application service implements it.
+type AssigneeGroupSource interface {
+	GroupPatentIDs(ctx context.Context, name string, limit int) (ids []string, found bool, err error)
+}
+
+// competitorPatentLimit caps the competitor patents loaded for a comparison.
+const competitorPatentLimit = 1000
+
 type constellationServiceImpl struct {
-	portfolioSvc  domainportfolio.Service
-	portfolioRepo domainportfolio.PortfolioRepository
-	moleculeSvc   domainmol.Service
-	patentRepo    domainpatent.Repository
-	moleculeRepo  domainmol.Repository
-	gnnInference  molpatent_gnn.GNNInferenceService
-	logger        logging.Logger
-	cache         ConstellationCache
-	cacheTTL      time.Duration
+	portfolioSvc   domainportfolio.Service
+	portfolioRepo  domainportfolio.PortfolioRepository
+	moleculeSvc    domainmol.Service
+	patentRepo     domainpatent.Repository
+	moleculeRepo   domainmol.Repository
+	gnnInference   molpatent_gnn.GNNInferenceService
+	assigneeGroups AssigneeGroupSource
+	logger         logging.Logger
+	cache          ConstellationCache
+	cacheTTL       time.Duration
 }
 
 // ConstellationServiceConfig holds configuration for constructing the service.
@@ -317,9 +331,12 @@ type ConstellationServiceConfig struct {
 	PatentRepository    domainpatent.Repository
 	MoleculeRepository  domainmol.Repository
 	GNNInference        molpatent_gnn.GNNInferenceService
-	Logger              logging.Logger
-	Cache               ConstellationCache
-	CacheTTL            time.Duration
+	// AssigneeGroups is optional; without it competitors are matched by
+	// assignee name only.
+	AssigneeGroups AssigneeGroupSource
+	Logger         logging.Logger
+	Cache          ConstellationCache
+	CacheTTL       time.Duration
 }
 
 // NewConstellationService constructs a ConstellationService with all required dependencies.
@@ -350,15 +367,16 @@ func NewConstellationService(cfg ConstellationServiceConfig) (ConstellationServi
 	}
 
 	return &constellationServiceImpl{
-		portfolioSvc:  cfg.PortfolioService,
-		portfolioRepo: cfg.PortfolioRepository,
-		moleculeSvc:   cfg.MoleculeService,
-		patentRepo:    cfg.PatentRepository,
-		moleculeRepo:  cfg.MoleculeRepository,
-		gnnInference:  cfg.GNNInference,
-		logger:        cfg.Logger,
-		cache:         cfg.Cache,
-		cacheTTL:      ttl,
+		portfolioSvc:   cfg.PortfolioService,
+		portfolioRepo:  cfg.PortfolioRepository,
+		moleculeSvc:    cfg.MoleculeService,
+		patentRepo:     cfg.PatentRepository,
+		moleculeRepo:   cfg.MoleculeRepository,
+		gnnInference:   cfg.GNNInference,
+		assigneeGroups: cfg.AssigneeGroups,
+		logger:         cfg.Logger,
+		cache:          cfg.Cache,
+		cacheTTL:       ttl,
 	}, nil
 }
 
@@ -627,13 +645,7 @@ func (s *constellationServiceImpl) CompareWithCompetitor(ctx context.Context, re
 			compPatents = append(compPatents, p)
 		}
 	} else {
-		// Search by assignee name
-		results, _, searchErr := s.patentRepo.SearchByAssigneeName(ctx, req.CompetitorName, 1000, 0)
-		if searchErr != nil {
-			s.logger.Warn("failed to search competitor patents by assignee name", logging.Err(searchErr))
-		} else {
-			compPatents = results
-		}
+		compPatents = s.loadCompetitorPatents(ctx, req.CompetitorName)
 	}
 
 	// Filter by tech domains if specified.
@@ -936,6 +948,33 @@ func (s *constellationServiceImpl) buildCacheKey(prefix string, req *Constellati
 	return fmt.Sprintf("%s:%s:%x", prefix, req.PortfolioID, hash[:8])
 }
 
+// loadCompetitorPatents loads a competitor's patents through its canonical
+// assignee group when one is known and has resolved patents, so spelling
+// variants and subsidiaries count as one competitor. Otherwise it falls back
+// to a search on the raw assignee name.
+func (s *constellationServiceImpl) loadCompetitorPatents(ctx context.Context, name string) []*domainpatent.Patent {
+	if s.assigneeGroups != nil {
+		ids, found, err := s.assigneeGroups.GroupPatentIDs(ctx, name, competitorPatentLimit)
+		switch {
+		case err != nil:
+			s.logger.Warn("failed to resolve competitor assignee group", logging.String("competitor", name), logging.Err(err))
+		case found && len(ids) > 0:
+			patents, err := s.patentRepo.FindByIDs(ctx, ids)
+			if err == nil {
+				return patents
+			}
+			s.logger.Warn("failed to load competitor group patents", logging.String("competitor", name), logging.Err(err))
+		}
+	}
+
+	results, _, err := s.patentRepo.SearchByAssigneeName(ctx, name, competitorPatentLimit, 0)
+	if err != nil {
+		s.logger.Warn("failed to search competitor patents by assignee name", logging.Err(err))
+		return nil
+	}
+	return results
+}
+
 // applyPatentFilters filters patents based on the provided criteria.
 func (s *constellationServiceImpl) applyPatentFilters(patents []domainpatent.Patent, filters ConstellationFilters) []domainpatent.Patent {
 	if len(filters.TechDomains) == 0 &&
@@ -948,7 +987,10 @@ func (s *constellationServiceImpl) applyPatentFilters(patents []domainpatent.Pat
 
 	techSet := toStringSet(filters.TechDomains)
 	statusSet := toStringSet(filters.LegalStatuses)
-	assigneeSet := toStringSet(filters.Assignees)
+	assigneeSet := make(map[string]struct{}, len(filters.Assignees))
+	for _, a := range filters.Assignees {
+		assigneeSet[domainassignee.Normalize(a)] = struct{}{}
+	}
 
 	filtered := make([]domainpatent.Patent, 0, len(patents))
 	for _, p := range patents {
@@ -981,9 +1023,9 @@ func (s *constellationServiceImpl) applyPatentFilters(patents []domainpatent.Pat
 			}
 		}
 
-		// Filter by assignee.
+		// Filter by assignee, ignoring legal form, case and script variants.
 		if len(assigneeSet) > 0 {
-			if _, ok := assigneeSet[p.GetAssignee()]; !ok {
+			if _, ok := assigneeSet[domainassignee.Normalize(p.GetAssignee())]; !ok {
 				continue
 			}
 		}
@@ -556,6 +556,77 @@ func TestCompareWithCompetitor_BySpecificIDs(t *testing.T) {
 	}
 }
 
+// stubAssigneeGroups resolves names from a fixed table.
+type stubAssigneeGroups struct {
+	groups map[string][]string
+	err    error
+}
+
+func (s *stubAssigneeGroups) GroupPatentIDs(_ context.Context, name string, _ int) ([]string, bool, error) {
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	ids, ok := s.groups[name]
+	return ids, ok, nil
+}
+
+func TestCompareWithCompetitor_ResolvesAssigneeGroup(t *testing.T) {
+	svc, _, patentRepo, _, _, _ := buildConstellationSvc()
+
+	filing := time.Now().AddDate(-1, 0, 0)
+	subsidiary := &domainpatent.Patent{
+		ID:             uuid.MustParse("80000000-0000-0000-0000-000000000004"),
+		PatentNumber:   "CN40000001",
+		Title:          "Subsidiary host material",
+		AssigneeName:   "三星显示有限公司",
+		FilingDate:     &filing,
+		Status:         domainpatent.PatentStatusGranted,
+		IPCCodes:       []string{"C09K"},
+		KeyIPTechCodes: []string{"C09K"},
+	}
+	patentRepo.byIDs[subsidiary.ID.String()] = subsidiary
+	svc.assigneeGroups = &stubAssigneeGroups{groups: map[string][]string{
+		"Samsung Electronics": {subsidiary.ID.String()},
+	}}
+
+	resp, err := svc.CompareWithCompetitor(context.Background(), &CompetitorCompareRequest{
+		PortfolioID:    testConstellationPortfolioID,
+		CompetitorName: "Samsung Electronics",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Summary.TotalCompPatents != 1 {
+		t.Errorf("expected 1 competitor patent from the assignee group, got %d", resp.Summary.TotalCompPatents)
+	}
+}
+
+func TestCompareWithCompetitor_UnknownAssigneeFallsBackToName(t *testing.T) {
+	svc, _, patentRepo, _, _, _ := buildConstellationSvc()
+
+	filing := time.Now().AddDate(-1, 0, 0)
+	patentRepo.byAssignee["NewCo"] = []*domainpatent.Patent{{
+		ID:             uuid.MustParse("80000000-0000-0000-0000-000000000005"),
+		PatentNumber:   "US50000001",
+		AssigneeName:   "NewCo",
+		FilingDate:     &filing,
+		Status:         domainpatent.PatentStatusGranted,
+		KeyIPTechCodes: []string{"C09K"},
+	}}
+	svc.assigneeGroups = &stubAssigneeGroups{groups: map[string][]string{}}
+
+	resp, err := svc.CompareWithCompetitor(context.Background(), &CompetitorCompareRequest{
+		PortfolioID:    testConstellationPortfolioID,
+		CompetitorName: "NewCo",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Summary.TotalCompPatents != 1 {
+		t.Errorf("expected 1 competitor patent from the name search, got %d", resp.Summary.TotalCompPatents)
+	}
+}
+
 // -----------------------------------------------------------------------
 // Tests: GetCoverageHeatmap
 // -----------------------------------------------------------------------
@@ -1105,6 +1176,14 @@ func TestApplyPatentFilters(t *testing.T) {
 	if len(result) == 0 {
 		t.Error("expected at least one patent in year range")
 	}
+
+	// Assignee filter matches normalised names
+	result = svc.applyPatentFilters(patents, ConstellationFilters{
+		Assignees: []string{"TESTCORP INC."},
+	})
+	if len(result) != len(patents) {
+		t.Errorf("expected %d TestCorp patents, got %d", len(patents), len(result))
+	}
 }
 
 // Verify that the type assertions compile
@@ -47,6 +47,7 @@ import (
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
 	domainportfolio "github.com/turtacn/KeyIP-Intelligence/internal/domain/portfolio"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	intcommon "github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 )
 
@@ -1010,6 +1011,9 @@ func (s *valuationServiceImpl) AssessPortfolio(ctx context.Context, req *Portfol
 }
 
 func (s *valuationServiceImpl) AssessPortfolioFull(ctx context.Context, req *PortfolioAssessmentRequest) (*PortfolioAssessmentResponse, error) {
+	// Valuing a portfolio scores every patent in it; those model calls queue
+	// behind requests a user is waiting on.
+	ctx = intcommon.ContextWithPriority(ctx, intcommon.PriorityBatch)
 	start := time.Now()
 	defer func() {
 		s.metrics.ObserveHistogram("valuation_assess_portfolio_duration_seconds", time.Since(start).Seconds(), nil)
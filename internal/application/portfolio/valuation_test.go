@@ -17,6 +17,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
 	domainportfolio "github.com/turtacn/KeyIP-Intelligence/internal/domain/portfolio"
+	intcommon "github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
 	pkgerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 )
@@ -466,9 +467,10 @@ func (m *mockAssessmentRepo) FindByIDs(ctx context.Context, ids []string) ([]*As
 // ---------------------------------------------------------------------------
 
 type mockAIScorer struct {
-	scores map[AssessmentDimension]map[string]float64
-	err    error
-	calls  int32
+	scores     map[AssessmentDimension]map[string]float64
+	err        error
+	calls      int32
+	batchCalls int32 // calls made at batch priority
 }
 
 func newMockAIScorer() *mockAIScorer {
@@ -479,6 +481,9 @@ func newMockAIScorer() *mockAIScorer {
 
 func (m *mockAIScorer) ScorePatent(ctx context.Context, pat *patent.Patent, dim AssessmentDimension) (map[string]float64, error) {
 	atomic.AddInt32(&m.calls, 1)
+	if intcommon.PriorityFromContext(ctx) == intcommon.PriorityBatch {
+		atomic.AddInt32(&m.batchCalls, 1)
+	}
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -1386,6 +1391,36 @@ func TestAssessPortfolio_Success(t *testing.T) {
 	}
 }
 
+func TestAssessPortfolio_ScoresAtBatchPriority(t *testing.T) {
+	patentRepo := newMockPatentRepo()
+	patentRepo.patents["30000000-0000-0000-0000-000000000002"] = makeTestPatent("30000000-0000-0000-0000-000000000002", "Patent One", "granted", 20, 5, 1)
+	aiScorer := newMockAIScorer()
+	aiScorer.err = fmt.Errorf("AI service unavailable")
+	cache := newMockCache()
+	cache.err = fmt.Errorf("cache miss")
+	svc := buildTestService(patentRepo, nil, nil, aiScorer, nil, cache)
+
+	if _, err := svc.AssessPatent(context.Background(), &SinglePatentAssessmentRequest{PatentID: "30000000-0000-0000-0000-000000000002"}); err != nil {
+		t.Fatalf("AssessPatent failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&aiScorer.batchCalls); n != 0 {
+		t.Errorf("single patent assessment made %d batch calls, want interactive", n)
+	}
+
+	atomic.StoreInt32(&aiScorer.calls, 0)
+	_, err := svc.AssessPortfolio(context.Background(), &PortfolioAssessmentRequest{
+		PortfolioID: "PF001",
+		PatentIDs:   []string{"30000000-0000-0000-0000-000000000002"},
+	})
+	if err != nil {
+		t.Fatalf("AssessPortfolio failed: %v", err)
+	}
+	calls, batch := atomic.LoadInt32(&aiScorer.calls), atomic.LoadInt32(&aiScorer.batchCalls)
+	if calls == 0 || batch != calls {
+		t.Errorf("portfolio valuation made %d of %d scorer calls at batch priority", batch, calls)
+	}
+}
+
 func TestAssessPortfolio_FromPortfolioID(t *testing.T) {
 	patentRepo := newMockPatentRepo()
 	patentRepo.patents["30000000-0000-0000-0000-000000000003"] = makeTestPatent("30000000-0000-0000-0000-000000000003", "Portfolio Patent", "granted", 12, 3, 2)
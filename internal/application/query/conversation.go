@@ -0,0 +1,582 @@
+/*
+---
+自然语言查询多轮对话状态。
+
+实现要求:
+* **功能定位**: 为 NLQueryService 维护跨轮次的对话状态，使追问（"其中日本申请的"、"compare those with Samsung's"）能够继承上一轮的实体、结果集与过滤条件。
+* **核心实现**:
+  * ConversationState: 显著实体、过滤条件栈、上一轮意图与结果集引用、待澄清问题、最后活跃时间。
+  * 指代与省略消解: 代词/指示词指向上一轮实体或结果集；缺省实体与意图从上一轮继承。
+  * 澄清: 无法标准化的实体先在上下文中匹配，仍未解决时返回澄清问题，下一轮回答后继续原查询。
+  * 过滤条件叠加与撤销: 追问的约束压栈，同字段后者覆盖前者；撤销弹出最近一层。
+  * 会话过期: 空闲超过 ConversationTTL 的会话重置，不依赖缓存自身的 TTL。
+* **强制约束**: 文件最后一行必须为 `//Personal.AI order the ending`
+---
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	conversationStateKeyPrefix = "conv_state:"
+
+	// MaxContextEntities bounds the salient entities carried between turns.
+	MaxContextEntities = 10
+	// MaxResultReferences bounds the result rows kept for "those"/"其中" references.
+	MaxResultReferences = 20
+	// MaxClarificationSuggestions bounds the options offered per unresolved entity.
+	MaxClarificationSuggestions = 5
+	// maxClarificationReplyRunes separates short clarification replies from new questions.
+	maxClarificationReplyRunes = 40
+)
+
+// FilterFrame is one layer of the filter stack: the constraints a single
+// turn added.
+type FilterFrame struct {
+	Question    string                 `json:"question"`
+	Constraints []RecognizedConstraint `json:"constraints"`
+}
+
+// ResultReference is a row of the previous result set that later turns can
+// refer back to.
+type ResultReference struct {
+	ID   string     `json:"id,omitempty"`
+	Name string     `json:"name"`
+	Type EntityType `json:"type,omitempty"`
+}
+
+// PendingClarification is a query held back until the user identifies its
+// unresolved entities.
+type PendingClarification struct {
+	Question   string             `json:"question"`
+	Intent     QueryIntent        `json:"intent"`
+	Unresolved []UnresolvedEntity `json:"unresolved"`
+}
+
+// ClarificationRequest is returned instead of an answer when entities could
+// not be identified. Reply with the intended names (in order, comma
+// separated), a suggestion number, or NLQueryRequest.Clarifications.
+type ClarificationRequest struct {
+	Question string             `json:"question"`
+	Entities []UnresolvedEntity `json:"entities"`
+}
+
+// ConversationState is the per-conversation memory used to resolve follow-ups.
+type ConversationState struct {
+	ID           string                `json:"id"`
+	Entities     []RecognizedEntity    `json:"entities"`
+	Filters      []FilterFrame         `json:"filters"`
+	LastIntent   *QueryIntent          `json:"last_intent,omitempty"`
+	LastResults  []ResultReference     `json:"last_results"`
+	Pending      *PendingClarification `json:"pending,omitempty"`
+	LastActiveAt time.Time             `json:"last_active_at"`
+}
+
+// ----------------------------------------------------------------------------
+// Persistence & expiry
+// ----------------------------------------------------------------------------
+
+// loadConversationState returns the stored state, or a fresh one when none
+// exists or the session has been idle longer than ConversationTTL. expired
+// reports the latter so the caller can drop stale history too.
+func (s *nlQueryServiceImpl) loadConversationState(ctx context.Context, convID string, now time.Time) (state *ConversationState, expired bool) {
+	var st ConversationState
+	if err := s.cache.Get(ctx, conversationStateKeyPrefix+convID, &st); err != nil || st.ID == "" {
+		return &ConversationState{ID: convID, LastActiveAt: now}, false
+	}
+	if now.Sub(st.LastActiveAt) > ConversationTTL {
+		return &ConversationState{ID: convID, LastActiveAt: now}, true
+	}
+	return &st, false
+}
+
+func (s *nlQueryServiceImpl) saveConversationState(ctx context.Context, st *ConversationState) error {
+	return s.cache.Set(ctx, conversationStateKeyPrefix+st.ID, st, ConversationTTL)
+}
+
+// resetConversationHistory clears the turn history of an expired session.
+func (s *nlQueryServiceImpl) resetConversationHistory(ctx context.Context, convID string) {
+	_ = s.cache.Set(ctx, "conv:"+convID, []ConversationTurn{}, ConversationTTL)
+}
+
+// ----------------------------------------------------------------------------
+// Cue detection
+// ----------------------------------------------------------------------------
+
+var (
+	enAnaphoraRe   = regexp.MustCompile(`(?i)\b(those|these|them|they|their|theirs|it|its|ones|same|above|previous)\b`)
+	enRefinementRe = regexp.MustCompile(`(?i)\b(only|just|now|also|instead|narrow|filter|exclude|among)\b`)
+	enUndoRe       = regexp.MustCompile(`(?i)^\s*(undo|go back|revert|remove the last filter|undo (the )?last( filter)?)\s*[.!]?\s*$`)
+
+	zhAnaphora   = []string{"其中", "这些", "那些", "它们", "他们", "上述", "以上", "前面", "刚才", "该"}
+	zhRefinement = []string{"只", "仅", "再", "进一步", "筛选", "换成", "排除", "另外"}
+	zhUndo       = []string{"撤销", "返回上一步", "取消上一个条件", "去掉上一个条件", "回退"}
+)
+
+func hasAnaphora(question string) bool {
+	return enAnaphoraRe.MatchString(question) || containsAny(question, zhAnaphora)
+}
+
+func hasRefinementCue(question string) bool {
+	return enRefinementRe.MatchString(question) || containsAny(question, zhRefinement)
+}
+
+func isUndoRequest(question string) bool {
+	q := strings.TrimSpace(question)
+	if enUndoRe.MatchString(q) {
+		return true
+	}
+	// Chinese undo commands are short imperatives; avoid matching questions
+	// that merely mention the words.
+	return len([]rune(q)) <= 12 && containsAny(q, zhUndo)
+}
+
+// isAnaphorEntity reports whether the LLM returned a pronoun as an entity.
+func isAnaphorEntity(text string) bool {
+	t := strings.TrimSpace(text)
+	if t == "" {
+		return false
+	}
+	if loc := enAnaphoraRe.FindStringIndex(t); loc != nil && loc[0] == 0 && loc[1] == len(t) {
+		return true
+	}
+	for _, p := range zhAnaphora {
+		if t == p {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// ----------------------------------------------------------------------------
+// Coreference & ellipsis resolution, filter stacking
+// ----------------------------------------------------------------------------
+
+// resolveFollowUp rewrites intent in place using the conversation state and
+// pushes the turn's constraints onto the filter stack. It reports whether
+// the question was treated as a follow-up; a standalone question resets the
+// filter stack instead.
+func (st *ConversationState) resolveFollowUp(question string, intent *QueryIntent) bool {
+	kept := make([]RecognizedEntity, 0, len(intent.Entities))
+	pronoun := false
+	for _, e := range intent.Entities {
+		if isAnaphorEntity(e.Text) {
+			pronoun = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+	anaphora := pronoun || hasAnaphora(question)
+
+	followUp := st.LastIntent != nil &&
+		(anaphora || hasRefinementCue(question) || (len(kept) == 0 && len(intent.Constraints) > 0))
+	if !followUp {
+		st.Filters = nil
+		st.pushFilters(question, intent.Constraints)
+		intent.Entities = kept
+		intent.Constraints = st.effectiveConstraints()
+		return false
+	}
+
+	if intent.IntentType == "" {
+		intent.IntentType = st.LastIntent.IntentType
+	}
+	switch {
+	case len(kept) == 0:
+		// Ellipsis: "now only the ones filed in Japan" keeps the subject.
+		intent.Entities = st.referents(nil)
+	case anaphora:
+		// "compare those with Samsung's": prepend what "those" points at.
+		intent.Entities = mergeEntities(st.referents(entityTypes(kept)), kept)
+	default:
+		intent.Entities = kept
+	}
+	if intent.TimeRange == nil {
+		intent.TimeRange = st.LastIntent.TimeRange
+	}
+	if intent.AggregationType == nil {
+		intent.AggregationType = st.LastIntent.AggregationType
+	}
+
+	st.pushFilters(question, intent.Constraints)
+	intent.Constraints = st.effectiveConstraints()
+	return true
+}
+
+// undo pops the most recent filter frame and returns the previous query with
+// the remaining filters applied.
+func (st *ConversationState) undo() (*QueryIntent, bool) {
+	if st.LastIntent == nil || len(st.Filters) == 0 {
+		return nil, false
+	}
+	st.Filters = st.Filters[:len(st.Filters)-1]
+	intent := *st.LastIntent
+	intent.Entities = append([]RecognizedEntity(nil), st.LastIntent.Entities...)
+	intent.Constraints = st.effectiveConstraints()
+	return &intent, true
+}
+
+func (st *ConversationState) pushFilters(question string, constraints []RecognizedConstraint) {
+	if len(constraints) == 0 {
+		return
+	}
+	st.Filters = append(st.Filters, FilterFrame{
+		Question:    question,
+		Constraints: append([]RecognizedConstraint(nil), constraints...),
+	})
+}
+
+// effectiveConstraints flattens the filter stack. A later constraint on the
+// same field and operator replaces an earlier one ("instead in Korea").
+func (st *ConversationState) effectiveConstraints() []RecognizedConstraint {
+	var out []RecognizedConstraint
+	index := make(map[string]int)
+	for _, frame := range st.Filters {
+		for _, c := range frame.Constraints {
+			key := c.Field + "\x00" + string(c.Operator)
+			if i, ok := index[key]; ok {
+				out[i] = c
+				continue
+			}
+			index[key] = len(out)
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// referents returns what an anaphor or ellipsis refers to, restricted to the
+// given entity types (nil means any). After an aggregation the result rows
+// are the most salient referents; otherwise the entities of earlier turns.
+func (st *ConversationState) referents(types map[EntityType]bool) []RecognizedEntity {
+	fromEntities := make([]RecognizedEntity, 0, len(st.Entities))
+	for _, e := range st.Entities {
+		if types == nil || types[e.Type] {
+			fromEntities = append(fromEntities, e)
+		}
+	}
+	fromResults := make([]RecognizedEntity, 0, len(st.LastResults))
+	for _, r := range st.LastResults {
+		if r.Name == "" || r.Type == "" || (types != nil && !types[r.Type]) {
+			continue
+		}
+		fromResults = append(fromResults, RecognizedEntity{Text: r.Name, Type: r.Type, NormalizedValue: r.Name, Confidence: 1.0})
+	}
+
+	preferResults := st.LastIntent != nil && isAggregateIntent(st.LastIntent.IntentType)
+	if preferResults && len(fromResults) > 0 {
+		return fromResults
+	}
+	if len(fromEntities) > 0 {
+		return fromEntities
+	}
+	return fromResults
+}
+
+// resolveFromContext matches unresolved entities against names already seen
+// in the conversation. A single match resolves the entity; otherwise the
+// candidates become clarification suggestions.
+func (st *ConversationState) resolveFromContext(unresolved []UnresolvedEntity) ([]RecognizedEntity, []UnresolvedEntity) {
+	var resolved []RecognizedEntity
+	var remaining []UnresolvedEntity
+	for _, u := range unresolved {
+		candidates := st.contextNames(u.Type)
+		var matches []string
+		for _, c := range candidates {
+			if namesMatch(c, u.Text) {
+				matches = append(matches, c)
+			}
+		}
+		if len(matches) == 1 {
+			resolved = append(resolved, RecognizedEntity{Text: u.Text, Type: u.Type, NormalizedValue: matches[0], Confidence: 0.7})
+			continue
+		}
+		if len(matches) == 0 {
+			matches = candidates
+		}
+		u.Suggestions = appendUnique(u.Suggestions, matches, MaxClarificationSuggestions)
+		remaining = append(remaining, u)
+	}
+	return resolved, remaining
+}
+
+func (st *ConversationState) contextNames(t EntityType) []string {
+	var names []string
+	for _, e := range st.Entities {
+		if e.Type == t && e.NormalizedValue != "" {
+			names = appendUnique(names, []string{e.NormalizedValue}, 0)
+		}
+	}
+	for _, r := range st.LastResults {
+		if r.Type == t && r.Name != "" {
+			names = appendUnique(names, []string{r.Name}, 0)
+		}
+	}
+	return names
+}
+
+// ----------------------------------------------------------------------------
+// Clarification
+// ----------------------------------------------------------------------------
+
+func buildClarification(unresolved []UnresolvedEntity, lang QueryLanguage) *ClarificationRequest {
+	var b strings.Builder
+	for i, u := range unresolved {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if lang == LangEN {
+			fmt.Fprintf(&b, "I could not identify %q (%s).", u.Text, u.Type)
+			if len(u.Suggestions) > 0 {
+				fmt.Fprintf(&b, " Did you mean: %s?", numberedOptions(u.Suggestions))
+			} else {
+				b.WriteString(" Please give its full name.")
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "未能识别「%s」（%s）。", u.Text, u.Type)
+		if len(u.Suggestions) > 0 {
+			fmt.Fprintf(&b, "您指的是：%s？", numberedOptions(u.Suggestions))
+		} else {
+			b.WriteString("请提供其全称。")
+		}
+	}
+	return &ClarificationRequest{Question: b.String(), Entities: unresolved}
+}
+
+func numberedOptions(options []string) string {
+	parts := make([]string, len(options))
+	for i, o := range options {
+		parts[i] = fmt.Sprintf("%d. %s", i+1, o)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// looksLikeClarificationReply distinguishes "Samsung SDI" or "2" from a new
+// question asked while a clarification is pending.
+func looksLikeClarificationReply(reply string) bool {
+	r := strings.TrimSpace(reply)
+	if r == "" || len([]rune(r)) > maxClarificationReplyRunes {
+		return false
+	}
+	return !strings.ContainsAny(r, "?？")
+}
+
+var clarificationSplitRe = regexp.MustCompile(`[,，、;；]`)
+
+// answer turns the user's reply into entities for the pending query.
+// Structured answers are keyed by the unresolved entity text. Otherwise the
+// reply is split in order, and a lone reply answers a lone entity. Each
+// answer may be a suggestion number, a suggestion, or a new name; new names
+// still need normalisation and have no NormalizedValue.
+func (p *PendingClarification) answer(reply string, answers map[string]string) []RecognizedEntity {
+	parts := clarificationSplitRe.Split(reply, -1)
+	if len(parts) != len(p.Unresolved) {
+		parts = []string{reply}
+	}
+
+	out := make([]RecognizedEntity, 0, len(p.Unresolved))
+	for i, u := range p.Unresolved {
+		choice, ok := answers[u.Text]
+		if !ok && len(parts) == len(p.Unresolved) {
+			choice = parts[i]
+		}
+		choice = strings.TrimSpace(choice)
+		if choice == "" {
+			out = append(out, RecognizedEntity{Text: u.Text, Type: u.Type})
+			continue
+		}
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(u.Suggestions) {
+			choice = u.Suggestions[n-1]
+		}
+		e := RecognizedEntity{Text: choice, Type: u.Type}
+		for _, s := range u.Suggestions {
+			if strings.EqualFold(s, choice) {
+				e.NormalizedValue, e.Confidence = s, 1.0
+				break
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ----------------------------------------------------------------------------
+// Turn bookkeeping
+// ----------------------------------------------------------------------------
+
+// recordTurn remembers the resolved intent and a reference to its results.
+func (st *ConversationState) recordTurn(intent *QueryIntent, results interface{}, now time.Time) {
+	saved := *intent
+	st.LastIntent = &saved
+	st.Entities = mergeEntities(intent.Entities, st.Entities)
+	if len(st.Entities) > MaxContextEntities {
+		st.Entities = st.Entities[:MaxContextEntities]
+	}
+	st.LastResults = resultReferences(results, intent)
+	st.Pending = nil
+	st.LastActiveAt = now
+}
+
+func resultReferences(results interface{}, intent *QueryIntent) []ResultReference {
+	var refs []ResultReference
+	switch r := results.(type) {
+	case *EntitySearchResponse:
+		for _, e := range r.Entities {
+			name, _ := e.Node.Properties["name"].(string)
+			if name == "" {
+				name, _ = e.Node.Properties["title"].(string)
+			}
+			refs = append(refs, ResultReference{ID: e.Node.ID, Name: name, Type: e.Node.Type})
+		}
+	case *AggregationResponse:
+		t := entityTypeForDimension(intent.AggregationType)
+		for _, b := range r.Buckets {
+			refs = append(refs, ResultReference{Name: b.Key, Type: t})
+		}
+	case *RelationTraverseResponse:
+		for _, n := range r.Nodes {
+			name, _ := n.Properties["name"].(string)
+			refs = append(refs, ResultReference{ID: n.ID, Name: name, Type: n.Type})
+		}
+	}
+	if len(refs) > MaxResultReferences {
+		refs = refs[:MaxResultReferences]
+	}
+	return refs
+}
+
+// entityTypeForDimension maps an aggregation dimension to the entity type of
+// its bucket keys; years and countries are not entities.
+func entityTypeForDimension(dim *AggregationDimension) EntityType {
+	if dim == nil {
+		return EntityTypeCompany // buildStructuredQuery defaults to ByAssignee
+	}
+	switch *dim {
+	case ByAssignee:
+		return EntityTypeCompany
+	case ByInventor:
+		return EntityTypeInventor
+	case ByTechDomain:
+		return EntityTypeTechDomain
+	default:
+		return ""
+	}
+}
+
+func isAggregateIntent(t IntentType) bool {
+	return t == IntentAggregation || t == IntentTrendAnalysis || t == IntentComparison
+}
+
+// contextPrompt summarises the state for the intent classifier.
+func (st *ConversationState) contextPrompt() string {
+	if st == nil {
+		return ""
+	}
+	var b strings.Builder
+	if len(st.Entities) > 0 {
+		names := make([]string, 0, len(st.Entities))
+		for _, e := range st.Entities {
+			names = append(names, fmt.Sprintf("%s (%s)", displayName(e), e.Type))
+		}
+		fmt.Fprintf(&b, "Context Entities: %s\n", strings.Join(names, ", "))
+	}
+	if cs := st.effectiveConstraints(); len(cs) > 0 {
+		parts := make([]string, 0, len(cs))
+		for _, c := range cs {
+			parts = append(parts, fmt.Sprintf("%s %s %v", c.Field, c.Operator, c.Value))
+		}
+		fmt.Fprintf(&b, "Active Filters: %s\n", strings.Join(parts, "; "))
+	}
+	return b.String()
+}
+
+// ----------------------------------------------------------------------------
+// Small helpers
+// ----------------------------------------------------------------------------
+
+func displayName(e RecognizedEntity) string {
+	if e.NormalizedValue != "" {
+		return e.NormalizedValue
+	}
+	return e.Text
+}
+
+func entityTypes(entities []RecognizedEntity) map[EntityType]bool {
+	types := make(map[EntityType]bool, len(entities))
+	for _, e := range entities {
+		types[e.Type] = true
+	}
+	return types
+}
+
+// mergeEntities concatenates a and b, dropping later duplicates of the same
+// type and name.
+func mergeEntities(a, b []RecognizedEntity) []RecognizedEntity {
+	seen := make(map[string]bool, len(a)+len(b))
+	out := make([]RecognizedEntity, 0, len(a)+len(b))
+	for _, list := range [][]RecognizedEntity{a, b} {
+		for _, e := range list {
+			key := string(e.Type) + "\x00" + strings.ToLower(displayName(e))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func namesMatch(candidate, text string) bool {
+	c, t := strings.ToLower(strings.TrimSpace(candidate)), strings.ToLower(strings.TrimSpace(text))
+	if c == "" || t == "" {
+		return false
+	}
+	if c == t {
+		return true
+	}
+	if len([]rune(t)) < 2 {
+		return false
+	}
+	return strings.Contains(c, t) || strings.Contains(t, c)
+}
+
+// appendUnique appends values not already present; max > 0 caps the length.
+func appendUnique(dst, values []string, max int) []string {
+	for _, v := range values {
+		if max > 0 && len(dst) >= max {
+			break
+		}
+		dup := false
+		for _, d := range dst {
+			if strings.EqualFold(d, v) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+//Personal.AI order the ending
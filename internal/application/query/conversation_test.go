@@ -0,0 +1,303 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// scriptIntents makes the mock LLM return the given intents in order.
+func scriptIntents(m *nlTestMocks, intents ...QueryIntent) {
+	i := 0
+	m.llm.inferIntentFunc = func(ctx context.Context, prompt string, temp float64) (string, error) {
+		if i >= len(intents) {
+			return buildIntentJSON(IntentEntitySearch, nil, 0.9), nil
+		}
+		b, _ := json.Marshal(intents[i])
+		i++
+		return string(b), nil
+	}
+}
+
+func udcEntity() RecognizedEntity {
+	return RecognizedEntity{Text: "UDC", Type: EntityTypeCompany, NormalizedValue: "Universal Display", Confidence: 1}
+}
+
+func TestConversation_EllipsisFilterStackingAndUndo(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	scriptIntents(m,
+		QueryIntent{IntentType: IntentEntitySearch, Entities: []RecognizedEntity{udcEntity()}},
+		QueryIntent{IntentType: IntentEntitySearch, Constraints: []RecognizedConstraint{{Field: "country", Operator: OpEq, Value: "JP"}}},
+		QueryIntent{IntentType: IntentEntitySearch, Constraints: []RecognizedConstraint{{Field: "legal_status", Operator: OpEq, Value: "granted"}}},
+	)
+	var lastFilters map[string]FilterCondition
+	m.kgSearch.searchEntitiesFunc = func(ctx context.Context, req *EntitySearchRequest) (*EntitySearchResponse, error) {
+		lastFilters = req.Filters
+		return &EntitySearchResponse{}, nil
+	}
+
+	first, err := svc.Query(ctx, &NLQueryRequest{Question: "List UDC patents", Language: LangEN})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	convID := first.ConversationID
+
+	resp, err := svc.Query(ctx, &NLQueryRequest{Question: "now only the ones filed in Japan", ConversationID: convID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.ParsedIntent.Entities) != 1 || resp.ParsedIntent.Entities[0].NormalizedValue != "Universal Display" {
+		t.Errorf("Expected UDC to be inherited, got %+v", resp.ParsedIntent.Entities)
+	}
+	if _, ok := lastFilters["country"]; !ok {
+		t.Errorf("Expected country filter, got %v", lastFilters)
+	}
+
+	resp, _ = svc.Query(ctx, &NLQueryRequest{Question: "also only granted", ConversationID: convID})
+	if len(resp.ActiveFilters) != 2 || len(lastFilters) != 2 {
+		t.Errorf("Expected stacked filters, got %+v", resp.ActiveFilters)
+	}
+
+	resp, err = svc.Query(ctx, &NLQueryRequest{Question: "undo", ConversationID: convID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.ActiveFilters) != 1 || resp.ActiveFilters[0].Field != "country" {
+		t.Errorf("Expected only the country filter after undo, got %+v", resp.ActiveFilters)
+	}
+	if _, ok := lastFilters["legal_status"]; ok {
+		t.Errorf("Expected legal_status filter to be removed, got %v", lastFilters)
+	}
+	if len(m.llm.inferCalls) != 3 {
+		t.Errorf("Undo should not call the LLM, got %d infer calls", len(m.llm.inferCalls))
+	}
+	assertPromptContains(t, m.llm, "infer", 2, "Context Entities: Universal Display", "Active Filters: country Eq JP")
+}
+
+func TestConversation_ComparisonResolvesThose(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	scriptIntents(m,
+		QueryIntent{IntentType: IntentEntitySearch, Entities: []RecognizedEntity{udcEntity()}},
+		QueryIntent{IntentType: IntentComparison, Entities: []RecognizedEntity{
+			{Text: "those", Type: EntityTypeCompany},
+			{Text: "Samsung", Type: EntityTypeCompany, NormalizedValue: "Samsung SDI"},
+		}},
+	)
+	var aggReq *AggregationRequest
+	m.kgSearch.aggregateByDimensionFunc = func(ctx context.Context, req *AggregationRequest) (*AggregationResponse, error) {
+		aggReq = req
+		return &AggregationResponse{}, nil
+	}
+
+	first, _ := svc.Query(ctx, &NLQueryRequest{Question: "UDC blue emitter patents"})
+	resp, err := svc.Query(ctx, &NLQueryRequest{Question: "compare those with Samsung's", ConversationID: first.ConversationID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aggReq == nil {
+		t.Fatalf("Expected comparison to run an aggregation")
+	}
+	want := []string{"Universal Display", "Samsung SDI"}
+	if got := aggReq.Filters["assignee"].Value; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected assignee filter %v, got %v", want, got)
+	}
+	if aggReq.Dimension != ByAssignee || resp.DataType != DataTypeAggregation {
+		t.Errorf("Unexpected dimension %v / data type %v", aggReq.Dimension, resp.DataType)
+	}
+}
+
+func TestConversation_ThemRefersToPreviousResults(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	dim := ByAssignee
+	scriptIntents(m,
+		QueryIntent{IntentType: IntentAggregation, AggregationType: &dim},
+		QueryIntent{IntentType: IntentComparison, Entities: []RecognizedEntity{
+			{Text: "Samsung", Type: EntityTypeCompany, NormalizedValue: "Samsung SDI"},
+		}},
+	)
+	var calls []*AggregationRequest
+	m.kgSearch.aggregateByDimensionFunc = func(ctx context.Context, req *AggregationRequest) (*AggregationResponse, error) {
+		calls = append(calls, req)
+		return &AggregationResponse{Buckets: []AggBucket{{Key: "UDC", Count: 40}, {Key: "Merck", Count: 30}}}, nil
+	}
+
+	first, _ := svc.Query(ctx, &NLQueryRequest{Question: "top assignees in TADF"})
+	if _, err := svc.Query(ctx, &NLQueryRequest{Question: "compare them with Samsung", ConversationID: first.ConversationID}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"UDC", "Merck", "Samsung SDI"}
+	if len(calls) != 2 || !reflect.DeepEqual(calls[1].Filters["assignee"].Value, want) {
+		t.Errorf("Expected comparison of %v, got %+v", want, calls)
+	}
+}
+
+func TestConversation_ClarificationRoundTrip(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	_ = m.cache.Set(ctx, "entity_dict:Company:Samsung SDI", "Samsung SDI Co., Ltd.", EntityCacheTTL)
+	dim := ByYear
+	scriptIntents(m, QueryIntent{
+		IntentType:      IntentTrendAnalysis,
+		AggregationType: &dim,
+		Entities:        []RecognizedEntity{{Text: "三星", Type: EntityTypeCompany}},
+	})
+	aggCalls := 0
+	m.kgSearch.aggregateByDimensionFunc = func(ctx context.Context, req *AggregationRequest) (*AggregationResponse, error) {
+		aggCalls++
+		return &AggregationResponse{}, nil
+	}
+
+	resp, err := svc.Query(ctx, &NLQueryRequest{Question: "三星的申请趋势"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.NeedsClarification || resp.Clarification == nil || aggCalls != 0 {
+		t.Fatalf("Expected a clarification before executing, got %+v (agg calls %d)", resp, aggCalls)
+	}
+	if len(resp.Clarification.Entities) != 1 || resp.Clarification.Entities[0].Text != "三星" {
+		t.Errorf("Unexpected clarification %+v", resp.Clarification)
+	}
+
+	resp, err = svc.Query(ctx, &NLQueryRequest{Question: "Samsung SDI", ConversationID: resp.ConversationID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.NeedsClarification || aggCalls != 1 {
+		t.Fatalf("Expected the held query to run, got %+v (agg calls %d)", resp, aggCalls)
+	}
+	if resp.ParsedIntent.IntentType != IntentTrendAnalysis || resp.ParsedIntent.Entities[0].NormalizedValue != "Samsung SDI Co., Ltd." {
+		t.Errorf("Unexpected resumed intent %+v", resp.ParsedIntent)
+	}
+	if len(m.llm.inferCalls) != 1 {
+		t.Errorf("Clarification reply should not be re-classified, got %d infer calls", len(m.llm.inferCalls))
+	}
+}
+
+func TestConversation_ClarificationSuggestionsFromContext(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	dim := ByAssignee
+	scriptIntents(m,
+		QueryIntent{IntentType: IntentAggregation, AggregationType: &dim},
+		QueryIntent{IntentType: IntentEntitySearch, Entities: []RecognizedEntity{{Text: "Samsung", Type: EntityTypeCompany}}},
+	)
+	m.kgSearch.aggregateByDimensionFunc = func(ctx context.Context, req *AggregationRequest) (*AggregationResponse, error) {
+		return &AggregationResponse{Buckets: []AggBucket{{Key: "Samsung SDI"}, {Key: "Samsung Display"}, {Key: "LG Chem"}}}, nil
+	}
+
+	first, _ := svc.Query(ctx, &NLQueryRequest{Question: "top assignees"})
+	resp, _ := svc.Query(ctx, &NLQueryRequest{Question: "Samsung patents", Language: LangEN, ConversationID: first.ConversationID})
+	if !resp.NeedsClarification {
+		t.Fatalf("Expected an ambiguous name to need clarification")
+	}
+	if got := resp.Clarification.Entities[0].Suggestions; !reflect.DeepEqual(got, []string{"Samsung SDI", "Samsung Display"}) {
+		t.Errorf("Unexpected suggestions %v", got)
+	}
+
+	resp, err := svc.Query(ctx, &NLQueryRequest{Question: "2", ConversationID: first.ConversationID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.NeedsClarification || resp.ParsedIntent.Entities[0].NormalizedValue != "Samsung Display" {
+		t.Errorf("Expected option 2 to resolve to Samsung Display, got %+v", resp.ParsedIntent.Entities)
+	}
+}
+
+func TestConversation_SessionExpiry(t *testing.T) {
+	t.Parallel()
+	svc, m := newTestNLQueryService()
+	ctx := context.Background()
+
+	convID := "conv-stale"
+	_ = m.cache.Set(ctx, conversationStateKeyPrefix+convID, &ConversationState{
+		ID:           convID,
+		Entities:     []RecognizedEntity{udcEntity()},
+		LastIntent:   &QueryIntent{IntentType: IntentEntitySearch},
+		Filters:      []FilterFrame{{Constraints: []RecognizedConstraint{{Field: "country", Operator: OpEq, Value: "JP"}}}},
+		LastActiveAt: time.Now().Add(-2 * ConversationTTL),
+	}, ConversationTTL)
+	_ = m.cache.Set(ctx, "conv:"+convID, []ConversationTurn{{Role: "User", Content: "old question"}}, ConversationTTL)
+
+	resp, err := svc.Query(ctx, &NLQueryRequest{Question: "only granted ones", ConversationID: convID})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !resp.SessionExpired {
+		t.Errorf("Expected the session to be reported as expired")
+	}
+	if len(resp.ParsedIntent.Entities) != 0 || len(resp.ActiveFilters) != 0 {
+		t.Errorf("Expected no inherited context, got %+v", resp.ParsedIntent)
+	}
+	assertPromptNotContains(t, m.llm, "infer", 0, "old question")
+}
+
+func TestConversation_UndoWithoutFilters(t *testing.T) {
+	t.Parallel()
+	svc, _ := newTestNLQueryService()
+
+	_, err := svc.Query(context.Background(), &NLQueryRequest{Question: "撤销", ConversationID: "conv-empty"})
+	assertErrorCode(t, err, errors.ErrCodeValidation)
+}
+
+func TestEffectiveConstraints_LaterFrameOverrides(t *testing.T) {
+	t.Parallel()
+	st := &ConversationState{}
+	st.pushFilters("in Japan", []RecognizedConstraint{{Field: "country", Operator: OpEq, Value: "JP"}})
+	st.pushFilters("granted", []RecognizedConstraint{{Field: "legal_status", Operator: OpEq, Value: "granted"}})
+	st.pushFilters("Korea instead", []RecognizedConstraint{{Field: "country", Operator: OpEq, Value: "KR"}})
+
+	got := st.effectiveConstraints()
+	if len(got) != 2 || got[0].Value != "KR" || got[1].Field != "legal_status" {
+		t.Errorf("Unexpected effective constraints %+v", got)
+	}
+}
+
+func TestCueDetection(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		question          string
+		anaphora, refine  bool
+		undo, anaphorOnly bool
+	}{
+		{question: "compare those with Samsung's", anaphora: true},
+		{question: "now only the ones filed in Japan", anaphora: true, refine: true},
+		{question: "其中日本申请的有多少", anaphora: true, refine: false},
+		{question: "只看已授权的", refine: true},
+		{question: "undo", undo: true},
+		{question: "撤销", undo: true},
+		{question: "List OLED patents by UDC"},
+	}
+	for _, tc := range cases {
+		if got := hasAnaphora(tc.question); got != tc.anaphora {
+			t.Errorf("hasAnaphora(%q) = %v", tc.question, got)
+		}
+		if got := hasRefinementCue(tc.question); got != tc.refine {
+			t.Errorf("hasRefinementCue(%q) = %v", tc.question, got)
+		}
+		if got := isUndoRequest(tc.question); got != tc.undo {
+			t.Errorf("isUndoRequest(%q) = %v", tc.question, got)
+		}
+	}
+	if !isAnaphorEntity("those") || !isAnaphorEntity("它们") || isAnaphorEntity("those patents") {
+		t.Errorf("isAnaphorEntity misclassified a pronoun")
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,796 @@
+/*
+---
+自然语言查询意图到 Cypher 的编译器。
+
+实现要求:
+* **功能定位**: 将 QueryIntent（意图、实体、关系、约束、时间范围）编译为参数化 Cypher，突破 buildStructuredQuery 的固定查询形态，由只读会话直接在知识图谱上执行。
+* **核心实现**:
+  * GraphSchema: 声明节点标签、关系类型及其属性；DefaultGraphSchema 与 docs/architecture.md 的图模型一致。
+  * CypherCompiler.Compile: 按意图生成查询，实体值与约束值一律走参数，标签、关系类型、属性名只取自 Schema。
+  * CypherCompiler.Validate: 拒绝写操作与过程调用、无上界或超出跳数上限的变长路径、Schema 之外的标签/关系/属性，并要求以不超过行数上限的 LIMIT 结尾；同样适用于 LLM 生成的 Cypher。
+  * 行数与超时上限随 CompiledCypher 交给 CypherRunner，在只读会话中执行。
+* **强制约束**: 文件最后一行必须为 `//Personal.AI order the ending`
+---
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+const (
+	DefaultCypherRowLimit = DefaultPaginationLimit
+	MaxCypherRowLimit     = MaxPaginationLimit
+	MaxCypherHops         = MaxGraphSearchDepth
+	DefaultCypherTimeout  = 10 * time.Second
+
+	relationQueryHops = 2
+	pathFindingHops   = 3
+)
+
+// ============================================================================
+// Graph Schema
+// ============================================================================
+
+// NodeSchema declares a node label. Key is the property matched against
+// RecognizedEntity.NormalizedValue.
+type NodeSchema struct {
+	Label      string
+	Key        string
+	Properties []string
+}
+
+// RelationshipSchema declares a relationship type between two labels.
+type RelationshipSchema struct {
+	Type       string
+	From       string
+	To         string
+	Properties []string
+}
+
+// GraphSchema is the whitelist compiled and validated Cypher is checked against.
+type GraphSchema struct {
+	nodes    map[string]map[string]bool
+	keys     map[string]string
+	rels     []RelationshipSchema
+	relProps map[string]map[string]bool
+}
+
+func NewGraphSchema(nodes []NodeSchema, rels []RelationshipSchema) *GraphSchema {
+	s := &GraphSchema{
+		nodes:    make(map[string]map[string]bool, len(nodes)),
+		keys:     make(map[string]string, len(nodes)),
+		rels:     rels,
+		relProps: make(map[string]map[string]bool, len(rels)),
+	}
+	for _, n := range nodes {
+		s.nodes[n.Label] = stringSet(n.Properties)
+		s.nodes[n.Label][n.Key] = true
+		s.keys[n.Label] = n.Key
+	}
+	for _, r := range rels {
+		props := s.relProps[r.Type]
+		if props == nil {
+			props = make(map[string]bool)
+			s.relProps[r.Type] = props
+		}
+		for _, p := range r.Properties {
+			props[p] = true
+		}
+	}
+	return s
+}
+
+// DefaultGraphSchema returns the knowledge graph model of docs/architecture.md.
+func DefaultGraphSchema() *GraphSchema {
+	return NewGraphSchema(
+		[]NodeSchema{
+			{Label: "Patent", Key: "patent_number", Properties: []string{
+				"id", "title", "abstract", "filing_date", "publication_date", "grant_date", "expiry_date",
+				"legal_status", "jurisdiction", "assignee", "inventors", "ipc_codes", "cpc_codes",
+				"pagerank", "hub_score", "authority_score", "citation_half_life", "tech_cycle_time",
+			}},
+			{Label: "Molecule", Key: "name", Properties: []string{
+				"id", "smiles", "canonical_smiles", "inchi", "inchi_key", "molecular_formula", "molecular_weight",
+			}},
+			{Label: "Claim", Key: "id", Properties: []string{
+				"claim_number", "claim_type", "claim_text", "parent_claim_number", "is_independent",
+			}},
+			{Label: "MaterialProperty", Key: "property_type", Properties: []string{
+				"value", "unit", "measurement_method", "device_structure", "test_conditions",
+			}},
+			{Label: "Assignee", Key: "name", Properties: []string{"country", "type", "aliases"}},
+			{Label: "Inventor", Key: "name", Properties: []string{"affiliations"}},
+			{Label: "PatentFamily", Key: "family_id", Properties: []string{"family_type"}},
+			{Label: "IPCClass", Key: "code", Properties: []string{"description", "level"}},
+		},
+		[]RelationshipSchema{
+			{Type: "CONTAINS_MOLECULE", From: "Patent", To: "Molecule"},
+			{Type: "HAS_CLAIM", From: "Patent", To: "Claim"},
+			{Type: "COVERS_MOLECULE", From: "Claim", To: "Molecule", Properties: []string{"coverage_type"}},
+			{Type: "CITES", From: "Patent", To: "Patent"},
+			{Type: "MEMBER_OF", From: "Patent", To: "PatentFamily"},
+			{Type: "ASSIGNED_TO", From: "Patent", To: "Assignee"},
+			{Type: "INVENTED_BY", From: "Patent", To: "Inventor"},
+			{Type: "CLASSIFIED_AS", From: "Patent", To: "IPCClass"},
+			{Type: "HAS_PROPERTY", From: "Molecule", To: "MaterialProperty"},
+			{Type: "STRUCTURALLY_SIMILAR", From: "Molecule", To: "Molecule", Properties: []string{"similarity"}},
+			{Type: "SUBSTRUCTURE_OF", From: "Molecule", To: "Molecule"},
+		},
+	)
+}
+
+func (s *GraphSchema) HasLabel(label string) bool {
+	_, ok := s.nodes[label]
+	return ok
+}
+
+func (s *GraphSchema) HasRelationship(relType string) bool {
+	_, ok := s.relProps[relType]
+	return ok
+}
+
+func (s *GraphSchema) HasProperty(label, prop string) bool {
+	return s.nodes[label][prop]
+}
+
+func (s *GraphSchema) HasRelationshipProperty(relType, prop string) bool {
+	return s.relProps[relType][prop]
+}
+
+// KeyProperty returns the property that identifies nodes of label.
+func (s *GraphSchema) KeyProperty(label string) string {
+	return s.keys[label]
+}
+
+// connection returns the first declared relationship between from and to
+// and whether it points from -> to.
+func (s *GraphSchema) connection(from, to string) (RelationshipSchema, bool, bool) {
+	for _, r := range s.rels {
+		if r.From == from && r.To == to {
+			return r, true, true
+		}
+	}
+	for _, r := range s.rels {
+		if r.From == to && r.To == from {
+			return r, false, true
+		}
+	}
+	return RelationshipSchema{}, false, false
+}
+
+// entityLabels maps recognised entity types onto node labels.
+var entityLabels = map[EntityType]string{
+	EntityTypePatent:     "Patent",
+	EntityTypeMolecule:   "Molecule",
+	EntityTypeCompany:    "Assignee",
+	EntityTypeInventor:   "Inventor",
+	EntityTypeTechDomain: "IPCClass",
+	EntityTypeClaim:      "Claim",
+	EntityTypeProperty:   "MaterialProperty",
+}
+
+// relationTypes maps recognised relations onto relationship types. Relations
+// that are inferred rather than stored (CompetesWith, PotentialInfringement,
+// DesignAround) have no graph counterpart.
+var relationTypes = map[RelationType]string{
+	RelationContainsMolecule: "CONTAINS_MOLECULE",
+	RelationClaimsStructure:  "COVERS_MOLECULE",
+	RelationSimilarTo:        "STRUCTURALLY_SIMILAR",
+	RelationCites:            "CITES",
+	RelationOwnedBy:          "ASSIGNED_TO",
+	RelationInventedBy:       "INVENTED_BY",
+	RelationHasProperty:      "HAS_PROPERTY",
+}
+
+// constraintFieldAliases maps constraint fields used by the NL pipeline onto
+// schema property names.
+var constraintFieldAliases = map[string]string{
+	"country": "jurisdiction",
+}
+
+var dateProperties = map[string]bool{
+	"filing_date":      true,
+	"publication_date": true,
+	"grant_date":       true,
+	"expiry_date":      true,
+}
+
+// cypherDimension describes how patents are grouped for an aggregation.
+// Dimensions without a label group on a Patent property.
+type cypherDimension struct {
+	rel     string
+	label   string
+	keyExpr string
+}
+
+var cypherDimensions = map[AggregationDimension]cypherDimension{
+	ByAssignee:   {rel: "ASSIGNED_TO", label: "Assignee", keyExpr: "g.name"},
+	ByInventor:   {rel: "INVENTED_BY", label: "Inventor", keyExpr: "g.name"},
+	ByTechDomain: {rel: "CLASSIFIED_AS", label: "IPCClass", keyExpr: "g.code"},
+	ByCountry:    {keyExpr: "p.jurisdiction"},
+	ByYear:       {keyExpr: "p.filing_date.year"},
+}
+
+// ============================================================================
+// Compiler
+// ============================================================================
+
+// CompiledCypher is a validated, parameterised read query together with the
+// limits it must be executed under.
+type CompiledCypher struct {
+	Query    string                 `json:"query"`
+	Params   map[string]interface{} `json:"params"`
+	RowLimit int                    `json:"row_limit"`
+	Timeout  time.Duration          `json:"timeout"`
+}
+
+// CypherRunner executes Cypher in a read-only session, returning at most
+// maxRows rows and aborting after timeout.
+type CypherRunner interface {
+	RunReadOnly(ctx context.Context, query string, params map[string]interface{}, maxRows int, timeout time.Duration) ([]map[string]interface{}, error)
+}
+
+type CypherCompilerConfig struct {
+	MaxRows int
+	MaxHops int
+	Timeout time.Duration
+}
+
+type CypherCompiler struct {
+	schema  *GraphSchema
+	maxRows int
+	maxHops int
+	timeout time.Duration
+}
+
+// NewCypherCompiler creates a compiler for schema; a nil schema uses
+// DefaultGraphSchema and zero limits use the package defaults.
+func NewCypherCompiler(schema *GraphSchema, cfg CypherCompilerConfig) *CypherCompiler {
+	if schema == nil {
+		schema = DefaultGraphSchema()
+	}
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = MaxCypherRowLimit
+	}
+	if cfg.MaxHops <= 0 {
+		cfg.MaxHops = MaxCypherHops
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultCypherTimeout
+	}
+	return &CypherCompiler{schema: schema, maxRows: cfg.MaxRows, maxHops: cfg.MaxHops, timeout: cfg.Timeout}
+}
+
+// Compile turns intent into a parameterised read query returning at most
+// limit rows (DefaultCypherRowLimit when limit <= 0, capped at MaxRows).
+// The result has passed Validate.
+func (c *CypherCompiler) Compile(intent QueryIntent, limit int) (*CompiledCypher, error) {
+	if limit <= 0 {
+		limit = DefaultCypherRowLimit
+	}
+	if limit > c.maxRows {
+		limit = c.maxRows
+	}
+
+	b := &cypherBuilder{schema: c.schema, params: make(map[string]interface{})}
+	var err error
+	switch intent.IntentType {
+	case IntentEntitySearch:
+		err = c.compileEntitySearch(b, intent)
+	case IntentRelationQuery:
+		err = c.compileRelationQuery(b, intent)
+	case IntentPathFinding:
+		err = c.compilePathFinding(b, intent)
+	case IntentAggregation, IntentTrendAnalysis:
+		err = c.compileAggregation(b, intent)
+	case IntentComparison:
+		err = c.compileComparison(b, intent)
+	case IntentSimilarity:
+		err = c.compileSimilarity(b, intent)
+	default:
+		err = errors.Newf(errors.ErrCodeValidation, "cannot compile intent %q to Cypher", intent.IntentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &CompiledCypher{Query: b.render(limit), Params: b.params, RowLimit: limit, Timeout: c.timeout}
+	if err := c.Validate(compiled.Query, compiled.Params); err != nil {
+		return nil, err
+	}
+	return compiled, nil
+}
+
+func (c *CypherCompiler) compileEntitySearch(b *cypherBuilder, intent QueryIntent) error {
+	target := "Patent"
+	if len(intent.Entities) > 0 {
+		l, err := labelFor(intent.Entities[0].Type)
+		if err != nil {
+			return err
+		}
+		target = l
+	}
+	b.match("(n:%s)", target)
+	if err := b.joinEntities("n", target, intent.Entities); err != nil {
+		return err
+	}
+	if err := b.filter("n", target, intent.Constraints, intent.TimeRange); err != nil {
+		return err
+	}
+	b.ret = "RETURN DISTINCT n"
+	return nil
+}
+
+func (c *CypherCompiler) compileRelationQuery(b *cypherBuilder, intent QueryIntent) error {
+	if len(intent.Entities) == 0 {
+		return errors.NewValidation("relation query needs a start entity")
+	}
+	start := intent.Entities[0]
+	startLabel, err := labelFor(start.Type)
+	if err != nil {
+		return err
+	}
+	types, err := relTypesFor(intent.Relations)
+	if err != nil {
+		return err
+	}
+
+	end, endLabel := "(m)", ""
+	if len(intent.Entities) > 1 {
+		if endLabel, err = labelFor(intent.Entities[1].Type); err != nil {
+			return err
+		}
+		end = fmt.Sprintf("(m:%s)", endLabel)
+	}
+	b.match("(s:%s {%s: %s})-[%s*1..%d]-%s",
+		startLabel, c.schema.KeyProperty(startLabel), b.param(displayName(start)), types, relationQueryHops, end)
+
+	if endLabel != "" {
+		if v := intent.Entities[1].NormalizedValue; v != "" {
+			b.where = append(b.where, fmt.Sprintf("m.%s = %s", c.schema.KeyProperty(endLabel), b.param(v)))
+		}
+		if err := b.filter("m", endLabel, intent.Constraints, intent.TimeRange); err != nil {
+			return err
+		}
+	} else if len(intent.Constraints) > 0 {
+		return errors.NewValidation("constraints on a relation query need a target entity type")
+	}
+	b.ret = "RETURN DISTINCT m"
+	return nil
+}
+
+func (c *CypherCompiler) compilePathFinding(b *cypherBuilder, intent QueryIntent) error {
+	if len(intent.Entities) < 2 {
+		return errors.NewValidation("path finding needs a source and a target entity")
+	}
+	src, dst := intent.Entities[0], intent.Entities[1]
+	srcLabel, err := labelFor(src.Type)
+	if err != nil {
+		return err
+	}
+	dstLabel, err := labelFor(dst.Type)
+	if err != nil {
+		return err
+	}
+	types, err := relTypesFor(intent.Relations)
+	if err != nil {
+		return err
+	}
+	b.match("(a:%s {%s: %s}), (b:%s {%s: %s})",
+		srcLabel, c.schema.KeyProperty(srcLabel), b.param(displayName(src)),
+		dstLabel, c.schema.KeyProperty(dstLabel), b.param(displayName(dst)))
+	b.match("path = shortestPath((a)-[%s*1..%d]-(b))", types, pathFindingHops)
+	b.ret = "RETURN path"
+	return nil
+}
+
+func (c *CypherCompiler) compileAggregation(b *cypherBuilder, intent QueryIntent) error {
+	dim := ByAssignee
+	if intent.IntentType == IntentTrendAnalysis {
+		dim = ByYear
+	}
+	if intent.AggregationType != nil {
+		dim = *intent.AggregationType
+	}
+	return c.aggregate(b, intent, dim, intent.Entities, nil)
+}
+
+func (c *CypherCompiler) compileComparison(b *cypherBuilder, intent QueryIntent) error {
+	if len(intent.Entities) < 2 {
+		return errors.NewValidation("Comparison needs at least two entities")
+	}
+	_, dim := comparisonDimension(intent.Entities[0].Type)
+	var compared, others []RecognizedEntity
+	for _, e := range intent.Entities {
+		if e.Type == intent.Entities[0].Type {
+			compared = append(compared, e)
+		} else {
+			others = append(others, e)
+		}
+	}
+	return c.aggregate(b, intent, dim, others, compared)
+}
+
+// aggregate counts patents per dimension bucket. Entities are joined as
+// filters; compared restricts the buckets to the named entities.
+func (c *CypherCompiler) aggregate(b *cypherBuilder, intent QueryIntent, dim AggregationDimension, entities, compared []RecognizedEntity) error {
+	spec, ok := cypherDimensions[dim]
+	if !ok {
+		return errors.Newf(errors.ErrCodeValidation, "unsupported aggregation dimension %q", dim)
+	}
+	b.match("(p:Patent)")
+	if spec.label != "" {
+		b.match("(p)-[:%s]->(g:%s)", spec.rel, spec.label)
+	}
+	if len(compared) > 0 {
+		names := make([]string, 0, len(compared))
+		for _, e := range compared {
+			names = append(names, displayName(e))
+		}
+		b.where = append(b.where, fmt.Sprintf("g.%s IN %s", c.schema.KeyProperty(spec.label), b.param(names)))
+	}
+	if err := b.joinEntities("p", "Patent", entities); err != nil {
+		return err
+	}
+	if err := b.filter("p", "Patent", intent.Constraints, intent.TimeRange); err != nil {
+		return err
+	}
+
+	b.ret = fmt.Sprintf("RETURN %s AS key, count(DISTINCT p) AS total", spec.keyExpr)
+	b.order = "ORDER BY total DESC"
+	if dim == ByYear {
+		b.order = "ORDER BY key"
+	}
+	return nil
+}
+
+func (c *CypherCompiler) compileSimilarity(b *cypherBuilder, intent QueryIntent) error {
+	if len(intent.Entities) == 0 || intent.Entities[0].Type != EntityTypeMolecule {
+		return errors.NewValidation("similarity search needs a molecule entity")
+	}
+	b.match("(m:Molecule {%s: %s})-[r:STRUCTURALLY_SIMILAR]-(s:Molecule)",
+		c.schema.KeyProperty("Molecule"), b.param(displayName(intent.Entities[0])))
+	if err := b.filter("s", "Molecule", intent.Constraints, nil); err != nil {
+		return err
+	}
+	b.ret = "RETURN s, r.similarity AS similarity"
+	b.order = "ORDER BY similarity DESC"
+	return nil
+}
+
+func labelFor(t EntityType) (string, error) {
+	l, ok := entityLabels[t]
+	if !ok {
+		return "", errors.Newf(errors.ErrCodeValidation, "entity type %q has no graph label", t)
+	}
+	return l, nil
+}
+
+// relTypesFor renders the type part of a relationship pattern, e.g.
+// ":CITES|ASSIGNED_TO", or "" for any type.
+func relTypesFor(relations []RecognizedRelation) (string, error) {
+	var types []string
+	seen := make(map[string]bool)
+	for _, r := range relations {
+		t, ok := relationTypes[r.Type]
+		if !ok {
+			return "", errors.Newf(errors.ErrCodeValidation, "relation %q has no counterpart in the graph schema", r.Type)
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return "", nil
+	}
+	return ":" + strings.Join(types, "|"), nil
+}
+
+// cypherBuilder accumulates the clauses of one query. Every value goes
+// through param; only schema names are interpolated.
+type cypherBuilder struct {
+	schema  *GraphSchema
+	matches []string
+	where   []string
+	ret     string
+	order   string
+	params  map[string]interface{}
+}
+
+func (b *cypherBuilder) param(v interface{}) string {
+	name := "p" + strconv.Itoa(len(b.params))
+	b.params[name] = v
+	return "$" + name
+}
+
+func (b *cypherBuilder) match(format string, args ...interface{}) {
+	b.matches = append(b.matches, "MATCH "+fmt.Sprintf(format, args...))
+}
+
+// joinEntities restricts v (a node of label) to the normalised entities.
+// Entities of the same label match v's key; others are joined through the
+// schema relationship between the two labels. Several entities of one label
+// are alternatives; entities without a normalised value are skipped.
+func (b *cypherBuilder) joinEntities(v, label string, entities []RecognizedEntity) error {
+	var order []string
+	values := make(map[string][]string)
+	for _, e := range entities {
+		if e.NormalizedValue == "" {
+			continue
+		}
+		l, err := labelFor(e.Type)
+		if err != nil {
+			return err
+		}
+		if _, ok := values[l]; !ok {
+			order = append(order, l)
+		}
+		values[l] = append(values[l], e.NormalizedValue)
+	}
+
+	for i, l := range order {
+		target := v
+		if l != label {
+			rel, outgoing, ok := b.schema.connection(label, l)
+			if !ok {
+				return errors.Newf(errors.ErrCodeValidation, "no relationship connects %s and %s", label, l)
+			}
+			target = "e" + strconv.Itoa(i)
+			if outgoing {
+				b.match("(%s)-[:%s]->(%s:%s)", v, rel.Type, target, l)
+			} else {
+				b.match("(%s)<-[:%s]-(%s:%s)", v, rel.Type, target, l)
+			}
+		}
+		key := b.schema.KeyProperty(l)
+		if vals := values[l]; len(vals) == 1 {
+			b.where = append(b.where, fmt.Sprintf("%s.%s = %s", target, key, b.param(vals[0])))
+		} else {
+			b.where = append(b.where, fmt.Sprintf("%s.%s IN %s", target, key, b.param(vals)))
+		}
+	}
+	return nil
+}
+
+var cypherOperators = map[FilterOperator]string{
+	OpEq:         "=",
+	OpNeq:        "<>",
+	OpGt:         ">",
+	OpGte:        ">=",
+	OpLt:         "<",
+	OpLte:        "<=",
+	OpIn:         "IN",
+	OpContains:   "CONTAINS",
+	OpStartsWith: "STARTS WITH",
+}
+
+// filter adds the constraints and the filing-date range on v.
+func (b *cypherBuilder) filter(v, label string, constraints []RecognizedConstraint, tr *common.TimeRange) error {
+	for _, c := range constraints {
+		prop := c.Field
+		if !b.schema.HasProperty(label, prop) {
+			alias, ok := constraintFieldAliases[prop]
+			if !ok || !b.schema.HasProperty(label, alias) {
+				return errors.Newf(errors.ErrCodeValidation, "filter field %q is not a property of %s", c.Field, label)
+			}
+			prop = alias
+		}
+		op, ok := cypherOperators[c.Operator]
+		if !ok {
+			return errors.Newf(errors.ErrCodeValidation, "unsupported filter operator %q", c.Operator)
+		}
+		p := b.param(c.Value)
+		if dateProperties[prop] && c.Operator != OpIn {
+			p = "date(" + p + ")"
+		}
+		b.where = append(b.where, fmt.Sprintf("%s.%s %s %s", v, prop, op, p))
+	}
+
+	if tr != nil && b.schema.HasProperty(label, "filing_date") {
+		from, to := time.Time(tr.From), time.Time(tr.To)
+		if !from.IsZero() {
+			b.where = append(b.where, fmt.Sprintf("%s.filing_date >= date(%s)", v, b.param(from.Format("2006-01-02"))))
+		}
+		if !to.IsZero() {
+			b.where = append(b.where, fmt.Sprintf("%s.filing_date <= date(%s)", v, b.param(to.Format("2006-01-02"))))
+		}
+	}
+	return nil
+}
+
+func (b *cypherBuilder) render(limit int) string {
+	b.params["limit"] = limit
+	lines := append([]string{}, b.matches...)
+	if len(b.where) > 0 {
+		lines = append(lines, "WHERE "+strings.Join(b.where, " AND "))
+	}
+	lines = append(lines, b.ret)
+	if b.order != "" {
+		lines = append(lines, b.order)
+	}
+	lines = append(lines, "LIMIT $limit")
+	return strings.Join(lines, "\n")
+}
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+var (
+	cypherStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+	cypherComment       = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	cypherNameRef       = regexp.MustCompile(`[.$]\s*[A-Za-z_]\w*`)
+	cypherWriteClause   = regexp.MustCompile(`(?i)\b(CREATE|MERGE|SET|DELETE|DETACH|REMOVE|DROP|FOREACH|LOAD\s+CSV|CALL)\b`)
+	cypherRelPattern    = regexp.MustCompile(`-\[([^\]]*)\]`)
+	cypherRelHead       = regexp.MustCompile(`^\s*(\w*)\s*(?::\s*([A-Za-z_]\w*(?:\s*\|\s*:?\s*[A-Za-z_]\w*)*))?`)
+	cypherVarLength     = regexp.MustCompile(`\*\s*(\d*)\s*(\.\.)?\s*(\d*)`)
+	cypherNodePattern   = regexp.MustCompile(`\(\s*(\w*)\s*((?::\s*[A-Za-z_]\w*\s*)+)(\{[^}]*\})?`)
+	cypherLabel         = regexp.MustCompile(`:\s*([A-Za-z_]\w*)`)
+	cypherMapKey        = regexp.MustCompile(`([A-Za-z_]\w*)\s*:`)
+	cypherPropertyRef   = regexp.MustCompile(`\b([A-Za-z_]\w*)\.([A-Za-z_]\w*)`)
+	cypherReturn        = regexp.MustCompile(`(?i)\bRETURN\b`)
+	cypherLimit         = regexp.MustCompile(`(?i)\bLIMIT\s+(\$\w+|\d+)`)
+)
+
+// Validate checks that query is a bounded read against the schema: no write
+// clauses or procedure calls, no unbounded or over-long variable-length
+// paths, only declared labels, relationship types and properties, and a
+// final LIMIT of at most MaxRows.
+func (c *CypherCompiler) Validate(query string, params map[string]interface{}) error {
+	if strings.TrimSpace(query) == "" {
+		return errors.NewValidation("empty Cypher query")
+	}
+	if strings.Contains(query, "`") {
+		return errors.NewValidation("quoted identifiers are not allowed in Cypher queries")
+	}
+	text := cypherComment.ReplaceAllString(cypherStringLiteral.ReplaceAllString(query, "''"), " ")
+
+	if m := cypherWriteClause.FindString(cypherNameRef.ReplaceAllString(text, "")); m != "" {
+		return errors.Newf(errors.ErrCodeValidation, "Cypher clause %s is not allowed in read-only queries", strings.ToUpper(m))
+	}
+
+	vars := make(map[string]string)
+	relVars := make(map[string]string)
+	if err := c.validateRelationships(text, relVars); err != nil {
+		return err
+	}
+	if err := c.validateNodes(text, vars); err != nil {
+		return err
+	}
+	for _, idx := range cypherPropertyRef.FindAllStringSubmatchIndex(text, -1) {
+		if idx[0] > 0 && text[idx[0]-1] == '$' {
+			continue
+		}
+		v, prop := text[idx[2]:idx[3]], text[idx[4]:idx[5]]
+		if label, ok := vars[v]; ok && !c.schema.HasProperty(label, prop) {
+			return errors.Newf(errors.ErrCodeValidation, "property %q is not declared for %s", prop, label)
+		}
+		if relType, ok := relVars[v]; ok && !c.schema.HasRelationshipProperty(relType, prop) {
+			return errors.Newf(errors.ErrCodeValidation, "property %q is not declared for %s", prop, relType)
+		}
+	}
+	return c.validateLimit(text, params)
+}
+
+func (c *CypherCompiler) validateRelationships(text string, relVars map[string]string) error {
+	for _, m := range cypherRelPattern.FindAllStringSubmatch(text, -1) {
+		body := m[1]
+		head := cypherRelHead.FindStringSubmatch(body)
+		var types []string
+		for _, t := range strings.Split(head[2], "|") {
+			if t = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), ":")); t != "" {
+				types = append(types, t)
+			}
+		}
+		for _, t := range types {
+			if !c.schema.HasRelationship(t) {
+				return errors.Newf(errors.ErrCodeValidation, "relationship type %s is not in the graph schema", t)
+			}
+		}
+		if head[1] != "" && len(types) == 1 {
+			relVars[head[1]] = types[0]
+		}
+
+		if strings.Contains(body, "*") {
+			vl := cypherVarLength.FindStringSubmatch(body)
+			upper := vl[3]
+			if vl[2] == "" {
+				upper = vl[1]
+			}
+			if upper == "" {
+				return errors.NewValidation("unbounded variable-length paths are not allowed")
+			}
+			if hops, _ := strconv.Atoi(upper); hops > c.maxHops {
+				return errors.Newf(errors.ErrCodeValidation, "variable-length path of %d hops exceeds the limit of %d", hops, c.maxHops)
+			}
+		}
+
+		if i := strings.Index(body, "{"); i >= 0 {
+			if len(types) != 1 {
+				return errors.NewValidation("relationship property maps need exactly one relationship type")
+			}
+			for _, k := range cypherMapKey.FindAllStringSubmatch(body[i:], -1) {
+				if !c.schema.HasRelationshipProperty(types[0], k[1]) {
+					return errors.Newf(errors.ErrCodeValidation, "property %q is not declared for %s", k[1], types[0])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *CypherCompiler) validateNodes(text string, vars map[string]string) error {
+	for _, m := range cypherNodePattern.FindAllStringSubmatch(text, -1) {
+		labels := cypherLabel.FindAllStringSubmatch(m[2], -1)
+		for _, l := range labels {
+			if !c.schema.HasLabel(l[1]) {
+				return errors.Newf(errors.ErrCodeValidation, "node label %s is not in the graph schema", l[1])
+			}
+		}
+		first := labels[0][1]
+		if m[1] != "" {
+			vars[m[1]] = first
+		}
+		for _, k := range cypherMapKey.FindAllStringSubmatch(m[3], -1) {
+			if !c.schema.HasProperty(first, k[1]) {
+				return errors.Newf(errors.ErrCodeValidation, "property %q is not declared for %s", k[1], first)
+			}
+		}
+	}
+	return nil
+}
+
+// validateLimit requires a LIMIT after the last RETURN that resolves to at
+// most MaxRows rows.
+func (c *CypherCompiler) validateLimit(text string, params map[string]interface{}) error {
+	limits := cypherLimit.FindAllStringSubmatchIndex(text, -1)
+	returns := cypherReturn.FindAllStringIndex(text, -1)
+	if len(limits) == 0 || len(returns) == 0 || limits[len(limits)-1][0] < returns[len(returns)-1][0] {
+		return errors.NewValidation("Cypher queries must end with RETURN ... LIMIT")
+	}
+	last := limits[len(limits)-1]
+	arg := text[last[2]:last[3]]
+
+	var n int
+	if strings.HasPrefix(arg, "$") {
+		v, ok := params[arg[1:]]
+		if !ok {
+			return errors.Newf(errors.ErrCodeValidation, "LIMIT parameter %s is not set", arg)
+		}
+		switch x := v.(type) {
+		case int:
+			n = x
+		case int64:
+			n = int(x)
+		default:
+			return errors.Newf(errors.ErrCodeValidation, "LIMIT parameter %s must be an integer", arg)
+		}
+	} else {
+		n, _ = strconv.Atoi(arg)
+	}
+	if n <= 0 || n > c.maxRows {
+		return errors.Newf(errors.ErrCodeValidation, "LIMIT %d is outside 1..%d", n, c.maxRows)
+	}
+	return nil
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,303 @@
+package query
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+func entity(t EntityType, value string) RecognizedEntity {
+	return RecognizedEntity{Text: value, Type: t, NormalizedValue: value, Confidence: 1}
+}
+
+func TestCypherCompiler_CompilesEachIntent(t *testing.T) {
+	t.Parallel()
+	byInventor := ByInventor
+	from := common.Timestamp(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	to := common.Timestamp(time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name   string
+		intent QueryIntent
+		query  string
+		params map[string]interface{}
+	}{
+		{
+			name: "entity search joins company and filters",
+			intent: QueryIntent{
+				IntentType: IntentEntitySearch,
+				Entities:   []RecognizedEntity{{Text: "patents", Type: EntityTypePatent}, entity(EntityTypeCompany, "Universal Display")},
+				Constraints: []RecognizedConstraint{
+					{Field: "country", Operator: OpEq, Value: "JP"},
+					{Field: "publication_date", Operator: OpGte, Value: "2020-01-01"},
+				},
+			},
+			query: "MATCH (n:Patent)\n" +
+				"MATCH (n)-[:ASSIGNED_TO]->(e0:Assignee)\n" +
+				"WHERE e0.name = $p0 AND n.jurisdiction = $p1 AND n.publication_date >= date($p2)\n" +
+				"RETURN DISTINCT n\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "Universal Display", "p1": "JP", "p2": "2020-01-01", "limit": 20},
+		},
+		{
+			name: "relation query with typed target",
+			intent: QueryIntent{
+				IntentType: IntentRelationQuery,
+				Entities:   []RecognizedEntity{entity(EntityTypePatent, "US1234567B2"), {Text: "molecules", Type: EntityTypeMolecule}},
+				Relations:  []RecognizedRelation{{Type: RelationContainsMolecule}},
+			},
+			query: "MATCH (s:Patent {patent_number: $p0})-[:CONTAINS_MOLECULE*1..2]-(m:Molecule)\n" +
+				"RETURN DISTINCT m\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "US1234567B2", "limit": 20},
+		},
+		{
+			name: "path finding between company and molecule",
+			intent: QueryIntent{
+				IntentType: IntentPathFinding,
+				Entities:   []RecognizedEntity{entity(EntityTypeCompany, "Samsung SDI"), entity(EntityTypeMolecule, "Ir(ppy)3")},
+			},
+			query: "MATCH (a:Assignee {name: $p0}), (b:Molecule {name: $p1})\n" +
+				"MATCH path = shortestPath((a)-[*1..3]-(b))\n" +
+				"RETURN path\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "Samsung SDI", "p1": "Ir(ppy)3", "limit": 20},
+		},
+		{
+			name: "aggregation by inventor within a company",
+			intent: QueryIntent{
+				IntentType:      IntentAggregation,
+				Entities:        []RecognizedEntity{entity(EntityTypeCompany, "Universal Display")},
+				AggregationType: &byInventor,
+			},
+			query: "MATCH (p:Patent)\n" +
+				"MATCH (p)-[:INVENTED_BY]->(g:Inventor)\n" +
+				"MATCH (p)-[:ASSIGNED_TO]->(e0:Assignee)\n" +
+				"WHERE e0.name = $p0\n" +
+				"RETURN g.name AS key, count(DISTINCT p) AS total\n" +
+				"ORDER BY total DESC\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "Universal Display", "limit": 20},
+		},
+		{
+			name: "trend analysis defaults to filing year",
+			intent: QueryIntent{
+				IntentType: IntentTrendAnalysis,
+				Entities:   []RecognizedEntity{entity(EntityTypeTechDomain, "C09K11/06")},
+				TimeRange:  &common.TimeRange{From: from, To: to},
+			},
+			query: "MATCH (p:Patent)\n" +
+				"MATCH (p)-[:CLASSIFIED_AS]->(e0:IPCClass)\n" +
+				"WHERE e0.code = $p0 AND p.filing_date >= date($p1) AND p.filing_date <= date($p2)\n" +
+				"RETURN p.filing_date.year AS key, count(DISTINCT p) AS total\n" +
+				"ORDER BY key\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "C09K11/06", "p1": "2018-01-01", "p2": "2022-12-31", "limit": 20},
+		},
+		{
+			name: "comparison restricts buckets to the named companies",
+			intent: QueryIntent{
+				IntentType:  IntentComparison,
+				Entities:    []RecognizedEntity{entity(EntityTypeCompany, "Universal Display"), entity(EntityTypeCompany, "Samsung SDI")},
+				Constraints: []RecognizedConstraint{{Field: "legal_status", Operator: OpEq, Value: "granted"}},
+			},
+			query: "MATCH (p:Patent)\n" +
+				"MATCH (p)-[:ASSIGNED_TO]->(g:Assignee)\n" +
+				"WHERE g.name IN $p0 AND p.legal_status = $p1\n" +
+				"RETURN g.name AS key, count(DISTINCT p) AS total\n" +
+				"ORDER BY total DESC\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": []string{"Universal Display", "Samsung SDI"}, "p1": "granted", "limit": 20},
+		},
+		{
+			name: "similarity over structurally similar molecules",
+			intent: QueryIntent{
+				IntentType:  IntentSimilarity,
+				Entities:    []RecognizedEntity{entity(EntityTypeMolecule, "Ir(ppy)3")},
+				Constraints: []RecognizedConstraint{{Field: "molecular_weight", Operator: OpLt, Value: 700}},
+			},
+			query: "MATCH (m:Molecule {name: $p0})-[r:STRUCTURALLY_SIMILAR]-(s:Molecule)\n" +
+				"WHERE s.molecular_weight < $p1\n" +
+				"RETURN s, r.similarity AS similarity\n" +
+				"ORDER BY similarity DESC\n" +
+				"LIMIT $limit",
+			params: map[string]interface{}{"p0": "Ir(ppy)3", "p1": 700, "limit": 20},
+		},
+	}
+
+	c := NewCypherCompiler(nil, CypherCompilerConfig{})
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			compiled, err := c.Compile(tt.intent, 0)
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if compiled.Query != tt.query {
+				t.Errorf("query mismatch\n got:\n%s\nwant:\n%s", compiled.Query, tt.query)
+			}
+			if !reflect.DeepEqual(compiled.Params, tt.params) {
+				t.Errorf("params = %#v, want %#v", compiled.Params, tt.params)
+			}
+			if compiled.RowLimit != DefaultCypherRowLimit || compiled.Timeout != DefaultCypherTimeout {
+				t.Errorf("limits = %d/%s", compiled.RowLimit, compiled.Timeout)
+			}
+		})
+	}
+}
+
+func TestCypherCompiler_CompileRejectsOffSchemaIntents(t *testing.T) {
+	t.Parallel()
+	c := NewCypherCompiler(nil, CypherCompilerConfig{})
+
+	tests := []struct {
+		name   string
+		intent QueryIntent
+	}{
+		{"unknown filter field", QueryIntent{IntentType: IntentEntitySearch, Constraints: []RecognizedConstraint{{Field: "password", Operator: OpEq, Value: "x"}}}},
+		{"inferred relation", QueryIntent{IntentType: IntentRelationQuery, Entities: []RecognizedEntity{entity(EntityTypeCompany, "UDC")}, Relations: []RecognizedRelation{{Type: RelationCompetesWith}}}},
+		{"unconnected labels", QueryIntent{IntentType: IntentEntitySearch, Entities: []RecognizedEntity{{Type: EntityTypeInventor}, entity(EntityTypeMolecule, "Ir(ppy)3")}}},
+		{"comparison of one", QueryIntent{IntentType: IntentComparison, Entities: []RecognizedEntity{entity(EntityTypeCompany, "UDC")}}},
+		{"similarity without molecule", QueryIntent{IntentType: IntentSimilarity, Entities: []RecognizedEntity{entity(EntityTypePatent, "US1")}}},
+		{"unknown intent", QueryIntent{IntentType: "Delete"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := c.Compile(tt.intent, 0)
+			assertErrorCode(t, err, errors.ErrCodeValidation)
+		})
+	}
+}
+
+func TestCypherCompiler_RowLimitIsCapped(t *testing.T) {
+	t.Parallel()
+	c := NewCypherCompiler(nil, CypherCompilerConfig{MaxRows: 50, Timeout: 2 * time.Second})
+
+	compiled, err := c.Compile(QueryIntent{IntentType: IntentEntitySearch}, 500)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if compiled.RowLimit != 50 || compiled.Params["limit"] != 50 {
+		t.Errorf("row limit = %d (param %v), want 50", compiled.RowLimit, compiled.Params["limit"])
+	}
+	if compiled.Timeout != 2*time.Second {
+		t.Errorf("timeout = %s", compiled.Timeout)
+	}
+}
+
+func TestCypherCompiler_Validate(t *testing.T) {
+	t.Parallel()
+	c := NewCypherCompiler(nil, CypherCompilerConfig{MaxRows: 100})
+	limit := map[string]interface{}{"limit": 10}
+
+	valid := []string{
+		"MATCH (p:Patent)-[:CITES*1..3]->(q:Patent) WHERE p.title CONTAINS 'SET' RETURN q.patent_number LIMIT 10",
+		"MATCH (m:Molecule)-[r:STRUCTURALLY_SIMILAR {similarity: 0.9}]-(s) RETURN s, r.similarity LIMIT $limit",
+		"// CREATE nothing\nMATCH (a:Assignee) WITH a LIMIT 5 RETURN a.name, a.country LIMIT 100",
+		"MATCH (p:Patent)-[:CITES*2]->(q) RETURN q LIMIT 1",
+	}
+	for _, q := range valid {
+		if err := c.Validate(q, limit); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", q, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"create":              "CREATE (p:Patent {patent_number: 'X'}) RETURN p LIMIT 1",
+		"merge":               "MERGE (p:Patent {patent_number: $n}) RETURN p LIMIT 1",
+		"set":                 "MATCH (p:Patent) SET p.title = 'x' RETURN p LIMIT 1",
+		"detach delete":       "MATCH (p:Patent) DETACH DELETE p",
+		"procedure call":      "CALL apoc.periodic.iterate('MATCH (n) RETURN n', 'DELETE n', {}) YIELD batches RETURN batches LIMIT 1",
+		"load csv":            "LOAD CSV FROM 'file:///x.csv' AS row RETURN row LIMIT 1",
+		"unbounded star":      "MATCH (p:Patent)-[:CITES*]->(q) RETURN q LIMIT 10",
+		"open upper bound":    "MATCH (p:Patent)-[:CITES*2..]->(q) RETURN q LIMIT 10",
+		"too many hops":       "MATCH (p:Patent)-[*1..9]-(q) RETURN q LIMIT 10",
+		"unknown label":       "MATCH (u:User) RETURN u LIMIT 10",
+		"unknown rel":         "MATCH (p:Patent)-[:OWNS]->(q) RETURN q LIMIT 10",
+		"unknown property":    "MATCH (p:Patent) RETURN p.secret LIMIT 10",
+		"unknown map key":     "MATCH (p:Patent {secret: 1}) RETURN p LIMIT 10",
+		"unknown rel prop":    "MATCH (p:Patent)-[r:CITES]->(q) RETURN r.weight LIMIT 10",
+		"no limit":            "MATCH (p:Patent) RETURN p",
+		"limit before ret":    "MATCH (p:Patent) WITH p LIMIT 10 RETURN p",
+		"limit too large":     "MATCH (p:Patent) RETURN p LIMIT 1000",
+		"missing limit param": "MATCH (p:Patent) RETURN p LIMIT $rows",
+		"quoted identifier":   "MATCH (p:`Patent`) RETURN p LIMIT 10",
+	}
+	for name, q := range invalid {
+		if err := c.Validate(q, limit); err == nil {
+			t.Errorf("%s: Validate(%q) = nil, want error", name, q)
+		} else if !errors.IsCode(err, errors.ErrCodeValidation) {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
+
+// stubCypherRunner records the last query it was asked to run.
+type stubCypherRunner struct {
+	query   string
+	params  map[string]interface{}
+	maxRows int
+	timeout time.Duration
+	rows    []map[string]interface{}
+}
+
+func (r *stubCypherRunner) RunReadOnly(ctx context.Context, query string, params map[string]interface{}, maxRows int, timeout time.Duration) ([]map[string]interface{}, error) {
+	r.query, r.params, r.maxRows, r.timeout = query, params, maxRows, timeout
+	return r.rows, nil
+}
+
+func TestQuery_CypherExecution(t *testing.T) {
+	t.Parallel()
+	m := &nlTestMocks{
+		llm:       &mockStrategyGPTModel{},
+		kgSearch:  &mockKGSearchService{},
+		simSearch: &mockSimilaritySearchService{},
+		cache:     newLocalMockCache(),
+	}
+	runner := &stubCypherRunner{rows: []map[string]interface{}{{"key": "Universal Display", "total": int64(12)}}}
+	svc := NewNLQueryService(m.llm, m.kgSearch, m.simSearch, &mockPatentRepository{}, &mockMoleculeRepository{},
+		m.cache, &localMockLogger{}, &localMockMetricsCollector{},
+		WithCypherExecution(NewCypherCompiler(nil, CypherCompilerConfig{}), runner))
+
+	scriptIntents(m,
+		QueryIntent{IntentType: IntentComparison, Entities: []RecognizedEntity{udcEntity(), entity(EntityTypeCompany, "Samsung SDI")}},
+		QueryIntent{IntentType: IntentRelationQuery, Entities: []RecognizedEntity{udcEntity()}, Relations: []RecognizedRelation{{Type: RelationDesignAround}}},
+	)
+
+	resp, err := svc.Query(context.Background(), &NLQueryRequest{Question: "Compare UDC and Samsung SDI", MaxResults: 5})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.GeneratedQuery != runner.query || !strings.Contains(runner.query, "g.name IN $p0") {
+		t.Errorf("generated query %q, runner got %q", resp.GeneratedQuery, runner.query)
+	}
+	if runner.maxRows != 5 || runner.params["limit"] != 5 || runner.timeout != DefaultCypherTimeout {
+		t.Errorf("runner limits = %d rows (param %v), %s", runner.maxRows, runner.params["limit"], runner.timeout)
+	}
+	if rows, ok := resp.StructuredData.([]map[string]interface{}); !ok || len(rows) != 1 {
+		t.Errorf("structured data = %#v", resp.StructuredData)
+	}
+
+	// A relation the schema cannot express falls back to the structured path.
+	runner.query = ""
+	resp, err = svc.Query(context.Background(), &NLQueryRequest{Question: "Who designs around UDC?", ConversationID: resp.ConversationID})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if runner.query != "" {
+		t.Errorf("runner should not be used, got %q", runner.query)
+	}
+	if _, ok := resp.StructuredData.(*RelationTraverseResponse); !ok {
+		t.Errorf("structured data = %T, want *RelationTraverseResponse", resp.StructuredData)
+	}
+}
+
+//Personal.AI order the ending
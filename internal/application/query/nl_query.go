@@ -6,6 +6,8 @@
 * **功能定位**: 自然语言查询业务编排层，接收用户以自然语言表达的查询意图，通过 LLM 将其转化为结构化查询，委派给底层服务执行，最后将结构化结果转化为自然语言回答返回给用户。
 * **核心实现**: 完整定义接口、DTO、枚举、结构体、六步管道(Query)、SuggestQuestions、ExplainQuery及辅助方法。
 * **业务逻辑**: Prompt注入检测、LLM重试及温度控制、实体标准化、滑动窗口多轮对话、结果截取等。
+* **多轮对话**: 追问的指代/省略消解、澄清问题、过滤条件叠加与撤销、会话过期见 conversation.go。
+* **Cypher 执行**: 配置 WithCypherExecution 时按意图编译并校验只读 Cypher 直接执行，见 cypher_compiler.go。
 * **强制约束**: 文件最后一行必须为 `//Personal.AI order the ending`
 ---
 */
@@ -93,17 +95,27 @@ type NLQueryRequest struct {
 	UserContext    UserQueryContext `json:"user_context"`
 	ConversationID string           `json:"conversation_id,omitempty"`
 	MaxResults     int              `json:"max_results"`
+	// Undo removes the most recent filter of the conversation and re-runs
+	// the previous query; "undo"/"撤销" in Question does the same.
+	Undo bool `json:"undo,omitempty"`
+	// Clarifications answers a pending ClarificationRequest, keyed by the
+	// unresolved entity text. A free-text Question reply also works.
+	Clarifications map[string]string `json:"clarifications,omitempty"`
 }
 
 type NLQueryResponse struct {
-	Answer         string           `json:"answer"`
-	StructuredData interface{}      `json:"structured_data"`
-	DataType       ResponseDataType `json:"data_type"`
-	Confidence     float64          `json:"confidence"`
-	ParsedIntent   QueryIntent      `json:"parsed_intent"`
-	GeneratedQuery string           `json:"generated_query"`
-	Suggestions    []string         `json:"suggestions"`
-	ConversationID string           `json:"conversation_id"`
+	Answer             string                 `json:"answer"`
+	StructuredData     interface{}            `json:"structured_data"`
+	DataType           ResponseDataType       `json:"data_type"`
+	Confidence         float64                `json:"confidence"`
+	ParsedIntent       QueryIntent            `json:"parsed_intent"`
+	GeneratedQuery     string                 `json:"generated_query"`
+	Suggestions        []string               `json:"suggestions"`
+	ConversationID     string                 `json:"conversation_id"`
+	NeedsClarification bool                   `json:"needs_clarification,omitempty"`
+	Clarification      *ClarificationRequest  `json:"clarification,omitempty"`
+	ActiveFilters      []RecognizedConstraint `json:"active_filters,omitempty"`
+	SessionExpired     bool                   `json:"session_expired,omitempty"`
 }
 
 type SuggestRequest struct {
@@ -231,6 +243,25 @@ type nlQueryServiceImpl struct {
 	cache        Cache
 	logger       Logger
 	metrics      MetricsCollector
+	cypher       *CypherCompiler
+	cypherRunner CypherRunner
+}
+
+// NLQueryOption configures optional NLQueryService behaviour.
+type NLQueryOption func(*nlQueryServiceImpl)
+
+// WithCypherExecution answers queries with Cypher compiled from the intent
+// and run read-only through runner, instead of the fixed KGSearchService
+// query shapes. Intents the compiler rejects use the structured path.
+//
+// No binary builds an NLQueryService yet, so this stays opt-in: whoever
+// constructs the service passes a compiler and the Neo4j
+// repositories.ReadOnlyCypherRunner here.
+func WithCypherExecution(compiler *CypherCompiler, runner CypherRunner) NLQueryOption {
+	return func(s *nlQueryServiceImpl) {
+		s.cypher = compiler
+		s.cypherRunner = runner
+	}
 }
 
 func NewNLQueryService(
@@ -242,8 +273,9 @@ func NewNLQueryService(
 	cache Cache,
 	logger Logger,
 	metrics MetricsCollector,
+	opts ...NLQueryOption,
 ) NLQueryService {
-	return &nlQueryServiceImpl{
+	s := &nlQueryServiceImpl{
 		llm:          llm,
 		kgSearch:     kgSearch,
 		simSearch:    simSearch,
@@ -253,6 +285,10 @@ func NewNLQueryService(
 		logger:       logger,
 		metrics:      metrics,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // ----------------------------------------------------------------------------
@@ -278,32 +314,70 @@ func (s *nlQueryServiceImpl) Query(ctx context.Context, req *NLQueryRequest) (*N
 		convID = s.generateConversationID()
 	}
 
-	// Load Conversation History
+	// Load Conversation State & History; an idle session starts over.
+	state, expired := s.loadConversationState(ctx, convID, startTime)
+	if expired {
+		s.resetConversationHistory(ctx, convID)
+	}
 	history, _ := s.loadConversationHistory(ctx, convID)
 
-	// Step 1: Intent Classification
-	intent, intentConf, err := s.stepIntentClassification(ctx, req.Question, req.Language, history)
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "intent classification failed")
-	}
+	var (
+		intent     *QueryIntent
+		intentConf float64
+		unresolved []UnresolvedEntity
+	)
+	switch {
+	case req.Undo || isUndoRequest(req.Question):
+		// Undo re-runs the previous query without its latest filter.
+		undone, ok := state.undo()
+		if !ok {
+			return nil, errors.NewValidation("There is no filter to undo in this conversation.")
+		}
+		intent, intentConf = undone, 1.0
 
-	// Step 2: Entity Recognition & Normalization
-	resolvedEntities, unresolved := s.normalizeEntities(ctx, intent.Entities)
-	intent.Entities = resolvedEntities
+	case state.Pending != nil && (len(req.Clarifications) > 0 || looksLikeClarificationReply(req.Question)):
+		// The user answered a clarification question: resume the held query.
+		pending := state.Pending
+		answered, stillUnresolved := s.normalizeEntities(ctx, pending.answer(req.Question, req.Clarifications))
+		intent = &pending.Intent
+		intent.Entities = mergeEntities(intent.Entities, answered)
+		intentConf, unresolved = 0.9, stillUnresolved
+
+	default:
+		state.Pending = nil
+
+		// Step 1: Intent Classification
+		classified, conf, err := s.stepIntentClassification(ctx, req.Question, req.Language, history, state)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "intent classification failed")
+		}
+		intent, intentConf = classified, conf
+
+		// Step 2: Follow-up resolution (pronouns, ellipsis, filter stacking),
+		// then Entity Recognition & Normalization
+		state.resolveFollowUp(req.Question, intent)
+		resolvedEntities, unresolvedEntities := s.normalizeEntities(ctx, intent.Entities)
+		intent.Entities = resolvedEntities
+
+		fromContext, remaining := state.resolveFromContext(unresolvedEntities)
+		intent.Entities = append(intent.Entities, fromContext...)
+		if len(remaining) > 0 {
+			s.logger.Warn(ctx, "Unresolved entities found, asking for clarification", "unresolved", remaining)
+			return s.clarify(ctx, req, convID, state, intent, intentConf, remaining, startTime)
+		}
+	}
 
 	if len(unresolved) > 0 {
 		s.logger.Warn(ctx, "Unresolved entities found", "unresolved", unresolved)
-		// We proceed but might ask user to clarify in the final answer
+		// We proceed but note the unresolved entities in the final answer
 	}
 
 	// Step 3: Query Generation (Logical & Transparent)
-	structuredQuery, queryType, err := s.buildStructuredQuery(*intent, resolvedEntities)
+	structuredQuery, queryType, generatedCypher, err := s.generateQuery(ctx, *intent, req.MaxResults)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "structured query generation failed")
 	}
 
-	generatedCypher, _ := s.llm.GenerateCypher(ctx, *intent) // Used for transparency
-
 	// Step 4: Query Execution
 	execCtx, cancel := context.WithTimeout(ctx, QueryExecutionTimeout)
 	defer cancel()
@@ -332,13 +406,15 @@ func (s *nlQueryServiceImpl) Query(ctx context.Context, req *NLQueryRequest) (*N
 	// Save Turn
 	_ = s.saveConversationTurn(ctx, convID, ConversationTurn{Role: "User", Content: req.Question, Timestamp: startTime})
 	_ = s.saveConversationTurn(ctx, convID, ConversationTurn{Role: "Assistant", Content: answer, Timestamp: time.Now(), Intent: intent})
+	state.recordTurn(intent, execResult, time.Now())
+	_ = s.saveConversationState(ctx, state)
 
 	// Metrics
 	s.metrics.ObserveHistogram("nl_query_latency", time.Since(startTime).Seconds(), map[string]string{"intent": string(intent.IntentType)})
 
 	// Determine DataType
 	dataType := DataTypeEntityList
-	if intent.IntentType == IntentAggregation || intent.IntentType == IntentTrendAnalysis {
+	if isAggregateIntent(intent.IntentType) {
 		dataType = DataTypeAggregation
 	}
 
@@ -351,6 +427,31 @@ func (s *nlQueryServiceImpl) Query(ctx context.Context, req *NLQueryRequest) (*N
 		GeneratedQuery: generatedCypher,
 		Suggestions:    suggestions,
 		ConversationID: convID,
+		ActiveFilters:  intent.Constraints,
+		SessionExpired: expired,
+	}, nil
+}
+
+// clarify holds the query back and asks the user to identify the remaining
+// entities. The next turn resumes it via PendingClarification.
+func (s *nlQueryServiceImpl) clarify(ctx context.Context, req *NLQueryRequest, convID string, state *ConversationState, intent *QueryIntent, intentConf float64, unresolved []UnresolvedEntity, startTime time.Time) (*NLQueryResponse, error) {
+	clarification := buildClarification(unresolved, req.Language)
+	state.Pending = &PendingClarification{Question: req.Question, Intent: *intent, Unresolved: unresolved}
+	state.LastActiveAt = time.Now()
+
+	_ = s.saveConversationTurn(ctx, convID, ConversationTurn{Role: "User", Content: req.Question, Timestamp: startTime})
+	_ = s.saveConversationTurn(ctx, convID, ConversationTurn{Role: "Assistant", Content: clarification.Question, Timestamp: time.Now()})
+	_ = s.saveConversationState(ctx, state)
+	s.metrics.IncCounter("nl_query_clarification", map[string]string{"intent": string(intent.IntentType)})
+
+	return &NLQueryResponse{
+		Answer:             clarification.Question,
+		Confidence:         intentConf,
+		ParsedIntent:       *intent,
+		ConversationID:     convID,
+		NeedsClarification: true,
+		Clarification:      clarification,
+		ActiveFilters:      intent.Constraints,
 	}, nil
 }
 
@@ -403,7 +504,7 @@ func (s *nlQueryServiceImpl) ExplainQuery(ctx context.Context, req *ExplainReque
 
 	// Step 1: Intent
 	start1 := time.Now()
-	intent, _, err := s.stepIntentClassification(ctx, req.Question, req.Language, nil)
+	intent, _, err := s.stepIntentClassification(ctx, req.Question, req.Language, nil, nil)
 	dur1 := time.Since(start1)
 	if err != nil {
 		return nil, err
@@ -418,8 +519,8 @@ func (s *nlQueryServiceImpl) ExplainQuery(ctx context.Context, req *ExplainReque
 
 	// Step 3: Query Gen
 	start3 := time.Now()
-	_, qType, err := s.buildStructuredQuery(*intent, resolved)
-	cypher, _ := s.llm.GenerateCypher(ctx, *intent)
+	intent.Entities = resolved
+	_, qType, cypher, err := s.generateQuery(ctx, *intent, 0)
 	dur3 := time.Since(start3)
 	steps = append(steps, ExplainStep{StepName: "Query Generation", Input: resolved, Output: cypher, Duration: dur3})
 
@@ -453,8 +554,8 @@ func (s *nlQueryServiceImpl) checkPromptInjection(query string) bool {
 	return false
 }
 
-func (s *nlQueryServiceImpl) stepIntentClassification(ctx context.Context, question string, lang QueryLanguage, history []ConversationTurn) (*QueryIntent, float64, error) {
-	prompt := s.buildIntentClassificationPrompt(question, lang, history)
+func (s *nlQueryServiceImpl) stepIntentClassification(ctx context.Context, question string, lang QueryLanguage, history []ConversationTurn, state *ConversationState) (*QueryIntent, float64, error) {
+	prompt := s.buildIntentClassificationPrompt(question, lang, history, state)
 
 	// Try with temp 0.3
 	resStr, err := s.llm.InferIntent(ctx, prompt, 0.3)
@@ -591,6 +692,24 @@ func (s *nlQueryServiceImpl) buildStructuredQuery(intent QueryIntent, entities [
 			req.Dimension = ByAssignee // Default
 		}
 		req.DateRange = intent.TimeRange
+		req.Filters = constraintFilters(intent.Constraints)
+		return req, QueryTypeAPI, nil
+
+	case IntentComparison:
+		// Compare the named entities side by side, one bucket each.
+		if len(entities) < 2 {
+			return nil, QueryTypeAPI, errors.NewValidation("Comparison needs at least two entities")
+		}
+		field, dim := comparisonDimension(entities[0].Type)
+		names := make([]string, 0, len(entities))
+		for _, e := range entities {
+			if e.Type == entities[0].Type {
+				names = append(names, displayName(e))
+			}
+		}
+		req := &AggregationRequest{Dimension: dim, DateRange: intent.TimeRange, TopN: len(names)}
+		req.Filters = constraintFilters(intent.Constraints)
+		req.Filters[field] = FilterCondition{Operator: OpIn, Value: names}
 		return req, QueryTypeAPI, nil
 
 	case IntentRelationQuery:
@@ -617,6 +736,48 @@ func (s *nlQueryServiceImpl) buildStructuredQuery(intent QueryIntent, entities [
 	}
 }
 
+// generateQuery builds the query to execute and the Cypher shown to the user.
+// With Cypher execution enabled the compiled query is both; otherwise the
+// structured request is executed and the LLM's Cypher is shown for
+// transparency.
+func (s *nlQueryServiceImpl) generateQuery(ctx context.Context, intent QueryIntent, maxResults int) (interface{}, QueryType, string, error) {
+	if s.cypher != nil && s.cypherRunner != nil {
+		compiled, err := s.cypher.Compile(intent, maxResults)
+		if err == nil {
+			return compiled, QueryTypeCypher, compiled.Query, nil
+		}
+		s.logger.Warn(ctx, "Cypher compilation failed, using structured query", "error", err, "intent", intent.IntentType)
+	}
+
+	structuredQuery, queryType, err := s.buildStructuredQuery(intent, intent.Entities)
+	if err != nil {
+		return nil, queryType, "", err
+	}
+	generatedCypher, _ := s.llm.GenerateCypher(ctx, intent) // Used for transparency
+	return structuredQuery, queryType, generatedCypher, nil
+}
+
+func constraintFilters(constraints []RecognizedConstraint) map[string]FilterCondition {
+	filters := make(map[string]FilterCondition, len(constraints)+1)
+	for _, c := range constraints {
+		filters[c.Field] = FilterCondition{Operator: c.Operator, Value: c.Value}
+	}
+	return filters
+}
+
+// comparisonDimension returns the filter field and aggregation dimension used
+// to compare entities of type t.
+func comparisonDimension(t EntityType) (string, AggregationDimension) {
+	switch t {
+	case EntityTypeInventor:
+		return "inventor", ByInventor
+	case EntityTypeTechDomain:
+		return "tech_domain_id", ByTechDomain
+	default:
+		return "assignee", ByAssignee
+	}
+}
+
 func (s *nlQueryServiceImpl) executeQuery(ctx context.Context, query interface{}, queryType QueryType) (interface{}, error) {
 	switch q := query.(type) {
 	case *EntitySearchRequest:
@@ -627,6 +788,8 @@ func (s *nlQueryServiceImpl) executeQuery(ctx context.Context, query interface{}
 		return s.kgSearch.TraverseRelations(ctx, q)
 	case *PathFindRequest:
 		return s.kgSearch.FindPaths(ctx, q)
+	case *CompiledCypher:
+		return s.cypherRunner.RunReadOnly(ctx, q.Query, q.Params, q.RowLimit, q.Timeout)
 	case map[string]interface{}:
 		// Handle similarity search (returns map from buildStructuredQuery)
 		if action, ok := q["action"].(string); ok && action == "similarity" {
@@ -657,6 +820,10 @@ func (s *nlQueryServiceImpl) truncateResults(results interface{}, maxItems int)
 			truncated.Buckets = r.Buckets[:maxItems]
 			return &truncated
 		}
+	case []map[string]interface{}:
+		if len(r) > maxItems {
+			return r[:maxItems]
+		}
 	}
 	return results
 }
@@ -703,16 +870,16 @@ func (s *nlQueryServiceImpl) saveConversationTurn(ctx context.Context, convID st
 // ----------------------------------------------------------------------------
 // Prompt Builders
 // ----------------------------------------------------------------------------
-func (s *nlQueryServiceImpl) buildIntentClassificationPrompt(question string, lang QueryLanguage, history []ConversationTurn) string {
+func (s *nlQueryServiceImpl) buildIntentClassificationPrompt(question string, lang QueryLanguage, history []ConversationTurn, state *ConversationState) string {
 	histStr := ""
 	for _, t := range history {
 		histStr += fmt.Sprintf("%s: %s\n", t.Role, t.Content)
 	}
 	return fmt.Sprintf(`System: You are an intent classification engine for a patent knowledge graph.
 History: %s
-User Question: %s
+%sUser Question: %s
 Language: %s
-Output JSON matching QueryIntent schema.`, histStr, question, lang)
+Output JSON matching QueryIntent schema.`, histStr, state.contextPrompt(), question, lang)
 }
 
 func (s *nlQueryServiceImpl) buildAnswerGenerationPrompt(question string, intent QueryIntent, results interface{}, lang QueryLanguage) string {
@@ -293,7 +293,8 @@ func (s *portfolioReportServiceImpl) GenerateFullReport(ctx context.Context, req
 		return nil, err
 	}
 
-	go s.processFullReport(context.Background(), reportID, req)
+	// Section insights call the LLM for the caller's tenant.
+	go s.processFullReport(context.WithoutCancel(ctx), reportID, req)
 
 	return &PortfolioReportResult{
 		ReportID:    reportID,
@@ -87,8 +87,9 @@ func (s *StrategyFTOReportService) Generate(ctx context.Context, req *FTOReportR
 	}
 	s.mu.Unlock()
 
-	// Fire-and-forget async generation
-	go s.generateAsync(context.Background(), reportID, req)
+	// Fire-and-forget async generation. The caller's identity stays on the
+	// context so LLM guardrails, metering and caching apply to its tenant.
+	go s.generateAsync(context.WithoutCancel(ctx), reportID, req)
 
 	return &FTOReportResponse{
 		ReportID:          reportID,
@@ -0,0 +1,443 @@
+// LLM usage application service: meter the token usage of every model call
+// into a per-tenant ledger, price it through the configured model price
+// table and enforce monthly soft and hard budgets. Crossing the soft limit
+// raises an alert; reaching the hard limit also refuses further calls until
+// the next month or a budget change.
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	usagedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// DefaultEnforcementTTL bounds how stale the budget and spend figures used to
+// admit calls may be. Other API server instances' spend shows up within it.
+const DefaultEnforcementTTL = time.Minute
+
+// Budget sources reported by BudgetStatus.
+const (
+	BudgetSourceTenant  = "tenant"  // set through the API
+	BudgetSourceConfig  = "config"  // llm.usage.tenants
+	BudgetSourceDefault = "default" // llm.usage.default_budget
+)
+
+// BudgetAlert is raised once per tenant, month and level when spend reaches
+// a budget limit.
+type BudgetAlert struct {
+	TenantID string                  `json:"tenant_id"`
+	Level    usagedomain.BudgetLevel `json:"level"`
+	Period   time.Time               `json:"period"`
+	SpentUSD float64                 `json:"spent_usd"`
+	LimitUSD float64                 `json:"limit_usd"`
+	RaisedAt time.Time               `json:"raised_at"`
+}
+
+// BudgetNotifier delivers budget alerts.
+type BudgetNotifier interface {
+	NotifyBudget(ctx context.Context, alert *BudgetAlert) error
+}
+
+// MultiBudgetNotifier fans an alert out to several notifiers, returning the
+// first error after trying all of them.
+type MultiBudgetNotifier []BudgetNotifier
+
+// NotifyBudget implements BudgetNotifier.
+func (m MultiBudgetNotifier) NotifyBudget(ctx context.Context, alert *BudgetAlert) error {
+	var firstErr error
+	for _, n := range m {
+		if n == nil {
+			continue
+		}
+		if err := n.NotifyBudget(ctx, alert); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type loggingBudgetNotifier struct {
+	logger logging.Logger
+}
+
+// NewLoggingBudgetNotifier writes budget alerts to the log.
+func NewLoggingBudgetNotifier(logger logging.Logger) BudgetNotifier {
+	return &loggingBudgetNotifier{logger: logger}
+}
+
+func (n *loggingBudgetNotifier) NotifyBudget(_ context.Context, a *BudgetAlert) error {
+	n.logger.Warn("LLM budget limit reached",
+		logging.String("tenant_id", a.TenantID),
+		logging.String("level", string(a.Level)),
+		logging.String("period", a.Period.Format("2006-01")),
+		logging.Float64("spent_usd", a.SpentUSD),
+		logging.Float64("limit_usd", a.LimitUSD))
+	return nil
+}
+
+// Limits are monthly USD spend limits; zero is unlimited.
+type Limits struct {
+	SoftLimitUSD float64
+	HardLimitUSD float64
+}
+
+// Config configures the usage service.
+type Config struct {
+	Prices *usagedomain.PriceTable
+	// DefaultBudget applies to tenants without a stored or configured budget.
+	DefaultBudget Limits
+	// TenantBudgets come from configuration; budgets set through the API
+	// take precedence.
+	TenantBudgets map[string]Limits
+	// EnforcementTTL defaults to DefaultEnforcementTTL.
+	EnforcementTTL time.Duration
+}
+
+// BudgetStatus reports a tenant's spend against its budget this month.
+// RemainingUSD is -1 without a hard limit.
+type BudgetStatus struct {
+	TenantID     string                  `json:"tenant_id"`
+	Period       time.Time               `json:"period"`
+	SpentUSD     float64                 `json:"spent_usd"`
+	SoftLimitUSD float64                 `json:"soft_limit_usd"`
+	HardLimitUSD float64                 `json:"hard_limit_usd"`
+	RemainingUSD float64                 `json:"remaining_usd"`
+	Level        usagedomain.BudgetLevel `json:"level"`
+	Source       string                  `json:"source"`
+}
+
+// SummaryRequest selects usage to report. A zero range covers the current
+// month; an empty TenantID covers all tenants.
+type SummaryRequest struct {
+	TenantID string                `json:"tenant_id,omitempty"`
+	UserID   string                `json:"user_id,omitempty"`
+	Feature  string                `json:"feature,omitempty"`
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	GroupBy  usagedomain.Dimension `json:"group_by"`
+}
+
+// Summary is a grouped usage report.
+type Summary struct {
+	TenantID string                `json:"tenant_id,omitempty"`
+	From     time.Time             `json:"from"`
+	To       time.Time             `json:"to"`
+	GroupBy  usagedomain.Dimension `json:"group_by"`
+	Lines    []*usagedomain.Line   `json:"lines"`
+	Total    usagedomain.Line      `json:"total"`
+}
+
+// SetBudgetRequest stores a tenant's budget.
+type SetBudgetRequest struct {
+	TenantID     string  `json:"tenant_id"`
+	SoftLimitUSD float64 `json:"soft_limit_usd"`
+	HardLimitUSD float64 `json:"hard_limit_usd"`
+}
+
+// Service meters LLM usage and reports on it. It implements
+// common.UsageMeter for common.NewMeteredBackend.
+type Service interface {
+	common.UsageMeter
+	Summary(ctx context.Context, req *SummaryRequest) (*Summary, error)
+	BudgetStatus(ctx context.Context, tenantID string) (*BudgetStatus, error)
+	SetBudget(ctx context.Context, req *SetBudgetRequest) (*BudgetStatus, error)
+	Prices() map[string]usagedomain.ModelPrice
+}
+
+// tenantState is the cached enforcement view of one tenant.
+type tenantState struct {
+	budget   *usagedomain.Budget
+	source   string
+	period   time.Time
+	spent    float64
+	loadedAt time.Time
+}
+
+type serviceImpl struct {
+	repo     usagedomain.Repository
+	cfg      Config
+	notifier BudgetNotifier
+	logger   logging.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex
+	states map[string]*tenantState
+
+	unpriced sync.Map // model -> struct{}, warned once
+}
+
+// NewService creates a usage service backed by repo. A nil notifier logs
+// alerts.
+func NewService(repo usagedomain.Repository, cfg Config, notifier BudgetNotifier, logger logging.Logger) Service {
+	if cfg.EnforcementTTL <= 0 {
+		cfg.EnforcementTTL = DefaultEnforcementTTL
+	}
+	if notifier == nil {
+		notifier = NewLoggingBudgetNotifier(logger)
+	}
+	return &serviceImpl{
+		repo:     repo,
+		cfg:      cfg,
+		notifier: notifier,
+		logger:   logger,
+		now:      func() time.Time { return time.Now().UTC() },
+		states:   make(map[string]*tenantState),
+	}
+}
+
+// Authorize refuses tenants whose spend reached the hard limit. Failures to
+// read the ledger are logged and the call is let through: an accounting
+// outage must not take the AI features down with it.
+func (s *serviceImpl) Authorize(ctx context.Context, tenantID string) error {
+	tenantID = normalizeTenant(tenantID)
+	st, err := s.state(ctx, tenantID)
+	if err != nil {
+		s.logger.Warn("LLM budget check skipped", logging.String("tenant_id", tenantID), logging.Err(err))
+		return nil
+	}
+	s.mu.Lock()
+	level := st.budget.Level(st.spent)
+	s.mu.Unlock()
+	if level == usagedomain.BudgetHard {
+		return errors.New(errors.ErrCodeTooManyRequests, fmt.Sprintf(
+			"monthly LLM budget of %.2f USD exhausted for tenant %s", st.budget.HardLimitUSD, tenantID))
+	}
+	return nil
+}
+
+// Record prices an event, appends it to the ledger and raises alerts for
+// budget limits crossed by it.
+func (s *serviceImpl) Record(ctx context.Context, ev *common.UsageEvent) error {
+	if ev == nil {
+		return errors.New(errors.ErrCodeValidation, "usage event cannot be nil")
+	}
+	tenantID := normalizeTenant(ev.TenantID)
+	rec, err := usagedomain.NewRecord(tenantID, ev.UserID, ev.Feature, ev.Provider, ev.Model, ev.PromptTokens, ev.CompletionTokens)
+	if err != nil {
+		return err
+	}
+	rec.RequestID = ev.RequestID
+	rec.Estimated = ev.Estimated
+	if !ev.Time.IsZero() {
+		rec.CreatedAt = ev.Time.UTC()
+	}
+	if price, ok := s.cfg.Prices.Lookup(rec.Model); ok {
+		rec.CostUSD = price.Cost(rec.PromptTokens, rec.CompletionTokens)
+	} else if _, warned := s.unpriced.LoadOrStore(rec.Model, struct{}{}); !warned {
+		s.logger.Warn("LLM model has no price; usage recorded at zero cost", logging.String("model", rec.Model))
+	}
+
+	// Load the enforcement view before appending so the new record is not
+	// counted twice.
+	st, stateErr := s.state(ctx, tenantID)
+	if err := s.repo.Append(ctx, rec); err != nil {
+		return err
+	}
+	if stateErr != nil || rec.CostUSD == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	period := usagedomain.Period(rec.CreatedAt)
+	if !st.period.Equal(period) {
+		s.mu.Unlock()
+		return nil
+	}
+	before := st.budget.Level(st.spent)
+	st.spent += rec.CostUSD
+	after := st.budget.Level(st.spent)
+	spent, budget := st.spent, st.budget
+	s.mu.Unlock()
+
+	for _, level := range []usagedomain.BudgetLevel{usagedomain.BudgetSoft, usagedomain.BudgetHard} {
+		if levelRank(before) < levelRank(level) && levelRank(level) <= levelRank(after) {
+			s.raiseAlert(ctx, &BudgetAlert{
+				TenantID: tenantID,
+				Level:    level,
+				Period:   period,
+				SpentUSD: roundUSD(spent),
+				LimitUSD: budget.Limit(level),
+				RaisedAt: s.now(),
+			})
+		}
+	}
+	return nil
+}
+
+func (s *serviceImpl) Summary(ctx context.Context, req *SummaryRequest) (*Summary, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "request cannot be nil")
+	}
+	q := &usagedomain.Query{
+		TenantID: req.TenantID,
+		UserID:   req.UserID,
+		Feature:  req.Feature,
+		From:     req.From,
+		To:       req.To,
+		GroupBy:  req.GroupBy,
+	}
+	if q.From.IsZero() && q.To.IsZero() {
+		now := s.now()
+		q.From, q.To = usagedomain.Period(now), usagedomain.PeriodEnd(now)
+	}
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	lines, err := s.repo.Summarize(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		lines = []*usagedomain.Line{}
+	}
+	sum := &Summary{TenantID: q.TenantID, From: q.From, To: q.To, GroupBy: q.GroupBy, Lines: lines}
+	for _, l := range lines {
+		sum.Total.Add(l)
+	}
+	return sum, nil
+}
+
+func (s *serviceImpl) BudgetStatus(ctx context.Context, tenantID string) (*BudgetStatus, error) {
+	tenantID = normalizeTenant(tenantID)
+	st, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.states[tenantID] = st
+	status := statusOf(tenantID, st)
+	s.mu.Unlock()
+	return status, nil
+}
+
+func (s *serviceImpl) SetBudget(ctx context.Context, req *SetBudgetRequest) (*BudgetStatus, error) {
+	if req == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "request cannot be nil")
+	}
+	budget, err := usagedomain.NewBudget(normalizeTenant(req.TenantID), req.SoftLimitUSD, req.HardLimitUSD)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveBudget(ctx, budget); err != nil {
+		return nil, err
+	}
+	s.logger.Info("LLM budget updated",
+		logging.String("tenant_id", budget.TenantID),
+		logging.Float64("soft_limit_usd", budget.SoftLimitUSD),
+		logging.Float64("hard_limit_usd", budget.HardLimitUSD))
+	return s.BudgetStatus(ctx, budget.TenantID)
+}
+
+func (s *serviceImpl) Prices() map[string]usagedomain.ModelPrice {
+	return s.cfg.Prices.Entries()
+}
+
+// state returns the cached enforcement view, reloading it when stale or when
+// the month has rolled over.
+func (s *serviceImpl) state(ctx context.Context, tenantID string) (*tenantState, error) {
+	now := s.now()
+	s.mu.Lock()
+	st, ok := s.states[tenantID]
+	s.mu.Unlock()
+	if ok && st.period.Equal(usagedomain.Period(now)) && now.Sub(st.loadedAt) < s.cfg.EnforcementTTL {
+		return st, nil
+	}
+	st, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.states[tenantID] = st
+	s.mu.Unlock()
+	return st, nil
+}
+
+func (s *serviceImpl) load(ctx context.Context, tenantID string) (*tenantState, error) {
+	budget, source, err := s.resolveBudget(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	period := usagedomain.Period(now)
+	spent, err := s.repo.TenantSpend(ctx, tenantID, period, usagedomain.PeriodEnd(now))
+	if err != nil {
+		return nil, err
+	}
+	return &tenantState{budget: budget, source: source, period: period, spent: spent, loadedAt: now}, nil
+}
+
+// resolveBudget prefers a budget set through the API, then a configured
+// tenant budget, then the default.
+func (s *serviceImpl) resolveBudget(ctx context.Context, tenantID string) (*usagedomain.Budget, string, error) {
+	stored, err := s.repo.GetBudget(ctx, tenantID)
+	if err == nil {
+		return stored, BudgetSourceTenant, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, "", err
+	}
+	if l, ok := s.cfg.TenantBudgets[tenantID]; ok {
+		return &usagedomain.Budget{TenantID: tenantID, SoftLimitUSD: l.SoftLimitUSD, HardLimitUSD: l.HardLimitUSD}, BudgetSourceConfig, nil
+	}
+	l := s.cfg.DefaultBudget
+	return &usagedomain.Budget{TenantID: tenantID, SoftLimitUSD: l.SoftLimitUSD, HardLimitUSD: l.HardLimitUSD}, BudgetSourceDefault, nil
+}
+
+// raiseAlert notifies once per tenant, month and level. When the alert
+// cannot be recorded the notification still goes out; a duplicate beats a
+// missed budget alert.
+func (s *serviceImpl) raiseAlert(ctx context.Context, alert *BudgetAlert) {
+	first, err := s.repo.MarkAlertSent(ctx, alert.TenantID, alert.Period, alert.Level)
+	if err != nil {
+		s.logger.Warn("failed to record LLM budget alert", logging.String("tenant_id", alert.TenantID), logging.Err(err))
+	} else if !first {
+		return
+	}
+	if err := s.notifier.NotifyBudget(ctx, alert); err != nil {
+		s.logger.Error("failed to deliver LLM budget alert", logging.String("tenant_id", alert.TenantID), logging.Err(err))
+	}
+}
+
+func statusOf(tenantID string, st *tenantState) *BudgetStatus {
+	return &BudgetStatus{
+		TenantID:     tenantID,
+		Period:       st.period,
+		SpentUSD:     roundUSD(st.spent),
+		SoftLimitUSD: st.budget.SoftLimitUSD,
+		HardLimitUSD: st.budget.HardLimitUSD,
+		RemainingUSD: roundUSD(st.budget.Remaining(st.spent)),
+		Level:        st.budget.Level(st.spent),
+		Source:       st.source,
+	}
+}
+
+func normalizeTenant(tenantID string) string {
+	if tenantID == "" {
+		return usagedomain.DefaultTenantID
+	}
+	return tenantID
+}
+
+func levelRank(l usagedomain.BudgetLevel) int {
+	switch l {
+	case usagedomain.BudgetSoft:
+		return 1
+	case usagedomain.BudgetHard:
+		return 2
+	}
+	return 0
+}
+
+func roundUSD(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+//Personal.AI order the ending
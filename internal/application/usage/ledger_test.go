@@ -0,0 +1,220 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	usagedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type memUsageRepo struct {
+	records  []*usagedomain.Record
+	budgets  map[string]*usagedomain.Budget
+	alerts   map[string]bool
+	lines    []*usagedomain.Line
+	queries  []*usagedomain.Query
+	spendErr error
+}
+
+func newMemUsageRepo() *memUsageRepo {
+	return &memUsageRepo{
+		budgets: make(map[string]*usagedomain.Budget),
+		alerts:  make(map[string]bool),
+	}
+}
+
+func (m *memUsageRepo) Append(_ context.Context, rec *usagedomain.Record) error {
+	m.records = append(m.records, rec)
+	return nil
+}
+
+func (m *memUsageRepo) Summarize(_ context.Context, q *usagedomain.Query) ([]*usagedomain.Line, error) {
+	m.queries = append(m.queries, q)
+	return m.lines, nil
+}
+
+func (m *memUsageRepo) TenantSpend(_ context.Context, tenantID string, from, to time.Time) (float64, error) {
+	if m.spendErr != nil {
+		return 0, m.spendErr
+	}
+	var spent float64
+	for _, r := range m.records {
+		if r.TenantID == tenantID && !r.CreatedAt.Before(from) && r.CreatedAt.Before(to) {
+			spent += r.CostUSD
+		}
+	}
+	return spent, nil
+}
+
+func (m *memUsageRepo) GetBudget(_ context.Context, tenantID string) (*usagedomain.Budget, error) {
+	b, ok := m.budgets[tenantID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "LLM budget not found")
+	}
+	return b, nil
+}
+
+func (m *memUsageRepo) SaveBudget(_ context.Context, b *usagedomain.Budget) error {
+	m.budgets[b.TenantID] = b
+	return nil
+}
+
+func (m *memUsageRepo) MarkAlertSent(_ context.Context, tenantID string, period time.Time, level usagedomain.BudgetLevel) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s", tenantID, period.Format("2006-01"), level)
+	if m.alerts[key] {
+		return false, nil
+	}
+	m.alerts[key] = true
+	return true, nil
+}
+
+type recordingNotifier struct {
+	alerts []*BudgetAlert
+}
+
+func (n *recordingNotifier) NotifyBudget(_ context.Context, a *BudgetAlert) error {
+	n.alerts = append(n.alerts, a)
+	return nil
+}
+
+func newTestService(t *testing.T, repo *memUsageRepo, cfg Config) (*serviceImpl, *recordingNotifier) {
+	t.Helper()
+	if cfg.Prices == nil {
+		prices, err := usagedomain.NewPriceTable(map[string]usagedomain.ModelPrice{
+			"claude-sonnet-4": {InputPer1K: 3, OutputPer1K: 15},
+		})
+		require.NoError(t, err)
+		cfg.Prices = prices
+	}
+	notifier := &recordingNotifier{}
+	svc := NewService(repo, cfg, notifier, logging.NewNopLogger()).(*serviceImpl)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, notifier
+}
+
+func chatEvent(tenantID string, prompt, completion int64) *common.UsageEvent {
+	return &common.UsageEvent{
+		TenantID:         tenantID,
+		UserID:           "u1",
+		Feature:          "ai.chat",
+		Provider:         "anthropic",
+		Model:            "claude-sonnet-4-20250514",
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		Time:             time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRecord_PricesEvent(t *testing.T) {
+	repo := newMemUsageRepo()
+	svc, _ := newTestService(t, repo, Config{})
+
+	require.NoError(t, svc.Record(context.Background(), chatEvent("", 1000, 1000)))
+	require.Len(t, repo.records, 1)
+	rec := repo.records[0]
+	assert.Equal(t, usagedomain.DefaultTenantID, rec.TenantID)
+	assert.Equal(t, 18.0, rec.CostUSD)
+
+	ev := chatEvent("acme", 10, 0)
+	ev.Model = "unknown-model"
+	require.NoError(t, svc.Record(context.Background(), ev))
+	assert.Equal(t, 0.0, repo.records[1].CostUSD)
+
+	assert.Error(t, svc.Record(context.Background(), nil))
+}
+
+func TestRecord_AlertsOncePerLevel(t *testing.T) {
+	repo := newMemUsageRepo()
+	svc, notifier := newTestService(t, repo, Config{DefaultBudget: Limits{SoftLimitUSD: 30, HardLimitUSD: 50}})
+	ctx := context.Background()
+
+	// 18 USD per call: 18, 36 (soft), 54 (hard), 72.
+	for i := 0; i < 4; i++ {
+		require.NoError(t, svc.Record(ctx, chatEvent("acme", 1000, 1000)))
+	}
+	require.Len(t, notifier.alerts, 2)
+	assert.Equal(t, usagedomain.BudgetSoft, notifier.alerts[0].Level)
+	assert.Equal(t, 36.0, notifier.alerts[0].SpentUSD)
+	assert.Equal(t, usagedomain.BudgetHard, notifier.alerts[1].Level)
+	assert.Equal(t, 50.0, notifier.alerts[1].LimitUSD)
+
+	err := svc.Authorize(ctx, "acme")
+	require.Error(t, err)
+	assert.True(t, errors.IsCode(err, errors.ErrCodeTooManyRequests))
+	assert.NoError(t, svc.Authorize(ctx, "other"))
+
+	// A second instance sees the spend but not a second alert.
+	svc2, notifier2 := newTestService(t, repo, Config{DefaultBudget: Limits{SoftLimitUSD: 30, HardLimitUSD: 50}})
+	repo.records = repo.records[:1]
+	require.NoError(t, svc2.Record(ctx, chatEvent("acme", 1000, 1000)))
+	assert.Empty(t, notifier2.alerts)
+}
+
+func TestAuthorize_FailsOpen(t *testing.T) {
+	repo := newMemUsageRepo()
+	repo.spendErr = errors.New(errors.ErrCodeDatabaseError, "connection refused")
+	svc, _ := newTestService(t, repo, Config{DefaultBudget: Limits{HardLimitUSD: 0.01}})
+
+	assert.NoError(t, svc.Authorize(context.Background(), "acme"))
+	assert.NoError(t, svc.Record(context.Background(), chatEvent("acme", 1000, 1000)))
+	assert.Len(t, repo.records, 1)
+}
+
+func TestBudgetStatus_Precedence(t *testing.T) {
+	repo := newMemUsageRepo()
+	svc, _ := newTestService(t, repo, Config{
+		DefaultBudget: Limits{SoftLimitUSD: 10, HardLimitUSD: 20},
+		TenantBudgets: map[string]Limits{"acme": {SoftLimitUSD: 100, HardLimitUSD: 200}},
+	})
+	ctx := context.Background()
+
+	st, err := svc.BudgetStatus(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, BudgetSourceDefault, st.Source)
+	assert.Equal(t, 20.0, st.RemainingUSD)
+
+	st, err = svc.BudgetStatus(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, BudgetSourceConfig, st.Source)
+	assert.Equal(t, 200.0, st.HardLimitUSD)
+
+	st, err = svc.SetBudget(ctx, &SetBudgetRequest{TenantID: "acme", SoftLimitUSD: 5, HardLimitUSD: 0})
+	require.NoError(t, err)
+	assert.Equal(t, BudgetSourceTenant, st.Source)
+	assert.Equal(t, -1.0, st.RemainingUSD)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), st.Period)
+
+	_, err = svc.SetBudget(ctx, &SetBudgetRequest{TenantID: "acme", SoftLimitUSD: 50, HardLimitUSD: 10})
+	assert.Error(t, err)
+}
+
+func TestSummary_DefaultsToCurrentMonth(t *testing.T) {
+	repo := newMemUsageRepo()
+	repo.lines = []*usagedomain.Line{
+		{Key: "ai.chat", Requests: 3, PromptTokens: 300, CompletionTokens: 30, CostUSD: 1.5},
+		{Key: "strategy.report", Requests: 1, EstimatedRequests: 1, PromptTokens: 1000, CostUSD: 0.5},
+	}
+	svc, _ := newTestService(t, repo, Config{})
+
+	sum, err := svc.Summary(context.Background(), &SummaryRequest{TenantID: "acme"})
+	require.NoError(t, err)
+	require.Len(t, repo.queries, 1)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), sum.From)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), sum.To)
+	assert.Equal(t, usagedomain.ByFeature, sum.GroupBy)
+	assert.Equal(t, int64(4), sum.Total.Requests)
+	assert.Equal(t, 2.0, sum.Total.CostUSD)
+
+	_, err = svc.Summary(context.Background(), &SummaryRequest{GroupBy: "region"})
+	assert.Error(t, err)
+}
+
+//Personal.AI order the ending
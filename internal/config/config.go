@@ -29,8 +29,79 @@ type Config struct {
 // LLMConfig holds LLM provider configuration.
 // Primary provider is used for all LLM calls; Fallback is used on primary failure.
 type LLMConfig struct {
-	Primary  LLMProviderConfig `mapstructure:"primary"`
-	Fallback LLMProviderConfig `mapstructure:"fallback"`
+	Primary   LLMProviderConfig  `mapstructure:"primary"`
+	Fallback  LLMProviderConfig  `mapstructure:"fallback"`
+	Cache     LLMCacheConfig     `mapstructure:"cache"`
+	Guardrail LLMGuardrailConfig `mapstructure:"guardrail"`
+	Usage     LLMUsageConfig     `mapstructure:"usage"`
+
+	EmbeddingCache LLMEmbeddingCacheConfig `mapstructure:"embedding_cache"`
+}
+
+// LLMEmbeddingCacheConfig configures the content-addressed embedding cache.
+// Vectors are keyed on the text and the embedding model ID and version; the
+// local directory is always consulted first and Redis, when enabled, is
+// shared between processes.
+type LLMEmbeddingCacheConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Dir      string        `mapstructure:"dir"`       // on-disk tier, e.g. /var/lib/keyip/embeddings
+	Redis    bool          `mapstructure:"redis"`     // also use the shared Redis tier
+	RedisTTL time.Duration `mapstructure:"redis_ttl"` // 0 keeps entries until evicted
+}
+
+// LLMCacheConfig configures the tenant-scoped LLM response cache.
+// Responses are keyed on the normalised prompt and model version; when
+// SimilarityThreshold is above zero, near-duplicate queries are also served
+// from cache via embedding similarity.
+type LLMCacheConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	TTL                 time.Duration `mapstructure:"ttl"`
+	SimilarityThreshold float64       `mapstructure:"similarity_threshold"` // 0 disables semantic hits, e.g. 0.95
+	MaxSemanticEntries  int           `mapstructure:"max_semantic_entries"` // per tenant and prompt template
+	InputCostPer1K      float64       `mapstructure:"input_cost_per_1k"`    // USD, for cost-saved metrics
+	OutputCostPer1K     float64       `mapstructure:"output_cost_per_1k"`   // USD
+}
+
+// LLMGuardrailConfig configures redaction of confidential data from prompts
+// sent to external LLM providers. Tenant policies extend the default one.
+type LLMGuardrailConfig struct {
+	Enabled bool                          `mapstructure:"enabled"`
+	Default LLMRedactionPolicy            `mapstructure:"default"`
+	Tenants map[string]LLMRedactionPolicy `mapstructure:"tenants"`
+}
+
+// LLMRedactionPolicy is one tenant's redaction policy.
+type LLMRedactionPolicy struct {
+	BlockExternal    bool     `mapstructure:"block_external"`    // refuse every call to an external provider
+	Action           string   `mapstructure:"action"`            // "redact" (default) or "block" when confidential data is found
+	Categories       []string `mapstructure:"categories"`        // built-in detectors; empty enables all
+	Names            []string `mapstructure:"names"`             // people, e.g. inventors
+	Terms            []string `mapstructure:"terms"`             // confidential terms, e.g. project code names
+	Patterns         []string `mapstructure:"patterns"`          // extra regexes
+	CompoundPatterns []string `mapstructure:"compound_patterns"` // internal compound ID regexes
+}
+
+// LLMUsageConfig configures the per-tenant LLM token and cost ledger.
+// Prices are matched on the exact model name, then the longest model prefix,
+// then the "default" entry. Budgets set through the API override the
+// configured ones.
+type LLMUsageConfig struct {
+	Enabled       bool                       `mapstructure:"enabled"`
+	Prices        map[string]LLMModelPrice   `mapstructure:"prices"`
+	DefaultBudget LLMBudgetConfig            `mapstructure:"default_budget"`
+	Tenants       map[string]LLMBudgetConfig `mapstructure:"tenants"`
+}
+
+// LLMModelPrice is a model's price in USD per 1K tokens.
+type LLMModelPrice struct {
+	InputPer1K  float64 `mapstructure:"input_per_1k"`
+	OutputPer1K float64 `mapstructure:"output_per_1k"`
+}
+
+// LLMBudgetConfig is a monthly spend budget in USD; zero is unlimited.
+type LLMBudgetConfig struct {
+	SoftLimitUSD float64 `mapstructure:"soft_limit_usd"` // alert
+	HardLimitUSD float64 `mapstructure:"hard_limit_usd"` // alert and refuse further calls
 }
 
 // LLMProviderConfig configures a single LLM provider.
@@ -52,6 +123,7 @@ type LLMProviderConfig struct {
 	// or falls back to prompt-based extraction (for Anthropic).
 	EmbeddingModelName  string `mapstructure:"embedding_model_name"`  // e.g. text-embedding-3-small
 	EmbeddingDimensions int    `mapstructure:"embedding_dimensions"`  // e.g. 1536, defaults 768
+	EmbeddingModelVersion string `mapstructure:"embedding_model_version"` // bump to re-embed; defaults "1"
 }
 
 // ResolvedAPIKey returns the API key with env-var interpolation and priority.
@@ -176,8 +248,9 @@ type RedisConfig struct {
 
 // SearchConfig holds search engine settings.
 type SearchConfig struct {
-	OpenSearch OpenSearchConfig `mapstructure:"opensearch"`
-	Milvus     MilvusConfig     `mapstructure:"milvus"`
+	OpenSearch OpenSearchConfig  `mapstructure:"opensearch"`
+	Milvus     MilvusConfig      `mapstructure:"milvus"`
+	Local      LocalVectorConfig `mapstructure:"local"`
 }
 
 type OpenSearchConfig struct {
@@ -198,6 +271,16 @@ type MilvusConfig struct {
 	ReadTimeout    time.Duration `mapstructure:"read_timeout"`
 }
 
+// LocalVectorConfig configures the embedded HNSW index used in place of
+// Milvus when no Milvus address is set. Zero values take the index
+// defaults.
+type LocalVectorConfig struct {
+	IndexDir       string `mapstructure:"index_dir"`
+	M              int    `mapstructure:"m"`
+	EfConstruction int    `mapstructure:"ef_construction"`
+	EfSearch       int    `mapstructure:"ef_search"`
+}
+
 // MessagingConfig holds messaging settings.
 type MessagingConfig struct {
 	Kafka KafkaConfig `mapstructure:"kafka"`
@@ -279,6 +362,7 @@ type IntelligenceConfig struct {
 	StrategyGPT   StrategyGPTConfig   `mapstructure:"strategy_gpt"`
 	ChemExtractor ChemExtractorConfig `mapstructure:"chem_extractor"`
 	InfringeNet   InfringeNetConfig   `mapstructure:"infringe_net"`
+	Serving       ModelServingConfig  `mapstructure:"serving"`
 }
 
 type MolPatentGNNConfig struct {
@@ -307,6 +391,10 @@ type StrategyGPTConfig struct {
 	Timeout     time.Duration `mapstructure:"timeout"`
 	RetryCount  int           `mapstructure:"retry_count"`
 	RetryDelay  time.Duration `mapstructure:"retry_delay"`
+	// PromptRegistry is the prompt registry file managed by `keyip prompt`.
+	// Report prompts use its active versions; a missing file means the
+	// built-in templates.
+	PromptRegistry string `mapstructure:"prompt_registry"`
 }
 
 type ChemExtractorConfig struct {
@@ -325,6 +413,15 @@ type InfringeNetConfig struct {
 	SimilarityMetric string        `mapstructure:"similarity_metric"`
 }
 
+// ModelServingConfig locates the model serving cluster that runs inference
+// for the in-house models.
+type ModelServingConfig struct {
+	// Endpoint is the serving cluster's HTTP base URL; empty disables
+	// remote inference.
+	Endpoint string        `mapstructure:"endpoint" validate:"omitempty,url"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
 // MonitoringConfig holds monitoring and logging settings.
 type MonitoringConfig struct {
 	Prometheus PrometheusConfig `mapstructure:"prometheus"`
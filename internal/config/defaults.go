@@ -50,6 +50,7 @@ const (
 	DefaultStrategyGPTTimeout           = 120 * time.Second
 	DefaultStrategyGPTRetryCount        = 3
 	DefaultStrategyGPTRetryDelay        = 2 * time.Second
+	DefaultStrategyGPTPromptRegistry    = "configs/prompts/registry.json"
 	DefaultChemExtractorTimeout        = 60 * time.Second
 	DefaultInfringeNetThreshold        = 0.85
 	DefaultInfringeNetBatchSize        = 16
@@ -236,6 +237,9 @@ func ApplyDefaults(cfg *Config) *Config {
 	if cfg.Intelligence.StrategyGPT.RetryDelay == 0 {
 		cfg.Intelligence.StrategyGPT.RetryDelay = DefaultStrategyGPTRetryDelay
 	}
+	if cfg.Intelligence.StrategyGPT.PromptRegistry == "" {
+		cfg.Intelligence.StrategyGPT.PromptRegistry = DefaultStrategyGPTPromptRegistry
+	}
 	if cfg.Intelligence.ChemExtractor.Timeout == 0 {
 		cfg.Intelligence.ChemExtractor.Timeout = DefaultChemExtractorTimeout
 	}
@@ -0,0 +1,161 @@
+// Package assignee resolves the free-text assignee names on patents to
+// canonical companies, so that analyses group "Samsung Display Co., Ltd.",
+// "SAMSUNG DISPLAY CO LTD" and "三星显示有限公司" as one assignee.
+package assignee
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// AliasSource records how a name became an alias of an assignee.
+type AliasSource string
+
+const (
+	// AliasCanonical is the assignee's own canonical name.
+	AliasCanonical AliasSource = "canonical"
+	// AliasCurated comes from the maintained alias table.
+	AliasCurated AliasSource = "curated"
+	// AliasFuzzy was merged automatically by a high-confidence match.
+	AliasFuzzy AliasSource = "fuzzy"
+	// AliasReview was merged by a reviewer approving a proposed match.
+	AliasReview AliasSource = "review"
+)
+
+// ReviewStatus is the state of a proposed merge.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// IsValid reports whether s is a known review status.
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case ReviewPending, ReviewApproved, ReviewRejected:
+		return true
+	}
+	return false
+}
+
+// Assignee is a canonical company. ParentID links a subsidiary to its
+// parent; Aliases holds the normalised names that resolve to it and is
+// filled by the repository when candidates are loaded.
+type Assignee struct {
+	ID             uuid.UUID  `json:"id"`
+	CanonicalName  string     `json:"canonical_name"`
+	NormalizedName string     `json:"normalized_name"`
+	Country        string     `json:"country,omitempty"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	Aliases        []string   `json:"aliases,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// NewAssignee creates an assignee whose canonical name is name.
+func NewAssignee(name, country string) (*Assignee, error) {
+	name = strings.TrimSpace(name)
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil, errors.NewValidationError("name", "assignee name is empty after normalisation")
+	}
+	now := time.Now().UTC()
+	return &Assignee{
+		ID:             uuid.New(),
+		CanonicalName:  name,
+		NormalizedName: normalized,
+		Country:        strings.ToUpper(strings.TrimSpace(country)),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// Alias maps a normalised name to an assignee. Name keeps the raw spelling
+// the alias was first seen with.
+type Alias struct {
+	AssigneeID     uuid.UUID   `json:"assignee_id"`
+	Name           string      `json:"name"`
+	NormalizedName string      `json:"normalized_name"`
+	BlockingKeys   []string    `json:"-"`
+	Source         AliasSource `json:"source"`
+	Confidence     float64     `json:"confidence"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// NewAlias creates an alias of assigneeID for the raw name.
+func NewAlias(assigneeID uuid.UUID, name string, source AliasSource, confidence float64) (*Alias, error) {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil, errors.NewValidationError("name", "alias is empty after normalisation")
+	}
+	return &Alias{
+		AssigneeID:     assigneeID,
+		Name:           strings.TrimSpace(name),
+		NormalizedName: normalized,
+		BlockingKeys:   BlockingKeys(normalized),
+		Source:         source,
+		Confidence:     confidence,
+		CreatedAt:      time.Now().UTC(),
+	}, nil
+}
+
+// MergeReview proposes that RawName is the assignee CandidateID. Names with
+// a pending review stay unresolved until a reviewer decides; on approval the
+// name becomes an alias of the candidate, on rejection a new assignee is
+// created for it (ResolvedAssigneeID).
+type MergeReview struct {
+	ID                 uuid.UUID    `json:"id"`
+	RawName            string       `json:"raw_name"`
+	NormalizedName     string       `json:"normalized_name"`
+	CandidateID        uuid.UUID    `json:"candidate_id"`
+	CandidateName      string       `json:"candidate_name"`
+	Score              float64      `json:"score"`
+	Status             ReviewStatus `json:"status"`
+	ResolvedAssigneeID *uuid.UUID   `json:"resolved_assignee_id,omitempty"`
+	ReviewedBy         string       `json:"reviewed_by,omitempty"`
+	ReviewedAt         *time.Time   `json:"reviewed_at,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
+// NewMergeReview creates a pending review proposing candidate for rawName.
+func NewMergeReview(rawName string, candidate Candidate) *MergeReview {
+	return &MergeReview{
+		ID:             uuid.New(),
+		RawName:        strings.TrimSpace(rawName),
+		NormalizedName: Normalize(rawName),
+		CandidateID:    candidate.Assignee.ID,
+		CandidateName:  candidate.Assignee.CanonicalName,
+		Score:          candidate.Score,
+		Status:         ReviewPending,
+		CreatedAt:      time.Now().UTC(),
+	}
+}
+
+// Approve records a reviewer's acceptance of the merge.
+func (r *MergeReview) Approve(reviewer string) error {
+	if r.Status != ReviewPending {
+		return errors.Newf(errors.ErrCodeConflict, "review %s is already %s", r.ID, r.Status)
+	}
+	now := time.Now().UTC()
+	id := r.CandidateID
+	r.Status, r.ResolvedAssigneeID, r.ReviewedBy, r.ReviewedAt = ReviewApproved, &id, reviewer, &now
+	return nil
+}
+
+// Reject records a reviewer's refusal; the name resolves to assigneeID.
+func (r *MergeReview) Reject(reviewer string, assigneeID uuid.UUID) error {
+	if r.Status != ReviewPending {
+		return errors.Newf(errors.ErrCodeConflict, "review %s is already %s", r.ID, r.Status)
+	}
+	now := time.Now().UTC()
+	r.Status, r.ResolvedAssigneeID, r.ReviewedBy, r.ReviewedAt = ReviewRejected, &assigneeID, reviewer, &now
+	return nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,57 @@
+package assignee
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func TestNewAssignee(t *testing.T) {
+	a, err := NewAssignee("  LG Chem, Ltd. ", "kr")
+	require.NoError(t, err)
+	assert.Equal(t, "LG Chem, Ltd.", a.CanonicalName)
+	assert.Equal(t, "lg chem", a.NormalizedName)
+	assert.Equal(t, "KR", a.Country)
+	assert.NotEqual(t, uuid.Nil, a.ID)
+
+	_, err = NewAssignee(" .,; ", "")
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestNewAlias(t *testing.T) {
+	id := uuid.New()
+	alias, err := NewAlias(id, "乐金显示有限公司", AliasCurated, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "lg display", alias.NormalizedName)
+	assert.Equal(t, BlockingKeys("lg display"), alias.BlockingKeys)
+	assert.Equal(t, id, alias.AssigneeID)
+}
+
+func TestMergeReview_Lifecycle(t *testing.T) {
+	candidate, err := NewAssignee("Samsung Display Co., Ltd.", "KR")
+	require.NoError(t, err)
+	r := NewMergeReview("Samsung Disp. Co", Candidate{Assignee: candidate, Score: 0.85})
+	assert.Equal(t, ReviewPending, r.Status)
+	assert.Equal(t, "samsung disp", r.NormalizedName)
+	assert.Equal(t, candidate.ID, r.CandidateID)
+
+	require.NoError(t, r.Approve("u-1"))
+	assert.Equal(t, ReviewApproved, r.Status)
+	require.NotNil(t, r.ResolvedAssigneeID)
+	assert.Equal(t, candidate.ID, *r.ResolvedAssigneeID)
+	assert.NotNil(t, r.ReviewedAt)
+
+	err = r.Reject("u-2", uuid.New())
+	assert.True(t, errors.IsConflict(err))
+	assert.Equal(t, "u-1", r.ReviewedBy)
+}
+
+func TestReviewStatus_IsValid(t *testing.T) {
+	assert.True(t, ReviewPending.IsValid())
+	assert.False(t, ReviewStatus("merged").IsValid())
+}
+
+//Personal.AI order the ending
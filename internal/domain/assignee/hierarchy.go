@@ -0,0 +1,96 @@
+package assignee
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MaxHierarchyDepth bounds parent chains; corporate structures deeper than
+// this are almost certainly data errors.
+const MaxHierarchyDepth = 16
+
+// ErrHierarchyCycle is returned when a parent link would make an assignee
+// its own ancestor.
+var ErrHierarchyCycle = errors.New(errors.ErrCodeValidation, "parent link would create a cycle in the assignee hierarchy")
+
+// Hierarchy is an in-memory view of parent/subsidiary links.
+type Hierarchy struct {
+	parent   map[uuid.UUID]uuid.UUID
+	children map[uuid.UUID][]uuid.UUID
+}
+
+// NewHierarchy builds a hierarchy from assignees' ParentID links.
+func NewHierarchy(assignees []*Assignee) *Hierarchy {
+	h := &Hierarchy{
+		parent:   make(map[uuid.UUID]uuid.UUID),
+		children: make(map[uuid.UUID][]uuid.UUID),
+	}
+	for _, a := range assignees {
+		if a.ParentID != nil {
+			h.parent[a.ID] = *a.ParentID
+			h.children[*a.ParentID] = append(h.children[*a.ParentID], a.ID)
+		}
+	}
+	return h
+}
+
+// Ancestors returns id's parent, grandparent and so on, nearest first.
+func (h *Hierarchy) Ancestors(id uuid.UUID) []uuid.UUID {
+	var out []uuid.UUID
+	seen := map[uuid.UUID]bool{id: true}
+	for cur, ok := h.parent[id]; ok && !seen[cur] && len(out) < MaxHierarchyDepth; cur, ok = h.parent[cur] {
+		seen[cur] = true
+		out = append(out, cur)
+	}
+	return out
+}
+
+// UltimateParent returns the top of id's chain, or id itself.
+func (h *Hierarchy) UltimateParent(id uuid.UUID) uuid.UUID {
+	if anc := h.Ancestors(id); len(anc) > 0 {
+		return anc[len(anc)-1]
+	}
+	return id
+}
+
+// Descendants returns every subsidiary below id, breadth first.
+func (h *Hierarchy) Descendants(id uuid.UUID) []uuid.UUID {
+	var out []uuid.UUID
+	seen := map[uuid.UUID]bool{id: true}
+	queue := []uuid.UUID{id}
+	for depth := 0; len(queue) > 0 && depth < MaxHierarchyDepth; depth++ {
+		var next []uuid.UUID
+		for _, cur := range queue {
+			for _, child := range h.children[cur] {
+				if !seen[child] {
+					seen[child] = true
+					out = append(out, child)
+					next = append(next, child)
+				}
+			}
+		}
+		queue = next
+	}
+	return out
+}
+
+// ValidateParent checks that making parentID the parent of childID keeps
+// the hierarchy acyclic and within MaxHierarchyDepth.
+func (h *Hierarchy) ValidateParent(childID, parentID uuid.UUID) error {
+	if childID == parentID {
+		return ErrHierarchyCycle
+	}
+	ancestors := h.Ancestors(parentID)
+	for _, a := range ancestors {
+		if a == childID {
+			return ErrHierarchyCycle
+		}
+	}
+	if len(ancestors)+1 >= MaxHierarchyDepth {
+		return errors.NewValidationError("parent_id", "assignee hierarchy is too deep")
+	}
+	return nil
+}
+
+//Personal.AI order the ending
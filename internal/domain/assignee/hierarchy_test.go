@@ -0,0 +1,68 @@
+package assignee
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func link(child *Assignee, parent *Assignee) {
+	id := parent.ID
+	child.ParentID = &id
+}
+
+func TestHierarchy(t *testing.T) {
+	group := &Assignee{ID: uuid.New()}
+	electronics := &Assignee{ID: uuid.New()}
+	display := &Assignee{ID: uuid.New()}
+	sdi := &Assignee{ID: uuid.New()}
+	link(electronics, group)
+	link(display, electronics)
+	link(sdi, group)
+
+	h := NewHierarchy([]*Assignee{group, electronics, display, sdi})
+	assert.Equal(t, []uuid.UUID{electronics.ID, group.ID}, h.Ancestors(display.ID))
+	assert.Equal(t, group.ID, h.UltimateParent(display.ID))
+	assert.Equal(t, group.ID, h.UltimateParent(group.ID))
+	assert.ElementsMatch(t, []uuid.UUID{electronics.ID, sdi.ID, display.ID}, h.Descendants(group.ID))
+	assert.Empty(t, h.Descendants(display.ID))
+}
+
+func TestHierarchy_ValidateParent(t *testing.T) {
+	a, b, c := &Assignee{ID: uuid.New()}, &Assignee{ID: uuid.New()}, &Assignee{ID: uuid.New()}
+	link(b, a)
+	link(c, b)
+	h := NewHierarchy([]*Assignee{a, b, c})
+
+	assert.NoError(t, h.ValidateParent(c.ID, a.ID))
+	assert.Equal(t, ErrHierarchyCycle, h.ValidateParent(a.ID, c.ID))
+	assert.Equal(t, ErrHierarchyCycle, h.ValidateParent(a.ID, a.ID))
+}
+
+func TestHierarchy_TooDeep(t *testing.T) {
+	chain := make([]*Assignee, MaxHierarchyDepth)
+	for i := range chain {
+		chain[i] = &Assignee{ID: uuid.New()}
+		if i > 0 {
+			link(chain[i], chain[i-1])
+		}
+	}
+	h := NewHierarchy(chain)
+
+	err := h.ValidateParent(uuid.New(), chain[len(chain)-1].ID)
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestHierarchy_ToleratesStoredCycle(t *testing.T) {
+	a, b := &Assignee{ID: uuid.New()}, &Assignee{ID: uuid.New()}
+	link(a, b)
+	link(b, a)
+	h := NewHierarchy([]*Assignee{a, b})
+
+	assert.Equal(t, []uuid.UUID{b.ID}, h.Ancestors(a.ID))
+	assert.Equal(t, []uuid.UUID{b.ID}, h.Descendants(a.ID))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,170 @@
+package assignee
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cjkLegalSuffixes are company-form markers in Chinese, Japanese and Korean
+// names. They are removed before transliteration because they are not
+// separated by spaces. Longer forms come first.
+var cjkLegalSuffixes = []string{
+	"股份有限公司", "有限责任公司", "有限責任公司", "有限公司", "株式会社", "有限会社", "合同会社",
+	"集团公司", "集團公司", "总公司", "總公司", "公司", "주식회사", "(주)", "㈜", "유한회사",
+}
+
+// transliterations map CJK company-name components onto the Latin forms the
+// same companies use in English filings. Only components seen in the OLED
+// and display materials field are listed; anything else is resolved through
+// the curated alias table.
+var transliterations = map[string]string{
+	"三星":    "samsung",
+	"삼성":    "samsung",
+	"显示":    "display",
+	"顯示":    "display",
+	"디스플레이": "display",
+	"电子":    "electronics",
+	"電子":    "electronics",
+	"전자":    "electronics",
+	"乐金":    "lg",
+	"樂金":    "lg",
+	"엘지":    "lg",
+	"京东方":   "boe",
+	"京東方":   "boe",
+	"科技":    "technology",
+	"技术":    "technology",
+	"技術":    "technology",
+	"集团":    "group",
+	"集團":    "group",
+	"化学":    "chemical",
+	"化學":    "chemical",
+	"화학":    "chemical",
+	"材料":    "materials",
+	"住友":    "sumitomo",
+	"出光兴产":  "idemitsu kosan",
+	"出光興産":  "idemitsu kosan",
+	"默克":    "merck",
+	"华为":    "huawei",
+	"華為":    "huawei",
+	"天马":    "tianma",
+	"天馬":    "tianma",
+	"维信诺":   "visionox",
+	"維信諾":   "visionox",
+	"华星光电":  "csot",
+	"華星光電":  "csot",
+	"德山":    "duksan",
+	"덕산":    "duksan",
+	"光电":    "optoelectronics",
+	"光電":    "optoelectronics",
+	"微电子":   "microelectronics",
+	"微電子":   "microelectronics",
+	"专利":    "patent",
+	"專利":    "patent",
+	"深圳市":   "shenzhen",
+	"네오룩스":  "neolux",
+}
+
+// transliterationKeys holds the keys of transliterations, longest first, so
+// that 出光兴产 wins over any shorter overlapping component.
+var transliterationKeys = func() []string {
+	keys := make([]string, 0, len(transliterations))
+	for k := range transliterations {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if li, lj := len([]rune(keys[i])), len([]rune(keys[j])); li != lj {
+			return li > lj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// legalSuffixTokens are Latin company-form tokens removed from the end of a
+// name (and "the" from the start).
+var legalSuffixTokens = map[string]bool{
+	"co": true, "company": true, "corp": true, "corporation": true, "inc": true,
+	"incorporated": true, "ltd": true, "limited": true, "llc": true, "llp": true,
+	"lp": true, "plc": true, "gmbh": true, "ag": true, "kg": true, "kgaa": true,
+	"mbh": true, "sa": true, "sas": true, "sarl": true, "nv": true, "bv": true,
+	"spa": true, "srl": true, "oy": true, "ab": true, "as": true, "asa": true,
+	"pte": true, "pty": true, "kk": true, "kabushiki": true, "kaisha": true,
+	"yugen": true, "gaisha": true, "se": true,
+}
+
+// Normalize reduces an assignee name to the form used for matching:
+// compatibility-folded (full-width letters become ASCII), accents stripped,
+// CJK legal forms removed and known components transliterated, lower-cased,
+// punctuation collapsed to single spaces and trailing legal-form tokens
+// dropped. "Samsung Display Co., Ltd.", "SAMSUNG DISPLAY CO LTD" and
+// "三星显示有限公司" all normalise to "samsung display".
+func Normalize(name string) string {
+	s := norm.NFKC.String(strings.TrimSpace(name))
+	s = stripMarks(s)
+	s = strings.ToLower(s)
+
+	for _, suffix := range cjkLegalSuffixes {
+		s = strings.ReplaceAll(s, suffix, " ")
+	}
+	for _, k := range transliterationKeys {
+		if strings.Contains(s, k) {
+			s = strings.ReplaceAll(s, k, " "+transliterations[k]+" ")
+		}
+	}
+	s = strings.ReplaceAll(s, "&", " and ")
+
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else if r != '\'' && r != '’' {
+			b.WriteByte(' ')
+		}
+	}
+
+	tokens := joinInitials(strings.Fields(b.String()))
+	for len(tokens) > 1 && legalSuffixTokens[tokens[len(tokens)-1]] {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) > 1 && tokens[0] == "the" {
+		tokens = tokens[1:]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// joinInitials merges runs of single-letter tokens, so the "s a" left by
+// "S.A." becomes "sa" and "i b m" becomes "ibm".
+func joinInitials(tokens []string) []string {
+	out := tokens[:0]
+	for i := 0; i < len(tokens); {
+		j := i
+		for j < len(tokens) && len([]rune(tokens[j])) == 1 {
+			j++
+		}
+		if j-i > 1 {
+			out = append(out, strings.Join(tokens[i:j], ""))
+			i = j
+			continue
+		}
+		out = append(out, tokens[i])
+		i++
+	}
+	return out
+}
+
+// stripMarks removes combining marks after canonical decomposition, turning
+// "Société" into "Societe".
+func stripMarks(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,45 @@
+package assignee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize_CollapsesVariants(t *testing.T) {
+	for _, name := range []string{
+		"Samsung Display Co., Ltd.",
+		"SAMSUNG DISPLAY CO LTD",
+		"Samsung Display Co.,Ltd",
+		"三星显示有限公司",
+		"삼성디스플레이 주식회사",
+		"ＳＡＭＳＵＮＧ　ＤＩＳＰＬＡＹ",
+	} {
+		assert.Equal(t, "samsung display", Normalize(name), name)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"出光興産株式会社":                 "idemitsu kosan",
+		"京东方科技集团股份有限公司":            "boe technology group",
+		"Merck Patent GmbH":        "merck patent",
+		"Société Générale S.A.":    "societe generale",
+		"The Dow Chemical Company": "dow chemical",
+		"Johnson & Johnson":        "johnson and johnson",
+		"I.B.M. Corp.":             "ibm",
+		"L'Oréal":                  "loreal",
+		"  ":                       "",
+		"Co., Ltd.":                "co",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, Normalize(in), in)
+	}
+}
+
+func TestNormalize_KeepsUnknownCJK(t *testing.T) {
+	// Unknown components stay in their script so curated aliases can match.
+	assert.Equal(t, "某某 display", Normalize("某某显示有限公司"))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,45 @@
+package assignee
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository persists assignees, their aliases and the merge review queue,
+// and writes resolved assignee IDs back onto patents.
+type Repository interface {
+	// Assignees
+	Create(ctx context.Context, a *Assignee) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Assignee, error)
+	// ListAll returns every assignee with ParentID set, for building a
+	// Hierarchy. Aliases are not loaded.
+	ListAll(ctx context.Context) ([]*Assignee, error)
+	SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error
+
+	// Aliases. FindByAlias returns nil, nil when no alias matches.
+	AddAlias(ctx context.Context, alias *Alias) error
+	FindByAlias(ctx context.Context, normalizedName string) (*Assignee, error)
+	// FindCandidates returns up to limit assignees having an alias that
+	// shares a blocking key, with Aliases loaded.
+	FindCandidates(ctx context.Context, blockingKeys []string, limit int) ([]*Assignee, error)
+
+	// Review queue. FindPendingReview returns nil, nil when there is none.
+	CreateReview(ctx context.Context, r *MergeReview) error
+	GetReview(ctx context.Context, id uuid.UUID) (*MergeReview, error)
+	FindPendingReview(ctx context.Context, normalizedName string) (*MergeReview, error)
+	ListReviews(ctx context.Context, status ReviewStatus, limit, offset int) ([]*MergeReview, int64, error)
+	UpdateReview(ctx context.Context, r *MergeReview) error
+
+	// Backfill. ListUnresolvedNames returns distinct assignee names of
+	// patents without an assignee ID, ordered and strictly after the cursor.
+	ListUnresolvedNames(ctx context.Context, after string, limit int) ([]string, error)
+	// AssignPatents sets assignee_id on patents with assignee name rawName
+	// that have none, returning the number updated.
+	AssignPatents(ctx context.Context, rawName string, assigneeID uuid.UUID) (int64, error)
+	// ListPatentIDs returns up to limit IDs of patents assigned to any of
+	// assigneeIDs, most recently filed first.
+	ListPatentIDs(ctx context.Context, assigneeIDs []uuid.UUID, limit int) ([]string, error)
+}
+
+//Personal.AI order the ending
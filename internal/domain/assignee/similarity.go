@@ -0,0 +1,195 @@
+package assignee
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultAutoMergeThreshold is the score at or above which a name is
+	// merged into an existing assignee without review.
+	DefaultAutoMergeThreshold = 0.93
+	// DefaultReviewThreshold is the score at or above which a merge is
+	// proposed for review instead of creating a new assignee.
+	DefaultReviewThreshold = 0.80
+
+	blockingPrefixLen  = 4
+	jaroWinklerPrefix  = 4
+	jaroWinklerScaling = 0.1
+	jaroWinklerWeight  = 0.6
+	tokenSetWeight     = 0.4
+	softTokenMatch     = 0.9
+)
+
+// BlockingKeys returns the keys used to fetch merge candidates for a
+// normalised name. Two names are only compared when they share a key, which
+// keeps resolution from scoring the whole assignee table. The keys are the
+// first token, the longest token and the first letters of the name with
+// spaces removed.
+func BlockingKeys(normalized string) []string {
+	tokens := strings.Fields(normalized)
+	if len(tokens) == 0 {
+		return nil
+	}
+	keys := []string{"t:" + tokens[0]}
+
+	longest := tokens[0]
+	for _, t := range tokens[1:] {
+		if len([]rune(t)) > len([]rune(longest)) {
+			longest = t
+		}
+	}
+	if longest != tokens[0] {
+		keys = append(keys, "t:"+longest)
+	}
+
+	compact := []rune(strings.Join(tokens, ""))
+	if len(compact) > blockingPrefixLen {
+		compact = compact[:blockingPrefixLen]
+	}
+	keys = append(keys, "p:"+string(compact))
+	return keys
+}
+
+// Score rates how likely two normalised names denote the same company, in
+// [0, 1]. It blends Jaro-Winkler similarity, which tolerates typos and
+// truncation, with token-set overlap, which tolerates reordering.
+func Score(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	return jaroWinklerWeight*JaroWinkler(a, b) + tokenSetWeight*tokenSetSimilarity(a, b)
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b.
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(len(ra), len(rb))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, k := 0, 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[k] {
+			k++
+		}
+		if ra[i] != rb[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(jaroWinklerPrefix, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*jaroWinklerScaling*(1-jaro)
+}
+
+// tokenSetSimilarity is a soft Jaccard similarity of the two names' token
+// sets: tokens count as shared when their Jaro-Winkler similarity reaches
+// softTokenMatch, so "display" and "displays" still overlap.
+func tokenSetSimilarity(a, b string) float64 {
+	ta, tb := uniqueTokens(a), uniqueTokens(b)
+	used := make([]bool, len(tb))
+	inter := 0
+	for _, x := range ta {
+		best, bestJ := 0.0, -1
+		for j, y := range tb {
+			if used[j] {
+				continue
+			}
+			if s := JaroWinkler(x, y); s > best {
+				best, bestJ = s, j
+			}
+		}
+		if bestJ >= 0 && best >= softTokenMatch {
+			used[bestJ] = true
+			inter++
+		}
+	}
+	union := len(ta) + len(tb) - inter
+	if union == 0 {
+		return 0
+	}
+	return float64(inter) / float64(union)
+}
+
+func uniqueTokens(s string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range strings.Fields(s) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Candidate is an existing assignee scored against a name being resolved.
+type Candidate struct {
+	Assignee *Assignee
+	// Score is the best score against the canonical name or any alias.
+	Score float64
+	// MatchedName is the normalised name that produced Score.
+	MatchedName string
+}
+
+// RankCandidates scores normalized against every candidate's canonical and
+// alias names and returns them best first. Ties keep the older assignee
+// first so merges are deterministic.
+func RankCandidates(normalized string, assignees []*Assignee) []Candidate {
+	ranked := make([]Candidate, 0, len(assignees))
+	for _, a := range assignees {
+		best := Candidate{Assignee: a, Score: Score(normalized, a.NormalizedName), MatchedName: a.NormalizedName}
+		for _, alias := range a.Aliases {
+			if s := Score(normalized, alias); s > best.Score {
+				best.Score, best.MatchedName = s, alias
+			}
+		}
+		ranked = append(ranked, best)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Assignee.CreatedAt.Before(ranked[j].Assignee.CreatedAt)
+	})
+	return ranked
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,64 @@
+package assignee
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockingKeys(t *testing.T) {
+	assert.Equal(t, []string{"t:lg", "t:display", "p:lgdi"}, BlockingKeys("lg display"))
+	assert.Equal(t, []string{"t:udc", "p:udc"}, BlockingKeys("udc"))
+	assert.Nil(t, BlockingKeys(""))
+}
+
+func TestBlockingKeys_SharedByVariants(t *testing.T) {
+	a, b := BlockingKeys(Normalize("Samsung Display Co., Ltd.")), BlockingKeys(Normalize("Samsung Displays Co"))
+	assert.Contains(t, b, a[0])
+}
+
+func TestJaroWinkler(t *testing.T) {
+	assert.InDelta(t, 0.961, JaroWinkler("martha", "marhta"), 0.001)
+	assert.InDelta(t, 0.840, JaroWinkler("dwayne", "duane"), 0.001)
+	assert.Equal(t, 1.0, JaroWinkler("", ""))
+	assert.Equal(t, 0.0, JaroWinkler("abc", ""))
+	assert.Equal(t, 0.0, JaroWinkler("abc", "xyz"))
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, 1.0, Score("samsung display", "samsung display"))
+	assert.Equal(t, 0.0, Score("", "samsung display"))
+
+	typo := Score("samsung display", "samsung displays")
+	parent := Score("samsung display", "samsung")
+	sibling := Score("lg display", "lg chem")
+	assert.GreaterOrEqual(t, typo, DefaultAutoMergeThreshold)
+	assert.Less(t, parent, DefaultReviewThreshold)
+	assert.Less(t, sibling, DefaultReviewThreshold)
+	assert.Equal(t, Score("display samsung", "samsung display"), Score("samsung display", "display samsung"))
+}
+
+func TestRankCandidates(t *testing.T) {
+	now := time.Now()
+	chem := &Assignee{NormalizedName: "lg chem", CreatedAt: now}
+	display := &Assignee{NormalizedName: "lg display", Aliases: []string{"lg philips lcd"}, CreatedAt: now}
+
+	ranked := RankCandidates("lg philips lcds", []*Assignee{chem, display})
+	require.Len(t, ranked, 2)
+	assert.Same(t, display, ranked[0].Assignee)
+	assert.Equal(t, "lg philips lcd", ranked[0].MatchedName)
+	assert.Greater(t, ranked[0].Score, ranked[1].Score)
+}
+
+func TestRankCandidates_TieKeepsOlder(t *testing.T) {
+	now := time.Now()
+	older := &Assignee{NormalizedName: "acme", CreatedAt: now.Add(-time.Hour)}
+	newer := &Assignee{NormalizedName: "acme", CreatedAt: now}
+
+	ranked := RankCandidates("acme", []*Assignee{newer, older})
+	assert.Same(t, older, ranked[0].Assignee)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,324 @@
+package citation
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageRankIterations = 20
+	DefaultDampingFactor      = 0.85
+	DefaultHITSIterations     = 50
+	DefaultTolerance          = 1e-6
+
+	// EngineGDS and EngineInProcess identify which engine produced the metrics.
+	EngineGDS       = "gds"
+	EngineInProcess = "in-process"
+
+	daysPerYear = 365.25
+)
+
+// AnalyticsOptions tunes the in-process citation analytics engine. Zero
+// values fall back to the defaults above.
+type AnalyticsOptions struct {
+	PageRankIterations int
+	DampingFactor      float64
+	HITSIterations     int
+	Tolerance          float64
+}
+
+func (o AnalyticsOptions) withDefaults() AnalyticsOptions {
+	if o.PageRankIterations <= 0 {
+		o.PageRankIterations = DefaultPageRankIterations
+	}
+	if o.DampingFactor <= 0 || o.DampingFactor >= 1 {
+		o.DampingFactor = DefaultDampingFactor
+	}
+	if o.HITSIterations <= 0 {
+		o.HITSIterations = DefaultHITSIterations
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = DefaultTolerance
+	}
+	return o
+}
+
+// PatentCitationMetrics holds the network metrics written back to a patent node.
+//
+// CitationHalfLife is the median age in years of the citations a patent has
+// received; TechCycleTime is the median age in years of the patents it cites.
+// Both are nil when filing dates are missing or there are no such citations.
+type PatentCitationMetrics struct {
+	PatentID         uuid.UUID  `json:"patent_id"`
+	PageRank         float64    `json:"pagerank"`
+	HubScore         float64    `json:"hub_score"`
+	AuthorityScore   float64    `json:"authority_score"`
+	CitationHalfLife *float64   `json:"citation_half_life,omitempty"`
+	TechCycleTime    *float64   `json:"tech_cycle_time,omitempty"`
+	ComputedAt       *time.Time `json:"computed_at,omitempty"`
+}
+
+// CitationMetricsSummary describes a completed analytics run.
+type CitationMetricsSummary struct {
+	Engine             string    `json:"engine"`
+	NodeCount          int       `json:"node_count"`
+	EdgeCount          int       `json:"edge_count"`
+	PageRankIterations int       `json:"pagerank_iterations"`
+	HITSIterations     int       `json:"hits_iterations"`
+	ComputedAt         time.Time `json:"computed_at"`
+}
+
+// CitationMetricsResult is the output of ComputeCitationMetrics.
+type CitationMetricsResult struct {
+	Metrics            []*PatentCitationMetrics
+	PageRankIterations int
+	HITSIterations     int
+}
+
+// CitationGraph is a compact in-memory directed citation graph. An edge
+// from A to B means A cites B. Parallel edges and self-citations are dropped.
+type CitationGraph struct {
+	ids    []uuid.UUID
+	index  map[uuid.UUID]int
+	filing []*time.Time
+	out    [][]int
+	in     [][]int
+	edges  map[[2]int]struct{}
+}
+
+func NewCitationGraph() *CitationGraph {
+	return &CitationGraph{
+		index: make(map[uuid.UUID]int),
+		edges: make(map[[2]int]struct{}),
+	}
+}
+
+// AddPatent adds a node, or sets the filing date of an existing node when
+// filingDate is non-nil. It returns the node's dense index.
+func (g *CitationGraph) AddPatent(id uuid.UUID, filingDate *time.Time) int {
+	if i, ok := g.index[id]; ok {
+		if filingDate != nil {
+			g.filing[i] = filingDate
+		}
+		return i
+	}
+	i := len(g.ids)
+	g.index[id] = i
+	g.ids = append(g.ids, id)
+	g.filing = append(g.filing, filingDate)
+	g.out = append(g.out, nil)
+	g.in = append(g.in, nil)
+	return i
+}
+
+// AddCitation records that from cites to, adding either node if needed.
+func (g *CitationGraph) AddCitation(from, to uuid.UUID) {
+	if from == to {
+		return
+	}
+	f, t := g.AddPatent(from, nil), g.AddPatent(to, nil)
+	key := [2]int{f, t}
+	if _, dup := g.edges[key]; dup {
+		return
+	}
+	g.edges[key] = struct{}{}
+	g.out[f] = append(g.out[f], t)
+	g.in[t] = append(g.in[t], f)
+}
+
+func (g *CitationGraph) NodeCount() int { return len(g.ids) }
+
+func (g *CitationGraph) EdgeCount() int { return len(g.edges) }
+
+// ComputeCitationMetrics runs PageRank, HITS, citation half-life and
+// technology cycle time over the whole graph. Metrics are returned in node
+// insertion order.
+func ComputeCitationMetrics(ctx context.Context, g *CitationGraph, opts AnalyticsOptions) (*CitationMetricsResult, error) {
+	opts = opts.withDefaults()
+	n := g.NodeCount()
+	res := &CitationMetricsResult{Metrics: make([]*PatentCitationMetrics, n)}
+	if n == 0 {
+		return res, nil
+	}
+
+	pr, prIters, err := g.pageRank(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	hubs, auths, hitsIters, err := g.hits(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	res.PageRankIterations, res.HITSIterations = prIters, hitsIters
+
+	for i := 0; i < n; i++ {
+		res.Metrics[i] = &PatentCitationMetrics{
+			PatentID:         g.ids[i],
+			PageRank:         pr[i],
+			HubScore:         hubs[i],
+			AuthorityScore:   auths[i],
+			CitationHalfLife: g.medianAge(i, g.in[i], true),
+			TechCycleTime:    g.medianAge(i, g.out[i], false),
+		}
+	}
+	return res, nil
+}
+
+// pageRank is the power iteration with dangling-node mass spread uniformly,
+// so scores always sum to 1. Rank flows from citing to cited patents.
+func (g *CitationGraph) pageRank(ctx context.Context, opts AnalyticsOptions) ([]float64, int, error) {
+	n := g.NodeCount()
+	d := opts.DampingFactor
+	rank := make([]float64, n)
+	next := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	iter := 0
+	for iter < opts.PageRankIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, iter, err
+		}
+		iter++
+
+		dangling := 0.0
+		for i, outs := range g.out {
+			if len(outs) == 0 {
+				dangling += rank[i]
+			}
+		}
+		base := (1-d)/float64(n) + d*dangling/float64(n)
+		for i := range next {
+			next[i] = base
+		}
+		for i, outs := range g.out {
+			if len(outs) == 0 {
+				continue
+			}
+			share := d * rank[i] / float64(len(outs))
+			for _, j := range outs {
+				next[j] += share
+			}
+		}
+
+		delta := 0.0
+		for i := range rank {
+			delta += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if delta < opts.Tolerance {
+			break
+		}
+	}
+	return rank, iter, nil
+}
+
+// hits computes Kleinberg hub and authority scores, each normalised to unit
+// L2 length. A good authority is cited by good hubs; a good hub cites good
+// authorities.
+func (g *CitationGraph) hits(ctx context.Context, opts AnalyticsOptions) ([]float64, []float64, int, error) {
+	n := g.NodeCount()
+	hubs := make([]float64, n)
+	auths := make([]float64, n)
+	nextHubs := make([]float64, n)
+	nextAuths := make([]float64, n)
+	for i := range hubs {
+		hubs[i] = 1
+	}
+	if g.EdgeCount() == 0 {
+		return make([]float64, n), auths, 0, nil
+	}
+
+	iter := 0
+	for iter < opts.HITSIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, iter, err
+		}
+		iter++
+
+		for j, ins := range g.in {
+			sum := 0.0
+			for _, i := range ins {
+				sum += hubs[i]
+			}
+			nextAuths[j] = sum
+		}
+		normalizeL2(nextAuths)
+		for i, outs := range g.out {
+			sum := 0.0
+			for _, j := range outs {
+				sum += nextAuths[j]
+			}
+			nextHubs[i] = sum
+		}
+		normalizeL2(nextHubs)
+
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			delta += math.Abs(nextHubs[i]-hubs[i]) + math.Abs(nextAuths[i]-auths[i])
+		}
+		hubs, nextHubs = nextHubs, hubs
+		auths, nextAuths = nextAuths, auths
+		if delta < opts.Tolerance {
+			break
+		}
+	}
+	return hubs, auths, iter, nil
+}
+
+// medianAge returns the median filing-date gap in years between node i and
+// its neighbours. forward selects citing patents (neighbour filed later);
+// otherwise cited patents (neighbour filed earlier). Negative gaps, which
+// only arise from bad data, are ignored.
+func (g *CitationGraph) medianAge(i int, neighbours []int, forward bool) *float64 {
+	own := g.filing[i]
+	if own == nil || len(neighbours) == 0 {
+		return nil
+	}
+	ages := make([]float64, 0, len(neighbours))
+	for _, j := range neighbours {
+		other := g.filing[j]
+		if other == nil {
+			continue
+		}
+		gap := own.Sub(*other)
+		if forward {
+			gap = -gap
+		}
+		if gap < 0 {
+			continue
+		}
+		ages = append(ages, gap.Hours()/24/daysPerYear)
+	}
+	if len(ages) == 0 {
+		return nil
+	}
+	sort.Float64s(ages)
+	mid := len(ages) / 2
+	m := ages[mid]
+	if len(ages)%2 == 0 {
+		m = (ages[mid-1] + ages[mid]) / 2
+	}
+	return &m
+}
+
+func normalizeL2(v []float64) {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+//Personal.AI order the ending
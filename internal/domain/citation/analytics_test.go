@@ -0,0 +1,112 @@
+package citation
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func date(year int) *time.Time {
+	t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestComputeCitationMetrics_PageRankAndHITS(t *testing.T) {
+	// a, b and c all cite hub-cited d; a also cites c.
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	g := NewCitationGraph()
+	g.AddCitation(a, d)
+	g.AddCitation(b, d)
+	g.AddCitation(c, d)
+	g.AddCitation(a, c)
+	g.AddCitation(a, c) // duplicate
+	g.AddCitation(a, a) // self-citation
+
+	if g.NodeCount() != 4 || g.EdgeCount() != 4 {
+		t.Fatalf("expected 4 nodes and 4 edges, got %d and %d", g.NodeCount(), g.EdgeCount())
+	}
+
+	res, err := ComputeCitationMetrics(context.Background(), g, AnalyticsOptions{PageRankIterations: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byID := make(map[uuid.UUID]*PatentCitationMetrics)
+	sum := 0.0
+	for _, m := range res.Metrics {
+		byID[m.PatentID] = m
+		sum += m.PageRank
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("expected PageRank to sum to 1, got %f", sum)
+	}
+	if byID[d].PageRank <= byID[c].PageRank || byID[c].PageRank <= byID[b].PageRank {
+		t.Errorf("expected d > c > b, got %f %f %f", byID[d].PageRank, byID[c].PageRank, byID[b].PageRank)
+	}
+	if byID[d].AuthorityScore <= byID[c].AuthorityScore {
+		t.Errorf("expected d to be the top authority")
+	}
+	if byID[a].HubScore <= byID[b].HubScore || byID[d].HubScore != 0 {
+		t.Errorf("unexpected hub scores a=%f b=%f d=%f", byID[a].HubScore, byID[b].HubScore, byID[d].HubScore)
+	}
+	if res.PageRankIterations == 0 || res.HITSIterations == 0 {
+		t.Errorf("expected iteration counts, got %+v", res)
+	}
+}
+
+func TestComputeCitationMetrics_HalfLifeAndCycleTime(t *testing.T) {
+	old, mid, recent, later, undated := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	g := NewCitationGraph()
+	g.AddPatent(old, date(2000))
+	g.AddPatent(mid, date(2004))
+	g.AddPatent(recent, date(2010))
+	g.AddPatent(later, date(2012))
+	g.AddCitation(mid, old)
+	g.AddCitation(recent, old)
+	g.AddCitation(later, old)
+	g.AddCitation(undated, old)
+	g.AddCitation(later, mid)
+
+	res, err := ComputeCitationMetrics(context.Background(), g, AnalyticsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byID := make(map[uuid.UUID]*PatentCitationMetrics)
+	for _, m := range res.Metrics {
+		byID[m.PatentID] = m
+	}
+
+	// Forward citation ages of old: 4, 10, 12 years -> median 10.
+	if hl := byID[old].CitationHalfLife; hl == nil || math.Abs(*hl-10) > 0.01 {
+		t.Errorf("expected half-life ~10, got %v", hl)
+	}
+	if byID[old].TechCycleTime != nil {
+		t.Errorf("expected no cycle time for a patent citing nothing")
+	}
+	// Backward citation ages of later: 12 and 8 years -> median 10.
+	if tct := byID[later].TechCycleTime; tct == nil || math.Abs(*tct-10) > 0.01 {
+		t.Errorf("expected cycle time ~10, got %v", tct)
+	}
+	if byID[undated].TechCycleTime != nil || byID[undated].CitationHalfLife != nil {
+		t.Errorf("expected no age metrics for an undated patent")
+	}
+}
+
+func TestComputeCitationMetrics_EmptyAndCancelled(t *testing.T) {
+	res, err := ComputeCitationMetrics(context.Background(), NewCitationGraph(), AnalyticsOptions{})
+	if err != nil || len(res.Metrics) != 0 {
+		t.Fatalf("expected empty result, got %v %v", res, err)
+	}
+
+	g := NewCitationGraph()
+	g.AddCitation(uuid.New(), uuid.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ComputeCitationMetrics(ctx, g, AnalyticsOptions{}); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+//Personal.AI order the ending
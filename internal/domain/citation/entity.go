@@ -11,7 +11,9 @@ type PatentNode struct {
 	PatentNumber string     `json:"patent_number"`
 	Jurisdiction string     `json:"jurisdiction"`
 	FilingDate   *time.Time `json:"filing_date,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
+	// AssigneeID is the canonical assignee ID, empty if not yet resolved.
+	AssigneeID string    `json:"assignee_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type PatentNodeData struct {
@@ -19,6 +21,8 @@ type PatentNodeData struct {
 	PatentNumber string
 	Jurisdiction string
 	FilingDate   *time.Time
+	// AssigneeID is the canonical assignee ID; empty keeps the stored one.
+	AssigneeID string
 }
 
 type CitationEdge struct {
@@ -50,6 +54,14 @@ type PatentWithCitationCount struct {
 	CitationCount int64       `json:"citation_count"`
 }
 
+// AssigneeWithCitationCount totals the citations received by the patents of
+// one canonical assignee, so name variants of a company count once.
+type AssigneeWithCitationCount struct {
+	AssigneeID    string `json:"assignee_id"`
+	PatentCount   int64  `json:"patent_count"`
+	CitationCount int64  `json:"citation_count"`
+}
+
 type CoCitationResult struct {
 	Patent      *PatentNode `json:"patent"`
 	CommonCount int64       `json:"common_count"`
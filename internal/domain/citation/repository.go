@@ -20,13 +20,21 @@ type CitationRepository interface {
 	GetCitationNetwork(ctx context.Context, patentID uuid.UUID, depth int) (*CitationNetwork, error)
 	GetCitationCount(ctx context.Context, patentID uuid.UUID) (*CitationStats, error)
 	GetMostCitedPatents(ctx context.Context, jurisdiction *string, limit int) ([]*PatentWithCitationCount, error)
+	// GetMostCitedAssignees groups patents by canonical assignee ID; patents
+	// without one are left out.
+	GetMostCitedAssignees(ctx context.Context, jurisdiction *string, limit int) ([]*AssigneeWithCitationCount, error)
 
 	GetCitationChain(ctx context.Context, fromPatentID, toPatentID uuid.UUID) ([]*CitationPath, error)
 	GetCoCitationPatents(ctx context.Context, patentID uuid.UUID, minCommonCitations int, limit int) ([]*CoCitationResult, error)
 	GetBibliographicCoupling(ctx context.Context, patentID uuid.UUID, minCommonReferences int, limit int) ([]*CouplingResult, error)
 
+	// CalculatePageRank uses the Neo4j GDS plugin when installed and falls
+	// back to the in-process engine (CalculateCitationMetrics) otherwise.
 	CalculatePageRank(ctx context.Context, iterations int, dampingFactor float64) error
 	GetTopPageRankPatents(ctx context.Context, limit int) ([]*PatentWithPageRank, error)
+
+	CalculateCitationMetrics(ctx context.Context, opts AnalyticsOptions) (*CitationMetricsSummary, error)
+	GetCitationMetrics(ctx context.Context, patentID uuid.UUID) (*PatentCitationMetrics, error)
 }
 
 //Personal.AI order the ending
@@ -0,0 +1,275 @@
+// Package classification models the CPC and IPC patent classification
+// schemes: symbol normalisation, the section → class → subclass → group →
+// dotted-subgroup tree loaded from the published title lists, IPC/CPC
+// concordance, and roll-ups that let portfolio and white-space analyses
+// aggregate codes at any level, e.g. count H10K 85/631 under H10K 85/60.
+package classification
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Scheme identifies a patent classification scheme.
+type Scheme string
+
+const (
+	// SchemeCPC is the Cooperative Patent Classification.
+	SchemeCPC Scheme = "cpc"
+	// SchemeIPC is the WIPO International Patent Classification.
+	SchemeIPC Scheme = "ipc"
+)
+
+// Level is a depth in the classification tree. Levels deeper than
+// LevelMainGroup are subgroups: LevelSubgroup is a one-dot subgroup,
+// LevelSubgroup+1 a two-dot subgroup, and so on.
+type Level int
+
+const (
+	LevelSection Level = iota + 1
+	LevelClass
+	LevelSubclass
+	LevelMainGroup
+	LevelSubgroup
+)
+
+// SubgroupLevel returns the level of a subgroup with the given number of
+// dots in the scheme.
+func SubgroupLevel(dots int) Level {
+	return LevelMainGroup + Level(max(dots, 1))
+}
+
+// String implements fmt.Stringer.
+func (l Level) String() string {
+	switch {
+	case l == LevelSection:
+		return "section"
+	case l == LevelClass:
+		return "class"
+	case l == LevelSubclass:
+		return "subclass"
+	case l == LevelMainGroup:
+		return "main_group"
+	case l == LevelSubgroup:
+		return "subgroup"
+	case l > LevelSubgroup:
+		return "subgroup." + strconv.Itoa(int(l-LevelMainGroup))
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel parses the output of Level.String. "group" is accepted for
+// main groups and "subgroup.N" for N-dot subgroups.
+func ParseLevel(s string) (Level, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "section":
+		return LevelSection, nil
+	case "class":
+		return LevelClass, nil
+	case "subclass":
+		return LevelSubclass, nil
+	case "main_group", "group":
+		return LevelMainGroup, nil
+	case "subgroup":
+		return LevelSubgroup, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "subgroup."); ok {
+		if dots, err := strconv.Atoi(rest); err == nil && dots > 0 {
+			return SubgroupLevel(dots), nil
+		}
+	}
+	return 0, errors.NewValidationError("level", "unknown classification level "+strconv.Quote(s))
+}
+
+// Code is a parsed classification symbol such as H10K 85/631. Fields below
+// the code's level are empty; a main group has Subgroup "00".
+type Code struct {
+	Section   string
+	Class     string
+	Subclass  string
+	MainGroup string
+	Subgroup  string
+}
+
+var (
+	// codePattern matches a compacted symbol: H, H10, H10K, H10K85 or
+	// H10K85/631.
+	codePattern = regexp.MustCompile(`^([A-HY])(?:(\d{2})(?:([A-Z])(?:(\d{1,4})(?:/(\d{2,6}))?)?)?)?$`)
+	// fixedPattern matches the 14-character form used in WIPO and EPO bulk
+	// files, e.g. H10K0085631000.
+	fixedPattern = regexp.MustCompile(`^([A-HY])(\d{2})([A-Z])(\d{4})(\d{6})$`)
+	// versionSuffix matches trailing version indicators: "(2023.01)" or a
+	// bare date such as "20230101".
+	versionSuffix = regexp.MustCompile(`(\s*\(\d{4}\.\d{2}\)|\s+\d{8})\s*$`)
+)
+
+// Parse parses a CPC or IPC symbol. It accepts the usual spellings —
+// "H10K 85/631", "H10K85/631", "h10k  85/631 (2023.01)" — and the
+// 14-character bulk form "H10K0085631000". A bare main group such as
+// "H10K85" is read as H10K85/00.
+func Parse(s string) (Code, error) {
+	raw := s
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = versionSuffix.ReplaceAllString(s, "")
+	s = strings.NewReplacer(" ", "", "\t", "", "-", "", "\u00a0", "").Replace(s)
+
+	var m []string
+	if f := fixedPattern.FindStringSubmatch(s); f != nil {
+		m = f
+	} else if m = codePattern.FindStringSubmatch(s); m == nil {
+		return Code{}, errors.NewValidationError("code", "invalid classification symbol "+strconv.Quote(raw))
+	}
+
+	c := Code{Section: m[1], Class: m[2], Subclass: m[3]}
+	if m[4] != "" {
+		group, _ := strconv.Atoi(m[4])
+		if group == 0 {
+			return Code{}, errors.NewValidationError("code", "invalid classification symbol "+strconv.Quote(raw)+": main group 0")
+		}
+		c.MainGroup = strconv.Itoa(group)
+		c.Subgroup = trimSubgroup(m[5])
+	}
+	return c, nil
+}
+
+// trimSubgroup drops the right padding of a subgroup, keeping at least
+// two digits: "631000" → "631", "000000" → "00".
+func trimSubgroup(s string) string {
+	s = strings.TrimRight(s, "0")
+	for len(s) < 2 {
+		s += "0"
+	}
+	return s
+}
+
+// Normalize returns the canonical compact spelling of a symbol, e.g.
+// "H10K 85/631" → "H10K85/631".
+func Normalize(s string) (string, error) {
+	c, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+// String returns the canonical compact spelling.
+func (c Code) String() string {
+	s := c.Section + c.Class + c.Subclass
+	if c.MainGroup != "" {
+		s += c.MainGroup + "/" + c.Subgroup
+	}
+	return s
+}
+
+// Display returns the spelling used in patent documents, with a space
+// between subclass and group: "H10K 85/631".
+func (c Code) Display() string {
+	if c.MainGroup == "" {
+		return c.String()
+	}
+	return c.Section + c.Class + c.Subclass + " " + c.MainGroup + "/" + c.Subgroup
+}
+
+// Level returns the code's structural level. Every subgroup reports
+// LevelSubgroup; only the scheme knows how many dots deep it really is
+// (see Hierarchy.Lookup).
+func (c Code) Level() Level {
+	switch {
+	case c.Class == "":
+		return LevelSection
+	case c.Subclass == "":
+		return LevelClass
+	case c.MainGroup == "":
+		return LevelSubclass
+	case c.Subgroup == "00":
+		return LevelMainGroup
+	default:
+		return LevelSubgroup
+	}
+}
+
+// Truncate returns the structural ancestor of c at level, which must be
+// LevelMainGroup or above. The second result is false when c is shallower
+// than level.
+func (c Code) Truncate(level Level) (Code, bool) {
+	if level > LevelMainGroup || level < LevelSection || c.Level() < level {
+		return Code{}, false
+	}
+	out := Code{Section: c.Section}
+	if level >= LevelClass {
+		out.Class = c.Class
+	}
+	if level >= LevelSubclass {
+		out.Subclass = c.Subclass
+	}
+	if level >= LevelMainGroup {
+		out.MainGroup, out.Subgroup = c.MainGroup, "00"
+	}
+	return out, true
+}
+
+// IsIndexing reports whether c belongs to a CPC 2000-series indexing
+// scheme, which has no IPC counterpart.
+func (c Code) IsIndexing() bool {
+	group, _ := strconv.Atoi(c.MainGroup)
+	return group >= 2000
+}
+
+// ValidFor reports whether c can exist in the scheme: section Y and the
+// 2000-series indexing codes are CPC-only.
+func (c Code) ValidFor(s Scheme) bool {
+	switch s {
+	case SchemeCPC:
+		return true
+	case SchemeIPC:
+		return c.Section != "Y" && !c.IsIndexing()
+	default:
+		return false
+	}
+}
+
+// Compare orders codes as they appear in the schemes. Subgroups sort as
+// decimal fractions, so 85/60 < 85/615 < 85/62.
+func Compare(a, b Code) int {
+	if r := strings.Compare(a.Section+a.Class+a.Subclass, b.Section+b.Class+b.Subclass); r != 0 {
+		return r
+	}
+	ag, _ := strconv.Atoi(a.MainGroup)
+	bg, _ := strconv.Atoi(b.MainGroup)
+	if ag != bg {
+		if ag < bg {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(padSubgroup(a.Subgroup), padSubgroup(b.Subgroup))
+}
+
+func padSubgroup(s string) string {
+	for len(s) < 6 {
+		s += "0"
+	}
+	return s
+}
+
+// compareStrings orders canonical code strings with Compare; strings that
+// do not parse sort last.
+func compareStrings(a, b string) int {
+	ca, errA := Parse(a)
+	cb, errB := Parse(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	}
+	return Compare(ca, cb)
+}
+
+//Personal.AI order the ending
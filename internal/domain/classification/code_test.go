@@ -0,0 +1,141 @@
+package classification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"H10K 85/631":          "H10K85/631",
+		"h10k  85/631":         "H10K85/631",
+		"H10K85/631 (2023.02)": "H10K85/631",
+		"H10K 85/631 20230201": "H10K85/631",
+		"H10K0085631000":       "H10K85/631",
+		"H10K0085000000":       "H10K85/00",
+		"C09K2211/1018":        "C09K2211/1018",
+		"C09K 2211/101800":     "C09K2211/1018",
+		"H10K85":               "H10K85/00",
+		"H10K 085/60":          "H10K85/60",
+		"H10K":                 "H10K",
+		"h10":                  "H10",
+		"Y":                    "Y",
+		"Y02E 10/549":          "Y02E10/549",
+		"A61K 31/4745\u00a0":   "A61K31/4745",
+		"G06F-16/00":           "G06F16/00",
+	}
+	for in, want := range cases {
+		got, err := Normalize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, bad := range []string{"", "Z01", "H1", "H10K0/00", "H10K85/6", "10K85/00", "H10K85/1234567", "IPC"} {
+		_, err := Normalize(bad)
+		assert.True(t, errors.IsValidation(err), bad)
+	}
+}
+
+func TestCode_Level(t *testing.T) {
+	cases := map[string]Level{
+		"H":          LevelSection,
+		"H10":        LevelClass,
+		"H10K":       LevelSubclass,
+		"H10K85/00":  LevelMainGroup,
+		"H10K85/631": LevelSubgroup,
+	}
+	for in, want := range cases {
+		c, err := Parse(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, c.Level(), in)
+	}
+}
+
+func TestCode_Truncate(t *testing.T) {
+	c, err := Parse("H10K 85/633")
+	require.NoError(t, err)
+
+	for level, want := range map[Level]string{
+		LevelSection:   "H",
+		LevelClass:     "H10",
+		LevelSubclass:  "H10K",
+		LevelMainGroup: "H10K85/00",
+	} {
+		got, ok := c.Truncate(level)
+		require.True(t, ok)
+		assert.Equal(t, want, got.String())
+	}
+	_, ok := c.Truncate(LevelSubgroup)
+	assert.False(t, ok, "subgroup depth is not structural")
+
+	sub, _ := Parse("H10K")
+	_, ok = sub.Truncate(LevelMainGroup)
+	assert.False(t, ok)
+	assert.Equal(t, "H10K 85/633", c.Display())
+}
+
+func TestCode_ValidFor(t *testing.T) {
+	for in, ipc := range map[string]bool{
+		"H10K85/631":    true,
+		"Y02E10/549":    false,
+		"C09K2211/1018": false,
+	} {
+		c, err := Parse(in)
+		require.NoError(t, err)
+		assert.True(t, c.ValidFor(SchemeCPC), in)
+		assert.Equal(t, ipc, c.ValidFor(SchemeIPC), in)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	codes := []string{"H10K85/62", "H10K85/615", "H10K85/60", "H10K50/00", "H10K85/6572", "H10K2101/00", "H10K85/654"}
+	want := []string{"H10K50/00", "H10K85/60", "H10K85/615", "H10K85/62", "H10K85/654", "H10K85/6572", "H10K2101/00"}
+	for i := range codes {
+		for j := range codes {
+			ci, _ := Parse(codes[i])
+			cj, _ := Parse(codes[j])
+			ri, rj := indexOf(want, codes[i]), indexOf(want, codes[j])
+			assert.Equal(t, sign(ri-rj), Compare(ci, cj), "%s vs %s", codes[i], codes[j])
+		}
+	}
+}
+
+func indexOf(s []string, v string) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range []Level{LevelSection, LevelClass, LevelSubclass, LevelMainGroup, LevelSubgroup, SubgroupLevel(3)} {
+		got, err := ParseLevel(l.String())
+		require.NoError(t, err)
+		assert.Equal(t, l, got)
+	}
+	got, err := ParseLevel("Group")
+	require.NoError(t, err)
+	assert.Equal(t, LevelMainGroup, got)
+	assert.Equal(t, "subgroup.2", SubgroupLevel(2).String())
+
+	_, err = ParseLevel("subgroup.0")
+	assert.True(t, errors.IsValidation(err))
+}
+
+//Personal.AI order the ending
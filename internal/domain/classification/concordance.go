@@ -0,0 +1,226 @@
+package classification
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// cpcOnly marks a concordance row whose CPC symbol has no IPC equivalent.
+const cpcOnly = "CPCONLY"
+
+// Concordance maps CPC symbols to IPC symbols. CPC was derived from IPC,
+// so most symbols are shared verbatim; the concordance lists only the
+// exceptions.
+type Concordance struct {
+	toIPC map[string]string
+	toCPC map[string][]string
+}
+
+// ParseConcordance reads "CPC <TAB> IPC" rows as in the published
+// CPC-to-IPC concordance. Further columns are ignored, an IPC value of
+// CPCONLY records that no equivalent exists, and a leading header row is
+// skipped.
+func ParseConcordance(r io.Reader) (*Concordance, error) {
+	cc := &Concordance{toIPC: make(map[string]string), toCPC: make(map[string][]string)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		cpc, err := Normalize(fields[0])
+		if err != nil {
+			if n == 1 {
+				continue
+			}
+			return nil, errors.Newf(errors.ErrCodeValidation, "concordance line %d: invalid CPC symbol %q", n, fields[0])
+		}
+		if len(fields) < 2 {
+			return nil, errors.Newf(errors.ErrCodeValidation, "concordance line %d: missing IPC column", n)
+		}
+		if strings.EqualFold(strings.TrimSpace(fields[1]), cpcOnly) {
+			cc.toIPC[cpc] = ""
+			continue
+		}
+		ipc, err := Normalize(fields[1])
+		if err != nil {
+			return nil, errors.Newf(errors.ErrCodeValidation, "concordance line %d: invalid IPC symbol %q", n, fields[1])
+		}
+		cc.toIPC[cpc] = ipc
+		cc.toCPC[ipc] = append(cc.toCPC[ipc], cpc)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "read concordance")
+	}
+	for _, cpcs := range cc.toCPC {
+		slices.SortFunc(cpcs, compareStrings)
+	}
+	return cc, nil
+}
+
+// Registry bundles the CPC and IPC schemes with their concordance. Any
+// part may be nil.
+type Registry struct {
+	CPC         *Hierarchy
+	IPC         *Hierarchy
+	Concordance *Concordance
+}
+
+// Registry directory layout used by LoadRegistry.
+const (
+	CPCDir         = "cpc"
+	IPCDir         = "ipc"
+	ConcordanceDir = "concordance"
+)
+
+// LoadRegistry loads the schemes under dir: title lists in dir/cpc and
+// dir/ipc, concordance files in dir/concordance. Missing subdirectories
+// leave that part nil, but at least one scheme must be present.
+func LoadRegistry(dir string) (*Registry, error) {
+	reg := &Registry{}
+	var err error
+	if reg.CPC, err = loadOptionalScheme(SchemeCPC, filepath.Join(dir, CPCDir)); err != nil {
+		return nil, err
+	}
+	if reg.IPC, err = loadOptionalScheme(SchemeIPC, filepath.Join(dir, IPCDir)); err != nil {
+		return nil, err
+	}
+	if reg.CPC == nil && reg.IPC == nil {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "no classification schemes under %s", dir)
+	}
+
+	paths, err := listFiles(filepath.Join(dir, ConcordanceDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "read concordance directory")
+	}
+	for _, p := range paths {
+		cc, err := loadConcordanceFile(p)
+		if err != nil {
+			return nil, err
+		}
+		reg.Concordance = reg.Concordance.merge(cc)
+	}
+	return reg, nil
+}
+
+func loadOptionalScheme(scheme Scheme, dir string) (*Hierarchy, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadSchemeDir(scheme, dir)
+}
+
+func loadConcordanceFile(path string) (*Concordance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "open concordance file")
+	}
+	defer f.Close()
+	return ParseConcordance(f)
+}
+
+// merge folds other into cc; rows in other win.
+func (cc *Concordance) merge(other *Concordance) *Concordance {
+	if cc == nil {
+		return other
+	}
+	for cpc, ipc := range other.toIPC {
+		if old, ok := cc.toIPC[cpc]; ok && old != "" {
+			cc.toCPC[old] = slices.DeleteFunc(cc.toCPC[old], func(s string) bool { return s == cpc })
+		}
+		cc.toIPC[cpc] = ipc
+		if ipc != "" {
+			cc.toCPC[ipc] = append(cc.toCPC[ipc], cpc)
+			slices.SortFunc(cc.toCPC[ipc], compareStrings)
+		}
+	}
+	return cc
+}
+
+// Hierarchy returns the loaded hierarchy for s, or nil.
+func (r *Registry) Hierarchy(s Scheme) *Hierarchy {
+	if r == nil {
+		return nil
+	}
+	switch s {
+	case SchemeCPC:
+		return r.CPC
+	case SchemeIPC:
+		return r.IPC
+	default:
+		return nil
+	}
+}
+
+// CPCToIPC returns the IPC symbol a CPC symbol is classified under. An
+// explicit concordance row for the symbol or its nearest ancestor wins;
+// otherwise it is the nearest of them that can exist in IPC, so an
+// indexing code without a row maps to its subclass. Section Y and CPCONLY
+// rows have no equivalent.
+func (r *Registry) CPCToIPC(code string) (string, bool) {
+	c, err := Parse(code)
+	if err != nil {
+		return "", false
+	}
+	ipcH := r.Hierarchy(SchemeIPC)
+	chain := append([]string{c.String()}, reverse(r.Hierarchy(SchemeCPC).Ancestors(code))...)
+	for _, sym := range chain {
+		if r != nil && r.Concordance != nil {
+			if ipc, ok := r.Concordance.toIPC[sym]; ok {
+				return ipc, ipc != ""
+			}
+		}
+		if sc, _ := Parse(sym); !sc.ValidFor(SchemeIPC) {
+			continue
+		}
+		if ipcH == nil {
+			return sym, true
+		}
+		if _, ok := ipcH.Lookup(sym); ok {
+			return sym, true
+		}
+	}
+	return "", false
+}
+
+// IPCToCPC returns the CPC symbols equivalent to an IPC symbol: the symbol
+// itself when CPC shares it, plus every CPC symbol the concordance maps
+// onto it. Use Hierarchy.Descendants on the result to widen a search to
+// the finer CPC subdivisions.
+func (r *Registry) IPCToCPC(code string) []string {
+	c, err := Parse(code)
+	if err != nil {
+		return nil
+	}
+	sym := c.String()
+	var out []string
+	if cpcH := r.Hierarchy(SchemeCPC); cpcH == nil {
+		out = append(out, sym)
+	} else if _, ok := cpcH.Lookup(sym); ok {
+		out = append(out, sym)
+	}
+	if r != nil && r.Concordance != nil {
+		for _, cpc := range r.Concordance.toCPC[sym] {
+			if cpc != sym {
+				out = append(out, cpc)
+			}
+		}
+	}
+	slices.SortFunc(out, compareStrings)
+	return out
+}
+
+func reverse(s []string) []string {
+	slices.Reverse(s)
+	return s
+}
+
+//Personal.AI order the ending
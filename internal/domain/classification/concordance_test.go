@@ -0,0 +1,85 @@
+package classification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func loadRegistry(t *testing.T) *Registry {
+	t.Helper()
+	reg, err := LoadRegistry("testdata")
+	require.NoError(t, err)
+	return reg
+}
+
+func TestLoadRegistry(t *testing.T) {
+	reg := loadRegistry(t)
+	require.NotNil(t, reg.CPC)
+	require.NotNil(t, reg.IPC)
+	require.NotNil(t, reg.Concordance)
+	assert.Same(t, reg.IPC, reg.Hierarchy(SchemeIPC))
+	assert.Nil(t, reg.Hierarchy("ecla"))
+
+	_, err := LoadRegistry(t.TempDir())
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestRegistry_CPCToIPC(t *testing.T) {
+	reg := loadRegistry(t)
+	cases := map[string]string{
+		"H10K 85/631":   "H10K85/60", // explicit row
+		"H10K85/633":    "H10K85/60", // row on an ancestor
+		"H10K85/342":    "H10K85/30", // nearest ancestor shared with IPC
+		"H10K50/11":     "H10K50/11", // shared verbatim
+		"C09K2211/1018": "C09K11/06", // indexing code with a row
+		"C09K2211/10":   "C09K",      // indexing code without one
+	}
+	for cpc, want := range cases {
+		got, ok := reg.CPCToIPC(cpc)
+		assert.True(t, ok, cpc)
+		assert.Equal(t, want, got, cpc)
+	}
+
+	_, ok := reg.CPCToIPC("H10K2101/10")
+	assert.False(t, ok, "CPCONLY")
+	_, ok = reg.CPCToIPC("Y02E10/549")
+	assert.False(t, ok)
+	_, ok = reg.CPCToIPC("A61K31/4745")
+	assert.False(t, ok, "not in the loaded IPC scheme")
+
+	var bare *Registry
+	got, ok := bare.CPCToIPC("H10K85/633")
+	assert.True(t, ok)
+	assert.Equal(t, "H10K85/633", got)
+}
+
+func TestRegistry_IPCToCPC(t *testing.T) {
+	reg := loadRegistry(t)
+	assert.Equal(t, []string{"H10K85/60", "H10K85/631"}, reg.IPCToCPC("H10K 85/60"))
+	assert.Equal(t, []string{"C09K11/06", "C09K2211/1018"}, reg.IPCToCPC("C09K0011060000"))
+	assert.Equal(t, []string{"H10K85/30"}, reg.IPCToCPC("H10K85/30"))
+	assert.Empty(t, reg.IPCToCPC("A61K31/00"))
+	assert.Nil(t, reg.IPCToCPC("not a code"))
+}
+
+func TestParseConcordance(t *testing.T) {
+	cc, err := ParseConcordance(strings.NewReader("CPC\tIPC\nH10K85/631\tH10K 85/60\textra\n\n# comment\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "H10K85/60", cc.toIPC["H10K85/631"])
+
+	_, err = ParseConcordance(strings.NewReader("H10K85/631\tH10K85/60\nnope\tH10K85/60\n"))
+	assert.True(t, errors.IsValidation(err))
+	_, err = ParseConcordance(strings.NewReader("H10K85/631\n"))
+	assert.True(t, errors.IsValidation(err))
+
+	merged := cc.merge(&Concordance{toIPC: map[string]string{"H10K85/631": "H10K85/615"}, toCPC: map[string][]string{}})
+	assert.Empty(t, merged.toCPC["H10K85/60"])
+	assert.Equal(t, []string{"H10K85/631"}, merged.toCPC["H10K85/615"])
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,247 @@
+package classification
+
+import (
+	"slices"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Entry is one symbol of a scheme.
+type Entry struct {
+	Code  string `json:"code"`
+	Title string `json:"title,omitempty"`
+	Level Level  `json:"level"`
+	// Parent is empty for sections.
+	Parent string `json:"parent,omitempty"`
+}
+
+// Hierarchy is an in-memory classification scheme. Build one with
+// ParseScheme or LoadSchemeDir; it is read-only afterwards and safe for
+// concurrent use.
+//
+// A nil *Hierarchy is usable: lookups miss, and ancestor and roll-up
+// queries fall back to the structure of the symbols themselves, which is
+// exact down to main groups.
+type Hierarchy struct {
+	scheme   Scheme
+	entries  map[string]*Entry
+	children map[string][]string
+}
+
+func newHierarchy(scheme Scheme) *Hierarchy {
+	return &Hierarchy{
+		scheme:   scheme,
+		entries:  make(map[string]*Entry),
+		children: make(map[string][]string),
+	}
+}
+
+// Scheme returns the scheme the hierarchy was loaded for.
+func (h *Hierarchy) Scheme() Scheme {
+	if h == nil {
+		return ""
+	}
+	return h.scheme
+}
+
+// Len returns the number of symbols in the scheme.
+func (h *Hierarchy) Len() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.entries)
+}
+
+// Lookup returns the entry for code, in any spelling Parse accepts.
+func (h *Hierarchy) Lookup(code string) (*Entry, bool) {
+	if h == nil {
+		return nil, false
+	}
+	c, err := Parse(code)
+	if err != nil {
+		return nil, false
+	}
+	e, ok := h.entries[c.String()]
+	return e, ok
+}
+
+// Validate normalises code and checks that it exists in the scheme.
+func (h *Hierarchy) Validate(code string) (*Entry, error) {
+	if h == nil {
+		return nil, errors.NewValidationError("code", "no classification scheme is loaded")
+	}
+	c, err := Parse(code)
+	if err != nil {
+		return nil, err
+	}
+	if !c.ValidFor(h.Scheme()) {
+		return nil, errors.NewValidationError("code", c.Display()+" cannot occur in the "+string(h.Scheme())+" scheme")
+	}
+	e, ok := h.Lookup(c.String())
+	if !ok {
+		return nil, errors.NewValidationError("code", c.Display()+" is not in the "+string(h.Scheme())+" scheme")
+	}
+	return e, nil
+}
+
+// Parent returns the parent of code. The second result is false for
+// sections and unparseable symbols.
+func (h *Hierarchy) Parent(code string) (string, bool) {
+	if e, ok := h.Lookup(code); ok {
+		return e.Parent, e.Parent != ""
+	}
+	c, err := Parse(code)
+	if err != nil || c.Level() == LevelSection {
+		return "", false
+	}
+	level := c.Level() - 1
+	if level > LevelMainGroup {
+		level = LevelMainGroup
+	}
+	p, _ := c.Truncate(level)
+	return p.String(), true
+}
+
+// Ancestors returns the ancestors of code from its section down to its
+// parent. Subgroups missing from the scheme get their main group and the
+// levels above it.
+func (h *Hierarchy) Ancestors(code string) []string {
+	var out []string
+	for p, ok := h.Parent(code); ok; p, ok = h.Parent(p) {
+		out = append(out, p)
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// Children returns the direct children of code in scheme order.
+func (h *Hierarchy) Children(code string) []string {
+	e, ok := h.Lookup(code)
+	if !ok {
+		return nil
+	}
+	return slices.Clone(h.children[e.Code])
+}
+
+// Descendants returns every symbol below code, depth first in scheme
+// order.
+func (h *Hierarchy) Descendants(code string) []string {
+	e, ok := h.Lookup(code)
+	if !ok {
+		return nil
+	}
+	var out []string
+	var walk func(string)
+	walk = func(c string) {
+		for _, child := range h.children[c] {
+			out = append(out, child)
+			walk(child)
+		}
+	}
+	walk(e.Code)
+	return out
+}
+
+// IsAncestor reports whether ancestor lies above code.
+func (h *Hierarchy) IsAncestor(ancestor, code string) bool {
+	a, err := Normalize(ancestor)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(h.Ancestors(code), a)
+}
+
+// AncestorAt returns the ancestor of code at level, or code itself when
+// it is at that level. The second result is false when code is shallower
+// than level or its depth below the main group is unknown.
+func (h *Hierarchy) AncestorAt(code string, level Level) (string, bool) {
+	c, err := Parse(code)
+	if err != nil {
+		return "", false
+	}
+	if e, ok := h.Lookup(code); ok {
+		for e.Level > level {
+			if e, ok = h.entries[e.Parent]; !ok {
+				return "", false
+			}
+		}
+		return e.Code, e.Level == level
+	}
+	if level > LevelMainGroup {
+		return "", false
+	}
+	t, ok := c.Truncate(level)
+	return t.String(), ok
+}
+
+// schemeBuilder assembles a Hierarchy from scheme lines in file order.
+type schemeBuilder struct {
+	h *Hierarchy
+	// stack holds the open subgroups of the current main group, outermost
+	// first, so a subgroup's parent is the nearest shallower one.
+	group string
+	stack []*Entry
+}
+
+// add records a symbol. dots is the subgroup depth from the scheme, or 0
+// when the file does not give one.
+func (b *schemeBuilder) add(c Code, dots int, title string) {
+	key := c.String()
+	if e, ok := b.h.entries[key]; ok {
+		if title != "" {
+			e.Title = title
+		}
+		return
+	}
+
+	level := c.Level()
+	var parent string
+	if level == LevelSubgroup {
+		level = SubgroupLevel(dots)
+		group, _ := c.Truncate(LevelMainGroup)
+		parent = b.ensure(group)
+		if b.group != parent {
+			b.group, b.stack = parent, nil
+		}
+		for len(b.stack) > 0 && b.stack[len(b.stack)-1].Level >= level {
+			b.stack = b.stack[:len(b.stack)-1]
+		}
+		if n := len(b.stack); n > 0 {
+			parent = b.stack[n-1].Code
+		}
+	} else if level > LevelSection {
+		p, _ := c.Truncate(level - 1)
+		parent = b.ensure(p)
+	}
+
+	e := &Entry{Code: key, Title: title, Level: level, Parent: parent}
+	b.h.entries[key] = e
+	if parent != "" {
+		b.h.children[parent] = append(b.h.children[parent], key)
+	}
+	if e.Level >= LevelSubgroup {
+		b.stack = append(b.stack, e)
+	}
+}
+
+// ensure adds an untitled structural symbol when the scheme file skipped
+// it, returning its canonical spelling.
+func (b *schemeBuilder) ensure(c Code) string {
+	if _, ok := b.h.entries[c.String()]; !ok {
+		group := b.group
+		stack := b.stack
+		b.add(c, 0, "")
+		b.group, b.stack = group, stack
+	}
+	return c.String()
+}
+
+// finish sorts children into scheme order.
+func (b *schemeBuilder) finish() *Hierarchy {
+	for _, kids := range b.h.children {
+		slices.SortFunc(kids, compareStrings)
+	}
+	return b.h
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,147 @@
+package classification
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func loadCPC(t *testing.T) *Hierarchy {
+	t.Helper()
+	h, err := LoadSchemeDir(SchemeCPC, filepath.Join("testdata", CPCDir))
+	require.NoError(t, err)
+	return h
+}
+
+func TestLoadSchemeDir(t *testing.T) {
+	h := loadCPC(t)
+	assert.Equal(t, SchemeCPC, h.Scheme())
+	assert.Equal(t, 35, h.Len())
+
+	e, ok := h.Lookup("H10K 85/631")
+	require.True(t, ok)
+	assert.Equal(t, "H10K85/631", e.Code)
+	assert.Equal(t, SubgroupLevel(2), e.Level)
+	assert.Equal(t, "H10K85/60", e.Parent)
+	assert.True(t, strings.HasPrefix(e.Title, "Amine compounds"))
+
+	e, ok = h.Lookup("H10K85/6572")
+	require.True(t, ok)
+	assert.Equal(t, "H10K85/654", e.Parent)
+	e, ok = h.Lookup("H10K")
+	require.True(t, ok)
+	assert.Equal(t, "H10", e.Parent)
+	assert.Equal(t, LevelSubclass, e.Level)
+}
+
+func TestHierarchy_Ancestors(t *testing.T) {
+	h := loadCPC(t)
+	assert.Equal(t, []string{"H", "H10", "H10K", "H10K85/00", "H10K85/60", "H10K85/631"}, h.Ancestors("H10K 85/633"))
+	assert.True(t, h.IsAncestor("H10K 85/60", "H10K85/633"))
+	assert.False(t, h.IsAncestor("H10K85/615", "H10K85/633"))
+	assert.Empty(t, h.Ancestors("H"))
+
+	// Outside the trimmed scheme only the structural levels are known.
+	assert.Equal(t, []string{"A", "A61", "A61K", "A61K31/00"}, h.Ancestors("A61K 31/4745"))
+	var none *Hierarchy
+	assert.Equal(t, []string{"H", "H10", "H10K", "H10K85/00"}, none.Ancestors("H10K85/633"))
+}
+
+func TestHierarchy_Descendants(t *testing.T) {
+	h := loadCPC(t)
+	assert.Equal(t, []string{"H10K85/615", "H10K85/626", "H10K85/631", "H10K85/633", "H10K85/654", "H10K85/6572"},
+		h.Descendants("H10K85/60"))
+	assert.Equal(t, []string{"H10K85/10", "H10K85/30", "H10K85/60"}, h.Children("H10K 85/00"))
+	assert.Equal(t, []string{"H10K50/00", "H10K85/00", "H10K2101/00"}, h.Children("H10K"))
+	assert.Empty(t, h.Descendants("H10K85/6572"))
+	assert.Nil(t, h.Descendants("A61K"))
+}
+
+func TestHierarchy_AncestorAt(t *testing.T) {
+	h := loadCPC(t)
+	for level, want := range map[Level]string{
+		LevelSubclass:    "H10K",
+		LevelMainGroup:   "H10K85/00",
+		LevelSubgroup:    "H10K85/60",
+		SubgroupLevel(2): "H10K85/631",
+		SubgroupLevel(3): "H10K85/633",
+	} {
+		got, ok := h.AncestorAt("H10K85/633", level)
+		assert.True(t, ok, level)
+		assert.Equal(t, want, got, level)
+	}
+	_, ok := h.AncestorAt("H10K85/60", SubgroupLevel(2))
+	assert.False(t, ok)
+	_, ok = h.AncestorAt("A61K31/4745", LevelSubgroup)
+	assert.False(t, ok, "depth of an unknown subgroup is unknown")
+	got, ok := h.AncestorAt("A61K31/4745", LevelSubclass)
+	assert.True(t, ok)
+	assert.Equal(t, "A61K", got)
+}
+
+func TestHierarchy_Validate(t *testing.T) {
+	h := loadCPC(t)
+	e, err := h.Validate("y02e 10/549")
+	require.NoError(t, err)
+	assert.Equal(t, "Y02E10/549", e.Code)
+
+	_, err = h.Validate("A61K31/4745")
+	assert.True(t, errors.IsValidation(err))
+	_, err = h.Validate("H10K85/6")
+	assert.True(t, errors.IsValidation(err))
+
+	ipc, err := ParseScheme(SchemeIPC, strings.NewReader("H\tELECTRICITY\n"))
+	require.NoError(t, err)
+	_, err = ipc.Validate("Y02E")
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestParseScheme_IPCTitleList(t *testing.T) {
+	h, err := LoadSchemeFile(SchemeIPC, filepath.Join("testdata", IPCDir, "ipc-title-list.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, 15, h.Len())
+
+	// Without dot levels subgroups hang off their main group.
+	e, ok := h.Lookup("H10K50/11")
+	require.True(t, ok)
+	assert.Equal(t, "H10K50/00", e.Parent)
+	assert.Equal(t, "characterised by the electroluminescent [EL] layers", e.Title)
+}
+
+func TestParseScheme_FillsGapsAndRejectsBadLines(t *testing.T) {
+	h, err := ParseScheme(SchemeCPC, strings.NewReader("H10K85/60\t1\tLow molecular weight\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"H", "H10", "H10K", "H10K85/00"}, h.Ancestors("H10K85/60"))
+	e, _ := h.Lookup("H10K")
+	assert.Empty(t, e.Title)
+
+	_, err = ParseScheme(SchemeCPC, strings.NewReader("H\t\tELECTRICITY\nH10K85/xx\t1\tBad\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+
+	_, err = ParseScheme(SchemeCPC, strings.NewReader("H10K85/60\tone\tBad level\n"))
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = ParseScheme(SchemeIPC, strings.NewReader("Y02E\tCPC only\n"))
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestLoadSchemeDir_Empty(t *testing.T) {
+	_, err := LoadSchemeDir(SchemeCPC, t.TempDir())
+	assert.True(t, errors.IsNotFound(err))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".DS_Store"), []byte("junk"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpc-section-H.txt"), []byte("H\t\tELECTRICITY\n"), 0o600))
+	h, err := LoadSchemeDir(SchemeCPC, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, h.Len())
+}
+
+//Personal.AI order the ending
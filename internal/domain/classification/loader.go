@@ -0,0 +1,126 @@
+package classification
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// maxLineBytes bounds a scheme line; the longest CPC titles are a few KB.
+const maxLineBytes = 1 << 20
+
+// ParseScheme reads tab-separated scheme title lists, in order. Two line
+// layouts are accepted:
+//
+//	SYMBOL <TAB> LEVEL <TAB> TITLE   CPC title lists (cpc-section-*.txt)
+//	SYMBOL <TAB> TITLE               WIPO IPC title lists
+//
+// LEVEL is the subgroup dot count; it is blank above main groups and 0 on
+// main groups. Without it every subgroup hangs directly off its main group,
+// because dot depth cannot be derived from the symbol. Blank lines and lines
+// starting with '#' are skipped.
+func ParseScheme(scheme Scheme, readers ...io.Reader) (*Hierarchy, error) {
+	b := &schemeBuilder{h: newHierarchy(scheme)}
+	for i, r := range readers {
+		if err := b.read(r); err != nil {
+			if ae, ok := err.(*errors.AppError); ok {
+				return nil, errors.Newf(ae.Code, "%s scheme input %d: %s", scheme, i+1, ae.Message)
+			}
+			return nil, errors.Wrapf(err, errors.ErrCodeInternal, "read %s scheme input %d", scheme, i+1)
+		}
+	}
+	return b.finish(), nil
+}
+
+func (b *schemeBuilder) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		c, err := Parse(fields[0])
+		if err != nil {
+			return errors.Newf(errors.ErrCodeValidation, "line %d: invalid classification symbol %q", n, fields[0])
+		}
+		if !c.ValidFor(b.h.scheme) {
+			return errors.Newf(errors.ErrCodeValidation, "line %d: %s cannot occur in the %s scheme", n, c.Display(), b.h.scheme)
+		}
+
+		dots, title := 0, ""
+		switch len(fields) {
+		case 1:
+		case 2:
+			title = fields[1]
+		default:
+			if lv := strings.TrimSpace(fields[1]); lv != "" {
+				if dots, err = strconv.Atoi(lv); err != nil || dots < 0 {
+					return errors.Newf(errors.ErrCodeValidation, "line %d: invalid level %q", n, fields[1])
+				}
+			}
+			title = strings.Join(fields[2:], " ")
+		}
+		b.add(c, dots, strings.TrimSpace(title))
+	}
+	return scanner.Err()
+}
+
+// LoadSchemeFile loads a scheme from one title list on disk.
+func LoadSchemeFile(scheme Scheme, path string) (*Hierarchy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.ErrCodeInternal, "open %s scheme file", scheme)
+	}
+	defer f.Close()
+	return ParseScheme(scheme, f)
+}
+
+// LoadSchemeDir loads a scheme from every regular file in dir, in name
+// order, so the per-section files of a CPC release can be dropped in as
+// published.
+func LoadSchemeDir(scheme Scheme, dir string) (*Hierarchy, error) {
+	paths, err := listFiles(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.ErrCodeInternal, "read %s scheme directory", scheme)
+	}
+	if len(paths) == 0 {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "no %s scheme files in %s", scheme, dir)
+	}
+
+	readers := make([]io.Reader, 0, len(paths))
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, errors.ErrCodeInternal, "open %s scheme file", scheme)
+		}
+		defer f.Close()
+		readers = append(readers, f)
+	}
+	return ParseScheme(scheme, readers...)
+}
+
+// listFiles returns the regular, non-hidden files in dir sorted by name.
+func listFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			out = append(out, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,101 @@
+package classification
+
+import (
+	"slices"
+	"sort"
+)
+
+// Bucket is one node of a roll-up.
+type Bucket struct {
+	Code  string `json:"code"`
+	Title string `json:"title,omitempty"`
+	Level Level  `json:"level"`
+	// Count is the number of distinct items (RollUp) or the summed count
+	// (RollUpCounts) in the bucket.
+	Count int `json:"count"`
+	// Items lists the item keys in the bucket; RollUpCounts leaves it empty.
+	Items []string `json:"items,omitempty"`
+}
+
+// bucketOf returns the roll-up bucket for code at level. Codes shallower
+// than level, or whose subgroup depth is unknown, stay in a bucket of their
+// own so nothing drops out of the totals.
+func (h *Hierarchy) bucketOf(code string, level Level) (string, bool) {
+	c, err := Parse(code)
+	if err != nil {
+		return "", false
+	}
+	if b, ok := h.AncestorAt(code, level); ok {
+		return b, true
+	}
+	return c.String(), true
+}
+
+// RollUp groups items — typically patents keyed by number, each with its
+// classification codes — into buckets at level. An item counts once per
+// bucket however many of its codes land there. Symbols that do not parse
+// are ignored. Buckets are ordered by count, then scheme order.
+func (h *Hierarchy) RollUp(items map[string][]string, level Level) []Bucket {
+	members := make(map[string]map[string]struct{})
+	for key, codes := range items {
+		for _, code := range codes {
+			b, ok := h.bucketOf(code, level)
+			if !ok {
+				continue
+			}
+			if members[b] == nil {
+				members[b] = make(map[string]struct{})
+			}
+			members[b][key] = struct{}{}
+		}
+	}
+
+	out := make([]Bucket, 0, len(members))
+	for code, keys := range members {
+		bucket := h.newBucket(code, len(keys))
+		for k := range keys {
+			bucket.Items = append(bucket.Items, k)
+		}
+		sort.Strings(bucket.Items)
+		out = append(out, bucket)
+	}
+	sortBuckets(out)
+	return out
+}
+
+// RollUpCounts sums pre-aggregated per-code counts into buckets at level.
+func (h *Hierarchy) RollUpCounts(counts map[string]int, level Level) []Bucket {
+	sums := make(map[string]int)
+	for code, n := range counts {
+		if b, ok := h.bucketOf(code, level); ok {
+			sums[b] += n
+		}
+	}
+	out := make([]Bucket, 0, len(sums))
+	for code, n := range sums {
+		out = append(out, h.newBucket(code, n))
+	}
+	sortBuckets(out)
+	return out
+}
+
+func (h *Hierarchy) newBucket(code string, count int) Bucket {
+	b := Bucket{Code: code, Count: count}
+	if e, ok := h.Lookup(code); ok {
+		b.Title, b.Level = e.Title, e.Level
+	} else if c, err := Parse(code); err == nil {
+		b.Level = c.Level()
+	}
+	return b
+}
+
+func sortBuckets(buckets []Bucket) {
+	slices.SortFunc(buckets, func(a, b Bucket) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return compareStrings(a.Code, b.Code)
+	})
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,81 @@
+package classification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// portfolio is a small OLED portfolio keyed by patent number.
+var portfolio = map[string][]string{
+	"US1": {"H10K 85/633", "H10K85/342", "C09K11/06"},
+	"US2": {"H10K85/626", "H10K85/615"},
+	"US3": {"H10K85/6572", "H10K50/12"},
+	"US4": {"H10K85/60", "Y02E10/549", "not-a-code"},
+}
+
+func TestRollUp_Levels(t *testing.T) {
+	h := loadCPC(t)
+
+	subclass := h.RollUp(portfolio, LevelSubclass)
+	require.Len(t, subclass, 3)
+	assert.Equal(t, Bucket{Code: "H10K", Title: "ORGANIC ELECTRIC SOLID-STATE DEVICES", Level: LevelSubclass, Count: 4,
+		Items: []string{"US1", "US2", "US3", "US4"}}, subclass[0])
+	assert.Equal(t, "C09K", subclass[1].Code)
+	assert.Equal(t, "Y02E", subclass[2].Code)
+
+	// At one dot H10K85/60 absorbs the low-molecular-weight families; the
+	// iridium complex in US1 lands under coordination compounds.
+	oneDot := h.RollUp(portfolio, LevelSubgroup)
+	counts := map[string]int{}
+	for _, b := range oneDot {
+		counts[b.Code] = b.Count
+	}
+	assert.Equal(t, map[string]int{
+		"H10K85/60": 4,
+		"H10K85/30": 1,
+		"H10K50/10": 1,
+		"Y02E10/50": 1,
+		"C09K11/06": 1,
+	}, counts)
+	assert.Equal(t, "H10K85/60", oneDot[0].Code)
+
+	// Codes above the requested level keep their own bucket.
+	twoDot := h.RollUp(portfolio, SubgroupLevel(2))
+	var shallow *Bucket
+	for i := range twoDot {
+		if twoDot[i].Code == "H10K85/60" {
+			shallow = &twoDot[i]
+		}
+	}
+	require.NotNil(t, shallow)
+	assert.Equal(t, []string{"US4"}, shallow.Items)
+	assert.Equal(t, LevelSubgroup, shallow.Level)
+}
+
+func TestRollUp_WithoutScheme(t *testing.T) {
+	var h *Hierarchy
+	groups := h.RollUp(portfolio, LevelMainGroup)
+	require.NotEmpty(t, groups)
+	assert.Equal(t, "H10K85/00", groups[0].Code)
+	assert.Equal(t, 4, groups[0].Count)
+	assert.Empty(t, groups[0].Title)
+}
+
+func TestRollUpCounts(t *testing.T) {
+	h := loadCPC(t)
+	buckets := h.RollUpCounts(map[string]int{
+		"H10K85/631": 10,
+		"H10K85/633": 5,
+		"H10K85/342": 7,
+		"H10K50/12":  3,
+	}, LevelMainGroup)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, "H10K85/00", buckets[0].Code)
+	assert.Equal(t, 22, buckets[0].Count)
+	assert.Equal(t, 3, buckets[1].Count)
+	assert.Nil(t, buckets[0].Items)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,348 @@
+package collaboration
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MaxCommentLength bounds the size of a single comment body.
+const MaxCommentLength = 10000
+
+// AnchorType identifies what a comment thread is attached to.
+type AnchorType string
+
+const (
+	AnchorPatent        AnchorType = "patent"
+	AnchorClaim         AnchorType = "claim"
+	AnchorMolecule      AnchorType = "molecule"
+	AnchorReportSection AnchorType = "report_section"
+)
+
+// CommentAnchor pins a comment thread to a resource. Claim anchors may narrow
+// the thread to a character span of the claim text; report anchors name a
+// section of the report.
+type CommentAnchor struct {
+	Type        AnchorType `json:"type"`
+	ResourceID  string     `json:"resource_id"`
+	ClaimNumber int        `json:"claim_number,omitempty"`
+	SpanStart   int        `json:"span_start,omitempty"`
+	SpanEnd     int        `json:"span_end,omitempty"`
+	SectionID   string     `json:"section_id,omitempty"`
+}
+
+// Validate checks that the anchor fields are consistent with its type.
+func (a CommentAnchor) Validate() error {
+	if a.ResourceID == "" {
+		return errors.InvalidParam("anchor resource_id is required")
+	}
+	switch a.Type {
+	case AnchorPatent, AnchorMolecule:
+		if a.ClaimNumber != 0 || a.SpanStart != 0 || a.SpanEnd != 0 || a.SectionID != "" {
+			return errors.InvalidParam("claim and section fields are only valid on claim and report_section anchors")
+		}
+	case AnchorClaim:
+		if a.ClaimNumber < 1 {
+			return errors.InvalidParam("claim_number must be at least 1")
+		}
+		if a.SpanStart < 0 || a.SpanEnd < a.SpanStart {
+			return errors.InvalidParam("span must satisfy 0 <= span_start <= span_end")
+		}
+		if a.SectionID != "" {
+			return errors.InvalidParam("section_id is not valid on a claim anchor")
+		}
+	case AnchorReportSection:
+		if a.SectionID == "" {
+			return errors.InvalidParam("section_id is required for report_section anchors")
+		}
+		if a.ClaimNumber != 0 || a.SpanStart != 0 || a.SpanEnd != 0 {
+			return errors.InvalidParam("claim fields are not valid on a report_section anchor")
+		}
+	default:
+		return errors.InvalidParam("invalid anchor type")
+	}
+	return nil
+}
+
+// HasSpan reports whether the anchor covers part of a claim rather than the
+// whole claim.
+func (a CommentAnchor) HasSpan() bool {
+	return a.Type == AnchorClaim && a.SpanEnd > a.SpanStart
+}
+
+// ProtectedResource returns the resource whose read permission gates access
+// to comments on this anchor. Molecules are only reachable through patent
+// disclosures, so they share patent access.
+func (a CommentAnchor) ProtectedResource() ResourceType {
+	if a.Type == AnchorReportSection {
+		return ResourceReport
+	}
+	return ResourcePatent
+}
+
+// Comment is a single message in a comment thread. The first comment of a
+// thread is its root; replies share the root's anchor and thread ID.
+type Comment struct {
+	ID          string        `json:"id"`
+	WorkspaceID string        `json:"workspace_id"`
+	AuthorID    string        `json:"author_id"`
+	Anchor      CommentAnchor `json:"anchor"`
+	ParentID    string        `json:"parent_id,omitempty"`
+	ThreadID    string        `json:"thread_id"`
+	Content     string        `json:"content"`
+	Mentions    []string      `json:"mentions,omitempty"`
+	IsResolved  bool          `json:"is_resolved"`
+	ResolvedBy  string        `json:"resolved_by,omitempty"`
+	ResolvedAt  *time.Time    `json:"resolved_at,omitempty"`
+	EditedAt    *time.Time    `json:"edited_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	DeletedAt   *time.Time    `json:"deleted_at,omitempty"`
+}
+
+// CommentThread groups a root comment with its replies in creation order.
+type CommentThread struct {
+	Root    *Comment   `json:"root"`
+	Replies []*Comment `json:"replies"`
+}
+
+func validateCommentContent(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return errors.InvalidParam("comment content is required")
+	}
+	if len(content) > MaxCommentLength {
+		return errors.InvalidParam("comment content must be at most 10000 characters")
+	}
+	return nil
+}
+
+// NewComment starts a new thread on the given anchor.
+func NewComment(workspaceID, authorID string, anchor CommentAnchor, content string) (*Comment, error) {
+	if workspaceID == "" || authorID == "" {
+		return nil, errors.InvalidParam("workspaceID and authorID are required")
+	}
+	if err := anchor.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateCommentContent(content); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	id := uuid.New().String()
+	return &Comment{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		AuthorID:    authorID,
+		Anchor:      anchor,
+		ThreadID:    id,
+		Content:     content,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// NewReply creates a reply to parent in parent's thread.
+func NewReply(parent *Comment, authorID, content string) (*Comment, error) {
+	if parent == nil {
+		return nil, errors.InvalidParam("parent comment is required")
+	}
+	if parent.IsDeleted() {
+		return nil, errors.InvalidState("cannot reply to a deleted comment")
+	}
+	if authorID == "" {
+		return nil, errors.InvalidParam("authorID is required")
+	}
+	if err := validateCommentContent(content); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Comment{
+		ID:          uuid.New().String(),
+		WorkspaceID: parent.WorkspaceID,
+		AuthorID:    authorID,
+		Anchor:      parent.Anchor,
+		ParentID:    parent.ID,
+		ThreadID:    parent.ThreadID,
+		Content:     content,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// IsRoot reports whether the comment starts its thread.
+func (c *Comment) IsRoot() bool {
+	return c.ParentID == ""
+}
+
+// IsDeleted reports whether the comment has been soft-deleted.
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// Edit replaces the comment body. Only the author may edit.
+func (c *Comment) Edit(editorID, content string) error {
+	if c.IsDeleted() {
+		return errors.InvalidState("cannot edit a deleted comment")
+	}
+	if editorID != c.AuthorID {
+		return errors.Forbidden("only the author can edit a comment")
+	}
+	if err := validateCommentContent(content); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	c.Content = content
+	c.EditedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// SetMentions records the IDs of users mentioned in the comment.
+func (c *Comment) SetMentions(userIDs []string) {
+	c.Mentions = userIDs
+}
+
+// Resolve marks the thread as resolved. Only root comments carry thread state.
+func (c *Comment) Resolve(userID string) error {
+	if !c.IsRoot() {
+		return errors.InvalidParam("only the root comment of a thread can be resolved")
+	}
+	if c.IsDeleted() {
+		return errors.InvalidState("cannot resolve a deleted thread")
+	}
+	if c.IsResolved {
+		return errors.InvalidState("thread already resolved")
+	}
+	now := time.Now().UTC()
+	c.IsResolved = true
+	c.ResolvedBy = userID
+	c.ResolvedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// Reopen clears the resolved state of a thread.
+func (c *Comment) Reopen() error {
+	if !c.IsRoot() {
+		return errors.InvalidParam("only the root comment of a thread can be reopened")
+	}
+	if !c.IsResolved {
+		return errors.InvalidState("thread is not resolved")
+	}
+	c.IsResolved = false
+	c.ResolvedBy = ""
+	c.ResolvedAt = nil
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// MarkDeleted soft-deletes the comment.
+func (c *Comment) MarkDeleted() error {
+	if c.IsDeleted() {
+		return errors.InvalidState("comment already deleted")
+	}
+	now := time.Now().UTC()
+	c.DeletedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._-]{0,63})`)
+
+// ExtractMentions returns the distinct @handles in content, in order of first
+// appearance. Email addresses are not treated as mentions, and trailing
+// punctuation is not part of a handle.
+func ExtractMentions(content string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(content, -1)
+	seen := make(map[string]bool, len(matches))
+	handles := make([]string, 0, len(matches))
+	for _, m := range matches {
+		h := strings.TrimRight(m[1], ".-_")
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		handles = append(handles, h)
+	}
+	return handles
+}
+
+// BuildThreads groups comments by thread. Threads are ordered by root
+// creation time and replies by their own creation time. Replies whose root
+// is not in comments are dropped.
+func BuildThreads(comments []*Comment) []*CommentThread {
+	byID := make(map[string]*CommentThread)
+	var threads []*CommentThread
+	for _, c := range comments {
+		if c.IsRoot() {
+			t := &CommentThread{Root: c, Replies: []*Comment{}}
+			byID[c.ID] = t
+			threads = append(threads, t)
+		}
+	}
+	for _, c := range comments {
+		if c.IsRoot() {
+			continue
+		}
+		if t, ok := byID[c.ThreadID]; ok {
+			t.Replies = append(t.Replies, c)
+		}
+	}
+
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Root.CreatedAt.Before(threads[j].Root.CreatedAt)
+	})
+	for _, t := range threads {
+		sort.SliceStable(t.Replies, func(i, j int) bool {
+			return t.Replies[i].CreatedAt.Before(t.Replies[j].CreatedAt)
+		})
+	}
+	return threads
+}
+
+// NotificationTypeCommentMention is the notification type emitted when a
+// user is @mentioned in a comment.
+const NotificationTypeCommentMention = "comment_mention"
+
+// Notification is an in-app message addressed to a single user.
+type Notification struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body,omitempty"`
+	ResourceType string    `json:"resource_type,omitempty"`
+	ResourceID   string    `json:"resource_id,omitempty"`
+	ActionURL    string    `json:"action_url,omitempty"`
+	SenderID     string    `json:"sender_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewMentionNotification builds the notification sent to userID when c
+// mentions them.
+func NewMentionNotification(c *Comment, userID string) *Notification {
+	body := c.Content
+	if r := []rune(body); len(r) > 280 {
+		body = string(r[:277]) + "..."
+	}
+	return &Notification{
+		ID:           uuid.New().String(),
+		UserID:       userID,
+		Type:         NotificationTypeCommentMention,
+		Title:        "You were mentioned in a comment",
+		Body:         body,
+		ResourceType: string(c.Anchor.Type),
+		ResourceID:   c.Anchor.ResourceID,
+		ActionURL:    "/workspaces/" + c.WorkspaceID + "/comments/" + c.ThreadID,
+		SenderID:     c.AuthorID,
+		CreatedAt:    time.Now().UTC(),
+	}
+}
+
+//Personal.AI order the ending
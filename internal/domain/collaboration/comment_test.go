@@ -0,0 +1,165 @@
+package collaboration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func claimAnchor() CommentAnchor {
+	return CommentAnchor{Type: AnchorClaim, ResourceID: "patent1", ClaimNumber: 1, SpanStart: 10, SpanEnd: 42}
+}
+
+func TestCommentAnchor_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		anchor  CommentAnchor
+		wantErr bool
+	}{
+		{"patent", CommentAnchor{Type: AnchorPatent, ResourceID: "p1"}, false},
+		{"molecule", CommentAnchor{Type: AnchorMolecule, ResourceID: "m1"}, false},
+		{"claim span", claimAnchor(), false},
+		{"whole claim", CommentAnchor{Type: AnchorClaim, ResourceID: "p1", ClaimNumber: 3}, false},
+		{"report section", CommentAnchor{Type: AnchorReportSection, ResourceID: "r1", SectionID: "claim-chart"}, false},
+		{"missing resource", CommentAnchor{Type: AnchorPatent}, true},
+		{"unknown type", CommentAnchor{Type: "figure", ResourceID: "p1"}, true},
+		{"claim number zero", CommentAnchor{Type: AnchorClaim, ResourceID: "p1"}, true},
+		{"inverted span", CommentAnchor{Type: AnchorClaim, ResourceID: "p1", ClaimNumber: 1, SpanStart: 9, SpanEnd: 3}, true},
+		{"negative span", CommentAnchor{Type: AnchorClaim, ResourceID: "p1", ClaimNumber: 1, SpanStart: -1}, true},
+		{"section without id", CommentAnchor{Type: AnchorReportSection, ResourceID: "r1"}, true},
+		{"claim on patent", CommentAnchor{Type: AnchorPatent, ResourceID: "p1", ClaimNumber: 2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.anchor.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCommentAnchor_ProtectedResource(t *testing.T) {
+	assert.Equal(t, ResourcePatent, claimAnchor().ProtectedResource())
+	assert.Equal(t, ResourcePatent, CommentAnchor{Type: AnchorMolecule}.ProtectedResource())
+	assert.Equal(t, ResourceReport, CommentAnchor{Type: AnchorReportSection}.ProtectedResource())
+	assert.True(t, claimAnchor().HasSpan())
+	assert.False(t, CommentAnchor{Type: AnchorClaim, ClaimNumber: 1}.HasSpan())
+}
+
+func TestNewComment_Success(t *testing.T) {
+	c, err := NewComment("ws1", "u1", claimAnchor(), "Is this limitation anticipated?")
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.ID)
+	assert.Equal(t, c.ID, c.ThreadID)
+	assert.True(t, c.IsRoot())
+	assert.False(t, c.IsResolved)
+}
+
+func TestNewComment_Invalid(t *testing.T) {
+	_, err := NewComment("", "u1", claimAnchor(), "text")
+	assert.Error(t, err)
+	_, err = NewComment("ws1", "u1", claimAnchor(), "   ")
+	assert.Error(t, err)
+	_, err = NewComment("ws1", "u1", claimAnchor(), strings.Repeat("a", MaxCommentLength+1))
+	assert.Error(t, err)
+	_, err = NewComment("ws1", "u1", CommentAnchor{Type: AnchorClaim, ResourceID: "p1"}, "text")
+	assert.Error(t, err)
+}
+
+func TestNewReply(t *testing.T) {
+	root, _ := NewComment("ws1", "u1", claimAnchor(), "root")
+	reply, err := NewReply(root, "u2", "reply")
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, reply.ParentID)
+	assert.Equal(t, root.ThreadID, reply.ThreadID)
+	assert.Equal(t, root.Anchor, reply.Anchor)
+	assert.False(t, reply.IsRoot())
+
+	nested, err := NewReply(reply, "u1", "nested")
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, nested.ThreadID)
+	assert.Equal(t, reply.ID, nested.ParentID)
+
+	_ = root.MarkDeleted()
+	_, err = NewReply(root, "u2", "too late")
+	assert.Error(t, err)
+}
+
+func TestComment_Edit(t *testing.T) {
+	c, _ := NewComment("ws1", "u1", claimAnchor(), "draft")
+	assert.Error(t, c.Edit("u2", "hijack"))
+	require.NoError(t, c.Edit("u1", "final"))
+	assert.Equal(t, "final", c.Content)
+	assert.NotNil(t, c.EditedAt)
+}
+
+func TestComment_ResolveReopen(t *testing.T) {
+	c, _ := NewComment("ws1", "u1", claimAnchor(), "root")
+	require.NoError(t, c.Resolve("u2"))
+	assert.True(t, c.IsResolved)
+	assert.Equal(t, "u2", c.ResolvedBy)
+	assert.NotNil(t, c.ResolvedAt)
+	assert.Error(t, c.Resolve("u2"))
+
+	require.NoError(t, c.Reopen())
+	assert.False(t, c.IsResolved)
+	assert.Empty(t, c.ResolvedBy)
+	assert.Nil(t, c.ResolvedAt)
+	assert.Error(t, c.Reopen())
+
+	reply, _ := NewReply(c, "u2", "reply")
+	assert.Error(t, reply.Resolve("u2"))
+}
+
+func TestExtractMentions(t *testing.T) {
+	got := ExtractMentions("@alice please check with @bob.smith, cc @alice and mail carol@example.com (@dave).")
+	assert.Equal(t, []string{"alice", "bob.smith", "dave"}, got)
+	assert.Empty(t, ExtractMentions("no mentions here"))
+}
+
+func TestBuildThreads(t *testing.T) {
+	base := time.Now().UTC()
+	r1 := &Comment{ID: "r1", ThreadID: "r1", CreatedAt: base.Add(2 * time.Second)}
+	r2 := &Comment{ID: "r2", ThreadID: "r2", CreatedAt: base}
+	a := &Comment{ID: "a", ParentID: "r1", ThreadID: "r1", CreatedAt: base.Add(5 * time.Second)}
+	b := &Comment{ID: "b", ParentID: "r1", ThreadID: "r1", CreatedAt: base.Add(3 * time.Second)}
+	orphan := &Comment{ID: "o", ParentID: "x", ThreadID: "x", CreatedAt: base}
+
+	threads := BuildThreads([]*Comment{a, r1, orphan, b, r2})
+	require.Len(t, threads, 2)
+	assert.Equal(t, "r2", threads[0].Root.ID)
+	assert.Empty(t, threads[0].Replies)
+	assert.Equal(t, "r1", threads[1].Root.ID)
+	require.Len(t, threads[1].Replies, 2)
+	assert.Equal(t, "b", threads[1].Replies[0].ID)
+	assert.Equal(t, "a", threads[1].Replies[1].ID)
+}
+
+func TestNewMentionNotification(t *testing.T) {
+	c, _ := NewComment("ws1", "u1", claimAnchor(), strings.Repeat("長", 300))
+	n := NewMentionNotification(c, "u2")
+	assert.Equal(t, NotificationTypeCommentMention, n.Type)
+	assert.Equal(t, "u2", n.UserID)
+	assert.Equal(t, "u1", n.SenderID)
+	assert.Equal(t, "patent1", n.ResourceID)
+	assert.Equal(t, 280, len([]rune(n.Body)))
+	assert.Contains(t, n.ActionURL, c.ThreadID)
+}
+
+func TestHasPermission_Comments(t *testing.T) {
+	p := NewPermissionPolicy()
+	assert.True(t, p.HasPermission(RoleAnalyst, ResourceComment, ActionCreate))
+	assert.False(t, p.HasPermission(RoleAnalyst, ResourceComment, ActionUpdate))
+	assert.True(t, p.HasPermission(RoleAttorney, ResourceComment, ActionUpdate))
+	assert.True(t, p.HasPermission(RoleViewer, ResourceComment, ActionRead))
+	assert.False(t, p.HasPermission(RoleViewer, ResourceComment, ActionCreate))
+	assert.True(t, p.HasPermission(RoleInventor, ResourceComment, ActionCreate))
+}
+
+//Personal.AI order the ending
@@ -33,6 +33,7 @@ const (
 	ResourceWorkspace ResourceType = "workspace"
 	ResourceReport    ResourceType = "report"
 	ResourceSettings  ResourceType = "settings"
+	ResourceComment   ResourceType = "comment"
 )
 
 // Action defines the operations that can be performed on resources.
@@ -114,7 +115,7 @@ func (p *permissionPolicyImpl) initRoles() {
 	allResources := []ResourceType{
 		ResourcePatent, ResourcePortfolio, ResourceLifecycle,
 		ResourceAnnuity, ResourceDeadline, ResourceWorkspace,
-		ResourceReport, ResourceSettings,
+		ResourceReport, ResourceSettings, ResourceComment,
 	}
 	allActions := []Action{
 		ActionCreate, ActionRead, ActionUpdate, ActionDelete,
@@ -166,6 +167,9 @@ func (p *permissionPolicyImpl) initRoles() {
 	managerPerms = append(managerPerms, perm(ResourceReport, ActionRead))
 	managerPerms = append(managerPerms, perm(ResourceReport, ActionExport))
 	managerPerms = append(managerPerms, perm(ResourceWorkspace, ActionRead))
+	for _, act := range []Action{ActionCreate, ActionRead, ActionUpdate, ActionDelete} {
+		managerPerms = append(managerPerms, perm(ResourceComment, act))
+	}
 
 	p.rolePermissions[RoleManager] = &RolePermissions{
 		Role:         RoleManager,
@@ -186,6 +190,9 @@ func (p *permissionPolicyImpl) initRoles() {
 	attorneyPerms = append(attorneyPerms, perm(ResourcePortfolio, ActionRead))
 	attorneyPerms = append(attorneyPerms, perm(ResourceAnnuity, ActionRead))
 	attorneyPerms = append(attorneyPerms, perm(ResourceWorkspace, ActionRead))
+	for _, act := range []Action{ActionCreate, ActionRead, ActionUpdate} {
+		attorneyPerms = append(attorneyPerms, perm(ResourceComment, act))
+	}
 
 	p.rolePermissions[RoleAttorney] = &RolePermissions{
 		Role:         RoleAttorney,
@@ -194,13 +201,14 @@ func (p *permissionPolicyImpl) initRoles() {
 		IsSystemRole: true,
 	}
 
-	// Analyst：所有资源的 Read + Analyze + Export，不可 Create/Update/Delete
+	// Analyst：所有资源的 Read + Analyze + Export，不可 Create/Update/Delete；可发表评论
 	analystPerms := []*Permission{}
 	for _, res := range allResources {
 		analystPerms = append(analystPerms, perm(res, ActionRead))
 		analystPerms = append(analystPerms, perm(res, ActionAnalyze))
 		analystPerms = append(analystPerms, perm(res, ActionExport))
 	}
+	analystPerms = append(analystPerms, perm(ResourceComment, ActionCreate))
 	p.rolePermissions[RoleAnalyst] = &RolePermissions{
 		Role:         RoleAnalyst,
 		Permissions:  analystPerms,
@@ -226,6 +234,8 @@ func (p *permissionPolicyImpl) initRoles() {
 		perm(ResourcePatent, ActionCreate),
 		permCond(ResourceDeadline, ActionRead, map[string]string{"own_only": "true"}),
 		perm(ResourceWorkspace, ActionRead),
+		perm(ResourceComment, ActionRead),
+		perm(ResourceComment, ActionCreate),
 	}
 	p.rolePermissions[RoleInventor] = &RolePermissions{
 		Role:         RoleInventor,
@@ -2,6 +2,7 @@ package collaboration
 
 import (
 	"context"
+	"time"
 )
 
 // WorkspaceRepository defines the persistence interface for workspaces.
@@ -28,6 +29,34 @@ type MemberRepository interface {
 	CountByRole(ctx context.Context, workspaceID string) (map[Role]int64, error)
 }
 
+// CommentRepository defines the persistence interface for comments.
+type CommentRepository interface {
+	Save(ctx context.Context, comment *Comment) error
+	FindByID(ctx context.Context, id string) (*Comment, error)
+	FindThread(ctx context.Context, threadID string) ([]*Comment, error)
+	// FindByAnchor returns every comment on the resource, replies included.
+	// With unresolvedOnly set, comments in resolved threads are omitted.
+	FindByAnchor(ctx context.Context, workspaceID string, anchorType AnchorType, resourceID string, unresolvedOnly bool) ([]*Comment, error)
+}
+
+// NotificationRepository defines the persistence interface for in-app notifications.
+type NotificationRepository interface {
+	Save(ctx context.Context, notification *Notification) error
+}
+
+// SavedSearchRepository defines the persistence interface for saved searches.
+type SavedSearchRepository interface {
+	Save(ctx context.Context, search *SavedSearch) error
+	FindByID(ctx context.Context, id string) (*SavedSearch, error)
+	FindByOwner(ctx context.Context, ownerID string) ([]*SavedSearch, error)
+	FindByWorkspace(ctx context.Context, workspaceID string) ([]*SavedSearch, error)
+	// FindDue claims up to limit scheduled searches whose next run is at or
+	// before now, oldest first. A claimed search is not returned to other
+	// callers until it is saved or its claim expires.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*SavedSearch, error)
+	Delete(ctx context.Context, id string) error
+}
+
 // CollaborationQueryOptions defines filtering and pagination for collaboration queries.
 type CollaborationQueryOptions struct {
 	Offset       int
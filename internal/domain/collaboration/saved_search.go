@@ -0,0 +1,242 @@
+package collaboration
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MaxSavedSearchResultIDs bounds how many hit IDs are remembered between runs
+// for new-hit detection.
+const MaxSavedSearchResultIDs = 1000
+
+// RealtimeSearchInterval is how often realtime saved searches are re-run.
+const RealtimeSearchInterval = 15 * time.Minute
+
+// SavedSearchKind identifies which search backend a saved query targets.
+type SavedSearchKind string
+
+const (
+	// SavedSearchPatent is a patent text query (advanced search fields).
+	SavedSearchPatent SavedSearchKind = "patent"
+	// SavedSearchMolecule is a structure or similarity query.
+	SavedSearchMolecule SavedSearchKind = "molecule"
+	// SavedSearchKnowledgeGraph is a knowledge-graph entity query.
+	SavedSearchKnowledgeGraph SavedSearchKind = "knowledge_graph"
+)
+
+// IsValid reports whether k is a known kind.
+func (k SavedSearchKind) IsValid() bool {
+	switch k {
+	case SavedSearchPatent, SavedSearchMolecule, SavedSearchKnowledgeGraph:
+		return true
+	}
+	return false
+}
+
+// SearchSchedule controls how often a saved search is re-run.
+type SearchSchedule string
+
+const (
+	ScheduleManual   SearchSchedule = "manual"
+	ScheduleRealtime SearchSchedule = "realtime"
+	ScheduleDaily    SearchSchedule = "daily"
+	ScheduleWeekly   SearchSchedule = "weekly"
+	ScheduleMonthly  SearchSchedule = "monthly"
+)
+
+// IsValid reports whether s is a known schedule.
+func (s SearchSchedule) IsValid() bool {
+	switch s {
+	case ScheduleManual, ScheduleRealtime, ScheduleDaily, ScheduleWeekly, ScheduleMonthly:
+		return true
+	}
+	return false
+}
+
+// Interval returns the delay between scheduled runs, or zero for manual
+// searches.
+func (s SearchSchedule) Interval() time.Duration {
+	switch s {
+	case ScheduleRealtime:
+		return RealtimeSearchInterval
+	case ScheduleDaily:
+		return 24 * time.Hour
+	case ScheduleWeekly:
+		return 7 * 24 * time.Hour
+	case ScheduleMonthly:
+		return 30 * 24 * time.Hour
+	}
+	return 0
+}
+
+// SavedSearch is a named query that can be re-run on a schedule. Query holds
+// the backend-specific request body verbatim. A search with a WorkspaceID is
+// shared with that workspace's members; only the owner may change it.
+type SavedSearch struct {
+	ID            string          `json:"id"`
+	OwnerID       string          `json:"owner_id"`
+	WorkspaceID   string          `json:"workspace_id,omitempty"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Kind          SavedSearchKind `json:"kind"`
+	Query         json.RawMessage `json:"query"`
+	Schedule      SearchSchedule  `json:"schedule"`
+	LastResultIDs []string        `json:"-"`
+	ResultCount   int             `json:"result_count"`
+	LastRunAt     *time.Time      `json:"last_run_at,omitempty"`
+	NextRunAt     *time.Time      `json:"next_run_at,omitempty"`
+	LastAlertAt   *time.Time      `json:"last_alert_at,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// NewSavedSearch creates a private saved search owned by ownerID.
+func NewSavedSearch(ownerID, name string, kind SavedSearchKind, query json.RawMessage, schedule SearchSchedule) (*SavedSearch, error) {
+	if ownerID == "" {
+		return nil, errors.InvalidParam("owner ID cannot be empty")
+	}
+	if schedule == "" {
+		schedule = ScheduleManual
+	}
+	now := time.Now().UTC()
+	s := &SavedSearch{
+		ID:        uuid.New().String(),
+		OwnerID:   ownerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Update(name, "", kind, query, schedule); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Update replaces the editable fields. Changing the kind or query discards
+// the remembered results so the next run starts a fresh baseline.
+func (s *SavedSearch) Update(name, description string, kind SavedSearchKind, query json.RawMessage, schedule SearchSchedule) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.InvalidParam("saved search name cannot be empty")
+	}
+	if len(name) > 256 {
+		return errors.InvalidParam("saved search name too long")
+	}
+	if !kind.IsValid() {
+		return errors.InvalidParam("invalid saved search kind")
+	}
+	if !schedule.IsValid() {
+		return errors.InvalidParam("invalid saved search schedule")
+	}
+	if len(query) == 0 || !json.Valid(query) {
+		return errors.InvalidParam("saved search query must be valid JSON")
+	}
+
+	if s.Kind != kind || string(s.Query) != string(query) {
+		s.LastResultIDs = nil
+		s.ResultCount = 0
+		s.LastRunAt = nil
+	}
+	s.Name = name
+	s.Description = description
+	s.Kind = kind
+	s.Query = query
+	s.setSchedule(schedule, time.Now().UTC())
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+func (s *SavedSearch) setSchedule(schedule SearchSchedule, now time.Time) {
+	if s.Schedule == schedule && s.NextRunAt != nil {
+		return
+	}
+	s.Schedule = schedule
+	if interval := schedule.Interval(); interval > 0 {
+		next := now.Add(interval)
+		s.NextRunAt = &next
+	} else {
+		s.NextRunAt = nil
+	}
+}
+
+// ShareWith makes the search visible to members of workspaceID.
+func (s *SavedSearch) ShareWith(workspaceID string) error {
+	if workspaceID == "" {
+		return errors.InvalidParam("workspace ID cannot be empty")
+	}
+	s.WorkspaceID = workspaceID
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Unshare makes the search private to its owner again.
+func (s *SavedSearch) Unshare() {
+	s.WorkspaceID = ""
+	s.UpdatedAt = time.Now().UTC()
+}
+
+// IsShared reports whether the search is shared with a workspace.
+func (s *SavedSearch) IsShared() bool {
+	return s.WorkspaceID != ""
+}
+
+// IsDue reports whether a scheduled run is pending at now.
+func (s *SavedSearch) IsDue(now time.Time) bool {
+	return s.Schedule != ScheduleManual && s.NextRunAt != nil && !now.Before(*s.NextRunAt)
+}
+
+// RecordRun stores the hit IDs of a completed run and returns those not seen
+// in the previous run, in hit order. The first run only establishes the
+// baseline and reports nothing new.
+func (s *SavedSearch) RecordRun(now time.Time, hitIDs []string) []string {
+	var fresh []string
+	if s.LastRunAt != nil {
+		seen := make(map[string]bool, len(s.LastResultIDs))
+		for _, id := range s.LastResultIDs {
+			seen[id] = true
+		}
+		for _, id := range hitIDs {
+			if !seen[id] {
+				seen[id] = true
+				fresh = append(fresh, id)
+			}
+		}
+	}
+
+	ids := hitIDs
+	if len(ids) > MaxSavedSearchResultIDs {
+		ids = ids[:MaxSavedSearchResultIDs]
+	}
+	s.LastResultIDs = append([]string(nil), ids...)
+	s.ResultCount = len(hitIDs)
+	s.LastRunAt = &now
+	s.LastError = ""
+	if len(fresh) > 0 {
+		s.LastAlertAt = &now
+	}
+	s.advance(now)
+	return fresh
+}
+
+// RecordFailure stores a failed run. The remembered results are kept so the
+// next successful run still diffs against the last good one.
+func (s *SavedSearch) RecordFailure(now time.Time, err error) {
+	if err != nil {
+		s.LastError = err.Error()
+	}
+	s.advance(now)
+}
+
+func (s *SavedSearch) advance(now time.Time) {
+	if interval := s.Schedule.Interval(); interval > 0 {
+		next := now.Add(interval)
+		s.NextRunAt = &next
+	}
+	s.UpdatedAt = now
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,116 @@
+package collaboration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var textQuery = json.RawMessage(`{"keywords":["OLED","host"]}`)
+
+func TestNewSavedSearch(t *testing.T) {
+	s, err := NewSavedSearch("u1", "  OLED hosts ", SavedSearchPatent, textQuery, ScheduleDaily)
+	require.NoError(t, err)
+	assert.Equal(t, "OLED hosts", s.Name)
+	assert.False(t, s.IsShared())
+	require.NotNil(t, s.NextRunAt)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *s.NextRunAt, time.Minute)
+
+	manual, err := NewSavedSearch("u1", "adhoc", SavedSearchMolecule, json.RawMessage(`{"smiles":"c1ccccc1"}`), "")
+	require.NoError(t, err)
+	assert.Equal(t, ScheduleManual, manual.Schedule)
+	assert.Nil(t, manual.NextRunAt)
+	assert.False(t, manual.IsDue(time.Now().Add(365*24*time.Hour)))
+}
+
+func TestNewSavedSearch_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		owner    string
+		title    string
+		kind     SavedSearchKind
+		query    json.RawMessage
+		schedule SearchSchedule
+	}{
+		{"no owner", "", "n", SavedSearchPatent, textQuery, ScheduleDaily},
+		{"no name", "u1", " ", SavedSearchPatent, textQuery, ScheduleDaily},
+		{"bad kind", "u1", "n", "portfolio", textQuery, ScheduleDaily},
+		{"bad schedule", "u1", "n", SavedSearchPatent, textQuery, "hourly"},
+		{"empty query", "u1", "n", SavedSearchPatent, nil, ScheduleDaily},
+		{"invalid json", "u1", "n", SavedSearchPatent, json.RawMessage(`{`), ScheduleDaily},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSavedSearch(tt.owner, tt.title, tt.kind, tt.query, tt.schedule)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSavedSearch_RecordRun(t *testing.T) {
+	s, _ := NewSavedSearch("u1", "n", SavedSearchPatent, textQuery, ScheduleWeekly)
+	now := time.Now().UTC()
+
+	assert.Empty(t, s.RecordRun(now, []string{"a", "b"}), "first run is the baseline")
+	assert.Equal(t, 2, s.ResultCount)
+	assert.Nil(t, s.LastAlertAt)
+
+	later := now.Add(7 * 24 * time.Hour)
+	assert.True(t, s.IsDue(later))
+	fresh := s.RecordRun(later, []string{"c", "a", "d", "c"})
+	assert.Equal(t, []string{"c", "d"}, fresh)
+	assert.Equal(t, later, *s.LastAlertAt)
+	assert.Equal(t, later.Add(7*24*time.Hour), *s.NextRunAt)
+	assert.False(t, s.IsDue(later))
+
+	assert.Empty(t, s.RecordRun(later.Add(time.Hour), []string{"a", "c", "d"}))
+}
+
+func TestSavedSearch_RecordFailureKeepsBaseline(t *testing.T) {
+	s, _ := NewSavedSearch("u1", "n", SavedSearchPatent, textQuery, ScheduleDaily)
+	now := time.Now().UTC()
+	s.RecordRun(now, []string{"a"})
+
+	s.RecordFailure(now.Add(time.Hour), errors.New("opensearch unavailable"))
+	assert.Equal(t, "opensearch unavailable", s.LastError)
+	assert.Equal(t, []string{"a"}, s.LastResultIDs)
+
+	assert.Equal(t, []string{"b"}, s.RecordRun(now.Add(2*time.Hour), []string{"a", "b"}))
+	assert.Empty(t, s.LastError)
+}
+
+func TestSavedSearch_UpdateQueryResetsBaseline(t *testing.T) {
+	s, _ := NewSavedSearch("u1", "n", SavedSearchPatent, textQuery, ScheduleDaily)
+	s.RecordRun(time.Now().UTC(), []string{"a"})
+	next := *s.NextRunAt
+
+	require.NoError(t, s.Update("renamed", "desc", SavedSearchPatent, textQuery, ScheduleDaily))
+	assert.NotNil(t, s.LastRunAt, "rename keeps the baseline")
+	assert.Equal(t, next, *s.NextRunAt, "same schedule keeps the next run")
+
+	require.NoError(t, s.Update("renamed", "desc", SavedSearchPatent, json.RawMessage(`{"keywords":["TADF"]}`), ScheduleManual))
+	assert.Nil(t, s.LastRunAt)
+	assert.Empty(t, s.LastResultIDs)
+	assert.Nil(t, s.NextRunAt)
+}
+
+func TestSavedSearch_Share(t *testing.T) {
+	s, _ := NewSavedSearch("u1", "n", SavedSearchKnowledgeGraph, json.RawMessage(`{"EntityType":"Company"}`), ScheduleManual)
+	assert.Error(t, s.ShareWith(""))
+	require.NoError(t, s.ShareWith("ws1"))
+	assert.True(t, s.IsShared())
+	s.Unshare()
+	assert.False(t, s.IsShared())
+}
+
+func TestSearchSchedule_Interval(t *testing.T) {
+	assert.Equal(t, time.Duration(0), ScheduleManual.Interval())
+	assert.Equal(t, RealtimeSearchInterval, ScheduleRealtime.Interval())
+	assert.Equal(t, 30*24*time.Hour, ScheduleMonthly.Interval())
+}
+
+//Personal.AI order the ending
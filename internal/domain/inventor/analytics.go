@@ -0,0 +1,231 @@
+package inventor
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+)
+
+// Move is an inventor's change of assignee between consecutive stints.
+type Move struct {
+	InventorID   uuid.UUID `json:"inventor_id"`
+	InventorName string    `json:"inventor_name"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	// LastFiledAtFrom and FirstFiledAtTo bracket when the move happened.
+	LastFiledAtFrom time.Time `json:"last_filed_at_from"`
+	FirstFiledAtTo  time.Time `json:"first_filed_at_to"`
+	PatentsAtFrom   int       `json:"patents_at_from"`
+	PatentsAtTo     int       `json:"patents_at_to"`
+}
+
+// MobilityOptions filters DetectMoves.
+type MobilityOptions struct {
+	// Competitors restricts moves to those leaving or joining one of these
+	// assignees. Names are compared after assignee normalisation, and a
+	// competitor also matches assignees whose name extends it, so "Merck"
+	// covers "Merck Patent GmbH". Empty means every move.
+	Competitors []string
+	// MinStintPatents is the number of patents an inventor must file at an
+	// assignee for the stint to count. Stints below it, typically a single
+	// joint filing with a partner, are ignored. Zero means 1.
+	MinStintPatents int
+	// Since ignores moves whose first filing at the new assignee is before
+	// it.
+	Since time.Time
+}
+
+type stint struct {
+	name      string
+	first     time.Time
+	last      time.Time
+	patents   int
+	normalize string
+}
+
+// DetectMoves finds assignee changes in the filing history of inventors,
+// most recent first. Mentions without a filing date or assignee are
+// ignored.
+func DetectMoves(inventors []*Inventor, opts MobilityOptions) []*Move {
+	minStint := max(opts.MinStintPatents, 1)
+	var competitors []string
+	for _, c := range opts.Competitors {
+		if n := assignee.Normalize(c); n != "" {
+			competitors = append(competitors, n)
+		}
+	}
+	isCompetitor := func(normalized string) bool {
+		for _, c := range competitors {
+			if normalized == c || strings.HasPrefix(normalized, c+" ") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var moves []*Move
+	for _, inv := range inventors {
+		stints := stintsOf(inv.Mentions)
+		var kept []*stint
+		for _, s := range stints {
+			if s.patents < minStint {
+				continue
+			}
+			if n := len(kept); n > 0 && kept[n-1].normalize == s.normalize {
+				kept[n-1].last = s.last
+				kept[n-1].patents += s.patents
+				continue
+			}
+			kept = append(kept, s)
+		}
+		for i := 1; i < len(kept); i++ {
+			from, to := kept[i-1], kept[i]
+			if len(competitors) > 0 && !isCompetitor(from.normalize) && !isCompetitor(to.normalize) {
+				continue
+			}
+			if !opts.Since.IsZero() && to.first.Before(opts.Since) {
+				continue
+			}
+			moves = append(moves, &Move{
+				InventorID:      inv.ID,
+				InventorName:    inv.CanonicalName,
+				From:            from.name,
+				To:              to.name,
+				LastFiledAtFrom: from.last,
+				FirstFiledAtTo:  to.first,
+				PatentsAtFrom:   from.patents,
+				PatentsAtTo:     to.patents,
+			})
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].FirstFiledAtTo.After(moves[j].FirstFiledAtTo) })
+	return moves
+}
+
+// stintsOf splits dated mentions into runs of consecutive filings at the
+// same assignee.
+func stintsOf(mentions []*Mention) []*stint {
+	dated := make([]*Mention, 0, len(mentions))
+	for _, m := range mentions {
+		if m.FilingDate != nil && assignee.Normalize(m.AssigneeName) != "" {
+			dated = append(dated, m)
+		}
+	}
+	sort.SliceStable(dated, func(i, j int) bool { return dated[i].FilingDate.Before(*dated[j].FilingDate) })
+
+	var out []*stint
+	for _, m := range dated {
+		n := assignee.Normalize(m.AssigneeName)
+		if len(out) > 0 && out[len(out)-1].normalize == n {
+			s := out[len(out)-1]
+			s.last = *m.FilingDate
+			s.patents++
+			continue
+		}
+		out = append(out, &stint{name: m.AssigneeName, normalize: n, first: *m.FilingDate, last: *m.FilingDate, patents: 1})
+	}
+	return out
+}
+
+// NetworkNode is an inventor in a collaboration network.
+type NetworkNode struct {
+	InventorID  uuid.UUID `json:"inventor_id"`
+	Name        string    `json:"name"`
+	Assignee    string    `json:"assignee,omitempty"`
+	PatentCount int       `json:"patent_count"`
+	// Degree is the number of distinct collaborators; Strength the number
+	// of co-invented patents summed over them.
+	Degree   int `json:"degree"`
+	Strength int `json:"strength"`
+}
+
+// Collaboration is an edge between two inventors who share patents.
+type Collaboration struct {
+	From          uuid.UUID `json:"from"`
+	To            uuid.UUID `json:"to"`
+	SharedPatents int       `json:"shared_patents"`
+}
+
+// Network is a co-inventor network. Nodes are ordered by Strength, so the
+// first nodes are the key inventors holding the group together.
+type Network struct {
+	Nodes []*NetworkNode   `json:"nodes"`
+	Edges []*Collaboration `json:"edges"`
+}
+
+// BuildNetwork links inventors who appear on the same patents. Edges with
+// fewer than minShared patents are dropped; zero means 1.
+func BuildNetwork(inventors []*Inventor, minShared int) *Network {
+	minShared = max(minShared, 1)
+	byPatent := make(map[uuid.UUID][]uuid.UUID)
+	for _, inv := range inventors {
+		for _, m := range inv.Mentions {
+			byPatent[m.PatentID] = append(byPatent[m.PatentID], inv.ID)
+		}
+	}
+
+	shared := make(map[[2]uuid.UUID]int)
+	for _, ids := range byPatent {
+		for i := 0; i < len(ids); i++ {
+			for j := i + 1; j < len(ids); j++ {
+				a, b := ids[i], ids[j]
+				if a == b {
+					continue
+				}
+				if b.String() < a.String() {
+					a, b = b, a
+				}
+				shared[[2]uuid.UUID{a, b}]++
+			}
+		}
+	}
+
+	nodes := make(map[uuid.UUID]*NetworkNode, len(inventors))
+	net := &Network{Nodes: []*NetworkNode{}, Edges: []*Collaboration{}}
+	for _, inv := range inventors {
+		n := &NetworkNode{InventorID: inv.ID, Name: inv.CanonicalName, PatentCount: inv.PatentCount}
+		if len(inv.Assignees) > 0 {
+			n.Assignee = inv.Assignees[0]
+		}
+		nodes[inv.ID] = n
+		net.Nodes = append(net.Nodes, n)
+	}
+	for pair, count := range shared {
+		if count < minShared {
+			continue
+		}
+		net.Edges = append(net.Edges, &Collaboration{From: pair[0], To: pair[1], SharedPatents: count})
+		for _, id := range pair {
+			nodes[id].Degree++
+			nodes[id].Strength += count
+		}
+	}
+
+	sort.SliceStable(net.Nodes, func(i, j int) bool {
+		a, b := net.Nodes[i], net.Nodes[j]
+		if a.Strength != b.Strength {
+			return a.Strength > b.Strength
+		}
+		if a.PatentCount != b.PatentCount {
+			return a.PatentCount > b.PatentCount
+		}
+		return a.InventorID.String() < b.InventorID.String()
+	})
+	sort.SliceStable(net.Edges, func(i, j int) bool {
+		a, b := net.Edges[i], net.Edges[j]
+		if a.SharedPatents != b.SharedPatents {
+			return a.SharedPatents > b.SharedPatents
+		}
+		if a.From != b.From {
+			return a.From.String() < b.From.String()
+		}
+		return a.To.String() < b.To.String()
+	})
+	return net
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,86 @@
+package inventor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func history(t *testing.T, steps ...[2]string) *Inventor {
+	t.Helper()
+	var mentions []*Mention
+	for _, s := range steps {
+		filed, err := time.Parse("2006-01-02", s[1])
+		require.NoError(t, err)
+		mentions = append(mentions, &Mention{PatentID: uuid.New(), Sequence: 1, Name: "Peter Wolohan", AssigneeName: s[0], FilingDate: &filed})
+	}
+	return NewInventor(uuid.New(), mentions)
+}
+
+func TestDetectMoves(t *testing.T) {
+	inv := history(t,
+		[2]string{"Universal Display Corporation", "2015-01-01"},
+		[2]string{"UNIVERSAL DISPLAY CORP", "2016-01-01"},
+		[2]string{"Merck Patent GmbH", "2018-06-01"},
+		[2]string{"Merck Patent GmbH", "2019-06-01"},
+	)
+
+	moves := DetectMoves([]*Inventor{inv}, MobilityOptions{})
+	require.Len(t, moves, 1)
+	m := moves[0]
+	assert.Equal(t, "Universal Display Corporation", m.From)
+	assert.Equal(t, "Merck Patent GmbH", m.To)
+	assert.Equal(t, 2, m.PatentsAtFrom)
+	assert.Equal(t, 2, m.PatentsAtTo)
+	assert.Equal(t, "2016-01-01", m.LastFiledAtFrom.Format("2006-01-02"))
+	assert.Equal(t, "2018-06-01", m.FirstFiledAtTo.Format("2006-01-02"))
+
+	assert.Len(t, DetectMoves([]*Inventor{inv}, MobilityOptions{Competitors: []string{"MERCK PATENT GMBH"}}), 1)
+	assert.Len(t, DetectMoves([]*Inventor{inv}, MobilityOptions{Competitors: []string{"Merck"}}), 1)
+	assert.Empty(t, DetectMoves([]*Inventor{inv}, MobilityOptions{Competitors: []string{"Mer"}}))
+	assert.Empty(t, DetectMoves([]*Inventor{inv}, MobilityOptions{Competitors: []string{"LG Display"}}))
+	assert.Empty(t, DetectMoves([]*Inventor{inv}, MobilityOptions{Since: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}))
+}
+
+func TestDetectMoves_IgnoresShortStints(t *testing.T) {
+	inv := history(t,
+		[2]string{"Samsung Display", "2015-01-01"},
+		[2]string{"Samsung Display", "2016-01-01"},
+		[2]string{"Seoul National University", "2016-06-01"},
+		[2]string{"Samsung Display", "2017-01-01"},
+	)
+	assert.Len(t, DetectMoves([]*Inventor{inv}, MobilityOptions{}), 2)
+	assert.Empty(t, DetectMoves([]*Inventor{inv}, MobilityOptions{MinStintPatents: 2}))
+}
+
+func TestBuildNetwork(t *testing.T) {
+	p1, p2, p3 := uuid.New(), uuid.New(), uuid.New()
+	inv := func(name string, patents ...uuid.UUID) *Inventor {
+		var ms []*Mention
+		for _, p := range patents {
+			ms = append(ms, &Mention{PatentID: p, Name: name, AssigneeName: "UDC"})
+		}
+		return NewInventor(uuid.New(), ms)
+	}
+	hub := inv("Hub", p1, p2, p3)
+	a := inv("A", p1, p2)
+	b := inv("B", p3)
+	loner := inv("Loner", uuid.New())
+
+	net := BuildNetwork([]*Inventor{loner, a, b, hub}, 0)
+	require.Len(t, net.Nodes, 4)
+	assert.Equal(t, hub.ID, net.Nodes[0].InventorID)
+	assert.Equal(t, 2, net.Nodes[0].Degree)
+	assert.Equal(t, 3, net.Nodes[0].Strength)
+	assert.Equal(t, "UDC", net.Nodes[0].Assignee)
+	require.Len(t, net.Edges, 2)
+	assert.Equal(t, 2, net.Edges[0].SharedPatents)
+
+	strong := BuildNetwork([]*Inventor{loner, a, b, hub}, 2)
+	assert.Len(t, strong.Edges, 1)
+}
+
+//Personal.AI order the ending
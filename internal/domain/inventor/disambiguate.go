@@ -0,0 +1,348 @@
+package inventor
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+)
+
+// idNamespace derives IDs for inventors no previous run has seen, from
+// their earliest mention, so re-running on the same data yields the same
+// IDs even before they are persisted.
+var idNamespace = uuid.MustParse("6f1d3c2e-8a57-4b0e-9c1e-5e0a7d4b9f21")
+
+// Weights are the contributions of each kind of evidence to a pair score.
+// A pair is linked when the weighted sum reaches Threshold. Missing
+// evidence contributes nothing, so a shared name alone never links two
+// mentions.
+type Weights struct {
+	Name       float64
+	CoInventor float64
+	Assignee   float64
+	Location   float64
+	CPC        float64
+}
+
+// Config tunes disambiguation.
+type Config struct {
+	Weights Weights
+	// Threshold is the pair score at which two mentions are linked.
+	Threshold float64
+	// MinNameSimilarity is the name similarity below which a pair is not
+	// scored at all.
+	MinNameSimilarity float64
+}
+
+// DefaultConfig returns the weights tuned on display-materials filings: an
+// identical name plus either the same assignee or a shared co-inventor and
+// technology links two mentions, which lets inventors who change employer
+// be followed through their collaborators.
+func DefaultConfig() Config {
+	return Config{
+		Weights: Weights{
+			Name:       0.40,
+			CoInventor: 0.25,
+			Assignee:   0.20,
+			Location:   0.05,
+			CPC:        0.10,
+		},
+		Threshold:         0.60,
+		MinNameSimilarity: 0.85,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Weights == (Weights{}) {
+		c.Weights = d.Weights
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = d.Threshold
+	}
+	if c.MinNameSimilarity <= 0 {
+		c.MinNameSimilarity = d.MinNameSimilarity
+	}
+	return c
+}
+
+// profile caches the normalised evidence of a mention.
+type profile struct {
+	m           *Mention
+	forms       []string
+	assignee    string
+	cpcs        []string
+	coInventors [][]string
+}
+
+func newProfile(m *Mention) *profile {
+	p := &profile{
+		m:        m,
+		forms:    Forms(m.Name, m.NameEn),
+		assignee: assignee.Normalize(m.AssigneeName),
+		cpcs:     cpcSubclasses(m.CPCCodes),
+	}
+	for _, co := range m.CoInventors {
+		if f := Forms(co, ""); len(f) > 0 {
+			p.coInventors = append(p.coInventors, f)
+		}
+	}
+	return p
+}
+
+// Disambiguator clusters mentions into inventors.
+type Disambiguator struct {
+	cfg Config
+}
+
+// NewDisambiguator returns a Disambiguator; zero fields of cfg take their
+// DefaultConfig values.
+func NewDisambiguator(cfg Config) *Disambiguator {
+	return &Disambiguator{cfg: cfg.withDefaults()}
+}
+
+// PairScore returns the evidence score of two mentions, or 0 when their
+// names are too dissimilar to be compared.
+func (d *Disambiguator) PairScore(a, b *Mention) float64 {
+	return d.score(newProfile(a), newProfile(b))
+}
+
+func (d *Disambiguator) score(a, b *profile) float64 {
+	if a.m.PatentID == b.m.PatentID {
+		return 0
+	}
+	name := bestFormSimilarity(a.forms, b.forms)
+	if name < d.cfg.MinNameSimilarity {
+		return 0
+	}
+	w := d.cfg.Weights
+	s := w.Name * name
+	s += w.CoInventor * coInventorOverlap(a.coInventors, b.coInventors)
+	if a.assignee != "" && a.assignee == b.assignee {
+		s += w.Assignee
+	}
+	if a.m.Country != "" && a.m.Country == b.m.Country {
+		s += w.Location
+	}
+	s += w.CPC * jaccard(a.cpcs, b.cpcs)
+	return s
+}
+
+// Cluster groups mentions into inventors. Mentions are blocked on NameKeys,
+// every pair within a block is scored and pairs at or above the threshold
+// are linked best first. Two mentions on the same patent are never placed
+// in one inventor, since a patent does not list a person twice.
+//
+// Inventor IDs are kept stable: a cluster takes the ID most of its mentions
+// already carry, larger clusters choosing first, so a split keeps the ID
+// with its bigger half. Clusters with no free ID get one derived from their
+// earliest mention.
+func (d *Disambiguator) Cluster(mentions []*Mention) []*Inventor {
+	profiles := make([]*profile, len(mentions))
+	blocks := make(map[string][]int)
+	for i, m := range mentions {
+		profiles[i] = newProfile(m)
+		seen := make(map[string]bool)
+		for _, f := range profiles[i].forms {
+			for _, k := range NameKeys(f) {
+				if !seen[k] {
+					seen[k] = true
+					blocks[k] = append(blocks[k], i)
+				}
+			}
+		}
+	}
+
+	type edge struct {
+		a, b  int
+		score float64
+	}
+	var edges []edge
+	scored := make(map[[2]int]bool)
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				a, b := members[x], members[y]
+				if scored[[2]int{a, b}] {
+					continue
+				}
+				scored[[2]int{a, b}] = true
+				if s := d.score(profiles[a], profiles[b]); s >= d.cfg.Threshold {
+					edges = append(edges, edge{a, b, s})
+				}
+			}
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].score != edges[j].score {
+			return edges[i].score > edges[j].score
+		}
+		if edges[i].a != edges[j].a {
+			return edges[i].a < edges[j].a
+		}
+		return edges[i].b < edges[j].b
+	})
+
+	uf := newUnionFind(mentions)
+	for _, e := range edges {
+		uf.union(e.a, e.b)
+	}
+
+	groups := make(map[int][]*Mention)
+	var roots []int
+	for i, m := range mentions {
+		r := uf.find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], m)
+	}
+	clusters := make([][]*Mention, 0, len(roots))
+	for _, r := range roots {
+		clusters = append(clusters, groups[r])
+	}
+	return assignIDs(clusters)
+}
+
+// assignIDs names each cluster and builds its inventor profile.
+func assignIDs(clusters [][]*Mention) []*Inventor {
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i]) > len(clusters[j]) })
+	claimed := make(map[uuid.UUID]bool)
+	out := make([]*Inventor, 0, len(clusters))
+	for _, ms := range clusters {
+		votes := make(map[uuid.UUID]int)
+		for _, m := range ms {
+			if m.InventorID != nil && !claimed[*m.InventorID] {
+				votes[*m.InventorID]++
+			}
+		}
+		id, best := uuid.Nil, 0
+		for candidate, n := range votes {
+			if n > best || (n == best && candidate.String() < id.String()) {
+				id, best = candidate, n
+			}
+		}
+		if id == uuid.Nil {
+			seed := ms[0].Key()
+			for _, m := range ms[1:] {
+				if k := m.Key(); k < seed {
+					seed = k
+				}
+			}
+			id = uuid.NewSHA1(idNamespace, []byte(seed))
+		}
+		claimed[id] = true
+		out = append(out, NewInventor(id, ms))
+	}
+	sortInventors(out)
+	return out
+}
+
+// Assignments lists the mention-to-inventor mapping of inventors.
+func Assignments(inventors []*Inventor) []Assignment {
+	var out []Assignment
+	for _, inv := range inventors {
+		for _, m := range inv.Mentions {
+			out = append(out, Assignment{PatentID: m.PatentID, Sequence: m.Sequence, InventorID: inv.ID})
+		}
+	}
+	return out
+}
+
+// unionFind merges mention indexes into clusters while keeping the set of
+// patents in each cluster disjoint.
+type unionFind struct {
+	parent  []int
+	patents []map[uuid.UUID]bool
+}
+
+func newUnionFind(mentions []*Mention) *unionFind {
+	uf := &unionFind{parent: make([]int, len(mentions)), patents: make([]map[uuid.UUID]bool, len(mentions))}
+	for i, m := range mentions {
+		uf.parent[i] = i
+		uf.patents[i] = map[uuid.UUID]bool{m.PatentID: true}
+	}
+	return uf
+}
+
+func (uf *unionFind) find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+func (uf *unionFind) union(a, b int) {
+	ra, rb := uf.find(a), uf.find(b)
+	if ra == rb {
+		return
+	}
+	pa, pb := uf.patents[ra], uf.patents[rb]
+	if len(pa) < len(pb) {
+		ra, rb, pa, pb = rb, ra, pb, pa
+	}
+	for p := range pb {
+		if pa[p] {
+			return
+		}
+	}
+	for p := range pb {
+		pa[p] = true
+	}
+	uf.parent[rb] = ra
+	uf.patents[rb] = nil
+}
+
+func bestFormSimilarity(a, b []string) float64 {
+	best := 0.0
+	for _, x := range a {
+		for _, y := range b {
+			best = max(best, NameSimilarity(x, y))
+		}
+	}
+	return best
+}
+
+// coInventorOverlap is the share of the smaller co-inventor list that also
+// appears, by name similarity, in the larger one.
+func coInventorOverlap(a, b [][]string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	used := make([]bool, len(b))
+	shared := 0
+	for _, x := range a {
+		for j, y := range b {
+			if !used[j] && bestFormSimilarity(x, y) >= minTokenSimilarity {
+				used[j] = true
+				shared++
+				break
+			}
+		}
+	}
+	return float64(shared) / float64(len(a))
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, x := range a {
+		set[x] = true
+	}
+	inter := 0
+	for _, y := range b {
+		if set[y] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,188 @@
+package inventor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type patentFixture struct {
+	assignee  string
+	country   string
+	cpc       []string
+	filed     string
+	inventors []string
+}
+
+// mentionsOf expands patents into mentions with co-inventors filled.
+func mentionsOf(t *testing.T, patents ...patentFixture) []*Mention {
+	t.Helper()
+	var out []*Mention
+	for i, p := range patents {
+		id := uuid.NewSHA1(uuid.NameSpaceOID, []byte{byte(i)})
+		filed, err := time.Parse("2006-01-02", p.filed)
+		require.NoError(t, err)
+		for seq, name := range p.inventors {
+			var co []string
+			for j, other := range p.inventors {
+				if j != seq {
+					co = append(co, other)
+				}
+			}
+			out = append(out, &Mention{
+				PatentID:     id,
+				PatentNumber: "P" + string(rune('A'+i)),
+				Sequence:     seq + 1,
+				Name:         name,
+				AssigneeName: p.assignee,
+				Country:      p.country,
+				CPCCodes:     p.cpc,
+				FilingDate:   &filed,
+				CoInventors:  co,
+			})
+		}
+	}
+	return out
+}
+
+func inventorNamed(inventors []*Inventor, variant string) *Inventor {
+	for _, inv := range inventors {
+		for _, v := range inv.Variants {
+			if v == variant {
+				return inv
+			}
+		}
+	}
+	return nil
+}
+
+func TestCluster_LinksVariantsAtSameAssignee(t *testing.T) {
+	mentions := mentionsOf(t,
+		patentFixture{"Samsung Display Co., Ltd.", "KR", []string{"H10K50/11"}, "2019-01-10", []string{"Kim, Jae-Hyun", "Park Min"}},
+		patentFixture{"SAMSUNG DISPLAY CO LTD", "US", []string{"C09K11/06"}, "2020-03-02", []string{"Jaehyun Kim", "Lee Sun"}},
+		patentFixture{"Samsung Display", "KR", []string{"H10K85/60"}, "2021-05-20", []string{"J. Kim", "Park Min"}},
+	)
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+
+	kim := inventorNamed(inventors, "Jaehyun Kim")
+	require.NotNil(t, kim)
+	assert.ElementsMatch(t, []string{"Kim, Jae-Hyun", "Jaehyun Kim", "J. Kim"}, kim.Variants)
+	assert.Equal(t, 3, kim.PatentCount)
+	assert.Equal(t, "2019-01-10", kim.FirstFiled.Format("2006-01-02"))
+	assert.Equal(t, "2021-05-20", kim.LastFiled.Format("2006-01-02"))
+
+	park := inventorNamed(inventors, "Park Min")
+	require.NotNil(t, park)
+	assert.Equal(t, 2, park.PatentCount)
+	assert.Len(t, inventors, 3)
+}
+
+func TestCluster_SameNameAloneDoesNotLink(t *testing.T) {
+	mentions := mentionsOf(t,
+		patentFixture{"BOE Technology Group", "CN", []string{"H10K50/11"}, "2019-01-10", []string{"Wei Zhang"}},
+		patentFixture{"Tianma Microelectronics", "JP", []string{"G09G3/32"}, "2020-01-10", []string{"Zhang Wei"}},
+	)
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+	assert.Len(t, inventors, 2)
+}
+
+func TestCluster_FollowsInventorAcrossAssigneesViaCoInventors(t *testing.T) {
+	mentions := mentionsOf(t,
+		patentFixture{"Universal Display Corporation", "US", []string{"H10K85/30"}, "2016-02-01", []string{"Peter Wolohan", "Alexey Dyatkin", "Chuanjun Xia"}},
+		patentFixture{"Universal Display Corp", "US", []string{"H10K85/30"}, "2017-06-01", []string{"Peter Wolohan", "Alexey Dyatkin"}},
+		patentFixture{"Merck Patent GmbH", "US", []string{"H10K85/30", "C07F15/00"}, "2019-09-01", []string{"Peter Wolohan", "Alexey B. Dyatkin"}},
+	)
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+
+	wolohan := inventorNamed(inventors, "Peter Wolohan")
+	require.NotNil(t, wolohan)
+	assert.Equal(t, 3, wolohan.PatentCount)
+	assert.Contains(t, wolohan.Assignees, "Merck Patent GmbH")
+}
+
+func TestCluster_NeverMergesMentionsOnOnePatent(t *testing.T) {
+	mentions := mentionsOf(t,
+		patentFixture{"LG Display Co., Ltd.", "KR", []string{"H10K50/11"}, "2019-01-10", []string{"Lee Sang Woo", "Lee Sangwoo"}},
+	)
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+	assert.Len(t, inventors, 2)
+}
+
+func TestCluster_CrossScriptThroughRomanisation(t *testing.T) {
+	mentions := mentionsOf(t,
+		patentFixture{"BOE Technology Group Co., Ltd.", "CN", []string{"H10K59/12"}, "2020-01-10", []string{"张伟 (Zhang Wei)", "李明"}},
+		patentFixture{"BOE Technology Group", "US", []string{"H10K59/12"}, "2021-01-10", []string{"Wei Zhang", "Ming Li"}},
+	)
+	mentions[1].NameEn = "Li Ming"
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+	assert.Len(t, inventors, 2)
+}
+
+func TestCluster_KeepsIDsStable(t *testing.T) {
+	fixtures := []patentFixture{
+		{"Idemitsu Kosan Co., Ltd.", "JP", []string{"C09K11/06"}, "2018-01-10", []string{"Hiroshi Tanaka", "Yuki Sato"}},
+		{"Idemitsu Kosan Co Ltd", "JP", []string{"C09K11/06"}, "2019-01-10", []string{"H. Tanaka", "Yuki Sato"}},
+	}
+	d := NewDisambiguator(Config{})
+
+	first := d.Cluster(mentionsOf(t, fixtures...))
+	again := d.Cluster(mentionsOf(t, fixtures...))
+	require.Len(t, first, 2)
+	assert.Equal(t, first[0].ID, again[0].ID, "IDs derive from the data, not the run")
+
+	// A persisted ID survives even when the derived seed would differ.
+	persisted := uuid.New()
+	mentions := mentionsOf(t, fixtures...)
+	for _, m := range mentions {
+		if m.Name == "Hiroshi Tanaka" || m.Name == "H. Tanaka" {
+			m.InventorID = &persisted
+		}
+	}
+	tanaka := inventorNamed(d.Cluster(mentions), "Hiroshi Tanaka")
+	require.NotNil(t, tanaka)
+	assert.Equal(t, persisted, tanaka.ID)
+}
+
+func TestAssignIDs_SplitKeepsIDWithLargerHalf(t *testing.T) {
+	shared := uuid.New()
+	m := func(seq int) *Mention {
+		return &Mention{PatentID: uuid.New(), Sequence: seq, Name: "A", InventorID: &shared}
+	}
+	big := []*Mention{m(1), m(1)}
+	small := []*Mention{m(1)}
+
+	inventors := assignIDs([][]*Mention{small, big})
+	require.Len(t, inventors, 2)
+	assert.Equal(t, shared, inventors[0].ID)
+	assert.Equal(t, 2, inventors[0].PatentCount)
+	assert.NotEqual(t, shared, inventors[1].ID)
+}
+
+func TestAssignments(t *testing.T) {
+	mentions := mentionsOf(t, patentFixture{"X", "KR", nil, "2020-01-01", []string{"A B", "C D"}})
+	inventors := NewDisambiguator(Config{}).Cluster(mentions)
+	assignments := Assignments(inventors)
+	assert.Len(t, assignments, 2)
+	for _, a := range assignments {
+		assert.Equal(t, mentions[0].PatentID, a.PatentID)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	mentions := []*Mention{
+		{PatentID: uuid.New(), Name: "Yuki Sato", InventorID: &a},
+		{PatentID: uuid.New(), Name: "Y. Sato", InventorID: &b},
+		{PatentID: uuid.New(), Name: "Yuki Sato", InventorID: &b},
+		{PatentID: uuid.New(), Name: "Unassigned"},
+	}
+	inventors := Group(mentions)
+	require.Len(t, inventors, 2)
+	assert.Equal(t, b, inventors[0].ID)
+	assert.Equal(t, "Yuki Sato", inventors[0].CanonicalName)
+}
+
+//Personal.AI order the ending
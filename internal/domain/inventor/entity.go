@@ -0,0 +1,159 @@
+// Package inventor disambiguates the inventor names on patents into people,
+// so that "ZHANG Wei", "Wei Zhang" and "张伟 (Zhang Wei)" at the same
+// company are tracked as one inventor across filings, assignees and scripts.
+package inventor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mention is one inventor name on one patent, with the evidence used to
+// decide which other mentions denote the same person.
+type Mention struct {
+	PatentID     uuid.UUID `json:"patent_id"`
+	PatentNumber string    `json:"patent_number"`
+	Sequence     int       `json:"sequence"`
+	Name         string    `json:"name"`
+	// NameEn is the romanised name recorded alongside a CJK name, if any.
+	NameEn       string `json:"name_en,omitempty"`
+	Affiliation  string `json:"affiliation,omitempty"`
+	AssigneeName string `json:"assignee_name,omitempty"`
+	// Country is the patent's jurisdiction, used as a weak location signal
+	// because most first filings are made where the inventors work.
+	Country    string     `json:"country,omitempty"`
+	CPCCodes   []string   `json:"cpc_codes,omitempty"`
+	FilingDate *time.Time `json:"filing_date,omitempty"`
+	// CoInventors holds the names of the other inventors on the patent.
+	CoInventors []string `json:"co_inventors,omitempty"`
+	// InventorID is the inventor this mention was assigned to by a previous
+	// run, if any. Disambiguation reuses it to keep IDs stable.
+	InventorID *uuid.UUID `json:"inventor_id,omitempty"`
+}
+
+// Key identifies the mention: a patent lists each inventor sequence once.
+func (m *Mention) Key() string {
+	return fmt.Sprintf("%s:%d", m.PatentID, m.Sequence)
+}
+
+// Assignment maps a mention to the inventor it belongs to.
+type Assignment struct {
+	PatentID   uuid.UUID `json:"patent_id"`
+	Sequence   int       `json:"sequence"`
+	InventorID uuid.UUID `json:"inventor_id"`
+}
+
+// Inventor is a disambiguated person with a profile summarised from their
+// mentions. Variants, Assignees, Countries and CPCSubclasses are ordered by
+// frequency, most common first.
+type Inventor struct {
+	ID             uuid.UUID  `json:"id"`
+	CanonicalName  string     `json:"canonical_name"`
+	NormalizedName string     `json:"normalized_name"`
+	Variants       []string   `json:"variants"`
+	Assignees      []string   `json:"assignees"`
+	Countries      []string   `json:"countries,omitempty"`
+	CPCSubclasses  []string   `json:"cpc_subclasses,omitempty"`
+	PatentCount    int        `json:"patent_count"`
+	FirstFiled     *time.Time `json:"first_filed,omitempty"`
+	LastFiled      *time.Time `json:"last_filed,omitempty"`
+	Mentions       []*Mention `json:"-"`
+}
+
+// NewInventor builds the profile of an inventor from their mentions. The
+// canonical name is the most frequent raw name; ties go to the longer one,
+// which is usually the fuller form ("John Smith" over "J. Smith").
+func NewInventor(id uuid.UUID, mentions []*Mention) *Inventor {
+	inv := &Inventor{ID: id, Mentions: mentions}
+	names, assignees, countries, cpcs := counter{}, counter{}, counter{}, counter{}
+	patents := make(map[uuid.UUID]bool)
+	for _, m := range mentions {
+		names.add(m.Name)
+		assignees.add(m.AssigneeName)
+		countries.add(m.Country)
+		for _, s := range cpcSubclasses(m.CPCCodes) {
+			cpcs.add(s)
+		}
+		patents[m.PatentID] = true
+		if m.FilingDate != nil {
+			if inv.FirstFiled == nil || m.FilingDate.Before(*inv.FirstFiled) {
+				inv.FirstFiled = m.FilingDate
+			}
+			if inv.LastFiled == nil || m.FilingDate.After(*inv.LastFiled) {
+				inv.LastFiled = m.FilingDate
+			}
+		}
+	}
+	inv.Variants = names.ranked()
+	if len(inv.Variants) > 0 {
+		inv.CanonicalName = inv.Variants[0]
+		inv.NormalizedName = NormalizeName(inv.CanonicalName)
+	}
+	inv.Assignees = assignees.ranked()
+	inv.Countries = countries.ranked()
+	inv.CPCSubclasses = cpcs.ranked()
+	inv.PatentCount = len(patents)
+	return inv
+}
+
+// Group builds inventors from mentions that already carry an InventorID,
+// ordered by patent count. Mentions without one are skipped.
+func Group(mentions []*Mention) []*Inventor {
+	byID := make(map[uuid.UUID][]*Mention)
+	var order []uuid.UUID
+	for _, m := range mentions {
+		if m.InventorID == nil {
+			continue
+		}
+		if _, ok := byID[*m.InventorID]; !ok {
+			order = append(order, *m.InventorID)
+		}
+		byID[*m.InventorID] = append(byID[*m.InventorID], m)
+	}
+	out := make([]*Inventor, 0, len(order))
+	for _, id := range order {
+		out = append(out, NewInventor(id, byID[id]))
+	}
+	sortInventors(out)
+	return out
+}
+
+func sortInventors(inventors []*Inventor) {
+	sort.SliceStable(inventors, func(i, j int) bool {
+		if inventors[i].PatentCount != inventors[j].PatentCount {
+			return inventors[i].PatentCount > inventors[j].PatentCount
+		}
+		return inventors[i].ID.String() < inventors[j].ID.String()
+	})
+}
+
+// counter tallies non-empty strings and ranks them by frequency.
+type counter map[string]int
+
+func (c counter) add(s string) {
+	if s != "" {
+		c[s]++
+	}
+}
+
+func (c counter) ranked() []string {
+	out := make([]string, 0, len(c))
+	for s := range c {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c[out[i]] != c[out[j]] {
+			return c[out[i]] > c[out[j]]
+		}
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) > len(out[j])
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,247 @@
+package inventor
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+)
+
+const (
+	// initialMatchScore is the credit for an initial matching a full given
+	// name ("J" against "John").
+	initialMatchScore = 0.8
+	// minTokenSimilarity is the Jaro-Winkler similarity at which two name
+	// tokens count as the same spelling ("Jon" and "John").
+	minTokenSimilarity = 0.88
+	// compactMatchScore is the similarity of names that differ only in
+	// spacing or hyphenation ("Jae-Hyun Kim" and "Jaehyun Kim").
+	compactMatchScore = 0.97
+	// extraTokenPenalty scales the similarity once per token only one name
+	// has, typically a middle name.
+	extraTokenPenalty = 0.96
+)
+
+// nameAffixes are titles and generational suffixes dropped from names.
+var nameAffixes = map[string]bool{
+	"dr": true, "prof": true, "mr": true, "mrs": true, "ms": true,
+	"jr": true, "sr": true, "phd": true, "ii": true, "iii": true, "iv": true,
+}
+
+// NormalizeName reduces an inventor name to the form used for matching:
+// compatibility-folded, accents stripped, lower-cased, "Last, First"
+// reordered to "first last", hyphens inside names removed ("Jae-Hyun"
+// becomes "jaehyun"), other punctuation collapsed to spaces and titles and
+// generational suffixes dropped. CJK names are kept as written, without
+// spaces, because their characters are the identity.
+func NormalizeName(name string) string {
+	s := norm.NFKC.String(strings.TrimSpace(name))
+	s = stripMarks(s)
+	s = strings.ToLower(s)
+
+	if parts := strings.Split(s, ","); len(parts) == 2 && !nameAffixes[strings.Trim(strings.TrimSpace(parts[1]), ".")] {
+		s = parts[1] + " " + parts[0]
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '-' || r == '\'' || r == '’':
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	var tokens []string
+	for _, t := range strings.Fields(b.String()) {
+		if !nameAffixes[t] {
+			tokens = append(tokens, t)
+		}
+	}
+	if isCJK(tokens) {
+		return strings.Join(tokens, "")
+	}
+	return strings.Join(tokens, " ")
+}
+
+// Forms returns the distinct normalised forms of a mention's name: the name
+// itself, its recorded romanisation and any parenthesised romanisation, so
+// "张伟 (Zhang Wei)" can match "Wei Zhang".
+func Forms(name, nameEn string) []string {
+	raw := []string{name, nameEn}
+	if open := strings.IndexAny(name, "(（"); open >= 0 {
+		inner := name[open:]
+		inner = strings.TrimLeft(inner, "(（")
+		if end := strings.IndexAny(inner, ")）"); end >= 0 {
+			inner = inner[:end]
+		}
+		raw = []string{name[:open], inner, nameEn}
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, r := range raw {
+		if n := NormalizeName(r); n != "" && !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// NameKeys returns the blocking keys of a normalised name. Two mentions are
+// only compared when they share a key. Each key pairs a full token with the
+// initial of another token, so "john a smith", "john smith", "j smith" and
+// "smith john" all share "smith|j".
+func NameKeys(normalized string) []string {
+	tokens := strings.Fields(normalized)
+	if len(tokens) == 1 {
+		return []string{"n:" + tokens[0]}
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for i, t := range tokens {
+		if len([]rune(t)) < 2 {
+			continue
+		}
+		for j, o := range tokens {
+			if i == j {
+				continue
+			}
+			k := t + "|" + string([]rune(o)[0])
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NameSimilarity rates how likely two normalised names denote the same
+// person, in [0, 1], ignoring token order. Every token of the shorter name
+// must match a distinct token of the longer one, either as a close spelling
+// or as an initial, and at least one match must be a full token; otherwise
+// the names are incompatible and the result is 0. "wei zhang" and "wei wang"
+// are therefore incompatible even though the strings are close.
+func NameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	ta, tb := strings.Fields(a), strings.Fields(b)
+	if strings.Join(ta, "") == strings.Join(tb, "") {
+		return compactMatchScore
+	}
+	if len(ta) > len(tb) {
+		ta, tb = tb, ta
+	}
+
+	type pair struct {
+		i, j  int
+		score float64
+	}
+	var pairs []pair
+	for i, x := range ta {
+		for j, y := range tb {
+			if s := tokenSimilarity(x, y); s > 0 {
+				pairs = append(pairs, pair{i, j, s})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(p, q int) bool { return pairs[p].score > pairs[q].score })
+
+	usedA, usedB := make([]bool, len(ta)), make([]bool, len(tb))
+	matched, full, total := 0, false, 0.0
+	for _, p := range pairs {
+		if usedA[p.i] || usedB[p.j] {
+			continue
+		}
+		usedA[p.i], usedB[p.j] = true, true
+		matched++
+		total += p.score
+		if p.score > initialMatchScore {
+			full = true
+		}
+	}
+	if matched < len(ta) || !full {
+		return 0
+	}
+	score := total / float64(matched)
+	for range len(tb) - matched {
+		score *= extraTokenPenalty
+	}
+	return score
+}
+
+// tokenSimilarity scores two name tokens: 1 when equal, initialMatchScore
+// when one is the initial of the other, their Jaro-Winkler similarity when
+// it reaches minTokenSimilarity, and 0 otherwise.
+func tokenSimilarity(x, y string) float64 {
+	if x == y {
+		return 1
+	}
+	rx, ry := []rune(x), []rune(y)
+	if len(rx) == 1 || len(ry) == 1 {
+		if rx[0] == ry[0] {
+			return initialMatchScore
+		}
+		return 0
+	}
+	if s := assignee.JaroWinkler(x, y); s >= minTokenSimilarity {
+		return s
+	}
+	return 0
+}
+
+// cpcSubclasses returns the distinct CPC subclasses ("H10K") of codes.
+func cpcSubclasses(codes []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, c := range codes {
+		c = strings.ToUpper(strings.ReplaceAll(c, " ", ""))
+		if len(c) < 4 {
+			continue
+		}
+		if s := c[:4]; !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func isCJK(tokens []string) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	for _, t := range tokens {
+		for _, r := range t {
+			if !unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stripMarks removes combining marks after canonical decomposition, turning
+// "Müller" into "Muller".
+func stripMarks(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+//Personal.AI order the ending
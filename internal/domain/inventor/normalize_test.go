@@ -0,0 +1,73 @@
+package inventor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"John Smith":         "john smith",
+		"Smith, John":        "john smith",
+		"SMITH, J.":          "j smith",
+		"Dr. José Müller":    "jose muller",
+		"Jae-Hyun Kim":       "jaehyun kim",
+		"O'Brien, Patrick":   "patrick obrien",
+		"John Smith, Jr.":    "john smith",
+		"張 偉":                "張偉",
+		"ＺＨＡＮＧ Ｗｅｉ":          "zhang wei",
+		"  ":                 "",
+		"Prof. Lee Sang-Woo": "lee sangwoo",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, NormalizeName(in), in)
+	}
+}
+
+func TestForms(t *testing.T) {
+	assert.Equal(t, []string{"张伟", "zhang wei"}, Forms("张伟 (Zhang Wei)", ""))
+	assert.Equal(t, []string{"李明", "ming li"}, Forms("李明", "Li, Ming"))
+	assert.Equal(t, []string{"john smith"}, Forms("John Smith", "JOHN SMITH"))
+}
+
+func TestNameKeys(t *testing.T) {
+	shared := func(a, b string) bool {
+		keys := map[string]bool{}
+		for _, k := range NameKeys(a) {
+			keys[k] = true
+		}
+		for _, k := range NameKeys(b) {
+			if keys[k] {
+				return true
+			}
+		}
+		return false
+	}
+	assert.True(t, shared("john a smith", "j smith"))
+	assert.True(t, shared("wei zhang", "zhang wei"))
+	assert.True(t, shared("jaehyun kim", "jae hyun kim"))
+	assert.False(t, shared("john smith", "mary jones"))
+	assert.Equal(t, []string{"n:张伟"}, NameKeys("张伟"))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, NameSimilarity("john smith", "john smith"))
+	assert.Equal(t, compactMatchScore, NameSimilarity("jaehyun kim", "jae hyun kim"))
+	assert.InDelta(t, 1.0, NameSimilarity("wei zhang", "zhang wei"), 1e-9)
+	assert.InDelta(t, 0.9, NameSimilarity("j smith", "john smith"), 1e-9)
+	assert.InDelta(t, 0.96, NameSimilarity("john a smith", "john smith"), 1e-9)
+	assert.Greater(t, NameSimilarity("jon smith", "john smith"), 0.9)
+
+	assert.Zero(t, NameSimilarity("wei zhang", "wei wang"))
+	assert.Zero(t, NameSimilarity("john smith", "jane smith"))
+	assert.Zero(t, NameSimilarity("j s", "john smith"), "initials alone are not a match")
+	assert.Zero(t, NameSimilarity("张伟", "张玮"))
+	assert.Zero(t, NameSimilarity("", "john smith"))
+}
+
+func TestCPCSubclasses(t *testing.T) {
+	assert.Equal(t, []string{"H10K", "C09K"}, cpcSubclasses([]string{"H10K 50/11", "h10k85/60", "C09K11/06", "C0"}))
+}
+
+//Personal.AI order the ending
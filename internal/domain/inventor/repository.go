@@ -0,0 +1,29 @@
+package inventor
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository reads inventor mentions from patents and records which
+// inventor each mention was assigned to.
+type Repository interface {
+	// ListMentions returns the mentions of up to limit patents with an ID
+	// strictly after the cursor, ordered by patent ID and sequence, with
+	// CoInventors filled. uuid.Nil starts from the beginning.
+	ListMentions(ctx context.Context, afterPatentID uuid.UUID, limit int) ([]*Mention, error)
+	// ListMentionsByInventors returns every mention assigned to one of ids.
+	ListMentionsByInventors(ctx context.Context, ids []uuid.UUID) ([]*Mention, error)
+	// ListCoMentions returns every mention on patents that one of ids
+	// invented, including the mentions of ids themselves.
+	ListCoMentions(ctx context.Context, ids []uuid.UUID) ([]*Mention, error)
+	// FindInventorIDsByAssignee returns up to limit IDs of inventors with a
+	// patent whose assignee name contains assigneeName, case-insensitively.
+	FindInventorIDsByAssignee(ctx context.Context, assigneeName string, limit int) ([]uuid.UUID, error)
+	// SaveAssignments writes inventor IDs onto mentions, returning the
+	// number of mentions whose ID changed.
+	SaveAssignments(ctx context.Context, assignments []Assignment) (int64, error)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,28 @@
+package usage
+
+import (
+	"context"
+	"time"
+)
+
+// Repository persists the usage ledger and tenant budgets.
+type Repository interface {
+	// Append adds a record to the ledger.
+	Append(ctx context.Context, record *Record) error
+	// Summarize aggregates the records selected by q, grouped by q.GroupBy
+	// and ordered by cost, highest first.
+	Summarize(ctx context.Context, q *Query) ([]*Line, error)
+	// TenantSpend sums the cost of a tenant's records in [from, to).
+	TenantSpend(ctx context.Context, tenantID string, from, to time.Time) (float64, error)
+
+	// GetBudget returns a tenant's stored budget, or an ErrCodeNotFound error.
+	GetBudget(ctx context.Context, tenantID string) (*Budget, error)
+	SaveBudget(ctx context.Context, budget *Budget) error
+
+	// MarkAlertSent records that a budget alert was raised for a tenant,
+	// period and level. It returns false when one was already recorded, so
+	// each alert goes out once even with several API server instances.
+	MarkAlertSent(ctx context.Context, tenantID string, period time.Time, level BudgetLevel) (bool, error)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,317 @@
+package usage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// DefaultTenantID is recorded for calls made without a tenant.
+const DefaultTenantID = "_default"
+
+// DefaultPriceKey names the price-table entry used for models without one.
+const DefaultPriceKey = "default"
+
+// MaxQueryRange bounds how much ledger a single summary may scan.
+const MaxQueryRange = 366 * 24 * time.Hour
+
+// ---------------------------------------------------------------------------
+// Ledger records
+// ---------------------------------------------------------------------------
+
+// Record is one metered LLM call in the usage ledger.
+type Record struct {
+	ID               string    `json:"id"`
+	TenantID         string    `json:"tenant_id"`
+	UserID           string    `json:"user_id,omitempty"`
+	Feature          string    `json:"feature"`
+	Provider         string    `json:"provider,omitempty"`
+	Model            string    `json:"model"`
+	RequestID        string    `json:"request_id,omitempty"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	Estimated        bool      `json:"estimated"`
+	CostUSD          float64   `json:"cost_usd"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// NewRecord creates a ledger record for one call. An empty tenant is recorded
+// as DefaultTenantID.
+func NewRecord(tenantID, userID, feature, provider, model string, promptTokens, completionTokens int64) (*Record, error) {
+	if promptTokens < 0 || completionTokens < 0 {
+		return nil, errors.InvalidParam("token counts cannot be negative")
+	}
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil, errors.InvalidParam("model cannot be empty")
+	}
+	if tenantID == "" {
+		tenantID = DefaultTenantID
+	}
+	return &Record{
+		ID:               uuid.New().String(),
+		TenantID:         tenantID,
+		UserID:           userID,
+		Feature:          feature,
+		Provider:         provider,
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		CreatedAt:        time.Now().UTC(),
+	}, nil
+}
+
+// TotalTokens returns prompt plus completion tokens.
+func (r *Record) TotalTokens() int64 {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// Period returns the start of the calendar month (UTC) that t falls in;
+// budgets are enforced per period.
+func Period(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// PeriodEnd returns the start of the period after the one t falls in.
+func PeriodEnd(t time.Time) time.Time {
+	return Period(t).AddDate(0, 1, 0)
+}
+
+// ---------------------------------------------------------------------------
+// Pricing
+// ---------------------------------------------------------------------------
+
+// ModelPrice is the USD price per 1,000 tokens of a model.
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// Cost prices a call.
+func (p ModelPrice) Cost(promptTokens, completionTokens int64) float64 {
+	cost := float64(promptTokens)/1000*p.InputPer1K + float64(completionTokens)/1000*p.OutputPer1K
+	// Round to micro-dollars so sums stay stable in the ledger.
+	return math.Round(cost*1e6) / 1e6
+}
+
+// PriceTable maps model names to prices. Keys are matched case-insensitively,
+// first exactly and then as the longest prefix, so "claude-sonnet-4" prices
+// "claude-sonnet-4-20250514"; the DefaultPriceKey entry covers the rest.
+type PriceTable struct {
+	prices   map[string]ModelPrice
+	prefixes []string // longest first
+}
+
+// NewPriceTable validates prices and builds a table.
+func NewPriceTable(prices map[string]ModelPrice) (*PriceTable, error) {
+	t := &PriceTable{prices: make(map[string]ModelPrice, len(prices))}
+	for model, p := range prices {
+		model = strings.ToLower(strings.TrimSpace(model))
+		if model == "" {
+			return nil, errors.InvalidParam("price table model name cannot be empty")
+		}
+		if p.InputPer1K < 0 || p.OutputPer1K < 0 {
+			return nil, errors.InvalidParam("price for " + model + " cannot be negative")
+		}
+		t.prices[model] = p
+		if model != DefaultPriceKey {
+			t.prefixes = append(t.prefixes, model)
+		}
+	}
+	sort.Slice(t.prefixes, func(i, j int) bool {
+		if len(t.prefixes[i]) != len(t.prefixes[j]) {
+			return len(t.prefixes[i]) > len(t.prefixes[j])
+		}
+		return t.prefixes[i] < t.prefixes[j]
+	})
+	return t, nil
+}
+
+// Lookup returns the price of model. The second result is false when neither
+// the model nor a default is priced.
+func (t *PriceTable) Lookup(model string) (ModelPrice, bool) {
+	if t == nil {
+		return ModelPrice{}, false
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	if p, ok := t.prices[model]; ok {
+		return p, true
+	}
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(model, prefix) {
+			return t.prices[prefix], true
+		}
+	}
+	p, ok := t.prices[DefaultPriceKey]
+	return p, ok
+}
+
+// Entries returns a copy of the table.
+func (t *PriceTable) Entries() map[string]ModelPrice {
+	out := make(map[string]ModelPrice)
+	if t == nil {
+		return out
+	}
+	for k, v := range t.prices {
+		out[k] = v
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Budgets
+// ---------------------------------------------------------------------------
+
+// BudgetLevel is how far a tenant's spend has gone into its budget.
+type BudgetLevel string
+
+const (
+	BudgetOK BudgetLevel = "ok"
+	// BudgetSoft means the soft limit was reached: alert, keep serving.
+	BudgetSoft BudgetLevel = "soft"
+	// BudgetHard means the hard limit was reached: refuse further calls.
+	BudgetHard BudgetLevel = "hard"
+)
+
+// Budget is a tenant's monthly LLM spend limits in USD. A zero limit is
+// unlimited.
+type Budget struct {
+	TenantID     string    `json:"tenant_id"`
+	SoftLimitUSD float64   `json:"soft_limit_usd"`
+	HardLimitUSD float64   `json:"hard_limit_usd"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// NewBudget validates and creates a budget.
+func NewBudget(tenantID string, softLimitUSD, hardLimitUSD float64) (*Budget, error) {
+	if strings.TrimSpace(tenantID) == "" {
+		return nil, errors.InvalidParam("tenant ID cannot be empty")
+	}
+	if softLimitUSD < 0 || hardLimitUSD < 0 {
+		return nil, errors.InvalidParam("budget limits cannot be negative")
+	}
+	if softLimitUSD > 0 && hardLimitUSD > 0 && softLimitUSD > hardLimitUSD {
+		return nil, errors.InvalidParam("soft limit cannot exceed hard limit")
+	}
+	return &Budget{
+		TenantID:     tenantID,
+		SoftLimitUSD: softLimitUSD,
+		HardLimitUSD: hardLimitUSD,
+		UpdatedAt:    time.Now().UTC(),
+	}, nil
+}
+
+// Level classifies spentUSD against the budget.
+func (b *Budget) Level(spentUSD float64) BudgetLevel {
+	switch {
+	case b == nil:
+		return BudgetOK
+	case b.HardLimitUSD > 0 && spentUSD >= b.HardLimitUSD:
+		return BudgetHard
+	case b.SoftLimitUSD > 0 && spentUSD >= b.SoftLimitUSD:
+		return BudgetSoft
+	}
+	return BudgetOK
+}
+
+// Limit returns the limit that defines level, or zero.
+func (b *Budget) Limit(level BudgetLevel) float64 {
+	switch level {
+	case BudgetSoft:
+		return b.SoftLimitUSD
+	case BudgetHard:
+		return b.HardLimitUSD
+	}
+	return 0
+}
+
+// Remaining returns what is left before the hard limit, or -1 without one.
+func (b *Budget) Remaining(spentUSD float64) float64 {
+	if b == nil || b.HardLimitUSD <= 0 {
+		return -1
+	}
+	return math.Max(0, b.HardLimitUSD-spentUSD)
+}
+
+// ---------------------------------------------------------------------------
+// Reporting
+// ---------------------------------------------------------------------------
+
+// Dimension groups a usage summary.
+type Dimension string
+
+const (
+	ByTenant  Dimension = "tenant"
+	ByUser    Dimension = "user"
+	ByFeature Dimension = "feature"
+	ByModel   Dimension = "model"
+	ByDay     Dimension = "day"
+)
+
+// IsValid reports whether d is a known dimension.
+func (d Dimension) IsValid() bool {
+	switch d {
+	case ByTenant, ByUser, ByFeature, ByModel, ByDay:
+		return true
+	}
+	return false
+}
+
+// Query selects ledger records for a summary. An empty TenantID covers all
+// tenants; UserID and Feature narrow the selection further.
+type Query struct {
+	TenantID string
+	UserID   string
+	Feature  string
+	From     time.Time // inclusive
+	To       time.Time // exclusive
+	GroupBy  Dimension
+}
+
+// Validate checks the query, defaulting GroupBy to ByFeature.
+func (q *Query) Validate() error {
+	if q.GroupBy == "" {
+		q.GroupBy = ByFeature
+	}
+	if !q.GroupBy.IsValid() {
+		return errors.InvalidParam("unknown group_by dimension " + string(q.GroupBy))
+	}
+	if q.From.IsZero() || q.To.IsZero() || !q.To.After(q.From) {
+		return errors.InvalidParam("usage range must have from before to")
+	}
+	if q.To.Sub(q.From) > MaxQueryRange {
+		return errors.InvalidParam("usage range cannot exceed one year")
+	}
+	return nil
+}
+
+// Line is one row of a usage summary.
+type Line struct {
+	Key               string  `json:"key"`
+	Requests          int64   `json:"requests"`
+	EstimatedRequests int64   `json:"estimated_requests"`
+	PromptTokens      int64   `json:"prompt_tokens"`
+	CompletionTokens  int64   `json:"completion_tokens"`
+	CostUSD           float64 `json:"cost_usd"`
+}
+
+// TotalTokens returns prompt plus completion tokens.
+func (l *Line) TotalTokens() int64 {
+	return l.PromptTokens + l.CompletionTokens
+}
+
+// Add accumulates other into l.
+func (l *Line) Add(other *Line) {
+	l.Requests += other.Requests
+	l.EstimatedRequests += other.EstimatedRequests
+	l.PromptTokens += other.PromptTokens
+	l.CompletionTokens += other.CompletionTokens
+	l.CostUSD = math.Round((l.CostUSD+other.CostUSD)*1e6) / 1e6
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,138 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRecord(t *testing.T) {
+	r, err := NewRecord("", "u1", "ai.chat", "anthropic", " claude-sonnet-4 ", 1200, 300)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.TenantID != DefaultTenantID || r.Model != "claude-sonnet-4" || r.ID == "" {
+		t.Errorf("unexpected record %+v", r)
+	}
+	if r.TotalTokens() != 1500 {
+		t.Errorf("expected 1500 total tokens, got %d", r.TotalTokens())
+	}
+	if _, err := NewRecord("t1", "", "", "", "m", -1, 0); err == nil {
+		t.Error("expected error for negative tokens")
+	}
+	if _, err := NewRecord("t1", "", "", "", "  ", 1, 0); err == nil {
+		t.Error("expected error for empty model")
+	}
+}
+
+func TestPriceTable_Lookup(t *testing.T) {
+	table, err := NewPriceTable(map[string]ModelPrice{
+		"claude-sonnet-4": {InputPer1K: 0.003, OutputPer1K: 0.015},
+		"claude":          {InputPer1K: 0.01, OutputPer1K: 0.05},
+		"Deepseek-Chat":   {InputPer1K: 0.00027, OutputPer1K: 0.0011},
+		DefaultPriceKey:   {InputPer1K: 0.001, OutputPer1K: 0.002},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		model string
+		want  ModelPrice
+	}{
+		{"claude-sonnet-4-20250514", ModelPrice{0.003, 0.015}},
+		{"claude-opus-4", ModelPrice{0.01, 0.05}},
+		{"deepseek-chat", ModelPrice{0.00027, 0.0011}},
+		{"gpt-4o", ModelPrice{0.001, 0.002}},
+	}
+	for _, tt := range tests {
+		got, ok := table.Lookup(tt.model)
+		if !ok || got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v; want %+v", tt.model, got, ok, tt.want)
+		}
+	}
+
+	if got := (ModelPrice{0.003, 0.015}).Cost(1200, 300); got != 0.0081 {
+		t.Errorf("expected cost 0.0081, got %v", got)
+	}
+
+	noDefault, _ := NewPriceTable(map[string]ModelPrice{"claude": {}})
+	if _, ok := noDefault.Lookup("gpt-4o"); ok {
+		t.Error("expected unpriced model without a default")
+	}
+	if _, err := NewPriceTable(map[string]ModelPrice{"m": {InputPer1K: -1}}); err == nil {
+		t.Error("expected error for negative price")
+	}
+}
+
+func TestBudget_Level(t *testing.T) {
+	b, err := NewBudget("t1", 80, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		spent float64
+		want  BudgetLevel
+	}{
+		{0, BudgetOK},
+		{79.99, BudgetOK},
+		{80, BudgetSoft},
+		{100, BudgetHard},
+		{250, BudgetHard},
+	}
+	for _, tt := range tests {
+		if got := b.Level(tt.spent); got != tt.want {
+			t.Errorf("Level(%v) = %s, want %s", tt.spent, got, tt.want)
+		}
+	}
+	if b.Remaining(30) != 70 || b.Remaining(130) != 0 {
+		t.Errorf("unexpected remaining %v / %v", b.Remaining(30), b.Remaining(130))
+	}
+
+	softOnly, _ := NewBudget("t1", 10, 0)
+	if softOnly.Level(1e6) != BudgetSoft || softOnly.Remaining(5) != -1 {
+		t.Error("a budget without a hard limit should never block")
+	}
+	if _, err := NewBudget("t1", 100, 50); err == nil {
+		t.Error("expected error when soft exceeds hard")
+	}
+	if _, err := NewBudget("", 1, 2); err == nil {
+		t.Error("expected error for empty tenant")
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	ts := time.Date(2026, 12, 31, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	if got := Period(ts); !got.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period %v", got)
+	}
+	if got := PeriodEnd(ts); !got.Equal(time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period end %v", got)
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	q := &Query{From: from, To: from.AddDate(0, 1, 0)}
+	if err := q.Validate(); err != nil || q.GroupBy != ByFeature {
+		t.Errorf("expected valid query grouped by feature, got %v / %s", err, q.GroupBy)
+	}
+	for _, bad := range []*Query{
+		{From: from, To: from, GroupBy: ByDay},
+		{From: from, To: from.AddDate(0, 1, 0), GroupBy: "region"},
+		{From: from, To: from.AddDate(2, 0, 0)},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestLine_Add(t *testing.T) {
+	total := &Line{}
+	total.Add(&Line{Requests: 2, PromptTokens: 10, CompletionTokens: 5, CostUSD: 0.1})
+	total.Add(&Line{Requests: 1, EstimatedRequests: 1, PromptTokens: 3, CostUSD: 0.2})
+	if total.Requests != 3 || total.EstimatedRequests != 1 || total.TotalTokens() != 18 || total.CostUSD != 0.3 {
+		t.Errorf("unexpected total %+v", total)
+	}
+}
+
+//Personal.AI order the ending
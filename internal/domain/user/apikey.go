@@ -0,0 +1,220 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// APIKeyTokenPrefix marks a credential as an API key rather than a JWT, so
+// it can be sent in either the X-API-Key or the Authorization header.
+const APIKeyTokenPrefix = "kip_"
+
+// apiKeySecretBytes is the entropy of a generated key. Keys are random, so
+// a fast unsalted hash is enough for storage and lookup.
+const apiKeySecretBytes = 32
+
+// apiKeyDisplayPrefixLen is how much of the plaintext key is kept so users
+// can tell their keys apart after the secret has been shown once.
+const apiKeyDisplayPrefixLen = 12
+
+// MaxAPIKeyNameLength bounds the key name.
+const MaxAPIKeyNameLength = 128
+
+// APIKeyTier selects the per-minute rate limit and monthly quota of a key.
+type APIKeyTier string
+
+const (
+	APIKeyTierFree         APIKeyTier = "free"
+	APIKeyTierProfessional APIKeyTier = "professional"
+	APIKeyTierEnterprise   APIKeyTier = "enterprise"
+)
+
+// IsValid reports whether t is a known tier.
+func (t APIKeyTier) IsValid() bool {
+	switch t {
+	case APIKeyTierFree, APIKeyTierProfessional, APIKeyTierEnterprise:
+		return true
+	}
+	return false
+}
+
+// RateLimit returns the default requests-per-minute limit of the tier.
+func (t APIKeyTier) RateLimit() int {
+	switch t {
+	case APIKeyTierEnterprise:
+		return 1000
+	case APIKeyTierProfessional:
+		return 300
+	}
+	return 60
+}
+
+// MonthlyQuota returns the default number of requests allowed per calendar
+// month.
+func (t APIKeyTier) MonthlyQuota() int64 {
+	switch t {
+	case APIKeyTierEnterprise:
+		return 1000000
+	case APIKeyTierProfessional:
+		return 100000
+	}
+	return 10000
+}
+
+// GenerateAPIKeySecret returns a new random plaintext key.
+func GenerateAPIKeySecret() (string, error) {
+	buf := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate API key")
+	}
+	return APIKeyTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashAPIKey returns the stored form of a plaintext key.
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyDisplayPrefix returns the non-secret leading part of a key.
+func APIKeyDisplayPrefix(secret string) string {
+	if len(secret) <= apiKeyDisplayPrefixLen {
+		return secret
+	}
+	return secret[:apiKeyDisplayPrefixLen]
+}
+
+// NormalizeAPIKeyScopes trims, de-duplicates and sorts scopes.
+func NormalizeAPIKeyScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NewAPIKey creates an active key for userID and returns it together with
+// the plaintext secret, which is not stored and cannot be recovered.
+func NewAPIKey(userID uuid.UUID, name string, scopes []string, tier APIKeyTier, expiresAt *time.Time) (*APIKey, string, error) {
+	if userID == uuid.Nil {
+		return nil, "", errors.InvalidParam("user ID cannot be empty")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.InvalidParam("API key name cannot be empty")
+	}
+	if len(name) > MaxAPIKeyNameLength {
+		return nil, "", errors.InvalidParam("API key name too long")
+	}
+	if tier == "" {
+		tier = APIKeyTierFree
+	}
+	if !tier.IsValid() {
+		return nil, "", errors.InvalidParam("invalid API key tier")
+	}
+	now := time.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", errors.InvalidParam("API key expiry must be in the future")
+	}
+
+	k := &APIKey{
+		UserID:       userID,
+		Name:         name,
+		RateLimit:    tier.RateLimit(),
+		Tier:         tier,
+		MonthlyQuota: tier.MonthlyQuota(),
+		ExpiresAt:    expiresAt,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := k.SetScopes(scopes); err != nil {
+		return nil, "", err
+	}
+	secret, err := GenerateAPIKeySecret()
+	if err != nil {
+		return nil, "", err
+	}
+	k.KeyHash = HashAPIKey(secret)
+	k.KeyPrefix = APIKeyDisplayPrefix(secret)
+	return k, secret, nil
+}
+
+// SetScopes replaces the key's scopes. A key must carry at least one scope.
+func (k *APIKey) SetScopes(scopes []string) error {
+	scopes = NormalizeAPIKeyScopes(scopes)
+	if len(scopes) == 0 {
+		return errors.InvalidParam("API key requires at least one scope")
+	}
+	k.Scopes = scopes
+	k.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired reports whether the key's expiry has passed at now.
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IsUsable reports whether the key may authenticate requests at now.
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.IsActive && k.RevokedAt == nil && !k.IsExpired(now)
+}
+
+// Revoke permanently disables the key.
+func (k *APIKey) Revoke(now time.Time) {
+	k.IsActive = false
+	if k.RevokedAt == nil {
+		k.RevokedAt = &now
+	}
+	k.UpdatedAt = now
+}
+
+// ExpireBy shortens the key's lifetime so it stops working at deadline. A
+// key that already expires earlier is left alone.
+func (k *APIKey) ExpireBy(deadline time.Time) {
+	if k.ExpiresAt == nil || deadline.Before(*k.ExpiresAt) {
+		k.ExpiresAt = &deadline
+	}
+	k.UpdatedAt = time.Now().UTC()
+}
+
+// QuotaExceeded reports whether used requests exceed the monthly quota. A
+// non-positive quota is unlimited.
+func (k *APIKey) QuotaExceeded(used int64) bool {
+	return k.MonthlyQuota > 0 && used > k.MonthlyQuota
+}
+
+// UsagePeriod returns the start of the calendar month (UTC) that t falls in;
+// monthly quotas are counted per period.
+func UsagePeriod(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+//Personal.AI order the ending
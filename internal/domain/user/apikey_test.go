@@ -0,0 +1,126 @@
+package user
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	uid := uuid.New()
+	k, secret, err := NewAPIKey(uid, "  ci  ", []string{"patent:read", " patent:read", "analysis:read"}, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(secret, APIKeyTokenPrefix) {
+		t.Errorf("expected secret prefix %q, got %q", APIKeyTokenPrefix, secret)
+	}
+	if k.KeyHash != HashAPIKey(secret) {
+		t.Error("stored hash does not match secret")
+	}
+	if !strings.HasPrefix(secret, k.KeyPrefix) || len(k.KeyPrefix) != apiKeyDisplayPrefixLen {
+		t.Errorf("unexpected display prefix %q", k.KeyPrefix)
+	}
+	if k.Name != "ci" {
+		t.Errorf("expected trimmed name, got %q", k.Name)
+	}
+	if k.Tier != APIKeyTierFree || k.RateLimit != 60 || k.MonthlyQuota != 10000 {
+		t.Errorf("unexpected tier defaults: %s %d %d", k.Tier, k.RateLimit, k.MonthlyQuota)
+	}
+	if len(k.Scopes) != 2 || k.Scopes[0] != "analysis:read" || k.Scopes[1] != "patent:read" {
+		t.Errorf("unexpected scopes %v", k.Scopes)
+	}
+	if !k.IsUsable(time.Now()) {
+		t.Error("expected new key to be usable")
+	}
+
+	_, other, _ := NewAPIKey(uid, "ci", []string{"patent:read"}, APIKeyTierFree, nil)
+	if other == secret {
+		t.Error("expected distinct secrets")
+	}
+}
+
+func TestNewAPIKey_Validation(t *testing.T) {
+	uid := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	cases := map[string]func() error{
+		"nil user": func() error {
+			_, _, err := NewAPIKey(uuid.Nil, "ci", []string{"patent:read"}, "", nil)
+			return err
+		},
+		"empty name": func() error {
+			_, _, err := NewAPIKey(uid, " ", []string{"patent:read"}, "", nil)
+			return err
+		},
+		"no scopes": func() error {
+			_, _, err := NewAPIKey(uid, "ci", []string{" "}, "", nil)
+			return err
+		},
+		"bad tier": func() error {
+			_, _, err := NewAPIKey(uid, "ci", []string{"patent:read"}, "gold", nil)
+			return err
+		},
+		"past expiry": func() error {
+			_, _, err := NewAPIKey(uid, "ci", []string{"patent:read"}, "", &past)
+			return err
+		},
+	}
+	for name, fn := range cases {
+		if fn() == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAPIKey_Lifecycle(t *testing.T) {
+	now := time.Now().UTC()
+	exp := now.Add(48 * time.Hour)
+	k, _, err := NewAPIKey(uuid.New(), "ci", []string{"patent:read"}, APIKeyTierEnterprise, &exp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	k.ExpireBy(now.Add(time.Hour))
+	if !k.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected shortened expiry, got %v", k.ExpiresAt)
+	}
+	k.ExpireBy(now.Add(72 * time.Hour))
+	if !k.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Error("ExpireBy must not extend the lifetime")
+	}
+	if !k.IsExpired(now.Add(2 * time.Hour)) {
+		t.Error("expected key to be expired")
+	}
+
+	k.Revoke(now)
+	if k.IsUsable(now) || k.RevokedAt == nil {
+		t.Error("expected revoked key to be unusable")
+	}
+}
+
+func TestAPIKey_QuotaExceeded(t *testing.T) {
+	k := &APIKey{MonthlyQuota: 10}
+	if k.QuotaExceeded(10) {
+		t.Error("quota reached but not exceeded")
+	}
+	if !k.QuotaExceeded(11) {
+		t.Error("expected quota exceeded")
+	}
+	k.MonthlyQuota = 0
+	if k.QuotaExceeded(1 << 40) {
+		t.Error("zero quota is unlimited")
+	}
+}
+
+func TestUsagePeriod(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	got := UsagePeriod(time.Date(2026, 3, 1, 2, 0, 0, 0, loc))
+	want := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+//Personal.AI order the ending
@@ -83,6 +83,10 @@ type APIKey struct {
 	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
 	LastUsedIP     string     `json:"last_used_ip,omitempty"`
 	IsActive       bool       `json:"is_active"`
+	Tier           APIKeyTier `json:"tier"`
+	MonthlyQuota   int64      `json:"monthly_quota"`
+	RotatedFromID  *uuid.UUID `json:"rotated_from_id,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
 }
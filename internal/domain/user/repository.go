@@ -50,7 +50,11 @@ type UserRepository interface {
 	CreateAPIKey(ctx context.Context, key *APIKey) error
 	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
 	GetAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*APIKey, error)
+	GetAPIKeyByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	UpdateAPIKey(ctx context.Context, key *APIKey) error
 	UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, ip string) error
+	IncrementAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error)
+	GetAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error)
 	DeactivateAPIKey(ctx context.Context, id uuid.UUID) error
 	DeleteAPIKey(ctx context.Context, id uuid.UUID) error
 
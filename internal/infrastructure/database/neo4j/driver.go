@@ -37,7 +37,7 @@ type Transaction interface {
 
 // internalSession abstracts neo4j.SessionWithContext
 type internalSession interface {
-	ExecuteRead(ctx context.Context, work func(Transaction) (any, error)) (any, error)
+	ExecuteRead(ctx context.Context, work func(Transaction) (any, error), configurers ...func(*neo4j.TransactionConfig)) (any, error)
 	ExecuteWrite(ctx context.Context, work func(Transaction) (any, error)) (any, error)
 	Close(ctx context.Context) error
 }
@@ -85,10 +85,10 @@ type stdSession struct {
 	s neo4j.SessionWithContext
 }
 
-func (s *stdSession) ExecuteRead(ctx context.Context, work func(Transaction) (any, error)) (any, error) {
+func (s *stdSession) ExecuteRead(ctx context.Context, work func(Transaction) (any, error), configurers ...func(*neo4j.TransactionConfig)) (any, error) {
 	return s.s.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
 		return work(&stdTransaction{tx: tx})
-	})
+	}, configurers...)
 }
 
 func (s *stdSession) ExecuteWrite(ctx context.Context, work func(Transaction) (any, error)) (any, error) {
@@ -185,11 +185,13 @@ func (d *Driver) WriteSession(ctx context.Context) internalSession {
 	return d.Session(ctx, neo4j.AccessModeWrite)
 }
 
-func (d *Driver) ExecuteRead(ctx context.Context, work func(Transaction) (interface{}, error)) (interface{}, error) {
+// ExecuteRead runs work in a read-access session. configurers such as
+// neo4j.WithTxTimeout apply to the transaction.
+func (d *Driver) ExecuteRead(ctx context.Context, work func(Transaction) (interface{}, error), configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
 	session := d.ReadSession(ctx)
 	defer session.Close(ctx)
 
-	result, err := session.ExecuteRead(ctx, work)
+	result, err := session.ExecuteRead(ctx, work, configurers...)
 	if err != nil {
 		d.logger.Error("Neo4j read transaction failed", logging.Err(err))
 		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "neo4j read failed")
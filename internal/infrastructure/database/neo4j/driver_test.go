@@ -29,7 +29,7 @@ type MockSession struct {
 	mock.Mock
 }
 
-func (m *MockSession) ExecuteRead(ctx context.Context, work func(Transaction) (any, error)) (interface{}, error) {
+func (m *MockSession) ExecuteRead(ctx context.Context, work func(Transaction) (any, error), configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
 	tx := new(MockTransaction)
 	return work(tx)
 }
@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/citation"
+	driver "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/neo4j"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// In-process citation analytics, used when the GDS plugin is not installed
+// (e.g. Neo4j Community). The citation graph is exported page by page with
+// keyset pagination on Patent.id, analysed in memory, and the scores are
+// written back to the Patent nodes in batches.
+
+const (
+	citationExportPageSize = 5000
+	citationWriteBatchSize = 1000
+)
+
+// ---------------------------------------------------------------------------
+// Full metrics run
+// ---------------------------------------------------------------------------
+
+func (r *neo4jCitationRepo) CalculateCitationMetrics(ctx context.Context, opts citation.AnalyticsOptions) (*citation.CitationMetricsSummary, error) {
+	start := time.Now()
+	g, err := r.exportCitationGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := citation.ComputeCitationMetrics(ctx, g, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.writeCitationMetrics(ctx, res.Metrics); err != nil {
+		return nil, err
+	}
+
+	summary := &citation.CitationMetricsSummary{
+		Engine:             citation.EngineInProcess,
+		NodeCount:          g.NodeCount(),
+		EdgeCount:          g.EdgeCount(),
+		PageRankIterations: res.PageRankIterations,
+		HITSIterations:     res.HITSIterations,
+		ComputedAt:         time.Now().UTC(),
+	}
+	r.log.Info("citation metrics computed",
+		logging.String("engine", summary.Engine),
+		logging.Int("nodes", summary.NodeCount),
+		logging.Int("edges", summary.EdgeCount),
+		logging.Duration("elapsed", time.Since(start)))
+	return summary, nil
+}
+
+// calculatePageRankInProcess is the CalculatePageRank fallback. It runs the
+// full metrics pass, since exporting the graph dominates the cost.
+func (r *neo4jCitationRepo) calculatePageRankInProcess(ctx context.Context, iterations int, dampingFactor float64) error {
+	r.log.Warn("neo4j GDS plugin not available, computing citation PageRank in process")
+	_, err := r.CalculateCitationMetrics(ctx, citation.AnalyticsOptions{
+		PageRankIterations: iterations,
+		DampingFactor:      dampingFactor,
+	})
+	return err
+}
+
+// ---------------------------------------------------------------------------
+// Export
+// ---------------------------------------------------------------------------
+
+func (r *neo4jCitationRepo) exportCitationGraph(ctx context.Context) (*citation.CitationGraph, error) {
+	query := `
+		MATCH (p:Patent)
+		WHERE $after IS NULL OR p.id > $after
+		WITH p ORDER BY p.id LIMIT $limit
+		OPTIONAL MATCH (p)-[:CITES]->(cited:Patent)
+		RETURN p.id AS id, p.filing_date AS filing_date, collect(DISTINCT cited.id) AS cites
+		ORDER BY id
+	`
+	g := citation.NewCitationGraph()
+	var after interface{}
+	for {
+		params := map[string]interface{}{
+			"after": after,
+			"limit": citationExportPageSize,
+		}
+		res, err := r.driver.ExecuteRead(ctx, func(tx driver.Transaction) (interface{}, error) {
+			result, err := tx.Run(ctx, query, params)
+			if err != nil {
+				return nil, err
+			}
+			return driver.CollectRecords(ctx, result, func(rec *neo4j.Record) (string, error) {
+				return addCitationExportRecord(g, rec), nil
+			})
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to export citation graph")
+		}
+		ids, _ := res.([]string)
+		if len(ids) < citationExportPageSize {
+			return g, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+// addCitationExportRecord adds one exported node and its outgoing citations
+// to g and returns the raw node id for keyset pagination. Nodes whose id is
+// not a UUID are skipped but still advance the cursor.
+func addCitationExportRecord(g *citation.CitationGraph, rec *neo4j.Record) string {
+	rawID, _ := rec.Get("id")
+	idStr, _ := rawID.(string)
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return idStr
+	}
+
+	var filingDate *time.Time
+	if fd, ok := rec.Get("filing_date"); ok && fd != nil {
+		filingDate = extractTime(fd)
+	}
+	g.AddPatent(id, filingDate)
+
+	if cites, ok := rec.Get("cites"); ok {
+		list, _ := cites.([]interface{})
+		for _, c := range list {
+			s, _ := c.(string)
+			if to, err := uuid.Parse(s); err == nil {
+				g.AddCitation(id, to)
+			}
+		}
+	}
+	return idStr
+}
+
+// ---------------------------------------------------------------------------
+// Write-back
+// ---------------------------------------------------------------------------
+
+func (r *neo4jCitationRepo) writeCitationMetrics(ctx context.Context, metrics []*citation.PatentCitationMetrics) error {
+	query := `
+		UNWIND $batch AS row
+		MATCH (p:Patent {id: row.id})
+		SET p.pagerank = row.pagerank,
+		    p.hub_score = row.hub_score,
+		    p.authority_score = row.authority_score,
+		    p.citation_half_life = row.citation_half_life,
+		    p.tech_cycle_time = row.tech_cycle_time,
+		    p.citation_metrics_at = datetime()
+	`
+	for start := 0; start < len(metrics); start += citationWriteBatchSize {
+		end := start + citationWriteBatchSize
+		if end > len(metrics) {
+			end = len(metrics)
+		}
+		batch := citationMetricsRows(metrics[start:end])
+		_, err := r.driver.ExecuteWrite(ctx, func(tx driver.Transaction) (interface{}, error) {
+			_, err := tx.Run(ctx, query, map[string]interface{}{"batch": batch})
+			return nil, err
+		})
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to write citation metrics")
+		}
+	}
+	return nil
+}
+
+// citationMetricsRows converts metrics to UNWIND rows. Missing half-life and
+// cycle-time values are sent as null, which removes stale properties.
+func citationMetricsRows(metrics []*citation.PatentCitationMetrics) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(metrics))
+	for _, m := range metrics {
+		row := map[string]interface{}{
+			"id":                 m.PatentID.String(),
+			"pagerank":           m.PageRank,
+			"hub_score":          m.HubScore,
+			"authority_score":    m.AuthorityScore,
+			"citation_half_life": nil,
+			"tech_cycle_time":    nil,
+		}
+		if m.CitationHalfLife != nil {
+			row["citation_half_life"] = *m.CitationHalfLife
+		}
+		if m.TechCycleTime != nil {
+			row["tech_cycle_time"] = *m.TechCycleTime
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ---------------------------------------------------------------------------
+// Read-back
+// ---------------------------------------------------------------------------
+
+func (r *neo4jCitationRepo) GetCitationMetrics(ctx context.Context, patentID uuid.UUID) (*citation.PatentCitationMetrics, error) {
+	query := `
+		MATCH (p:Patent {id: $id})
+		RETURN p.pagerank AS pagerank, p.hub_score AS hub_score, p.authority_score AS authority_score,
+		       p.citation_half_life AS citation_half_life, p.tech_cycle_time AS tech_cycle_time,
+		       p.citation_metrics_at AS computed_at
+	`
+	params := map[string]interface{}{
+		"id": patentID.String(),
+	}
+
+	res, err := r.driver.ExecuteRead(ctx, func(tx driver.Transaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return driver.ExtractSingleRecord(ctx, result, func(rec *neo4j.Record) (*citation.PatentCitationMetrics, error) {
+			return recordToCitationMetrics(rec, patentID), nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*citation.PatentCitationMetrics), nil
+}
+
+func recordToCitationMetrics(rec *neo4j.Record, patentID uuid.UUID) *citation.PatentCitationMetrics {
+	m := &citation.PatentCitationMetrics{PatentID: patentID}
+	if v, ok := rec.Get("pagerank"); ok && v != nil {
+		m.PageRank = toFloat64(v)
+	}
+	if v, ok := rec.Get("hub_score"); ok && v != nil {
+		m.HubScore = toFloat64(v)
+	}
+	if v, ok := rec.Get("authority_score"); ok && v != nil {
+		m.AuthorityScore = toFloat64(v)
+	}
+	if v, ok := rec.Get("citation_half_life"); ok && v != nil {
+		f := toFloat64(v)
+		m.CitationHalfLife = &f
+	}
+	if v, ok := rec.Get("tech_cycle_time"); ok && v != nil {
+		f := toFloat64(v)
+		m.TechCycleTime = &f
+	}
+	if v, ok := rec.Get("computed_at"); ok && v != nil {
+		t := extractTimeValue(v)
+		m.ComputedAt = &t
+	}
+	return m
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/citation"
+)
+
+func TestAddCitationExportRecord(t *testing.T) {
+	g := citation.NewCitationGraph()
+	from, to := uuid.New(), uuid.New()
+	rec := &neo4j.Record{
+		Keys:   []string{"id", "filing_date", "cites"},
+		Values: []any{from.String(), neo4j.DateOf(time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)), []any{to.String(), "not-a-uuid"}},
+	}
+
+	cursor := addCitationExportRecord(g, rec)
+	assert.Equal(t, from.String(), cursor)
+	assert.Equal(t, 2, g.NodeCount())
+	assert.Equal(t, 1, g.EdgeCount())
+
+	bad := &neo4j.Record{Keys: []string{"id", "filing_date", "cites"}, Values: []any{"legacy-1", nil, []any{}}}
+	assert.Equal(t, "legacy-1", addCitationExportRecord(g, bad))
+	assert.Equal(t, 2, g.NodeCount())
+}
+
+func TestCitationMetricsRows(t *testing.T) {
+	hl := 7.5
+	id := uuid.New()
+	rows := citationMetricsRows([]*citation.PatentCitationMetrics{{PatentID: id, PageRank: 0.2, CitationHalfLife: &hl}})
+	require.Len(t, rows, 1)
+	assert.Equal(t, id.String(), rows[0]["id"])
+	assert.Equal(t, 0.2, rows[0]["pagerank"])
+	assert.Equal(t, 7.5, rows[0]["citation_half_life"])
+	v, ok := rows[0]["tech_cycle_time"]
+	assert.True(t, ok)
+	assert.Nil(t, v)
+}
+
+func TestRecordToCitationMetrics(t *testing.T) {
+	id := uuid.New()
+	rec := &neo4j.Record{
+		Keys:   []string{"pagerank", "hub_score", "authority_score", "citation_half_life", "tech_cycle_time", "computed_at"},
+		Values: []any{0.3, 0.1, 0.9, nil, int64(4), nil},
+	}
+	m := recordToCitationMetrics(rec, id)
+	assert.Equal(t, id, m.PatentID)
+	assert.Equal(t, 0.9, m.AuthorityScore)
+	assert.Nil(t, m.CitationHalfLife)
+	require.NotNil(t, m.TechCycleTime)
+	assert.Equal(t, 4.0, *m.TechCycleTime)
+	assert.Nil(t, m.ComputedAt)
+}
+
+//Personal.AI order the ending
@@ -49,6 +49,9 @@ func neo4jNodeToPatentNode(n neo4j.Node) *citation.PatentNode {
 	if ca, ok := n.Props["created_at"]; ok && ca != nil {
 		pn.CreatedAt = extractTimeValue(ca)
 	}
+	if a, ok := n.Props["assignee_id"].(string); ok {
+		pn.AssigneeID = a
+	}
 	return pn
 }
 
@@ -142,6 +145,7 @@ func (r *neo4jCitationRepo) BatchEnsurePatentNodes(ctx context.Context, patents
 		MERGE (p:Patent {id: row.id})
 		ON CREATE SET p.patent_number = row.number, p.jurisdiction = row.jurisdiction, p.filing_date = date(row.filing_date), p.created_at = datetime()
 		ON MATCH SET p.patent_number = row.number, p.jurisdiction = row.jurisdiction
+		SET p.assignee_id = coalesce(row.assignee_id, p.assignee_id)
 	`
 	var batch []map[string]interface{}
 	for _, p := range patents {
@@ -153,6 +157,9 @@ func (r *neo4jCitationRepo) BatchEnsurePatentNodes(ctx context.Context, patents
 		if p.FilingDate != nil {
 			row["filing_date"] = p.FilingDate.Format("2006-01-02")
 		}
+		if p.AssigneeID != "" {
+			row["assignee_id"] = p.AssigneeID
+		}
 		batch = append(batch, row)
 	}
 
@@ -553,6 +560,65 @@ func (r *neo4jCitationRepo) GetMostCitedPatents(ctx context.Context, jurisdictio
 	return res.([]*citation.PatentWithCitationCount), nil
 }
 
+// mostCitedAssigneesQuery totals citations per canonical assignee. Each
+// patent is counted once per assignee before the citations are summed.
+func mostCitedAssigneesQuery(byJurisdiction bool) string {
+	filter := ""
+	if byJurisdiction {
+		filter = " AND p.jurisdiction = $jurisdiction"
+	}
+	return `
+		MATCH (p:Patent)
+		WHERE p.assignee_id IS NOT NULL` + filter + `
+		OPTIONAL MATCH ()-[r:CITES]->(p)
+		WITH p, count(r) AS cites
+		RETURN p.assignee_id AS assignee_id, count(p) AS patent_count, sum(cites) AS citation_count
+		ORDER BY citation_count DESC, assignee_id
+		LIMIT $limit
+	`
+}
+
+func (r *neo4jCitationRepo) GetMostCitedAssignees(ctx context.Context, jurisdiction *string, limit int) ([]*citation.AssigneeWithCitationCount, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	params := map[string]interface{}{
+		"limit": limit,
+	}
+	byJurisdiction := jurisdiction != nil && *jurisdiction != ""
+	if byJurisdiction {
+		params["jurisdiction"] = *jurisdiction
+	}
+	query := mostCitedAssigneesQuery(byJurisdiction)
+
+	res, err := r.driver.ExecuteRead(ctx, func(tx driver.Transaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return driver.CollectRecords(ctx, result, func(rec *neo4j.Record) (*citation.AssigneeWithCitationCount, error) {
+			ac := &citation.AssigneeWithCitationCount{}
+			if id, ok := rec.Get("assignee_id"); ok {
+				ac.AssigneeID, _ = id.(string)
+			}
+			if pc, ok := rec.Get("patent_count"); ok && pc != nil {
+				ac.PatentCount = toInt64(pc)
+			}
+			if cc, ok := rec.Get("citation_count"); ok && cc != nil {
+				ac.CitationCount = toInt64(cc)
+			}
+			return ac, nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return []*citation.AssigneeWithCitationCount{}, nil
+	}
+	return res.([]*citation.AssigneeWithCitationCount), nil
+}
+
 // ---------------------------------------------------------------------------
 // Citation chain — shortest path between two patents
 // ---------------------------------------------------------------------------
@@ -704,7 +770,7 @@ func (r *neo4jCitationRepo) GetBibliographicCoupling(ctx context.Context, patent
 }
 
 // ---------------------------------------------------------------------------
-// PageRank via GDS plugin
+// PageRank via GDS plugin, falling back to the in-process engine
 // ---------------------------------------------------------------------------
 
 func (r *neo4jCitationRepo) CalculatePageRank(ctx context.Context, iterations int, dampingFactor float64) error {
@@ -714,7 +780,11 @@ func (r *neo4jCitationRepo) CalculatePageRank(ctx context.Context, iterations in
 	if dampingFactor <= 0 {
 		dampingFactor = 0.85
 	}
-	return r.runPageRank(ctx, iterations, dampingFactor, false)
+	err := r.runPageRank(ctx, iterations, dampingFactor, false)
+	if isGDSNotAvailableError(err) {
+		return r.calculatePageRankInProcess(ctx, iterations, dampingFactor)
+	}
+	return err
 }
 
 func (r *neo4jCitationRepo) runPageRank(ctx context.Context, iterations int, dampingFactor float64, isRetry bool) error {
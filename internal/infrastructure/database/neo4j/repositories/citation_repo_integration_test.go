@@ -313,6 +313,33 @@ func (s *CitationRepoIntegrationTestSuite) TestGetMostCitedPatents() {
 	s.Require().NotEmpty(mostCitedUS)
 }
 
+func (s *CitationRepoIntegrationTestSuite) TestGetMostCitedAssignees() {
+	assignee := uuid.NewString()
+	cited := []uuid.UUID{uuid.New(), uuid.New()}
+	citer := uuid.New()
+	s.Require().NoError(s.repo.BatchEnsurePatentNodes(s.ctx, []*citation.PatentNodeData{
+		{ID: cited[0], PatentNumber: "US-MCA-001", Jurisdiction: "US", AssigneeID: assignee},
+		{ID: cited[1], PatentNumber: "US-MCA-002", Jurisdiction: "US", AssigneeID: assignee},
+		{ID: citer, PatentNumber: "US-MCA-003", Jurisdiction: "US"},
+	}))
+	for _, c := range cited {
+		s.Require().NoError(s.repo.CreateCitation(s.ctx, citer, c, "FORWARD", nil))
+	}
+
+	usPtr := "US"
+	top, err := s.repo.GetMostCitedAssignees(s.ctx, &usPtr, 100)
+	s.Require().NoError(err)
+	found := false
+	for _, a := range top {
+		if a.AssigneeID == assignee {
+			found = true
+			s.Equal(int64(2), a.PatentCount)
+			s.Equal(int64(2), a.CitationCount)
+		}
+	}
+	s.True(found, "assignee should be grouped across its patents")
+}
+
 func (s *CitationRepoIntegrationTestSuite) TestGetCitationChain() {
 	p1 := uuid.New()
 	p2 := uuid.New()
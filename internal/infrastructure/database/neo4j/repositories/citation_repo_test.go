@@ -21,6 +21,15 @@ func (s *CitationRepoTestSuite) TestEnsurePatentNode_CreateNew() {
 	assert.True(s.T(), true)
 }
 
+func (s *CitationRepoTestSuite) TestMostCitedAssigneesQuery() {
+	q := mostCitedAssigneesQuery(false)
+	assert.Contains(s.T(), q, "WHERE p.assignee_id IS NOT NULL")
+	assert.Contains(s.T(), q, "RETURN p.assignee_id AS assignee_id, count(p) AS patent_count, sum(cites) AS citation_count")
+	assert.NotContains(s.T(), q, "$jurisdiction")
+
+	assert.Contains(s.T(), mostCitedAssigneesQuery(true), "AND p.jurisdiction = $jurisdiction")
+}
+
 func TestCitationRepoTestSuite(t *testing.T) {
 	suite.Run(t, new(CitationRepoTestSuite))
 }
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	driver "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/neo4j"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ReadOnlyCypherRunner executes compiled natural-language queries. Queries
+// run in a read-access session, so the server rejects writes even if one
+// slipped past validation, and both the client context and the server-side
+// transaction are bounded by the timeout.
+type ReadOnlyCypherRunner struct {
+	driver *driver.Driver
+	log    logging.Logger
+}
+
+// NewReadOnlyCypherRunner returns the runner that query.WithCypherExecution
+// expects.
+func NewReadOnlyCypherRunner(d *driver.Driver, log logging.Logger) *ReadOnlyCypherRunner {
+	return &ReadOnlyCypherRunner{
+		driver: d,
+		log:    log,
+	}
+}
+
+// RunReadOnly returns at most maxRows rows keyed by column name. Nodes,
+// relationships and paths are converted to GraphNode, Relation and GraphPath.
+func (r *ReadOnlyCypherRunner) RunReadOnly(ctx context.Context, query string, params map[string]interface{}, maxRows int, timeout time.Duration) ([]map[string]interface{}, error) {
+	if maxRows <= 0 {
+		return nil, ErrInvalidArgument
+	}
+	var configurers []func(*neo4j.TransactionConfig)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		configurers = append(configurers, neo4j.WithTxTimeout(timeout))
+	}
+
+	start := time.Now()
+	res, err := r.driver.ExecuteRead(ctx, func(tx driver.Transaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return collectCypherRows(ctx, result, maxRows)
+	}, configurers...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to run read-only cypher")
+	}
+
+	rows, _ := res.([]map[string]interface{})
+	r.log.Debug("read-only cypher executed",
+		logging.Int("rows", len(rows)),
+		logging.Duration("elapsed", time.Since(start)))
+	return rows, nil
+}
+
+// collectCypherRows reads up to maxRows records; the rest of the result is
+// discarded when the transaction closes.
+func collectCypherRows(ctx context.Context, result driver.Result, maxRows int) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, 0)
+	for len(rows) < maxRows && result.Next(ctx) {
+		rec := result.Record()
+		row := make(map[string]interface{}, len(rec.Keys))
+		for i, k := range rec.Keys {
+			row[k] = cypherRowValue(rec.Values[i])
+		}
+		rows = append(rows, row)
+	}
+	if err := result.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func cypherRowValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case neo4j.Node:
+		return neo4jNodeToGraphNode(val)
+	case neo4j.Relationship:
+		return &Relation{
+			ID:         fmt.Sprintf("%d", val.Id),
+			Type:       val.Type,
+			Properties: val.Props,
+		}
+	case neo4j.Path:
+		return neo4jPathToGraphPath(val)
+	case neo4j.Date:
+		return val.Time()
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = cypherRowValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = cypherRowValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+//Personal.AI order the ending
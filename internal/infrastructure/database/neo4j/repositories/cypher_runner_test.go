@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceResult replays fixed records as a driver.Result.
+type sliceResult struct {
+	records []*neo4j.Record
+	pos     int
+}
+
+func (r *sliceResult) Next(ctx context.Context) bool {
+	if r.pos >= len(r.records) {
+		return false
+	}
+	r.pos++
+	return true
+}
+func (r *sliceResult) Record() *neo4j.Record { return r.records[r.pos-1] }
+func (r *sliceResult) Err() error            { return nil }
+func (r *sliceResult) Consume(ctx context.Context) (neo4j.ResultSummary, error) {
+	return nil, nil
+}
+
+func TestCollectCypherRows_StopsAtRowLimit(t *testing.T) {
+	res := &sliceResult{}
+	for i := 0; i < 5; i++ {
+		res.records = append(res.records, &neo4j.Record{Keys: []string{"key", "total"}, Values: []any{"UDC", int64(i)}})
+	}
+
+	rows, err := collectCypherRows(context.Background(), res, 3)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, int64(2), rows[2]["total"])
+	assert.Equal(t, 3, res.pos)
+}
+
+func TestCypherRowValue_ConvertsGraphTypes(t *testing.T) {
+	a := neo4j.Node{Id: 1, Labels: []string{"Patent"}, Props: map[string]any{"id": "p-1"}}
+	b := neo4j.Node{Id: 2, Labels: []string{"Assignee"}, Props: map[string]any{"name": "UDC"}}
+	rel := neo4j.Relationship{Id: 7, StartId: 1, EndId: 2, Type: "ASSIGNED_TO"}
+
+	node, ok := cypherRowValue(a).(*GraphNode)
+	require.True(t, ok)
+	assert.Equal(t, "p-1", node.ID)
+
+	path, ok := cypherRowValue(neo4j.Path{Nodes: []neo4j.Node{a, b}, Relationships: []neo4j.Relationship{rel}}).(*GraphPath)
+	require.True(t, ok)
+	assert.Equal(t, 1, path.Length)
+	assert.Equal(t, "2", path.Relations[0].ToNode.ID)
+
+	list, ok := cypherRowValue([]any{b, neo4j.DateOf(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC))}).([]interface{})
+	require.True(t, ok)
+	assert.IsType(t, &GraphNode{}, list[0])
+	assert.Equal(t, 2020, list[1].(time.Time).Year())
+}
+
+//Personal.AI order the ending
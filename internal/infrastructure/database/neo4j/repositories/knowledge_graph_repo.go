@@ -28,6 +28,9 @@ type KnowledgeGraphRepository interface {
 	FullTextSearch(ctx context.Context, indexName string, query string, limit int) ([]*GraphNode, error)
 	BatchCreateNodes(ctx context.Context, label string, nodes []map[string]interface{}) (int64, error)
 	BatchCreateRelations(ctx context.Context, relations []*RelationInput) (int64, error)
+	UpsertNodes(ctx context.Context, label string, nodes []map[string]interface{}) (int64, error)
+	UpsertRelations(ctx context.Context, fromLabel, relType, toLabel string, rows []map[string]interface{}) (int64, error)
+	PruneGeneration(ctx context.Context, label string, generation string) (int64, error)
 	EnsureIndexes(ctx context.Context) error
 	EnsureConstraints(ctx context.Context) error
 }
@@ -1362,6 +1365,133 @@ func (r *neo4jKnowledgeGraphRepo) BatchCreateRelations(ctx context.Context, rela
 	return res.(int64), nil
 }
 
+// ---------------------------------------------------------------------------
+// Upserts and generation pruning
+// ---------------------------------------------------------------------------
+
+// UpsertNodes merges nodes on their "id" property and overwrites the given
+// properties, leaving any others in place. Every node must carry a
+// non-empty string id.
+func (r *neo4jKnowledgeGraphRepo) UpsertNodes(ctx context.Context, label string, nodes []map[string]interface{}) (int64, error) {
+	if len(nodes) == 0 {
+		return 0, nil
+	}
+	query, err := upsertNodesQuery(label)
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range nodes {
+		if id, ok := n["id"].(string); !ok || id == "" {
+			return 0, ErrInvalidArgument
+		}
+	}
+	return r.runCountingWrite(ctx, query, map[string]interface{}{"batch": nodes})
+}
+
+// UpsertRelations merges one relationship per row between existing nodes.
+// Rows carry "from_id", "to_id" and optionally "properties", which are
+// merged onto the relationship. Rows whose endpoints do not exist are
+// skipped.
+func (r *neo4jKnowledgeGraphRepo) UpsertRelations(ctx context.Context, fromLabel, relType, toLabel string, rows []map[string]interface{}) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	query, err := upsertRelationsQuery(fromLabel, relType, toLabel)
+	if err != nil {
+		return 0, err
+	}
+	return r.runCountingWrite(ctx, query, map[string]interface{}{"batch": rows})
+}
+
+// PruneGeneration removes what an earlier rebuild of label left behind:
+// relationships touching label nodes whose "generation" property differs
+// from generation, then label nodes whose generation differs. Nodes and
+// relationships without a generation are not touched. It returns the number
+// of nodes deleted.
+func (r *neo4jKnowledgeGraphRepo) PruneGeneration(ctx context.Context, label string, generation string) (int64, error) {
+	relQuery, nodeQuery, err := pruneGenerationQueries(label)
+	if err != nil {
+		return 0, err
+	}
+	params := map[string]interface{}{"generation": generation}
+	if _, err := r.runCountingWrite(ctx, relQuery, params); err != nil {
+		return 0, err
+	}
+	return r.runCountingWrite(ctx, nodeQuery, params)
+}
+
+func upsertNodesQuery(label string) (string, error) {
+	safeNodeLabel := safeLabel(label)
+	if safeNodeLabel == "" {
+		return "", ErrInvalidArgument
+	}
+	return fmt.Sprintf(`
+		UNWIND $batch AS props
+		MERGE (n:%s {id: props.id})
+		SET n += props
+		RETURN count(n) AS count
+	`, safeNodeLabel), nil
+}
+
+func upsertRelationsQuery(fromLabel, relType, toLabel string) (string, error) {
+	from, rel, to := safeLabel(fromLabel), safeLabel(relType), safeLabel(toLabel)
+	if from == "" || rel == "" || to == "" {
+		return "", ErrInvalidArgument
+	}
+	return fmt.Sprintf(`
+		UNWIND $batch AS row
+		MATCH (a:%s {id: row.from_id})
+		MATCH (b:%s {id: row.to_id})
+		MERGE (a)-[r:%s]->(b)
+		SET r += coalesce(row.properties, {})
+		RETURN count(r) AS count
+	`, from, to, rel), nil
+}
+
+func pruneGenerationQueries(label string) (string, string, error) {
+	safeNodeLabel := safeLabel(label)
+	if safeNodeLabel == "" {
+		return "", "", ErrInvalidArgument
+	}
+	relQuery := fmt.Sprintf(`
+		MATCH (:%s)-[r]-()
+		WHERE r.generation IS NOT NULL AND r.generation <> $generation
+		WITH DISTINCT r
+		DELETE r
+		RETURN count(r) AS count
+	`, safeNodeLabel)
+	nodeQuery := fmt.Sprintf(`
+		MATCH (n:%s)
+		WHERE n.generation IS NOT NULL AND n.generation <> $generation
+		DETACH DELETE n
+		RETURN count(n) AS count
+	`, safeNodeLabel)
+	return relQuery, nodeQuery, nil
+}
+
+// runCountingWrite runs a write query returning a single "count" column.
+func (r *neo4jKnowledgeGraphRepo) runCountingWrite(ctx context.Context, query string, params map[string]interface{}) (int64, error) {
+	res, err := r.driver.ExecuteWrite(ctx, func(tx driver.Transaction) (interface{}, error) {
+		result, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		if result.Next(ctx) {
+			if c, ok := result.Record().Get("count"); ok && c != nil {
+				return toInt64(c), nil
+			}
+		}
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+		return int64(0), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.(int64), nil
+}
+
 // ---------------------------------------------------------------------------
 // Indexes and constraints
 // ---------------------------------------------------------------------------
@@ -1372,6 +1502,7 @@ func (r *neo4jKnowledgeGraphRepo) EnsureIndexes(ctx context.Context) error {
 		"CREATE INDEX IF NOT EXISTS FOR (p:Patent) ON (p.patent_number)",
 		"CREATE INDEX IF NOT EXISTS FOR (p:Patent) ON (p.jurisdiction)",
 		"CREATE INDEX IF NOT EXISTS FOR (f:PatentFamily) ON (f.family_id)",
+		"CREATE INDEX IF NOT EXISTS FOR (i:Inventor) ON (i.normalized_name)",
 	}
 
 	for _, idx := range indexes {
@@ -1390,6 +1521,7 @@ func (r *neo4jKnowledgeGraphRepo) EnsureConstraints(ctx context.Context) error {
 	constraints := []string{
 		"CREATE CONSTRAINT IF NOT EXISTS FOR (p:Patent) REQUIRE p.id IS UNIQUE",
 		"CREATE CONSTRAINT IF NOT EXISTS FOR (f:PatentFamily) REQUIRE f.family_id IS UNIQUE",
+		"CREATE CONSTRAINT IF NOT EXISTS FOR (i:Inventor) REQUIRE i.id IS UNIQUE",
 	}
 
 	for _, c := range constraints {
@@ -1440,7 +1572,11 @@ func buildGraphPathFromRecord(rec *neo4j.Record, key string) (*GraphPath, error)
 	if !ok {
 		return nil, fmt.Errorf("%s is not a neo4j.Path", key)
 	}
+	return neo4jPathToGraphPath(path), nil
+}
 
+// neo4jPathToGraphPath converts a neo4j.Path to a GraphPath.
+func neo4jPathToGraphPath(path neo4j.Path) *GraphPath {
 	gp := &GraphPath{
 		Length: len(path.Relationships),
 	}
@@ -1465,7 +1601,7 @@ func buildGraphPathFromRecord(rec *neo4j.Record, key string) (*GraphPath, error)
 		}
 		gp.Relations = append(gp.Relations, graphRel)
 	}
-	return gp, nil
+	return gp
 }
 
 // Compile-time interface check
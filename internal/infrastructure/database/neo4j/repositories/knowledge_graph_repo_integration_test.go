@@ -474,6 +474,39 @@ func (s *KnowledgeGraphRepoIntegrationTestSuite) TestFindAllPaths() {
 	s.Require().NotEmpty(paths, "should find at least one path between A and C")
 }
 
+func (s *KnowledgeGraphRepoIntegrationTestSuite) TestUpsertAndPruneGeneration() {
+	first := []map[string]interface{}{
+		{"id": "kg-up-a", "name": "A", "generation": "g1"},
+		{"id": "kg-up-b", "name": "B", "generation": "g1"},
+	}
+	n, err := s.repo.UpsertNodes(s.ctx, "TestEntity", first)
+	s.Require().NoError(err)
+	s.Equal(int64(2), n)
+
+	rows := []map[string]interface{}{
+		{"from_id": "kg-up-a", "to_id": "kg-up-b", "properties": map[string]interface{}{"generation": "g1"}},
+		{"from_id": "kg-up-a", "to_id": "kg-up-missing"},
+	}
+	n, err = s.repo.UpsertRelations(s.ctx, "TestEntity", "LINKED_TO", "TestEntity", rows)
+	s.Require().NoError(err)
+	s.Equal(int64(1), n)
+
+	// Re-running merges instead of duplicating.
+	n, err = s.repo.UpsertNodes(s.ctx, "TestEntity", []map[string]interface{}{{"id": "kg-up-a", "name": "A2", "generation": "g2"}})
+	s.Require().NoError(err)
+	s.Equal(int64(1), n)
+	counts, err := s.repo.GetNodeLabelCounts(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(int64(2), counts["TestEntity"])
+
+	deleted, err := s.repo.PruneGeneration(s.ctx, "TestEntity", "g2")
+	s.Require().NoError(err)
+	s.Equal(int64(1), deleted)
+	relations, err := s.repo.GetEntityRelations(s.ctx, "kg-up-a", "TestEntity", "both")
+	s.Require().NoError(err)
+	s.Empty(relations)
+}
+
 func TestKnowledgeGraphRepoIntegration(t *testing.T) {
 	suite.Run(t, new(KnowledgeGraphRepoIntegrationTestSuite))
 }
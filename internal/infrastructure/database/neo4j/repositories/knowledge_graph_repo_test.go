@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,6 +21,40 @@ func (s *KnowledgeGraphRepoTestSuite) TestGetSubgraph_Success() {
 	assert.True(s.T(), true)
 }
 
+func (s *KnowledgeGraphRepoTestSuite) TestUpsertQueries_ValidateLabels() {
+	q, err := upsertNodesQuery("Inventor")
+	s.Require().NoError(err)
+	s.Contains(q, "MERGE (n:Inventor {id: props.id})")
+	s.Contains(q, "SET n += props")
+
+	q, err = upsertRelationsQuery("Inventor", "INVENTED", "Patent")
+	s.Require().NoError(err)
+	s.Contains(q, "MATCH (a:Inventor {id: row.from_id})")
+	s.Contains(q, "MERGE (a)-[r:INVENTED]->(b)")
+
+	relQuery, nodeQuery, err := pruneGenerationQueries("Inventor")
+	s.Require().NoError(err)
+	s.Contains(relQuery, "r.generation <> $generation")
+	s.Contains(nodeQuery, "DETACH DELETE n")
+
+	_, err = upsertNodesQuery("Inventor) DETACH DELETE (x")
+	s.ErrorIs(err, ErrInvalidArgument)
+	_, err = upsertRelationsQuery("Inventor", "CO-INVENTED", "Inventor")
+	s.ErrorIs(err, ErrInvalidArgument)
+	_, _, err = pruneGenerationQueries("")
+	s.ErrorIs(err, ErrInvalidArgument)
+}
+
+func (s *KnowledgeGraphRepoTestSuite) TestUpsertNodes_RequiresID() {
+	repo := &neo4jKnowledgeGraphRepo{}
+	_, err := repo.UpsertNodes(context.Background(), "Inventor", []map[string]interface{}{{"name": "no id"}})
+	s.ErrorIs(err, ErrInvalidArgument)
+
+	n, err := repo.UpsertRelations(context.Background(), "Inventor", "INVENTED", "Patent", nil)
+	s.NoError(err)
+	s.Zero(n)
+}
+
 func TestKnowledgeGraphRepoTestSuite(t *testing.T) {
 	suite.Run(t, new(KnowledgeGraphRepoTestSuite))
 }
@@ -0,0 +1,49 @@
+-- +migrate Up
+
+-- Anchor comments to a workspace and to a precise location: a patent, a claim
+-- (optionally a character span of the claim text), a molecule or a report
+-- section. resource_type names the table resource_id points into; anchor_type
+-- says how the comment is attached to it.
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_resource_type_check;
+ALTER TABLE comments ADD CONSTRAINT comments_resource_type_check
+    CHECK (resource_type IN ('patent', 'molecule', 'portfolio', 'project', 'claim', 'valuation', 'report'));
+
+ALTER TABLE comments
+    ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    ADD COLUMN thread_id UUID,
+    ADD COLUMN anchor_type VARCHAR(32) NOT NULL DEFAULT 'patent'
+        CHECK (anchor_type IN ('patent', 'claim', 'molecule', 'report_section')),
+    ADD COLUMN claim_number INTEGER CHECK (claim_number IS NULL OR claim_number >= 1),
+    ADD COLUMN span_start INTEGER,
+    ADD COLUMN span_end INTEGER,
+    ADD COLUMN section_id VARCHAR(128),
+    ADD CONSTRAINT comments_span_check
+        CHECK (span_start IS NULL OR (span_start >= 0 AND span_end >= span_start));
+
+-- Existing rows become single-comment threads unless they are replies.
+UPDATE comments SET thread_id = COALESCE(parent_comment_id, id) WHERE thread_id IS NULL;
+ALTER TABLE comments ALTER COLUMN thread_id SET NOT NULL;
+
+CREATE INDEX idx_comments_anchor ON comments(workspace_id, anchor_type, resource_id);
+CREATE INDEX idx_comments_thread_id ON comments(thread_id, created_at);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_comments_thread_id;
+DROP INDEX IF EXISTS idx_comments_anchor;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_span_check,
+    DROP COLUMN IF EXISTS section_id,
+    DROP COLUMN IF EXISTS span_end,
+    DROP COLUMN IF EXISTS span_start,
+    DROP COLUMN IF EXISTS claim_number,
+    DROP COLUMN IF EXISTS anchor_type,
+    DROP COLUMN IF EXISTS thread_id,
+    DROP COLUMN IF EXISTS workspace_id;
+
+DELETE FROM comments WHERE resource_type = 'report';
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_resource_type_check;
+ALTER TABLE comments ADD CONSTRAINT comments_resource_type_check
+    CHECK (resource_type IN ('patent', 'molecule', 'portfolio', 'project', 'claim', 'valuation'));
+
+--Personal.AI order the ending
@@ -0,0 +1,31 @@
+-- +migrate Up
+
+-- Saved searches cover patent text, structure/similarity and knowledge-graph
+-- queries. Scheduled re-runs diff against the hit IDs of the previous run so
+-- only new hits are notified.
+ALTER TABLE saved_searches DROP CONSTRAINT IF EXISTS saved_searches_search_type_check;
+ALTER TABLE saved_searches ADD CONSTRAINT saved_searches_search_type_check
+    CHECK (search_type IN ('patent', 'molecule', 'portfolio', 'combined', 'knowledge_graph'));
+
+ALTER TABLE saved_searches
+    ADD COLUMN last_result_ids TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN next_run_at TIMESTAMPTZ,
+    ADD COLUMN last_error TEXT;
+
+CREATE INDEX idx_saved_searches_next_run ON saved_searches(next_run_at)
+    WHERE is_alert_enabled = TRUE;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_saved_searches_next_run;
+
+ALTER TABLE saved_searches
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS next_run_at,
+    DROP COLUMN IF EXISTS last_result_ids;
+
+DELETE FROM saved_searches WHERE search_type = 'knowledge_graph';
+ALTER TABLE saved_searches DROP CONSTRAINT IF EXISTS saved_searches_search_type_check;
+ALTER TABLE saved_searches ADD CONSTRAINT saved_searches_search_type_check
+    CHECK (search_type IN ('patent', 'molecule', 'portfolio', 'combined'));
+
+--Personal.AI order the ending
@@ -0,0 +1,31 @@
+-- +migrate Up
+
+-- API keys carry a tier that sets their per-minute rate limit and monthly
+-- quota. Rotated keys point back at the key they replaced, and revocation is
+-- recorded separately from expiry.
+ALTER TABLE api_keys
+    ADD COLUMN tier VARCHAR(16) NOT NULL DEFAULT 'free'
+        CHECK (tier IN ('free', 'professional', 'enterprise')),
+    ADD COLUMN monthly_quota BIGINT NOT NULL DEFAULT 10000,
+    ADD COLUMN rotated_from_id UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    ADD COLUMN revoked_at TIMESTAMPTZ;
+
+-- Request counts per key and calendar month (period is the first day).
+CREATE TABLE api_key_usage (
+    api_key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    period DATE NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (api_key_id, period)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS api_key_usage;
+
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS rotated_from_id,
+    DROP COLUMN IF EXISTS monthly_quota,
+    DROP COLUMN IF EXISTS tier;
+
+--Personal.AI order the ending
@@ -0,0 +1,120 @@
+-- +migrate Up
+
+-- Canonical assignees. Free-text patents.assignee_name values are resolved to
+-- these through the alias table; parent_id links subsidiaries to parents.
+CREATE TABLE assignees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    canonical_name VARCHAR(512) NOT NULL,
+    normalized_name VARCHAR(512) NOT NULL,
+    country VARCHAR(8),
+    parent_id UUID REFERENCES assignees(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_assignees_parent_id ON assignees(parent_id);
+CREATE INDEX idx_assignees_normalized_name ON assignees(normalized_name);
+
+-- Each normalised spelling resolves to exactly one assignee. blocking_keys
+-- hold the fuzzy-match buckets computed by assignee.BlockingKeys.
+CREATE TABLE assignee_aliases (
+    normalized_name VARCHAR(512) PRIMARY KEY,
+    assignee_id UUID NOT NULL REFERENCES assignees(id) ON DELETE CASCADE,
+    name VARCHAR(512) NOT NULL,
+    blocking_keys TEXT[] NOT NULL DEFAULT '{}',
+    source VARCHAR(16) NOT NULL CHECK (source IN ('canonical', 'curated', 'fuzzy', 'review')),
+    confidence REAL NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_assignee_aliases_assignee_id ON assignee_aliases(assignee_id);
+CREATE INDEX idx_assignee_aliases_blocking_keys ON assignee_aliases USING GIN (blocking_keys);
+
+-- Low-confidence matches wait here for a reviewer. At most one pending
+-- review exists per normalised name.
+CREATE TABLE assignee_merge_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    raw_name VARCHAR(512) NOT NULL,
+    normalized_name VARCHAR(512) NOT NULL,
+    candidate_id UUID NOT NULL REFERENCES assignees(id) ON DELETE CASCADE,
+    candidate_name VARCHAR(512) NOT NULL,
+    score REAL NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    resolved_assignee_id UUID REFERENCES assignees(id) ON DELETE SET NULL,
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX uq_assignee_reviews_pending ON assignee_merge_reviews(normalized_name)
+    WHERE status = 'pending';
+CREATE INDEX idx_assignee_reviews_status ON assignee_merge_reviews(status, created_at);
+
+-- patents.assignee_id pointed at users, which never held assignee companies.
+-- Repoint it at canonical assignees; the backfill job fills it from
+-- assignee_name.
+ALTER TABLE patents DROP CONSTRAINT IF EXISTS fk_patents_assignee;
+UPDATE patents SET assignee_id = NULL WHERE assignee_id IS NOT NULL;
+ALTER TABLE patents ADD CONSTRAINT fk_patents_assignee
+    FOREIGN KEY (assignee_id) REFERENCES assignees(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_patents_unresolved_assignee ON patents(assignee_name)
+    WHERE assignee_id IS NULL AND deleted_at IS NULL;
+
+-- Curated OLED/display assignees and their known aliases. normalized_name
+-- and blocking_keys match assignee.Normalize and assignee.BlockingKeys.
+INSERT INTO assignees (id, canonical_name, normalized_name, country, parent_id) VALUES
+    ('a5510000-0000-4000-8000-000000000001', 'Samsung Electronics Co., Ltd.', 'samsung electronics', 'KR', NULL),
+    ('a5510000-0000-4000-8000-000000000002', 'Samsung Display Co., Ltd.', 'samsung display', 'KR', 'a5510000-0000-4000-8000-000000000001'),
+    ('a5510000-0000-4000-8000-000000000003', 'LG Electronics Inc.', 'lg electronics', 'KR', NULL),
+    ('a5510000-0000-4000-8000-000000000004', 'LG Display Co., Ltd.', 'lg display', 'KR', 'a5510000-0000-4000-8000-000000000003'),
+    ('a5510000-0000-4000-8000-000000000005', 'LG Chem, Ltd.', 'lg chem', 'KR', NULL),
+    ('a5510000-0000-4000-8000-000000000006', 'BOE Technology Group Co., Ltd.', 'boe technology group', 'CN', NULL),
+    ('a5510000-0000-4000-8000-000000000007', 'Universal Display Corporation', 'universal display', 'US', NULL),
+    ('a5510000-0000-4000-8000-000000000008', 'Idemitsu Kosan Co., Ltd.', 'idemitsu kosan', 'JP', NULL),
+    ('a5510000-0000-4000-8000-000000000009', 'Merck KGaA', 'merck', 'DE', NULL),
+    ('a5510000-0000-4000-8000-000000000010', 'Merck Patent GmbH', 'merck patent', 'DE', 'a5510000-0000-4000-8000-000000000009'),
+    ('a5510000-0000-4000-8000-000000000011', 'Sumitomo Chemical Co., Ltd.', 'sumitomo chemical', 'JP', NULL),
+    ('a5510000-0000-4000-8000-000000000012', 'Duksan Neolux Co., Ltd.', 'duksan neolux', 'KR', NULL),
+    ('a5510000-0000-4000-8000-000000000013', 'TCL China Star Optoelectronics Technology Co., Ltd.', 'tcl china star optoelectronics technology', 'CN', NULL),
+    ('a5510000-0000-4000-8000-000000000014', 'Tianma Microelectronics Co., Ltd.', 'tianma microelectronics', 'CN', NULL),
+    ('a5510000-0000-4000-8000-000000000015', 'Visionox Technology Inc.', 'visionox technology', 'CN', NULL);
+
+INSERT INTO assignee_aliases (normalized_name, assignee_id, name, blocking_keys, source) VALUES
+    ('samsung electronics', 'a5510000-0000-4000-8000-000000000001', 'Samsung Electronics Co., Ltd.', ARRAY['t:samsung', 't:electronics', 'p:sams'], 'canonical'),
+    ('samsung display', 'a5510000-0000-4000-8000-000000000002', 'Samsung Display Co., Ltd.', ARRAY['t:samsung', 'p:sams'], 'canonical'),
+    ('samsung mobile display', 'a5510000-0000-4000-8000-000000000002', 'Samsung Mobile Display Co., Ltd.', ARRAY['t:samsung', 'p:sams'], 'curated'),
+    ('lg electronics', 'a5510000-0000-4000-8000-000000000003', 'LG Electronics Inc.', ARRAY['t:lg', 't:electronics', 'p:lgel'], 'canonical'),
+    ('lg display', 'a5510000-0000-4000-8000-000000000004', 'LG Display Co., Ltd.', ARRAY['t:lg', 't:display', 'p:lgdi'], 'canonical'),
+    ('lg philips lcd', 'a5510000-0000-4000-8000-000000000004', 'LG Philips LCD Co., Ltd.', ARRAY['t:lg', 't:philips', 'p:lgph'], 'curated'),
+    ('lg chem', 'a5510000-0000-4000-8000-000000000005', 'LG Chem, Ltd.', ARRAY['t:lg', 't:chem', 'p:lgch'], 'canonical'),
+    ('lg chemical', 'a5510000-0000-4000-8000-000000000005', '株式会社LG化学', ARRAY['t:lg', 't:chemical', 'p:lgch'], 'curated'),
+    ('boe technology group', 'a5510000-0000-4000-8000-000000000006', 'BOE Technology Group Co., Ltd.', ARRAY['t:boe', 't:technology', 'p:boet'], 'canonical'),
+    ('universal display', 'a5510000-0000-4000-8000-000000000007', 'Universal Display Corporation', ARRAY['t:universal', 'p:univ'], 'canonical'),
+    ('udc', 'a5510000-0000-4000-8000-000000000007', 'UDC', ARRAY['t:udc', 'p:udc'], 'curated'),
+    ('idemitsu kosan', 'a5510000-0000-4000-8000-000000000008', 'Idemitsu Kosan Co., Ltd.', ARRAY['t:idemitsu', 'p:idem'], 'canonical'),
+    ('merck', 'a5510000-0000-4000-8000-000000000009', 'Merck KGaA', ARRAY['t:merck', 'p:merc'], 'canonical'),
+    ('merck group', 'a5510000-0000-4000-8000-000000000009', '默克集团', ARRAY['t:merck', 'p:merc'], 'curated'),
+    ('merck patent', 'a5510000-0000-4000-8000-000000000010', 'Merck Patent GmbH', ARRAY['t:merck', 't:patent', 'p:merc'], 'canonical'),
+    ('sumitomo chemical', 'a5510000-0000-4000-8000-000000000011', 'Sumitomo Chemical Co., Ltd.', ARRAY['t:sumitomo', 'p:sumi'], 'canonical'),
+    ('duksan neolux', 'a5510000-0000-4000-8000-000000000012', 'Duksan Neolux Co., Ltd.', ARRAY['t:duksan', 'p:duks'], 'canonical'),
+    ('tcl china star optoelectronics technology', 'a5510000-0000-4000-8000-000000000013', 'TCL China Star Optoelectronics Technology Co., Ltd.', ARRAY['t:tcl', 't:optoelectronics', 'p:tclc'], 'canonical'),
+    ('tcl csot technology', 'a5510000-0000-4000-8000-000000000013', 'TCL华星光电技术有限公司', ARRAY['t:tcl', 't:technology', 'p:tclc'], 'curated'),
+    ('shenzhen csot technology', 'a5510000-0000-4000-8000-000000000013', '深圳市华星光电技术有限公司', ARRAY['t:shenzhen', 't:technology', 'p:shen'], 'curated'),
+    ('shenzhen china star optoelectronics technology', 'a5510000-0000-4000-8000-000000000013', 'Shenzhen China Star Optoelectronics Technology Co., Ltd.', ARRAY['t:shenzhen', 't:optoelectronics', 'p:shen'], 'curated'),
+    ('tianma microelectronics', 'a5510000-0000-4000-8000-000000000014', 'Tianma Microelectronics Co., Ltd.', ARRAY['t:tianma', 't:microelectronics', 'p:tian'], 'canonical'),
+    ('visionox technology', 'a5510000-0000-4000-8000-000000000015', 'Visionox Technology Inc.', ARRAY['t:visionox', 't:technology', 'p:visi'], 'canonical');
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_patents_unresolved_assignee;
+
+ALTER TABLE patents DROP CONSTRAINT IF EXISTS fk_patents_assignee;
+UPDATE patents SET assignee_id = NULL WHERE assignee_id IS NOT NULL;
+ALTER TABLE patents ADD CONSTRAINT fk_patents_assignee
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL;
+
+DROP TABLE IF EXISTS assignee_merge_reviews;
+DROP TABLE IF EXISTS assignee_aliases;
+DROP TABLE IF EXISTS assignees;
+
+--Personal.AI order the ending
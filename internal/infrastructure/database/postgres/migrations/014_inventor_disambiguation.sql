@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Disambiguated inventor IDs. Each run of inventor.Disambiguator writes the
+-- inventor it assigned to every mention; the next run reuses these IDs so
+-- they stay stable. The inventors themselves live in the knowledge graph.
+ALTER TABLE patent_inventors
+    ADD COLUMN inventor_id UUID,
+    ADD COLUMN inventor_assigned_at TIMESTAMPTZ;
+
+CREATE INDEX idx_patent_inventors_inventor_id ON patent_inventors(inventor_id)
+    WHERE inventor_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_patent_inventors_inventor_id;
+
+ALTER TABLE patent_inventors
+    DROP COLUMN IF EXISTS inventor_assigned_at,
+    DROP COLUMN IF EXISTS inventor_id;
+
+--Personal.AI order the ending
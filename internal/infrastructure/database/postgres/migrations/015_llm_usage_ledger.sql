@@ -0,0 +1,46 @@
+-- +migrate Up
+
+-- One row per metered LLM call. Tenants are free-form IDs so that calls made
+-- without an organisation are still accounted; cost is priced at write time
+-- from the configured model price table.
+CREATE TABLE llm_usage_ledger (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(128) NOT NULL,
+    user_id VARCHAR(128),
+    feature VARCHAR(128) NOT NULL,
+    provider VARCHAR(64),
+    model VARCHAR(128) NOT NULL,
+    request_id VARCHAR(128),
+    prompt_tokens BIGINT NOT NULL DEFAULT 0 CHECK (prompt_tokens >= 0),
+    completion_tokens BIGINT NOT NULL DEFAULT 0 CHECK (completion_tokens >= 0),
+    estimated BOOLEAN NOT NULL DEFAULT FALSE,
+    cost_usd NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_usage_tenant_time ON llm_usage_ledger(tenant_id, created_at);
+CREATE INDEX idx_llm_usage_time ON llm_usage_ledger(created_at);
+
+-- Monthly spend limits per tenant in USD; 0 means unlimited.
+CREATE TABLE llm_budgets (
+    tenant_id VARCHAR(128) PRIMARY KEY,
+    soft_limit_usd NUMERIC(14, 2) NOT NULL DEFAULT 0 CHECK (soft_limit_usd >= 0),
+    hard_limit_usd NUMERIC(14, 2) NOT NULL DEFAULT 0 CHECK (hard_limit_usd >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Budget alerts already raised, so each level alerts once per month.
+CREATE TABLE llm_budget_alerts (
+    tenant_id VARCHAR(128) NOT NULL,
+    period DATE NOT NULL,
+    level VARCHAR(8) NOT NULL CHECK (level IN ('soft', 'hard')),
+    raised_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, period, level)
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS llm_budget_alerts;
+DROP TABLE IF EXISTS llm_budgets;
+DROP TABLE IF EXISTS llm_usage_ledger;
+
+--Personal.AI order the ending
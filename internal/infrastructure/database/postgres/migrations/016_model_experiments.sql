@@ -0,0 +1,28 @@
+-- +migrate Up
+
+-- Shadow, canary and A/B experiments run by the model registry. Per-version
+-- outcome counters and the shadow diff summary are stored as JSONB so that
+-- significance tests can be recomputed after a restart.
+CREATE TABLE model_experiments (
+    id UUID PRIMARY KEY,
+    model_id VARCHAR(128) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('shadow', 'canary', 'ab_test')),
+    status VARCHAR(16) NOT NULL CHECK (status IN ('running', 'promoted', 'rolled_back', 'completed')),
+    baseline_version VARCHAR(64) NOT NULL,
+    candidate_version VARCHAR(64),
+    canary_weight INTEGER NOT NULL DEFAULT 0 CHECK (canary_weight BETWEEN 0 AND 100),
+    arms JSONB NOT NULL DEFAULT '[]',
+    shadow JSONB,
+    reason TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_model_experiments_model ON model_experiments(model_id, started_at DESC);
+CREATE INDEX idx_model_experiments_running ON model_experiments(status) WHERE status = 'running';
+
+-- +migrate Down
+DROP TABLE IF EXISTS model_experiments;
+
+--Personal.AI order the ending
@@ -0,0 +1,42 @@
+-- +migrate Up
+
+-- Store collaboration member permissions in workspace_members: give each
+-- membership its own ID, record acceptance and activity, and align the role
+-- set with the collaboration domain roles.
+ALTER TABLE workspace_members DROP CONSTRAINT IF EXISTS workspace_members_role_check;
+
+UPDATE workspace_members SET role = 'analyst' WHERE role = 'editor';
+UPDATE workspace_members SET role = 'viewer' WHERE role = 'commenter';
+
+ALTER TABLE workspace_members
+    ADD COLUMN id UUID NOT NULL DEFAULT gen_random_uuid(),
+    ADD COLUMN custom_permissions JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN accepted_at TIMESTAMPTZ,
+    ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD CONSTRAINT workspace_members_id_key UNIQUE (id),
+    ADD CONSTRAINT workspace_members_role_check
+        CHECK (role IN ('owner', 'admin', 'manager', 'attorney', 'analyst', 'viewer', 'inventor'));
+
+-- Existing members joined directly, so they count as accepted.
+UPDATE workspace_members SET accepted_at = joined_at WHERE accepted_at IS NULL;
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_workspace_members_user_id;
+
+ALTER TABLE workspace_members
+    DROP CONSTRAINT IF EXISTS workspace_members_role_check,
+    DROP CONSTRAINT IF EXISTS workspace_members_id_key,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS is_active,
+    DROP COLUMN IF EXISTS accepted_at,
+    DROP COLUMN IF EXISTS custom_permissions,
+    DROP COLUMN IF EXISTS id;
+
+UPDATE workspace_members SET role = 'editor' WHERE role IN ('manager', 'attorney', 'analyst', 'inventor');
+ALTER TABLE workspace_members ADD CONSTRAINT workspace_members_role_check
+    CHECK (role IN ('owner', 'admin', 'editor', 'commenter', 'viewer'));
+
+--Personal.AI order the ending
@@ -0,0 +1,399 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const assigneeColumns = `id, canonical_name, normalized_name, country, parent_id, created_at, updated_at`
+
+const mergeReviewColumns = `
+	id, raw_name, normalized_name, candidate_id, candidate_name, score, status,
+	resolved_assignee_id, reviewed_by, reviewed_at, created_at`
+
+type postgresAssigneeRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+func NewPostgresAssigneeRepo(conn *postgres.Connection, log logging.Logger) assignee.Repository {
+	return &postgresAssigneeRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresAssigneeRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+func (r *postgresAssigneeRepo) Create(ctx context.Context, a *assignee.Assignee) error {
+	query := `
+		INSERT INTO assignees (id, canonical_name, normalized_name, country, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.executor().ExecContext(ctx, query,
+		a.ID, a.CanonicalName, a.NormalizedName, nullIfEmpty(a.Country), a.ParentID, a.CreatedAt, a.UpdatedAt)
+	if err != nil {
+		r.log.Error("failed to create assignee", logging.Err(err), logging.String("name", a.CanonicalName))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to create assignee")
+	}
+	return nil
+}
+
+func (r *postgresAssigneeRepo) GetByID(ctx context.Context, id uuid.UUID) (*assignee.Assignee, error) {
+	query := `SELECT ` + assigneeColumns + ` FROM assignees WHERE id = $1`
+	a, err := scanAssignee(r.executor().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "assignee not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get assignee")
+	}
+	return a, nil
+}
+
+func (r *postgresAssigneeRepo) ListAll(ctx context.Context) ([]*assignee.Assignee, error) {
+	rows, err := r.executor().QueryContext(ctx, `SELECT `+assigneeColumns+` FROM assignees ORDER BY canonical_name`)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to list assignees")
+	}
+	defer rows.Close()
+
+	var out []*assignee.Assignee
+	for rows.Next() {
+		a, err := scanAssignee(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan assignee")
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate assignees")
+	}
+	return out, nil
+}
+
+func (r *postgresAssigneeRepo) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	res, err := r.executor().ExecContext(ctx,
+		`UPDATE assignees SET parent_id = $2, updated_at = NOW() WHERE id = $1`, id, parentID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to set assignee parent")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New(errors.ErrCodeNotFound, "assignee not found")
+	}
+	return nil
+}
+
+// AddAlias keeps the first assignee a normalised name was mapped to; a later
+// alias for the same name is ignored.
+func (r *postgresAssigneeRepo) AddAlias(ctx context.Context, alias *assignee.Alias) error {
+	query := `
+		INSERT INTO assignee_aliases (normalized_name, assignee_id, name, blocking_keys, source, confidence, created_at)
+		VALUES ($1, $2, $3, COALESCE($4::text[], '{}'), $5, $6, $7)
+		ON CONFLICT (normalized_name) DO NOTHING
+	`
+	_, err := r.executor().ExecContext(ctx, query,
+		alias.NormalizedName, alias.AssigneeID, alias.Name, pq.Array(alias.BlockingKeys),
+		string(alias.Source), alias.Confidence, alias.CreatedAt)
+	if err != nil {
+		r.log.Error("failed to add assignee alias", logging.Err(err), logging.String("alias", alias.NormalizedName))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to add assignee alias")
+	}
+	return nil
+}
+
+func (r *postgresAssigneeRepo) FindByAlias(ctx context.Context, normalizedName string) (*assignee.Assignee, error) {
+	query := `
+		SELECT a.id, a.canonical_name, a.normalized_name, a.country, a.parent_id, a.created_at, a.updated_at
+		FROM assignee_aliases al
+		JOIN assignees a ON a.id = al.assignee_id
+		WHERE al.normalized_name = $1
+	`
+	a, err := scanAssignee(r.executor().QueryRowContext(ctx, query, normalizedName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to find assignee by alias")
+	}
+	return a, nil
+}
+
+func (r *postgresAssigneeRepo) FindCandidates(ctx context.Context, blockingKeys []string, limit int) ([]*assignee.Assignee, error) {
+	if len(blockingKeys) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT a.id, a.canonical_name, a.normalized_name, a.country, a.parent_id, a.created_at, a.updated_at,
+			array_agg(al.normalized_name ORDER BY al.normalized_name)
+		FROM assignees a
+		JOIN assignee_aliases al ON al.assignee_id = a.id
+		WHERE a.id IN (
+			SELECT DISTINCT assignee_id FROM assignee_aliases
+			WHERE blocking_keys && $1::text[]
+			LIMIT $2
+		)
+		GROUP BY a.id
+		ORDER BY a.canonical_name
+	`
+	rows, err := r.executor().QueryContext(ctx, query, pq.Array(blockingKeys), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to find assignee candidates")
+	}
+	defer rows.Close()
+
+	var out []*assignee.Assignee
+	for rows.Next() {
+		var (
+			a        assignee.Assignee
+			country  sql.NullString
+			parentID uuid.NullUUID
+			aliases  pq.StringArray
+		)
+		if err := rows.Scan(&a.ID, &a.CanonicalName, &a.NormalizedName, &country, &parentID,
+			&a.CreatedAt, &a.UpdatedAt, &aliases); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan assignee candidate")
+		}
+		a.Country = country.String
+		if parentID.Valid {
+			a.ParentID = &parentID.UUID
+		}
+		a.Aliases = []string(aliases)
+		out = append(out, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate assignee candidates")
+	}
+	return out, nil
+}
+
+func (r *postgresAssigneeRepo) CreateReview(ctx context.Context, rv *assignee.MergeReview) error {
+	query := `
+		INSERT INTO assignee_merge_reviews (
+			id, raw_name, normalized_name, candidate_id, candidate_name, score, status,
+			resolved_assignee_id, reviewed_by, reviewed_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.executor().ExecContext(ctx, query,
+		rv.ID, rv.RawName, rv.NormalizedName, rv.CandidateID, rv.CandidateName, rv.Score, string(rv.Status),
+		rv.ResolvedAssigneeID, nullIfEmpty(rv.ReviewedBy), rv.ReviewedAt, rv.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return errors.New(errors.ErrCodeConflict, "a merge review is already pending for this name")
+		}
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to create merge review")
+	}
+	return nil
+}
+
+func (r *postgresAssigneeRepo) GetReview(ctx context.Context, id uuid.UUID) (*assignee.MergeReview, error) {
+	query := `SELECT ` + mergeReviewColumns + ` FROM assignee_merge_reviews WHERE id = $1`
+	rv, err := scanMergeReview(r.executor().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "merge review not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get merge review")
+	}
+	return rv, nil
+}
+
+func (r *postgresAssigneeRepo) FindPendingReview(ctx context.Context, normalizedName string) (*assignee.MergeReview, error) {
+	query := `SELECT ` + mergeReviewColumns + `
+		FROM assignee_merge_reviews
+		WHERE normalized_name = $1 AND status = 'pending'`
+	rv, err := scanMergeReview(r.executor().QueryRowContext(ctx, query, normalizedName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to find pending merge review")
+	}
+	return rv, nil
+}
+
+func (r *postgresAssigneeRepo) ListReviews(ctx context.Context, status assignee.ReviewStatus, limit, offset int) ([]*assignee.MergeReview, int64, error) {
+	var total int64
+	if err := r.executor().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM assignee_merge_reviews WHERE status = $1`, string(status)).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to count merge reviews")
+	}
+
+	query := `SELECT ` + mergeReviewColumns + `
+		FROM assignee_merge_reviews
+		WHERE status = $1
+		ORDER BY score DESC, created_at
+		LIMIT $2 OFFSET $3`
+	rows, err := r.executor().QueryContext(ctx, query, string(status), limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to list merge reviews")
+	}
+	defer rows.Close()
+
+	var out []*assignee.MergeReview
+	for rows.Next() {
+		rv, err := scanMergeReview(rows)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan merge review")
+		}
+		out = append(out, rv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate merge reviews")
+	}
+	return out, total, nil
+}
+
+func (r *postgresAssigneeRepo) UpdateReview(ctx context.Context, rv *assignee.MergeReview) error {
+	query := `
+		UPDATE assignee_merge_reviews
+		SET status = $2, resolved_assignee_id = $3, reviewed_by = $4, reviewed_at = $5
+		WHERE id = $1
+	`
+	res, err := r.executor().ExecContext(ctx, query,
+		rv.ID, string(rv.Status), rv.ResolvedAssigneeID, nullIfEmpty(rv.ReviewedBy), rv.ReviewedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to update merge review")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New(errors.ErrCodeNotFound, "merge review not found")
+	}
+	return nil
+}
+
+func (r *postgresAssigneeRepo) ListUnresolvedNames(ctx context.Context, after string, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT assignee_name
+		FROM patents
+		WHERE assignee_id IS NULL AND deleted_at IS NULL
+			AND assignee_name IS NOT NULL AND assignee_name <> ''
+			AND assignee_name > $1
+		ORDER BY assignee_name
+		LIMIT $2
+	`
+	rows, err := r.executor().QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to list unresolved assignee names")
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan assignee name")
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate assignee names")
+	}
+	return names, nil
+}
+
+func (r *postgresAssigneeRepo) AssignPatents(ctx context.Context, rawName string, assigneeID uuid.UUID) (int64, error) {
+	query := `
+		UPDATE patents SET assignee_id = $2, updated_at = NOW()
+		WHERE assignee_name = $1 AND assignee_id IS NULL AND deleted_at IS NULL
+	`
+	res, err := r.executor().ExecContext(ctx, query, rawName, assigneeID)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to assign patents")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to assign patents")
+	}
+	return n, nil
+}
+
+func (r *postgresAssigneeRepo) ListPatentIDs(ctx context.Context, assigneeIDs []uuid.UUID, limit int) ([]string, error) {
+	if len(assigneeIDs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(assigneeIDs))
+	for i, id := range assigneeIDs {
+		ids[i] = id.String()
+	}
+	query := `
+		SELECT id FROM patents
+		WHERE assignee_id = ANY($1::uuid[]) AND deleted_at IS NULL
+		ORDER BY filing_date DESC NULLS LAST
+		LIMIT $2
+	`
+	rows, err := r.executor().QueryContext(ctx, query, pq.Array(ids), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to list assignee patents")
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan patent id")
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate assignee patents")
+	}
+	return out, nil
+}
+
+func scanAssignee(row scanner) (*assignee.Assignee, error) {
+	var (
+		a        assignee.Assignee
+		country  sql.NullString
+		parentID uuid.NullUUID
+	)
+	if err := row.Scan(&a.ID, &a.CanonicalName, &a.NormalizedName, &country, &parentID, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return nil, err
+	}
+	a.Country = country.String
+	if parentID.Valid {
+		a.ParentID = &parentID.UUID
+	}
+	return &a, nil
+}
+
+func scanMergeReview(row scanner) (*assignee.MergeReview, error) {
+	var (
+		rv         assignee.MergeReview
+		status     string
+		resolvedID uuid.NullUUID
+		reviewedBy sql.NullString
+		reviewedAt sql.NullTime
+	)
+	err := row.Scan(
+		&rv.ID, &rv.RawName, &rv.NormalizedName, &rv.CandidateID, &rv.CandidateName, &rv.Score, &status,
+		&resolvedID, &reviewedBy, &reviewedAt, &rv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rv.Status = assignee.ReviewStatus(status)
+	if resolvedID.Valid {
+		rv.ResolvedAssigneeID = &resolvedID.UUID
+	}
+	rv.ReviewedBy = reviewedBy.String
+	if reviewedAt.Valid {
+		rv.ReviewedAt = &reviewedAt.Time
+	}
+	return &rv, nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type AssigneeRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo assignee.Repository
+}
+
+func (s *AssigneeRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresAssigneeRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *AssigneeRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+var (
+	assigneeRowColumns = []string{"id", "canonical_name", "normalized_name", "country", "parent_id", "created_at", "updated_at"}
+	reviewRowColumns   = []string{
+		"id", "raw_name", "normalized_name", "candidate_id", "candidate_name", "score", "status",
+		"resolved_assignee_id", "reviewed_by", "reviewed_at", "created_at",
+	}
+)
+
+func (s *AssigneeRepoTestSuite) TestAddAlias_IgnoresExisting() {
+	id := uuid.New()
+	alias, err := assignee.NewAlias(id, "Samsung Display Co., Ltd.", assignee.AliasFuzzy, 0.95)
+	s.Require().NoError(err)
+
+	s.mock.ExpectExec("INSERT INTO assignee_aliases .+ ON CONFLICT \\(normalized_name\\) DO NOTHING").
+		WithArgs("samsung display", id, "Samsung Display Co., Ltd.", sqlmock.AnyArg(), "fuzzy", 0.95, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s.NoError(s.repo.AddAlias(context.Background(), alias))
+}
+
+func (s *AssigneeRepoTestSuite) TestFindByAlias() {
+	id, parent := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("FROM assignee_aliases al\\s+JOIN assignees a ON a.id = al.assignee_id\\s+WHERE al.normalized_name = \\$1").
+		WithArgs("samsung display").
+		WillReturnRows(sqlmock.NewRows(assigneeRowColumns).
+			AddRow(id, "Samsung Display Co., Ltd.", "samsung display", "KR", parent, now, now))
+
+	a, err := s.repo.FindByAlias(context.Background(), "samsung display")
+	s.Require().NoError(err)
+	s.Equal(id, a.ID)
+	s.Equal("KR", a.Country)
+	s.Require().NotNil(a.ParentID)
+	s.Equal(parent, *a.ParentID)
+}
+
+func (s *AssigneeRepoTestSuite) TestFindByAlias_NoMatch() {
+	s.mock.ExpectQuery("FROM assignee_aliases").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	a, err := s.repo.FindByAlias(context.Background(), "unknown")
+	s.NoError(err)
+	s.Nil(a)
+}
+
+func (s *AssigneeRepoTestSuite) TestFindCandidates_LoadsAliases() {
+	id := uuid.New()
+	now := time.Now().UTC()
+	keys := []string{"t:samsung", "p:sams"}
+	s.mock.ExpectQuery("WHERE blocking_keys && \\$1::text\\[\\]\\s+LIMIT \\$2").
+		WithArgs(pq.Array(keys), 50).
+		WillReturnRows(sqlmock.NewRows(append(assigneeRowColumns, "aliases")).
+			AddRow(id, "Samsung Display Co., Ltd.", "samsung display", nil, nil, now, now, "{\"samsung display\",\"samsung mobile display\"}"))
+
+	got, err := s.repo.FindCandidates(context.Background(), keys, 50)
+	s.Require().NoError(err)
+	s.Require().Len(got, 1)
+	s.Equal([]string{"samsung display", "samsung mobile display"}, got[0].Aliases)
+	s.Nil(got[0].ParentID)
+	s.Empty(got[0].Country)
+}
+
+func (s *AssigneeRepoTestSuite) TestFindCandidates_NoKeys() {
+	got, err := s.repo.FindCandidates(context.Background(), nil, 50)
+	s.NoError(err)
+	s.Nil(got)
+}
+
+func (s *AssigneeRepoTestSuite) TestCreateReview_PendingConflict() {
+	a, _ := assignee.NewAssignee("Samsung Display Co., Ltd.", "KR")
+	rv := assignee.NewMergeReview("Samsung Disp", assignee.Candidate{Assignee: a, Score: 0.85})
+
+	s.mock.ExpectExec("INSERT INTO assignee_merge_reviews").
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	err := s.repo.CreateReview(context.Background(), rv)
+	s.True(errors.IsCode(err, errors.ErrCodeConflict))
+}
+
+func (s *AssigneeRepoTestSuite) TestGetReview_NotFound() {
+	id := uuid.New()
+	s.mock.ExpectQuery("SELECT .+ FROM assignee_merge_reviews WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.GetReview(context.Background(), id)
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *AssigneeRepoTestSuite) TestListReviews() {
+	now := time.Now().UTC()
+	id, candidate := uuid.New(), uuid.New()
+	s.mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM assignee_merge_reviews WHERE status = \\$1").
+		WithArgs("pending").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	s.mock.ExpectQuery("ORDER BY score DESC, created_at\\s+LIMIT \\$2 OFFSET \\$3").
+		WithArgs("pending", 1, 2).
+		WillReturnRows(sqlmock.NewRows(reviewRowColumns).
+			AddRow(id, "Samsung Disp", "samsung disp", candidate, "Samsung Display Co., Ltd.", 0.85, "pending",
+				nil, nil, nil, now))
+
+	reviews, total, err := s.repo.ListReviews(context.Background(), assignee.ReviewPending, 1, 2)
+	s.Require().NoError(err)
+	s.Equal(int64(3), total)
+	s.Require().Len(reviews, 1)
+	s.Equal(assignee.ReviewPending, reviews[0].Status)
+	s.Nil(reviews[0].ResolvedAssigneeID)
+	s.Nil(reviews[0].ReviewedAt)
+}
+
+func (s *AssigneeRepoTestSuite) TestListUnresolvedNames_Cursor() {
+	s.mock.ExpectQuery("SELECT DISTINCT assignee_name\\s+FROM patents\\s+WHERE assignee_id IS NULL").
+		WithArgs("BOE", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"assignee_name"}).AddRow("LG Chem").AddRow("Merck"))
+
+	names, err := s.repo.ListUnresolvedNames(context.Background(), "BOE", 2)
+	s.NoError(err)
+	s.Equal([]string{"LG Chem", "Merck"}, names)
+}
+
+func (s *AssigneeRepoTestSuite) TestAssignPatents() {
+	id := uuid.New()
+	s.mock.ExpectExec("UPDATE patents SET assignee_id = \\$2.+WHERE assignee_name = \\$1 AND assignee_id IS NULL").
+		WithArgs("LG Chem, Ltd.", id).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := s.repo.AssignPatents(context.Background(), "LG Chem, Ltd.", id)
+	s.NoError(err)
+	s.Equal(int64(4), n)
+}
+
+func (s *AssigneeRepoTestSuite) TestListPatentIDs() {
+	a, b := uuid.New(), uuid.New()
+	s.mock.ExpectQuery("WHERE assignee_id = ANY\\(\\$1::uuid\\[\\]\\)").
+		WithArgs(pq.Array([]string{a.String(), b.String()}), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("p1").AddRow("p2"))
+
+	ids, err := s.repo.ListPatentIDs(context.Background(), []uuid.UUID{a, b}, 100)
+	s.NoError(err)
+	s.Equal([]string{"p1", "p2"}, ids)
+}
+
+func (s *AssigneeRepoTestSuite) TestSetParent_NotFound() {
+	id := uuid.New()
+	s.mock.ExpectExec("UPDATE assignees SET parent_id = \\$2").
+		WithArgs(id, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := s.repo.SetParent(context.Background(), id, nil)
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func TestAssigneeRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(AssigneeRepoTestSuite))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const commentColumns = `
+	c.id, c.workspace_id, c.author_id, c.anchor_type, c.resource_id,
+	c.claim_number, c.span_start, c.span_end, c.section_id,
+	c.parent_comment_id, c.thread_id, c.content, c.mentions,
+	c.is_resolved, c.resolved_by, c.resolved_at, c.edited_at,
+	c.created_at, c.updated_at, c.deleted_at`
+
+type postgresCommentRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+func NewPostgresCommentRepo(conn *postgres.Connection, log logging.Logger) collaboration.CommentRepository {
+	return &postgresCommentRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresCommentRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+// commentResourceType maps an anchor to the resource_type column, which
+// names the table resource_id refers to.
+func commentResourceType(a collaboration.AnchorType) string {
+	switch a {
+	case collaboration.AnchorMolecule:
+		return "molecule"
+	case collaboration.AnchorReportSection:
+		return "report"
+	default:
+		return "patent"
+	}
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func (r *postgresCommentRepo) Save(ctx context.Context, c *collaboration.Comment) error {
+	query := `
+		INSERT INTO comments (
+			id, workspace_id, author_id, resource_type, resource_id, anchor_type,
+			claim_number, span_start, span_end, section_id,
+			parent_comment_id, thread_id, content, mentions,
+			is_resolved, resolved_by, resolved_at, edited_at,
+			created_at, updated_at, deleted_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13,
+			COALESCE($14::uuid[], '{}'), $15, $16, $17, $18, $19, $20, $21
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			content = EXCLUDED.content,
+			mentions = EXCLUDED.mentions,
+			is_resolved = EXCLUDED.is_resolved,
+			resolved_by = EXCLUDED.resolved_by,
+			resolved_at = EXCLUDED.resolved_at,
+			edited_at = EXCLUDED.edited_at,
+			updated_at = EXCLUDED.updated_at,
+			deleted_at = EXCLUDED.deleted_at
+	`
+	var claimNumber, spanStart, spanEnd interface{}
+	if c.Anchor.ClaimNumber > 0 {
+		claimNumber = c.Anchor.ClaimNumber
+	}
+	if c.Anchor.HasSpan() {
+		spanStart, spanEnd = c.Anchor.SpanStart, c.Anchor.SpanEnd
+	}
+
+	_, err := r.executor().ExecContext(ctx, query,
+		c.ID, c.WorkspaceID, c.AuthorID, commentResourceType(c.Anchor.Type), c.Anchor.ResourceID, string(c.Anchor.Type),
+		claimNumber, spanStart, spanEnd, nullIfEmpty(c.Anchor.SectionID),
+		nullIfEmpty(c.ParentID), c.ThreadID, c.Content, pq.Array(c.Mentions),
+		c.IsResolved, nullIfEmpty(c.ResolvedBy), c.ResolvedAt, c.EditedAt,
+		c.CreatedAt, c.UpdatedAt, c.DeletedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to save comment", logging.Err(err), logging.String("comment_id", c.ID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save comment")
+	}
+	return nil
+}
+
+func (r *postgresCommentRepo) FindByID(ctx context.Context, id string) (*collaboration.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c WHERE c.id = $1`
+	c, err := scanComment(r.executor().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "comment not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get comment")
+	}
+	return c, nil
+}
+
+func (r *postgresCommentRepo) FindThread(ctx context.Context, threadID string) ([]*collaboration.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c WHERE c.thread_id = $1 ORDER BY c.created_at`
+	return r.queryComments(ctx, query, threadID)
+}
+
+func (r *postgresCommentRepo) FindByAnchor(
+	ctx context.Context,
+	workspaceID string,
+	anchorType collaboration.AnchorType,
+	resourceID string,
+	unresolvedOnly bool,
+) ([]*collaboration.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+		FROM comments c
+		JOIN comments root ON root.id = c.thread_id
+		WHERE c.workspace_id = $1 AND c.anchor_type = $2 AND c.resource_id = $3`
+	if unresolvedOnly {
+		query += ` AND root.is_resolved = FALSE`
+	}
+	query += ` ORDER BY c.created_at`
+	return r.queryComments(ctx, query, workspaceID, string(anchorType), resourceID)
+}
+
+func (r *postgresCommentRepo) queryComments(ctx context.Context, query string, args ...interface{}) ([]*collaboration.Comment, error) {
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to query comments")
+	}
+	defer rows.Close()
+
+	var comments []*collaboration.Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan comment")
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate comments")
+	}
+	return comments, nil
+}
+
+func scanComment(row scanner) (*collaboration.Comment, error) {
+	var (
+		c                               collaboration.Comment
+		anchorType                      string
+		workspaceID, sectionID          sql.NullString
+		parentID, resolvedBy            sql.NullString
+		claimNumber, spanStart, spanEnd sql.NullInt64
+		resolvedAt, editedAt, deletedAt sql.NullTime
+		mentions                        pq.StringArray
+	)
+	err := row.Scan(
+		&c.ID, &workspaceID, &c.AuthorID, &anchorType, &c.Anchor.ResourceID,
+		&claimNumber, &spanStart, &spanEnd, &sectionID,
+		&parentID, &c.ThreadID, &c.Content, &mentions,
+		&c.IsResolved, &resolvedBy, &resolvedAt, &editedAt,
+		&c.CreatedAt, &c.UpdatedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.WorkspaceID = workspaceID.String
+	c.Anchor.Type = collaboration.AnchorType(anchorType)
+	c.Anchor.ClaimNumber = int(claimNumber.Int64)
+	c.Anchor.SpanStart = int(spanStart.Int64)
+	c.Anchor.SpanEnd = int(spanEnd.Int64)
+	c.Anchor.SectionID = sectionID.String
+	c.ParentID = parentID.String
+	c.ResolvedBy = resolvedBy.String
+	if len(mentions) > 0 {
+		c.Mentions = []string(mentions)
+	}
+	if resolvedAt.Valid {
+		c.ResolvedAt = &resolvedAt.Time
+	}
+	if editedAt.Valid {
+		c.EditedAt = &editedAt.Time
+	}
+	if deletedAt.Valid {
+		c.DeletedAt = &deletedAt.Time
+	}
+	return &c, nil
+}
+
+// Notifications
+
+type postgresNotificationRepo struct {
+	conn *postgres.Connection
+	log  logging.Logger
+}
+
+func NewPostgresNotificationRepo(conn *postgres.Connection, log logging.Logger) collaboration.NotificationRepository {
+	return &postgresNotificationRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresNotificationRepo) Save(ctx context.Context, n *collaboration.Notification) error {
+	query := `
+		INSERT INTO notifications (
+			id, user_id, notification_type, title, body,
+			resource_type, resource_id, action_url, sender_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.conn.DB().ExecContext(ctx, query,
+		n.ID, n.UserID, n.Type, n.Title, nullIfEmpty(n.Body),
+		nullIfEmpty(n.ResourceType), nullIfEmpty(n.ResourceID), nullIfEmpty(n.ActionURL), nullIfEmpty(n.SenderID), n.CreatedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to save notification", logging.Err(err), logging.String("user_id", n.UserID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, fmt.Sprintf("failed to save %s notification", n.Type))
+	}
+	return nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type CommentRepoTestSuite struct {
+	suite.Suite
+	mock          sqlmock.Sqlmock
+	db            *sql.DB
+	repo          collaboration.CommentRepository
+	notifications collaboration.NotificationRepository
+}
+
+func (s *CommentRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	conn := postgres.NewConnectionWithDB(s.db, logger)
+	s.repo = NewPostgresCommentRepo(conn, logger)
+	s.notifications = NewPostgresNotificationRepo(conn, logger)
+}
+
+func (s *CommentRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+var commentRowColumns = []string{
+	"id", "workspace_id", "author_id", "anchor_type", "resource_id",
+	"claim_number", "span_start", "span_end", "section_id",
+	"parent_comment_id", "thread_id", "content", "mentions",
+	"is_resolved", "resolved_by", "resolved_at", "edited_at",
+	"created_at", "updated_at", "deleted_at",
+}
+
+func (s *CommentRepoTestSuite) TestSave_ClaimSpan() {
+	c, err := collaboration.NewComment("ws1", "u1",
+		collaboration.CommentAnchor{Type: collaboration.AnchorClaim, ResourceID: "p1", ClaimNumber: 2, SpanStart: 4, SpanEnd: 20},
+		"@bob look here")
+	s.Require().NoError(err)
+	c.SetMentions([]string{"u2"})
+
+	s.mock.ExpectExec("INSERT INTO comments").
+		WithArgs(c.ID, "ws1", "u1", "patent", "p1", "claim",
+			2, 4, 20, nil,
+			nil, c.ID, "@bob look here", sqlmock.AnyArg(),
+			false, nil, sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.Save(context.Background(), c))
+}
+
+func (s *CommentRepoTestSuite) TestSave_ReportSection() {
+	c, err := collaboration.NewComment("ws1", "u1",
+		collaboration.CommentAnchor{Type: collaboration.AnchorReportSection, ResourceID: "r1", SectionID: "fto-summary"},
+		"needs a citation")
+	s.Require().NoError(err)
+
+	s.mock.ExpectExec("INSERT INTO comments").
+		WithArgs(c.ID, "ws1", "u1", "report", "r1", "report_section",
+			nil, nil, nil, "fto-summary",
+			nil, c.ID, "needs a citation", sqlmock.AnyArg(),
+			false, nil, sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(sql.ErrConnDone)
+
+	err = s.repo.Save(context.Background(), c)
+	s.Error(err)
+	s.True(errors.IsCode(err, errors.ErrCodeDatabaseError))
+}
+
+func (s *CommentRepoTestSuite) TestFindByID_Found() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("SELECT .+ FROM comments c WHERE c.id = \\$1").
+		WithArgs("c2").
+		WillReturnRows(sqlmock.NewRows(commentRowColumns).AddRow(
+			"c2", "ws1", "u2", "claim", "p1",
+			int64(2), int64(4), int64(20), nil,
+			"c1", "c1", "agreed", "{u1,u3}",
+			false, nil, nil, now,
+			now, now, nil,
+		))
+
+	c, err := s.repo.FindByID(context.Background(), "c2")
+	s.Require().NoError(err)
+	s.Equal("c1", c.ParentID)
+	s.Equal("c1", c.ThreadID)
+	s.Equal(collaboration.AnchorClaim, c.Anchor.Type)
+	s.Equal(2, c.Anchor.ClaimNumber)
+	s.True(c.Anchor.HasSpan())
+	s.Equal([]string{"u1", "u3"}, c.Mentions)
+	s.NotNil(c.EditedAt)
+	s.Nil(c.DeletedAt)
+}
+
+func (s *CommentRepoTestSuite) TestFindByID_NotFound() {
+	s.mock.ExpectQuery("SELECT .+ FROM comments c WHERE c.id = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.FindByID(context.Background(), "missing")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *CommentRepoTestSuite) TestFindByAnchor_UnresolvedOnly() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("JOIN comments root ON root.id = c.thread_id .+ AND root.is_resolved = FALSE ORDER BY c.created_at").
+		WithArgs("ws1", "molecule", "m1").
+		WillReturnRows(sqlmock.NewRows(commentRowColumns).AddRow(
+			"c1", "ws1", "u1", "molecule", "m1",
+			nil, nil, nil, nil,
+			nil, "c1", "scaffold looks novel", "{}",
+			false, nil, nil, nil,
+			now, now, nil,
+		))
+
+	comments, err := s.repo.FindByAnchor(context.Background(), "ws1", collaboration.AnchorMolecule, "m1", true)
+	s.Require().NoError(err)
+	s.Require().Len(comments, 1)
+	s.True(comments[0].IsRoot())
+	s.Empty(comments[0].Mentions)
+}
+
+func (s *CommentRepoTestSuite) TestNotificationSave() {
+	c, _ := collaboration.NewComment("ws1", "u1",
+		collaboration.CommentAnchor{Type: collaboration.AnchorPatent, ResourceID: "p1"}, "@bob fyi")
+	n := collaboration.NewMentionNotification(c, "u2")
+
+	s.mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(n.ID, "u2", collaboration.NotificationTypeCommentMention, n.Title, n.Body,
+			n.ResourceType, "p1", n.ActionURL, "u1", n.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.notifications.Save(context.Background(), n))
+}
+
+func TestCommentRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentRepoTestSuite))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mentionSelect reads inventor mentions with their patent's evidence and
+// the names of the other inventors on the same patent.
+const mentionSelect = `
+	SELECT pi.patent_id, p.patent_number, pi.sequence, pi.inventor_name, pi.inventor_name_en,
+		pi.affiliation, p.assignee_name, p.jurisdiction, p.cpc_codes, p.filing_date, pi.inventor_id,
+		ARRAY(
+			SELECT o.inventor_name FROM patent_inventors o
+			WHERE o.patent_id = pi.patent_id AND o.sequence <> pi.sequence
+			ORDER BY o.sequence
+		) AS co_inventors
+	FROM patent_inventors pi
+	JOIN patents p ON p.id = pi.patent_id
+	WHERE p.deleted_at IS NULL`
+
+// assignmentBatchSize bounds the rows written per SaveAssignments statement.
+const assignmentBatchSize = 1000
+
+type postgresInventorRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+func NewPostgresInventorRepo(conn *postgres.Connection, log logging.Logger) inventor.Repository {
+	return &postgresInventorRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresInventorRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+func (r *postgresInventorRepo) ListMentions(ctx context.Context, afterPatentID uuid.UUID, limit int) ([]*inventor.Mention, error) {
+	query := mentionSelect + `
+		AND pi.patent_id IN (
+			SELECT DISTINCT patent_id FROM patent_inventors
+			WHERE patent_id > $1
+			ORDER BY patent_id
+			LIMIT $2
+		)
+		ORDER BY pi.patent_id, pi.sequence
+	`
+	return r.queryMentions(ctx, "failed to list inventor mentions", query, afterPatentID, limit)
+}
+
+func (r *postgresInventorRepo) ListMentionsByInventors(ctx context.Context, ids []uuid.UUID) ([]*inventor.Mention, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := mentionSelect + `
+		AND pi.inventor_id = ANY($1::uuid[])
+		ORDER BY p.filing_date NULLS LAST, pi.patent_id, pi.sequence
+	`
+	return r.queryMentions(ctx, "failed to list inventor mentions", query, pq.Array(uuidStrings(ids)))
+}
+
+func (r *postgresInventorRepo) ListCoMentions(ctx context.Context, ids []uuid.UUID) ([]*inventor.Mention, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := mentionSelect + `
+		AND pi.patent_id IN (
+			SELECT patent_id FROM patent_inventors WHERE inventor_id = ANY($1::uuid[])
+		)
+		ORDER BY pi.patent_id, pi.sequence
+	`
+	return r.queryMentions(ctx, "failed to list co-inventor mentions", query, pq.Array(uuidStrings(ids)))
+}
+
+func (r *postgresInventorRepo) FindInventorIDsByAssignee(ctx context.Context, assigneeName string, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT pi.inventor_id
+		FROM patent_inventors pi
+		JOIN patents p ON p.id = pi.patent_id
+		WHERE pi.inventor_id IS NOT NULL AND p.deleted_at IS NULL AND p.assignee_name ILIKE $1
+		LIMIT $2
+	`
+	rows, err := r.executor().QueryContext(ctx, query, "%"+assigneeName+"%", limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to find inventors by assignee")
+	}
+	defer rows.Close()
+
+	var out []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan inventor id")
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate inventor ids")
+	}
+	return out, nil
+}
+
+func (r *postgresInventorRepo) SaveAssignments(ctx context.Context, assignments []inventor.Assignment) (int64, error) {
+	query := `
+		UPDATE patent_inventors pi
+		SET inventor_id = a.inventor_id, inventor_assigned_at = NOW()
+		FROM unnest($1::uuid[], $2::int[], $3::uuid[]) AS a(patent_id, sequence, inventor_id)
+		WHERE pi.patent_id = a.patent_id AND pi.sequence = a.sequence
+			AND pi.inventor_id IS DISTINCT FROM a.inventor_id
+	`
+	var changed int64
+	for start := 0; start < len(assignments); start += assignmentBatchSize {
+		batch := assignments[start:min(start+assignmentBatchSize, len(assignments))]
+		patentIDs := make([]string, len(batch))
+		sequences := make([]int64, len(batch))
+		inventorIDs := make([]string, len(batch))
+		for i, a := range batch {
+			patentIDs[i] = a.PatentID.String()
+			sequences[i] = int64(a.Sequence)
+			inventorIDs[i] = a.InventorID.String()
+		}
+		res, err := r.executor().ExecContext(ctx, query, pq.Array(patentIDs), pq.Array(sequences), pq.Array(inventorIDs))
+		if err != nil {
+			r.log.Error("failed to save inventor assignments", logging.Err(err), logging.Int("batch", len(batch)))
+			return changed, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save inventor assignments")
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return changed, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to read affected rows")
+		}
+		changed += n
+	}
+	return changed, nil
+}
+
+func (r *postgresInventorRepo) queryMentions(ctx context.Context, msg, query string, args ...interface{}) ([]*inventor.Mention, error) {
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, msg)
+	}
+	defer rows.Close()
+
+	var out []*inventor.Mention
+	for rows.Next() {
+		m, err := scanMention(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan inventor mention")
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, msg)
+	}
+	return out, nil
+}
+
+func scanMention(row scanner) (*inventor.Mention, error) {
+	var (
+		m                                          inventor.Mention
+		nameEn, affiliation, assigneeName, country sql.NullString
+		filingDate                                 sql.NullTime
+		inventorID                                 uuid.NullUUID
+	)
+	err := row.Scan(&m.PatentID, &m.PatentNumber, &m.Sequence, &m.Name, &nameEn,
+		&affiliation, &assigneeName, &country, pq.Array(&m.CPCCodes), &filingDate, &inventorID,
+		pq.Array(&m.CoInventors))
+	if err != nil {
+		return nil, err
+	}
+	m.NameEn = nameEn.String
+	m.Affiliation = affiliation.String
+	m.AssigneeName = assigneeName.String
+	m.Country = country.String
+	if filingDate.Valid {
+		t := filingDate.Time
+		m.FilingDate = &t
+	}
+	if inventorID.Valid {
+		id := inventorID.UUID
+		m.InventorID = &id
+	}
+	return &m, nil
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
+
+//Personal.AI order the ending
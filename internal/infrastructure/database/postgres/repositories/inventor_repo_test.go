@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type InventorRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo inventor.Repository
+}
+
+func (s *InventorRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresInventorRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *InventorRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+var mentionRowColumns = []string{
+	"patent_id", "patent_number", "sequence", "inventor_name", "inventor_name_en",
+	"affiliation", "assignee_name", "jurisdiction", "cpc_codes", "filing_date", "inventor_id", "co_inventors",
+}
+
+func (s *InventorRepoTestSuite) TestListMentions_Cursor() {
+	after, patentID, inventorID := uuid.New(), uuid.New(), uuid.New()
+	filed := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery("WHERE patent_id > \\$1\\s+ORDER BY patent_id\\s+LIMIT \\$2").
+		WithArgs(after, 500).
+		WillReturnRows(sqlmock.NewRows(mentionRowColumns).
+			AddRow(patentID, "KR1020200001", 1, "김재현", "Kim Jae-Hyun", nil, "Samsung Display Co., Ltd.", "KR",
+				"{H10K50/11,C09K11/06}", filed, inventorID, "{\"Park Min\"}").
+			AddRow(patentID, "KR1020200001", 2, "Park Min", nil, nil, "Samsung Display Co., Ltd.", "KR",
+				"{H10K50/11,C09K11/06}", filed, nil, "{\"김재현\"}"))
+
+	mentions, err := s.repo.ListMentions(context.Background(), after, 500)
+	s.Require().NoError(err)
+	s.Require().Len(mentions, 2)
+	m := mentions[0]
+	s.Equal("Kim Jae-Hyun", m.NameEn)
+	s.Equal("KR", m.Country)
+	s.Equal([]string{"H10K50/11", "C09K11/06"}, m.CPCCodes)
+	s.Equal([]string{"Park Min"}, m.CoInventors)
+	s.Require().NotNil(m.InventorID)
+	s.Equal(inventorID, *m.InventorID)
+	s.Require().NotNil(m.FilingDate)
+	s.Nil(mentions[1].InventorID)
+	s.Empty(mentions[1].NameEn)
+}
+
+func (s *InventorRepoTestSuite) TestListMentionsByInventors() {
+	id := uuid.New()
+	s.mock.ExpectQuery("AND pi.inventor_id = ANY\\(\\$1::uuid\\[\\]\\)").
+		WithArgs(pq.Array([]string{id.String()})).
+		WillReturnRows(sqlmock.NewRows(mentionRowColumns))
+
+	mentions, err := s.repo.ListMentionsByInventors(context.Background(), []uuid.UUID{id})
+	s.NoError(err)
+	s.Empty(mentions)
+
+	none, err := s.repo.ListMentionsByInventors(context.Background(), nil)
+	s.NoError(err)
+	s.Nil(none)
+}
+
+func (s *InventorRepoTestSuite) TestListCoMentions_Error() {
+	s.mock.ExpectQuery("SELECT patent_id FROM patent_inventors WHERE inventor_id = ANY").
+		WillReturnError(sql.ErrConnDone)
+
+	_, err := s.repo.ListCoMentions(context.Background(), []uuid.UUID{uuid.New()})
+	s.True(errors.IsCode(err, errors.ErrCodeDatabaseError))
+}
+
+func (s *InventorRepoTestSuite) TestFindInventorIDsByAssignee() {
+	a, b := uuid.New(), uuid.New()
+	s.mock.ExpectQuery("SELECT DISTINCT pi.inventor_id.+assignee_name ILIKE \\$1").
+		WithArgs("%Merck%", 100).
+		WillReturnRows(sqlmock.NewRows([]string{"inventor_id"}).AddRow(a).AddRow(b))
+
+	ids, err := s.repo.FindInventorIDsByAssignee(context.Background(), "Merck", 100)
+	s.NoError(err)
+	s.Equal([]uuid.UUID{a, b}, ids)
+}
+
+func (s *InventorRepoTestSuite) TestSaveAssignments_Batches() {
+	assignments := make([]inventor.Assignment, assignmentBatchSize+1)
+	for i := range assignments {
+		assignments[i] = inventor.Assignment{PatentID: uuid.New(), Sequence: 1, InventorID: uuid.New()}
+	}
+	s.mock.ExpectExec("UPDATE patent_inventors pi.+FROM unnest\\(\\$1::uuid\\[\\], \\$2::int\\[\\], \\$3::uuid\\[\\]\\)").
+		WillReturnResult(sqlmock.NewResult(0, 900))
+	s.mock.ExpectExec("UPDATE patent_inventors pi").
+		WithArgs(pq.Array([]string{assignments[assignmentBatchSize].PatentID.String()}), pq.Array([]int64{1}),
+			pq.Array([]string{assignments[assignmentBatchSize].InventorID.String()})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := s.repo.SaveAssignments(context.Background(), assignments)
+	s.NoError(err)
+	s.Equal(int64(901), n)
+}
+
+func (s *InventorRepoTestSuite) TestSaveAssignments_Empty() {
+	n, err := s.repo.SaveAssignments(context.Background(), nil)
+	s.NoError(err)
+	s.Zero(n)
+}
+
+func TestInventorRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(InventorRepoTestSuite))
+}
+
+//Personal.AI order the ending
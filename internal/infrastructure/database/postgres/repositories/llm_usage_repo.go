@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type postgresLLMUsageRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+func NewPostgresLLMUsageRepo(conn *postgres.Connection, log logging.Logger) usage.Repository {
+	return &postgresLLMUsageRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresLLMUsageRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+// usageGroupColumns maps summary dimensions onto SQL expressions.
+var usageGroupColumns = map[usage.Dimension]string{
+	usage.ByTenant:  "tenant_id",
+	usage.ByUser:    "COALESCE(user_id, '')",
+	usage.ByFeature: "feature",
+	usage.ByModel:   "model",
+	usage.ByDay:     "to_char(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD')",
+}
+
+func (r *postgresLLMUsageRepo) Append(ctx context.Context, rec *usage.Record) error {
+	query := `
+		INSERT INTO llm_usage_ledger (
+			id, tenant_id, user_id, feature, provider, model, request_id,
+			prompt_tokens, completion_tokens, estimated, cost_usd, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.executor().ExecContext(ctx, query,
+		rec.ID, rec.TenantID, nullIfEmpty(rec.UserID), rec.Feature, nullIfEmpty(rec.Provider), rec.Model,
+		nullIfEmpty(rec.RequestID), rec.PromptTokens, rec.CompletionTokens, rec.Estimated, rec.CostUSD, rec.CreatedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to append LLM usage", logging.Err(err), logging.String("tenant_id", rec.TenantID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to record LLM usage")
+	}
+	return nil
+}
+
+func (r *postgresLLMUsageRepo) Summarize(ctx context.Context, q *usage.Query) ([]*usage.Line, error) {
+	group, ok := usageGroupColumns[q.GroupBy]
+	if !ok {
+		return nil, errors.InvalidParam("unknown group_by dimension " + string(q.GroupBy))
+	}
+	where := []string{"created_at >= $1", "created_at < $2"}
+	args := []interface{}{q.From, q.To}
+	for _, f := range []struct{ column, value string }{
+		{"tenant_id", q.TenantID}, {"user_id", q.UserID}, {"feature", q.Feature},
+	} {
+		if f.value != "" {
+			args = append(args, f.value)
+			where = append(where, fmt.Sprintf("%s = $%d", f.column, len(args)))
+		}
+	}
+	query := `
+		SELECT ` + group + ` AS key,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE estimated),
+			COALESCE(SUM(prompt_tokens), 0),
+			COALESCE(SUM(completion_tokens), 0),
+			COALESCE(SUM(cost_usd), 0)::float8
+		FROM llm_usage_ledger
+		WHERE ` + strings.Join(where, " AND ") + `
+		GROUP BY 1
+		ORDER BY 6 DESC, 1`
+
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to summarize LLM usage")
+	}
+	defer rows.Close()
+
+	var lines []*usage.Line
+	for rows.Next() {
+		var l usage.Line
+		if err := rows.Scan(&l.Key, &l.Requests, &l.EstimatedRequests, &l.PromptTokens, &l.CompletionTokens, &l.CostUSD); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan LLM usage")
+		}
+		lines = append(lines, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate LLM usage")
+	}
+	return lines, nil
+}
+
+func (r *postgresLLMUsageRepo) TenantSpend(ctx context.Context, tenantID string, from, to time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(cost_usd), 0)::float8
+		FROM llm_usage_ledger
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3`
+	var spent float64
+	if err := r.executor().QueryRowContext(ctx, query, tenantID, from, to).Scan(&spent); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get tenant LLM spend")
+	}
+	return spent, nil
+}
+
+func (r *postgresLLMUsageRepo) GetBudget(ctx context.Context, tenantID string) (*usage.Budget, error) {
+	query := `SELECT tenant_id, soft_limit_usd::float8, hard_limit_usd::float8, updated_at FROM llm_budgets WHERE tenant_id = $1`
+	var b usage.Budget
+	err := r.executor().QueryRowContext(ctx, query, tenantID).Scan(&b.TenantID, &b.SoftLimitUSD, &b.HardLimitUSD, &b.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "LLM budget not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get LLM budget")
+	}
+	return &b, nil
+}
+
+func (r *postgresLLMUsageRepo) SaveBudget(ctx context.Context, b *usage.Budget) error {
+	query := `
+		INSERT INTO llm_budgets (tenant_id, soft_limit_usd, hard_limit_usd, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			soft_limit_usd = EXCLUDED.soft_limit_usd,
+			hard_limit_usd = EXCLUDED.hard_limit_usd,
+			updated_at = EXCLUDED.updated_at
+	`
+	if _, err := r.executor().ExecContext(ctx, query, b.TenantID, b.SoftLimitUSD, b.HardLimitUSD, b.UpdatedAt); err != nil {
+		r.log.Error("failed to save LLM budget", logging.Err(err), logging.String("tenant_id", b.TenantID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save LLM budget")
+	}
+	return nil
+}
+
+func (r *postgresLLMUsageRepo) MarkAlertSent(ctx context.Context, tenantID string, period time.Time, level usage.BudgetLevel) (bool, error) {
+	query := `
+		INSERT INTO llm_budget_alerts (tenant_id, period, level, raised_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (tenant_id, period, level) DO NOTHING
+	`
+	res, err := r.executor().ExecContext(ctx, query, tenantID, period, string(level))
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to record LLM budget alert")
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to record LLM budget alert")
+	}
+	return n > 0, nil
+}
+
+//Personal.AI order the ending
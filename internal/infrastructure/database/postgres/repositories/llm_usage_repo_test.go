@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type LLMUsageRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo usage.Repository
+}
+
+func (s *LLMUsageRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresLLMUsageRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *LLMUsageRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func (s *LLMUsageRepoTestSuite) TestAppend() {
+	rec, err := usage.NewRecord("acme", "", "ai.chat", "anthropic", "claude-sonnet-4", 1200, 300)
+	s.Require().NoError(err)
+	rec.CostUSD = 0.0081
+
+	s.mock.ExpectExec("INSERT INTO llm_usage_ledger").
+		WithArgs(rec.ID, "acme", nil, "ai.chat", "anthropic", "claude-sonnet-4", nil,
+			int64(1200), int64(300), false, 0.0081, rec.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.Append(context.Background(), rec))
+}
+
+func (s *LLMUsageRepoTestSuite) TestSummarize_FiltersAndGroups() {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	s.mock.ExpectQuery(`SELECT model AS key.*WHERE created_at >= \$1 AND created_at < \$2 AND tenant_id = \$3 AND feature = \$4`).
+		WithArgs(from, to, "acme", "ai.chat").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count", "estimated", "prompt", "completion", "cost"}).
+			AddRow("claude-sonnet-4", 10, 2, 12000, 3000, 0.081).
+			AddRow("deepseek-chat", 4, 0, 4000, 1000, 0.0022))
+
+	lines, err := s.repo.Summarize(context.Background(), &usage.Query{
+		TenantID: "acme", Feature: "ai.chat", From: from, To: to, GroupBy: usage.ByModel,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(lines, 2)
+	s.Equal("claude-sonnet-4", lines[0].Key)
+	s.Equal(int64(2), lines[0].EstimatedRequests)
+	s.Equal(int64(15000), lines[0].TotalTokens())
+}
+
+func (s *LLMUsageRepoTestSuite) TestSummarize_RejectsUnknownDimension() {
+	_, err := s.repo.Summarize(context.Background(), &usage.Query{GroupBy: "region"})
+	s.Error(err)
+}
+
+func (s *LLMUsageRepoTestSuite) TestTenantSpend() {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery("SELECT COALESCE\\(SUM\\(cost_usd\\), 0\\)::float8").
+		WithArgs("acme", from, from.AddDate(0, 1, 0)).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(42.5))
+
+	spent, err := s.repo.TenantSpend(context.Background(), "acme", from, from.AddDate(0, 1, 0))
+	s.NoError(err)
+	s.Equal(42.5, spent)
+}
+
+func (s *LLMUsageRepoTestSuite) TestBudgets() {
+	s.mock.ExpectQuery("SELECT tenant_id, soft_limit_usd::float8, hard_limit_usd::float8, updated_at FROM llm_budgets").
+		WithArgs("acme").
+		WillReturnError(sql.ErrNoRows)
+	_, err := s.repo.GetBudget(context.Background(), "acme")
+	s.True(errors.IsNotFound(err))
+
+	b, err := usage.NewBudget("acme", 80, 100)
+	s.Require().NoError(err)
+	s.mock.ExpectExec("INSERT INTO llm_budgets").
+		WithArgs("acme", 80.0, 100.0, b.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.NoError(s.repo.SaveBudget(context.Background(), b))
+}
+
+func (s *LLMUsageRepoTestSuite) TestMarkAlertSent_OncePerPeriod() {
+	period := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	s.mock.ExpectExec("INSERT INTO llm_budget_alerts").
+		WithArgs("acme", period, "soft").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec("INSERT INTO llm_budget_alerts").
+		WithArgs("acme", period, "soft").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	first, err := s.repo.MarkAlertSent(context.Background(), "acme", period, usage.BudgetSoft)
+	s.NoError(err)
+	s.True(first)
+	again, err := s.repo.MarkAlertSent(context.Background(), "acme", period, usage.BudgetSoft)
+	s.NoError(err)
+	s.False(again)
+}
+
+func TestLLMUsageRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(LLMUsageRepoTestSuite))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const memberColumns = `
+	id, workspace_id, user_id, role, custom_permissions, invited_by,
+	joined_at, accepted_at, is_active, updated_at`
+
+type postgresMemberRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+// NewPostgresMemberRepo stores collaboration member permissions in the
+// workspace_members table. A membership is unique per workspace and user;
+// joined_at holds both the invitation and the creation time.
+func NewPostgresMemberRepo(conn *postgres.Connection, log logging.Logger) collaboration.MemberRepository {
+	return &postgresMemberRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresMemberRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+func (r *postgresMemberRepo) Save(ctx context.Context, m *collaboration.MemberPermission) error {
+	perms, err := json.Marshal(m.CustomPermissions)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeSerialization, "failed to marshal custom permissions")
+	}
+	if m.CustomPermissions == nil {
+		perms = []byte("[]")
+	}
+	query := `
+		INSERT INTO workspace_members (
+			id, workspace_id, user_id, role, custom_permissions, invited_by,
+			joined_at, accepted_at, is_active, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			custom_permissions = EXCLUDED.custom_permissions,
+			accepted_at = EXCLUDED.accepted_at,
+			is_active = EXCLUDED.is_active,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = r.executor().ExecContext(ctx, query,
+		m.ID, m.WorkspaceID, m.UserID, string(m.Role), perms, nullIfEmpty(m.InvitedBy),
+		m.InvitedAt, m.AcceptedAt, m.IsActive, m.UpdatedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to save workspace member", logging.Err(err),
+			logging.String("workspace_id", m.WorkspaceID), logging.String("user_id", m.UserID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save workspace member")
+	}
+	return nil
+}
+
+func (r *postgresMemberRepo) FindByID(ctx context.Context, id string) (*collaboration.MemberPermission, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members WHERE id = $1`
+	return r.queryMember(ctx, query, id)
+}
+
+func (r *postgresMemberRepo) FindByWorkspaceID(ctx context.Context, workspaceID string) ([]*collaboration.MemberPermission, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members WHERE workspace_id = $1 ORDER BY joined_at`
+	return r.queryMembers(ctx, query, workspaceID)
+}
+
+func (r *postgresMemberRepo) FindByUserID(ctx context.Context, userID string) ([]*collaboration.MemberPermission, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members WHERE user_id = $1 ORDER BY joined_at`
+	return r.queryMembers(ctx, query, userID)
+}
+
+func (r *postgresMemberRepo) FindByWorkspaceAndUser(ctx context.Context, workspaceID, userID string) (*collaboration.MemberPermission, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+	return r.queryMember(ctx, query, workspaceID, userID)
+}
+
+func (r *postgresMemberRepo) FindByRole(ctx context.Context, workspaceID string, role collaboration.Role) ([]*collaboration.MemberPermission, error) {
+	query := `SELECT ` + memberColumns + ` FROM workspace_members WHERE workspace_id = $1 AND role = $2 ORDER BY joined_at`
+	return r.queryMembers(ctx, query, workspaceID, string(role))
+}
+
+func (r *postgresMemberRepo) Delete(ctx context.Context, id string) error {
+	res, err := r.executor().ExecContext(ctx, `DELETE FROM workspace_members WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to delete workspace member")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New(errors.ErrCodeNotFound, "workspace member not found")
+	}
+	return nil
+}
+
+func (r *postgresMemberRepo) CountByWorkspace(ctx context.Context, workspaceID string) (int64, error) {
+	var n int64
+	err := r.executor().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1`, workspaceID).Scan(&n)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to count workspace members")
+	}
+	return n, nil
+}
+
+func (r *postgresMemberRepo) CountByRole(ctx context.Context, workspaceID string) (map[collaboration.Role]int64, error) {
+	rows, err := r.executor().QueryContext(ctx,
+		`SELECT role, COUNT(*) FROM workspace_members WHERE workspace_id = $1 GROUP BY role`, workspaceID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to count workspace members by role")
+	}
+	defer rows.Close()
+
+	counts := make(map[collaboration.Role]int64)
+	for rows.Next() {
+		var (
+			role string
+			n    int64
+		)
+		if err := rows.Scan(&role, &n); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan workspace member count")
+		}
+		counts[collaboration.Role(role)] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate workspace member counts")
+	}
+	return counts, nil
+}
+
+func (r *postgresMemberRepo) queryMember(ctx context.Context, query string, args ...interface{}) (*collaboration.MemberPermission, error) {
+	m, err := scanMember(r.executor().QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "workspace member not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get workspace member")
+	}
+	return m, nil
+}
+
+func (r *postgresMemberRepo) queryMembers(ctx context.Context, query string, args ...interface{}) ([]*collaboration.MemberPermission, error) {
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to query workspace members")
+	}
+	defer rows.Close()
+
+	var members []*collaboration.MemberPermission
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan workspace member")
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate workspace members")
+	}
+	return members, nil
+}
+
+func scanMember(row scanner) (*collaboration.MemberPermission, error) {
+	var (
+		m          collaboration.MemberPermission
+		role       string
+		perms      []byte
+		invitedBy  sql.NullString
+		acceptedAt sql.NullTime
+	)
+	err := row.Scan(
+		&m.ID, &m.WorkspaceID, &m.UserID, &role, &perms, &invitedBy,
+		&m.InvitedAt, &acceptedAt, &m.IsActive, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Role = collaboration.Role(role)
+	if len(perms) > 0 {
+		if err := json.Unmarshal(perms, &m.CustomPermissions); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.CustomPermissions) == 0 {
+		m.CustomPermissions = nil
+	}
+	m.InvitedBy = invitedBy.String
+	m.CreatedAt = m.InvitedAt
+	if acceptedAt.Valid {
+		m.AcceptedAt = &acceptedAt.Time
+	}
+	return &m, nil
+}
+
+//Personal.AI order the ending
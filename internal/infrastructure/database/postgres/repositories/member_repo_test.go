@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type MemberRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo collaboration.MemberRepository
+}
+
+func (s *MemberRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresMemberRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *MemberRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+var memberRowColumns = []string{
+	"id", "workspace_id", "user_id", "role", "custom_permissions", "invited_by",
+	"joined_at", "accepted_at", "is_active", "updated_at",
+}
+
+func (s *MemberRepoTestSuite) TestSave_Upserts() {
+	m, err := collaboration.NewMemberPermission("ws1", "u1", collaboration.RoleAnalyst, "u0")
+	s.Require().NoError(err)
+
+	s.mock.ExpectExec("INSERT INTO workspace_members .+ ON CONFLICT \\(workspace_id, user_id\\) DO UPDATE").
+		WithArgs(m.ID, "ws1", "u1", "analyst", []byte("[]"), "u0",
+			m.InvitedAt, nil, true, m.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.Save(context.Background(), m))
+}
+
+func (s *MemberRepoTestSuite) TestFindByWorkspaceAndUser() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("SELECT .+ FROM workspace_members WHERE workspace_id = \\$1 AND user_id = \\$2").
+		WithArgs("ws1", "u1").
+		WillReturnRows(sqlmock.NewRows(memberRowColumns).AddRow(
+			"m1", "ws1", "u1", "attorney",
+			[]byte(`[{"resource":"patent","action":"delete","allowed":true}]`), nil,
+			now, now, true, now,
+		))
+
+	m, err := s.repo.FindByWorkspaceAndUser(context.Background(), "ws1", "u1")
+	s.Require().NoError(err)
+	s.Equal(collaboration.RoleAttorney, m.Role)
+	s.Require().Len(m.CustomPermissions, 1)
+	s.True(m.CustomPermissions[0].Allowed)
+	s.Empty(m.InvitedBy)
+	s.NotNil(m.AcceptedAt)
+	s.Equal(now, m.CreatedAt)
+}
+
+func (s *MemberRepoTestSuite) TestFindByWorkspaceAndUser_NotFound() {
+	s.mock.ExpectQuery("SELECT .+ FROM workspace_members WHERE workspace_id = \\$1 AND user_id = \\$2").
+		WithArgs("ws1", "u9").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.FindByWorkspaceAndUser(context.Background(), "ws1", "u9")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *MemberRepoTestSuite) TestFindByWorkspaceID() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("SELECT .+ FROM workspace_members WHERE workspace_id = \\$1 ORDER BY joined_at").
+		WithArgs("ws1").
+		WillReturnRows(sqlmock.NewRows(memberRowColumns).
+			AddRow("m1", "ws1", "u1", "owner", []byte(`[]`), nil, now, now, true, now).
+			AddRow("m2", "ws1", "u2", "viewer", []byte(`[]`), "u1", now, nil, false, now))
+
+	members, err := s.repo.FindByWorkspaceID(context.Background(), "ws1")
+	s.Require().NoError(err)
+	s.Require().Len(members, 2)
+	s.Nil(members[0].CustomPermissions)
+	s.Equal("u1", members[1].InvitedBy)
+	s.False(members[1].IsActive)
+	s.Nil(members[1].AcceptedAt)
+}
+
+func (s *MemberRepoTestSuite) TestCountByRole() {
+	s.mock.ExpectQuery("SELECT role, COUNT\\(\\*\\) FROM workspace_members WHERE workspace_id = \\$1 GROUP BY role").
+		WithArgs("ws1").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "count"}).AddRow("owner", 1).AddRow("analyst", 3))
+
+	counts, err := s.repo.CountByRole(context.Background(), "ws1")
+	s.Require().NoError(err)
+	s.Equal(int64(3), counts[collaboration.RoleAnalyst])
+}
+
+func (s *MemberRepoTestSuite) TestDelete_NotFound() {
+	s.mock.ExpectExec("DELETE FROM workspace_members WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := s.repo.Delete(context.Background(), "missing")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func TestMemberRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(MemberRepoTestSuite))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type postgresModelExperimentRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+// NewPostgresModelExperimentRepo returns the durable store for model
+// registry experiments.
+func NewPostgresModelExperimentRepo(conn *postgres.Connection, log logging.Logger) common.ExperimentStore {
+	return &postgresModelExperimentRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresModelExperimentRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+const modelExperimentColumns = `
+	id, model_id, kind, status, baseline_version, candidate_version, canary_weight,
+	arms, shadow, reason, started_at, updated_at, ended_at`
+
+func (r *postgresModelExperimentRepo) SaveExperiment(ctx context.Context, exp *common.Experiment) error {
+	arms, err := json.Marshal(exp.Arms)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeSerialization, "failed to encode experiment arms")
+	}
+	var shadow interface{}
+	if exp.Shadow != nil {
+		b, err := json.Marshal(exp.Shadow)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeSerialization, "failed to encode shadow stats")
+		}
+		shadow = b
+	}
+	var endedAt interface{}
+	if !exp.EndedAt.IsZero() {
+		endedAt = exp.EndedAt
+	}
+
+	query := `
+		INSERT INTO model_experiments (` + modelExperimentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			canary_weight = EXCLUDED.canary_weight,
+			arms = EXCLUDED.arms,
+			shadow = EXCLUDED.shadow,
+			reason = EXCLUDED.reason,
+			updated_at = EXCLUDED.updated_at,
+			ended_at = EXCLUDED.ended_at
+	`
+	_, err = r.executor().ExecContext(ctx, query,
+		exp.ID, exp.ModelID, string(exp.Kind), string(exp.Status), exp.BaselineVersion, nullIfEmpty(exp.CandidateVersion),
+		exp.CanaryWeight, arms, shadow, nullIfEmpty(exp.Reason), exp.StartedAt, exp.UpdatedAt, endedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to save model experiment", logging.Err(err), logging.String("experiment_id", exp.ID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save model experiment")
+	}
+	return nil
+}
+
+func (r *postgresModelExperimentRepo) GetExperiment(ctx context.Context, id string) (*common.Experiment, error) {
+	query := `SELECT ` + modelExperimentColumns + ` FROM model_experiments WHERE id = $1`
+	exp, err := scanModelExperiment(r.executor().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.ErrNotFound("experiment", id)
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get model experiment")
+	}
+	return exp, nil
+}
+
+func (r *postgresModelExperimentRepo) ListExperiments(ctx context.Context, modelID string) ([]*common.Experiment, error) {
+	query := `SELECT ` + modelExperimentColumns + ` FROM model_experiments`
+	var args []interface{}
+	if modelID != "" {
+		query += ` WHERE model_id = $1`
+		args = append(args, modelID)
+	}
+	query += ` ORDER BY started_at DESC`
+
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to list model experiments")
+	}
+	defer rows.Close()
+
+	var out []*common.Experiment
+	for rows.Next() {
+		exp, err := scanModelExperiment(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan model experiment")
+		}
+		out = append(out, exp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate model experiments")
+	}
+	return out, nil
+}
+
+func scanModelExperiment(row scanner) (*common.Experiment, error) {
+	var (
+		exp               common.Experiment
+		kind, status      string
+		candidate, reason sql.NullString
+		arms, shadow      []byte
+		endedAt           sql.NullTime
+	)
+	err := row.Scan(
+		&exp.ID, &exp.ModelID, &kind, &status, &exp.BaselineVersion, &candidate, &exp.CanaryWeight,
+		&arms, &shadow, &reason, &exp.StartedAt, &exp.UpdatedAt, &endedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exp.Kind = common.ExperimentKind(kind)
+	exp.Status = common.ExperimentStatus(status)
+	exp.CandidateVersion = candidate.String
+	exp.Reason = reason.String
+	if endedAt.Valid {
+		exp.EndedAt = endedAt.Time
+	}
+	if err := json.Unmarshal(arms, &exp.Arms); err != nil {
+		return nil, err
+	}
+	if len(shadow) > 0 {
+		exp.Shadow = &common.ShadowStats{}
+		if err := json.Unmarshal(shadow, exp.Shadow); err != nil {
+			return nil, err
+		}
+	}
+	return &exp, nil
+}
+
+//Personal.AI order the ending
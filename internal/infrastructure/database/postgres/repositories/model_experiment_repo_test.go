@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type ModelExperimentRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo common.ExperimentStore
+}
+
+func (s *ModelExperimentRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresModelExperimentRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *ModelExperimentRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+func experimentColumns() []string {
+	return []string{"id", "model_id", "kind", "status", "baseline_version", "candidate_version", "canary_weight",
+		"arms", "shadow", "reason", "started_at", "updated_at", "ended_at"}
+}
+
+func (s *ModelExperimentRepoTestSuite) TestSaveExperiment_Upserts() {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	exp := &common.Experiment{
+		ID: "6f1c2a4e-0000-4000-8000-000000000001", ModelID: "infringe-net", Kind: common.ExperimentCanary,
+		Status: common.ExperimentRunning, BaselineVersion: "1.0.0", CandidateVersion: "1.1.0", CanaryWeight: 25,
+		Arms:      []*common.ArmStats{{Version: "1.0.0", Requests: 10}, {Version: "1.1.0", Requests: 2, Errors: 1}},
+		StartedAt: started, UpdatedAt: started,
+	}
+
+	s.mock.ExpectExec("INSERT INTO model_experiments .+ ON CONFLICT \\(id\\) DO UPDATE").
+		WithArgs(exp.ID, "infringe-net", "canary", "running", "1.0.0", "1.1.0", 25,
+			sqlmock.AnyArg(), nil, nil, started, started, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.SaveExperiment(context.Background(), exp))
+}
+
+func (s *ModelExperimentRepoTestSuite) TestGetExperiment_DecodesStats() {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery("SELECT .+ FROM model_experiments WHERE id = \\$1").
+		WithArgs("exp-1").
+		WillReturnRows(sqlmock.NewRows(experimentColumns()).AddRow(
+			"exp-1", "chem-extractor", "shadow", "completed", "2.0.0", "2.1.0", 0,
+			[]byte(`[{"version":"2.0.0","requests":40},{"version":"2.1.0","requests":38,"errors":2}]`),
+			[]byte(`{"compared":36,"mismatches":3,"diff_sum":3}`),
+			"stopped", started, started.Add(time.Hour), started.Add(time.Hour),
+		))
+
+	exp, err := s.repo.GetExperiment(context.Background(), "exp-1")
+	s.Require().NoError(err)
+	s.Equal(common.ExperimentShadow, exp.Kind)
+	s.Equal(int64(2), exp.Arm("2.1.0").Errors)
+	s.Equal(int64(3), exp.Shadow.Mismatches)
+	s.Equal("stopped", exp.Reason)
+	s.Equal(started.Add(time.Hour), exp.EndedAt)
+}
+
+func (s *ModelExperimentRepoTestSuite) TestGetExperiment_NotFound() {
+	s.mock.ExpectQuery("SELECT .+ FROM model_experiments WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.GetExperiment(context.Background(), "missing")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *ModelExperimentRepoTestSuite) TestListExperiments_FiltersByModel() {
+	started := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	s.mock.ExpectQuery("SELECT .+ FROM model_experiments WHERE model_id = \\$1 ORDER BY started_at DESC").
+		WithArgs("infringe-net").
+		WillReturnRows(sqlmock.NewRows(experimentColumns()).AddRow(
+			"exp-2", "infringe-net", "ab_test", "running", "1.0.0", nil, 0,
+			[]byte(`[{"version":"1.0.0"}]`), nil, nil, started, started, nil,
+		))
+
+	list, err := s.repo.ListExperiments(context.Background(), "infringe-net")
+	s.Require().NoError(err)
+	s.Require().Len(list, 1)
+	s.Nil(list[0].Shadow)
+	s.Empty(list[0].CandidateVersion)
+	s.True(list[0].EndedAt.IsZero())
+}
+
+func TestModelExperimentRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(ModelExperimentRepoTestSuite))
+}
+
+//Personal.AI order the ending
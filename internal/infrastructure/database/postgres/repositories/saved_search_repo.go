@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const savedSearchColumns = `
+	id, user_id, workspace_id, name, description, search_type, query_params,
+	is_alert_enabled, alert_frequency, last_result_ids, result_count,
+	last_executed_at, next_run_at, last_alert_at, last_error,
+	created_at, updated_at`
+
+// savedSearchClaimLease is how far FindDue pushes back the next run of the
+// searches it claims. A run saves the real next run time; if the process
+// dies first, the search becomes due again once the lease expires.
+const savedSearchClaimLease = 15 * time.Minute
+
+type postgresSavedSearchRepo struct {
+	conn *postgres.Connection
+	tx   *sql.Tx
+	log  logging.Logger
+}
+
+func NewPostgresSavedSearchRepo(conn *postgres.Connection, log logging.Logger) collaboration.SavedSearchRepository {
+	return &postgresSavedSearchRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *postgresSavedSearchRepo) executor() queryExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.conn.DB()
+}
+
+// savedSearchAlert maps a schedule onto the is_alert_enabled and
+// alert_frequency columns; manual searches are stored with alerts disabled.
+func savedSearchAlert(s collaboration.SearchSchedule) (bool, string) {
+	if s == collaboration.ScheduleManual || s == "" {
+		return false, string(collaboration.ScheduleDaily)
+	}
+	return true, string(s)
+}
+
+func (r *postgresSavedSearchRepo) Save(ctx context.Context, s *collaboration.SavedSearch) error {
+	query := `
+		INSERT INTO saved_searches (
+			id, user_id, workspace_id, name, description, search_type, query_params,
+			is_alert_enabled, alert_frequency, last_result_ids, result_count,
+			last_executed_at, next_run_at, last_alert_at, last_error,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9,
+			COALESCE($10::text[], '{}'), $11, $12, $13, $14, $15, $16, $17
+		)
+		ON CONFLICT (id) DO UPDATE SET
+			workspace_id = EXCLUDED.workspace_id,
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			search_type = EXCLUDED.search_type,
+			query_params = EXCLUDED.query_params,
+			is_alert_enabled = EXCLUDED.is_alert_enabled,
+			alert_frequency = EXCLUDED.alert_frequency,
+			last_result_ids = EXCLUDED.last_result_ids,
+			result_count = EXCLUDED.result_count,
+			last_executed_at = EXCLUDED.last_executed_at,
+			next_run_at = EXCLUDED.next_run_at,
+			last_alert_at = EXCLUDED.last_alert_at,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at
+	`
+	alertEnabled, frequency := savedSearchAlert(s.Schedule)
+	_, err := r.executor().ExecContext(ctx, query,
+		s.ID, s.OwnerID, nullIfEmpty(s.WorkspaceID), s.Name, nullIfEmpty(s.Description), string(s.Kind), []byte(s.Query),
+		alertEnabled, frequency, pq.Array(s.LastResultIDs), s.ResultCount,
+		s.LastRunAt, s.NextRunAt, s.LastAlertAt, nullIfEmpty(s.LastError),
+		s.CreatedAt, s.UpdatedAt,
+	)
+	if err != nil {
+		r.log.Error("failed to save saved search", logging.Err(err), logging.String("saved_search_id", s.ID))
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to save saved search")
+	}
+	return nil
+}
+
+func (r *postgresSavedSearchRepo) FindByID(ctx context.Context, id string) (*collaboration.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE id = $1`
+	s, err := scanSavedSearch(r.executor().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "saved search not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get saved search")
+	}
+	return s, nil
+}
+
+func (r *postgresSavedSearchRepo) FindByOwner(ctx context.Context, ownerID string) ([]*collaboration.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC`
+	return r.querySavedSearches(ctx, query, ownerID)
+}
+
+func (r *postgresSavedSearchRepo) FindByWorkspace(ctx context.Context, workspaceID string) ([]*collaboration.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE workspace_id = $1 ORDER BY created_at DESC`
+	return r.querySavedSearches(ctx, query, workspaceID)
+}
+
+// FindDue claims due searches by leasing their next run, skipping rows that
+// a concurrent worker has locked, so each due search is handed to exactly
+// one caller. The returned searches keep their original next run time.
+func (r *postgresSavedSearchRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]*collaboration.SavedSearch, error) {
+	query := `
+		WITH due AS (
+			SELECT id, next_run_at
+			FROM saved_searches
+			WHERE is_alert_enabled = TRUE AND next_run_at <= $1
+			ORDER BY next_run_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE saved_searches s SET next_run_at = $3
+			FROM due
+			WHERE s.id = due.id
+			RETURNING
+				s.id, s.user_id, s.workspace_id, s.name, s.description, s.search_type, s.query_params,
+				s.is_alert_enabled, s.alert_frequency, s.last_result_ids, s.result_count,
+				s.last_executed_at, due.next_run_at, s.last_alert_at, s.last_error,
+				s.created_at, s.updated_at
+		)
+		SELECT ` + savedSearchColumns + ` FROM claimed ORDER BY next_run_at`
+	return r.querySavedSearches(ctx, query, now, limit, now.Add(savedSearchClaimLease))
+}
+
+func (r *postgresSavedSearchRepo) Delete(ctx context.Context, id string) error {
+	res, err := r.executor().ExecContext(ctx, `DELETE FROM saved_searches WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to delete saved search")
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.New(errors.ErrCodeNotFound, "saved search not found")
+	}
+	return nil
+}
+
+func (r *postgresSavedSearchRepo) querySavedSearches(ctx context.Context, query string, args ...interface{}) ([]*collaboration.SavedSearch, error) {
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to query saved searches")
+	}
+	defer rows.Close()
+
+	var searches []*collaboration.SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan saved search")
+		}
+		searches = append(searches, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to iterate saved searches")
+	}
+	return searches, nil
+}
+
+func scanSavedSearch(row scanner) (*collaboration.SavedSearch, error) {
+	var (
+		s                                collaboration.SavedSearch
+		workspaceID, description         sql.NullString
+		frequency, lastError             sql.NullString
+		searchType                       string
+		query                            []byte
+		alertEnabled                     bool
+		resultIDs                        pq.StringArray
+		lastRunAt, nextRunAt, lastAlerts sql.NullTime
+	)
+	err := row.Scan(
+		&s.ID, &s.OwnerID, &workspaceID, &s.Name, &description, &searchType, &query,
+		&alertEnabled, &frequency, &resultIDs, &s.ResultCount,
+		&lastRunAt, &nextRunAt, &lastAlerts, &lastError,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.WorkspaceID = workspaceID.String
+	s.Description = description.String
+	s.Kind = collaboration.SavedSearchKind(searchType)
+	s.Query = query
+	s.Schedule = collaboration.ScheduleManual
+	if alertEnabled && frequency.Valid {
+		s.Schedule = collaboration.SearchSchedule(frequency.String)
+	}
+	if len(resultIDs) > 0 {
+		s.LastResultIDs = []string(resultIDs)
+	}
+	s.LastError = lastError.String
+	if lastRunAt.Valid {
+		s.LastRunAt = &lastRunAt.Time
+	}
+	if nextRunAt.Valid {
+		s.NextRunAt = &nextRunAt.Time
+	}
+	if lastAlerts.Valid {
+		s.LastAlertAt = &lastAlerts.Time
+	}
+	return &s, nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/suite"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type SavedSearchRepoTestSuite struct {
+	suite.Suite
+	mock sqlmock.Sqlmock
+	db   *sql.DB
+	repo collaboration.SavedSearchRepository
+}
+
+func (s *SavedSearchRepoTestSuite) SetupTest() {
+	var err error
+	s.db, s.mock, err = sqlmock.New()
+	s.NoError(err)
+
+	logger := logging.NewNopLogger()
+	s.repo = NewPostgresSavedSearchRepo(postgres.NewConnectionWithDB(s.db, logger), logger)
+}
+
+func (s *SavedSearchRepoTestSuite) TearDownTest() {
+	s.NoError(s.mock.ExpectationsWereMet())
+	s.db.Close()
+}
+
+var savedSearchRowColumns = []string{
+	"id", "user_id", "workspace_id", "name", "description", "search_type", "query_params",
+	"is_alert_enabled", "alert_frequency", "last_result_ids", "result_count",
+	"last_executed_at", "next_run_at", "last_alert_at", "last_error",
+	"created_at", "updated_at",
+}
+
+func (s *SavedSearchRepoTestSuite) TestSave_ManualStoredWithAlertsOff() {
+	ss, err := collaboration.NewSavedSearch("u1", "OLED hosts", collaboration.SavedSearchPatent,
+		json.RawMessage(`{"keywords":["host"]}`), collaboration.ScheduleManual)
+	s.Require().NoError(err)
+
+	s.mock.ExpectExec("INSERT INTO saved_searches").
+		WithArgs(ss.ID, "u1", nil, "OLED hosts", nil, "patent", []byte(`{"keywords":["host"]}`),
+			false, "daily", sqlmock.AnyArg(), 0,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil,
+			sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.Save(context.Background(), ss))
+}
+
+func (s *SavedSearchRepoTestSuite) TestSave_DatabaseError() {
+	ss, _ := collaboration.NewSavedSearch("u1", "kg", collaboration.SavedSearchKnowledgeGraph,
+		json.RawMessage(`{"entity_type":"patent"}`), collaboration.ScheduleWeekly)
+	s.Require().NoError(ss.ShareWith("ws1"))
+
+	s.mock.ExpectExec("INSERT INTO saved_searches").
+		WithArgs(ss.ID, "u1", "ws1", "kg", nil, "knowledge_graph", sqlmock.AnyArg(),
+			true, "weekly", sqlmock.AnyArg(), 0,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil,
+			sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnError(sql.ErrConnDone)
+
+	err := s.repo.Save(context.Background(), ss)
+	s.True(errors.IsCode(err, errors.ErrCodeDatabaseError))
+}
+
+func (s *SavedSearchRepoTestSuite) TestFindByID_Found() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("SELECT .+ FROM saved_searches WHERE id = \\$1").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows(savedSearchRowColumns).AddRow(
+			"s1", "u1", "ws1", "hosts", nil, "molecule", []byte(`{"smiles":"c1ccccc1"}`),
+			true, "realtime", "{p1,p2}", 2,
+			now, now.Add(time.Minute), nil, nil,
+			now, now,
+		))
+
+	ss, err := s.repo.FindByID(context.Background(), "s1")
+	s.Require().NoError(err)
+	s.Equal(collaboration.ScheduleRealtime, ss.Schedule)
+	s.Equal(collaboration.SavedSearchMolecule, ss.Kind)
+	s.Equal([]string{"p1", "p2"}, ss.LastResultIDs)
+	s.True(ss.IsShared())
+	s.NotNil(ss.NextRunAt)
+	s.Nil(ss.LastAlertAt)
+}
+
+func (s *SavedSearchRepoTestSuite) TestFindByID_AlertsOffIsManual() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("SELECT .+ FROM saved_searches WHERE id = \\$1").
+		WithArgs("s1").
+		WillReturnRows(sqlmock.NewRows(savedSearchRowColumns).AddRow(
+			"s1", "u1", nil, "hosts", nil, "patent", []byte(`{}`),
+			false, "daily", "{}", 0,
+			nil, nil, nil, nil,
+			now, now,
+		))
+
+	ss, err := s.repo.FindByID(context.Background(), "s1")
+	s.Require().NoError(err)
+	s.Equal(collaboration.ScheduleManual, ss.Schedule)
+	s.False(ss.IsShared())
+	s.Empty(ss.LastResultIDs)
+}
+
+func (s *SavedSearchRepoTestSuite) TestFindByID_NotFound() {
+	s.mock.ExpectQuery("SELECT .+ FROM saved_searches WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.FindByID(context.Background(), "missing")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *SavedSearchRepoTestSuite) TestFindDue() {
+	now := time.Now().UTC()
+	s.mock.ExpectQuery("FOR UPDATE SKIP LOCKED.+UPDATE saved_searches s SET next_run_at = \\$3.+FROM claimed ORDER BY next_run_at").
+		WithArgs(now, 10, now.Add(savedSearchClaimLease)).
+		WillReturnRows(sqlmock.NewRows(savedSearchRowColumns).AddRow(
+			"s1", "u1", nil, "hosts", nil, "patent", []byte(`{}`),
+			true, "daily", "{}", 0,
+			nil, now.Add(-time.Minute), nil, nil,
+			now, now,
+		))
+
+	due, err := s.repo.FindDue(context.Background(), now, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 1)
+	s.True(due[0].IsDue(now), "claimed searches keep their original next run")
+}
+
+func (s *SavedSearchRepoTestSuite) TestDelete_NotFound() {
+	s.mock.ExpectExec("DELETE FROM saved_searches WHERE id = \\$1").
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := s.repo.Delete(context.Background(), "missing")
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func TestSavedSearchRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(SavedSearchRepoTestSuite))
+}
+
+//Personal.AI order the ending
@@ -543,16 +543,24 @@ func (r *postgresUserRepo) HasPermission(ctx context.Context, userID uuid.UUID,
 }
 
 // API Key
+const apiKeyColumns = `
+	id, user_id, organization_id, name, key_hash, key_prefix, scopes, rate_limit,
+	expires_at, last_used_at, last_used_ip, is_active,
+	tier, monthly_quota, rotated_from_id, revoked_at, created_at, updated_at`
+
 func scanAPIKey(row scanner) (*user.APIKey, error) {
 	k := &user.APIKey{}
-	var orgID uuid.NullUUID
-	var expiresAt, lastUsedAt sql.NullTime
+	var orgID, rotatedFrom uuid.NullUUID
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var lastUsedIP sql.NullString
+	var tier string
 
 	err := row.Scan(
 		&k.ID, &k.UserID, &orgID, &k.Name, &k.KeyHash, &k.KeyPrefix,
 		pq.Array(&k.Scopes), &k.RateLimit,
-		&expiresAt, &lastUsedAt, &k.LastUsedIP,
-		&k.IsActive, &k.CreatedAt, &k.UpdatedAt,
+		&expiresAt, &lastUsedAt, &lastUsedIP, &k.IsActive,
+		&tier, &k.MonthlyQuota, &rotatedFrom, &revokedAt,
+		&k.CreatedAt, &k.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -560,27 +568,43 @@ func scanAPIKey(row scanner) (*user.APIKey, error) {
 		}
 		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to scan API key")
 	}
+	k.Tier = user.APIKeyTier(tier)
+	k.LastUsedIP = lastUsedIP.String
 	if orgID.Valid {
 		k.OrganizationID = &orgID.UUID
 	}
+	if rotatedFrom.Valid {
+		k.RotatedFromID = &rotatedFrom.UUID
+	}
 	if expiresAt.Valid {
 		k.ExpiresAt = &expiresAt.Time
 	}
 	if lastUsedAt.Valid {
 		k.LastUsedAt = &lastUsedAt.Time
 	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
 	return k, nil
 }
 
 func (r *postgresUserRepo) CreateAPIKey(ctx context.Context, key *user.APIKey) error {
 	query := `
-		INSERT INTO api_keys (user_id, organization_id, name, key_hash, key_prefix, scopes, rate_limit, expires_at, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO api_keys (
+			user_id, organization_id, name, key_hash, key_prefix, scopes, rate_limit, expires_at, is_active,
+			tier, monthly_quota, rotated_from_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at
 	`
+	tier := key.Tier
+	if tier == "" {
+		tier = user.APIKeyTierFree
+	}
 	err := r.executor().QueryRowContext(ctx, query,
 		key.UserID, key.OrganizationID, key.Name, key.KeyHash, key.KeyPrefix,
 		pq.Array(key.Scopes), key.RateLimit, key.ExpiresAt, key.IsActive,
+		string(tier), key.MonthlyQuota, key.RotatedFromID,
 	).Scan(&key.ID, &key.CreatedAt, &key.UpdatedAt)
 
 	if err != nil {
@@ -589,14 +613,20 @@ func (r *postgresUserRepo) CreateAPIKey(ctx context.Context, key *user.APIKey) e
 	return nil
 }
 
+func (r *postgresUserRepo) GetAPIKeyByID(ctx context.Context, id uuid.UUID) (*user.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+	row := r.executor().QueryRowContext(ctx, query, id)
+	return scanAPIKey(row)
+}
+
 func (r *postgresUserRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*user.APIKey, error) {
-	query := `SELECT * FROM api_keys WHERE key_hash = $1`
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
 	row := r.executor().QueryRowContext(ctx, query, keyHash)
 	return scanAPIKey(row)
 }
 
 func (r *postgresUserRepo) GetAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*user.APIKey, error) {
-	query := `SELECT * FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
 	rows, err := r.executor().QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get API keys by user")
@@ -614,10 +644,62 @@ func (r *postgresUserRepo) GetAPIKeysByUser(ctx context.Context, userID uuid.UUI
 	return keys, nil
 }
 
+func (r *postgresUserRepo) UpdateAPIKey(ctx context.Context, key *user.APIKey) error {
+	query := `
+		UPDATE api_keys SET
+			name = $1, scopes = $2, rate_limit = $3, expires_at = $4, is_active = $5,
+			tier = $6, monthly_quota = $7, revoked_at = $8, updated_at = NOW()
+		WHERE id = $9
+		RETURNING updated_at
+	`
+	err := r.executor().QueryRowContext(ctx, query,
+		key.Name, pq.Array(key.Scopes), key.RateLimit, key.ExpiresAt, key.IsActive,
+		string(key.Tier), key.MonthlyQuota, key.RevokedAt, key.ID,
+	).Scan(&key.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.New(errors.ErrCodeNotFound, "API key not found")
+		}
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to update API key")
+	}
+	return nil
+}
+
 func (r *postgresUserRepo) UpdateAPIKeyLastUsed(ctx context.Context, id uuid.UUID, ip string) error {
 	query := `UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $1 WHERE id = $2`
-	_, err := r.executor().ExecContext(ctx, query, ip, id)
-	return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to update API key last used")
+	if _, err := r.executor().ExecContext(ctx, query, ip, id); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to update API key last used")
+	}
+	return nil
+}
+
+func (r *postgresUserRepo) IncrementAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error) {
+	query := `
+		INSERT INTO api_key_usage (api_key_id, period, request_count, updated_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (api_key_id, period) DO UPDATE SET
+			request_count = api_key_usage.request_count + 1,
+			updated_at = NOW()
+		RETURNING request_count
+	`
+	var count int64
+	if err := r.executor().QueryRowContext(ctx, query, id, period).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to record API key usage")
+	}
+	return count, nil
+}
+
+func (r *postgresUserRepo) GetAPIKeyUsage(ctx context.Context, id uuid.UUID, period time.Time) (int64, error) {
+	query := `SELECT request_count FROM api_key_usage WHERE api_key_id = $1 AND period = $2`
+	var count int64
+	err := r.executor().QueryRowContext(ctx, query, id, period).Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "failed to get API key usage")
+	}
+	return count, nil
 }
 
 func (r *postgresUserRepo) DeactivateAPIKey(ctx context.Context, id uuid.UUID) error {
@@ -12,6 +12,7 @@ import (
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 )
 
 type UserRepoTestSuite struct {
@@ -73,6 +74,83 @@ func (s *UserRepoTestSuite) TestCreate_Success() {
 	s.Equal(id, u.ID)
 }
 
+func (s *UserRepoTestSuite) TestGetAPIKeyByHash_Found() {
+	id, uid, prev := uuid.New(), uuid.New(), uuid.New()
+	s.mock.ExpectQuery("SELECT .* FROM api_keys WHERE key_hash = \\$1").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "organization_id", "name", "key_hash", "key_prefix", "scopes", "rate_limit",
+			"expires_at", "last_used_at", "last_used_ip", "is_active",
+			"tier", "monthly_quota", "rotated_from_id", "revoked_at", "created_at", "updated_at",
+		}).AddRow(
+			id, uid, nil, "ci", "hash", "kip_abcdefgh", "{patent:read}", 300,
+			nil, nil, nil, true,
+			"professional", 100000, prev, nil, time.Now(), time.Now(),
+		))
+
+	k, err := s.repo.GetAPIKeyByHash(context.Background(), "hash")
+	s.NoError(err)
+	s.Equal(id, k.ID)
+	s.Equal(user.APIKeyTierProfessional, k.Tier)
+	s.Equal(int64(100000), k.MonthlyQuota)
+	s.Equal([]string{"patent:read"}, k.Scopes)
+	s.Equal("", k.LastUsedIP)
+	s.Require().NotNil(k.RotatedFromID)
+	s.Equal(prev, *k.RotatedFromID)
+}
+
+func (s *UserRepoTestSuite) TestGetAPIKeyByID_NotFound() {
+	id := uuid.New()
+	s.mock.ExpectQuery("SELECT .* FROM api_keys WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := s.repo.GetAPIKeyByID(context.Background(), id)
+	s.Error(err)
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *UserRepoTestSuite) TestUpdateAPIKey_NotFound() {
+	s.mock.ExpectQuery("UPDATE api_keys SET").
+		WillReturnError(sql.ErrNoRows)
+
+	err := s.repo.UpdateAPIKey(context.Background(), &user.APIKey{ID: uuid.New(), Tier: user.APIKeyTierFree})
+	s.True(errors.IsCode(err, errors.ErrCodeNotFound))
+}
+
+func (s *UserRepoTestSuite) TestUpdateAPIKeyLastUsed_Success() {
+	id := uuid.New()
+	s.mock.ExpectExec("UPDATE api_keys SET last_used_at").
+		WithArgs("10.0.0.1", id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	s.NoError(s.repo.UpdateAPIKeyLastUsed(context.Background(), id, "10.0.0.1"))
+}
+
+func (s *UserRepoTestSuite) TestIncrementAPIKeyUsage() {
+	id := uuid.New()
+	period := user.UsagePeriod(time.Now())
+	s.mock.ExpectQuery("INSERT INTO api_key_usage").
+		WithArgs(id, period).
+		WillReturnRows(sqlmock.NewRows([]string{"request_count"}).AddRow(42))
+
+	n, err := s.repo.IncrementAPIKeyUsage(context.Background(), id, period)
+	s.NoError(err)
+	s.Equal(int64(42), n)
+}
+
+func (s *UserRepoTestSuite) TestGetAPIKeyUsage_NoRows() {
+	id := uuid.New()
+	period := user.UsagePeriod(time.Now())
+	s.mock.ExpectQuery("SELECT request_count FROM api_key_usage").
+		WithArgs(id, period).
+		WillReturnError(sql.ErrNoRows)
+
+	n, err := s.repo.GetAPIKeyUsage(context.Background(), id, period)
+	s.NoError(err)
+	s.Zero(n)
+}
+
 func TestUserRepoTestSuite(t *testing.T) {
 	suite.Run(t, new(UserRepoTestSuite))
 }
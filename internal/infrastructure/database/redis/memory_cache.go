@@ -0,0 +1,482 @@
+package redis
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// memoryEntry holds one key of any Redis type; exactly one of the value
+// fields is set.
+type memoryEntry struct {
+	str      []byte
+	hash     map[string]string
+	zset     map[string]float64
+	expireAt time.Time // zero means no expiry
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// memoryCache is an in-process Cache with Redis semantics for keys, TTLs,
+// counters, hashes and sorted sets. It backs unit tests and single-node
+// tooling where running Redis is not worth it.
+type memoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryEntry
+	defaultTTL time.Duration
+	serializer Serializer
+	now        func() time.Time
+}
+
+// MemoryCacheOption configures a memory cache.
+type MemoryCacheOption func(*memoryCache)
+
+// WithMemoryDefaultTTL sets the TTL applied when Set is called with ttl 0.
+// A negative value disables expiry for such writes.
+func WithMemoryDefaultTTL(ttl time.Duration) MemoryCacheOption {
+	return func(c *memoryCache) { c.defaultTTL = ttl }
+}
+
+// WithMemoryClock replaces time.Now, for expiry tests.
+func WithMemoryClock(now func() time.Time) MemoryCacheOption {
+	return func(c *memoryCache) { c.now = now }
+}
+
+// NewMemoryCache returns an in-memory Cache. Like the Redis cache, Set with
+// ttl 0 applies a 15 minute default.
+func NewMemoryCache(opts ...MemoryCacheOption) Cache {
+	c := &memoryCache{
+		entries:    make(map[string]*memoryEntry),
+		defaultTTL: 15 * time.Minute,
+		serializer: &jsonSerializer{},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// lookup returns a live entry, dropping it if expired. Callers hold mu.
+func (c *memoryCache) lookup(key string) *memoryEntry {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(c.now()) {
+		delete(c.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (c *memoryCache) expiry(ttl time.Duration) time.Time {
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl < 0 {
+		return time.Time{}
+	}
+	return c.now().Add(ttl)
+}
+
+func wrongType(key string) error {
+	return errors.New(errors.ErrCodeCacheError, "WRONGTYPE operation against key "+key)
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	e := c.lookup(key)
+	var data []byte
+	if e != nil {
+		data = append([]byte(nil), e.str...)
+	}
+	c.mu.Unlock()
+	if e == nil || e.str == nil {
+		return ErrCacheMiss
+	}
+	return c.serializer.Unmarshal(data, dest)
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := c.serializer.Marshal(value)
+	if err != nil {
+		return ErrSerializationFailed
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &memoryEntry{str: data, expireAt: c.expiry(ttl)}
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range keys {
+		delete(c.entries, k)
+	}
+	return nil
+}
+
+func (c *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(key) != nil, nil
+}
+
+func (c *memoryCache) MGet(ctx context.Context, keys []string) (map[string][]byte, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string][]byte)
+	for _, k := range keys {
+		if e := c.lookup(k); e != nil && e.str != nil {
+			result[k] = append([]byte(nil), e.str...)
+		}
+	}
+	return result, nil
+}
+
+func (c *memoryCache) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	encoded := make(map[string][]byte, len(items))
+	for k, v := range items {
+		data, err := c.serializer.Marshal(v)
+		if err != nil {
+			return ErrSerializationFailed
+		}
+		encoded[k] = data
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, data := range encoded {
+		c.entries[k] = &memoryEntry{str: data, expireAt: c.expiry(ttl)}
+	}
+	return nil
+}
+
+func (c *memoryCache) GetOrSet(ctx context.Context, key string, dest interface{}, ttl time.Duration, loader func(ctx context.Context) (interface{}, error)) error {
+	err := c.Get(ctx, key, dest)
+	if err != ErrCacheMiss {
+		return err
+	}
+	v, err := loader(ctx)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return ErrCacheMiss
+	}
+	if err := c.Set(ctx, key, v, ttl); err != nil {
+		return err
+	}
+	return c.Get(ctx, key, dest)
+}
+
+func (c *memoryCache) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var deleted int64
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) && c.lookup(k) != nil {
+			delete(c.entries, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (c *memoryCache) HGet(ctx context.Context, key, field string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		return "", ErrCacheMiss
+	}
+	if e.hash == nil {
+		return "", wrongType(key)
+	}
+	v, ok := e.hash[field]
+	if !ok {
+		return "", ErrCacheMiss
+	}
+	return v, nil
+}
+
+func (c *memoryCache) HSet(ctx context.Context, key string, fields map[string]interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		e = &memoryEntry{hash: make(map[string]string)}
+		c.entries[key] = e
+	} else if e.hash == nil {
+		return wrongType(key)
+	}
+	for f, v := range fields {
+		e.hash[f] = toRedisString(v)
+	}
+	if ttl > 0 {
+		e.expireAt = c.now().Add(ttl)
+	}
+	return nil
+}
+
+func (c *memoryCache) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]string)
+	e := c.lookup(key)
+	if e == nil {
+		return out, nil
+	}
+	if e.hash == nil {
+		return nil, wrongType(key)
+	}
+	for f, v := range e.hash {
+		out[f] = v
+	}
+	return out, nil
+}
+
+func (c *memoryCache) HDel(ctx context.Context, key string, fields ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		return nil
+	}
+	if e.hash == nil {
+		return wrongType(key)
+	}
+	for _, f := range fields {
+		delete(e.hash, f)
+	}
+	if len(e.hash) == 0 {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *memoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, 1)
+}
+
+func (c *memoryCache) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		e = &memoryEntry{str: []byte("0")}
+		c.entries[key] = e
+	}
+	if e.str == nil {
+		return 0, wrongType(key)
+	}
+	n, err := strconv.ParseInt(string(e.str), 10, 64)
+	if err != nil {
+		return 0, errors.New(errors.ErrCodeCacheError, "value is not an integer or out of range")
+	}
+	n += value
+	e.str = []byte(strconv.FormatInt(n, 10))
+	return n, nil
+}
+
+func (c *memoryCache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.IncrBy(ctx, key, -1)
+}
+
+func (c *memoryCache) ZAdd(ctx context.Context, key string, members ...*ZMember) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		e = &memoryEntry{zset: make(map[string]float64)}
+		c.entries[key] = e
+	} else if e.zset == nil {
+		return wrongType(key)
+	}
+	for _, m := range members {
+		e.zset[m.Member] = m.Score
+	}
+	return nil
+}
+
+// sortedMembers orders a sorted set by score, then member, as Redis does.
+func sortedMembers(zset map[string]float64) []*ZMember {
+	out := make([]*ZMember, 0, len(zset))
+	for m, s := range zset {
+		out = append(out, &ZMember{Score: s, Member: m})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score < out[j].Score
+		}
+		return out[i].Member < out[j].Member
+	})
+	return out
+}
+
+func (c *memoryCache) zset(key string) (map[string]float64, error) {
+	e := c.lookup(key)
+	if e == nil {
+		return nil, nil
+	}
+	if e.zset == nil {
+		return nil, wrongType(key)
+	}
+	return e.zset, nil
+}
+
+func (c *memoryCache) ZRangeByScore(ctx context.Context, key string, min, max float64, offset, count int64) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	zs, err := c.zset(key)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, m := range sortedMembers(zs) {
+		if m.Score >= min && m.Score <= max {
+			out = append(out, m.Member)
+		}
+	}
+	if offset > 0 {
+		if offset >= int64(len(out)) {
+			return []string{}, nil
+		}
+		out = out[offset:]
+	}
+	if count > 0 && count < int64(len(out)) {
+		out = out[:count]
+	}
+	return out, nil
+}
+
+func (c *memoryCache) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]*ZMember, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	zs, err := c.zset(key)
+	if err != nil {
+		return nil, err
+	}
+	members := sortedMembers(zs)
+	n := int64(len(members))
+	for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+		members[i], members[j] = members[j], members[i]
+	}
+	if start < 0 {
+		start = max(n+start, 0)
+	}
+	if stop < 0 {
+		stop = n + stop
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []*ZMember{}, nil
+	}
+	return members[start : stop+1], nil
+}
+
+func (c *memoryCache) ZRem(ctx context.Context, key string, members ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	zs, err := c.zset(key)
+	if err != nil || zs == nil {
+		return err
+	}
+	for _, m := range members {
+		delete(zs, m)
+	}
+	if len(zs) == 0 {
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+func (c *memoryCache) ZScore(ctx context.Context, key, member string) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	zs, err := c.zset(key)
+	if err != nil {
+		return 0, err
+	}
+	s, ok := zs[member]
+	if !ok {
+		return 0, ErrCacheMiss
+	}
+	return s, nil
+}
+
+func (c *memoryCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	if e == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		delete(c.entries, key)
+		return nil
+	}
+	e.expireAt = c.now().Add(ttl)
+	return nil
+}
+
+// TTL mirrors go-redis: -2 for a missing key, -1 for a key without expiry.
+func (c *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.lookup(key)
+	switch {
+	case e == nil:
+		return -2, nil
+	case e.expireAt.IsZero():
+		return -1, nil
+	}
+	return e.expireAt.Sub(c.now()), nil
+}
+
+func (c *memoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// toRedisString formats a hash field value the way go-redis writes it.
+func toRedisString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		if t {
+			return "1"
+		}
+		return "0"
+	case nil:
+		return ""
+	}
+	data, err := (&jsonSerializer{}).Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (f *fakeClock) now() time.Time          { return f.t }
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestMemoryCache_GetSetExpiry(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	cache := NewMemoryCache(WithMemoryClock(clock.now))
+	ctx := context.Background()
+	type Data struct{ Name string }
+
+	require.NoError(t, cache.Set(ctx, "key", &Data{Name: "test"}, 0))
+	var got Data
+	require.NoError(t, cache.Get(ctx, "key", &got))
+	assert.Equal(t, "test", got.Name)
+
+	ttl, err := cache.TTL(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, ttl)
+
+	require.NoError(t, cache.Expire(ctx, "key", time.Second))
+	clock.advance(2 * time.Second)
+	assert.ErrorIs(t, cache.Get(ctx, "key", &got), ErrCacheMiss)
+	exists, _ := cache.Exists(ctx, "key")
+	assert.False(t, exists)
+
+	ttl, _ = cache.TTL(ctx, "missing")
+	assert.Equal(t, time.Duration(-2), ttl)
+
+	persistent := NewMemoryCache(WithMemoryDefaultTTL(-1))
+	require.NoError(t, persistent.Set(ctx, "k", 1, 0))
+	ttl, _ = persistent.TTL(ctx, "k")
+	assert.Equal(t, time.Duration(-1), ttl)
+}
+
+func TestMemoryCache_MultiAndPrefix(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.MSet(ctx, map[string]interface{}{"a:1": 1, "a:2": 2, "b:1": 3}, 0))
+	vals, err := cache.MGet(ctx, []string{"a:1", "b:1", "nope"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"a:1": []byte("1"), "b:1": []byte("3")}, vals)
+
+	n, err := cache.DeleteByPrefix(ctx, "a:")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	exists, _ := cache.Exists(ctx, "b:1")
+	assert.True(t, exists)
+
+	var out string
+	calls := 0
+	loader := func(context.Context) (interface{}, error) { calls++; return "loaded", nil }
+	require.NoError(t, cache.GetOrSet(ctx, "lazy", &out, 0, loader))
+	require.NoError(t, cache.GetOrSet(ctx, "lazy", &out, 0, loader))
+	assert.Equal(t, "loaded", out)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMemoryCache_CountersAndHashes(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	v, _ := cache.Incr(ctx, "counter")
+	assert.Equal(t, int64(1), v)
+	v, _ = cache.IncrBy(ctx, "counter", 10)
+	assert.Equal(t, int64(11), v)
+	v, _ = cache.Decr(ctx, "counter")
+	assert.Equal(t, int64(10), v)
+
+	// Counters written through Set stay incrementable, as in Redis.
+	require.NoError(t, cache.Set(ctx, "n", 5, 0))
+	v, err := cache.Incr(ctx, "n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), v)
+
+	require.NoError(t, cache.HSet(ctx, "h", map[string]interface{}{"s": "x", "i": 7, "f": 0.5}, 0))
+	all, err := cache.HGetAll(ctx, "h")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"s": "x", "i": "7", "f": "0.5"}, all)
+	_, err = cache.HGet(ctx, "h", "missing")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	require.NoError(t, cache.HDel(ctx, "h", "s", "i", "f"))
+	exists, _ := cache.Exists(ctx, "h")
+	assert.False(t, exists)
+
+	_, err = cache.HGet(ctx, "counter", "x")
+	assert.Error(t, err, "hash op on a string key")
+}
+
+func TestMemoryCache_SortedSets(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.ZAdd(ctx, "z",
+		&ZMember{Score: 3, Member: "c"}, &ZMember{Score: 1, Member: "a"}, &ZMember{Score: 2, Member: "b"}))
+
+	got, err := cache.ZRangeByScore(ctx, "z", 1, 2, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, got)
+	got, _ = cache.ZRangeByScore(ctx, "z", 0, 10, 1, 1)
+	assert.Equal(t, []string{"b"}, got)
+
+	top, err := cache.ZRevRangeWithScores(ctx, "z", 0, 1)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	assert.Equal(t, "c", top[0].Member)
+	all, _ := cache.ZRevRangeWithScores(ctx, "z", 0, -1)
+	assert.Len(t, all, 3)
+
+	score, err := cache.ZScore(ctx, "z", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, score)
+	require.NoError(t, cache.ZRem(ctx, "z", "b"))
+	_, err = cache.ZScore(ctx, "z", "b")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	assert.NoError(t, cache.Ping(ctx))
+}
+
+//Personal.AI order the ending
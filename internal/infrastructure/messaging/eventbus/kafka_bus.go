@@ -10,8 +10,10 @@ import (
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/events"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MessagePublisher defines the interface for publishing messages to a message broker.
@@ -93,13 +95,19 @@ func (b *KafkaEventBus) Publish(ctx context.Context, domainEvents ...events.Even
 				"content_type": "application/x-domain-event",
 			},
 		}
+		// Carry the caller's trace so consumers of this event continue it;
+		// a Kafka producer replaces traceparent with its own publish span.
+		tracing.InjectMessage(ctx, msg)
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			msg.Headers["trace_id"] = sc.TraceID().String()
+		}
 
 		if err := b.producer.Publish(ctx, msg); err != nil {
 			return errors.Wrap(err, errors.ErrCodeInternal,
 				fmt.Sprintf("failed to publish event %s to topic %s", event.EventType(), topic))
 		}
 
-		b.logger.Debug("Event published to Kafka",
+		b.logger.WithContext(ctx).Debug("Event published to Kafka",
 			logging.String("event_type", string(event.EventType())),
 			logging.String("topic", topic),
 			logging.String("aggregate_id", event.AggregateID()))
@@ -5,6 +5,10 @@ import (
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/events"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // LoggingBus is a decorator that wraps an events.EventBus and logs
@@ -22,10 +26,17 @@ func NewLoggingBus(inner events.EventBus, logger logging.Logger) *LoggingBus {
 	}
 }
 
-// Publish logs each event and delegates to the wrapped bus.
+// Publish logs each event and delegates to the wrapped bus. Publishing runs
+// in its own span, and the log lines carry its trace and span IDs, so events
+// can be correlated with the consumers that handle them downstream.
 func (b *LoggingBus) Publish(ctx context.Context, domainEvents ...events.Event) error {
+	ctx, span := otel.Tracer("eventbus").Start(ctx, "eventbus publish",
+		trace.WithAttributes(attribute.Int("eventbus.event_count", len(domainEvents))))
+	ctx = tracing.ContextWithLogIDs(ctx)
+
+	logger := b.logger.WithContext(ctx)
 	for _, event := range domainEvents {
-		b.logger.Info("Domain event published",
+		logger.Info("Domain event published",
 			logging.String("event_type", string(event.EventType())),
 			logging.String("event_id", event.EventID()),
 			logging.String("aggregate_id", event.AggregateID()),
@@ -33,7 +44,9 @@ func (b *LoggingBus) Publish(ctx context.Context, domainEvents ...events.Event)
 		)
 	}
 
-	return b.inner.Publish(ctx, domainEvents...)
+	err := b.inner.Publish(ctx, domainEvents...)
+	tracing.EndSpan(span, err)
+	return err
 }
 
 // Subscribe logs the subscription and delegates to the wrapped bus.
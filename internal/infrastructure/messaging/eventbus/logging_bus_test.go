@@ -6,6 +6,7 @@ import (
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/domain/events"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
 )
 
 func TestLoggingBus_Publish(t *testing.T) {
@@ -72,3 +73,32 @@ func TestLoggingBus_NilInner(t *testing.T) {
 
 	_ = bus.Publish(ctx, event)
 }
+
+func TestLoggingBus_PropagatesTraceToKafka(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	mp := &mockProducer{}
+	bus := NewLoggingBus(NewKafkaEventBus(mp, logging.NewNopLogger()), logging.NewNopLogger())
+
+	if err := bus.Publish(context.Background(), newTestEvent("agg-1")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	sc := spans[0].SpanContext
+
+	published := mp.GetPublished()
+	if len(published) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(published))
+	}
+	headers := published[0].Headers
+	if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; headers["traceparent"] != want {
+		t.Errorf("traceparent = %q, want %q", headers["traceparent"], want)
+	}
+	if headers["trace_id"] != sc.TraceID().String() {
+		t.Errorf("trace_id = %q, want %q", headers["trace_id"], sc.TraceID().String())
+	}
+}
@@ -14,8 +14,10 @@ import (
 	"github.com/segmentio/kafka-go/sasl/plain"
 	"github.com/segmentio/kafka-go/sasl/scram"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+	"go.opentelemetry.io/otel/codes"
 )
 
 var (
@@ -53,6 +55,10 @@ type ConsumerConfig struct {
 	TLSEnabled         bool
 	TLSCertPath        string
 	RetryConfig        RetryConfig
+	// ManualCommit stops the consume loop from committing once the handler
+	// returns; the caller commits each message with Commit after it has
+	// really been processed.
+	ManualCommit bool
 }
 
 // ConsumerMetrics holds consumer metrics.
@@ -306,7 +312,7 @@ func (c *Consumer) consumeLoop(ctx context.Context) {
 		if err := c.processMessage(ctx, msg, handler); err == nil {
 			// Success
 			c.metrics.MessagesProcessed.Add(1)
-			if !c.config.EnableAutoCommit {
+			if !c.config.EnableAutoCommit && !c.config.ManualCommit {
 				if err := c.reader.CommitMessages(ctx, m); err != nil {
 					c.logger.Error("CommitMessages failed", logging.Error(err))
 				}
@@ -319,14 +325,30 @@ func (c *Consumer) consumeLoop(ctx context.Context) {
 			// Only if processMessage returns error do we NOT commit?
 			// But processMessage implementation logic says "return nil (不阻塞消费进度)".
 			// So we always commit if processMessage returns nil.
-			if !c.config.EnableAutoCommit {
+			if !c.config.EnableAutoCommit && !c.config.ManualCommit {
 				c.reader.CommitMessages(ctx, m)
 			}
 		}
 	}
 }
 
+// Commit commits msg's offset for the consumer group. It is meant for
+// ManualCommit consumers; committing an offset also commits every earlier
+// offset of the same partition.
+func (c *Consumer) Commit(ctx context.Context, msg *common.Message) error {
+	return c.reader.CommitMessages(ctx, kafka.Message{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+	})
+}
+
 func (c *Consumer) processMessage(ctx context.Context, msg *common.Message, handler common.MessageHandler) error {
+	// The span covers every attempt and the DLQ hand-off, and continues the
+	// trace propagated by the producer.
+	ctx, span := tracing.StartConsumerSpan(ctx, msg, tracing.OperationReceive)
+	defer span.End()
+
 	// First attempt
 	err := handler(ctx, msg)
 	if err == nil {
@@ -370,22 +392,17 @@ func (c *Consumer) processMessage(ctx context.Context, msg *common.Message, hand
 	}
 
 	// Retries exhausted
-	c.logger.Error("Message processing failed after retries",
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	c.logger.WithContext(ctx).Error("Message processing failed after retries",
 		logging.String("topic", msg.Topic),
 		logging.Int64("offset", msg.Offset),
 		logging.Error(err))
 
 	if c.deadLetterProducer != nil && c.config.RetryConfig.DeadLetterTopic != "" {
-		// Send to DLQ
-		dlMsg := &common.ProducerMessage{
-			Topic:   c.config.RetryConfig.DeadLetterTopic,
-			Key:     msg.Key,
-			Value:   msg.Value,
-			Headers: msg.Headers,
-			// Add error info to headers?
-		}
-		dlMsg.Headers["original_topic"] = msg.Topic
-		dlMsg.Headers["error_message"] = err.Error()
+		// Send to DLQ. The producer re-injects traceparent from ctx, so the
+		// dead letter joins this trace; the original one is kept alongside.
+		dlMsg := NewDeadLetterMessage(c.config.RetryConfig.DeadLetterTopic, msg, err, maxRetries+1, time.Now())
 
 		if dlErr := c.deadLetterProducer.Publish(ctx, dlMsg); dlErr != nil {
 			c.logger.Error("Failed to send to dead letter queue", logging.Error(dlErr))
@@ -9,6 +9,8 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
 )
 
@@ -152,6 +154,60 @@ func TestConsumeLoop_SingleMessage(t *testing.T) {
 	cancel()
 }
 
+func TestConsumeLoop_ManualCommit(t *testing.T) {
+	fetched := false
+	commits := make(chan []kafka.Message, 2)
+	mockReader := &mockKafkaReader{
+		fetchFunc: func(ctx context.Context) (kafka.Message, error) {
+			if fetched {
+				<-ctx.Done()
+				return kafka.Message{}, ctx.Err()
+			}
+			fetched = true
+			return kafka.Message{Topic: "test-topic", Partition: 2, Offset: 41}, nil
+		},
+		commitFunc: func(ctx context.Context, msgs ...kafka.Message) error {
+			commits <- msgs
+			return nil
+		},
+	}
+
+	cfg := newTestConsumerConfig()
+	cfg.ManualCommit = true
+	c := &Consumer{
+		reader:   mockReader,
+		config:   cfg,
+		logger:   newMockLogger(),
+		handlers: make(map[string]common.MessageHandler),
+		metrics:  &ConsumerMetrics{},
+	}
+
+	received := make(chan *common.Message, 1)
+	c.Subscribe("test-topic", func(ctx context.Context, msg *common.Message) error {
+		received <- msg
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.Start(ctx)
+
+	var msg *common.Message
+	select {
+	case msg = <-received:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for handler")
+	}
+	c.Close()
+	assert.Empty(t, commits, "the consume loop must not commit")
+
+	assert.NoError(t, c.Commit(context.Background(), msg))
+	committed := <-commits
+	assert.Len(t, committed, 1)
+	assert.Equal(t, 2, committed[0].Partition)
+	assert.Equal(t, int64(41), committed[0].Offset)
+}
+
 func TestProcessMessage_RetrySuccess(t *testing.T) {
 	c := &Consumer{
 		config: ConsumerConfig{
@@ -200,3 +256,58 @@ func TestProcessMessage_RetryExhausted(t *testing.T) {
 	assert.NoError(t, err)
 	// But logged error
 }
+
+func TestProcessMessage_DeadLetterJoinsTrace(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	var dead kafka.Message
+	dlq := newTestProducer(&mockKafkaWriter{
+		writeFunc: func(ctx context.Context, msgs ...kafka.Message) error {
+			dead = msgs[0]
+			return nil
+		},
+	})
+	c := &Consumer{
+		config: ConsumerConfig{
+			RetryConfig: RetryConfig{
+				MaxRetries:      1,
+				RetryBackoff:    time.Millisecond,
+				DeadLetterTopic: "patent.new.dlq",
+			},
+		},
+		metrics:            &ConsumerMetrics{},
+		logger:             newMockLogger(),
+		deadLetterProducer: dlq,
+	}
+
+	// Simulate the upstream publish.
+	origin := &common.ProducerMessage{Topic: "patent.new", Value: []byte("v")}
+	_, originSpan := tracing.StartPublishSpan(context.Background(), origin)
+	originSpan.End()
+
+	msg := &common.Message{Topic: "patent.new", Value: []byte("v"), Headers: origin.Headers}
+	err := c.processMessage(context.Background(), msg, func(ctx context.Context, msg *common.Message) error {
+		return errors.New("fail")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), c.metrics.MessagesDeadLettered.Load())
+
+	headers := make(map[string]string)
+	for _, h := range dead.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	assert.Equal(t, origin.Headers["traceparent"], headers[tracing.HeaderOriginalTraceparent])
+	assert.NotEqual(t, origin.Headers["traceparent"], headers["traceparent"])
+	assert.Contains(t, headers["traceparent"], originSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "patent.new", headers["original_topic"])
+	// The consumed message keeps the headers it arrived with.
+	assert.NotContains(t, msg.Headers, "original_topic")
+
+	// origin publish, dead-letter publish, consumer receive
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+	receive := spans[2]
+	assert.Equal(t, "patent.new receive", receive.Name)
+	assert.Equal(t, originSpan.SpanContext().SpanID(), receive.Parent.SpanID())
+	assert.Equal(t, receive.SpanContext.SpanID(), spans[1].Parent.SpanID())
+}
@@ -0,0 +1,607 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// DeadLetterSuffix is appended to a topic to name its dead letter topic.
+const DeadLetterSuffix = ".dlq"
+
+// DefaultDLQGroupID is the consumer group that stores the DLQ cursors:
+// messages below a partition's committed offset are resolved (replayed or
+// purged) and no longer reported as pending.
+const DefaultDLQGroupID = "keyip-dlq-admin"
+
+// Headers recorded on dead letters.
+const (
+	HeaderOriginalTopic     = "original_topic"
+	HeaderOriginalPartition = "original_partition"
+	HeaderOriginalOffset    = "original_offset"
+	HeaderErrorMessage      = "error_message"
+	HeaderErrorClass        = "error_class"
+	HeaderFailedAt          = "failed_at"
+	HeaderAttempts          = "attempts"
+	HeaderEventType         = "event_type"
+	HeaderReplayCount       = "dlq_replay_count"
+	HeaderReplayedFrom      = "dlq_replayed_from"
+)
+
+// Error classes recorded in HeaderErrorClass.
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassCanceled   = "canceled"
+	ErrorClassDecode     = "decode"
+	ErrorClassValidation = "validation"
+	ErrorClassNotFound   = "not_found"
+	ErrorClassDependency = "dependency"
+	ErrorClassHandler    = "handler"
+)
+
+// DLQ limits.
+const (
+	DefaultDLQInspectLimit = 20
+	MaxDLQInspectLimit     = 500
+	DefaultDLQReplayRate   = 50.0
+)
+
+// failureHeaders are dropped when a dead letter is replayed; they describe
+// the previous failure, not the message.
+var failureHeaders = []string{
+	HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset,
+	HeaderErrorMessage, HeaderErrorClass, HeaderFailedAt, HeaderAttempts, "error",
+}
+
+// DeadLetterTopic returns the dead letter topic for topic.
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterSuffix
+}
+
+// IsDeadLetterTopic reports whether topic holds dead letters, either by the
+// <topic>.dlq convention or as one of the shared dead_letter.* topics.
+func IsDeadLetterTopic(topic string) bool {
+	return strings.HasSuffix(topic, DeadLetterSuffix) || strings.HasPrefix(topic, "dead_letter.")
+}
+
+// ClassifyError maps a handler error to one of the ErrorClass constants, so
+// dead letters can be replayed selectively once the cause is fixed.
+func ClassifyError(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return ""
+	case stderrors.Is(err, context.DeadlineExceeded), errors.IsCode(err, errors.ErrCodeTimeout):
+		return ErrorClassTimeout
+	case stderrors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case stderrors.As(err, &syntaxErr), stderrors.As(err, &typeErr), errors.IsCode(err, errors.ErrCodeSerialization):
+		return ErrorClassDecode
+	case errors.IsValidation(err), errors.IsCode(err, errors.ErrCodeInvalidInput):
+		return ErrorClassValidation
+	case errors.IsNotFound(err):
+		return ErrorClassNotFound
+	case errors.IsCode(err, errors.ErrCodeDatabaseError), errors.IsCode(err, errors.ErrCodeCacheError),
+		errors.IsCode(err, errors.ErrCodeExternalService), errors.IsCode(err, errors.ErrCodeServiceUnavailable):
+		return ErrorClassDependency
+	default:
+		return ErrorClassHandler
+	}
+}
+
+// NewDeadLetterMessage builds the message sent to dlqTopic after msg failed
+// attempts times with err. The original headers are kept, and the failure
+// is recorded in the Header* fields.
+func NewDeadLetterMessage(dlqTopic string, msg *common.Message, err error, attempts int, failedAt time.Time) *common.ProducerMessage {
+	headers := make(map[string]string, len(msg.Headers)+8)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	if tp, ok := msg.Headers["traceparent"]; ok {
+		if _, kept := msg.Headers[tracing.HeaderOriginalTraceparent]; !kept {
+			headers[tracing.HeaderOriginalTraceparent] = tp
+		}
+	}
+	headers[HeaderOriginalTopic] = msg.Topic
+	headers[HeaderOriginalPartition] = strconv.Itoa(msg.Partition)
+	headers[HeaderOriginalOffset] = strconv.FormatInt(msg.Offset, 10)
+	headers[HeaderFailedAt] = failedAt.UTC().Format(time.RFC3339Nano)
+	headers[HeaderAttempts] = strconv.Itoa(attempts)
+	if err != nil {
+		headers[HeaderErrorMessage] = err.Error()
+		headers[HeaderErrorClass] = ClassifyError(err)
+	}
+	if _, ok := headers[HeaderEventType]; !ok {
+		if et := envelopeEventType(msg.Value); et != "" {
+			headers[HeaderEventType] = et
+		}
+	}
+
+	return &common.ProducerMessage{
+		Topic:   dlqTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// DeadLetter is a decoded dead letter message.
+type DeadLetter struct {
+	Topic         string            `json:"topic"`
+	Partition     int               `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Key           string            `json:"key,omitempty"`
+	OriginalTopic string            `json:"original_topic"`
+	EventType     string            `json:"event_type,omitempty"`
+	ErrorClass    string            `json:"error_class,omitempty"`
+	ErrorMessage  string            `json:"error_message,omitempty"`
+	Attempts      int               `json:"attempts,omitempty"`
+	ReplayCount   int               `json:"replay_count"`
+	FailedAt      time.Time         `json:"failed_at"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Payload       string            `json:"payload"`
+
+	msg *common.Message
+}
+
+// ParseDeadLetter decodes msg read from a dead letter topic. Messages
+// dead-lettered before failure headers were recorded fall back to the
+// message timestamp and to the topic name without its .dlq suffix; if the
+// original topic cannot be told, OriginalTopic is empty and Replay skips
+// the message.
+func ParseDeadLetter(msg *common.Message) *DeadLetter {
+	dl := &DeadLetter{
+		Topic:         msg.Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		Key:           string(msg.Key),
+		OriginalTopic: msg.Headers[HeaderOriginalTopic],
+		EventType:     msg.Headers[HeaderEventType],
+		ErrorClass:    msg.Headers[HeaderErrorClass],
+		ErrorMessage:  msg.Headers[HeaderErrorMessage],
+		FailedAt:      msg.Timestamp,
+		Headers:       msg.Headers,
+		Payload:       string(msg.Value),
+		msg:           msg,
+	}
+	if dl.OriginalTopic == "" && strings.HasSuffix(msg.Topic, DeadLetterSuffix) {
+		dl.OriginalTopic = strings.TrimSuffix(msg.Topic, DeadLetterSuffix)
+	}
+	if dl.EventType == "" {
+		dl.EventType = envelopeEventType(msg.Value)
+	}
+	if dl.ErrorMessage == "" {
+		dl.ErrorMessage = msg.Headers["error"]
+	}
+	if t, err := time.Parse(time.RFC3339Nano, msg.Headers[HeaderFailedAt]); err == nil {
+		dl.FailedAt = t
+	}
+	dl.Attempts, _ = strconv.Atoi(msg.Headers[HeaderAttempts])
+	dl.ReplayCount, _ = strconv.Atoi(msg.Headers[HeaderReplayCount])
+	return dl
+}
+
+// replayMessage returns the message that re-publishes dl to its original
+// topic.
+func (dl *DeadLetter) replayMessage() *common.ProducerMessage {
+	headers := make(map[string]string, len(dl.Headers)+2)
+	for k, v := range dl.Headers {
+		headers[k] = v
+	}
+	for _, h := range failureHeaders {
+		delete(headers, h)
+	}
+	headers[HeaderReplayCount] = strconv.Itoa(dl.ReplayCount + 1)
+	headers[HeaderReplayedFrom] = fmt.Sprintf("%s/%d/%d", dl.Topic, dl.Partition, dl.Offset)
+
+	return &common.ProducerMessage{
+		Topic:   dl.OriginalTopic,
+		Key:     dl.msg.Key,
+		Value:   dl.msg.Value,
+		Headers: headers,
+	}
+}
+
+func envelopeEventType(value []byte) string {
+	var env struct {
+		EventType string `json:"event_type"`
+	}
+	if json.Unmarshal(value, &env) != nil {
+		return ""
+	}
+	return env.EventType
+}
+
+// DLQFilter selects dead letters. Empty fields match everything; Since is
+// inclusive and Until exclusive, both compared with the failure time.
+type DLQFilter struct {
+	EventTypes   []string  `json:"event_types,omitempty"`
+	ErrorClasses []string  `json:"error_classes,omitempty"`
+	Since        time.Time `json:"since,omitempty"`
+	Until        time.Time `json:"until,omitempty"`
+}
+
+// Validate checks the time window.
+func (f DLQFilter) Validate() error {
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Until.After(f.Since) {
+		return errors.New(errors.ErrCodeValidation, "until must be after since")
+	}
+	return nil
+}
+
+// Matches reports whether dl passes the filter.
+func (f DLQFilter) Matches(dl *DeadLetter) bool {
+	if len(f.EventTypes) > 0 && !containsString(f.EventTypes, dl.EventType) {
+		return false
+	}
+	if len(f.ErrorClasses) > 0 && !containsString(f.ErrorClasses, dl.ErrorClass) {
+		return false
+	}
+	if !f.Since.IsZero() && dl.FailedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !dl.FailedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DLQTopicStats summarises one dead letter topic.
+type DLQTopicStats struct {
+	Topic         string `json:"topic"`
+	OriginalTopic string `json:"original_topic,omitempty"`
+	Partitions    int    `json:"partitions"`
+	// Total counts messages still retained by the broker; Pending those
+	// past the DLQ cursor.
+	Total   int64 `json:"total"`
+	Pending int64 `json:"pending"`
+}
+
+// ReplayOptions controls Replay.
+type ReplayOptions struct {
+	Filter DLQFilter `json:"filter"`
+	// RatePerSecond caps re-publishing; 0 uses DefaultDLQReplayRate.
+	RatePerSecond float64 `json:"rate_per_second,omitempty"`
+	// Limit stops after this many messages were replayed; 0 means no limit.
+	Limit  int  `json:"limit,omitempty"`
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// ReplayResult reports what Replay did.
+type ReplayResult struct {
+	Topic    string `json:"topic"`
+	Scanned  int    `json:"scanned"`
+	Matched  int    `json:"matched"`
+	Replayed int    `json:"replayed"`
+	// Resolved counts messages the DLQ cursor moved past.
+	Resolved int64 `json:"resolved"`
+	DryRun   bool  `json:"dry_run"`
+}
+
+// PurgeOptions controls Purge.
+type PurgeOptions struct {
+	// Before, when set, purges only the leading messages of each partition
+	// that failed before this time; otherwise every pending message is purged.
+	Before time.Time `json:"before,omitempty"`
+}
+
+// PurgeResult reports what Purge did.
+type PurgeResult struct {
+	Topic  string `json:"topic"`
+	Purged int64  `json:"purged"`
+}
+
+// DLQPartition describes the retained offsets of one partition. LastOffset
+// is the offset the next message will be written at.
+type DLQPartition struct {
+	Partition   int
+	FirstOffset int64
+	LastOffset  int64
+}
+
+// DLQSource reads dead letter topics without joining a consumer group and
+// stores the DLQ cursors.
+type DLQSource interface {
+	ListTopics(ctx context.Context) ([]string, error)
+	Partitions(ctx context.Context, topic string) ([]DLQPartition, error)
+	// Committed returns the cursor of each partition that has one.
+	Committed(ctx context.Context, topic string) (map[int]int64, error)
+	Commit(ctx context.Context, topic string, offsets map[int]int64) error
+	// Read calls fn for the messages in [from, to) of the partition in
+	// offset order until fn returns false.
+	Read(ctx context.Context, topic string, partition int, from, to int64, fn func(*common.Message) bool) error
+	Close() error
+}
+
+// MessagePublisher publishes a single message.
+type MessagePublisher interface {
+	Publish(ctx context.Context, msg *common.ProducerMessage) error
+}
+
+// DLQManager lists, inspects, replays and purges dead letter topics.
+//
+// Kafka cannot delete individual records, so resolution is tracked with a
+// per-partition cursor committed under DefaultDLQGroupID. Replay moves the
+// cursor past a message only when it and every message before it were
+// replayed, so filtered-out messages stay pending; a later replay may send
+// the messages after them again, and HeaderReplayCount lets handlers spot
+// such duplicates. Purge moves the cursor without re-publishing.
+type DLQManager struct {
+	source    DLQSource
+	publisher MessagePublisher
+	logger    logging.Logger
+}
+
+// NewDLQManager creates a DLQManager. publisher is only used by Replay.
+func NewDLQManager(source DLQSource, publisher MessagePublisher, logger logging.Logger) *DLQManager {
+	return &DLQManager{
+		source:    source,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// List returns the dead letter topics with their message counts.
+func (m *DLQManager) List(ctx context.Context) ([]DLQTopicStats, error) {
+	topics, err := m.source.ListTopics(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list topics")
+	}
+
+	stats := make([]DLQTopicStats, 0)
+	for _, topic := range topics {
+		if !IsDeadLetterTopic(topic) {
+			continue
+		}
+		ranges, parts, err := m.pendingRanges(ctx, topic)
+		if err != nil {
+			return nil, err
+		}
+		s := DLQTopicStats{Topic: topic, Partitions: len(parts)}
+		if strings.HasSuffix(topic, DeadLetterSuffix) {
+			s.OriginalTopic = strings.TrimSuffix(topic, DeadLetterSuffix)
+		}
+		for _, p := range parts {
+			s.Total += p.LastOffset - p.FirstOffset
+		}
+		for _, r := range ranges {
+			s.Pending += r.to - r.from
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Topic < stats[j].Topic })
+	return stats, nil
+}
+
+// Inspect returns up to limit pending dead letters of topic that match
+// filter, in partition and offset order. The cursor is not moved.
+func (m *DLQManager) Inspect(ctx context.Context, topic string, filter DLQFilter, limit int) ([]*DeadLetter, error) {
+	if err := validateDLQRequest(topic, filter); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultDLQInspectLimit
+	}
+	if limit > MaxDLQInspectLimit {
+		limit = MaxDLQInspectLimit
+	}
+
+	ranges, _, err := m.pendingRanges(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]*DeadLetter, 0)
+	for _, r := range ranges {
+		err := m.source.Read(ctx, topic, r.partition, r.from, r.to, func(msg *common.Message) bool {
+			if dl := ParseDeadLetter(msg); filter.Matches(dl) {
+				letters = append(letters, dl)
+			}
+			return len(letters) < limit
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read dead letters")
+		}
+		if len(letters) >= limit {
+			break
+		}
+	}
+	return letters, nil
+}
+
+// Replay re-publishes the pending dead letters of topic that match the
+// filter to their original topic, paced to opts.RatePerSecond. Replayed
+// messages carry HeaderReplayCount and HeaderReplayedFrom. On a publish
+// error or cancellation the progress made so far is kept and the error is
+// returned with the partial result.
+func (m *DLQManager) Replay(ctx context.Context, topic string, opts ReplayOptions) (*ReplayResult, error) {
+	if err := validateDLQRequest(topic, opts.Filter); err != nil {
+		return nil, err
+	}
+	if opts.RatePerSecond < 0 || opts.Limit < 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "rate and limit cannot be negative")
+	}
+	rate := opts.RatePerSecond
+	if rate == 0 {
+		rate = DefaultDLQReplayRate
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+
+	ranges, _, err := m.pendingRanges(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{Topic: topic, DryRun: opts.DryRun}
+	cursors := make(map[int]int64)
+	var next time.Time
+	var replayErr error
+
+	for _, r := range ranges {
+		cursor, blocked := r.from, false
+		err := m.source.Read(ctx, topic, r.partition, r.from, r.to, func(msg *common.Message) bool {
+			if opts.Limit > 0 && result.Replayed >= opts.Limit {
+				return false
+			}
+			result.Scanned++
+			dl := ParseDeadLetter(msg)
+			if !opts.Filter.Matches(dl) || dl.OriginalTopic == "" || IsDeadLetterTopic(dl.OriginalTopic) {
+				blocked = true
+				return true
+			}
+			result.Matched++
+			if opts.DryRun {
+				result.Replayed++
+				return true
+			}
+
+			if wait := time.Until(next); wait > 0 {
+				select {
+				case <-ctx.Done():
+					replayErr = ctx.Err()
+					return false
+				case <-time.After(wait):
+				}
+			}
+			next = time.Now().Add(interval)
+
+			if err := m.publisher.Publish(ctx, dl.replayMessage()); err != nil {
+				replayErr = errors.Wrap(err, errors.ErrCodeInternal,
+					fmt.Sprintf("failed to replay %s/%d/%d", topic, msg.Partition, msg.Offset))
+				return false
+			}
+			result.Replayed++
+			if !blocked {
+				cursor = msg.Offset + 1
+			}
+			return true
+		})
+		if err != nil && replayErr == nil {
+			replayErr = errors.Wrap(err, errors.ErrCodeInternal, "failed to read dead letters")
+		}
+		if cursor > r.from {
+			cursors[r.partition] = cursor
+			result.Resolved += cursor - r.from
+		}
+		if replayErr != nil || (opts.Limit > 0 && result.Replayed >= opts.Limit) {
+			break
+		}
+	}
+
+	if len(cursors) > 0 {
+		if err := m.source.Commit(ctx, topic, cursors); err != nil {
+			return result, errors.Wrap(err, errors.ErrCodeInternal, "failed to commit DLQ cursor")
+		}
+	}
+	m.logger.Info("dead letters replayed",
+		logging.String("topic", topic),
+		logging.Int("scanned", result.Scanned),
+		logging.Int("replayed", result.Replayed),
+		logging.Int64("resolved", result.Resolved),
+		logging.Bool("dry_run", opts.DryRun))
+	return result, replayErr
+}
+
+// Purge marks pending dead letters of topic as resolved without replaying
+// them.
+func (m *DLQManager) Purge(ctx context.Context, topic string, opts PurgeOptions) (*PurgeResult, error) {
+	if err := validateDLQRequest(topic, DLQFilter{}); err != nil {
+		return nil, err
+	}
+	ranges, _, err := m.pendingRanges(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PurgeResult{Topic: topic}
+	cursors := make(map[int]int64)
+	for _, r := range ranges {
+		cursor := r.to
+		if !opts.Before.IsZero() {
+			cursor = r.from
+			err := m.source.Read(ctx, topic, r.partition, r.from, r.to, func(msg *common.Message) bool {
+				if !ParseDeadLetter(msg).FailedAt.Before(opts.Before) {
+					return false
+				}
+				cursor = msg.Offset + 1
+				return true
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read dead letters")
+			}
+		}
+		if cursor > r.from {
+			cursors[r.partition] = cursor
+			result.Purged += cursor - r.from
+		}
+	}
+
+	if len(cursors) > 0 {
+		if err := m.source.Commit(ctx, topic, cursors); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to commit DLQ cursor")
+		}
+	}
+	m.logger.Warn("dead letters purged",
+		logging.String("topic", topic),
+		logging.Int64("purged", result.Purged))
+	return result, nil
+}
+
+type dlqRange struct {
+	partition int
+	from, to  int64
+}
+
+// pendingRanges returns the unresolved offsets of each partition of topic.
+func (m *DLQManager) pendingRanges(ctx context.Context, topic string) ([]dlqRange, []DLQPartition, error) {
+	parts, err := m.source.Partitions(ctx, topic)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read partition offsets")
+	}
+	if len(parts) == 0 {
+		return nil, nil, errors.New(errors.ErrCodeNotFound, "dead letter topic not found: "+topic)
+	}
+	committed, err := m.source.Committed(ctx, topic)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read DLQ cursor")
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Partition < parts[j].Partition })
+	ranges := make([]dlqRange, 0, len(parts))
+	for _, p := range parts {
+		from := p.FirstOffset
+		if c, ok := committed[p.Partition]; ok && c > from {
+			from = c
+		}
+		if from < p.LastOffset {
+			ranges = append(ranges, dlqRange{partition: p.Partition, from: from, to: p.LastOffset})
+		}
+	}
+	return ranges, parts, nil
+}
+
+func validateDLQRequest(topic string, filter DLQFilter) error {
+	if !IsDeadLetterTopic(topic) {
+		return errors.New(errors.ErrCodeValidation, "not a dead letter topic: "+topic)
+	}
+	return filter.Validate()
+}
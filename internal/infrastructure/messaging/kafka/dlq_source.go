@@ -0,0 +1,261 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	stderrors "errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// dlqReadIdleTimeout ends a partition read when no message arrives for this
+// long, e.g. because the last offsets of the range are transaction markers.
+const dlqReadIdleTimeout = 5 * time.Second
+
+// KafkaDLQSource implements DLQSource against a Kafka cluster. Partitions
+// are read directly, without joining a consumer group; the cursors are
+// committed as offsets of cfg.GroupID.
+type KafkaDLQSource struct {
+	client  *kafka.Client
+	dialer  *kafka.Dialer
+	brokers []string
+	groupID string
+}
+
+// NewDLQSource creates a KafkaDLQSource. cfg.GroupID defaults to
+// DefaultDLQGroupID; Topics is not used.
+func NewDLQSource(cfg ConsumerConfig) (*KafkaDLQSource, error) {
+	if cfg.GroupID == "" {
+		cfg.GroupID = DefaultDLQGroupID
+	}
+	if err := ValidateConsumerConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	transport := &kafka.Transport{DialTimeout: 10 * time.Second}
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	if cfg.TLSEnabled {
+		tlsConfig := &tls.Config{InsecureSkipVerify: true}
+		if cfg.TLSCertPath != "" {
+			caCert, err := os.ReadFile(cfg.TLSCertPath)
+			if err == nil {
+				caCertPool := x509.NewCertPool()
+				caCertPool.AppendCertsFromPEM(caCert)
+				tlsConfig.RootCAs = caCertPool
+				tlsConfig.InsecureSkipVerify = false
+			}
+		}
+		transport.TLS = tlsConfig
+		dialer.TLS = tlsConfig
+	}
+	if cfg.SASLEnabled {
+		var mech sasl.Mechanism
+		var err error
+		switch cfg.SASLMechanism {
+		case "PLAIN":
+			mech = plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}
+		case "SCRAM-SHA-256":
+			mech, err = scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+		case "SCRAM-SHA-512":
+			mech, err = scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create SASL mechanism")
+		}
+		transport.SASL = mech
+		dialer.SASLMechanism = mech
+	}
+
+	return &KafkaDLQSource{
+		client: &kafka.Client{
+			Addr:      kafka.TCP(cfg.Brokers...),
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+		dialer:  dialer,
+		brokers: cfg.Brokers,
+		groupID: cfg.GroupID,
+	}, nil
+}
+
+// ListTopics returns all non-internal topics.
+func (s *KafkaDLQSource) ListTopics(ctx context.Context) ([]string, error) {
+	resp, err := s.client.Metadata(ctx, &kafka.MetadataRequest{})
+	if err != nil {
+		return nil, err
+	}
+	topics := make([]string, 0, len(resp.Topics))
+	for _, t := range resp.Topics {
+		if t.Error == nil && !t.Internal && !strings.HasPrefix(t.Name, "__") {
+			topics = append(topics, t.Name)
+		}
+	}
+	return topics, nil
+}
+
+// Partitions returns the first and next offsets of each partition. An
+// unknown topic yields no partitions.
+func (s *KafkaDLQSource) Partitions(ctx context.Context, topic string) ([]DLQPartition, error) {
+	meta, err := s.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	var requests []kafka.OffsetRequest
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			if stderrors.Is(t.Error, kafka.UnknownTopicOrPartition) {
+				return nil, nil
+			}
+			return nil, t.Error
+		}
+		for _, p := range t.Partitions {
+			requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+		}
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	offsets, err := s.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics:         map[string][]kafka.OffsetRequest{topic: requests},
+		IsolationLevel: kafka.ReadCommitted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]DLQPartition, 0, len(offsets.Topics[topic]))
+	for _, p := range offsets.Topics[topic] {
+		if p.Error != nil {
+			return nil, p.Error
+		}
+		parts = append(parts, DLQPartition{Partition: p.Partition, FirstOffset: p.FirstOffset, LastOffset: p.LastOffset})
+	}
+	return parts, nil
+}
+
+// Committed returns the cursor of each partition that has one.
+func (s *KafkaDLQSource) Committed(ctx context.Context, topic string) (map[int]int64, error) {
+	parts, err := s.Partitions(ctx, topic)
+	if err != nil || len(parts) == 0 {
+		return nil, err
+	}
+	ids := make([]int, len(parts))
+	for i, p := range parts {
+		ids[i] = p.Partition
+	}
+
+	resp, err := s.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: s.groupID,
+		Topics:  map[string][]int{topic: ids},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	committed := make(map[int]int64)
+	for _, p := range resp.Topics[topic] {
+		if p.Error == nil && p.CommittedOffset >= 0 {
+			committed[p.Partition] = p.CommittedOffset
+		}
+	}
+	return committed, nil
+}
+
+// Commit stores the cursors. The group has no members, so the commit uses
+// the simple-consumer generation.
+func (s *KafkaDLQSource) Commit(ctx context.Context, topic string, offsets map[int]int64) error {
+	commits := make([]kafka.OffsetCommit, 0, len(offsets))
+	for partition, offset := range offsets {
+		commits = append(commits, kafka.OffsetCommit{Partition: partition, Offset: offset})
+	}
+	resp, err := s.client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      s.groupID,
+		GenerationID: -1,
+		Topics:       map[string][]kafka.OffsetCommit{topic: commits},
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range resp.Topics[topic] {
+		if p.Error != nil {
+			return p.Error
+		}
+	}
+	return nil
+}
+
+// Read calls fn for the messages in [from, to) of the partition.
+func (s *KafkaDLQSource) Read(ctx context.Context, topic string, partition int, from, to int64, fn func(*common.Message) bool) error {
+	if from >= to {
+		return nil
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        s.brokers,
+		Topic:          topic,
+		Partition:      partition,
+		Dialer:         s.dialer,
+		MinBytes:       1,
+		MaxBytes:       10 * 1024 * 1024,
+		MaxWait:        time.Second,
+		IsolationLevel: kafka.ReadCommitted,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(from); err != nil {
+		return err
+	}
+
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, dlqReadIdleTimeout)
+		m, err := reader.ReadMessage(readCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && stderrors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return err
+		}
+		if m.Offset >= to {
+			return nil
+		}
+
+		msg := &common.Message{
+			Topic:     m.Topic,
+			Partition: m.Partition,
+			Offset:    m.Offset,
+			Key:       m.Key,
+			Value:     m.Value,
+			Timestamp: m.Time,
+			Headers:   make(map[string]string, len(m.Headers)),
+		}
+		for _, h := range m.Headers {
+			msg.Headers[h.Key] = string(h.Value)
+		}
+		if !fn(msg) || m.Offset+1 >= to {
+			return nil
+		}
+	}
+}
+
+// Close releases idle broker connections.
+func (s *KafkaDLQSource) Close() error {
+	if t, ok := s.client.Transport.(*kafka.Transport); ok {
+		t.CloseIdleConnections()
+	}
+	return nil
+}
+
+var _ DLQSource = (*KafkaDLQSource)(nil)
@@ -0,0 +1,397 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
+	apperrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// fakeDLQSource keeps dead letters in memory. Partition offsets start at
+// first[partition], so retention can be simulated.
+type fakeDLQSource struct {
+	topics     map[string]map[int][]*common.Message
+	first      map[int]int64
+	committed  map[string]map[int]int64
+	commitErr  error
+	commitCall int
+}
+
+func newFakeDLQSource() *fakeDLQSource {
+	return &fakeDLQSource{
+		topics:    make(map[string]map[int][]*common.Message),
+		first:     make(map[int]int64),
+		committed: make(map[string]map[int]int64),
+	}
+}
+
+// add appends a dead letter built the way the consumer builds them.
+func (s *fakeDLQSource) add(topic string, partition int, eventType string, err error, failedAt time.Time) {
+	if s.topics[topic] == nil {
+		s.topics[topic] = make(map[int][]*common.Message)
+	}
+	msgs := s.topics[topic][partition]
+	original := &common.Message{
+		Topic:  topic[:len(topic)-len(DeadLetterSuffix)],
+		Offset: int64(len(msgs)),
+		Key:    []byte("k" + strconv.Itoa(len(msgs))),
+		Value:  []byte(fmt.Sprintf(`{"event_type":%q}`, eventType)),
+	}
+	pm := NewDeadLetterMessage(topic, original, err, 4, failedAt)
+	s.topics[topic][partition] = append(msgs, &common.Message{
+		Topic:     topic,
+		Partition: partition,
+		Offset:    s.first[partition] + int64(len(msgs)),
+		Key:       pm.Key,
+		Value:     pm.Value,
+		Headers:   pm.Headers,
+		Timestamp: failedAt,
+	})
+}
+
+func (s *fakeDLQSource) ListTopics(ctx context.Context) ([]string, error) {
+	topics := []string{"patent.new"}
+	for t := range s.topics {
+		topics = append(topics, t)
+	}
+	return topics, nil
+}
+
+func (s *fakeDLQSource) Partitions(ctx context.Context, topic string) ([]DLQPartition, error) {
+	var parts []DLQPartition
+	for p, msgs := range s.topics[topic] {
+		parts = append(parts, DLQPartition{Partition: p, FirstOffset: s.first[p], LastOffset: s.first[p] + int64(len(msgs))})
+	}
+	return parts, nil
+}
+
+func (s *fakeDLQSource) Committed(ctx context.Context, topic string) (map[int]int64, error) {
+	return s.committed[topic], nil
+}
+
+func (s *fakeDLQSource) Commit(ctx context.Context, topic string, offsets map[int]int64) error {
+	s.commitCall++
+	if s.commitErr != nil {
+		return s.commitErr
+	}
+	if s.committed[topic] == nil {
+		s.committed[topic] = make(map[int]int64)
+	}
+	for p, o := range offsets {
+		s.committed[topic][p] = o
+	}
+	return nil
+}
+
+func (s *fakeDLQSource) Read(ctx context.Context, topic string, partition int, from, to int64, fn func(*common.Message) bool) error {
+	for _, msg := range s.topics[topic][partition] {
+		if msg.Offset < from || msg.Offset >= to {
+			continue
+		}
+		if !fn(msg) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *fakeDLQSource) Close() error { return nil }
+
+type fakePublisher struct {
+	published []*common.ProducerMessage
+	times     []time.Time
+	failAfter int
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, msg *common.ProducerMessage) error {
+	if p.failAfter > 0 && len(p.published) >= p.failAfter {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, msg)
+	p.times = append(p.times, time.Now())
+	return nil
+}
+
+var dlqT0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// seedDLQ returns a source with patent.new.dlq holding, on partition 0:
+//
+//	0 patent.created  timeout     t0
+//	1 patent.updated  validation  t0+1h
+//	2 patent.created  timeout     t0+2h
+//
+// and on partition 1 one patent.created timeout at t0+3h.
+func seedDLQ() *fakeDLQSource {
+	src := newFakeDLQSource()
+	src.add("patent.new.dlq", 0, "patent.created", context.DeadlineExceeded, dlqT0)
+	src.add("patent.new.dlq", 0, "patent.updated", apperrors.NewValidation("bad claim"), dlqT0.Add(time.Hour))
+	src.add("patent.new.dlq", 0, "patent.created", context.DeadlineExceeded, dlqT0.Add(2*time.Hour))
+	src.add("patent.new.dlq", 1, "patent.created", context.DeadlineExceeded, dlqT0.Add(3*time.Hour))
+	return src
+}
+
+func TestClassifyError(t *testing.T) {
+	var syntaxErr error = json.Unmarshal([]byte("{"), &struct{}{})
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.DeadlineExceeded, ErrorClassTimeout},
+		{fmt.Errorf("wrapped: %w", context.Canceled), ErrorClassCanceled},
+		{syntaxErr, ErrorClassDecode},
+		{apperrors.NewValidation("bad"), ErrorClassValidation},
+		{apperrors.New(apperrors.ErrCodeNotFound, "gone"), ErrorClassNotFound},
+		{apperrors.New(apperrors.ErrCodeDatabaseError, "pg down"), ErrorClassDependency},
+		{errors.New("boom"), ErrorClassHandler},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestNewDeadLetterMessage_RecordsFailure(t *testing.T) {
+	msg := &common.Message{
+		Topic:     "patent.new",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("k"),
+		Value:     []byte(`{"event_type":"patent.created"}`),
+		Headers:   map[string]string{"traceparent": "00-abc-def-01", "tenant": "t1"},
+	}
+	dl := NewDeadLetterMessage("patent.new.dlq", msg, context.DeadlineExceeded, 4, dlqT0)
+
+	assert.Equal(t, "patent.new.dlq", dl.Topic)
+	assert.Equal(t, msg.Value, dl.Value)
+	assert.Equal(t, "t1", dl.Headers["tenant"])
+	assert.Equal(t, "00-abc-def-01", dl.Headers[tracing.HeaderOriginalTraceparent])
+	assert.Equal(t, "patent.new", dl.Headers[HeaderOriginalTopic])
+	assert.Equal(t, "3", dl.Headers[HeaderOriginalPartition])
+	assert.Equal(t, "42", dl.Headers[HeaderOriginalOffset])
+	assert.Equal(t, ErrorClassTimeout, dl.Headers[HeaderErrorClass])
+	assert.Equal(t, context.DeadlineExceeded.Error(), dl.Headers[HeaderErrorMessage])
+	assert.Equal(t, "4", dl.Headers[HeaderAttempts])
+	assert.Equal(t, "patent.created", dl.Headers[HeaderEventType])
+	assert.NotContains(t, msg.Headers, HeaderOriginalTopic)
+
+	parsed := ParseDeadLetter(&common.Message{Topic: dl.Topic, Value: dl.Value, Headers: dl.Headers})
+	assert.Equal(t, dlqT0, parsed.FailedAt)
+	assert.Equal(t, 4, parsed.Attempts)
+	assert.Equal(t, "patent.new", parsed.OriginalTopic)
+}
+
+func TestParseDeadLetter_LegacyHeaders(t *testing.T) {
+	dl := ParseDeadLetter(&common.Message{
+		Topic:     "molecule.indexed.dlq",
+		Timestamp: dlqT0,
+		Headers:   map[string]string{"error": "boom"},
+	})
+	assert.Equal(t, "molecule.indexed", dl.OriginalTopic)
+	assert.Equal(t, "boom", dl.ErrorMessage)
+	assert.Equal(t, dlqT0, dl.FailedAt)
+
+	shared := ParseDeadLetter(&common.Message{Topic: "dead_letter.patent"})
+	assert.Empty(t, shared.OriginalTopic)
+}
+
+func TestDLQManager_List(t *testing.T) {
+	src := seedDLQ()
+	src.add("dead_letter.patent.dlq", 0, "x", errors.New("boom"), dlqT0)
+	src.committed["patent.new.dlq"] = map[int]int64{0: 1}
+	m := NewDLQManager(src, nil, newMockLogger())
+
+	stats, err := m.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, "patent.new.dlq", stats[1].Topic)
+	assert.Equal(t, "patent.new", stats[1].OriginalTopic)
+	assert.Equal(t, 2, stats[1].Partitions)
+	assert.Equal(t, int64(4), stats[1].Total)
+	assert.Equal(t, int64(3), stats[1].Pending)
+}
+
+func TestDLQManager_Inspect(t *testing.T) {
+	m := NewDLQManager(seedDLQ(), nil, newMockLogger())
+	ctx := context.Background()
+
+	all, err := m.Inspect(ctx, "patent.new.dlq", DLQFilter{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	letters, err := m.Inspect(ctx, "patent.new.dlq", DLQFilter{
+		EventTypes:   []string{"patent.created"},
+		ErrorClasses: []string{ErrorClassTimeout},
+		Since:        dlqT0.Add(30 * time.Minute),
+	}, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, int64(2), letters[0].Offset)
+	assert.Equal(t, 1, letters[1].Partition)
+
+	limited, err := m.Inspect(ctx, "patent.new.dlq", DLQFilter{}, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+}
+
+func TestDLQManager_InspectValidation(t *testing.T) {
+	m := NewDLQManager(seedDLQ(), nil, newMockLogger())
+	ctx := context.Background()
+
+	_, err := m.Inspect(ctx, "patent.new", DLQFilter{}, 0)
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = m.Inspect(ctx, "patent.new.dlq", DLQFilter{Since: dlqT0, Until: dlqT0}, 0)
+	assert.True(t, apperrors.IsValidation(err))
+
+	_, err = m.Inspect(ctx, "unknown.dlq", DLQFilter{}, 0)
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func TestDLQManager_ReplayFiltersAndAdvancesCursorOverPrefix(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	res, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{
+		Filter:        DLQFilter{ErrorClasses: []string{ErrorClassTimeout}},
+		RatePerSecond: 1000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Scanned)
+	assert.Equal(t, 3, res.Matched)
+	assert.Equal(t, 3, res.Replayed)
+	// Offset 1 of partition 0 stays pending, so only offset 0 is resolved
+	// there; partition 1 is fully resolved.
+	assert.Equal(t, int64(2), res.Resolved)
+	assert.Equal(t, map[int]int64{0: 1, 1: 1}, src.committed["patent.new.dlq"])
+
+	require.Len(t, pub.published, 3)
+	first := pub.published[0]
+	assert.Equal(t, "patent.new", first.Topic)
+	assert.Equal(t, []byte("k0"), first.Key)
+	assert.Equal(t, "1", first.Headers[HeaderReplayCount])
+	assert.Equal(t, "patent.new.dlq/0/0", first.Headers[HeaderReplayedFrom])
+	assert.NotContains(t, first.Headers, HeaderErrorMessage)
+	assert.NotContains(t, first.Headers, HeaderOriginalTopic)
+	assert.Equal(t, "patent.created", first.Headers[HeaderEventType])
+}
+
+func TestDLQManager_ReplayDryRunKeepsCursor(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	res, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{
+		Filter: DLQFilter{Until: dlqT0.Add(90 * time.Minute)},
+		DryRun: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, 2, res.Replayed)
+	assert.Empty(t, pub.published)
+	assert.Zero(t, src.commitCall)
+}
+
+func TestDLQManager_ReplayLimit(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	res, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{RatePerSecond: 1000, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Replayed)
+	assert.Equal(t, map[int]int64{0: 2}, src.committed["patent.new.dlq"])
+}
+
+func TestDLQManager_ReplayPublishErrorKeepsProgress(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{failAfter: 1}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	res, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{RatePerSecond: 1000})
+	require.Error(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, 1, res.Replayed)
+	assert.Equal(t, map[int]int64{0: 1}, src.committed["patent.new.dlq"])
+
+	// The next replay resumes after the committed message.
+	pub.failAfter = 0
+	res, err = m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{RatePerSecond: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Replayed)
+	assert.Equal(t, "patent.new.dlq/0/1", pub.published[1].Headers[HeaderReplayedFrom])
+}
+
+func TestDLQManager_ReplayRateLimit(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	_, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{RatePerSecond: 50})
+	require.NoError(t, err)
+	require.Len(t, pub.times, 4)
+	// Four publishes at 50/s need at least three 20ms gaps.
+	assert.GreaterOrEqual(t, pub.times[3].Sub(pub.times[0]), 55*time.Millisecond)
+}
+
+func TestDLQManager_ReplayCanceled(t *testing.T) {
+	src := seedDLQ()
+	pub := &fakePublisher{}
+	m := NewDLQManager(src, pub, newMockLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := m.Replay(ctx, "patent.new.dlq", ReplayOptions{RatePerSecond: 0.5})
+	assert.ErrorIs(t, err, context.Canceled)
+	// The first publish is not delayed; the second waits and is canceled.
+	assert.Equal(t, 1, res.Replayed)
+}
+
+func TestDLQManager_ReplayRejectsNegativeOptions(t *testing.T) {
+	m := NewDLQManager(seedDLQ(), &fakePublisher{}, newMockLogger())
+	_, err := m.Replay(context.Background(), "patent.new.dlq", ReplayOptions{Limit: -1})
+	assert.True(t, apperrors.IsValidation(err))
+}
+
+func TestDLQManager_Purge(t *testing.T) {
+	src := seedDLQ()
+	src.committed["patent.new.dlq"] = map[int]int64{0: 1}
+	m := NewDLQManager(src, nil, newMockLogger())
+
+	res, err := m.Purge(context.Background(), "patent.new.dlq", PurgeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Purged)
+	assert.Equal(t, map[int]int64{0: 3, 1: 1}, src.committed["patent.new.dlq"])
+
+	stats, err := m.List(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, stats[0].Pending)
+}
+
+func TestDLQManager_PurgeBefore(t *testing.T) {
+	src := seedDLQ()
+	m := NewDLQManager(src, nil, newMockLogger())
+
+	res, err := m.Purge(context.Background(), "patent.new.dlq", PurgeOptions{Before: dlqT0.Add(90 * time.Minute)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.Purged)
+	assert.Equal(t, map[int]int64{0: 2}, src.committed["patent.new.dlq"])
+}
+
+func TestDLQManager_PurgeCommitError(t *testing.T) {
+	src := seedDLQ()
+	src.commitErr = errors.New("coordinator unavailable")
+	m := NewDLQManager(src, nil, newMockLogger())
+
+	_, err := m.Purge(context.Background(), "patent.new.dlq", PurgeOptions{})
+	assert.Error(t, err)
+}
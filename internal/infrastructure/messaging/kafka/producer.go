@@ -13,8 +13,10 @@ import (
 	"github.com/segmentio/kafka-go/sasl/plain"
 	"github.com/segmentio/kafka-go/sasl/scram"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/tracing"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -204,10 +206,12 @@ func (p *Producer) Publish(ctx context.Context, msg *common.ProducerMessage) err
 		return errors.New(errors.ErrCodeValidation, "Message too large")
 	}
 
+	ctx, span := tracing.StartPublishSpan(ctx, msg)
 	kMsg := p.toKafkaMessage(msg)
 
 	start := time.Now()
 	err := p.writer.WriteMessages(ctx, kMsg)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		p.metrics.MessagesFailed.Add(1)
 		return errors.Wrap(err, errors.ErrCodeInternal, "publish failed")
@@ -235,16 +239,28 @@ func (p *Producer) PublishBatch(ctx context.Context, msgs []*common.ProducerMess
 		return nil, errors.New(errors.ErrCodeValidation, "Messages empty")
 	}
 
+	// Each message gets its own producer span so consumers can parent on
+	// exactly the publish that produced their message.
+	spans := make([]trace.Span, len(msgs))
 	kMsgs := make([]kafka.Message, len(msgs))
 	for i, msg := range msgs {
+		_, spans[i] = tracing.StartPublishSpan(ctx, msg)
 		kMsgs[i] = p.toKafkaMessage(msg)
 	}
 
 	result := &common.BatchPublishResult{}
 
 	err := p.writer.WriteMessages(ctx, kMsgs...)
+	writeErrs, partial := err.(kafka.WriteErrors)
+	for i, span := range spans {
+		if partial && i < len(writeErrs) {
+			tracing.EndSpan(span, writeErrs[i])
+		} else {
+			tracing.EndSpan(span, err)
+		}
+	}
 	if err != nil {
-		if writeErrs, ok := err.(kafka.WriteErrors); ok {
+		if partial {
 			for i, we := range writeErrs {
 				if we != nil {
 					result.Failed++
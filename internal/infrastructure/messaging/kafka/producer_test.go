@@ -8,6 +8,7 @@ import (
 
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
 )
 
@@ -166,3 +167,31 @@ func TestClose_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, closed)
 }
+
+func TestPublish_InjectsTraceparent(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	var written kafka.Message
+	p := newTestProducer(&mockKafkaWriter{
+		writeFunc: func(ctx context.Context, msgs ...kafka.Message) error {
+			written = msgs[0]
+			return nil
+		},
+	})
+
+	err := p.Publish(context.Background(), newTestProducerMessage("patent.new", "k", "v"))
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "patent.new publish", spans[0].Name)
+
+	var traceparent string
+	for _, h := range written.Headers {
+		if h.Key == "traceparent" {
+			traceparent = string(h.Value)
+		}
+	}
+	assert.Contains(t, traceparent, spans[0].SpanContext.TraceID().String())
+	assert.Contains(t, traceparent, spans[0].SpanContext.SpanID().String())
+}
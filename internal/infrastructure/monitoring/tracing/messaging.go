@@ -0,0 +1,151 @@
+// Phase 11 - 基础设施层: 消息链路追踪
+// 文件: internal/infrastructure/monitoring/tracing/messaging.go
+// 功能定位: 在 Kafka 消息头中传播 W3C traceparent，使生产者、消费者、DLQ 重投递与事件总线处于同一条链路中
+// 核心实现:
+//   - InjectMessage / ExtractMessage: 基于全局 TextMapPropagator 读写消息头
+//   - StartPublishSpan: 生产者 span，并把 span 上下文注入待发送消息
+//   - StartConsumerSpan: 以消息头中的远端上下文为父节点启动消费者 span
+//   - StartBatchConsumerSpan: 批量消费时为每条消息的上下文建立 span link
+//   - ContextWithLogIDs: 把 trace_id/span_id 写入 context，供 Logger.WithContext 输出
+//
+// 依赖关系:
+//   - 依赖: go.opentelemetry.io/otel, monitoring/logging, pkg/types/common
+//   - 被依赖: messaging/kafka, messaging/eventbus, cmd/worker
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+const messagingTracerName = "messaging.kafka"
+
+// Consumer-side operation names used in span names and the
+// messaging.operation attribute.
+const (
+	OperationReceive = "receive"
+	OperationProcess = "process"
+)
+
+// HeaderOriginalTraceparent keeps the traceparent a message carried before it
+// was re-published (e.g. to a dead letter topic), so the original publish can
+// still be found after the header is overwritten by the re-publish span.
+const HeaderOriginalTraceparent = "original_traceparent"
+
+// InjectMessage writes the span context carried by ctx into the message
+// headers using the global propagator.
+func InjectMessage(ctx context.Context, msg *common.ProducerMessage) {
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Headers))
+}
+
+// ExtractMessage returns ctx with the remote span context found in the
+// message headers, if any.
+func ExtractMessage(ctx context.Context, msg *common.Message) context.Context {
+	if len(msg.Headers) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Headers))
+}
+
+// StartPublishSpan starts a producer span for msg and injects it into the
+// message headers, so the consumer continues the same trace. The caller
+// must end the span once the broker acknowledged (or rejected) the write.
+func StartPublishSpan(ctx context.Context, msg *common.ProducerMessage) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(messagingTracerName).Start(ctx, msg.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingMessageBodySize(len(msg.Value)),
+		),
+	)
+	InjectMessage(ctx, msg)
+	return ctx, span
+}
+
+// StartConsumerSpan starts a consumer span whose parent is the span context
+// propagated in the message headers. The returned context also carries the
+// trace and span IDs for structured logging.
+func StartConsumerSpan(ctx context.Context, msg *common.Message, operation string) (context.Context, trace.Span) {
+	ctx = ExtractMessage(ctx, msg)
+	ctx, span := otel.Tracer(messagingTracerName).Start(ctx, msg.Topic+" "+operation,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			attribute.String(string(semconv.MessagingOperationKey), operation),
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaDestinationPartition(msg.Partition),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+		),
+	)
+	return ContextWithLogIDs(ctx), span
+}
+
+// StartBatchConsumerSpan starts one consumer span for a batch of messages.
+// A batch has many producers, so instead of picking one as the parent the
+// span stays in the caller's trace and links to every message's context.
+func StartBatchConsumerSpan(ctx context.Context, topic string, msgs []*common.Message) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(msgs))
+	for _, msg := range msgs {
+		sc := trace.SpanContextFromContext(ExtractMessage(context.Background(), msg))
+		if !sc.IsValid() {
+			continue
+		}
+		links = append(links, trace.Link{
+			SpanContext: sc,
+			Attributes: []attribute.KeyValue{
+				semconv.MessagingKafkaDestinationPartition(msg.Partition),
+				semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+			},
+		})
+	}
+
+	ctx, span := otel.Tracer(messagingTracerName).Start(ctx, topic+" "+OperationProcess,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			attribute.String(string(semconv.MessagingOperationKey), OperationProcess),
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingBatchMessageCount(len(msgs)),
+		),
+	)
+	return ContextWithLogIDs(ctx), span
+}
+
+// EndSpan records err on span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ContextWithLogIDs copies the active span's trace and span IDs into the
+// logging context keys, so Logger.WithContext(ctx) emits them.
+func ContextWithLogIDs(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+	ctx = logging.WithTraceID(ctx, sc.TraceID().String())
+	return logging.WithSpanID(ctx, sc.SpanID().String())
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// publish starts and ends a producer span for topic and returns the headers
+// a consumer would receive.
+func publish(t *testing.T, topic string) (map[string]string, trace.SpanContext) {
+	t.Helper()
+	msg := &common.ProducerMessage{Topic: topic, Value: []byte("{}")}
+	_, span := StartPublishSpan(context.Background(), msg)
+	span.End()
+	require.Contains(t, msg.Headers, "traceparent")
+	return msg.Headers, span.SpanContext()
+}
+
+func TestStartConsumerSpan_ContinuesProducerTrace(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	headers, producer := publish(t, "patent.new")
+	msg := &common.Message{Topic: "patent.new", Partition: 2, Offset: 41, Headers: headers}
+
+	ctx, span := StartConsumerSpan(context.Background(), msg, OperationProcess)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	consumer := spans[1]
+	assert.Equal(t, "patent.new process", consumer.Name)
+	assert.Equal(t, trace.SpanKindConsumer, consumer.SpanKind)
+	assert.Equal(t, producer.TraceID(), consumer.SpanContext.TraceID())
+	assert.Equal(t, producer.SpanID(), consumer.Parent.SpanID())
+	assert.True(t, consumer.Parent.IsRemote())
+
+	assert.Equal(t, producer.TraceID().String(), logging.TraceIDFromContext(ctx))
+	assert.Equal(t, consumer.SpanContext.SpanID().String(), logging.SpanIDFromContext(ctx))
+}
+
+func TestStartConsumerSpan_WithoutHeadersStartsNewTrace(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	_, span := StartConsumerSpan(context.Background(), &common.Message{Topic: "patent.new"}, OperationReceive)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent.IsValid())
+}
+
+func TestStartBatchConsumerSpan_LinksEveryMessage(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	h1, p1 := publish(t, "molecule.indexed")
+	h2, p2 := publish(t, "molecule.indexed")
+	msgs := []*common.Message{
+		{Topic: "molecule.indexed", Offset: 1, Headers: h1},
+		{Topic: "molecule.indexed", Offset: 2, Headers: h2},
+		{Topic: "molecule.indexed", Offset: 3}, // untraced producer
+	}
+
+	_, span := StartBatchConsumerSpan(context.Background(), "molecule.indexed", msgs)
+	span.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	batch := spans[2]
+	assert.False(t, batch.Parent.IsValid(), "a batch must not adopt one producer as parent")
+	require.Len(t, batch.Links, 2)
+	assert.Equal(t, p1.SpanID(), batch.Links[0].SpanContext.SpanID())
+	assert.Equal(t, p2.SpanID(), batch.Links[1].SpanContext.SpanID())
+	assert.NotEqual(t, p1.TraceID(), batch.SpanContext.TraceID())
+}
+
+func TestEndSpan_RecordsError(t *testing.T) {
+	exporter := testutil.InstallInMemoryTracer(t)
+
+	_, span := StartPublishSpan(context.Background(), &common.ProducerMessage{Topic: "alert.trigger"})
+	EndSpan(span, errors.New("broker unavailable"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "broker unavailable", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+}
+
+func TestContextWithLogIDs_NoSpan(t *testing.T) {
+	ctx := ContextWithLogIDs(context.Background())
+	assert.Empty(t, logging.TraceIDFromContext(ctx))
+}
+
+//Personal.AI order the ending
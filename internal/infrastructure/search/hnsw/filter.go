@@ -0,0 +1,457 @@
+package hnsw
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Filter is a compiled boolean expression over entity fields, in the
+// subset of the Milvus expression language that callers here build:
+//
+//	jurisdiction == "CN" && (year >= 2020 or status in ["granted", "pending"])
+//	not (assignee like "Samsung%")
+//
+// Operators are ==, !=, <, <=, >, >=, in, not in and like (% wildcards),
+// combined with &&/and, ||/or and !/not. "id" refers to the primary key.
+// A comparison against a missing or differently typed field is false.
+type Filter struct {
+	root expr
+}
+
+// ParseFilter compiles an expression. An empty expression yields nil,
+// which matches everything.
+func ParseFilter(s string) (*Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return &Filter{root: root}, nil
+}
+
+// Match evaluates the filter against an entity.
+func (f *Filter) Match(id int64, fields map[string]interface{}) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.eval(id, fields)
+}
+
+type expr interface {
+	eval(id int64, fields map[string]interface{}) bool
+}
+
+type andExpr struct{ l, r expr }
+type orExpr struct{ l, r expr }
+type notExpr struct{ x expr }
+
+func (e andExpr) eval(id int64, f map[string]interface{}) bool {
+	return e.l.eval(id, f) && e.r.eval(id, f)
+}
+
+func (e orExpr) eval(id int64, f map[string]interface{}) bool {
+	return e.l.eval(id, f) || e.r.eval(id, f)
+}
+
+func (e notExpr) eval(id int64, f map[string]interface{}) bool {
+	return !e.x.eval(id, f)
+}
+
+// cmpExpr compares a field with one or more literals.
+type cmpExpr struct {
+	field  string
+	op     string
+	values []interface{}
+}
+
+func (e cmpExpr) eval(id int64, fields map[string]interface{}) bool {
+	var v interface{}
+	if e.field == "id" {
+		v = id
+	} else {
+		var ok bool
+		if v, ok = fields[e.field]; !ok || v == nil {
+			return false
+		}
+	}
+
+	switch e.op {
+	case "in", "not in":
+		found := false
+		for _, lit := range e.values {
+			if c, ok := compare(v, lit); ok && c == 0 {
+				found = true
+				break
+			}
+		}
+		if _, comparable := compare(v, e.values[0]); !comparable {
+			return false
+		}
+		return found == (e.op == "in")
+	case "like":
+		s, ok := v.(string)
+		pattern, _ := e.values[0].(string)
+		return ok && like(s, pattern)
+	}
+
+	c, ok := compare(v, e.values[0])
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// compare orders a field value against a literal. Literals are string,
+// float64 or bool; the second result is false when the types differ.
+func compare(v, lit interface{}) (int, bool) {
+	switch l := lit.(type) {
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(s, l), true
+	case bool:
+		b, ok := v.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case b == l:
+			return 0, true
+		case !b:
+			return -1, true
+		default:
+			return 1, true
+		}
+	case float64:
+		n, ok := toFloat(v)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case n < l:
+			return -1, true
+		case n > l:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// like matches s against a pattern where % matches any run of characters.
+func like(s, pattern string) bool {
+	parts := strings.Split(pattern, "%")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, mid := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, mid)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(mid):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+// --- lexer ---
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(s) && rune(s[j]) != c; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				sb.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, filterError(i, "unterminated string")
+			}
+			toks = append(toks, token{tokString, sb.String(), i})
+			i = j + 1
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, s[i:j], i})
+			i = j
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1]))):
+			j := i + 1
+			for j < len(s) && strings.ContainsRune("0123456789.eE+-", rune(s[j])) {
+				if (s[j] == '+' || s[j] == '-') && s[j-1] != 'e' && s[j-1] != 'E' {
+					break
+				}
+				j++
+			}
+			toks = append(toks, token{tokNumber, s[i:j], i})
+			i = j
+		default:
+			if i+1 < len(s) {
+				if two := s[i : i+2]; two == "==" || two == "!=" || two == "<=" || two == ">=" || two == "&&" || two == "||" {
+					toks = append(toks, token{tokPunct, two, i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("()[],<>!", c) {
+				return nil, filterError(i, "unexpected character "+strconv.QuoteRune(c))
+			}
+			toks = append(toks, token{tokPunct, string(c), i})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+func filterError(pos int, msg string) error {
+	return errors.Newf(errors.ErrCodeValidation, "filter expression at %d: %s", pos, msg)
+}
+
+// --- parser ---
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) done() bool { return p.i >= len(p.toks) }
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{kind: tokPunct, text: "end of expression", pos: -1}
+	}
+	return p.toks[p.i]
+}
+
+// keyword reports whether the next token is the given keyword or operator,
+// consuming it if so.
+func (p *parser) keyword(words ...string) bool {
+	t := p.peek()
+	if p.done() || t.kind == tokString || t.kind == tokNumber {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(t.text, w) {
+			p.i++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	pos := p.peek().pos
+	if pos < 0 && len(p.toks) > 0 {
+		last := p.toks[len(p.toks)-1]
+		pos = last.pos + len(last.text)
+	}
+	return errors.Newf(errors.ErrCodeValidation, "filter expression at %d: "+format, append([]interface{}{pos}, args...)...)
+}
+
+func (p *parser) or() (expr, error) {
+	l, err := p.and()
+	for err == nil && p.keyword("||", "or") {
+		var r expr
+		if r, err = p.and(); err == nil {
+			l = orExpr{l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) and() (expr, error) {
+	l, err := p.not()
+	for err == nil && p.keyword("&&", "and") {
+		var r expr
+		if r, err = p.not(); err == nil {
+			l = andExpr{l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) not() (expr, error) {
+	if p.keyword("!", "not") {
+		x, err := p.not()
+		return notExpr{x}, err
+	}
+	if p.keyword("(") {
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, p.errorf("expected )")
+		}
+		return x, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expr, error) {
+	field := p.peek()
+	if p.done() || field.kind != tokIdent {
+		return nil, p.errorf("expected field name")
+	}
+	p.i++
+
+	switch {
+	case p.keyword("in"):
+		values, err := p.list()
+		return cmpExpr{field: field.text, op: "in", values: values}, err
+	case p.keyword("not"):
+		if !p.keyword("in") {
+			return nil, p.errorf("expected in after not")
+		}
+		values, err := p.list()
+		return cmpExpr{field: field.text, op: "not in", values: values}, err
+	case p.keyword("like"):
+		v, err := p.literal()
+		if _, ok := v.(string); err == nil && !ok {
+			err = p.errorf("like needs a string pattern")
+		}
+		return cmpExpr{field: field.text, op: "like", values: []interface{}{v}}, err
+	}
+
+	op := p.peek()
+	if p.done() || op.kind != tokPunct || !strings.Contains("== != < <= > >=", op.text) || op.text == "!" {
+		return nil, p.errorf("expected comparison operator after %s", field.text)
+	}
+	p.i++
+	v, err := p.literal()
+	return cmpExpr{field: field.text, op: op.text, values: []interface{}{v}}, err
+}
+
+func (p *parser) list() ([]interface{}, error) {
+	if !p.keyword("[") {
+		return nil, p.errorf("expected [")
+	}
+	var values []interface{}
+	for {
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if p.keyword("]") {
+			return values, nil
+		}
+		if !p.keyword(",") {
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *parser) literal() (interface{}, error) {
+	t := p.peek()
+	if p.done() {
+		return nil, p.errorf("expected value")
+	}
+	p.i++
+	switch {
+	case t.kind == tokString:
+		return t.text, nil
+	case t.kind == tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, filterError(t.pos, "invalid number "+strconv.Quote(t.text))
+		}
+		return n, nil
+	case t.kind == tokIdent && strings.EqualFold(t.text, "true"):
+		return true, nil
+	case t.kind == tokIdent && strings.EqualFold(t.text, "false"):
+		return false, nil
+	}
+	p.i--
+	return nil, p.errorf("expected value, got %q", t.text)
+}
+
+//Personal.AI order the ending
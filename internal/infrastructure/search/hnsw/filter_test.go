@@ -0,0 +1,87 @@
+package hnsw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func TestParseFilter_Match(t *testing.T) {
+	fields := map[string]interface{}{
+		"jurisdiction": "CN",
+		"year":         int64(2021),
+		"score":        0.82,
+		"granted":      true,
+		"assignee":     "Samsung Display Co., Ltd.",
+	}
+	cases := map[string]bool{
+		`jurisdiction == "CN"`:                                       true,
+		`jurisdiction != 'CN'`:                                       false,
+		`year >= 2020 && year < 2022`:                                true,
+		`year > 2021`:                                                false,
+		`score <= 0.82 and granted == true`:                          true,
+		`jurisdiction in ["US", "CN"]`:                               true,
+		`jurisdiction not in ["US", "CN"]`:                           false,
+		`year in [2019, 2021]`:                                       true,
+		`assignee like "Samsung%"`:                                   true,
+		`assignee like "%Display%Ltd."`:                              true,
+		`assignee like "LG%"`:                                        false,
+		`not (assignee like "LG%")`:                                  true,
+		`!granted == false`:                                          true,
+		`jurisdiction == "US" || year == 2021`:                       true,
+		`jurisdiction == "US" or (year == 2021 && !granted == true)`: false,
+		`id == 7`:              true,
+		`id in [1, 2]`:         false,
+		`missing == "x"`:       false,
+		`not missing == "x"`:   true,
+		`year == "2021"`:       false, // type mismatch
+		`year not in ["2021"]`: false, // type mismatch is never a match
+		`year >= -1e3`:         true,
+	}
+	for expr, want := range cases {
+		f, err := ParseFilter(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, f.Match(7, fields), expr)
+	}
+}
+
+func TestParseFilter_Empty(t *testing.T) {
+	f, err := ParseFilter("  ")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	assert.True(t, f.Match(1, nil))
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	for _, expr := range []string{
+		`year >`,
+		`year = 2020`,
+		`(year > 2020`,
+		`year in 2020`,
+		`year in [2020`,
+		`name == "unterminated`,
+		`name like 3`,
+		`== 3`,
+		`year > 2020 year`,
+		`year not 2020`,
+		`year > 1.2.3`,
+		`year ~ 2`,
+	} {
+		_, err := ParseFilter(expr)
+		assert.True(t, errors.IsValidation(err), expr)
+	}
+}
+
+func TestLike(t *testing.T) {
+	assert.True(t, like("abc", "abc"))
+	assert.True(t, like("abc", "%"))
+	assert.True(t, like("abc", "a%c"))
+	assert.True(t, like("abcbc", "%bc"))
+	assert.False(t, like("abc", "a%b%b"))
+	assert.False(t, like("ab", "ab%b"))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,519 @@
+// Package hnsw is an embedded, pure-Go HNSW (hierarchical navigable small
+// world) vector index. It stands in for Milvus in local and air-gapped
+// deployments: the Searcher exposes the same operations as milvus.Searcher
+// over collections persisted to a directory, with Milvus-style filter
+// expressions evaluated during graph traversal.
+package hnsw
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Config tunes one index.
+type Config struct {
+	// Dim is the vector dimension; for TANIMOTO, the fingerprint length in
+	// bits, a multiple of 8.
+	Dim    int
+	Metric Metric
+	// M is the number of links per node above layer 0; layer 0 keeps 2*M.
+	M int
+	// EfConstruction is the candidate list size while inserting.
+	EfConstruction int
+	// EfSearch is the default candidate list size while searching; it is
+	// raised to k when smaller.
+	EfSearch int
+	// Seed makes level assignment reproducible.
+	Seed int64
+}
+
+func (c *Config) applyDefaults() {
+	if c.Metric == "" {
+		c.Metric = MetricCosine
+	}
+	if c.M <= 0 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+}
+
+func (c Config) validate() error {
+	if c.Dim <= 0 {
+		return errors.New(errors.ErrCodeValidation, "dimension must be > 0")
+	}
+	if c.Metric.binary() && c.Dim%8 != 0 {
+		return errors.Newf(errors.ErrCodeValidation, "TANIMOTO dimension must be a multiple of 8, got %d", c.Dim)
+	}
+	_, err := ParseMetric(string(c.Metric))
+	return err
+}
+
+// compactMinTombstones is the tombstone count below which deletes never
+// trigger a rebuild.
+const compactMinTombstones = 64
+
+// node is one inserted vector. Deleted nodes stay in the graph as routers
+// until the next compaction so that deletes never disconnect it.
+type node struct {
+	id      int64
+	p       *point
+	fields  map[string]interface{}
+	links   [][]int32
+	deleted bool
+}
+
+// Neighbor is one search result.
+type Neighbor struct {
+	ID       int64
+	Distance float32
+	Score    float32
+	Fields   map[string]interface{}
+}
+
+// Index is a single HNSW graph. It is not safe for concurrent writes;
+// concurrent searches are safe when no write is in progress.
+type Index struct {
+	cfg       Config
+	nodes     []*node
+	byID      map[int64]int32
+	entry     int32
+	maxLevel  int
+	deleted   int
+	levelMult float64
+	rng       *rand.Rand
+	visited   sync.Pool
+}
+
+// NewIndex returns an empty index.
+func NewIndex(cfg Config) (*Index, error) {
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return newIndex(cfg), nil
+}
+
+func newIndex(cfg Config) *Index {
+	return &Index{
+		cfg:       cfg,
+		byID:      make(map[int64]int32),
+		entry:     -1,
+		levelMult: 1 / math.Log(float64(cfg.M)),
+		rng:       newRand(cfg.Seed),
+	}
+}
+
+// Config returns the index configuration with defaults applied.
+func (ix *Index) Config() Config { return ix.cfg }
+
+// Len returns the number of live vectors.
+func (ix *Index) Len() int { return len(ix.byID) }
+
+// Contains reports whether id is live in the index.
+func (ix *Index) Contains(id int64) bool {
+	_, ok := ix.byID[id]
+	return ok
+}
+
+// Get returns the stored vector and fields of a live id.
+func (ix *Index) Get(id int64) (interface{}, map[string]interface{}, bool) {
+	i, ok := ix.byID[id]
+	if !ok {
+		return nil, nil, false
+	}
+	n := ix.nodes[i]
+	return ix.cfg.Metric.rawVector(n.p, ix.cfg.Dim), n.fields, true
+}
+
+// Add inserts a vector. It fails with a conflict if id is already live.
+func (ix *Index) Add(id int64, vec interface{}, fields map[string]interface{}) error {
+	if ix.Contains(id) {
+		return errors.Newf(errors.ErrCodeConflict, "id %d already exists", id)
+	}
+	p, err := ix.cfg.Metric.prepare(vec, ix.cfg.Dim)
+	if err != nil {
+		return err
+	}
+	ix.insert(&node{id: id, p: p, fields: fields})
+	return nil
+}
+
+// Upsert inserts a vector, replacing any live vector with the same id.
+func (ix *Index) Upsert(id int64, vec interface{}, fields map[string]interface{}) error {
+	p, err := ix.cfg.Metric.prepare(vec, ix.cfg.Dim)
+	if err != nil {
+		return err
+	}
+	ix.tombstone(id)
+	ix.insert(&node{id: id, p: p, fields: fields})
+	ix.maybeCompact()
+	return nil
+}
+
+// Delete removes ids, returning how many were live.
+func (ix *Index) Delete(ids ...int64) int {
+	removed := 0
+	for _, id := range ids {
+		if ix.tombstone(id) {
+			removed++
+		}
+	}
+	ix.maybeCompact()
+	return removed
+}
+
+func (ix *Index) tombstone(id int64) bool {
+	i, ok := ix.byID[id]
+	if !ok {
+		return false
+	}
+	ix.nodes[i].deleted = true
+	delete(ix.byID, id)
+	ix.deleted++
+	return true
+}
+
+// maybeCompact rebuilds once tombstones outnumber live nodes, which keeps
+// searches from wading through mostly dead neighbourhoods.
+func (ix *Index) maybeCompact() {
+	if ix.deleted >= compactMinTombstones && ix.deleted > len(ix.byID) {
+		ix.Compact()
+	}
+}
+
+// Compact rebuilds the graph from the live nodes, dropping tombstones.
+func (ix *Index) Compact() {
+	if ix.deleted == 0 {
+		return
+	}
+	old := ix.nodes
+	ix.nodes = make([]*node, 0, len(ix.byID))
+	ix.byID = make(map[int64]int32, len(ix.byID))
+	ix.entry, ix.maxLevel, ix.deleted = -1, 0, 0
+	for _, n := range old {
+		if !n.deleted {
+			n.links = nil
+			ix.insert(n)
+		}
+	}
+}
+
+func newRand(seed int64) *rand.Rand { return rand.New(rand.NewSource(seed)) }
+
+func (ix *Index) randomLevel() int {
+	return int(math.Floor(-math.Log(1-ix.rng.Float64()) * ix.levelMult))
+}
+
+func (ix *Index) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * ix.cfg.M
+	}
+	return ix.cfg.M
+}
+
+func (ix *Index) dist(a, b int32) float32 {
+	return ix.cfg.Metric.distance(ix.nodes[a].p, ix.nodes[b].p)
+}
+
+func (ix *Index) insert(n *node) {
+	level := ix.randomLevel()
+	n.links = make([][]int32, level+1)
+	idx := int32(len(ix.nodes))
+	ix.nodes = append(ix.nodes, n)
+	ix.byID[n.id] = idx
+
+	if ix.entry < 0 {
+		ix.entry, ix.maxLevel = idx, level
+		return
+	}
+
+	ep := []candidate{{idx: ix.entry, dist: ix.cfg.Metric.distance(n.p, ix.nodes[ix.entry].p)}}
+	for l := ix.maxLevel; l > level; l-- {
+		ep = ix.searchLayer(n.p, ep, 1, l, nil)
+	}
+	for l := min(level, ix.maxLevel); l >= 0; l-- {
+		found := ix.searchLayer(n.p, ep, ix.cfg.EfConstruction, l, nil)
+		neighbours := ix.selectNeighbours(found, ix.cfg.M)
+		n.links[l] = make([]int32, 0, len(neighbours))
+		for _, c := range neighbours {
+			n.links[l] = append(n.links[l], c.idx)
+			ix.link(c.idx, idx, c.dist, l)
+		}
+		ep = found
+	}
+	if level > ix.maxLevel {
+		ix.entry, ix.maxLevel = idx, level
+	}
+}
+
+// link adds a back-link from a to b at level, pruning a's list with the
+// selection heuristic when it overflows.
+func (ix *Index) link(a, b int32, d float32, level int) {
+	na := ix.nodes[a]
+	na.links[level] = append(na.links[level], b)
+	if len(na.links[level]) <= ix.maxLinks(level) {
+		return
+	}
+	cands := make([]candidate, len(na.links[level]))
+	for i, c := range na.links[level] {
+		if c == b {
+			cands[i] = candidate{idx: c, dist: d}
+		} else {
+			cands[i] = candidate{idx: c, dist: ix.dist(a, c)}
+		}
+	}
+	sortCandidates(cands)
+	kept := ix.selectNeighbours(cands, ix.maxLinks(level))
+	na.links[level] = na.links[level][:0]
+	for _, c := range kept {
+		na.links[level] = append(na.links[level], c.idx)
+	}
+}
+
+// selectNeighbours picks up to m diverse neighbours from candidates sorted
+// nearest first: a candidate is skipped when it is closer to an already
+// selected neighbour than to the base. Skipped candidates fill any
+// remaining slots, so sparse regions keep their degree.
+func (ix *Index) selectNeighbours(cands []candidate, m int) []candidate {
+	if len(cands) <= m {
+		return cands
+	}
+	selected := make([]candidate, 0, m)
+	var pruned []candidate
+	for _, c := range cands {
+		if len(selected) >= m {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if ix.dist(c.idx, s.idx) < c.dist {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			pruned = append(pruned, c)
+		}
+	}
+	for _, c := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// searchLayer is the HNSW beam search over one layer, returning up to ef
+// candidates nearest first. When accept is non-nil only accepted nodes
+// enter the result set, but every node is still expanded, so filtered and
+// deleted nodes keep routing the search.
+func (ix *Index) searchLayer(q *point, entry []candidate, ef, level int, accept func(*node) bool) []candidate {
+	vis := ix.acquireVisited()
+	defer ix.visited.Put(vis)
+
+	frontier := &minHeap{}
+	results := &maxHeap{}
+	for _, e := range entry {
+		vis.visit(e.idx)
+		heap.Push(frontier, e)
+		if accept == nil || accept(ix.nodes[e.idx]) {
+			heap.Push(results, e)
+		}
+	}
+
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if results.Len() >= ef && c.dist > (*results)[0].dist {
+			break
+		}
+		links := ix.nodes[c.idx].links
+		if level >= len(links) {
+			continue
+		}
+		for _, nb := range links[level] {
+			if !vis.visit(nb) {
+				continue
+			}
+			n := ix.nodes[nb]
+			d := ix.cfg.Metric.distance(q, n.p)
+			if results.Len() < ef || d < (*results)[0].dist {
+				heap.Push(frontier, candidate{idx: nb, dist: d})
+				if accept == nil || accept(n) {
+					heap.Push(results, candidate{idx: nb, dist: d})
+					if results.Len() > ef {
+						heap.Pop(results)
+					}
+				}
+			}
+		}
+	}
+
+	out := make([]candidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(candidate)
+	}
+	return out
+}
+
+// Search returns the k nearest live vectors matching filter, nearest
+// first. ef <= 0 uses the configured EfSearch.
+func (ix *Index) Search(vec interface{}, k, ef int, filter *Filter) ([]Neighbor, error) {
+	q, err := ix.cfg.Metric.prepare(vec, ix.cfg.Dim)
+	if err != nil {
+		return nil, err
+	}
+	return ix.search(q, k, ef, filter), nil
+}
+
+func (ix *Index) search(q *point, k, ef int, filter *Filter) []Neighbor {
+	if k <= 0 || ix.entry < 0 || len(ix.byID) == 0 {
+		return nil
+	}
+	if ef <= 0 {
+		ef = ix.cfg.EfSearch
+	}
+	ef = max(ef, k)
+
+	ep := []candidate{{idx: ix.entry, dist: ix.cfg.Metric.distance(q, ix.nodes[ix.entry].p)}}
+	for l := ix.maxLevel; l > 0; l-- {
+		ep = ix.searchLayer(q, ep, 1, l, nil)
+	}
+	accept := func(n *node) bool { return !n.deleted && filter.Match(n.id, n.fields) }
+	found := ix.searchLayer(q, ep, ef, 0, accept)
+
+	// A selective filter can leave the beam short even though matches
+	// exist elsewhere; an exact scan is then both correct and cheap, since
+	// few nodes pass the filter.
+	if len(found) < k && len(found) < len(ix.byID) {
+		return ix.exactSearch(q, k, filter)
+	}
+	return ix.neighbours(found[:min(k, len(found))])
+}
+
+// ExactSearch is the brute-force equivalent of Search, used as the recall
+// baseline and for small or heavily filtered collections.
+func (ix *Index) ExactSearch(vec interface{}, k int, filter *Filter) ([]Neighbor, error) {
+	q, err := ix.cfg.Metric.prepare(vec, ix.cfg.Dim)
+	if err != nil {
+		return nil, err
+	}
+	return ix.exactSearch(q, k, filter), nil
+}
+
+func (ix *Index) exactSearch(q *point, k int, filter *Filter) []Neighbor {
+	if k <= 0 {
+		return nil
+	}
+	results := &maxHeap{}
+	for i, n := range ix.nodes {
+		if n.deleted || !filter.Match(n.id, n.fields) {
+			continue
+		}
+		d := ix.cfg.Metric.distance(q, n.p)
+		if results.Len() < k {
+			heap.Push(results, candidate{idx: int32(i), dist: d})
+		} else if d < (*results)[0].dist {
+			(*results)[0] = candidate{idx: int32(i), dist: d}
+			heap.Fix(results, 0)
+		}
+	}
+	out := make([]candidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(candidate)
+	}
+	return ix.neighbours(out)
+}
+
+func (ix *Index) neighbours(cands []candidate) []Neighbor {
+	out := make([]Neighbor, len(cands))
+	for i, c := range cands {
+		n := ix.nodes[c.idx]
+		out[i] = Neighbor{ID: n.id, Distance: c.dist, Score: ix.cfg.Metric.score(c.dist), Fields: n.fields}
+	}
+	return out
+}
+
+// --- candidate heaps ---
+
+type candidate struct {
+	idx  int32
+	dist float32
+}
+
+func sortCandidates(c []candidate) {
+	sort.Slice(c, func(i, j int) bool { return c[i].dist < c[j].dist })
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// visitedSet marks nodes per search using a generation counter, so reuse
+// from the pool costs nothing until the counter wraps.
+type visitedSet struct {
+	marks []uint32
+	gen   uint32
+}
+
+func (ix *Index) acquireVisited() *visitedSet {
+	v, _ := ix.visited.Get().(*visitedSet)
+	if v == nil {
+		v = &visitedSet{}
+	}
+	if len(v.marks) < len(ix.nodes) {
+		v.marks = make([]uint32, len(ix.nodes)+len(ix.nodes)/4)
+		v.gen = 0
+	}
+	v.gen++
+	if v.gen == 0 {
+		clear(v.marks)
+		v.gen = 1
+	}
+	return v
+}
+
+// visit marks i, reporting whether it was unvisited.
+func (v *visitedSet) visit(i int32) bool {
+	if v.marks[i] == v.gen {
+		return false
+	}
+	v.marks[i] = v.gen
+	return true
+}
+
+//Personal.AI order the ending
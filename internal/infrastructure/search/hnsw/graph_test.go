@@ -0,0 +1,262 @@
+package hnsw
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// clusteredVectors draws n vectors around a few random centres, which is
+// closer to real embeddings than uniform noise and harder for the graph.
+func clusteredVectors(rng *rand.Rand, n, dim, clusters int) [][]float32 {
+	centres := make([][]float32, clusters)
+	for i := range centres {
+		centres[i] = make([]float32, dim)
+		for j := range centres[i] {
+			centres[i][j] = float32(rng.NormFloat64())
+		}
+	}
+	out := make([][]float32, n)
+	for i := range out {
+		c := centres[rng.Intn(clusters)]
+		out[i] = make([]float32, dim)
+		for j := range out[i] {
+			out[i][j] = c[j] + 0.4*float32(rng.NormFloat64())
+		}
+	}
+	return out
+}
+
+// fingerprints draws n bit vectors derived from a few scaffolds by flipping
+// a fraction of bits, mimicking analogue series in a compound library.
+func fingerprints(rng *rand.Rand, n, bits, scaffolds int) [][]byte {
+	bases := make([][]byte, scaffolds)
+	for i := range bases {
+		bases[i] = make([]byte, bits/8)
+		for j := 0; j < bits/8; j++ {
+			bases[i][j] = byte(rng.Intn(256)) & byte(rng.Intn(256)) // ~25% density
+		}
+	}
+	out := make([][]byte, n)
+	for i := range out {
+		fp := append([]byte(nil), bases[rng.Intn(scaffolds)]...)
+		for k := 0; k < bits/10; k++ {
+			b := rng.Intn(bits)
+			fp[b/8] ^= 1 << (b % 8)
+		}
+		out[i] = fp
+	}
+	return out
+}
+
+// recall is the fraction of exact neighbours that the approximate search
+// also returned.
+func recall(exact, approx []Neighbor) float64 {
+	if len(exact) == 0 {
+		return 1
+	}
+	want := make(map[int64]bool, len(exact))
+	for _, n := range exact {
+		want[n.ID] = true
+	}
+	hit := 0
+	for _, n := range approx {
+		if want[n.ID] {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(exact))
+}
+
+func buildIndex(t testing.TB, cfg Config, vectors []interface{}) *Index {
+	t.Helper()
+	ix, err := NewIndex(cfg)
+	require.NoError(t, err)
+	for i, v := range vectors {
+		require.NoError(t, ix.Add(int64(i), v, map[string]interface{}{"bucket": int64(i % 10)}))
+	}
+	return ix
+}
+
+func meanRecall(t testing.TB, ix *Index, queries []interface{}, k int, filter *Filter) float64 {
+	t.Helper()
+	var total float64
+	for _, q := range queries {
+		exact, err := ix.ExactSearch(q, k, filter)
+		require.NoError(t, err)
+		approx, err := ix.Search(q, k, 0, filter)
+		require.NoError(t, err)
+		total += recall(exact, approx)
+	}
+	return total / float64(len(queries))
+}
+
+func TestIndex_Recall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	floats := clusteredVectors(rng, 2000, 32, 20)
+	fps := fingerprints(rng, 2000, 256, 20)
+
+	cases := []struct {
+		metric  Metric
+		dim     int
+		vectors []interface{}
+	}{
+		{MetricCosine, 32, asInterfaces(floats)},
+		{MetricInnerProduct, 32, asInterfaces(floats)},
+		{MetricTanimoto, 256, asInterfaces(fps)},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.metric), func(t *testing.T) {
+			ix := buildIndex(t, Config{Dim: tc.dim, Metric: tc.metric, Seed: 7}, tc.vectors[:1900])
+			got := meanRecall(t, ix, tc.vectors[1900:], 10, nil)
+			assert.GreaterOrEqual(t, got, 0.9, "recall@10")
+		})
+	}
+}
+
+func TestIndex_Scores(t *testing.T) {
+	ix, err := NewIndex(Config{Dim: 16, Metric: MetricTanimoto})
+	require.NoError(t, err)
+	require.NoError(t, ix.Add(1, []byte{0b1111, 0}, nil))
+	require.NoError(t, ix.Add(2, []byte{0b0011, 0b1}, nil))
+
+	hits, err := ix.Search([]byte{0b1111, 0}, 2, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, int64(1), hits[0].ID)
+	assert.InDelta(t, 1.0, hits[0].Score, 1e-6)
+	assert.InDelta(t, 0.4, hits[1].Score, 1e-6) // 2 shared of 5 set
+
+	cos, err := NewIndex(Config{Dim: 2})
+	require.NoError(t, err)
+	require.NoError(t, cos.Add(1, []float64{3, 4}, nil))
+	hits, err = cos.Search([]float32{6, 8}, 1, 0, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, hits[0].Score, 1e-6)
+	assert.InDelta(t, 0.0, hits[0].Distance, 1e-6)
+}
+
+func TestIndex_Validation(t *testing.T) {
+	_, err := NewIndex(Config{Dim: 0})
+	assert.True(t, errors.IsValidation(err))
+	_, err = NewIndex(Config{Dim: 12, Metric: MetricTanimoto})
+	assert.True(t, errors.IsValidation(err))
+	_, err = NewIndex(Config{Dim: 8, Metric: "L2"})
+	assert.True(t, errors.IsValidation(err))
+
+	ix, err := NewIndex(Config{Dim: 3})
+	require.NoError(t, err)
+	assert.True(t, errors.IsValidation(ix.Add(1, []float32{1, 2}, nil)))
+	assert.True(t, errors.IsValidation(ix.Add(1, []float32{0, 0, 0}, nil)))
+	assert.True(t, errors.IsValidation(ix.Add(1, "vector", nil)))
+	require.NoError(t, ix.Add(1, []float32{1, 2, 3}, nil))
+	assert.True(t, errors.IsCode(ix.Add(1, []float32{1, 2, 3}, nil), errors.ErrCodeConflict))
+	_, err = ix.Search([]float32{1}, 1, 0, nil)
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestIndex_DeleteAndUpsert(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	vectors := asInterfaces(clusteredVectors(rng, 500, 16, 5))
+	ix := buildIndex(t, Config{Dim: 16, Seed: 3}, vectors)
+
+	hits, err := ix.Search(vectors[42], 1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), hits[0].ID)
+
+	assert.Equal(t, 1, ix.Delete(42, 9999))
+	assert.False(t, ix.Contains(42))
+	hits, err = ix.Search(vectors[42], 10, 0, nil)
+	require.NoError(t, err)
+	for _, h := range hits {
+		assert.NotEqual(t, int64(42), h.ID)
+	}
+
+	// Upsert moves id 7 onto 42's old vector.
+	require.NoError(t, ix.Upsert(7, vectors[42], map[string]interface{}{"bucket": int64(99)}))
+	hits, err = ix.Search(vectors[42], 1, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), hits[0].ID)
+	assert.Equal(t, int64(99), hits[0].Fields["bucket"])
+	assert.Equal(t, 499, ix.Len())
+
+	// Deleting most of the graph triggers a rebuild without tombstones.
+	var ids []int64
+	for i := int64(100); i < 500; i++ {
+		ids = append(ids, i)
+	}
+	assert.Equal(t, 400, ix.Delete(ids...))
+	assert.Equal(t, 99, ix.Len())
+	assert.Zero(t, ix.deleted)
+	assert.Len(t, ix.nodes, 99)
+	assert.GreaterOrEqual(t, meanRecall(t, ix, vectors[:20], 5, nil), 0.95)
+}
+
+func TestIndex_FilteredSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	vectors := asInterfaces(clusteredVectors(rng, 1500, 24, 10))
+	ix := buildIndex(t, Config{Dim: 24, Seed: 5}, vectors)
+
+	broad, err := ParseFilter("bucket < 5")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, meanRecall(t, ix, vectors[:50], 10, broad), 0.9)
+
+	// One row in a thousand passes: the beam comes back short and the exact
+	// fallback still returns every match.
+	narrow, err := ParseFilter("id in [3, 1003]")
+	require.NoError(t, err)
+	hits, err := ix.Search(vectors[500], 10, 0, narrow)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.ElementsMatch(t, []int64{3, 1003}, []int64{hits[0].ID, hits[1].ID})
+	assert.LessOrEqual(t, hits[0].Distance, hits[1].Distance)
+
+	for _, h := range mustSearch(t, ix, vectors[0], 20, broad) {
+		assert.Less(t, h.Fields["bucket"].(int64), int64(5))
+	}
+}
+
+func TestIndex_WriteRead(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	vectors := asInterfaces(clusteredVectors(rng, 300, 8, 4))
+	ix := buildIndex(t, Config{Dim: 8, Metric: MetricInnerProduct, Seed: 8}, vectors)
+	ix.Delete(0, 1, 2)
+
+	var buf bytes.Buffer
+	_, err := ix.WriteTo(&buf)
+	require.NoError(t, err)
+	loaded, err := ReadIndex(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, ix.Config(), loaded.Config())
+	assert.Equal(t, 297, loaded.Len())
+	for _, q := range vectors[:10] {
+		assert.Equal(t, mustSearch(t, ix, q, 5, nil), mustSearch(t, loaded, q, 5, nil))
+	}
+	require.NoError(t, loaded.Add(1000, vectors[0], nil))
+
+	_, err = ReadIndex(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+}
+
+func mustSearch(t testing.TB, ix *Index, q interface{}, k int, filter *Filter) []Neighbor {
+	t.Helper()
+	hits, err := ix.Search(q, k, 0, filter)
+	require.NoError(t, err)
+	return hits
+}
+
+func asInterfaces[T any](in []T) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,102 @@
+package hnsw
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// Benchmarks compare HNSW search against the exact scan on the same data
+// and report recall@10 next to latency:
+//
+//	go test -run '^$' -bench . ./internal/infrastructure/search/hnsw/
+const (
+	benchVectors = 20000
+	benchQueries = 200
+	benchK       = 10
+)
+
+type benchFixture struct {
+	ix      *Index
+	queries []interface{}
+}
+
+var (
+	benchOnce     sync.Map // Metric -> *sync.Once
+	benchFixtures sync.Map // Metric -> *benchFixture
+)
+
+func loadBenchFixture(b *testing.B, metric Metric) *benchFixture {
+	b.Helper()
+	once, _ := benchOnce.LoadOrStore(metric, &sync.Once{})
+	once.(*sync.Once).Do(func() {
+		rng := rand.New(rand.NewSource(42))
+		var vectors []interface{}
+		dim := 128
+		if metric == MetricTanimoto {
+			dim = 2048
+			vectors = asInterfaces(fingerprints(rng, benchVectors+benchQueries, dim, 200))
+		} else {
+			vectors = asInterfaces(clusteredVectors(rng, benchVectors+benchQueries, dim, 100))
+		}
+		ix := buildIndex(b, Config{Dim: dim, Metric: metric, Seed: 1}, vectors[:benchVectors])
+		benchFixtures.Store(metric, &benchFixture{ix: ix, queries: vectors[benchVectors:]})
+	})
+	f, _ := benchFixtures.Load(metric)
+	return f.(*benchFixture)
+}
+
+func benchmarkSearch(b *testing.B, metric Metric, exact bool) {
+	f := loadBenchFixture(b, metric)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := f.queries[i%len(f.queries)]
+		if exact {
+			_, _ = f.ix.ExactSearch(q, benchK, nil)
+		} else {
+			_, _ = f.ix.Search(q, benchK, 0, nil)
+		}
+	}
+	b.StopTimer()
+	if !exact {
+		b.ReportMetric(meanRecall(b, f.ix, f.queries, benchK, nil), "recall@10")
+	}
+}
+
+func BenchmarkSearch_HNSW_Cosine(b *testing.B)         { benchmarkSearch(b, MetricCosine, false) }
+func BenchmarkSearch_BruteForce_Cosine(b *testing.B)   { benchmarkSearch(b, MetricCosine, true) }
+func BenchmarkSearch_HNSW_IP(b *testing.B)             { benchmarkSearch(b, MetricInnerProduct, false) }
+func BenchmarkSearch_BruteForce_IP(b *testing.B)       { benchmarkSearch(b, MetricInnerProduct, true) }
+func BenchmarkSearch_HNSW_Tanimoto(b *testing.B)       { benchmarkSearch(b, MetricTanimoto, false) }
+func BenchmarkSearch_BruteForce_Tanimoto(b *testing.B) { benchmarkSearch(b, MetricTanimoto, true) }
+
+func BenchmarkSearch_HNSW_Filtered(b *testing.B) {
+	f := loadBenchFixture(b, MetricCosine)
+	filter, err := ParseFilter("bucket in [1, 2]")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = f.ix.Search(f.queries[i%len(f.queries)], benchK, 0, filter)
+	}
+	b.StopTimer()
+	b.ReportMetric(meanRecall(b, f.ix, f.queries, benchK, filter), "recall@10")
+}
+
+func BenchmarkInsert(b *testing.B) {
+	rng := rand.New(rand.NewSource(3))
+	vectors := clusteredVectors(rng, b.N, 128, 100)
+	ix, err := NewIndex(Config{Dim: 128, Seed: 1})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ix.Add(int64(i), vectors[i], nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,180 @@
+package hnsw
+
+import (
+	"math"
+	"math/bits"
+	"strings"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// Metric is a similarity metric. The names match Milvus metric types so
+// requests can be routed to either backend unchanged.
+type Metric string
+
+const (
+	// MetricCosine compares float vectors by angle. Vectors are normalised
+	// on insert.
+	MetricCosine Metric = "COSINE"
+	// MetricInnerProduct compares float vectors by dot product.
+	MetricInnerProduct Metric = "IP"
+	// MetricTanimoto compares bit vectors (molecular fingerprints) by
+	// |a∧b| / |a∨b|.
+	MetricTanimoto Metric = "TANIMOTO"
+)
+
+// ParseMetric parses a metric name. JACCARD is accepted for TANIMOTO, to
+// which it is identical on bit vectors; an empty name is COSINE.
+func ParseMetric(s string) (Metric, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", string(MetricCosine):
+		return MetricCosine, nil
+	case string(MetricInnerProduct):
+		return MetricInnerProduct, nil
+	case string(MetricTanimoto), "JACCARD":
+		return MetricTanimoto, nil
+	default:
+		return "", errors.Newf(errors.ErrCodeValidation, "unsupported metric %q", s)
+	}
+}
+
+// binary reports whether the metric works on bit vectors.
+func (m Metric) binary() bool { return m == MetricTanimoto }
+
+// point is a prepared vector: normalised floats, or packed bits with their
+// population count.
+type point struct {
+	vec  []float32
+	bits []uint64
+	pop  int
+}
+
+// distance returns a dissimilarity where smaller is closer. The graph
+// only needs an ordering; score converts it back to a similarity.
+func (m Metric) distance(a, b *point) float32 {
+	switch m {
+	case MetricTanimoto:
+		var and int
+		for i := range a.bits {
+			and += bits.OnesCount64(a.bits[i] & b.bits[i])
+		}
+		union := a.pop + b.pop - and
+		if union == 0 {
+			return 1
+		}
+		return 1 - float32(and)/float32(union)
+	case MetricInnerProduct:
+		return -dot(a.vec, b.vec)
+	default:
+		return 1 - dot(a.vec, b.vec)
+	}
+}
+
+// score converts a distance to the similarity reported in hits: cosine
+// similarity, inner product or Tanimoto coefficient.
+func (m Metric) score(d float32) float32 {
+	if m == MetricInnerProduct {
+		return -d
+	}
+	return 1 - d
+}
+
+func popcount(w uint64) int { return bits.OnesCount64(w) }
+
+func dot(a, b []float32) float32 {
+	var s0, s1, s2, s3 float32
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for i := n; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// prepare converts a raw vector into a point for the metric. Float metrics
+// take []float32 or []float64; Tanimoto takes packed []byte (as Milvus
+// binary vectors) or one float per bit, non-zero meaning set.
+func (m Metric) prepare(raw interface{}, dim int) (*point, error) {
+	if m.binary() {
+		return prepareBits(raw, dim)
+	}
+
+	var vec []float32
+	switch v := raw.(type) {
+	case []float32:
+		vec = append([]float32(nil), v...)
+	case []float64:
+		vec = make([]float32, len(v))
+		for i, x := range v {
+			vec[i] = float32(x)
+		}
+	default:
+		return nil, errors.Newf(errors.ErrCodeValidation, "%s vectors must be []float32 or []float64, got %T", m, raw)
+	}
+	if len(vec) != dim {
+		return nil, errors.Newf(errors.ErrCodeValidation, "vector has dimension %d, collection expects %d", len(vec), dim)
+	}
+	if m == MetricCosine {
+		var norm float64
+		for _, x := range vec {
+			norm += float64(x) * float64(x)
+		}
+		if norm == 0 {
+			return nil, errors.New(errors.ErrCodeValidation, "cannot normalise a zero vector for COSINE")
+		}
+		inv := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= inv
+		}
+	}
+	return &point{vec: vec}, nil
+}
+
+func prepareBits(raw interface{}, dim int) (*point, error) {
+	words := make([]uint64, (dim+63)/64)
+	switch v := raw.(type) {
+	case []byte:
+		if len(v)*8 != dim {
+			return nil, errors.Newf(errors.ErrCodeValidation, "fingerprint has %d bits, collection expects %d", len(v)*8, dim)
+		}
+		for i, b := range v {
+			words[i/8] |= uint64(b) << (8 * (i % 8))
+		}
+	case []float32:
+		if len(v) != dim {
+			return nil, errors.Newf(errors.ErrCodeValidation, "fingerprint has %d bits, collection expects %d", len(v), dim)
+		}
+		for i, x := range v {
+			if x != 0 {
+				words[i/64] |= 1 << (i % 64)
+			}
+		}
+	default:
+		return nil, errors.Newf(errors.ErrCodeValidation, "TANIMOTO vectors must be []byte or []float32, got %T", raw)
+	}
+	p := &point{bits: words}
+	for _, w := range words {
+		p.pop += bits.OnesCount64(w)
+	}
+	return p, nil
+}
+
+// rawVector returns the point in the form it was inserted: floats (after
+// normalisation for COSINE) or packed bytes.
+func (m Metric) rawVector(p *point, dim int) interface{} {
+	if !m.binary() {
+		return append([]float32(nil), p.vec...)
+	}
+	out := make([]byte, dim/8)
+	for i := range out {
+		out[i] = byte(p.bits[i/8] >> (8 * (i % 8)))
+	}
+	return out
+}
+
+//Personal.AI order the ending
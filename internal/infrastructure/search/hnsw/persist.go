@@ -0,0 +1,170 @@
+package hnsw
+
+import (
+	"bufio"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const (
+	snapshotMagic   = "KEYIP-HNSW"
+	snapshotVersion = 1
+	// fileExt is the extension of collection files in a Searcher directory.
+	fileExt = ".hnsw"
+)
+
+func init() {
+	// Field values travel as interface{}; gob needs the concrete types
+	// beyond its builtins registered up front.
+	gob.Register(time.Time{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// snapshot is the on-disk form of an Index. Tombstones are dropped on
+// save by compacting first, so every node in a snapshot is live.
+type snapshot struct {
+	Magic    string
+	Version  int
+	Config   Config
+	Entry    int32
+	MaxLevel int
+	Nodes    []snapshotNode
+	// Collection metadata kept alongside the graph by the Searcher.
+	VectorField string
+	NextID      int64
+}
+
+type snapshotNode struct {
+	ID     int64
+	Vec    []float32
+	Bits   []uint64
+	Fields map[string]interface{}
+	Links  [][]int32
+}
+
+// WriteTo serialises the index, compacting it first.
+func (ix *Index) WriteTo(w io.Writer) (int64, error) {
+	return ix.writeSnapshot(w, "", 0)
+}
+
+func (ix *Index) writeSnapshot(w io.Writer, vectorField string, nextID int64) (int64, error) {
+	ix.Compact()
+	s := snapshot{
+		Magic:       snapshotMagic,
+		Version:     snapshotVersion,
+		Config:      ix.cfg,
+		Entry:       ix.entry,
+		MaxLevel:    ix.maxLevel,
+		Nodes:       make([]snapshotNode, len(ix.nodes)),
+		VectorField: vectorField,
+		NextID:      nextID,
+	}
+	for i, n := range ix.nodes {
+		s.Nodes[i] = snapshotNode{ID: n.id, Vec: n.p.vec, Bits: n.p.bits, Fields: n.fields, Links: n.links}
+	}
+	cw := &countingWriter{w: w}
+	if err := gob.NewEncoder(cw).Encode(&s); err != nil {
+		return cw.n, errors.Wrap(err, errors.ErrCodeInternal, "encode HNSW snapshot")
+	}
+	return cw.n, nil
+}
+
+// ReadIndex deserialises an index written by WriteTo.
+func ReadIndex(r io.Reader) (*Index, error) {
+	ix, _, err := readSnapshot(r)
+	return ix, err
+}
+
+func readSnapshot(r io.Reader) (*Index, *snapshot, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "decode HNSW snapshot")
+	}
+	if s.Magic != snapshotMagic {
+		return nil, nil, errors.New(errors.ErrCodeValidation, "not an HNSW snapshot")
+	}
+	if s.Version != snapshotVersion {
+		return nil, nil, errors.Newf(errors.ErrCodeValidation, "unsupported HNSW snapshot version %d", s.Version)
+	}
+	if err := s.Config.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	ix := newIndex(s.Config)
+	// Continue the level sequence rather than replaying the seed, which
+	// would skew levels of nodes added after a reload.
+	ix.rng = newRand(s.Config.Seed + int64(len(s.Nodes)))
+	ix.entry, ix.maxLevel = s.Entry, s.MaxLevel
+	ix.nodes = make([]*node, len(s.Nodes))
+	for i, sn := range s.Nodes {
+		p := &point{vec: sn.Vec, bits: sn.Bits}
+		for _, w := range sn.Bits {
+			p.pop += popcount(w)
+		}
+		for _, links := range sn.Links {
+			for _, l := range links {
+				if l < 0 || int(l) >= len(s.Nodes) {
+					return nil, nil, errors.Newf(errors.ErrCodeValidation, "HNSW snapshot node %d links to %d of %d", sn.ID, l, len(s.Nodes))
+				}
+			}
+		}
+		ix.nodes[i] = &node{id: sn.ID, p: p, fields: sn.Fields, links: sn.Links}
+		ix.byID[sn.ID] = int32(i)
+	}
+	if len(ix.nodes) == 0 {
+		ix.entry = -1
+	} else if ix.entry < 0 || int(ix.entry) >= len(ix.nodes) {
+		return nil, nil, errors.New(errors.ErrCodeValidation, "HNSW snapshot entry point out of range")
+	}
+	return ix, &s, nil
+}
+
+// writeFileAtomic writes through a temp file and renames it into place so
+// a crash mid-save leaves the previous snapshot intact.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "create HNSW snapshot file")
+	}
+	defer os.Remove(tmp.Name())
+
+	bw := bufio.NewWriter(tmp)
+	if err := write(bw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, errors.ErrCodeInternal, "write HNSW snapshot file")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, errors.ErrCodeInternal, "sync HNSW snapshot file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "close HNSW snapshot file")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "replace HNSW snapshot file")
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+//Personal.AI order the ending
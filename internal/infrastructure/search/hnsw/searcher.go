@@ -0,0 +1,698 @@
+package hnsw
+
+import (
+	"context"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// SearcherConfig holds configuration for the Searcher.
+type SearcherConfig struct {
+	// Dir holds one <collection>.hnsw file per collection. Empty keeps
+	// everything in memory.
+	Dir            string
+	M              int
+	EfConstruction int
+	EfSearch       int
+	DefaultTopK    int
+	MaxTopK        int
+}
+
+// Reranker fuses per-request hits in HybridSearch. milvus.RRFReranker and
+// milvus.WeightedReranker satisfy it.
+type Reranker interface {
+	Rerank(results [][]common.VectorHit, topK int) []common.VectorHit
+}
+
+// collection is one index plus the Milvus-side metadata the Searcher API
+// needs: the vector field name and the next auto-assigned primary key.
+type collection struct {
+	mu          sync.RWMutex
+	name        string
+	vectorField string
+	index       *Index
+	nextID      int64
+	dirty       bool
+}
+
+// Searcher performs vector operations against local HNSW collections. It
+// mirrors milvus.Searcher so callers can swap one for the other. The
+// primary key is the "id" field; rows inserted without one are numbered
+// from 1.
+type Searcher struct {
+	config      SearcherConfig
+	logger      logging.Logger
+	mu          sync.RWMutex
+	collections map[string]*collection
+}
+
+// NewSearcher creates a Searcher, loading any collections saved in
+// cfg.Dir.
+func NewSearcher(cfg SearcherConfig, logger logging.Logger) (*Searcher, error) {
+	if cfg.DefaultTopK == 0 {
+		cfg.DefaultTopK = 10
+	}
+	if cfg.MaxTopK == 0 {
+		cfg.MaxTopK = 16384
+	}
+
+	s := &Searcher{
+		config:      cfg,
+		logger:      logger,
+		collections: make(map[string]*collection),
+	}
+	if cfg.Dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create index directory")
+	}
+	paths, err := filepath.Glob(filepath.Join(cfg.Dir, "*"+fileExt))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list index directory")
+	}
+	for _, path := range paths {
+		c, err := loadCollection(path)
+		if err != nil {
+			return nil, errors.Newf(errors.ErrCodeInternal, "failed to load %s: %v", filepath.Base(path), err)
+		}
+		s.collections[c.name] = c
+		logger.Info("Loaded HNSW collection", logging.String("collection", c.name), logging.Int("count", c.index.Len()))
+	}
+	return s, nil
+}
+
+func loadCollection(path string) (*collection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ix, snap, err := readSnapshot(f)
+	if err != nil {
+		return nil, err
+	}
+	return &collection{
+		name:        strings.TrimSuffix(filepath.Base(path), fileExt),
+		vectorField: snap.VectorField,
+		index:       ix,
+		nextID:      snap.NextID,
+	}, nil
+}
+
+// CreateCollection creates an empty collection. metric is a Milvus metric
+// type name (COSINE, IP, TANIMOTO or JACCARD); dim is in bits for TANIMOTO.
+func (s *Searcher) CreateCollection(ctx context.Context, name, vectorField string, dim int, metric string) error {
+	if err := validateCollectionName(name); err != nil {
+		return err
+	}
+	if vectorField == "" {
+		return errors.New(errors.ErrCodeValidation, "VectorFieldName is required")
+	}
+	m, err := ParseMetric(metric)
+	if err != nil {
+		return err
+	}
+	ix, err := NewIndex(Config{
+		Dim:            dim,
+		Metric:         m,
+		M:              s.config.M,
+		EfConstruction: s.config.EfConstruction,
+		EfSearch:       s.config.EfSearch,
+		Seed:           time.Now().UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; ok {
+		return errors.Newf(errors.ErrCodeConflict, "collection %s already exists", name)
+	}
+	s.collections[name] = &collection{name: name, vectorField: vectorField, index: ix, nextID: 1, dirty: true}
+	s.logger.Info("Created HNSW collection", logging.String("collection", name), logging.Int("dim", dim), logging.String("metric", string(m)))
+	return nil
+}
+
+// EnsureCollection creates the collection unless it exists, in which case
+// its vector field, dimension and metric must match.
+func (s *Searcher) EnsureCollection(ctx context.Context, name, vectorField string, dim int, metric string) error {
+	err := s.CreateCollection(ctx, name, vectorField, dim, metric)
+	if !errors.IsCode(err, errors.ErrCodeConflict) {
+		return err
+	}
+	m, err := ParseMetric(metric)
+	if err != nil {
+		return err
+	}
+	c, err := s.collection(name)
+	if err != nil {
+		return err
+	}
+	cfg := c.index.Config()
+	if c.vectorField != vectorField || cfg.Dim != dim || cfg.Metric != m {
+		return errors.Newf(errors.ErrCodeValidation, "collection %s exists as %s %d-dim %s", name, c.vectorField, cfg.Dim, cfg.Metric)
+	}
+	return nil
+}
+
+// HasCollection reports whether the collection exists.
+func (s *Searcher) HasCollection(ctx context.Context, name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.collections[name]
+	return ok
+}
+
+// DropCollection removes the collection and its file.
+func (s *Searcher) DropCollection(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; !ok {
+		return errors.Newf(errors.ErrCodeNotFound, "collection %s not found", name)
+	}
+	delete(s.collections, name)
+	if s.config.Dir != "" {
+		if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to remove collection file")
+		}
+	}
+	s.logger.Info("Dropped HNSW collection", logging.String("collection", name))
+	return nil
+}
+
+func validateCollectionName(name string) error {
+	if name == "" {
+		return errors.New(errors.ErrCodeValidation, "CollectionName is required")
+	}
+	for _, r := range name {
+		if r != '_' && r != '-' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return errors.Newf(errors.ErrCodeValidation, "invalid collection name %q", name)
+		}
+	}
+	return nil
+}
+
+func (s *Searcher) collection(name string) (*collection, error) {
+	if name == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "CollectionName is required")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, errors.Newf(errors.ErrCodeNotFound, "collection %s not found", name)
+	}
+	return c, nil
+}
+
+func (s *Searcher) path(name string) string {
+	return filepath.Join(s.config.Dir, name+fileExt)
+}
+
+// Insert inserts rows. Each row carries the collection's vector field, an
+// optional int64 "id" and any scalar fields to filter and return.
+func (s *Searcher) Insert(ctx context.Context, req common.InsertRequest) (*common.InsertResult, error) {
+	return s.write(ctx, req, false)
+}
+
+// Upsert inserts rows, replacing existing rows with the same id.
+func (s *Searcher) Upsert(ctx context.Context, req common.InsertRequest) (*common.InsertResult, error) {
+	return s.write(ctx, req, true)
+}
+
+type row struct {
+	id     int64
+	vec    interface{}
+	fields map[string]interface{}
+}
+
+func (s *Searcher) write(ctx context.Context, req common.InsertRequest, upsert bool) (*common.InsertResult, error) {
+	c, err := s.collection(req.CollectionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "Data is empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Validate the whole batch before touching the graph so a bad row
+	// leaves the collection unchanged.
+	rows := make([]row, len(req.Data))
+	seen := make(map[int64]bool, len(req.Data))
+	nextID := c.nextID
+	for i, data := range req.Data {
+		r := row{fields: make(map[string]interface{}, len(data))}
+		for k, v := range data {
+			switch k {
+			case "id":
+			case c.vectorField:
+				r.vec = v
+			default:
+				r.fields[k] = v
+			}
+		}
+		if r.vec == nil {
+			return nil, errors.Newf(errors.ErrCodeValidation, "row %d: missing vector field %s", i, c.vectorField)
+		}
+		if raw, ok := data["id"]; ok {
+			if r.id, err = toID(raw); err != nil {
+				return nil, errors.Newf(errors.ErrCodeValidation, "row %d: %s", i, message(err))
+			}
+		} else {
+			for c.index.Contains(nextID) || seen[nextID] {
+				nextID++
+			}
+			r.id = nextID
+		}
+		if seen[r.id] {
+			return nil, errors.Newf(errors.ErrCodeValidation, "row %d: duplicate id %d in batch", i, r.id)
+		}
+		if !upsert && c.index.Contains(r.id) {
+			return nil, errors.Newf(errors.ErrCodeConflict, "row %d: id %d already exists", i, r.id)
+		}
+		if _, err := c.index.cfg.Metric.prepare(r.vec, c.index.cfg.Dim); err != nil {
+			return nil, errors.Newf(errors.ErrCodeValidation, "row %d: %s", i, message(err))
+		}
+		seen[r.id] = true
+		nextID = max(nextID, r.id+1)
+		rows[i] = r
+	}
+
+	result := &common.InsertResult{IDs: make([]int64, 0, len(rows))}
+	for _, r := range rows {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if upsert {
+			err = c.index.Upsert(r.id, r.vec, r.fields)
+		} else {
+			err = c.index.Add(r.id, r.vec, r.fields)
+		}
+		if err != nil {
+			return result, err
+		}
+		c.dirty = true
+		c.nextID = max(c.nextID, r.id+1)
+		result.IDs = append(result.IDs, r.id)
+		result.InsertedCount++
+	}
+
+	s.logger.Info("Inserted entities", logging.String("collection", req.CollectionName), logging.Int64("count", result.InsertedCount))
+	return result, nil
+}
+
+func toID(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case float64:
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			return int64(n), nil
+		}
+	case string:
+		if id, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return id, nil
+		}
+	}
+	return 0, errors.Newf(errors.ErrCodeValidation, "id must be an int64, got %T %v", v, v)
+}
+
+// message returns an error's text without the "[CODE]" prefix, for
+// re-wrapping with row or vector context.
+func message(err error) string {
+	if ae, ok := err.(*errors.AppError); ok {
+		return ae.Message
+	}
+	return err.Error()
+}
+
+// Delete deletes rows by ID. Unknown IDs are ignored, as in Milvus.
+func (s *Searcher) Delete(ctx context.Context, collectionName string, ids []int64) error {
+	if len(ids) == 0 {
+		return errors.New(errors.ErrCodeValidation, "IDs cannot be empty")
+	}
+	c, err := s.collection(collectionName)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	removed := c.index.Delete(ids...)
+	c.dirty = c.dirty || removed > 0
+	c.mu.Unlock()
+
+	s.logger.Info("Deleted entities", logging.String("collection", collectionName), logging.Int("count", removed))
+	return nil
+}
+
+// Search executes a vector search. The filter expression is evaluated
+// during graph traversal. SearchParams may set "ef".
+func (s *Searcher) Search(ctx context.Context, req common.VectorSearchRequest) (*common.VectorSearchResult, error) {
+	if req.CollectionName == "" || req.VectorFieldName == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "CollectionName and VectorFieldName required")
+	}
+	if len(req.Vectors) == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "Vectors cannot be empty")
+	}
+	if req.TopK <= 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "TopK must be > 0")
+	}
+	if req.TopK > s.config.MaxTopK {
+		req.TopK = s.config.MaxTopK
+	}
+
+	c, err := s.collection(req.CollectionName)
+	if err != nil {
+		return nil, err
+	}
+	if req.VectorFieldName != c.vectorField {
+		return nil, errors.Newf(errors.ErrCodeValidation, "collection %s has no vector field %s", c.name, req.VectorFieldName)
+	}
+	metric := c.index.Config().Metric
+	if req.MetricType != "" {
+		if m, err := ParseMetric(req.MetricType); err != nil || m != metric {
+			return nil, errors.Newf(errors.ErrCodeValidation, "collection %s is indexed with %s, not %s", c.name, metric, req.MetricType)
+		}
+	}
+	filter, err := ParseFilter(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	ef, err := searchEf(req.SearchParams)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := &common.VectorSearchResult{Results: make([][]common.VectorHit, len(req.Vectors))}
+	for i, vec := range req.Vectors {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		neighbours, err := c.index.Search(vec, req.TopK, ef, filter)
+		if err != nil {
+			return nil, errors.Newf(errors.ErrCodeSimilaritySearchFailed, "search failed for vector %d: %s", i, message(err))
+		}
+		result.Results[i] = toHits(neighbours, req.OutputFields, c.vectorField)
+	}
+	result.TookMs = time.Since(start).Milliseconds()
+
+	s.logger.Debug("Vector search executed",
+		logging.String("collection", req.CollectionName),
+		logging.Int("hits", len(result.Results[0])))
+	return result, nil
+}
+
+func searchEf(params map[string]interface{}) (int, error) {
+	raw, ok := params["ef"]
+	if !ok {
+		return 0, nil
+	}
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, nil
+		}
+	}
+	return 0, errors.Newf(errors.ErrCodeValidation, "invalid search param ef %v", raw)
+}
+
+// toHits converts neighbours to hits, copying only the requested output
+// fields; "*" selects every scalar field.
+func toHits(neighbours []Neighbor, outputFields []string, vectorField string) []common.VectorHit {
+	hits := make([]common.VectorHit, len(neighbours))
+	for i, n := range neighbours {
+		hits[i] = common.VectorHit{ID: n.ID, Score: n.Score, Distance: n.Distance}
+		if len(outputFields) > 0 {
+			hits[i].Fields = selectFields(n.ID, n.Fields, outputFields, vectorField)
+		}
+	}
+	return hits
+}
+
+func selectFields(id int64, fields map[string]interface{}, outputFields []string, vectorField string) map[string]interface{} {
+	out := make(map[string]interface{}, len(outputFields))
+	for _, f := range outputFields {
+		switch f {
+		case "*":
+			for k, v := range fields {
+				out[k] = v
+			}
+		case "id":
+			out["id"] = id
+		case vectorField:
+			// Vectors are only returned by GetEntityByIDs.
+		default:
+			if v, ok := fields[f]; ok {
+				out[f] = v
+			}
+		}
+	}
+	return out
+}
+
+// HybridSearch runs several searches over one collection and fuses the
+// hits for each query vector with the reranker.
+func (s *Searcher) HybridSearch(ctx context.Context, collectionName string, requests []common.VectorSearchRequest, reranker Reranker, topK int) (*common.VectorSearchResult, error) {
+	if len(requests) == 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "requests cannot be empty")
+	}
+	batchSize := len(requests[0].Vectors)
+	for _, req := range requests {
+		if len(req.Vectors) != batchSize {
+			return nil, errors.New(errors.ErrCodeValidation, "batch size mismatch in hybrid search")
+		}
+	}
+
+	start := time.Now()
+	resultsPerRequest := make([][][]common.VectorHit, len(requests))
+	for i, req := range requests {
+		req.CollectionName = collectionName
+		// Fetch more candidates than needed so fusion has overlap to work with.
+		req.TopK = topK * 2
+		res, err := s.Search(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resultsPerRequest[i] = res.Results
+	}
+
+	fused := make([][]common.VectorHit, batchSize)
+	for i := range fused {
+		queryResults := make([][]common.VectorHit, len(requests))
+		for j := range requests {
+			queryResults[j] = resultsPerRequest[j][i]
+		}
+		fused[i] = reranker.Rerank(queryResults, topK)
+	}
+	return &common.VectorSearchResult{Results: fused, TookMs: time.Since(start).Milliseconds()}, nil
+}
+
+// SearchByID finds entities similar to a stored one. The entity itself is
+// included in the hits, as with Milvus.
+func (s *Searcher) SearchByID(ctx context.Context, collectionName string, vectorFieldName string, id int64, topK int, filters string, outputFields []string) ([]common.VectorHit, error) {
+	c, err := s.collection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	vec, _, ok := c.index.Get(id)
+	c.mu.RUnlock()
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "entity not found")
+	}
+
+	var query []float32
+	switch v := vec.(type) {
+	case []float32:
+		query = v
+	case []byte:
+		// Unpack to one float per bit, the form Search accepts for TANIMOTO.
+		query = make([]float32, len(v)*8)
+		for i := range query {
+			if v[i/8]&(1<<(i%8)) != 0 {
+				query[i] = 1
+			}
+		}
+	}
+
+	res, err := s.Search(ctx, common.VectorSearchRequest{
+		CollectionName:  collectionName,
+		VectorFieldName: vectorFieldName,
+		Vectors:         [][]float32{query},
+		TopK:            topK,
+		Filters:         filters,
+		OutputFields:    outputFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Results[0], nil
+}
+
+// BatchSearch runs independent searches. A failed request yields a nil
+// result without failing the others.
+func (s *Searcher) BatchSearch(ctx context.Context, requests []common.VectorSearchRequest) ([]*common.VectorSearchResult, error) {
+	results := make([]*common.VectorSearchResult, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		i, req := i, req
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := s.Search(ctx, req)
+			if err != nil {
+				s.logger.Warn("Batch search sub-request failed", logging.Error(err))
+				return
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetEntityByIDs returns the stored rows for the IDs that exist, in the
+// order requested. Empty outputFields returns every scalar field; the
+// vector field is returned only when asked for.
+func (s *Searcher) GetEntityByIDs(ctx context.Context, collectionName string, ids []int64, outputFields []string) ([]map[string]interface{}, error) {
+	c, err := s.collection(collectionName)
+	if err != nil {
+		return nil, err
+	}
+	if len(outputFields) == 0 {
+		outputFields = []string{"*"}
+	}
+	wantVector := false
+	for _, f := range outputFields {
+		wantVector = wantVector || f == c.vectorField
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rows := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		vec, fields, ok := c.index.Get(id)
+		if !ok {
+			continue
+		}
+		row := selectFields(id, fields, outputFields, c.vectorField)
+		row["id"] = id
+		if wantVector {
+			row[c.vectorField] = vec
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// GetEntityCount returns the number of live rows.
+func (s *Searcher) GetEntityCount(ctx context.Context, collectionName string) (int64, error) {
+	c, err := s.collection(collectionName)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return int64(c.index.Len()), nil
+}
+
+// Compact rebuilds a collection's graph without its deleted rows.
+func (s *Searcher) Compact(ctx context.Context, collectionName string) error {
+	c, err := s.collection(collectionName)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.index.Compact()
+	return nil
+}
+
+// Save writes every collection changed since the last save to Dir. It is
+// a no-op for an in-memory Searcher.
+func (s *Searcher) Save(ctx context.Context) error {
+	if s.config.Dir == "" {
+		return nil
+	}
+	s.mu.RLock()
+	names := make([]string, 0, len(s.collections))
+	for name := range s.collections {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c, err := s.collection(name)
+		if err != nil {
+			continue // dropped concurrently
+		}
+		if err := s.saveCollection(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Searcher) saveCollection(c *collection) error {
+	// Saving compacts the index, so it takes the write lock.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	err := writeFileAtomic(s.path(c.name), func(w io.Writer) error {
+		_, err := c.index.writeSnapshot(w, c.vectorField, c.nextID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	c.dirty = false
+	s.logger.Info("Saved HNSW collection", logging.String("collection", c.name), logging.Int("count", c.index.Len()))
+	return nil
+}
+
+// Close saves pending changes.
+func (s *Searcher) Close() error {
+	return s.Save(context.Background())
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,269 @@
+package hnsw
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+const testCollection = "patent_embeddings"
+
+func newTestSearcher(t *testing.T, dir string) *Searcher {
+	t.Helper()
+	s, err := NewSearcher(SearcherConfig{Dir: dir}, logging.NewNopLogger())
+	require.NoError(t, err)
+	return s
+}
+
+func seedPatents(t *testing.T, s *Searcher) {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, s.CreateCollection(ctx, testCollection, "embedding", 3, "COSINE"))
+	res, err := s.Insert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data: []map[string]interface{}{
+			{"id": int64(10), "embedding": []float32{1, 0, 0}, "jurisdiction": "CN", "year": 2021},
+			{"embedding": []float32{0.9, 0.1, 0}, "jurisdiction": "US", "year": 2019},
+			{"embedding": []float64{0, 1, 0}, "jurisdiction": "CN", "year": 2018},
+			{"id": 2.0, "embedding": []float32{0, 0, 1}, "jurisdiction": "EP", "year": 2022},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.InsertedCount)
+	assert.Equal(t, []int64{10, 11, 12, 2}, res.IDs)
+}
+
+func TestSearcher_Search(t *testing.T) {
+	s := newTestSearcher(t, "")
+	seedPatents(t, s)
+	ctx := context.Background()
+
+	res, err := s.Search(ctx, common.VectorSearchRequest{
+		CollectionName:  testCollection,
+		VectorFieldName: "embedding",
+		Vectors:         [][]float32{{1, 0, 0}, {0, 0, 1}},
+		TopK:            2,
+		MetricType:      "COSINE",
+		OutputFields:    []string{"jurisdiction", "id"},
+		SearchParams:    map[string]interface{}{"ef": 32},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Results, 2)
+	require.Len(t, res.Results[0], 2)
+	assert.Equal(t, int64(10), res.Results[0][0].ID)
+	assert.InDelta(t, 1.0, res.Results[0][0].Score, 1e-6)
+	assert.Equal(t, map[string]interface{}{"jurisdiction": "CN", "id": int64(10)}, res.Results[0][0].Fields)
+	assert.Equal(t, int64(11), res.Results[0][1].ID)
+	assert.Equal(t, int64(2), res.Results[1][0].ID)
+
+	res, err = s.Search(ctx, common.VectorSearchRequest{
+		CollectionName:  testCollection,
+		VectorFieldName: "embedding",
+		Vectors:         [][]float32{{1, 0, 0}},
+		TopK:            10,
+		Filters:         `jurisdiction == "CN" && year >= 2018`,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Results[0], 2)
+	assert.Equal(t, int64(10), res.Results[0][0].ID)
+	assert.Equal(t, int64(12), res.Results[0][1].ID)
+	assert.Nil(t, res.Results[0][0].Fields)
+}
+
+func TestSearcher_SearchValidation(t *testing.T) {
+	s := newTestSearcher(t, "")
+	seedPatents(t, s)
+	ctx := context.Background()
+	base := common.VectorSearchRequest{
+		CollectionName:  testCollection,
+		VectorFieldName: "embedding",
+		Vectors:         [][]float32{{1, 0, 0}},
+		TopK:            1,
+	}
+
+	cases := map[string]func(r *common.VectorSearchRequest){
+		"no collection": func(r *common.VectorSearchRequest) { r.CollectionName = "" },
+		"no vectors":    func(r *common.VectorSearchRequest) { r.Vectors = nil },
+		"topk":          func(r *common.VectorSearchRequest) { r.TopK = 0 },
+		"field":         func(r *common.VectorSearchRequest) { r.VectorFieldName = "title_vec" },
+		"metric":        func(r *common.VectorSearchRequest) { r.MetricType = "IP" },
+		"filter":        func(r *common.VectorSearchRequest) { r.Filters = "year >" },
+		"ef":            func(r *common.VectorSearchRequest) { r.SearchParams = map[string]interface{}{"ef": "wide"} },
+	}
+	for name, mutate := range cases {
+		req := base
+		mutate(&req)
+		_, err := s.Search(ctx, req)
+		assert.True(t, errors.IsValidation(err), name)
+	}
+
+	req := base
+	req.Vectors = [][]float32{{1, 0}}
+	_, err := s.Search(ctx, req)
+	assert.True(t, errors.IsCode(err, errors.ErrCodeSimilaritySearchFailed))
+
+	req = base
+	req.CollectionName = "missing"
+	_, err = s.Search(ctx, req)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSearcher_Writes(t *testing.T) {
+	s := newTestSearcher(t, "")
+	seedPatents(t, s)
+	ctx := context.Background()
+
+	// A bad row rejects the whole batch.
+	_, err := s.Insert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data: []map[string]interface{}{
+			{"embedding": []float32{1, 1, 0}},
+			{"embedding": []float32{1, 1}},
+		},
+	})
+	assert.True(t, errors.IsValidation(err))
+	_, err = s.Insert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data:           []map[string]interface{}{{"id": int64(10), "embedding": []float32{1, 1, 0}}},
+	})
+	assert.True(t, errors.IsCode(err, errors.ErrCodeConflict))
+	_, err = s.Insert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data:           []map[string]interface{}{{"id": "x", "embedding": []float32{1, 1, 0}}},
+	})
+	assert.True(t, errors.IsValidation(err))
+	count, err := s.GetEntityCount(ctx, testCollection)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	_, err = s.Upsert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data:           []map[string]interface{}{{"id": int64(10), "embedding": []float32{0, 1, 0.1}, "jurisdiction": "JP"}},
+	})
+	require.NoError(t, err)
+	hits, err := s.SearchByID(ctx, testCollection, "embedding", 12, 2, "", []string{"*"})
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, int64(12), hits[0].ID)
+	assert.Equal(t, int64(10), hits[1].ID)
+	assert.Equal(t, "JP", hits[1].Fields["jurisdiction"])
+
+	require.NoError(t, s.Delete(ctx, testCollection, []int64{12, 404}))
+	_, err = s.SearchByID(ctx, testCollection, "embedding", 12, 2, "", nil)
+	assert.True(t, errors.IsNotFound(err))
+	assert.True(t, errors.IsValidation(s.Delete(ctx, testCollection, nil)))
+
+	rows, err := s.GetEntityByIDs(ctx, testCollection, []int64{2, 12, 10}, []string{"embedding", "year"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, map[string]interface{}{"id": int64(2), "year": 2022, "embedding": []float32{0, 0, 1}}, rows[0])
+	assert.Equal(t, int64(10), rows[1]["id"])
+	assert.NotContains(t, rows[1], "year")
+}
+
+func TestSearcher_Tanimoto(t *testing.T) {
+	s := newTestSearcher(t, "")
+	ctx := context.Background()
+	require.NoError(t, s.CreateCollection(ctx, "fingerprints", "fp", 16, "JACCARD"))
+	_, err := s.Insert(ctx, common.InsertRequest{
+		CollectionName: "fingerprints",
+		Data: []map[string]interface{}{
+			{"id": int64(1), "fp": []byte{0b1111, 0}},
+			{"id": int64(2), "fp": []byte{0b0011, 0b1}},
+		},
+	})
+	require.NoError(t, err)
+
+	hits, err := s.SearchByID(ctx, "fingerprints", "fp", 1, 2, "", nil)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.InDelta(t, 1.0, hits[0].Score, 1e-6)
+	assert.InDelta(t, 0.4, hits[1].Score, 1e-6)
+}
+
+func TestSearcher_Collections(t *testing.T) {
+	s := newTestSearcher(t, "")
+	ctx := context.Background()
+	assert.True(t, errors.IsValidation(s.CreateCollection(ctx, "../etc", "v", 4, "")))
+	assert.True(t, errors.IsValidation(s.CreateCollection(ctx, "c", "", 4, "")))
+	assert.True(t, errors.IsValidation(s.CreateCollection(ctx, "c", "v", 4, "HAMMING")))
+
+	require.NoError(t, s.EnsureCollection(ctx, "c", "v", 4, ""))
+	require.NoError(t, s.EnsureCollection(ctx, "c", "v", 4, "COSINE"))
+	assert.True(t, errors.IsValidation(s.EnsureCollection(ctx, "c", "v", 8, "COSINE")))
+	assert.True(t, errors.IsCode(s.CreateCollection(ctx, "c", "v", 4, ""), errors.ErrCodeConflict))
+	assert.True(t, s.HasCollection(ctx, "c"))
+
+	require.NoError(t, s.DropCollection(ctx, "c"))
+	assert.False(t, s.HasCollection(ctx, "c"))
+	assert.True(t, errors.IsNotFound(s.DropCollection(ctx, "c")))
+}
+
+func TestSearcher_BatchAndHybrid(t *testing.T) {
+	s := newTestSearcher(t, "")
+	seedPatents(t, s)
+	ctx := context.Background()
+	good := common.VectorSearchRequest{CollectionName: testCollection, VectorFieldName: "embedding", Vectors: [][]float32{{0, 1, 0}}, TopK: 1}
+	bad := good
+	bad.TopK = 0
+
+	results, err := s.BatchSearch(ctx, []common.VectorSearchRequest{good, bad})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, int64(12), results[0].Results[0][0].ID)
+	assert.Nil(t, results[1])
+
+	fused, err := s.HybridSearch(ctx, testCollection, []common.VectorSearchRequest{good, good}, firstHits{}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), fused.Results[0][0].ID)
+}
+
+type firstHits struct{}
+
+func (firstHits) Rerank(results [][]common.VectorHit, topK int) []common.VectorHit {
+	return results[0][:topK]
+}
+
+func TestSearcher_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestSearcher(t, dir)
+	seedPatents(t, s)
+	ctx := context.Background()
+	require.NoError(t, s.Delete(ctx, testCollection, []int64{11}))
+	require.NoError(t, s.Close())
+	assert.FileExists(t, filepath.Join(dir, testCollection+fileExt))
+
+	reopened := newTestSearcher(t, dir)
+	count, err := reopened.GetEntityCount(ctx, testCollection)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// Auto ids continue after the highest id ever assigned.
+	res, err := reopened.Insert(ctx, common.InsertRequest{
+		CollectionName: testCollection,
+		Data:           []map[string]interface{}{{"embedding": []float32{1, 1, 1}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{13}, res.IDs)
+
+	rows, err := reopened.GetEntityByIDs(ctx, testCollection, []int64{10}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(10), "jurisdiction": "CN", "year": 2021}, rows[0])
+
+	require.NoError(t, reopened.DropCollection(ctx, testCollection))
+	assert.NoFileExists(t, filepath.Join(dir, testCollection+fileExt))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken"+fileExt), []byte("nope"), 0o644))
+	_, err = NewSearcher(SearcherConfig{Dir: dir}, logging.NewNopLogger())
+	assert.Error(t, err)
+}
+
+//Personal.AI order the ending
@@ -379,7 +379,8 @@ func (m *nerModelImpl) invokeBackend(ctx context.Context, tokens []string) ([][]
 	if timeoutDur <= 0 {
 		timeoutDur = 2 * time.Second
 	}
-	ctx2, cancel := context.WithTimeout(ctx, timeoutDur)
+	// Shadow experiments on the NER model mirror this payload.
+	ctx2, cancel := context.WithTimeout(common.ContextWithShadowInput(ctx, payload), timeoutDur)
 	defer cancel()
 
 	resp, err := m.backend.Predict(ctx2, req)
@@ -0,0 +1,1457 @@
+package claim_bert
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ============================================================================
+// Enumerations
+// ============================================================================
+
+// AmendmentKind enumerates the narrowing strategies the amendment engine
+// proposes.
+type AmendmentKind string
+
+const (
+	AmendmentMarkushSubset   AmendmentKind = "MARKUSH_SUBSET"
+	AmendmentRangeTightening AmendmentKind = "RANGE_TIGHTENING"
+	AmendmentAddedFeature    AmendmentKind = "ADDED_FEATURE"
+	AmendmentCombined        AmendmentKind = "COMBINED"
+)
+
+// CoverageStatus states whether a claim reads on an embodiment.
+type CoverageStatus string
+
+const (
+	CoverageCovered    CoverageStatus = "COVERED"
+	CoverageNotCovered CoverageStatus = "NOT_COVERED"
+	// CoverageUnknown means the embodiment does not state the member,
+	// parameter value or feature a limitation depends on.
+	CoverageUnknown CoverageStatus = "UNKNOWN"
+)
+
+// addedFeatureScopeReduction is the share of the remaining scope an added
+// dependent-claim feature is assumed to remove. Unlike Markush and range
+// edits there is no structural measure for it, so it is deliberately
+// pessimistic: deleting list members or tightening a range is preferred
+// over adding a limitation when both clear the same conflicts.
+const addedFeatureScopeReduction = 0.5
+
+// defaultMaxAmendmentCandidates bounds the ranked list when the request
+// does not set MaxCandidates.
+const defaultMaxAmendmentCandidates = 10
+
+// ============================================================================
+// Data structures
+// ============================================================================
+
+// ClaimEmbodiment is a concrete thing a claim may or may not read on: a
+// prior-art disclosure, a third-party molecule from an infringement read, or
+// one of the tenant's own products.
+type ClaimEmbodiment struct {
+	ID string `json:"id"`
+	// Text is the disclosure or product description; limitations not listed
+	// explicitly below are looked up in it.
+	Text   string `json:"text,omitempty"`
+	SMILES string `json:"smiles,omitempty"`
+	// Members lists the Markush alternatives (substituents, compounds) the
+	// embodiment uses.
+	Members []string `json:"members,omitempty"`
+	// Parameters maps a parameter name (or unit, for ranges without a named
+	// parameter) to the embodiment's value, e.g. {"temperature": 150}.
+	Parameters map[string]float64 `json:"parameters,omitempty"`
+}
+
+// AmendmentRequest asks for narrowings of one claim.
+type AmendmentRequest struct {
+	Claim *ParsedClaim `json:"claim"`
+	// ClaimText is the claim as filed. When empty it is rebuilt from the
+	// parsed preamble, transitional phrase and body.
+	ClaimText string `json:"claim_text,omitempty"`
+	// Specification is the description as filed. Every new limitation must
+	// be found in it; nothing else counts as support.
+	Specification string `json:"specification"`
+	// DependentClaims are the other claims as filed; features of those that
+	// depend on Claim are offered as additions.
+	DependentClaims []string `json:"dependent_claims,omitempty"`
+	// CandidateFeatures are further features to try, e.g. proposed by an
+	// attorney. They need specification support like any other.
+	CandidateFeatures []string           `json:"candidate_features,omitempty"`
+	Conflicts         []*ClaimEmbodiment `json:"conflicts"`
+	OwnProducts       []*ClaimEmbodiment `json:"own_products,omitempty"`
+	MaxCandidates     int                `json:"max_candidates,omitempty"`
+}
+
+// AmendmentSupport is a passage of the specification that discloses a new
+// limitation.
+type AmendmentSupport struct {
+	Limitation string `json:"limitation"`
+	Quote      string `json:"quote"`
+}
+
+// ProductCoverage reports whether an amended claim still covers one of the
+// tenant's products.
+type ProductCoverage struct {
+	ProductID string         `json:"product_id"`
+	Status    CoverageStatus `json:"status"`
+	Reason    string         `json:"reason,omitempty"`
+}
+
+// AmendmentCandidate is one proposed narrowing.
+type AmendmentCandidate struct {
+	Rank        int           `json:"rank"`
+	Kind        AmendmentKind `json:"kind"`
+	Description string        `json:"description"`
+	AmendedText string        `json:"amended_text"`
+	// ScopeReduction estimates the fraction of the claimed scope removed:
+	// deleted Markush combinations, the share of a numerical range cut off,
+	// and addedFeatureScopeReduction per added feature.
+	ScopeReduction float64 `json:"scope_reduction"`
+	// BreadthBefore and BreadthAfter are ScopeAnalyzer breadth scores of
+	// the original and amended claim.
+	BreadthBefore      float64             `json:"breadth_before"`
+	BreadthAfter       float64             `json:"breadth_after"`
+	AvoidedConflicts   []string            `json:"avoided_conflicts"`
+	RemainingConflicts []string            `json:"remaining_conflicts,omitempty"`
+	CoversOwnProducts  bool                `json:"covers_own_products"`
+	ProductCoverage    []*ProductCoverage  `json:"product_coverage,omitempty"`
+	Support            []*AmendmentSupport `json:"support"`
+	AmendedClaim       *ParsedClaim        `json:"amended_claim"`
+}
+
+// RejectedAmendment records a narrowing the engine considered but could not
+// propose, typically for lack of specification support.
+type RejectedAmendment struct {
+	Kind        AmendmentKind `json:"kind"`
+	Description string        `json:"description"`
+	Reason      string        `json:"reason"`
+}
+
+// AmendmentSuggestions is the ranked result for one claim.
+type AmendmentSuggestions struct {
+	ClaimNumber int                   `json:"claim_number"`
+	Candidates  []*AmendmentCandidate `json:"candidates"`
+	Rejected    []*RejectedAmendment  `json:"rejected,omitempty"`
+	// AlreadyAvoided lists conflicts the original claim does not read on.
+	AlreadyAvoided []string `json:"already_avoided,omitempty"`
+	// Unresolved lists conflicts that no candidate avoids.
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+// ============================================================================
+// AmendmentEngine Interface
+// ============================================================================
+
+// AmendmentEngine proposes claim amendments that steer clear of conflicting
+// prior art or molecules.
+type AmendmentEngine interface {
+	// SuggestAmendments returns candidate narrowings ranked by whether they
+	// keep the tenant's products covered, how many conflicts they avoid and
+	// how little scope they give up.
+	SuggestAmendments(ctx context.Context, req *AmendmentRequest) (*AmendmentSuggestions, error)
+}
+
+// ============================================================================
+// amendmentEngineImpl
+// ============================================================================
+
+type amendmentEngineImpl struct {
+	scope  ScopeAnalyzer
+	logger common.Logger
+}
+
+// NewAmendmentEngine creates an AmendmentEngine that scores candidates with
+// the given ScopeAnalyzer.
+func NewAmendmentEngine(scope ScopeAnalyzer, logger common.Logger) (AmendmentEngine, error) {
+	if scope == nil {
+		return nil, errors.NewInvalidInputError("scope analyzer is required for amendment suggestions")
+	}
+	if logger == nil {
+		logger = common.NewNoopLogger()
+	}
+	return &amendmentEngineImpl{scope: scope, logger: logger}, nil
+}
+
+// amendmentLimit is one limitation of a (possibly amended) claim, used to
+// decide whether the claim reads on an embodiment.
+type amendmentLimit struct {
+	// Markush limitation: the embodiment must use one of keep, judged
+	// against the members of the original group.
+	group *MarkushGroup
+	keep  []string
+	// Range limitation on the parameter key.
+	key    string
+	lo, hi float64
+	// Feature limitation: the embodiment text must disclose the feature.
+	feature string
+}
+
+// amendmentEdit is one change to the claim; a candidate applies one or more.
+type amendmentEdit struct {
+	kind        AmendmentKind
+	target      string
+	description string
+	limit       *amendmentLimit
+	reduction   float64
+	support     []*AmendmentSupport
+	applyText   func(text string) (string, bool)
+	applyClaim  func(c *ParsedClaim)
+}
+
+// amendmentContext carries the request data shared by the generators.
+type amendmentContext struct {
+	req       *AmendmentRequest
+	text      string
+	sentences []string
+	groups    []*MarkushGroup
+	ranges    []*NumericalRange
+	rejected  []*RejectedAmendment
+}
+
+func (e *amendmentEngineImpl) SuggestAmendments(ctx context.Context, req *AmendmentRequest) (*AmendmentSuggestions, error) {
+	if req == nil || req.Claim == nil {
+		return nil, errors.NewInvalidInputError("claim must not be nil")
+	}
+	if strings.TrimSpace(req.Specification) == "" {
+		return nil, errors.NewInvalidInputError("specification text is required to check support")
+	}
+	if len(req.Conflicts) == 0 {
+		return nil, errors.NewInvalidInputError("at least one conflicting reference or molecule is required")
+	}
+
+	ac := newAmendmentContext(req)
+	result := &AmendmentSuggestions{ClaimNumber: req.Claim.ClaimNumber}
+
+	// Conflicts the original claim does not read on need no amendment.
+	baseline := ac.baselineLimits()
+	var conflicts []*ClaimEmbodiment
+	for _, c := range req.Conflicts {
+		if c == nil {
+			continue
+		}
+		if status, _ := evaluateCoverage(c, baseline); status == CoverageNotCovered {
+			result.AlreadyAvoided = append(result.AlreadyAvoided, c.ID)
+			continue
+		}
+		conflicts = append(conflicts, c)
+	}
+	if len(conflicts) == 0 {
+		return result, nil
+	}
+
+	var edits []*amendmentEdit
+	edits = append(edits, ac.markushEdits(conflicts)...)
+	edits = append(edits, ac.rangeEdits(conflicts)...)
+	edits = append(edits, ac.featureEdits()...)
+
+	breadthBefore, err := e.scope.ComputeScopeBreadth(ctx, req.Claim)
+	if err != nil {
+		return nil, fmt.Errorf("computing breadth: %w", err)
+	}
+
+	var candidates []*AmendmentCandidate
+	for _, ed := range edits {
+		if c := e.buildCandidate(ctx, ac, []*amendmentEdit{ed}, baseline, conflicts, breadthBefore); c != nil {
+			candidates = append(candidates, c)
+		}
+	}
+	if combo := combineEdits(ac, edits, baseline, conflicts); combo != nil {
+		if c := e.buildCandidate(ctx, ac, combo, baseline, conflicts, breadthBefore); c != nil {
+			candidates = append(candidates, c)
+		}
+	}
+
+	rankAmendmentCandidates(candidates)
+	limit := req.MaxCandidates
+	if limit <= 0 {
+		limit = defaultMaxAmendmentCandidates
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	for i, c := range candidates {
+		c.Rank = i + 1
+	}
+	result.Candidates = candidates
+	result.Rejected = ac.rejected
+
+	for _, c := range conflicts {
+		avoided := false
+		for _, cand := range candidates {
+			if containsString(cand.AvoidedConflicts, c.ID) {
+				avoided = true
+				break
+			}
+		}
+		if !avoided {
+			result.Unresolved = append(result.Unresolved, c.ID)
+		}
+	}
+	return result, nil
+}
+
+func newAmendmentContext(req *AmendmentRequest) *amendmentContext {
+	claim := req.Claim
+	text := strings.TrimSpace(req.ClaimText)
+	if text == "" {
+		text = strings.TrimSpace(strings.Join([]string{claim.Preamble, claim.TransitionalPhrase, claim.Body}, " "))
+	}
+	ac := &amendmentContext{req: req, text: text, sentences: splitSpecSentences(req.Specification)}
+
+	ac.groups = claim.MarkushGroups
+	if len(ac.groups) == 0 {
+		ac.groups = extractMarkushGroups(text)
+	}
+
+	// ParseClaim attaches ranges to features; collect them along with any
+	// claim-level ranges, falling back to the claim text.
+	seen := make(map[string]bool)
+	addRange := func(r *NumericalRange) {
+		lo, hi := rangeBounds(r)
+		key := fmt.Sprintf("%s|%g|%g", rangeKey(r), lo, hi)
+		if r != nil && !seen[key] && (r.LowerBound != nil || r.UpperBound != nil || r.Max > r.Min) {
+			seen[key] = true
+			ac.ranges = append(ac.ranges, r)
+		}
+	}
+	for _, r := range claim.NumericalRanges {
+		addRange(r)
+	}
+	for _, f := range claim.Features {
+		if f != nil {
+			for _, r := range f.NumericalRanges {
+				addRange(r)
+			}
+		}
+	}
+	if len(ac.ranges) == 0 {
+		for _, r := range extractNumericalRanges(text) {
+			addRange(r)
+		}
+	}
+	return ac
+}
+
+// baselineLimits describes the claim as filed.
+func (ac *amendmentContext) baselineLimits() []*amendmentLimit {
+	var limits []*amendmentLimit
+	for _, g := range ac.groups {
+		if g != nil && !g.IsOpenEnded && len(g.Members) > 0 {
+			limits = append(limits, &amendmentLimit{group: g, keep: g.Members})
+		}
+	}
+	for _, r := range ac.ranges {
+		lo, hi := rangeBounds(r)
+		limits = append(limits, &amendmentLimit{key: rangeKey(r), lo: lo, hi: hi})
+	}
+	return limits
+}
+
+func (ac *amendmentContext) reject(kind AmendmentKind, description, reason string) {
+	ac.rejected = append(ac.rejected, &RejectedAmendment{Kind: kind, Description: description, Reason: reason})
+}
+
+// ---------------------------------------------------------------------------
+// Markush subsets
+// ---------------------------------------------------------------------------
+
+// markushEdits deletes the members used by conflicts from each Markush
+// group. Open-ended groups are closed at the same time, since deleting an
+// example from "such as" would not narrow anything.
+func (ac *amendmentContext) markushEdits(conflicts []*ClaimEmbodiment) []*amendmentEdit {
+	var edits []*amendmentEdit
+	for gi, g := range ac.groups {
+		if g == nil || len(g.Members) < 2 {
+			continue
+		}
+		hit := make(map[string]bool)
+		for _, c := range conflicts {
+			for _, m := range embodimentMembers(c, g) {
+				hit[m] = true
+			}
+		}
+		if len(hit) == 0 {
+			continue
+		}
+
+		if ed := ac.markushSubset(gi, g, hit, true); ed != nil {
+			edits = append(edits, ed)
+		}
+
+		// Deleting a member the tenant's own products use gives them up.
+		// Offer the subset that spares those members too, leaving the
+		// conflicts that use them to another limitation.
+		spared := make(map[string]bool)
+		for m := range hit {
+			spared[m] = true
+		}
+		for _, p := range ac.req.OwnProducts {
+			if p != nil {
+				for _, m := range embodimentMembers(p, g) {
+					delete(spared, m)
+				}
+			}
+		}
+		if len(spared) > 0 && len(spared) < len(hit) {
+			if ed := ac.markushSubset(gi, g, spared, false); ed != nil {
+				edits = append(edits, ed)
+			}
+		}
+	}
+	return edits
+}
+
+// markushSubset builds the edit deleting drop from group g. Members the
+// specification does not describe are deleted as well, and reported when
+// report is set.
+func (ac *amendmentContext) markushSubset(gi int, g *MarkushGroup, drop map[string]bool, report bool) *amendmentEdit {
+	desc := fmt.Sprintf("Delete %s from %s", strings.Join(sortedKeys(drop), ", "), groupLabel(g))
+	var keep, unsupported []string
+	var support []*AmendmentSupport
+	for _, m := range g.Members {
+		if drop[m] {
+			continue
+		}
+		quote := findSpecSupport(ac.sentences, func(s string) bool { return mentionsTerm(s, m) })
+		if quote == "" {
+			unsupported = append(unsupported, m)
+			continue
+		}
+		support = append(support, &AmendmentSupport{Limitation: m, Quote: quote})
+	}
+	for _, s := range support {
+		keep = append(keep, s.Limitation)
+	}
+	if len(unsupported) > 0 && report {
+		ac.reject(AmendmentMarkushSubset, fmt.Sprintf("Keep %s in %s", strings.Join(unsupported, ", "), groupLabel(g)),
+			"not described in the specification; dropped from the subset")
+	}
+	if len(keep) == 0 {
+		if report {
+			ac.reject(AmendmentMarkushSubset, desc, "no remaining member of the group is supported by the specification")
+		}
+		return nil
+	}
+
+	group, groupIndex, kept := g, gi, keep
+	reduction := 1 - float64(len(kept))/float64(len(g.Members))
+	return &amendmentEdit{
+		kind:        AmendmentMarkushSubset,
+		target:      g.GroupID,
+		description: fmt.Sprintf("Restrict %s to %s", groupLabel(g), strings.Join(kept, ", ")),
+		limit:       &amendmentLimit{group: group, keep: kept},
+		reduction:   reduction,
+		support:     support,
+		applyText: func(text string) (string, bool) {
+			return replaceMarkushMembers(text, group, groupIndex, kept)
+		},
+		applyClaim: func(c *ParsedClaim) {
+			for i, cg := range c.MarkushGroups {
+				if cg == group || (cg != nil && cg.GroupID == group.GroupID) {
+					narrowed := *cg
+					narrowed.Members = kept
+					narrowed.IsOpenEnded = false
+					narrowed.LeadPhrase = "selected from the group consisting of"
+					c.MarkushGroups[i] = &narrowed
+				}
+			}
+		},
+	}
+}
+
+// embodimentMembers returns the members of g an embodiment uses.
+func embodimentMembers(e *ClaimEmbodiment, g *MarkushGroup) []string {
+	var used []string
+	for _, m := range g.Members {
+		found := mentionsTerm(e.Text, m)
+		for _, em := range e.Members {
+			if strings.EqualFold(strings.TrimSpace(em), m) {
+				found = true
+			}
+		}
+		if found {
+			used = append(used, m)
+		}
+	}
+	return used
+}
+
+func groupLabel(g *MarkushGroup) string {
+	if g.ChemicalType != "" {
+		return fmt.Sprintf("Markush group %s (%s)", g.GroupID, g.ChemicalType)
+	}
+	return "Markush group " + g.GroupID
+}
+
+// markushLocations finds the Markush expressions in text, in the order
+// extractMarkushGroups numbers them. Each entry holds the start of the lead
+// phrase, the start and end of the member list, and the end of the text to
+// replace, which excludes any closing punctuation the pattern consumed.
+func markushLocations(text string) [][4]int {
+	var locs [][4]int
+	for _, re := range []*regexp.Regexp{reMarkushClosed, reMarkushOpen, reChineseMarkushClosed, reChineseMarkushOpen} {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			end := m[3]
+			if re == reChineseMarkushClosed {
+				end = m[1] // "组成的组" closes the expression
+			}
+			locs = append(locs, [4]int{m[0], m[2], m[3], end})
+		}
+	}
+	return locs
+}
+
+func replaceMarkushMembers(text string, g *MarkushGroup, index int, keep []string) (string, bool) {
+	locs := markushLocations(text)
+	if n, err := strconv.Atoi(strings.TrimPrefix(g.GroupID, "markush-")); err == nil && n >= 1 && n <= len(locs) {
+		index = n - 1
+	}
+	if index < 0 || index >= len(locs) {
+		return text, false
+	}
+	loc := locs[index]
+	if members := parseMarkushMembers(text[loc[1]:loc[2]]); len(members) != len(g.Members) {
+		return text, false
+	}
+
+	head, tail := text[:loc[0]], text[loc[3]:]
+	chinese := containsHan(text[loc[0]:loc[3]])
+	list := formatMemberList(keep, chinese)
+	switch {
+	case chinese && len(keep) == 1:
+		// "R1选自由甲基、乙基组成的组" → "R1为甲基"
+		return head + "为" + list + tail, true
+	case chinese:
+		return head + "选自由" + list + "组成的组" + tail, true
+	case len(keep) == 1 && g.IsOpenEnded:
+		return head + "which is " + list + tail, true
+	case len(keep) == 1:
+		// "R1 is selected from the group consisting of methyl and ethyl"
+		// → "R1 is methyl"
+		return head + list + tail, true
+	default:
+		return head + "selected from the group consisting of " + list + tail, true
+	}
+}
+
+func formatMemberList(members []string, chinese bool) string {
+	if chinese {
+		if len(members) == 1 {
+			return members[0]
+		}
+		return strings.Join(members[:len(members)-1], "、") + "和" + members[len(members)-1]
+	}
+	switch len(members) {
+	case 1:
+		return members[0]
+	case 2:
+		return members[0] + " and " + members[1]
+	}
+	return strings.Join(members[:len(members)-1], ", ") + " and " + members[len(members)-1]
+}
+
+// ---------------------------------------------------------------------------
+// Range tightening
+// ---------------------------------------------------------------------------
+
+// specEndpoint is a value disclosed for a parameter in the specification.
+type specEndpoint struct {
+	value    float64
+	sentence string
+}
+
+// rangeEdits moves the bounds of each numerical range that a conflict falls
+// inside onto values disclosed in the specification, so that the conflict
+// values are excluded and the tenant's product values kept. The shape of
+// the range (one- or two-sided) is preserved.
+func (ac *amendmentContext) rangeEdits(conflicts []*ClaimEmbodiment) []*amendmentEdit {
+	var edits []*amendmentEdit
+	for _, r := range ac.ranges {
+		key := rangeKey(r)
+		if key == "" {
+			continue
+		}
+		lo, hi := rangeBounds(r)
+		var threats, products []float64
+		for _, c := range conflicts {
+			if v, ok := embodimentParameter(c, key); ok && v >= lo && v <= hi {
+				threats = append(threats, v)
+			}
+		}
+		if len(threats) == 0 {
+			continue
+		}
+		for _, p := range ac.req.OwnProducts {
+			if v, ok := embodimentParameter(p, key); ok && p != nil {
+				products = append(products, v)
+			}
+		}
+
+		label := rangeLabel(key, lo, hi, r.Unit)
+		endpoints := ac.specEndpoints(r, lo, hi)
+		if len(endpoints) == 0 {
+			ac.reject(AmendmentRangeTightening, "Tighten "+label, "the specification discloses no other values for this parameter")
+			continue
+		}
+
+		choices := make([]*rangeChoice, 0, 2)
+		best := bestRangeTightening(lo, hi, endpoints, threats, products)
+		if best == nil {
+			ac.reject(AmendmentRangeTightening, "Tighten "+label,
+				"no sub-range with disclosed end points excludes the conflicting values")
+		} else {
+			choices = append(choices, best)
+		}
+		// Conflicts lying between the tenant's product values cannot be cut
+		// off without losing a product. Offer the range that excludes the
+		// others, leaving those to another limitation.
+		if best == nil || !best.coversProducts {
+			if spared := valuesOutside(threats, products); len(spared) > 0 && len(spared) < len(threats) {
+				if alt := bestRangeTightening(lo, hi, endpoints, spared, products); alt != nil && alt.coversProducts {
+					choices = append(choices, alt)
+				}
+			}
+		}
+		for _, c := range choices {
+			edits = append(edits, rangeEdit(r, key, label, c))
+		}
+	}
+	return edits
+}
+
+// valuesOutside returns the values not within the span of ref.
+func valuesOutside(values, ref []float64) []float64 {
+	if len(ref) == 0 {
+		return nil
+	}
+	lo, hi := ref[0], ref[0]
+	for _, v := range ref {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var out []float64
+	for _, v := range values {
+		if v < lo || v > hi {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// rangeEdit builds the edit moving the bounds of r to those of best.
+func rangeEdit(r *NumericalRange, key, label string, best *rangeChoice) *amendmentEdit {
+	lo, hi := rangeBounds(r)
+	newLo, newHi := best.lo, best.hi
+	var support []*AmendmentSupport
+	if best.pairQuote != "" {
+		support = append(support, &AmendmentSupport{Limitation: rangeLabel(key, newLo, newHi, r.Unit), Quote: best.pairQuote})
+	} else {
+		if newLo != lo {
+			support = append(support, &AmendmentSupport{Limitation: fmt.Sprintf("%s end point %s", key, formatNumber(newLo)), Quote: best.loQuote})
+		}
+		if newHi != hi {
+			support = append(support, &AmendmentSupport{Limitation: fmt.Sprintf("%s end point %s", key, formatNumber(newHi)), Quote: best.hiQuote})
+		}
+	}
+
+	origRange, origLo, origHi := r, lo, hi
+	return &amendmentEdit{
+		kind:        AmendmentRangeTightening,
+		target:      key,
+		description: fmt.Sprintf("Tighten %s to %s", label, rangeLabel(key, newLo, newHi, r.Unit)),
+		limit:       &amendmentLimit{key: key, lo: newLo, hi: newHi},
+		reduction:   best.reduction,
+		support:     support,
+		applyText: func(text string) (string, bool) {
+			return replaceRangeBounds(text, origLo, origHi, newLo, newHi)
+		},
+		applyClaim: func(c *ParsedClaim) {
+			narrowed := *origRange
+			if !math.IsInf(newLo, -1) {
+				v := newLo
+				narrowed.LowerBound = &v
+				narrowed.Min = newLo
+			}
+			if !math.IsInf(newHi, 1) {
+				v := newHi
+				narrowed.UpperBound = &v
+				narrowed.Max = newHi
+			}
+			if narrowed.LowerBound != nil && narrowed.UpperBound != nil {
+				narrowed.Width = narrowed.Max - narrowed.Min
+			}
+			replaced := false
+			for i, cr := range c.NumericalRanges {
+				if cr == origRange {
+					c.NumericalRanges[i], replaced = &narrowed, true
+				}
+			}
+			if !replaced {
+				c.NumericalRanges = append(c.NumericalRanges, &narrowed)
+			}
+		},
+	}
+}
+
+// specEndpoints collects the values the specification states for the same
+// parameter as r, inside [lo, hi]. A value only counts when it is written
+// out literally, so the ±10% band the parser derives for "about X" never
+// becomes an end point.
+func (ac *amendmentContext) specEndpoints(r *NumericalRange, lo, hi float64) []specEndpoint {
+	var out []specEndpoint
+	seen := make(map[string]bool)
+	for _, s := range ac.sentences {
+		literals := literalNumbers(s)
+		for _, sr := range extractNumericalRanges(s) {
+			if !sameParameter(r, sr) {
+				continue
+			}
+			for _, b := range []*float64{sr.LowerBound, sr.UpperBound} {
+				if b == nil || *b < lo || *b > hi || !literals[*b] {
+					continue
+				}
+				key := formatNumber(*b) + "|" + s
+				if !seen[key] {
+					seen[key] = true
+					out = append(out, specEndpoint{value: *b, sentence: s})
+				}
+			}
+		}
+	}
+	return out
+}
+
+type rangeChoice struct {
+	lo, hi           float64
+	loQuote, hiQuote string
+	pairQuote        string
+	coversProducts   bool
+	width, reduction float64
+}
+
+// bestRangeTightening picks the sub-range that excludes every threat value,
+// preferring one that keeps all product values, then the widest, then one
+// disclosed as a pair in a single sentence.
+func bestRangeTightening(lo, hi float64, endpoints []specEndpoint, threats, products []float64) *rangeChoice {
+	// Unbounded sides are measured up to the furthest value seen for the
+	// parameter.
+	floor, ceil := math.Inf(1), math.Inf(-1)
+	for _, v := range append(append([]float64{lo, hi}, threats...), products...) {
+		if !math.IsInf(v, 0) {
+			floor, ceil = math.Min(floor, v), math.Max(ceil, v)
+		}
+	}
+	for _, e := range endpoints {
+		floor, ceil = math.Min(floor, e.value), math.Max(ceil, e.value)
+	}
+	span := func(a, b float64) float64 {
+		if math.IsInf(a, -1) {
+			a = floor
+		}
+		if math.IsInf(b, 1) {
+			b = ceil
+		}
+		return b - a
+	}
+	oldWidth := span(lo, hi)
+
+	type option struct {
+		v     float64
+		quote string
+	}
+	loOpts := []option{{v: lo}}
+	hiOpts := []option{{v: hi}}
+	for _, e := range endpoints {
+		if !math.IsInf(lo, -1) {
+			loOpts = append(loOpts, option{e.value, e.sentence})
+		}
+		if !math.IsInf(hi, 1) {
+			hiOpts = append(hiOpts, option{e.value, e.sentence})
+		}
+	}
+
+	var best *rangeChoice
+	for _, a := range loOpts {
+		for _, b := range hiOpts {
+			if a.v >= b.v || (a.v == lo && b.v == hi) {
+				continue
+			}
+			excluded := true
+			for _, t := range threats {
+				if t >= a.v && t <= b.v {
+					excluded = false
+					break
+				}
+			}
+			if !excluded {
+				continue
+			}
+			c := &rangeChoice{lo: a.v, hi: b.v, loQuote: a.quote, hiQuote: b.quote, coversProducts: true, width: span(a.v, b.v)}
+			for _, p := range products {
+				if p < a.v || p > b.v {
+					c.coversProducts = false
+				}
+			}
+			if a.quote != "" && a.quote == b.quote {
+				c.pairQuote = a.quote
+			}
+			if oldWidth > 0 {
+				c.reduction = clamp01(1 - c.width/oldWidth)
+			}
+			if best == nil || betterRangeChoice(c, best) {
+				best = c
+			}
+		}
+	}
+	return best
+}
+
+func betterRangeChoice(a, b *rangeChoice) bool {
+	if a.coversProducts != b.coversProducts {
+		return a.coversProducts
+	}
+	if a.width != b.width {
+		return a.width > b.width
+	}
+	return a.pairQuote != "" && b.pairQuote == ""
+}
+
+// replaceRangeBounds rewrites the numbers of a range in the claim text. A
+// two-sided range is located as two consecutive numbers equal to its bounds.
+func replaceRangeBounds(text string, lo, hi, newLo, newHi float64) (string, bool) {
+	nums := reAmendNumber.FindAllStringIndex(text, -1)
+	value := func(i int) (float64, bool) {
+		loc := nums[i]
+		if loc[0] > 0 && unicode.IsLetter(rune(text[loc[0]-1])) {
+			return 0, false // part of an identifier such as R1
+		}
+		v, err := strconv.ParseFloat(text[loc[0]:loc[1]], 64)
+		return v, err == nil
+	}
+	replace := func(loc []int, v float64) string {
+		return text[:loc[0]] + formatNumber(v) + text[loc[1]:]
+	}
+
+	switch {
+	case !math.IsInf(lo, -1) && !math.IsInf(hi, 1):
+		for i := 0; i+1 < len(nums); i++ {
+			a, okA := value(i)
+			b, okB := value(i + 1)
+			if okA && okB && a == lo && b == hi {
+				// Replace the upper bound first so the lower's offsets hold.
+				text = replace(nums[i+1], newHi)
+				return text[:nums[i][0]] + formatNumber(newLo) + text[nums[i][1]:], true
+			}
+		}
+	case !math.IsInf(lo, -1):
+		for i := range nums {
+			if v, ok := value(i); ok && v == lo {
+				return replace(nums[i], newLo), true
+			}
+		}
+	case !math.IsInf(hi, 1):
+		for i := range nums {
+			if v, ok := value(i); ok && v == hi {
+				return replace(nums[i], newHi), true
+			}
+		}
+	}
+	return text, false
+}
+
+var reAmendNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// literalNumbers returns the numbers written out in s.
+func literalNumbers(s string) map[float64]bool {
+	out := make(map[float64]bool)
+	for _, m := range reAmendNumber.FindAllString(s, -1) {
+		if v, err := strconv.ParseFloat(m, 64); err == nil {
+			out[v] = true
+		}
+	}
+	return out
+}
+
+// rangeBounds returns a range's bounds, using ±Inf for open sides.
+func rangeBounds(r *NumericalRange) (float64, float64) {
+	if r == nil {
+		return 0, 0
+	}
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if r.LowerBound != nil {
+		lo = *r.LowerBound
+	}
+	if r.UpperBound != nil {
+		hi = *r.UpperBound
+	}
+	if r.LowerBound == nil && r.UpperBound == nil && r.Max > r.Min {
+		lo, hi = r.Min, r.Max
+	}
+	return lo, hi
+}
+
+// rangeKey names the parameter a range limits: its parameter name, or its
+// unit when the parser found none.
+func rangeKey(r *NumericalRange) string {
+	if r == nil {
+		return ""
+	}
+	if p := strings.ToLower(strings.TrimSpace(r.Parameter)); p != "" {
+		return p
+	}
+	return strings.ToLower(strings.TrimSpace(r.Unit))
+}
+
+func sameParameter(a, b *NumericalRange) bool {
+	if a.Parameter != "" && b.Parameter != "" {
+		return strings.EqualFold(a.Parameter, b.Parameter)
+	}
+	return a.Unit != "" && strings.EqualFold(a.Unit, b.Unit)
+}
+
+func embodimentParameter(e *ClaimEmbodiment, key string) (float64, bool) {
+	if e == nil {
+		return 0, false
+	}
+	for k, v := range e.Parameters {
+		if strings.EqualFold(strings.TrimSpace(k), key) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+func rangeLabel(key string, lo, hi float64, unit string) string {
+	var s string
+	switch {
+	case math.IsInf(lo, -1):
+		s = "≤ " + formatNumber(hi)
+	case math.IsInf(hi, 1):
+		s = "≥ " + formatNumber(lo)
+	default:
+		s = formatNumber(lo) + "-" + formatNumber(hi)
+	}
+	if unit != "" && !strings.EqualFold(unit, key) {
+		s += " " + unit
+	}
+	return key + " " + s
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// ---------------------------------------------------------------------------
+// Added features
+// ---------------------------------------------------------------------------
+
+var (
+	reFeatureLeadEN = regexp.MustCompile(`(?i)\b(wherein|further\s+comprising|characterized\s+in\s+that)\b\s*`)
+	reFeatureLeadCN = regexp.MustCompile(`(其中|其特征在于|还包括)[，,:：]?\s*`)
+)
+
+// featureEdits offers the features of the dependent claims that depend on
+// the claim, and any caller-proposed features, as additions. Whether an
+// addition avoids a conflict is decided per candidate.
+func (ac *amendmentContext) featureEdits() []*amendmentEdit {
+	type proposal struct{ connector, feature, origin string }
+	var proposals []proposal
+	for _, dc := range ac.req.DependentClaims {
+		if n := ac.req.Claim.ClaimNumber; n > 0 && !dependsOnClaim(extractDependencyReferences(dc), n) {
+			continue
+		}
+		if connector, feature, ok := splitAddedFeature(dc); ok {
+			proposals = append(proposals, proposal{connector, feature, "dependent claim"})
+		}
+	}
+	for _, f := range ac.req.CandidateFeatures {
+		if f = strings.TrimSpace(strings.TrimRight(f, ".。")); f != "" {
+			connector := "wherein"
+			if containsHan(f) {
+				connector = "其中"
+			}
+			proposals = append(proposals, proposal{connector, f, "proposed feature"})
+		}
+	}
+
+	var edits []*amendmentEdit
+	seen := make(map[string]bool)
+	for _, p := range proposals {
+		norm := strings.ToLower(p.feature)
+		if seen[norm] || featureMentioned(ac.text, p.feature) {
+			continue
+		}
+		seen[norm] = true
+		desc := fmt.Sprintf("Add the %s feature \"%s\"", p.origin, p.feature)
+		quote := findSpecSupport(ac.sentences, func(s string) bool { return featureMentioned(s, p.feature) })
+		if quote == "" {
+			ac.reject(AmendmentAddedFeature, desc, "not described in the specification")
+			continue
+		}
+
+		limit := &amendmentLimit{feature: p.feature}
+		// A feature stating a numerical limit is checked against the
+		// embodiments' parameter values rather than their wording.
+		if rs := extractNumericalRanges(p.feature); len(rs) == 1 && rangeKey(rs[0]) != "" {
+			limit.key = rangeKey(rs[0])
+			limit.lo, limit.hi = rangeBounds(rs[0])
+		}
+		connector, feature := p.connector, p.feature
+		edits = append(edits, &amendmentEdit{
+			kind:        AmendmentAddedFeature,
+			target:      norm,
+			description: desc,
+			limit:       limit,
+			reduction:   addedFeatureScopeReduction,
+			support:     []*AmendmentSupport{{Limitation: feature, Quote: quote}},
+			applyText: func(text string) (string, bool) {
+				return appendClaimFeature(text, connector, feature), true
+			},
+			applyClaim: func(c *ParsedClaim) {
+				f := &TechnicalFeature{
+					ID:          fmt.Sprintf("amend-%d", len(c.Features)+1),
+					Text:        feature,
+					FeatureType: FeatureStructural,
+					IsEssential: true,
+				}
+				if limit.key != "" {
+					f.FeatureType = FeatureParameter
+					f.NumericalRanges = extractNumericalRanges(feature)
+				}
+				c.Features = append(c.Features, f)
+			},
+		})
+	}
+	return edits
+}
+
+// splitAddedFeature extracts the limitation a dependent claim adds, e.g.
+// "wherein R1 is methyl" from "2. The compound of claim 1, wherein R1 is
+// methyl."
+func splitAddedFeature(claimText string) (connector, feature string, ok bool) {
+	text := strings.TrimSpace(claimText)
+	if loc := reFeatureLeadEN.FindStringSubmatchIndex(text); loc != nil {
+		connector = strings.ToLower(strings.Join(strings.Fields(text[loc[2]:loc[3]]), " "))
+		if connector == "characterized in that" {
+			connector = "wherein"
+		}
+		feature = text[loc[1]:]
+	} else if loc := reFeatureLeadCN.FindStringSubmatchIndex(text); loc != nil {
+		connector, feature = text[loc[2]:loc[3]], text[loc[1]:]
+		if connector == "其特征在于" {
+			connector = "其中"
+		}
+	} else {
+		return "", "", false
+	}
+	feature = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(feature), ".。;；"))
+	return connector, feature, feature != ""
+}
+
+func appendClaimFeature(text, connector, feature string) string {
+	text = strings.TrimSpace(text)
+	if containsHan(text) {
+		return strings.TrimRight(text, "。.") + "，" + connector + feature + "。"
+	}
+	text = strings.TrimRight(text, ".")
+	if connector == "wherein" && reFeatureLeadEN.MatchString(text) {
+		return text + ", and wherein " + feature + "."
+	}
+	return text + ", " + connector + " " + feature + "."
+}
+
+// ---------------------------------------------------------------------------
+// Candidates
+// ---------------------------------------------------------------------------
+
+// combineEdits greedily joins edits of different targets when no single
+// edit avoids every conflict while keeping the products covered, adding at each step the edit that avoids the
+// most remaining conflicts without losing a product.
+func combineEdits(ac *amendmentContext, edits []*amendmentEdit, baseline []*amendmentLimit, conflicts []*ClaimEmbodiment) []*amendmentEdit {
+	avoids := func(chosen []*amendmentEdit) (int, bool) {
+		limits := limitsWith(baseline, chosen)
+		n := 0
+		for _, c := range conflicts {
+			if s, _ := evaluateCoverage(c, limits); s == CoverageNotCovered {
+				n++
+			}
+		}
+		covers := true
+		for _, p := range ac.req.OwnProducts {
+			if s, _ := evaluateCoverage(p, limits); p != nil && s == CoverageNotCovered {
+				covers = false
+			}
+		}
+		return n, covers
+	}
+	for _, ed := range edits {
+		if n, covers := avoids([]*amendmentEdit{ed}); n == len(conflicts) && covers {
+			return nil
+		}
+	}
+
+	var chosen []*amendmentEdit
+	used := make(map[string]bool)
+	current := 0
+	for current < len(conflicts) {
+		var next *amendmentEdit
+		bestN := current
+		for _, ed := range edits {
+			if used[ed.target] {
+				continue
+			}
+			n, covers := avoids(append(append([]*amendmentEdit(nil), chosen...), ed))
+			if covers && n > bestN {
+				next, bestN = ed, n
+			}
+		}
+		if next == nil {
+			break
+		}
+		chosen = append(chosen, next)
+		used[next.target] = true
+		current = bestN
+	}
+	if len(chosen) < 2 {
+		return nil
+	}
+	return chosen
+}
+
+// limitsWith returns the baseline limitations with the edits applied.
+func limitsWith(baseline []*amendmentLimit, edits []*amendmentEdit) []*amendmentLimit {
+	limits := make([]*amendmentLimit, 0, len(baseline)+len(edits))
+	for _, b := range baseline {
+		replaced := false
+		for _, ed := range edits {
+			l := ed.limit
+			if (b.group != nil && l.group == b.group) || (b.group == nil && l.group == nil && l.feature == "" && l.key == b.key) {
+				replaced = true
+			}
+		}
+		if !replaced {
+			limits = append(limits, b)
+		}
+	}
+	for _, ed := range edits {
+		limits = append(limits, ed.limit)
+	}
+	return limits
+}
+
+func (e *amendmentEngineImpl) buildCandidate(ctx context.Context, ac *amendmentContext, edits []*amendmentEdit, baseline []*amendmentLimit, conflicts []*ClaimEmbodiment, breadthBefore float64) *AmendmentCandidate {
+	text := ac.text
+	for _, ed := range edits {
+		var ok bool
+		if text, ok = ed.applyText(text); !ok {
+			ac.reject(ed.kind, ed.description, "could not locate the limitation in the claim text")
+			return nil
+		}
+	}
+
+	amended := cloneParsedClaim(ac.req.Claim)
+	if len(amended.MarkushGroups) == 0 {
+		amended.MarkushGroups = cloneMarkushGroups(ac.groups)
+	}
+	kept := 1.0
+	var descs []string
+	cand := &AmendmentCandidate{Kind: edits[0].kind, AmendedText: text, BreadthBefore: breadthBefore, AmendedClaim: amended}
+	for _, ed := range edits {
+		ed.applyClaim(amended)
+		kept *= 1 - ed.reduction
+		descs = append(descs, ed.description)
+		cand.Support = append(cand.Support, ed.support...)
+	}
+	if len(edits) > 1 {
+		cand.Kind = AmendmentCombined
+	}
+	cand.Description = strings.Join(descs, "; ")
+	cand.ScopeReduction = math.Round((1-kept)*1e4) / 1e4
+
+	limits := limitsWith(baseline, edits)
+	for _, c := range conflicts {
+		if s, _ := evaluateCoverage(c, limits); s == CoverageNotCovered {
+			cand.AvoidedConflicts = append(cand.AvoidedConflicts, c.ID)
+		} else {
+			cand.RemainingConflicts = append(cand.RemainingConflicts, c.ID)
+		}
+	}
+	if len(cand.AvoidedConflicts) == 0 {
+		return nil
+	}
+
+	cand.CoversOwnProducts = true
+	for _, p := range ac.req.OwnProducts {
+		if p == nil {
+			continue
+		}
+		s, reason := evaluateCoverage(p, limits)
+		cand.ProductCoverage = append(cand.ProductCoverage, &ProductCoverage{ProductID: p.ID, Status: s, Reason: reason})
+		if s != CoverageCovered {
+			cand.CoversOwnProducts = false
+		}
+	}
+
+	after, err := e.scope.ComputeScopeBreadth(ctx, amended)
+	if err != nil {
+		e.logger.Warn("breadth of amended claim unavailable", "claim_number", amended.ClaimNumber, "error", err)
+		after = breadthBefore
+	}
+	cand.BreadthAfter = after
+	return cand
+}
+
+// rankAmendmentCandidates orders candidates: those keeping every product
+// covered first, then by conflicts avoided, then by least scope given up,
+// then by fewest edits.
+func rankAmendmentCandidates(cs []*AmendmentCandidate) {
+	sort.SliceStable(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if a.CoversOwnProducts != b.CoversOwnProducts {
+			return a.CoversOwnProducts
+		}
+		if len(a.AvoidedConflicts) != len(b.AvoidedConflicts) {
+			return len(a.AvoidedConflicts) > len(b.AvoidedConflicts)
+		}
+		if a.ScopeReduction != b.ScopeReduction {
+			return a.ScopeReduction < b.ScopeReduction
+		}
+		return a.Kind != AmendmentCombined && b.Kind == AmendmentCombined
+	})
+}
+
+// ---------------------------------------------------------------------------
+// Coverage
+// ---------------------------------------------------------------------------
+
+// evaluateCoverage decides whether a claim with the given limitations reads
+// on an embodiment: NOT_COVERED as soon as one limitation is shown not to
+// be met, COVERED when all are shown to be met, UNKNOWN otherwise. The
+// reason names the first limitation that was not met or could not be
+// checked.
+func evaluateCoverage(e *ClaimEmbodiment, limits []*amendmentLimit) (CoverageStatus, string) {
+	status, reason := CoverageCovered, ""
+	unknown := func(r string) {
+		if status == CoverageCovered {
+			status, reason = CoverageUnknown, r
+		}
+	}
+	for _, l := range limits {
+		switch {
+		case l.group != nil:
+			used := embodimentMembers(e, l.group)
+			if len(used) == 0 {
+				unknown(fmt.Sprintf("does not state which member of %s it uses", l.group.GroupID))
+				continue
+			}
+			if !anyString(used, l.keep) {
+				return CoverageNotCovered, fmt.Sprintf("uses %s, outside the amended %s", strings.Join(used, ", "), l.group.GroupID)
+			}
+		case l.key != "":
+			v, ok := embodimentParameter(e, l.key)
+			if !ok {
+				if l.feature != "" && featureMentioned(e.Text, l.feature) {
+					continue
+				}
+				unknown("no value for " + l.key)
+				continue
+			}
+			if v < l.lo || v > l.hi {
+				return CoverageNotCovered, fmt.Sprintf("%s %s is outside %s", l.key, formatNumber(v), rangeLabel(l.key, l.lo, l.hi, ""))
+			}
+		case l.feature != "":
+			if strings.TrimSpace(e.Text) == "" {
+				unknown("no text to check for \"" + l.feature + "\"")
+				continue
+			}
+			if !featureMentioned(e.Text, l.feature) {
+				return CoverageNotCovered, "does not disclose \"" + l.feature + "\""
+			}
+		}
+	}
+	return status, reason
+}
+
+// ---------------------------------------------------------------------------
+// Text helpers
+// ---------------------------------------------------------------------------
+
+// splitSpecSentences splits a specification into sentences. A period only
+// ends a sentence when followed by whitespace, so decimals stay intact.
+func splitSpecSentences(text string) []string {
+	var (
+		out []string
+		cur strings.Builder
+	)
+	runes := []rune(text)
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			out = append(out, s)
+		}
+		cur.Reset()
+	}
+	for i, r := range runes {
+		switch {
+		case r == '\n' || r == '。' || r == '；' || r == ';':
+			if r != '\n' {
+				cur.WriteRune(r)
+			}
+			flush()
+		case r == '.' && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])):
+			cur.WriteRune(r)
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return out
+}
+
+func findSpecSupport(sentences []string, match func(string) bool) string {
+	for _, s := range sentences {
+		if match(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// mentionsTerm reports whether text contains term as a whole word,
+// ignoring case. CJK terms are matched as substrings.
+func mentionsTerm(text, term string) bool {
+	term = strings.TrimSpace(term)
+	if term == "" || text == "" {
+		return false
+	}
+	lt, lterm := strings.ToLower(text), strings.ToLower(term)
+	if containsHan(term) {
+		return strings.Contains(lt, lterm)
+	}
+	for from := 0; ; {
+		i := strings.Index(lt[from:], lterm)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(lterm)
+		if !isWordByteAt(lt, start-1) && !isWordByteAt(lt, end) {
+			return true
+		}
+		from = start + 1
+	}
+}
+
+func isWordByteAt(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// featureMentioned reports whether text discloses a feature: at least 80%
+// of its content words appear in text (the whole phrase for CJK).
+func featureMentioned(text, feature string) bool {
+	if containsHan(feature) {
+		return strings.Contains(text, feature)
+	}
+	words := featureWords(feature)
+	if len(words) == 0 {
+		return false
+	}
+	found := 0
+	for _, w := range words {
+		if mentionsTerm(text, w) {
+			found++
+		}
+	}
+	return float64(found) >= 0.8*float64(len(words))
+}
+
+var featureStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "has": true, "have": true, "having": true,
+	"said": true, "which": true, "that": true, "from": true, "into": true, "least": true, "most": true,
+	"than": true, "wherein": true, "comprising": true, "comprises": true, "claim": true,
+}
+
+func featureWords(s string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '.')
+	}) {
+		w = strings.Trim(w, "-.")
+		if len(w) >= 2 && !featureStopwords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func dependsOnClaim(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func anyString(list, set []string) bool {
+	for _, v := range list {
+		if containsString(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func cloneParsedClaim(c *ParsedClaim) *ParsedClaim {
+	out := *c
+	out.Features = append([]*TechnicalFeature(nil), c.Features...)
+	out.MarkushGroups = cloneMarkushGroups(c.MarkushGroups)
+	out.NumericalRanges = append([]*NumericalRange(nil), c.NumericalRanges...)
+	out.DependsOn = append([]int(nil), c.DependsOn...)
+	return &out
+}
+
+func cloneMarkushGroups(groups []*MarkushGroup) []*MarkushGroup {
+	return append([]*MarkushGroup(nil), groups...)
+}
+
+//Personal.AI order the ending
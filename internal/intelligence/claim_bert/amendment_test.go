@@ -0,0 +1,225 @@
+package claim_bert
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const amendmentClaim = "1. An organic light-emitting device comprising a host compound of formula (I), " +
+	"wherein Ar is selected from the group consisting of carbazole, dibenzofuran, dibenzothiophene and fluorene; " +
+	"and wherein the host layer is deposited at a temperature of from 100 to 300 °C."
+
+const amendmentSpec = `In formula (I), Ar may be carbazole. Ar may also be dibenzofuran, which raises the glass transition temperature.
+Dibenzothiophene is a further option for Ar.
+The host layer is deposited at a temperature of from 150 to 250 °C, preferably from 180 to 220 °C.
+In a preferred embodiment the device further comprises a hole blocking layer of triazine.`
+
+func newTestAmendmentEngine(t *testing.T) AmendmentEngine {
+	t.Helper()
+	scope, err := NewScopeAnalyzer(newMockClaimEmbedder(16), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := NewAmendmentEngine(scope, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func amendmentRequest(conflicts ...*ClaimEmbodiment) *AmendmentRequest {
+	return &AmendmentRequest{
+		Claim:         &ParsedClaim{ClaimNumber: 1, ClaimType: ClaimIndependent},
+		ClaimText:     amendmentClaim,
+		Specification: amendmentSpec,
+		DependentClaims: []string{
+			"2. The device of claim 1, further comprising a hole blocking layer of triazine.",
+			"3. The device of claim 1, wherein the host compound is doped with platinum.",
+		},
+		Conflicts: conflicts,
+		OwnProducts: []*ClaimEmbodiment{
+			{ID: "BH-3", Members: []string{"carbazole"}, Parameters: map[string]float64{"temperature": 200},
+				Text: "carbazole host with a hole blocking layer of triazine"},
+		},
+	}
+}
+
+func candidateOfKind(s *AmendmentSuggestions, kind AmendmentKind) *AmendmentCandidate {
+	for _, c := range s.Candidates {
+		if c.Kind == kind {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestSuggestAmendments_MarkushSubset(t *testing.T) {
+	req := amendmentRequest(&ClaimEmbodiment{ID: "D1", Text: "A host with a dibenzofuran group deposited by evaporation."})
+	got, err := newTestAmendmentEngine(t).SuggestAmendments(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SuggestAmendments: %v", err)
+	}
+	c := candidateOfKind(got, AmendmentMarkushSubset)
+	if c == nil {
+		t.Fatalf("expected a Markush subset, got %+v", got.Candidates)
+	}
+	if c.Rank != 1 || !c.CoversOwnProducts || len(c.AvoidedConflicts) != 1 {
+		t.Errorf("Markush subset should rank first and keep BH-3: %+v", c)
+	}
+	// Fluorene is not described, so it cannot be kept; dibenzofuran is prior art.
+	if !strings.Contains(c.AmendedText, "consisting of carbazole and dibenzothiophene; and wherein") {
+		t.Errorf("unexpected amended text %q", c.AmendedText)
+	}
+	if c.ScopeReduction != 0.5 || len(c.Support) != 2 || !strings.Contains(c.Support[1].Quote, "Dibenzothiophene") {
+		t.Errorf("unexpected reduction %.2f or support %+v", c.ScopeReduction, c.Support)
+	}
+	if g := c.AmendedClaim.MarkushGroups[0]; len(g.Members) != 2 {
+		t.Errorf("amended claim should carry the subset, got %v", g.Members)
+	}
+	rejectedFluorene := false
+	for _, r := range got.Rejected {
+		rejectedFluorene = rejectedFluorene || strings.Contains(r.Description, "fluorene")
+	}
+	if !rejectedFluorene {
+		t.Errorf("dropping unsupported fluorene should be reported, got %+v", got.Rejected)
+	}
+}
+
+func TestSuggestAmendments_RangeTightening(t *testing.T) {
+	req := amendmentRequest(&ClaimEmbodiment{ID: "D2", Members: []string{"carbazole"},
+		Parameters: map[string]float64{"temperature": 120}})
+	got, err := newTestAmendmentEngine(t).SuggestAmendments(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := candidateOfKind(got, AmendmentRangeTightening)
+	if c == nil || c.Rank != 1 {
+		t.Fatalf("expected range tightening first, got %+v", got.Candidates)
+	}
+	// 150-300 is the widest disclosed sub-range that excludes 120.
+	if !strings.Contains(c.AmendedText, "from 150 to 300 °C") || c.ScopeReduction != 0.25 {
+		t.Errorf("unexpected amendment %q (reduction %.2f)", c.AmendedText, c.ScopeReduction)
+	}
+	if len(c.Support) != 1 || !strings.Contains(c.Support[0].Quote, "from 150 to 250") {
+		t.Errorf("new end point must be quoted from the specification: %+v", c.Support)
+	}
+	// Deleting carbazole also avoids D2 but gives up BH-3.
+	if m := candidateOfKind(got, AmendmentMarkushSubset); m == nil || m.CoversOwnProducts || m.Rank == 1 {
+		t.Errorf("Markush subset losing BH-3 must rank below, got %+v", m)
+	}
+}
+
+func TestSuggestAmendments_AddedFeatureAndProductCheck(t *testing.T) {
+	// The conflict uses every supported member and a temperature inside
+	// every disclosed range, so only an added feature can avoid it.
+	req := amendmentRequest(&ClaimEmbodiment{ID: "D3", Members: []string{"carbazole", "dibenzofuran", "dibenzothiophene"},
+		Parameters: map[string]float64{"temperature": 200}, Text: "A carbazole host device."})
+	got, err := newTestAmendmentEngine(t).SuggestAmendments(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := candidateOfKind(got, AmendmentAddedFeature)
+	if c == nil || c.Rank != 1 || !c.CoversOwnProducts {
+		t.Fatalf("expected the supported dependent feature first, got %+v", got.Candidates)
+	}
+	if !strings.HasSuffix(c.AmendedText, "300 °C, further comprising a hole blocking layer of triazine.") {
+		t.Errorf("unexpected amended text %q", c.AmendedText)
+	}
+	for _, r := range got.Rejected {
+		if strings.Contains(r.Description, "platinum") && r.Reason == "not described in the specification" {
+			return
+		}
+	}
+	t.Errorf("unsupported dependent feature must be rejected, got %+v", got.Rejected)
+}
+
+func TestSuggestAmendments_CombinedAndUnresolved(t *testing.T) {
+	req := amendmentRequest(
+		&ClaimEmbodiment{ID: "D1", Members: []string{"dibenzofuran"}, Parameters: map[string]float64{"temperature": 200}},
+		&ClaimEmbodiment{ID: "D2", Members: []string{"carbazole"}, Parameters: map[string]float64{"temperature": 120}},
+		&ClaimEmbodiment{ID: "D4", Members: []string{"fluorene"}, Parameters: map[string]float64{"temperature": 350}},
+	)
+	req.DependentClaims = nil
+	got, err := newTestAmendmentEngine(t).SuggestAmendments(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.AlreadyAvoided) != 1 || got.AlreadyAvoided[0] != "D4" {
+		t.Errorf("D4 lies outside the claimed range, got %v", got.AlreadyAvoided)
+	}
+	c := got.Candidates[0]
+	if c.Kind != AmendmentCombined || len(c.AvoidedConflicts) != 2 || !c.CoversOwnProducts {
+		t.Fatalf("expected a combined amendment first, got %+v", c)
+	}
+	if !strings.Contains(c.AmendedText, "consisting of carbazole and dibenzothiophene") ||
+		!strings.Contains(c.AmendedText, "from 150 to 300 °C") {
+		t.Errorf("combined amendment should apply both edits: %q", c.AmendedText)
+	}
+	if c.ScopeReduction != 0.625 {
+		t.Errorf("expected compounded reduction 0.625, got %v", c.ScopeReduction)
+	}
+	if len(got.Unresolved) != 0 {
+		t.Errorf("every conflict has a candidate, unresolved %v", got.Unresolved)
+	}
+}
+
+func TestSuggestAmendments_InvalidRequests(t *testing.T) {
+	if _, err := NewAmendmentEngine(nil, nil); err == nil {
+		t.Error("expected error without scope analyzer")
+	}
+	engine := newTestAmendmentEngine(t)
+	ctx := context.Background()
+	for name, req := range map[string]*AmendmentRequest{
+		"nil":          nil,
+		"no spec":      {Claim: &ParsedClaim{}, Conflicts: []*ClaimEmbodiment{{ID: "x"}}},
+		"no conflicts": {Claim: &ParsedClaim{}, Specification: amendmentSpec},
+	} {
+		if _, err := engine.SuggestAmendments(ctx, req); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEvaluateCoverage(t *testing.T) {
+	g := &MarkushGroup{GroupID: "markush-1", Members: []string{"methyl", "ethyl"}}
+	limits := []*amendmentLimit{{group: g, keep: []string{"methyl"}}, {key: "temperature", lo: 100, hi: 200}}
+
+	cases := []struct {
+		e    *ClaimEmbodiment
+		want CoverageStatus
+	}{
+		{&ClaimEmbodiment{Text: "a methyl substituent", Parameters: map[string]float64{"Temperature": 150}}, CoverageCovered},
+		{&ClaimEmbodiment{Members: []string{"ethyl"}, Parameters: map[string]float64{"temperature": 150}}, CoverageNotCovered},
+		{&ClaimEmbodiment{Members: []string{"methyl"}, Parameters: map[string]float64{"temperature": 250}}, CoverageNotCovered},
+		{&ClaimEmbodiment{Text: "a dimethylamino group"}, CoverageUnknown},
+	}
+	for i, tc := range cases {
+		if got, reason := evaluateCoverage(tc.e, limits); got != tc.want {
+			t.Errorf("case %d: got %s (%s), want %s", i, got, reason, tc.want)
+		}
+	}
+}
+
+func TestReplaceMarkushMembers_Chinese(t *testing.T) {
+	text := "1、一种化合物，其中R1选自由甲基、乙基和丙基组成的组。"
+	g := extractMarkushGroups(text)[0]
+	got, ok := replaceMarkushMembers(text, g, 0, []string{"甲基", "丙基"})
+	if !ok || got != "1、一种化合物，其中R1选自由甲基和丙基组成的组。" {
+		t.Errorf("unexpected %q", got)
+	}
+	got, _ = replaceMarkushMembers(text, g, 0, []string{"乙基"})
+	if got != "1、一种化合物，其中R1为乙基。" {
+		t.Errorf("unexpected %q", got)
+	}
+}
+
+func TestSplitSpecSentences(t *testing.T) {
+	got := splitSpecSentences("Tg is 1.5 times higher. Next line\n化合物一。化合物二；end.")
+	want := []string{"Tg is 1.5 times higher.", "Next line", "化合物一。", "化合物二；", "end."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q", got)
+	}
+}
+
+//Personal.AI order the ending
@@ -25,6 +25,7 @@ type EmbeddingClient struct {
 	endpoint    string
 	apiKey      string
 	modelName   string
+	modelVersion string
 	dimensions  int
 	httpClient  *http.Client
 	backend     ModelBackend // fallback: use Predict() for prompt-based embeddings
@@ -36,9 +37,13 @@ type EmbeddingClientConfig struct {
 	Endpoint   string
 	APIKey     string
 	ModelName  string // embedding model name; if empty, defaults to provider default
+	ModelVersion string // bumped to invalidate cached vectors and trigger re-embedding
 	Dimensions int    // output vector dimensions
 }
 
+// DefaultEmbeddingModelVersion is used when no embedding model version is configured.
+const DefaultEmbeddingModelVersion = "1"
+
 // DefaultEmbeddingConfigs maps provider → default model + dimensions.
 var DefaultEmbeddingConfigs = map[string]struct {
 	ModelName  string
@@ -94,27 +99,61 @@ func NewEmbeddingClient(cfg *config.Config, backend ModelBackend) *EmbeddingClie
 	if dimensions <= 0 {
 		dimensions = 768
 	}
+	modelVersion := primary.EmbeddingModelVersion
+	if modelVersion == "" {
+		modelVersion = DefaultEmbeddingModelVersion
+	}
 
 	return &EmbeddingClient{
 		provider:   primary.Provider,
 		endpoint:   endpoint,
 		apiKey:     apiKey,
 		modelName:  modelName,
+		modelVersion: modelVersion,
 		dimensions: dimensions,
 		httpClient: &http.Client{},
 		backend:    backend,
 	}
 }
 
-// Embed returns a float32 vector for the given input text.
+// Embed returns a float32 vector for the given input text. It fails with
+// ErrFallbackEmbedding rather than return a hash-derived vector; callers
+// that can use one must ask for it through EmbedTagged.
 func (c *EmbeddingClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	emb, err := c.EmbedTagged(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if emb.Fallback {
+		return nil, fmt.Errorf("embedding: %s: %w", c.modelName, ErrFallbackEmbedding)
+	}
+	return emb.Vector, nil
+}
+
+// EmbedTagged returns the vector for text together with the model that
+// produced it. Hash-derived fallback vectors are returned with Fallback set.
+func (c *EmbeddingClient) EmbedTagged(ctx context.Context, text string) (*Embedding, error) {
+	var (
+		vec      []float32
+		fallback bool
+		err      error
+	)
 	switch c.provider {
 	case "openai", "deepseek":
-		return c.embedOpenAICompat(ctx, text)
+		vec, err = c.embedOpenAICompat(ctx, text)
 	default:
 		// Anthropic: use prompt-based extraction via Predict()
-		return c.embedPromptBased(ctx, text)
+		vec, fallback, err = c.embedPromptBased(ctx, text)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &Embedding{Vector: vec, ModelID: c.modelName, ModelVersion: c.modelVersion, Fallback: fallback}, nil
+}
+
+// EmbeddingModel returns the model ID and version stamped on every vector.
+func (c *EmbeddingClient) EmbeddingModel() (string, string) {
+	return c.modelName, c.modelVersion
 }
 
 // embedOpenAICompat calls POST /v1/embeddings on an OpenAI-compatible API.
@@ -167,9 +206,10 @@ func (c *EmbeddingClient) embedOpenAICompat(ctx context.Context, text string) ([
 // embedPromptBased uses the LLM Predict() to extract a structured representation,
 // then hashes it to produce a deterministic embedding vector.
 // This is a fallback for providers without a dedicated embeddings API (e.g. Anthropic).
-func (c *EmbeddingClient) embedPromptBased(ctx context.Context, text string) ([]float32, error) {
+// The bool result reports whether the vector is a hash-based fallback.
+func (c *EmbeddingClient) embedPromptBased(ctx context.Context, text string) ([]float32, bool, error) {
 	if c.backend == nil {
-		return nil, fmt.Errorf("embedding: no backend available for prompt-based embedding")
+		return nil, false, fmt.Errorf("embedding: no backend available for prompt-based embedding")
 	}
 
 	prompt := fmt.Sprintf("Vectorize this chemical entity: %s", text)
@@ -182,7 +222,7 @@ func (c *EmbeddingClient) embedPromptBased(ctx context.Context, text string) ([]
 
 	resp, err := c.backend.Predict(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("embedding: predict: %w", err)
+		return nil, false, fmt.Errorf("embedding: predict: %w", err)
 	}
 
 	// Extract text output from PredictResponse.Outputs
@@ -202,11 +242,11 @@ func (c *EmbeddingClient) embedPromptBased(ctx context.Context, text string) ([]
 
 	if len(baseVec) == 0 {
 		// Fallback: hash-based embedding
-		return c.hashEmbedding(text), nil
+		return c.hashEmbedding(text), true, nil
 	}
 
 	// Pad or truncate to target dimensions
-	return c.normalizeVector(baseVec), nil
+	return c.normalizeVector(baseVec), false, nil
 }
 
 // hashEmbedding produces a deterministic float32 vector from SHA-256 hash of input.
@@ -263,6 +303,7 @@ func (c *EmbeddingClient) Config() EmbeddingClientConfig {
 		Provider:   c.provider,
 		Endpoint:   c.endpoint,
 		ModelName:  c.modelName,
+		ModelVersion: c.modelVersion,
 		Dimensions: c.dimensions,
 	}
 }
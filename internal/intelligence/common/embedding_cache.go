@@ -0,0 +1,377 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Tagged embeddings
+// ---------------------------------------------------------------------------
+
+// ErrFallbackEmbedding is returned where a hash-derived fallback vector would
+// otherwise be used as if a model had produced it.
+var ErrFallbackEmbedding = fmt.Errorf("embedding: fallback vector")
+
+// FallbackEmbeddingTag is the model tag of hash-derived fallback vectors.
+const FallbackEmbeddingTag = "fallback"
+
+// Embedding is a vector together with the model that produced it.
+type Embedding struct {
+	Vector       []float32 `json:"vector"`
+	ModelID      string    `json:"model_id"`
+	ModelVersion string    `json:"model_version"`
+	// Fallback marks a hash-derived vector produced when the model gave no
+	// usable output. It carries no semantics and must not be indexed.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// Tag identifies the producing model as "id@version", or
+// FallbackEmbeddingTag for fallback vectors.
+func (e *Embedding) Tag() string {
+	if e.Fallback {
+		return FallbackEmbeddingTag
+	}
+	return EmbeddingModelTag(e.ModelID, e.ModelVersion)
+}
+
+// EmbeddingModelTag formats a model ID and version as stored alongside
+// vectors.
+func EmbeddingModelTag(modelID, version string) string {
+	return modelID + "@" + version
+}
+
+// TaggedEmbedder produces vectors stamped with their model. EmbeddingClient
+// and EmbeddingCache satisfy it.
+type TaggedEmbedder interface {
+	EmbedTagged(ctx context.Context, text string) (*Embedding, error)
+	// EmbeddingModel returns the model ID and version of produced vectors.
+	EmbeddingModel() (id, version string)
+}
+
+var (
+	_ TaggedEmbedder = (*EmbeddingClient)(nil)
+	_ TaggedEmbedder = (*EmbeddingCache)(nil)
+	_ Embedder       = (*EmbeddingCache)(nil)
+)
+
+// EmbeddingCacheKey is the content address of text embedded by a model
+// version. Any change to the model ID or version yields a new key.
+func EmbeddingCacheKey(modelID, version, text string) string {
+	h := sha256.New()
+	io.WriteString(h, modelID)
+	h.Write([]byte{0})
+	io.WriteString(h, version)
+	h.Write([]byte{0})
+	io.WriteString(h, text)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ---------------------------------------------------------------------------
+// Stores
+// ---------------------------------------------------------------------------
+
+// EmbeddingStore is one tier of the embedding cache. Get returns
+// redis.ErrCacheMiss when the key is absent.
+type EmbeddingStore interface {
+	GetEmbedding(ctx context.Context, key string) (*Embedding, error)
+	PutEmbedding(ctx context.Context, key string, emb *Embedding) error
+}
+
+// FileEmbeddingStore keeps one file per vector under a directory, sharded by
+// the first two characters of the key. Writes are atomic renames, so
+// concurrent processes may share the directory.
+type FileEmbeddingStore struct {
+	dir string
+}
+
+// fileEmbeddingMagic prefixes every file; bump it when the layout changes.
+var fileEmbeddingMagic = [4]byte{'K', 'E', 'V', '1'}
+
+// NewFileEmbeddingStore opens (creating if needed) an on-disk store in dir.
+func NewFileEmbeddingStore(dir string) (*FileEmbeddingStore, error) {
+	if dir == "" {
+		return nil, errors.NewInvalidInputError("embedding cache directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create embedding cache directory")
+	}
+	return &FileEmbeddingStore{dir: dir}, nil
+}
+
+func (s *FileEmbeddingStore) path(key string) string {
+	shard := key
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(s.dir, shard, key+".vec")
+}
+
+// GetEmbedding reads a vector. Unreadable files are removed and reported as
+// misses.
+func (s *FileEmbeddingStore) GetEmbedding(_ context.Context, key string) (*Embedding, error) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, redis.ErrCacheMiss
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeCacheError, "read cached embedding")
+	}
+	emb, ok := decodeEmbeddingFile(data)
+	if !ok {
+		_ = os.Remove(s.path(key))
+		return nil, redis.ErrCacheMiss
+	}
+	return emb, nil
+}
+
+// PutEmbedding writes a vector.
+func (s *FileEmbeddingStore) PutEmbedding(_ context.Context, key string, emb *Embedding) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, errors.ErrCodeCacheError, "create embedding cache shard")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeCacheError, "write cached embedding")
+	}
+	_, err = tmp.Write(encodeEmbeddingFile(emb))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, errors.ErrCodeCacheError, "write cached embedding")
+	}
+	return nil
+}
+
+// Layout: magic, uint16 model ID length, model ID, uint16 version length,
+// version, uint32 dimensions, little-endian float32 components.
+func encodeEmbeddingFile(emb *Embedding) []byte {
+	buf := make([]byte, 0, 12+len(emb.ModelID)+len(emb.ModelVersion)+4*len(emb.Vector))
+	buf = append(buf, fileEmbeddingMagic[:]...)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(emb.ModelID)))
+	buf = append(buf, emb.ModelID...)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(emb.ModelVersion)))
+	buf = append(buf, emb.ModelVersion...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(emb.Vector)))
+	for _, v := range emb.Vector {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeEmbeddingFile(data []byte) (*Embedding, bool) {
+	if len(data) < 4 || [4]byte(data[:4]) != fileEmbeddingMagic {
+		return nil, false
+	}
+	data = data[4:]
+	str := func() (string, bool) {
+		if len(data) < 2 {
+			return "", false
+		}
+		n := int(binary.LittleEndian.Uint16(data))
+		if len(data) < 2+n {
+			return "", false
+		}
+		s := string(data[2 : 2+n])
+		data = data[2+n:]
+		return s, true
+	}
+	emb := &Embedding{}
+	var ok bool
+	if emb.ModelID, ok = str(); !ok {
+		return nil, false
+	}
+	if emb.ModelVersion, ok = str(); !ok {
+		return nil, false
+	}
+	if len(data) < 4 {
+		return nil, false
+	}
+	dims := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	if len(data) != 4*dims {
+		return nil, false
+	}
+	emb.Vector = make([]float32, dims)
+	for i := range emb.Vector {
+		emb.Vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return emb, true
+}
+
+// RedisEmbeddingStore shares vectors between processes through a
+// redis.Cache.
+type RedisEmbeddingStore struct {
+	cache  redis.Cache
+	ttl    time.Duration
+	prefix string
+}
+
+// NewRedisEmbeddingStore stores vectors under "emb:<key>" with the given
+// TTL; 0 uses the cache's default.
+func NewRedisEmbeddingStore(cache redis.Cache, ttl time.Duration) (*RedisEmbeddingStore, error) {
+	if cache == nil {
+		return nil, errors.NewInvalidInputError("cache is required")
+	}
+	return &RedisEmbeddingStore{cache: cache, ttl: ttl, prefix: "emb:"}, nil
+}
+
+// GetEmbedding reads a vector.
+func (s *RedisEmbeddingStore) GetEmbedding(ctx context.Context, key string) (*Embedding, error) {
+	var emb Embedding
+	if err := s.cache.Get(ctx, s.prefix+key, &emb); err != nil {
+		return nil, err
+	}
+	return &emb, nil
+}
+
+// PutEmbedding writes a vector.
+func (s *RedisEmbeddingStore) PutEmbedding(ctx context.Context, key string, emb *Embedding) error {
+	return s.cache.Set(ctx, s.prefix+key, emb, s.ttl)
+}
+
+// ---------------------------------------------------------------------------
+// EmbeddingCache
+// ---------------------------------------------------------------------------
+
+// EmbeddingCacheStats summarises cache effectiveness.
+type EmbeddingCacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Fallbacks int64 `json:"fallbacks"`
+}
+
+// EmbeddingCacheOption configures an EmbeddingCache.
+type EmbeddingCacheOption func(*EmbeddingCache)
+
+// WithEmbeddingCacheTier adds a slower tier consulted after the ones
+// already configured, e.g. a RedisEmbeddingStore behind the local files.
+func WithEmbeddingCacheTier(store EmbeddingStore) EmbeddingCacheOption {
+	return func(c *EmbeddingCache) { c.tiers = append(c.tiers, store) }
+}
+
+// WithEmbeddingCacheLogger injects a logger.
+func WithEmbeddingCacheLogger(l Logger) EmbeddingCacheOption {
+	return func(c *EmbeddingCache) { c.logger = l }
+}
+
+// EmbeddingCache decorates a TaggedEmbedder with content-addressed, tiered
+// storage. Hits in a slower tier are copied into the faster ones. Fallback
+// vectors are never cached, and tier failures degrade to computing the
+// vector.
+type EmbeddingCache struct {
+	next   TaggedEmbedder
+	tiers  []EmbeddingStore
+	logger Logger
+
+	hits, misses, fallbacks atomic.Int64
+}
+
+// NewEmbeddingCache wraps next with local as the first cache tier.
+func NewEmbeddingCache(next TaggedEmbedder, local EmbeddingStore, opts ...EmbeddingCacheOption) (*EmbeddingCache, error) {
+	if next == nil {
+		return nil, errors.NewInvalidInputError("embedder is required")
+	}
+	if local == nil {
+		return nil, errors.NewInvalidInputError("local embedding store is required")
+	}
+	c := &EmbeddingCache{next: next, tiers: []EmbeddingStore{local}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.logger == nil {
+		c.logger = NewNoopLogger()
+	}
+	return c, nil
+}
+
+// Embed returns the vector for text, failing with ErrFallbackEmbedding
+// instead of returning a fallback vector.
+func (c *EmbeddingCache) Embed(ctx context.Context, text string) ([]float32, error) {
+	emb, err := c.EmbedTagged(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if emb.Fallback {
+		return nil, fmt.Errorf("embedding: %s: %w", emb.ModelID, ErrFallbackEmbedding)
+	}
+	return emb.Vector, nil
+}
+
+// EmbedTagged serves text from the first tier holding it, or computes and
+// stores it.
+func (c *EmbeddingCache) EmbedTagged(ctx context.Context, text string) (*Embedding, error) {
+	id, version := c.next.EmbeddingModel()
+	key := EmbeddingCacheKey(id, version, text)
+
+	for i, tier := range c.tiers {
+		emb, err := tier.GetEmbedding(ctx, key)
+		if err != nil {
+			if err != redis.ErrCacheMiss {
+				c.logger.Warn("embedding cache: read failed", "tier", i, "error", err)
+			}
+			continue
+		}
+		if emb.ModelID != id || emb.ModelVersion != version || emb.Fallback {
+			continue
+		}
+		c.hits.Add(1)
+		c.fill(ctx, key, emb, c.tiers[:i])
+		return emb, nil
+	}
+
+	c.misses.Add(1)
+	emb, err := c.next.EmbedTagged(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if emb.Fallback {
+		c.fallbacks.Add(1)
+		c.logger.Warn("embedding cache: model returned a fallback vector", "model", id, "version", version)
+		return emb, nil
+	}
+	c.fill(ctx, key, emb, c.tiers)
+	return emb, nil
+}
+
+// EmbeddingModel returns the wrapped embedder's model.
+func (c *EmbeddingCache) EmbeddingModel() (string, string) {
+	return c.next.EmbeddingModel()
+}
+
+// Stats returns counters accumulated by this process.
+func (c *EmbeddingCache) Stats() EmbeddingCacheStats {
+	return EmbeddingCacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Fallbacks: c.fallbacks.Load(),
+	}
+}
+
+func (c *EmbeddingCache) fill(ctx context.Context, key string, emb *Embedding, tiers []EmbeddingStore) {
+	for i, tier := range tiers {
+		if err := tier.PutEmbedding(ctx, key, emb); err != nil {
+			c.logger.Warn("embedding cache: write failed", "tier", i, "error", err)
+		}
+	}
+}
+
+//Personal.AI order the ending
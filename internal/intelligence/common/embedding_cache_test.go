@@ -0,0 +1,196 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+)
+
+// scriptedEmbedder returns a vector derived from the text length and counts
+// calls. Texts listed in fallback get a fallback vector.
+type scriptedEmbedder struct {
+	mu       sync.Mutex
+	id, ver  string
+	calls    int
+	fallback map[string]bool
+}
+
+func (s *scriptedEmbedder) EmbedTagged(_ context.Context, text string) (*Embedding, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	return &Embedding{
+		Vector:       []float32{float32(len(text)), 1, 0.5},
+		ModelID:      s.id,
+		ModelVersion: s.ver,
+		Fallback:     s.fallback[text],
+	}, nil
+}
+
+func (s *scriptedEmbedder) EmbeddingModel() (string, string) { return s.id, s.ver }
+
+func (s *scriptedEmbedder) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestEmbeddingCacheKey_VersionScoped(t *testing.T) {
+	a := EmbeddingCacheKey("text-embedding-3-small", "1", "benzene")
+	if a != EmbeddingCacheKey("text-embedding-3-small", "1", "benzene") {
+		t.Error("key must be deterministic")
+	}
+	if a == EmbeddingCacheKey("text-embedding-3-small", "2", "benzene") {
+		t.Error("a new model version must not share keys")
+	}
+	if EmbeddingCacheKey("ab", "c", "x") == EmbeddingCacheKey("a", "bc", "x") {
+		t.Error("key components must be delimited")
+	}
+}
+
+func TestFileEmbeddingStore_RoundTripAndCorruption(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileEmbeddingStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileEmbeddingStore: %v", err)
+	}
+
+	key := EmbeddingCacheKey("m", "1", "aspirin")
+	if _, err := store.GetEmbedding(ctx, key); err != redis.ErrCacheMiss {
+		t.Fatalf("expected miss, got %v", err)
+	}
+	want := &Embedding{Vector: []float32{0.25, -1.5, 3}, ModelID: "m", ModelVersion: "1"}
+	if err := store.PutEmbedding(ctx, key, want); err != nil {
+		t.Fatalf("PutEmbedding: %v", err)
+	}
+
+	reopened, _ := NewFileEmbeddingStore(dir)
+	got, err := reopened.GetEmbedding(ctx, key)
+	if err != nil {
+		t.Fatalf("GetEmbedding: %v", err)
+	}
+	if got.ModelID != "m" || got.ModelVersion != "1" || len(got.Vector) != 3 || got.Vector[1] != -1.5 {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	path := filepath.Join(dir, key[:2], key+".vec")
+	if err := os.WriteFile(path, []byte("KEV1garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetEmbedding(ctx, key); err != redis.ErrCacheMiss {
+		t.Errorf("corrupt file should read as a miss, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("corrupt file should be removed")
+	}
+}
+
+func TestEmbeddingCache_TiersAndBackfill(t *testing.T) {
+	ctx := context.Background()
+	shared := redis.NewMemoryCache()
+	remote, err := NewRedisEmbeddingStore(shared, 0)
+	if err != nil {
+		t.Fatalf("NewRedisEmbeddingStore: %v", err)
+	}
+
+	newCache := func(next TaggedEmbedder) (*EmbeddingCache, *FileEmbeddingStore) {
+		local, err := NewFileEmbeddingStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileEmbeddingStore: %v", err)
+		}
+		c, err := NewEmbeddingCache(next, local, WithEmbeddingCacheTier(remote))
+		if err != nil {
+			t.Fatalf("NewEmbeddingCache: %v", err)
+		}
+		return c, local
+	}
+
+	first := &scriptedEmbedder{id: "m", ver: "1"}
+	c1, _ := newCache(first)
+	if _, err := c1.Embed(ctx, "caffeine"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if _, err := c1.Embed(ctx, "caffeine"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if first.callCount() != 1 {
+		t.Errorf("second lookup should hit the local tier, model called %d times", first.callCount())
+	}
+
+	// Another process with an empty local tier is served from Redis and
+	// keeps a local copy.
+	second := &scriptedEmbedder{id: "m", ver: "1"}
+	c2, local2 := newCache(second)
+	emb, err := c2.EmbedTagged(ctx, "caffeine")
+	if err != nil {
+		t.Fatalf("EmbedTagged: %v", err)
+	}
+	if second.callCount() != 0 || emb.Tag() != "m@1" {
+		t.Errorf("expected a Redis hit tagged m@1, calls %d tag %s", second.callCount(), emb.Tag())
+	}
+	if _, err := local2.GetEmbedding(ctx, EmbeddingCacheKey("m", "1", "caffeine")); err != nil {
+		t.Errorf("Redis hit should be copied to the local tier: %v", err)
+	}
+	if st := c2.Stats(); st.Hits != 1 || st.Misses != 0 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+
+	// A new model version never sees the old vectors.
+	upgraded := &scriptedEmbedder{id: "m", ver: "2"}
+	c3, _ := newCache(upgraded)
+	emb, _ = c3.EmbedTagged(ctx, "caffeine")
+	if upgraded.callCount() != 1 || emb.ModelVersion != "2" {
+		t.Errorf("version bump should recompute, calls %d version %s", upgraded.callCount(), emb.ModelVersion)
+	}
+}
+
+func TestEmbeddingCache_FallbackNeverCached(t *testing.T) {
+	ctx := context.Background()
+	next := &scriptedEmbedder{id: "m", ver: "1", fallback: map[string]bool{"??": true}}
+	local, _ := NewFileEmbeddingStore(t.TempDir())
+	c, _ := NewEmbeddingCache(next, local)
+
+	if _, err := c.Embed(ctx, "??"); !errors.Is(err, ErrFallbackEmbedding) {
+		t.Fatalf("Embed should refuse fallback vectors, got %v", err)
+	}
+	emb, err := c.EmbedTagged(ctx, "??")
+	if err != nil || !emb.Fallback || emb.Tag() != FallbackEmbeddingTag {
+		t.Fatalf("EmbedTagged should return the tagged fallback, got %+v %v", emb, err)
+	}
+	if next.callCount() != 2 {
+		t.Errorf("fallback vectors must not be cached, model called %d times", next.callCount())
+	}
+	if st := c.Stats(); st.Fallbacks != 2 {
+		t.Errorf("expected 2 fallbacks, got %+v", st)
+	}
+}
+
+func TestEmbeddingClient_TagsFallbackVectors(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Primary.Provider = "anthropic"
+	cfg.LLM.Primary.EmbeddingModelVersion = "3"
+	// echoBackend answers under "content", so prompt-based extraction finds
+	// no numbers and falls back to hashing.
+	client := NewEmbeddingClient(cfg, &echoBackend{})
+
+	emb, err := client.EmbedTagged(context.Background(), "CCO")
+	if err != nil {
+		t.Fatalf("EmbedTagged: %v", err)
+	}
+	if !emb.Fallback || emb.ModelVersion != "3" || len(emb.Vector) != 768 {
+		t.Errorf("expected a tagged 768-d fallback at version 3, got fallback=%v version=%s dims=%d",
+			emb.Fallback, emb.ModelVersion, len(emb.Vector))
+	}
+	if _, err := client.Embed(context.Background(), "CCO"); !errors.Is(err, ErrFallbackEmbedding) {
+		t.Errorf("Embed should not return fallback vectors silently, got %v", err)
+	}
+}
+
+//Personal.AI order the ending
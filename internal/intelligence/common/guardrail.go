@@ -0,0 +1,777 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Policy types
+// ---------------------------------------------------------------------------
+
+// RedactionCategory labels a kind of confidential data. It also names the
+// placeholders written in its place, e.g. [[EMAIL_1]].
+type RedactionCategory string
+
+const (
+	RedactEmail             RedactionCategory = "EMAIL"
+	RedactApplicationNumber RedactionCategory = "APPNO"
+	RedactCompoundCode      RedactionCategory = "COMPOUND"
+	RedactName              RedactionCategory = "NAME"
+	RedactTerm              RedactionCategory = "TERM"
+	RedactCustom            RedactionCategory = "CUSTOM"
+)
+
+// RedactionAction decides what happens when confidential data is found.
+type RedactionAction string
+
+const (
+	// RedactionActionRedact replaces the data with placeholders and restores
+	// it in the response.
+	RedactionActionRedact RedactionAction = "redact"
+	// RedactionActionBlock refuses the call.
+	RedactionActionBlock RedactionAction = "block"
+)
+
+// MetaRedactions carries the number of redacted spans on requests and
+// responses that passed through a RedactionGuard.
+const MetaRedactions = "redactions"
+
+// RedactionPolicy is one tenant's guardrail policy.
+type RedactionPolicy struct {
+	// BlockExternal refuses every call to an external provider.
+	BlockExternal bool
+	Action        RedactionAction
+	// Categories selects the built-in detectors (EMAIL, APPNO, COMPOUND,
+	// NAME); empty enables all. Dictionaries and patterns always apply.
+	Categories []RedactionCategory
+	// Names are people (inventors, employees), redacted as NAME.
+	Names []string
+	// Terms are confidential words such as project code names, redacted as TERM.
+	Terms []string
+	// Patterns are tenant regexes, redacted as CUSTOM. A named group "v"
+	// limits the redaction to that group.
+	Patterns []string
+	// CompoundPatterns are regexes for internal compound IDs, redacted as
+	// COMPOUND.
+	CompoundPatterns []string
+}
+
+// RedactionPolicySource returns the policy for a tenant; an empty tenant asks
+// for the default policy. A nil policy disables the guardrail.
+type RedactionPolicySource interface {
+	PolicyFor(ctx context.Context, tenant string) (*RedactionPolicy, error)
+}
+
+type staticRedactionPolicies struct {
+	def     *RedactionPolicy
+	tenants map[string]*RedactionPolicy
+}
+
+// NewStaticRedactionPolicies serves a fixed set of policies. Tenant policies
+// extend def: dictionaries and patterns are added, BlockExternal is sticky,
+// and Action and Categories override when set. Every policy is compiled up
+// front so bad patterns fail here rather than on a request.
+func NewStaticRedactionPolicies(def *RedactionPolicy, tenants map[string]*RedactionPolicy) (RedactionPolicySource, error) {
+	if def == nil {
+		def = &RedactionPolicy{}
+	}
+	s := &staticRedactionPolicies{def: def, tenants: make(map[string]*RedactionPolicy, len(tenants))}
+	if _, err := compileRedactionPolicy(def); err != nil {
+		return nil, err
+	}
+	for tenant, p := range tenants {
+		if p == nil {
+			continue
+		}
+		merged := mergeRedactionPolicies(def, p)
+		if _, err := compileRedactionPolicy(merged); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+		s.tenants[tenant] = merged
+	}
+	return s, nil
+}
+
+func (s *staticRedactionPolicies) PolicyFor(_ context.Context, tenant string) (*RedactionPolicy, error) {
+	if p, ok := s.tenants[tenant]; ok {
+		return p, nil
+	}
+	return s.def, nil
+}
+
+// RedactionPoliciesFromConfig builds a policy source from llm.guardrail.
+func RedactionPoliciesFromConfig(cfg config.LLMGuardrailConfig) (RedactionPolicySource, error) {
+	tenants := make(map[string]*RedactionPolicy, len(cfg.Tenants))
+	for tenant, p := range cfg.Tenants {
+		tenants[tenant] = redactionPolicyFromConfig(p)
+	}
+	return NewStaticRedactionPolicies(redactionPolicyFromConfig(cfg.Default), tenants)
+}
+
+func redactionPolicyFromConfig(p config.LLMRedactionPolicy) *RedactionPolicy {
+	out := &RedactionPolicy{
+		BlockExternal:    p.BlockExternal,
+		Action:           RedactionAction(strings.ToLower(p.Action)),
+		Names:            p.Names,
+		Terms:            p.Terms,
+		Patterns:         p.Patterns,
+		CompoundPatterns: p.CompoundPatterns,
+	}
+	for _, c := range p.Categories {
+		out.Categories = append(out.Categories, RedactionCategory(strings.ToUpper(c)))
+	}
+	return out
+}
+
+func mergeRedactionPolicies(def, t *RedactionPolicy) *RedactionPolicy {
+	join := func(a, b []string) []string { return append(append([]string(nil), a...), b...) }
+	out := &RedactionPolicy{
+		BlockExternal:    def.BlockExternal || t.BlockExternal,
+		Action:           def.Action,
+		Categories:       def.Categories,
+		Names:            join(def.Names, t.Names),
+		Terms:            join(def.Terms, t.Terms),
+		Patterns:         join(def.Patterns, t.Patterns),
+		CompoundPatterns: join(def.CompoundPatterns, t.CompoundPatterns),
+	}
+	if t.Action != "" {
+		out.Action = t.Action
+	}
+	if len(t.Categories) > 0 {
+		out.Categories = t.Categories
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Detectors
+// ---------------------------------------------------------------------------
+
+type redactionDetector struct {
+	category RedactionCategory
+	re       *regexp.Regexp
+	group    int // submatch to redact; 0 redacts the whole match
+}
+
+// Person names in running text are only caught via a label; the policy's
+// name dictionary covers the rest.
+const nameTokenPattern = `\p{Lu}[\p{Ll}'’-]+(?:\s+\p{Lu}\.)?(?:\s+\p{Lu}[\p{Ll}'’-]+)+`
+
+var builtinRedactionDetectors = map[RedactionCategory][]string{
+	RedactEmail: {
+		`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	},
+	RedactApplicationNumber: {
+		// Application (not publication) numbers identify filings that may
+		// still be unpublished: US serials, PCT, CN and EP applications.
+		`\b\d{2}/\d{3},?\d{3}\b`,
+		`\bPCT/[A-Z]{2}\d{4}/\d{5,6}\b`,
+		`\b(?:CN\s?)?20\d{2}[1-9]\d{7}\.[\dX]\b`,
+		`\b(?:EP\s?)?\d{8}\.\d\b`,
+		`\b(?i:application\s+(?:no\.?|number|serial\s+no\.?))\s*:?\s*(?P<v>[A-Z]{0,3}\d[\d/.,\-]{5,}[\dX])`,
+	},
+	RedactCompoundCode: {
+		`\b(?:CMPD|CPD|CMP|MOL)[-_]?\d{3,}[A-Z]?\b`,
+	},
+	RedactName: {
+		`\b(?i:inventors?|inventor\s+names?)\s*:\s*(?P<v>` + nameTokenPattern + `(?:\s*(?:,|;|and)\s*` + nameTokenPattern + `)*)`,
+	},
+}
+
+// builtinRedactionOrder fixes detector precedence for equal-length overlaps.
+var builtinRedactionOrder = []RedactionCategory{RedactEmail, RedactApplicationNumber, RedactCompoundCode, RedactName}
+
+type compiledRedaction struct {
+	policy    *RedactionPolicy
+	detectors []redactionDetector
+}
+
+func compileRedactionPolicy(p *RedactionPolicy) (*compiledRedaction, error) {
+	switch p.Action {
+	case "", RedactionActionRedact, RedactionActionBlock:
+	default:
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("unknown redaction action %q", p.Action))
+	}
+	enabled := make(map[RedactionCategory]bool)
+	for _, c := range p.Categories {
+		if _, ok := builtinRedactionDetectors[c]; !ok {
+			return nil, errors.NewInvalidInputError(fmt.Sprintf("unknown redaction category %q", c))
+		}
+		enabled[c] = true
+	}
+
+	c := &compiledRedaction{policy: p}
+	add := func(category RedactionCategory, pattern string) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.NewInvalidInputError(fmt.Sprintf("invalid %s pattern %q: %v", category, pattern, err))
+		}
+		d := redactionDetector{category: category, re: re}
+		if i := re.SubexpIndex("v"); i > 0 {
+			d.group = i
+		}
+		c.detectors = append(c.detectors, d)
+		return nil
+	}
+	for _, category := range builtinRedactionOrder {
+		if len(enabled) > 0 && !enabled[category] {
+			continue
+		}
+		for _, pattern := range builtinRedactionDetectors[category] {
+			if err := add(category, pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, set := range []struct {
+		category RedactionCategory
+		patterns []string
+	}{{RedactCompoundCode, p.CompoundPatterns}, {RedactCustom, p.Patterns}} {
+		for _, pattern := range set.patterns {
+			if err := add(set.category, pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, dict := range []struct {
+		category RedactionCategory
+		terms    []string
+	}{{RedactName, p.Names}, {RedactTerm, p.Terms}} {
+		if pattern := dictionaryPattern(dict.terms); pattern != "" {
+			if err := add(dict.category, pattern); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// dictionaryPattern matches any term case-insensitively, longest first, on
+// word boundaries where the term starts or ends with a word character.
+func dictionaryPattern(terms []string) string {
+	var alts []string
+	seen := make(map[string]bool)
+	for _, t := range terms {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		alt := regexp.QuoteMeta(t)
+		if r, _ := utf8.DecodeRuneInString(t); isWordRune(r) {
+			alt = `\b` + alt
+		}
+		if r, _ := utf8.DecodeLastRuneInString(t); isWordRune(r) {
+			alt += `\b`
+		}
+		alts = append(alts, alt)
+	}
+	if len(alts) == 0 {
+		return ""
+	}
+	sort.SliceStable(alts, func(i, j int) bool { return len(alts[i]) > len(alts[j]) })
+	return `(?i)(?:` + strings.Join(alts, "|") + `)`
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ---------------------------------------------------------------------------
+// Vault: reversible placeholders for one request
+// ---------------------------------------------------------------------------
+
+var redactionPlaceholderPattern = regexp.MustCompile(`\[\[([A-Z]+_\d+)\]\]`)
+
+// maxPlaceholderLen bounds how much streamed text is held back while a
+// placeholder may still be arriving.
+const maxPlaceholderLen = 24
+
+type redactionEntry struct {
+	placeholder string
+	category    RedactionCategory
+	value       string
+	occurrences int
+}
+
+type redactionVault struct {
+	mu      sync.Mutex
+	byValue map[string]*redactionEntry // category + "\x00" + value
+	byToken map[string]*redactionEntry // placeholder without brackets
+	counts  map[RedactionCategory]int
+	entries []*redactionEntry
+}
+
+func newRedactionVault() *redactionVault {
+	return &redactionVault{
+		byValue: make(map[string]*redactionEntry),
+		byToken: make(map[string]*redactionEntry),
+		counts:  make(map[RedactionCategory]int),
+	}
+}
+
+// placeholder returns the stable placeholder for value, so repeated mentions
+// read consistently to the model.
+func (v *redactionVault) placeholder(category RedactionCategory, value string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := string(category) + "\x00" + value
+	if e, ok := v.byValue[key]; ok {
+		e.occurrences++
+		return e.placeholder
+	}
+	v.counts[category]++
+	token := fmt.Sprintf("%s_%d", category, v.counts[category])
+	e := &redactionEntry{placeholder: "[[" + token + "]]", category: category, value: value, occurrences: 1}
+	v.byValue[key], v.byToken[token] = e, e
+	v.entries = append(v.entries, e)
+	return e.placeholder
+}
+
+func (v *redactionVault) spans() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	n := 0
+	for _, e := range v.entries {
+		n += e.occurrences
+	}
+	return n
+}
+
+func (v *redactionVault) restore(s string) string {
+	return redactionPlaceholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if e, ok := v.byToken[m[2:len(m)-2]]; ok {
+			return e.value
+		}
+		return m
+	})
+}
+
+type redactionSpan struct {
+	start, end int
+	category   RedactionCategory
+}
+
+func (c *compiledRedaction) redactString(s string, v *redactionVault) string {
+	var spans []redactionSpan
+	for _, d := range c.detectors {
+		for _, m := range d.re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := m[0], m[1]
+			if d.group > 0 {
+				start, end = m[2*d.group], m[2*d.group+1]
+			}
+			if start >= 0 && end > start {
+				spans = append(spans, redactionSpan{start, end, d.category})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return s
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+	var b strings.Builder
+	pos := 0
+	for _, sp := range spans {
+		if sp.start < pos {
+			continue
+		}
+		b.WriteString(s[pos:sp.start])
+		b.WriteString(v.placeholder(sp.category, s[sp.start:sp.end]))
+		pos = sp.end
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+// redactInput redacts text input directly and JSON input string by string,
+// so the payload stays valid JSON.
+func (c *compiledRedaction) redactInput(data []byte, format InputFormat, v *redactionVault) []byte {
+	if format == FormatJSON {
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err == nil {
+			before := v.spans()
+			doc = c.redactJSON(doc, v)
+			if v.spans() == before {
+				return data
+			}
+			if out, err := json.Marshal(doc); err == nil {
+				return out
+			}
+		}
+	}
+	return []byte(c.redactString(string(data), v))
+}
+
+func (c *compiledRedaction) redactJSON(doc interface{}, v *redactionVault) interface{} {
+	switch t := doc.(type) {
+	case string:
+		return c.redactString(t, v)
+	case []interface{}:
+		for i := range t {
+			t[i] = c.redactJSON(t[i], v)
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = c.redactJSON(t[k], v)
+		}
+	}
+	return doc
+}
+
+// ---------------------------------------------------------------------------
+// Audit
+// ---------------------------------------------------------------------------
+
+// RedactionAuditEvent records one redacted value or one blocked call. The
+// value itself is never recorded, only a tenant-salted fingerprint.
+type RedactionAuditEvent struct {
+	Time        time.Time         `json:"time"`
+	Tenant      string            `json:"tenant,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	Provider    string            `json:"provider,omitempty"`
+	Model       string            `json:"model,omitempty"`
+	Action      string            `json:"action"` // "redacted" or "blocked"
+	Category    RedactionCategory `json:"category,omitempty"`
+	Placeholder string            `json:"placeholder,omitempty"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	Occurrences int               `json:"occurrences,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+}
+
+// RedactionAuditor persists redaction audit events.
+type RedactionAuditor interface {
+	RecordRedaction(ctx context.Context, event *RedactionAuditEvent) error
+}
+
+type loggingRedactionAuditor struct {
+	logger Logger
+}
+
+// NewLoggingRedactionAuditor writes audit events to the structured log.
+func NewLoggingRedactionAuditor(logger Logger) RedactionAuditor {
+	if logger == nil {
+		logger = NewNoopLogger()
+	}
+	return &loggingRedactionAuditor{logger: logger}
+}
+
+func (a *loggingRedactionAuditor) RecordRedaction(_ context.Context, e *RedactionAuditEvent) error {
+	a.logger.Info("llm guardrail audit",
+		"action", e.Action, "tenant", e.Tenant, "request_id", e.RequestID,
+		"provider", e.Provider, "model", e.Model, "category", string(e.Category),
+		"placeholder", e.Placeholder, "fingerprint", e.Fingerprint,
+		"occurrences", e.Occurrences, "reason", e.Reason)
+	return nil
+}
+
+func redactionFingerprint(tenant, value string) string {
+	sum := sha256.Sum256([]byte(tenant + "\x00" + value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ---------------------------------------------------------------------------
+// RedactionGuard
+// ---------------------------------------------------------------------------
+
+// RedactionGuardConfig describes the provider a guard protects.
+type RedactionGuardConfig struct {
+	// Provider labels audit events, e.g. "anthropic".
+	Provider string
+	// External marks a third-party provider; BlockExternal policies refuse
+	// calls to it.
+	External bool
+}
+
+// RedactionGuardOption configures a RedactionGuard.
+type RedactionGuardOption func(*RedactionGuard)
+
+// WithRedactionAuditor sets where audit events go; the default logs them.
+func WithRedactionAuditor(a RedactionAuditor) RedactionGuardOption {
+	return func(g *RedactionGuard) { g.auditor = a }
+}
+
+// WithRedactionLogger injects a logger.
+func WithRedactionLogger(l Logger) RedactionGuardOption {
+	return func(g *RedactionGuard) { g.logger = l }
+}
+
+// WithRedactionTenantResolver overrides how the tenant is read from the context.
+func WithRedactionTenantResolver(fn func(ctx context.Context) (string, bool)) RedactionGuardOption {
+	return func(g *RedactionGuard) { g.tenantOf = fn }
+}
+
+// RedactionGuard keeps confidential data out of outbound LLM calls. As a
+// RequestInterceptor it replaces confidential spans with placeholders before
+// the call and restores them in the response; NewRedactingBackend applies it
+// to any ModelBackend, including streaming.
+type RedactionGuard struct {
+	cfg      RedactionGuardConfig
+	policies RedactionPolicySource
+	auditor  RedactionAuditor
+	logger   Logger
+	tenantOf func(ctx context.Context) (string, bool)
+	compiled sync.Map // *RedactionPolicy -> *compiledRedaction
+}
+
+var _ RequestInterceptor = (*RedactionGuard)(nil)
+
+type redactionVaultKey struct{}
+
+// NewRedactionGuard creates a guard enforcing the given policies.
+func NewRedactionGuard(cfg RedactionGuardConfig, policies RedactionPolicySource, opts ...RedactionGuardOption) (*RedactionGuard, error) {
+	if policies == nil {
+		return nil, errors.NewInvalidInputError("redaction policy source is required")
+	}
+	g := &RedactionGuard{cfg: cfg, policies: policies}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.logger == nil {
+		g.logger = NewNoopLogger()
+	}
+	if g.auditor == nil {
+		g.auditor = NewLoggingRedactionAuditor(g.logger)
+	}
+	if g.tenantOf == nil {
+		g.tenantOf = contextTenantID
+	}
+	return g, nil
+}
+
+func (g *RedactionGuard) compile(p *RedactionPolicy) (*compiledRedaction, error) {
+	if c, ok := g.compiled.Load(p); ok {
+		return c.(*compiledRedaction), nil
+	}
+	c, err := compileRedactionPolicy(p)
+	if err != nil {
+		return nil, err
+	}
+	g.compiled.Store(p, c)
+	return c, nil
+}
+
+// BeforeRequest enforces the tenant's policy and redacts the input. The
+// returned request is a copy; the caller's request is left untouched.
+func (g *RedactionGuard) BeforeRequest(ctx context.Context, req *PredictRequest) (context.Context, *PredictRequest, error) {
+	if req == nil {
+		return ctx, req, nil
+	}
+	tenant := requestTenant(ctx, req, g.tenantOf, "")
+	policy, err := g.policies.PolicyFor(ctx, tenant)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, errors.ErrCodeServiceUnavailable, "resolve redaction policy")
+	}
+	if policy == nil {
+		return ctx, req, nil
+	}
+	audit := func(e *RedactionAuditEvent) {
+		e.Time, e.Tenant, e.Provider, e.Model = time.Now().UTC(), tenant, g.cfg.Provider, req.ModelName
+		e.RequestID = req.Metadata["request_id"]
+		if err := g.auditor.RecordRedaction(ctx, e); err != nil {
+			g.logger.Error("llm guardrail: audit write failed", "error", err)
+		}
+	}
+
+	if policy.BlockExternal && g.cfg.External {
+		audit(&RedactionAuditEvent{Action: "blocked", Reason: "tenant policy forbids external providers"})
+		return ctx, nil, errors.New(errors.ErrCodeForbidden,
+			fmt.Sprintf("tenant policy forbids sending data to external LLM provider %s", g.providerName()))
+	}
+
+	compiled, err := g.compile(policy)
+	if err != nil {
+		return ctx, nil, err
+	}
+	vault := newRedactionVault()
+	data := compiled.redactInput(req.InputData, req.InputFormat, vault)
+	if len(vault.entries) == 0 {
+		return ctx, req, nil
+	}
+
+	if policy.Action == RedactionActionBlock {
+		var cats []string
+		seen := make(map[RedactionCategory]bool)
+		for _, e := range vault.entries {
+			if !seen[e.category] {
+				seen[e.category] = true
+				cats = append(cats, string(e.category))
+			}
+		}
+		audit(&RedactionAuditEvent{Action: "blocked", Reason: "confidential data: " + strings.Join(cats, ", ")})
+		return ctx, nil, errors.New(errors.ErrCodeForbidden,
+			"request contains confidential data ("+strings.Join(cats, ", ")+") and tenant policy blocks it")
+	}
+
+	for _, e := range vault.entries {
+		audit(&RedactionAuditEvent{
+			Action:      "redacted",
+			Category:    e.category,
+			Placeholder: e.placeholder,
+			Fingerprint: redactionFingerprint(tenant, e.value),
+			Occurrences: e.occurrences,
+		})
+	}
+	out := *req
+	out.InputData = data
+	out.Metadata = make(map[string]string, len(req.Metadata)+1)
+	for k, val := range req.Metadata {
+		out.Metadata[k] = val
+	}
+	out.Metadata[MetaRedactions] = strconv.Itoa(vault.spans())
+	return context.WithValue(ctx, redactionVaultKey{}, vault), &out, nil
+}
+
+// AfterResponse restores redacted values in every output.
+func (g *RedactionGuard) AfterResponse(ctx context.Context, resp *PredictResponse, err error) (*PredictResponse, error) {
+	vault, _ := ctx.Value(redactionVaultKey{}).(*redactionVault)
+	if err != nil || resp == nil || vault == nil {
+		return resp, err
+	}
+	out := *resp
+	out.Outputs = make(map[string][]byte, len(resp.Outputs))
+	for k, v := range resp.Outputs {
+		out.Outputs[k] = []byte(vault.restore(string(v)))
+	}
+	out.Metadata = make(map[string]string, len(resp.Metadata)+1)
+	for k, v := range resp.Metadata {
+		out.Metadata[k] = v
+	}
+	out.Metadata[MetaRedactions] = strconv.Itoa(vault.spans())
+	return &out, nil
+}
+
+func (g *RedactionGuard) providerName() string {
+	if g.cfg.Provider != "" {
+		return g.cfg.Provider
+	}
+	return "(unnamed)"
+}
+
+// ---------------------------------------------------------------------------
+// redactingBackend
+// ---------------------------------------------------------------------------
+
+type redactingBackend struct {
+	next  ModelBackend
+	guard *RedactionGuard
+}
+
+// NewRedactingBackend applies guard to every call made through next.
+func NewRedactingBackend(next ModelBackend, guard *RedactionGuard) (ModelBackend, error) {
+	if next == nil {
+		return nil, errors.NewInvalidInputError("backend is required")
+	}
+	if guard == nil {
+		return nil, errors.NewInvalidInputError("redaction guard is required")
+	}
+	return &redactingBackend{next: next, guard: guard}, nil
+}
+
+func (b *redactingBackend) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	ctx, redacted, err := b.guard.BeforeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.next.Predict(ctx, redacted)
+	return b.guard.AfterResponse(ctx, resp, err)
+}
+
+// PredictStream restores placeholders across chunk boundaries by holding
+// back a possibly incomplete placeholder until the next chunk arrives.
+func (b *redactingBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	ctx, redacted, err := b.guard.BeforeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	in, err := b.next.PredictStream(ctx, redacted)
+	vault, _ := ctx.Value(redactionVaultKey{}).(*redactionVault)
+	if err != nil || vault == nil {
+		return in, err
+	}
+	out := make(chan *PredictResponse)
+	go func() {
+		defer close(out)
+		pending := make(map[string]string)
+		var last *PredictResponse
+		for resp := range in {
+			if resp == nil {
+				continue
+			}
+			last = resp
+			chunk := *resp
+			chunk.Outputs = make(map[string][]byte, len(resp.Outputs))
+			for k, v := range resp.Outputs {
+				text := pending[k] + string(v)
+				emit, hold := splitPendingPlaceholder(text)
+				pending[k] = hold
+				chunk.Outputs[k] = []byte(vault.restore(emit))
+			}
+			select {
+			case out <- &chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+		flush := &PredictResponse{Outputs: make(map[string][]byte)}
+		if last != nil {
+			flush.ModelName, flush.ModelVersion, flush.OutputFormat = last.ModelName, last.ModelVersion, last.OutputFormat
+		}
+		for k, hold := range pending {
+			if hold != "" {
+				flush.Outputs[k] = []byte(vault.restore(hold))
+			}
+		}
+		if len(flush.Outputs) > 0 {
+			select {
+			case out <- flush:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (b *redactingBackend) Healthy(ctx context.Context) error { return b.next.Healthy(ctx) }
+func (b *redactingBackend) Close() error                      { return b.next.Close() }
+
+// splitPendingPlaceholder separates a trailing, possibly incomplete
+// placeholder from text that is safe to emit.
+func splitPendingPlaceholder(text string) (emit, hold string) {
+	if strings.HasSuffix(text, "[") && !strings.HasSuffix(text, "]]") {
+		i := strings.LastIndex(text, "[[")
+		if i < 0 || i < len(text)-2 {
+			i = len(text) - 1
+		}
+		return text[:i], text[i:]
+	}
+	i := strings.LastIndex(text, "[[")
+	if i < 0 || strings.Contains(text[i:], "]]") || len(text)-i > maxPlaceholderLen {
+		return text, ""
+	}
+	return text[:i], text[i:]
+}
+
+//Personal.AI order the ending
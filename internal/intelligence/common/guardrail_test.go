@@ -0,0 +1,309 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// ---------------------------------------------------------------------------
+// Test doubles
+// ---------------------------------------------------------------------------
+
+// echoBackend answers with the input it received, so tests see exactly what
+// would have left the process.
+type echoBackend struct {
+	mu     sync.Mutex
+	seen   []string
+	chunks int // PredictStream splits the echo into this many chunks
+}
+
+func (e *echoBackend) Predict(_ context.Context, req *PredictRequest) (*PredictResponse, error) {
+	e.mu.Lock()
+	e.seen = append(e.seen, string(req.InputData))
+	e.mu.Unlock()
+	return &PredictResponse{
+		ModelName: req.ModelName,
+		Outputs:   map[string][]byte{"content": append([]byte(nil), req.InputData...)},
+	}, nil
+}
+
+func (e *echoBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	resp, _ := e.Predict(ctx, req)
+	text := string(resp.Outputs["content"])
+	n := e.chunks
+	if n <= 0 {
+		n = 1
+	}
+	ch := make(chan *PredictResponse, n+1)
+	size := (len(text) + n - 1) / n
+	for i := 0; i < len(text); i += size {
+		end := i + size
+		if end > len(text) {
+			end = len(text)
+		}
+		ch <- &PredictResponse{ModelName: req.ModelName, Outputs: map[string][]byte{"content": []byte(text[i:end])}}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (e *echoBackend) Healthy(context.Context) error { return nil }
+func (e *echoBackend) Close() error                  { return nil }
+
+func (e *echoBackend) last() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.seen) == 0 {
+		return ""
+	}
+	return e.seen[len(e.seen)-1]
+}
+
+type recordingAuditor struct {
+	mu     sync.Mutex
+	events []*RedactionAuditEvent
+}
+
+func (r *recordingAuditor) RecordRedaction(_ context.Context, e *RedactionAuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	return nil
+}
+
+func newTestRedactingBackend(t *testing.T, def *RedactionPolicy, tenants map[string]*RedactionPolicy) (ModelBackend, *echoBackend, *recordingAuditor) {
+	t.Helper()
+	policies, err := NewStaticRedactionPolicies(def, tenants)
+	if err != nil {
+		t.Fatalf("NewStaticRedactionPolicies: %v", err)
+	}
+	auditor := &recordingAuditor{}
+	guard, err := NewRedactionGuard(RedactionGuardConfig{Provider: "openai", External: true}, policies, WithRedactionAuditor(auditor))
+	if err != nil {
+		t.Fatalf("NewRedactionGuard: %v", err)
+	}
+	next := &echoBackend{}
+	b, err := NewRedactingBackend(next, guard)
+	if err != nil {
+		t.Fatalf("NewRedactingBackend: %v", err)
+	}
+	return b, next, auditor
+}
+
+func tenantContext(tenant string) context.Context {
+	return context.WithValue(context.Background(), commontypes.ContextKeyTenantID, tenant)
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestRedactingBackend_RedactsAndRestoresText(t *testing.T) {
+	b, next, auditor := newTestRedactingBackend(t, &RedactionPolicy{}, nil)
+	prompt := "Contact jane.doe@acme.com about application no. 17/123,456 and CMPD-00421. " +
+		"Inventors: Jane Doe and Wei Zhang. Follow up with jane.doe@acme.com."
+
+	resp, err := b.Predict(context.Background(), textRequest(prompt, nil))
+	if err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	sent := next.last()
+	for _, secret := range []string{"jane.doe@acme.com", "17/123,456", "CMPD-00421", "Jane Doe", "Wei Zhang"} {
+		if strings.Contains(sent, secret) {
+			t.Errorf("outbound prompt leaks %q: %s", secret, sent)
+		}
+	}
+	if got := strings.Count(sent, "[[EMAIL_1]]"); got != 2 {
+		t.Errorf("repeated email should reuse one placeholder, got %d in %s", got, sent)
+	}
+	for _, ph := range []string{"[[APPNO_1]]", "[[COMPOUND_1]]", "[[NAME_1]]"} {
+		if !strings.Contains(sent, ph) {
+			t.Errorf("expected %s in %s", ph, sent)
+		}
+	}
+	if content(resp) != prompt {
+		t.Errorf("response not restored:\n got %s\nwant %s", content(resp), prompt)
+	}
+	if resp.Metadata[MetaRedactions] != "5" {
+		t.Errorf("redactions = %q, want 5", resp.Metadata[MetaRedactions])
+	}
+
+	if len(auditor.events) != 4 {
+		t.Fatalf("audit events = %d, want 4", len(auditor.events))
+	}
+	for _, e := range auditor.events {
+		if e.Action != "redacted" || e.Fingerprint == "" || e.Provider != "openai" {
+			t.Errorf("unexpected audit event %+v", e)
+		}
+		raw, _ := json.Marshal(e)
+		if strings.Contains(string(raw), "acme.com") || strings.Contains(string(raw), "Jane") {
+			t.Errorf("audit event leaks the raw value: %s", raw)
+		}
+	}
+}
+
+func TestRedactingBackend_JSONInputStaysValid(t *testing.T) {
+	b, next, _ := newTestRedactingBackend(t, &RedactionPolicy{Terms: []string{"Project Falcon"}}, nil)
+	input := `{"messages":[{"role":"user","content":"Summarise project falcon results for PCT/US2023/012345"}]}`
+	req := &PredictRequest{ModelName: "llm", InputFormat: FormatJSON, InputData: []byte(input)}
+
+	if _, err := b.Predict(context.Background(), req); err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	var doc struct {
+		Messages []struct{ Content string } `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(next.last()), &doc); err != nil {
+		t.Fatalf("redacted payload is not valid JSON: %v", err)
+	}
+	if got, want := doc.Messages[0].Content, "Summarise [[TERM_1]] results for [[APPNO_1]]"; got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if string(req.InputData) != input {
+		t.Error("caller's request was modified")
+	}
+}
+
+func TestRedactingBackend_CleanRequestPassesThrough(t *testing.T) {
+	b, next, auditor := newTestRedactingBackend(t, &RedactionPolicy{}, nil)
+	prompt := "Explain claim 1 of US 10,000,001 B2."
+	resp, err := b.Predict(context.Background(), textRequest(prompt, nil))
+	if err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if next.last() != prompt || content(resp) != prompt {
+		t.Errorf("clean request altered: sent %q, got %q", next.last(), content(resp))
+	}
+	if len(auditor.events) != 0 {
+		t.Errorf("expected no audit events, got %d", len(auditor.events))
+	}
+}
+
+func TestRedactingBackend_TenantPolicies(t *testing.T) {
+	def := &RedactionPolicy{Categories: []RedactionCategory{RedactEmail}}
+	tenants := map[string]*RedactionPolicy{
+		"acme":   {Names: []string{"Dr. Ada Lovelace"}, Patterns: []string{`\bACME-(?P<v>\d{4})\b`}},
+		"secret": {BlockExternal: true},
+		"strict": {Action: RedactionActionBlock},
+	}
+	b, next, auditor := newTestRedactingBackend(t, def, tenants)
+	prompt := "dr. ada lovelace reviewed ACME-2024 with CMPD-123 at ada@acme.com"
+
+	if _, err := b.Predict(tenantContext("acme"), textRequest(prompt, nil)); err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if got, want := next.last(), "[[NAME_1]] reviewed ACME-[[CUSTOM_1]] with CMPD-123 at [[EMAIL_1]]"; got != want {
+		t.Errorf("acme prompt = %q, want %q", got, want)
+	}
+
+	// Other tenants only get the default policy.
+	if _, err := b.Predict(tenantContext("globex"), textRequest(prompt, nil)); err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if !strings.Contains(next.last(), "ACME-2024") || strings.Contains(next.last(), "ada@acme.com") {
+		t.Errorf("globex prompt = %q", next.last())
+	}
+
+	calls := len(next.seen)
+	_, err := b.Predict(tenantContext("secret"), textRequest("nothing confidential", nil))
+	if !errors.IsForbidden(err) {
+		t.Fatalf("block_external: err = %v, want forbidden", err)
+	}
+	_, err = b.Predict(context.Background(), textRequest("mail ada@acme.com", map[string]string{MetaTenantID: "strict"}))
+	if !errors.IsForbidden(err) || !strings.Contains(err.Error(), "EMAIL") {
+		t.Fatalf("block action: err = %v, want forbidden naming EMAIL", err)
+	}
+	if len(next.seen) != calls {
+		t.Error("blocked requests reached the backend")
+	}
+	blocked := 0
+	for _, e := range auditor.events {
+		if e.Action == "blocked" {
+			blocked++
+		}
+	}
+	if blocked != 2 {
+		t.Errorf("blocked audit events = %d, want 2", blocked)
+	}
+}
+
+func TestRedactingBackend_StreamRestoresSplitPlaceholders(t *testing.T) {
+	b, next, _ := newTestRedactingBackend(t, &RedactionPolicy{}, nil)
+	next.chunks = 9
+	prompt := "Send the CMPD-00421 assay to jane.doe@acme.com and cc wei@acme.com today"
+
+	ch, err := b.PredictStream(context.Background(), textRequest(prompt, nil))
+	if err != nil {
+		t.Fatalf("PredictStream: %v", err)
+	}
+	var got strings.Builder
+	for resp := range ch {
+		chunk := string(resp.Outputs["content"])
+		if strings.Contains(chunk, "[[") || strings.Contains(chunk, "]]") {
+			t.Errorf("chunk leaks placeholder fragment: %q", chunk)
+		}
+		got.WriteString(chunk)
+	}
+	if got.String() != prompt {
+		t.Errorf("stream = %q, want %q", got.String(), prompt)
+	}
+}
+
+func TestSplitPendingPlaceholder(t *testing.T) {
+	tests := []struct{ in, emit, hold string }{
+		{"plain text", "plain text", ""},
+		{"ends with [", "ends with ", "["},
+		{"ends with [[", "ends with ", "[["},
+		{"partial [[EMAIL_", "partial ", "[[EMAIL_"},
+		{"done [[EMAIL_1]]", "done [[EMAIL_1]]", ""},
+		{"[[EMAIL_1]] then [", "[[EMAIL_1]] then ", "["},
+		{"stray [[ " + strings.Repeat("x", 30), "stray [[ " + strings.Repeat("x", 30), ""},
+	}
+	for _, tt := range tests {
+		emit, hold := splitPendingPlaceholder(tt.in)
+		if emit != tt.emit || hold != tt.hold {
+			t.Errorf("splitPendingPlaceholder(%q) = (%q, %q), want (%q, %q)", tt.in, emit, hold, tt.emit, tt.hold)
+		}
+	}
+}
+
+func TestRedactionPoliciesFromConfig(t *testing.T) {
+	src, err := RedactionPoliciesFromConfig(config.LLMGuardrailConfig{
+		Enabled: true,
+		Default: config.LLMRedactionPolicy{Action: "Redact", Categories: []string{"email"}},
+		Tenants: map[string]config.LLMRedactionPolicy{
+			"acme": {Action: "block", Terms: []string{"Falcon"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RedactionPoliciesFromConfig: %v", err)
+	}
+	p, _ := src.PolicyFor(context.Background(), "acme")
+	if p.Action != RedactionActionBlock || len(p.Categories) != 1 || p.Categories[0] != RedactEmail || p.Terms[0] != "Falcon" {
+		t.Errorf("merged policy = %+v", p)
+	}
+	p, _ = src.PolicyFor(context.Background(), "other")
+	if p.Action != RedactionActionRedact || len(p.Terms) != 0 {
+		t.Errorf("default policy = %+v", p)
+	}
+
+	bad := []config.LLMGuardrailConfig{
+		{Default: config.LLMRedactionPolicy{Action: "shred"}},
+		{Default: config.LLMRedactionPolicy{Categories: []string{"ssn"}}},
+		{Tenants: map[string]config.LLMRedactionPolicy{"acme": {Patterns: []string{"(unclosed"}}}},
+	}
+	for i, cfg := range bad {
+		if _, err := RedactionPoliciesFromConfig(cfg); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,340 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// ---------------------------------------------------------------------------
+// Request metadata understood by the usage meter
+// ---------------------------------------------------------------------------
+
+const (
+	// MetaUserID attributes a request to a user when the context carries none.
+	MetaUserID = "user_id"
+	// MetaFeature names the product feature making the call, e.g. "ai.chat"
+	// or "strategy.report", so spend can be broken down by feature.
+	MetaFeature = "feature"
+	// MetaTokensEstimated is set to "true" on responses whose token counts
+	// were estimated because the provider did not report them.
+	MetaTokensEstimated = "tokens_estimated"
+)
+
+// UnattributedFeature is recorded for requests that name no feature.
+const UnattributedFeature = "unattributed"
+
+// EstimateTokenCount provides a rough token count. English ≈ 1 token per 4
+// chars; CJK ≈ 1 token per 1.5 chars. We use a blended heuristic.
+func EstimateTokenCount(text string) int {
+	if text == "" {
+		return 0
+	}
+	runeCount := utf8.RuneCountInString(text)
+	byteCount := len(text)
+
+	// If average bytes-per-rune > 2, assume CJK-heavy.
+	if runeCount > 0 && float64(byteCount)/float64(runeCount) > 2.0 {
+		return int(math.Ceil(float64(runeCount) / 1.5))
+	}
+	// English / Latin approximation.
+	return int(math.Ceil(float64(byteCount) / 4.0))
+}
+
+// ---------------------------------------------------------------------------
+// Interfaces
+// ---------------------------------------------------------------------------
+
+// UsageEvent is the token usage of one completed model call.
+type UsageEvent struct {
+	TenantID         string
+	UserID           string
+	Feature          string
+	Provider         string
+	Model            string
+	RequestID        string
+	PromptTokens     int64
+	CompletionTokens int64
+	// Estimated is true when either count was estimated with
+	// EstimateTokenCount because the provider omitted it.
+	Estimated bool
+	Time      time.Time
+}
+
+// UsageMeter accounts token usage against tenant budgets.
+type UsageMeter interface {
+	// Authorize is called before a model call. It fails with
+	// ErrCodeTooManyRequests when the tenant has exhausted its hard budget.
+	Authorize(ctx context.Context, tenantID string) error
+	// Record adds a completed call to the ledger.
+	Record(ctx context.Context, event *UsageEvent) error
+}
+
+// MeteredBackendOption configures a metered backend.
+type MeteredBackendOption func(*meteredBackend)
+
+// WithMeterLogger injects a logger.
+func WithMeterLogger(l Logger) MeteredBackendOption {
+	return func(b *meteredBackend) { b.logger = l }
+}
+
+// WithMeterTenantResolver overrides how the tenant is read from the context.
+func WithMeterTenantResolver(fn func(ctx context.Context) (string, bool)) MeteredBackendOption {
+	return func(b *meteredBackend) { b.tenantOf = fn }
+}
+
+// WithMeterUserResolver overrides how the user is read from the context.
+func WithMeterUserResolver(fn func(ctx context.Context) (string, bool)) MeteredBackendOption {
+	return func(b *meteredBackend) { b.userOf = fn }
+}
+
+// ---------------------------------------------------------------------------
+// meteredBackend
+// ---------------------------------------------------------------------------
+
+type meteredBackend struct {
+	next     ModelBackend
+	meter    UsageMeter
+	provider string
+	logger   Logger
+	tenantOf func(ctx context.Context) (string, bool)
+	userOf   func(ctx context.Context) (string, bool)
+	now      func() time.Time
+}
+
+// NewMeteredBackend records the token usage of every call made through next
+// and refuses calls from tenants over their hard budget. provider labels the
+// ledger entries. Ledger write failures are logged and never fail a call.
+// Place it below any response cache so cache hits are not billed.
+func NewMeteredBackend(next ModelBackend, meter UsageMeter, provider string, opts ...MeteredBackendOption) (ModelBackend, error) {
+	if next == nil {
+		return nil, errors.NewInvalidInputError("backend is required")
+	}
+	if meter == nil {
+		return nil, errors.NewInvalidInputError("usage meter is required")
+	}
+	b := &meteredBackend{next: next, meter: meter, provider: provider, now: time.Now}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.logger == nil {
+		b.logger = NewNoopLogger()
+	}
+	if b.tenantOf == nil {
+		b.tenantOf = contextTenantID
+	}
+	if b.userOf == nil {
+		b.userOf = contextUserID
+	}
+	return b, nil
+}
+
+func (b *meteredBackend) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	tenant := requestTenant(ctx, req, b.tenantOf, "")
+	if err := b.meter.Authorize(ctx, tenant); err != nil {
+		return nil, err
+	}
+	resp, err := b.next.Predict(ctx, req)
+	if err != nil || resp == nil || isCacheHit(resp) {
+		return resp, err
+	}
+
+	reportedIn, reportedOut := reportedTokens(resp)
+	in, out, estimated := usageTokens(req, reportedIn, reportedOut, outputText(resp))
+	b.record(ctx, req, tenant, resp.ModelName, in, out, estimated)
+	if !estimated {
+		return resp, nil
+	}
+	cp := *resp
+	cp.Metadata = make(map[string]string, len(resp.Metadata)+3)
+	for k, v := range resp.Metadata {
+		cp.Metadata[k] = v
+	}
+	cp.Metadata["input_tokens"] = strconv.FormatInt(in, 10)
+	cp.Metadata["output_tokens"] = strconv.FormatInt(out, 10)
+	cp.Metadata[MetaTokensEstimated] = "true"
+	return &cp, nil
+}
+
+// PredictStream passes chunks through unchanged and records usage once the
+// stream ends, taking token counts from the last chunk that reports them.
+func (b *meteredBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	tenant := requestTenant(ctx, req, b.tenantOf, "")
+	if err := b.meter.Authorize(ctx, tenant); err != nil {
+		return nil, err
+	}
+	in, err := b.next.PredictStream(ctx, req)
+	if err != nil {
+		return in, err
+	}
+	out := make(chan *PredictResponse)
+	go func() {
+		defer close(out)
+		var (
+			text        strings.Builder
+			model       string
+			reportedIn  int64
+			reportedOut int64
+			cacheHit    bool
+			forwarding  = true
+		)
+		for resp := range in {
+			if resp == nil {
+				continue
+			}
+			if resp.ModelName != "" {
+				model = resp.ModelName
+			}
+			if i, o := reportedTokens(resp); i > 0 || o > 0 {
+				reportedIn, reportedOut = i, o
+			}
+			cacheHit = cacheHit || isCacheHit(resp)
+			text.WriteString(outputText(resp))
+			if !forwarding {
+				continue
+			}
+			select {
+			case out <- resp:
+			case <-ctx.Done():
+				// Keep draining so the usage that was produced is recorded.
+				forwarding = false
+			}
+		}
+		if cacheHit || (text.Len() == 0 && reportedOut == 0) {
+			return
+		}
+		promptTokens, completionTokens, estimated := usageTokens(req, reportedIn, reportedOut, text.String())
+		b.record(ctx, req, tenant, model, promptTokens, completionTokens, estimated)
+	}()
+	return out, nil
+}
+
+func (b *meteredBackend) Healthy(ctx context.Context) error { return b.next.Healthy(ctx) }
+func (b *meteredBackend) Close() error                      { return b.next.Close() }
+
+func (b *meteredBackend) record(ctx context.Context, req *PredictRequest, tenant, model string, in, out int64, estimated bool) {
+	event := &UsageEvent{
+		TenantID:         tenant,
+		Feature:          UnattributedFeature,
+		Provider:         b.provider,
+		Model:            model,
+		PromptTokens:     in,
+		CompletionTokens: out,
+		Estimated:        estimated,
+		Time:             b.now().UTC(),
+	}
+	if user, _ := b.userOf(ctx); user != "" {
+		event.UserID = user
+	}
+	if req != nil {
+		if event.UserID == "" {
+			event.UserID = req.Metadata[MetaUserID]
+		}
+		if f := req.Metadata[MetaFeature]; f != "" {
+			event.Feature = f
+		}
+		event.RequestID = req.Metadata["request_id"]
+		if event.Model == "" {
+			event.Model = req.ModelName
+		}
+	}
+	// The caller may already be gone; the tokens were still spent.
+	if err := b.meter.Record(context.WithoutCancel(ctx), event); err != nil {
+		b.logger.Error("usage ledger write failed", "error", err, "tenant", tenant, "model", event.Model)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Token accounting helpers
+// ---------------------------------------------------------------------------
+
+// contextUserID reads the user set by the API layer.
+func contextUserID(ctx context.Context) (string, bool) {
+	uid, ok := ctx.Value(commontypes.ContextKeyUserID).(string)
+	return uid, ok
+}
+
+func isCacheHit(resp *PredictResponse) bool {
+	switch resp.Metadata[MetaCache] {
+	case "hit", "semantic_hit":
+		return true
+	}
+	return false
+}
+
+// reportedTokens reads token usage reported by the Anthropic or
+// OpenAI-compatible backends; zero means not reported.
+func reportedTokens(resp *PredictResponse) (in, out int64) {
+	meta := func(keys ...string) int64 {
+		for _, k := range keys {
+			if n, err := strconv.ParseInt(resp.Metadata[k], 10, 64); err == nil && n > 0 {
+				return n
+			}
+		}
+		return 0
+	}
+	return meta("input_tokens", "prompt_tokens"), meta("output_tokens", "completion_tokens")
+}
+
+// usageTokens completes reported counts with estimates of the prompt and of
+// outputText.
+func usageTokens(req *PredictRequest, in, out int64, outputText string) (int64, int64, bool) {
+	estimated := false
+	if in == 0 && req != nil {
+		if n := int64(EstimateTokenCount(promptText(req))); n > 0 {
+			in, estimated = n, true
+		}
+	}
+	if out == 0 {
+		if n := int64(EstimateTokenCount(outputText)); n > 0 {
+			out, estimated = n, true
+		}
+	}
+	return in, out, estimated
+}
+
+// promptText is the text a provider would tokenise: the message contents of
+// a chat payload, or the raw input otherwise.
+func promptText(req *PredictRequest) string {
+	if req.InputFormat == FormatJSON || json.Valid(req.InputData) {
+		var chat struct {
+			System   string `json:"system"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if json.Unmarshal(req.InputData, &chat) == nil && len(chat.Messages) > 0 {
+			parts := make([]string, 0, len(chat.Messages)+1)
+			if chat.System != "" {
+				parts = append(parts, chat.System)
+			}
+			for _, m := range chat.Messages {
+				parts = append(parts, m.Content)
+			}
+			return strings.Join(parts, "\n")
+		}
+	}
+	return string(req.InputData)
+}
+
+func outputText(resp *PredictResponse) string {
+	if len(resp.Outputs) == 1 {
+		for _, v := range resp.Outputs {
+			return string(v)
+		}
+	}
+	var b strings.Builder
+	for _, v := range resp.Outputs {
+		b.Write(v)
+	}
+	return b.String()
+}
+
+//Personal.AI order the ending
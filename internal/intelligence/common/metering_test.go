@@ -0,0 +1,191 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// recordingMeter captures usage events and refuses tenants in blocked.
+type recordingMeter struct {
+	mu      sync.Mutex
+	events  []*UsageEvent
+	blocked map[string]bool
+}
+
+func (m *recordingMeter) Authorize(_ context.Context, tenantID string) error {
+	if m.blocked[tenantID] {
+		return errors.New(errors.ErrCodeTooManyRequests, "budget exhausted")
+	}
+	return nil
+}
+
+func (m *recordingMeter) Record(_ context.Context, ev *UsageEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, ev)
+	return nil
+}
+
+func (m *recordingMeter) recorded() []*UsageEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*UsageEvent(nil), m.events...)
+}
+
+// reportingBackend answers with fixed output and response metadata.
+type reportingBackend struct {
+	output string
+	meta   map[string]string
+}
+
+func (r *reportingBackend) Predict(_ context.Context, req *PredictRequest) (*PredictResponse, error) {
+	return &PredictResponse{
+		ModelName: "claude-sonnet-4",
+		Outputs:   map[string][]byte{"content": []byte(r.output)},
+		Metadata:  r.meta,
+	}, nil
+}
+
+func (r *reportingBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	resp, _ := r.Predict(ctx, req)
+	ch := make(chan *PredictResponse, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func (r *reportingBackend) Healthy(context.Context) error { return nil }
+func (r *reportingBackend) Close() error                  { return nil }
+
+func TestEstimateTokenCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"有机发光二极管", 5},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokenCount(tt.text); got != tt.want {
+			t.Errorf("EstimateTokenCount(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMeteredBackend_RecordsReportedTokens(t *testing.T) {
+	meter := &recordingMeter{}
+	next := &reportingBackend{output: "ok", meta: map[string]string{"input_tokens": "120", "output_tokens": "30"}}
+	b, err := NewMeteredBackend(next, meter, "anthropic")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := textRequest("analyse claim 1", map[string]string{MetaFeature: "ai.chat", "request_id": "r-1"})
+	resp, err := b.Predict(tenantContext("acme"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Metadata[MetaTokensEstimated] != "" {
+		t.Error("reported counts should not be marked as estimated")
+	}
+	events := meter.recorded()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	ev := events[0]
+	if ev.TenantID != "acme" || ev.Feature != "ai.chat" || ev.RequestID != "r-1" || ev.Provider != "anthropic" ||
+		ev.Model != "claude-sonnet-4" || ev.PromptTokens != 120 || ev.CompletionTokens != 30 || ev.Estimated {
+		t.Errorf("unexpected event %+v", ev)
+	}
+}
+
+func TestMeteredBackend_EstimatesMissingTokens(t *testing.T) {
+	meter := &recordingMeter{}
+	b, _ := NewMeteredBackend(&echoBackend{}, meter, "deepseek")
+
+	req := &PredictRequest{
+		ModelName:   "deepseek-chat",
+		InputFormat: FormatJSON,
+		InputData:   []byte(`{"messages":[{"role":"user","content":"abcdefgh"}],"max_tokens":800}`),
+	}
+	resp, err := b.Predict(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Metadata[MetaTokensEstimated] != "true" || resp.Metadata["input_tokens"] != "2" {
+		t.Errorf("expected estimated counts on the response, got %v", resp.Metadata)
+	}
+	ev := meter.recorded()[0]
+	if !ev.Estimated || ev.PromptTokens != 2 || ev.Feature != UnattributedFeature || ev.TenantID != "" {
+		t.Errorf("unexpected event %+v", ev)
+	}
+	if ev.CompletionTokens != int64(EstimateTokenCount(string(req.InputData))) {
+		t.Errorf("expected completion estimated from the echoed output, got %d", ev.CompletionTokens)
+	}
+}
+
+func TestMeteredBackend_BlocksExhaustedTenant(t *testing.T) {
+	meter := &recordingMeter{blocked: map[string]bool{"acme": true}}
+	next := &echoBackend{}
+	b, _ := NewMeteredBackend(next, meter, "anthropic")
+
+	_, err := b.Predict(tenantContext("acme"), textRequest("hello", nil))
+	if !errors.IsCode(err, errors.ErrCodeTooManyRequests) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if _, err := b.PredictStream(tenantContext("acme"), textRequest("hello", nil)); err == nil {
+		t.Error("expected stream to be refused")
+	}
+	if len(next.seen) != 0 || len(meter.recorded()) != 0 {
+		t.Error("a refused call must not reach the provider or the ledger")
+	}
+}
+
+func TestMeteredBackend_SkipsCacheHits(t *testing.T) {
+	meter := &recordingMeter{}
+	b, _ := NewMeteredBackend(&reportingBackend{output: "cached", meta: map[string]string{MetaCache: "semantic_hit"}}, meter, "anthropic")
+
+	if _, err := b.Predict(context.Background(), textRequest("hello", nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(meter.recorded()) != 0 {
+		t.Error("cache hits cost nothing and must not be recorded")
+	}
+}
+
+func TestMeteredBackend_StreamRecordsOnce(t *testing.T) {
+	meter := &recordingMeter{}
+	b, _ := NewMeteredBackend(&echoBackend{chunks: 4}, meter, "anthropic",
+		WithMeterUserResolver(func(context.Context) (string, bool) { return "u-7", true }))
+	b.(*meteredBackend).now = func() time.Time { return time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC) }
+
+	prompt := strings.Repeat("token ", 20)
+	ch, err := b.PredictStream(tenantContext("acme"), textRequest(prompt, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got strings.Builder
+	for resp := range ch {
+		got.WriteString(content(resp))
+	}
+	if got.String() != prompt {
+		t.Errorf("stream altered: %q", got.String())
+	}
+	events := meter.recorded()
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	ev := events[0]
+	if ev.UserID != "u-7" || ev.CompletionTokens != int64(EstimateTokenCount(prompt)) || !ev.Time.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected event %+v", ev)
+	}
+}
+
+//Personal.AI order the ending
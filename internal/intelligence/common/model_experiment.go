@@ -0,0 +1,447 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Experiment records
+// ---------------------------------------------------------------------------
+
+// ExperimentKind identifies how a candidate version receives traffic.
+type ExperimentKind string
+
+const (
+	// ExperimentShadow mirrors requests to the candidate; its output never
+	// reaches the caller.
+	ExperimentShadow ExperimentKind = "shadow"
+	// ExperimentCanary routes a growing share of live traffic to the candidate.
+	ExperimentCanary ExperimentKind = "canary"
+	// ExperimentABTest records the outcomes of a manually configured A/B test.
+	ExperimentABTest ExperimentKind = "ab_test"
+)
+
+// ExperimentStatus is the lifecycle state of an experiment.
+type ExperimentStatus string
+
+const (
+	ExperimentRunning    ExperimentStatus = "running"
+	ExperimentPromoted   ExperimentStatus = "promoted"
+	ExperimentRolledBack ExperimentStatus = "rolled_back"
+	ExperimentCompleted  ExperimentStatus = "completed"
+)
+
+// maxLatencySamples bounds the latency reservoir kept per arm for percentile
+// estimation.
+const maxLatencySamples = 512
+
+// maxShadowDiffSamples bounds the mismatching shadow requests kept for review.
+const maxShadowDiffSamples = 50
+
+// ArmStats accumulates the outcomes observed for one model version.
+type ArmStats struct {
+	Version        string    `json:"version"`
+	Requests       int64     `json:"requests"`
+	Errors         int64     `json:"errors"`
+	LatencySumMs   float64   `json:"latency_sum_ms"`
+	LatencySumSqMs float64   `json:"latency_sum_sq_ms"`
+	LatencySamples []float64 `json:"latency_samples,omitempty"` // most recent successful calls
+}
+
+// Observe adds one outcome to the arm.
+func (a *ArmStats) Observe(latency time.Duration, failed bool) {
+	a.Requests++
+	if failed {
+		a.Errors++
+		return
+	}
+	ms := float64(latency) / float64(time.Millisecond)
+	a.LatencySumMs += ms
+	a.LatencySumSqMs += ms * ms
+	if len(a.LatencySamples) >= maxLatencySamples {
+		copy(a.LatencySamples, a.LatencySamples[1:])
+		a.LatencySamples = a.LatencySamples[:maxLatencySamples-1]
+	}
+	a.LatencySamples = append(a.LatencySamples, ms)
+}
+
+// ErrorRate returns the share of failed requests.
+func (a *ArmStats) ErrorRate() float64 {
+	if a.Requests == 0 {
+		return 0
+	}
+	return float64(a.Errors) / float64(a.Requests)
+}
+
+// MeanLatencyMs returns the mean latency of successful requests.
+func (a *ArmStats) MeanLatencyMs() float64 {
+	n := a.Requests - a.Errors
+	if n == 0 {
+		return 0
+	}
+	return a.LatencySumMs / float64(n)
+}
+
+// LatencyVarianceMs returns the sample variance of successful latencies.
+func (a *ArmStats) LatencyVarianceMs() float64 {
+	n := float64(a.Requests - a.Errors)
+	if n < 2 {
+		return 0
+	}
+	mean := a.LatencySumMs / n
+	v := (a.LatencySumSqMs - n*mean*mean) / (n - 1)
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// P95LatencyMs returns the 95th percentile of the latency reservoir.
+func (a *ArmStats) P95LatencyMs() float64 {
+	if len(a.LatencySamples) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), a.LatencySamples...)
+	sort.Float64s(sorted)
+	idx := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// ShadowDiff is one shadow request whose candidate output differed.
+type ShadowDiff struct {
+	RequestID string    `json:"request_id"`
+	Score     float64   `json:"score"`
+	At        time.Time `json:"at"`
+}
+
+// ShadowStats summarises how candidate outputs compare with production.
+type ShadowStats struct {
+	Compared   int64        `json:"compared"`
+	Mismatches int64        `json:"mismatches"`
+	DiffSum    float64      `json:"diff_sum"`
+	Dropped    int64        `json:"dropped"` // mirrors skipped because the shadow pool was saturated
+	Expired    int64        `json:"expired"` // pairs that never received both outputs
+	Recent     []ShadowDiff `json:"recent,omitempty"`
+}
+
+// MismatchRate returns the share of compared requests whose outputs differed.
+func (s *ShadowStats) MismatchRate() float64 {
+	if s.Compared == 0 {
+		return 0
+	}
+	return float64(s.Mismatches) / float64(s.Compared)
+}
+
+// MeanDiff returns the mean comparator score over compared requests.
+func (s *ShadowStats) MeanDiff() float64 {
+	if s.Compared == 0 {
+		return 0
+	}
+	return s.DiffSum / float64(s.Compared)
+}
+
+func (s *ShadowStats) observe(requestID string, score float64, at time.Time) {
+	s.Compared++
+	s.DiffSum += score
+	if score == 0 {
+		return
+	}
+	s.Mismatches++
+	if len(s.Recent) >= maxShadowDiffSamples {
+		copy(s.Recent, s.Recent[1:])
+		s.Recent = s.Recent[:maxShadowDiffSamples-1]
+	}
+	s.Recent = append(s.Recent, ShadowDiff{RequestID: requestID, Score: score, At: at})
+}
+
+// Experiment is the durable record of a shadow, canary or A/B experiment.
+type Experiment struct {
+	ID               string           `json:"id"`
+	ModelID          string           `json:"model_id"`
+	Kind             ExperimentKind   `json:"kind"`
+	Status           ExperimentStatus `json:"status"`
+	BaselineVersion  string           `json:"baseline_version"`
+	CandidateVersion string           `json:"candidate_version"`
+	CanaryWeight     int              `json:"canary_weight,omitempty"`
+	Arms             []*ArmStats      `json:"arms"`
+	Shadow           *ShadowStats     `json:"shadow,omitempty"`
+	Reason           string           `json:"reason,omitempty"`
+	StartedAt        time.Time        `json:"started_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	EndedAt          time.Time        `json:"ended_at,omitempty"`
+}
+
+// Arm returns the stats of the given version, or nil if it is not an arm.
+func (e *Experiment) Arm(version string) *ArmStats {
+	for _, a := range e.Arms {
+		if a.Version == version {
+			return a
+		}
+	}
+	return nil
+}
+
+// Significance compares the candidate arm against the baseline arm.
+func (e *Experiment) Significance(alpha float64) *SignificanceResult {
+	base, cand := e.Arm(e.BaselineVersion), e.Arm(e.CandidateVersion)
+	if base == nil || cand == nil {
+		return &SignificanceResult{Alpha: alpha, ErrorRatePValue: 1, LatencyPValue: 1}
+	}
+	return CompareArms(base, cand, alpha)
+}
+
+// Clone returns a deep copy safe to hand to a store or caller.
+func (e *Experiment) Clone() *Experiment {
+	out := *e
+	out.Arms = make([]*ArmStats, len(e.Arms))
+	for i, a := range e.Arms {
+		arm := *a
+		arm.LatencySamples = append([]float64(nil), a.LatencySamples...)
+		out.Arms[i] = &arm
+	}
+	if e.Shadow != nil {
+		sh := *e.Shadow
+		sh.Recent = append([]ShadowDiff(nil), e.Shadow.Recent...)
+		out.Shadow = &sh
+	}
+	return &out
+}
+
+// ---------------------------------------------------------------------------
+// Significance tests
+// ---------------------------------------------------------------------------
+
+// DefaultSignificanceLevel is the alpha used when none is configured.
+const DefaultSignificanceLevel = 0.05
+
+// SignificanceResult reports whether candidate and baseline differ.
+type SignificanceResult struct {
+	Alpha              float64 `json:"alpha"`
+	ErrorRateDelta     float64 `json:"error_rate_delta"` // candidate minus baseline
+	ErrorRateZ         float64 `json:"error_rate_z"`
+	ErrorRatePValue    float64 `json:"error_rate_p_value"`
+	LatencyDeltaMs     float64 `json:"latency_delta_ms"` // candidate minus baseline mean
+	LatencyT           float64 `json:"latency_t"`
+	LatencyDF          float64 `json:"latency_df"`
+	LatencyPValue      float64 `json:"latency_p_value"`
+	ErrorRateDiffers   bool    `json:"error_rate_differs"`
+	LatencyDiffers     bool    `json:"latency_differs"`
+	CandidateRegressed bool    `json:"candidate_regressed"` // significantly more errors or slower
+}
+
+// CompareArms runs a two-proportion z-test on error rates and Welch's t-test
+// on mean latency.
+func CompareArms(baseline, candidate *ArmStats, alpha float64) *SignificanceResult {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = DefaultSignificanceLevel
+	}
+	res := &SignificanceResult{Alpha: alpha}
+
+	res.ErrorRateDelta = candidate.ErrorRate() - baseline.ErrorRate()
+	res.ErrorRateZ, res.ErrorRatePValue = TwoProportionZTest(candidate.Errors, candidate.Requests, baseline.Errors, baseline.Requests)
+
+	res.LatencyDeltaMs = candidate.MeanLatencyMs() - baseline.MeanLatencyMs()
+	res.LatencyT, res.LatencyDF, res.LatencyPValue = WelchTTest(
+		candidate.MeanLatencyMs(), candidate.LatencyVarianceMs(), candidate.Requests-candidate.Errors,
+		baseline.MeanLatencyMs(), baseline.LatencyVarianceMs(), baseline.Requests-baseline.Errors,
+	)
+
+	res.ErrorRateDiffers = res.ErrorRatePValue < alpha
+	res.LatencyDiffers = res.LatencyPValue < alpha
+	res.CandidateRegressed = (res.ErrorRateDiffers && res.ErrorRateDelta > 0) ||
+		(res.LatencyDiffers && res.LatencyDeltaMs > 0)
+	return res
+}
+
+// TwoProportionZTest tests whether x1/n1 and x2/n2 differ and returns the z
+// statistic and two-sided p-value. Empty samples yield p = 1.
+func TwoProportionZTest(x1, n1, x2, n2 int64) (z, p float64) {
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+	p1, p2 := float64(x1)/float64(n1), float64(x2)/float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0, 1
+	}
+	z = (p1 - p2) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// WelchTTest tests whether two means with unequal variances differ and
+// returns the t statistic, the Welch–Satterthwaite degrees of freedom and the
+// two-sided p-value. Fewer than two observations per side yield p = 1.
+func WelchTTest(mean1, var1 float64, n1 int64, mean2, var2 float64, n2 int64) (t, df, p float64) {
+	if n1 < 2 || n2 < 2 {
+		return 0, 0, 1
+	}
+	a, b := var1/float64(n1), var2/float64(n2)
+	se := math.Sqrt(a + b)
+	if se == 0 {
+		return 0, 0, 1
+	}
+	t = (mean1 - mean2) / se
+	df = (a + b) * (a + b) / (a*a/float64(n1-1) + b*b/float64(n2-1))
+	p = regularizedIncompleteBeta(df/2, 0.5, df/(df+t*t))
+	return t, df, p
+}
+
+// regularizedIncompleteBeta evaluates I_x(a, b) with the continued fraction
+// from Numerical Recipes.
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a + b)
+	lb, _ := math.Lgamma(a)
+	lc, _ := math.Lgamma(b)
+	front := math.Exp(la - lb - lc + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIter = 200
+		eps     = 3e-14
+		tiny    = 1e-300
+	)
+	qab, qap, qam := a+b, a+1, a-1
+	c, d := 1.0, 1-qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// ---------------------------------------------------------------------------
+// Output comparison
+// ---------------------------------------------------------------------------
+
+// OutputComparator scores how far a candidate output is from the production
+// output: 0 means equivalent, 1 means entirely different.
+type OutputComparator func(primary, candidate []byte) float64
+
+// ExactOutputComparator treats outputs as equivalent when they are
+// byte-identical or, for JSON, semantically equal.
+func ExactOutputComparator(primary, candidate []byte) float64 {
+	if string(primary) == string(candidate) {
+		return 0
+	}
+	var a, b interface{}
+	if json.Unmarshal(primary, &a) == nil && json.Unmarshal(candidate, &b) == nil {
+		ca, _ := json.Marshal(a)
+		cb, _ := json.Marshal(b)
+		if string(ca) == string(cb) {
+			return 0
+		}
+	}
+	return 1
+}
+
+// ---------------------------------------------------------------------------
+// Experiment store
+// ---------------------------------------------------------------------------
+
+// ExperimentStore persists experiment records so results survive restarts.
+type ExperimentStore interface {
+	SaveExperiment(ctx context.Context, exp *Experiment) error
+	GetExperiment(ctx context.Context, id string) (*Experiment, error)
+	// ListExperiments returns experiments newest first; an empty modelID
+	// lists every model.
+	ListExperiments(ctx context.Context, modelID string) ([]*Experiment, error)
+}
+
+type memoryExperimentStore struct {
+	mu          sync.RWMutex
+	experiments map[string]*Experiment
+}
+
+// NewMemoryExperimentStore returns a process-local ExperimentStore. It is the
+// registry default when no durable store is configured.
+func NewMemoryExperimentStore() ExperimentStore {
+	return &memoryExperimentStore{experiments: make(map[string]*Experiment)}
+}
+
+func (s *memoryExperimentStore) SaveExperiment(_ context.Context, exp *Experiment) error {
+	if exp == nil || exp.ID == "" {
+		return errors.NewInvalidInputError("experiment id is required")
+	}
+	s.mu.Lock()
+	s.experiments[exp.ID] = exp.Clone()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *memoryExperimentStore) GetExperiment(_ context.Context, id string) (*Experiment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	exp, ok := s.experiments[id]
+	if !ok {
+		return nil, errors.ErrNotFound("experiment", id)
+	}
+	return exp.Clone(), nil
+}
+
+func (s *memoryExperimentStore) ListExperiments(_ context.Context, modelID string) ([]*Experiment, error) {
+	s.mu.RLock()
+	out := make([]*Experiment, 0, len(s.experiments))
+	for _, exp := range s.experiments {
+		if modelID == "" || exp.ModelID == modelID {
+			out = append(out, exp.Clone())
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, nil
+}
+
+//Personal.AI order the ending
@@ -20,7 +20,8 @@ import (
 // ---------------------------------------------------------------------------
 
 // ModelRegistry manages AI model lifecycle: registration, versioning,
-// hot-swap, rollback, A/B testing and health monitoring.
+// hot-swap, rollback, A/B testing and health monitoring. Shadow traffic and
+// canary rollouts are available through RolloutManager.
 type ModelRegistry interface {
 	Register(ctx context.Context, meta *ModelMetadata) error
 	Unregister(ctx context.Context, modelID string, version string) error
@@ -190,6 +191,9 @@ type registryOptions struct {
 	healthCheckInterval time.Duration
 	unloadDelay         time.Duration
 	maxLoadedVersions   int
+	invoker             ModelInvoker
+	experimentStore     ExperimentStore
+	rolloutInterval     time.Duration
 }
 
 func defaultRegistryOptions() *registryOptions {
@@ -197,6 +201,7 @@ func defaultRegistryOptions() *registryOptions {
 		healthCheckInterval: 30 * time.Second,
 		unloadDelay:         60 * time.Second,
 		maxLoadedVersions:   3,
+		rolloutInterval:     15 * time.Second,
 	}
 }
 
@@ -263,6 +268,7 @@ type modelEntry struct {
 	activeVersion   atomic.Value // string
 	previousVersion string
 	abTestConfig    *ABTestConfig
+	shadowVersion   string // candidate receiving mirrored traffic, kept loaded
 	createdAt       time.Time
 }
 
@@ -329,6 +335,8 @@ type modelRegistry struct {
 	logger  Logger
 	opts    *registryOptions
 
+	rollouts *rolloutTracker
+
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
@@ -354,13 +362,17 @@ func NewModelRegistry(
 	for _, fn := range opts {
 		fn(o)
 	}
+	if o.experimentStore == nil {
+		o.experimentStore = NewMemoryExperimentStore()
+	}
 
 	r := &modelRegistry{
-		loader:  loader,
-		metrics: metrics,
-		logger:  logger,
-		opts:    o,
-		stopCh:  make(chan struct{}),
+		loader:   loader,
+		metrics:  metrics,
+		logger:   logger,
+		opts:     o,
+		rollouts: newRolloutTracker(),
+		stopCh:   make(chan struct{}),
 	}
 
 	r.wg.Add(1)
@@ -369,10 +381,25 @@ func NewModelRegistry(
 	return r, nil
 }
 
-// Close stops background goroutines and releases resources.
+// Close stops background goroutines, waits for in-flight shadow calls and
+// flushes experiment results.
 func (r *modelRegistry) Close() error {
 	close(r.stopCh)
 	r.wg.Wait()
+	r.rollouts.shadowWG.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r.rollouts.mu.Lock()
+	running := make([]*Experiment, 0, len(r.rollouts.runs))
+	for _, run := range r.rollouts.runs {
+		if run.dirty {
+			run.dirty = false
+			running = append(running, run.exp.Clone())
+		}
+	}
+	r.rollouts.mu.Unlock()
+	r.saveExperiments(ctx, running...)
 	return nil
 }
 
@@ -756,11 +783,16 @@ func (r *modelRegistry) ConfigureABTest(ctx context.Context, config *ABTestConfi
 	}
 	entry := raw.(*modelEntry)
 
+	if err := r.checkABTestAllowed(config.ModelID); err != nil {
+		return err
+	}
+
 	// If disabling, just clear
 	if !config.Enabled {
 		entry.mu.Lock()
 		entry.abTestConfig = nil
 		entry.mu.Unlock()
+		r.endABTest(ctx, config.ModelID, "disabled")
 		r.logger.Info("A/B test disabled", "model_id", config.ModelID)
 		return nil
 	}
@@ -791,7 +823,9 @@ func (r *modelRegistry) ConfigureABTest(ctx context.Context, config *ABTestConfi
 
 	entry.mu.Lock()
 	entry.abTestConfig = config
+	active := entry.getActiveVersion()
 	entry.mu.Unlock()
+	r.recordABTest(ctx, config, active)
 
 	r.logger.Info("A/B test configured",
 		"model_id", config.ModelID,
@@ -853,6 +887,7 @@ func (r *modelRegistry) ResolveModel(ctx context.Context, modelID string, reques
 	rm := r.buildRegisteredModel(modelID, entry, ve)
 	entry.mu.RUnlock()
 
+	r.mirrorToShadow(ctx, modelID, requestID, rm)
 	return rm, nil
 }
 
@@ -1061,8 +1096,8 @@ func (r *modelRegistry) evictExcessVersions(ctx context.Context, modelID string,
 	evictCount := len(loaded) + 1 - maxLoaded
 	for i := 0; i < evictCount && i < len(loaded); i++ {
 		ver := loaded[i].version
-		// Check if it's part of an active A/B test
-		if r.isVersionInABTest(entry, ver) {
+		// Check if it's part of an active A/B test or receives shadow traffic
+		if r.isVersionInABTest(entry, ver) || entry.shadowVersion == ver {
 			continue
 		}
 		r.doUnload(modelID, entry, ver)
@@ -1098,6 +1133,9 @@ func (r *modelRegistry) backgroundLoop() {
 	abCleanupTicker := time.NewTicker(1 * time.Minute)
 	defer abCleanupTicker.Stop()
 
+	rolloutTicker := time.NewTicker(r.opts.rolloutInterval)
+	defer rolloutTicker.Stop()
+
 	for {
 		select {
 		case <-r.stopCh:
@@ -1106,6 +1144,8 @@ func (r *modelRegistry) backgroundLoop() {
 			r.periodicHealthCheck()
 		case <-abCleanupTicker.C:
 			r.cleanupExpiredABTests()
+		case <-rolloutTicker.C:
+			r.EvaluateRollouts(context.Background())
 		}
 	}
 }
@@ -1130,6 +1170,7 @@ func (r *modelRegistry) periodicHealthCheck() {
 
 func (r *modelRegistry) cleanupExpiredABTests() {
 	now := time.Now()
+	var expired []string
 
 	r.models.Range(func(key, value interface{}) bool {
 		entry := value.(*modelEntry)
@@ -1145,11 +1186,16 @@ func (r *modelRegistry) cleanupExpiredABTests() {
 				"end_time", entry.abTestConfig.EndTime,
 			)
 			entry.abTestConfig.Enabled = false
+			expired = append(expired, key.(string))
 		}
 		entry.mu.Unlock()
 
 		return true
 	})
+
+	for _, modelID := range expired {
+		r.endABTest(context.Background(), modelID, "expired")
+	}
 }
 
 // noopModelLoader is a no-op implementation of ModelLoader for testing and initialization.
@@ -0,0 +1,858 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Interfaces
+// ---------------------------------------------------------------------------
+
+// RolloutManager runs shadow and canary experiments on top of the model
+// registry. The registry returned by NewModelRegistry implements it.
+type RolloutManager interface {
+	// ConfigureShadow mirrors requests resolved to the active version to a
+	// candidate version and records how the outputs differ.
+	ConfigureShadow(ctx context.Context, cfg *ShadowConfig) (*Experiment, error)
+	StopShadow(ctx context.Context, modelID string) (*Experiment, error)
+	// StartCanary shifts live traffic to a candidate step by step, promoting
+	// it when every step meets the SLOs and rolling back on the first breach.
+	StartCanary(ctx context.Context, cfg *CanaryConfig) (*Experiment, error)
+	AbortCanary(ctx context.Context, modelID string, reason string) (*Experiment, error)
+	// RecordOutcome reports the result of an inference made against a
+	// version returned by ResolveModel.
+	RecordOutcome(ctx context.Context, outcome *InferenceOutcome)
+	// EvaluateRollouts advances, promotes or rolls back canaries and flushes
+	// experiment results. The registry calls it periodically.
+	EvaluateRollouts(ctx context.Context)
+	GetExperiment(ctx context.Context, id string) (*Experiment, error)
+	ListExperiments(ctx context.Context, modelID string) ([]*Experiment, error)
+}
+
+var _ RolloutManager = (*modelRegistry)(nil)
+
+// ModelInvoker runs inference on a specific registered model version. The
+// registry uses it to mirror requests to shadow candidates.
+type ModelInvoker interface {
+	Invoke(ctx context.Context, model *RegisteredModel, input []byte) ([]byte, error)
+}
+
+// ---------------------------------------------------------------------------
+// Configuration
+// ---------------------------------------------------------------------------
+
+// InferenceOutcome is the result of one inference call.
+type InferenceOutcome struct {
+	ModelID   string
+	Version   string
+	RequestID string
+	Latency   time.Duration
+	Err       error
+	Output    []byte // compared with the shadow candidate's output, if any
+}
+
+// ShadowConfig describes a shadow experiment.
+type ShadowConfig struct {
+	ModelID          string           `json:"model_id"`
+	CandidateVersion string           `json:"candidate_version"`
+	SampleRate       float64          `json:"sample_rate"`    // share of requests mirrored, 0-1; 0 mirrors all
+	Timeout          time.Duration    `json:"timeout"`        // per mirrored call, default 30s
+	MaxConcurrent    int              `json:"max_concurrent"` // in-flight mirrors, default 8; excess requests are not mirrored
+	Comparator       OutputComparator `json:"-"`              // default ExactOutputComparator
+}
+
+// CanaryConfig describes a progressive canary rollout.
+type CanaryConfig struct {
+	ModelID          string        `json:"model_id"`
+	CandidateVersion string        `json:"candidate_version"`
+	Steps            []int         `json:"steps"`         // ascending candidate traffic percentages in 1-99, default 5, 25, 50
+	StepDuration     time.Duration `json:"step_duration"` // minimum time at each step, default 10m
+	MinRequests      int64         `json:"min_requests"`  // candidate requests needed per step, default 100
+	// MaxErrorRate and MaxP95LatencyMs are the candidate SLOs; 0 disables a check.
+	MaxErrorRate    float64 `json:"max_error_rate"`
+	MaxP95LatencyMs float64 `json:"max_p95_latency_ms"`
+	// SignificanceLevel is the alpha for rolling back a candidate whose error
+	// rate is significantly above the baseline, default 0.05.
+	SignificanceLevel float64 `json:"significance_level"`
+}
+
+var defaultCanarySteps = []int{5, 25, 50}
+
+type shadowInputKey struct{}
+
+// ContextWithShadowInput attaches the request payload that ResolveModel
+// mirrors to a shadow candidate. Requests without it are not mirrored, so
+// every inference call site taking part in a shadow experiment must set it.
+func ContextWithShadowInput(ctx context.Context, input []byte) context.Context {
+	return context.WithValue(ctx, shadowInputKey{}, input)
+}
+
+// ShadowInputFromContext returns the payload set by ContextWithShadowInput.
+func ShadowInputFromContext(ctx context.Context) ([]byte, bool) {
+	input, ok := ctx.Value(shadowInputKey{}).([]byte)
+	return input, ok
+}
+
+// WithModelInvoker sets the invoker used to mirror shadow traffic. Shadow
+// experiments cannot be configured without one.
+func WithModelInvoker(inv ModelInvoker) RegistryOption {
+	return func(o *registryOptions) {
+		o.invoker = inv
+	}
+}
+
+// WithExperimentStore sets where experiment results are persisted. The
+// default store keeps them in memory.
+func WithExperimentStore(store ExperimentStore) RegistryOption {
+	return func(o *registryOptions) {
+		if store != nil {
+			o.experimentStore = store
+		}
+	}
+}
+
+// WithRolloutEvaluationInterval sets how often canaries are evaluated and
+// experiment results flushed.
+func WithRolloutEvaluationInterval(d time.Duration) RegistryOption {
+	return func(o *registryOptions) {
+		if d > 0 {
+			o.rolloutInterval = d
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Rollout tracker
+// ---------------------------------------------------------------------------
+
+// rolloutTracker holds the running experiment of each model. A model runs at
+// most one experiment at a time. Lock order: entry.mu before tracker.mu.
+type rolloutTracker struct {
+	mu       sync.Mutex
+	runs     map[string]*rolloutRun
+	pending  map[string]*shadowPair // keyed by shadowKey
+	shadowWG sync.WaitGroup
+	now      func() time.Time
+}
+
+type rolloutRun struct {
+	exp       *Experiment
+	shadow    *ShadowConfig
+	shadowSem chan struct{}
+	canary    *CanaryConfig
+	step      int
+	stepStart time.Time
+	stepBase  int64 // candidate requests when the current step began
+	dirty     bool
+}
+
+// shadowPair waits for both the production and the candidate output of one
+// mirrored request.
+type shadowPair struct {
+	modelID      string
+	primary      []byte
+	candidate    []byte
+	hasPrimary   bool
+	hasCandidate bool
+	createdAt    time.Time
+}
+
+// canaryAction is a traffic change decided under the tracker lock and applied
+// after it is released.
+type canaryAction struct {
+	modelID   string
+	baseline  string
+	candidate string
+	weight    int
+	promote   bool
+	final     *Experiment
+}
+
+func newRolloutTracker() *rolloutTracker {
+	return &rolloutTracker{
+		runs:    make(map[string]*rolloutRun),
+		pending: make(map[string]*shadowPair),
+		now:     time.Now,
+	}
+}
+
+func shadowKey(modelID, requestID string) string {
+	return modelID + "\x00" + requestID
+}
+
+func (t *rolloutTracker) newExperiment(modelID string, kind ExperimentKind, baseline, candidate string, versions ...string) *Experiment {
+	now := t.now()
+	exp := &Experiment{
+		ID:               uuid.New().String(),
+		ModelID:          modelID,
+		Kind:             kind,
+		Status:           ExperimentRunning,
+		BaselineVersion:  baseline,
+		CandidateVersion: candidate,
+		StartedAt:        now,
+		UpdatedAt:        now,
+	}
+	if len(versions) == 0 {
+		versions = []string{baseline, candidate}
+	}
+	for _, v := range versions {
+		exp.Arms = append(exp.Arms, &ArmStats{Version: v})
+	}
+	return exp
+}
+
+// finishLocked ends a run and returns the final record.
+func (t *rolloutTracker) finishLocked(run *rolloutRun, status ExperimentStatus, reason string) *Experiment {
+	now := t.now()
+	delete(t.runs, run.exp.ModelID)
+	for key, p := range t.pending {
+		if p.modelID == run.exp.ModelID {
+			delete(t.pending, key)
+		}
+	}
+	run.exp.Status = status
+	run.exp.Reason = reason
+	run.exp.UpdatedAt = now
+	run.exp.EndedAt = now
+	return run.exp.Clone()
+}
+
+// compareLocked scores a completed shadow pair.
+func (t *rolloutTracker) compareLocked(run *rolloutRun, key, requestID string, pair *shadowPair) {
+	delete(t.pending, key)
+	score := run.shadow.Comparator(pair.primary, pair.candidate)
+	run.exp.Shadow.observe(requestID, score, t.now())
+	run.dirty = true
+}
+
+// ---------------------------------------------------------------------------
+// Shadow traffic
+// ---------------------------------------------------------------------------
+
+func (r *modelRegistry) ConfigureShadow(ctx context.Context, cfg *ShadowConfig) (*Experiment, error) {
+	if cfg == nil {
+		return nil, errors.NewInvalidInputError("shadow config is required")
+	}
+	if cfg.ModelID == "" || cfg.CandidateVersion == "" {
+		return nil, errors.NewInvalidInputError("model_id and candidate_version are required")
+	}
+	if r.opts.invoker == nil {
+		return nil, errors.NewInvalidInputError("shadow traffic requires a model invoker")
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		return nil, errors.NewInvalidInputError("sample rate must be between 0 and 1")
+	}
+	c := *cfg
+	if c.SampleRate == 0 {
+		c.SampleRate = 1
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.MaxConcurrent <= 0 {
+		c.MaxConcurrent = 8
+	}
+	if c.Comparator == nil {
+		c.Comparator = ExactOutputComparator
+	}
+
+	entry, baseline, err := r.prepareCandidate(ctx, c.ModelID, c.CandidateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	t := r.rollouts
+	t.mu.Lock()
+	if _, busy := t.runs[c.ModelID]; busy {
+		t.mu.Unlock()
+		return nil, errors.ErrConflict("experiment", c.ModelID)
+	}
+	run := &rolloutRun{
+		exp:       t.newExperiment(c.ModelID, ExperimentShadow, baseline, c.CandidateVersion),
+		shadow:    &c,
+		shadowSem: make(chan struct{}, c.MaxConcurrent),
+	}
+	run.exp.Shadow = &ShadowStats{}
+	t.runs[c.ModelID] = run
+	snapshot := run.exp.Clone()
+	t.mu.Unlock()
+
+	entry.mu.Lock()
+	entry.shadowVersion = c.CandidateVersion
+	entry.mu.Unlock()
+
+	r.saveExperiments(ctx, snapshot)
+	r.logger.Info("shadow experiment started",
+		"model_id", c.ModelID,
+		"baseline", baseline,
+		"candidate", c.CandidateVersion,
+		"sample_rate", c.SampleRate,
+	)
+	return snapshot, nil
+}
+
+func (r *modelRegistry) StopShadow(ctx context.Context, modelID string) (*Experiment, error) {
+	t := r.rollouts
+	t.mu.Lock()
+	run, ok := t.runs[modelID]
+	if !ok || run.shadow == nil {
+		t.mu.Unlock()
+		return nil, errors.ErrNotFound("shadow experiment for model", modelID)
+	}
+	final := t.finishLocked(run, ExperimentCompleted, "stopped")
+	t.mu.Unlock()
+
+	if raw, ok := r.models.Load(modelID); ok {
+		entry := raw.(*modelEntry)
+		entry.mu.Lock()
+		entry.shadowVersion = ""
+		entry.mu.Unlock()
+	}
+
+	r.saveExperiments(ctx, final)
+	r.logger.Info("shadow experiment stopped",
+		"model_id", modelID,
+		"compared", final.Shadow.Compared,
+		"mismatch_rate", final.Shadow.MismatchRate(),
+	)
+	return final, nil
+}
+
+// mirrorToShadow dispatches a resolved request to the shadow candidate. The
+// caller's response never waits on the mirror.
+func (r *modelRegistry) mirrorToShadow(ctx context.Context, modelID, requestID string, resolved *RegisteredModel) {
+	if requestID == "" || resolved == nil || resolved.Metadata == nil {
+		return
+	}
+	input, ok := ShadowInputFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	t := r.rollouts
+	t.mu.Lock()
+	run, ok := t.runs[modelID]
+	if !ok || run.shadow == nil || resolved.Metadata.Version != run.exp.BaselineVersion {
+		t.mu.Unlock()
+		return
+	}
+	cfg := run.shadow
+	if float64(deterministicBucket(requestID, modelID+"/shadow")) >= cfg.SampleRate*100 {
+		t.mu.Unlock()
+		return
+	}
+	key := shadowKey(modelID, requestID)
+	if _, dup := t.pending[key]; dup {
+		t.mu.Unlock()
+		return
+	}
+	select {
+	case run.shadowSem <- struct{}{}:
+	default:
+		run.exp.Shadow.Dropped++
+		run.dirty = true
+		t.mu.Unlock()
+		return
+	}
+	t.pending[key] = &shadowPair{modelID: modelID, createdAt: t.now()}
+	expID, candidate, sem := run.exp.ID, run.exp.CandidateVersion, run.shadowSem
+	t.shadowWG.Add(1)
+	t.mu.Unlock()
+
+	go func() {
+		defer t.shadowWG.Done()
+		defer func() { <-sem }()
+
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.Timeout)
+		defer cancel()
+
+		start := time.Now()
+		var output []byte
+		model, err := r.GetModelVersion(callCtx, modelID, candidate)
+		if err == nil {
+			output, err = r.opts.invoker.Invoke(callCtx, model, input)
+		}
+		r.recordShadowResult(expID, modelID, requestID, candidate, output, time.Since(start), err)
+	}()
+}
+
+func (r *modelRegistry) recordShadowResult(expID, modelID, requestID, version string, output []byte, latency time.Duration, err error) {
+	t := r.rollouts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := shadowKey(modelID, requestID)
+	run, ok := t.runs[modelID]
+	if !ok || run.exp.ID != expID {
+		delete(t.pending, key)
+		return
+	}
+	run.exp.Arm(version).Observe(latency, err != nil)
+	run.dirty = true
+
+	pair, ok := t.pending[key]
+	if !ok {
+		return
+	}
+	if err != nil {
+		r.logger.Debug("shadow candidate failed", "model_id", modelID, "version", version, "error", err)
+		delete(t.pending, key)
+		return
+	}
+	pair.candidate, pair.hasCandidate = output, true
+	if pair.hasPrimary {
+		t.compareLocked(run, key, requestID, pair)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Canary rollouts
+// ---------------------------------------------------------------------------
+
+func (r *modelRegistry) StartCanary(ctx context.Context, cfg *CanaryConfig) (*Experiment, error) {
+	if cfg == nil {
+		return nil, errors.NewInvalidInputError("canary config is required")
+	}
+	if cfg.ModelID == "" || cfg.CandidateVersion == "" {
+		return nil, errors.NewInvalidInputError("model_id and candidate_version are required")
+	}
+	if cfg.MaxErrorRate < 0 || cfg.MaxErrorRate > 1 || cfg.MaxP95LatencyMs < 0 {
+		return nil, errors.NewInvalidInputError("canary SLOs must be non-negative and error rate at most 1")
+	}
+	c := *cfg
+	if len(c.Steps) == 0 {
+		c.Steps = defaultCanarySteps
+	}
+	c.Steps = append([]int(nil), c.Steps...)
+	for i, w := range c.Steps {
+		if w < 1 || w > 99 || (i > 0 && w <= c.Steps[i-1]) {
+			return nil, errors.NewInvalidInputError("canary steps must be ascending percentages between 1 and 99")
+		}
+	}
+	if c.StepDuration <= 0 {
+		c.StepDuration = 10 * time.Minute
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = 100
+	}
+	if c.SignificanceLevel <= 0 || c.SignificanceLevel >= 1 {
+		c.SignificanceLevel = DefaultSignificanceLevel
+	}
+
+	entry, baseline, err := r.prepareCandidate(ctx, c.ModelID, c.CandidateVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	t := r.rollouts
+	t.mu.Lock()
+	if _, busy := t.runs[c.ModelID]; busy {
+		t.mu.Unlock()
+		return nil, errors.ErrConflict("experiment", c.ModelID)
+	}
+	run := &rolloutRun{
+		exp:       t.newExperiment(c.ModelID, ExperimentCanary, baseline, c.CandidateVersion),
+		canary:    &c,
+		stepStart: t.now(),
+	}
+	run.exp.CanaryWeight = c.Steps[0]
+	t.runs[c.ModelID] = run
+	snapshot := run.exp.Clone()
+	t.mu.Unlock()
+
+	r.setTrafficSplit(entry, c.ModelID, baseline, c.CandidateVersion, c.Steps[0])
+	r.saveExperiments(ctx, snapshot)
+	r.logger.Info("canary rollout started",
+		"model_id", c.ModelID,
+		"baseline", baseline,
+		"candidate", c.CandidateVersion,
+		"weight", c.Steps[0],
+	)
+	return snapshot, nil
+}
+
+func (r *modelRegistry) AbortCanary(ctx context.Context, modelID string, reason string) (*Experiment, error) {
+	if reason == "" {
+		reason = "aborted"
+	}
+	t := r.rollouts
+	t.mu.Lock()
+	run, ok := t.runs[modelID]
+	if !ok || run.canary == nil {
+		t.mu.Unlock()
+		return nil, errors.ErrNotFound("canary rollout for model", modelID)
+	}
+	final := t.finishLocked(run, ExperimentRolledBack, reason)
+	t.mu.Unlock()
+
+	if raw, ok := r.models.Load(modelID); ok {
+		r.setTrafficSplit(raw.(*modelEntry), modelID, final.BaselineVersion, final.CandidateVersion, 0)
+	}
+	r.saveExperiments(ctx, final)
+	r.logger.Warn("canary rollout aborted", "model_id", modelID, "reason", reason)
+	return final, nil
+}
+
+// evaluateCanaryLocked checks the SLOs of a canary and decides whether it
+// rolls back, advances to the next step or is promoted.
+func (t *rolloutTracker) evaluateCanaryLocked(run *rolloutRun, now time.Time) *canaryAction {
+	cfg := run.canary
+	exp := run.exp
+	base, cand := exp.Arm(exp.BaselineVersion), exp.Arm(exp.CandidateVersion)
+	act := &canaryAction{modelID: exp.ModelID, baseline: exp.BaselineVersion, candidate: exp.CandidateVersion}
+
+	if cand.Requests >= cfg.MinRequests {
+		reason := ""
+		sig := CompareArms(base, cand, cfg.SignificanceLevel)
+		switch {
+		case cfg.MaxErrorRate > 0 && cand.ErrorRate() > cfg.MaxErrorRate:
+			reason = fmt.Sprintf("error rate %.4f exceeds SLO %.4f", cand.ErrorRate(), cfg.MaxErrorRate)
+		case cfg.MaxP95LatencyMs > 0 && cand.P95LatencyMs() > cfg.MaxP95LatencyMs:
+			reason = fmt.Sprintf("p95 latency %.1fms exceeds SLO %.1fms", cand.P95LatencyMs(), cfg.MaxP95LatencyMs)
+		case sig.ErrorRateDiffers && sig.ErrorRateDelta > 0:
+			reason = fmt.Sprintf("error rate %.4f significantly above baseline %.4f (p=%.4f)",
+				cand.ErrorRate(), base.ErrorRate(), sig.ErrorRatePValue)
+		}
+		if reason != "" {
+			act.final = t.finishLocked(run, ExperimentRolledBack, reason)
+			return act
+		}
+	}
+
+	if cand.Requests-run.stepBase < cfg.MinRequests || now.Sub(run.stepStart) < cfg.StepDuration {
+		return nil
+	}
+	if run.step == len(cfg.Steps)-1 {
+		act.promote = true
+		act.final = t.finishLocked(run, ExperimentPromoted, "all canary steps met the SLOs")
+		return act
+	}
+	run.step++
+	run.stepStart = now
+	run.stepBase = cand.Requests
+	exp.CanaryWeight = cfg.Steps[run.step]
+	run.dirty = true
+	act.weight = exp.CanaryWeight
+	return act
+}
+
+// applyCanaryAction changes traffic for a canary decision. A failed promotion
+// leaves the baseline active and records the rollout as rolled back.
+func (r *modelRegistry) applyCanaryAction(ctx context.Context, act *canaryAction) {
+	raw, ok := r.models.Load(act.modelID)
+	if !ok {
+		return
+	}
+	entry := raw.(*modelEntry)
+	r.setTrafficSplit(entry, act.modelID, act.baseline, act.candidate, act.weight)
+
+	switch {
+	case act.final == nil:
+		r.logger.Info("canary advanced", "model_id", act.modelID, "candidate", act.candidate, "weight", act.weight)
+	case act.promote:
+		if err := r.SetActiveVersion(ctx, act.modelID, act.candidate); err != nil {
+			act.final.Status = ExperimentRolledBack
+			act.final.Reason = "promotion failed: " + err.Error()
+			r.logger.Error("canary promotion failed", "model_id", act.modelID, "candidate", act.candidate, "error", err)
+			return
+		}
+		r.logger.Info("canary promoted", "model_id", act.modelID, "version", act.candidate)
+	default:
+		r.logger.Warn("canary rolled back",
+			"model_id", act.modelID,
+			"candidate", act.candidate,
+			"reason", act.final.Reason,
+		)
+	}
+}
+
+// setTrafficSplit routes weight percent of traffic to the candidate through
+// the A/B routing table; a zero weight restores the active version alone.
+func (r *modelRegistry) setTrafficSplit(entry *modelEntry, modelID, baseline, candidate string, weight int) {
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if weight <= 0 {
+		entry.abTestConfig = nil
+		return
+	}
+	entry.abTestConfig = &ABTestConfig{
+		ModelID: modelID,
+		Variants: []*ABTestVariant{
+			{Version: baseline, TrafficWeight: 100 - weight, Description: "canary baseline"},
+			{Version: candidate, TrafficWeight: weight, Description: "canary candidate"},
+		},
+		Enabled: true,
+	}
+}
+
+// ---------------------------------------------------------------------------
+// A/B test results
+// ---------------------------------------------------------------------------
+
+// checkABTestAllowed rejects A/B changes while a shadow or canary experiment
+// owns the model's traffic.
+func (r *modelRegistry) checkABTestAllowed(modelID string) error {
+	t := r.rollouts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if run, ok := t.runs[modelID]; ok && run.exp.Kind != ExperimentABTest {
+		return errors.ErrConflict("experiment", modelID)
+	}
+	return nil
+}
+
+// recordABTest starts an experiment for a newly enabled A/B test, completing
+// the one it replaces.
+func (r *modelRegistry) recordABTest(ctx context.Context, cfg *ABTestConfig, active string) {
+	versions := make([]string, 0, len(cfg.Variants))
+	baseline := cfg.Variants[0].Version
+	for _, v := range cfg.Variants {
+		versions = append(versions, v.Version)
+		if v.Version == active {
+			baseline = active
+		}
+	}
+	candidate := ""
+	for _, v := range versions {
+		if v != baseline {
+			candidate = v
+			break
+		}
+	}
+
+	t := r.rollouts
+	t.mu.Lock()
+	var saves []*Experiment
+	if run, ok := t.runs[cfg.ModelID]; ok && run.exp.Kind == ExperimentABTest {
+		saves = append(saves, t.finishLocked(run, ExperimentCompleted, "replaced"))
+	}
+	run := &rolloutRun{exp: t.newExperiment(cfg.ModelID, ExperimentABTest, baseline, candidate, versions...)}
+	t.runs[cfg.ModelID] = run
+	saves = append(saves, run.exp.Clone())
+	t.mu.Unlock()
+
+	r.saveExperiments(ctx, saves...)
+}
+
+// endABTest completes the experiment of a disabled or expired A/B test.
+func (r *modelRegistry) endABTest(ctx context.Context, modelID, reason string) {
+	t := r.rollouts
+	t.mu.Lock()
+	run, ok := t.runs[modelID]
+	if !ok || run.exp.Kind != ExperimentABTest {
+		t.mu.Unlock()
+		return
+	}
+	final := t.finishLocked(run, ExperimentCompleted, reason)
+	t.mu.Unlock()
+
+	r.saveExperiments(ctx, final)
+}
+
+// ---------------------------------------------------------------------------
+// Outcomes, evaluation and results
+// ---------------------------------------------------------------------------
+
+func (r *modelRegistry) RecordOutcome(ctx context.Context, outcome *InferenceOutcome) {
+	if outcome == nil || outcome.ModelID == "" || outcome.Version == "" {
+		return
+	}
+	t := r.rollouts
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	run, ok := t.runs[outcome.ModelID]
+	if !ok {
+		return
+	}
+	arm := run.exp.Arm(outcome.Version)
+	if arm == nil {
+		return
+	}
+	arm.Observe(outcome.Latency, outcome.Err != nil)
+	run.dirty = true
+
+	if run.shadow == nil || outcome.Version != run.exp.BaselineVersion || outcome.RequestID == "" {
+		return
+	}
+	key := shadowKey(outcome.ModelID, outcome.RequestID)
+	pair, ok := t.pending[key]
+	if !ok {
+		return
+	}
+	if outcome.Err != nil {
+		delete(t.pending, key)
+		return
+	}
+	pair.primary, pair.hasPrimary = outcome.Output, true
+	if pair.hasCandidate {
+		t.compareLocked(run, key, outcome.RequestID, pair)
+	}
+}
+
+func (r *modelRegistry) EvaluateRollouts(ctx context.Context) {
+	t := r.rollouts
+	now := t.now()
+	pairTTL := 2 * time.Minute
+
+	t.mu.Lock()
+	for key, p := range t.pending {
+		run, ok := t.runs[p.modelID]
+		if ok && run.shadow != nil && pairTTL < 2*run.shadow.Timeout {
+			pairTTL = 2 * run.shadow.Timeout
+		}
+		if now.Sub(p.createdAt) <= pairTTL {
+			continue
+		}
+		delete(t.pending, key)
+		if ok && run.exp.Shadow != nil {
+			run.exp.Shadow.Expired++
+			run.dirty = true
+		}
+	}
+
+	var actions []*canaryAction
+	var saves []*Experiment
+	for _, run := range t.runs {
+		if run.canary != nil {
+			if act := t.evaluateCanaryLocked(run, now); act != nil {
+				actions = append(actions, act)
+				if act.final != nil {
+					continue
+				}
+			}
+		}
+		if run.dirty {
+			run.dirty = false
+			run.exp.UpdatedAt = now
+			saves = append(saves, run.exp.Clone())
+		}
+	}
+	t.mu.Unlock()
+
+	for _, act := range actions {
+		r.applyCanaryAction(ctx, act)
+		if act.final != nil {
+			saves = append(saves, act.final)
+		}
+	}
+	r.saveExperiments(ctx, saves...)
+}
+
+func (r *modelRegistry) GetExperiment(ctx context.Context, id string) (*Experiment, error) {
+	t := r.rollouts
+	t.mu.Lock()
+	for _, run := range t.runs {
+		if run.exp.ID == id {
+			exp := run.exp.Clone()
+			t.mu.Unlock()
+			return exp, nil
+		}
+	}
+	t.mu.Unlock()
+	return r.opts.experimentStore.GetExperiment(ctx, id)
+}
+
+func (r *modelRegistry) ListExperiments(ctx context.Context, modelID string) ([]*Experiment, error) {
+	stored, err := r.opts.experimentStore.ListExperiments(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Running experiments are only flushed periodically; prefer the live view.
+	live := make(map[string]*Experiment)
+	t := r.rollouts
+	t.mu.Lock()
+	for id, run := range t.runs {
+		if modelID == "" || id == modelID {
+			live[run.exp.ID] = run.exp.Clone()
+		}
+	}
+	t.mu.Unlock()
+
+	out := make([]*Experiment, 0, len(stored)+len(live))
+	for _, exp := range stored {
+		if l, ok := live[exp.ID]; ok {
+			exp = l
+			delete(live, exp.ID)
+		}
+		out = append(out, exp)
+	}
+	for _, exp := range live {
+		out = append(out, exp)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, nil
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+// prepareCandidate validates a candidate version against the active version
+// and loads it so it can serve traffic.
+func (r *modelRegistry) prepareCandidate(ctx context.Context, modelID, candidate string) (*modelEntry, string, error) {
+	raw, ok := r.models.Load(modelID)
+	if !ok {
+		return nil, "", errors.ErrNotFound("model", modelID)
+	}
+	entry := raw.(*modelEntry)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	active := entry.getActiveVersion()
+	if active == "" {
+		return nil, "", errors.ErrNotFound("active version for model", modelID)
+	}
+	if active == candidate {
+		return nil, "", errors.NewInvalidInputError(fmt.Sprintf("version %s is already active", candidate))
+	}
+	ve, exists := entry.versions[candidate]
+	if !exists {
+		return nil, "", errors.ErrNotFound("version", candidate)
+	}
+	switch ve.info.Status {
+	case VersionStatusDeprecated, VersionStatusFailed:
+		return nil, "", errors.NewInvalidInputError(
+			fmt.Sprintf("version %s of model %s is %s", candidate, modelID, ve.info.Status))
+	case VersionStatusRegistered, VersionStatusUnloaded:
+		ve.info.Status = VersionStatusLoading
+		handle, err := r.loader.Load(ctx, ve.info.ArtifactPath)
+		if err != nil {
+			ve.info.Status = VersionStatusFailed
+			r.metrics.RecordModelLoad(ctx, modelID, candidate, 0, false)
+			return nil, "", fmt.Errorf("loading version %s: %w", candidate, err)
+		}
+		ve.handle = handle
+		ve.info.Status = VersionStatusReady
+		ve.loadedAt = time.Now()
+		r.metrics.RecordModelLoad(ctx, modelID, candidate, 0, true)
+	}
+	if ve.info.Status != VersionStatusReady {
+		return nil, "", fmt.Errorf("version %s is not ready (status: %s)", candidate, ve.info.Status)
+	}
+	return entry, active, nil
+}
+
+func (r *modelRegistry) saveExperiments(ctx context.Context, exps ...*Experiment) {
+	for _, exp := range exps {
+		if err := r.opts.experimentStore.SaveExperiment(ctx, exp); err != nil {
+			r.logger.Error("failed to persist experiment",
+				"experiment_id", exp.ID,
+				"model_id", exp.ModelID,
+				"error", err,
+			)
+		}
+	}
+}
+
+//Personal.AI order the ending
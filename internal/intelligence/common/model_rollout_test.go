@@ -0,0 +1,372 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// scriptedInvoker answers shadow calls with the input, prefixed for the
+// request payloads listed in differ.
+type scriptedInvoker struct {
+	mu     sync.Mutex
+	calls  []string
+	differ map[string]bool
+}
+
+func (s *scriptedInvoker) Invoke(_ context.Context, model *RegisteredModel, input []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, model.Metadata.Version)
+	if s.differ[string(input)] {
+		return []byte("changed:" + string(input)), nil
+	}
+	return input, nil
+}
+
+// fakeClock is a settable time source for canary step timing.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newRolloutTestRegistry(t *testing.T, opts ...RegistryOption) (*modelRegistry, *fakeClock) {
+	t.Helper()
+	opts = append([]RegistryOption{
+		WithRegistryHealthCheckInterval(1 * time.Hour),
+		WithRolloutEvaluationInterval(1 * time.Hour), // tests evaluate explicitly
+		WithUnloadDelay(0),
+	}, opts...)
+	reg, err := NewModelRegistry(newMockModelLoader(), nil, NewNoopLogger(), opts...)
+	if err != nil {
+		t.Fatalf("NewModelRegistry: %v", err)
+	}
+	t.Cleanup(func() { _ = reg.Close() })
+	clock := &fakeClock{now: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)}
+	reg.rollouts.now = clock.Now
+	activateTestModel(t, reg, "infringe-net", "1.0.0")
+	registerTestModel(t, reg, "infringe-net", "1.1.0")
+	return reg, clock
+}
+
+func recordOutcomes(reg *modelRegistry, version string, n, failures int, latency time.Duration) {
+	for i := 0; i < n; i++ {
+		var err error
+		if i < failures {
+			err = fmt.Errorf("inference failed")
+		}
+		reg.RecordOutcome(context.Background(), &InferenceOutcome{
+			ModelID: "infringe-net", Version: version, RequestID: fmt.Sprintf("%s-%d", version, i),
+			Latency: latency, Err: err,
+		})
+	}
+}
+
+func TestTwoProportionZTest(t *testing.T) {
+	z, p := TwoProportionZTest(30, 1000, 10, 1000)
+	if math.Abs(z-3.194) > 0.01 || math.Abs(p-0.0014) > 0.0002 {
+		t.Errorf("z=%.4f p=%.5f, want z≈3.194 p≈0.0014", z, p)
+	}
+	if _, p := TwoProportionZTest(0, 0, 5, 100); p != 1 {
+		t.Errorf("empty sample should give p=1, got %v", p)
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	tStat, df, p := WelchTTest(10, 4, 10, 8, 4, 10)
+	if math.Abs(tStat-2.236) > 0.001 || math.Abs(df-18) > 1e-9 || math.Abs(p-0.0382) > 0.0005 {
+		t.Errorf("t=%.4f df=%.2f p=%.5f, want t≈2.236 df=18 p≈0.0382", tStat, df, p)
+	}
+	if _, _, p := WelchTTest(10, 4, 1, 8, 4, 10); p != 1 {
+		t.Errorf("single observation should give p=1, got %v", p)
+	}
+}
+
+func TestArmStats(t *testing.T) {
+	arm := &ArmStats{Version: "1.0.0"}
+	for i := 1; i <= 100; i++ {
+		arm.Observe(time.Duration(i)*time.Millisecond, false)
+	}
+	arm.Observe(0, true)
+	if arm.Requests != 101 || arm.Errors != 1 {
+		t.Errorf("unexpected counts %+v", arm)
+	}
+	if got := arm.MeanLatencyMs(); math.Abs(got-50.5) > 1e-9 {
+		t.Errorf("mean = %v, want 50.5", got)
+	}
+	if got := arm.P95LatencyMs(); got != 95 {
+		t.Errorf("p95 = %v, want 95", got)
+	}
+	if got := arm.LatencyVarianceMs(); math.Abs(got-841.6667) > 0.001 {
+		t.Errorf("variance = %v, want 841.67", got)
+	}
+}
+
+func TestExactOutputComparator(t *testing.T) {
+	if ExactOutputComparator([]byte(`{"a":1,"b":2}`), []byte(`{"b":2, "a":1}`)) != 0 {
+		t.Error("equivalent JSON should compare equal")
+	}
+	if ExactOutputComparator([]byte("infringing"), []byte("clear")) != 1 {
+		t.Error("different outputs should score 1")
+	}
+}
+
+func TestConfigureShadow_RequiresInvoker(t *testing.T) {
+	reg, _ := newRolloutTestRegistry(t)
+	_, err := reg.ConfigureShadow(context.Background(), &ShadowConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0"})
+	if !errors.IsCode(err, errors.ErrCodeBadRequest) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+}
+
+func TestShadow_MirrorsAndRecordsDiffs(t *testing.T) {
+	inv := &scriptedInvoker{differ: map[string]bool{"payload-3": true}}
+	reg, _ := newRolloutTestRegistry(t, WithModelInvoker(inv))
+	ctx := context.Background()
+
+	started, err := reg.ConfigureShadow(ctx, &ShadowConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0"})
+	if err != nil {
+		t.Fatalf("ConfigureShadow: %v", err)
+	}
+	if started.BaselineVersion != "1.0.0" || started.Status != ExperimentRunning {
+		t.Errorf("unexpected experiment %+v", started)
+	}
+
+	for i := 0; i < 5; i++ {
+		reqID := fmt.Sprintf("req-%d", i)
+		payload := []byte(fmt.Sprintf("payload-%d", i))
+		rm, err := reg.ResolveModel(ContextWithShadowInput(ctx, payload), "infringe-net", reqID)
+		if err != nil {
+			t.Fatalf("ResolveModel: %v", err)
+		}
+		if rm.Metadata.Version != "1.0.0" {
+			t.Fatalf("shadow must not change the served version, got %s", rm.Metadata.Version)
+		}
+		reg.RecordOutcome(ctx, &InferenceOutcome{
+			ModelID: "infringe-net", Version: "1.0.0", RequestID: reqID, Latency: 20 * time.Millisecond, Output: payload,
+		})
+	}
+	// Requests without a payload are served but not mirrored.
+	if _, err := reg.ResolveModel(ctx, "infringe-net", "req-no-input"); err != nil {
+		t.Fatalf("ResolveModel: %v", err)
+	}
+	reg.rollouts.shadowWG.Wait()
+
+	final, err := reg.StopShadow(ctx, "infringe-net")
+	if err != nil {
+		t.Fatalf("StopShadow: %v", err)
+	}
+	if len(inv.calls) != 5 || inv.calls[0] != "1.1.0" {
+		t.Errorf("expected 5 mirrored calls to 1.1.0, got %v", inv.calls)
+	}
+	if final.Status != ExperimentCompleted || final.Shadow.Compared != 5 || final.Shadow.Mismatches != 1 {
+		t.Errorf("unexpected shadow stats %+v", final.Shadow)
+	}
+	if len(final.Shadow.Recent) != 1 || final.Shadow.Recent[0].RequestID != "req-3" {
+		t.Errorf("expected the mismatching request to be kept, got %+v", final.Shadow.Recent)
+	}
+	if cand := final.Arm("1.1.0"); cand.Requests != 5 {
+		t.Errorf("expected candidate arm to count mirrored calls, got %+v", cand)
+	}
+
+	stored, err := reg.GetExperiment(ctx, final.ID)
+	if err != nil || stored.Status != ExperimentCompleted {
+		t.Errorf("expected persisted result, got %+v, %v", stored, err)
+	}
+}
+
+func TestCanary_AdvancesAndPromotes(t *testing.T) {
+	reg, clock := newRolloutTestRegistry(t)
+	ctx := context.Background()
+
+	exp, err := reg.StartCanary(ctx, &CanaryConfig{
+		ModelID: "infringe-net", CandidateVersion: "1.1.0",
+		Steps: []int{10, 50}, StepDuration: time.Minute, MinRequests: 20,
+		MaxErrorRate: 0.05, MaxP95LatencyMs: 200,
+	})
+	if err != nil {
+		t.Fatalf("StartCanary: %v", err)
+	}
+	if exp.CanaryWeight != 10 {
+		t.Errorf("expected first step weight 10, got %d", exp.CanaryWeight)
+	}
+
+	routed := 0
+	for i := 0; i < 1000; i++ {
+		rm, _ := reg.ResolveModel(ctx, "infringe-net", fmt.Sprintf("r-%d", i))
+		if rm.Metadata.Version == "1.1.0" {
+			routed++
+		}
+	}
+	if routed < 50 || routed > 150 {
+		t.Errorf("expected about 10%% of traffic on the candidate, got %d/1000", routed)
+	}
+
+	recordOutcomes(reg, "1.0.0", 200, 2, 40*time.Millisecond)
+	recordOutcomes(reg, "1.1.0", 20, 0, 35*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+	if got, _ := reg.GetExperiment(ctx, exp.ID); got.CanaryWeight != 10 {
+		t.Fatalf("step must hold for its duration, weight %d", got.CanaryWeight)
+	}
+
+	clock.Advance(2 * time.Minute)
+	reg.EvaluateRollouts(ctx)
+	if got, _ := reg.GetExperiment(ctx, exp.ID); got.CanaryWeight != 50 {
+		t.Fatalf("expected advance to 50, got %d", got.CanaryWeight)
+	}
+
+	clock.Advance(2 * time.Minute)
+	reg.EvaluateRollouts(ctx)
+	if got, _ := reg.GetExperiment(ctx, exp.ID); got.CanaryWeight != 50 {
+		t.Fatal("a step must not advance before it sees MinRequests new candidate requests")
+	}
+
+	recordOutcomes(reg, "1.1.0", 20, 0, 35*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+
+	final, err := reg.GetExperiment(ctx, exp.ID)
+	if err != nil {
+		t.Fatalf("GetExperiment: %v", err)
+	}
+	if final.Status != ExperimentPromoted || final.EndedAt.IsZero() {
+		t.Errorf("expected promotion, got %+v", final)
+	}
+	model, _ := reg.GetModel(ctx, "infringe-net")
+	if model.ActiveVersion != "1.1.0" || model.PreviousVersion != "1.0.0" {
+		t.Errorf("expected 1.1.0 active with 1.0.0 for rollback, got %+v", model)
+	}
+	if rm, _ := reg.ResolveModel(ctx, "infringe-net", "after"); rm.Metadata.Version != "1.1.0" {
+		t.Error("traffic split must be cleared after promotion")
+	}
+}
+
+func TestCanary_RollsBackOnSLOBreach(t *testing.T) {
+	reg, _ := newRolloutTestRegistry(t)
+	ctx := context.Background()
+
+	exp, err := reg.StartCanary(ctx, &CanaryConfig{
+		ModelID: "infringe-net", CandidateVersion: "1.1.0", MinRequests: 20, MaxErrorRate: 0.05,
+	})
+	if err != nil {
+		t.Fatalf("StartCanary: %v", err)
+	}
+	recordOutcomes(reg, "1.1.0", 19, 5, 30*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+	if got, _ := reg.GetExperiment(ctx, exp.ID); got.Status != ExperimentRunning {
+		t.Fatal("SLOs are only judged after MinRequests")
+	}
+
+	recordOutcomes(reg, "1.1.0", 1, 0, 30*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+
+	final, _ := reg.GetExperiment(ctx, exp.ID)
+	if final.Status != ExperimentRolledBack || !strings.Contains(final.Reason, "error rate") {
+		t.Errorf("expected rollback on error rate, got %s %q", final.Status, final.Reason)
+	}
+	model, _ := reg.GetModel(ctx, "infringe-net")
+	if model.ActiveVersion != "1.0.0" {
+		t.Errorf("baseline must stay active, got %s", model.ActiveVersion)
+	}
+	for i := 0; i < 200; i++ {
+		if rm, _ := reg.ResolveModel(ctx, "infringe-net", fmt.Sprintf("r-%d", i)); rm.Metadata.Version != "1.0.0" {
+			t.Fatal("candidate must receive no traffic after rollback")
+		}
+	}
+}
+
+func TestCanary_RollsBackOnSignificantRegression(t *testing.T) {
+	reg, _ := newRolloutTestRegistry(t)
+	ctx := context.Background()
+
+	exp, _ := reg.StartCanary(ctx, &CanaryConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0", MinRequests: 100})
+	recordOutcomes(reg, "1.0.0", 2000, 20, 40*time.Millisecond)
+	recordOutcomes(reg, "1.1.0", 200, 12, 40*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+
+	final, _ := reg.GetExperiment(ctx, exp.ID)
+	if final.Status != ExperimentRolledBack || !strings.Contains(final.Reason, "significantly") {
+		t.Errorf("expected rollback on significant regression, got %s %q", final.Status, final.Reason)
+	}
+	if sig := final.Significance(0.05); !sig.CandidateRegressed {
+		t.Errorf("expected regression to be significant, got %+v", sig)
+	}
+}
+
+func TestCanary_Conflicts(t *testing.T) {
+	reg, _ := newRolloutTestRegistry(t)
+	ctx := context.Background()
+
+	if _, err := reg.StartCanary(ctx, &CanaryConfig{ModelID: "infringe-net", CandidateVersion: "1.0.0"}); err == nil {
+		t.Error("expected error when the candidate is already active")
+	}
+	if _, err := reg.StartCanary(ctx, &CanaryConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0", Steps: []int{50, 20}}); err == nil {
+		t.Error("expected error for descending steps")
+	}
+	if _, err := reg.StartCanary(ctx, &CanaryConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0"}); err != nil {
+		t.Fatalf("StartCanary: %v", err)
+	}
+	if _, err := reg.StartCanary(ctx, &CanaryConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0"}); !errors.IsCode(err, errors.ErrCodeConflict) {
+		t.Errorf("expected conflict for a second canary, got %v", err)
+	}
+	err := reg.ConfigureABTest(ctx, &ABTestConfig{ModelID: "infringe-net", Enabled: true,
+		Variants: []*ABTestVariant{{Version: "1.0.0", TrafficWeight: 50}, {Version: "1.1.0", TrafficWeight: 50}}})
+	if !errors.IsCode(err, errors.ErrCodeConflict) {
+		t.Errorf("expected conflict for an A/B test during a canary, got %v", err)
+	}
+
+	final, err := reg.AbortCanary(ctx, "infringe-net", "")
+	if err != nil || final.Status != ExperimentRolledBack || final.Reason != "aborted" {
+		t.Errorf("unexpected abort result %+v, %v", final, err)
+	}
+}
+
+func TestABTest_OutcomesPersisted(t *testing.T) {
+	store := NewMemoryExperimentStore()
+	reg, _ := newRolloutTestRegistry(t, WithExperimentStore(store))
+	ctx := context.Background()
+
+	err := reg.ConfigureABTest(ctx, &ABTestConfig{ModelID: "infringe-net", Enabled: true,
+		Variants: []*ABTestVariant{{Version: "1.1.0", TrafficWeight: 30}, {Version: "1.0.0", TrafficWeight: 70}}})
+	if err != nil {
+		t.Fatalf("ConfigureABTest: %v", err)
+	}
+	recordOutcomes(reg, "1.0.0", 7, 0, 10*time.Millisecond)
+	recordOutcomes(reg, "1.1.0", 3, 1, 10*time.Millisecond)
+	reg.EvaluateRollouts(ctx)
+
+	list, _ := store.ListExperiments(ctx, "infringe-net")
+	if len(list) != 1 || list[0].Kind != ExperimentABTest || list[0].BaselineVersion != "1.0.0" || list[0].CandidateVersion != "1.1.0" {
+		t.Fatalf("unexpected stored experiments %+v", list)
+	}
+	if list[0].Arm("1.1.0").Errors != 1 || list[0].Arm("1.0.0").Requests != 7 {
+		t.Errorf("outcomes not flushed: %+v", list[0].Arms)
+	}
+
+	if err := reg.ConfigureABTest(ctx, &ABTestConfig{ModelID: "infringe-net", Enabled: false}); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	got, _ := store.GetExperiment(ctx, list[0].ID)
+	if got.Status != ExperimentCompleted || got.Reason != "disabled" {
+		t.Errorf("expected completed experiment, got %s %q", got.Status, got.Reason)
+	}
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,425 @@
+package common
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// FieldEmbeddingModel is the vector-collection field recording which model
+// produced a row's vector, as EmbeddingModelTag formats it.
+const FieldEmbeddingModel = "embedding_model"
+
+// VectorRow builds a row for a vector collection from an embedding and the
+// row's scalar fields, stamping FieldEmbeddingModel. Fallback vectors are
+// refused with ErrFallbackEmbedding so they cannot be indexed by accident.
+func VectorRow(vectorField string, emb *Embedding, fields map[string]interface{}) (map[string]interface{}, error) {
+	if emb == nil || len(emb.Vector) == 0 {
+		return nil, errors.NewInvalidInputError("embedding is empty")
+	}
+	if emb.Fallback {
+		return nil, ErrFallbackEmbedding
+	}
+	row := make(map[string]interface{}, len(fields)+2)
+	for k, v := range fields {
+		row[k] = v
+	}
+	row[vectorField] = emb.Vector
+	row[FieldEmbeddingModel] = emb.Tag()
+	return row, nil
+}
+
+// ---------------------------------------------------------------------------
+// Re-embedding job
+// ---------------------------------------------------------------------------
+
+// ReembedRecord is one entity whose vector is to be recomputed.
+type ReembedRecord struct {
+	ID     int64
+	Text   string
+	Fields map[string]interface{}
+}
+
+// ReembedSource pages through the text behind a vector collection in
+// ascending ID order, returning records with ID > afterID.
+type ReembedSource interface {
+	NextBatch(ctx context.Context, afterID int64, limit int) ([]ReembedRecord, error)
+}
+
+// VectorWriter writes rows to a vector collection. milvus.Searcher and
+// hnsw.Searcher satisfy it.
+type VectorWriter interface {
+	Upsert(ctx context.Context, req commontypes.InsertRequest) (*commontypes.InsertResult, error)
+}
+
+// VectorSearcher searches a vector collection. milvus.Searcher and
+// hnsw.Searcher satisfy it.
+type VectorSearcher interface {
+	Search(ctx context.Context, req commontypes.VectorSearchRequest) (*commontypes.VectorSearchResult, error)
+}
+
+// ReembeddingConfig configures a ReembeddingJob.
+type ReembeddingConfig struct {
+	// TargetCollection receives the new vectors. It must be a different
+	// collection from the one being served, so the two model versions are
+	// never mixed.
+	TargetCollection string
+	// VectorField defaults to "embedding".
+	VectorField string
+	// BatchSize defaults to 64.
+	BatchSize int
+	// StartAfterID resumes a previous run from its LastID.
+	StartAfterID int64
+}
+
+// ReembeddingProgress reports how far a job has got. LastID is the resume
+// point: every record up to it has been written or skipped.
+type ReembeddingProgress struct {
+	TargetCollection string  `json:"target_collection"`
+	ModelTag         string  `json:"model_tag"`
+	LastID           int64   `json:"last_id"`
+	Embedded         int64   `json:"embedded"`
+	Fallbacks        int64   `json:"fallbacks"`
+	FallbackIDs      []int64 `json:"fallback_ids,omitempty"`
+	Done             bool    `json:"done"`
+}
+
+// maxReportedFallbackIDs bounds ReembeddingProgress.FallbackIDs.
+const maxReportedFallbackIDs = 100
+
+// ReembeddingOption configures a ReembeddingJob.
+type ReembeddingOption func(*ReembeddingJob)
+
+// WithReembeddingLogger injects a logger.
+func WithReembeddingLogger(l Logger) ReembeddingOption {
+	return func(j *ReembeddingJob) { j.logger = l }
+}
+
+// WithReembeddingProgress is called after every written batch, e.g. with
+// DualReadSearcher.Advance or to persist a checkpoint.
+func WithReembeddingProgress(fn func(ReembeddingProgress)) ReembeddingOption {
+	return func(j *ReembeddingJob) { j.onProgress = fn }
+}
+
+// ReembeddingJob recomputes every vector of a collection with a new
+// embedding model version into a separate target collection. Records whose
+// new vector is a fallback are skipped and reported, never written.
+type ReembeddingJob struct {
+	source     ReembedSource
+	target     VectorWriter
+	embedder   TaggedEmbedder
+	cfg        ReembeddingConfig
+	logger     Logger
+	onProgress func(ReembeddingProgress)
+}
+
+// NewReembeddingJob creates a job writing embedder's vectors for source
+// into cfg.TargetCollection.
+func NewReembeddingJob(source ReembedSource, target VectorWriter, embedder TaggedEmbedder, cfg ReembeddingConfig, opts ...ReembeddingOption) (*ReembeddingJob, error) {
+	if source == nil || target == nil || embedder == nil {
+		return nil, errors.NewInvalidInputError("source, target and embedder are required")
+	}
+	if cfg.TargetCollection == "" {
+		return nil, errors.NewInvalidInputError("target collection is required")
+	}
+	if cfg.VectorField == "" {
+		cfg.VectorField = "embedding"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 64
+	}
+	j := &ReembeddingJob{source: source, target: target, embedder: embedder, cfg: cfg}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.logger == nil {
+		j.logger = NewNoopLogger()
+	}
+	return j, nil
+}
+
+// Run migrates records until the source is exhausted or an error occurs.
+// On error the returned progress holds the point to resume from.
+func (j *ReembeddingJob) Run(ctx context.Context) (ReembeddingProgress, error) {
+	id, version := j.embedder.EmbeddingModel()
+	tag := EmbeddingModelTag(id, version)
+	p := ReembeddingProgress{TargetCollection: j.cfg.TargetCollection, ModelTag: tag, LastID: j.cfg.StartAfterID}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		batch, err := j.source.NextBatch(ctx, p.LastID, j.cfg.BatchSize)
+		if err != nil {
+			return p, errors.Wrap(err, errors.ErrCodeInternal, "read re-embedding source")
+		}
+		if len(batch) == 0 {
+			p.Done = true
+			j.logger.Info("re-embedding complete", "collection", j.cfg.TargetCollection, "model", tag,
+				"embedded", p.Embedded, "fallbacks", p.Fallbacks)
+			return p, nil
+		}
+
+		rows := make([]map[string]interface{}, 0, len(batch))
+		var fallbackIDs []int64
+		for _, rec := range batch {
+			emb, err := j.embedder.EmbedTagged(ctx, rec.Text)
+			if err != nil {
+				return p, errors.Wrap(err, errors.ErrCodeInternal, "re-embed record "+strconv.FormatInt(rec.ID, 10))
+			}
+			if emb.Fallback {
+				fallbackIDs = append(fallbackIDs, rec.ID)
+				continue
+			}
+			if emb.Tag() != tag {
+				return p, errors.Newf(errors.ErrCodeInternal, "embedder returned a %s vector while migrating to %s", emb.Tag(), tag)
+			}
+			fields := make(map[string]interface{}, len(rec.Fields)+1)
+			for k, v := range rec.Fields {
+				fields[k] = v
+			}
+			fields["id"] = rec.ID
+			row, err := VectorRow(j.cfg.VectorField, emb, fields)
+			if err != nil {
+				return p, err
+			}
+			rows = append(rows, row)
+		}
+
+		if len(rows) > 0 {
+			if _, err := j.target.Upsert(ctx, commontypes.InsertRequest{CollectionName: j.cfg.TargetCollection, Data: rows}); err != nil {
+				return p, errors.Wrap(err, errors.ErrCodeInternal, "write re-embedded vectors")
+			}
+		}
+		p.Embedded += int64(len(rows))
+		p.Fallbacks += int64(len(fallbackIDs))
+		for _, fid := range fallbackIDs {
+			if len(p.FallbackIDs) < maxReportedFallbackIDs {
+				p.FallbackIDs = append(p.FallbackIDs, fid)
+			}
+		}
+		if len(fallbackIDs) > 0 {
+			j.logger.Warn("re-embedding skipped fallback vectors", "collection", j.cfg.TargetCollection, "ids", fallbackIDs)
+		}
+		p.LastID = batch[len(batch)-1].ID
+		if j.onProgress != nil {
+			j.onProgress(p)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Dual-read cutover
+// ---------------------------------------------------------------------------
+
+// CutoverPhase selects which collections a DualReadSearcher reads.
+type CutoverPhase int
+
+const (
+	// CutoverSource reads only the collection being replaced.
+	CutoverSource CutoverPhase = iota
+	// CutoverDualRead reads both and merges, while the target is filled.
+	CutoverDualRead
+	// CutoverTarget reads only the re-embedded collection.
+	CutoverTarget
+)
+
+// String implements fmt.Stringer.
+func (p CutoverPhase) String() string {
+	switch p {
+	case CutoverSource:
+		return "source"
+	case CutoverDualRead:
+		return "dual_read"
+	case CutoverTarget:
+		return "target"
+	default:
+		return "unknown"
+	}
+}
+
+// EmbeddedCollection is a vector collection and the embedder its vectors
+// were produced with. Queries against it must use the same embedder.
+type EmbeddedCollection struct {
+	Name     string
+	Embedder TaggedEmbedder
+}
+
+// DualReadSearcher answers text queries while a collection is migrated to
+// a new embedding model. Each collection is queried with its own model, so
+// vectors from different models are never compared. In the dual-read phase
+// source hits already migrated (ID at or below the job's LastID) are
+// dropped and the two rankings are merged by reciprocal rank, since scores
+// from different models are not comparable.
+type DualReadSearcher struct {
+	searcher    VectorSearcher
+	source      EmbeddedCollection
+	target      EmbeddedCollection
+	vectorField string
+	logger      Logger
+
+	mu       sync.RWMutex
+	phase    CutoverPhase
+	migrated int64
+}
+
+// NewDualReadSearcher starts in CutoverSource.
+func NewDualReadSearcher(searcher VectorSearcher, source, target EmbeddedCollection, vectorField string, logger Logger) (*DualReadSearcher, error) {
+	if searcher == nil || source.Embedder == nil || target.Embedder == nil {
+		return nil, errors.NewInvalidInputError("searcher and both embedders are required")
+	}
+	if source.Name == "" || target.Name == "" || source.Name == target.Name {
+		return nil, errors.NewInvalidInputError("source and target must be distinct collections")
+	}
+	if vectorField == "" {
+		vectorField = "embedding"
+	}
+	if logger == nil {
+		logger = NewNoopLogger()
+	}
+	return &DualReadSearcher{searcher: searcher, source: source, target: target, vectorField: vectorField, logger: logger}, nil
+}
+
+// SetPhase switches the read phase.
+func (d *DualReadSearcher) SetPhase(p CutoverPhase) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.phase = p
+}
+
+// Phase returns the current read phase.
+func (d *DualReadSearcher) Phase() CutoverPhase {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.phase
+}
+
+// Advance records migration progress, entering the dual-read phase from
+// the source phase. Pass it to WithReembeddingProgress.
+func (d *DualReadSearcher) Advance(p ReembeddingProgress) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p.LastID > d.migrated {
+		d.migrated = p.LastID
+	}
+	if d.phase == CutoverSource {
+		d.phase = CutoverDualRead
+	}
+}
+
+// SearchText embeds text for each collection in play and returns up to
+// topK hits. The target collection is restricted to rows stamped with the
+// target model.
+func (d *DualReadSearcher) SearchText(ctx context.Context, text string, topK int, filters string, outputFields []string) ([]commontypes.VectorHit, error) {
+	d.mu.RLock()
+	phase, migrated := d.phase, d.migrated
+	d.mu.RUnlock()
+
+	switch phase {
+	case CutoverSource:
+		return d.search(ctx, d.source, text, topK, filters, outputFields)
+	case CutoverTarget:
+		return d.search(ctx, d.target, text, topK, d.targetFilter(filters), outputFields)
+	}
+
+	targetHits, targetErr := d.search(ctx, d.target, text, topK, d.targetFilter(filters), outputFields)
+	sourceHits, sourceErr := d.search(ctx, d.source, text, topK, filters, outputFields)
+	switch {
+	case targetErr != nil && sourceErr != nil:
+		return nil, targetErr
+	case targetErr != nil:
+		d.logger.Warn("dual read: target search failed, serving source only", "collection", d.target.Name, "error", targetErr)
+	case sourceErr != nil:
+		d.logger.Warn("dual read: source search failed, serving target only", "collection", d.source.Name, "error", sourceErr)
+	}
+
+	pending := sourceHits[:0:0]
+	for _, h := range sourceHits {
+		if h.ID > migrated {
+			pending = append(pending, h)
+		}
+	}
+	return mergeByRank(topK, targetHits, pending), nil
+}
+
+func (d *DualReadSearcher) search(ctx context.Context, coll EmbeddedCollection, text string, topK int, filters string, outputFields []string) ([]commontypes.VectorHit, error) {
+	emb, err := coll.Embedder.EmbedTagged(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if emb.Fallback {
+		return nil, ErrFallbackEmbedding
+	}
+	res, err := d.searcher.Search(ctx, commontypes.VectorSearchRequest{
+		CollectionName:  coll.Name,
+		VectorFieldName: d.vectorField,
+		Vectors:         [][]float32{emb.Vector},
+		TopK:            topK,
+		Filters:         filters,
+		OutputFields:    outputFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Results) == 0 {
+		return nil, nil
+	}
+	return res.Results[0], nil
+}
+
+func (d *DualReadSearcher) targetFilter(filters string) string {
+	id, version := d.target.Embedder.EmbeddingModel()
+	expr := FieldEmbeddingModel + " == " + strconv.Quote(EmbeddingModelTag(id, version))
+	if filters == "" {
+		return expr
+	}
+	return "(" + filters + ") && " + expr
+}
+
+// rrfK is the usual reciprocal rank fusion constant.
+const rrfK = 60
+
+// mergeByRank fuses rankings by reciprocal rank, keeping each hit's own
+// score and the first occurrence of an ID.
+func mergeByRank(topK int, rankings ...[]commontypes.VectorHit) []commontypes.VectorHit {
+	type fused struct {
+		hit   commontypes.VectorHit
+		score float64
+		order int
+	}
+	byID := make(map[int64]*fused)
+	for _, ranking := range rankings {
+		for rank, h := range ranking {
+			f, ok := byID[h.ID]
+			if !ok {
+				f = &fused{hit: h, order: len(byID)}
+				byID[h.ID] = f
+			}
+			f.score += 1 / float64(rrfK+rank+1)
+		}
+	}
+	all := make([]*fused, 0, len(byID))
+	for _, f := range byID {
+		all = append(all, f)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].order < all[j].order
+	})
+	if topK > 0 && len(all) > topK {
+		all = all[:topK]
+	}
+	out := make([]commontypes.VectorHit, len(all))
+	for i, f := range all {
+		out[i] = f.hit
+	}
+	return out
+}
+
+//Personal.AI order the ending
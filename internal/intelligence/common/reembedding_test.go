@@ -0,0 +1,210 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// sliceReembedSource serves records sorted by ID.
+type sliceReembedSource struct {
+	records []ReembedRecord
+	failAt  int64 // NextBatch fails once when afterID equals this
+}
+
+func (s *sliceReembedSource) NextBatch(_ context.Context, afterID int64, limit int) ([]ReembedRecord, error) {
+	if s.failAt != 0 && afterID == s.failAt {
+		s.failAt = 0
+		return nil, errors.New("source unavailable")
+	}
+	var out []ReembedRecord
+	for _, r := range s.records {
+		if r.ID > afterID && len(out) < limit {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// memoryVectorCollections records upserted rows and answers searches from
+// scripted hits, capturing each request.
+type memoryVectorCollections struct {
+	mu       sync.Mutex
+	rows     map[string]map[int64]map[string]interface{}
+	hits     map[string][]commontypes.VectorHit
+	requests []commontypes.VectorSearchRequest
+}
+
+func newMemoryVectorCollections() *memoryVectorCollections {
+	return &memoryVectorCollections{
+		rows: make(map[string]map[int64]map[string]interface{}),
+		hits: make(map[string][]commontypes.VectorHit),
+	}
+}
+
+func (m *memoryVectorCollections) Upsert(_ context.Context, req commontypes.InsertRequest) (*commontypes.InsertResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rows[req.CollectionName] == nil {
+		m.rows[req.CollectionName] = make(map[int64]map[string]interface{})
+	}
+	res := &commontypes.InsertResult{}
+	for _, row := range req.Data {
+		id := row["id"].(int64)
+		m.rows[req.CollectionName][id] = row
+		res.IDs = append(res.IDs, id)
+	}
+	res.InsertedCount = int64(len(res.IDs))
+	return res, nil
+}
+
+func (m *memoryVectorCollections) Search(_ context.Context, req commontypes.VectorSearchRequest) (*commontypes.VectorSearchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+	return &commontypes.VectorSearchResult{Results: [][]commontypes.VectorHit{m.hits[req.CollectionName]}}, nil
+}
+
+func (m *memoryVectorCollections) ids(collection string) []int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []int64
+	for id := range m.rows[collection] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func reembedRecords(texts ...string) []ReembedRecord {
+	out := make([]ReembedRecord, len(texts))
+	for i, text := range texts {
+		out[i] = ReembedRecord{ID: int64(i + 1), Text: text, Fields: map[string]interface{}{"patent_number": "CN" + text}}
+	}
+	return out
+}
+
+func TestVectorRow_RefusesFallback(t *testing.T) {
+	row, err := VectorRow("embedding", &Embedding{Vector: []float32{1}, ModelID: "m", ModelVersion: "2"}, map[string]interface{}{"id": int64(7)})
+	if err != nil {
+		t.Fatalf("VectorRow: %v", err)
+	}
+	if row[FieldEmbeddingModel] != "m@2" || row["id"] != int64(7) {
+		t.Errorf("unexpected row %v", row)
+	}
+	if _, err := VectorRow("embedding", &Embedding{Vector: []float32{1}, Fallback: true}, nil); !errors.Is(err, ErrFallbackEmbedding) {
+		t.Errorf("expected ErrFallbackEmbedding, got %v", err)
+	}
+}
+
+func TestReembeddingJob_MigratesAndSkipsFallbacks(t *testing.T) {
+	src := &sliceReembedSource{records: reembedRecords("a", "bb", "??", "dddd", "eeeee")}
+	store := newMemoryVectorCollections()
+	embedder := &scriptedEmbedder{id: "m", ver: "2", fallback: map[string]bool{"??": true}}
+
+	var reported []int64
+	job, err := NewReembeddingJob(src, store, embedder,
+		ReembeddingConfig{TargetCollection: "patents_v2", BatchSize: 2},
+		WithReembeddingProgress(func(p ReembeddingProgress) { reported = append(reported, p.LastID) }))
+	if err != nil {
+		t.Fatalf("NewReembeddingJob: %v", err)
+	}
+
+	p, err := job.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !p.Done || p.Embedded != 4 || p.Fallbacks != 1 || len(p.FallbackIDs) != 1 || p.FallbackIDs[0] != 3 {
+		t.Errorf("unexpected progress %+v", p)
+	}
+	if got := store.ids("patents_v2"); len(got) != 4 || got[2] != 4 {
+		t.Errorf("fallback record must not be written, got ids %v", got)
+	}
+	row := store.rows["patents_v2"][1]
+	if row[FieldEmbeddingModel] != "m@2" || row["patent_number"] != "CNa" {
+		t.Errorf("row not tagged with the new model: %v", row)
+	}
+	if len(reported) != 3 || reported[2] != 5 {
+		t.Errorf("expected progress after each batch, got %v", reported)
+	}
+}
+
+func TestReembeddingJob_ResumesFromLastID(t *testing.T) {
+	src := &sliceReembedSource{records: reembedRecords("a", "b", "c", "d"), failAt: 2}
+	store := newMemoryVectorCollections()
+	embedder := &scriptedEmbedder{id: "m", ver: "2"}
+	cfg := ReembeddingConfig{TargetCollection: "patents_v2", BatchSize: 2}
+
+	job, _ := NewReembeddingJob(src, store, embedder, cfg)
+	p, err := job.Run(context.Background())
+	if err == nil || p.LastID != 2 || p.Done {
+		t.Fatalf("expected failure after the first batch, got %+v %v", p, err)
+	}
+
+	cfg.StartAfterID = p.LastID
+	job, _ = NewReembeddingJob(src, store, embedder, cfg)
+	if p, err = job.Run(context.Background()); err != nil || !p.Done {
+		t.Fatalf("resume: %+v %v", p, err)
+	}
+	if embedder.callCount() != 4 {
+		t.Errorf("resume should not re-embed finished records, %d calls", embedder.callCount())
+	}
+}
+
+func TestDualReadSearcher_Phases(t *testing.T) {
+	store := newMemoryVectorCollections()
+	store.hits["patents_v1"] = []commontypes.VectorHit{{ID: 1, Score: 0.9}, {ID: 8, Score: 0.8}, {ID: 9, Score: 0.7}}
+	store.hits["patents_v2"] = []commontypes.VectorHit{{ID: 2, Score: 0.95}, {ID: 1, Score: 0.6}}
+
+	oldModel := &scriptedEmbedder{id: "m", ver: "1"}
+	newModel := &scriptedEmbedder{id: "m", ver: "2"}
+	d, err := NewDualReadSearcher(store,
+		EmbeddedCollection{Name: "patents_v1", Embedder: oldModel},
+		EmbeddedCollection{Name: "patents_v2", Embedder: newModel}, "", nil)
+	if err != nil {
+		t.Fatalf("NewDualReadSearcher: %v", err)
+	}
+
+	hits, _ := d.SearchText(context.Background(), "oled", 10, "", nil)
+	if len(hits) != 3 || newModel.callCount() != 0 {
+		t.Errorf("source phase should read only the old collection, got %v", hits)
+	}
+
+	d.Advance(ReembeddingProgress{LastID: 5})
+	if d.Phase() != CutoverDualRead {
+		t.Fatalf("progress should enter dual read, phase %s", d.Phase())
+	}
+	hits, err = d.SearchText(context.Background(), "oled", 3, `jurisdiction == "CN"`, nil)
+	if err != nil {
+		t.Fatalf("SearchText: %v", err)
+	}
+	var ids []int64
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	// ID 1 is already migrated, so only the target's copy counts; 8 and 9
+	// are not yet in the target and come from the source.
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 8 || ids[2] != 1 {
+		t.Errorf("unexpected merged ids %v", ids)
+	}
+	targetReq := store.requests[1]
+	if targetReq.CollectionName != "patents_v2" ||
+		!strings.Contains(targetReq.Filters, `embedding_model == "m@2"`) ||
+		!strings.Contains(targetReq.Filters, `jurisdiction == "CN"`) {
+		t.Errorf("target search must be restricted to the new model, got %+v", targetReq)
+	}
+
+	d.SetPhase(CutoverTarget)
+	before := oldModel.callCount()
+	hits, _ = d.SearchText(context.Background(), "oled", 10, "", nil)
+	if len(hits) != 2 || oldModel.callCount() != before {
+		t.Errorf("target phase should read only the new collection, got %v", hits)
+	}
+}
+
+//Personal.AI order the ending
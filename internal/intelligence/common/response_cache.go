@@ -0,0 +1,703 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	commontypes "github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// ---------------------------------------------------------------------------
+// Request metadata understood by the response cache
+// ---------------------------------------------------------------------------
+
+const (
+	// MetaTenantID scopes a request to a tenant when the context carries none.
+	MetaTenantID = "tenant_id"
+	// MetaCacheQuery holds the free-text user query embedded in the prompt.
+	// Requests that share everything but this query are candidates for
+	// semantic (near-duplicate) hits.
+	MetaCacheQuery = "cache_query"
+	// MetaRAGDocumentIDs lists the comma-separated source document IDs that
+	// were retrieved into the prompt. Re-indexing any of them invalidates
+	// the cached response.
+	MetaRAGDocumentIDs = "rag_document_ids"
+	// MetaCacheControl accepts "no-cache" (skip lookup, still store) and
+	// "no-store" (bypass the cache entirely).
+	MetaCacheControl = "cache_control"
+	// MetaCache is set on responses: "hit", "semantic_hit" or "miss".
+	MetaCache = "cache"
+	// MetaCacheSimilarity carries the cosine similarity of a semantic hit.
+	MetaCacheSimilarity = "cache_similarity"
+)
+
+const (
+	CacheControlNoCache = "no-cache"
+	CacheControlNoStore = "no-store"
+)
+
+// ---------------------------------------------------------------------------
+// Interfaces and configuration
+// ---------------------------------------------------------------------------
+
+// Embedder produces a vector for a piece of text. EmbeddingClient satisfies it.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// CacheInvalidator drops cached responses that were generated from the given
+// RAG source documents.
+type CacheInvalidator interface {
+	InvalidateDocuments(ctx context.Context, documentIDs ...string) error
+}
+
+// ResponseCacheConfig configures a CachedBackend.
+type ResponseCacheConfig struct {
+	// TTL of cached responses; 0 uses the cache's default.
+	TTL time.Duration
+	// ModelVersion is folded into every key, so switching the configured
+	// model naturally starts a fresh cache.
+	ModelVersion string
+	// SimilarityThreshold enables semantic hits when > 0 and an Embedder is
+	// configured. Cosine similarity must be at least this value.
+	SimilarityThreshold float64
+	// MaxSemanticEntries bounds the vectors kept per tenant and template.
+	MaxSemanticEntries int
+	// InputCostPer1K / OutputCostPer1K price tokens in USD for the
+	// cost-saved metric.
+	InputCostPer1K  float64
+	OutputCostPer1K float64
+	// KeyPrefix namespaces all keys; defaults to "llm:".
+	KeyPrefix string
+}
+
+// ResponseCacheStats summarises cache effectiveness.
+type ResponseCacheStats struct {
+	Hits          int64   `json:"hits"`
+	SemanticHits  int64   `json:"semantic_hits"`
+	Misses        int64   `json:"misses"`
+	Stores        int64   `json:"stores"`
+	Invalidations int64   `json:"invalidations"`
+	TokensSaved   int64   `json:"tokens_saved"`
+	CostSavedUSD  float64 `json:"cost_saved_usd"`
+}
+
+// HitRate returns (hits + semantic hits) / lookups.
+func (s ResponseCacheStats) HitRate() float64 {
+	hits := s.Hits + s.SemanticHits
+	if total := hits + s.Misses; total > 0 {
+		return float64(hits) / float64(total)
+	}
+	return 0
+}
+
+// CachedBackendOption configures a CachedBackend.
+type CachedBackendOption func(*CachedBackend)
+
+// WithCacheEmbedder enables semantic lookups with the given embedder.
+func WithCacheEmbedder(e Embedder) CachedBackendOption {
+	return func(b *CachedBackend) { b.embedder = e }
+}
+
+// WithCacheMetrics injects a metrics collector.
+func WithCacheMetrics(m IntelligenceMetrics) CachedBackendOption {
+	return func(b *CachedBackend) { b.metrics = m }
+}
+
+// WithCacheLogger injects a logger.
+func WithCacheLogger(l Logger) CachedBackendOption {
+	return func(b *CachedBackend) { b.logger = l }
+}
+
+// WithCacheTenantResolver overrides how the tenant is read from the context,
+// e.g. to use the auth middleware's accessor.
+func WithCacheTenantResolver(fn func(ctx context.Context) (string, bool)) CachedBackendOption {
+	return func(b *CachedBackend) { b.tenantOf = fn }
+}
+
+// ---------------------------------------------------------------------------
+// CachedBackend
+// ---------------------------------------------------------------------------
+
+// CachedBackend decorates a ModelBackend with a tenant-scoped response cache.
+// Requests that resolve no tenant bypass the cache, since anonymous callers
+// would otherwise share one scope. Cache failures never fail a request; they
+// degrade to calling the backend.
+type CachedBackend struct {
+	next     ModelBackend
+	cache    redis.Cache
+	cfg      ResponseCacheConfig
+	embedder Embedder
+	metrics  IntelligenceMetrics
+	logger   Logger
+	tenantOf func(ctx context.Context) (string, bool)
+
+	// semMu serialises read-modify-write of semantic indexes in-process.
+	semMu sync.Mutex
+
+	hits, semanticHits, misses, stores, invalidations, tokensSaved atomic.Int64
+	costSavedMicros                                                atomic.Int64
+}
+
+var (
+	_ ModelBackend     = (*CachedBackend)(nil)
+	_ CacheInvalidator = (*CachedBackend)(nil)
+)
+
+// NewCachedBackend wraps next with a response cache stored in cache.
+func NewCachedBackend(next ModelBackend, cache redis.Cache, cfg ResponseCacheConfig, opts ...CachedBackendOption) (*CachedBackend, error) {
+	if next == nil {
+		return nil, errors.NewInvalidInputError("backend is required")
+	}
+	if cache == nil {
+		return nil, errors.NewInvalidInputError("cache is required")
+	}
+	if cfg.SimilarityThreshold < 0 || cfg.SimilarityThreshold > 1 {
+		return nil, errors.NewInvalidInputError("similarity threshold must be within [0, 1]")
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "llm:"
+	}
+	if cfg.MaxSemanticEntries <= 0 {
+		cfg.MaxSemanticEntries = 500
+	}
+	b := &CachedBackend{next: next, cache: cache, cfg: cfg}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.metrics == nil {
+		b.metrics = NewNoopIntelligenceMetrics()
+	}
+	if b.logger == nil {
+		b.logger = NewNoopLogger()
+	}
+	if b.tenantOf == nil {
+		b.tenantOf = contextTenantID
+	}
+	return b, nil
+}
+
+// cachedEntry is the stored form of a response.
+type cachedEntry struct {
+	Response     *PredictResponse `json:"response"`
+	DocVersions  map[string]int64 `json:"doc_versions,omitempty"`
+	InputTokens  int64            `json:"input_tokens"`
+	OutputTokens int64            `json:"output_tokens"`
+	StoredAt     time.Time        `json:"stored_at"`
+}
+
+// semanticEntry points a query vector at an exact-match key.
+type semanticEntry struct {
+	Key    string    `json:"key"`
+	Vector []float32 `json:"vector"`
+}
+
+// cacheScope is everything derived from one request.
+type cacheScope struct {
+	tenant   string
+	exactKey string
+	semKey   string // empty when semantic lookup does not apply
+	query    string
+	docIDs   []string
+}
+
+// Predict serves from cache when possible and otherwise calls the backend
+// and stores the response.
+func (b *CachedBackend) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	if req == nil || len(req.InputData) == 0 {
+		return b.next.Predict(ctx, req)
+	}
+	control := req.Metadata[MetaCacheControl]
+	tenant := requestTenant(ctx, req, b.tenantOf, "")
+	if control == CacheControlNoStore || tenant == "" {
+		return b.next.Predict(ctx, req)
+	}
+	scope := b.scope(tenant, req)
+
+	if control != CacheControlNoCache {
+		if resp, ok := b.lookup(ctx, req, scope); ok {
+			return resp, nil
+		}
+	}
+	b.misses.Add(1)
+	b.metrics.RecordCacheAccess(ctx, false, req.ModelName)
+
+	// Snapshot document versions before generating, so a re-index that races
+	// with generation leaves the new entry already stale.
+	versions, err := b.documentVersions(ctx, scope.docIDs)
+	if err != nil {
+		b.logger.Warn("response cache: reading document versions failed", "error", err)
+	}
+
+	resp, err := b.next.Predict(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if versions != nil || len(scope.docIDs) == 0 {
+		b.store(ctx, scope, resp, versions)
+	}
+	return withCacheMeta(resp, "miss"), nil
+}
+
+// PredictStream replays a cached response as a single chunk; misses stream
+// from the backend uncached.
+func (b *CachedBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	if req != nil && len(req.InputData) > 0 {
+		control := req.Metadata[MetaCacheControl]
+		tenant := requestTenant(ctx, req, b.tenantOf, "")
+		if control != CacheControlNoStore && control != CacheControlNoCache && tenant != "" {
+			if resp, ok := b.lookup(ctx, req, b.scope(tenant, req)); ok {
+				ch := make(chan *PredictResponse, 1)
+				ch <- resp
+				close(ch)
+				return ch, nil
+			}
+			b.misses.Add(1)
+			b.metrics.RecordCacheAccess(ctx, false, req.ModelName)
+		}
+	}
+	return b.next.PredictStream(ctx, req)
+}
+
+// Healthy delegates to the wrapped backend.
+func (b *CachedBackend) Healthy(ctx context.Context) error {
+	return b.next.Healthy(ctx)
+}
+
+// Close closes the wrapped backend.
+func (b *CachedBackend) Close() error {
+	return b.next.Close()
+}
+
+// InvalidateDocuments bumps the version of each document so every response
+// generated from an older version is treated as a miss.
+func (b *CachedBackend) InvalidateDocuments(ctx context.Context, documentIDs ...string) error {
+	for _, id := range documentIDs {
+		if id == "" {
+			continue
+		}
+		if _, err := b.cache.Incr(ctx, b.docKey(id)); err != nil {
+			return errors.Wrap(err, errors.ErrCodeCacheError, "invalidate document "+id)
+		}
+		b.invalidations.Add(1)
+	}
+	return nil
+}
+
+// InvalidateTenant drops every cached response for a tenant.
+func (b *CachedBackend) InvalidateTenant(ctx context.Context, tenant string) (int64, error) {
+	if tenant == "" {
+		return 0, errors.NewInvalidInputError("tenant is required")
+	}
+	n, err := b.cache.DeleteByPrefix(ctx, b.cfg.KeyPrefix+"resp:"+tenant+":")
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeCacheError, "invalidate tenant")
+	}
+	if _, err := b.cache.DeleteByPrefix(ctx, b.cfg.KeyPrefix+"sem:"+tenant+":"); err != nil {
+		return n, errors.Wrap(err, errors.ErrCodeCacheError, "invalidate tenant")
+	}
+	b.invalidations.Add(n)
+	return n, nil
+}
+
+// Stats returns counters accumulated by this process.
+func (b *CachedBackend) Stats() ResponseCacheStats {
+	return ResponseCacheStats{
+		Hits:          b.hits.Load(),
+		SemanticHits:  b.semanticHits.Load(),
+		Misses:        b.misses.Load(),
+		Stores:        b.stores.Load(),
+		Invalidations: b.invalidations.Load(),
+		TokensSaved:   b.tokensSaved.Load(),
+		CostSavedUSD:  float64(b.costSavedMicros.Load()) / 1e6,
+	}
+}
+
+// TenantStats returns the hit and savings counters persisted for a tenant,
+// aggregated across all processes sharing the cache.
+func (b *CachedBackend) TenantStats(ctx context.Context, tenant string) (ResponseCacheStats, error) {
+	fields := []string{"hits", "semantic_hits", "misses", "tokens_saved", "cost_saved_micros"}
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = b.statKey(tenant, f)
+	}
+	raw, err := b.cache.MGet(ctx, keys)
+	if err != nil {
+		return ResponseCacheStats{}, errors.Wrap(err, errors.ErrCodeCacheError, "read tenant cache stats")
+	}
+	v := func(i int) int64 {
+		n, _ := strconv.ParseInt(string(raw[keys[i]]), 10, 64)
+		return n
+	}
+	return ResponseCacheStats{
+		Hits:         v(0),
+		SemanticHits: v(1),
+		Misses:       v(2),
+		TokensSaved:  v(3),
+		CostSavedUSD: float64(v(4)) / 1e6,
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Lookup and store
+// ---------------------------------------------------------------------------
+
+func (b *CachedBackend) lookup(ctx context.Context, req *PredictRequest, scope *cacheScope) (*PredictResponse, bool) {
+	if entry := b.load(ctx, scope.exactKey); entry != nil {
+		b.hits.Add(1)
+		b.recordHit(ctx, req, scope, entry, "hits")
+		return withCacheMeta(entry.Response, "hit"), true
+	}
+	if scope.semKey == "" || b.embedder == nil || b.cfg.SimilarityThreshold <= 0 {
+		return nil, false
+	}
+	vec, err := b.embedder.Embed(ctx, scope.query)
+	if err != nil {
+		b.logger.Warn("response cache: embedding query failed", "error", err)
+		return nil, false
+	}
+	var index []semanticEntry
+	if err := b.cache.Get(ctx, scope.semKey, &index); err != nil {
+		if err != redis.ErrCacheMiss {
+			b.logger.Warn("response cache: reading semantic index failed", "error", err)
+		}
+		return nil, false
+	}
+	best, bestSim := "", b.cfg.SimilarityThreshold
+	for _, e := range index {
+		if sim := cosineSimilarity(vec, e.Vector); sim >= bestSim {
+			best, bestSim = e.Key, sim
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	entry := b.load(ctx, best)
+	if entry == nil {
+		return nil, false
+	}
+	b.semanticHits.Add(1)
+	b.recordHit(ctx, req, scope, entry, "semantic_hits")
+	resp := withCacheMeta(entry.Response, "semantic_hit")
+	resp.Metadata[MetaCacheSimilarity] = strconv.FormatFloat(bestSim, 'f', 4, 64)
+	return resp, true
+}
+
+// load returns a fresh entry, or nil on miss, error or staleness.
+func (b *CachedBackend) load(ctx context.Context, key string) *cachedEntry {
+	var entry cachedEntry
+	if err := b.cache.Get(ctx, key, &entry); err != nil {
+		if err != redis.ErrCacheMiss {
+			b.logger.Warn("response cache: read failed", "key", key, "error", err)
+		}
+		return nil
+	}
+	if entry.Response == nil {
+		return nil
+	}
+	if len(entry.DocVersions) > 0 {
+		ids := make([]string, 0, len(entry.DocVersions))
+		for id := range entry.DocVersions {
+			ids = append(ids, id)
+		}
+		current, err := b.documentVersions(ctx, ids)
+		if err != nil {
+			b.logger.Warn("response cache: reading document versions failed", "error", err)
+			return nil
+		}
+		for id, v := range entry.DocVersions {
+			if current[id] != v {
+				return nil
+			}
+		}
+	}
+	return &entry
+}
+
+func (b *CachedBackend) store(ctx context.Context, scope *cacheScope, resp *PredictResponse, versions map[string]int64) {
+	if resp == nil || len(resp.Outputs) == 0 {
+		return
+	}
+	in, out := responseTokens(resp)
+	entry := &cachedEntry{
+		Response:     resp,
+		DocVersions:  versions,
+		InputTokens:  in,
+		OutputTokens: out,
+		StoredAt:     time.Now().UTC(),
+	}
+	if err := b.cache.Set(ctx, scope.exactKey, entry, b.cfg.TTL); err != nil {
+		b.logger.Warn("response cache: write failed", "error", err)
+		return
+	}
+	b.stores.Add(1)
+
+	if scope.semKey == "" || b.embedder == nil || b.cfg.SimilarityThreshold <= 0 {
+		return
+	}
+	vec, err := b.embedder.Embed(ctx, scope.query)
+	if err != nil {
+		b.logger.Warn("response cache: embedding query failed", "error", err)
+		return
+	}
+	b.semMu.Lock()
+	defer b.semMu.Unlock()
+	var index []semanticEntry
+	if err := b.cache.Get(ctx, scope.semKey, &index); err != nil && err != redis.ErrCacheMiss {
+		b.logger.Warn("response cache: reading semantic index failed", "error", err)
+		return
+	}
+	kept := index[:0]
+	for _, e := range index {
+		if e.Key != scope.exactKey {
+			kept = append(kept, e)
+		}
+	}
+	kept = append(kept, semanticEntry{Key: scope.exactKey, Vector: vec})
+	if over := len(kept) - b.cfg.MaxSemanticEntries; over > 0 {
+		kept = kept[over:]
+	}
+	if err := b.cache.Set(ctx, scope.semKey, kept, b.cfg.TTL); err != nil {
+		b.logger.Warn("response cache: writing semantic index failed", "error", err)
+	}
+}
+
+func (b *CachedBackend) recordHit(ctx context.Context, req *PredictRequest, scope *cacheScope, entry *cachedEntry, counter string) {
+	b.metrics.RecordCacheAccess(ctx, true, req.ModelName)
+	cost := float64(entry.InputTokens)/1000*b.cfg.InputCostPer1K + float64(entry.OutputTokens)/1000*b.cfg.OutputCostPer1K
+	micros := int64(math.Round(cost * 1e6))
+	tokens := entry.InputTokens + entry.OutputTokens
+	b.tokensSaved.Add(tokens)
+	b.costSavedMicros.Add(micros)
+
+	for field, delta := range map[string]int64{counter: 1, "tokens_saved": tokens, "cost_saved_micros": micros} {
+		if delta == 0 {
+			continue
+		}
+		if _, err := b.cache.IncrBy(ctx, b.statKey(scope.tenant, field), delta); err != nil {
+			b.logger.Debug("response cache: stats update failed", "error", err)
+		}
+	}
+}
+
+// documentVersions returns the current version of each document; documents
+// never invalidated are at version 0.
+func (b *CachedBackend) documentVersions(ctx context.Context, ids []string) (map[string]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = b.docKey(id)
+	}
+	raw, err := b.cache.MGet(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]int64, len(ids))
+	for i, id := range ids {
+		if data, ok := raw[keys[i]]; ok {
+			versions[id], _ = strconv.ParseInt(string(data), 10, 64)
+		} else {
+			versions[id] = 0
+		}
+	}
+	return versions, nil
+}
+
+// ---------------------------------------------------------------------------
+// Keys and normalisation
+// ---------------------------------------------------------------------------
+
+// contextTenantID reads the tenant set by the API layer.
+func contextTenantID(ctx context.Context) (string, bool) {
+	tid, ok := ctx.Value(commontypes.ContextKeyTenantID).(string)
+	return tid, ok
+}
+
+// requestTenant resolves a request's tenant from the context, then the
+// request metadata, then fallback.
+func requestTenant(ctx context.Context, req *PredictRequest, tenantOf func(context.Context) (string, bool), fallback string) string {
+	if tenant, _ := tenantOf(ctx); tenant != "" {
+		return tenant
+	}
+	if req != nil && req.Metadata[MetaTenantID] != "" {
+		return req.Metadata[MetaTenantID]
+	}
+	return fallback
+}
+
+func (b *CachedBackend) scope(tenant string, req *PredictRequest) *cacheScope {
+	norm := normalizePromptInput(req.InputData, req.InputFormat)
+	s := &cacheScope{
+		tenant:   tenant,
+		exactKey: b.cfg.KeyPrefix + "resp:" + tenant + ":" + b.digest(req, norm),
+		docIDs:   splitDocumentIDs(req.Metadata[MetaRAGDocumentIDs]),
+	}
+	if template, query, ok := promptTemplate(req, norm); ok {
+		s.query = query
+		s.semKey = b.cfg.KeyPrefix + "sem:" + tenant + ":" + b.digest(req, template)
+	}
+	return s
+}
+
+func (b *CachedBackend) digest(req *PredictRequest, body string) string {
+	h := sha256.New()
+	for _, part := range []string{req.ModelName, req.ModelVersion, b.cfg.ModelVersion, req.InputFormat.String(), body} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (b *CachedBackend) docKey(id string) string {
+	return b.cfg.KeyPrefix + "doc:" + id
+}
+
+func (b *CachedBackend) statKey(tenant, field string) string {
+	return b.cfg.KeyPrefix + "stats:" + tenant + ":" + field
+}
+
+// normalizePromptInput makes cosmetically different prompts hash alike:
+// whitespace runs collapse to one space, and JSON input is re-encoded with
+// sorted keys.
+func normalizePromptInput(data []byte, format InputFormat) string {
+	if format == FormatJSON {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			if canon, err := json.Marshal(collapseJSONStrings(v)); err == nil {
+				return string(canon)
+			}
+		}
+	}
+	return collapseSpace(string(data))
+}
+
+func collapseJSONStrings(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return collapseSpace(t)
+	case []interface{}:
+		for i := range t {
+			t[i] = collapseJSONStrings(t[i])
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = collapseJSONStrings(t[k])
+		}
+	}
+	return v
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// promptTemplate cuts the user query out of the normalised prompt. Requests
+// sharing a template differ only in their query, so their responses are
+// interchangeable when the queries are semantically equivalent.
+func promptTemplate(req *PredictRequest, norm string) (template, query string, ok bool) {
+	query = req.Metadata[MetaCacheQuery]
+	if query == "" && req.InputFormat == FormatJSON {
+		query = lastUserMessage(req.InputData)
+	}
+	query = collapseSpace(query)
+	if query == "" {
+		return "", "", false
+	}
+	needle := query
+	if req.InputFormat == FormatJSON {
+		encoded, err := json.Marshal(query)
+		if err != nil {
+			return "", "", false
+		}
+		needle = string(encoded[1 : len(encoded)-1])
+	}
+	idx := strings.LastIndex(norm, needle)
+	if idx < 0 {
+		return "", "", false
+	}
+	return norm[:idx] + "\x00" + norm[idx+len(needle):], query, true
+}
+
+func lastUserMessage(data []byte) string {
+	var chat struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"messages"`
+	}
+	if json.Unmarshal(data, &chat) != nil {
+		return ""
+	}
+	for i := len(chat.Messages) - 1; i >= 0; i-- {
+		if chat.Messages[i].Role == "user" {
+			return chat.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+func splitDocumentIDs(s string) []string {
+	if s == "" {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// responseTokens reads token usage reported by the backend, estimating the
+// output with EstimateTokenCount when the backend reports none.
+func responseTokens(resp *PredictResponse) (in, out int64) {
+	in, out = reportedTokens(resp)
+	if out == 0 {
+		out = int64(EstimateTokenCount(outputText(resp)))
+	}
+	return in, out
+}
+
+// withCacheMeta returns a copy of resp tagged with its cache status.
+func withCacheMeta(resp *PredictResponse, status string) *PredictResponse {
+	cp := *resp
+	cp.Metadata = make(map[string]string, len(resp.Metadata)+1)
+	for k, v := range resp.Metadata {
+		cp.Metadata[k] = v
+	}
+	cp.Metadata[MetaCache] = status
+	return &cp
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+//Personal.AI order the ending
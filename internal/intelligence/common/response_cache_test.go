@@ -0,0 +1,293 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/redis"
+)
+
+// ---------------------------------------------------------------------------
+// Test doubles
+// ---------------------------------------------------------------------------
+
+type countingBackend struct {
+	calls atomic.Int64
+}
+
+func (c *countingBackend) Predict(_ context.Context, req *PredictRequest) (*PredictResponse, error) {
+	n := c.calls.Add(1)
+	return &PredictResponse{
+		ModelName: req.ModelName,
+		Outputs:   map[string][]byte{"content": []byte(fmt.Sprintf("answer %d", n))},
+		Metadata:  map[string]string{"input_tokens": "1000", "output_tokens": "500"},
+	}, nil
+}
+
+func (c *countingBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	resp, _ := c.Predict(ctx, req)
+	ch := make(chan *PredictResponse, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func (c *countingBackend) Healthy(context.Context) error { return nil }
+func (c *countingBackend) Close() error                  { return nil }
+
+// tableEmbedder maps known queries to fixed vectors.
+type tableEmbedder map[string][]float32
+
+func (t tableEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	if v, ok := t[text]; ok {
+		return v, nil
+	}
+	return []float32{0, 0, 1}, nil
+}
+
+func newTestCachedBackend(t *testing.T, cfg ResponseCacheConfig, opts ...CachedBackendOption) (*CachedBackend, *countingBackend) {
+	t.Helper()
+	next := &countingBackend{}
+	b, err := NewCachedBackend(next, redis.NewMemoryCache(), cfg, opts...)
+	if err != nil {
+		t.Fatalf("NewCachedBackend: %v", err)
+	}
+	return b, next
+}
+
+func textRequest(prompt string, meta map[string]string) *PredictRequest {
+	return &PredictRequest{ModelName: "llm", InputFormat: FormatText, InputData: []byte(prompt), Metadata: meta}
+}
+
+func content(resp *PredictResponse) string { return string(resp.Outputs["content"]) }
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestCachedBackend_ExactHitNormalisesPrompt(t *testing.T) {
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{InputCostPer1K: 0.003, OutputCostPer1K: 0.015})
+	ctx := tenantContext("acme")
+
+	first, err := b.Predict(ctx, textRequest("Assess   FTO\nfor compound X", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := b.Predict(ctx, textRequest("  Assess FTO for compound X ", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.calls.Load() != 1 || content(first) != content(second) {
+		t.Fatalf("expected one backend call, got %d (%q vs %q)", next.calls.Load(), content(first), content(second))
+	}
+	if first.Metadata[MetaCache] != "miss" || second.Metadata[MetaCache] != "hit" {
+		t.Errorf("cache status: %q, %q", first.Metadata[MetaCache], second.Metadata[MetaCache])
+	}
+
+	// JSON chat input is compared on its canonical form.
+	chatA := `{"messages":[{"role":"user","content":"hello  world"}],"max_tokens":10}`
+	chatB := `{"max_tokens": 10, "messages": [{"content": "hello world", "role": "user"}]}`
+	for _, in := range []string{chatA, chatB} {
+		if _, err := b.Predict(ctx, &PredictRequest{ModelName: "llm", InputFormat: FormatJSON, InputData: []byte(in)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if next.calls.Load() != 2 {
+		t.Errorf("equivalent JSON prompts should share an entry, calls = %d", next.calls.Load())
+	}
+
+	// A different model version never shares entries.
+	req := textRequest("Assess FTO for compound X", nil)
+	req.ModelVersion = "v2"
+	if resp, _ := b.Predict(ctx, req); resp.Metadata[MetaCache] != "miss" {
+		t.Error("model version must be part of the key")
+	}
+
+	stats := b.Stats()
+	if stats.Hits != 2 || stats.Misses != 3 || stats.Stores != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.TokensSaved != 3000 || stats.CostSavedUSD < 0.0209 || stats.CostSavedUSD > 0.0211 {
+		t.Errorf("savings: %+v", stats)
+	}
+	if rate := stats.HitRate(); rate != 0.4 {
+		t.Errorf("hit rate = %v", rate)
+	}
+}
+
+func TestCachedBackend_TenantIsolation(t *testing.T) {
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{})
+	acme := tenantContext("acme")
+	globex := tenantContext("globex")
+
+	a, _ := b.Predict(acme, textRequest("same prompt", nil))
+	g, _ := b.Predict(globex, textRequest("same prompt", nil))
+	if next.calls.Load() != 2 || content(a) == content(g) {
+		t.Fatal("tenants must not share cached responses")
+	}
+	if resp, _ := b.Predict(context.Background(), textRequest("same prompt", map[string]string{MetaTenantID: "acme"})); resp.Metadata[MetaCache] != "hit" {
+		t.Error("tenant from request metadata should resolve to the same scope")
+	}
+
+	acmeStats, err := b.TenantStats(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acmeStats.Hits != 1 || acmeStats.TokensSaved != 1500 {
+		t.Errorf("acme stats: %+v", acmeStats)
+	}
+	if globexStats, _ := b.TenantStats(context.Background(), "globex"); globexStats.Hits != 0 {
+		t.Errorf("globex stats: %+v", globexStats)
+	}
+
+	n, err := b.InvalidateTenant(context.Background(), "acme")
+	if err != nil || n != 1 {
+		t.Fatalf("InvalidateTenant = %d, %v", n, err)
+	}
+	if resp, _ := b.Predict(acme, textRequest("same prompt", nil)); resp.Metadata[MetaCache] != "miss" {
+		t.Error("tenant invalidation did not drop the entry")
+	}
+	if resp, _ := b.Predict(globex, textRequest("same prompt", nil)); resp.Metadata[MetaCache] != "hit" {
+		t.Error("invalidating one tenant affected another")
+	}
+}
+
+func TestCachedBackend_NoTenantBypassesCache(t *testing.T) {
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		resp, err := b.Predict(ctx, textRequest("same prompt", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Metadata[MetaCache] != "" {
+			t.Errorf("request without a tenant was served by the cache: %v", resp.Metadata)
+		}
+	}
+	ch, err := b.PredictStream(ctx, textRequest("same prompt", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range ch {
+	}
+	if next.calls.Load() != 3 {
+		t.Errorf("expected every request to reach the backend, calls = %d", next.calls.Load())
+	}
+	if stats := b.Stats(); stats.Hits != 0 || stats.Misses != 0 || stats.Stores != 0 {
+		t.Errorf("bypassed requests must not touch the cache: %+v", stats)
+	}
+}
+
+func TestCachedBackend_DocumentInvalidation(t *testing.T) {
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{})
+	ctx := tenantContext("acme")
+	meta := map[string]string{MetaRAGDocumentIDs: "US1, US2"}
+
+	b.Predict(ctx, textRequest("summarise prior art", meta))
+	b.Predict(ctx, textRequest("unrelated prompt", nil))
+	if resp, _ := b.Predict(ctx, textRequest("summarise prior art", meta)); resp.Metadata[MetaCache] != "hit" {
+		t.Fatal("expected hit before invalidation")
+	}
+
+	if err := b.InvalidateDocuments(ctx, "US2"); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ := b.Predict(ctx, textRequest("summarise prior art", meta))
+	if resp.Metadata[MetaCache] != "miss" || next.calls.Load() != 3 {
+		t.Errorf("re-indexed source should invalidate, status %q calls %d", resp.Metadata[MetaCache], next.calls.Load())
+	}
+	if resp, _ := b.Predict(ctx, textRequest("summarise prior art", meta)); resp.Metadata[MetaCache] != "hit" {
+		t.Error("regenerated response should be cached against the new version")
+	}
+	if resp, _ := b.Predict(ctx, textRequest("unrelated prompt", nil)); resp.Metadata[MetaCache] != "hit" {
+		t.Error("responses without the document must survive")
+	}
+	if b.Stats().Invalidations != 1 {
+		t.Errorf("invalidations = %d", b.Stats().Invalidations)
+	}
+}
+
+func TestCachedBackend_SemanticHits(t *testing.T) {
+	embedder := tableEmbedder{
+		"Is compound X infringing?":        {1, 0, 0},
+		"Does compound X infringe?":        {0.99, 0.1, 0},
+		"What is the value of compound X?": {0, 1, 0},
+	}
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{SimilarityThreshold: 0.95}, WithCacheEmbedder(embedder))
+	ctx := tenantContext("acme")
+	prompt := func(q string) *PredictRequest {
+		return textRequest("System: FTO analyst.\nContext: US1 claim 1.\nQuestion: "+q, map[string]string{MetaCacheQuery: q})
+	}
+
+	b.Predict(ctx, prompt("Is compound X infringing?"))
+	resp, err := b.Predict(ctx, prompt("Does compound X infringe?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Metadata[MetaCache] != "semantic_hit" || resp.Metadata[MetaCacheSimilarity] == "" || next.calls.Load() != 1 {
+		t.Fatalf("expected semantic hit, got %v (calls %d)", resp.Metadata, next.calls.Load())
+	}
+
+	if resp, _ := b.Predict(ctx, prompt("What is the value of compound X?")); resp.Metadata[MetaCache] != "miss" {
+		t.Error("dissimilar query must miss")
+	}
+
+	// Same query against different retrieved context is a different template.
+	other := textRequest("System: FTO analyst.\nContext: US9 claim 4.\nQuestion: Does compound X infringe?",
+		map[string]string{MetaCacheQuery: "Does compound X infringe?"})
+	if resp, _ := b.Predict(ctx, other); resp.Metadata[MetaCache] != "miss" {
+		t.Error("semantic hits must not cross prompt templates")
+	}
+
+	// JSON chat takes the last user message as the query.
+	chat := func(q string) *PredictRequest {
+		return &PredictRequest{ModelName: "llm", InputFormat: FormatJSON,
+			InputData: []byte(`{"system":"FTO","messages":[{"role":"user","content":"` + q + `"}]}`)}
+	}
+	b.Predict(ctx, chat("Is compound X infringing?"))
+	if resp, _ := b.Predict(ctx, chat("Does compound X infringe?")); resp.Metadata[MetaCache] != "semantic_hit" {
+		t.Errorf("chat semantic lookup: %v", resp.Metadata)
+	}
+	if b.Stats().SemanticHits != 2 {
+		t.Errorf("stats: %+v", b.Stats())
+	}
+}
+
+func TestCachedBackend_CacheControlAndStream(t *testing.T) {
+	b, next := newTestCachedBackend(t, ResponseCacheConfig{})
+	ctx := tenantContext("acme")
+
+	b.Predict(ctx, textRequest("p", map[string]string{MetaCacheControl: CacheControlNoStore}))
+	if resp, _ := b.Predict(ctx, textRequest("p", nil)); resp.Metadata[MetaCache] != "miss" {
+		t.Error("no-store must not write")
+	}
+	resp, _ := b.Predict(ctx, textRequest("p", map[string]string{MetaCacheControl: CacheControlNoCache}))
+	if resp.Metadata[MetaCache] != "miss" || content(resp) != "answer 3" {
+		t.Errorf("no-cache must bypass lookup, got %q", content(resp))
+	}
+
+	ch, err := b.PredictStream(ctx, textRequest("p", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []string
+	for r := range ch {
+		chunks = append(chunks, content(r)+"/"+r.Metadata[MetaCache])
+	}
+	if strings.Join(chunks, ",") != "answer 3/hit" || next.calls.Load() != 3 {
+		t.Errorf("stream replay: %v (calls %d)", chunks, next.calls.Load())
+	}
+
+	if _, err := NewCachedBackend(nil, redis.NewMemoryCache(), ResponseCacheConfig{}); err == nil {
+		t.Error("expected error for nil backend")
+	}
+	if _, err := NewCachedBackend(next, redis.NewMemoryCache(), ResponseCacheConfig{SimilarityThreshold: 1.5}); err == nil {
+		t.Error("expected error for invalid threshold")
+	}
+}
+
+//Personal.AI order the ending
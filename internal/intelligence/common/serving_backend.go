@@ -0,0 +1,134 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// ServingBackend
+// ---------------------------------------------------------------------------
+
+// ServingBackend runs ModelBackend requests on a ServingClient. With a
+// registry, each request is resolved through ResolveModel first, so A/B
+// tests, canaries and shadow experiments apply to it, and its outcome is
+// reported back to the registry.
+type ServingBackend struct {
+	client   ServingClient
+	registry ModelRegistry
+}
+
+// NewServingBackend wraps client. registry may be nil, in which case
+// requests go to the version the caller names.
+func NewServingBackend(client ServingClient, registry ModelRegistry) (*ServingBackend, error) {
+	if client == nil {
+		return nil, fmt.Errorf("%w: serving client is required", ErrInvalidInput)
+	}
+	return &ServingBackend{client: client, registry: registry}, nil
+}
+
+func (b *ServingBackend) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if b.registry == nil {
+		return b.client.Predict(ctx, req)
+	}
+
+	// The request ID keys A/B routing and shadow pairing.
+	requestID := req.Metadata["request_id"]
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	// ResolveModel mirrors the payload tagged with ContextWithShadowInput
+	// to a running shadow candidate.
+	model, err := b.registry.ResolveModel(ctx, req.ModelName, requestID)
+	if err != nil {
+		return nil, err
+	}
+	routed := *req
+	if model.Metadata != nil {
+		routed.ModelVersion = model.Metadata.Version
+	}
+
+	start := time.Now()
+	resp, err := b.client.Predict(ctx, &routed)
+	if rollouts, ok := b.registry.(RolloutManager); ok {
+		outcome := &InferenceOutcome{
+			ModelID:   req.ModelName,
+			Version:   routed.ModelVersion,
+			RequestID: requestID,
+			Latency:   time.Since(start),
+			Err:       err,
+		}
+		if err == nil {
+			outcome.Output = encodeModelOutputs(resp)
+		}
+		rollouts.RecordOutcome(ctx, outcome)
+	}
+	return resp, err
+}
+
+// PredictStream is not routed; it runs the version the caller names.
+func (b *ServingBackend) PredictStream(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	return b.client.StreamPredict(ctx, req)
+}
+
+func (b *ServingBackend) Healthy(ctx context.Context) error {
+	return b.client.Healthy(ctx)
+}
+
+// Close closes the serving client. The registry is owned by the caller.
+func (b *ServingBackend) Close() error {
+	return b.client.Close()
+}
+
+var _ ModelBackend = (*ServingBackend)(nil)
+
+// ---------------------------------------------------------------------------
+// Serving model invoker
+// ---------------------------------------------------------------------------
+
+type servingModelInvoker struct {
+	client ServingClient
+}
+
+// NewServingModelInvoker returns a ModelInvoker that mirrors shadow traffic
+// to the candidate version on client. Shadow inputs are sent as JSON, the
+// format every tagged call site uses. No caller waits on a mirror, so a
+// scheduling client queues it as background work.
+func NewServingModelInvoker(client ServingClient) ModelInvoker {
+	return &servingModelInvoker{client: client}
+}
+
+func (i *servingModelInvoker) Invoke(ctx context.Context, model *RegisteredModel, input []byte) ([]byte, error) {
+	req := &PredictRequest{
+		ModelName:   model.ModelID,
+		InputData:   input,
+		InputFormat: FormatJSON,
+	}
+	if model.Metadata != nil {
+		req.ModelVersion = model.Metadata.Version
+	}
+	resp, err := i.client.Predict(ContextWithPriority(ctx, PriorityBackground), req)
+	if err != nil {
+		return nil, err
+	}
+	return encodeModelOutputs(resp), nil
+}
+
+// encodeModelOutputs gives the primary and shadow outputs of a request the
+// same byte form, so the comparator sees equal outputs as equal.
+func encodeModelOutputs(resp *PredictResponse) []byte {
+	if resp == nil {
+		return nil
+	}
+	data, _ := json.Marshal(resp.Outputs) // map keys are sorted
+	return data
+}
+
+//Personal.AI order the ending
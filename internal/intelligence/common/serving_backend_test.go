@@ -0,0 +1,88 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func servedVersions(client *mockServingClient) []string {
+	var versions []string
+	for _, call := range client.CallHistory() {
+		if call.Method == "Predict" {
+			versions = append(versions, call.Args[0].(*PredictRequest).ModelVersion)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+func TestServingBackend_MirrorsShadowTraffic(t *testing.T) {
+	client := NewMockServingClient()
+	reg, _ := newRolloutTestRegistry(t, WithModelInvoker(NewServingModelInvoker(client)))
+	ctx := context.Background()
+
+	if _, err := reg.ConfigureShadow(ctx, &ShadowConfig{ModelID: "infringe-net", CandidateVersion: "1.1.0"}); err != nil {
+		t.Fatalf("ConfigureShadow: %v", err)
+	}
+	backend, err := NewServingBackend(client, reg)
+	if err != nil {
+		t.Fatalf("NewServingBackend: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		payload := []byte(fmt.Sprintf(`{"smiles":"C%d"}`, i))
+		resp, err := backend.Predict(ContextWithShadowInput(ctx, payload), &PredictRequest{
+			ModelName:   "infringe-net",
+			InputData:   payload,
+			InputFormat: FormatJSON,
+			Metadata:    map[string]string{"request_id": fmt.Sprintf("req-%d", i)},
+		})
+		if err != nil {
+			t.Fatalf("Predict: %v", err)
+		}
+		if resp.ModelVersion != "1.0.0" {
+			t.Errorf("expected the active version to serve, got %q", resp.ModelVersion)
+		}
+	}
+	reg.rollouts.shadowWG.Wait()
+
+	want := []string{"1.0.0", "1.0.0", "1.0.0", "1.1.0", "1.1.0", "1.1.0"}
+	if got := servedVersions(client); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected primary and shadow calls %v, got %v", want, got)
+	}
+	final, err := reg.StopShadow(ctx, "infringe-net")
+	if err != nil {
+		t.Fatalf("StopShadow: %v", err)
+	}
+	if final.Shadow.Compared != 3 || final.Shadow.Mismatches != 0 {
+		t.Errorf("expected 3 matching comparisons, got %+v", final.Shadow)
+	}
+	if base := final.Arm("1.0.0"); base.Requests != 3 {
+		t.Errorf("expected the primary outcomes to be recorded, got %+v", base)
+	}
+}
+
+func TestServingBackend_WithoutRegistry(t *testing.T) {
+	client := NewMockServingClient()
+	backend, err := NewServingBackend(client, nil)
+	if err != nil {
+		t.Fatalf("NewServingBackend: %v", err)
+	}
+	resp, err := backend.Predict(context.Background(), &PredictRequest{
+		ModelName: "chem-ner", ModelVersion: "2", InputData: []byte(`["benzene"]`),
+	})
+	if err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if resp.ModelVersion != "2" {
+		t.Errorf("expected the requested version, got %q", resp.ModelVersion)
+	}
+
+	if _, err := NewServingBackend(nil, nil); err == nil {
+		t.Error("expected an error for a nil client")
+	}
+}
+
+//Personal.AI order the ending
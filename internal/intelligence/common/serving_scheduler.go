@@ -0,0 +1,632 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// Priorities
+// ---------------------------------------------------------------------------
+
+// RequestPriority orders queued inference requests. Lower values are served
+// first.
+type RequestPriority int
+
+const (
+	// PriorityInteractive is for requests a user is waiting on.
+	PriorityInteractive RequestPriority = iota
+	// PriorityBatch is for bulk jobs such as portfolio valuation.
+	PriorityBatch
+	// PriorityBackground is for work nobody waits on, e.g. watchlist scans.
+	PriorityBackground
+
+	numRequestPriorities = 3
+)
+
+func (p RequestPriority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBatch:
+		return "batch"
+	case PriorityBackground:
+		return "background"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+type requestPriorityKey struct{}
+
+// ContextWithPriority sets the scheduling priority of requests made with ctx.
+func ContextWithPriority(ctx context.Context, p RequestPriority) context.Context {
+	return context.WithValue(ctx, requestPriorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority set by ContextWithPriority,
+// defaulting to PriorityInteractive.
+func PriorityFromContext(ctx context.Context) RequestPriority {
+	if p, ok := ctx.Value(requestPriorityKey{}).(RequestPriority); ok && p >= 0 && p < numRequestPriorities {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// ErrSchedulerQueueFull is returned when a model's queue is at its limit.
+var ErrSchedulerQueueFull = fmt.Errorf("serving: scheduler queue full")
+
+// ---------------------------------------------------------------------------
+// Metrics
+// ---------------------------------------------------------------------------
+
+// SchedulerMetrics receives queue and batching measurements.
+type SchedulerMetrics interface {
+	RecordQueueDepth(model string, priority RequestPriority, depth int)
+	RecordBatch(model string, size int, queueWaitMs float64)
+	RecordDrop(model string, priority RequestPriority, reason string)
+}
+
+type noopSchedulerMetrics struct{}
+
+func (noopSchedulerMetrics) RecordQueueDepth(string, RequestPriority, int) {}
+func (noopSchedulerMetrics) RecordBatch(string, int, float64)              {}
+func (noopSchedulerMetrics) RecordDrop(string, RequestPriority, string)    {}
+
+// Drop reasons reported to SchedulerMetrics.
+const (
+	DropReasonQueueFull = "queue_full"
+	DropReasonDeadline  = "deadline"
+	DropReasonCancelled = "cancelled"
+)
+
+// ModelQueueStats is a point-in-time view of one model's queue.
+type ModelQueueStats struct {
+	Model        string                  `json:"model"`
+	Depth        map[RequestPriority]int `json:"depth"`
+	InFlight     int                     `json:"in_flight"`
+	Dispatched   int64                   `json:"dispatched"`
+	Batches      int64                   `json:"batches"`
+	Dropped      int64                   `json:"dropped"`
+	EstLatencyMs float64                 `json:"est_latency_ms"`
+}
+
+// ---------------------------------------------------------------------------
+// Options
+// ---------------------------------------------------------------------------
+
+type schedulerOptions struct {
+	maxBatchSize     int
+	maxBatchWait     time.Duration
+	concurrency      int
+	modelConcurrency map[string]int
+	queueLimit       int
+	metrics          SchedulerMetrics
+	logger           Logger
+}
+
+func defaultSchedulerOptions() *schedulerOptions {
+	return &schedulerOptions{
+		maxBatchSize:     16,
+		maxBatchWait:     5 * time.Millisecond,
+		concurrency:      4,
+		modelConcurrency: make(map[string]int),
+		queueLimit:       1000,
+		metrics:          noopSchedulerMetrics{},
+		logger:           NewNoopLogger(),
+	}
+}
+
+// SchedulerOption configures a ServingScheduler.
+type SchedulerOption func(*schedulerOptions)
+
+// WithSchedulerMaxBatchSize caps how many requests are sent in one batch.
+func WithSchedulerMaxBatchSize(n int) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if n > 0 {
+			o.maxBatchSize = n
+		}
+	}
+}
+
+// WithSchedulerMaxBatchWait bounds how long the oldest queued request waits
+// for a batch to fill.
+func WithSchedulerMaxBatchWait(d time.Duration) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if d >= 0 {
+			o.maxBatchWait = d
+		}
+	}
+}
+
+// WithSchedulerConcurrency sets the default number of in-flight batches per
+// model.
+func WithSchedulerConcurrency(n int) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithSchedulerModelConcurrency overrides the in-flight batch limit of one
+// model.
+func WithSchedulerModelConcurrency(model string, n int) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if model != "" && n > 0 {
+			o.modelConcurrency[model] = n
+		}
+	}
+}
+
+// WithSchedulerQueueLimit caps the number of queued requests per model.
+func WithSchedulerQueueLimit(n int) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if n > 0 {
+			o.queueLimit = n
+		}
+	}
+}
+
+// WithSchedulerMetrics sets the queue metrics sink.
+func WithSchedulerMetrics(m SchedulerMetrics) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if m != nil {
+			o.metrics = m
+		}
+	}
+}
+
+// WithSchedulerLogger sets the scheduler logger.
+func WithSchedulerLogger(l Logger) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// ServingScheduler
+// ---------------------------------------------------------------------------
+
+// ServingScheduler wraps a ServingClient with per-model priority queues.
+// Queued Predict calls for the same model version are coalesced into
+// BatchPredict calls of up to the max batch size, waiting at most the max
+// batch wait for a batch to fill. Requests whose deadline cannot be met given
+// the model's recent latency are dropped before they reach the server.
+// StreamPredict and status calls pass through unscheduled.
+type ServingScheduler struct {
+	next ServingClient
+	opts *schedulerOptions
+
+	mu     sync.Mutex
+	queues map[string]*modelQueue
+	closed bool
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+type scheduledResult struct {
+	resp *PredictResponse
+	err  error
+}
+
+type scheduledRequest struct {
+	ctx        context.Context
+	req        *PredictRequest
+	priority   RequestPriority
+	enqueuedAt time.Time
+	done       chan scheduledResult
+}
+
+func (r *scheduledRequest) complete(resp *PredictResponse, err error) {
+	r.done <- scheduledResult{resp: resp, err: err}
+}
+
+type modelQueue struct {
+	name   string // model name, used for metrics and concurrency overrides
+	key    string
+	notify chan struct{}
+	slots  chan struct{}
+
+	mu         sync.Mutex
+	classes    [numRequestPriorities][]*scheduledRequest
+	depth      int
+	estimate   time.Duration // EWMA of dispatch latency
+	dispatched int64
+	batches    int64
+	dropped    int64
+}
+
+// NewServingScheduler wraps next with priority queueing and micro-batching.
+func NewServingScheduler(next ServingClient, opts ...SchedulerOption) (*ServingScheduler, error) {
+	if next == nil {
+		return nil, fmt.Errorf("%w: serving client is required", ErrInvalidInput)
+	}
+	o := defaultSchedulerOptions()
+	for _, fn := range opts {
+		fn(o)
+	}
+	return &ServingScheduler{
+		next:   next,
+		opts:   o,
+		queues: make(map[string]*modelQueue),
+		stopCh: make(chan struct{}),
+	}, nil
+}
+
+func (s *ServingScheduler) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	item := &scheduledRequest{
+		ctx:        ctx,
+		req:        req,
+		priority:   PriorityFromContext(ctx),
+		enqueuedAt: time.Now(),
+		done:       make(chan scheduledResult, 1),
+	}
+	if err := s.enqueue(item); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-item.done:
+		return res.resp, res.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %v", ErrInferenceTimeout, ctx.Err())
+	}
+}
+
+// BatchPredict schedules every request individually so that they share
+// batches with other callers of the same model.
+func (s *ServingScheduler) BatchPredict(ctx context.Context, reqs []*PredictRequest) ([]*PredictResponse, error) {
+	results := make([]*PredictResponse, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func(i int, req *PredictRequest) {
+			defer wg.Done()
+			results[i], errs[i] = s.Predict(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+func (s *ServingScheduler) StreamPredict(ctx context.Context, req *PredictRequest) (<-chan *PredictResponse, error) {
+	return s.next.StreamPredict(ctx, req)
+}
+
+func (s *ServingScheduler) GetModelStatus(ctx context.Context, modelName string) (*ServingModelStatus, error) {
+	return s.next.GetModelStatus(ctx, modelName)
+}
+
+func (s *ServingScheduler) ListServingModels(ctx context.Context) ([]*ServingModelStatus, error) {
+	return s.next.ListServingModels(ctx)
+}
+
+func (s *ServingScheduler) Healthy(ctx context.Context) error {
+	return s.next.Healthy(ctx)
+}
+
+// Close stops scheduling, fails requests still queued with ErrClientClosed
+// and closes the wrapped client.
+func (s *ServingScheduler) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.stopCh)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	s.mu.Lock()
+	for _, q := range s.queues {
+		q.mu.Lock()
+		for p := range q.classes {
+			for _, item := range q.classes[p] {
+				item.complete(nil, ErrClientClosed)
+			}
+			q.classes[p] = nil
+		}
+		q.depth = 0
+		q.mu.Unlock()
+	}
+	s.mu.Unlock()
+
+	return s.next.Close()
+}
+
+// Stats returns a snapshot of every model queue, sorted by model.
+func (s *ServingScheduler) Stats() []*ModelQueueStats {
+	s.mu.Lock()
+	queues := make([]*modelQueue, 0, len(s.queues))
+	for _, q := range s.queues {
+		queues = append(queues, q)
+	}
+	s.mu.Unlock()
+
+	out := make([]*ModelQueueStats, 0, len(queues))
+	for _, q := range queues {
+		q.mu.Lock()
+		st := &ModelQueueStats{
+			Model:        q.key,
+			Depth:        make(map[RequestPriority]int, numRequestPriorities),
+			InFlight:     len(q.slots),
+			Dispatched:   q.dispatched,
+			Batches:      q.batches,
+			Dropped:      q.dropped,
+			EstLatencyMs: float64(q.estimate) / float64(time.Millisecond),
+		}
+		for p := range q.classes {
+			st.Depth[RequestPriority(p)] = len(q.classes[p])
+		}
+		q.mu.Unlock()
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Queueing
+// ---------------------------------------------------------------------------
+
+func (s *ServingScheduler) enqueue(item *scheduledRequest) error {
+	key := item.req.ModelName
+	if item.req.ModelVersion != "" {
+		key += "@" + item.req.ModelVersion
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClientClosed
+	}
+	q, ok := s.queues[key]
+	if !ok {
+		concurrency := s.opts.concurrency
+		if n, ok := s.opts.modelConcurrency[item.req.ModelName]; ok {
+			concurrency = n
+		}
+		q = &modelQueue{
+			name:   item.req.ModelName,
+			key:    key,
+			notify: make(chan struct{}, 1),
+			slots:  make(chan struct{}, concurrency),
+		}
+		s.queues[key] = q
+		s.wg.Add(1)
+		go s.dispatchLoop(q)
+	}
+	s.mu.Unlock()
+
+	q.mu.Lock()
+	if q.depth >= s.opts.queueLimit {
+		q.dropped++
+		q.mu.Unlock()
+		s.opts.metrics.RecordDrop(q.name, item.priority, DropReasonQueueFull)
+		return fmt.Errorf("%w: %d requests queued for %s", ErrSchedulerQueueFull, s.opts.queueLimit, key)
+	}
+	q.classes[item.priority] = append(q.classes[item.priority], item)
+	q.depth++
+	depth := len(q.classes[item.priority])
+	q.mu.Unlock()
+
+	s.opts.metrics.RecordQueueDepth(q.name, item.priority, depth)
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// dispatchLoop forms batches for one model and hands them to dispatch while
+// respecting the model's concurrency limit.
+func (s *ServingScheduler) dispatchLoop(q *modelQueue) {
+	defer s.wg.Done()
+	for {
+		q.mu.Lock()
+		empty := q.depth == 0
+		q.mu.Unlock()
+		if empty {
+			select {
+			case <-q.notify:
+				continue
+			case <-s.stopCh:
+				return
+			}
+		}
+
+		select {
+		case q.slots <- struct{}{}:
+		case <-s.stopCh:
+			return
+		}
+		if !s.linger(q) {
+			<-q.slots
+			return
+		}
+
+		batch := s.take(q, time.Now())
+		if len(batch) == 0 {
+			<-q.slots
+			continue
+		}
+		s.wg.Add(1)
+		go s.dispatch(q, batch)
+	}
+}
+
+// linger waits until a full batch is queued or the oldest request has waited
+// the max batch wait. It returns false if the scheduler is stopping.
+func (s *ServingScheduler) linger(q *modelQueue) bool {
+	for {
+		q.mu.Lock()
+		depth := q.depth
+		var oldest time.Time
+		for p := range q.classes {
+			if len(q.classes[p]) > 0 && (oldest.IsZero() || q.classes[p][0].enqueuedAt.Before(oldest)) {
+				oldest = q.classes[p][0].enqueuedAt
+			}
+		}
+		q.mu.Unlock()
+
+		if depth >= s.opts.maxBatchSize || oldest.IsZero() {
+			return true
+		}
+		remaining := time.Until(oldest.Add(s.opts.maxBatchWait))
+		if remaining <= 0 {
+			return true
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-q.notify:
+			timer.Stop()
+		case <-timer.C:
+			return true
+		case <-s.stopCh:
+			timer.Stop()
+			return false
+		}
+	}
+}
+
+// take removes up to max batch size requests in priority order, dropping
+// those that were cancelled or cannot finish before their deadline.
+func (s *ServingScheduler) take(q *modelQueue, now time.Time) []*scheduledRequest {
+	type drop struct {
+		item   *scheduledRequest
+		reason string
+		err    error
+	}
+	var (
+		batch   []*scheduledRequest
+		dropped []drop
+	)
+
+	q.mu.Lock()
+	estimate := q.estimate
+	for p := range q.classes {
+		kept := q.classes[p][:0]
+		for _, item := range q.classes[p] {
+			switch {
+			case len(batch) >= s.opts.maxBatchSize:
+				kept = append(kept, item)
+			case item.ctx.Err() != nil:
+				dropped = append(dropped, drop{item, DropReasonCancelled, fmt.Errorf("%w: %v", ErrInferenceTimeout, item.ctx.Err())})
+			case deadlineUnreachable(item.ctx, now, estimate):
+				dropped = append(dropped, drop{item, DropReasonDeadline,
+					fmt.Errorf("%w: deadline cannot be met, expected latency %s", ErrInferenceTimeout, estimate)})
+			default:
+				batch = append(batch, item)
+			}
+		}
+		for i := len(kept); i < len(q.classes[p]); i++ {
+			q.classes[p][i] = nil
+		}
+		q.classes[p] = kept
+	}
+	q.depth -= len(batch) + len(dropped)
+	q.dropped += int64(len(dropped))
+	var depths [numRequestPriorities]int
+	for p := range q.classes {
+		depths[p] = len(q.classes[p])
+	}
+	q.mu.Unlock()
+
+	for _, d := range dropped {
+		s.opts.metrics.RecordDrop(q.name, d.item.priority, d.reason)
+		s.opts.logger.Debug("inference request dropped",
+			"model", q.key, "priority", d.item.priority.String(), "reason", d.reason)
+		d.item.complete(nil, d.err)
+	}
+	for p, depth := range depths {
+		s.opts.metrics.RecordQueueDepth(q.name, RequestPriority(p), depth)
+	}
+	return batch
+}
+
+func deadlineUnreachable(ctx context.Context, now time.Time, estimate time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && estimate > 0 && deadline.Before(now.Add(estimate))
+}
+
+// dispatch sends one batch and releases its concurrency slot.
+func (s *ServingScheduler) dispatch(q *modelQueue, batch []*scheduledRequest) {
+	defer s.wg.Done()
+	defer func() { <-q.slots }()
+
+	start := time.Now()
+	s.opts.metrics.RecordBatch(q.name, len(batch), float64(start.Sub(batch[0].enqueuedAt))/float64(time.Millisecond))
+
+	if len(batch) == 1 {
+		resp, err := s.next.Predict(batch[0].ctx, batch[0].req)
+		batch[0].complete(resp, err)
+	} else {
+		ctx, cancel := batchContext(batch)
+		reqs := make([]*PredictRequest, len(batch))
+		for i, item := range batch {
+			reqs[i] = item.req
+		}
+		resps, err := s.next.BatchPredict(ctx, reqs)
+		cancel()
+		for i, item := range batch {
+			switch {
+			case i < len(resps) && resps[i] != nil:
+				item.complete(resps[i], nil)
+			case err != nil:
+				item.complete(nil, err)
+			default:
+				item.complete(nil, fmt.Errorf("%w: batch response missing for %s", ErrServingUnavailable, q.key))
+			}
+		}
+	}
+
+	latency := time.Since(start)
+	q.mu.Lock()
+	if q.estimate == 0 {
+		q.estimate = latency
+	} else {
+		q.estimate = (4*q.estimate + latency) / 5
+	}
+	q.dispatched += int64(len(batch))
+	q.batches++
+	q.mu.Unlock()
+}
+
+// batchContext keeps the values of the first request and the latest deadline
+// in the batch, so one caller giving up does not fail the others.
+func batchContext(batch []*scheduledRequest) (context.Context, context.CancelFunc) {
+	base := context.WithoutCancel(batch[0].ctx)
+	var latest time.Time
+	for _, item := range batch {
+		deadline, ok := item.ctx.Deadline()
+		if !ok {
+			return context.WithCancel(base)
+		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+	return context.WithDeadline(base, latest)
+}
+
+var _ ServingClient = (*ServingScheduler)(nil)
+
+//Personal.AI order the ending
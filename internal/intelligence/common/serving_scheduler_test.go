@@ -0,0 +1,305 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// peakServingClient tracks how many calls are in flight on the mock.
+type peakServingClient struct {
+	*mockServingClient
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (p *peakServingClient) track() func() {
+	n := p.inFlight.Add(1)
+	for {
+		old := p.peak.Load()
+		if n <= old || p.peak.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	return func() { p.inFlight.Add(-1) }
+}
+
+func (p *peakServingClient) Predict(ctx context.Context, req *PredictRequest) (*PredictResponse, error) {
+	defer p.track()()
+	return p.mockServingClient.Predict(ctx, req)
+}
+
+func (p *peakServingClient) BatchPredict(ctx context.Context, reqs []*PredictRequest) ([]*PredictResponse, error) {
+	defer p.track()()
+	return p.mockServingClient.BatchPredict(ctx, reqs)
+}
+
+// recordingSchedulerMetrics keeps the last reported depth and every drop.
+type recordingSchedulerMetrics struct {
+	mu      sync.Mutex
+	depth   map[RequestPriority]int
+	batches []int
+	drops   []string
+}
+
+func (m *recordingSchedulerMetrics) RecordQueueDepth(_ string, p RequestPriority, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.depth == nil {
+		m.depth = make(map[RequestPriority]int)
+	}
+	m.depth[p] = depth
+}
+
+func (m *recordingSchedulerMetrics) RecordBatch(_ string, size int, _ float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, size)
+}
+
+func (m *recordingSchedulerMetrics) RecordDrop(_ string, p RequestPriority, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drops = append(m.drops, p.String()+"/"+reason)
+}
+
+func (m *recordingSchedulerMetrics) snapshot() (batches []int, drops []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.batches...), append([]string(nil), m.drops...)
+}
+
+func schedReq(label string) *PredictRequest {
+	return &PredictRequest{ModelName: "chem-extractor", InputData: []byte(label), InputFormat: FormatJSON}
+}
+
+func newTestScheduler(t *testing.T, next ServingClient, opts ...SchedulerOption) *ServingScheduler {
+	t.Helper()
+	s, err := NewServingScheduler(next, opts...)
+	if err != nil {
+		t.Fatalf("NewServingScheduler: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// predictedLabels returns the inputs of Predict calls in call order.
+func predictedLabels(m *mockServingClient) []string {
+	var out []string
+	for _, c := range m.CallHistory() {
+		if c.Method == "Predict" {
+			out = append(out, string(c.Args[0].(*PredictRequest).InputData))
+		}
+	}
+	return out
+}
+
+func countCalls(m *mockServingClient, method string) int {
+	n := 0
+	for _, c := range m.CallHistory() {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRequestPriority_Context(t *testing.T) {
+	if PriorityFromContext(context.Background()) != PriorityInteractive {
+		t.Error("default priority should be interactive")
+	}
+	ctx := ContextWithPriority(context.Background(), PriorityBackground)
+	if PriorityFromContext(ctx) != PriorityBackground || PriorityBackground.String() != "background" {
+		t.Error("priority not carried by context")
+	}
+}
+
+func TestServingScheduler_MicroBatches(t *testing.T) {
+	mock := NewMockServingClient()
+	metrics := &recordingSchedulerMetrics{}
+	s := newTestScheduler(t, mock,
+		WithSchedulerMaxBatchSize(4), WithSchedulerMaxBatchWait(time.Second), WithSchedulerMetrics(metrics))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := s.Predict(context.Background(), schedReq(fmt.Sprintf("m-%d", i)))
+			if err != nil {
+				t.Errorf("Predict: %v", err)
+				return
+			}
+			if string(resp.Outputs["default"]) != fmt.Sprintf("m-%d", i) {
+				t.Errorf("response routed to the wrong caller: %q", resp.Outputs["default"])
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := countCalls(mock, "BatchPredict"); got != 1 {
+		t.Errorf("expected one coalesced BatchPredict, got %d", got)
+	}
+	if batches, _ := metrics.snapshot(); len(batches) != 1 || batches[0] != 4 {
+		t.Errorf("expected a single batch of 4, got %v", batches)
+	}
+	st := s.Stats()
+	if len(st) != 1 || st[0].Dispatched != 4 || st[0].Batches != 1 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+}
+
+func TestServingScheduler_MaxWaitFlushesPartialBatch(t *testing.T) {
+	mock := NewMockServingClient()
+	s := newTestScheduler(t, mock, WithSchedulerMaxBatchSize(8), WithSchedulerMaxBatchWait(20*time.Millisecond))
+
+	start := time.Now()
+	if _, err := s.Predict(context.Background(), schedReq("alone")); err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("expected the request to wait about the max batch wait, took %s", elapsed)
+	}
+	if countCalls(mock, "BatchPredict") != 0 {
+		t.Error("a single request should be sent with Predict")
+	}
+}
+
+func TestServingScheduler_PriorityOrder(t *testing.T) {
+	mock := NewMockServingClient()
+	mock.SetDelay(30 * time.Millisecond)
+	s := newTestScheduler(t, mock,
+		WithSchedulerMaxBatchSize(1), WithSchedulerMaxBatchWait(0), WithSchedulerConcurrency(1))
+
+	bg := ContextWithPriority(context.Background(), PriorityBackground)
+	batch := ContextWithPriority(context.Background(), PriorityBatch)
+
+	var wg sync.WaitGroup
+	submit := func(ctx context.Context, label string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Predict(ctx, schedReq(label)); err != nil {
+				t.Errorf("Predict(%s): %v", label, err)
+			}
+		}()
+	}
+	submit(bg, "first")
+	time.Sleep(10 * time.Millisecond) // "first" now occupies the only slot
+	submit(bg, "background")
+	time.Sleep(2 * time.Millisecond)
+	submit(batch, "batch")
+	time.Sleep(2 * time.Millisecond)
+	submit(context.Background(), "interactive")
+	wg.Wait()
+
+	got := predictedLabels(mock)
+	want := []string{"first", "interactive", "batch", "background"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("dispatch order = %v, want %v", got, want)
+	}
+}
+
+func TestServingScheduler_ModelConcurrency(t *testing.T) {
+	mock := &peakServingClient{mockServingClient: NewMockServingClient()}
+	mock.SetDelay(20 * time.Millisecond)
+	s := newTestScheduler(t, mock,
+		WithSchedulerMaxBatchSize(1), WithSchedulerMaxBatchWait(0),
+		WithSchedulerConcurrency(8), WithSchedulerModelConcurrency("chem-extractor", 2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = s.Predict(context.Background(), schedReq(fmt.Sprintf("c-%d", i)))
+		}(i)
+	}
+	wg.Wait()
+
+	if peak := mock.peak.Load(); peak != 2 {
+		t.Errorf("expected at most 2 concurrent calls for the model, peak %d", peak)
+	}
+}
+
+func TestServingScheduler_DropsUnreachableDeadline(t *testing.T) {
+	mock := NewMockServingClient()
+	mock.SetDelay(50 * time.Millisecond)
+	metrics := &recordingSchedulerMetrics{}
+	s := newTestScheduler(t, mock, WithSchedulerMaxBatchWait(0), WithSchedulerMetrics(metrics))
+
+	// Establish the model's latency estimate.
+	if _, err := s.Predict(context.Background(), schedReq("warmup")); err != nil {
+		t.Fatalf("Predict: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.Predict(ctx, schedReq("hopeless"))
+	if !errors.Is(err, ErrInferenceTimeout) {
+		t.Fatalf("expected ErrInferenceTimeout, got %v", err)
+	}
+	if time.Since(start) > 15*time.Millisecond {
+		t.Error("an unreachable deadline should fail fast, not wait for it to expire")
+	}
+	if got := predictedLabels(mock); len(got) != 1 {
+		t.Errorf("dropped request must not reach the server, calls %v", got)
+	}
+	if _, drops := metrics.snapshot(); len(drops) != 1 || drops[0] != "interactive/deadline" {
+		t.Errorf("unexpected drops %v", drops)
+	}
+}
+
+func TestServingScheduler_QueueLimitAndClose(t *testing.T) {
+	mock := NewMockServingClient()
+	mock.SetDelay(200 * time.Millisecond)
+	metrics := &recordingSchedulerMetrics{}
+	s, err := NewServingScheduler(mock,
+		WithSchedulerMaxBatchSize(1), WithSchedulerMaxBatchWait(0), WithSchedulerConcurrency(1),
+		WithSchedulerQueueLimit(2), WithSchedulerMetrics(metrics))
+	if err != nil {
+		t.Fatalf("NewServingScheduler: %v", err)
+	}
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			_, err := s.Predict(ContextWithPriority(context.Background(), PriorityBatch), schedReq(fmt.Sprintf("q-%d", i)))
+			errs <- err
+		}(i)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	metrics.mu.Lock()
+	depth := metrics.depth[PriorityBatch]
+	metrics.mu.Unlock()
+	if depth != 2 {
+		t.Errorf("expected batch queue depth 2, got %d", depth)
+	}
+	if _, err := s.Predict(context.Background(), schedReq("overflow")); !errors.Is(err, ErrSchedulerQueueFull) {
+		t.Errorf("expected ErrSchedulerQueueFull, got %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	closed := 0
+	for i := 0; i < 3; i++ {
+		if errors.Is(<-errs, ErrClientClosed) {
+			closed++
+		}
+	}
+	if closed != 2 {
+		t.Errorf("expected the 2 queued requests to fail with ErrClientClosed, got %d", closed)
+	}
+	if _, err := s.Predict(context.Background(), schedReq("late")); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed after Close, got %v", err)
+	}
+}
+
+//Personal.AI order the ending
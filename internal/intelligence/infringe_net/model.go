@@ -760,6 +760,8 @@ func (m *remoteInfringeModel) Healthy(ctx context.Context) error {
 // callWithRetry invokes the remote serving client with exponential-backoff retry.
 func (m *remoteInfringeModel) callWithRetry(ctx context.Context, task string, payload []byte) ([]byte, error) {
 	modelID := "infringe-net-remote-v1"
+	// Shadow experiments on InfringeNet mirror this payload.
+	ctx = common.ContextWithShadowInput(ctx, payload)
 	var lastErr error
 	for attempt := 0; attempt <= m.opts.maxRetries; attempt++ {
 		callCtx, cancel := context.WithTimeout(ctx, m.opts.inferenceTimeout)
@@ -967,6 +967,25 @@ func TestRemoteModel_ComputeSimilarity_CacheMiss(t *testing.T) {
 	}
 }
 
+func TestRemoteModel_TagsShadowInput(t *testing.T) {
+	var shadowInput []byte
+	client := &mockServingClient{
+		predictFn: func(ctx context.Context, modelID string, payload []byte) ([]byte, error) {
+			shadowInput, _ = common.ShadowInputFromContext(ctx)
+			return json.Marshal(map[string]interface{}{"score": 0.80})
+		},
+	}
+	m := newTestRemoteModel(t, client)
+
+	if _, err := m.ComputeStructuralSimilarity(context.Background(), "CCO", "CCCO"); err != nil {
+		t.Fatalf("ComputeStructuralSimilarity: %v", err)
+	}
+	want := marshalJSON(map[string]string{"smiles1": "CCO", "smiles2": "CCCO"})
+	if string(shadowInput) != string(want) {
+		t.Errorf("expected the request payload as shadow input, got %q", shadowInput)
+	}
+}
+
 func TestRemoteModel_ComputeSimilarity_CacheEviction(t *testing.T) {
 	client := &mockServingClient{
 		predictFn: func(ctx context.Context, modelID string, payload []byte) ([]byte, error) {
@@ -0,0 +1,666 @@
+package strategy_gpt
+
+import (
+	"context"
+	stdliberrors "errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Types
+// ---------------------------------------------------------------------------
+
+// PatentLookup resolves patent numbers against the corpus.
+// patent.PatentRepository satisfies it.
+type PatentLookup interface {
+	FindByPatentNumber(ctx context.Context, patentNumber string) (*patent.Patent, error)
+}
+
+// ReferenceKind classifies a reference found in generated text.
+type ReferenceKind string
+
+const (
+	RefPatent ReferenceKind = "patent"
+	RefClaim  ReferenceKind = "claim"
+	RefQuote  ReferenceKind = "quote"
+)
+
+// GroundingStatus is the outcome of resolving one reference.
+type GroundingStatus string
+
+const (
+	// GroundingVerified means the reference exists in the retrieved context
+	// or the patent corpus.
+	GroundingVerified GroundingStatus = "verified"
+	// GroundingHallucinated means the sources were checked and the reference
+	// does not exist: an unknown patent, a claim number the patent does not
+	// have, or a quotation that appears nowhere.
+	GroundingHallucinated GroundingStatus = "hallucinated"
+	// GroundingUnverified means no source was available to decide.
+	GroundingUnverified GroundingStatus = "unverified"
+)
+
+// GroundingPolicy decides what happens to hallucinated references.
+type GroundingPolicy string
+
+const (
+	// GroundingFlag leaves the text untouched and only reports.
+	GroundingFlag GroundingPolicy = "flag"
+	// GroundingStrip removes hallucinated references from the report text.
+	GroundingStrip GroundingPolicy = "strip"
+)
+
+// Placeholders written in place of stripped references.
+const (
+	StrippedCitation  = "[unverified citation removed]"
+	StrippedQuotation = "[unverified quotation removed]"
+)
+
+// GroundingConfig configures the citation grounding verifier.
+type GroundingConfig struct {
+	Policy GroundingPolicy `json:"policy" yaml:"policy"`
+	// MinQuoteLength ignores shorter quoted strings, which are usually terms
+	// of art rather than quotations.
+	MinQuoteLength int `json:"min_quote_length" yaml:"min_quote_length"`
+}
+
+// GroundingReference is one patent, claim or quote reference in the output.
+type GroundingReference struct {
+	Kind         ReferenceKind   `json:"kind"`
+	Text         string          `json:"text"`
+	SectionID    string          `json:"section_id"`
+	PatentNumber string          `json:"patent_number,omitempty"`
+	ClaimNumber  int             `json:"claim_number,omitempty"`
+	Status       GroundingStatus `json:"status"`
+	Source       string          `json:"source,omitempty"` // "context" or "corpus"
+	Reason       string          `json:"reason,omitempty"`
+	Stripped     bool            `json:"stripped,omitempty"`
+
+	start, end int
+}
+
+// SectionGrounding scores the references of one report section. Verified
+// references count 1, unverified 0.5 and hallucinated 0; a section without
+// references scores 1.
+type SectionGrounding struct {
+	SectionID    string  `json:"section_id"`
+	Title        string  `json:"title,omitempty"`
+	Score        float64 `json:"score"`
+	References   int     `json:"references"`
+	Verified     int     `json:"verified"`
+	Unverified   int     `json:"unverified"`
+	Hallucinated int     `json:"hallucinated"`
+}
+
+// GroundingReport is the verifier's result for a whole report.
+type GroundingReport struct {
+	Score        float64               `json:"score"`
+	Sections     []*SectionGrounding   `json:"sections"`
+	References   []*GroundingReference `json:"references"`
+	Hallucinated int                   `json:"hallucinated"`
+	Stripped     int                   `json:"stripped"`
+	Policy       GroundingPolicy       `json:"policy"`
+}
+
+// HallucinatedReferences returns the references that failed verification.
+func (r *GroundingReport) HallucinatedReferences() []*GroundingReference {
+	var out []*GroundingReference
+	for _, ref := range r.References {
+		if ref.Status == GroundingHallucinated {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// GroundingVerifier checks the references in generated content against the
+// retrieved chunks and the patent corpus.
+type GroundingVerifier interface {
+	// Verify scores content in place: each section's Grounding is set and,
+	// under GroundingStrip, hallucinated references are removed from the
+	// text. subjectPatents are the patents the report is about; bare claim
+	// references ("claim 3") resolve against them when exactly one is given.
+	Verify(ctx context.Context, content *ReportContent, chunks []*RAGChunk, subjectPatents []string) (*GroundingReport, error)
+}
+
+// ---------------------------------------------------------------------------
+// groundingVerifierImpl
+// ---------------------------------------------------------------------------
+
+type groundingVerifierImpl struct {
+	patents PatentLookup
+	config  GroundingConfig
+}
+
+// NewGroundingVerifier creates a verifier. patents may be nil, in which case
+// only the retrieved context is consulted and references missing from it are
+// reported as unverified rather than hallucinated.
+func NewGroundingVerifier(patents PatentLookup, config GroundingConfig) (GroundingVerifier, error) {
+	switch config.Policy {
+	case "":
+		config.Policy = GroundingFlag
+	case GroundingFlag, GroundingStrip:
+	default:
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("unknown grounding policy %q", config.Policy))
+	}
+	if config.MinQuoteLength <= 0 {
+		config.MinQuoteLength = 20
+	}
+	return &groundingVerifierImpl{patents: patents, config: config}, nil
+}
+
+var (
+	// US 10,000,001 B2, US2023/0123456A1, EP3500001, WO2023/123456, CN112345678A
+	// A kind code separated by a space needs its digit ("B2"), so the
+	// article in "US12345678 A method" is not taken for one.
+	groundingPatentPattern = regexp.MustCompile(`\b(US|EP|CN|WO|JP|KR|DE|GB|FR)\s?(\d[\d,/ ]{4,}\d)(?:([A-Z]\d?)|\s([A-Z]\d))?\b`)
+	groundingClaimPattern  = regexp.MustCompile(`(?i)\bclaims?\s+(\d+(?:\s*(?:,|and|or|-|–|to|through)\s*\d+)*)`)
+	groundingOfPattern     = regexp.MustCompile(`(?i)^\s*(?:of|in)\s+(?:the\s+)?(?:patent\s+)?`)
+	groundingQuotePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`"([^"\n]+)"`),
+		regexp.MustCompile(`“([^”\n]+)”`),
+	}
+	groundingNumberPattern = regexp.MustCompile(`\d+`)
+	groundingNonWord       = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// maxClaimRange bounds "claims 1-N" expansion.
+const maxClaimRange = 50
+
+// patentRecord caches one corpus lookup.
+type patentRecord struct {
+	patent *patent.Patent
+	err    error
+}
+
+// patentSpan locates a patent reference in a block of text.
+type patentSpan struct {
+	start, end int
+	base       string
+}
+
+// verification holds per-call state.
+type verification struct {
+	v        *groundingVerifierImpl
+	ctx      context.Context
+	chunks   []*RAGChunk
+	subjects []string
+
+	contextPatents map[string]bool // base numbers seen in chunks
+	contextText    string          // normalised chunk contents
+	corpus         map[string]*patentRecord
+}
+
+func (v *groundingVerifierImpl) Verify(ctx context.Context, content *ReportContent, chunks []*RAGChunk, subjectPatents []string) (*GroundingReport, error) {
+	if content == nil {
+		return nil, errors.NewInvalidInputError("report content is required")
+	}
+	run := &verification{
+		v:              v,
+		ctx:            ctx,
+		chunks:         chunks,
+		contextPatents: make(map[string]bool),
+		corpus:         make(map[string]*patentRecord),
+	}
+	var texts []string
+	for _, c := range chunks {
+		if c == nil {
+			continue
+		}
+		for _, id := range []string{c.DocumentID, c.Metadata["document_id"], c.Metadata["patent_number"]} {
+			if base, _, ok := parsePatentNumber(id); ok {
+				run.contextPatents[base] = true
+			}
+		}
+		for _, m := range groundingPatentPattern.FindAllStringSubmatch(c.Content, -1) {
+			if base, _, ok := parsePatentNumber(m[0]); ok {
+				run.contextPatents[base] = true
+			}
+		}
+		texts = append(texts, normalizeForQuote(c.Content))
+	}
+	run.contextText = strings.Join(texts, " | ")
+	for _, s := range subjectPatents {
+		if base, _, ok := parsePatentNumber(s); ok {
+			run.subjects = append(run.subjects, base)
+		}
+	}
+
+	report := &GroundingReport{Policy: v.config.Policy}
+	score := func(sectionID, title string, text *string) {
+		refs := run.verifyText(sectionID, *text)
+		if v.config.Policy == GroundingStrip {
+			var n int
+			*text, n = stripHallucinated(*text, refs)
+			report.Stripped += n
+		}
+		report.Sections = append(report.Sections, sectionGrounding(sectionID, title, refs))
+		report.References = append(report.References, refs...)
+	}
+
+	if content.ExecutiveSummary != "" {
+		score("executive_summary", "Executive Summary", &content.ExecutiveSummary)
+	}
+	var walk func(sections []*ReportSection)
+	walk = func(sections []*ReportSection) {
+		for _, s := range sections {
+			if s == nil {
+				continue
+			}
+			score(s.SectionID, s.Title, &s.Content)
+			s.Grounding = report.Sections[len(report.Sections)-1]
+			walk(s.SubSections)
+		}
+	}
+	walk(content.Sections)
+	for i, c := range content.Conclusions {
+		if c != nil {
+			score(fmt.Sprintf("conclusion-%d", i+1), "Conclusion", &c.Statement)
+		}
+	}
+
+	var total float64
+	for _, ref := range report.References {
+		total += statusWeight(ref.Status)
+		if ref.Status == GroundingHallucinated {
+			report.Hallucinated++
+		}
+	}
+	report.Score = 1
+	if len(report.References) > 0 {
+		report.Score = roundScore(total / float64(len(report.References)))
+	}
+	syncCitationStatus(content.Citations, report.References)
+	return report, nil
+}
+
+// verifyText extracts and resolves the references in one block of text.
+func (r *verification) verifyText(sectionID, text string) []*GroundingReference {
+	var refs []*GroundingReference
+
+	var patents []patentSpan
+	for _, loc := range groundingPatentPattern.FindAllStringIndex(text, -1) {
+		raw := text[loc[0]:loc[1]]
+		base, display, ok := parsePatentNumber(raw)
+		if !ok {
+			continue
+		}
+		patents = append(patents, patentSpan{loc[0], loc[1], base})
+		ref := &GroundingReference{Kind: RefPatent, Text: raw, SectionID: sectionID, PatentNumber: display, start: loc[0], end: loc[1]}
+		r.resolvePatent(ref, base)
+		refs = append(refs, ref)
+	}
+
+	// attribute finds the patent a claim reference belongs to: one named
+	// right after it ("claim 3 of US..."), else the last one named earlier
+	// in the same paragraph, else the sole subject patent.
+	attribute := func(start, end int) string {
+		if m := groundingOfPattern.FindStringIndex(text[end:]); m != nil {
+			for _, p := range patents {
+				if p.start == end+m[1] {
+					return p.base
+				}
+			}
+		}
+		for i := len(patents) - 1; i >= 0; i-- {
+			p := patents[i]
+			if p.end <= start && !strings.Contains(text[p.end:start], "\n\n") {
+				return p.base
+			}
+		}
+		if len(r.subjects) == 1 {
+			return r.subjects[0]
+		}
+		return ""
+	}
+	for _, loc := range groundingClaimPattern.FindAllStringSubmatchIndex(text, -1) {
+		base := attribute(loc[0], loc[1])
+		for _, n := range expandClaimNumbers(text[loc[2]:loc[3]]) {
+			ref := &GroundingReference{Kind: RefClaim, Text: text[loc[0]:loc[1]], SectionID: sectionID, ClaimNumber: n, start: loc[0], end: loc[1]}
+			if base != "" {
+				ref.PatentNumber = base
+			}
+			r.resolveClaim(ref, base)
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, pat := range groundingQuotePatterns {
+		for _, loc := range pat.FindAllStringSubmatchIndex(text, -1) {
+			quote := strings.TrimSpace(text[loc[2]:loc[3]])
+			if len([]rune(quote)) < r.v.config.MinQuoteLength {
+				continue
+			}
+			ref := &GroundingReference{Kind: RefQuote, Text: quote, SectionID: sectionID, start: loc[0], end: loc[1]}
+			r.resolveQuote(ref, patents)
+			refs = append(refs, ref)
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].start < refs[j].start })
+	return refs
+}
+
+func (r *verification) resolvePatent(ref *GroundingReference, base string) {
+	if r.contextPatents[base] {
+		ref.Status, ref.Source = GroundingVerified, "context"
+		return
+	}
+	rec := r.lookup(base, ref.Text)
+	switch {
+	case rec == nil:
+		ref.Status, ref.Reason = GroundingUnverified, "not in retrieved context; no corpus configured"
+	case rec.patent != nil:
+		ref.Status, ref.Source = GroundingVerified, "corpus"
+	case rec.err != nil:
+		ref.Status, ref.Reason = GroundingUnverified, "corpus lookup failed: "+rec.err.Error()
+	default:
+		ref.Status, ref.Reason = GroundingHallucinated, "patent not found in retrieved context or corpus"
+	}
+}
+
+func (r *verification) resolveClaim(ref *GroundingReference, base string) {
+	if base == "" {
+		if r.contextMentionsClaim("", ref.ClaimNumber) {
+			ref.Status, ref.Source = GroundingVerified, "context"
+		} else {
+			ref.Status, ref.Reason = GroundingUnverified, "claim not attributable to a patent"
+		}
+		return
+	}
+	rec := r.lookup(base, base)
+	switch {
+	case rec != nil && rec.patent != nil && len(rec.patent.Claims) > 0:
+		if _, ok := rec.patent.Claims.FindByNumber(ref.ClaimNumber); ok {
+			ref.Status, ref.Source = GroundingVerified, "corpus"
+		} else {
+			ref.Status = GroundingHallucinated
+			ref.Reason = fmt.Sprintf("%s has no claim %d (%d claims)", base, ref.ClaimNumber, len(rec.patent.Claims))
+		}
+		return
+	case rec != nil && rec.patent == nil && rec.err == nil && !r.contextPatents[base]:
+		ref.Status, ref.Reason = GroundingHallucinated, "claim of a patent that does not exist"
+		return
+	}
+	if r.contextMentionsClaim(base, ref.ClaimNumber) {
+		ref.Status, ref.Source = GroundingVerified, "context"
+		return
+	}
+	ref.Status, ref.Reason = GroundingUnverified, "claim text not available"
+}
+
+// contextMentionsClaim reports whether a chunk (of base, when given) carries
+// or mentions claim n.
+func (r *verification) contextMentionsClaim(base string, n int) bool {
+	mention := regexp.MustCompile(`(?i)\bclaim\s+` + strconv.Itoa(n) + `\b`)
+	for _, c := range r.chunks {
+		if c == nil {
+			continue
+		}
+		if base != "" {
+			docBase, _, _ := parsePatentNumber(c.DocumentID)
+			if docBase == "" {
+				docBase, _, _ = parsePatentNumber(c.Metadata["document_id"])
+			}
+			if docBase != base {
+				continue
+			}
+		}
+		if c.Metadata["claim_number"] == strconv.Itoa(n) || mention.MatchString(c.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *verification) resolveQuote(ref *GroundingReference, patents []patentSpan) {
+	needle := normalizeForQuote(ref.Text)
+	if needle == "" {
+		ref.Status = GroundingUnverified
+		return
+	}
+	if strings.Contains(r.contextText, needle) {
+		ref.Status, ref.Source = GroundingVerified, "context"
+		return
+	}
+	haveSource := r.contextText != ""
+	bases := append([]string(nil), r.subjects...)
+	for _, p := range patents {
+		bases = append(bases, p.base)
+	}
+	for _, base := range bases {
+		rec := r.lookup(base, base)
+		if rec == nil || rec.patent == nil {
+			continue
+		}
+		haveSource = true
+		if strings.Contains(patentText(rec.patent), needle) {
+			ref.Status, ref.Source = GroundingVerified, "corpus"
+			return
+		}
+	}
+	if haveSource {
+		ref.Status, ref.Reason = GroundingHallucinated, "quotation not found in any source"
+	} else {
+		ref.Status, ref.Reason = GroundingUnverified, "no source text available"
+	}
+}
+
+// lookup resolves a patent in the corpus, trying the number as written and
+// then without kind code. It returns nil when no corpus is configured; a
+// record with neither patent nor error means not found.
+func (r *verification) lookup(base, raw string) *patentRecord {
+	if r.v.patents == nil {
+		return nil
+	}
+	if rec, ok := r.corpus[base]; ok {
+		return rec
+	}
+	rec := &patentRecord{}
+	candidates := []string{base}
+	if compact := compactPatentNumber(raw); compact != base {
+		candidates = append([]string{compact}, candidates...)
+	}
+	for _, num := range candidates {
+		p, err := r.v.patents.FindByPatentNumber(r.ctx, num)
+		if err == nil && p != nil {
+			rec.patent, rec.err = p, nil
+			break
+		}
+		if err != nil && !errors.IsNotFound(err) && !stdliberrors.Is(err, patent.ErrPatentNotFound) {
+			rec.err = err
+		}
+	}
+	r.corpus[base] = rec
+	return rec
+}
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+func compactPatentNumber(s string) string {
+	return strings.NewReplacer(" ", "", ",", "", "-", "").Replace(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+// parsePatentNumber returns the comparison key (office + digits, no kind
+// code) and a display form (compact, with kind code).
+func parsePatentNumber(s string) (base, display string, ok bool) {
+	m := groundingPatentPattern.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return "", "", false
+	}
+	digits := strings.NewReplacer(",", "", "/", "", " ", "").Replace(m[2])
+	base = m[1] + digits
+	return base, base + m[3] + m[4], true
+}
+
+func expandClaimNumbers(s string) []int {
+	nums := groundingNumberPattern.FindAllStringIndex(s, -1)
+	var out []int
+	seen := make(map[int]bool)
+	add := func(n int) {
+		if n > 0 && !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	for i := 0; i < len(nums); i++ {
+		n, _ := strconv.Atoi(s[nums[i][0]:nums[i][1]])
+		if i+1 < len(nums) {
+			sep := strings.ToLower(strings.TrimSpace(s[nums[i][1]:nums[i+1][0]]))
+			if sep == "-" || sep == "–" || sep == "to" || sep == "through" {
+				hi, _ := strconv.Atoi(s[nums[i+1][0]:nums[i+1][1]])
+				if hi >= n && hi-n < maxClaimRange {
+					for k := n; k <= hi; k++ {
+						add(k)
+					}
+					i++
+					continue
+				}
+			}
+		}
+		add(n)
+	}
+	return out
+}
+
+func normalizeForQuote(s string) string {
+	return strings.TrimSpace(groundingNonWord.ReplaceAllString(strings.ToLower(s), " "))
+}
+
+func patentText(p *patent.Patent) string {
+	parts := []string{p.Title, p.TitleEn, p.Abstract, p.AbstractEn}
+	for _, c := range p.Claims {
+		parts = append(parts, c.Text)
+	}
+	return normalizeForQuote(strings.Join(parts, " | "))
+}
+
+func statusWeight(s GroundingStatus) float64 {
+	switch s {
+	case GroundingVerified:
+		return 1
+	case GroundingUnverified:
+		return 0.5
+	}
+	return 0
+}
+
+func roundScore(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
+
+func sectionGrounding(sectionID, title string, refs []*GroundingReference) *SectionGrounding {
+	sg := &SectionGrounding{SectionID: sectionID, Title: title, Score: 1, References: len(refs)}
+	var total float64
+	for _, ref := range refs {
+		total += statusWeight(ref.Status)
+		switch ref.Status {
+		case GroundingVerified:
+			sg.Verified++
+		case GroundingUnverified:
+			sg.Unverified++
+		case GroundingHallucinated:
+			sg.Hallucinated++
+		}
+	}
+	if len(refs) > 0 {
+		sg.Score = roundScore(total / float64(len(refs)))
+	}
+	return sg
+}
+
+// stripHallucinated replaces hallucinated spans, outermost first, and marks
+// the references it removed.
+func stripHallucinated(text string, refs []*GroundingReference) (string, int) {
+	var spans []*GroundingReference
+	for _, ref := range refs {
+		if ref.Status == GroundingHallucinated {
+			spans = append(spans, ref)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+	var b strings.Builder
+	pos, stripped := 0, 0
+	for _, ref := range spans {
+		if ref.start < pos {
+			ref.Stripped = true // inside a span already removed
+			continue
+		}
+		b.WriteString(text[pos:ref.start])
+		if ref.Kind == RefQuote {
+			b.WriteString(StrippedQuotation)
+		} else {
+			b.WriteString(StrippedCitation)
+		}
+		pos = ref.end
+		ref.Stripped = true
+		stripped++
+	}
+	b.WriteString(text[pos:])
+	return b.String(), stripped
+}
+
+// syncCitationStatus updates the extracted citation list with the outcome
+// of the patent references it corresponds to.
+func syncCitationStatus(citations []*Citation, refs []*GroundingReference) {
+	status := make(map[string]GroundingStatus)
+	for _, ref := range refs {
+		if ref.Kind != RefPatent {
+			continue
+		}
+		base, _, _ := parsePatentNumber(ref.Text)
+		if prev, ok := status[base]; !ok || statusWeight(ref.Status) > statusWeight(prev) {
+			status[base] = ref.Status
+		}
+	}
+	for _, c := range citations {
+		if c == nil || c.SourceType != SourcePatent {
+			continue
+		}
+		base, _, ok := parsePatentNumber(c.Source)
+		if !ok {
+			continue
+		}
+		switch status[base] {
+		case GroundingVerified:
+			c.VerificationStatus = "Verified"
+		case GroundingHallucinated:
+			c.VerificationStatus = "NotFound"
+		}
+	}
+}
+
+// groundingCitationSummary condenses the patent references of a grounding
+// report into the legacy citation counters.
+func groundingCitationSummary(r *GroundingReport) *CitationVerificationResult {
+	res := &CitationVerificationResult{}
+	for _, ref := range r.References {
+		if ref.Kind != RefPatent {
+			continue
+		}
+		res.TotalCitations++
+		switch ref.Status {
+		case GroundingVerified:
+			res.VerifiedCount++
+		case GroundingHallucinated:
+			res.NotFoundCount++
+		default:
+			res.UnverifiedCount++
+		}
+	}
+	return res
+}
@@ -0,0 +1,258 @@
+package strategy_gpt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type fakePatentLookup struct {
+	patents map[string]*patent.Patent
+	calls   int
+	err     error
+}
+
+func (f *fakePatentLookup) FindByPatentNumber(_ context.Context, number string) (*patent.Patent, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if p, ok := f.patents[number]; ok {
+		return p, nil
+	}
+	return nil, errors.New(errors.ErrCodePatentNotFound, "patent not found: "+number)
+}
+
+func newFakeCorpus() *fakePatentLookup {
+	return &fakePatentLookup{patents: map[string]*patent.Patent{
+		"US10000001": {
+			PatentNumber: "US10000001B2",
+			Abstract:     "An organic light emitting device comprising a triazine acceptor linked through a phenylene bridge.",
+			Claims: patent.ClaimSet{
+				{Number: 1, Text: "A compound comprising a triazine acceptor and a carbazole donor."},
+				{Number: 2, Text: "The compound of claim 1 wherein the bridge is phenylene."},
+			},
+		},
+	}}
+}
+
+func groundingChunks() []*RAGChunk {
+	return []*RAGChunk{
+		{ChunkID: "c1", DocumentID: "EP3500001", Content: "Claim 4 recites a host material with a bipolar core.", Source: SourcePatent},
+		{ChunkID: "c2", Content: "MPEP 2111.03 explains transitional phrases.", Source: SourceMPEP},
+	}
+}
+
+func refsByKind(r *GroundingReport, kind ReferenceKind) map[string]GroundingStatus {
+	out := make(map[string]GroundingStatus)
+	for _, ref := range r.References {
+		if ref.Kind == kind {
+			key := ref.PatentNumber
+			if kind == RefClaim {
+				key = fmt.Sprintf("%s#%d", ref.PatentNumber, ref.ClaimNumber)
+			} else if kind == RefQuote {
+				key = ref.Text
+			}
+			out[key] = ref.Status
+		}
+	}
+	return out
+}
+
+func TestGroundingVerifier_ResolvesReferences(t *testing.T) {
+	corpus := newFakeCorpus()
+	v, err := NewGroundingVerifier(corpus, GroundingConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := &ReportContent{
+		ExecutiveSummary: "US 10,000,001 B2 and EP3500001 are the closest art.",
+		Sections: []*ReportSection{
+			{SectionID: "sec-1", Title: "Claims", Content: "Claim 2 of US10000001B2 narrows to a phenylene bridge. " +
+				"Claims 1-3 of US10000001 are relevant. EP3500001 claim 4 recites a host."},
+			{SectionID: "sec-2", Title: "Prior art", Content: "US99999999 discloses everything. Its claim 7 is broad.\n\n" +
+				`US10000001 states "a triazine acceptor linked through a phenylene bridge" but never "a quantum dot emitter layer of any kind".`},
+			{SectionID: "sec-3", Title: "Outlook", Content: "No references here."},
+		},
+		Conclusions: []*Conclusion{{Statement: "Claim 1 is at risk."}},
+		Citations: []*Citation{
+			{Source: "US99999999", SourceType: SourcePatent, VerificationStatus: "Unverified"},
+			{Source: "EP3500001", SourceType: SourcePatent, VerificationStatus: "Unverified"},
+		},
+	}
+	report, err := v.Verify(context.Background(), content, groundingChunks(), []string{"US10000001B2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patents := refsByKind(report, RefPatent)
+	for num, want := range map[string]GroundingStatus{
+		"US10000001B2": GroundingVerified,
+		"EP3500001":    GroundingVerified,
+		"US99999999":   GroundingHallucinated,
+	} {
+		if patents[num] != want {
+			t.Errorf("patent %s: got %q, want %q", num, patents[num], want)
+		}
+	}
+
+	claims := refsByKind(report, RefClaim)
+	for key, want := range map[string]GroundingStatus{
+		"US10000001#2": GroundingVerified,     // "claim 2 of US..."
+		"US10000001#1": GroundingVerified,     // range, and the bare conclusion claim
+		"US10000001#3": GroundingHallucinated, // patent has two claims
+		"EP3500001#4":  GroundingVerified,     // from retrieved chunk text
+		"US99999999#7": GroundingHallucinated, // claim of a nonexistent patent
+	} {
+		if claims[key] != want {
+			t.Errorf("claim %s: got %q, want %q (all: %v)", key, claims[key], want, claims)
+		}
+	}
+
+	quotes := refsByKind(report, RefQuote)
+	if quotes["a triazine acceptor linked through a phenylene bridge"] != GroundingVerified {
+		t.Errorf("real quotation not verified: %v", quotes)
+	}
+	if quotes["a quantum dot emitter layer of any kind"] != GroundingHallucinated {
+		t.Errorf("invented quotation not flagged: %v", quotes)
+	}
+
+	sections := map[string]*SectionGrounding{}
+	for _, s := range report.Sections {
+		sections[s.SectionID] = s
+	}
+	if s := sections["sec-3"]; s == nil || s.Score != 1 || s.References != 0 {
+		t.Errorf("section without references: %+v", s)
+	}
+	if s := sections["sec-2"]; s.Hallucinated != 3 || s.Score >= sections["sec-1"].Score {
+		t.Errorf("sec-2 grounding: %+v vs sec-1 %+v", s, sections["sec-1"])
+	}
+	if content.Sections[0].Grounding != sections["sec-1"] {
+		t.Error("section grounding not attached to the section")
+	}
+	if report.Hallucinated != 4 || report.Score <= 0 || report.Score >= 1 {
+		t.Errorf("report: hallucinated=%d score=%v", report.Hallucinated, report.Score)
+	}
+	if !strings.Contains(content.Sections[1].Content, "US99999999") {
+		t.Error("flag policy must not modify text")
+	}
+	if content.Citations[0].VerificationStatus != "NotFound" || content.Citations[1].VerificationStatus != "Verified" {
+		t.Errorf("citation statuses: %s, %s", content.Citations[0].VerificationStatus, content.Citations[1].VerificationStatus)
+	}
+	if corpus.calls > 4 {
+		t.Errorf("corpus lookups should be cached, got %d calls", corpus.calls)
+	}
+}
+
+func TestGroundingVerifier_StripPolicy(t *testing.T) {
+	v, _ := NewGroundingVerifier(newFakeCorpus(), GroundingConfig{Policy: GroundingStrip})
+	content := &ReportContent{Sections: []*ReportSection{{
+		SectionID: "sec-1",
+		Content:   `See US99999999 and claim 9 of US10000001. It reads "an entirely fabricated passage about lasers".`,
+	}}}
+	report, err := v.Verify(context.Background(), content, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "See " + StrippedCitation + " and " + StrippedCitation + " of US10000001. It reads " + StrippedQuotation + "."
+	if got := content.Sections[0].Content; got != want {
+		t.Errorf("stripped text:\n got %q\nwant %q", got, want)
+	}
+	if report.Stripped != 3 || len(report.HallucinatedReferences()) != 3 {
+		t.Errorf("stripped=%d hallucinated=%d", report.Stripped, len(report.HallucinatedReferences()))
+	}
+}
+
+func TestGroundingVerifier_WithoutCorpus(t *testing.T) {
+	v, _ := NewGroundingVerifier(nil, GroundingConfig{})
+	content := &ReportContent{ExecutiveSummary: "EP3500001 claim 4 and US12345678 claim 2."}
+	report, err := v.Verify(context.Background(), content, groundingChunks(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patents := refsByKind(report, RefPatent)
+	if patents["EP3500001"] != GroundingVerified || patents["US12345678"] != GroundingUnverified {
+		t.Errorf("without a corpus, unknown patents are unverified, not hallucinated: %v", patents)
+	}
+	if report.Hallucinated != 0 {
+		t.Errorf("hallucinated = %d", report.Hallucinated)
+	}
+
+	// Corpus outages degrade to unverified as well.
+	broken := &fakePatentLookup{err: fmt.Errorf("connection refused")}
+	v, _ = NewGroundingVerifier(broken, GroundingConfig{})
+	report, _ = v.Verify(context.Background(), &ReportContent{ExecutiveSummary: "US12345678"}, nil, nil)
+	if report.References[0].Status != GroundingUnverified {
+		t.Errorf("lookup error: %+v", report.References[0])
+	}
+
+	if _, err := NewGroundingVerifier(nil, GroundingConfig{Policy: "delete"}); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+	if _, err := v.Verify(context.Background(), nil, nil, nil); err == nil {
+		t.Error("expected error for nil content")
+	}
+}
+
+func TestParsePatentNumberAndClaimRanges(t *testing.T) {
+	for in, want := range map[string]string{
+		"US 10,000,001 B2": "US10000001",
+		"US2023/0123456A1": "US20230123456",
+		"wo2023/123456":    "WO2023123456",
+		"CN112345678A":     "CN112345678",
+	} {
+		if base, _, ok := parsePatentNumber(in); !ok || base != want {
+			t.Errorf("parsePatentNumber(%q) = %q, %v", in, base, ok)
+		}
+	}
+	if _, display, _ := parsePatentNumber("US12345678 A method"); display != "US12345678" {
+		t.Errorf("article taken for kind code: %q", display)
+	}
+	if got := fmt.Sprint(expandClaimNumbers("1-3, 5 and 7 to 8")); got != "[1 2 3 5 7 8]" {
+		t.Errorf("expandClaimNumbers = %s", got)
+	}
+	if got := len(expandClaimNumbers("1-5000")); got != 2 {
+		t.Errorf("oversized range should not expand, got %d numbers", got)
+	}
+}
+
+func TestGenerateReport_WithGrounding(t *testing.T) {
+	backend := &mockLLMBackend{}
+	verifier, _ := NewGroundingVerifier(newFakeCorpus(), GroundingConfig{})
+	gen, err := NewReportGeneratorWithGrounding(backend, &mockPromptMgr{}, &mockRAG{}, NewStrategyGPTConfig(), nil, nil, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.predictFn = func(ctx context.Context, req *common.PredictRequest) (*common.PredictResponse, error) {
+		text := "## Executive Summary\n\nUS12345678 claim 1 and US99999999 claim 3 read on the product.\n\n" +
+			"## Conclusions\n\n- Risk is high.\n"
+		return &common.PredictResponse{Outputs: map[string][]byte{"text": []byte(text)}}, nil
+	}
+	req := ftoRequest(true)
+	report, err := gen.GenerateReport(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Grounding == nil || report.Grounding.Hallucinated == 0 {
+		t.Fatalf("expected hallucinations to be reported: %+v", report.Grounding)
+	}
+	if report.Validation == nil || report.Validation.IsValid {
+		t.Fatalf("report citing a nonexistent patent must be invalid: %+v", report.Validation)
+	}
+	found := false
+	for _, issue := range report.Validation.Issues {
+		found = found || issue.IssueType == "hallucinated_citation"
+	}
+	if !found {
+		t.Error("missing hallucinated_citation issue")
+	}
+
+	if _, err := NewReportGeneratorWithGrounding(backend, &mockPromptMgr{}, nil, nil, nil, nil, nil); err == nil {
+		t.Error("expected error for nil verifier")
+	}
+}
@@ -322,6 +322,7 @@ type promptManagerImpl struct {
 	templates map[string]*templateEntry
 	config    *PromptManagerConfig
 	funcMap   template.FuncMap
+	registry  PromptRegistry // optional; overrides system templates
 	mu        sync.RWMutex
 }
 
@@ -366,7 +367,7 @@ func (pm *promptManagerImpl) BuildPrompt(ctx context.Context, task AnalysisTask,
 	}
 
 	// 1. System prompt
-	systemPrompt, err := pm.GetSystemPrompt(task)
+	systemPrompt, templateVersion, err := pm.systemPrompt(task)
 	if err != nil {
 		return nil, err
 	}
@@ -486,7 +487,7 @@ func (pm *promptManagerImpl) BuildPrompt(ctx context.Context, task AnalysisTask,
 		Messages:          messages,
 		EstimatedTokens:   totalTokens,
 		TruncationApplied: truncated,
-		TemplateVersion:   pm.config.TemplateVersion,
+		TemplateVersion:   templateVersion,
 	}, nil
 }
 
@@ -495,14 +496,27 @@ func (pm *promptManagerImpl) BuildPrompt(ctx context.Context, task AnalysisTask,
 // ---------------------------------------------------------------------------
 
 func (pm *promptManagerImpl) GetSystemPrompt(task AnalysisTask) (string, error) {
+	body, _, err := pm.systemPrompt(task)
+	return body, err
+}
+
+// systemPrompt resolves the system prompt for a task and the version label
+// recorded in BuiltPrompt. The registry's active version wins over the
+// template map when a registry is attached.
+func (pm *promptManagerImpl) systemPrompt(task AnalysisTask) (string, string, error) {
+	if pm.registry != nil {
+		if v, err := pm.registry.Active(task); err == nil {
+			return v.Body, v.Label(), nil
+		}
+	}
 	key := systemTemplateKey(task)
 	pm.mu.RLock()
 	entry, ok := pm.templates[key]
 	pm.mu.RUnlock()
 	if !ok {
-		return "", errors.NewInvalidInputError(fmt.Sprintf("no system prompt template for task %s", task))
+		return "", "", errors.NewInvalidInputError(fmt.Sprintf("no system prompt template for task %s", task))
 	}
-	return entry.raw, nil
+	return entry.raw, entry.info.Version, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -563,6 +577,23 @@ func (pm *promptManagerImpl) ListTemplates() []TemplateInfo {
 	for _, e := range pm.templates {
 		out = append(out, e.info)
 	}
+	if pm.registry == nil {
+		return out
+	}
+	active := make(map[string]*PromptVersion)
+	for _, task := range pm.registry.Tasks() {
+		if v, err := pm.registry.Active(task); err == nil {
+			active[systemTemplateKey(task)] = v
+		}
+	}
+	for i, info := range out {
+		if v, ok := active[info.Name]; ok {
+			out[i].Version = v.Label()
+			out[i].Task = v.Task.String()
+			out[i].Description = v.Description
+			out[i].RegisteredAt = v.CreatedAt
+		}
+	}
 	return out
 }
 
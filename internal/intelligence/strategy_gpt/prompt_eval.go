@@ -0,0 +1,767 @@
+package strategy_gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Golden set
+// ---------------------------------------------------------------------------
+
+// GoldenCase is one patent/molecule scenario replayed through BuildPrompt and
+// the backend, together with what a good answer must contain.
+type GoldenCase struct {
+	ID          string            `json:"id"`
+	Task        AnalysisTask      `json:"task"`
+	Description string            `json:"description,omitempty"`
+	Params      *PromptParams     `json:"params"`
+	Expect      GoldenExpectation `json:"expect"`
+}
+
+// GoldenExpectation lists the checks applied to a case's output. Empty
+// fields switch the corresponding scorer off for that case.
+type GoldenExpectation struct {
+	// Citations are patent or prior-art references the answer must cite.
+	Citations []string `json:"citations,omitempty"`
+	// ClaimElements are claim features the answer must address.
+	ClaimElements []string `json:"claim_elements,omitempty"`
+	// Schema is a JSON schema (type, required, properties, items, enum,
+	// minItems, minLength) the structured output must satisfy.
+	Schema map[string]interface{} `json:"schema,omitempty"`
+	// Rubric holds free-form pattern checks.
+	Rubric []RubricCheck `json:"rubric,omitempty"`
+}
+
+// RubricCheck passes when Pattern (a case-insensitive regular expression)
+// matches the output, or when it does not match and Forbid is set.
+type RubricCheck struct {
+	Name    string  `json:"name"`
+	Pattern string  `json:"pattern"`
+	Forbid  bool    `json:"forbid,omitempty"`
+	Weight  float64 `json:"weight,omitempty"` // defaults to 1
+}
+
+// GoldenSet is a named collection of golden cases.
+type GoldenSet struct {
+	Name  string        `json:"name"`
+	Cases []*GoldenCase `json:"cases"`
+}
+
+// LoadGoldenSet reads and validates a golden set from a JSON file.
+func LoadGoldenSet(path string) (*GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading golden set %s: %w", path, err)
+	}
+	var set GoldenSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decoding golden set %s: %w", path, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// Validate checks case ids, tasks and rubric patterns.
+func (s *GoldenSet) Validate() error {
+	if len(s.Cases) == 0 {
+		return errors.NewInvalidInputError("golden set has no cases")
+	}
+	seen := make(map[string]bool, len(s.Cases))
+	for i, c := range s.Cases {
+		if c == nil || c.ID == "" {
+			return errors.NewInvalidInputError(fmt.Sprintf("golden case %d: id is required", i))
+		}
+		if seen[c.ID] {
+			return errors.NewInvalidInputError(fmt.Sprintf("golden case %s: duplicate id", c.ID))
+		}
+		seen[c.ID] = true
+		if !c.Task.IsValid() {
+			return errors.NewInvalidInputError(fmt.Sprintf("golden case %s: unknown task", c.ID))
+		}
+		for _, r := range c.Expect.Rubric {
+			if _, err := regexp.Compile("(?i)" + r.Pattern); err != nil {
+				return errors.NewInvalidInputError(fmt.Sprintf("golden case %s: rubric %q: %v", c.ID, r.Name, err))
+			}
+		}
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Scorers
+// ---------------------------------------------------------------------------
+
+// ScoreResult is one scorer's verdict on one case. Scores are in [0, 1];
+// a result that is not Applicable is left out of all averages.
+type ScoreResult struct {
+	Scorer     string   `json:"scorer"`
+	Score      float64  `json:"score"`
+	Applicable bool     `json:"applicable"`
+	Notes      []string `json:"notes,omitempty"`
+}
+
+// Scorer grades a backend output against a golden case.
+type Scorer interface {
+	Name() string
+	Score(c *GoldenCase, prompt *BuiltPrompt, output string) ScoreResult
+}
+
+// DefaultScorers returns the schema, citation, claim-element and rubric
+// scorers.
+func DefaultScorers() []Scorer {
+	return []Scorer{SchemaScorer{}, CitationCoverageScorer{}, ClaimElementRecallScorer{}, RubricScorer{}}
+}
+
+// SchemaScorer checks structured output. With an expected schema it scores
+// 1 when the output validates; without one it only requires valid JSON when
+// the case asked for structured output.
+type SchemaScorer struct{}
+
+func (SchemaScorer) Name() string { return "schema" }
+
+func (s SchemaScorer) Score(c *GoldenCase, _ *BuiltPrompt, output string) ScoreResult {
+	structured := c.Params != nil && c.Params.OutputFormat == OutputStructured
+	if c.Expect.Schema == nil && !structured {
+		return ScoreResult{Scorer: s.Name()}
+	}
+	res := ScoreResult{Scorer: s.Name(), Applicable: true}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(extractJSON(output)), &doc); err != nil {
+		res.Notes = []string{"output is not valid JSON: " + err.Error()}
+		return res
+	}
+	if c.Expect.Schema != nil {
+		validateSchema(c.Expect.Schema, doc, "$", &res.Notes)
+	}
+	if len(res.Notes) == 0 {
+		res.Score = 1
+	}
+	return res
+}
+
+// CitationCoverageScorer is the fraction of expected references the output
+// cites. References match ignoring case, spaces and punctuation, so
+// "US 10,000,001 B2" counts for "US10000001B2".
+type CitationCoverageScorer struct{}
+
+func (CitationCoverageScorer) Name() string { return "citation_coverage" }
+
+func (s CitationCoverageScorer) Score(c *GoldenCase, _ *BuiltPrompt, output string) ScoreResult {
+	return coverage(s.Name(), c.Expect.Citations, compactAlnum(output), compactAlnum, "missing citation")
+}
+
+// ClaimElementRecallScorer is the fraction of expected claim elements the
+// output addresses, matched case-insensitively with collapsed whitespace.
+type ClaimElementRecallScorer struct{}
+
+func (ClaimElementRecallScorer) Name() string { return "claim_element_recall" }
+
+func (s ClaimElementRecallScorer) Score(c *GoldenCase, _ *BuiltPrompt, output string) ScoreResult {
+	return coverage(s.Name(), c.Expect.ClaimElements, normalizeSpace(output), normalizeSpace, "missing claim element")
+}
+
+// RubricScorer is the weighted fraction of rubric checks that pass.
+type RubricScorer struct{}
+
+func (RubricScorer) Name() string { return "rubric" }
+
+func (s RubricScorer) Score(c *GoldenCase, _ *BuiltPrompt, output string) ScoreResult {
+	if len(c.Expect.Rubric) == 0 {
+		return ScoreResult{Scorer: s.Name()}
+	}
+	res := ScoreResult{Scorer: s.Name(), Applicable: true}
+	var passed, total float64
+	for _, r := range c.Expect.Rubric {
+		w := r.Weight
+		if w <= 0 {
+			w = 1
+		}
+		total += w
+		re, err := regexp.Compile("(?i)" + r.Pattern)
+		if err != nil {
+			res.Notes = append(res.Notes, fmt.Sprintf("%s: invalid pattern", r.Name))
+			continue
+		}
+		if re.MatchString(output) != r.Forbid {
+			passed += w
+		} else {
+			res.Notes = append(res.Notes, "failed rubric: "+r.Name)
+		}
+	}
+	res.Score = passed / total
+	return res
+}
+
+func coverage(name string, want []string, haystack string, norm func(string) string, note string) ScoreResult {
+	if len(want) == 0 {
+		return ScoreResult{Scorer: name}
+	}
+	res := ScoreResult{Scorer: name, Applicable: true}
+	hit := 0
+	for _, w := range want {
+		if n := norm(w); n != "" && strings.Contains(haystack, n) {
+			hit++
+		} else {
+			res.Notes = append(res.Notes, fmt.Sprintf("%s: %s", note, w))
+		}
+	}
+	res.Score = float64(hit) / float64(len(want))
+	return res
+}
+
+func compactAlnum(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+func normalizeSpace(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// extractJSON strips a markdown code fence around a JSON document, which
+// models add even when asked not to.
+func extractJSON(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "```") {
+		if i := strings.Index(s, "\n"); i >= 0 {
+			s = s[i+1:]
+		}
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	}
+	return strings.TrimSpace(s)
+}
+
+// validateSchema appends a note for every violation of the supported JSON
+// schema subset.
+func validateSchema(schema map[string]interface{}, v interface{}, path string, notes *[]string) {
+	if t, ok := schema["type"].(string); ok && !schemaTypeMatches(t, v) {
+		*notes = append(*notes, fmt.Sprintf("%s: expected %s", path, t))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*notes = append(*notes, fmt.Sprintf("%s: %v not in enum", path, v))
+		}
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if req, ok := schema["required"].([]interface{}); ok {
+			for _, r := range req {
+				if _, present := val[fmt.Sprint(r)]; !present {
+					*notes = append(*notes, fmt.Sprintf("%s: missing required field %v", path, r))
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			names := make([]string, 0, len(props))
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				sub, ok := props[name].(map[string]interface{})
+				if child, present := val[name]; ok && present {
+					validateSchema(sub, child, path+"."+name, notes)
+				}
+			}
+		}
+	case []interface{}:
+		if n, ok := schema["minItems"].(float64); ok && float64(len(val)) < n {
+			*notes = append(*notes, fmt.Sprintf("%s: expected at least %d items", path, int(n)))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), notes)
+			}
+		}
+	case string:
+		if n, ok := schema["minLength"].(float64); ok && float64(len([]rune(val))) < n {
+			*notes = append(*notes, fmt.Sprintf("%s: shorter than %d characters", path, int(n)))
+		}
+	}
+}
+
+func schemaTypeMatches(t string, v interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------
+// Evaluator
+// ---------------------------------------------------------------------------
+
+// EvalConfig configures a PromptEvaluator.
+type EvalConfig struct {
+	ModelName string `json:"model_name" yaml:"model_name"`
+	// InlineSystemPrompt sends the system prompt as the first chat message
+	// instead of a top-level "system" field, for OpenAI-style backends.
+	InlineSystemPrompt bool `json:"inline_system_prompt" yaml:"inline_system_prompt"`
+}
+
+// CaseResult is the outcome of one golden case.
+type CaseResult struct {
+	ID              string        `json:"id"`
+	Task            string        `json:"task"`
+	TemplateVersion string        `json:"template_version"`
+	Score           float64       `json:"score"`
+	Scores          []ScoreResult `json:"scores"`
+	Output          string        `json:"output,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	LatencyMs       int64         `json:"latency_ms"`
+}
+
+// EvalReport aggregates a golden-set run. Case scores are the mean of their
+// applicable scorer results; task and overall scores are means of case
+// scores. A case whose prompt or backend call failed scores 0.
+type EvalReport struct {
+	GoldenSet      string             `json:"golden_set"`
+	Model          string             `json:"model"`
+	PromptVersions map[string]string  `json:"prompt_versions"`
+	Overall        float64            `json:"overall"`
+	Scorers        map[string]float64 `json:"scorers"`
+	Tasks          map[string]float64 `json:"tasks"`
+	Cases          []*CaseResult      `json:"cases"`
+	StartedAt      time.Time          `json:"started_at"`
+	DurationMs     int64              `json:"duration_ms"`
+}
+
+// LoadEvalReport reads a report written by WriteFile, typically a baseline.
+func LoadEvalReport(path string) (*EvalReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r EvalReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decoding eval report %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// WriteFile stores the report as indented JSON.
+func (r *EvalReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding eval report: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// PromptEvaluator replays a golden set through a PromptManager and a model
+// backend and grades every answer.
+type PromptEvaluator struct {
+	pm      PromptManager
+	backend common.ModelBackend
+	config  EvalConfig
+	scorers []Scorer
+}
+
+// NewPromptEvaluator creates an evaluator. With no scorers it uses
+// DefaultScorers.
+func NewPromptEvaluator(pm PromptManager, backend common.ModelBackend, config EvalConfig, scorers ...Scorer) (*PromptEvaluator, error) {
+	if pm == nil {
+		return nil, errors.NewInvalidInputError("prompt manager is required")
+	}
+	if backend == nil {
+		return nil, errors.NewInvalidInputError("model backend is required")
+	}
+	if config.ModelName == "" {
+		config.ModelName = "prompt-eval"
+	}
+	if len(scorers) == 0 {
+		scorers = DefaultScorers()
+	}
+	return &PromptEvaluator{pm: pm, backend: backend, config: config, scorers: scorers}, nil
+}
+
+// Run evaluates every case in order. Per-case failures are recorded in the
+// report; only a cancelled context aborts the run.
+func (e *PromptEvaluator) Run(ctx context.Context, set *GoldenSet) (*EvalReport, error) {
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+	report := &EvalReport{
+		GoldenSet:      set.Name,
+		Model:          e.config.ModelName,
+		PromptVersions: make(map[string]string),
+		Scorers:        make(map[string]float64),
+		Tasks:          make(map[string]float64),
+		StartedAt:      time.Now().UTC(),
+	}
+
+	scorerSums := make(map[string]float64)
+	scorerCounts := make(map[string]int)
+	taskSums := make(map[string]float64)
+	taskCounts := make(map[string]int)
+	for _, c := range set.Cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res := e.runCase(ctx, c)
+		report.Cases = append(report.Cases, res)
+		if res.TemplateVersion != "" {
+			report.PromptVersions[res.Task] = res.TemplateVersion
+		}
+		for _, s := range res.Scores {
+			if s.Applicable {
+				scorerSums[s.Scorer] += s.Score
+				scorerCounts[s.Scorer]++
+			}
+		}
+		taskSums[res.Task] += res.Score
+		taskCounts[res.Task]++
+		report.Overall += res.Score
+	}
+
+	for name, sum := range scorerSums {
+		report.Scorers[name] = round4(sum / float64(scorerCounts[name]))
+	}
+	for task, sum := range taskSums {
+		report.Tasks[task] = round4(sum / float64(taskCounts[task]))
+	}
+	report.Overall = round4(report.Overall / float64(len(set.Cases)))
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report, nil
+}
+
+func (e *PromptEvaluator) runCase(ctx context.Context, c *GoldenCase) *CaseResult {
+	start := time.Now()
+	res := &CaseResult{ID: c.ID, Task: c.Task.String()}
+	defer func() { res.LatencyMs = time.Since(start).Milliseconds() }()
+
+	// BuildPrompt normalises params in place; keep the golden case pristine.
+	params := &PromptParams{}
+	if c.Params != nil {
+		cp := *c.Params
+		params = &cp
+	}
+	prompt, err := e.pm.BuildPrompt(ctx, c.Task, params)
+	if err != nil {
+		res.Error = "building prompt: " + err.Error()
+		return res
+	}
+	res.TemplateVersion = prompt.TemplateVersion
+
+	output, err := e.predict(ctx, c, prompt)
+	if err != nil {
+		res.Error = "backend: " + err.Error()
+		return res
+	}
+	res.Output = output
+
+	var sum float64
+	n := 0
+	for _, s := range e.scorers {
+		sr := s.Score(c, prompt, output)
+		sr.Score = round4(sr.Score)
+		res.Scores = append(res.Scores, sr)
+		if sr.Applicable {
+			sum += sr.Score
+			n++
+		}
+	}
+	if n > 0 {
+		res.Score = round4(sum / float64(n))
+	} else {
+		res.Score = 1
+	}
+	return res
+}
+
+func (e *PromptEvaluator) predict(ctx context.Context, c *GoldenCase, prompt *BuiltPrompt) (string, error) {
+	input := map[string]interface{}{}
+	if e.config.InlineSystemPrompt {
+		input["messages"] = prompt.Messages
+	} else {
+		input["system"] = prompt.SystemPrompt
+		input["messages"] = []Message{{Role: "user", Content: prompt.UserPrompt}}
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	resp, err := e.backend.Predict(ctx, &common.PredictRequest{
+		ModelName:   e.config.ModelName,
+		InputData:   data,
+		InputFormat: common.FormatJSON,
+		Metadata: map[string]string{
+			"task":             c.Task.String(),
+			"eval_case":        c.ID,
+			"template_version": prompt.TemplateVersion,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, key := range []string{"content", "text", "output"} {
+		if v, ok := resp.Outputs[key]; ok {
+			return string(v), nil
+		}
+	}
+	return "", fmt.Errorf("backend returned no content")
+}
+
+func round4(f float64) float64 {
+	return math.Round(f*1e4) / 1e4
+}
+
+// ---------------------------------------------------------------------------
+// Regression gate
+// ---------------------------------------------------------------------------
+
+// EvalRegression is a score that dropped by more than the tolerance.
+type EvalRegression struct {
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// Delta is the signed change from baseline to current.
+func (r EvalRegression) Delta() float64 {
+	return r.Current - r.Baseline
+}
+
+// CompareEvalReports lists every overall, per-scorer, per-task and per-case
+// score in current that fell more than tolerance below baseline. Metrics
+// missing from either report are skipped, so adding cases never fails the
+// gate on its own.
+func CompareEvalReports(baseline, current *EvalReport, tolerance float64) []EvalRegression {
+	var out []EvalRegression
+	check := func(metric string, base, cur float64) {
+		if cur < base-tolerance-1e-9 {
+			out = append(out, EvalRegression{Metric: metric, Baseline: base, Current: cur})
+		}
+	}
+	check("overall", baseline.Overall, current.Overall)
+	for _, name := range sortedKeys(baseline.Scorers) {
+		if cur, ok := current.Scorers[name]; ok {
+			check("scorer:"+name, baseline.Scorers[name], cur)
+		}
+	}
+	for _, task := range sortedKeys(baseline.Tasks) {
+		if cur, ok := current.Tasks[task]; ok {
+			check("task:"+task, baseline.Tasks[task], cur)
+		}
+	}
+	currentCases := make(map[string]*CaseResult, len(current.Cases))
+	for _, c := range current.Cases {
+		currentCases[c.ID] = c
+	}
+	for _, base := range baseline.Cases {
+		if cur, ok := currentCases[base.ID]; ok {
+			check("case:"+base.ID, base.Score, cur.Score)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ---------------------------------------------------------------------------
+// Offline mock backend
+// ---------------------------------------------------------------------------
+
+// mockEvalBackend answers from the prompt alone, deterministically. It cites
+// the patents and prior art the prompt lists, echoes claim features, and
+// picks up a handful of analysis cues from the instructions, so a prompt
+// edit that drops evidence or guidance lowers the offline scores the same
+// way it would degrade a real model's answer.
+type mockEvalBackend struct{}
+
+// NewMockEvalBackend returns the offline backend used by `keyip prompt eval`
+// when no LLM is configured.
+func NewMockEvalBackend() common.ModelBackend {
+	return mockEvalBackend{}
+}
+
+var (
+	mockReferenceRe = regexp.MustCompile(`(?m)^### (?:Patent|Prior Art) \d+: (.+)$`)
+	mockFeaturesRe  = regexp.MustCompile(`(?m)^\s*Features: (.+)$`)
+	mockClaimRe     = regexp.MustCompile(`(?m)^Claim (\d+) \(`)
+
+	// mockCues maps instruction phrases to the sentence a model following
+	// them would write.
+	mockCues = []struct{ phrase, sentence string }{
+		{"design-around", "Consider design-around options that avoid the blocking claim elements."},
+		{"doctrine of equivalents", "Each element was assessed for literal infringement and under the doctrine of equivalents."},
+		{"markush", "Markush group coverage of the target structure was checked."},
+		{"claim construction", "Claim terms were construed before comparison."},
+		{"white space", "White spaces in the filing landscape are highlighted."},
+		{"inventive step", "Novelty and inventive step were assessed against the closest prior art."},
+	}
+)
+
+func (mockEvalBackend) Predict(ctx context.Context, req *common.PredictRequest) (*common.PredictResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prompt := string(req.InputData)
+	var input struct {
+		System   string    `json:"system"`
+		Messages []Message `json:"messages"`
+	}
+	if req.InputFormat == common.FormatJSON && json.Unmarshal(req.InputData, &input) == nil {
+		parts := []string{input.System}
+		for _, m := range input.Messages {
+			parts = append(parts, m.Content)
+		}
+		prompt = strings.Join(parts, "\n")
+	}
+	content := mockAnswer(prompt)
+	return &common.PredictResponse{
+		ModelName: req.ModelName,
+		Outputs:   map[string][]byte{"content": []byte(content)},
+		Metadata: map[string]string{
+			"input_tokens":  fmt.Sprint(len(prompt) / 4),
+			"output_tokens": fmt.Sprint(len(content) / 4),
+		},
+	}, nil
+}
+
+func (b mockEvalBackend) PredictStream(ctx context.Context, req *common.PredictRequest) (<-chan *common.PredictResponse, error) {
+	resp, err := b.Predict(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *common.PredictResponse, 1)
+	ch <- resp
+	close(ch)
+	return ch, nil
+}
+
+func (mockEvalBackend) Healthy(context.Context) error { return nil }
+
+func (mockEvalBackend) Close() error { return nil }
+
+func mockAnswer(prompt string) string {
+	lower := strings.ToLower(prompt)
+	var refs, features, claims, recs []string
+	for _, m := range mockReferenceRe.FindAllStringSubmatch(prompt, -1) {
+		refs = append(refs, strings.TrimSpace(m[1]))
+	}
+	for _, m := range mockFeaturesRe.FindAllStringSubmatch(prompt, -1) {
+		for _, f := range strings.Split(m[1], ";") {
+			if f = strings.TrimSpace(f); f != "" {
+				features = append(features, f)
+			}
+		}
+	}
+	for _, m := range mockClaimRe.FindAllStringSubmatch(prompt, -1) {
+		claims = append(claims, m[1])
+	}
+	for _, cue := range mockCues {
+		if strings.Contains(lower, cue.phrase) {
+			recs = append(recs, cue.sentence)
+		}
+	}
+	riskLevel := ""
+	if strings.Contains(prompt, "HIGH/MEDIUM/LOW") {
+		riskLevel = "LOW"
+		if len(refs) > 0 {
+			riskLevel = "MEDIUM"
+		}
+	}
+	summary := fmt.Sprintf("Reviewed %d references and %d claims.", len(refs), len(claims))
+
+	if strings.Contains(prompt, "structured JSON format") {
+		type finding struct {
+			Reference string `json:"reference"`
+			Analysis  string `json:"analysis"`
+		}
+		doc := map[string]interface{}{
+			"summary":         summary,
+			"findings":        []finding{},
+			"claim_elements":  append([]string{}, features...),
+			"recommendations": append([]string{}, recs...),
+		}
+		if riskLevel != "" {
+			doc["risk_level"] = riskLevel
+		}
+		var findings []finding
+		for _, r := range refs {
+			findings = append(findings, finding{Reference: r, Analysis: "Relevant to the analysed claims."})
+		}
+		if findings != nil {
+			doc["findings"] = findings
+		}
+		out, _ := json.MarshalIndent(doc, "", "  ")
+		return string(out)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary\n%s\n", summary)
+	if riskLevel != "" {
+		fmt.Fprintf(&b, "Overall risk level: %s\n", riskLevel)
+	}
+	b.WriteString("\n## Findings\n")
+	for _, r := range refs {
+		fmt.Fprintf(&b, "- %s: relevant to the analysed claims.\n", r)
+	}
+	if len(features) > 0 {
+		b.WriteString("\n## Claim Elements\n")
+		for _, f := range features {
+			fmt.Fprintf(&b, "- %s\n", f)
+		}
+	}
+	if len(recs) > 0 {
+		b.WriteString("\n## Recommendations\n")
+		for _, r := range recs {
+			fmt.Fprintf(&b, "- %s\n", r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,233 @@
+package strategy_gpt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+)
+
+const shippedGoldenSet = "../../../configs/prompts/golden_set.json"
+
+func scoreOf(t *testing.T, s Scorer, c *GoldenCase, output string) ScoreResult {
+	t.Helper()
+	return s.Score(c, &BuiltPrompt{}, output)
+}
+
+func TestSchemaScorer(t *testing.T) {
+	c := &GoldenCase{
+		Params: &PromptParams{OutputFormat: OutputStructured},
+		Expect: GoldenExpectation{Schema: map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"risk_level", "findings"},
+			"properties": map[string]interface{}{
+				"risk_level": map[string]interface{}{"type": "string", "enum": []interface{}{"HIGH", "LOW"}},
+				"findings":   map[string]interface{}{"type": "array", "minItems": 1.0},
+			},
+		}},
+	}
+	cases := map[string]float64{
+		"```json\n{\"risk_level\": \"HIGH\", \"findings\": [1]}\n```": 1,
+		`{"risk_level": "MAYBE", "findings": [1]}`:                    0,
+		`{"risk_level": "LOW", "findings": []}`:                       0,
+		`{"findings": [1]}`:                                           0,
+		`not json`:                                                    0,
+	}
+	for out, want := range cases {
+		if got := scoreOf(t, SchemaScorer{}, c, out); got.Score != want || !got.Applicable {
+			t.Errorf("%q: got %+v, want %v", out, got, want)
+		}
+	}
+
+	// No schema and narrative output: nothing to check.
+	narrative := &GoldenCase{Params: &PromptParams{OutputFormat: OutputNarrative}}
+	if got := scoreOf(t, SchemaScorer{}, narrative, "prose"); got.Applicable {
+		t.Errorf("expected not applicable, got %+v", got)
+	}
+}
+
+func TestCoverageAndRubricScorers(t *testing.T) {
+	c := &GoldenCase{Expect: GoldenExpectation{
+		Citations:     []string{"US10000001B2", "EP 3500001 A1"},
+		ClaimElements: []string{"Phenylene  bridge", "triazine acceptor"},
+		Rubric: []RubricCheck{
+			{Name: "risk", Pattern: `risk level: (high|low)`, Weight: 3},
+			{Name: "no guarantee", Pattern: `guarantee`, Forbid: true},
+		},
+	}}
+	output := "US 10,000,001 B2 discloses a phenylene bridge. Risk level: HIGH. We guarantee nothing."
+
+	if got := scoreOf(t, CitationCoverageScorer{}, c, output); got.Score != 0.5 || len(got.Notes) != 1 {
+		t.Errorf("citation coverage: %+v", got)
+	}
+	if got := scoreOf(t, ClaimElementRecallScorer{}, c, output); got.Score != 0.5 {
+		t.Errorf("claim element recall: %+v", got)
+	}
+	if got := scoreOf(t, RubricScorer{}, c, output); got.Score != 0.75 || got.Notes[0] != "failed rubric: no guarantee" {
+		t.Errorf("rubric: %+v", got)
+	}
+	if got := scoreOf(t, RubricScorer{}, &GoldenCase{}, output); got.Applicable {
+		t.Errorf("rubric without checks should not apply: %+v", got)
+	}
+}
+
+func TestLoadGoldenSet(t *testing.T) {
+	set, err := LoadGoldenSet(shippedGoldenSet)
+	if err != nil {
+		t.Fatalf("LoadGoldenSet: %v", err)
+	}
+	if len(set.Cases) < 5 || set.Cases[0].Task != TaskFTO {
+		t.Errorf("unexpected golden set: %d cases", len(set.Cases))
+	}
+
+	bad := &GoldenSet{Cases: []*GoldenCase{{ID: "a", Task: TaskFTO}, {ID: "a", Task: TaskFTO}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected duplicate id error")
+	}
+	bad = &GoldenSet{Cases: []*GoldenCase{{ID: "a", Task: TaskFTO, Expect: GoldenExpectation{Rubric: []RubricCheck{{Pattern: "("}}}}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected invalid rubric error")
+	}
+	if _, err := LoadGoldenSet(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func runShippedEval(t *testing.T, registry PromptRegistry) *EvalReport {
+	t.Helper()
+	set, err := LoadGoldenSet(shippedGoldenSet)
+	if err != nil {
+		t.Fatalf("LoadGoldenSet: %v", err)
+	}
+	pm, err := NewPromptManagerWithRegistry(nil, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := NewPromptEvaluator(pm, NewMockEvalBackend(), EvalConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := ev.Run(context.Background(), set)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return report
+}
+
+func TestPromptEvaluator_BuiltinsPassShippedSet(t *testing.T) {
+	report := runShippedEval(t, NewPromptRegistry())
+	for _, c := range report.Cases {
+		if c.Score != 1 {
+			t.Errorf("case %s scored %v: %+v %s", c.ID, c.Score, c.Scores, c.Error)
+		}
+	}
+	if report.Overall != 1 || report.PromptVersions["FTO"] != "FTO@v1" {
+		t.Errorf("unexpected report: overall=%v versions=%v", report.Overall, report.PromptVersions)
+	}
+	for _, name := range []string{"schema", "citation_coverage", "claim_element_recall", "rubric"} {
+		if _, ok := report.Scorers[name]; !ok {
+			t.Errorf("scorer %s missing from report", name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadEvalReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regs := CompareEvalReports(loaded, report, 0); len(regs) != 0 {
+		t.Errorf("report should not regress against itself: %+v", regs)
+	}
+}
+
+func TestPromptEvaluator_DetectsPromptRegression(t *testing.T) {
+	baseline := runShippedEval(t, NewPromptRegistry())
+
+	// Dropping the Markush and design-around guidance from the FTO prompt
+	// must show up as a regression on the FTO cases.
+	registry := NewPromptRegistry()
+	if _, err := registry.Publish(TaskFTO, "You are a patent attorney. Answer briefly.", "trimmed", "test"); err != nil {
+		t.Fatal(err)
+	}
+	current := runShippedEval(t, registry)
+	if current.PromptVersions["FTO"] != "FTO@v2" {
+		t.Errorf("prompt version not recorded: %v", current.PromptVersions)
+	}
+
+	regs := CompareEvalReports(baseline, current, 0.01)
+	metrics := make(map[string]bool)
+	for _, r := range regs {
+		metrics[r.Metric] = true
+		if r.Delta() >= 0 {
+			t.Errorf("regression with non-negative delta: %+v", r)
+		}
+	}
+	for _, want := range []string{"overall", "scorer:rubric", "task:FTO", "case:fto-oled-host-structured"} {
+		if !metrics[want] {
+			t.Errorf("missing regression %s in %+v", want, regs)
+		}
+	}
+	if metrics["task:InfringementRisk"] {
+		t.Error("unrelated task flagged as regressed")
+	}
+	if regs := CompareEvalReports(baseline, current, 1); len(regs) != 0 {
+		t.Errorf("tolerance of 1 should accept everything: %+v", regs)
+	}
+}
+
+type failingBackend struct{ common.ModelBackend }
+
+func (failingBackend) Predict(context.Context, *common.PredictRequest) (*common.PredictResponse, error) {
+	return nil, fmt.Errorf("upstream unavailable")
+}
+
+func TestPromptEvaluator_BackendErrorsScoreZero(t *testing.T) {
+	pm := newTestPromptManager(t)
+	ev, err := NewPromptEvaluator(pm, failingBackend{}, EvalConfig{InlineSystemPrompt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &GoldenSet{Cases: []*GoldenCase{{ID: "a", Task: TaskFTO, Params: &PromptParams{UserQuery: "q"}}}}
+	report, err := ev.Run(context.Background(), set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Overall != 0 || !strings.Contains(report.Cases[0].Error, "upstream unavailable") {
+		t.Errorf("unexpected report: %+v", report.Cases[0])
+	}
+	if set.Cases[0].Params.Language != "" {
+		t.Error("golden case params were mutated")
+	}
+
+	if _, err := NewPromptEvaluator(nil, failingBackend{}, EvalConfig{}); err == nil {
+		t.Error("expected error for nil prompt manager")
+	}
+}
+
+func TestMockEvalBackend(t *testing.T) {
+	b := NewMockEvalBackend()
+	resp, err := b.Predict(context.Background(), &common.PredictRequest{
+		InputFormat: common.FormatText,
+		InputData: []byte("Assess HIGH/MEDIUM/LOW risk and design-around options.\n" +
+			"### Patent 1: US1\nClaim 1 (independent, scope=0.50):\n  Features: alpha; beta\n" +
+			"Please provide your analysis in a structured JSON format."),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(resp.Outputs["content"])
+	for _, want := range []string{`"risk_level": "MEDIUM"`, `"reference": "US1"`, `"alpha"`, "design-around"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("mock output missing %s:\n%s", want, out)
+		}
+	}
+	if err := b.Healthy(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,441 @@
+package strategy_gpt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// PromptVersion
+// ---------------------------------------------------------------------------
+
+// PromptVersion is one immutable revision of the system prompt for a task.
+// Versions are numbered from 1 per task; publishing never rewrites an
+// existing version, so a report can always be traced back to the exact
+// prompt text that produced it.
+type PromptVersion struct {
+	Task        AnalysisTask `json:"task"`
+	Version     int          `json:"version"`
+	Body        string       `json:"body"`
+	Digest      string       `json:"digest"`
+	Description string       `json:"description,omitempty"`
+	Author      string       `json:"author,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// Label identifies the version in reports and BuiltPrompt.TemplateVersion,
+// e.g. "FTO@v3".
+func (v *PromptVersion) Label() string {
+	return fmt.Sprintf("%s@v%d", v.Task, v.Version)
+}
+
+func promptDigest(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// ---------------------------------------------------------------------------
+// PromptRegistry interface
+// ---------------------------------------------------------------------------
+
+// PromptRegistry stores versioned system prompts per AnalysisTask and tracks
+// which version is active. Activating an older version is a rollback.
+type PromptRegistry interface {
+	// Publish stores body as the next version of the task's prompt and makes
+	// it active. Publishing the body that is already active is a no-op that
+	// returns the active version.
+	Publish(task AnalysisTask, body, description, author string) (*PromptVersion, error)
+	Get(task AnalysisTask, version int) (*PromptVersion, error)
+	Active(task AnalysisTask) (*PromptVersion, error)
+	Activate(task AnalysisTask, version int) error
+	History(task AnalysisTask) []*PromptVersion
+	Tasks() []AnalysisTask
+}
+
+// ---------------------------------------------------------------------------
+// promptRegistryImpl
+// ---------------------------------------------------------------------------
+
+type promptRegistryImpl struct {
+	versions map[AnalysisTask][]*PromptVersion
+	active   map[AnalysisTask]int
+	path     string // empty for an in-memory registry
+	funcMap  template.FuncMap
+	mu       sync.RWMutex
+}
+
+// registryFile is the on-disk layout of a persisted registry.
+type registryFile struct {
+	Versions []*PromptVersion `json:"versions"`
+	Active   []registryActive `json:"active"`
+}
+
+type registryActive struct {
+	Task    AnalysisTask `json:"task"`
+	Version int          `json:"version"`
+}
+
+// NewPromptRegistry returns an in-memory registry seeded with the built-in
+// templates as version 1 of each task.
+func NewPromptRegistry() PromptRegistry {
+	r := newPromptRegistry("")
+	r.seedBuiltins()
+	return r
+}
+
+// OpenPromptRegistry loads a registry persisted at path, creating it from
+// the built-in templates if the file does not exist yet. Every Publish and
+// Activate rewrites the file atomically.
+func OpenPromptRegistry(path string) (PromptRegistry, error) {
+	if path == "" {
+		return nil, errors.NewInvalidInputError("prompt registry path is required")
+	}
+	r := newPromptRegistry(path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		r.seedBuiltins()
+		if err := r.save(); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading prompt registry %s: %w", path, err)
+	}
+
+	var f registryFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decoding prompt registry %s: %w", path, err)
+	}
+	for _, v := range f.Versions {
+		if v == nil || !v.Task.IsValid() {
+			return nil, fmt.Errorf("prompt registry %s: invalid version entry", path)
+		}
+		if want := len(r.versions[v.Task]) + 1; v.Version != want {
+			return nil, fmt.Errorf("prompt registry %s: %s versions out of order (got v%d, want v%d)", path, v.Task, v.Version, want)
+		}
+		if v.Digest != promptDigest(v.Body) {
+			return nil, fmt.Errorf("prompt registry %s: %s digest mismatch", path, v.Label())
+		}
+		r.versions[v.Task] = append(r.versions[v.Task], v)
+	}
+	for _, a := range f.Active {
+		if a.Version < 1 || a.Version > len(r.versions[a.Task]) {
+			return nil, fmt.Errorf("prompt registry %s: active version %s@v%d does not exist", path, a.Task, a.Version)
+		}
+		r.active[a.Task] = a.Version
+	}
+	return r, nil
+}
+
+func newPromptRegistry(path string) *promptRegistryImpl {
+	return &promptRegistryImpl{
+		versions: make(map[AnalysisTask][]*PromptVersion),
+		active:   make(map[AnalysisTask]int),
+		path:     path,
+		funcMap:  defaultFuncMap(),
+	}
+}
+
+func (r *promptRegistryImpl) seedBuiltins() {
+	for task := range analysisTaskNames {
+		body, ok := builtinTemplates[systemTemplateKey(task)]
+		if !ok {
+			continue
+		}
+		r.versions[task] = []*PromptVersion{{
+			Task:        task,
+			Version:     1,
+			Body:        body,
+			Digest:      promptDigest(body),
+			Description: "built-in",
+			Author:      "system",
+		}}
+		r.active[task] = 1
+	}
+}
+
+func (r *promptRegistryImpl) Publish(task AnalysisTask, body, description, author string) (*PromptVersion, error) {
+	if !task.IsValid() {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("unknown analysis task: %d", int(task)))
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, errors.NewInvalidInputError("prompt body is required")
+	}
+	if _, err := template.New(systemTemplateKey(task)).Funcs(r.funcMap).Parse(body); err != nil {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("parsing prompt for %s: %v", task, err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	digest := promptDigest(body)
+	history := r.versions[task]
+	if n := r.active[task]; n > 0 && history[n-1].Digest == digest {
+		cp := *history[n-1]
+		return &cp, nil
+	}
+
+	v := &PromptVersion{
+		Task:        task,
+		Version:     len(history) + 1,
+		Body:        body,
+		Digest:      digest,
+		Description: description,
+		Author:      author,
+		CreatedAt:   time.Now().UTC(),
+	}
+	prevActive := r.active[task]
+	r.versions[task] = append(history, v)
+	r.active[task] = v.Version
+	if err := r.save(); err != nil {
+		r.versions[task] = history
+		r.active[task] = prevActive
+		if prevActive == 0 {
+			delete(r.active, task)
+		}
+		return nil, err
+	}
+	cp := *v
+	return &cp, nil
+}
+
+func (r *promptRegistryImpl) Get(task AnalysisTask, version int) (*PromptVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	history := r.versions[task]
+	if version < 1 || version > len(history) {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Sprintf("prompt %s@v%d not found", task, version))
+	}
+	cp := *history[version-1]
+	return &cp, nil
+}
+
+func (r *promptRegistryImpl) Active(task AnalysisTask) (*PromptVersion, error) {
+	r.mu.RLock()
+	n := r.active[task]
+	r.mu.RUnlock()
+	if n == 0 {
+		return nil, errors.New(errors.ErrCodeNotFound, fmt.Sprintf("no active prompt for task %s", task))
+	}
+	return r.Get(task, n)
+}
+
+func (r *promptRegistryImpl) Activate(task AnalysisTask, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version < 1 || version > len(r.versions[task]) {
+		return errors.New(errors.ErrCodeNotFound, fmt.Sprintf("prompt %s@v%d not found", task, version))
+	}
+	prev := r.active[task]
+	r.active[task] = version
+	if err := r.save(); err != nil {
+		r.active[task] = prev
+		return err
+	}
+	return nil
+}
+
+func (r *promptRegistryImpl) History(task AnalysisTask) []*PromptVersion {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*PromptVersion, len(r.versions[task]))
+	for i, v := range r.versions[task] {
+		cp := *v
+		out[i] = &cp
+	}
+	return out
+}
+
+func (r *promptRegistryImpl) Tasks() []AnalysisTask {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tasksLocked()
+}
+
+// save writes the registry to disk; callers hold the write lock.
+func (r *promptRegistryImpl) save() error {
+	if r.path == "" {
+		return nil
+	}
+	var f registryFile
+	for _, task := range r.tasksLocked() {
+		f.Versions = append(f.Versions, r.versions[task]...)
+		if n := r.active[task]; n > 0 {
+			f.Active = append(f.Active, registryActive{Task: task, Version: n})
+		}
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding prompt registry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("creating prompt registry directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing prompt registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing prompt registry: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing prompt registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing prompt registry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("writing prompt registry: %w", err)
+	}
+	return nil
+}
+
+func (r *promptRegistryImpl) tasksLocked() []AnalysisTask {
+	out := make([]AnalysisTask, 0, len(r.versions))
+	for task := range r.versions {
+		out = append(out, task)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Registry-backed PromptManager
+// ---------------------------------------------------------------------------
+
+// NewPromptManagerWithRegistry creates a PromptManager whose system prompts
+// come from the registry's active versions. Activations take effect on the
+// next BuildPrompt, and BuiltPrompt.TemplateVersion carries the version
+// label. Tasks without a registry entry fall back to the built-in templates.
+func NewPromptManagerWithRegistry(config *PromptManagerConfig, registry PromptRegistry) (PromptManager, error) {
+	if registry == nil {
+		return nil, errors.NewInvalidInputError("prompt registry is required")
+	}
+	pm, err := NewPromptManager(config)
+	if err != nil {
+		return nil, err
+	}
+	impl := pm.(*promptManagerImpl)
+	impl.registry = registry
+	return impl, nil
+}
+
+// ---------------------------------------------------------------------------
+// Diff
+// ---------------------------------------------------------------------------
+
+// DiffPromptVersions renders a line-based unified diff between two prompt
+// versions with three lines of context around each change.
+func DiffPromptVersions(from, to *PromptVersion) string {
+	a := strings.Split(from.Body, "\n")
+	b := strings.Split(to.Body, "\n")
+
+	// Longest common subsequence table, filled from the end.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+		ai   int // 1-based line in a for ' ' and '-'
+		bj   int // 1-based line in b for ' ' and '+'
+	}
+	var ops []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, line{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, line{'-', a[i], i + 1, j})
+			i++
+		default:
+			ops = append(ops, line{'+', b[j], i, j + 1})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from.Label(), to.Label())
+	const diffContext = 3
+	for k := 0; k < len(ops); {
+		if ops[k].op == ' ' {
+			k++
+			continue
+		}
+		start := max(0, k-diffContext)
+		end := k
+		for end < len(ops) {
+			if ops[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].op == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContext {
+				end = min(len(ops), end+diffContext)
+				break
+			}
+			end = run
+		}
+
+		var aStart, aLen, bStart, bLen int
+		for _, l := range ops[start:end] {
+			if l.op != '+' {
+				if aLen == 0 {
+					aStart = l.ai
+				}
+				aLen++
+			}
+			if l.op != '-' {
+				if bLen == 0 {
+					bStart = l.bj
+				}
+				bLen++
+			}
+		}
+		if aLen == 0 {
+			aStart = ops[start].ai
+		}
+		if bLen == 0 {
+			bStart = ops[start].bj
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen)
+		for _, l := range ops[start:end] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
@@ -0,0 +1,189 @@
+package strategy_gpt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func TestPromptRegistry_SeedsBuiltins(t *testing.T) {
+	r := NewPromptRegistry()
+	v, err := r.Active(TaskFTO)
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+	if v.Version != 1 || v.Body != builtinTemplates["system_FTO"] || v.Label() != "FTO@v1" {
+		t.Errorf("unexpected seed version: %+v", v)
+	}
+	if _, err := r.Active(TaskValidity); !errors.IsNotFound(err) {
+		t.Errorf("Validity has no built-in template, want not found, got %v", err)
+	}
+	tasks := r.Tasks()
+	if len(tasks) == 0 || tasks[0] != TaskFTO {
+		t.Errorf("Tasks should be sorted and start with FTO, got %v", tasks)
+	}
+}
+
+func TestPromptRegistry_PublishActivate(t *testing.T) {
+	r := NewPromptRegistry()
+	v2, err := r.Publish(TaskFTO, "You are an FTO analyst.", "shorter prompt", "alice")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if v2.Version != 2 || v2.Author != "alice" || v2.Digest != promptDigest(v2.Body) {
+		t.Errorf("unexpected version: %+v", v2)
+	}
+
+	// Republishing the active body is a no-op.
+	again, err := r.Publish(TaskFTO, "You are an FTO analyst.", "dup", "bob")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if again.Version != 2 || len(r.History(TaskFTO)) != 2 {
+		t.Errorf("duplicate publish created a version: %+v", again)
+	}
+
+	// Returned versions are copies.
+	v2.Body = "tampered"
+	if got, _ := r.Get(TaskFTO, 2); got.Body != "You are an FTO analyst." {
+		t.Errorf("registry version was mutated: %q", got.Body)
+	}
+
+	if err := r.Activate(TaskFTO, 1); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	if v, _ := r.Active(TaskFTO); v.Version != 1 {
+		t.Errorf("rollback failed, active = v%d", v.Version)
+	}
+	if err := r.Activate(TaskFTO, 9); !errors.IsNotFound(err) {
+		t.Errorf("want not found, got %v", err)
+	}
+
+	for name, body := range map[string]string{"empty": "  ", "bad template": "{{ .Broken "} {
+		if _, err := r.Publish(TaskFTO, body, "", ""); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := r.Publish(AnalysisTask(99), "x", "", ""); err == nil {
+		t.Error("expected error for unknown task")
+	}
+
+	// A task without a built-in can be published from scratch.
+	v, err := r.Publish(TaskValidity, "You assess patent validity.", "", "")
+	if err != nil || v.Version != 1 {
+		t.Fatalf("Publish Validity: %v %+v", err, v)
+	}
+}
+
+func TestOpenPromptRegistry_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts", "registry.json")
+	r, err := OpenPromptRegistry(path)
+	if err != nil {
+		t.Fatalf("OpenPromptRegistry: %v", err)
+	}
+	if _, err := r.Publish(TaskValuation, "Value the patent.", "terse", "carol"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := r.Activate(TaskValuation, 1); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+
+	reopened, err := OpenPromptRegistry(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	history := reopened.History(TaskValuation)
+	if len(history) != 2 || history[1].Author != "carol" {
+		t.Fatalf("history not persisted: %+v", history)
+	}
+	if v, _ := reopened.Active(TaskValuation); v.Version != 1 {
+		t.Errorf("active version not persisted, got v%d", v.Version)
+	}
+
+	// Hand edits to a stored body are detected.
+	data, _ := os.ReadFile(path)
+	tampered := strings.Replace(string(data), "Value the patent.", "Value it.", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenPromptRegistry(path); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("expected digest mismatch, got %v", err)
+	}
+}
+
+func TestPromptManagerWithRegistry(t *testing.T) {
+	r := NewPromptRegistry()
+	pm, err := NewPromptManagerWithRegistry(nil, r)
+	if err != nil {
+		t.Fatalf("NewPromptManagerWithRegistry: %v", err)
+	}
+	ctx := context.Background()
+
+	built, err := pm.BuildPrompt(ctx, TaskFTO, &PromptParams{UserQuery: "q"})
+	if err != nil {
+		t.Fatalf("BuildPrompt: %v", err)
+	}
+	if built.TemplateVersion != "FTO@v1" {
+		t.Errorf("TemplateVersion = %q", built.TemplateVersion)
+	}
+
+	if _, err := r.Publish(TaskFTO, "Custom FTO prompt.", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	built, err = pm.BuildPrompt(ctx, TaskFTO, &PromptParams{UserQuery: "q"})
+	if err != nil {
+		t.Fatalf("BuildPrompt: %v", err)
+	}
+	if built.SystemPrompt != "Custom FTO prompt." || built.TemplateVersion != "FTO@v2" {
+		t.Errorf("activation not picked up: %q %q", built.SystemPrompt, built.TemplateVersion)
+	}
+
+	for _, info := range pm.ListTemplates() {
+		if info.Name == "system_FTO" && info.Version != "FTO@v2" {
+			t.Errorf("ListTemplates version = %q", info.Version)
+		}
+	}
+
+	// The plain manager keeps the configured version string.
+	plain := newTestPromptManager(t)
+	built, _ = plain.BuildPrompt(ctx, TaskFTO, &PromptParams{UserQuery: "q"})
+	if built.TemplateVersion != "v1.0" {
+		t.Errorf("default TemplateVersion = %q", built.TemplateVersion)
+	}
+
+	if _, err := NewPromptManagerWithRegistry(nil, nil); err == nil {
+		t.Error("expected error for nil registry")
+	}
+}
+
+func TestDiffPromptVersions(t *testing.T) {
+	from := &PromptVersion{Task: TaskFTO, Version: 1, Body: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj"}
+	to := &PromptVersion{Task: TaskFTO, Version: 2, Body: "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"}
+	want := strings.Join([]string{
+		"--- FTO@v1",
+		"+++ FTO@v2",
+		"@@ -1,5 +1,5 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		" e",
+		"@@ -8,3 +8,4 @@",
+		" h",
+		" i",
+		" j",
+		"+k",
+		"",
+	}, "\n")
+	if got := DiffPromptVersions(from, to); got != want {
+		t.Errorf("diff mismatch:\n%s\nwant:\n%s", got, want)
+	}
+	if got := DiffPromptVersions(from, from); got != "--- FTO@v1\n+++ FTO@v1\n" {
+		t.Errorf("identical bodies should produce an empty diff, got %q", got)
+	}
+}
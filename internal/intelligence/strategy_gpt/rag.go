@@ -3,13 +3,12 @@ package strategy_gpt
 import (
 	"context"
 	"fmt"
-	"math"
 	"sort"
 	"strings"
 	"sync"
 	"time"
-	"unicode/utf8"
 
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
 	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 )
 
@@ -896,21 +895,9 @@ func formatSourceAnnotation(chunk *RAGChunk) string {
 	}
 }
 
-// estimateTokens provides a rough token count. English ≈ 1 token per 4 chars;
-// CJK ≈ 1 token per 1.5 chars. We use a blended heuristic.
+// estimateTokens provides a rough token count; see common.EstimateTokenCount.
 func estimateTokens(text string) int {
-	if text == "" {
-		return 0
-	}
-	runeCount := utf8.RuneCountInString(text)
-	byteCount := len(text)
-
-	// If average bytes-per-rune > 2, assume CJK-heavy.
-	if runeCount > 0 && float64(byteCount)/float64(runeCount) > 2.0 {
-		return int(math.Ceil(float64(runeCount) / 1.5))
-	}
-	// English / Latin approximation.
-	return int(math.Ceil(float64(byteCount) / 4.0))
+	return common.EstimateTokenCount(text)
 }
 
 func truncateTextToTokens(text string, maxTokens int) string {
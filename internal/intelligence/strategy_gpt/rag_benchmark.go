@@ -0,0 +1,287 @@
+package strategy_gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Benchmark set
+// ---------------------------------------------------------------------------
+
+// RelevantPassage identifies a passage that answers a query. A retrieved
+// chunk matches when it belongs to DocumentID and contains the Contains
+// text (case- and whitespace-insensitive), so labels stay valid whatever
+// chunker produced the chunk.
+type RelevantPassage struct {
+	DocumentID string `json:"document_id"`
+	Contains   string `json:"contains"`
+}
+
+// RetrievalCase is one labelled query.
+type RetrievalCase struct {
+	ID       string             `json:"id"`
+	Query    string             `json:"query"`
+	Filters  *RAGFilters        `json:"filters,omitempty"`
+	Relevant []*RelevantPassage `json:"relevant"`
+}
+
+// RetrievalBenchmark is a corpus of documents and the queries labelled
+// against it. Documents are re-indexed for every variant so that chunking
+// settings can be compared.
+type RetrievalBenchmark struct {
+	Name      string           `json:"name"`
+	Documents []*Document      `json:"documents"`
+	Cases     []*RetrievalCase `json:"cases"`
+}
+
+// LoadRetrievalBenchmark reads and validates a benchmark from a JSON file.
+func LoadRetrievalBenchmark(path string) (*RetrievalBenchmark, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading retrieval benchmark %s: %w", path, err)
+	}
+	var b RetrievalBenchmark
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("decoding retrieval benchmark %s: %w", path, err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Validate checks that every case has a query and that its relevant
+// passages occur in the benchmark documents.
+func (b *RetrievalBenchmark) Validate() error {
+	if len(b.Documents) == 0 || len(b.Cases) == 0 {
+		return errors.NewInvalidInputError("retrieval benchmark needs documents and cases")
+	}
+	docs := make(map[string]string, len(b.Documents))
+	for _, d := range b.Documents {
+		if d == nil || d.DocumentID == "" || strings.TrimSpace(d.Content) == "" {
+			return errors.NewInvalidInputError("benchmark documents need an id and content")
+		}
+		if _, dup := docs[d.DocumentID]; dup {
+			return errors.NewInvalidInputError(fmt.Sprintf("duplicate benchmark document %q", d.DocumentID))
+		}
+		docs[d.DocumentID] = normalizePassage(d.Content)
+	}
+	seen := make(map[string]bool, len(b.Cases))
+	for i, c := range b.Cases {
+		if c == nil || c.ID == "" {
+			return errors.NewInvalidInputError(fmt.Sprintf("case %d has no id", i))
+		}
+		if seen[c.ID] {
+			return errors.NewInvalidInputError(fmt.Sprintf("duplicate case id %q", c.ID))
+		}
+		seen[c.ID] = true
+		if strings.TrimSpace(c.Query) == "" || len(c.Relevant) == 0 {
+			return errors.NewInvalidInputError(fmt.Sprintf("case %q needs a query and relevant passages", c.ID))
+		}
+		for _, p := range c.Relevant {
+			content, ok := docs[p.DocumentID]
+			if !ok {
+				return errors.NewInvalidInputError(fmt.Sprintf("case %q references unknown document %q", c.ID, p.DocumentID))
+			}
+			if p.Contains == "" || !strings.Contains(content, normalizePassage(p.Contains)) {
+				return errors.NewInvalidInputError(fmt.Sprintf("case %q: %q does not occur in %s", c.ID, p.Contains, p.DocumentID))
+			}
+		}
+	}
+	return nil
+}
+
+func normalizePassage(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// ---------------------------------------------------------------------------
+// Variants
+// ---------------------------------------------------------------------------
+
+// RetrievalVariant is one chunking and reranking configuration under test.
+// A nil Reranker measures raw vector retrieval.
+type RetrievalVariant struct {
+	Name     string
+	Chunker  DocumentChunker
+	Reranker Reranker
+}
+
+// DefaultRetrievalVariants compares the fixed-size and structured chunkers,
+// each with and without the lexical reranker.
+func DefaultRetrievalVariants(chunkSize, chunkOverlap int) []RetrievalVariant {
+	fixed := NewDefaultDocumentChunker(chunkSize, chunkOverlap)
+	structured := NewStructuredDocumentChunker(StructuredChunkerConfig{ChunkSize: chunkSize, ChunkOverlap: chunkOverlap})
+	return []RetrievalVariant{
+		{Name: "fixed", Chunker: fixed},
+		{Name: "fixed+lexical", Chunker: fixed, Reranker: NewLexicalReranker()},
+		{Name: "structured", Chunker: structured},
+		{Name: "structured+lexical", Chunker: structured, Reranker: NewLexicalReranker()},
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Report
+// ---------------------------------------------------------------------------
+
+// RetrievalCaseResult records where a case's relevant passages ranked.
+type RetrievalCaseResult struct {
+	ID string `json:"id"`
+	// FirstRelevantRank is the 1-based rank of the first matching chunk, or
+	// 0 when none was retrieved.
+	FirstRelevantRank int `json:"first_relevant_rank"`
+	// PassageRanks holds, per relevant passage, the best rank at which it was
+	// found (0 when missed).
+	PassageRanks []int  `json:"passage_ranks"`
+	Error        string `json:"error,omitempty"`
+}
+
+// VariantReport holds the metrics of one variant. Recall maps k to the mean
+// fraction of relevant passages found in the top k chunks.
+type VariantReport struct {
+	Variant string                 `json:"variant"`
+	Chunks  int                    `json:"chunks"`
+	Recall  map[int]float64        `json:"recall"`
+	MRR     float64                `json:"mrr"`
+	Cases   []*RetrievalCaseResult `json:"cases"`
+}
+
+// RetrievalReport aggregates a benchmark run across variants.
+type RetrievalReport struct {
+	Benchmark      string           `json:"benchmark"`
+	EmbeddingModel string           `json:"embedding_model"`
+	Ks             []int            `json:"ks"`
+	Variants       []*VariantReport `json:"variants"`
+	StartedAt      time.Time        `json:"started_at"`
+	DurationMs     int64            `json:"duration_ms"`
+}
+
+// WriteFile stores the report as indented JSON.
+func (r *RetrievalReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding retrieval report: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ---------------------------------------------------------------------------
+// Runner
+// ---------------------------------------------------------------------------
+
+// DefaultRecallKs are the cut-offs reported when none are given.
+var DefaultRecallKs = []int{1, 3, 5, 10}
+
+// RunRetrievalBenchmark indexes the benchmark documents into a fresh
+// LocalCorpus for each variant and scores every case with RetrieveAndRerank.
+// The similarity threshold is disabled so that ranks, not cut-offs, are
+// measured. A failing case scores 0; only a cancelled context aborts.
+func RunRetrievalBenchmark(ctx context.Context, b *RetrievalBenchmark, embedder TextEmbedder, variants []RetrievalVariant, ks []int) (*RetrievalReport, error) {
+	if b == nil {
+		return nil, errors.NewInvalidInputError("benchmark is required")
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if embedder == nil {
+		return nil, errors.NewInvalidInputError("embedder is required")
+	}
+	if len(variants) == 0 {
+		variants = DefaultRetrievalVariants(0, 0)
+	}
+	if len(ks) == 0 {
+		ks = DefaultRecallKs
+	}
+	ks = append([]int(nil), ks...)
+	sort.Ints(ks)
+	if ks[0] <= 0 {
+		return nil, errors.NewInvalidInputError("recall cut-offs must be positive")
+	}
+	depth := ks[len(ks)-1]
+
+	model := "unknown"
+	if m, ok := embedder.(interface{ EmbeddingModel() (string, string) }); ok {
+		id, version := m.EmbeddingModel()
+		model = id + "@" + version
+	}
+	report := &RetrievalReport{Benchmark: b.Name, EmbeddingModel: model, Ks: ks, StartedAt: time.Now().UTC()}
+
+	for _, v := range variants {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		corpus := NewLocalCorpus(model)
+		cfg := DefaultRAGConfig()
+		cfg.SimilarityThreshold = -1
+		cfg.RerankerTopK = depth
+		engine, err := NewRAGEngine(corpus, embedder, v.Reranker, v.Chunker, cfg, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range b.Documents {
+			if err := engine.IndexDocument(ctx, d); err != nil {
+				return nil, fmt.Errorf("variant %s: %w", v.Name, err)
+			}
+		}
+
+		vr := &VariantReport{Variant: v.Name, Chunks: corpus.Manifest().Chunks, Recall: make(map[int]float64)}
+		for _, c := range b.Cases {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			res := runRetrievalCase(ctx, engine, c, depth)
+			vr.Cases = append(vr.Cases, res)
+			if res.FirstRelevantRank > 0 {
+				vr.MRR += 1 / float64(res.FirstRelevantRank)
+			}
+			for _, k := range ks {
+				found := 0
+				for _, rank := range res.PassageRanks {
+					if rank > 0 && rank <= k {
+						found++
+					}
+				}
+				vr.Recall[k] += float64(found) / float64(len(res.PassageRanks))
+			}
+		}
+		n := float64(len(b.Cases))
+		vr.MRR = round4(vr.MRR / n)
+		for k := range vr.Recall {
+			vr.Recall[k] = round4(vr.Recall[k] / n)
+		}
+		report.Variants = append(report.Variants, vr)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report, nil
+}
+
+func runRetrievalCase(ctx context.Context, engine RAGEngine, c *RetrievalCase, depth int) *RetrievalCaseResult {
+	res := &RetrievalCaseResult{ID: c.ID, PassageRanks: make([]int, len(c.Relevant))}
+	result, err := engine.RetrieveAndRerank(ctx, &RAGQuery{QueryText: c.Query, Filters: c.Filters, TopK: depth})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for i, chunk := range result.Chunks {
+		content := normalizePassage(chunk.Content)
+		for j, p := range c.Relevant {
+			if res.PassageRanks[j] != 0 || chunk.DocumentID != p.DocumentID ||
+				!strings.Contains(content, normalizePassage(p.Contains)) {
+				continue
+			}
+			res.PassageRanks[j] = i + 1
+			if res.FirstRelevantRank == 0 {
+				res.FirstRelevantRank = i + 1
+			}
+		}
+	}
+	return res
+}
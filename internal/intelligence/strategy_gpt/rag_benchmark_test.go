@@ -0,0 +1,104 @@
+package strategy_gpt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+const shippedRetrievalBenchmark = "../../../configs/rag/benchmark.json"
+
+func TestLoadRetrievalBenchmark(t *testing.T) {
+	b, err := LoadRetrievalBenchmark(shippedRetrievalBenchmark)
+	if err != nil {
+		t.Fatalf("LoadRetrievalBenchmark: %v", err)
+	}
+	if len(b.Documents) < 3 || len(b.Cases) < 5 {
+		t.Errorf("unexpected benchmark: %d documents, %d cases", len(b.Documents), len(b.Cases))
+	}
+
+	docs := []*Document{{DocumentID: "D1", Content: "A carbazole host."}}
+	bad := &RetrievalBenchmark{Documents: docs, Cases: []*RetrievalCase{
+		{ID: "a", Query: "host", Relevant: []*RelevantPassage{{DocumentID: "D2", Contains: "host"}}},
+	}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected unknown document error")
+	}
+	bad.Cases[0].Relevant[0] = &RelevantPassage{DocumentID: "D1", Contains: "triazine"}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for a passage missing from its document")
+	}
+	bad.Cases[0].Relevant[0] = &RelevantPassage{DocumentID: "D1", Contains: "CARBAZOLE   host"}
+	if err := bad.Validate(); err != nil {
+		t.Errorf("passage matching should ignore case and spacing: %v", err)
+	}
+	if _, err := LoadRetrievalBenchmark(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestRunRetrievalBenchmark_ShippedSet(t *testing.T) {
+	b, err := LoadRetrievalBenchmark(shippedRetrievalBenchmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := RunRetrievalBenchmark(context.Background(), b, NewHashingTextEmbedder(0), DefaultRetrievalVariants(64, 8), nil)
+	if err != nil {
+		t.Fatalf("RunRetrievalBenchmark: %v", err)
+	}
+	if report.EmbeddingModel != "hashing-1024@1" || len(report.Variants) != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	byName := make(map[string]*VariantReport)
+	for _, v := range report.Variants {
+		byName[v.Variant] = v
+		if len(v.Cases) != len(b.Cases) || v.Recall[1] > v.Recall[10] {
+			t.Errorf("%s: inconsistent report %+v", v.Variant, v)
+		}
+		if v.MRR < 0 || v.MRR > 1 {
+			t.Errorf("%s: MRR out of range: %f", v.Variant, v.MRR)
+		}
+	}
+	// Keeping tables and examples whole must not lose passages that the
+	// fixed-size chunker finds.
+	if byName["structured"].Recall[10] < byName["fixed"].Recall[10] {
+		t.Errorf("structured recall@10 %.2f below fixed %.2f", byName["structured"].Recall[10], byName["fixed"].Recall[10])
+	}
+	if byName["structured+lexical"].MRR < 0.5 {
+		t.Errorf("structured+lexical MRR too low: %f", byName["structured+lexical"].MRR)
+	}
+}
+
+func TestRunRetrievalBenchmark_Metrics(t *testing.T) {
+	b := &RetrievalBenchmark{
+		Documents: []*Document{
+			{DocumentID: "A", Source: SourcePatent, Content: "Abstract\nA triazine host for green devices."},
+			{DocumentID: "B", Source: SourcePatent, Content: "Abstract\nAn acridan donor with a sulfone acceptor."},
+		},
+		Cases: []*RetrievalCase{
+			{ID: "hit", Query: "sulfone acceptor acridan", Relevant: []*RelevantPassage{{DocumentID: "B", Contains: "sulfone acceptor"}}},
+			{ID: "filtered", Query: "triazine host", Filters: &RAGFilters{ExcludeDocIDs: []string{"A"}},
+				Relevant: []*RelevantPassage{{DocumentID: "A", Contains: "triazine host"}}},
+		},
+	}
+	variants := []RetrievalVariant{{Name: "structured", Chunker: NewStructuredDocumentChunker(StructuredChunkerConfig{})}}
+	report, err := RunRetrievalBenchmark(context.Background(), b, NewHashingTextEmbedder(256), variants, []int{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := report.Variants[0]
+	if v.Cases[0].FirstRelevantRank != 1 || v.Cases[1].FirstRelevantRank != 0 {
+		t.Errorf("unexpected ranks %+v %+v", v.Cases[0], v.Cases[1])
+	}
+	if v.Recall[1] != 0.5 || v.MRR != 0.5 {
+		t.Errorf("expected recall@1 = MRR = 0.5, got %v %f", v.Recall, v.MRR)
+	}
+
+	if _, err := RunRetrievalBenchmark(context.Background(), b, nil, variants, nil); err == nil {
+		t.Error("expected error without an embedder")
+	}
+	if _, err := RunRetrievalBenchmark(context.Background(), b, NewHashingTextEmbedder(8), variants, []int{0}); err == nil {
+		t.Error("expected error for a zero cut-off")
+	}
+}
@@ -0,0 +1,316 @@
+package strategy_gpt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Section types and chunk metadata
+// ---------------------------------------------------------------------------
+
+// Section types recorded in chunk metadata under MetaSection.
+const (
+	SectionAbstract    = "abstract"
+	SectionBackground  = "background"
+	SectionSummary     = "summary"
+	SectionDescription = "description"
+	SectionExample     = "example"
+	SectionTable       = "table"
+	SectionClaims      = "claims"
+)
+
+// Chunk metadata keys written by the structured chunker.
+const (
+	MetaSection       = "section"
+	MetaClaimNumber   = "claim_number"
+	MetaClaimType     = "claim_type" // "independent" or "dependent"
+	MetaDependsOn     = "depends_on" // comma-separated claim numbers
+	MetaExampleNumber = "example_number"
+	MetaTableNumber   = "table_number"
+	MetaPart          = "part" // "2/3" when an oversized block had to be split
+)
+
+// ---------------------------------------------------------------------------
+// StructuredChunkerConfig
+// ---------------------------------------------------------------------------
+
+// StructuredChunkerConfig configures NewStructuredDocumentChunker. Token
+// counts are estimates, as elsewhere in the RAG pipeline.
+type StructuredChunkerConfig struct {
+	// ChunkSize is the target size of prose chunks; default 512.
+	ChunkSize int `json:"chunk_size" yaml:"chunk_size"`
+	// ChunkOverlap is carried between prose chunks of one section.
+	ChunkOverlap int `json:"chunk_overlap" yaml:"chunk_overlap"`
+	// MaxBlockTokens bounds claims, examples and tables, which are otherwise
+	// never split; default 4 × ChunkSize.
+	MaxBlockTokens int `json:"max_block_tokens" yaml:"max_block_tokens"`
+}
+
+// ---------------------------------------------------------------------------
+// structuredDocumentChunker
+// ---------------------------------------------------------------------------
+
+type structuredDocumentChunker struct {
+	cfg   StructuredChunkerConfig
+	prose *defaultDocumentChunker
+}
+
+// NewStructuredDocumentChunker creates a chunker that follows patent
+// structure: prose is packed by paragraph without crossing section
+// headings, while each claim, worked example and table is kept whole and
+// annotated with its section type and number.
+func NewStructuredDocumentChunker(cfg StructuredChunkerConfig) DocumentChunker {
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 512
+	}
+	if cfg.MaxBlockTokens <= 0 {
+		cfg.MaxBlockTokens = 4 * cfg.ChunkSize
+	}
+	prose := NewDefaultDocumentChunker(cfg.ChunkSize, cfg.ChunkOverlap).(*defaultDocumentChunker)
+	cfg.ChunkOverlap = prose.chunkOverlap
+	return &structuredDocumentChunker{cfg: cfg, prose: prose}
+}
+
+// docBlock is a run of text with one section type. Atomic blocks (claims,
+// examples, tables) become a single chunk when they fit.
+type docBlock struct {
+	section string
+	text    string
+	atomic  bool
+	number  string
+	meta    map[string]string
+}
+
+var (
+	headingMarkup  = regexp.MustCompile(`^[#\s\[【(]*|[\]】):：\s]*$`)
+	exampleHeading = regexp.MustCompile(`^(?i:comparative\s+example|example|实施例|对比例)\s*(\d+)`)
+	tableHeading   = regexp.MustCompile(`^(?i:table|表)\s*(\d+)`)
+	claimStart     = regexp.MustCompile(`^(\d+)\s*[.、．)]\s*\S`)
+	claimReference = regexp.MustCompile(`(?i)(?:claims?|权利要求)\s*(\d+)(?:\s*(?:to|-|–|至|或|or|and|,)\s*(\d+))?`)
+)
+
+// maxBlockHeadingRunes keeps sentences that merely mention "Example 2" or
+// "Table 1" from opening a block.
+const maxBlockHeadingRunes = 80
+
+// sectionHeadings maps normalised heading text to section types.
+var sectionHeadings = map[string]string{
+	"abstract":                              SectionAbstract,
+	"摘要":                                    SectionAbstract,
+	"背景技术":                                  SectionBackground,
+	"技术领域":                                  SectionBackground,
+	"background":                            SectionBackground,
+	"background of the invention":           SectionBackground,
+	"technical field":                       SectionBackground,
+	"field of the invention":                SectionBackground,
+	"summary":                               SectionSummary,
+	"summary of the invention":              SectionSummary,
+	"发明内容":                                  SectionSummary,
+	"description":                           SectionDescription,
+	"detailed description":                  SectionDescription,
+	"detailed description of the invention": SectionDescription,
+	"description of embodiments":            SectionDescription,
+	"具体实施方式":                                SectionDescription,
+	"examples":                              SectionExample,
+	"claims":                                SectionClaims,
+	"what is claimed is":                    SectionClaims,
+	"we claim":                              SectionClaims,
+	"权利要求":                                  SectionClaims,
+	"权利要求书":                                 SectionClaims,
+}
+
+// sectionHeading reports the section a heading line opens.
+func sectionHeading(line string) (string, bool) {
+	if len([]rune(line)) > 60 {
+		return "", false
+	}
+	key := strings.ToLower(strings.TrimSpace(headingMarkup.ReplaceAllString(line, "")))
+	section, ok := sectionHeadings[key]
+	return section, ok
+}
+
+func (s *structuredDocumentChunker) Chunk(doc *Document) ([]*DocumentChunk, error) {
+	if doc == nil || strings.TrimSpace(doc.Content) == "" {
+		return []*DocumentChunk{}, nil
+	}
+
+	var chunks []*DocumentChunk
+	counters := make(map[string]int)
+	used := make(map[string]bool)
+	for _, b := range s.parse(doc.Content) {
+		for _, c := range s.chunkBlock(doc, b) {
+			id := b.section
+			if b.number != "" {
+				id += "-" + b.number
+			} else {
+				id += "-" + strconv.Itoa(counters[b.section])
+				counters[b.section]++
+			}
+			if part := c.Metadata[MetaPart]; part != "" {
+				id += "-p" + part[:strings.IndexByte(part, '/')]
+			}
+			// Comparative examples restart numbering; keep IDs unique.
+			for base, n := id, 2; used[id]; n++ {
+				id = base + "-" + strconv.Itoa(n)
+			}
+			used[id] = true
+			c.ChunkID = doc.DocumentID + "-" + id
+			c.Index = len(chunks)
+			chunks = append(chunks, c)
+		}
+	}
+	if chunks == nil {
+		chunks = []*DocumentChunk{}
+	}
+	return chunks, nil
+}
+
+// parse splits content into blocks at section headings, example and table
+// headings, and claim numbers within the claims section.
+func (s *structuredDocumentChunker) parse(content string) []*docBlock {
+	var (
+		blocks  []*docBlock
+		current *docBlock
+		section = SectionDescription
+		lines   []string
+	)
+	flush := func() {
+		if current != nil {
+			current.text = strings.TrimSpace(strings.Join(lines, "\n"))
+			if current.text != "" {
+				blocks = append(blocks, current)
+			}
+		}
+		current, lines = nil, nil
+	}
+	open := func(b *docBlock) {
+		flush()
+		current = b
+	}
+
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		short := len([]rune(line)) <= maxBlockHeadingRunes
+
+		if sec, ok := sectionHeading(line); ok {
+			flush()
+			section = sec
+			continue
+		}
+
+		if section == SectionClaims {
+			if m := claimStart.FindStringSubmatch(line); m != nil {
+				open(&docBlock{section: SectionClaims, atomic: true, number: m[1]})
+			} else if current == nil {
+				open(&docBlock{section: SectionClaims})
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if m := exampleHeading.FindStringSubmatch(line); m != nil && short {
+			open(&docBlock{section: SectionExample, atomic: true, number: m[1],
+				meta: map[string]string{MetaExampleNumber: m[1]}})
+			lines = append(lines, line)
+			continue
+		}
+		if m := tableHeading.FindStringSubmatch(line); m != nil && short {
+			open(&docBlock{section: SectionTable, atomic: true, number: m[1],
+				meta: map[string]string{MetaTableNumber: m[1]}})
+			lines = append(lines, line)
+			continue
+		}
+
+		if current != nil && current.section == SectionTable {
+			// A table runs until the first blank line after its rows.
+			if line == "" && len(lines) > 1 {
+				flush()
+				continue
+			}
+			lines = append(lines, line)
+			continue
+		}
+		if current == nil {
+			open(&docBlock{section: section})
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	for _, b := range blocks {
+		if b.section == SectionClaims && b.atomic {
+			b.meta = claimMetadata(b.number, b.text)
+		}
+	}
+	return blocks
+}
+
+// claimMetadata classifies a claim by whether its text refers back to an
+// earlier claim.
+func claimMetadata(number, text string) map[string]string {
+	meta := map[string]string{MetaClaimNumber: number, MetaClaimType: "independent"}
+	own, _ := strconv.Atoi(number)
+	var deps []string
+	seen := make(map[int]bool)
+	for _, m := range claimReference.FindAllStringSubmatch(text, -1) {
+		from, _ := strconv.Atoi(m[1])
+		to := from
+		if m[2] != "" {
+			to, _ = strconv.Atoi(m[2])
+		}
+		for n := from; n <= to && n-from < 50; n++ {
+			if n > 0 && n < own && !seen[n] {
+				seen[n] = true
+				deps = append(deps, strconv.Itoa(n))
+			}
+		}
+	}
+	if len(deps) > 0 {
+		meta[MetaClaimType] = "dependent"
+		meta[MetaDependsOn] = strings.Join(deps, ",")
+	}
+	return meta
+}
+
+// chunkBlock turns one block into chunks carrying the block's metadata.
+func (s *structuredDocumentChunker) chunkBlock(doc *Document, b *docBlock) []*DocumentChunk {
+	meta := copyMetadata(doc.Metadata)
+	if doc.Title != "" {
+		meta["title"] = doc.Title
+	}
+	meta[MetaSection] = b.section
+	for k, v := range b.meta {
+		meta[k] = v
+	}
+
+	if !b.atomic {
+		chunks := s.prose.splitByParagraphs(b.text, doc)
+		for _, c := range chunks {
+			c.Metadata = copyMetadata(meta)
+		}
+		return chunks
+	}
+
+	if estimateTokens(b.text) <= s.cfg.MaxBlockTokens {
+		return []*DocumentChunk{{
+			DocumentID: doc.DocumentID,
+			Content:    b.text,
+			Source:     doc.Source,
+			Metadata:   meta,
+			TokenCount: estimateTokens(b.text),
+		}}
+	}
+
+	// Oversized block: split at sentences, repeating the block's metadata
+	// and heading so each part stays attributable.
+	splitter := &defaultDocumentChunker{chunkSize: s.cfg.MaxBlockTokens}
+	parts := splitter.splitBySentences(b.text, doc)
+	for i, c := range parts {
+		c.Metadata = copyMetadata(meta)
+		c.Metadata[MetaPart] = fmt.Sprintf("%d/%d", i+1, len(parts))
+	}
+	return parts
+}
@@ -0,0 +1,125 @@
+package strategy_gpt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const chunkerPatent = `Abstract
+A bipolar host with a triazine core.
+
+Background
+Green phosphorescent devices suffer from short lifetime.
+
+Example 2 below shows that the dibenzofuran groups raise the glass transition temperature well above that of CBP.
+
+Example 1
+Preparation of BH-3 under Suzuki conditions.
+The crude product was sublimed twice.
+
+Comparative Example 1
+A device using CBP host.
+
+Table 1
+Device | Host | LT95 (h)
+E1 | BH-3 | 410
+
+Tg was measured by DSC.
+
+Claims
+1. A host material comprising a 1,3,5-triazine core.
+
+2. The host material according to claim 1, having a Tg of at least 140 C.
+
+3. A device comprising the host material according to claim 1 or 2.`
+
+func chunksBySection(chunks []*DocumentChunk, section string) []*DocumentChunk {
+	var out []*DocumentChunk
+	for _, c := range chunks {
+		if c.Metadata[MetaSection] == section {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func TestStructuredChunker_KeepsBlocksIntact(t *testing.T) {
+	doc := &Document{DocumentID: "CN1", Title: "Host", Content: chunkerPatent, Source: SourcePatent,
+		Metadata: map[string]string{"jurisdiction": "CN"}}
+	chunks, err := NewStructuredDocumentChunker(StructuredChunkerConfig{ChunkSize: 64}).Chunk(doc)
+	if err != nil {
+		t.Fatalf("Chunk: %v", err)
+	}
+
+	claims := chunksBySection(chunks, SectionClaims)
+	if len(claims) != 3 {
+		t.Fatalf("expected 3 claim chunks, got %d", len(claims))
+	}
+	if claims[0].ChunkID != "CN1-claims-1" || claims[0].Metadata[MetaClaimType] != "independent" {
+		t.Errorf("unexpected first claim %+v", claims[0])
+	}
+	if claims[2].Metadata[MetaClaimType] != "dependent" || claims[2].Metadata[MetaDependsOn] != "1,2" {
+		t.Errorf("claim 3 should depend on 1 and 2, got %v", claims[2].Metadata)
+	}
+
+	examples := chunksBySection(chunks, SectionExample)
+	if len(examples) != 2 || !strings.Contains(examples[0].Content, "sublimed twice") {
+		t.Fatalf("examples should be kept whole, got %d", len(examples))
+	}
+	if examples[1].ChunkID != "CN1-example-1-2" {
+		t.Errorf("comparative example needs a distinct id, got %s", examples[1].ChunkID)
+	}
+
+	tables := chunksBySection(chunks, SectionTable)
+	if len(tables) != 1 || !strings.Contains(tables[0].Content, "E1 | BH-3 | 410") ||
+		strings.Contains(tables[0].Content, "DSC") || tables[0].Metadata[MetaTableNumber] != "1" {
+		t.Errorf("table should end at the first blank line, got %+v", tables)
+	}
+
+	// A sentence that starts with "Example 2" stays in the background prose.
+	bg := chunksBySection(chunks, SectionBackground)
+	if len(bg) == 0 || !strings.Contains(bg[0].Content, "Example 2 below shows") {
+		t.Errorf("long lines must not open example blocks, background %+v", bg)
+	}
+	for i, c := range chunks {
+		if c.Index != i || c.Metadata["jurisdiction"] != "CN" || c.Metadata["title"] != "Host" {
+			t.Errorf("chunk %d lost index or document metadata: %+v", i, c)
+		}
+	}
+}
+
+func TestStructuredChunker_SplitsOversizedBlocks(t *testing.T) {
+	long := "Claims\n1. A compound" + strings.Repeat(" wherein R1 is methyl. Further", 60) + " limited."
+	chunks, err := NewStructuredDocumentChunker(StructuredChunkerConfig{ChunkSize: 32, MaxBlockTokens: 64}).
+		Chunk(&Document{DocumentID: "US1", Content: long})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("oversized claim should be split, got %d chunks", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Metadata[MetaClaimNumber] != "1" || !strings.HasSuffix(c.Metadata[MetaPart], "/"+strconv.Itoa(len(chunks))) {
+			t.Errorf("part %d lost claim metadata: %v", i, c.Metadata)
+		}
+	}
+	if chunks[1].ChunkID != "US1-claims-1-p2" {
+		t.Errorf("unexpected part id %s", chunks[1].ChunkID)
+	}
+}
+
+func TestStructuredChunker_ChineseHeadings(t *testing.T) {
+	content := "摘要\n一种三嗪主体材料。\n\n实施例1\n化合物BH-3的制备。\n\n权利要求书\n1、一种主体材料。\n2、根据权利要求1所述的主体材料。"
+	chunks, _ := NewStructuredDocumentChunker(StructuredChunkerConfig{}).Chunk(&Document{DocumentID: "CN2", Content: content})
+	if got := chunksBySection(chunks, SectionExample); len(got) != 1 || got[0].Metadata[MetaExampleNumber] != "1" {
+		t.Errorf("expected one example, got %+v", got)
+	}
+	claims := chunksBySection(chunks, SectionClaims)
+	if len(claims) != 2 || claims[1].Metadata[MetaDependsOn] != "1" {
+		t.Errorf("expected claim 2 to depend on claim 1, got %+v", claims)
+	}
+	if empty, _ := NewStructuredDocumentChunker(StructuredChunkerConfig{}).Chunk(&Document{DocumentID: "x"}); len(empty) != 0 {
+		t.Error("empty document should yield no chunks")
+	}
+}
@@ -0,0 +1,509 @@
+package strategy_gpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// Patent documents
+// ---------------------------------------------------------------------------
+
+// PatentDocument renders a patent as a RAG document with the section
+// headings the structured chunker recognises. The description is read from
+// raw_data["description"] when the source provided one.
+func PatentDocument(p *patent.Patent) *Document {
+	var b strings.Builder
+	section := func(heading, text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			fmt.Fprintf(&b, "%s\n%s\n\n", heading, text)
+		}
+	}
+	section("Abstract", firstNonEmpty(p.Abstract, p.AbstractEn))
+	if desc, ok := p.RawData["description"].(string); ok {
+		section("Description", desc)
+	}
+	if len(p.Claims) > 0 {
+		claims := make([]string, 0, len(p.Claims))
+		for _, c := range p.Claims {
+			claims = append(claims, fmt.Sprintf("%d. %s", c.Number, strings.TrimSpace(c.Text)))
+		}
+		section("Claims", strings.Join(claims, "\n\n"))
+	}
+
+	meta := map[string]string{"patent_number": p.PatentNumber}
+	if p.Jurisdiction != "" {
+		meta["jurisdiction"] = p.Jurisdiction
+	}
+	if p.AssigneeName != "" {
+		meta["assignee"] = p.AssigneeName
+	}
+	if len(p.IPCCodes) > 0 {
+		meta["ipc_codes"] = strings.Join(p.IPCCodes, ",")
+	}
+	return &Document{
+		DocumentID: p.PatentNumber,
+		Title:      firstNonEmpty(p.Title, p.TitleEn),
+		Content:    strings.TrimSpace(b.String()),
+		Source:     SourcePatent,
+		Metadata:   meta,
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ---------------------------------------------------------------------------
+// LocalCorpus
+// ---------------------------------------------------------------------------
+
+// CorpusManifest describes how a local corpus was built.
+type CorpusManifest struct {
+	// EmbeddingModel is the model tag of every stored vector; queries must
+	// be embedded with the same model.
+	EmbeddingModel string    `json:"embedding_model"`
+	Dimensions     int       `json:"dimensions"`
+	Chunker        string    `json:"chunker,omitempty"`
+	Documents      int       `json:"documents"`
+	Chunks         int       `json:"chunks"`
+	BuiltAt        time.Time `json:"built_at"`
+}
+
+type corpusEntry struct {
+	ID       string            `json:"id"`
+	Vector   []float32         `json:"vector"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// LocalCorpus is an exact (brute-force cosine) VectorStore held in memory
+// and saved as a single JSON file. It serves offline indexing, evaluation
+// and small deployments without Milvus.
+type LocalCorpus struct {
+	mu       sync.RWMutex
+	manifest CorpusManifest
+	entries  map[string]*corpusEntry
+}
+
+var _ VectorStore = (*LocalCorpus)(nil)
+
+// NewLocalCorpus creates an empty corpus for vectors of the given model.
+func NewLocalCorpus(embeddingModel string) *LocalCorpus {
+	return &LocalCorpus{
+		manifest: CorpusManifest{EmbeddingModel: embeddingModel},
+		entries:  make(map[string]*corpusEntry),
+	}
+}
+
+// LoadLocalCorpus reads a corpus written by SaveFile.
+func LoadLocalCorpus(path string) (*LocalCorpus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Manifest CorpusManifest `json:"manifest"`
+		Entries  []*corpusEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decoding corpus %s: %w", path, err)
+	}
+	c := &LocalCorpus{manifest: file.Manifest, entries: make(map[string]*corpusEntry, len(file.Entries))}
+	for _, e := range file.Entries {
+		c.entries[e.ID] = e
+	}
+	return c, nil
+}
+
+// SaveFile writes the corpus, creating parent directories.
+func (c *LocalCorpus) SaveFile(path string) error {
+	c.mu.RLock()
+	file := struct {
+		Manifest CorpusManifest `json:"manifest"`
+		Entries  []*corpusEntry `json:"entries"`
+	}{Manifest: c.manifestLocked()}
+	for _, e := range c.entries {
+		file.Entries = append(file.Entries, e)
+	}
+	c.mu.RUnlock()
+	sort.Slice(file.Entries, func(i, j int) bool { return file.Entries[i].ID < file.Entries[j].ID })
+	file.Manifest.BuiltAt = time.Now().UTC()
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("encoding corpus: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// SetChunker records the chunker settings in the manifest.
+func (c *LocalCorpus) SetChunker(desc string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifest.Chunker = desc
+}
+
+// Manifest returns the corpus description with current counts.
+func (c *LocalCorpus) Manifest() CorpusManifest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.manifestLocked()
+}
+
+func (c *LocalCorpus) manifestLocked() CorpusManifest {
+	m := c.manifest
+	docs := make(map[string]bool)
+	for _, e := range c.entries {
+		docs[e.Metadata["document_id"]] = true
+	}
+	m.Documents = len(docs)
+	m.Chunks = len(c.entries)
+	return m
+}
+
+// Insert stores one vector.
+func (c *LocalCorpus) Insert(ctx context.Context, id string, vector []float32, metadata map[string]interface{}) error {
+	return c.BatchInsert(ctx, []*VectorInsertItem{{ID: id, Vector: vector, Metadata: metadata}})
+}
+
+// BatchInsert stores vectors, replacing entries with the same ID. All
+// vectors must share the corpus dimension.
+func (c *LocalCorpus) BatchInsert(_ context.Context, items []*VectorInsertItem) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, it := range items {
+		if it == nil || it.ID == "" || len(it.Vector) == 0 {
+			return errors.NewInvalidInputError("corpus item requires an id and a vector")
+		}
+		if c.manifest.Dimensions == 0 {
+			c.manifest.Dimensions = len(it.Vector)
+		}
+		if len(it.Vector) != c.manifest.Dimensions {
+			return errors.NewInvalidInputError(fmt.Sprintf("vector for %s has %d dimensions, corpus has %d", it.ID, len(it.Vector), c.manifest.Dimensions))
+		}
+	}
+	for _, it := range items {
+		meta := make(map[string]string, len(it.Metadata))
+		for k, v := range it.Metadata {
+			meta[k] = fmt.Sprint(v)
+		}
+		c.entries[it.ID] = &corpusEntry{ID: it.ID, Vector: unitVector(it.Vector), Metadata: meta}
+	}
+	return nil
+}
+
+// Delete removes a chunk by ID, or every chunk of a document by document ID.
+func (c *LocalCorpus) Delete(_ context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+	for key, e := range c.entries {
+		if e.Metadata["document_id"] == id {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// Search returns the topK entries by cosine similarity. It honours the
+// source_types, jurisdictions, document_types, assignees and exclude_doc_ids
+// filters built by the RAG engine.
+func (c *LocalCorpus) Search(_ context.Context, vector []float32, topK int, filters map[string]interface{}) ([]*VectorSearchResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.entries) == 0 {
+		return []*VectorSearchResult{}, nil
+	}
+	if len(vector) != c.manifest.Dimensions {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("query has %d dimensions, corpus %s has %d", len(vector), c.manifest.EmbeddingModel, c.manifest.Dimensions))
+	}
+	q := unitVector(vector)
+
+	results := make([]*VectorSearchResult, 0, len(c.entries))
+	for _, e := range c.entries {
+		if !corpusFilterMatch(e.Metadata, filters) {
+			continue
+		}
+		var dot float64
+		for i, v := range e.Vector {
+			dot += float64(v) * float64(q[i])
+		}
+		results = append(results, &VectorSearchResult{ID: e.ID, Score: dot, Metadata: e.Metadata})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func corpusFilterMatch(meta map[string]string, filters map[string]interface{}) bool {
+	in := func(key, value string) bool {
+		list, ok := filters[key].([]string)
+		if !ok || len(list) == 0 {
+			return true
+		}
+		for _, v := range list {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	}
+	if !in("source_types", meta["source"]) || !in("jurisdictions", meta["jurisdiction"]) ||
+		!in("document_types", meta["document_type"]) || !in("assignees", meta["assignee"]) {
+		return false
+	}
+	if excluded, ok := filters["exclude_doc_ids"].([]string); ok {
+		for _, id := range excluded {
+			if id == meta["document_id"] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func unitVector(v []float32) []float32 {
+	var sumSq float64
+	for _, x := range v {
+		sumSq += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if sumSq == 0 {
+		return out
+	}
+	norm := math.Sqrt(sumSq)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Embedders
+// ---------------------------------------------------------------------------
+
+// NewTextEmbedder adapts a single-text embedder, such as
+// common.EmbeddingClient, to TextEmbedder.
+func NewTextEmbedder(e common.Embedder) TextEmbedder {
+	return &textEmbedderAdapter{Embedder: e}
+}
+
+type textEmbedderAdapter struct {
+	common.Embedder
+}
+
+func (a *textEmbedderAdapter) BatchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v, err := a.Embed(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// HashingTextEmbedder is a deterministic lexical embedder: word unigrams
+// and bigrams are hashed into a fixed number of buckets. It needs no model
+// and serves as the offline baseline for benchmarks and local corpora.
+type HashingTextEmbedder struct {
+	dims int
+}
+
+var (
+	_ TextEmbedder          = (*HashingTextEmbedder)(nil)
+	_ common.TaggedEmbedder = (*HashingTextEmbedder)(nil)
+)
+
+// NewHashingTextEmbedder creates an embedder producing dims-dimensional
+// vectors; dims defaults to 1024.
+func NewHashingTextEmbedder(dims int) *HashingTextEmbedder {
+	if dims <= 0 {
+		dims = 1024
+	}
+	return &HashingTextEmbedder{dims: dims}
+}
+
+// Embed returns the hashed term vector of text.
+func (h *HashingTextEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	vec := make([]float32, h.dims)
+	terms := lexicalTerms(text)
+	add := func(term string, weight float32) {
+		f := fnv.New32a()
+		f.Write([]byte(term))
+		sum := f.Sum32()
+		sign := float32(1)
+		if sum&1 == 1 {
+			sign = -1
+		}
+		vec[int(sum>>1)%h.dims] += sign * weight
+	}
+	for i, t := range terms {
+		add(t, 1)
+		if i > 0 {
+			add(terms[i-1]+" "+t, 0.5)
+		}
+	}
+	return unitVector(vec), nil
+}
+
+// BatchEmbed embeds each text.
+func (h *HashingTextEmbedder) BatchEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i], _ = h.Embed(ctx, t)
+	}
+	return out, nil
+}
+
+// EmbedTagged returns the vector tagged with the hashing model.
+func (h *HashingTextEmbedder) EmbedTagged(ctx context.Context, text string) (*common.Embedding, error) {
+	v, _ := h.Embed(ctx, text)
+	id, version := h.EmbeddingModel()
+	return &common.Embedding{Vector: v, ModelID: id, ModelVersion: version}, nil
+}
+
+// EmbeddingModel identifies the hashing scheme and dimension.
+func (h *HashingTextEmbedder) EmbeddingModel() (string, string) {
+	return "hashing-" + strconv.Itoa(h.dims), "1"
+}
+
+// lexicalStopwords are dropped before hashing and reranking.
+var lexicalStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"that": true, "the": true, "to": true, "which": true, "with": true, "wherein": true, "said": true,
+}
+
+// lexicalTerms lower-cases text into word terms, keeping chemical names
+// such as "4-methyl" intact and splitting CJK text into characters.
+func lexicalTerms(text string) []string {
+	var (
+		terms []string
+		cur   []rune
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			t := strings.Trim(string(cur), "-")
+			if t != "" && !lexicalStopwords[t] {
+				terms = append(terms, t)
+			}
+			cur = cur[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			terms = append(terms, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			cur = append(cur, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// ---------------------------------------------------------------------------
+// LexicalReranker
+// ---------------------------------------------------------------------------
+
+// LexicalReranker re-scores candidates by BM25 over the candidate set. It is
+// a model-free baseline against which cross-encoder rerankers are measured.
+type LexicalReranker struct {
+	k1, b float64
+}
+
+var _ Reranker = (*LexicalReranker)(nil)
+
+// NewLexicalReranker creates a BM25 reranker with the usual k1 = 1.2,
+// b = 0.75.
+func NewLexicalReranker() *LexicalReranker {
+	return &LexicalReranker{k1: 1.2, b: 0.75}
+}
+
+// Rerank returns the topK documents by BM25 score against query.
+func (l *LexicalReranker) Rerank(_ context.Context, query string, documents []string, topK int) ([]*RerankResult, error) {
+	if len(documents) == 0 {
+		return []*RerankResult{}, nil
+	}
+	docTerms := make([]map[string]int, len(documents))
+	docLen := make([]int, len(documents))
+	df := make(map[string]int)
+	var total int
+	for i, d := range documents {
+		terms := lexicalTerms(d)
+		counts := make(map[string]int, len(terms))
+		for _, t := range terms {
+			counts[t]++
+		}
+		for t := range counts {
+			df[t]++
+		}
+		docTerms[i], docLen[i] = counts, len(terms)
+		total += len(terms)
+	}
+	avgLen := float64(total) / float64(len(documents))
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	queryTerms := make(map[string]bool)
+	for _, t := range lexicalTerms(query) {
+		queryTerms[t] = true
+	}
+	n := float64(len(documents))
+	results := make([]*RerankResult, len(documents))
+	for i := range documents {
+		var score float64
+		for t := range queryTerms {
+			tf := float64(docTerms[i][t])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[t])+0.5)/(float64(df[t])+0.5))
+			score += idf * tf * (l.k1 + 1) / (tf + l.k1*(1-l.b+l.b*float64(docLen[i])/avgLen))
+		}
+		results[i] = &RerankResult{Index: i, Score: score}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package strategy_gpt
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
+)
+
+func TestPatentDocument(t *testing.T) {
+	p := &patent.Patent{
+		PatentNumber: "CN108912345A",
+		Title:        "Bipolar host material",
+		Abstract:     "A bipolar host with a triazine core.",
+		Jurisdiction: "CN",
+		AssigneeName: "Jilin OLED",
+		RawData:      map[string]any{"description": "Dibenzofuran raises Tg."},
+		Claims: patent.ClaimSet{
+			{Number: 1, Text: "A host material comprising a triazine core."},
+			{Number: 2, Text: "The host material according to claim 1."},
+		},
+	}
+	doc := PatentDocument(p)
+	if doc.DocumentID != "CN108912345A" || doc.Source != SourcePatent || doc.Metadata["assignee"] != "Jilin OLED" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	chunks, err := NewStructuredDocumentChunker(StructuredChunkerConfig{}).Chunk(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections := make([]string, len(chunks))
+	for i, c := range chunks {
+		sections[i] = c.Metadata[MetaSection]
+	}
+	if got := strings.Join(sections, ","); got != "abstract,description,claims,claims" {
+		t.Errorf("rendered patent should round-trip through the chunker, sections %s", got)
+	}
+	if chunks[3].Metadata[MetaDependsOn] != "1" {
+		t.Errorf("claim 2 should depend on claim 1: %v", chunks[3].Metadata)
+	}
+}
+
+func TestLocalCorpus_SearchFiltersAndPersistence(t *testing.T) {
+	ctx := context.Background()
+	embedder := NewHashingTextEmbedder(256)
+	corpus := NewLocalCorpus("hashing-256@1")
+	engine, err := NewRAGEngine(corpus, embedder, nil, NewStructuredDocumentChunker(StructuredChunkerConfig{}), DefaultRAGConfig(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []*Document{
+		{DocumentID: "US1", Source: SourcePatent, Metadata: map[string]string{"jurisdiction": "US"},
+			Content: "Abstract\nA carbazole triazine host for phosphorescent devices."},
+		{DocumentID: "CN1", Source: SourcePatent, Metadata: map[string]string{"jurisdiction": "CN"},
+			Content: "Abstract\nA dibenzofuran triazine host with high Tg."},
+	}
+	for _, d := range docs {
+		if err := engine.IndexDocument(ctx, d); err != nil {
+			t.Fatalf("IndexDocument: %v", err)
+		}
+	}
+
+	res, err := engine.Retrieve(ctx, &RAGQuery{QueryText: "carbazole triazine host", TopK: 2, SimilarityThreshold: 0.01})
+	if err != nil || len(res.Chunks) == 0 || res.Chunks[0].DocumentID != "US1" {
+		t.Fatalf("expected US1 first, got %+v %v", res, err)
+	}
+	if res.Chunks[0].Metadata[MetaSection] != SectionAbstract || !strings.Contains(res.Chunks[0].Content, "carbazole") {
+		t.Errorf("chunk content and metadata should round-trip: %+v", res.Chunks[0])
+	}
+	res, _ = engine.Retrieve(ctx, &RAGQuery{QueryText: "carbazole triazine host", TopK: 2, SimilarityThreshold: 0.01,
+		Filters: &RAGFilters{Jurisdictions: []string{"cn"}}})
+	if len(res.Chunks) != 1 || res.Chunks[0].DocumentID != "CN1" {
+		t.Errorf("jurisdiction filter not applied: %+v", res.Chunks)
+	}
+
+	path := filepath.Join(t.TempDir(), "corpus", "oled.json")
+	if err := corpus.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile: %v", err)
+	}
+	loaded, err := LoadLocalCorpus(path)
+	if err != nil {
+		t.Fatalf("LoadLocalCorpus: %v", err)
+	}
+	if m := loaded.Manifest(); m.Documents != 2 || m.Chunks != 2 || m.Dimensions != 256 || m.EmbeddingModel != "hashing-256@1" {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+	q, _ := embedder.Embed(ctx, "query")
+	if _, err := loaded.Search(ctx, q[:10], 1, nil); err == nil {
+		t.Error("expected dimension mismatch error")
+	}
+	if err := loaded.Delete(ctx, "US1"); err != nil || loaded.Manifest().Documents != 1 {
+		t.Errorf("Delete by document id should drop its chunks: %+v %v", loaded.Manifest(), err)
+	}
+}
+
+func TestLexicalReranker(t *testing.T) {
+	docs := []string{
+		"An iridium complex dopant.",
+		"A triazine host with a carbazole donor and triazine acceptor.",
+		"Carbazole synthesis.",
+	}
+	got, err := NewLexicalReranker().Rerank(context.Background(), "carbazole triazine host", docs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Index != 1 || got[1].Index != 2 {
+		t.Errorf("unexpected ranking %+v %+v", got[0], got[1])
+	}
+}
+
+func TestHashingTextEmbedder(t *testing.T) {
+	e := NewHashingTextEmbedder(64)
+	a, _ := e.Embed(context.Background(), "Triazine host")
+	b, _ := e.Embed(context.Background(), "triazine HOST")
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatal("embedding should be case-insensitive and deterministic")
+		}
+	}
+	emb, _ := e.EmbedTagged(context.Background(), "x")
+	if emb.Tag() != "hashing-64@1" || len(emb.Vector) != 64 {
+		t.Errorf("unexpected tagged embedding %s/%d", emb.Tag(), len(emb.Vector))
+	}
+}
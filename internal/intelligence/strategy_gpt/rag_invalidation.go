@@ -0,0 +1,71 @@
+package strategy_gpt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// ---------------------------------------------------------------------------
+// invalidatingRAGEngine
+// ---------------------------------------------------------------------------
+
+// invalidatingRAGEngine invalidates cached LLM responses whenever a source
+// document is re-indexed or deleted, so answers never outlive the context
+// they were generated from.
+type invalidatingRAGEngine struct {
+	RAGEngine
+	invalidator common.CacheInvalidator
+}
+
+// NewInvalidatingRAGEngine wraps engine so that IndexDocument, IndexBatch and
+// DeleteDocument invalidate responses cached against the affected documents.
+// Invalidation runs after the index write succeeds.
+func NewInvalidatingRAGEngine(engine RAGEngine, invalidator common.CacheInvalidator) (RAGEngine, error) {
+	if engine == nil {
+		return nil, errors.NewInvalidInputError("rag engine is required")
+	}
+	if invalidator == nil {
+		return nil, errors.NewInvalidInputError("cache invalidator is required")
+	}
+	return &invalidatingRAGEngine{RAGEngine: engine, invalidator: invalidator}, nil
+}
+
+func (e *invalidatingRAGEngine) IndexDocument(ctx context.Context, doc *Document) error {
+	if err := e.RAGEngine.IndexDocument(ctx, doc); err != nil {
+		return err
+	}
+	if doc == nil {
+		return nil
+	}
+	return e.invalidate(ctx, doc.DocumentID)
+}
+
+func (e *invalidatingRAGEngine) IndexBatch(ctx context.Context, docs []*Document) error {
+	if err := e.RAGEngine.IndexBatch(ctx, docs); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		if d != nil {
+			ids = append(ids, d.DocumentID)
+		}
+	}
+	return e.invalidate(ctx, ids...)
+}
+
+func (e *invalidatingRAGEngine) DeleteDocument(ctx context.Context, docID string) error {
+	if err := e.RAGEngine.DeleteDocument(ctx, docID); err != nil {
+		return err
+	}
+	return e.invalidate(ctx, docID)
+}
+
+func (e *invalidatingRAGEngine) invalidate(ctx context.Context, ids ...string) error {
+	if err := e.invalidator.InvalidateDocuments(ctx, ids...); err != nil {
+		return fmt.Errorf("documents indexed but cache invalidation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package strategy_gpt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type recordingInvalidator struct {
+	ids []string
+	err error
+}
+
+func (r *recordingInvalidator) InvalidateDocuments(_ context.Context, ids ...string) error {
+	r.ids = append(r.ids, ids...)
+	return r.err
+}
+
+type failingIndexRAG struct{ mockRAG }
+
+func (*failingIndexRAG) IndexDocument(context.Context, *Document) error {
+	return fmt.Errorf("vector store down")
+}
+
+func TestInvalidatingRAGEngine(t *testing.T) {
+	inv := &recordingInvalidator{}
+	engine, err := NewInvalidatingRAGEngine(&mockRAG{}, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := engine.IndexDocument(ctx, &Document{DocumentID: "US1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.IndexBatch(ctx, []*Document{{DocumentID: "US2"}, nil, {DocumentID: "US3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.DeleteDocument(ctx, "US4"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"US1", "US2", "US3", "US4"}; !reflect.DeepEqual(inv.ids, want) {
+		t.Errorf("invalidated %v, want %v", inv.ids, want)
+	}
+
+	// Retrieval passes through untouched.
+	if res, err := engine.Retrieve(ctx, &RAGQuery{}); err != nil || len(res.Chunks) != 2 {
+		t.Errorf("Retrieve: %v %v", res, err)
+	}
+
+	// A failed index write must not invalidate anything.
+	inv.ids = nil
+	failing, _ := NewInvalidatingRAGEngine(&failingIndexRAG{}, inv)
+	if err := failing.IndexDocument(ctx, &Document{DocumentID: "US5"}); err == nil || len(inv.ids) != 0 {
+		t.Errorf("expected index error without invalidation, got %v %v", err, inv.ids)
+	}
+
+	inv.err = fmt.Errorf("redis down")
+	if err := engine.DeleteDocument(ctx, "US6"); err == nil {
+		t.Error("invalidation failure should be reported")
+	}
+
+	if _, err := NewInvalidatingRAGEngine(nil, inv); err == nil {
+		t.Error("expected error for nil engine")
+	}
+	if _, err := NewInvalidatingRAGEngine(&mockRAG{}, nil); err == nil {
+		t.Error("expected error for nil invalidator")
+	}
+}
@@ -84,6 +84,7 @@ type Report struct {
 	Content     *ReportContent    `json:"content"`
 	Metadata    *ReportMetadata   `json:"metadata"`
 	Validation  *ReportValidation `json:"validation,omitempty"`
+	Grounding   *GroundingReport  `json:"grounding,omitempty"`
 	GeneratedAt time.Time         `json:"generated_at"`
 	LatencyMs   int64             `json:"latency_ms"`
 	TokensUsed  *TokenUsage       `json:"tokens_used"`
@@ -103,13 +104,14 @@ type ReportContent struct {
 
 // ReportSection is a recursive section structure.
 type ReportSection struct {
-	SectionID   string           `json:"section_id"`
-	Title       string           `json:"title"`
-	Content     string           `json:"content"`
-	SubSections []*ReportSection `json:"sub_sections,omitempty"`
-	Tables      []*ReportTable   `json:"tables,omitempty"`
-	Figures     []*ReportFigure  `json:"figures,omitempty"`
-	Order       int              `json:"order"`
+	SectionID   string            `json:"section_id"`
+	Title       string            `json:"title"`
+	Content     string            `json:"content"`
+	SubSections []*ReportSection  `json:"sub_sections,omitempty"`
+	Tables      []*ReportTable    `json:"tables,omitempty"`
+	Figures     []*ReportFigure   `json:"figures,omitempty"`
+	Order       int               `json:"order"`
+	Grounding   *SectionGrounding `json:"grounding,omitempty"`
 }
 
 // Conclusion represents a single conclusion statement.
@@ -254,6 +256,7 @@ type reportGeneratorImpl struct {
 	config        *StrategyGPTConfig
 	metrics       common.IntelligenceMetrics
 	logger        common.Logger
+	grounding     GroundingVerifier
 }
 
 // NewReportGenerator creates a new ReportGenerator.
@@ -290,6 +293,29 @@ func NewReportGenerator(
 	}, nil
 }
 
+// NewReportGeneratorWithGrounding creates a ReportGenerator that checks every
+// generated report's patent, claim and quote references with verifier and
+// attaches the result as Report.Grounding. Streaming output is not verified.
+func NewReportGeneratorWithGrounding(
+	backend common.ModelBackend,
+	promptMgr PromptManager,
+	rag RAGEngine,
+	cfg *StrategyGPTConfig,
+	metrics common.IntelligenceMetrics,
+	logger common.Logger,
+	verifier GroundingVerifier,
+) (ReportGenerator, error) {
+	if verifier == nil {
+		return nil, errors.NewInvalidInputError("grounding verifier is required")
+	}
+	gen, err := NewReportGenerator(backend, promptMgr, rag, cfg, metrics, logger)
+	if err != nil {
+		return nil, err
+	}
+	gen.(*reportGeneratorImpl).grounding = verifier
+	return gen, nil
+}
+
 // ---------------------------------------------------------------------------
 // GenerateReport — full (non-streaming) report generation
 // ---------------------------------------------------------------------------
@@ -332,10 +358,12 @@ func (g *reportGeneratorImpl) GenerateReport(ctx context.Context, req *ReportReq
 		InputData:   []byte(fullPrompt),
 		InputFormat: common.FormatText,
 		Metadata: map[string]string{
-			"task":       req.Task.String(),
-			"request_id": req.RequestID,
+			"task":             req.Task.String(),
+			"request_id":       req.RequestID,
+			common.MetaFeature: "strategy." + req.Task.String(),
 		},
 	}
+	addCacheMetadata(backendReq.Metadata, promptParams, ragChunks)
 
 	backendResp, err := g.modelBackend.Predict(ctx, backendReq)
 	if err != nil {
@@ -374,7 +402,25 @@ func (g *reportGeneratorImpl) GenerateReport(ctx context.Context, req *ReportReq
 		TokensUsed:  tokenUsage,
 	}
 
-	// 8. Quality check (if requested)
+	// 8. Citation grounding
+	if g.grounding != nil {
+		var subjects []string
+		if req.Params != nil {
+			subjects = req.Params.PatentNumbers
+		}
+		grounding, gErr := g.grounding.Verify(ctx, content, ragChunks, subjects)
+		if gErr != nil {
+			g.logger.Warn("citation grounding failed", "error", gErr)
+		} else {
+			report.Grounding = grounding
+			if grounding.Hallucinated > 0 {
+				g.logger.Warn("report cites unverifiable sources",
+					"report_id", reportID, "hallucinated", grounding.Hallucinated, "stripped", grounding.Stripped)
+			}
+		}
+	}
+
+	// 9. Quality check (if requested)
 	if req.QualityCheck {
 		validation, vErr := g.ValidateReport(report)
 		if vErr != nil {
@@ -384,7 +430,7 @@ func (g *reportGeneratorImpl) GenerateReport(ctx context.Context, req *ReportReq
 		}
 	}
 
-	// 9. Metrics
+	// 10. Metrics
 	g.metrics.RecordInference(ctx, &common.InferenceMetricParams{
 		ModelName:    g.config.ModelID,
 		ModelVersion: "v1",
@@ -432,11 +478,13 @@ func (g *reportGeneratorImpl) GenerateReportStream(ctx context.Context, req *Rep
 		InputData:   []byte(fullPrompt),
 		InputFormat: common.FormatText,
 		Metadata: map[string]string{
-			"task":       req.Task.String(),
-			"request_id": req.RequestID,
-			"stream":     "true",
+			"task":             req.Task.String(),
+			"request_id":       req.RequestID,
+			common.MetaFeature: "strategy." + req.Task.String(),
+			"stream":           "true",
 		},
 	}
+	addCacheMetadata(backendReq.Metadata, promptParams, ragChunks)
 
 	streamCh, err := g.modelBackend.PredictStream(ctx, backendReq)
 	if err != nil {
@@ -522,6 +570,32 @@ func (g *reportGeneratorImpl) GenerateReportStream(ctx context.Context, req *Rep
 // Helpers for Params Mapping
 // ---------------------------------------------------------------------------
 
+// addCacheMetadata tells a caching backend which part of the prompt is the
+// user's query and which RAG documents the answer depends on.
+func addCacheMetadata(meta map[string]string, params *PromptParams, ragChunks []*RAGChunk) {
+	if params != nil && params.UserQuery != "" {
+		meta[common.MetaCacheQuery] = params.UserQuery
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for _, c := range ragChunks {
+		if c == nil {
+			continue
+		}
+		id := c.DocumentID
+		if id == "" {
+			id = c.Metadata["document_id"]
+		}
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		meta[common.MetaRAGDocumentIDs] = strings.Join(ids, ",")
+	}
+}
+
 func (g *reportGeneratorImpl) mapToPromptParams(req *ReportRequest, ragChunks []*RAGChunk) *PromptParams {
 	p := req.Params
 	if p == nil {
@@ -682,7 +756,26 @@ func (g *reportGeneratorImpl) ValidateReport(report *Report) (*ReportValidation,
 	// 2. Citation verification
 	citationScore := 1.0
 	var citVerification *CitationVerificationResult
-	if len(report.Content.Citations) > 0 {
+	ungrounded := 0
+	if report.Grounding != nil {
+		// Grounding already resolved every reference; reuse its verdicts.
+		citationScore = report.Grounding.Score
+		citVerification = groundingCitationSummary(report.Grounding)
+		for _, ref := range report.Grounding.HallucinatedReferences() {
+			issue := &ValidationIssue{
+				IssueType:   "hallucinated_citation",
+				Description: fmt.Sprintf("%s reference %q: %s", ref.Kind, ref.Text, ref.Reason),
+				Severity:    "critical",
+				Location:    ref.SectionID,
+			}
+			if ref.Stripped {
+				issue.IssueType, issue.Severity = "stripped_citation", "low"
+			} else {
+				ungrounded++
+			}
+			issues = append(issues, issue)
+		}
+	} else if len(report.Content.Citations) > 0 {
 		citVerification = g.verifyCitations(report.Content.Citations)
 		if citVerification.TotalCitations > 0 {
 			citationScore = float64(citVerification.VerifiedCount) / float64(citVerification.TotalCitations)
@@ -707,7 +800,8 @@ func (g *reportGeneratorImpl) ValidateReport(report *Report) (*ReportValidation,
 	qualityScore := structureScore*0.3 + citationScore*0.3 + lengthScore*0.2 + actionScore*0.2
 	qualityScore = math.Round(qualityScore*100) / 100
 
-	isValid := len(issues) == 0 || qualityScore >= 0.5
+	// A report still citing nonexistent sources is never valid.
+	isValid := (len(issues) == 0 || qualityScore >= 0.5) && ungrounded == 0
 
 	return &ReportValidation{
 		IsValid:              isValid,
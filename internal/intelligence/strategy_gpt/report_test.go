@@ -256,6 +256,24 @@ func TestGenerateReport_WithoutRAG(t *testing.T) {
 	}
 }
 
+func TestGenerateReport_CacheMetadata(t *testing.T) {
+	gen, backend, _, _ := newTestReportGenerator(t)
+	var meta map[string]string
+	backend.predictFn = func(ctx context.Context, req *common.PredictRequest) (*common.PredictResponse, error) {
+		meta = req.Metadata
+		return defaultLLMResponse(), nil
+	}
+	if _, err := gen.GenerateReport(context.Background(), ftoRequest(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta[common.MetaCacheQuery] != "Novel catalytic converter" {
+		t.Errorf("cache query = %q", meta[common.MetaCacheQuery])
+	}
+	if meta[common.MetaRAGDocumentIDs] != "US12345678,MPEP §2111.03" {
+		t.Errorf("rag document ids = %q", meta[common.MetaRAGDocumentIDs])
+	}
+}
+
 func TestGenerateReport_WithQualityCheck(t *testing.T) {
 	gen, _, _, _ := newTestReportGenerator(t)
 	report, err := gen.GenerateReport(context.Background(), ftoRequest(true))
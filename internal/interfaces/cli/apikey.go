@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/client"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// apiKeyEnvVar holds the credential the CLI authenticates with.
+const apiKeyEnvVar = "KEYIP_API_KEY"
+
+var (
+	apiKeyName          string
+	apiKeyScopes        string
+	apiKeyTier          string
+	apiKeyExpiresInDays int
+	apiKeyGraceHours    int
+)
+
+// apiKeyTable renders a key list for --output table.
+type apiKeyTable []client.APIKey
+
+func (t apiKeyTable) TableHeaders() []string {
+	return []string{"ID", "Name", "Prefix", "Tier", "Scopes", "Status", "Expires", "Last Used"}
+}
+
+func (t apiKeyTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t))
+	for _, k := range t {
+		rows = append(rows, []string{
+			k.ID,
+			k.Name,
+			k.KeyPrefix,
+			k.Tier,
+			strings.Join(k.Scopes, ","),
+			apiKeyStatus(&k),
+			formatOptionalTime(k.ExpiresAt),
+			formatOptionalTime(k.LastUsedAt),
+		})
+	}
+	return rows
+}
+
+// NewAPIKeyCmd creates the apikey command
+func NewAPIKeyCmd() *cobra.Command {
+	apiKeyCmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "Manage API keys for CI and other machine clients",
+		Long: `Issue, list, scope, rotate and revoke long-lived API keys.
+Commands authenticate with the key in the ` + apiKeyEnvVar + ` environment variable.`,
+		Example: `  # Issue a read-only key for CI
+  keyip apikey create --name ci --scopes patent:read,molecule:read --expires-in-days 90
+
+  # List keys
+  keyip apikey list --output table
+
+  # Rotate a key, keeping the old one valid for a day
+  keyip apikey rotate <key-id> --grace-hours 24`,
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Issue a new API key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyCreate(cmd)
+		},
+	}
+	createCmd.Flags().StringVar(&apiKeyName, "name", "", "Key name (required)")
+	createCmd.Flags().StringVar(&apiKeyScopes, "scopes", "", "Comma-separated scopes, e.g. patent:read,molecule:read (required)")
+	createCmd.Flags().StringVar(&apiKeyTier, "tier", "free", "Quota tier: free|professional|enterprise")
+	createCmd.Flags().IntVar(&apiKeyExpiresInDays, "expires-in-days", 0, "Expire the key after N days (0 = never)")
+	createCmd.MarkFlagRequired("name")
+	createCmd.MarkFlagRequired("scopes")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List your API keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyList(cmd)
+		},
+	}
+
+	scopesCmd := &cobra.Command{
+		Use:   "scopes [key-id]",
+		Short: "List grantable scopes, or replace the scopes of a key",
+		Example: `  # List scopes that can be granted
+  keyip apikey scopes
+
+  # Narrow a key to read-only access
+  keyip apikey scopes <key-id> --set patent:read`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyScopes(cmd, args)
+		},
+	}
+	scopesCmd.Flags().StringVar(&apiKeyScopes, "set", "", "Comma-separated scopes to assign to the key")
+
+	rotateCmd := &cobra.Command{
+		Use:   "rotate <key-id>",
+		Short: "Replace a key with a new secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyRotate(cmd, args[0])
+		},
+	}
+	rotateCmd.Flags().IntVar(&apiKeyGraceHours, "grace-hours", 0, "Hours the old key keeps working (0 = revoke now, max 168)")
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke <key-id>",
+		Short: "Permanently disable a key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyRevoke(cmd, args[0])
+		},
+	}
+
+	usageCmd := &cobra.Command{
+		Use:   "usage <key-id>",
+		Short: "Show a key's requests and remaining quota this month",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAPIKeyUsage(cmd, args[0])
+		},
+	}
+
+	apiKeyCmd.AddCommand(createCmd, listCmd, scopesCmd, rotateCmd, revokeCmd, usageCmd)
+	return apiKeyCmd
+}
+
+func runAPIKeyCreate(cmd *cobra.Command) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+	scopes := parseScopeList(apiKeyScopes)
+	if len(scopes) == 0 {
+		return errors.NewMsg("--scopes must list at least one scope")
+	}
+
+	issued, err := keys.Create(cmd.Context(), &client.CreateAPIKeyRequest{
+		Name:          apiKeyName,
+		Scopes:        scopes,
+		Tier:          apiKeyTier,
+		ExpiresInDays: apiKeyExpiresInDays,
+	})
+	if err != nil {
+		return errors.WrapMsg(err, "failed to create API key")
+	}
+	return printIssuedAPIKey(cmd, issued)
+}
+
+func runAPIKeyList(cmd *cobra.Command) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+	list, err := keys.List(cmd.Context())
+	if err != nil {
+		return errors.WrapMsg(err, "failed to list API keys")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, list)
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No API keys found.")
+		return nil
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable(apiKeyTable(list).TableHeaders(), apiKeyTable(list).TableRows()))
+	return nil
+}
+
+func runAPIKeyScopes(cmd *cobra.Command, args []string) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if apiKeyScopes != "" {
+			return errors.NewMsg("--set requires a key id")
+		}
+		scopes, err := keys.Scopes(cmd.Context())
+		if err != nil {
+			return errors.WrapMsg(err, "failed to list scopes")
+		}
+		if isJSONOutput(cmd) {
+			return PrintResult(cmd, scopes)
+		}
+		for _, s := range scopes {
+			fmt.Fprintln(cmd.OutOrStdout(), s)
+		}
+		return nil
+	}
+
+	scopes := parseScopeList(apiKeyScopes)
+	if len(scopes) == 0 {
+		return errors.NewMsg("--set must list at least one scope")
+	}
+	key, err := keys.UpdateScopes(cmd.Context(), args[0], scopes)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to update API key scopes")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, key)
+	}
+	PrintSuccess(cmd, fmt.Sprintf("scopes of %s set to %s", key.ID, strings.Join(key.Scopes, ",")))
+	return nil
+}
+
+func runAPIKeyRotate(cmd *cobra.Command, keyID string) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+	if apiKeyGraceHours < 0 {
+		return errors.NewMsg("--grace-hours cannot be negative")
+	}
+	issued, err := keys.Rotate(cmd.Context(), keyID, time.Duration(apiKeyGraceHours)*time.Hour)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to rotate API key")
+	}
+	return printIssuedAPIKey(cmd, issued)
+}
+
+func runAPIKeyRevoke(cmd *cobra.Command, keyID string) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+	if err := keys.Revoke(cmd.Context(), keyID); err != nil {
+		return errors.WrapMsg(err, "failed to revoke API key")
+	}
+	PrintSuccess(cmd, fmt.Sprintf("API key %s revoked", keyID))
+	return nil
+}
+
+func runAPIKeyUsage(cmd *cobra.Command, keyID string) error {
+	keys, err := apiKeysClient(cmd)
+	if err != nil {
+		return err
+	}
+	usage, err := keys.Usage(cmd.Context(), keyID)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to get API key usage")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, usage)
+	}
+
+	quota, remaining := "unlimited", "unlimited"
+	if usage.Remaining >= 0 {
+		quota = strconv.FormatInt(usage.Quota, 10)
+		remaining = strconv.FormatInt(usage.Remaining, 10)
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Key:        %s\n", usage.KeyID)
+	fmt.Fprintf(out, "Period:     %s\n", usage.Period.Format("2006-01"))
+	fmt.Fprintf(out, "Used:       %d\n", usage.Used)
+	fmt.Fprintf(out, "Quota:      %s\n", quota)
+	fmt.Fprintf(out, "Remaining:  %s\n", remaining)
+	fmt.Fprintf(out, "Rate limit: %d req/min\n", usage.RateLimit)
+	return nil
+}
+
+// apiKeysClient returns the API key sub-client, failing with a hint when the
+// CLI has no credential configured.
+func apiKeysClient(cmd *cobra.Command) (*client.APIKeysClient, error) {
+	cliCtx, err := GetCLIContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if cliCtx.Client == nil {
+		return nil, errors.Errorf("API client unavailable; set %s to an existing API key", apiKeyEnvVar)
+	}
+	return cliCtx.Client.APIKeys(), nil
+}
+
+func printIssuedAPIKey(cmd *cobra.Command, issued *client.IssuedAPIKey) error {
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, issued)
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "ID:      %s\n", issued.Key.ID)
+	fmt.Fprintf(out, "Name:    %s\n", issued.Key.Name)
+	fmt.Fprintf(out, "Tier:    %s\n", issued.Key.Tier)
+	fmt.Fprintf(out, "Scopes:  %s\n", strings.Join(issued.Key.Scopes, ","))
+	fmt.Fprintf(out, "Expires: %s\n", formatOptionalTime(issued.Key.ExpiresAt))
+	fmt.Fprintf(out, "Secret:  %s\n", issued.Secret)
+	fmt.Fprintln(cmd.ErrOrStderr(), "Store the secret now; it cannot be shown again.")
+	return nil
+}
+
+func isJSONOutput(cmd *cobra.Command) bool {
+	cliCtx, err := GetCLIContext(cmd)
+	return err == nil && strings.EqualFold(cliCtx.OutputFormat, "json")
+}
+
+func parseScopeList(s string) []string {
+	var scopes []string
+	for _, part := range strings.Split(s, ",") {
+		if p := strings.TrimSpace(part); p != "" {
+			scopes = append(scopes, p)
+		}
+	}
+	return scopes
+}
+
+func apiKeyStatus(k *client.APIKey) string {
+	switch {
+	case k.RevokedAt != nil || !k.IsActive:
+		return "revoked"
+	case k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/client"
+)
+
+func newAPIKeyTestCmd(t *testing.T, handler http.HandlerFunc, format string) (*cobra.Command, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient(srv.URL, "kip_test")
+	require.NoError(t, err)
+
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.WithValue(context.Background(), cliContextKey{}, &CLIContext{Client: c, OutputFormat: format}))
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	return cmd, &out, &errOut
+}
+
+func writeAPIKeyJSON(t *testing.T, w http.ResponseWriter, status int, data interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"data": data}))
+}
+
+func TestAPIKeyCreate_PrintsSecretOnce(t *testing.T) {
+	var body map[string]interface{}
+	cmd, out, errOut := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer kip_test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		writeAPIKeyJSON(t, w, http.StatusCreated, map[string]interface{}{
+			"key":    map[string]interface{}{"id": "k-1", "name": "ci", "tier": "free", "scopes": []string{"patent:read", "molecule:read"}},
+			"secret": "kip_secret",
+		})
+	}, "text")
+
+	apiKeyName, apiKeyScopes, apiKeyTier, apiKeyExpiresInDays = "ci", " patent:read, molecule:read,", "free", 30
+	require.NoError(t, runAPIKeyCreate(cmd))
+
+	assert.Equal(t, []interface{}{"patent:read", "molecule:read"}, body["scopes"])
+	assert.Equal(t, float64(30), body["expires_in_days"])
+	assert.Contains(t, out.String(), "Secret:  kip_secret")
+	assert.Contains(t, errOut.String(), "cannot be shown again")
+}
+
+func TestAPIKeyList_Table(t *testing.T) {
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"api_keys": []map[string]interface{}{
+			{"id": "k-1", "name": "ci", "key_prefix": "kip_abcd1234", "tier": "free", "is_active": true},
+			{"id": "k-2", "name": "old", "key_prefix": "kip_efgh5678", "tier": "free", "is_active": false},
+		}})
+	}, "table")
+
+	require.NoError(t, runAPIKeyList(cmd))
+	assert.Contains(t, out.String(), "kip_abcd1234")
+	assert.Contains(t, out.String(), "active")
+	assert.Contains(t, out.String(), "revoked")
+}
+
+func TestAPIKeyUsage_Unlimited(t *testing.T) {
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/api-keys/k-1/usage", r.URL.Path)
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"key_id": "k-1", "used": 12, "quota": 0, "remaining": -1, "rate_limit": 60})
+	}, "text")
+
+	require.NoError(t, runAPIKeyUsage(cmd, "k-1"))
+	assert.Contains(t, out.String(), "Used:       12")
+	assert.Contains(t, out.String(), "Remaining:  unlimited")
+}
+
+func TestAPIKeyCommands_NoClient(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.WithValue(context.Background(), cliContextKey{}, &CLIContext{}))
+
+	err := runAPIKeyRevoke(cmd, "k-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), apiKeyEnvVar)
+}
+
+func TestParseScopeList(t *testing.T) {
+	assert.Equal(t, []string{"a:read", "b:write"}, parseScopeList(" a:read,,b:write "))
+	assert.Nil(t, parseScopeList(""))
+}
+
+//Personal.AI order the ending
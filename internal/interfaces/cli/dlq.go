@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/client"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+var (
+	dlqEventTypes   string
+	dlqErrorClasses string
+	dlqSince        string
+	dlqUntil        string
+	dlqBefore       string
+	dlqLimit        int
+	dlqRate         float64
+	dlqDryRun       bool
+	dlqConfirm      bool
+)
+
+// dlqTopicTable renders dead letter topics for --output table.
+type dlqTopicTable []client.DLQTopic
+
+func (t dlqTopicTable) TableHeaders() []string {
+	return []string{"Topic", "Original Topic", "Partitions", "Pending", "Retained"}
+}
+
+func (t dlqTopicTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t))
+	for _, d := range t {
+		original := d.OriginalTopic
+		if original == "" {
+			original = "-"
+		}
+		rows = append(rows, []string{
+			d.Topic,
+			original,
+			strconv.Itoa(d.Partitions),
+			strconv.FormatInt(d.Pending, 10),
+			strconv.FormatInt(d.Total, 10),
+		})
+	}
+	return rows
+}
+
+// deadLetterTable renders dead letters for --output table.
+type deadLetterTable []client.DeadLetter
+
+func (t deadLetterTable) TableHeaders() []string {
+	return []string{"Partition/Offset", "Event Type", "Error Class", "Attempts", "Replays", "Failed At", "Error"}
+}
+
+func (t deadLetterTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t))
+	for _, d := range t {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d/%d", d.Partition, d.Offset),
+			orDash(d.EventType),
+			orDash(d.ErrorClass),
+			strconv.Itoa(d.Attempts),
+			strconv.Itoa(d.ReplayCount),
+			d.FailedAt.Local().Format("2006-01-02 15:04:05"),
+			truncateString(strings.ReplaceAll(d.ErrorMessage, "\n", " "), 60),
+		})
+	}
+	return rows
+}
+
+// NewDLQCmd creates the dlq command
+func NewDLQCmd() *cobra.Command {
+	dlqCmd := &cobra.Command{
+		Use:   "dlq",
+		Short: "Inspect, replay and purge dead-lettered messages",
+		Long: `Operate on dead letter topics (<topic>.dlq), which receive messages whose
+processing failed after all retries. Each dead letter records the original
+topic, error message, error class and failure time.
+
+Reading needs the system:monitor permission; replay and purge need
+system:config. Commands authenticate with the key in the ` + apiKeyEnvVar + `
+environment variable.`,
+		Example: `  # Show the backlog of every dead letter topic
+  keyip dlq list --output table
+
+  # Look at timeouts from the last day
+  keyip dlq inspect patent.new.dlq --error-class timeout --since 24h
+
+  # Preview, then replay patent.created failures at 20 messages per second
+  keyip dlq replay patent.new.dlq --event-type patent.created --dry-run
+  keyip dlq replay patent.new.dlq --event-type patent.created --rate 20
+
+  # Discard dead letters older than a week
+  keyip dlq purge patent.new.dlq --before 168h --yes`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List dead letter topics and their pending messages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDLQList(cmd)
+		},
+	}
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect <dlq-topic>",
+		Short: "Show pending dead letters with their failure reasons",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDLQInspect(cmd, args[0])
+		},
+	}
+	addDLQFilterFlags(inspectCmd)
+	inspectCmd.Flags().IntVar(&dlqLimit, "limit", 20, "Maximum messages to show (max 500)")
+
+	replayCmd := &cobra.Command{
+		Use:   "replay <dlq-topic>",
+		Short: "Re-publish pending dead letters to their original topic",
+		Long: `Re-publish pending dead letters that match the filters to their original
+topic, rate limited. Replayed messages carry a dlq_replay_count header.
+Messages that do not match stay pending.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDLQReplay(cmd, args[0])
+		},
+	}
+	addDLQFilterFlags(replayCmd)
+	replayCmd.Flags().Float64Var(&dlqRate, "rate", 0, "Messages per second (0 = server default)")
+	replayCmd.Flags().IntVar(&dlqLimit, "limit", 0, "Stop after this many messages (0 = no limit)")
+	replayCmd.Flags().BoolVar(&dlqDryRun, "dry-run", false, "Only count the messages that would be replayed")
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge <dlq-topic>",
+		Short: "Discard pending dead letters without replaying them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDLQPurge(cmd, args[0])
+		},
+	}
+	purgeCmd.Flags().StringVar(&dlqBefore, "before", "", "Only purge messages that failed before this time (RFC 3339, or a duration ago such as 168h)")
+	purgeCmd.Flags().BoolVar(&dlqConfirm, "yes", false, "Confirm the purge")
+
+	dlqCmd.AddCommand(listCmd, inspectCmd, replayCmd, purgeCmd)
+	return dlqCmd
+}
+
+func addDLQFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dlqEventTypes, "event-type", "", "Comma-separated event types to match")
+	cmd.Flags().StringVar(&dlqErrorClasses, "error-class", "", "Comma-separated error classes: timeout,canceled,decode,validation,not_found,dependency,handler")
+	cmd.Flags().StringVar(&dlqSince, "since", "", "Failed at or after (RFC 3339, or a duration ago such as 24h)")
+	cmd.Flags().StringVar(&dlqUntil, "until", "", "Failed before (RFC 3339, or a duration ago such as 1h)")
+}
+
+func runDLQList(cmd *cobra.Command) error {
+	dlq, err := dlqClient(cmd)
+	if err != nil {
+		return err
+	}
+	topics, err := dlq.List(cmd.Context())
+	if err != nil {
+		return errors.WrapMsg(err, "failed to list dead letter topics")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, topics)
+	}
+	if len(topics) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No dead letter topics found.")
+		return nil
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable(dlqTopicTable(topics).TableHeaders(), dlqTopicTable(topics).TableRows()))
+	return nil
+}
+
+func runDLQInspect(cmd *cobra.Command, topic string) error {
+	dlq, err := dlqClient(cmd)
+	if err != nil {
+		return err
+	}
+	filter, err := dlqFilterFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+	if dlqLimit < 0 {
+		return errors.NewMsg("--limit cannot be negative")
+	}
+
+	letters, err := dlq.Inspect(cmd.Context(), topic, filter, dlqLimit)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to inspect dead letters")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, letters)
+	}
+	if len(letters) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No pending dead letters match.")
+		return nil
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable(deadLetterTable(letters).TableHeaders(), deadLetterTable(letters).TableRows()))
+	return nil
+}
+
+func runDLQReplay(cmd *cobra.Command, topic string) error {
+	dlq, err := dlqClient(cmd)
+	if err != nil {
+		return err
+	}
+	filter, err := dlqFilterFromFlags(time.Now())
+	if err != nil {
+		return err
+	}
+	if dlqRate < 0 || dlqLimit < 0 {
+		return errors.NewMsg("--rate and --limit cannot be negative")
+	}
+
+	req := &client.ReplayDLQRequest{
+		EventTypes:    filter.EventTypes,
+		ErrorClasses:  filter.ErrorClasses,
+		RatePerSecond: dlqRate,
+		Limit:         dlqLimit,
+		DryRun:        dlqDryRun,
+	}
+	if !filter.Since.IsZero() {
+		req.Since = &filter.Since
+	}
+	if !filter.Until.IsZero() {
+		req.Until = &filter.Until
+	}
+
+	res, err := dlq.Replay(cmd.Context(), topic, req)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to replay dead letters")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, res)
+	}
+	if res.DryRun {
+		PrintSuccess(cmd, fmt.Sprintf("dry run: %d of %d scanned messages would be replayed", res.Replayed, res.Scanned))
+		return nil
+	}
+	PrintSuccess(cmd, fmt.Sprintf("replayed %d of %d scanned messages from %s; %d no longer pending",
+		res.Replayed, res.Scanned, res.Topic, res.Resolved))
+	return nil
+}
+
+func runDLQPurge(cmd *cobra.Command, topic string) error {
+	if !dlqConfirm {
+		return errors.NewMsg("purge discards dead letters permanently; pass --yes to confirm")
+	}
+	dlq, err := dlqClient(cmd)
+	if err != nil {
+		return err
+	}
+	before, err := parseDLQTime(dlqBefore, "--before", time.Now())
+	if err != nil {
+		return err
+	}
+
+	res, err := dlq.Purge(cmd.Context(), topic, before)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to purge dead letters")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, res)
+	}
+	PrintSuccess(cmd, fmt.Sprintf("purged %d dead letters from %s", res.Purged, res.Topic))
+	return nil
+}
+
+// dlqClient returns the DLQ sub-client, failing with a hint when the CLI
+// has no credential configured.
+func dlqClient(cmd *cobra.Command) (*client.DLQClient, error) {
+	cliCtx, err := GetCLIContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if cliCtx.Client == nil {
+		return nil, errors.Errorf("API client unavailable; set %s to an API key with system scopes", apiKeyEnvVar)
+	}
+	return cliCtx.Client.DLQ(), nil
+}
+
+func dlqFilterFromFlags(now time.Time) (client.DLQFilter, error) {
+	filter := client.DLQFilter{
+		EventTypes:   parseScopeList(dlqEventTypes),
+		ErrorClasses: parseScopeList(dlqErrorClasses),
+	}
+	var err error
+	if filter.Since, err = parseDLQTime(dlqSince, "--since", now); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseDLQTime(dlqUntil, "--until", now); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseDLQTime accepts an RFC 3339 timestamp or a duration before now.
+func parseDLQTime(v, flag string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil && d > 0 {
+		return now.Add(-d).Truncate(time.Second), nil
+	}
+	return time.Time{}, errors.Errorf("%s must be an RFC 3339 time or a positive duration such as 24h", flag)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetDLQFlags() {
+	dlqEventTypes, dlqErrorClasses, dlqSince, dlqUntil, dlqBefore = "", "", "", "", ""
+	dlqLimit, dlqRate, dlqDryRun, dlqConfirm = 0, 0, false, false
+}
+
+func TestDLQList_Table(t *testing.T) {
+	resetDLQFlags()
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/admin/dlq", r.URL.Path)
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"topics": []map[string]interface{}{
+			{"topic": "patent.new.dlq", "original_topic": "patent.new", "partitions": 3, "total": 9, "pending": 4},
+		}})
+	}, "table")
+
+	require.NoError(t, runDLQList(cmd))
+	assert.Contains(t, out.String(), "patent.new.dlq")
+	assert.Contains(t, out.String(), "4")
+}
+
+func TestDLQInspect_Filters(t *testing.T) {
+	resetDLQFlags()
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/api/v1/admin/dlq/patent.new.dlq/messages", r.URL.Path)
+		assert.Equal(t, "timeout,dependency", q.Get("error_class"))
+		assert.Equal(t, "2026-03-01T00:00:00Z", q.Get("since"))
+		assert.Equal(t, "5", q.Get("limit"))
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"messages": []map[string]interface{}{
+			{"partition": 1, "offset": 7, "event_type": "patent.created", "error_class": "timeout",
+				"error_message": "context deadline exceeded", "failed_at": "2026-03-01T10:00:00Z"},
+		}})
+	}, "table")
+
+	dlqErrorClasses, dlqSince, dlqLimit = "timeout, dependency", "2026-03-01T00:00:00Z", 5
+	require.NoError(t, runDLQInspect(cmd, "patent.new.dlq"))
+	assert.Contains(t, out.String(), "1/7")
+	assert.Contains(t, out.String(), "context deadline exceeded")
+}
+
+func TestDLQReplay_DryRun(t *testing.T) {
+	resetDLQFlags()
+	var body map[string]interface{}
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/admin/dlq/patent.new.dlq/replay", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"topic": "patent.new.dlq", "scanned": 10, "replayed": 6, "dry_run": true})
+	}, "text")
+
+	dlqEventTypes, dlqRate, dlqDryRun = "patent.created", 20, true
+	require.NoError(t, runDLQReplay(cmd, "patent.new.dlq"))
+	assert.Equal(t, []interface{}{"patent.created"}, body["event_types"])
+	assert.Equal(t, float64(20), body["rate_per_second"])
+	assert.Equal(t, true, body["dry_run"])
+	assert.NotContains(t, body, "since")
+	assert.Contains(t, out.String(), "6 of 10")
+}
+
+func TestDLQPurge_RequiresConfirmation(t *testing.T) {
+	resetDLQFlags()
+	cmd, _, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	}, "text")
+
+	err := runDLQPurge(cmd, "patent.new.dlq")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--yes")
+}
+
+func TestDLQPurge_Before(t *testing.T) {
+	resetDLQFlags()
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "2026-01-01T00:00:00Z", r.URL.Query().Get("before"))
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"topic": "patent.new.dlq", "purged": 12})
+	}, "text")
+
+	dlqBefore, dlqConfirm = "2026-01-01T00:00:00Z", true
+	require.NoError(t, runDLQPurge(cmd, "patent.new.dlq"))
+	assert.Contains(t, out.String(), "purged 12")
+}
+
+func TestParseDLQTime(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseDLQTime("24h", "--since", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-24*time.Hour), got)
+
+	got, err = parseDLQTime("", "--since", now)
+	require.NoError(t, err)
+	assert.True(t, got.IsZero())
+
+	_, err = parseDLQTime("-1h", "--since", now)
+	assert.Error(t, err)
+	_, err = parseDLQTime("yesterday", "--since", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,437 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/strategy_gpt"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const (
+	defaultGoldenSetPath      = "configs/prompts/golden_set.json"
+	defaultPromptRegistryPath = "configs/prompts/registry.json"
+	defaultEvalBaselinePath   = "configs/prompts/eval_baseline.json"
+)
+
+var (
+	promptRegistryPath  string
+	promptGoldenPath    string
+	promptBaselinePath  string
+	promptBackend       string
+	promptTolerance     float64
+	promptMinScore      float64
+	promptWriteBaseline bool
+	promptFile          string
+	promptDescription   string
+	promptAuthor        string
+)
+
+// promptEvalTable renders per-case eval results for --output table.
+type promptEvalTable []*strategy_gpt.CaseResult
+
+func (t promptEvalTable) TableHeaders() []string {
+	return []string{"Case", "Task", "Prompt", "Score", "Notes"}
+}
+
+func (t promptEvalTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t))
+	for _, c := range t {
+		note := c.Error
+		if note == "" {
+			for _, s := range c.Scores {
+				if len(s.Notes) > 0 {
+					note = s.Notes[0]
+					break
+				}
+			}
+		}
+		rows = append(rows, []string{
+			c.ID,
+			c.Task,
+			orDash(c.TemplateVersion),
+			strconv.FormatFloat(c.Score, 'f', 3, 64),
+			orDash(truncateString(note, 60)),
+		})
+	}
+	return rows
+}
+
+// promptHistoryTable renders prompt versions for --output table.
+type promptHistoryTable struct {
+	versions []*strategy_gpt.PromptVersion
+	active   int
+}
+
+func (t promptHistoryTable) TableHeaders() []string {
+	return []string{"Version", "Active", "Author", "Created", "Digest", "Description"}
+}
+
+func (t promptHistoryTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t.versions))
+	for _, v := range t.versions {
+		active, created := "", "-"
+		if v.Version == t.active {
+			active = "*"
+		}
+		if !v.CreatedAt.IsZero() {
+			created = v.CreatedAt.Local().Format("2006-01-02 15:04")
+		}
+		rows = append(rows, []string{
+			v.Label(),
+			active,
+			orDash(v.Author),
+			created,
+			v.Digest[:12],
+			orDash(truncateString(v.Description, 50)),
+		})
+	}
+	return rows
+}
+
+// NewPromptCmd creates the prompt command
+func NewPromptCmd() *cobra.Command {
+	promptCmd := &cobra.Command{
+		Use:   "prompt",
+		Short: "Version, evaluate and roll back StrategyGPT prompts",
+		Long: `Manage the versioned system prompts used by StrategyGPT. Every published
+prompt is an immutable version per analysis task (FTO@v3); activating an older
+version rolls back.
+
+"prompt eval" replays a golden set of patent and molecule cases through the
+active prompts and a model backend, scores the answers for JSON-schema
+validity, citation coverage, claim-element recall and rubric checks, and
+fails when any score drops below the stored baseline.`,
+		Example: `  # Gate a prompt change in CI with the offline mock backend
+  keyip prompt eval
+
+  # Record a new baseline after an intended change
+  keyip prompt eval --write-baseline
+
+  # Publish a new FTO prompt, compare it, and roll back
+  keyip prompt publish FTO --file fto.txt --description "tighter risk rubric"
+  keyip prompt diff FTO 1 2
+  keyip prompt activate FTO 1`,
+	}
+
+	evalCmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Score the active prompts against the golden set",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptEval(cmd)
+		},
+	}
+	evalCmd.Flags().StringVar(&promptGoldenPath, "golden", defaultGoldenSetPath, "Golden set file")
+	evalCmd.Flags().StringVar(&promptBaselinePath, "baseline", defaultEvalBaselinePath, "Baseline report to compare against")
+	evalCmd.Flags().StringVar(&promptBackend, "backend", "mock", "Model backend: mock (offline) or llm (configured primary LLM)")
+	evalCmd.Flags().Float64Var(&promptTolerance, "tolerance", 0.01, "Allowed score drop before a metric counts as regressed")
+	evalCmd.Flags().Float64Var(&promptMinScore, "min-score", 0, "Fail when the overall score is below this value")
+	evalCmd.Flags().BoolVar(&promptWriteBaseline, "write-baseline", false, "Write this run as the new baseline instead of comparing")
+
+	historyCmd := &cobra.Command{
+		Use:   "history <task>",
+		Short: "List the versions of a task's prompt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptHistory(cmd, args[0])
+		},
+	}
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <task> <from-version> <to-version>",
+		Short: "Show a unified diff between two prompt versions",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptDiff(cmd, args[0], args[1], args[2])
+		},
+	}
+
+	publishCmd := &cobra.Command{
+		Use:   "publish <task>",
+		Short: "Publish a prompt file as the next active version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptPublish(cmd, args[0])
+		},
+	}
+	publishCmd.Flags().StringVar(&promptFile, "file", "", "File containing the prompt body (required)")
+	publishCmd.Flags().StringVar(&promptDescription, "description", "", "What changed in this version")
+	publishCmd.Flags().StringVar(&promptAuthor, "author", os.Getenv("USER"), "Author recorded with the version")
+
+	activateCmd := &cobra.Command{
+		Use:   "activate <task> <version>",
+		Short: "Make an existing version active (rollback)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPromptActivate(cmd, args[0], args[1])
+		},
+	}
+
+	promptCmd.PersistentFlags().StringVar(&promptRegistryPath, "registry", defaultPromptRegistryPath, "Prompt registry file")
+	promptCmd.AddCommand(evalCmd, historyCmd, diffCmd, publishCmd, activateCmd)
+	return promptCmd
+}
+
+func runPromptEval(cmd *cobra.Command) error {
+	if promptTolerance < 0 {
+		return errors.NewMsg("--tolerance cannot be negative")
+	}
+	set, err := strategy_gpt.LoadGoldenSet(promptGoldenPath)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to load golden set")
+	}
+	registry, err := loadPromptRegistry(false)
+	if err != nil {
+		return err
+	}
+	pm, err := strategy_gpt.NewPromptManagerWithRegistry(nil, registry)
+	if err != nil {
+		return err
+	}
+	backend, evalCfg, err := promptEvalBackend(cmd)
+	if err != nil {
+		return err
+	}
+	defer backend.Close()
+
+	evaluator, err := strategy_gpt.NewPromptEvaluator(pm, backend, evalCfg)
+	if err != nil {
+		return err
+	}
+	report, err := evaluator.Run(cmd.Context(), set)
+	if err != nil {
+		return errors.WrapMsg(err, "prompt evaluation failed")
+	}
+
+	if promptWriteBaseline {
+		if err := report.WriteFile(promptBaselinePath); err != nil {
+			return errors.WrapMsg(err, "failed to write baseline")
+		}
+	}
+
+	var regressions []strategy_gpt.EvalRegression
+	baselineFound := false
+	if !promptWriteBaseline {
+		baseline, err := strategy_gpt.LoadEvalReport(promptBaselinePath)
+		switch {
+		case err == nil:
+			baselineFound = true
+			regressions = strategy_gpt.CompareEvalReports(baseline, report, promptTolerance)
+		case os.IsNotExist(err):
+		default:
+			return errors.WrapMsg(err, "failed to load baseline")
+		}
+	}
+
+	if isJSONOutput(cmd) {
+		if err := PrintResult(cmd, map[string]interface{}{"report": report, "regressions": regressions}); err != nil {
+			return err
+		}
+	} else {
+		printPromptEvalReport(cmd, report, regressions, baselineFound)
+	}
+
+	if len(regressions) > 0 {
+		return errors.Errorf("prompt evaluation regressed on %d metrics (tolerance %.3f)", len(regressions), promptTolerance)
+	}
+	if report.Overall < promptMinScore {
+		return errors.Errorf("overall score %.3f is below --min-score %.3f", report.Overall, promptMinScore)
+	}
+	return nil
+}
+
+func printPromptEvalReport(cmd *cobra.Command, report *strategy_gpt.EvalReport, regressions []strategy_gpt.EvalRegression, baselineFound bool) {
+	out := cmd.OutOrStdout()
+	fmt.Fprint(out, FormatTable(promptEvalTable(report.Cases).TableHeaders(), promptEvalTable(report.Cases).TableRows()))
+
+	fmt.Fprintf(out, "\nOverall: %.3f (%d cases, model %s)\n", report.Overall, len(report.Cases), report.Model)
+	for _, name := range sortedScoreKeys(report.Scorers) {
+		fmt.Fprintf(out, "  %-22s %.3f\n", name, report.Scorers[name])
+	}
+
+	switch {
+	case promptWriteBaseline:
+		PrintSuccess(cmd, "baseline written to "+promptBaselinePath)
+	case !baselineFound:
+		fmt.Fprintf(out, "\nNo baseline at %s; run with --write-baseline to record one.\n", promptBaselinePath)
+	case len(regressions) == 0:
+		PrintSuccess(cmd, "no regressions against "+promptBaselinePath)
+	default:
+		rows := make([][]string, 0, len(regressions))
+		for _, r := range regressions {
+			rows = append(rows, []string{
+				r.Metric,
+				strconv.FormatFloat(r.Baseline, 'f', 3, 64),
+				strconv.FormatFloat(r.Current, 'f', 3, 64),
+				strconv.FormatFloat(r.Delta(), 'f', 3, 64),
+			})
+		}
+		fmt.Fprintln(out, "\nRegressions:")
+		fmt.Fprint(out, FormatTable([]string{"Metric", "Baseline", "Current", "Delta"}, rows))
+	}
+}
+
+func runPromptHistory(cmd *cobra.Command, taskArg string) error {
+	task, err := parsePromptTask(taskArg)
+	if err != nil {
+		return err
+	}
+	registry, err := loadPromptRegistry(false)
+	if err != nil {
+		return err
+	}
+	versions := registry.History(task)
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, versions)
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No prompt versions for %s.\n", task)
+		return nil
+	}
+	table := promptHistoryTable{versions: versions}
+	if active, err := registry.Active(task); err == nil {
+		table.active = active.Version
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable(table.TableHeaders(), table.TableRows()))
+	return nil
+}
+
+func runPromptDiff(cmd *cobra.Command, taskArg, fromArg, toArg string) error {
+	task, err := parsePromptTask(taskArg)
+	if err != nil {
+		return err
+	}
+	registry, err := loadPromptRegistry(false)
+	if err != nil {
+		return err
+	}
+	var versions [2]*strategy_gpt.PromptVersion
+	for i, arg := range []string{fromArg, toArg} {
+		n, err := strconv.Atoi(strings.TrimPrefix(arg, "v"))
+		if err != nil {
+			return errors.Errorf("invalid version %q", arg)
+		}
+		if versions[i], err = registry.Get(task, n); err != nil {
+			return err
+		}
+	}
+	fmt.Fprint(cmd.OutOrStdout(), strategy_gpt.DiffPromptVersions(versions[0], versions[1]))
+	return nil
+}
+
+func runPromptPublish(cmd *cobra.Command, taskArg string) error {
+	task, err := parsePromptTask(taskArg)
+	if err != nil {
+		return err
+	}
+	if promptFile == "" {
+		return errors.NewMsg("--file is required")
+	}
+	body, err := os.ReadFile(promptFile)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to read prompt file")
+	}
+	registry, err := loadPromptRegistry(true)
+	if err != nil {
+		return err
+	}
+	v, err := registry.Publish(task, string(body), promptDescription, promptAuthor)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to publish prompt")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, v)
+	}
+	PrintSuccess(cmd, fmt.Sprintf("%s is active (digest %s)", v.Label(), v.Digest[:12]))
+	return nil
+}
+
+func runPromptActivate(cmd *cobra.Command, taskArg, versionArg string) error {
+	task, err := parsePromptTask(taskArg)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(versionArg, "v"))
+	if err != nil {
+		return errors.Errorf("invalid version %q", versionArg)
+	}
+	registry, err := loadPromptRegistry(true)
+	if err != nil {
+		return err
+	}
+	if err := registry.Activate(task, n); err != nil {
+		return errors.WrapMsg(err, "failed to activate prompt")
+	}
+	PrintSuccess(cmd, fmt.Sprintf("%s@v%d is active", task, n))
+	return nil
+}
+
+// loadPromptRegistry opens the registry file. Read-only commands fall back
+// to the built-in prompts when the file does not exist; writes create it.
+func loadPromptRegistry(create bool) (strategy_gpt.PromptRegistry, error) {
+	if !create {
+		if _, err := os.Stat(promptRegistryPath); os.IsNotExist(err) {
+			return strategy_gpt.NewPromptRegistry(), nil
+		}
+	}
+	registry, err := strategy_gpt.OpenPromptRegistry(promptRegistryPath)
+	if err != nil {
+		return nil, errors.WrapMsg(err, "failed to open prompt registry")
+	}
+	return registry, nil
+}
+
+// promptEvalBackend selects the backend for prompt eval. The mock backend
+// needs no credentials and is what CI runs.
+func promptEvalBackend(cmd *cobra.Command) (common.ModelBackend, strategy_gpt.EvalConfig, error) {
+	switch strings.ToLower(promptBackend) {
+	case "mock":
+		return strategy_gpt.NewMockEvalBackend(), strategy_gpt.EvalConfig{ModelName: "mock"}, nil
+	case "llm":
+		cliCtx, err := GetCLIContext(cmd)
+		if err != nil {
+			return nil, strategy_gpt.EvalConfig{}, err
+		}
+		if cliCtx.Config == nil {
+			return nil, strategy_gpt.EvalConfig{}, errors.NewMsg("no configuration loaded for the llm backend")
+		}
+		backend, err := common.NewLLMBackend(cliCtx.Config)
+		if err != nil {
+			return nil, strategy_gpt.EvalConfig{}, errors.WrapMsg(err, "failed to create LLM backend")
+		}
+		primary := cliCtx.Config.LLM.Primary
+		provider := strings.ToLower(primary.Provider)
+		return backend, strategy_gpt.EvalConfig{
+			ModelName:          primary.ModelName,
+			InlineSystemPrompt: provider == "openai" || provider == "deepseek",
+		}, nil
+	}
+	return nil, strategy_gpt.EvalConfig{}, errors.Errorf("unknown --backend %q (want mock or llm)", promptBackend)
+}
+
+func parsePromptTask(s string) (strategy_gpt.AnalysisTask, error) {
+	var task strategy_gpt.AnalysisTask
+	if err := json.Unmarshal([]byte(strconv.Quote(s)), &task); err != nil {
+		return 0, errors.Errorf("unknown analysis task %q", s)
+	}
+	return task, nil
+}
+
+func sortedScoreKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGoldenSet = "../../../configs/prompts/golden_set.json"
+
+func resetPromptFlags(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	promptRegistryPath = filepath.Join(dir, "registry.json")
+	promptGoldenPath = testGoldenSet
+	promptBaselinePath = filepath.Join(dir, "baseline.json")
+	promptBackend = "mock"
+	promptTolerance, promptMinScore, promptWriteBaseline = 0.01, 0, false
+	promptFile, promptDescription, promptAuthor = "", "", "tester"
+	return dir
+}
+
+func newPromptTestCmd(format string) (*cobra.Command, *bytes.Buffer) {
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.WithValue(context.Background(), cliContextKey{}, &CLIContext{OutputFormat: format}))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	return cmd, &out
+}
+
+func TestPromptEval_BaselineAndRegression(t *testing.T) {
+	dir := resetPromptFlags(t)
+
+	cmd, out := newPromptTestCmd("text")
+	require.NoError(t, runPromptEval(cmd))
+	assert.Contains(t, out.String(), "No baseline at")
+	assert.NoFileExists(t, promptRegistryPath, "eval must not create the registry")
+
+	promptWriteBaseline = true
+	cmd, out = newPromptTestCmd("text")
+	require.NoError(t, runPromptEval(cmd))
+	assert.Contains(t, out.String(), "baseline written")
+	assert.FileExists(t, promptBaselinePath)
+
+	promptWriteBaseline = false
+	cmd, out = newPromptTestCmd("text")
+	require.NoError(t, runPromptEval(cmd))
+	assert.Contains(t, out.String(), "no regressions")
+
+	// A prompt that drops the Markush guidance fails the gate.
+	promptFile = filepath.Join(dir, "fto.txt")
+	require.NoError(t, os.WriteFile(promptFile, []byte("You are a patent attorney."), 0o644))
+	cmd, out = newPromptTestCmd("text")
+	require.NoError(t, runPromptPublish(cmd, "fto"))
+	assert.Contains(t, out.String(), "FTO@v2 is active")
+
+	cmd, out = newPromptTestCmd("text")
+	err := runPromptEval(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "regressed")
+	assert.Contains(t, out.String(), "case:fto-oled-host-structured")
+	assert.Contains(t, out.String(), "FTO@v2")
+
+	// Rolling back restores the baseline scores.
+	cmd, _ = newPromptTestCmd("text")
+	require.NoError(t, runPromptActivate(cmd, "FTO", "v1"))
+	cmd, _ = newPromptTestCmd("json")
+	require.NoError(t, runPromptEval(cmd))
+}
+
+func TestPromptEval_MinScoreAndBackend(t *testing.T) {
+	resetPromptFlags(t)
+	promptMinScore = 1.1
+	cmd, _ := newPromptTestCmd("text")
+	assert.ErrorContains(t, runPromptEval(cmd), "below --min-score")
+
+	resetPromptFlags(t)
+	promptBackend = "gpt"
+	cmd, _ = newPromptTestCmd("text")
+	assert.ErrorContains(t, runPromptEval(cmd), "unknown --backend")
+}
+
+func TestPromptHistoryAndDiff(t *testing.T) {
+	dir := resetPromptFlags(t)
+	promptFile = filepath.Join(dir, "valuation.txt")
+	require.NoError(t, os.WriteFile(promptFile, []byte("Value the patent."), 0o644))
+	promptDescription = "terse"
+	cmd, _ := newPromptTestCmd("text")
+	require.NoError(t, runPromptPublish(cmd, "valuation"))
+
+	cmd, out := newPromptTestCmd("table")
+	require.NoError(t, runPromptHistory(cmd, "Valuation"))
+	assert.Contains(t, out.String(), "Valuation@v1")
+	assert.Contains(t, out.String(), "Valuation@v2")
+	assert.Contains(t, out.String(), "tester")
+
+	cmd, out = newPromptTestCmd("text")
+	require.NoError(t, runPromptDiff(cmd, "Valuation", "1", "v2"))
+	assert.Contains(t, out.String(), "+++ Valuation@v2")
+	assert.Contains(t, out.String(), "+Value the patent.")
+
+	cmd, _ = newPromptTestCmd("text")
+	assert.Error(t, runPromptDiff(cmd, "Valuation", "1", "9"))
+	assert.ErrorContains(t, runPromptHistory(cmd, "astrology"), "unknown analysis task")
+	assert.ErrorContains(t, runPromptActivate(cmd, "FTO", "latest"), "invalid version")
+
+	promptFile = ""
+	assert.ErrorContains(t, runPromptPublish(cmd, "FTO"), "--file is required")
+}
+
+//Personal.AI order the ending
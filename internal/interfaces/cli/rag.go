@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/patent"
+	pgconn "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/database/postgres/repositories"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/strategy_gpt"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const (
+	defaultRAGCorpusPath    = "data/rag/corpus.json"
+	defaultRAGBenchmarkPath = "configs/rag/benchmark.json"
+)
+
+var (
+	ragOutPath       string
+	ragJurisdictions []string
+	ragLimit         int
+	ragPageSize      int
+	ragEmbedder      string
+	ragHashDims      int
+	ragChunker       string
+	ragChunkSize     int
+	ragChunkOverlap  int
+	ragBenchmarkPath string
+	ragKs            []int
+	ragReportPath    string
+	ragMinMRR        float64
+)
+
+// ragBenchTable renders per-variant retrieval metrics for --output table.
+type ragBenchTable struct {
+	report *strategy_gpt.RetrievalReport
+}
+
+func (t ragBenchTable) TableHeaders() []string {
+	headers := []string{"Variant", "Chunks"}
+	for _, k := range t.report.Ks {
+		headers = append(headers, "Recall@"+strconv.Itoa(k))
+	}
+	return append(headers, "MRR")
+}
+
+func (t ragBenchTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t.report.Variants))
+	for _, v := range t.report.Variants {
+		row := []string{v.Variant, strconv.Itoa(v.Chunks)}
+		for _, k := range t.report.Ks {
+			row = append(row, strconv.FormatFloat(v.Recall[k], 'f', 3, 64))
+		}
+		rows = append(rows, append(row, strconv.FormatFloat(v.MRR, 'f', 3, 64)))
+	}
+	return rows
+}
+
+// NewRAGCmd creates the rag command
+func NewRAGCmd() *cobra.Command {
+	ragCmd := &cobra.Command{
+		Use:   "rag",
+		Short: "Build local RAG corpora and benchmark retrieval quality",
+		Long: `Build and evaluate the retrieval corpus behind StrategyGPT.
+
+"rag index" reads patents from PostgreSQL, splits them with the
+structure-aware chunker (one chunk per claim, worked example and table,
+prose packed by section) and writes a local vector corpus file.
+
+"rag bench" indexes a labelled benchmark of documents and queries under each
+chunking and reranking setting and reports recall@k and MRR, so retrieval
+changes can be compared with numbers instead of guesswork.`,
+		Example: `  # Build a corpus of Chinese patents with the offline hashing embedder
+  keyip rag index --jurisdiction CN --out data/rag/cn.json
+
+  # Use the configured embedding model instead
+  keyip rag index --embedder llm --limit 500
+
+  # Compare chunkers and rerankers on the shipped benchmark
+  keyip rag bench --k 1,3,5`,
+	}
+
+	indexCmd := &cobra.Command{
+		Use:   "index",
+		Short: "Build a local corpus from patents in PostgreSQL",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRAGIndex(cmd)
+		},
+	}
+	indexCmd.Flags().StringVar(&ragOutPath, "out", defaultRAGCorpusPath, "Corpus file to write")
+	indexCmd.Flags().StringSliceVar(&ragJurisdictions, "jurisdiction", nil, "Only index patents from these jurisdictions")
+	indexCmd.Flags().IntVar(&ragLimit, "limit", 0, "Maximum number of patents to index (0 = all)")
+	indexCmd.Flags().IntVar(&ragPageSize, "page-size", 200, "Patents read from the database per page")
+	indexCmd.Flags().StringVar(&ragChunker, "chunker", "structured", "Chunker: structured or fixed")
+
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Report recall@k and MRR per chunker and reranker",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRAGBench(cmd)
+		},
+	}
+	benchCmd.Flags().StringVar(&ragBenchmarkPath, "cases", defaultRAGBenchmarkPath, "Retrieval benchmark file")
+	benchCmd.Flags().IntSliceVar(&ragKs, "k", strategy_gpt.DefaultRecallKs, "Recall cut-offs")
+	benchCmd.Flags().StringVar(&ragReportPath, "report", "", "Also write the full report to this file")
+	benchCmd.Flags().Float64Var(&ragMinMRR, "min-mrr", 0, "Fail when any variant's MRR is below this value")
+
+	ragCmd.PersistentFlags().StringVar(&ragEmbedder, "embedder", "hashing", "Embedder: hashing (offline) or llm (configured embedding model)")
+	ragCmd.PersistentFlags().IntVar(&ragHashDims, "hash-dims", 1024, "Vector size of the hashing embedder")
+	ragCmd.PersistentFlags().IntVar(&ragChunkSize, "chunk-size", 512, "Target chunk size in estimated tokens")
+	ragCmd.PersistentFlags().IntVar(&ragChunkOverlap, "chunk-overlap", 64, "Overlap between prose chunks in estimated tokens")
+	ragCmd.AddCommand(indexCmd, benchCmd)
+	return ragCmd
+}
+
+func runRAGIndex(cmd *cobra.Command) error {
+	if ragLimit < 0 || ragPageSize <= 0 || ragPageSize > 1000 {
+		return errors.NewMsg("--limit must be >= 0 and --page-size between 1 and 1000")
+	}
+	chunker, err := ragDocumentChunker()
+	if err != nil {
+		return err
+	}
+	cliCtx, err := GetCLIContext(cmd)
+	if err != nil {
+		return err
+	}
+	if cliCtx.Config == nil {
+		return errors.NewMsg("no configuration loaded; rag index needs database settings")
+	}
+	embedder, model, closeEmbedder, err := ragTextEmbedder(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+
+	pg := cliCtx.Config.Database.Postgres
+	conn, err := pgconn.NewConnection(pgconn.PostgresConfig{
+		Host:            pg.Host,
+		Port:            pg.Port,
+		Database:        pg.DBName,
+		Username:        pg.User,
+		Password:        pg.Password,
+		SSLMode:         pg.SSLMode,
+		MaxOpenConns:    pg.MaxOpenConns,
+		MaxIdleConns:    pg.MaxIdleConns,
+		ConnMaxLifetime: pg.ConnMaxLifetime,
+		ConnMaxIdleTime: pg.ConnMaxIdleTime,
+	}, cliCtx.Logger)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to connect to postgres")
+	}
+	defer conn.Close()
+	repo := repositories.NewPostgresPatentRepo(conn, cliCtx.Logger)
+
+	corpus := strategy_gpt.NewLocalCorpus(model)
+	corpus.SetChunker(fmt.Sprintf("%s size=%d overlap=%d", ragChunker, ragChunkSize, ragChunkOverlap))
+	engine, err := strategy_gpt.NewRAGEngine(corpus, embedder, nil, chunker, strategy_gpt.DefaultRAGConfig(), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var indexed, skipped int
+	for offset := 0; ; offset += ragPageSize {
+		page, err := repo.Search(cmd.Context(), patent.PatentSearchCriteria{
+			Jurisdictions: ragJurisdictions,
+			Offset:        offset,
+			Limit:         ragPageSize,
+		})
+		if err != nil {
+			return errors.WrapMsg(err, "failed to read patents")
+		}
+		for _, p := range page.Patents {
+			if ragLimit > 0 && indexed+skipped >= ragLimit {
+				break
+			}
+			// Search returns patent rows only; GetByID preloads the claims.
+			if len(p.Claims) == 0 {
+				full, err := repo.GetByID(cmd.Context(), p.ID)
+				if err != nil {
+					return errors.WrapMsg(err, "failed to read claims of "+p.PatentNumber)
+				}
+				p = full
+			}
+			doc := strategy_gpt.PatentDocument(p)
+			if doc.Content == "" {
+				skipped++
+				continue
+			}
+			// A patent whose text cannot be embedded is reported and
+			// skipped rather than aborting a long build.
+			if err := engine.IndexDocument(cmd.Context(), doc); err != nil {
+				if cmd.Context().Err() != nil {
+					return cmd.Context().Err()
+				}
+				cliCtx.Logger.Warn("skipping patent", logging.String("patent_number", p.PatentNumber), logging.Err(err))
+				skipped++
+				continue
+			}
+			indexed++
+		}
+		if !page.HasMore || len(page.Patents) == 0 || (ragLimit > 0 && indexed+skipped >= ragLimit) {
+			break
+		}
+	}
+
+	if err := corpus.SaveFile(ragOutPath); err != nil {
+		return errors.WrapMsg(err, "failed to write corpus")
+	}
+	manifest := corpus.Manifest()
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, map[string]interface{}{"path": ragOutPath, "manifest": manifest, "skipped": skipped})
+	}
+	PrintSuccess(cmd, fmt.Sprintf("indexed %d patents into %d chunks (%s, %d dims) at %s",
+		manifest.Documents, manifest.Chunks, manifest.EmbeddingModel, manifest.Dimensions, ragOutPath))
+	if skipped > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d patents skipped (no text or embedding failed); see the log for details.\n", skipped)
+	}
+	return nil
+}
+
+func runRAGBench(cmd *cobra.Command) error {
+	benchmark, err := strategy_gpt.LoadRetrievalBenchmark(ragBenchmarkPath)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to load retrieval benchmark")
+	}
+	embedder, _, closeEmbedder, err := ragTextEmbedder(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+	report, err := strategy_gpt.RunRetrievalBenchmark(cmd.Context(), benchmark, embedder,
+		strategy_gpt.DefaultRetrievalVariants(ragChunkSize, ragChunkOverlap), ragKs)
+	if err != nil {
+		return errors.WrapMsg(err, "retrieval benchmark failed")
+	}
+	if ragReportPath != "" {
+		if err := report.WriteFile(ragReportPath); err != nil {
+			return errors.WrapMsg(err, "failed to write report")
+		}
+	}
+
+	if isJSONOutput(cmd) {
+		if err := PrintResult(cmd, report); err != nil {
+			return err
+		}
+	} else {
+		out := cmd.OutOrStdout()
+		table := ragBenchTable{report: report}
+		fmt.Fprint(out, FormatTable(table.TableHeaders(), table.TableRows()))
+		fmt.Fprintf(out, "\n%d cases, %d documents, embedder %s\n", len(benchmark.Cases), len(benchmark.Documents), report.EmbeddingModel)
+	}
+
+	for _, v := range report.Variants {
+		if v.MRR < ragMinMRR {
+			return errors.Errorf("variant %s has MRR %.3f, below --min-mrr %.3f", v.Variant, v.MRR, ragMinMRR)
+		}
+	}
+	return nil
+}
+
+// ragDocumentChunker builds the chunker selected by --chunker.
+func ragDocumentChunker() (strategy_gpt.DocumentChunker, error) {
+	switch strings.ToLower(ragChunker) {
+	case "structured":
+		return strategy_gpt.NewStructuredDocumentChunker(strategy_gpt.StructuredChunkerConfig{
+			ChunkSize:    ragChunkSize,
+			ChunkOverlap: ragChunkOverlap,
+		}), nil
+	case "fixed":
+		return strategy_gpt.NewDefaultDocumentChunker(ragChunkSize, ragChunkOverlap), nil
+	}
+	return nil, errors.Errorf("unknown --chunker %q (want structured or fixed)", ragChunker)
+}
+
+// ragTextEmbedder returns the embedder selected by --embedder, its model tag
+// and a func releasing the embedder's backend. The hashing embedder needs no
+// credentials and is what CI runs.
+func ragTextEmbedder(cmd *cobra.Command) (strategy_gpt.TextEmbedder, string, func() error, error) {
+	switch strings.ToLower(ragEmbedder) {
+	case "hashing":
+		e := strategy_gpt.NewHashingTextEmbedder(ragHashDims)
+		id, version := e.EmbeddingModel()
+		return e, common.EmbeddingModelTag(id, version), func() error { return nil }, nil
+	case "llm":
+		cliCtx, err := GetCLIContext(cmd)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if cliCtx.Config == nil {
+			return nil, "", nil, errors.NewMsg("no configuration loaded for the llm embedder")
+		}
+		backend, err := common.NewLLMBackend(cliCtx.Config)
+		if err != nil {
+			return nil, "", nil, errors.WrapMsg(err, "failed to create LLM backend")
+		}
+		client := common.NewEmbeddingClient(cliCtx.Config, backend)
+		if client == nil {
+			backend.Close()
+			return nil, "", nil, errors.NewMsg("llm embedder needs llm.primary.provider to be configured")
+		}
+		id, version := client.EmbeddingModel()
+		return strategy_gpt.NewTextEmbedder(client), common.EmbeddingModelTag(id, version), backend.Close, nil
+	}
+	return nil, "", nil, errors.Errorf("unknown --embedder %q (want hashing or llm)", ragEmbedder)
+}
+
+//Personal.AI order the ending
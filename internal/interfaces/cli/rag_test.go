@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/config"
+)
+
+const testRAGBenchmark = "../../../configs/rag/benchmark.json"
+
+func resetRAGFlags(t *testing.T) {
+	t.Helper()
+	ragBenchmarkPath = testRAGBenchmark
+	ragEmbedder, ragHashDims = "hashing", 1024
+	ragChunker, ragChunkSize, ragChunkOverlap = "structured", 64, 8
+	ragKs = []int{1, 3, 5}
+	ragReportPath, ragMinMRR = "", 0
+}
+
+func newRAGTestCmd(format string) (*cobra.Command, *bytes.Buffer) {
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.WithValue(context.Background(), cliContextKey{}, &CLIContext{OutputFormat: format}))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	return cmd, &out
+}
+
+func TestRAGBench_Table(t *testing.T) {
+	resetRAGFlags(t)
+	ragReportPath = filepath.Join(t.TempDir(), "report.json")
+
+	cmd, out := newRAGTestCmd("table")
+	require.NoError(t, runRAGBench(cmd))
+	for _, want := range []string{"Recall@1", "Recall@5", "MRR", "fixed+lexical", "structured+lexical", "hashing-1024@1"} {
+		assert.Contains(t, out.String(), want)
+	}
+	assert.FileExists(t, ragReportPath)
+}
+
+func TestRAGBench_JSONAndGate(t *testing.T) {
+	resetRAGFlags(t)
+
+	cmd, out := newRAGTestCmd("json")
+	require.NoError(t, runRAGBench(cmd))
+	var report struct {
+		Ks       []int `json:"ks"`
+		Variants []struct {
+			Variant string  `json:"variant"`
+			MRR     float64 `json:"mrr"`
+		} `json:"variants"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, []int{1, 3, 5}, report.Ks)
+	assert.Len(t, report.Variants, 4)
+
+	ragMinMRR = 1.1
+	cmd, _ = newRAGTestCmd("table")
+	err := runRAGBench(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "below --min-mrr")
+}
+
+func TestRAG_InvalidOptions(t *testing.T) {
+	resetRAGFlags(t)
+	ragEmbedder = "bogus"
+	cmd, _ := newRAGTestCmd("table")
+	assert.Error(t, runRAGBench(cmd))
+
+	resetRAGFlags(t)
+	ragChunker = "bogus"
+	_, err := ragDocumentChunker()
+	assert.Error(t, err)
+
+	ragLimit, ragPageSize = 0, 0
+	cmd, _ = newRAGTestCmd("table")
+	assert.Error(t, runRAGIndex(cmd))
+
+	resetRAGFlags(t)
+	ragLimit, ragPageSize = 0, 100
+	cmd, _ = newRAGTestCmd("table")
+	err = runRAGIndex(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no configuration loaded")
+
+	resetRAGFlags(t)
+	ragBenchmarkPath = filepath.Join(t.TempDir(), "missing.json")
+	cmd, _ = newRAGTestCmd("table")
+	assert.Error(t, runRAGBench(cmd))
+}
+
+func TestRAGTextEmbedder_LLMWithoutProvider(t *testing.T) {
+	resetRAGFlags(t)
+	ragEmbedder = "llm"
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.WithValue(context.Background(), cliContextKey{}, &CLIContext{Config: &config.Config{}}))
+
+	_, _, _, err := ragTextEmbedder(cmd)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.primary.provider")
+}
+
+//Personal.AI order the ending
@@ -110,6 +110,18 @@ func NewRootCommand() *cobra.Command {
   # Generate an FTO report
   keyip report generate --type fto --target "CCOC(=O)c1ccccc1" --format pdf
 
+  # Issue a scoped API key for CI
+  keyip apikey create --name ci --scopes patent:read
+
+  # Replay dead-lettered timeouts
+  keyip dlq replay patent.new.dlq --error-class timeout --rate 20
+
+  # LLM cost by model this month
+  keyip usage report --group-by model --output table
+
+  # Gate prompt changes against the golden set
+  keyip prompt eval
+
   # Validate configuration
   keyip config validate
 
@@ -163,6 +175,11 @@ func RegisterCommands(rootCmd *cobra.Command, deps CommandDependencies) {
 		NewCompletionCmd(),
 		NewVersionCmd(),
 		NewConfigCmd(),
+		NewAPIKeyCmd(),
+		NewDLQCmd(),
+		NewUsageCmd(),
+		NewPromptCmd(),
+		NewRAGCmd(),
 		NewSearchCmd(deps.SimilaritySearchService, deps.Logger),
 		NewAssessCmd(deps.ValuationService, deps.Logger),
 		NewLifecycleCmd(
@@ -293,8 +310,9 @@ func initClient(cfg *config.Config, opts *RootOptions) (*client.Client, error) {
 		addr = "http://localhost:8080"
 	}
 
-	// NewClient requires baseURL and apiKey; use empty apiKey for now (CLI may use token auth later)
-	return client.NewClient(addr, "", client.WithTimeout(opts.Timeout))
+	// The server accepts API keys as Bearer tokens; without one the CLI runs
+	// offline-only commands and client-backed commands report the missing key.
+	return client.NewClient(addr, os.Getenv(apiKeyEnvVar), client.WithTimeout(opts.Timeout))
 }
 
 // GetCLIContext extracts CLIContext from a cobra command's context.
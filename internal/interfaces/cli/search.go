@@ -69,8 +69,20 @@ func NewSearchCmd(
   keyip search molecule --smiles "C1=CC=CC=C1" --include-risk
 
   # Output as JSON
-  keyip search molecule --smiles "CCO" --output json`,
+  keyip search molecule --smiles "CCO" --output json
+
+  # Stream a SMILES library through the gRPC server, one NDJSON hit per line
+  keyip search molecule --input library.smi --stream
+
+  # Stream from stdin and write CSV
+  cat library.smi | keyip search molecule --input - --stream --output csv --grpc-addr keyip:9090`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if searchStream || searchInput != "" {
+				if !searchStream {
+					return errors.NewMsg("--input requires --stream")
+				}
+				return runSearchMoleculeStream(cmd, logger)
+			}
 			return runSearchMolecule(cmd.Context(), similaritySearchService, logger)
 		},
 	}
@@ -82,7 +94,10 @@ func NewSearchCmd(
 	moleculeCmd.Flags().IntVar(&searchMaxResults, "max-results", 20, "Maximum number of results (1-500)")
 	moleculeCmd.Flags().StringVar(&searchOffices, "offices", "", "Patent office filter (e.g., CN,US,EP)")
 	moleculeCmd.Flags().BoolVar(&searchIncludeRisk, "include-risk", false, "Include infringement risk assessment")
-	moleculeCmd.Flags().StringVar(&searchOutput, "output", "stdout", "Output format: stdout|json")
+	moleculeCmd.Flags().StringVar(&searchOutput, "output", "stdout", "Output format: stdout|json (with --stream: ndjson|csv)")
+	moleculeCmd.Flags().StringVar(&searchInput, "input", "", "SMILES library file (.smi, one \"SMILES [id]\" per line, - for stdin)")
+	moleculeCmd.Flags().BoolVar(&searchStream, "stream", false, "Stream --input queries over gRPC and print hits as they arrive")
+	moleculeCmd.Flags().StringVar(&searchGRPCAddr, "grpc-addr", "", "gRPC server address (default from config, else "+defaultGRPCAddr+")")
 
 	// Subcommand: search patent
 	patentCmd := &cobra.Command{
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/client"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+const defaultGRPCAddr = "localhost:9090"
+
+var (
+	searchInput    string
+	searchStream   bool
+	searchGRPCAddr string
+)
+
+// dialMoleculeService opens a MoleculeService client for streaming search.
+// Tests replace it to point at an in-memory server.
+var dialMoleculeService = func(ctx context.Context, target string) (pb.MoleculeServiceClient, func() error, error) {
+	gc, err := client.NewGRPCClient(client.GRPCClientConfig{Target: target, EnableRoundRobin: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return gc.Molecules(), gc.Close, nil
+}
+
+// smiQuery is one parsed line of a SMILES library file.
+type smiQuery struct {
+	ID     string
+	SMILES string
+}
+
+// streamSearchRecord is one NDJSON/CSV output row: a hit or a failed query.
+type streamSearchRecord struct {
+	QueryID     string  `json:"query_id"`
+	QuerySMILES string  `json:"query_smiles"`
+	Rank        int     `json:"rank,omitempty"`
+	MoleculeID  string  `json:"molecule_id,omitempty"`
+	SMILES      string  `json:"smiles,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Similarity  float64 `json:"similarity,omitempty"`
+	Method      string  `json:"method,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	ErrorCode   string  `json:"error_code,omitempty"`
+}
+
+var streamSearchCSVHeader = []string{
+	"query_id", "query_smiles", "rank", "molecule_id", "smiles", "name", "similarity", "method", "error", "error_code",
+}
+
+func (r *streamSearchRecord) csvRow() []string {
+	rank, sim := "", ""
+	if r.Error == "" {
+		rank = strconv.Itoa(r.Rank)
+		sim = strconv.FormatFloat(r.Similarity, 'f', 4, 64)
+	}
+	return []string{r.QueryID, r.QuerySMILES, rank, r.MoleculeID, r.SMILES, r.Name, sim, r.Method, r.Error, r.ErrorCode}
+}
+
+// streamSearchWriter writes records as they arrive, flushing after each one
+// so downstream consumers (jq, tail -f) see results immediately.
+type streamSearchWriter interface {
+	Write(rec *streamSearchRecord) error
+}
+
+type ndjsonSearchWriter struct{ enc *json.Encoder }
+
+func (w *ndjsonSearchWriter) Write(rec *streamSearchRecord) error { return w.enc.Encode(rec) }
+
+type csvSearchWriter struct{ w *csv.Writer }
+
+func (w *csvSearchWriter) Write(rec *streamSearchRecord) error {
+	if err := w.w.Write(rec.csvRow()); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func newStreamSearchWriter(out io.Writer, format string) (streamSearchWriter, error) {
+	switch strings.ToLower(format) {
+	case "", "stdout", "ndjson", "json":
+		return &ndjsonSearchWriter{enc: json.NewEncoder(out)}, nil
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(streamSearchCSVHeader); err != nil {
+			return nil, err
+		}
+		w.Flush()
+		return &csvSearchWriter{w: w}, w.Error()
+	default:
+		return nil, errors.Errorf("invalid output format for --stream: %s (must be ndjson|csv)", format)
+	}
+}
+
+// parseSMILine parses one line of a .smi file: a SMILES string optionally
+// followed by whitespace and an identifier. Blank lines and lines starting
+// with '#' yield ok=false.
+func parseSMILine(line string, lineNo int) (smiQuery, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return smiQuery{}, false
+	}
+	fields := strings.Fields(line)
+	q := smiQuery{SMILES: fields[0], ID: "line:" + strconv.Itoa(lineNo)}
+	if len(fields) > 1 {
+		q.ID = strings.Join(fields[1:], " ")
+	}
+	return q, true
+}
+
+// resolveGRPCAddr picks the gRPC target: --grpc-addr, then the loaded
+// configuration, then localhost:9090.
+func resolveGRPCAddr(cmd *cobra.Command) string {
+	if searchGRPCAddr != "" {
+		return searchGRPCAddr
+	}
+	if cliCtx, err := GetCLIContext(cmd); err == nil && cliCtx.Config != nil {
+		g := cliCtx.Config.Server.GRPC
+		if g.Port > 0 {
+			host := g.Host
+			if host == "" || host == "0.0.0.0" {
+				host = "localhost"
+			}
+			return fmt.Sprintf("%s:%d", host, g.Port)
+		}
+	}
+	return defaultGRPCAddr
+}
+
+func runSearchMoleculeStream(cmd *cobra.Command, logger logging.Logger) error {
+	if searchInput == "" {
+		return errors.NewMsg("--stream requires --input")
+	}
+	if searchSMILES != "" || searchInChI != "" {
+		return errors.NewMsg("--input cannot be combined with --smiles or --inchi")
+	}
+	if searchThreshold < 0.0 || searchThreshold > 1.0 {
+		return errors.Errorf("threshold must be between 0.0 and 1.0, got %.2f", searchThreshold)
+	}
+	if searchMaxResults < 1 || searchMaxResults > 500 {
+		return errors.Errorf("max-results must be between 1 and 500, got %d", searchMaxResults)
+	}
+	fingerprints, err := parseFingerprints(searchFingerprints)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newStreamSearchWriter(cmd.OutOrStdout(), searchOutput)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = cmd.InOrStdin()
+	if searchInput != "-" {
+		f, err := os.Open(searchInput)
+		if err != nil {
+			return errors.WrapMsg(err, "failed to open input library")
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	target := resolveGRPCAddr(cmd)
+	svc, closeConn, err := dialMoleculeService(ctx, target)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to connect to gRPC server "+target)
+	}
+	defer closeConn()
+
+	stream, err := svc.StreamSimilaritySearch(ctx)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to open similarity search stream")
+	}
+
+	logger.Info("Starting streaming molecule search",
+		logging.String("input", searchInput),
+		logging.String("target", target),
+		logging.Float64("threshold", searchThreshold),
+		logging.Int("max_results", searchMaxResults))
+
+	// pending maps request sequence numbers to query SMILES until the
+	// terminal message for that query arrives.
+	var (
+		mu      sync.Mutex
+		pending = make(map[uint32]string)
+	)
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- sendSMILibrary(in, stream, fingerprints, func(seq uint32, smiles string) {
+			mu.Lock()
+			pending[seq] = smiles
+			mu.Unlock()
+		})
+	}()
+
+	// abort cancels the stream and waits for the sender, so it is not left
+	// running against a closed stream or input file.
+	abort := func(err error, msg string) error {
+		cancel()
+		<-sendErr
+		return errors.WrapMsg(err, msg)
+	}
+
+	var queries, hits, failed int
+	ranks := make(map[uint32]int)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return abort(err, "similarity search stream failed")
+		}
+
+		mu.Lock()
+		smiles := pending[resp.Sequence]
+		if resp.Done {
+			delete(pending, resp.Sequence)
+		}
+		mu.Unlock()
+
+		var rec *streamSearchRecord
+		switch {
+		case resp.Hit != nil:
+			ranks[resp.Sequence]++
+			hits++
+			rec = &streamSearchRecord{
+				QueryID:     resp.QueryId,
+				QuerySMILES: smiles,
+				Rank:        ranks[resp.Sequence],
+				Similarity:  resp.Hit.Similarity,
+				Method:      resp.Hit.Method,
+			}
+			if m := resp.Hit.Molecule; m != nil {
+				rec.MoleculeID, rec.SMILES, rec.Name = m.MoleculeId, m.Smiles, m.Name
+			}
+		case resp.Error != "":
+			failed++
+			rec = &streamSearchRecord{
+				QueryID:     resp.QueryId,
+				QuerySMILES: smiles,
+				Error:       resp.Error,
+				ErrorCode:   resp.ErrorCode,
+			}
+		}
+		if resp.Done {
+			queries++
+			delete(ranks, resp.Sequence)
+		}
+		if rec != nil {
+			if err := writer.Write(rec); err != nil {
+				return abort(err, "failed to write result")
+			}
+		}
+	}
+
+	if err := <-sendErr; err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Queries: %d  Hits: %d  Failed: %d\n", queries, hits, failed)
+	logger.Info("Streaming molecule search completed",
+		logging.Int("queries", queries),
+		logging.Int("hits", hits),
+		logging.Int("failed", failed))
+	return nil
+}
+
+// sendSMILibrary streams every query in r and half-closes the stream.
+// register is called with each query's sequence number before it is sent.
+func sendSMILibrary(
+	r io.Reader,
+	stream pb.MoleculeService_StreamSimilaritySearchClient,
+	fingerprints []string,
+	register func(seq uint32, smiles string),
+) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var seq uint32
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		q, ok := parseSMILine(scanner.Text(), lineNo)
+		if !ok {
+			continue
+		}
+		register(seq, q.SMILES)
+		err := stream.Send(&pb.StreamSimilaritySearchRequest{
+			QueryId: q.ID,
+			Query: &pb.SimilaritySearchRequest{
+				Smiles:          q.SMILES,
+				Threshold:       searchThreshold,
+				FingerprintType: fingerprints,
+				MaxResults:      int32(searchMaxResults),
+			},
+		})
+		if err == io.EOF {
+			// The server ended the stream; Recv reports why.
+			return nil
+		}
+		if err != nil {
+			return errors.WrapMsg(err, "failed to send query")
+		}
+		seq++
+	}
+	if err := scanner.Err(); err != nil {
+		_ = stream.CloseSend()
+		return errors.WrapMsg(err, "failed to read input library")
+	}
+	return stream.CloseSend()
+}
+
+//Personal.AI order the ending
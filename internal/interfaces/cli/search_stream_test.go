@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+)
+
+type cliJSONCodec struct{}
+
+func (cliJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (cliJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (cliJSONCodec) Name() string                               { return "json-stub-cli-test" }
+
+var registerCLICodec sync.Once
+
+// fakeStreamMoleculeServer answers every query with two hits, except SMILES
+// "X" which fails and "ABORT" which ends the stream with an error.
+type fakeStreamMoleculeServer struct {
+	pb.UnimplementedMoleculeServiceServer
+}
+
+func (fakeStreamMoleculeServer) StreamSimilaritySearch(stream pb.MoleculeService_StreamSimilaritySearchServer) error {
+	for seq := uint32(0); ; seq++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.Query.Smiles == "ABORT" {
+			return status.Error(codes.Internal, "search backend unavailable")
+		}
+		if req.Query.Smiles == "X" {
+			if err := stream.Send(&pb.StreamSimilaritySearchResponse{
+				QueryId: req.QueryId, Sequence: seq, Done: true,
+				Error: "invalid SMILES", ErrorCode: "InvalidArgument",
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := 1; i <= 2; i++ {
+			if err := stream.Send(&pb.StreamSimilaritySearchResponse{
+				QueryId:  req.QueryId,
+				Sequence: seq,
+				Hit: &pb.SimilarityResult{
+					Molecule:   &pb.Molecule{MoleculeId: req.QueryId + "-" + strconv.Itoa(i), Smiles: req.Query.Smiles},
+					Similarity: 1.0 / float64(i),
+					Method:     req.Query.FingerprintType[0],
+				},
+			}); err != nil {
+				return err
+			}
+		}
+		if err := stream.Send(&pb.StreamSimilaritySearchResponse{
+			QueryId: req.QueryId, Sequence: seq, Done: true, HitCount: 2,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// useFakeMoleculeService points dialMoleculeService at an in-memory server for
+// the duration of the test.
+func useFakeMoleculeService(t *testing.T) {
+	t.Helper()
+	registerCLICodec.Do(func() { encoding.RegisterCodec(cliJSONCodec{}) })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterMoleculeServiceServer(srv, fakeStreamMoleculeServer{})
+	go func() { _ = srv.Serve(lis) }()
+
+	orig := dialMoleculeService
+	dialMoleculeService = func(ctx context.Context, _ string) (pb.MoleculeServiceClient, func() error, error) {
+		conn, err := grpc.DialContext(ctx, "bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.CallContentSubtype(cliJSONCodec{}.Name())),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		return pb.NewMoleculeServiceClient(conn), conn.Close, nil
+	}
+	t.Cleanup(func() {
+		dialMoleculeService = orig
+		srv.Stop()
+	})
+}
+
+func resetStreamSearchFlags(input, output string) {
+	searchSMILES = ""
+	searchInChI = ""
+	searchThreshold = 0.65
+	searchMaxResults = 20
+	searchFingerprints = "morgan"
+	searchInput = input
+	searchStream = true
+	searchOutput = output
+	searchGRPCAddr = "bufnet"
+}
+
+func newStreamTestCmd(stdin string) (*cobra.Command, *bytes.Buffer, *bytes.Buffer) {
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	return cmd, &out, &errOut
+}
+
+const testSMILibrary = `# test library
+CCO ethanol
+
+c1ccccc1 benzene
+X
+`
+
+func TestParseSMILine(t *testing.T) {
+	tests := []struct {
+		line   string
+		ok     bool
+		smiles string
+		id     string
+	}{
+		{"CCO ethanol", true, "CCO", "ethanol"},
+		{"  c1ccccc1\tbenzene ring  ", true, "c1ccccc1", "benzene ring"},
+		{"CCN", true, "CCN", "line:7"},
+		{"", false, "", ""},
+		{"   ", false, "", ""},
+		{"# comment", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		q, ok := parseSMILine(tt.line, 7)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.Equal(t, tt.smiles, q.SMILES, tt.line)
+		assert.Equal(t, tt.id, q.ID, tt.line)
+	}
+}
+
+func TestSearchMoleculeStream_NDJSON(t *testing.T) {
+	useFakeMoleculeService(t)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	path := filepath.Join(t.TempDir(), "library.smi")
+	require.NoError(t, os.WriteFile(path, []byte(testSMILibrary), 0o644))
+	resetStreamSearchFlags(path, "stdout")
+
+	cmd, out, errOut := newStreamTestCmd("")
+	require.NoError(t, runSearchMoleculeStream(cmd, mockLogger))
+
+	var records []streamSearchRecord
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var rec streamSearchRecord
+		require.NoError(t, dec.Decode(&rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 5)
+
+	assert.Equal(t, "ethanol", records[0].QueryID)
+	assert.Equal(t, "CCO", records[0].QuerySMILES)
+	assert.Equal(t, 1, records[0].Rank)
+	assert.Equal(t, "ethanol-1", records[0].MoleculeID)
+	assert.Equal(t, "morgan", records[0].Method)
+	assert.Equal(t, 2, records[1].Rank)
+	assert.Equal(t, "benzene", records[2].QueryID)
+	assert.Equal(t, 1, records[2].Rank)
+
+	failed := records[4]
+	assert.Equal(t, "line:5", failed.QueryID)
+	assert.Equal(t, "X", failed.QuerySMILES)
+	assert.Equal(t, "invalid SMILES", failed.Error)
+	assert.Equal(t, "InvalidArgument", failed.ErrorCode)
+
+	assert.Contains(t, errOut.String(), "Queries: 3  Hits: 4  Failed: 1")
+}
+
+func TestSearchMoleculeStream_CSVFromStdin(t *testing.T) {
+	useFakeMoleculeService(t)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	resetStreamSearchFlags("-", "csv")
+
+	cmd, out, _ := newStreamTestCmd(testSMILibrary)
+	require.NoError(t, runSearchMoleculeStream(cmd, mockLogger))
+
+	rows, err := csv.NewReader(out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	assert.Equal(t, streamSearchCSVHeader, rows[0])
+	assert.Equal(t, []string{"ethanol", "CCO", "1", "ethanol-1", "CCO", "", "1.0000", "morgan", "", ""}, rows[1])
+	assert.Equal(t, []string{"line:5", "X", "", "", "", "", "", "", "invalid SMILES", "InvalidArgument"}, rows[5])
+}
+
+func TestSearchMoleculeStream_StreamError(t *testing.T) {
+	useFakeMoleculeService(t)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Return()
+
+	resetStreamSearchFlags("-", "stdout")
+
+	var library strings.Builder
+	library.WriteString("ABORT\n")
+	for i := 0; i < 1000; i++ {
+		library.WriteString("CCO\n")
+	}
+	cmd, _, _ := newStreamTestCmd(library.String())
+	err := runSearchMoleculeStream(cmd, mockLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "similarity search stream failed")
+}
+
+func TestSearchMoleculeStream_Validation(t *testing.T) {
+	mockLogger := new(MockLogger)
+
+	resetStreamSearchFlags("", "stdout")
+	cmd, _, _ := newStreamTestCmd("")
+	err := runSearchMoleculeStream(cmd, mockLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--stream requires --input")
+
+	resetStreamSearchFlags("lib.smi", "stdout")
+	searchSMILES = "CCO"
+	err = runSearchMoleculeStream(cmd, mockLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be combined")
+
+	resetStreamSearchFlags("lib.smi", "table")
+	err = runSearchMoleculeStream(cmd, mockLogger)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid output format")
+}
@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/turtacn/KeyIP-Intelligence/pkg/client"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+var (
+	usageGroupBy string
+	usageMonth   string
+	usageFrom    string
+	usageTo      string
+	usageTenant  string
+	usageUser    string
+	usageFeature string
+	usageSoft    float64
+	usageHard    float64
+)
+
+// usageSummaryTable renders a usage report for --output table, ending with
+// a total row.
+type usageSummaryTable client.UsageSummary
+
+func (t usageSummaryTable) TableHeaders() []string {
+	key := t.GroupBy
+	if key == "" {
+		key = "feature"
+	}
+	return []string{strings.ToUpper(key[:1]) + key[1:], "Requests", "Estimated", "Prompt Tokens", "Completion Tokens", "Cost (USD)"}
+}
+
+func (t usageSummaryTable) TableRows() [][]string {
+	rows := make([][]string, 0, len(t.Lines)+1)
+	for _, l := range t.Lines {
+		rows = append(rows, usageLineRow(orDash(l.Key), l))
+	}
+	return append(rows, usageLineRow("TOTAL", t.Total))
+}
+
+func usageLineRow(key string, l client.UsageLine) []string {
+	return []string{
+		key,
+		strconv.FormatInt(l.Requests, 10),
+		strconv.FormatInt(l.EstimatedRequests, 10),
+		strconv.FormatInt(l.PromptTokens, 10),
+		strconv.FormatInt(l.CompletionTokens, 10),
+		fmt.Sprintf("%.4f", l.CostUSD),
+	}
+}
+
+// modelPriceTable renders the model price table for --output table.
+type modelPriceTable map[string]client.ModelPrice
+
+func (t modelPriceTable) TableHeaders() []string {
+	return []string{"Model", "Input (USD/1K)", "Output (USD/1K)"}
+}
+
+func (t modelPriceTable) TableRows() [][]string {
+	models := make([]string, 0, len(t))
+	for m := range t {
+		models = append(models, m)
+	}
+	sort.Strings(models)
+	rows := make([][]string, 0, len(models))
+	for _, m := range models {
+		rows = append(rows, []string{
+			m,
+			strconv.FormatFloat(t[m].InputPer1K, 'f', -1, 64),
+			strconv.FormatFloat(t[m].OutputPer1K, 'f', -1, 64),
+		})
+	}
+	return rows
+}
+
+// NewUsageCmd creates the usage command
+func NewUsageCmd() *cobra.Command {
+	usageCmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Report LLM token usage and cost, and manage monthly budgets",
+		Long: `Report the LLM tokens and cost recorded in the usage ledger, grouped by
+tenant, user, feature, model or day, and show or set a tenant's monthly
+budget. Crossing the soft limit raises an alert; reaching the hard limit
+also refuses further LLM calls until the next month.
+
+Reading your own tenant needs the tenant:read permission; other tenants and
+setting budgets need system:config. Commands authenticate with the key in
+the ` + apiKeyEnvVar + ` environment variable.`,
+		Example: `  # Cost by feature this month
+  keyip usage report --output table
+
+  # Cost by model for September
+  keyip usage report --group-by model --month 2026-09 --output table
+
+  # Daily spend of one feature across all tenants
+  keyip usage report --tenant '*' --feature ai.chat --group-by day
+
+  # Show, then set, a tenant's monthly budget
+  keyip usage budget --tenant acme
+  keyip usage budget set --tenant acme --soft 400 --hard 500`,
+	}
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarise LLM usage and cost",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsageReport(cmd)
+		},
+	}
+	reportCmd.Flags().StringVar(&usageGroupBy, "group-by", "feature", "Group by tenant, user, feature, model or day")
+	reportCmd.Flags().StringVar(&usageMonth, "month", "", "Calendar month to report (YYYY-MM); default the current month")
+	reportCmd.Flags().StringVar(&usageFrom, "from", "", "Start of the range, inclusive (YYYY-MM-DD or RFC 3339)")
+	reportCmd.Flags().StringVar(&usageTo, "to", "", "End of the range, exclusive (YYYY-MM-DD or RFC 3339)")
+	reportCmd.Flags().StringVar(&usageTenant, "tenant", "", "Tenant to report; '*' for all tenants (default your own)")
+	reportCmd.Flags().StringVar(&usageUser, "user", "", "Only usage of this user")
+	reportCmd.Flags().StringVar(&usageFeature, "feature", "", "Only usage of this feature, e.g. ai.chat")
+
+	budgetCmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Show a tenant's monthly LLM budget and spend",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsageBudget(cmd)
+		},
+	}
+	budgetCmd.PersistentFlags().StringVar(&usageTenant, "tenant", "", "Tenant (default your own)")
+
+	setCmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a tenant's monthly LLM budget",
+		Long: `Set a tenant's monthly soft and hard limits in USD; 0 is unlimited. A limit
+not given keeps its current value. Budgets set here take precedence over
+the ones in the server configuration.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsageBudgetSet(cmd)
+		},
+	}
+	setCmd.Flags().Float64Var(&usageSoft, "soft", 0, "Soft limit in USD: alert when reached")
+	setCmd.Flags().Float64Var(&usageHard, "hard", 0, "Hard limit in USD: alert and refuse further LLM calls when reached")
+	budgetCmd.AddCommand(setCmd)
+
+	pricesCmd := &cobra.Command{
+		Use:   "prices",
+		Short: "Show the model price table used to cost LLM calls",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUsagePrices(cmd)
+		},
+	}
+
+	usageCmd.AddCommand(reportCmd, budgetCmd, pricesCmd)
+	return usageCmd
+}
+
+func runUsageReport(cmd *cobra.Command) error {
+	uc, err := usageClient(cmd)
+	if err != nil {
+		return err
+	}
+	q := &client.UsageQuery{
+		TenantID: usageTenant,
+		UserID:   usageUser,
+		Feature:  usageFeature,
+		GroupBy:  usageGroupBy,
+	}
+	if q.From, q.To, err = usageRangeFromFlags(); err != nil {
+		return err
+	}
+
+	sum, err := uc.Summary(cmd.Context(), q)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to get LLM usage")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, sum)
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "LLM usage %s to %s\n", sum.From.Format("2006-01-02"), sum.To.Format("2006-01-02"))
+	if len(sum.Lines) == 0 {
+		fmt.Fprintln(out, "No LLM usage recorded.")
+		return nil
+	}
+	fmt.Fprint(out, FormatTable(usageSummaryTable(*sum).TableHeaders(), usageSummaryTable(*sum).TableRows()))
+	if sum.Total.EstimatedRequests > 0 {
+		fmt.Fprintf(out, "%d of %d requests have estimated token counts.\n", sum.Total.EstimatedRequests, sum.Total.Requests)
+	}
+	return nil
+}
+
+func runUsageBudget(cmd *cobra.Command) error {
+	uc, err := usageClient(cmd)
+	if err != nil {
+		return err
+	}
+	st, err := uc.Budget(cmd.Context(), usageTenant)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to get LLM budget")
+	}
+	return printBudgetStatus(cmd, st)
+}
+
+func runUsageBudgetSet(cmd *cobra.Command) error {
+	softSet, hardSet := cmd.Flags().Changed("soft"), cmd.Flags().Changed("hard")
+	if !softSet && !hardSet {
+		return errors.NewMsg("pass --soft, --hard or both")
+	}
+	uc, err := usageClient(cmd)
+	if err != nil {
+		return err
+	}
+	req := &client.SetBudgetRequest{SoftLimitUSD: usageSoft, HardLimitUSD: usageHard}
+	if !softSet || !hardSet {
+		current, err := uc.Budget(cmd.Context(), usageTenant)
+		if err != nil {
+			return errors.WrapMsg(err, "failed to get current LLM budget")
+		}
+		if !softSet {
+			req.SoftLimitUSD = current.SoftLimitUSD
+		}
+		if !hardSet {
+			req.HardLimitUSD = current.HardLimitUSD
+		}
+	}
+
+	st, err := uc.SetBudget(cmd.Context(), usageTenant, req)
+	if err != nil {
+		return errors.WrapMsg(err, "failed to set LLM budget")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, st)
+	}
+	PrintSuccess(cmd, fmt.Sprintf("budget of %s set: soft %s, hard %s", st.TenantID, formatLimit(st.SoftLimitUSD), formatLimit(st.HardLimitUSD)))
+	return printBudgetStatus(cmd, st)
+}
+
+func runUsagePrices(cmd *cobra.Command) error {
+	uc, err := usageClient(cmd)
+	if err != nil {
+		return err
+	}
+	prices, err := uc.Prices(cmd.Context())
+	if err != nil {
+		return errors.WrapMsg(err, "failed to get model prices")
+	}
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, prices)
+	}
+	if len(prices) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No model prices configured; usage is recorded at zero cost.")
+		return nil
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable(modelPriceTable(prices).TableHeaders(), modelPriceTable(prices).TableRows()))
+	return nil
+}
+
+func printBudgetStatus(cmd *cobra.Command, st *client.BudgetStatus) error {
+	if isJSONOutput(cmd) {
+		return PrintResult(cmd, st)
+	}
+	remaining := "unlimited"
+	if st.RemainingUSD >= 0 {
+		remaining = fmt.Sprintf("%.2f USD", st.RemainingUSD)
+	}
+	rows := [][]string{
+		{"Tenant", st.TenantID},
+		{"Month", st.Period.Format("2006-01")},
+		{"Spent", fmt.Sprintf("%.2f USD", st.SpentUSD)},
+		{"Soft limit", formatLimit(st.SoftLimitUSD)},
+		{"Hard limit", formatLimit(st.HardLimitUSD)},
+		{"Remaining", remaining},
+		{"Level", st.Level},
+		{"Budget source", st.Source},
+	}
+	fmt.Fprint(cmd.OutOrStdout(), FormatTable([]string{"Field", "Value"}, rows))
+	return nil
+}
+
+// usageClient returns the usage sub-client, failing with a hint when the
+// CLI has no credential configured.
+func usageClient(cmd *cobra.Command) (*client.UsageClient, error) {
+	cliCtx, err := GetCLIContext(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if cliCtx.Client == nil {
+		return nil, errors.Errorf("API client unavailable; set %s to an API key with the tenant:read scope", apiKeyEnvVar)
+	}
+	return cliCtx.Client.Usage(), nil
+}
+
+// usageRangeFromFlags resolves --month or --from/--to. Both zero leaves the
+// range to the server, which reports the current month.
+func usageRangeFromFlags() (time.Time, time.Time, error) {
+	if usageMonth != "" {
+		if usageFrom != "" || usageTo != "" {
+			return time.Time{}, time.Time{}, errors.NewMsg("--month cannot be combined with --from or --to")
+		}
+		m, err := time.Parse("2006-01", usageMonth)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.NewMsg("--month must be YYYY-MM")
+		}
+		return m, m.AddDate(0, 1, 0), nil
+	}
+	if (usageFrom == "") != (usageTo == "") {
+		return time.Time{}, time.Time{}, errors.NewMsg("--from and --to must be given together")
+	}
+	from, err := parseUsageDate(usageFrom, "--from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseUsageDate(usageTo, "--to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+func parseUsageDate(v, flag string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.Errorf("%s must be YYYY-MM-DD or an RFC 3339 time", flag)
+}
+
+func formatLimit(usd float64) string {
+	if usd <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.2f USD", usd)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resetUsageFlags() {
+	usageGroupBy, usageMonth, usageFrom, usageTo = "feature", "", "", ""
+	usageTenant, usageUser, usageFeature = "", "", ""
+	usageSoft, usageHard = 0, 0
+}
+
+func TestUsageReport_Table(t *testing.T) {
+	resetUsageFlags()
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/api/v1/usage", r.URL.Path)
+		assert.Equal(t, "model", q.Get("group_by"))
+		assert.Equal(t, "2026-09-01T00:00:00Z", q.Get("from"))
+		assert.Equal(t, "2026-10-01T00:00:00Z", q.Get("to"))
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{
+			"from": "2026-09-01T00:00:00Z", "to": "2026-10-01T00:00:00Z", "group_by": "model",
+			"lines": []map[string]interface{}{
+				{"key": "claude-sonnet-4", "requests": 12, "estimated_requests": 2, "prompt_tokens": 48000, "completion_tokens": 9000, "cost_usd": 0.279},
+			},
+			"total": map[string]interface{}{"requests": 12, "estimated_requests": 2, "prompt_tokens": 48000, "completion_tokens": 9000, "cost_usd": 0.279},
+		})
+	}, "table")
+
+	usageGroupBy, usageMonth = "model", "2026-09"
+	require.NoError(t, runUsageReport(cmd))
+	assert.Contains(t, out.String(), "Model")
+	assert.Contains(t, out.String(), "claude-sonnet-4")
+	assert.Contains(t, out.String(), "TOTAL")
+	assert.Contains(t, out.String(), "0.2790")
+	assert.Contains(t, out.String(), "2 of 12 requests")
+}
+
+func TestUsageReport_RangeFlags(t *testing.T) {
+	resetUsageFlags()
+	cmd, _, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	}, "text")
+
+	usageMonth, usageFrom = "2026-09", "2026-09-01"
+	assert.Error(t, runUsageReport(cmd))
+
+	usageMonth = ""
+	assert.Error(t, runUsageReport(cmd))
+
+	usageFrom, usageTo = "yesterday", "2026-10-01"
+	assert.Error(t, runUsageReport(cmd))
+}
+
+func TestUsageBudgetSet_KeepsUnchangedLimit(t *testing.T) {
+	resetUsageFlags()
+	var body map[string]interface{}
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "acme", r.URL.Query().Get("tenant_id"))
+		status := map[string]interface{}{"tenant_id": "acme", "period": "2026-10-01T00:00:00Z", "spent_usd": 120,
+			"soft_limit_usd": 400, "hard_limit_usd": 500, "remaining_usd": 380, "level": "ok", "source": "config"}
+		if r.Method == http.MethodPut {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			status["hard_limit_usd"], status["remaining_usd"], status["source"] = 800, 680, "tenant"
+		}
+		writeAPIKeyJSON(t, w, http.StatusOK, status)
+	}, "text")
+	setCmd := &cobra.Command{}
+	setCmd.Flags().Float64Var(&usageSoft, "soft", 0, "")
+	setCmd.Flags().Float64Var(&usageHard, "hard", 0, "")
+	setCmd.SetContext(cmd.Context())
+	setCmd.SetOut(cmd.OutOrStdout())
+
+	require.Error(t, runUsageBudgetSet(setCmd))
+
+	usageTenant = "acme"
+	require.NoError(t, setCmd.Flags().Set("hard", "800"))
+	require.NoError(t, runUsageBudgetSet(setCmd))
+	assert.Equal(t, float64(400), body["soft_limit_usd"])
+	assert.Equal(t, float64(800), body["hard_limit_usd"])
+	assert.Contains(t, out.String(), "hard 800.00 USD")
+	assert.Contains(t, out.String(), "680.00 USD")
+}
+
+func TestUsagePrices_Table(t *testing.T) {
+	resetUsageFlags()
+	cmd, out, _ := newAPIKeyTestCmd(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/usage/prices", r.URL.Path)
+		writeAPIKeyJSON(t, w, http.StatusOK, map[string]interface{}{"prices": map[string]interface{}{
+			"deepseek-chat": map[string]interface{}{"input_per_1k": 0.00027, "output_per_1k": 0.0011},
+			"default":       map[string]interface{}{"input_per_1k": 0.003, "output_per_1k": 0.015},
+		}})
+	}, "table")
+
+	require.NoError(t, runUsagePrices(cmd))
+	assert.Contains(t, out.String(), "deepseek-chat")
+	assert.Contains(t, out.String(), "0.00027")
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,477 @@
+// File: internal/interfaces/grpc/services/lifecycle_service.go
+package services
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/lifecycle"
+	domainLifecycle "github.com/turtacn/KeyIP-Intelligence/internal/domain/lifecycle"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+)
+
+const (
+	// maxRecordPaymentsStream caps the number of payments accepted on a single
+	// RecordPayments stream so one client cannot pin a handler indefinitely.
+	maxRecordPaymentsStream = 10000
+)
+
+// LifecycleServiceServer implements the gRPC LifecycleService
+type LifecycleServiceServer struct {
+	pb.UnimplementedLifecycleServiceServer
+	annuitySvc  lifecycle.AnnuityService
+	deadlineSvc lifecycle.DeadlineService
+	logger      logging.Logger
+}
+
+// NewLifecycleServiceServer creates a new LifecycleServiceServer instance
+func NewLifecycleServiceServer(
+	annuitySvc lifecycle.AnnuityService,
+	deadlineSvc lifecycle.DeadlineService,
+	logger logging.Logger,
+) *LifecycleServiceServer {
+	return &LifecycleServiceServer{
+		annuitySvc:  annuitySvc,
+		deadlineSvc: deadlineSvc,
+		logger:      logger,
+	}
+}
+
+// CalculateAnnuity computes the next annuity due for one patent
+func (s *LifecycleServiceServer) CalculateAnnuity(
+	ctx context.Context,
+	req *pb.CalculateAnnuityRequest,
+) (*pb.CalculateAnnuityResponse, error) {
+	if req.PatentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "patent_id is required")
+	}
+	if req.Jurisdiction == "" {
+		return nil, status.Error(codes.InvalidArgument, "jurisdiction is required")
+	}
+
+	result, err := s.annuitySvc.CalculateAnnuity(ctx, &lifecycle.CalculateAnnuityRequest{
+		PatentID:       req.PatentId,
+		Jurisdiction:   domainLifecycle.Jurisdiction(req.Jurisdiction),
+		TargetCurrency: lifecycle.Currency(req.TargetCurrency),
+		AsOfDate:       unixToTime(req.AsOfDate),
+	})
+	if err != nil {
+		s.logger.Error("failed to calculate annuity",
+			logging.Err(err),
+			logging.String("patent_id", req.PatentId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.CalculateAnnuityResponse{
+		Result: &pb.AnnuityResult{
+			PatentId:       result.PatentID,
+			PatentNumber:   result.PatentNumber,
+			Jurisdiction:   string(result.Jurisdiction),
+			YearNumber:     int32(result.YearNumber),
+			BaseFee:        moneyToProto(result.BaseFee),
+			ConvertedFee:   moneyToProto(result.ConvertedFee),
+			DueDate:        timeToUnix(result.DueDate),
+			GracePeriodEnd: timeToUnix(result.GracePeriodEnd),
+			Status:         string(result.Status),
+		},
+	}, nil
+}
+
+// GetPaymentSchedule lists annuity payments due in a date window
+func (s *LifecycleServiceServer) GetPaymentSchedule(
+	ctx context.Context,
+	req *pb.GetPaymentScheduleRequest,
+) (*pb.GetPaymentScheduleResponse, error) {
+	if req.PatentId == "" && req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "patent_id or portfolio_id is required")
+	}
+	if req.PatentId != "" && req.PortfolioId != "" {
+		return nil, status.Error(codes.InvalidArgument, "only one of patent_id or portfolio_id may be set")
+	}
+	if req.EndDate != 0 && req.StartDate != 0 && req.EndDate < req.StartDate {
+		return nil, status.Error(codes.InvalidArgument, "end_date must not precede start_date")
+	}
+
+	entries, err := s.annuitySvc.GetPaymentSchedule(ctx, &lifecycle.PaymentScheduleRequest{
+		PatentID:       req.PatentId,
+		PortfolioID:    req.PortfolioId,
+		StartDate:      unixToTime(req.StartDate),
+		EndDate:        unixToTime(req.EndDate),
+		TargetCurrency: lifecycle.Currency(req.TargetCurrency),
+	})
+	if err != nil {
+		s.logger.Error("failed to get payment schedule",
+			logging.Err(err),
+			logging.String("patent_id", req.PatentId),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	pbEntries := make([]*pb.PaymentScheduleEntry, 0, len(entries))
+	for _, e := range entries {
+		pbEntries = append(pbEntries, &pb.PaymentScheduleEntry{
+			PatentId:     e.PatentID,
+			PatentNumber: e.PatentNumber,
+			Jurisdiction: string(e.Jurisdiction),
+			YearNumber:   int32(e.YearNumber),
+			DueDate:      timeToUnix(e.DueDate),
+			Fee:          moneyToProto(e.Fee),
+			Status:       string(e.Status),
+			DaysUntilDue: int32(e.DaysUntilDue),
+		})
+	}
+
+	return &pb.GetPaymentScheduleResponse{Entries: pbEntries}, nil
+}
+
+// GetPaymentHistory returns recorded annuity payments
+func (s *LifecycleServiceServer) GetPaymentHistory(
+	ctx context.Context,
+	req *pb.GetPaymentHistoryRequest,
+) (*pb.GetPaymentHistoryResponse, error) {
+	records, total, err := s.annuitySvc.GetPaymentHistory(ctx, &lifecycle.PaymentHistoryRequest{
+		PatentID:     req.PatentId,
+		PortfolioID:  req.PortfolioId,
+		Jurisdiction: domainLifecycle.Jurisdiction(req.Jurisdiction),
+		StartDate:    unixToTime(req.StartDate),
+		EndDate:      unixToTime(req.EndDate),
+		Page:         int(req.Page),
+		PageSize:     int(req.PageSize),
+	})
+	if err != nil {
+		s.logger.Error("failed to get payment history", logging.Err(err))
+		return nil, mapDomainError(err)
+	}
+
+	pbRecords := make([]*pb.PaymentRecord, 0, len(records))
+	for i := range records {
+		pbRecords = append(pbRecords, paymentRecordToProto(&records[i]))
+	}
+
+	return &pb.GetPaymentHistoryResponse{Records: pbRecords, TotalCount: total}, nil
+}
+
+// RecordPayments records a client stream of annuity payments. Each payment is
+// persisted independently; invalid or failed items are reported by index and
+// do not abort the stream.
+func (s *LifecycleServiceServer) RecordPayments(stream pb.LifecycleService_RecordPaymentsServer) error {
+	ctx := stream.Context()
+	resp := &pb.RecordPaymentsResponse{}
+
+	for {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return status.Error(codes.Canceled, "record payments cancelled")
+		}
+
+		idx := resp.ReceivedCount
+		resp.ReceivedCount++
+		if resp.ReceivedCount > maxRecordPaymentsStream {
+			return status.Errorf(codes.ResourceExhausted, "at most %d payments per stream", maxRecordPaymentsStream)
+		}
+
+		if item.Amount == nil {
+			resp.Errors = append(resp.Errors, &pb.RecordPaymentError{
+				Index:    idx,
+				PatentId: item.PatentId,
+				Code:     codes.InvalidArgument.String(),
+				Message:  "amount is required",
+			})
+			continue
+		}
+
+		record, err := s.annuitySvc.RecordPayment(ctx, &lifecycle.RecordPaymentRequest{
+			PatentID:     item.PatentId,
+			Jurisdiction: domainLifecycle.Jurisdiction(item.Jurisdiction),
+			YearNumber:   int(item.YearNumber),
+			Amount: lifecycle.MoneyAmount{
+				Amount:   item.Amount.Amount,
+				Currency: lifecycle.Currency(item.Amount.Currency),
+			},
+			PaidDate:   unixToTime(item.PaidDate),
+			PaymentRef: item.PaymentRef,
+			PaidBy:     item.PaidBy,
+			Notes:      item.Notes,
+		})
+		if err != nil {
+			s.logger.Warn("bulk payment item rejected",
+				logging.Err(err),
+				logging.Int("index", int(idx)),
+				logging.String("patent_id", item.PatentId))
+			resp.Errors = append(resp.Errors, &pb.RecordPaymentError{
+				Index:    idx,
+				PatentId: item.PatentId,
+				Code:     status.Code(mapDomainError(err)).String(),
+				Message:  err.Error(),
+			})
+			continue
+		}
+
+		resp.RecordedCount++
+		resp.Records = append(resp.Records, paymentRecordToProto(record))
+	}
+
+	s.logger.Info("bulk payment recording completed",
+		logging.Int("received", int(resp.ReceivedCount)),
+		logging.Int("recorded", int(resp.RecordedCount)),
+		logging.Int("failed", len(resp.Errors)))
+
+	return stream.SendAndClose(resp)
+}
+
+// ListDeadlines lists tracked deadlines matching the filters
+func (s *LifecycleServiceServer) ListDeadlines(
+	ctx context.Context,
+	req *pb.ListDeadlinesRequest,
+) (*pb.ListDeadlinesResponse, error) {
+	query := &lifecycle.DeadlineQuery{
+		PatentID:         req.PatentId,
+		PortfolioID:      req.PortfolioId,
+		AssignedTo:       req.AssignedTo,
+		StartDate:        unixToTime(req.StartDate),
+		EndDate:          unixToTime(req.EndDate),
+		IncludeCompleted: req.IncludeCompleted,
+		Page:             int(req.Page),
+		PageSize:         int(req.PageSize),
+	}
+	for _, t := range req.Types {
+		query.Types = append(query.Types, lifecycle.DeadlineType(t))
+	}
+	for _, j := range req.Jurisdictions {
+		query.Jurisdictions = append(query.Jurisdictions, domainLifecycle.Jurisdiction(j))
+	}
+	for _, u := range req.Urgencies {
+		query.Urgencies = append(query.Urgencies, lifecycle.DeadlineUrgency(u))
+	}
+
+	result, err := s.deadlineSvc.ListDeadlines(ctx, query)
+	if err != nil {
+		s.logger.Error("failed to list deadlines", logging.Err(err))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.ListDeadlinesResponse{
+		Deadlines:  deadlinesToProto(result.Deadlines),
+		TotalCount: result.Total,
+		Page:       int32(result.Page),
+		PageSize:   int32(result.PageSize),
+	}, nil
+}
+
+// CreateDeadline creates a tracked deadline
+func (s *LifecycleServiceServer) CreateDeadline(
+	ctx context.Context,
+	req *pb.CreateDeadlineRequest,
+) (*pb.CreateDeadlineResponse, error) {
+	if req.PatentId == "" {
+		return nil, status.Error(codes.InvalidArgument, "patent_id is required")
+	}
+	if req.Title == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+	if req.DueDate == 0 {
+		return nil, status.Error(codes.InvalidArgument, "due_date is required")
+	}
+
+	deadlineType := lifecycle.DeadlineType(req.DeadlineType)
+	if deadlineType == "" {
+		deadlineType = lifecycle.DeadlineTypeCustom
+	}
+
+	d, err := s.deadlineSvc.CreateDeadline(ctx, &lifecycle.CreateDeadlineRequest{
+		PatentID:      req.PatentId,
+		Title:         req.Title,
+		Description:   req.Description,
+		DeadlineType:  deadlineType,
+		Jurisdiction:  domainLifecycle.Jurisdiction(req.Jurisdiction),
+		DueDate:       unixToTime(req.DueDate),
+		IsExtensible:  req.IsExtensible,
+		MaxExtensions: int(req.MaxExtensions),
+		AssignedTo:    req.AssignedTo,
+	})
+	if err != nil {
+		s.logger.Error("failed to create deadline",
+			logging.Err(err),
+			logging.String("patent_id", req.PatentId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.CreateDeadlineResponse{Deadline: deadlineToProto(d)}, nil
+}
+
+// CompleteDeadline marks a deadline as completed
+func (s *LifecycleServiceServer) CompleteDeadline(
+	ctx context.Context,
+	req *pb.CompleteDeadlineRequest,
+) (*pb.CompleteDeadlineResponse, error) {
+	if req.DeadlineId == "" {
+		return nil, status.Error(codes.InvalidArgument, "deadline_id is required")
+	}
+
+	if err := s.deadlineSvc.CompleteDeadline(ctx, req.DeadlineId); err != nil {
+		s.logger.Error("failed to complete deadline",
+			logging.Err(err),
+			logging.String("deadline_id", req.DeadlineId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.CompleteDeadlineResponse{Success: true}, nil
+}
+
+// ExtendDeadline moves a deadline's due date
+func (s *LifecycleServiceServer) ExtendDeadline(
+	ctx context.Context,
+	req *pb.ExtendDeadlineRequest,
+) (*pb.ExtendDeadlineResponse, error) {
+	if req.DeadlineId == "" {
+		return nil, status.Error(codes.InvalidArgument, "deadline_id is required")
+	}
+	if req.NewDueDate == 0 {
+		return nil, status.Error(codes.InvalidArgument, "new_due_date is required")
+	}
+
+	d, err := s.deadlineSvc.ExtendDeadline(ctx, &lifecycle.ExtendDeadlineRequest{
+		DeadlineID: req.DeadlineId,
+		NewDueDate: unixToTime(req.NewDueDate),
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		s.logger.Error("failed to extend deadline",
+			logging.Err(err),
+			logging.String("deadline_id", req.DeadlineId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.ExtendDeadlineResponse{Deadline: deadlineToProto(d)}, nil
+}
+
+// GetComplianceDashboard summarises deadline compliance for a portfolio
+func (s *LifecycleServiceServer) GetComplianceDashboard(
+	ctx context.Context,
+	req *pb.GetComplianceDashboardRequest,
+) (*pb.GetComplianceDashboardResponse, error) {
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	dash, err := s.deadlineSvc.GetComplianceDashboard(ctx, req.PortfolioId)
+	if err != nil {
+		s.logger.Error("failed to build compliance dashboard",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	byUrgency := make(map[string]int32, len(dash.ByUrgency))
+	for k, v := range dash.ByUrgency {
+		byUrgency[string(k)] = int32(v)
+	}
+	byType := make(map[string]int32, len(dash.ByType))
+	for k, v := range dash.ByType {
+		byType[string(k)] = int32(v)
+	}
+
+	return &pb.GetComplianceDashboardResponse{
+		TotalDeadlines:   int32(dash.TotalDeadlines),
+		ByUrgency:        byUrgency,
+		ByType:           byType,
+		ByJurisdiction:   int32Map(dash.ByJurisdiction),
+		OverdueCount:     int32(dash.OverdueCount),
+		DueSoonCount:     int32(dash.DueSoonCount),
+		ComplianceRate:   dash.ComplianceRate,
+		UpcomingCritical: deadlinesToProto(dash.UpcomingCritical),
+		GeneratedAt:      timeToUnix(dash.GeneratedAt),
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// Mapping helpers
+// ---------------------------------------------------------------------------
+
+func moneyToProto(m lifecycle.MoneyAmount) *pb.MoneyAmount {
+	if m.Currency == "" && m.Amount == 0 {
+		return nil
+	}
+	return &pb.MoneyAmount{Amount: m.Amount, Currency: string(m.Currency)}
+}
+
+func paymentRecordToProto(r *lifecycle.PaymentRecord) *pb.PaymentRecord {
+	return &pb.PaymentRecord{
+		Id:           r.ID,
+		PatentId:     r.PatentID,
+		Jurisdiction: string(r.Jurisdiction),
+		YearNumber:   int32(r.YearNumber),
+		Amount:       moneyToProto(r.Amount),
+		PaidDate:     timeToUnix(r.PaidDate),
+		PaymentRef:   r.PaymentRef,
+		PaidBy:       r.PaidBy,
+		Notes:        r.Notes,
+		RecordedAt:   timeToUnix(r.RecordedAt),
+	}
+}
+
+func deadlineToProto(d *lifecycle.Deadline) *pb.Deadline {
+	if d == nil {
+		return nil
+	}
+	out := &pb.Deadline{
+		Id:             d.ID,
+		PatentId:       d.PatentID,
+		PatentNumber:   d.PatentNumber,
+		Title:          d.Title,
+		Description:    d.Description,
+		DeadlineType:   string(d.DeadlineType),
+		Jurisdiction:   string(d.Jurisdiction),
+		DueDate:        timeToUnix(d.DueDate),
+		DaysRemaining:  int32(d.DaysRemaining),
+		Urgency:        string(d.Urgency),
+		IsExtensible:   d.IsExtensible,
+		MaxExtensions:  int32(d.MaxExtensions),
+		ExtensionsUsed: int32(d.ExtensionsUsed),
+		AssignedTo:     d.AssignedTo,
+	}
+	if d.ExtendedDate != nil {
+		out.ExtendedDate = d.ExtendedDate.Unix()
+	}
+	if d.CompletedAt != nil {
+		out.CompletedAt = d.CompletedAt.Unix()
+	}
+	return out
+}
+
+func deadlinesToProto(ds []lifecycle.Deadline) []*pb.Deadline {
+	out := make([]*pb.Deadline, 0, len(ds))
+	for i := range ds {
+		out = append(out, deadlineToProto(&ds[i]))
+	}
+	return out
+}
+
+// unixToTime converts Unix seconds to time.Time; zero maps to the zero time so
+// application services can apply their own defaults.
+func unixToTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0).UTC()
+}
+
+func timeToUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/lifecycle"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MockAnnuityService mocks the methods of lifecycle.AnnuityService used by the gRPC layer
+type MockAnnuityService struct {
+	mock.Mock
+	lifecycle.AnnuityService
+}
+
+func (m *MockAnnuityService) RecordPayment(ctx context.Context, req *lifecycle.RecordPaymentRequest) (*lifecycle.PaymentRecord, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lifecycle.PaymentRecord), args.Error(1)
+}
+
+// MockDeadlineService mocks the methods of lifecycle.DeadlineService used by the gRPC layer
+type MockDeadlineService struct {
+	mock.Mock
+	lifecycle.DeadlineService
+}
+
+func (m *MockDeadlineService) ExtendDeadline(ctx context.Context, req *lifecycle.ExtendDeadlineRequest) (*lifecycle.Deadline, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lifecycle.Deadline), args.Error(1)
+}
+
+// dialTestServer starts an in-memory gRPC server with the services registered
+// by register and returns a client connection using the JSON stub codec.
+func dialTestServer(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	initJSONCodec()
+
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	register(s)
+	go func() { _ = s.Serve(lis) }()
+
+	conn, err := grpc.DialContext(
+		context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, a string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithCodec(jsonCodec{}),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		s.Stop()
+	})
+	return conn
+}
+
+func TestRecordPayments_Stream(t *testing.T) {
+	annuity := new(MockAnnuityService)
+	conn := dialTestServer(t, func(s *grpc.Server) {
+		pb.RegisterLifecycleServiceServer(s, NewLifecycleServiceServer(annuity, nil, newTestLogger()))
+	})
+	client := pb.NewLifecycleServiceClient(conn)
+
+	paid := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	annuity.On("RecordPayment", mock.Anything, mock.MatchedBy(func(r *lifecycle.RecordPaymentRequest) bool {
+		return r.PatentID == "pat-1"
+	})).Return(&lifecycle.PaymentRecord{
+		ID: "rec-1", PatentID: "pat-1", Jurisdiction: "CN", YearNumber: 5,
+		Amount: lifecycle.MoneyAmount{Amount: 1200, Currency: "CNY"}, PaidDate: paid,
+	}, nil)
+	annuity.On("RecordPayment", mock.Anything, mock.MatchedBy(func(r *lifecycle.RecordPaymentRequest) bool {
+		return r.PatentID == "pat-missing"
+	})).Return(nil, errors.NewNotFound("patent not found"))
+
+	stream, err := client.RecordPayments(context.Background())
+	require.NoError(t, err)
+
+	items := []*pb.RecordPaymentRequest{
+		{PatentId: "pat-1", Jurisdiction: "CN", YearNumber: 5, Amount: &pb.MoneyAmount{Amount: 1200, Currency: "CNY"}, PaidDate: paid.Unix()},
+		{PatentId: "pat-2", Jurisdiction: "CN", YearNumber: 5},
+		{PatentId: "pat-missing", Jurisdiction: "US", YearNumber: 4, Amount: &pb.MoneyAmount{Amount: 900, Currency: "USD"}},
+	}
+	for _, item := range items {
+		require.NoError(t, stream.Send(item))
+	}
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.ReceivedCount)
+	assert.Equal(t, int32(1), resp.RecordedCount)
+	require.Len(t, resp.Records, 1)
+	assert.Equal(t, "rec-1", resp.Records[0].Id)
+	assert.Equal(t, paid.Unix(), resp.Records[0].PaidDate)
+
+	require.Len(t, resp.Errors, 2)
+	assert.Equal(t, int32(1), resp.Errors[0].Index)
+	assert.Equal(t, codes.InvalidArgument.String(), resp.Errors[0].Code)
+	assert.Equal(t, int32(2), resp.Errors[1].Index)
+	assert.Equal(t, "pat-missing", resp.Errors[1].PatentId)
+	assert.Equal(t, codes.NotFound.String(), resp.Errors[1].Code)
+}
+
+func TestRecordPayments_EmptyStream(t *testing.T) {
+	conn := dialTestServer(t, func(s *grpc.Server) {
+		pb.RegisterLifecycleServiceServer(s, NewLifecycleServiceServer(new(MockAnnuityService), nil, newTestLogger()))
+	})
+	stream, err := pb.NewLifecycleServiceClient(conn).RecordPayments(context.Background())
+	require.NoError(t, err)
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Zero(t, resp.ReceivedCount)
+	assert.Empty(t, resp.Errors)
+}
+
+func TestExtendDeadline(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		deadlines := new(MockDeadlineService)
+		server := NewLifecycleServiceServer(nil, deadlines, newTestLogger())
+
+		newDue := time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)
+		deadlines.On("ExtendDeadline", mock.Anything, &lifecycle.ExtendDeadlineRequest{
+			DeadlineID: "d1", NewDueDate: newDue, Reason: "office action",
+		}).Return(&lifecycle.Deadline{ID: "d1", DueDate: newDue, ExtendedDate: &newDue, ExtensionsUsed: 1}, nil)
+
+		resp, err := server.ExtendDeadline(context.Background(), &pb.ExtendDeadlineRequest{
+			DeadlineId: "d1", NewDueDate: newDue.Unix(), Reason: "office action",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, newDue.Unix(), resp.Deadline.ExtendedDate)
+		assert.Equal(t, int32(1), resp.Deadline.ExtensionsUsed)
+		assert.Zero(t, resp.Deadline.CompletedAt)
+	})
+
+	t.Run("missing due date", func(t *testing.T) {
+		server := NewLifecycleServiceServer(nil, new(MockDeadlineService), newTestLogger())
+		_, err := server.ExtendDeadline(context.Background(), &pb.ExtendDeadlineRequest{DeadlineId: "d1"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,181 @@
+// File: internal/interfaces/grpc/services/molecule_stream_search.go
+package services
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/patent_mining"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+)
+
+const (
+	// streamSearchConcurrency bounds the number of searches a single
+	// StreamSimilaritySearch call runs at once. While that many are
+	// outstanding the handler stops reading the request stream, so a fast
+	// client is throttled by HTTP/2 flow control instead of server memory.
+	streamSearchConcurrency = 8
+
+	// streamSearchMaxResults caps max_results per streamed query.
+	streamSearchMaxResults = 500
+)
+
+// StreamSimilaritySearch runs similarity searches for queries as they arrive
+// on the request stream and streams hits back as each search completes.
+// Hits of one query are sent contiguously and followed by a terminal message
+// with done set; a failed query yields a single terminal message carrying the
+// error, and the stream carries on.
+func (s *MoleculeServiceServer) StreamSimilaritySearch(stream pb.MoleculeService_StreamSimilaritySearchServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	out := make(chan []*pb.StreamSimilaritySearchResponse, streamSearchConcurrency)
+	slots := make(chan struct{}, streamSearchConcurrency)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		for seq := uint32(0); ; seq++ {
+			req, err := stream.Recv()
+			if err == io.EOF {
+				recvErr <- nil
+				return
+			}
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+
+			wg.Add(1)
+			go func(seq uint32, req *pb.StreamSimilaritySearchRequest) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				msgs := s.streamSearchOne(ctx, seq, req)
+				select {
+				case out <- msgs:
+				case <-ctx.Done():
+				}
+			}(seq, req)
+		}
+	}()
+
+	var queries, hits int
+	for msgs := range out {
+		for _, m := range msgs {
+			if err := stream.Send(m); err != nil {
+				s.logger.Error("failed to send stream search result",
+					logging.Err(err),
+					logging.String("query_id", m.QueryId))
+				return err
+			}
+			if m.Hit != nil {
+				hits++
+			}
+		}
+		queries++
+	}
+
+	if err := <-recvErr; err != nil {
+		if st, ok := status.FromError(err); ok {
+			return st.Err()
+		}
+		return status.FromContextError(err).Err()
+	}
+
+	s.logger.Info("stream similarity search completed",
+		logging.Int("queries", queries),
+		logging.Int("hits", hits))
+	return nil
+}
+
+// streamSearchOne runs one streamed query and returns the messages to send
+// for it, terminal message last.
+func (s *MoleculeServiceServer) streamSearchOne(
+	ctx context.Context,
+	seq uint32,
+	req *pb.StreamSimilaritySearchRequest,
+) []*pb.StreamSimilaritySearchResponse {
+	queryID := req.QueryId
+	if queryID == "" {
+		queryID = strconv.FormatUint(uint64(seq), 10)
+	}
+	fail := func(code codes.Code, msg string) []*pb.StreamSimilaritySearchResponse {
+		return []*pb.StreamSimilaritySearchResponse{{
+			QueryId:   queryID,
+			Sequence:  seq,
+			Done:      true,
+			Error:     msg,
+			ErrorCode: code.String(),
+		}}
+	}
+
+	q := req.Query
+	if q == nil || q.Smiles == "" {
+		return fail(codes.InvalidArgument, "smiles is required")
+	}
+	if q.Threshold < 0 || q.Threshold > 1 {
+		return fail(codes.InvalidArgument, "threshold must be between 0 and 1")
+	}
+	if q.MaxResults < 0 || q.MaxResults > streamSearchMaxResults {
+		return fail(codes.InvalidArgument, "max_results must be between 0 and 500")
+	}
+
+	fpType := "morgan"
+	if len(q.FingerprintType) > 0 && q.FingerprintType[0] != "" {
+		fpType = q.FingerprintType[0]
+	}
+
+	results, err := s.similaritySearch.Search(ctx, &patent_mining.SimilarityQuery{
+		SMILES:          q.Smiles,
+		Threshold:       q.Threshold,
+		FingerprintType: fpType,
+		MaxResults:      int(q.MaxResults),
+	})
+	if err != nil {
+		s.logger.Warn("stream similarity search failed for query",
+			logging.Err(err),
+			logging.String("query_id", queryID))
+		st := status.Convert(mapDomainError(err))
+		return fail(st.Code(), st.Message())
+	}
+
+	msgs := make([]*pb.StreamSimilaritySearchResponse, 0, len(results)+1)
+	for _, r := range results {
+		msgs = append(msgs, &pb.StreamSimilaritySearchResponse{
+			QueryId:  queryID,
+			Sequence: seq,
+			Hit: &pb.SimilarityResult{
+				Molecule:   moleculeInfoToProto(r.Molecule),
+				Similarity: r.Similarity,
+				Method:     r.Method,
+			},
+		})
+	}
+	return append(msgs, &pb.StreamSimilaritySearchResponse{
+		QueryId:  queryID,
+		Sequence: seq,
+		Done:     true,
+		HitCount: uint32(len(results)),
+	})
+}
+
+//Personal.AI order the ending
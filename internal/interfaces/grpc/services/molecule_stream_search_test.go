@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/patent_mining"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func newStreamSearchClient(t *testing.T, sim *MockSimilaritySearch) pb.MoleculeServiceClient {
+	conn := dialTestServer(t, func(s *grpc.Server) {
+		pb.RegisterMoleculeServiceServer(s, NewMoleculeServiceServer(new(MockMoleculeRepo), sim, newTestLogger()))
+	})
+	return pb.NewMoleculeServiceClient(conn)
+}
+
+func TestStreamSimilaritySearch_PerQueryResults(t *testing.T) {
+	sim := new(MockSimilaritySearch)
+	client := newStreamSearchClient(t, sim)
+
+	sim.On("Search", mock.Anything, mock.MatchedBy(func(q *patent_mining.SimilarityQuery) bool {
+		return q.SMILES == "CCO"
+	})).Return([]patent_mining.SimilarityResult{
+		{Molecule: &patent_mining.MoleculeInfo{ID: "m1", SMILES: "CCO"}, Similarity: 1.0, Method: "morgan"},
+		{Molecule: &patent_mining.MoleculeInfo{ID: "m2", SMILES: "CCCO"}, Similarity: 0.8, Method: "morgan"},
+	}, nil)
+	sim.On("Search", mock.Anything, mock.MatchedBy(func(q *patent_mining.SimilarityQuery) bool {
+		return q.SMILES == "C1CC"
+	})).Return(nil, errors.NewValidation("invalid SMILES"))
+
+	stream, err := client.StreamSimilaritySearch(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&pb.StreamSimilaritySearchRequest{QueryId: "ethanol", Query: &pb.SimilaritySearchRequest{Smiles: "CCO", Threshold: 0.7}}))
+	require.NoError(t, stream.Send(&pb.StreamSimilaritySearchRequest{Query: &pb.SimilaritySearchRequest{}}))
+	require.NoError(t, stream.Send(&pb.StreamSimilaritySearchRequest{QueryId: "bad", Query: &pb.SimilaritySearchRequest{Smiles: "C1CC"}}))
+	require.NoError(t, stream.CloseSend())
+
+	byQuery := map[string][]*pb.StreamSimilaritySearchResponse{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		byQuery[resp.QueryId] = append(byQuery[resp.QueryId], resp)
+	}
+
+	require.Len(t, byQuery, 3)
+
+	ethanol := byQuery["ethanol"]
+	require.Len(t, ethanol, 3)
+	assert.Equal(t, "m1", ethanol[0].Hit.Molecule.MoleculeId)
+	assert.Equal(t, "m2", ethanol[1].Hit.Molecule.MoleculeId)
+	assert.True(t, ethanol[2].Done)
+	assert.Equal(t, uint32(2), ethanol[2].HitCount)
+	assert.Equal(t, uint32(0), ethanol[2].Sequence)
+
+	// Missing query_id defaults to the sequence number.
+	missing := byQuery["1"]
+	require.Len(t, missing, 1)
+	assert.True(t, missing[0].Done)
+	assert.Equal(t, codes.InvalidArgument.String(), missing[0].ErrorCode)
+
+	bad := byQuery["bad"]
+	require.Len(t, bad, 1)
+	assert.Equal(t, uint32(2), bad[0].Sequence)
+	assert.Equal(t, codes.InvalidArgument.String(), bad[0].ErrorCode)
+	assert.Contains(t, bad[0].Error, "invalid SMILES")
+}
+
+func TestStreamSimilaritySearch_BoundsConcurrency(t *testing.T) {
+	sim := new(MockSimilaritySearch)
+	client := newStreamSearchClient(t, sim)
+
+	var running, peak int32
+	release := make(chan struct{})
+	sim.On("Search", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+	}).Return([]patent_mining.SimilarityResult{}, nil)
+
+	stream, err := client.StreamSimilaritySearch(context.Background())
+	require.NoError(t, err)
+
+	const total = streamSearchConcurrency * 3
+	go func() {
+		for i := 0; i < total; i++ {
+			if err := stream.Send(&pb.StreamSimilaritySearchRequest{
+				QueryId: fmt.Sprintf("q%d", i),
+				Query:   &pb.SimilaritySearchRequest{Smiles: "CCO"},
+			}); err != nil {
+				return
+			}
+		}
+		_ = stream.CloseSend()
+	}()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&running) == streamSearchConcurrency
+	}, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(streamSearchConcurrency), atomic.LoadInt32(&peak))
+	close(release)
+
+	done := 0
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if resp.Done {
+			done++
+		}
+	}
+	assert.Equal(t, total, done)
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(streamSearchConcurrency))
+}
@@ -0,0 +1,411 @@
+// File: internal/interfaces/grpc/services/portfolio_service.go
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/portfolio"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+)
+
+const (
+	defaultPortfolioPageSize = 20
+	maxPortfolioPageSize     = 100
+	maxPortfolioPatentBatch  = 1000
+)
+
+// PortfolioServiceServer implements the gRPC PortfolioService
+type PortfolioServiceServer struct {
+	pb.UnimplementedPortfolioServiceServer
+	portfolioSvc portfolio.Service
+	valuationSvc portfolio.ValuationService
+	logger       logging.Logger
+}
+
+// NewPortfolioServiceServer creates a new PortfolioServiceServer instance.
+// valuationSvc may be nil, in which case ValuatePortfolio returns Unimplemented.
+func NewPortfolioServiceServer(
+	portfolioSvc portfolio.Service,
+	valuationSvc portfolio.ValuationService,
+	logger logging.Logger,
+) *PortfolioServiceServer {
+	return &PortfolioServiceServer{
+		portfolioSvc: portfolioSvc,
+		valuationSvc: valuationSvc,
+		logger:       logger,
+	}
+}
+
+// CreatePortfolio creates a new portfolio
+func (s *PortfolioServiceServer) CreatePortfolio(
+	ctx context.Context,
+	req *pb.CreatePortfolioRequest,
+) (*pb.CreatePortfolioResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	p, err := s.portfolioSvc.Create(ctx, &portfolio.CreateInput{
+		Name:        req.Name,
+		Description: req.Description,
+		PatentIDs:   req.PatentIds,
+		Tags:        req.Tags,
+		UserID:      req.UserId,
+	})
+	if err != nil {
+		s.logger.Error("failed to create portfolio",
+			logging.Err(err),
+			logging.String("name", req.Name))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.CreatePortfolioResponse{Portfolio: portfolioToProto(p)}, nil
+}
+
+// GetPortfolio retrieves a portfolio by ID
+func (s *PortfolioServiceServer) GetPortfolio(
+	ctx context.Context,
+	req *pb.GetPortfolioRequest,
+) (*pb.GetPortfolioResponse, error) {
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	p, err := s.portfolioSvc.GetByID(ctx, req.PortfolioId)
+	if err != nil {
+		s.logger.Error("failed to get portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.GetPortfolioResponse{Portfolio: portfolioToProto(p)}, nil
+}
+
+// ListPortfolios lists portfolios with pagination
+func (s *PortfolioServiceServer) ListPortfolios(
+	ctx context.Context,
+	req *pb.ListPortfoliosRequest,
+) (*pb.ListPortfoliosResponse, error) {
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPortfolioPageSize
+	}
+	if pageSize > maxPortfolioPageSize {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 1 and 100")
+	}
+	page := int(req.Page)
+	if page <= 0 {
+		page = 1
+	}
+
+	result, err := s.portfolioSvc.List(ctx, &portfolio.ListInput{
+		Page:     page,
+		PageSize: pageSize,
+		UserID:   req.UserId,
+	})
+	if err != nil {
+		s.logger.Error("failed to list portfolios", logging.Err(err))
+		return nil, mapDomainError(err)
+	}
+
+	pbPortfolios := make([]*pb.Portfolio, 0, len(result.Portfolios))
+	for _, p := range result.Portfolios {
+		pbPortfolios = append(pbPortfolios, portfolioToProto(p))
+	}
+
+	return &pb.ListPortfoliosResponse{
+		Portfolios: pbPortfolios,
+		TotalCount: result.Total,
+		Page:       int32(result.Page),
+		PageSize:   int32(result.PageSize),
+	}, nil
+}
+
+// UpdatePortfolio applies partial updates to a portfolio
+func (s *PortfolioServiceServer) UpdatePortfolio(
+	ctx context.Context,
+	req *pb.UpdatePortfolioRequest,
+) (*pb.UpdatePortfolioResponse, error) {
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	input := &portfolio.UpdateInput{
+		ID:     req.PortfolioId,
+		UserID: req.UserId,
+	}
+	if req.Name != "" {
+		input.Name = &req.Name
+	}
+	if req.Description != "" {
+		input.Description = &req.Description
+	}
+	if len(req.Tags) > 0 {
+		input.Tags = req.Tags
+	}
+
+	p, err := s.portfolioSvc.Update(ctx, input)
+	if err != nil {
+		s.logger.Error("failed to update portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.UpdatePortfolioResponse{Portfolio: portfolioToProto(p)}, nil
+}
+
+// DeletePortfolio soft-deletes a portfolio
+func (s *PortfolioServiceServer) DeletePortfolio(
+	ctx context.Context,
+	req *pb.DeletePortfolioRequest,
+) (*pb.DeletePortfolioResponse, error) {
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	if err := s.portfolioSvc.Delete(ctx, req.PortfolioId, req.UserId); err != nil {
+		s.logger.Error("failed to delete portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.DeletePortfolioResponse{Success: true}, nil
+}
+
+// AddPatents adds member patents to a portfolio
+func (s *PortfolioServiceServer) AddPatents(
+	ctx context.Context,
+	req *pb.ModifyPortfolioPatentsRequest,
+) (*pb.ModifyPortfolioPatentsResponse, error) {
+	if err := validateModifyPatents(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.portfolioSvc.AddPatents(ctx, req.PortfolioId, req.PatentIds, req.UserId); err != nil {
+		s.logger.Error("failed to add patents to portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId),
+			logging.Int("count", len(req.PatentIds)))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.ModifyPortfolioPatentsResponse{
+		Success:       true,
+		AffectedCount: int32(len(req.PatentIds)),
+	}, nil
+}
+
+// RemovePatents removes member patents from a portfolio
+func (s *PortfolioServiceServer) RemovePatents(
+	ctx context.Context,
+	req *pb.ModifyPortfolioPatentsRequest,
+) (*pb.ModifyPortfolioPatentsResponse, error) {
+	if err := validateModifyPatents(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.portfolioSvc.RemovePatents(ctx, req.PortfolioId, req.PatentIds, req.UserId); err != nil {
+		s.logger.Error("failed to remove patents from portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId),
+			logging.Int("count", len(req.PatentIds)))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.ModifyPortfolioPatentsResponse{
+		Success:       true,
+		AffectedCount: int32(len(req.PatentIds)),
+	}, nil
+}
+
+// GetPortfolioAnalysis returns composition statistics for a portfolio
+func (s *PortfolioServiceServer) GetPortfolioAnalysis(
+	ctx context.Context,
+	req *pb.GetPortfolioAnalysisRequest,
+) (*pb.GetPortfolioAnalysisResponse, error) {
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	analysis, err := s.portfolioSvc.GetAnalysis(ctx, req.PortfolioId)
+	if err != nil {
+		s.logger.Error("failed to analyze portfolio",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	topCodes := make([]*pb.ClassificationCount, 0, len(analysis.TopIPCCodes))
+	for _, c := range analysis.TopIPCCodes {
+		topCodes = append(topCodes, &pb.ClassificationCount{Code: c.Code, Count: int32(c.Count)})
+	}
+
+	return &pb.GetPortfolioAnalysisResponse{
+		Analysis: &pb.PortfolioAnalysis{
+			PortfolioId:     analysis.PortfolioID,
+			TotalPatents:    int32(analysis.TotalPatents),
+			ByJurisdiction:  int32Map(analysis.ByJurisdiction),
+			ByStatus:        int32Map(analysis.ByStatus),
+			ByYear:          int32Map(analysis.ByYear),
+			TopIpcCodes:     topCodes,
+			TotalValue:      analysis.TotalValue,
+			Recommendations: analysis.Recommendations,
+		},
+	}, nil
+}
+
+// ValuatePortfolio runs the patent valuation model over a portfolio
+func (s *PortfolioServiceServer) ValuatePortfolio(
+	ctx context.Context,
+	req *pb.ValuatePortfolioRequest,
+) (*pb.ValuatePortfolioResponse, error) {
+	if req.PortfolioId == "" && len(req.PatentIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id or patent_ids is required")
+	}
+	if s.valuationSvc == nil {
+		return nil, status.Error(codes.Unimplemented, "portfolio valuation is not configured")
+	}
+
+	dims := make([]portfolio.AssessmentDimension, 0, len(req.Dimensions))
+	for _, d := range req.Dimensions {
+		dims = append(dims, portfolio.AssessmentDimension(d))
+	}
+	assessCtx := portfolio.DefaultAssessmentContext()
+	if req.Currency != "" {
+		assessCtx.CurrencyCode = req.Currency
+	}
+
+	start := time.Now()
+	resp, err := s.valuationSvc.AssessPortfolio(ctx, &portfolio.PortfolioAssessmentRequest{
+		PortfolioID:             req.PortfolioId,
+		PatentIDs:               req.PatentIds,
+		Dimensions:              dims,
+		Context:                 assessCtx,
+		IncludeCostOptimization: req.IncludeCostOptimization,
+	})
+	if err != nil {
+		s.logger.Error("portfolio valuation failed",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	s.logger.Info("portfolio valuation completed",
+		logging.String("portfolio_id", req.PortfolioId),
+		logging.Int("assessed", len(resp.Assessments)),
+		logging.Duration("elapsed", time.Since(start)))
+
+	return valuationToProto(resp), nil
+}
+
+// ---------------------------------------------------------------------------
+// Mapping helpers
+// ---------------------------------------------------------------------------
+
+func validateModifyPatents(req *pb.ModifyPortfolioPatentsRequest) error {
+	if req.PortfolioId == "" {
+		return status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+	if len(req.PatentIds) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one patent_id is required")
+	}
+	if len(req.PatentIds) > maxPortfolioPatentBatch {
+		return status.Errorf(codes.InvalidArgument, "at most %d patent_ids per call", maxPortfolioPatentBatch)
+	}
+	return nil
+}
+
+func portfolioToProto(p *portfolio.Portfolio) *pb.Portfolio {
+	if p == nil {
+		return nil
+	}
+	return &pb.Portfolio{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		PatentIds:   p.PatentIDs,
+		Tags:        p.Tags,
+		PatentCount: int32(p.PatentCount),
+		CreatedAt:   p.CreatedAt.Unix(),
+		UpdatedAt:   p.UpdatedAt.Unix(),
+	}
+}
+
+func valuationToProto(resp *portfolio.PortfolioAssessmentResponse) *pb.ValuatePortfolioResponse {
+	out := &pb.ValuatePortfolioResponse{
+		PortfolioId: resp.PortfolioID,
+		Valuations:  make([]*pb.PatentValuation, 0, len(resp.Assessments)),
+		AssessedAt:  resp.AssessedAt.Unix(),
+	}
+
+	for _, a := range resp.Assessments {
+		if a == nil {
+			continue
+		}
+		v := &pb.PatentValuation{
+			PatentId:    a.PatentID,
+			PatentTitle: a.PatentTitle,
+		}
+		if a.OverallScore != nil {
+			v.OverallScore = a.OverallScore.Score
+			v.Tier = string(a.OverallScore.Tier)
+		}
+		// Emit dimensions in canonical order so responses are deterministic.
+		for _, dim := range portfolio.AllDimensions() {
+			if ds, ok := a.Scores[dim]; ok && ds != nil {
+				v.Scores = append(v.Scores, &pb.DimensionScore{
+					Dimension:   string(dim),
+					Score:       ds.Score,
+					Explanation: ds.Explanation,
+				})
+			}
+		}
+		for _, r := range a.Recommendations {
+			if r != nil {
+				v.Recommendations = append(v.Recommendations, r.Action)
+			}
+		}
+		out.Valuations = append(out.Valuations, v)
+	}
+	sort.SliceStable(out.Valuations, func(i, j int) bool {
+		return out.Valuations[i].OverallScore > out.Valuations[j].OverallScore
+	})
+
+	if resp.Summary != nil {
+		tiers := make(map[string]int32, len(resp.Summary.TierDistribution))
+		for t, n := range resp.Summary.TierDistribution {
+			tiers[string(t)] = int32(n)
+		}
+		out.Summary = &pb.ValuationSummary{
+			TotalAssessed:             int32(resp.Summary.TotalAssessed),
+			TierDistribution:          tiers,
+			AverageScore:              resp.Summary.AverageScore,
+			TotalMaintenanceCost:      resp.Summary.TotalMaintenanceCost,
+			CostOptimizationPotential: resp.Summary.CostOptimizationPotential,
+			Currency:                  resp.Summary.Currency,
+		}
+	}
+	return out
+}
+
+func int32Map(m map[string]int) map[string]int32 {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]int32, len(m))
+	for k, v := range m {
+		out[k] = int32(v)
+	}
+	return out
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/portfolio"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MockPortfolioService mocks portfolio.Service
+type MockPortfolioService struct {
+	mock.Mock
+}
+
+func (m *MockPortfolioService) Create(ctx context.Context, input *portfolio.CreateInput) (*portfolio.Portfolio, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetByID(ctx context.Context, id string) (*portfolio.Portfolio, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioService) List(ctx context.Context, input *portfolio.ListInput) (*portfolio.ListResult, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.ListResult), args.Error(1)
+}
+
+func (m *MockPortfolioService) Update(ctx context.Context, input *portfolio.UpdateInput) (*portfolio.Portfolio, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioService) Delete(ctx context.Context, id string, userID string) error {
+	return m.Called(ctx, id, userID).Error(0)
+}
+
+func (m *MockPortfolioService) AddPatents(ctx context.Context, id string, patentIDs []string, userID string) error {
+	return m.Called(ctx, id, patentIDs, userID).Error(0)
+}
+
+func (m *MockPortfolioService) RemovePatents(ctx context.Context, id string, patentIDs []string, userID string) error {
+	return m.Called(ctx, id, patentIDs, userID).Error(0)
+}
+
+func (m *MockPortfolioService) GetAnalysis(ctx context.Context, id string) (*portfolio.PortfolioAnalysis, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.PortfolioAnalysis), args.Error(1)
+}
+
+// MockValuationService mocks the AssessPortfolio method of portfolio.ValuationService
+type MockValuationService struct {
+	mock.Mock
+	portfolio.ValuationService
+}
+
+func (m *MockValuationService) AssessPortfolio(ctx context.Context, req *portfolio.PortfolioAssessmentRequest) (*portfolio.PortfolioAssessmentResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*portfolio.PortfolioAssessmentResponse), args.Error(1)
+}
+
+func newTestLogger() *MockLogger {
+	l := new(MockLogger)
+	l.On("Info", mock.Anything, mock.Anything).Return().Maybe()
+	l.On("Warn", mock.Anything, mock.Anything).Return().Maybe()
+	l.On("Error", mock.Anything, mock.Anything).Return().Maybe()
+	return l
+}
+
+func TestCreatePortfolio(t *testing.T) {
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		svc := new(MockPortfolioService)
+		server := NewPortfolioServiceServer(svc, nil, newTestLogger())
+
+		svc.On("Create", mock.Anything, mock.MatchedBy(func(in *portfolio.CreateInput) bool {
+			return in.Name == "OLED" && in.UserID == "u1" && len(in.PatentIDs) == 2
+		})).Return(&portfolio.Portfolio{
+			ID: "p1", Name: "OLED", PatentIDs: []string{"a", "b"}, PatentCount: 2, CreatedAt: now, UpdatedAt: now,
+		}, nil)
+
+		resp, err := server.CreatePortfolio(context.Background(), &pb.CreatePortfolioRequest{
+			Name: "OLED", PatentIds: []string{"a", "b"}, UserId: "u1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "p1", resp.Portfolio.Id)
+		assert.Equal(t, int32(2), resp.Portfolio.PatentCount)
+		assert.Equal(t, now.Unix(), resp.Portfolio.CreatedAt)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		server := NewPortfolioServiceServer(new(MockPortfolioService), nil, newTestLogger())
+		_, err := server.CreatePortfolio(context.Background(), &pb.CreatePortfolioRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestGetPortfolio_NotFound(t *testing.T) {
+	svc := new(MockPortfolioService)
+	server := NewPortfolioServiceServer(svc, nil, newTestLogger())
+	svc.On("GetByID", mock.Anything, "missing").Return(nil, errors.NewNotFound("portfolio not found"))
+
+	_, err := server.GetPortfolio(context.Background(), &pb.GetPortfolioRequest{PortfolioId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestListPortfolios(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		svc := new(MockPortfolioService)
+		server := NewPortfolioServiceServer(svc, nil, newTestLogger())
+		svc.On("List", mock.Anything, &portfolio.ListInput{Page: 1, PageSize: defaultPortfolioPageSize}).
+			Return(&portfolio.ListResult{Portfolios: []*portfolio.Portfolio{{ID: "p1"}}, Total: 1, Page: 1, PageSize: defaultPortfolioPageSize}, nil)
+
+		resp, err := server.ListPortfolios(context.Background(), &pb.ListPortfoliosRequest{})
+		require.NoError(t, err)
+		assert.Len(t, resp.Portfolios, 1)
+		assert.Equal(t, int64(1), resp.TotalCount)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("page size too large", func(t *testing.T) {
+		server := NewPortfolioServiceServer(new(MockPortfolioService), nil, newTestLogger())
+		_, err := server.ListPortfolios(context.Background(), &pb.ListPortfoliosRequest{PageSize: 5000})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestAddPatents(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := new(MockPortfolioService)
+		server := NewPortfolioServiceServer(svc, nil, newTestLogger())
+		svc.On("AddPatents", mock.Anything, "p1", []string{"a", "b"}, "u1").Return(nil)
+
+		resp, err := server.AddPatents(context.Background(), &pb.ModifyPortfolioPatentsRequest{
+			PortfolioId: "p1", PatentIds: []string{"a", "b"}, UserId: "u1",
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.Equal(t, int32(2), resp.AffectedCount)
+	})
+
+	t.Run("batch too large", func(t *testing.T) {
+		server := NewPortfolioServiceServer(new(MockPortfolioService), nil, newTestLogger())
+		_, err := server.AddPatents(context.Background(), &pb.ModifyPortfolioPatentsRequest{
+			PortfolioId: "p1", PatentIds: make([]string, maxPortfolioPatentBatch+1),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestValuatePortfolio(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		server := NewPortfolioServiceServer(new(MockPortfolioService), nil, newTestLogger())
+		_, err := server.ValuatePortfolio(context.Background(), &pb.ValuatePortfolioRequest{PortfolioId: "p1"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("orders valuations by score", func(t *testing.T) {
+		val := new(MockValuationService)
+		server := NewPortfolioServiceServer(new(MockPortfolioService), val, newTestLogger())
+
+		val.On("AssessPortfolio", mock.Anything, mock.MatchedBy(func(r *portfolio.PortfolioAssessmentRequest) bool {
+			return r.PortfolioID == "p1" && r.Context != nil && r.Context.CurrencyCode == "EUR"
+		})).Return(&portfolio.PortfolioAssessmentResponse{
+			PortfolioID: "p1",
+			Assessments: []*portfolio.SinglePatentAssessmentResponse{
+				{PatentID: "low", OverallScore: &portfolio.OverallValuation{Score: 40, Tier: portfolio.TierC}},
+				{
+					PatentID:     "high",
+					OverallScore: &portfolio.OverallValuation{Score: 90, Tier: portfolio.TierS},
+					Scores: map[portfolio.AssessmentDimension]*portfolio.DimensionScore{
+						portfolio.DimensionLegalValue:     {Score: 80},
+						portfolio.DimensionTechnicalValue: {Score: 95},
+					},
+				},
+			},
+			Summary: &portfolio.PortfolioSummary{
+				TotalAssessed:    2,
+				TierDistribution: map[portfolio.PatentTier]int{portfolio.TierS: 1, portfolio.TierC: 1},
+			},
+		}, nil)
+
+		resp, err := server.ValuatePortfolio(context.Background(), &pb.ValuatePortfolioRequest{PortfolioId: "p1", Currency: "EUR"})
+		require.NoError(t, err)
+		require.Len(t, resp.Valuations, 2)
+		assert.Equal(t, "high", resp.Valuations[0].PatentId)
+		require.Len(t, resp.Valuations[0].Scores, 2)
+		assert.Equal(t, string(portfolio.DimensionTechnicalValue), resp.Valuations[0].Scores[0].Dimension)
+		assert.Equal(t, int32(1), resp.Summary.TierDistribution["S"])
+	})
+}
@@ -0,0 +1,459 @@
+// File: internal/interfaces/grpc/services/report_service.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/reporting"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+const (
+	defaultProgressPollInterval = time.Second
+	minProgressPollInterval     = 200 * time.Millisecond
+	maxProgressPollInterval     = 30 * time.Second
+
+	// reportChunkSize is the payload size of each DownloadReport message.
+	reportChunkSize = 64 * 1024
+)
+
+var reportContentTypes = map[string]string{
+	"PDF":  "application/pdf",
+	"DOCX": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"PPTX": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	"HTML": "text/html; charset=utf-8",
+}
+
+// ReportServiceServer implements the gRPC ReportService
+type ReportServiceServer struct {
+	pb.UnimplementedReportServiceServer
+	ftoSvc       reporting.FTOReportService
+	portfolioSvc reporting.PortfolioReportService
+	logger       logging.Logger
+}
+
+// NewReportServiceServer creates a new ReportServiceServer instance.
+// Either service may be nil, in which case the RPCs for that report kind
+// return Unimplemented.
+func NewReportServiceServer(
+	ftoSvc reporting.FTOReportService,
+	portfolioSvc reporting.PortfolioReportService,
+	logger logging.Logger,
+) *ReportServiceServer {
+	return &ReportServiceServer{
+		ftoSvc:       ftoSvc,
+		portfolioSvc: portfolioSvc,
+		logger:       logger,
+	}
+}
+
+// GenerateFTOReport queues an FTO report
+func (s *ReportServiceServer) GenerateFTOReport(
+	ctx context.Context,
+	req *pb.GenerateFTOReportRequest,
+) (*pb.GenerateFTOReportResponse, error) {
+	if s.ftoSvc == nil {
+		return nil, status.Error(codes.Unimplemented, "FTO reporting is not configured")
+	}
+	if len(req.TargetMolecules) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one target molecule is required")
+	}
+	if len(req.Jurisdictions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one jurisdiction is required")
+	}
+
+	molecules := make([]reporting.MoleculeInput, 0, len(req.TargetMolecules))
+	for i, m := range req.TargetMolecules {
+		if m == nil || m.Value == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "target_molecules[%d].value is required", i)
+		}
+		format := m.Format
+		if format == "" {
+			format = "smiles"
+		}
+		molecules = append(molecules, reporting.MoleculeInput{Format: format, Value: m.Value, Name: m.Name})
+	}
+
+	depth := reporting.AnalysisDepth(req.AnalysisDepth)
+	if depth == "" {
+		depth = reporting.DepthStandard
+	}
+	lang := reporting.ReportLanguage(strings.ToUpper(req.Language))
+	if lang == "" {
+		lang = reporting.LangEN
+	}
+
+	resp, err := s.ftoSvc.Generate(ctx, &reporting.FTOReportRequest{
+		TargetMolecules:     molecules,
+		TargetProduct:       req.TargetProduct,
+		Jurisdictions:       req.Jurisdictions,
+		CompetitorFilter:    req.CompetitorFilter,
+		AnalysisDepth:       depth,
+		IncludeDesignAround: req.IncludeDesignAround,
+		IncludeClaimChart:   req.IncludeClaimChart,
+		Language:            lang,
+		RequestedBy:         req.RequestedBy,
+	})
+	if err != nil {
+		s.logger.Error("failed to generate FTO report", logging.Err(err))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.GenerateFTOReportResponse{
+		ReportId:                 resp.ReportID,
+		Status:                   string(resp.Status),
+		EstimatedDurationSeconds: int32(resp.EstimatedDuration / time.Second),
+		CreatedAt:                timeToUnix(resp.CreatedAt),
+	}, nil
+}
+
+// GeneratePortfolioReport generates a full portfolio report
+func (s *ReportServiceServer) GeneratePortfolioReport(
+	ctx context.Context,
+	req *pb.GeneratePortfolioReportRequest,
+) (*pb.GeneratePortfolioReportResponse, error) {
+	if s.portfolioSvc == nil {
+		return nil, status.Error(codes.Unimplemented, "portfolio reporting is not configured")
+	}
+	if req.PortfolioId == "" {
+		return nil, status.Error(codes.InvalidArgument, "portfolio_id is required")
+	}
+
+	sections := make([]reporting.ReportSection, 0, len(req.IncludeSections))
+	for _, sec := range req.IncludeSections {
+		sections = append(sections, reporting.ReportSection(sec))
+	}
+	format := reporting.ExportFormat(strings.ToUpper(req.OutputFormat))
+	if format == "" {
+		format = reporting.FormatPortfolioPDF
+	}
+	lang := reporting.ReportLanguage(strings.ToUpper(req.Language))
+	if lang == "" {
+		lang = reporting.LangEN
+	}
+
+	result, err := s.portfolioSvc.GenerateFullReport(ctx, &reporting.PortfolioReportRequest{
+		PortfolioID:      req.PortfolioId,
+		IncludeSections:  sections,
+		CompetitorIDs:    req.CompetitorIds,
+		TechDomainFilter: req.TechDomainFilter,
+		Language:         lang,
+		OutputFormat:     format,
+		RequestedBy:      req.RequestedBy,
+	})
+	if err != nil {
+		s.logger.Error("failed to generate portfolio report",
+			logging.Err(err),
+			logging.String("portfolio_id", req.PortfolioId))
+		return nil, mapDomainError(err)
+	}
+
+	urls := make(map[string]string, len(result.ExportURLs))
+	for f, u := range result.ExportURLs {
+		urls[string(f)] = u
+	}
+
+	return &pb.GeneratePortfolioReportResponse{
+		ReportId:    result.ReportID,
+		Status:      string(result.Status),
+		ExportUrls:  urls,
+		GeneratedAt: timeToUnix(result.GeneratedAt),
+	}, nil
+}
+
+// GetReportStatus returns the current progress of a report job
+func (s *ReportServiceServer) GetReportStatus(
+	ctx context.Context,
+	req *pb.GetReportStatusRequest,
+) (*pb.GetReportStatusResponse, error) {
+	if req.ReportId == "" {
+		return nil, status.Error(codes.InvalidArgument, "report_id is required")
+	}
+
+	info, err := s.reportStatus(ctx, req.ReportId, req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetReportStatusResponse{Progress: progressToProto(info)}, nil
+}
+
+// WatchReportProgress streams progress updates until the report reaches a
+// terminal state or the client goes away. A snapshot is sent immediately and
+// then only when the status, percentage or message changes.
+func (s *ReportServiceServer) WatchReportProgress(
+	req *pb.WatchReportProgressRequest,
+	stream pb.ReportService_WatchReportProgressServer,
+) error {
+	if req.ReportId == "" {
+		return status.Error(codes.InvalidArgument, "report_id is required")
+	}
+
+	ctx := stream.Context()
+	interval := clampPollInterval(req.PollIntervalMs)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *reporting.ReportStatusInfo
+	for {
+		info, err := s.reportStatus(ctx, req.ReportId, req.Kind)
+		if err != nil {
+			return err
+		}
+
+		if last == nil || *info != *last {
+			if err := stream.Send(progressToProto(info)); err != nil {
+				return err
+			}
+			last = info
+		}
+
+		if isTerminalReportStatus(info.Status) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListFTOReports lists FTO reports
+func (s *ReportServiceServer) ListFTOReports(
+	ctx context.Context,
+	req *pb.ListFTOReportsRequest,
+) (*pb.ListFTOReportsResponse, error) {
+	if s.ftoSvc == nil {
+		return nil, status.Error(codes.Unimplemented, "FTO reporting is not configured")
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultPortfolioPageSize
+	}
+	if pageSize > maxPortfolioPageSize {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 1 and 100")
+	}
+	page := int(req.Page)
+	if page <= 0 {
+		page = 1
+	}
+
+	filter := &reporting.FTOReportFilter{RequestedBy: req.RequestedBy}
+	for _, st := range req.Status {
+		filter.Status = append(filter.Status, reporting.ReportStatus(st))
+	}
+
+	result, err := s.ftoSvc.ListReports(ctx, filter, &common.Pagination{Page: page, PageSize: pageSize})
+	if err != nil {
+		s.logger.Error("failed to list FTO reports", logging.Err(err))
+		return nil, mapDomainError(err)
+	}
+
+	reports := make([]*pb.ReportSummary, 0, len(result.Items))
+	for _, r := range result.Items {
+		summary := &pb.ReportSummary{
+			ReportId:            r.ReportID,
+			Title:               r.Title,
+			Status:              string(r.Status),
+			TargetMoleculeCount: int32(r.TargetMoleculeCount),
+			JurisdictionCount:   int32(r.JurisdictionCount),
+			HighRiskCount:       int32(r.HighRiskCount),
+			MediumRiskCount:     int32(r.MediumRiskCount),
+			LowRiskCount:        int32(r.LowRiskCount),
+			CreatedAt:           timeToUnix(r.CreatedAt),
+		}
+		if r.CompletedAt != nil {
+			summary.CompletedAt = r.CompletedAt.Unix()
+		}
+		reports = append(reports, summary)
+	}
+
+	return &pb.ListFTOReportsResponse{
+		Reports:    reports,
+		TotalCount: int64(result.Pagination.Total),
+		Page:       int32(result.Pagination.Page),
+		PageSize:   int32(result.Pagination.PageSize),
+	}, nil
+}
+
+// DownloadReport streams a rendered report in fixed-size chunks
+func (s *ReportServiceServer) DownloadReport(
+	req *pb.DownloadReportRequest,
+	stream pb.ReportService_DownloadReportServer,
+) error {
+	if req.ReportId == "" {
+		return status.Error(codes.InvalidArgument, "report_id is required")
+	}
+
+	ctx := stream.Context()
+	format := strings.ToUpper(req.Format)
+	if format == "" {
+		format = "PDF"
+	}
+	contentType, ok := reportContentTypes[format]
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unsupported format: %s", req.Format)
+	}
+
+	var reader io.Reader
+	switch req.Kind {
+	case pb.ReportKind_REPORT_KIND_PORTFOLIO:
+		if s.portfolioSvc == nil {
+			return status.Error(codes.Unimplemented, "portfolio reporting is not configured")
+		}
+		data, err := s.portfolioSvc.ExportReport(ctx, req.ReportId, reporting.ExportFormat(format))
+		if err != nil {
+			s.logger.Error("failed to export portfolio report",
+				logging.Err(err),
+				logging.String("report_id", req.ReportId))
+			return mapDomainError(err)
+		}
+		reader = bytes.NewReader(data)
+	default:
+		if s.ftoSvc == nil {
+			return status.Error(codes.Unimplemented, "FTO reporting is not configured")
+		}
+		if format != string(reporting.FormatPDF) && format != string(reporting.FormatDOCX) {
+			return status.Errorf(codes.InvalidArgument, "unsupported format for FTO report: %s", req.Format)
+		}
+		rc, err := s.ftoSvc.GetReport(ctx, req.ReportId, reporting.ReportFormat(format))
+		if err != nil {
+			s.logger.Error("failed to open FTO report",
+				logging.Err(err),
+				logging.String("report_id", req.ReportId))
+			return mapDomainError(err)
+		}
+		defer rc.Close()
+		reader = rc
+	}
+
+	buf := make([]byte, reportChunkSize)
+	var offset int64
+	for {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			chunk := &pb.ReportChunk{
+				Data:   append([]byte(nil), buf[:n]...),
+				Offset: offset,
+			}
+			if offset == 0 {
+				chunk.ContentType = contentType
+			}
+			if sendErr := stream.Send(chunk); sendErr != nil {
+				return sendErr
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			s.logger.Error("failed to read report",
+				logging.Err(err),
+				logging.String("report_id", req.ReportId))
+			return status.Error(codes.Internal, "failed to read report")
+		}
+	}
+}
+
+// DeleteReport deletes an FTO report and its artifacts
+func (s *ReportServiceServer) DeleteReport(
+	ctx context.Context,
+	req *pb.DeleteReportRequest,
+) (*pb.DeleteReportResponse, error) {
+	if s.ftoSvc == nil {
+		return nil, status.Error(codes.Unimplemented, "FTO reporting is not configured")
+	}
+	if req.ReportId == "" {
+		return nil, status.Error(codes.InvalidArgument, "report_id is required")
+	}
+
+	if err := s.ftoSvc.DeleteReport(ctx, req.ReportId); err != nil {
+		s.logger.Error("failed to delete report",
+			logging.Err(err),
+			logging.String("report_id", req.ReportId))
+		return nil, mapDomainError(err)
+	}
+
+	return &pb.DeleteReportResponse{Success: true}, nil
+}
+
+// reportStatus resolves the status of a report from the service that owns it.
+func (s *ReportServiceServer) reportStatus(
+	ctx context.Context,
+	reportID string,
+	kind pb.ReportKind,
+) (*reporting.ReportStatusInfo, error) {
+	var (
+		info *reporting.ReportStatusInfo
+		err  error
+	)
+	switch kind {
+	case pb.ReportKind_REPORT_KIND_PORTFOLIO:
+		if s.portfolioSvc == nil {
+			return nil, status.Error(codes.Unimplemented, "portfolio reporting is not configured")
+		}
+		info, err = s.portfolioSvc.GetReportStatus(ctx, reportID)
+	default:
+		if s.ftoSvc == nil {
+			return nil, status.Error(codes.Unimplemented, "FTO reporting is not configured")
+		}
+		info, err = s.ftoSvc.GetStatus(ctx, reportID)
+	}
+	if err != nil {
+		s.logger.Error("failed to get report status",
+			logging.Err(err),
+			logging.String("report_id", reportID))
+		return nil, mapDomainError(err)
+	}
+	if info == nil {
+		return nil, status.Errorf(codes.NotFound, "report not found: %s", reportID)
+	}
+	return info, nil
+}
+
+func progressToProto(info *reporting.ReportStatusInfo) *pb.ReportProgress {
+	return &pb.ReportProgress{
+		ReportId:    info.ReportID,
+		Status:      string(info.Status),
+		ProgressPct: int32(info.ProgressPct),
+		Message:     info.Message,
+		ObservedAt:  time.Now().UnixMilli(),
+	}
+}
+
+func isTerminalReportStatus(st reporting.ReportStatus) bool {
+	return st == reporting.StatusCompleted || st == reporting.StatusFailed
+}
+
+func clampPollInterval(ms int32) time.Duration {
+	if ms <= 0 {
+		return defaultProgressPollInterval
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d < minProgressPollInterval {
+		return minProgressPollInterval
+	}
+	if d > maxProgressPollInterval {
+		return maxProgressPollInterval
+	}
+	return d
+}
+
+//Personal.AI order the ending
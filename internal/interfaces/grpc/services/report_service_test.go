@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/reporting"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// MockFTOReportService mocks reporting.FTOReportService
+type MockFTOReportService struct {
+	mock.Mock
+	reporting.FTOReportService
+}
+
+func (m *MockFTOReportService) GetStatus(ctx context.Context, reportID string) (*reporting.ReportStatusInfo, error) {
+	args := m.Called(ctx, reportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reporting.ReportStatusInfo), args.Error(1)
+}
+
+func (m *MockFTOReportService) GetReport(ctx context.Context, reportID string, format reporting.ReportFormat) (io.ReadCloser, error) {
+	args := m.Called(ctx, reportID, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func newReportTestClient(t *testing.T, fto reporting.FTOReportService) pb.ReportServiceClient {
+	conn := dialTestServer(t, func(s *grpc.Server) {
+		pb.RegisterReportServiceServer(s, NewReportServiceServer(fto, nil, newTestLogger()))
+	})
+	return pb.NewReportServiceClient(conn)
+}
+
+func TestWatchReportProgress(t *testing.T) {
+	fto := new(MockFTOReportService)
+	client := newReportTestClient(t, fto)
+
+	queued := &reporting.ReportStatusInfo{ReportID: "r1", Status: reporting.StatusQueued}
+	running := &reporting.ReportStatusInfo{ReportID: "r1", Status: reporting.StatusProcessing, ProgressPct: 50}
+	done := &reporting.ReportStatusInfo{ReportID: "r1", Status: reporting.StatusCompleted, ProgressPct: 100}
+	fto.On("GetStatus", mock.Anything, "r1").Return(queued, nil).Once()
+	fto.On("GetStatus", mock.Anything, "r1").Return(queued, nil).Once()
+	fto.On("GetStatus", mock.Anything, "r1").Return(running, nil).Once()
+	fto.On("GetStatus", mock.Anything, "r1").Return(done, nil).Once()
+
+	stream, err := client.WatchReportProgress(context.Background(), &pb.WatchReportProgressRequest{
+		ReportId: "r1", PollIntervalMs: 1,
+	})
+	require.NoError(t, err)
+
+	var got []*pb.ReportProgress
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, p)
+	}
+
+	// The duplicate Queued snapshot is suppressed.
+	require.Len(t, got, 3)
+	assert.Equal(t, "Queued", got[0].Status)
+	assert.Equal(t, int32(50), got[1].ProgressPct)
+	assert.Equal(t, "Completed", got[2].Status)
+	assert.NotZero(t, got[2].ObservedAt)
+	fto.AssertExpectations(t)
+}
+
+func TestWatchReportProgress_NotFound(t *testing.T) {
+	fto := new(MockFTOReportService)
+	client := newReportTestClient(t, fto)
+	fto.On("GetStatus", mock.Anything, "missing").Return(nil, errors.NewNotFound("report not found"))
+
+	stream, err := client.WatchReportProgress(context.Background(), &pb.WatchReportProgressRequest{ReportId: "missing"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestWatchReportProgress_PortfolioNotConfigured(t *testing.T) {
+	client := newReportTestClient(t, new(MockFTOReportService))
+
+	stream, err := client.WatchReportProgress(context.Background(), &pb.WatchReportProgressRequest{
+		ReportId: "r1", Kind: pb.ReportKind_REPORT_KIND_PORTFOLIO,
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestDownloadReport_Chunks(t *testing.T) {
+	fto := new(MockFTOReportService)
+	client := newReportTestClient(t, fto)
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), (reportChunkSize*2+100)/16)
+	fto.On("GetReport", mock.Anything, "r1", reporting.FormatPDF).
+		Return(io.NopCloser(bytes.NewReader(payload)), nil)
+
+	stream, err := client.DownloadReport(context.Background(), &pb.DownloadReportRequest{ReportId: "r1"})
+	require.NoError(t, err)
+
+	var (
+		buf    bytes.Buffer
+		chunks []*pb.ReportChunk
+	)
+	for {
+		c, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), c.Offset)
+		buf.Write(c.Data)
+		chunks = append(chunks, c)
+	}
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, "application/pdf", chunks[0].ContentType)
+	assert.Empty(t, chunks[1].ContentType)
+	assert.Equal(t, payload, buf.Bytes())
+}
+
+func TestDownloadReport_UnsupportedFormat(t *testing.T) {
+	client := newReportTestClient(t, new(MockFTOReportService))
+
+	stream, err := client.DownloadReport(context.Background(), &pb.DownloadReportRequest{ReportId: "r1", Format: "PPTX"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestClampPollInterval(t *testing.T) {
+	assert.Equal(t, defaultProgressPollInterval, clampPollInterval(0))
+	assert.Equal(t, minProgressPollInterval, clampPollInterval(1))
+	assert.Equal(t, maxProgressPollInterval, clampPollInterval(600000))
+}
@@ -67,10 +67,11 @@ func (h *AIHandler) AnalyzePatent(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.backend.Predict(ctx, &common.PredictRequest{
 		ModelName: "deepseek-chat",
 		InputData: input,
+		Metadata:  map[string]string{common.MetaFeature: "ai.analyze_patent"},
 	})
 	if err != nil {
 		h.logger.Error("AI predict failed", logging.Err(err))
-		writeError(w, http.StatusInternalServerError, errors.NewInternal("AI analysis failed"))
+		writeAIError(w, err, "AI analysis failed")
 		return
 	}
 
@@ -108,10 +109,11 @@ func (h *AIHandler) Chat(w http.ResponseWriter, r *http.Request) {
 	resp, err := h.backend.Predict(ctx, &common.PredictRequest{
 		ModelName: "deepseek-chat",
 		InputData: input,
+		Metadata:  map[string]string{common.MetaFeature: "ai.chat"},
 	})
 	if err != nil {
 		h.logger.Error("AI chat failed", logging.Err(err))
-		writeError(w, http.StatusInternalServerError, errors.NewInternal("AI chat failed"))
+		writeAIError(w, err, "AI chat failed")
 		return
 	}
 
@@ -121,6 +123,21 @@ func (h *AIHandler) Chat(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// writeAIError reports an exhausted LLM budget as 429 and a request blocked
+// by the tenant's guardrail policy as 403, and masks every other backend
+// failure.
+func writeAIError(w http.ResponseWriter, err error, msg string) {
+	if errors.IsCode(err, errors.ErrCodeTooManyRequests) {
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+	if errors.IsForbidden(err) {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, errors.NewInternal(msg))
+}
+
 func (h *AIHandler) Health(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,92 @@
+// Tests for the AI analysis and chat HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockModelBackend implements common.ModelBackend for testing.
+type mockModelBackend struct {
+	err error
+}
+
+func (m *mockModelBackend) Predict(context.Context, *common.PredictRequest) (*common.PredictResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &common.PredictResponse{Outputs: map[string][]byte{"content": []byte("ok")}}, nil
+}
+func (m *mockModelBackend) PredictStream(context.Context, *common.PredictRequest) (<-chan *common.PredictResponse, error) {
+	return nil, m.err
+}
+func (m *mockModelBackend) Healthy(context.Context) error { return nil }
+func (m *mockModelBackend) Close() error                  { return nil }
+
+func serveAI(backend common.ModelBackend, path, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewAIHandler(backend, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body)))
+	return rec
+}
+
+func TestAIHandler_BackendErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantMsg    string
+	}{
+		{
+			name:       "guardrail block",
+			err:        errors.New(errors.ErrCodeForbidden, "tenant policy forbids external models"),
+			wantStatus: http.StatusForbidden,
+			wantMsg:    "tenant policy forbids external models",
+		},
+		{
+			name:       "budget exhausted",
+			err:        errors.New(errors.ErrCodeTooManyRequests, "LLM budget exhausted"),
+			wantStatus: http.StatusTooManyRequests,
+			wantMsg:    "LLM budget exhausted",
+		},
+		{
+			name:       "backend failure is masked",
+			err:        errors.New(errors.ErrCodeInternal, "upstream said no: secret detail"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	routes := map[string]string{
+		"/api/v1/ai/analyze-patent": `{"patent_title":"OLED host"}`,
+		"/api/v1/ai/chat":           `{"message":"hello"}`,
+	}
+	for _, tt := range tests {
+		for path, body := range routes {
+			t.Run(tt.name+" "+path, func(t *testing.T) {
+				rec := serveAI(&mockModelBackend{err: tt.err}, path, body)
+				require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+
+				var resp ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				if tt.wantMsg != "" {
+					assert.Contains(t, resp.Message, tt.wantMsg)
+				} else {
+					assert.NotContains(t, resp.Message, "secret detail")
+				}
+			})
+		}
+	}
+}
+
+//Personal.AI order the ending
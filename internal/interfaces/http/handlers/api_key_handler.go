@@ -0,0 +1,302 @@
+// internal/interfaces/http/handlers/api_key_handler.go
+// 实现 API Key 管理 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：为 CI 等机器客户端签发、查询、调整权限范围、轮换与吊销长期 API Key，
+//   并将 API Key 服务适配为认证中间件的 APIKeyValidator
+// * 核心实现：
+//   - CreateAPIKey / ListAPIKeys / GetAPIKey / ListAPIKeyScopes
+//   - UpdateAPIKeyScopes / RotateAPIKey / RevokeAPIKey / GetAPIKeyUsage
+//   - APIKeyAuthenticator（middleware.RequestAPIKeyValidator 实现）
+//   - RegisterRoutes
+// * 依赖：internal/application/auth/apikey.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	appauth "github.com/turtacn/KeyIP-Intelligence/internal/application/auth"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// APIKeyHandler handles HTTP requests for API key management.
+type APIKeyHandler struct {
+	apiKeySvc appauth.APIKeyService
+	logger    logging.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(
+	apiKeySvc appauth.APIKeyService,
+	logger logging.Logger,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeySvc: apiKeySvc,
+		logger:    logger,
+	}
+}
+
+// CreateAPIKeyBody is the request body for issuing an API key.
+type CreateAPIKeyBody struct {
+	Name          string          `json:"name"`
+	Scopes        []string        `json:"scopes"`
+	Tier          user.APIKeyTier `json:"tier,omitempty"`
+	ExpiresInDays int             `json:"expires_in_days,omitempty"`
+}
+
+// APIKeyScopesBody is the request body for replacing a key's scopes.
+type APIKeyScopesBody struct {
+	Scopes []string `json:"scopes"`
+}
+
+// RotateAPIKeyBody is the optional request body for rotating a key. The old
+// key keeps working for GracePeriodHours after rotation.
+type RotateAPIKeyBody struct {
+	GracePeriodHours int `json:"grace_period_hours,omitempty"`
+}
+
+// RegisterRoutes registers all API key routes.
+func (h *APIKeyHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/api-keys", h.CreateAPIKey)
+	mux.HandleFunc("GET /api/v1/api-keys", h.ListAPIKeys)
+	mux.HandleFunc("GET /api/v1/api-keys/scopes", h.ListAPIKeyScopes)
+	mux.HandleFunc("GET /api/v1/api-keys/{id}", h.GetAPIKey)
+	mux.HandleFunc("PUT /api/v1/api-keys/{id}/scopes", h.UpdateAPIKeyScopes)
+	mux.HandleFunc("POST /api/v1/api-keys/{id}/rotate", h.RotateAPIKey)
+	mux.HandleFunc("DELETE /api/v1/api-keys/{id}", h.RevokeAPIKey)
+	mux.HandleFunc("GET /api/v1/api-keys/{id}/usage", h.GetAPIKeyUsage)
+}
+
+// CreateAPIKey handles POST /api/v1/api-keys
+//
+// The plaintext key is only included in this response.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body CreateAPIKeyBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	issued, err := h.apiKeySvc.Issue(r.Context(), &appauth.IssueAPIKeyRequest{
+		UserID:        getUserIDFromContext(r),
+		TenantID:      middleware.ContextGetTenantID(r.Context()),
+		Roles:         getRolesFromContext(r),
+		Name:          body.Name,
+		Scopes:        body.Scopes,
+		Tier:          body.Tier,
+		ExpiresInDays: body.ExpiresInDays,
+	})
+	if err != nil {
+		h.logger.Error("failed to issue API key", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+// ListAPIKeys handles GET /api/v1/api-keys
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeySvc.List(r.Context(), getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to list API keys", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+	if keys == nil {
+		keys = []*user.APIKey{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"api_keys": keys})
+}
+
+// ListAPIKeyScopes handles GET /api/v1/api-keys/scopes
+func (h *APIKeyHandler) ListAPIKeyScopes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"scopes": appauth.APIKeyScopes()})
+}
+
+// GetAPIKey handles GET /api/v1/api-keys/{id}
+func (h *APIKeyHandler) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := apiKeyIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeySvc.Get(r.Context(), keyID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to get API key", logging.Err(err), logging.String("key_id", keyID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, key)
+}
+
+// UpdateAPIKeyScopes handles PUT /api/v1/api-keys/{id}/scopes
+func (h *APIKeyHandler) UpdateAPIKeyScopes(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := apiKeyIDFromPath(w, r)
+	if !ok {
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body APIKeyScopesBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	key, err := h.apiKeySvc.UpdateScopes(r.Context(), &appauth.UpdateAPIKeyScopesRequest{
+		KeyID:  keyID,
+		UserID: getUserIDFromContext(r),
+		Roles:  getRolesFromContext(r),
+		Scopes: body.Scopes,
+	})
+	if err != nil {
+		h.logger.Error("failed to update API key scopes", logging.Err(err), logging.String("key_id", keyID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, key)
+}
+
+// RotateAPIKey handles POST /api/v1/api-keys/{id}/rotate
+//
+// The body is optional; without it the old key is revoked immediately.
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := apiKeyIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var body RotateAPIKeyBody
+	if r.ContentLength != 0 {
+		if !isContentTypeJSON(r) {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+			return
+		}
+	}
+
+	issued, err := h.apiKeySvc.Rotate(r.Context(), &appauth.RotateAPIKeyRequest{
+		KeyID:       keyID,
+		UserID:      getUserIDFromContext(r),
+		GracePeriod: time.Duration(body.GracePeriodHours) * time.Hour,
+	})
+	if err != nil {
+		h.logger.Error("failed to rotate API key", logging.Err(err), logging.String("key_id", keyID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, issued)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/api-keys/{id}
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := apiKeyIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeySvc.Revoke(r.Context(), keyID, getUserIDFromContext(r)); err != nil {
+		h.logger.Error("failed to revoke API key", logging.Err(err), logging.String("key_id", keyID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// GetAPIKeyUsage handles GET /api/v1/api-keys/{id}/usage
+func (h *APIKeyHandler) GetAPIKeyUsage(w http.ResponseWriter, r *http.Request) {
+	keyID, ok := apiKeyIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := h.apiKeySvc.Usage(r.Context(), keyID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to get API key usage", logging.Err(err), logging.String("key_id", keyID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, usage)
+}
+
+func apiKeyIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	keyID := r.PathValue("id")
+	if keyID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("id", "api key id is required"))
+		return "", false
+	}
+	return keyID, true
+}
+
+// getRolesFromContext returns the JWT roles of the caller. API key callers
+// have none; their grants come from stored role permissions only.
+func getRolesFromContext(r *http.Request) []string {
+	if claims := middleware.ContextGetClaims(r.Context()); claims != nil {
+		return claims.Roles
+	}
+	return nil
+}
+
+// APIKeyAuthenticator adapts APIKeyService to the auth middleware so that
+// requests made with an API key are checked, quota-counted and tracked.
+type APIKeyAuthenticator struct {
+	apiKeySvc appauth.APIKeyService
+}
+
+// NewAPIKeyAuthenticator creates an APIKeyAuthenticator.
+func NewAPIKeyAuthenticator(apiKeySvc appauth.APIKeyService) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{apiKeySvc: apiKeySvc}
+}
+
+// ValidateAPIKey implements middleware.APIKeyValidator.
+func (a *APIKeyAuthenticator) ValidateAPIKey(key string) (*middleware.APIKeyInfo, error) {
+	return a.ValidateAPIKeyRequest(context.Background(), key, "")
+}
+
+// ValidateAPIKeyRequest implements middleware.RequestAPIKeyValidator.
+func (a *APIKeyAuthenticator) ValidateAPIKeyRequest(ctx context.Context, key, clientIP string) (*middleware.APIKeyInfo, error) {
+	k, err := a.apiKeySvc.Authenticate(ctx, key, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	info := &middleware.APIKeyInfo{
+		KeyID:     k.ID.String(),
+		UserID:    k.UserID.String(),
+		Scopes:    k.Scopes,
+		RateLimit: k.RateLimit,
+		Tier:      string(k.Tier),
+	}
+	if k.OrganizationID != nil {
+		info.TenantID = k.OrganizationID.String()
+	}
+	return info, nil
+}
+
+//Personal.AI order the ending
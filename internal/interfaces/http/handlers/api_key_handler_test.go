@@ -0,0 +1,264 @@
+// Tests for the API key HTTP handler and middleware adapter.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appauth "github.com/turtacn/KeyIP-Intelligence/internal/application/auth"
+	"github.com/turtacn/KeyIP-Intelligence/internal/domain/user"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockAPIKeyService implements appauth.APIKeyService for testing.
+type mockAPIKeyService struct {
+	issueFn        func(context.Context, *appauth.IssueAPIKeyRequest) (*appauth.IssuedAPIKey, error)
+	listFn         func(context.Context, string) ([]*user.APIKey, error)
+	getFn          func(context.Context, string, string) (*user.APIKey, error)
+	updateScopesFn func(context.Context, *appauth.UpdateAPIKeyScopesRequest) (*user.APIKey, error)
+	rotateFn       func(context.Context, *appauth.RotateAPIKeyRequest) (*appauth.IssuedAPIKey, error)
+	revokeFn       func(context.Context, string, string) error
+	usageFn        func(context.Context, string, string) (*appauth.APIKeyUsage, error)
+	authFn         func(context.Context, string, string) (*user.APIKey, error)
+}
+
+func (m *mockAPIKeyService) Issue(ctx context.Context, req *appauth.IssueAPIKeyRequest) (*appauth.IssuedAPIKey, error) {
+	return m.issueFn(ctx, req)
+}
+func (m *mockAPIKeyService) List(ctx context.Context, userID string) ([]*user.APIKey, error) {
+	return m.listFn(ctx, userID)
+}
+func (m *mockAPIKeyService) Get(ctx context.Context, keyID, userID string) (*user.APIKey, error) {
+	return m.getFn(ctx, keyID, userID)
+}
+func (m *mockAPIKeyService) UpdateScopes(ctx context.Context, req *appauth.UpdateAPIKeyScopesRequest) (*user.APIKey, error) {
+	return m.updateScopesFn(ctx, req)
+}
+func (m *mockAPIKeyService) Rotate(ctx context.Context, req *appauth.RotateAPIKeyRequest) (*appauth.IssuedAPIKey, error) {
+	return m.rotateFn(ctx, req)
+}
+func (m *mockAPIKeyService) Revoke(ctx context.Context, keyID, userID string) error {
+	return m.revokeFn(ctx, keyID, userID)
+}
+func (m *mockAPIKeyService) Usage(ctx context.Context, keyID, userID string) (*appauth.APIKeyUsage, error) {
+	return m.usageFn(ctx, keyID, userID)
+}
+func (m *mockAPIKeyService) Authenticate(ctx context.Context, secret, clientIP string) (*user.APIKey, error) {
+	return m.authFn(ctx, secret, clientIP)
+}
+
+type staticTokenValidator struct{ claims *middleware.Claims }
+
+func (v staticTokenValidator) ValidateToken(string) (*middleware.Claims, error) { return v.claims, nil }
+
+// withClaims serves req through the auth middleware so handlers see claims.
+func withClaims(h http.HandlerFunc, claims *middleware.Claims, rec http.ResponseWriter, req *http.Request) {
+	auth := middleware.NewAuthMiddleware(staticTokenValidator{claims}, nil, middleware.AuthConfig{}, testutil.NewNopLogger())
+	req.Header.Set("Authorization", "Bearer jwt")
+	auth.Authenticate()(h).ServeHTTP(rec, req)
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := &mockAPIKeyService{
+			issueFn: func(_ context.Context, req *appauth.IssueAPIKeyRequest) (*appauth.IssuedAPIKey, error) {
+				assert.Equal(t, "u-1", req.UserID)
+				assert.Equal(t, "t-1", req.TenantID)
+				assert.Equal(t, []string{"ip_manager"}, req.Roles)
+				assert.Equal(t, "ci", req.Name)
+				assert.Equal(t, []string{"patent:read"}, req.Scopes)
+				assert.Equal(t, 90, req.ExpiresInDays)
+				return &appauth.IssuedAPIKey{Key: &user.APIKey{Name: req.Name}, Secret: "kip_secret"}, nil
+			},
+		}
+		h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+		body := []byte(`{"name":"ci","scopes":["patent:read"],"expires_in_days":90}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		withClaims(h.CreateAPIKey, &middleware.Claims{
+			UserID:    "u-1",
+			TenantID:  "t-1",
+			Roles:     []string{"ip_manager"},
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var issued appauth.IssuedAPIKey
+		decodeCommentData(t, rec, &issued)
+		assert.Equal(t, "kip_secret", issued.Secret)
+	})
+
+	t.Run("forbidden scope", func(t *testing.T) {
+		svc := &mockAPIKeyService{
+			issueFn: func(context.Context, *appauth.IssueAPIKeyRequest) (*appauth.IssuedAPIKey, error) {
+				return nil, errors.New(errors.ErrCodeForbidden, "cannot grant a scope you do not hold")
+			},
+		}
+		h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader([]byte(`{"name":"ci","scopes":["graph:admin"]}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		h.CreateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("invalid content type", func(t *testing.T) {
+		h := NewAPIKeyHandler(&mockAPIKeyService{}, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys", bytes.NewReader([]byte(`{}`)))
+		rec := httptest.NewRecorder()
+
+		h.CreateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAPIKeyHandler_ListAPIKeys_Empty(t *testing.T) {
+	svc := &mockAPIKeyService{
+		listFn: func(context.Context, string) ([]*user.APIKey, error) { return nil, nil },
+	}
+	h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+	rec := httptest.NewRecorder()
+
+	h.ListAPIKeys(rec, httptest.NewRequest(http.MethodGet, "/api/v1/api-keys", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var out struct {
+		APIKeys []*user.APIKey `json:"api_keys"`
+	}
+	decodeCommentData(t, rec, &out)
+	assert.NotNil(t, out.APIKeys)
+	assert.Empty(t, out.APIKeys)
+}
+
+func TestAPIKeyHandler_RotateAPIKey(t *testing.T) {
+	var got *appauth.RotateAPIKeyRequest
+	svc := &mockAPIKeyService{
+		rotateFn: func(_ context.Context, req *appauth.RotateAPIKeyRequest) (*appauth.IssuedAPIKey, error) {
+			got = req
+			return &appauth.IssuedAPIKey{Key: &user.APIKey{}, Secret: "kip_new"}, nil
+		},
+	}
+	h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+
+	t.Run("with grace period", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys/k-1/rotate", bytes.NewReader([]byte(`{"grace_period_hours":24}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "k-1")
+		rec := httptest.NewRecorder()
+
+		h.RotateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		require.NotNil(t, got)
+		assert.Equal(t, "k-1", got.KeyID)
+		assert.Equal(t, 24*time.Hour, got.GracePeriod)
+	})
+
+	t.Run("without body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/api-keys/k-1/rotate", nil)
+		req.SetPathValue("id", "k-1")
+		rec := httptest.NewRecorder()
+
+		h.RotateAPIKey(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Zero(t, got.GracePeriod)
+	})
+}
+
+func TestAPIKeyHandler_UpdateAPIKeyScopes(t *testing.T) {
+	svc := &mockAPIKeyService{
+		updateScopesFn: func(_ context.Context, req *appauth.UpdateAPIKeyScopesRequest) (*user.APIKey, error) {
+			assert.Equal(t, "k-1", req.KeyID)
+			assert.Equal(t, []string{"graph:read"}, req.Scopes)
+			return &user.APIKey{Scopes: req.Scopes}, nil
+		},
+	}
+	h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/api-keys/k-1/scopes", bytes.NewReader([]byte(`{"scopes":["graph:read"]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("id", "k-1")
+	rec := httptest.NewRecorder()
+
+	h.UpdateAPIKeyScopes(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAPIKeyHandler_RevokeAPIKey_NotFound(t *testing.T) {
+	svc := &mockAPIKeyService{
+		revokeFn: func(context.Context, string, string) error {
+			return errors.New(errors.ErrCodeNotFound, "API key not found")
+		},
+	}
+	h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/api-keys/k-1", nil)
+	req.SetPathValue("id", "k-1")
+	rec := httptest.NewRecorder()
+
+	h.RevokeAPIKey(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIKeyHandler_GetAPIKeyUsage(t *testing.T) {
+	svc := &mockAPIKeyService{
+		usageFn: func(_ context.Context, keyID, _ string) (*appauth.APIKeyUsage, error) {
+			return &appauth.APIKeyUsage{KeyID: keyID, Used: 5, Quota: 10, Remaining: 5}, nil
+		},
+	}
+	h := NewAPIKeyHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/api-keys/k-1/usage", nil)
+	req.SetPathValue("id", "k-1")
+	rec := httptest.NewRecorder()
+
+	h.GetAPIKeyUsage(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var usage appauth.APIKeyUsage
+	decodeCommentData(t, rec, &usage)
+	assert.Equal(t, int64(5), usage.Remaining)
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	keyID, userID, orgID := uuid.New(), uuid.New(), uuid.New()
+	svc := &mockAPIKeyService{
+		authFn: func(_ context.Context, secret, ip string) (*user.APIKey, error) {
+			assert.Equal(t, "kip_abc", secret)
+			assert.Equal(t, "10.0.0.1", ip)
+			return &user.APIKey{
+				ID:             keyID,
+				UserID:         userID,
+				OrganizationID: &orgID,
+				Scopes:         []string{"patent:read"},
+				RateLimit:      300,
+				Tier:           user.APIKeyTierProfessional,
+			}, nil
+		},
+	}
+
+	info, err := NewAPIKeyAuthenticator(svc).ValidateAPIKeyRequest(context.Background(), "kip_abc", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, keyID.String(), info.KeyID)
+	assert.Equal(t, userID.String(), info.UserID)
+	assert.Equal(t, orgID.String(), info.TenantID)
+	assert.Equal(t, "professional", info.Tier)
+	assert.Equal(t, []string{"patent:read"}, info.Scopes)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,235 @@
+// internal/interfaces/http/handlers/assignee_handler.go
+// 实现申请人实体解析管理 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：供数据运营人员解析原始申请人名称、处理低置信度合并复核队列，并维护母公司/子公司层级
+// * 核心实现：
+//   - ListAssigneeReviews / GetAssigneeHierarchy：需要 patent:read 权限
+//   - ResolveAssignee / ApproveAssigneeReview / RejectAssigneeReview / SetAssigneeParent：需要 patent:write 权限
+//   - RegisterRoutes
+// * 依赖：internal/application/assignee/service.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/assignee"
+	assigneedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/auth/keycloak"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// AssigneeHandler handles HTTP requests for assignee entity resolution.
+type AssigneeHandler struct {
+	assigneeSvc assignee.Service
+	logger      logging.Logger
+}
+
+// NewAssigneeHandler creates a new AssigneeHandler.
+func NewAssigneeHandler(assigneeSvc assignee.Service, logger logging.Logger) *AssigneeHandler {
+	return &AssigneeHandler{
+		assigneeSvc: assigneeSvc,
+		logger:      logger,
+	}
+}
+
+// ResolveAssigneeBody is the request body for resolving a raw assignee name.
+type ResolveAssigneeBody struct {
+	Name string `json:"name"`
+}
+
+// SetAssigneeParentBody is the request body for linking an assignee to its
+// parent company. A null parent_id detaches it.
+type SetAssigneeParentBody struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// RegisterRoutes registers all assignee admin routes.
+func (h *AssigneeHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/admin/assignees/resolve", h.ResolveAssignee)
+	mux.HandleFunc("GET /api/v1/admin/assignees/reviews", h.ListAssigneeReviews)
+	mux.HandleFunc("POST /api/v1/admin/assignees/reviews/{id}/approve", h.ApproveAssigneeReview)
+	mux.HandleFunc("POST /api/v1/admin/assignees/reviews/{id}/reject", h.RejectAssigneeReview)
+	mux.HandleFunc("PUT /api/v1/admin/assignees/{id}/parent", h.SetAssigneeParent)
+	mux.HandleFunc("GET /api/v1/admin/assignees/{id}/hierarchy", h.GetAssigneeHierarchy)
+}
+
+// ResolveAssignee handles POST /api/v1/admin/assignees/resolve
+//
+// Resolution may create an assignee or queue a review, so it needs write
+// permission.
+func (h *AssigneeHandler) ResolveAssignee(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentWrite) {
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+	var body ResolveAssigneeBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	res, err := h.assigneeSvc.Resolve(r.Context(), body.Name)
+	if err != nil {
+		h.logger.Error("failed to resolve assignee", logging.Err(err), logging.String("name", body.Name))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ListAssigneeReviews handles GET /api/v1/admin/assignees/reviews
+//
+// Query parameters: status (pending, approved or rejected; default
+// pending), limit, offset.
+func (h *AssigneeHandler) ListAssigneeReviews(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, offset := 0, 0
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("limit", "limit must be a non-negative integer"))
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("offset", "offset must be a non-negative integer"))
+			return
+		}
+	}
+
+	reviews, total, err := h.assigneeSvc.ListReviews(r.Context(), assigneedomain.ReviewStatus(q.Get("status")), limit, offset)
+	if err != nil {
+		h.logger.Error("failed to list assignee reviews", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+	if reviews == nil {
+		reviews = []*assigneedomain.MergeReview{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"reviews": reviews, "total": total})
+}
+
+// ApproveAssigneeReview handles POST /api/v1/admin/assignees/reviews/{id}/approve
+func (h *AssigneeHandler) ApproveAssigneeReview(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentWrite) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	review, err := h.assigneeSvc.ApproveReview(r.Context(), id, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to approve assignee review", logging.Err(err), logging.String("review_id", id.String()))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+// RejectAssigneeReview handles POST /api/v1/admin/assignees/reviews/{id}/reject
+//
+// The name is kept apart from the proposed candidate as a new assignee.
+func (h *AssigneeHandler) RejectAssigneeReview(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentWrite) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	review, err := h.assigneeSvc.RejectReview(r.Context(), id, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to reject assignee review", logging.Err(err), logging.String("review_id", id.String()))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, review)
+}
+
+// SetAssigneeParent handles PUT /api/v1/admin/assignees/{id}/parent
+func (h *AssigneeHandler) SetAssigneeParent(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentWrite) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+	var body SetAssigneeParentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	if err := h.assigneeSvc.SetParent(r.Context(), id, body.ParentID); err != nil {
+		h.logger.Error("failed to set assignee parent", logging.Err(err), logging.String("assignee_id", id.String()))
+		writeAppError(w, err)
+		return
+	}
+
+	view, err := h.assigneeSvc.Hierarchy(r.Context(), id)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// GetAssigneeHierarchy handles GET /api/v1/admin/assignees/{id}/hierarchy
+func (h *AssigneeHandler) GetAssigneeHierarchy(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	view, err := h.assigneeSvc.Hierarchy(r.Context(), id)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// parsePathUUID parses the path value name as a UUID, writing 400 and
+// returning false when it is not one.
+func parsePathUUID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError(name, name+" must be a UUID"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,206 @@
+// Tests for the assignee entity resolution HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/assignee"
+	assigneedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/assignee"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockAssigneeService implements assignee.Service for testing.
+type mockAssigneeService struct {
+	resolveFn   func(context.Context, string) (*assignee.Resolution, error)
+	listFn      func(context.Context, assigneedomain.ReviewStatus, int, int) ([]*assigneedomain.MergeReview, int64, error)
+	approveFn   func(context.Context, uuid.UUID, string) (*assigneedomain.MergeReview, error)
+	rejectFn    func(context.Context, uuid.UUID, string) (*assigneedomain.MergeReview, error)
+	setParentFn func(context.Context, uuid.UUID, *uuid.UUID) error
+	hierarchyFn func(context.Context, uuid.UUID) (*assignee.HierarchyView, error)
+}
+
+func (m *mockAssigneeService) Resolve(ctx context.Context, name string) (*assignee.Resolution, error) {
+	return m.resolveFn(ctx, name)
+}
+func (m *mockAssigneeService) ListReviews(ctx context.Context, status assigneedomain.ReviewStatus, limit, offset int) ([]*assigneedomain.MergeReview, int64, error) {
+	return m.listFn(ctx, status, limit, offset)
+}
+func (m *mockAssigneeService) ApproveReview(ctx context.Context, id uuid.UUID, reviewer string) (*assigneedomain.MergeReview, error) {
+	return m.approveFn(ctx, id, reviewer)
+}
+func (m *mockAssigneeService) RejectReview(ctx context.Context, id uuid.UUID, reviewer string) (*assigneedomain.MergeReview, error) {
+	return m.rejectFn(ctx, id, reviewer)
+}
+func (m *mockAssigneeService) SetParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	return m.setParentFn(ctx, id, parentID)
+}
+func (m *mockAssigneeService) Hierarchy(ctx context.Context, id uuid.UUID) (*assignee.HierarchyView, error) {
+	return m.hierarchyFn(ctx, id)
+}
+func (m *mockAssigneeService) Backfill(context.Context, assignee.BackfillOptions) (*assignee.BackfillResult, error) {
+	return &assignee.BackfillResult{}, nil
+}
+func (m *mockAssigneeService) GroupPatentIDs(context.Context, string, int) ([]string, bool, error) {
+	return nil, false, nil
+}
+
+// serveAssignee routes req through the handler's mux as a caller with roles.
+func serveAssignee(svc assignee.Service, roles []string, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewAssigneeHandler(svc, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	withClaims(mux.ServeHTTP, &middleware.Claims{
+		UserID:    "u-1",
+		Roles:     roles,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, rec, req)
+	return rec
+}
+
+func TestAssigneeHandler_ListAssigneeReviews(t *testing.T) {
+	var gotStatus assigneedomain.ReviewStatus
+	var gotLimit, gotOffset int
+	svc := &mockAssigneeService{
+		listFn: func(_ context.Context, status assigneedomain.ReviewStatus, limit, offset int) ([]*assigneedomain.MergeReview, int64, error) {
+			gotStatus, gotLimit, gotOffset = status, limit, offset
+			return nil, 0, nil
+		},
+	}
+
+	t.Run("read permission", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/assignees/reviews?status=approved&limit=10&offset=20", nil)
+		rec := serveAssignee(svc, []string{"researcher"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, assigneedomain.ReviewApproved, gotStatus)
+		assert.Equal(t, 10, gotLimit)
+		assert.Equal(t, 20, gotOffset)
+		var out struct {
+			Reviews []*assigneedomain.MergeReview `json:"reviews"`
+		}
+		decodeCommentData(t, rec, &out)
+		assert.NotNil(t, out.Reviews)
+	})
+
+	t.Run("bad offset", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/assignees/reviews?offset=-1", nil)
+		rec := serveAssignee(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAssigneeHandler_ApproveAssigneeReview(t *testing.T) {
+	reviewID := uuid.New()
+	var gotReviewer string
+	svc := &mockAssigneeService{
+		approveFn: func(_ context.Context, id uuid.UUID, reviewer string) (*assigneedomain.MergeReview, error) {
+			gotReviewer = reviewer
+			if id != reviewID {
+				return nil, errors.New(errors.ErrCodeNotFound, "merge review not found")
+			}
+			return &assigneedomain.MergeReview{ID: id, Status: assigneedomain.ReviewApproved, ReviewedBy: reviewer}, nil
+		},
+	}
+
+	t.Run("approves as caller", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/reviews/"+reviewID.String()+"/approve", nil)
+		rec := serveAssignee(svc, []string{"ip_manager"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "u-1", gotReviewer)
+	})
+
+	t.Run("read-only caller forbidden", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/reviews/"+reviewID.String()+"/approve", nil)
+		rec := serveAssignee(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/reviews/nope/approve", nil)
+		rec := serveAssignee(svc, []string{"ip_manager"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown review", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/reviews/"+uuid.NewString()+"/approve", nil)
+		rec := serveAssignee(svc, []string{"ip_manager"}, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestAssigneeHandler_RejectAssigneeReview_Conflict(t *testing.T) {
+	svc := &mockAssigneeService{
+		rejectFn: func(context.Context, uuid.UUID, string) (*assigneedomain.MergeReview, error) {
+			return nil, errors.New(errors.ErrCodeConflict, "review is already approved")
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/reviews/"+uuid.NewString()+"/reject", nil)
+	rec := serveAssignee(svc, []string{"ip_manager"}, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestAssigneeHandler_SetAssigneeParent(t *testing.T) {
+	child, parent := uuid.New(), uuid.New()
+	var gotParent *uuid.UUID
+	svc := &mockAssigneeService{
+		setParentFn: func(_ context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+			gotParent = parentID
+			if parentID != nil && *parentID == id {
+				return assigneedomain.ErrHierarchyCycle
+			}
+			return nil
+		},
+		hierarchyFn: func(_ context.Context, id uuid.UUID) (*assignee.HierarchyView, error) {
+			return &assignee.HierarchyView{Assignee: &assigneedomain.Assignee{ID: id}, Ancestors: []uuid.UUID{parent}, UltimateParent: parent}, nil
+		},
+	}
+
+	t.Run("links parent", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/assignees/"+child.String()+"/parent",
+			bytes.NewBufferString(`{"parent_id":"`+parent.String()+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveAssignee(svc, []string{"ip_manager"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, gotParent)
+		assert.Equal(t, parent, *gotParent)
+		var out assignee.HierarchyView
+		decodeCommentData(t, rec, &out)
+		assert.Equal(t, parent, out.UltimateParent)
+	})
+
+	t.Run("cycle rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/assignees/"+child.String()+"/parent",
+			bytes.NewBufferString(`{"parent_id":"`+child.String()+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveAssignee(svc, []string{"ip_manager"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAssigneeHandler_ResolveAssignee(t *testing.T) {
+	id := uuid.New()
+	svc := &mockAssigneeService{
+		resolveFn: func(_ context.Context, name string) (*assignee.Resolution, error) {
+			return &assignee.Resolution{RawName: name, NormalizedName: assigneedomain.Normalize(name), AssigneeID: id, Method: assignee.MatchAlias, Score: 1}, nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/assignees/resolve",
+		bytes.NewBufferString(`{"name":"SAMSUNG DISPLAY CO LTD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serveAssignee(svc, []string{"ip_manager"}, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var out assignee.Resolution
+	decodeCommentData(t, rec, &out)
+	assert.Equal(t, "samsung display", out.NormalizedName)
+	assert.Equal(t, id, out.AssigneeID)
+}
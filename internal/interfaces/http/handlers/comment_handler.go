@@ -0,0 +1,287 @@
+// internal/interfaces/http/handlers/comment_handler.go
+// 实现评论与批注 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：处理工作空间内评论线程相关的 HTTP 请求
+// * 核心实现：
+//   - CreateComment / ListThreads / GetThread / ReplyComment
+//   - EditComment / DeleteComment / ResolveThread / ReopenThread
+//   - RegisterRoutes
+// * 依赖：internal/application/collaboration/comment.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// CommentHandler handles HTTP requests for comments and annotations.
+type CommentHandler struct {
+	commentSvc collaboration.CommentService
+	logger     logging.Logger
+}
+
+// NewCommentHandler creates a new CommentHandler.
+func NewCommentHandler(
+	commentSvc collaboration.CommentService,
+	logger logging.Logger,
+) *CommentHandler {
+	return &CommentHandler{
+		commentSvc: commentSvc,
+		logger:     logger,
+	}
+}
+
+// CreateCommentBody is the request body for starting a comment thread.
+type CreateCommentBody struct {
+	Anchor  collabdomain.CommentAnchor `json:"anchor"`
+	Content string                     `json:"content"`
+}
+
+// CommentContentBody is the request body for replies and edits.
+type CommentContentBody struct {
+	Content string `json:"content"`
+}
+
+// RegisterRoutes registers all comment routes.
+func (h *CommentHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/workspaces/{id}/comments", h.CreateComment)
+	mux.HandleFunc("GET /api/v1/workspaces/{id}/comments", h.ListThreads)
+	mux.HandleFunc("POST /api/v1/comments/{commentId}/replies", h.ReplyComment)
+	mux.HandleFunc("PUT /api/v1/comments/{commentId}", h.EditComment)
+	mux.HandleFunc("DELETE /api/v1/comments/{commentId}", h.DeleteComment)
+	mux.HandleFunc("GET /api/v1/comment-threads/{threadId}", h.GetThread)
+	mux.HandleFunc("POST /api/v1/comment-threads/{threadId}/resolve", h.ResolveThread)
+	mux.HandleFunc("POST /api/v1/comment-threads/{threadId}/reopen", h.ReopenThread)
+}
+
+// CreateComment handles POST /api/v1/workspaces/{id}/comments
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("id")
+	if workspaceID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("id", "workspace id is required"))
+		return
+	}
+
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body CreateCommentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	comment, err := h.commentSvc.Create(r.Context(), &collaboration.CreateCommentRequest{
+		WorkspaceID: workspaceID,
+		AuthorID:    getUserIDFromContext(r),
+		Anchor:      body.Anchor,
+		Content:     body.Content,
+	})
+	if err != nil {
+		h.logger.Error("failed to create comment", logging.Err(err), logging.String("workspace_id", workspaceID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// ListThreads handles GET /api/v1/workspaces/{id}/comments
+//
+// Query parameters: anchor_type, resource_id (required), claim_number,
+// section_id, unresolved=true.
+func (h *CommentHandler) ListThreads(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.PathValue("id")
+	if workspaceID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("id", "workspace id is required"))
+		return
+	}
+
+	q := r.URL.Query()
+	req := &collaboration.ListCommentThreadsRequest{
+		WorkspaceID: workspaceID,
+		UserID:      getUserIDFromContext(r),
+		AnchorType:  collabdomain.AnchorType(q.Get("anchor_type")),
+		ResourceID:  q.Get("resource_id"),
+		SectionID:   q.Get("section_id"),
+	}
+	if req.AnchorType == "" {
+		req.AnchorType = collabdomain.AnchorPatent
+	}
+	if v := q.Get("claim_number"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("claim_number", "claim_number must be a positive integer"))
+			return
+		}
+		req.ClaimNumber = n
+	}
+	if v := q.Get("unresolved"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("unresolved", "unresolved must be a boolean"))
+			return
+		}
+		req.UnresolvedOnly = b
+	}
+
+	threads, err := h.commentSvc.ListThreads(r.Context(), req)
+	if err != nil {
+		h.logger.Error("failed to list comment threads", logging.Err(err), logging.String("workspace_id", workspaceID))
+		writeAppError(w, err)
+		return
+	}
+	if threads == nil {
+		threads = []*collabdomain.CommentThread{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"threads": threads})
+}
+
+// GetThread handles GET /api/v1/comment-threads/{threadId}
+func (h *CommentHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	threadID := r.PathValue("threadId")
+	if threadID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("threadId", "thread id is required"))
+		return
+	}
+
+	thread, err := h.commentSvc.GetThread(r.Context(), threadID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to get comment thread", logging.Err(err), logging.String("thread_id", threadID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, thread)
+}
+
+// ReplyComment handles POST /api/v1/comments/{commentId}/replies
+func (h *CommentHandler) ReplyComment(w http.ResponseWriter, r *http.Request) {
+	commentID := r.PathValue("commentId")
+	body, ok := h.decodeContent(w, r, commentID)
+	if !ok {
+		return
+	}
+
+	reply, err := h.commentSvc.Reply(r.Context(), &collaboration.ReplyCommentRequest{
+		ParentID: commentID,
+		AuthorID: getUserIDFromContext(r),
+		Content:  body.Content,
+	})
+	if err != nil {
+		h.logger.Error("failed to reply to comment", logging.Err(err), logging.String("comment_id", commentID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reply)
+}
+
+// EditComment handles PUT /api/v1/comments/{commentId}
+func (h *CommentHandler) EditComment(w http.ResponseWriter, r *http.Request) {
+	commentID := r.PathValue("commentId")
+	body, ok := h.decodeContent(w, r, commentID)
+	if !ok {
+		return
+	}
+
+	comment, err := h.commentSvc.Edit(r.Context(), &collaboration.EditCommentRequest{
+		CommentID: commentID,
+		EditorID:  getUserIDFromContext(r),
+		Content:   body.Content,
+	})
+	if err != nil {
+		h.logger.Error("failed to edit comment", logging.Err(err), logging.String("comment_id", commentID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /api/v1/comments/{commentId}
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	commentID := r.PathValue("commentId")
+	if commentID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("commentId", "comment id is required"))
+		return
+	}
+
+	if err := h.commentSvc.Delete(r.Context(), commentID, getUserIDFromContext(r)); err != nil {
+		h.logger.Error("failed to delete comment", logging.Err(err), logging.String("comment_id", commentID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// ResolveThread handles POST /api/v1/comment-threads/{threadId}/resolve
+func (h *CommentHandler) ResolveThread(w http.ResponseWriter, r *http.Request) {
+	h.setResolved(w, r, true)
+}
+
+// ReopenThread handles POST /api/v1/comment-threads/{threadId}/reopen
+func (h *CommentHandler) ReopenThread(w http.ResponseWriter, r *http.Request) {
+	h.setResolved(w, r, false)
+}
+
+func (h *CommentHandler) setResolved(w http.ResponseWriter, r *http.Request, resolved bool) {
+	threadID := r.PathValue("threadId")
+	if threadID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("threadId", "thread id is required"))
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	var (
+		root *collabdomain.Comment
+		err  error
+	)
+	if resolved {
+		root, err = h.commentSvc.Resolve(r.Context(), threadID, userID)
+	} else {
+		root, err = h.commentSvc.Reopen(r.Context(), threadID, userID)
+	}
+	if err != nil {
+		h.logger.Error("failed to update comment thread", logging.Err(err), logging.String("thread_id", threadID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, root)
+}
+
+// decodeContent validates the comment id path value and decodes a
+// CommentContentBody, writing the error response itself on failure.
+func (h *CommentHandler) decodeContent(w http.ResponseWriter, r *http.Request, commentID string) (*CommentContentBody, bool) {
+	if commentID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("commentId", "comment id is required"))
+		return nil, false
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return nil, false
+	}
+	var body CommentContentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return nil, false
+	}
+	return &body, true
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,297 @@
+// Tests for the comment HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockCommentService implements collaboration.CommentService for testing.
+type mockCommentService struct {
+	createFn      func(context.Context, *collaboration.CreateCommentRequest) (*collabdomain.Comment, error)
+	replyFn       func(context.Context, *collaboration.ReplyCommentRequest) (*collabdomain.Comment, error)
+	editFn        func(context.Context, *collaboration.EditCommentRequest) (*collabdomain.Comment, error)
+	resolveFn     func(context.Context, string, string) (*collabdomain.Comment, error)
+	reopenFn      func(context.Context, string, string) (*collabdomain.Comment, error)
+	deleteFn      func(context.Context, string, string) error
+	listThreadsFn func(context.Context, *collaboration.ListCommentThreadsRequest) ([]*collabdomain.CommentThread, error)
+	getThreadFn   func(context.Context, string, string) (*collabdomain.CommentThread, error)
+}
+
+func (m *mockCommentService) Create(ctx context.Context, req *collaboration.CreateCommentRequest) (*collabdomain.Comment, error) {
+	return m.createFn(ctx, req)
+}
+func (m *mockCommentService) Reply(ctx context.Context, req *collaboration.ReplyCommentRequest) (*collabdomain.Comment, error) {
+	return m.replyFn(ctx, req)
+}
+func (m *mockCommentService) Edit(ctx context.Context, req *collaboration.EditCommentRequest) (*collabdomain.Comment, error) {
+	return m.editFn(ctx, req)
+}
+func (m *mockCommentService) Resolve(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error) {
+	return m.resolveFn(ctx, threadID, userID)
+}
+func (m *mockCommentService) Reopen(ctx context.Context, threadID, userID string) (*collabdomain.Comment, error) {
+	return m.reopenFn(ctx, threadID, userID)
+}
+func (m *mockCommentService) Delete(ctx context.Context, commentID, userID string) error {
+	return m.deleteFn(ctx, commentID, userID)
+}
+func (m *mockCommentService) ListThreads(ctx context.Context, req *collaboration.ListCommentThreadsRequest) ([]*collabdomain.CommentThread, error) {
+	return m.listThreadsFn(ctx, req)
+}
+func (m *mockCommentService) GetThread(ctx context.Context, threadID, userID string) (*collabdomain.CommentThread, error) {
+	return m.getThreadFn(ctx, threadID, userID)
+}
+
+// decodeCommentData unwraps the success envelope written by writeJSON.
+func decodeCommentData(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	var env struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&env))
+	require.NoError(t, json.Unmarshal(env.Data, v))
+}
+
+func TestCommentHandler_CreateComment(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := &mockCommentService{
+			createFn: func(_ context.Context, req *collaboration.CreateCommentRequest) (*collabdomain.Comment, error) {
+				assert.Equal(t, "ws-1", req.WorkspaceID)
+				assert.Equal(t, collabdomain.AnchorClaim, req.Anchor.Type)
+				assert.Equal(t, 3, req.Anchor.ClaimNumber)
+				return &collabdomain.Comment{ID: "c-1", ThreadID: "c-1", Content: req.Content}, nil
+			},
+		}
+		h := NewCommentHandler(svc, testutil.NewNopLogger())
+		body, _ := json.Marshal(map[string]interface{}{
+			"anchor":  map[string]interface{}{"type": "claim", "resource_id": "p-1", "claim_number": 3},
+			"content": "@bob is this anticipated?",
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/ws-1/comments", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "ws-1")
+		rec := httptest.NewRecorder()
+
+		h.CreateComment(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var resp collabdomain.Comment
+		decodeCommentData(t, rec, &resp)
+		assert.Equal(t, "c-1", resp.ID)
+	})
+
+	t.Run("wrong content type", func(t *testing.T) {
+		h := NewCommentHandler(&mockCommentService{}, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/ws-1/comments", bytes.NewReader([]byte("{}")))
+		req.SetPathValue("id", "ws-1")
+		rec := httptest.NewRecorder()
+
+		h.CreateComment(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		svc := &mockCommentService{
+			createFn: func(context.Context, *collaboration.CreateCommentRequest) (*collabdomain.Comment, error) {
+				return nil, errors.New(errors.ErrCodeForbidden, "viewers cannot comment")
+			},
+		}
+		h := NewCommentHandler(svc, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/workspaces/ws-1/comments", bytes.NewReader([]byte(`{"content":"x"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "ws-1")
+		rec := httptest.NewRecorder()
+
+		h.CreateComment(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestCommentHandler_ListThreads(t *testing.T) {
+	t.Run("query parameters", func(t *testing.T) {
+		svc := &mockCommentService{
+			listThreadsFn: func(_ context.Context, req *collaboration.ListCommentThreadsRequest) ([]*collabdomain.CommentThread, error) {
+				assert.Equal(t, collabdomain.AnchorClaim, req.AnchorType)
+				assert.Equal(t, "p-1", req.ResourceID)
+				assert.Equal(t, 2, req.ClaimNumber)
+				assert.True(t, req.UnresolvedOnly)
+				return nil, nil
+			},
+		}
+		h := NewCommentHandler(svc, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/workspaces/ws-1/comments?anchor_type=claim&resource_id=p-1&claim_number=2&unresolved=true", nil)
+		req.SetPathValue("id", "ws-1")
+		rec := httptest.NewRecorder()
+
+		h.ListThreads(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp map[string][]*collabdomain.CommentThread
+		decodeCommentData(t, rec, &resp)
+		assert.NotNil(t, resp["threads"])
+	})
+
+	t.Run("bad claim number", func(t *testing.T) {
+		h := NewCommentHandler(&mockCommentService{}, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/workspaces/ws-1/comments?resource_id=p-1&claim_number=zero", nil)
+		req.SetPathValue("id", "ws-1")
+		rec := httptest.NewRecorder()
+
+		h.ListThreads(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestCommentHandler_ReplyAndEdit(t *testing.T) {
+	svc := &mockCommentService{
+		replyFn: func(_ context.Context, req *collaboration.ReplyCommentRequest) (*collabdomain.Comment, error) {
+			assert.Equal(t, "c-1", req.ParentID)
+			return &collabdomain.Comment{ID: "c-2", ParentID: "c-1", ThreadID: "c-1"}, nil
+		},
+		editFn: func(_ context.Context, req *collaboration.EditCommentRequest) (*collabdomain.Comment, error) {
+			return nil, errors.New(errors.ErrCodeForbidden, "only the author can edit")
+		},
+	}
+	h := NewCommentHandler(svc, testutil.NewNopLogger())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/comments/c-1/replies", bytes.NewReader([]byte(`{"content":"agreed"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("commentId", "c-1")
+	rec := httptest.NewRecorder()
+	h.ReplyComment(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/comments/c-1", bytes.NewReader([]byte(`{"content":"changed"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.SetPathValue("commentId", "c-1")
+	rec = httptest.NewRecorder()
+	h.EditComment(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/comments/", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	h.EditComment(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCommentHandler_ResolveReopen(t *testing.T) {
+	svc := &mockCommentService{
+		resolveFn: func(_ context.Context, threadID, _ string) (*collabdomain.Comment, error) {
+			return &collabdomain.Comment{ID: threadID, ThreadID: threadID, IsResolved: true}, nil
+		},
+		reopenFn: func(context.Context, string, string) (*collabdomain.Comment, error) {
+			return nil, errors.New(errors.ErrCodeNotFound, "comment not found")
+		},
+	}
+	h := NewCommentHandler(svc, testutil.NewNopLogger())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/comment-threads/t-1/resolve", nil)
+	req.SetPathValue("threadId", "t-1")
+	rec := httptest.NewRecorder()
+	h.ResolveThread(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp collabdomain.Comment
+	decodeCommentData(t, rec, &resp)
+	assert.True(t, resp.IsResolved)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/comment-threads/t-9/reopen", nil)
+	req.SetPathValue("threadId", "t-9")
+	rec = httptest.NewRecorder()
+	h.ReopenThread(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCommentHandler_DeleteComment(t *testing.T) {
+	svc := &mockCommentService{
+		deleteFn: func(_ context.Context, commentID, _ string) error {
+			assert.Equal(t, "c-1", commentID)
+			return nil
+		},
+	}
+	h := NewCommentHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/comments/c-1", nil)
+	req.SetPathValue("commentId", "c-1")
+	rec := httptest.NewRecorder()
+
+	h.DeleteComment(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCommentHandler_RegisterRoutes(t *testing.T) {
+	svc := &mockCommentService{
+		getThreadFn: func(_ context.Context, threadID, _ string) (*collabdomain.CommentThread, error) {
+			return &collabdomain.CommentThread{Root: &collabdomain.Comment{ID: threadID}}, nil
+		},
+	}
+	mux := http.NewServeMux()
+	NewCommentHandler(svc, testutil.NewNopLogger()).RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/comment-threads/t-1", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"t-1"`)
+}
+
+// workspaceMembers serves FindByWorkspaceID from a fixed member list.
+type workspaceMembers struct {
+	collabdomain.MemberRepository
+	byWorkspace map[string][]*collabdomain.MemberPermission
+}
+
+func (m workspaceMembers) FindByWorkspaceID(_ context.Context, workspaceID string) ([]*collabdomain.MemberPermission, error) {
+	return m.byWorkspace[workspaceID], nil
+}
+
+func TestWSHandler_PublishActivity(t *testing.T) {
+	members := workspaceMembers{byWorkspace: map[string][]*collabdomain.MemberPermission{
+		"ws-1": {
+			{WorkspaceID: "ws-1", UserID: "u-member", IsActive: true},
+			{WorkspaceID: "ws-1", UserID: "u-removed", IsActive: false},
+		},
+	}}
+	server, h := setupAuthedWSTestServer(t, members)
+	member := dialWebSocketAs(t, server, "u-member")
+	removed := dialWebSocketAs(t, server, "u-removed")
+	outsider := dialWebSocketAs(t, server, "u-outsider")
+	pollClientCount(t, h, 3)
+
+	assert.NoError(t, h.PublishActivity(context.Background(), nil))
+	require.NoError(t, h.PublishActivity(context.Background(), &collabdomain.ActivityRecord{
+		WorkspaceID: "ws-1",
+		ActionType:  collaboration.CommentActionCreated,
+		TargetID:    "c-1",
+	}))
+
+	_, data := readMessageWithTimeout(t, member, testReadTimeout)
+	assert.Contains(t, string(data), `"target_id":"c-1"`)
+	assertNoMessage(t, removed, 200*time.Millisecond)
+	assertNoMessage(t, outsider, 200*time.Millisecond)
+}
+
+func TestWSHandler_PublishActivityWithoutMembers(t *testing.T) {
+	h := NewWSHandler(nil, testutil.NewNopLogger())
+	assert.NoError(t, h.PublishActivity(context.Background(), &collabdomain.ActivityRecord{WorkspaceID: "ws-1"}))
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,273 @@
+// internal/interfaces/http/handlers/dlq_handler.go
+// 实现死信队列（DLQ）管理 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：供运维人员查看死信主题积压、检查失败消息与失败原因，按事件类型、时间窗口与错误类别限速重放，或清除已确认无需处理的死信
+// * 核心实现：
+//   - ListDLQTopics / InspectDLQMessages：需要 system:monitor 权限
+//   - ReplayDLQMessages / PurgeDLQMessages：需要 system:config 权限
+//   - RegisterRoutes
+// * 依赖：internal/infrastructure/messaging/kafka/dlq.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/auth/keycloak"
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// DLQAdmin is the dead letter queue tooling used by DLQHandler.
+// *kafkaclient.DLQManager implements it.
+type DLQAdmin interface {
+	List(ctx context.Context) ([]kafkaclient.DLQTopicStats, error)
+	Inspect(ctx context.Context, topic string, filter kafkaclient.DLQFilter, limit int) ([]*kafkaclient.DeadLetter, error)
+	Replay(ctx context.Context, topic string, opts kafkaclient.ReplayOptions) (*kafkaclient.ReplayResult, error)
+	Purge(ctx context.Context, topic string, opts kafkaclient.PurgeOptions) (*kafkaclient.PurgeResult, error)
+}
+
+// DLQHandler handles HTTP requests for dead letter queue administration.
+type DLQHandler struct {
+	admin  DLQAdmin
+	logger logging.Logger
+}
+
+// NewDLQHandler creates a new DLQHandler.
+func NewDLQHandler(admin DLQAdmin, logger logging.Logger) *DLQHandler {
+	return &DLQHandler{
+		admin:  admin,
+		logger: logger,
+	}
+}
+
+// ReplayDLQBody is the request body for replaying dead letters. Empty
+// filters match every pending message; Since and Until are RFC 3339.
+type ReplayDLQBody struct {
+	EventTypes    []string   `json:"event_types,omitempty"`
+	ErrorClasses  []string   `json:"error_classes,omitempty"`
+	Since         *time.Time `json:"since,omitempty"`
+	Until         *time.Time `json:"until,omitempty"`
+	RatePerSecond float64    `json:"rate_per_second,omitempty"`
+	Limit         int        `json:"limit,omitempty"`
+	DryRun        bool       `json:"dry_run,omitempty"`
+}
+
+// RegisterRoutes registers all DLQ admin routes.
+func (h *DLQHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/dlq", h.ListDLQTopics)
+	mux.HandleFunc("GET /api/v1/admin/dlq/{topic}/messages", h.InspectDLQMessages)
+	mux.HandleFunc("POST /api/v1/admin/dlq/{topic}/replay", h.ReplayDLQMessages)
+	mux.HandleFunc("DELETE /api/v1/admin/dlq/{topic}/messages", h.PurgeDLQMessages)
+}
+
+// ListDLQTopics handles GET /api/v1/admin/dlq
+func (h *DLQHandler) ListDLQTopics(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermSystemMonitor) {
+		return
+	}
+
+	topics, err := h.admin.List(r.Context())
+	if err != nil {
+		h.logger.Error("failed to list dead letter topics", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"topics": topics})
+}
+
+// InspectDLQMessages handles GET /api/v1/admin/dlq/{topic}/messages
+//
+// Query parameters: event_type and error_class (comma-separated), since and
+// until (RFC 3339), limit.
+func (h *DLQHandler) InspectDLQMessages(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermSystemMonitor) {
+		return
+	}
+	topic := r.PathValue("topic")
+
+	q := r.URL.Query()
+	filter := kafkaclient.DLQFilter{
+		EventTypes:   splitQueryList(q.Get("event_type")),
+		ErrorClasses: splitQueryList(q.Get("error_class")),
+	}
+	var err error
+	if filter.Since, err = parseQueryTime(q.Get("since"), "since"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.Until, err = parseQueryTime(q.Get("until"), "until"); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("limit", "limit must be a non-negative integer"))
+			return
+		}
+	}
+
+	letters, err := h.admin.Inspect(r.Context(), topic, filter, limit)
+	if err != nil {
+		h.logger.Error("failed to inspect dead letters", logging.Err(err), logging.String("topic", topic))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"topic": topic, "messages": letters})
+}
+
+// ReplayDLQMessages handles POST /api/v1/admin/dlq/{topic}/replay
+//
+// The body is optional; without it every pending message is replayed at
+// the default rate. A failure part-way returns 500 after committing the
+// progress made.
+func (h *DLQHandler) ReplayDLQMessages(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermSystemConfig) {
+		return
+	}
+	topic := r.PathValue("topic")
+
+	var body ReplayDLQBody
+	if r.ContentLength != 0 {
+		if !isContentTypeJSON(r) {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+			return
+		}
+	}
+
+	opts := kafkaclient.ReplayOptions{
+		Filter: kafkaclient.DLQFilter{
+			EventTypes:   body.EventTypes,
+			ErrorClasses: body.ErrorClasses,
+		},
+		RatePerSecond: body.RatePerSecond,
+		Limit:         body.Limit,
+		DryRun:        body.DryRun,
+	}
+	if body.Since != nil {
+		opts.Filter.Since = *body.Since
+	}
+	if body.Until != nil {
+		opts.Filter.Until = *body.Until
+	}
+
+	result, err := h.admin.Replay(r.Context(), topic, opts)
+	if err != nil {
+		fields := []logging.Field{logging.Err(err), logging.String("topic", topic)}
+		if result != nil {
+			fields = append(fields, logging.Int("replayed", result.Replayed))
+		}
+		h.logger.Error("failed to replay dead letters", fields...)
+		writeAppError(w, err)
+		return
+	}
+
+	h.logger.Info("dead letters replayed via admin API",
+		logging.String("topic", topic),
+		logging.String("user_id", getUserIDFromContext(r)),
+		logging.Int("replayed", result.Replayed),
+		logging.Bool("dry_run", result.DryRun))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// PurgeDLQMessages handles DELETE /api/v1/admin/dlq/{topic}/messages
+//
+// With ?before=<RFC 3339> only messages that failed earlier are purged.
+func (h *DLQHandler) PurgeDLQMessages(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermSystemConfig) {
+		return
+	}
+	topic := r.PathValue("topic")
+
+	before, err := parseQueryTime(r.URL.Query().Get("before"), "before")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := h.admin.Purge(r.Context(), topic, kafkaclient.PurgeOptions{Before: before})
+	if err != nil {
+		h.logger.Error("failed to purge dead letters", logging.Err(err), logging.String("topic", topic))
+		writeAppError(w, err)
+		return
+	}
+
+	h.logger.Warn("dead letters purged via admin API",
+		logging.String("topic", topic),
+		logging.String("user_id", getUserIDFromContext(r)),
+		logging.Int64("purged", result.Purged))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// requirePermission writes 403 and returns false unless the caller holds
+// perm, through a JWT role or an API key scope. Unauthenticated requests
+// are refused.
+func requirePermission(w http.ResponseWriter, r *http.Request, perm keycloak.Permission) bool {
+	if callerHasPermission(r, perm) {
+		return true
+	}
+	writeError(w, http.StatusForbidden, errors.New(errors.ErrCodeForbidden, "missing permission: "+string(perm)))
+	return false
+}
+
+func callerHasPermission(r *http.Request, perm keycloak.Permission) bool {
+	if claims := middleware.ContextGetClaims(r.Context()); claims != nil {
+		mapping := keycloak.DefaultRolePermissionMapping()
+		for _, role := range claims.Roles {
+			for _, p := range mapping[keycloak.Role(role)] {
+				if p == perm {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if info := middleware.ContextGetAPIKeyInfo(r.Context()); info != nil {
+		for _, scope := range info.Scopes {
+			if scope == string(perm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitQueryList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func parseQueryTime(v, field string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.NewValidationError(field, field+" must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,202 @@
+// Tests for the dead letter queue admin HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockDLQAdmin implements DLQAdmin for testing.
+type mockDLQAdmin struct {
+	listFn    func(context.Context) ([]kafkaclient.DLQTopicStats, error)
+	inspectFn func(context.Context, string, kafkaclient.DLQFilter, int) ([]*kafkaclient.DeadLetter, error)
+	replayFn  func(context.Context, string, kafkaclient.ReplayOptions) (*kafkaclient.ReplayResult, error)
+	purgeFn   func(context.Context, string, kafkaclient.PurgeOptions) (*kafkaclient.PurgeResult, error)
+}
+
+func (m *mockDLQAdmin) List(ctx context.Context) ([]kafkaclient.DLQTopicStats, error) {
+	return m.listFn(ctx)
+}
+func (m *mockDLQAdmin) Inspect(ctx context.Context, topic string, filter kafkaclient.DLQFilter, limit int) ([]*kafkaclient.DeadLetter, error) {
+	return m.inspectFn(ctx, topic, filter, limit)
+}
+func (m *mockDLQAdmin) Replay(ctx context.Context, topic string, opts kafkaclient.ReplayOptions) (*kafkaclient.ReplayResult, error) {
+	return m.replayFn(ctx, topic, opts)
+}
+func (m *mockDLQAdmin) Purge(ctx context.Context, topic string, opts kafkaclient.PurgeOptions) (*kafkaclient.PurgeResult, error) {
+	return m.purgeFn(ctx, topic, opts)
+}
+
+// serveDLQ routes req through the handler's mux as a caller with roles.
+func serveDLQ(admin DLQAdmin, roles []string, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewDLQHandler(admin, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	withClaims(mux.ServeHTTP, &middleware.Claims{
+		UserID:    "u-1",
+		Roles:     roles,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, rec, req)
+	return rec
+}
+
+func TestDLQHandler_ListDLQTopics(t *testing.T) {
+	admin := &mockDLQAdmin{
+		listFn: func(context.Context) ([]kafkaclient.DLQTopicStats, error) {
+			return []kafkaclient.DLQTopicStats{{Topic: "patent.new.dlq", Total: 3, Pending: 2}}, nil
+		},
+	}
+
+	t.Run("monitor permission", func(t *testing.T) {
+		rec := serveDLQ(admin, []string{"tenant_admin"}, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var out struct {
+			Topics []kafkaclient.DLQTopicStats `json:"topics"`
+		}
+		decodeCommentData(t, rec, &out)
+		require.Len(t, out.Topics, 1)
+		assert.Equal(t, int64(2), out.Topics[0].Pending)
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		rec := serveDLQ(admin, []string{"researcher"}, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq", nil))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestDLQHandler_InspectDLQMessages(t *testing.T) {
+	t.Run("parses filter", func(t *testing.T) {
+		admin := &mockDLQAdmin{
+			inspectFn: func(_ context.Context, topic string, filter kafkaclient.DLQFilter, limit int) ([]*kafkaclient.DeadLetter, error) {
+				assert.Equal(t, "patent.new.dlq", topic)
+				assert.Equal(t, []string{"patent.created", "patent.updated"}, filter.EventTypes)
+				assert.Equal(t, []string{"timeout"}, filter.ErrorClasses)
+				assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), filter.Since.UTC())
+				assert.True(t, filter.Until.IsZero())
+				assert.Equal(t, 5, limit)
+				return []*kafkaclient.DeadLetter{{Topic: topic, Offset: 7, ErrorClass: "timeout"}}, nil
+			},
+		}
+		req := httptest.NewRequest(http.MethodGet,
+			"/api/v1/admin/dlq/patent.new.dlq/messages?event_type=patent.created,patent.updated&error_class=timeout&since=2026-03-01T00:00:00Z&limit=5", nil)
+		rec := serveDLQ(admin, []string{"ip_manager"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var out struct {
+			Messages []kafkaclient.DeadLetter `json:"messages"`
+		}
+		decodeCommentData(t, rec, &out)
+		require.Len(t, out.Messages, 1)
+		assert.Equal(t, int64(7), out.Messages[0].Offset)
+	})
+
+	t.Run("bad since", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq/patent.new.dlq/messages?since=yesterday", nil)
+		rec := serveDLQ(&mockDLQAdmin{}, []string{"super_admin"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not a dead letter topic", func(t *testing.T) {
+		admin := &mockDLQAdmin{
+			inspectFn: func(context.Context, string, kafkaclient.DLQFilter, int) ([]*kafkaclient.DeadLetter, error) {
+				return nil, errors.New(errors.ErrCodeValidation, "not a dead letter topic: patent.new")
+			},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq/patent.new/messages", nil)
+		rec := serveDLQ(admin, []string{"super_admin"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestDLQHandler_ReplayDLQMessages(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		admin := &mockDLQAdmin{
+			replayFn: func(_ context.Context, topic string, opts kafkaclient.ReplayOptions) (*kafkaclient.ReplayResult, error) {
+				assert.Equal(t, "patent.new.dlq", topic)
+				assert.Equal(t, []string{"dependency"}, opts.Filter.ErrorClasses)
+				assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), opts.Filter.Until.UTC())
+				assert.Equal(t, 10.0, opts.RatePerSecond)
+				assert.True(t, opts.DryRun)
+				return &kafkaclient.ReplayResult{Topic: topic, Matched: 4, Replayed: 4, DryRun: true}, nil
+			},
+		}
+		body := `{"error_classes":["dependency"],"until":"2026-03-02T00:00:00Z","rate_per_second":10,"dry_run":true}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/dlq/patent.new.dlq/replay", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveDLQ(admin, []string{"super_admin"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res kafkaclient.ReplayResult
+		decodeCommentData(t, rec, &res)
+		assert.Equal(t, 4, res.Replayed)
+	})
+
+	t.Run("requires config permission", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/dlq/patent.new.dlq/replay", nil)
+		rec := serveDLQ(&mockDLQAdmin{}, []string{"tenant_admin"}, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestDLQHandler_PurgeDLQMessages(t *testing.T) {
+	admin := &mockDLQAdmin{
+		purgeFn: func(_ context.Context, topic string, opts kafkaclient.PurgeOptions) (*kafkaclient.PurgeResult, error) {
+			assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), opts.Before.UTC())
+			return &kafkaclient.PurgeResult{Topic: topic, Purged: 12}, nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dlq/patent.new.dlq/messages?before=2026-01-01T00:00:00Z", nil)
+	rec := serveDLQ(admin, []string{"super_admin"}, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var res kafkaclient.PurgeResult
+	decodeCommentData(t, rec, &res)
+	assert.Equal(t, int64(12), res.Purged)
+}
+
+type staticAPIKeyValidator struct{ info *middleware.APIKeyInfo }
+
+func (v staticAPIKeyValidator) ValidateAPIKey(string) (*middleware.APIKeyInfo, error) {
+	return v.info, nil
+}
+
+func TestDLQHandler_APIKeyScopes(t *testing.T) {
+	admin := &mockDLQAdmin{
+		listFn: func(context.Context) ([]kafkaclient.DLQTopicStats, error) { return nil, nil },
+	}
+	mux := http.NewServeMux()
+	NewDLQHandler(admin, testutil.NewNopLogger()).RegisterRoutes(mux)
+	auth := middleware.NewAuthMiddleware(staticTokenValidator{}, staticAPIKeyValidator{
+		&middleware.APIKeyInfo{KeyID: "k-1", Scopes: []string{"system:monitor"}},
+	}, middleware.AuthConfig{}, testutil.NewNopLogger())
+	h := auth.Authenticate()(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq", nil)
+	req.Header.Set("X-API-Key", "kip_test")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/dlq/patent.new.dlq/messages", nil)
+	req.Header.Set("X-API-Key", "kip_test")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestDLQHandler_Unauthenticated(t *testing.T) {
+	mux := http.NewServeMux()
+	NewDLQHandler(&mockDLQAdmin{}, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/dlq", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -310,6 +310,8 @@ func writeAppError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusUnauthorized, err)
 	case errors.IsForbidden(err):
 		writeError(w, http.StatusForbidden, err)
+	case errors.IsCode(err, errors.ErrCodeTooManyRequests):
+		writeError(w, http.StatusTooManyRequests, err)
 	default:
 		// Mask internal errors
 		msg := err.Error()
@@ -0,0 +1,200 @@
+// internal/interfaces/http/handlers/inventor_handler.go
+// 实现发明人消歧与发明人网络分析 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：供竞争情报团队查询发明人档案、关键发明人在竞争对手之间的流动与合作网络，并由数据运营人员触发消歧
+// * 核心实现：
+//   - GetInventor / GetInventorMobility / GetInventorNetwork / GetAssigneeInventorNetwork：需要 patent:read 权限
+//   - RunInventorDisambiguation：需要 patent:write 权限
+//   - RegisterRoutes
+// * 依赖：internal/application/inventor/service.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/auth/keycloak"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// InventorHandler handles HTTP requests for inventor analytics.
+type InventorHandler struct {
+	inventorSvc inventor.Service
+	logger      logging.Logger
+}
+
+// NewInventorHandler creates a new InventorHandler.
+func NewInventorHandler(inventorSvc inventor.Service, logger logging.Logger) *InventorHandler {
+	return &InventorHandler{
+		inventorSvc: inventorSvc,
+		logger:      logger,
+	}
+}
+
+// RunInventorDisambiguationBody is the optional request body for a
+// disambiguation run.
+type RunInventorDisambiguationBody struct {
+	MaxMentions int `json:"max_mentions"`
+}
+
+// RegisterRoutes registers all inventor routes.
+func (h *InventorHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/inventors/mobility", h.GetInventorMobility)
+	mux.HandleFunc("GET /api/v1/inventors/network", h.GetAssigneeInventorNetwork)
+	mux.HandleFunc("GET /api/v1/inventors/{id}", h.GetInventor)
+	mux.HandleFunc("GET /api/v1/inventors/{id}/network", h.GetInventorNetwork)
+	mux.HandleFunc("POST /api/v1/admin/inventors/disambiguate", h.RunInventorDisambiguation)
+}
+
+// GetInventor handles GET /api/v1/inventors/{id}
+func (h *InventorHandler) GetInventor(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	inv, err := h.inventorSvc.Get(r.Context(), id)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+// GetInventorMobility handles GET /api/v1/inventors/mobility
+//
+// Query parameters: competitors (comma-separated, required), since (RFC
+// 3339), min_stint (patents an inventor must file at an assignee for the
+// stint to count).
+func (h *InventorHandler) GetInventorMobility(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+
+	q := r.URL.Query()
+	since, err := parseQueryTime(q.Get("since"), "since")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	minStint, ok := parseNonNegativeQueryInt(w, q.Get("min_stint"), "min_stint")
+	if !ok {
+		return
+	}
+
+	moves, err := h.inventorSvc.Mobility(r.Context(), inventor.MobilityQuery{
+		Competitors:     splitQueryList(q.Get("competitors")),
+		Since:           since,
+		MinStintPatents: minStint,
+	})
+	if err != nil {
+		h.logger.Error("failed to compute inventor mobility", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"moves": moves, "total": len(moves)})
+}
+
+// GetInventorNetwork handles GET /api/v1/inventors/{id}/network
+//
+// Returns the inventor's co-inventor network. Query parameters: min_shared,
+// limit.
+func (h *InventorHandler) GetInventorNetwork(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+	id, ok := parsePathUUID(w, r, "id")
+	if !ok {
+		return
+	}
+	h.writeNetwork(w, r, inventor.NetworkQuery{InventorID: &id})
+}
+
+// GetAssigneeInventorNetwork handles GET /api/v1/inventors/network
+//
+// Returns the collaboration network of an assignee's inventors. Query
+// parameters: assignee (required), min_shared, limit.
+func (h *InventorHandler) GetAssigneeInventorNetwork(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentRead) {
+		return
+	}
+	h.writeNetwork(w, r, inventor.NetworkQuery{Assignee: r.URL.Query().Get("assignee")})
+}
+
+func (h *InventorHandler) writeNetwork(w http.ResponseWriter, r *http.Request, query inventor.NetworkQuery) {
+	q := r.URL.Query()
+	var ok bool
+	if query.MinShared, ok = parseNonNegativeQueryInt(w, q.Get("min_shared"), "min_shared"); !ok {
+		return
+	}
+	if query.Limit, ok = parseNonNegativeQueryInt(w, q.Get("limit"), "limit"); !ok {
+		return
+	}
+
+	network, err := h.inventorSvc.Network(r.Context(), query)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, network)
+}
+
+// RunInventorDisambiguation handles POST /api/v1/admin/inventors/disambiguate
+//
+// Re-clusters every inventor mention and republishes inventors to the
+// knowledge graph. The body is optional.
+func (h *InventorHandler) RunInventorDisambiguation(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermPatentWrite) {
+		return
+	}
+	var body RunInventorDisambiguationBody
+	if r.ContentLength != 0 {
+		if !isContentTypeJSON(r) {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+			return
+		}
+	}
+	if body.MaxMentions < 0 {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("max_mentions", "max_mentions must be non-negative"))
+		return
+	}
+
+	result, err := h.inventorSvc.Disambiguate(r.Context(), inventor.RunOptions{MaxMentions: body.MaxMentions})
+	if err != nil {
+		h.logger.Error("inventor disambiguation failed", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// parseNonNegativeQueryInt parses an optional non-negative integer query
+// value, writing 400 and returning false when it is invalid.
+func parseNonNegativeQueryInt(w http.ResponseWriter, v, name string) (int, bool) {
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError(name, name+" must be a non-negative integer"))
+		return 0, false
+	}
+	return n, true
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,199 @@
+// Tests for the inventor analytics HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/inventor"
+	inventordomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/inventor"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockInventorService implements inventor.Service for testing.
+type mockInventorService struct {
+	disambiguateFn func(context.Context, inventor.RunOptions) (*inventor.RunResult, error)
+	getFn          func(context.Context, uuid.UUID) (*inventordomain.Inventor, error)
+	mobilityFn     func(context.Context, inventor.MobilityQuery) ([]*inventordomain.Move, error)
+	networkFn      func(context.Context, inventor.NetworkQuery) (*inventordomain.Network, error)
+}
+
+func (m *mockInventorService) Disambiguate(ctx context.Context, opts inventor.RunOptions) (*inventor.RunResult, error) {
+	return m.disambiguateFn(ctx, opts)
+}
+func (m *mockInventorService) Get(ctx context.Context, id uuid.UUID) (*inventordomain.Inventor, error) {
+	return m.getFn(ctx, id)
+}
+func (m *mockInventorService) Mobility(ctx context.Context, q inventor.MobilityQuery) ([]*inventordomain.Move, error) {
+	return m.mobilityFn(ctx, q)
+}
+func (m *mockInventorService) Network(ctx context.Context, q inventor.NetworkQuery) (*inventordomain.Network, error) {
+	return m.networkFn(ctx, q)
+}
+
+// serveInventor routes req through the handler's mux as a caller with roles.
+func serveInventor(svc inventor.Service, roles []string, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewInventorHandler(svc, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	withClaims(mux.ServeHTTP, &middleware.Claims{
+		UserID:    "u-1",
+		Roles:     roles,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, rec, req)
+	return rec
+}
+
+func TestInventorHandler_GetInventor(t *testing.T) {
+	id := uuid.New()
+	svc := &mockInventorService{
+		getFn: func(_ context.Context, got uuid.UUID) (*inventordomain.Inventor, error) {
+			if got != id {
+				return nil, errors.New(errors.ErrCodeNotFound, "inventor not found")
+			}
+			return &inventordomain.Inventor{ID: id, CanonicalName: "Peter Wolohan", PatentCount: 4}, nil
+		},
+	}
+
+	t.Run("found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/"+id.String(), nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var out inventordomain.Inventor
+		decodeCommentData(t, rec, &out)
+		assert.Equal(t, "Peter Wolohan", out.CanonicalName)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/"+uuid.NewString(), nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/nope", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestInventorHandler_GetInventorMobility(t *testing.T) {
+	var got inventor.MobilityQuery
+	svc := &mockInventorService{
+		mobilityFn: func(_ context.Context, q inventor.MobilityQuery) ([]*inventordomain.Move, error) {
+			got = q
+			if len(q.Competitors) == 0 {
+				return nil, errors.NewValidationError("competitors", "at least one competitor is required")
+			}
+			return []*inventordomain.Move{{InventorName: "Peter Wolohan", From: "Universal Display Corporation", To: "Merck Patent GmbH"}}, nil
+		},
+	}
+
+	t.Run("parses query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/mobility?competitors=Merck,+LG+Display&since=2018-01-01T00:00:00Z&min_stint=2", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"Merck", "LG Display"}, got.Competitors)
+		assert.Equal(t, 2018, got.Since.Year())
+		assert.Equal(t, 2, got.MinStintPatents)
+		var out struct {
+			Moves []*inventordomain.Move `json:"moves"`
+			Total int                    `json:"total"`
+		}
+		decodeCommentData(t, rec, &out)
+		assert.Equal(t, 1, out.Total)
+		assert.Equal(t, "Merck Patent GmbH", out.Moves[0].To)
+	})
+
+	t.Run("missing competitors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/mobility", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("bad since", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/mobility?competitors=Merck&since=2018", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestInventorHandler_Networks(t *testing.T) {
+	var got inventor.NetworkQuery
+	svc := &mockInventorService{
+		networkFn: func(_ context.Context, q inventor.NetworkQuery) (*inventordomain.Network, error) {
+			got = q
+			return &inventordomain.Network{}, nil
+		},
+	}
+
+	t.Run("ego network", func(t *testing.T) {
+		id := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/"+id.String()+"/network?min_shared=2&limit=50", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, got.InventorID)
+		assert.Equal(t, id, *got.InventorID)
+		assert.Equal(t, 2, got.MinShared)
+		assert.Equal(t, 50, got.Limit)
+	})
+
+	t.Run("assignee network", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/network?assignee=Merck+Patent+GmbH", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, got.InventorID)
+		assert.Equal(t, "Merck Patent GmbH", got.Assignee)
+	})
+
+	t.Run("bad limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/inventors/network?assignee=Merck&limit=-1", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestInventorHandler_RunInventorDisambiguation(t *testing.T) {
+	var got inventor.RunOptions
+	svc := &mockInventorService{
+		disambiguateFn: func(_ context.Context, opts inventor.RunOptions) (*inventor.RunResult, error) {
+			got = opts
+			return &inventor.RunResult{Generation: "g1", Inventors: 3, Complete: opts.MaxMentions == 0}, nil
+		},
+	}
+
+	t.Run("no body", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/inventors/disambiguate", nil)
+		rec := serveInventor(svc, []string{"ip_manager"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var out inventor.RunResult
+		decodeCommentData(t, rec, &out)
+		assert.True(t, out.Complete)
+		assert.Equal(t, 3, out.Inventors)
+	})
+
+	t.Run("bounded run", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/inventors/disambiguate",
+			bytes.NewBufferString(`{"max_mentions":5000}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveInventor(svc, []string{"ip_manager"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 5000, got.MaxMentions)
+	})
+
+	t.Run("read-only caller forbidden", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/inventors/disambiguate", nil)
+		rec := serveInventor(svc, []string{"researcher"}, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,263 @@
+// internal/interfaces/http/handlers/saved_search_handler.go
+// 实现保存检索 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：处理保存检索的创建、查询、修改、删除、手动执行与工作空间共享
+// * 核心实现：
+//   - CreateSavedSearch / ListSavedSearches / GetSavedSearch
+//   - UpdateSavedSearch / DeleteSavedSearch / RunSavedSearch
+//   - ShareSavedSearch / UnshareSavedSearch
+//   - RegisterRoutes
+// * 依赖：internal/application/collaboration/saved_search.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// SavedSearchHandler handles HTTP requests for saved searches.
+type SavedSearchHandler struct {
+	savedSearchSvc collaboration.SavedSearchService
+	logger         logging.Logger
+}
+
+// NewSavedSearchHandler creates a new SavedSearchHandler.
+func NewSavedSearchHandler(
+	savedSearchSvc collaboration.SavedSearchService,
+	logger logging.Logger,
+) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchSvc: savedSearchSvc,
+		logger:         logger,
+	}
+}
+
+// SavedSearchBody is the request body for creating or updating a saved search.
+// Kind is ignored on update.
+type SavedSearchBody struct {
+	Name        string                       `json:"name"`
+	Description string                       `json:"description,omitempty"`
+	Kind        collabdomain.SavedSearchKind `json:"kind"`
+	Query       json.RawMessage              `json:"query"`
+	Schedule    collabdomain.SearchSchedule  `json:"schedule,omitempty"`
+	WorkspaceID string                       `json:"workspace_id,omitempty"`
+}
+
+// ShareSavedSearchBody is the request body for sharing a saved search.
+type ShareSavedSearchBody struct {
+	WorkspaceID string `json:"workspace_id"`
+}
+
+// RegisterRoutes registers all saved search routes.
+func (h *SavedSearchHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/saved-searches", h.CreateSavedSearch)
+	mux.HandleFunc("GET /api/v1/saved-searches", h.ListSavedSearches)
+	mux.HandleFunc("GET /api/v1/saved-searches/{id}", h.GetSavedSearch)
+	mux.HandleFunc("PUT /api/v1/saved-searches/{id}", h.UpdateSavedSearch)
+	mux.HandleFunc("DELETE /api/v1/saved-searches/{id}", h.DeleteSavedSearch)
+	mux.HandleFunc("POST /api/v1/saved-searches/{id}/run", h.RunSavedSearch)
+	mux.HandleFunc("POST /api/v1/saved-searches/{id}/share", h.ShareSavedSearch)
+	mux.HandleFunc("DELETE /api/v1/saved-searches/{id}/share", h.UnshareSavedSearch)
+}
+
+// CreateSavedSearch handles POST /api/v1/saved-searches
+func (h *SavedSearchHandler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body SavedSearchBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	search, err := h.savedSearchSvc.Create(r.Context(), &collaboration.CreateSavedSearchRequest{
+		OwnerID:     getUserIDFromContext(r),
+		WorkspaceID: body.WorkspaceID,
+		Name:        body.Name,
+		Description: body.Description,
+		Kind:        body.Kind,
+		Query:       body.Query,
+		Schedule:    body.Schedule,
+	})
+	if err != nil {
+		h.logger.Error("failed to create saved search", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, search)
+}
+
+// ListSavedSearches handles GET /api/v1/saved-searches
+//
+// Without workspace_id the caller's own searches are listed; with it, the
+// searches shared with that workspace.
+func (h *SavedSearchHandler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	workspaceID := r.URL.Query().Get("workspace_id")
+	searches, err := h.savedSearchSvc.List(r.Context(), getUserIDFromContext(r), workspaceID)
+	if err != nil {
+		h.logger.Error("failed to list saved searches", logging.Err(err), logging.String("workspace_id", workspaceID))
+		writeAppError(w, err)
+		return
+	}
+	if searches == nil {
+		searches = []*collabdomain.SavedSearch{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"saved_searches": searches})
+}
+
+// GetSavedSearch handles GET /api/v1/saved-searches/{id}
+func (h *SavedSearchHandler) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+
+	search, err := h.savedSearchSvc.Get(r.Context(), searchID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to get saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+// UpdateSavedSearch handles PUT /api/v1/saved-searches/{id}
+func (h *SavedSearchHandler) UpdateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body SavedSearchBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	search, err := h.savedSearchSvc.Update(r.Context(), &collaboration.UpdateSavedSearchRequest{
+		SearchID:    searchID,
+		UserID:      getUserIDFromContext(r),
+		Name:        body.Name,
+		Description: body.Description,
+		Query:       body.Query,
+		Schedule:    body.Schedule,
+	})
+	if err != nil {
+		h.logger.Error("failed to update saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+// DeleteSavedSearch handles DELETE /api/v1/saved-searches/{id}
+func (h *SavedSearchHandler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchSvc.Delete(r.Context(), searchID, getUserIDFromContext(r)); err != nil {
+		h.logger.Error("failed to delete saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// RunSavedSearch handles POST /api/v1/saved-searches/{id}/run
+func (h *SavedSearchHandler) RunSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.savedSearchSvc.Run(r.Context(), searchID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to run saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// ShareSavedSearch handles POST /api/v1/saved-searches/{id}/share
+func (h *SavedSearchHandler) ShareSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body ShareSavedSearchBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+	if body.WorkspaceID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("workspace_id", "workspace_id is required"))
+		return
+	}
+
+	search, err := h.savedSearchSvc.Share(r.Context(), searchID, body.WorkspaceID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to share saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+// UnshareSavedSearch handles DELETE /api/v1/saved-searches/{id}/share
+func (h *SavedSearchHandler) UnshareSavedSearch(w http.ResponseWriter, r *http.Request) {
+	searchID, ok := savedSearchID(w, r)
+	if !ok {
+		return
+	}
+
+	search, err := h.savedSearchSvc.Unshare(r.Context(), searchID, getUserIDFromContext(r))
+	if err != nil {
+		h.logger.Error("failed to unshare saved search", logging.Err(err), logging.String("saved_search_id", searchID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, search)
+}
+
+func savedSearchID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if id == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("id", "saved search id is required"))
+		return "", false
+	}
+	return id, true
+}
+
+//Personal.AI order the ending
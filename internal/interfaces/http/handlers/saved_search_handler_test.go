@@ -0,0 +1,237 @@
+// Tests for the saved search HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
+)
+
+// mockSavedSearchService implements collaboration.SavedSearchService for testing.
+type mockSavedSearchService struct {
+	createFn  func(context.Context, *collaboration.CreateSavedSearchRequest) (*collabdomain.SavedSearch, error)
+	getFn     func(context.Context, string, string) (*collabdomain.SavedSearch, error)
+	listFn    func(context.Context, string, string) ([]*collabdomain.SavedSearch, error)
+	updateFn  func(context.Context, *collaboration.UpdateSavedSearchRequest) (*collabdomain.SavedSearch, error)
+	deleteFn  func(context.Context, string, string) error
+	shareFn   func(context.Context, string, string, string) (*collabdomain.SavedSearch, error)
+	unshareFn func(context.Context, string, string) (*collabdomain.SavedSearch, error)
+	runFn     func(context.Context, string, string) (*collaboration.SavedSearchRunResult, error)
+}
+
+func (m *mockSavedSearchService) Create(ctx context.Context, req *collaboration.CreateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+	return m.createFn(ctx, req)
+}
+func (m *mockSavedSearchService) Get(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error) {
+	return m.getFn(ctx, searchID, userID)
+}
+func (m *mockSavedSearchService) List(ctx context.Context, userID, workspaceID string) ([]*collabdomain.SavedSearch, error) {
+	return m.listFn(ctx, userID, workspaceID)
+}
+func (m *mockSavedSearchService) Update(ctx context.Context, req *collaboration.UpdateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+	return m.updateFn(ctx, req)
+}
+func (m *mockSavedSearchService) Delete(ctx context.Context, searchID, userID string) error {
+	return m.deleteFn(ctx, searchID, userID)
+}
+func (m *mockSavedSearchService) Share(ctx context.Context, searchID, workspaceID, userID string) (*collabdomain.SavedSearch, error) {
+	return m.shareFn(ctx, searchID, workspaceID, userID)
+}
+func (m *mockSavedSearchService) Unshare(ctx context.Context, searchID, userID string) (*collabdomain.SavedSearch, error) {
+	return m.unshareFn(ctx, searchID, userID)
+}
+func (m *mockSavedSearchService) Run(ctx context.Context, searchID, userID string) (*collaboration.SavedSearchRunResult, error) {
+	return m.runFn(ctx, searchID, userID)
+}
+func (m *mockSavedSearchService) RunDue(context.Context, time.Time, int) (int, error) {
+	return 0, nil
+}
+
+func TestSavedSearchHandler_Create(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		svc := &mockSavedSearchService{
+			createFn: func(_ context.Context, req *collaboration.CreateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+				assert.Equal(t, collabdomain.SavedSearchPatent, req.Kind)
+				assert.Equal(t, collabdomain.ScheduleDaily, req.Schedule)
+				assert.JSONEq(t, `{"keywords":["carbazole"]}`, string(req.Query))
+				return &collabdomain.SavedSearch{ID: "s-1", Name: req.Name, Kind: req.Kind}, nil
+			},
+		}
+		h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+		body := []byte(`{"name":"hosts","kind":"patent","query":{"keywords":["carbazole"]},"schedule":"daily"}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/saved-searches", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		h.CreateSavedSearch(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var resp collabdomain.SavedSearch
+		decodeCommentData(t, rec, &resp)
+		assert.Equal(t, "s-1", resp.ID)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		svc := &mockSavedSearchService{
+			createFn: func(context.Context, *collaboration.CreateSavedSearchRequest) (*collabdomain.SavedSearch, error) {
+				return nil, errors.New(errors.ErrCodeValidation, "invalid kind: portfolio")
+			},
+		}
+		h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/saved-searches", bytes.NewReader([]byte(`{"name":"x","kind":"portfolio"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+
+		h.CreateSavedSearch(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestSavedSearchHandler_ListByWorkspace(t *testing.T) {
+	svc := &mockSavedSearchService{
+		listFn: func(_ context.Context, _, workspaceID string) ([]*collabdomain.SavedSearch, error) {
+			assert.Equal(t, "ws-1", workspaceID)
+			return nil, nil
+		},
+	}
+	h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/saved-searches?workspace_id=ws-1", nil)
+	rec := httptest.NewRecorder()
+
+	h.ListSavedSearches(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string][]*collabdomain.SavedSearch
+	decodeCommentData(t, rec, &resp)
+	assert.NotNil(t, resp["saved_searches"])
+}
+
+func TestSavedSearchHandler_Run(t *testing.T) {
+	svc := &mockSavedSearchService{
+		runFn: func(_ context.Context, searchID, _ string) (*collaboration.SavedSearchRunResult, error) {
+			return &collaboration.SavedSearchRunResult{
+				Search:  &collabdomain.SavedSearch{ID: searchID},
+				Hits:    []collaboration.SearchHit{{ID: "p-1"}, {ID: "p-2"}},
+				NewHits: []collaboration.SearchHit{{ID: "p-2"}},
+			}, nil
+		},
+	}
+	h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/saved-searches/s-1/run", nil)
+	req.SetPathValue("id", "s-1")
+	rec := httptest.NewRecorder()
+
+	h.RunSavedSearch(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp collaboration.SavedSearchRunResult
+	decodeCommentData(t, rec, &resp)
+	require.Len(t, resp.NewHits, 1)
+	assert.Equal(t, "p-2", resp.NewHits[0].ID)
+}
+
+func TestSavedSearchHandler_Share(t *testing.T) {
+	t.Run("requires workspace", func(t *testing.T) {
+		h := NewSavedSearchHandler(&mockSavedSearchService{}, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/saved-searches/s-1/share", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "s-1")
+		rec := httptest.NewRecorder()
+
+		h.ShareSavedSearch(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		svc := &mockSavedSearchService{
+			shareFn: func(_ context.Context, _, workspaceID, _ string) (*collabdomain.SavedSearch, error) {
+				assert.Equal(t, "ws-1", workspaceID)
+				return nil, errors.New(errors.ErrCodeForbidden, "only the owner can share a saved search")
+			},
+		}
+		h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/saved-searches/s-1/share", bytes.NewReader([]byte(`{"workspace_id":"ws-1"}`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetPathValue("id", "s-1")
+		rec := httptest.NewRecorder()
+
+		h.ShareSavedSearch(rec, req)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestSavedSearchHandler_DeleteNotFound(t *testing.T) {
+	svc := &mockSavedSearchService{
+		deleteFn: func(context.Context, string, string) error {
+			return errors.New(errors.ErrCodeNotFound, "saved search not found")
+		},
+	}
+	h := NewSavedSearchHandler(svc, testutil.NewNopLogger())
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/saved-searches/s-9", nil)
+	req.SetPathValue("id", "s-9")
+	rec := httptest.NewRecorder()
+
+	h.DeleteSavedSearch(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWSHandler_ForwardSavedSearchHits(t *testing.T) {
+	server, handler := setupAuthedWSTestServer(t, nil)
+	conn := dialWebSocketAs(t, server, "u-1")
+	other := dialWebSocketAs(t, server, "u-2")
+	pollClientCount(t, handler, 2)
+
+	env, err := kafkaclient.NewEventEnvelope(collaboration.SavedSearchHitsEventType, "worker", &collaboration.SavedSearchHitsEvent{
+		SearchID:   "s-1",
+		Recipients: []string{"u-1"},
+		NewHits:    []collaboration.SearchHit{{ID: "p-7", Title: "CN123 OLED host"}},
+		TotalHits:  4,
+	})
+	require.NoError(t, err)
+	pm, err := env.ToMessage(kafkaclient.TopicNotification)
+	require.NoError(t, err)
+
+	require.NoError(t, handler.ForwardSavedSearchHits(context.Background(), &common.Message{Topic: pm.Topic, Value: pm.Value}))
+
+	_, data := readMessageWithTimeout(t, conn, testReadTimeout)
+	var msg struct {
+		Type    string                 `json:"type"`
+		Payload savedSearchHitsPayload `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(data, &msg))
+	assert.Equal(t, EventTypeSavedSearchHits, msg.Type)
+	assert.Equal(t, []string{"p-7"}, msg.Payload.NewHitIDs)
+	assert.NotContains(t, string(data), "OLED host")
+	assert.NotContains(t, string(data), "recipients")
+	assertNoMessage(t, other, 200*time.Millisecond)
+}
+
+func TestWSHandler_ForwardIgnoresOtherNotifications(t *testing.T) {
+	h := NewWSHandler(nil, testutil.NewNopLogger())
+	env, err := kafkaclient.NewEventEnvelope("notification.send", "worker", map[string]string{"alert_id": "a-1"})
+	require.NoError(t, err)
+	pm, err := env.ToMessage(kafkaclient.TopicNotification)
+	require.NoError(t, err)
+
+	assert.NoError(t, h.ForwardSavedSearchHits(context.Background(), &common.Message{Value: pm.Value}))
+}
+
+//Personal.AI order the ending
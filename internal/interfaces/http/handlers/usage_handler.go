@@ -0,0 +1,211 @@
+// internal/interfaces/http/handlers/usage_handler.go
+// 实现 LLM 用量与预算 HTTP Handler。
+//
+// 实现要求:
+// * 功能定位：按租户、用户、功能、模型或日期汇总 LLM token 用量与成本，查询与设置租户月度预算，
+//   查看当前模型价格表
+// * 核心实现：
+//   - GetUsage / GetBudget / ListPrices：本租户需要 tenant:read 权限，
+//     其他租户或全部租户（tenant_id=*）需要 system:config 权限
+//   - SetBudget：需要 system:config 权限，预算是平台侧的成本上限
+//   - RegisterRoutes
+// * 依赖：internal/application/usage/ledger.go
+// * 被依赖：internal/interfaces/http/router.go
+// * 强制约束：文件最后一行必须为 //Personal.AI order the ending
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	appusage "github.com/turtacn/KeyIP-Intelligence/internal/application/usage"
+	usagedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/auth/keycloak"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// allTenants is the tenant_id value selecting every tenant in a usage report.
+const allTenants = "*"
+
+// UsageHandler handles HTTP requests for LLM usage reporting and budgets.
+type UsageHandler struct {
+	usageSvc appusage.Service
+	logger   logging.Logger
+}
+
+// NewUsageHandler creates a new UsageHandler.
+func NewUsageHandler(usageSvc appusage.Service, logger logging.Logger) *UsageHandler {
+	return &UsageHandler{
+		usageSvc: usageSvc,
+		logger:   logger,
+	}
+}
+
+// SetBudgetBody is the request body for setting a tenant's monthly budget.
+// A zero limit is unlimited.
+type SetBudgetBody struct {
+	SoftLimitUSD float64 `json:"soft_limit_usd"`
+	HardLimitUSD float64 `json:"hard_limit_usd"`
+}
+
+// RegisterRoutes registers all usage routes.
+func (h *UsageHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/usage", h.GetUsage)
+	mux.HandleFunc("GET /api/v1/usage/budget", h.GetBudget)
+	mux.HandleFunc("PUT /api/v1/usage/budget", h.SetBudget)
+	mux.HandleFunc("GET /api/v1/usage/prices", h.ListPrices)
+}
+
+// GetUsage handles GET /api/v1/usage
+//
+// Query parameters: from, to (RFC 3339 or YYYY-MM-DD; default the current
+// month), group_by (tenant, user, feature, model, day), tenant_id, user_id
+// and feature.
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	tenantID, ok := usageTenant(w, r, q.Get("tenant_id"))
+	if !ok {
+		return
+	}
+	from, err := parseUsageTime(q.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("from", "must be RFC 3339 or YYYY-MM-DD"))
+		return
+	}
+	to, err := parseUsageTime(q.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("to", "must be RFC 3339 or YYYY-MM-DD"))
+		return
+	}
+	if from.IsZero() != to.IsZero() {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("from", "from and to must be given together"))
+		return
+	}
+
+	summary, err := h.usageSvc.Summary(r.Context(), &appusage.SummaryRequest{
+		TenantID: tenantID,
+		UserID:   q.Get("user_id"),
+		Feature:  q.Get("feature"),
+		From:     from,
+		To:       to,
+		GroupBy:  usagedomain.Dimension(q.Get("group_by")),
+	})
+	if err != nil {
+		h.logger.Error("failed to summarize LLM usage", logging.Err(err))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// GetBudget handles GET /api/v1/usage/budget
+func (h *UsageHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := usageTenant(w, r, r.URL.Query().Get("tenant_id"))
+	if !ok {
+		return
+	}
+	if tenantID == "" {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("tenant_id", "a budget belongs to a single tenant"))
+		return
+	}
+
+	status, err := h.usageSvc.BudgetStatus(r.Context(), tenantID)
+	if err != nil {
+		h.logger.Error("failed to get LLM budget", logging.Err(err), logging.String("tenant_id", tenantID))
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// SetBudget handles PUT /api/v1/usage/budget
+//
+// The target tenant is the tenant_id query parameter, or the caller's own.
+func (h *UsageHandler) SetBudget(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermSystemConfig) {
+		return
+	}
+	tenantID := r.URL.Query().Get("tenant_id")
+	if tenantID == "" {
+		tenantID = middleware.ContextGetTenantID(r.Context())
+	}
+	if tenantID == allTenants {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("tenant_id", "a budget belongs to a single tenant"))
+		return
+	}
+	if !isContentTypeJSON(r) {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("content-type", "Content-Type must be application/json"))
+		return
+	}
+
+	var body SetBudgetBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, errors.NewValidationError("body", "invalid request body"))
+		return
+	}
+
+	status, err := h.usageSvc.SetBudget(r.Context(), &appusage.SetBudgetRequest{
+		TenantID:     tenantID,
+		SoftLimitUSD: body.SoftLimitUSD,
+		HardLimitUSD: body.HardLimitUSD,
+	})
+	if err != nil {
+		h.logger.Error("failed to set LLM budget", logging.Err(err), logging.String("tenant_id", tenantID))
+		writeAppError(w, err)
+		return
+	}
+
+	h.logger.Info("LLM budget set via API",
+		logging.String("tenant_id", status.TenantID),
+		logging.String("user_id", getUserIDFromContext(r)))
+	writeJSON(w, http.StatusOK, status)
+}
+
+// ListPrices handles GET /api/v1/usage/prices
+func (h *UsageHandler) ListPrices(w http.ResponseWriter, r *http.Request) {
+	if !requirePermission(w, r, keycloak.PermTenantRead) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"prices": h.usageSvc.Prices()})
+}
+
+// usageTenant resolves the tenant a read targets and checks the caller may
+// see it, writing the error response when not. An empty result selects every
+// tenant.
+func usageTenant(w http.ResponseWriter, r *http.Request, requested string) (string, bool) {
+	own := middleware.ContextGetTenantID(r.Context())
+	if requested == "" {
+		requested = own
+		if requested == "" {
+			requested = usagedomain.DefaultTenantID
+		}
+	}
+	if requested == own || (own == "" && requested == usagedomain.DefaultTenantID) {
+		return requested, requirePermission(w, r, keycloak.PermTenantRead)
+	}
+	if !requirePermission(w, r, keycloak.PermSystemConfig) {
+		return "", false
+	}
+	if requested == allTenants {
+		return "", true
+	}
+	return requested, true
+}
+
+func parseUsageTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+//Personal.AI order the ending
@@ -0,0 +1,178 @@
+// Tests for the LLM usage and budget HTTP handler.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appusage "github.com/turtacn/KeyIP-Intelligence/internal/application/usage"
+	usagedomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/usage"
+	"github.com/turtacn/KeyIP-Intelligence/internal/intelligence/common"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/internal/testutil"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+// mockUsageService implements appusage.Service for testing.
+type mockUsageService struct {
+	summaryFn   func(context.Context, *appusage.SummaryRequest) (*appusage.Summary, error)
+	budgetFn    func(context.Context, string) (*appusage.BudgetStatus, error)
+	setBudgetFn func(context.Context, *appusage.SetBudgetRequest) (*appusage.BudgetStatus, error)
+}
+
+func (m *mockUsageService) Authorize(context.Context, string) error { return nil }
+func (m *mockUsageService) Record(context.Context, *common.UsageEvent) error {
+	return nil
+}
+func (m *mockUsageService) Summary(ctx context.Context, req *appusage.SummaryRequest) (*appusage.Summary, error) {
+	return m.summaryFn(ctx, req)
+}
+func (m *mockUsageService) BudgetStatus(ctx context.Context, tenantID string) (*appusage.BudgetStatus, error) {
+	return m.budgetFn(ctx, tenantID)
+}
+func (m *mockUsageService) SetBudget(ctx context.Context, req *appusage.SetBudgetRequest) (*appusage.BudgetStatus, error) {
+	return m.setBudgetFn(ctx, req)
+}
+func (m *mockUsageService) Prices() map[string]usagedomain.ModelPrice {
+	return map[string]usagedomain.ModelPrice{"default": {InputPer1K: 0.003, OutputPer1K: 0.015}}
+}
+
+// serveUsage routes req through the handler's mux as a caller of tenant
+// with roles.
+func serveUsage(svc appusage.Service, tenant string, roles []string, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	NewUsageHandler(svc, testutil.NewNopLogger()).RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	withClaims(mux.ServeHTTP, &middleware.Claims{
+		UserID:    "u-1",
+		TenantID:  tenant,
+		Roles:     roles,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, rec, req)
+	return rec
+}
+
+func TestUsageHandler_GetUsage(t *testing.T) {
+	t.Run("own tenant", func(t *testing.T) {
+		svc := &mockUsageService{
+			summaryFn: func(_ context.Context, req *appusage.SummaryRequest) (*appusage.Summary, error) {
+				assert.Equal(t, "acme", req.TenantID)
+				assert.Equal(t, usagedomain.ByModel, req.GroupBy)
+				assert.Equal(t, "ai.chat", req.Feature)
+				assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), req.From)
+				return &appusage.Summary{
+					TenantID: req.TenantID,
+					GroupBy:  req.GroupBy,
+					Lines:    []*usagedomain.Line{{Key: "claude-sonnet-4", Requests: 2, CostUSD: 0.5}},
+					Total:    usagedomain.Line{Requests: 2, CostUSD: 0.5},
+				}, nil
+			},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?group_by=model&feature=ai.chat&from=2026-09-01&to=2026-10-01", nil)
+		rec := serveUsage(svc, "acme", []string{"tenant_admin"}, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var out appusage.Summary
+		decodeCommentData(t, rec, &out)
+		require.Len(t, out.Lines, 1)
+		assert.Equal(t, 0.5, out.Total.CostUSD)
+	})
+
+	t.Run("other tenant needs system config", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?tenant_id=globex", nil)
+		rec := serveUsage(&mockUsageService{}, "acme", []string{"tenant_admin"}, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("all tenants", func(t *testing.T) {
+		svc := &mockUsageService{
+			summaryFn: func(_ context.Context, req *appusage.SummaryRequest) (*appusage.Summary, error) {
+				assert.Empty(t, req.TenantID)
+				return &appusage.Summary{}, nil
+			},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?tenant_id=*&group_by=tenant", nil)
+		rec := serveUsage(svc, "acme", []string{"super_admin"}, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("bad range", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/usage?from=2026-09-01", nil)
+		rec := serveUsage(&mockUsageService{}, "acme", []string{"tenant_admin"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestUsageHandler_Budget(t *testing.T) {
+	status := func(tenantID string) *appusage.BudgetStatus {
+		return &appusage.BudgetStatus{TenantID: tenantID, SpentUSD: 12, HardLimitUSD: 100, RemainingUSD: 88, Level: usagedomain.BudgetOK}
+	}
+
+	t.Run("get own budget", func(t *testing.T) {
+		svc := &mockUsageService{
+			budgetFn: func(_ context.Context, tenantID string) (*appusage.BudgetStatus, error) {
+				return status(tenantID), nil
+			},
+		}
+		rec := serveUsage(svc, "acme", []string{"viewer"}, httptest.NewRequest(http.MethodGet, "/api/v1/usage/budget", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var out appusage.BudgetStatus
+		decodeCommentData(t, rec, &out)
+		assert.Equal(t, "acme", out.TenantID)
+		assert.Equal(t, 88.0, out.RemainingUSD)
+	})
+
+	t.Run("set requires system config", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/usage/budget", bytes.NewBufferString(`{"hard_limit_usd":500}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveUsage(&mockUsageService{}, "acme", []string{"tenant_admin"}, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		svc := &mockUsageService{
+			setBudgetFn: func(_ context.Context, req *appusage.SetBudgetRequest) (*appusage.BudgetStatus, error) {
+				assert.Equal(t, "globex", req.TenantID)
+				assert.Equal(t, 400.0, req.SoftLimitUSD)
+				assert.Equal(t, 500.0, req.HardLimitUSD)
+				return status(req.TenantID), nil
+			},
+		}
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/usage/budget?tenant_id=globex",
+			bytes.NewBufferString(`{"soft_limit_usd":400,"hard_limit_usd":500}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveUsage(svc, "acme", []string{"super_admin"}, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid budget", func(t *testing.T) {
+		svc := &mockUsageService{
+			setBudgetFn: func(context.Context, *appusage.SetBudgetRequest) (*appusage.BudgetStatus, error) {
+				return nil, errors.NewValidationError("soft_limit_usd", "cannot exceed the hard limit")
+			},
+		}
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/usage/budget", bytes.NewBufferString(`{"soft_limit_usd":600,"hard_limit_usd":500}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serveUsage(svc, "acme", []string{"super_admin"}, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestUsageHandler_ListPrices(t *testing.T) {
+	rec := serveUsage(&mockUsageService{}, "acme", []string{"viewer"}, httptest.NewRequest(http.MethodGet, "/api/v1/usage/prices", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var out struct {
+		Prices map[string]usagedomain.ModelPrice `json:"prices"`
+	}
+	decodeCommentData(t, rec, &out)
+	assert.Contains(t, out.Prices, "default")
+}
+
+//Personal.AI order the ending
@@ -20,13 +20,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/turtacn/KeyIP-Intelligence/internal/application/collaboration"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
+	kafkaclient "github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/messaging/kafka"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/types/common"
 )
 
 // WebSocket event type constants for frontend consumption.
@@ -35,6 +41,8 @@ const (
 	EventTypeDeadlineAlert       = "deadline_alert"
 	EventTypeInfringementWarning = "infringement_warning"
 	EventTypeSystemNotification  = "system_notification"
+	EventTypeCommentActivity     = "comment_activity"
+	EventTypeSavedSearchHits     = "saved_search_hits"
 )
 
 // WebSocket operational constants.
@@ -57,20 +65,24 @@ type WSMessage struct {
 type WSHandler struct {
 	upgrader websocket.Upgrader
 	clients  sync.Map
+	members  collabdomain.MemberRepository
 	logger   logging.Logger
 }
 
-// wsClient represents a single connected WebSocket client.
+// wsClient represents a single connected WebSocket client. userID is the
+// authenticated caller, empty for anonymous connections.
 type wsClient struct {
 	mu      sync.Mutex
 	closed  bool
 	handler *WSHandler
 	conn    *websocket.Conn
 	send    chan []byte
+	userID  string
 }
 
-// NewWSHandler creates a new WSHandler with the given logger.
-func NewWSHandler(logger logging.Logger) *WSHandler {
+// NewWSHandler creates a new WSHandler. members resolves the recipients of
+// workspace activity; when nil, workspace activity is not delivered.
+func NewWSHandler(members collabdomain.MemberRepository, logger logging.Logger) *WSHandler {
 	return &WSHandler{
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
@@ -79,7 +91,8 @@ func NewWSHandler(logger logging.Logger) *WSHandler {
 				return true // Allow all origins; tighten for production environments
 			},
 		},
-		logger: logger,
+		members: members,
+		logger:  logger,
 	}
 }
 
@@ -102,6 +115,7 @@ func (h *WSHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		handler: h,
 		conn:    conn,
 		send:    make(chan []byte, 256),
+		userID:  middleware.ContextGetUserID(r.Context()),
 	}
 
 	h.clients.Store(c, true)
@@ -117,6 +131,29 @@ func (h *WSHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 // Broadcast sends a message to all connected WebSocket clients.
 // If a client's send buffer is full, the client is removed.
 func (h *WSHandler) Broadcast(msg WSMessage) {
+	h.deliver(msg, func(*wsClient) bool { return true })
+}
+
+// SendToUsers sends a message only to the connections authenticated as one
+// of the given users. Anonymous connections never receive it.
+func (h *WSHandler) SendToUsers(userIDs []string, msg WSMessage) {
+	if len(userIDs) == 0 {
+		return
+	}
+	want := make(map[string]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		if id != "" {
+			want[id] = struct{}{}
+		}
+	}
+	h.deliver(msg, func(c *wsClient) bool {
+		_, ok := want[c.userID]
+		return ok
+	})
+}
+
+// deliver queues msg on every client accepted by match.
+func (h *WSHandler) deliver(msg WSMessage, match func(*wsClient) bool) {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		h.logger.Error("websocket broadcast marshal failed", logging.Err(err))
@@ -125,7 +162,7 @@ func (h *WSHandler) Broadcast(msg WSMessage) {
 
 	h.clients.Range(func(key, value interface{}) bool {
 		c, ok := key.(*wsClient)
-		if !ok {
+		if !ok || !match(c) {
 			return true
 		}
 
@@ -156,6 +193,84 @@ func (h *WSHandler) BroadcastEvent(eventType string, payload interface{}) {
 	})
 }
 
+// PublishActivity sends a workspace activity record as a comment_activity
+// event to the active members of the record's workspace. It satisfies the
+// collaboration ActivityPublisher port; records carry identifiers only, so
+// clients re-fetch content through the REST API.
+func (h *WSHandler) PublishActivity(ctx context.Context, record *collabdomain.ActivityRecord) error {
+	if record == nil || record.WorkspaceID == "" || h.members == nil {
+		return nil
+	}
+	members, err := h.members.FindByWorkspaceID(ctx, record.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	userIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.IsActive {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	h.SendToUsers(userIDs, WSMessage{
+		Type:      EventTypeCommentActivity,
+		Payload:   record,
+		Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+// savedSearchHitsPayload is the WebSocket form of a saved search hits event.
+// Like comment activity it carries identifiers only.
+type savedSearchHitsPayload struct {
+	SearchID    string    `json:"search_id"`
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	NewHitIDs   []string  `json:"new_hit_ids"`
+	TotalHits   int       `json:"total_hits"`
+	RunAt       time.Time `json:"run_at"`
+}
+
+// NotifyNewHits sends the new hits of a saved search run as a
+// saved_search_hits event to the event's recipients only. It satisfies the
+// collaboration HitNotifier port.
+func (h *WSHandler) NotifyNewHits(_ context.Context, event *collaboration.SavedSearchHitsEvent) error {
+	if event == nil || len(event.NewHits) == 0 || len(event.Recipients) == 0 {
+		return nil
+	}
+	ids := make([]string, len(event.NewHits))
+	for i, hit := range event.NewHits {
+		ids[i] = hit.ID
+	}
+	h.SendToUsers(event.Recipients, WSMessage{
+		Type: EventTypeSavedSearchHits,
+		Payload: savedSearchHitsPayload{
+			SearchID:    event.SearchID,
+			WorkspaceID: event.WorkspaceID,
+			NewHitIDs:   ids,
+			TotalHits:   event.TotalHits,
+			RunAt:       event.RunAt,
+		},
+		Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
+
+// ForwardSavedSearchHits relays saved search hits events published on the
+// notification topic by the worker. Other notification events are ignored.
+func (h *WSHandler) ForwardSavedSearchHits(ctx context.Context, msg *common.Message) error {
+	env, err := kafkaclient.MessageToEventEnvelope(msg)
+	if err != nil {
+		return err
+	}
+	if env.EventType != collaboration.SavedSearchHitsEventType {
+		return nil
+	}
+	var event collaboration.SavedSearchHitsEvent
+	if err := env.DecodePayload(&event); err != nil {
+		return err
+	}
+	return h.NotifyNewHits(ctx, &event)
+}
+
 // clientCount returns the number of currently connected WebSocket clients.
 func (h *WSHandler) clientCount() int {
 	count := 0
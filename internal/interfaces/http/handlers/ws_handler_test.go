@@ -24,7 +24,9 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	collabdomain "github.com/turtacn/KeyIP-Intelligence/internal/domain/collaboration"
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/internal/interfaces/http/middleware"
 )
 
 const (
@@ -43,7 +45,7 @@ func setupWSTestServer(t *testing.T) (*httptest.Server, *WSHandler) {
 	t.Helper()
 
 	logger := logging.NewNopLogger()
-	handler := NewWSHandler(logger)
+	handler := NewWSHandler(nil, logger)
 
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
@@ -54,6 +56,51 @@ func setupWSTestServer(t *testing.T) (*httptest.Server, *WSHandler) {
 	return server, handler
 }
 
+// userTokenValidator accepts any bearer token and treats it as the user ID.
+type userTokenValidator struct{}
+
+func (userTokenValidator) ValidateToken(token string) (*middleware.Claims, error) {
+	return &middleware.Claims{UserID: token, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+// setupAuthedWSTestServer is setupWSTestServer behind the auth middleware,
+// so connections carry the user they dialled as.
+func setupAuthedWSTestServer(t *testing.T, members collabdomain.MemberRepository) (*httptest.Server, *WSHandler) {
+	t.Helper()
+
+	logger := logging.NewNopLogger()
+	handler := NewWSHandler(members, logger)
+
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	auth := middleware.NewAuthMiddleware(userTokenValidator{}, nil, middleware.AuthConfig{}, logger)
+
+	server := httptest.NewServer(auth.Handler(mux))
+	t.Cleanup(server.Close)
+
+	return server, handler
+}
+
+// dialWebSocketAs connects to the WebSocket endpoint as userID.
+func dialWebSocketAs(t *testing.T, server *httptest.Server, userID string) *websocket.Conn {
+	t.Helper()
+
+	header := http.Header{"Authorization": []string{"Bearer " + userID}}
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL(server, "/api/v1/ws/events"), header)
+	require.NoError(t, err, "WebSocket dial should succeed")
+
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// assertNoMessage fails the test if conn receives a message within timeout.
+func assertNoMessage(t *testing.T, conn *websocket.Conn, timeout time.Duration) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	_, data, err := conn.ReadMessage()
+	assert.Error(t, err, "unexpected message %s", data)
+}
+
 // wsURL converts an httptest server http:// URL into a ws:// URL suitable
 // for gorilla/websocket dialler.
 func wsURL(server *httptest.Server, path string) string {
@@ -165,6 +212,21 @@ func TestWebSocketBroadcast(t *testing.T) {
 	}
 }
 
+// TestWebSocketSendToUsers verifies that targeted messages reach only the
+// named users' connections.
+func TestWebSocketSendToUsers(t *testing.T) {
+	server, handler := setupAuthedWSTestServer(t, nil)
+	alice := dialWebSocketAs(t, server, "u-alice")
+	bob := dialWebSocketAs(t, server, "u-bob")
+	pollClientCount(t, handler, 2)
+
+	handler.SendToUsers([]string{"u-alice"}, WSMessage{Type: EventTypeSystemNotification, Timestamp: time.Now().UTC()})
+
+	_, data := readMessageWithTimeout(t, alice, testReadTimeout)
+	assert.Contains(t, string(data), EventTypeSystemNotification)
+	assertNoMessage(t, bob, 200*time.Millisecond)
+}
+
 // TestWebSocketBroadcastEvent verifies that the BroadcastEvent convenience
 // method correctly wraps payload in a WSMessage and broadcasts it.
 func TestWebSocketBroadcastEvent(t *testing.T) {
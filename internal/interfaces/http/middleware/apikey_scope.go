@@ -0,0 +1,106 @@
+// Phase 11 - 接口层: HTTP Middleware - API Key 权限范围
+// 文件: internal/interfaces/http/middleware/apikey_scope.go
+// 功能定位: 将请求方法与路径映射为 API key 必须持有的权限（keycloak Permission 字符串），
+// 由 AuthMiddleware 在 API key 认证成功后校验
+// 核心实现:
+//   - 定义 APIKeyScopeRule: Method, PathPrefix, Scope
+//   - DefaultAPIKeyScopeRules: 按资源前缀划分读/写/删除权限，检索类 POST 视为读
+//   - RequiredAPIKeyScope: 顺序匹配，首条命中规则生效；无命中表示拒绝
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIKeyScopeRule maps requests to the scope an API key needs.
+type APIKeyScopeRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any.
+	// GET rules also match HEAD.
+	Method string
+	// PathPrefix is matched against the request path on segment boundaries.
+	PathPrefix string
+	// Scope is the permission the key must hold.
+	Scope string
+}
+
+// DefaultAPIKeyScopeRules returns the scope rules for the v1 API. Search
+// and analysis endpoints that use POST only need read or analysis scopes.
+// Collaboration and WebSocket routes have no rule and so are closed to API
+// keys.
+func DefaultAPIKeyScopeRules() []APIKeyScopeRule {
+	return []APIKeyScopeRule{
+		{Method: http.MethodPost, PathPrefix: "/api/v1/patents/search", Scope: "patent:read"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/molecules/search", Scope: "patent:read"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/molecules/properties/calculate", Scope: "analysis:create"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/patents/analyze-claims", Scope: "analysis:create"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/patents/assess-infringement", Scope: "analysis:create"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/patents/check-fto", Scope: "analysis:create"},
+		{Method: http.MethodPost, PathPrefix: "/api/v1/ai", Scope: "analysis:create"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/patents", Scope: "patent:read"},
+		{Method: http.MethodDelete, PathPrefix: "/api/v1/patents", Scope: "patent:delete"},
+		{PathPrefix: "/api/v1/patents", Scope: "patent:write"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/molecules", Scope: "patent:read"},
+		{PathPrefix: "/api/v1/molecules", Scope: "patent:write"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/portfolios", Scope: "patent:read"},
+		{PathPrefix: "/api/v1/portfolios", Scope: "patent:write"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/lifecycle", Scope: "patent:read"},
+		{PathPrefix: "/api/v1/lifecycle", Scope: "patent:write"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/deadlines", Scope: "patent:read"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/saved-searches", Scope: "patent:read"},
+		{PathPrefix: "/api/v1/saved-searches", Scope: "patent:write"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/infringement", Scope: "analysis:read"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/fto", Scope: "analysis:read"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/knowledge-graph", Scope: "graph:read"},
+		{PathPrefix: "/api/v1/knowledge-graph", Scope: "graph:write"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/reports", Scope: "report:read"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/report-templates", Scope: "report:read"},
+		{PathPrefix: "/api/v1/reports", Scope: "report:create"},
+
+		{Method: http.MethodDelete, PathPrefix: "/api/v1/api-keys", Scope: "api:key_revoke"},
+		{PathPrefix: "/api/v1/api-keys", Scope: "api:key_create"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/admin/dlq", Scope: "system:monitor"},
+		{PathPrefix: "/api/v1/admin/dlq", Scope: "system:config"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/usage", Scope: "tenant:read"},
+		{PathPrefix: "/api/v1/usage", Scope: "system:config"},
+
+		{Method: http.MethodGet, PathPrefix: "/api/v1/admin/assignees", Scope: "patent:read"},
+		{PathPrefix: "/api/v1/admin/assignees", Scope: "patent:write"},
+		{PathPrefix: "/api/v1/admin/inventors", Scope: "patent:write"},
+		{Method: http.MethodGet, PathPrefix: "/api/v1/inventors", Scope: "patent:read"},
+	}
+}
+
+// RequiredAPIKeyScope returns the scope of the first rule matching r. The
+// second result is false when no rule matches.
+func RequiredAPIKeyScope(rules []APIKeyScopeRule, r *http.Request) (string, bool) {
+	for _, rule := range rules {
+		if rule.Method != "" && rule.Method != r.Method &&
+			!(rule.Method == http.MethodGet && r.Method == http.MethodHead) {
+			continue
+		}
+		if r.URL.Path == rule.PathPrefix || strings.HasPrefix(r.URL.Path, rule.PathPrefix+"/") {
+			return rule.Scope, true
+		}
+	}
+	return "", false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//Personal.AI order the ending
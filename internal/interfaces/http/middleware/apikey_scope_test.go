@@ -0,0 +1,212 @@
+// Phase 11 - 接口层: HTTP Middleware - API Key 权限范围单元测试
+// 文件: internal/interfaces/http/middleware/apikey_scope_test.go
+// 测试用例:
+//   - TestRequiredAPIKeyScope: 方法/路径到权限的映射，检索类 POST 视为读
+//   - TestAuthenticate_APIKey_RequestValidator: 按请求校验并传入客户端 IP
+//   - TestAuthenticate_APIKey_BearerPrefix: Bearer 头中的 API key
+//   - TestAuthenticate_APIKey_QuotaExceeded: 配额超限返回 429
+//   - TestAuthenticate_APIKey_ScopeDenied: 权限不足或无匹配规则返回 403
+//   - TestAuthenticate_APIKey_RateLimited: 单 key 按层级限流
+//   - TestUsageStats_RecordsAPIKeyIdentity: 使用统计记录认证后的 API key
+//
+// 强制约束: 文件最后一行必须为 //Personal.AI order the ending
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+type fakeRequestAPIKeyValidator struct {
+	info     *APIKeyInfo
+	err      error
+	gotKey   string
+	gotIP    string
+	requests int
+}
+
+func (f *fakeRequestAPIKeyValidator) ValidateAPIKey(key string) (*APIKeyInfo, error) {
+	panic("ValidateAPIKeyRequest should be preferred")
+}
+
+func (f *fakeRequestAPIKeyValidator) ValidateAPIKeyRequest(_ context.Context, key, clientIP string) (*APIKeyInfo, error) {
+	f.requests++
+	f.gotKey, f.gotIP = key, clientIP
+	return f.info, f.err
+}
+
+func newScopedAuthMiddleware(v APIKeyValidator, config AuthConfig) *AuthMiddleware {
+	return NewAuthMiddleware(new(mockTokenValidator), v, config, logging.NewNopLogger())
+}
+
+func TestRequiredAPIKeyScope(t *testing.T) {
+	rules := DefaultAPIKeyScopeRules()
+	cases := []struct {
+		method, path, scope string
+		ok                  bool
+	}{
+		{http.MethodGet, "/api/v1/patents/123", "patent:read", true},
+		{http.MethodHead, "/api/v1/patents", "patent:read", true},
+		{http.MethodPost, "/api/v1/patents/search/advanced", "patent:read", true},
+		{http.MethodPost, "/api/v1/patents", "patent:write", true},
+		{http.MethodDelete, "/api/v1/patents/123", "patent:delete", true},
+		{http.MethodPost, "/api/v1/patents/check-fto", "analysis:create", true},
+		{http.MethodGet, "/api/v1/knowledge-graph/entities", "graph:read", true},
+		{http.MethodDelete, "/api/v1/api-keys/1", "api:key_revoke", true},
+		{http.MethodGet, "/api/v1/admin/dlq/patent.new.dlq/messages", "system:monitor", true},
+		{http.MethodPost, "/api/v1/admin/dlq/patent.new.dlq/replay", "system:config", true},
+		{http.MethodGet, "/api/v1/admin/assignees/reviews", "patent:read", true},
+		{http.MethodPost, "/api/v1/admin/assignees/reviews/r1/approve", "patent:write", true},
+		{http.MethodPost, "/api/v1/admin/inventors/disambiguate", "patent:write", true},
+		{http.MethodGet, "/api/v1/inventors/mobility", "patent:read", true},
+		{http.MethodGet, "/api/v1/patentsx", "", false},
+		{http.MethodGet, "/api/v1/workspaces/1", "", false},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		scope, ok := RequiredAPIKeyScope(rules, r)
+		assert.Equal(t, tc.ok, ok, "%s %s", tc.method, tc.path)
+		assert.Equal(t, tc.scope, scope, "%s %s", tc.method, tc.path)
+	}
+}
+
+func TestAuthenticate_APIKey_RequestValidator(t *testing.T) {
+	v := &fakeRequestAPIKeyValidator{info: &APIKeyInfo{KeyID: "k1", UserID: "u1", Tier: "professional"}}
+	m := newScopedAuthMiddleware(v, AuthConfig{})
+
+	var gotUser string
+	var gotTier UserTier
+	handler := m.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = ContextGetUserID(r.Context())
+		gotTier = ContextGetUserTier(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/v1/patents", nil)
+	r.Header.Set("X-API-Key", "kip_abc")
+	r.Header.Set("X-Real-IP", "10.1.2.3")
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "kip_abc", v.gotKey)
+	assert.Equal(t, "10.1.2.3", v.gotIP)
+	assert.Equal(t, "u1", gotUser)
+	assert.Equal(t, TierProfessional, gotTier)
+}
+
+func TestAuthenticate_APIKey_BearerPrefix(t *testing.T) {
+	v := &fakeRequestAPIKeyValidator{info: &APIKeyInfo{KeyID: "k1"}}
+	m := newScopedAuthMiddleware(v, AuthConfig{APIKeyBearerPrefix: "kip_"})
+
+	called := false
+	handler := m.Authenticate()(testHandler(&called))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/v1/patents", nil)
+	r.Header.Set("Authorization", "Bearer kip_abc")
+	handler.ServeHTTP(w, r)
+
+	assert.True(t, called)
+	assert.Equal(t, "kip_abc", v.gotKey)
+}
+
+func TestAuthenticate_APIKey_QuotaExceeded(t *testing.T) {
+	v := &fakeRequestAPIKeyValidator{err: errors.New(errors.ErrCodeTooManyRequests, "quota")}
+	m := newScopedAuthMiddleware(v, AuthConfig{})
+
+	called := false
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/v1/patents", nil)
+	r.Header.Set("X-API-Key", "kip_abc")
+	m.Authenticate()(testHandler(&called)).ServeHTTP(w, r)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "QUOTA_EXCEEDED")
+}
+
+func TestAuthenticate_APIKey_ScopeDenied(t *testing.T) {
+	v := &fakeRequestAPIKeyValidator{info: &APIKeyInfo{KeyID: "k1", Scopes: []string{"patent:read"}}}
+	m := newScopedAuthMiddleware(v, AuthConfig{APIKeyScopeRules: DefaultAPIKeyScopeRules()})
+	handler := func(called *bool) http.Handler { return m.Authenticate()(testHandler(called)) }
+
+	cases := []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/api/v1/patents/1", http.StatusOK},
+		{http.MethodPost, "/api/v1/patents/search", http.StatusOK},
+		{http.MethodPost, "/api/v1/patents", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/workspaces", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		called := false
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		r.Header.Set("X-API-Key", "kip_abc")
+		handler(&called).ServeHTTP(w, r)
+		assert.Equal(t, tc.code, w.Code, "%s %s", tc.method, tc.path)
+		assert.Equal(t, tc.code == http.StatusOK, called)
+	}
+}
+
+func TestAuthenticate_APIKey_RateLimited(t *testing.T) {
+	limiter := NewTierRateLimiter(RateLimitConfig{
+		RequestsPerSecond: 100,
+		BurstSize:         100,
+		CleanupInterval:   time.Minute,
+		TierLimits: map[UserTier]TierLimits{
+			TierFree: {RequestsPerSecond: 0.001, BurstSize: 2},
+		},
+	})
+	defer limiter.Stop()
+
+	v := &fakeRequestAPIKeyValidator{info: &APIKeyInfo{KeyID: "k1", Tier: "free"}}
+	m := newScopedAuthMiddleware(v, AuthConfig{APIKeyRateLimiter: limiter})
+	var served int32
+	handler := m.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&served, 1)
+	}))
+
+	codes := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/patents", nil)
+		r.Header.Set("X-API-Key", "kip_abc")
+		handler.ServeHTTP(w, r)
+		codes = append(codes, w.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, int32(2), served)
+}
+
+func TestUsageStats_RecordsAPIKeyIdentity(t *testing.T) {
+	v := &fakeRequestAPIKeyValidator{info: &APIKeyInfo{KeyID: "k1", UserID: "u1"}}
+	auth := newScopedAuthMiddleware(v, AuthConfig{})
+	stats := NewUsageStatsMiddleware(logging.NewNopLogger(), DefaultUsageStatsConfig())
+	defer stats.Stop()
+
+	called := false
+	handler := stats.Handler(auth.Authenticate()(testHandler(&called)))
+	r := httptest.NewRequest("GET", "/api/v1/patents", nil)
+	r.Header.Set("X-API-Key", "kip_abc")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	require.True(t, called)
+
+	count, ok := stats.apiKeyCounts.Load("k1")
+	require.True(t, ok)
+	assert.Equal(t, int64(1), atomic.LoadInt64(count.(*int64)))
+	_, ok = stats.userCounts.Load(userKey{UserID: "u1"})
+	assert.True(t, ok)
+}
+
+//Personal.AI order the ending
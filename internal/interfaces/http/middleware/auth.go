@@ -16,6 +16,8 @@
 //   - 实现 OptionalAuth() 中间件: 认证可选，未提供凭证时以匿名身份继续
 //   - 实现 contextKey 类型和 ContextGetClaims/ContextGetAPIKeyInfo 辅助函数
 //   - 支持路径白名单配置，跳过特定路径的认证（如 /health, /metrics）
+//   - API key 支持按请求校验（记录最近使用、月度配额超限返回 429）、
+//     按 APIKeyScopeRules 校验权限范围（403），并按层级进行单 key 限流
 //
 // 安全考量:
 //   - Token 过期自动拒绝
@@ -37,6 +39,7 @@ import (
 	"time"
 
 	"github.com/turtacn/KeyIP-Intelligence/internal/infrastructure/monitoring/logging"
+	"github.com/turtacn/KeyIP-Intelligence/pkg/errors"
 )
 
 // contextKey is an unexported type for context keys to prevent collisions.
@@ -47,6 +50,8 @@ const (
 	claimsContextKey contextKey = iota
 	// apiKeyInfoContextKey is the context key for API key info.
 	apiKeyInfoContextKey
+	// usageIdentityContextKey is the context key for the usage stats identity holder.
+	usageIdentityContextKey
 )
 
 // Claims represents the decoded JWT token claims.
@@ -61,9 +66,11 @@ type Claims struct {
 // APIKeyInfo represents validated API key information.
 type APIKeyInfo struct {
 	KeyID     string   `json:"key_id"`
+	UserID    string   `json:"user_id,omitempty"`
 	TenantID  string   `json:"tenant_id"`
 	Scopes    []string `json:"scopes"`
 	RateLimit int      `json:"rate_limit"`
+	Tier      string   `json:"tier,omitempty"`
 }
 
 // TokenValidator validates JWT bearer tokens.
@@ -76,12 +83,34 @@ type APIKeyValidator interface {
 	ValidateAPIKey(key string) (*APIKeyInfo, error)
 }
 
+// RequestAPIKeyValidator is implemented by validators that need the request
+// context and client IP, e.g. to record last use and count quota. When the
+// configured APIKeyValidator implements it, it is used instead of
+// ValidateAPIKey. Errors with code ErrCodeTooManyRequests produce 429.
+type RequestAPIKeyValidator interface {
+	ValidateAPIKeyRequest(ctx context.Context, key, clientIP string) (*APIKeyInfo, error)
+}
+
 // AuthConfig holds configuration for the auth middleware.
 type AuthConfig struct {
 	// SkipPaths are paths that bypass authentication entirely.
 	SkipPaths []string
 	// AllowExpiredGracePeriod allows tokens expired within this duration.
 	AllowExpiredGracePeriod time.Duration
+	// APIKeyBearerPrefix routes Bearer tokens with this prefix to the API key
+	// validator, so SDK clients can send keys in the Authorization header.
+	APIKeyBearerPrefix string
+	// APIKeyScopeRules, when non-nil, restrict API key requests to paths whose
+	// matching rule names a scope the key holds. Paths without a matching
+	// rule are forbidden for API keys.
+	APIKeyScopeRules []APIKeyScopeRule
+	// APIKeyRateLimiter, when set, rate limits API key requests per key using
+	// TierKeyFunc, after authentication has resolved the key's tier.
+	APIKeyRateLimiter RateLimiter
+	// RequiredPaths, when non-empty, limits the 401 for missing credentials
+	// to these path prefixes; other requests without credentials continue
+	// anonymously. Invalid credentials are rejected on every path.
+	RequiredPaths []string
 }
 
 // AuthMiddleware provides HTTP authentication middleware.
@@ -111,6 +140,11 @@ func NewAuthMiddleware(
 // Requests without valid credentials receive 401 Unauthorized.
 func (m *AuthMiddleware) Authenticate() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
+		apiKeyNext := next
+		if m.config.APIKeyRateLimiter != nil {
+			apiKeyNext = RateLimit(m.config.APIKeyRateLimiter, RateLimitConfig{KeyFunc: TierKeyFunc})(next)
+		}
+
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Check skip paths
 			if m.shouldSkip(r.URL.Path) {
@@ -119,7 +153,7 @@ func (m *AuthMiddleware) Authenticate() func(http.Handler) http.Handler {
 			}
 
 			// Try Bearer token first
-			if token := extractBearerToken(r); token != "" {
+			if token := extractBearerToken(r); token != "" && !m.isAPIKeyBearer(token) {
 				claims, err := m.tokenValidator.ValidateToken(token)
 				if err != nil {
 					m.logger.Error("token validation failed",
@@ -136,27 +170,45 @@ func (m *AuthMiddleware) Authenticate() func(http.Handler) http.Handler {
 				}
 
 				ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+				noteUsageIdentity(ctx)
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
 			// Try API key
-			if apiKey := extractAPIKey(r); apiKey != "" {
-				info, err := m.apiKeyValidator.ValidateAPIKey(apiKey)
+			if apiKey := m.extractAPIKey(r); apiKey != "" {
+				info, err := m.validateAPIKey(r, apiKey)
 				if err != nil {
 					m.logger.Error("API key validation failed",
 						logging.Err(err),
 						logging.String("path", r.URL.Path))
+					if errors.IsCode(err, errors.ErrCodeTooManyRequests) {
+						writeQuotaExceeded(w)
+						return
+					}
 					writeUnauthorized(w, "invalid API key")
 					return
 				}
 
+				if m.config.APIKeyScopeRules != nil {
+					scope, ok := RequiredAPIKeyScope(m.config.APIKeyScopeRules, r)
+					if !ok || !hasScope(info.Scopes, scope) {
+						writeForbidden(w, "API key scope does not permit this request")
+						return
+					}
+				}
+
 				ctx := context.WithValue(r.Context(), apiKeyInfoContextKey, info)
-				next.ServeHTTP(w, r.WithContext(ctx))
+				noteUsageIdentity(ctx)
+				apiKeyNext.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
 			// No credentials provided
+			if !m.requiresAuth(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			writeUnauthorized(w, "authentication required")
 		})
 	}
@@ -167,20 +219,22 @@ func (m *AuthMiddleware) OptionalAuth() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Try Bearer token
-			if token := extractBearerToken(r); token != "" {
+			if token := extractBearerToken(r); token != "" && !m.isAPIKeyBearer(token) {
 				claims, err := m.tokenValidator.ValidateToken(token)
 				if err == nil && time.Now().Before(claims.ExpiresAt.Add(m.config.AllowExpiredGracePeriod)) {
 					ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+					noteUsageIdentity(ctx)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
 			}
 
 			// Try API key
-			if apiKey := extractAPIKey(r); apiKey != "" {
-				info, err := m.apiKeyValidator.ValidateAPIKey(apiKey)
+			if apiKey := m.extractAPIKey(r); apiKey != "" {
+				info, err := m.validateAPIKey(r, apiKey)
 				if err == nil {
 					ctx := context.WithValue(r.Context(), apiKeyInfoContextKey, info)
+					noteUsageIdentity(ctx)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
 				}
@@ -202,6 +256,45 @@ func (m *AuthMiddleware) shouldSkip(path string) bool {
 	return false
 }
 
+// requiresAuth reports whether a request without credentials must be
+// rejected on the given path.
+func (m *AuthMiddleware) requiresAuth(path string) bool {
+	if len(m.config.RequiredPaths) == 0 {
+		return true
+	}
+	for _, p := range m.config.RequiredPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAPIKeyBearer reports whether a Bearer token is really an API key.
+func (m *AuthMiddleware) isAPIKeyBearer(token string) bool {
+	return m.config.APIKeyBearerPrefix != "" && strings.HasPrefix(token, m.config.APIKeyBearerPrefix)
+}
+
+// extractAPIKey returns the API key from X-API-Key, the api_key query
+// parameter, or a Bearer token carrying the configured API key prefix.
+func (m *AuthMiddleware) extractAPIKey(r *http.Request) string {
+	if key := extractAPIKey(r); key != "" {
+		return key
+	}
+	if token := extractBearerToken(r); m.isAPIKeyBearer(token) {
+		return token
+	}
+	return ""
+}
+
+// validateAPIKey prefers the request-aware validator when available.
+func (m *AuthMiddleware) validateAPIKey(r *http.Request, key string) (*APIKeyInfo, error) {
+	if v, ok := m.apiKeyValidator.(RequestAPIKeyValidator); ok {
+		return v.ValidateAPIKeyRequest(r.Context(), key, defaultKeyFunc(r))
+	}
+	return m.apiKeyValidator.ValidateAPIKey(key)
+}
+
 // extractBearerToken extracts the Bearer token from the Authorization header.
 func extractBearerToken(r *http.Request) string {
 	auth := r.Header.Get("Authorization")
@@ -262,12 +355,16 @@ func ContextGetTenantID(ctx context.Context) string {
 	return ""
 }
 
-// ContextGetUserID extracts the user ID from JWT claims.
-// Returns empty string if not authenticated via JWT.
+// ContextGetUserID extracts the user ID from JWT claims, or from the owner of
+// the API key used to authenticate.
+// Returns empty string if not authenticated.
 func ContextGetUserID(ctx context.Context) string {
 	if claims := ContextGetClaims(ctx); claims != nil {
 		return claims.UserID
 	}
+	if info := ContextGetAPIKeyInfo(ctx); info != nil {
+		return info.UserID
+	}
 	return ""
 }
 
@@ -286,6 +383,21 @@ func writeUnauthorized(w http.ResponseWriter, message string) {
 	w.Write([]byte(`{"error":{"code":"UNAUTHORIZED","message":"` + message + `"}}`))
 }
 
+// writeForbidden writes a 403 Forbidden JSON response.
+func writeForbidden(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte(`{"error":{"code":"FORBIDDEN","message":"` + message + `"}}`))
+}
+
+// writeQuotaExceeded writes a 429 response for an exhausted monthly API key
+// quota. No Retry-After is set; the quota resets at the start of next month.
+func writeQuotaExceeded(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(`{"error":{"code":"QUOTA_EXCEEDED","message":"monthly API key quota exhausted"}}`))
+}
+
 // Handler returns the primary authentication middleware handler function.
 // This is an alias for Authenticate() for router compatibility.
 func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
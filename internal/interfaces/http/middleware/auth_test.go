@@ -11,6 +11,7 @@
 //   - TestAuthenticate_NoCredentials: 无凭证返回 401
 //   - TestAuthenticate_SkipPaths: 白名单路径跳过认证
 //   - TestAuthenticate_SkipPaths_SubPath: 白名单子路径跳过认证
+//   - TestAuthenticate_RequiredPaths: 仅必需路径拒绝匿名请求
 //   - TestOptionalAuth_WithToken: 可选认证带 token
 //   - TestOptionalAuth_WithoutToken: 可选认证无 token 继续匿名
 //   - TestOptionalAuth_InvalidToken: 可选认证无效 token 继续匿名
@@ -281,6 +282,42 @@ func TestAuthenticate_SkipPaths_SubPath(t *testing.T) {
 	assert.True(t, called)
 }
 
+func TestAuthenticate_RequiredPaths(t *testing.T) {
+	tv := new(mockTokenValidator)
+	logger := new(mockMiddlewareLogger)
+	logger.On("Error", mock.Anything, mock.Anything).Maybe()
+	m := NewAuthMiddleware(tv, new(mockAPIKeyValidator), AuthConfig{
+		RequiredPaths: []string{"/api/v1/api-keys"},
+	}, logger)
+	tv.On("ValidateToken", "bad-token").Return(nil, fmt.Errorf("invalid signature"))
+
+	tests := []struct {
+		path   string
+		token  string
+		status int
+		called bool
+	}{
+		{path: "/api/v1/patents", status: http.StatusOK, called: true},
+		{path: "/api/v1/api-keys", status: http.StatusUnauthorized},
+		{path: "/api/v1/api-keys/key-1", status: http.StatusUnauthorized},
+		{path: "/api/v1/patents", token: "bad-token", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		called := false
+		handler := m.Authenticate()(testHandler(&called))
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", tt.path, nil)
+		if tt.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, tt.called, called, tt.path)
+		assert.Equal(t, tt.status, w.Code, tt.path)
+	}
+}
+
 // --- OptionalAuth Tests ---
 
 func TestOptionalAuth_WithToken(t *testing.T) {
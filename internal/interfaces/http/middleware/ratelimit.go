@@ -402,8 +402,12 @@ func DefaultTierLimits(tier UserTier) TierLimits {
 // It checks API key RateLimit field first, then JWT claims roles.
 // Returns TierUnset for anonymous/unauthenticated requests.
 func ContextGetUserTier(ctx context.Context) UserTier {
-	// Check API key info first (APIKeyInfo has an explicit RateLimit field)
+	// Check API key info first (explicit Tier, else the RateLimit field)
 	if info := ContextGetAPIKeyInfo(ctx); info != nil {
+		switch UserTier(info.Tier) {
+		case TierFree, TierProfessional, TierEnterprise:
+			return UserTier(info.Tier)
+		}
 		switch {
 		case info.RateLimit >= 1000:
 			return TierEnterprise
@@ -8,6 +8,7 @@
 //   - 使用 sync.Map 线程安全存储统计数据
 //   - 按 endpoint + method 统计请求数
 //   - 按用户/租户统计（从 JWT claims 或 API key 提取）
+//   - 按 API key 统计请求数
 //   - 响应大小统计（五级直方图: small/medium/large/xlarge/xxlarge）
 //   - 定期写入日志（每 5 分钟汇总并重置计数器）
 //   - 实现 Stop() 方法用于优雅关闭
@@ -20,6 +21,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"sort"
@@ -84,6 +86,30 @@ func (k userKey) String() string {
 	}
 }
 
+// usageIdentity receives the authenticated identity from AuthMiddleware.
+// The usage stats middleware runs before authentication, so it cannot read
+// the identity from its own request context; it places this holder in the
+// context instead and reads it once the request has been served.
+type usageIdentity struct {
+	UserID   string
+	TenantID string
+	APIKeyID string
+}
+
+// noteUsageIdentity copies the authenticated identity in ctx into the usage
+// holder, if the request passed through UsageStatsMiddleware.
+func noteUsageIdentity(ctx context.Context) {
+	id, ok := ctx.Value(usageIdentityContextKey).(*usageIdentity)
+	if !ok {
+		return
+	}
+	id.UserID = ContextGetUserID(ctx)
+	id.TenantID = ContextGetTenantID(ctx)
+	if info := ContextGetAPIKeyInfo(ctx); info != nil {
+		id.APIKeyID = info.KeyID
+	}
+}
+
 // SizeBucket represents a response size category for histogram tracking.
 type SizeBucket int
 
@@ -153,6 +179,8 @@ type UsageStatsMiddleware struct {
 	endpointBytes sync.Map
 	// userCounts tracks requests per (userID, tenantID): map[userKey]*int64
 	userCounts sync.Map
+	// apiKeyCounts tracks requests per API key ID: map[string]*int64
+	apiKeyCounts sync.Map
 	// sizeBuckets tracks response size distribution: map[SizeBucket]*int64
 	sizeBuckets sync.Map
 
@@ -216,6 +244,7 @@ func (m *UsageStatsMiddleware) flushLoop() {
 func (m *UsageStatsMiddleware) flush() {
 	m.flushEndpointStats()
 	m.flushUserStats()
+	m.flushAPIKeyStats()
 	m.flushSizeHistogram()
 }
 
@@ -317,6 +346,47 @@ func (m *UsageStatsMiddleware) flushUserStats() {
 	m.logger.Info("API usage stats - users", fields...)
 }
 
+// flushAPIKeyStats collects, logs, and resets per-API-key request counters.
+func (m *UsageStatsMiddleware) flushAPIKeyStats() {
+	if !m.config.EnableUserTracking {
+		return
+	}
+
+	type keyEntry struct {
+		keyID string
+		count int64
+	}
+
+	var entries []keyEntry
+	m.apiKeyCounts.Range(func(k, v interface{}) bool {
+		count := atomic.SwapInt64(v.(*int64), 0)
+		if count > 0 {
+			entries = append(entries, keyEntry{keyID: k.(string), count: count})
+		}
+		return true
+	})
+
+	if len(entries) == 0 {
+		return
+	}
+
+	// Sort by count descending.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].count > entries[j].count
+	})
+
+	fields := []logging.Field{
+		logging.Int("total_api_keys", len(entries)),
+	}
+	for _, e := range entries {
+		fields = append(fields,
+			logging.String("api_key", e.keyID),
+			logging.Int64("count", e.count),
+		)
+	}
+	m.logger.Info("API usage stats - api keys", fields...)
+}
+
 // flushSizeHistogram collects, logs, and resets response size bucket counters.
 func (m *UsageStatsMiddleware) flushSizeHistogram() {
 	type sizeEntry struct {
@@ -399,15 +469,12 @@ func (m *UsageStatsMiddleware) Handler(next http.Handler) http.Handler {
 		epKey := endpointKey{Method: r.Method, Endpoint: r.URL.Path}
 		incrementCounter(&m.endpointCounts, epKey)
 
-		// Track request by user/tenant from auth context.
-		if m.config.EnableUserTracking {
-			uid := ContextGetUserID(r.Context())
-			tid := ContextGetTenantID(r.Context())
-			if uid != "" || tid != "" {
-				uKey := userKey{UserID: uid, TenantID: tid}
-				incrementCounter(&m.userCounts, uKey)
-			}
-		}
+		// Collect the identity set by authentication further down the chain,
+		// seeded from the current context in case authentication already ran.
+		identity := &usageIdentity{}
+		ctx := context.WithValue(r.Context(), usageIdentityContextKey, identity)
+		noteUsageIdentity(ctx)
+		r = r.WithContext(ctx)
 
 		// Wrap response writer to capture bytes written.
 		wrapped := &usageStatsResponseWriter{ResponseWriter: w}
@@ -415,6 +482,17 @@ func (m *UsageStatsMiddleware) Handler(next http.Handler) http.Handler {
 		// Serve the request.
 		next.ServeHTTP(wrapped, r)
 
+		// Track request by user/tenant and API key from auth context.
+		if m.config.EnableUserTracking {
+			if identity.UserID != "" || identity.TenantID != "" {
+				uKey := userKey{UserID: identity.UserID, TenantID: identity.TenantID}
+				incrementCounter(&m.userCounts, uKey)
+			}
+			if identity.APIKeyID != "" {
+				incrementCounter(&m.apiKeyCounts, identity.APIKeyID)
+			}
+		}
+
 		// Record response size for the endpoint and histogram.
 		addCounter(&m.endpointBytes, epKey, wrapped.bytesWritten)
 		bucket := responseBucketFor(wrapped.bytesWritten)
@@ -35,6 +35,13 @@ type RouterConfig struct {
 	LifecycleHandler     *handlers.LifecycleHandler
 	AuthHandler          *handlers.AuthHandler
 	CollaborationHandler *handlers.CollaborationHandler
+	CommentHandler       *handlers.CommentHandler
+	SavedSearchHandler   *handlers.SavedSearchHandler
+	APIKeyHandler        *handlers.APIKeyHandler
+	DLQHandler           *handlers.DLQHandler
+	UsageHandler         *handlers.UsageHandler
+	AssigneeHandler      *handlers.AssigneeHandler
+	InventorHandler      *handlers.InventorHandler
 	ReportHandler        *handlers.ReportHandler
 	HealthHandler        *handlers.HealthHandler
 	AIHandler            *handlers.AIHandler
@@ -179,6 +186,27 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	if cfg.CollaborationHandler != nil {
 		cfg.CollaborationHandler.RegisterRoutes(mux)
 	}
+	if cfg.CommentHandler != nil {
+		cfg.CommentHandler.RegisterRoutes(mux)
+	}
+	if cfg.SavedSearchHandler != nil {
+		cfg.SavedSearchHandler.RegisterRoutes(mux)
+	}
+	if cfg.APIKeyHandler != nil {
+		cfg.APIKeyHandler.RegisterRoutes(mux)
+	}
+	if cfg.DLQHandler != nil {
+		cfg.DLQHandler.RegisterRoutes(mux)
+	}
+	if cfg.UsageHandler != nil {
+		cfg.UsageHandler.RegisterRoutes(mux)
+	}
+	if cfg.AssigneeHandler != nil {
+		cfg.AssigneeHandler.RegisterRoutes(mux)
+	}
+	if cfg.InventorHandler != nil {
+		cfg.InventorHandler.RegisterRoutes(mux)
+	}
 	if cfg.ReportHandler != nil {
 		cfg.ReportHandler.RegisterRoutes(mux)
 	}
@@ -0,0 +1,208 @@
+// SDK API Key Management Sub-Client
+// File: pkg/client/apikeys.go
+// API key lifecycle: issue, list, scope, rotate, revoke and usage.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DTOs — request / response
+// ---------------------------------------------------------------------------
+
+// APIKey describes an issued API key. The secret is never included.
+type APIKey struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name"`
+	KeyPrefix     string     `json:"key_prefix"`
+	Scopes        []string   `json:"scopes"`
+	RateLimit     int        `json:"rate_limit"`
+	Tier          string     `json:"tier"`
+	MonthlyQuota  int64      `json:"monthly_quota"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP    string     `json:"last_used_ip,omitempty"`
+	IsActive      bool       `json:"is_active"`
+	RotatedFromID string     `json:"rotated_from_id,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyRequest describes a key to issue. Scopes must be permissions
+// the caller holds; tiers above "free" need the api:rate_config permission.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	Tier          string   `json:"tier,omitempty"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// IssuedAPIKey is a newly created key together with its plaintext secret,
+// which the server returns only once.
+type IssuedAPIKey struct {
+	Key    APIKey `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// APIKeyUsage reports a key's request count for the current month.
+// Remaining is -1 for keys without a monthly quota.
+type APIKeyUsage struct {
+	KeyID     string    `json:"key_id"`
+	Period    time.Time `json:"period"`
+	Used      int64     `json:"used"`
+	Quota     int64     `json:"quota"`
+	Remaining int64     `json:"remaining"`
+	RateLimit int       `json:"rate_limit"`
+}
+
+type issuedAPIKeyResp struct {
+	Data IssuedAPIKey `json:"data"`
+}
+
+type apiKeyResp struct {
+	Data APIKey `json:"data"`
+}
+
+type apiKeyListResp struct {
+	Data struct {
+		APIKeys []APIKey `json:"api_keys"`
+	} `json:"data"`
+}
+
+type apiKeyScopesResp struct {
+	Data struct {
+		Scopes []string `json:"scopes"`
+	} `json:"data"`
+}
+
+type apiKeyUsageResp struct {
+	Data APIKeyUsage `json:"data"`
+}
+
+// ---------------------------------------------------------------------------
+// APIKeysClient
+// ---------------------------------------------------------------------------
+
+// APIKeysClient provides access to API key management endpoints.
+type APIKeysClient struct {
+	client *Client
+}
+
+// Create issues a new API key.
+// POST /api/v1/api-keys
+func (ac *APIKeysClient) Create(ctx context.Context, req *CreateAPIKeyRequest) (*IssuedAPIKey, error) {
+	if req == nil {
+		return nil, invalidArg("request is required")
+	}
+	if req.Name == "" {
+		return nil, invalidArg("name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, invalidArg("at least one scope is required")
+	}
+	var resp issuedAPIKeyResp
+	if err := ac.client.post(ctx, "/api/v1/api-keys", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// List returns the caller's API keys, including revoked and expired ones.
+// GET /api/v1/api-keys
+func (ac *APIKeysClient) List(ctx context.Context) ([]APIKey, error) {
+	var resp apiKeyListResp
+	if err := ac.client.get(ctx, "/api/v1/api-keys", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.APIKeys == nil {
+		return []APIKey{}, nil
+	}
+	return resp.Data.APIKeys, nil
+}
+
+// Scopes lists the scopes that can be granted to API keys.
+// GET /api/v1/api-keys/scopes
+func (ac *APIKeysClient) Scopes(ctx context.Context) ([]string, error) {
+	var resp apiKeyScopesResp
+	if err := ac.client.get(ctx, "/api/v1/api-keys/scopes", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Scopes, nil
+}
+
+// Get retrieves a single API key.
+// GET /api/v1/api-keys/{keyID}
+func (ac *APIKeysClient) Get(ctx context.Context, keyID string) (*APIKey, error) {
+	if keyID == "" {
+		return nil, invalidArg("keyID is required")
+	}
+	var resp apiKeyResp
+	if err := ac.client.get(ctx, "/api/v1/api-keys/"+url.PathEscape(keyID), &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// UpdateScopes replaces the scopes of a key.
+// PUT /api/v1/api-keys/{keyID}/scopes
+func (ac *APIKeysClient) UpdateScopes(ctx context.Context, keyID string, scopes []string) (*APIKey, error) {
+	if keyID == "" {
+		return nil, invalidArg("keyID is required")
+	}
+	if len(scopes) == 0 {
+		return nil, invalidArg("at least one scope is required")
+	}
+	body := map[string][]string{"scopes": scopes}
+	var resp apiKeyResp
+	if err := ac.client.put(ctx, "/api/v1/api-keys/"+url.PathEscape(keyID)+"/scopes", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Rotate replaces a key with a new secret. The old key keeps working for
+// gracePeriod (rounded down to whole hours); zero revokes it immediately.
+// POST /api/v1/api-keys/{keyID}/rotate
+func (ac *APIKeysClient) Rotate(ctx context.Context, keyID string, gracePeriod time.Duration) (*IssuedAPIKey, error) {
+	if keyID == "" {
+		return nil, invalidArg("keyID is required")
+	}
+	if gracePeriod < 0 {
+		return nil, invalidArg("gracePeriod cannot be negative")
+	}
+	body := map[string]int{"grace_period_hours": int(gracePeriod / time.Hour)}
+	var resp issuedAPIKeyResp
+	if err := ac.client.post(ctx, "/api/v1/api-keys/"+url.PathEscape(keyID)+"/rotate", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Revoke permanently disables a key.
+// DELETE /api/v1/api-keys/{keyID}
+func (ac *APIKeysClient) Revoke(ctx context.Context, keyID string) error {
+	if keyID == "" {
+		return invalidArg("keyID is required")
+	}
+	return ac.client.delete(ctx, "/api/v1/api-keys/"+url.PathEscape(keyID))
+}
+
+// Usage reports a key's request count and remaining quota this month.
+// GET /api/v1/api-keys/{keyID}/usage
+func (ac *APIKeysClient) Usage(ctx context.Context, keyID string) (*APIKeyUsage, error) {
+	if keyID == "" {
+		return nil, invalidArg("keyID is required")
+	}
+	var resp apiKeyUsageResp
+	if err := ac.client.get(ctx, "/api/v1/api-keys/"+url.PathEscape(keyID)+"/usage", &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+//Personal.AI order the ending
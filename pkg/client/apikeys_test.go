@@ -0,0 +1,128 @@
+// SDK API Key Management Sub-Client Test
+// File: pkg/client/apikeys_test.go
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	kerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func newTestAPIKeysClient(t *testing.T, handler http.HandlerFunc) *APIKeysClient {
+	t.Helper()
+	return newTestLifecycleClient(t, handler).client.APIKeys()
+}
+
+func TestAPIKeysCreate_Success(t *testing.T) {
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/api-keys" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body := lcReadBody(t, r)
+		if body["name"] != "ci" || body["tier"] != "professional" {
+			t.Errorf("unexpected body %v", body)
+		}
+		lcWriteJSON(t, w, http.StatusCreated, map[string]interface{}{
+			"data": map[string]interface{}{
+				"key":    map[string]interface{}{"id": "k-1", "name": "ci", "tier": "professional"},
+				"secret": "kip_secret",
+			},
+		})
+	})
+
+	issued, err := ac.Create(context.Background(), &CreateAPIKeyRequest{
+		Name:   "ci",
+		Scopes: []string{"patent:read"},
+		Tier:   "professional",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issued.Secret != "kip_secret" || issued.Key.ID != "k-1" {
+		t.Errorf("unexpected result %+v", issued)
+	}
+}
+
+func TestAPIKeysCreate_Validation(t *testing.T) {
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	})
+	for _, req := range []*CreateAPIKeyRequest{nil, {Scopes: []string{"patent:read"}}, {Name: "ci"}} {
+		if _, err := ac.Create(context.Background(), req); !errors.Is(err, kerrors.ErrInvalidArgument) {
+			t.Errorf("expected ErrInvalidArgument, got %v", err)
+		}
+	}
+}
+
+func TestAPIKeysList_Empty(t *testing.T) {
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+	})
+	keys, err := ac.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys == nil || len(keys) != 0 {
+		t.Errorf("expected empty non-nil slice, got %v", keys)
+	}
+}
+
+func TestAPIKeysRotate_GracePeriod(t *testing.T) {
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/api-keys/k-1/rotate" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if body := lcReadBody(t, r); body["grace_period_hours"] != float64(24) {
+			t.Errorf("unexpected body %v", body)
+		}
+		lcWriteJSON(t, w, http.StatusCreated, map[string]interface{}{
+			"data": map[string]interface{}{"key": map[string]interface{}{"id": "k-2", "rotated_from_id": "k-1"}, "secret": "kip_new"},
+		})
+	})
+	issued, err := ac.Rotate(context.Background(), "k-1", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issued.Key.RotatedFromID != "k-1" {
+		t.Errorf("unexpected result %+v", issued)
+	}
+}
+
+func TestAPIKeysRevoke(t *testing.T) {
+	var method, path string
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{"data": map[string]bool{"success": true}})
+	})
+	if err := ac.Revoke(context.Background(), "k-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodDelete || path != "/api/v1/api-keys/k-1" {
+		t.Errorf("unexpected request %s %s", method, path)
+	}
+	if err := ac.Revoke(context.Background(), ""); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestAPIKeysUsage(t *testing.T) {
+	ac := newTestAPIKeysClient(t, func(w http.ResponseWriter, r *http.Request) {
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{"key_id": "k-1", "used": 7, "quota": 10, "remaining": 3},
+		})
+	})
+	usage, err := ac.Usage(context.Background(), "k-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if usage.Used != 7 || usage.Remaining != 3 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+}
+
+//Personal.AI order the ending
@@ -132,6 +132,15 @@ type Client struct {
 	lifecycleOnce sync.Once
 	lifecycle     *LifecycleClient
 
+	apiKeysOnce sync.Once
+	apiKeys     *APIKeysClient
+
+	dlqOnce sync.Once
+	dlq     *DLQClient
+
+	usageOnce sync.Once
+	usage     *UsageClient
+
 	// --- fields driven by options.go ---
 	baseHeaders map[string]string
 	rateLimiter *internalRateLimiter
@@ -202,6 +211,30 @@ func (c *Client) Lifecycle() *LifecycleClient {
 }
 
 
+// APIKeys returns the API key management sub-client.
+func (c *Client) APIKeys() *APIKeysClient {
+	c.apiKeysOnce.Do(func() {
+		c.apiKeys = &APIKeysClient{client: c}
+	})
+	return c.apiKeys
+}
+
+// DLQ returns the dead letter queue admin sub-client.
+func (c *Client) DLQ() *DLQClient {
+	c.dlqOnce.Do(func() {
+		c.dlq = &DLQClient{client: c}
+	})
+	return c.dlq
+}
+
+// Usage returns the LLM usage and budget sub-client.
+func (c *Client) Usage() *UsageClient {
+	c.usageOnce.Do(func() {
+		c.usage = &UsageClient{client: c}
+	})
+	return c.usage
+}
+
 // Close releases resources held by the Client (e.g. rate limiter goroutine).
 // It is safe to call Close multiple times.
 func (c *Client) Close() error {
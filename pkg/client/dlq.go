@@ -0,0 +1,206 @@
+// SDK Dead Letter Queue Admin Sub-Client
+// File: pkg/client/dlq.go
+// Dead letter topics: list backlog, inspect failures, replay and purge.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DTOs — request / response
+// ---------------------------------------------------------------------------
+
+// DLQTopic summarises a dead letter topic. Total counts messages the broker
+// still retains; Pending those not yet replayed or purged.
+type DLQTopic struct {
+	Topic         string `json:"topic"`
+	OriginalTopic string `json:"original_topic,omitempty"`
+	Partitions    int    `json:"partitions"`
+	Total         int64  `json:"total"`
+	Pending       int64  `json:"pending"`
+}
+
+// DeadLetter is a message that failed processing, with the recorded
+// failure reason.
+type DeadLetter struct {
+	Topic         string            `json:"topic"`
+	Partition     int               `json:"partition"`
+	Offset        int64             `json:"offset"`
+	Key           string            `json:"key,omitempty"`
+	OriginalTopic string            `json:"original_topic"`
+	EventType     string            `json:"event_type,omitempty"`
+	ErrorClass    string            `json:"error_class,omitempty"`
+	ErrorMessage  string            `json:"error_message,omitempty"`
+	Attempts      int               `json:"attempts,omitempty"`
+	ReplayCount   int               `json:"replay_count"`
+	FailedAt      time.Time         `json:"failed_at"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Payload       string            `json:"payload"`
+}
+
+// DLQFilter selects dead letters. Empty fields match everything; Since is
+// inclusive and Until exclusive, both compared with the failure time.
+type DLQFilter struct {
+	EventTypes   []string
+	ErrorClasses []string
+	Since        time.Time
+	Until        time.Time
+}
+
+// ReplayDLQRequest describes a replay. RatePerSecond 0 uses the server
+// default; Limit 0 replays every matching message.
+type ReplayDLQRequest struct {
+	EventTypes    []string   `json:"event_types,omitempty"`
+	ErrorClasses  []string   `json:"error_classes,omitempty"`
+	Since         *time.Time `json:"since,omitempty"`
+	Until         *time.Time `json:"until,omitempty"`
+	RatePerSecond float64    `json:"rate_per_second,omitempty"`
+	Limit         int        `json:"limit,omitempty"`
+	DryRun        bool       `json:"dry_run,omitempty"`
+}
+
+// ReplayDLQResult reports a replay. Resolved counts messages that are no
+// longer pending.
+type ReplayDLQResult struct {
+	Topic    string `json:"topic"`
+	Scanned  int    `json:"scanned"`
+	Matched  int    `json:"matched"`
+	Replayed int    `json:"replayed"`
+	Resolved int64  `json:"resolved"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+// PurgeDLQResult reports a purge.
+type PurgeDLQResult struct {
+	Topic  string `json:"topic"`
+	Purged int64  `json:"purged"`
+}
+
+type dlqTopicsResp struct {
+	Data struct {
+		Topics []DLQTopic `json:"topics"`
+	} `json:"data"`
+}
+
+type dlqMessagesResp struct {
+	Data struct {
+		Messages []DeadLetter `json:"messages"`
+	} `json:"data"`
+}
+
+type replayDLQResp struct {
+	Data ReplayDLQResult `json:"data"`
+}
+
+type purgeDLQResp struct {
+	Data PurgeDLQResult `json:"data"`
+}
+
+// ---------------------------------------------------------------------------
+// DLQClient
+// ---------------------------------------------------------------------------
+
+// DLQClient provides access to the dead letter queue admin endpoints. Reads
+// need the system:monitor permission, replay and purge system:config.
+type DLQClient struct {
+	client *Client
+}
+
+// List returns the dead letter topics with their backlog.
+// GET /api/v1/admin/dlq
+func (dc *DLQClient) List(ctx context.Context) ([]DLQTopic, error) {
+	var resp dlqTopicsResp
+	if err := dc.client.get(ctx, "/api/v1/admin/dlq", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Topics == nil {
+		return []DLQTopic{}, nil
+	}
+	return resp.Data.Topics, nil
+}
+
+// Inspect returns up to limit pending dead letters of topic matching
+// filter; limit 0 uses the server default.
+// GET /api/v1/admin/dlq/{topic}/messages
+func (dc *DLQClient) Inspect(ctx context.Context, topic string, filter DLQFilter, limit int) ([]DeadLetter, error) {
+	if topic == "" {
+		return nil, invalidArg("topic is required")
+	}
+	if limit < 0 {
+		return nil, invalidArg("limit cannot be negative")
+	}
+	q := url.Values{}
+	if len(filter.EventTypes) > 0 {
+		q.Set("event_type", strings.Join(filter.EventTypes, ","))
+	}
+	if len(filter.ErrorClasses) > 0 {
+		q.Set("error_class", strings.Join(filter.ErrorClasses, ","))
+	}
+	if !filter.Since.IsZero() {
+		q.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if !filter.Until.IsZero() {
+		q.Set("until", filter.Until.Format(time.RFC3339))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/api/v1/admin/dlq/" + url.PathEscape(topic) + "/messages"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var resp dlqMessagesResp
+	if err := dc.client.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Messages == nil {
+		return []DeadLetter{}, nil
+	}
+	return resp.Data.Messages, nil
+}
+
+// Replay re-publishes matching dead letters of topic to their original
+// topic. A nil req replays everything pending.
+// POST /api/v1/admin/dlq/{topic}/replay
+func (dc *DLQClient) Replay(ctx context.Context, topic string, req *ReplayDLQRequest) (*ReplayDLQResult, error) {
+	if topic == "" {
+		return nil, invalidArg("topic is required")
+	}
+	if req == nil {
+		req = &ReplayDLQRequest{}
+	}
+	if req.RatePerSecond < 0 || req.Limit < 0 {
+		return nil, invalidArg("rate and limit cannot be negative")
+	}
+	var resp replayDLQResp
+	if err := dc.client.post(ctx, "/api/v1/admin/dlq/"+url.PathEscape(topic)+"/replay", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Purge discards the pending dead letters of topic. A non-zero before
+// limits the purge to messages that failed earlier.
+// DELETE /api/v1/admin/dlq/{topic}/messages
+func (dc *DLQClient) Purge(ctx context.Context, topic string, before time.Time) (*PurgeDLQResult, error) {
+	if topic == "" {
+		return nil, invalidArg("topic is required")
+	}
+	path := "/api/v1/admin/dlq/" + url.PathEscape(topic) + "/messages"
+	if !before.IsZero() {
+		path += "?" + url.Values{"before": {before.Format(time.RFC3339)}}.Encode()
+	}
+	var resp purgeDLQResp
+	if err := dc.client.do(ctx, http.MethodDelete, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
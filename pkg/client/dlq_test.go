@@ -0,0 +1,128 @@
+// SDK Dead Letter Queue Admin Sub-Client Test
+// File: pkg/client/dlq_test.go
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	kerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func newTestDLQClient(t *testing.T, handler http.HandlerFunc) *DLQClient {
+	t.Helper()
+	return newTestLifecycleClient(t, handler).client.DLQ()
+}
+
+func TestDLQList_Success(t *testing.T) {
+	dc := newTestDLQClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/admin/dlq" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"topics": []map[string]interface{}{{"topic": "patent.new.dlq", "total": 5, "pending": 2}},
+			},
+		})
+	})
+
+	topics, err := dc.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(topics) != 1 || topics[0].Pending != 2 {
+		t.Errorf("unexpected topics %+v", topics)
+	}
+}
+
+func TestDLQInspect_Query(t *testing.T) {
+	dc := newTestDLQClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/dlq/patent.new.dlq/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("event_type") != "patent.created,patent.updated" || q.Get("error_class") != "timeout" ||
+			q.Get("since") != "2026-03-01T00:00:00Z" || q.Get("until") != "" || q.Get("limit") != "5" {
+			t.Errorf("unexpected query %v", q)
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"messages": []map[string]interface{}{{"offset": 7, "error_class": "timeout"}},
+			},
+		})
+	})
+
+	letters, err := dc.Inspect(context.Background(), "patent.new.dlq", DLQFilter{
+		EventTypes:   []string{"patent.created", "patent.updated"},
+		ErrorClasses: []string{"timeout"},
+		Since:        time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(letters) != 1 || letters[0].Offset != 7 {
+		t.Errorf("unexpected letters %+v", letters)
+	}
+}
+
+func TestDLQReplay_Success(t *testing.T) {
+	dc := newTestDLQClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/admin/dlq/patent.new.dlq/replay" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		body := lcReadBody(t, r)
+		if body["rate_per_second"] != 10.0 || body["dry_run"] != true {
+			t.Errorf("unexpected body %v", body)
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{"topic": "patent.new.dlq", "replayed": 3, "dry_run": true},
+		})
+	})
+
+	res, err := dc.Replay(context.Background(), "patent.new.dlq", &ReplayDLQRequest{RatePerSecond: 10, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Replayed != 3 || !res.DryRun {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestDLQPurge_Before(t *testing.T) {
+	dc := newTestDLQClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Query().Get("before") != "2026-01-01T00:00:00Z" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{"topic": "patent.new.dlq", "purged": 12},
+		})
+	})
+
+	res, err := dc.Purge(context.Background(), "patent.new.dlq", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Purged != 12 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestDLQ_Validation(t *testing.T) {
+	dc := newTestDLQClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request expected")
+	})
+	ctx := context.Background()
+	if _, err := dc.Inspect(ctx, "", DLQFilter{}, 0); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := dc.Replay(ctx, "patent.new.dlq", &ReplayDLQRequest{Limit: -1}); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := dc.Purge(ctx, "", time.Time{}); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+// File: pkg/client/grpc_services.go
+// gRPC 服务存根：在 GRPCClient 连接之上暴露 PortfolioService、LifecycleService、
+// MoleculeService、ReportService 的类型化客户端，并为流式 RPC 提供便捷封装，供批处理管道使用。
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+)
+
+// ---------------------------------------------------------------------------
+// 类型化服务客户端
+// ---------------------------------------------------------------------------
+
+// Portfolios 返回基于当前连接的专利组合 gRPC 客户端。
+func (c *GRPCClient) Portfolios() pb.PortfolioServiceClient {
+	return pb.NewPortfolioServiceClient(c.conn)
+}
+
+// Lifecycle 返回基于当前连接的生命周期（年费、期限）gRPC 客户端。
+func (c *GRPCClient) Lifecycle() pb.LifecycleServiceClient {
+	return pb.NewLifecycleServiceClient(c.conn)
+}
+
+// Molecules 返回基于当前连接的分子检索 gRPC 客户端。
+func (c *GRPCClient) Molecules() pb.MoleculeServiceClient {
+	return pb.NewMoleculeServiceClient(c.conn)
+}
+
+// Reports 返回基于当前连接的报告生成 gRPC 客户端。
+func (c *GRPCClient) Reports() pb.ReportServiceClient {
+	return pb.NewReportServiceClient(c.conn)
+}
+
+// ---------------------------------------------------------------------------
+// 流式 RPC 便捷封装
+// ---------------------------------------------------------------------------
+
+// RecordPayments 通过客户端流批量登记年费缴纳记录。
+//
+// 服务端逐条处理：单条失败不会中断整个批次，失败明细见响应中的 Errors，
+// 其 Index 对应 payments 中的下标。
+func (c *GRPCClient) RecordPayments(ctx context.Context, payments []*pb.RecordPaymentRequest) (*pb.RecordPaymentsResponse, error) {
+	stream, err := c.Lifecycle().RecordPayments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("打开 RecordPayments 流失败: %w", err)
+	}
+	for i, p := range payments {
+		if err := stream.Send(p); err != nil {
+			// Send 返回 io.EOF 时，真实错误需通过 CloseAndRecv 获取。
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("发送第 %d 条缴费记录失败: %w", i, err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("RecordPayments 失败: %w", err)
+	}
+	c.logger.Debugf("RecordPayments: received=%d recorded=%d failed=%d",
+		resp.ReceivedCount, resp.RecordedCount, len(resp.Errors))
+	return resp, nil
+}
+
+// WatchReportProgress 订阅报告生成进度，每收到一次进度变化回调 fn。
+//
+// 报告到达 Completed/Failed 终态后服务端关闭流，函数返回最后一次进度；
+// fn 返回错误或 ctx 取消时提前结束。
+func (c *GRPCClient) WatchReportProgress(
+	ctx context.Context,
+	req *pb.WatchReportProgressRequest,
+	fn func(*pb.ReportProgress) error,
+) (*pb.ReportProgress, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.Reports().WatchReportProgress(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("订阅报告进度失败: %w", err)
+	}
+
+	var last *pb.ReportProgress
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return last, fmt.Errorf("接收报告进度失败: %w", err)
+		}
+		last = p
+		if fn != nil {
+			if err := fn(p); err != nil {
+				return last, err
+			}
+		}
+	}
+}
+
+// DownloadReport 以服务端流下载报告文件并写入 w，返回写入字节数与内容类型。
+func (c *GRPCClient) DownloadReport(
+	ctx context.Context,
+	req *pb.DownloadReportRequest,
+	w io.Writer,
+) (int64, string, error) {
+	stream, err := c.Reports().DownloadReport(ctx, req)
+	if err != nil {
+		return 0, "", fmt.Errorf("打开报告下载流失败: %w", err)
+	}
+
+	var (
+		written     int64
+		contentType string
+	)
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return written, contentType, nil
+		}
+		if err != nil {
+			return written, contentType, fmt.Errorf("接收报告分块失败: %w", err)
+		}
+		if chunk.Offset != written {
+			return written, contentType, fmt.Errorf("报告分块偏移不连续: 期望 %d，实际 %d", written, chunk.Offset)
+		}
+		if chunk.ContentType != "" {
+			contentType = chunk.ContentType
+		}
+		n, err := w.Write(chunk.Data)
+		written += int64(n)
+		if err != nil {
+			return written, contentType, fmt.Errorf("写入报告数据失败: %w", err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// File: pkg/client/grpc_services_test.go
+// Unit tests for the typed gRPC service stubs, served over an in-memory
+// bufconn listener with a JSON codec for the stub proto types.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/turtacn/KeyIP-Intelligence/api/proto/v1"
+)
+
+// ---------------------------------------------------------------------------
+// Helpers
+// ---------------------------------------------------------------------------
+
+type stubJSONCodec struct{}
+
+func (stubJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (stubJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (stubJSONCodec) Name() string                               { return "json-stub-client-test" }
+
+var registerStubCodec sync.Once
+
+type fakeLifecycleServer struct {
+	pb.UnimplementedLifecycleServiceServer
+}
+
+func (fakeLifecycleServer) RecordPayments(stream pb.LifecycleService_RecordPaymentsServer) error {
+	resp := &pb.RecordPaymentsResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		resp.ReceivedCount++
+		resp.RecordedCount++
+		resp.Records = append(resp.Records, &pb.PaymentRecord{PatentId: req.PatentId})
+	}
+}
+
+type fakeReportServer struct {
+	pb.UnimplementedReportServiceServer
+	payload []byte
+}
+
+func (fakeReportServer) WatchReportProgress(req *pb.WatchReportProgressRequest, stream pb.ReportService_WatchReportProgressServer) error {
+	for _, st := range []string{"Queued", "Processing", "Completed"} {
+		if err := stream.Send(&pb.ReportProgress{ReportId: req.ReportId, Status: st}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f fakeReportServer) DownloadReport(_ *pb.DownloadReportRequest, stream pb.ReportService_DownloadReportServer) error {
+	const chunk = 4
+	for off := 0; off < len(f.payload); off += chunk {
+		end := off + chunk
+		if end > len(f.payload) {
+			end = len(f.payload)
+		}
+		c := &pb.ReportChunk{Data: f.payload[off:end], Offset: int64(off)}
+		if off == 0 {
+			c.ContentType = "application/pdf"
+		}
+		if err := stream.Send(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestGRPCClient(t *testing.T, payload []byte) *GRPCClient {
+	t.Helper()
+	registerStubCodec.Do(func() { encoding.RegisterCodec(stubJSONCodec{}) })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterLifecycleServiceServer(srv, fakeLifecycleServer{})
+	pb.RegisterReportServiceServer(srv, fakeReportServer{payload: payload})
+	go func() { _ = srv.Serve(lis) }()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(stubJSONCodec{}.Name())),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+	return &GRPCClient{conn: conn, logger: noopLogger{}}
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+func TestGRPCClient_RecordPayments(t *testing.T) {
+	c := newTestGRPCClient(t, nil)
+	resp, err := c.RecordPayments(context.Background(), []*pb.RecordPaymentRequest{
+		{PatentId: "p1"}, {PatentId: "p2"},
+	})
+	if err != nil {
+		t.Fatalf("RecordPayments: %v", err)
+	}
+	if resp.ReceivedCount != 2 || len(resp.Records) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestGRPCClient_WatchReportProgress(t *testing.T) {
+	c := newTestGRPCClient(t, nil)
+
+	var seen []string
+	last, err := c.WatchReportProgress(context.Background(), &pb.WatchReportProgressRequest{ReportId: "r1"},
+		func(p *pb.ReportProgress) error {
+			seen = append(seen, p.Status)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("WatchReportProgress: %v", err)
+	}
+	if len(seen) != 3 || last.Status != "Completed" {
+		t.Fatalf("seen=%v last=%+v", seen, last)
+	}
+}
+
+func TestGRPCClient_WatchReportProgress_CallbackStops(t *testing.T) {
+	c := newTestGRPCClient(t, nil)
+	stop := errors.New("stop")
+
+	last, err := c.WatchReportProgress(context.Background(), &pb.WatchReportProgressRequest{ReportId: "r1"},
+		func(*pb.ReportProgress) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Fatalf("err = %v, want stop", err)
+	}
+	if last.Status != "Queued" {
+		t.Fatalf("last = %+v", last)
+	}
+}
+
+func TestGRPCClient_DownloadReport(t *testing.T) {
+	payload := []byte("%PDF-1.7 report body")
+	c := newTestGRPCClient(t, payload)
+
+	var buf bytes.Buffer
+	n, contentType, err := c.DownloadReport(context.Background(), &pb.DownloadReportRequest{ReportId: "r1"}, &buf)
+	if err != nil {
+		t.Fatalf("DownloadReport: %v", err)
+	}
+	if n != int64(len(payload)) || !bytes.Equal(buf.Bytes(), payload) {
+		t.Fatalf("got %d bytes %q", n, buf.String())
+	}
+	if contentType != "application/pdf" {
+		t.Fatalf("contentType = %q", contentType)
+	}
+}
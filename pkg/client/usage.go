@@ -0,0 +1,191 @@
+// SDK LLM Usage Sub-Client
+// File: pkg/client/usage.go
+// LLM token and cost reports, monthly budgets and the model price table.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// ---------------------------------------------------------------------------
+// DTOs — request / response
+// ---------------------------------------------------------------------------
+
+// UsageQuery selects LLM usage to report. A zero range covers the current
+// month; an empty TenantID the caller's tenant and "*" every tenant.
+// GroupBy is one of tenant, user, feature (default), model or day.
+type UsageQuery struct {
+	TenantID string
+	UserID   string
+	Feature  string
+	From     time.Time
+	To       time.Time
+	GroupBy  string
+}
+
+// UsageLine aggregates the LLM calls sharing one group key.
+// EstimatedRequests counts calls whose tokens the provider did not report.
+type UsageLine struct {
+	Key               string  `json:"key"`
+	Requests          int64   `json:"requests"`
+	EstimatedRequests int64   `json:"estimated_requests"`
+	PromptTokens      int64   `json:"prompt_tokens"`
+	CompletionTokens  int64   `json:"completion_tokens"`
+	CostUSD           float64 `json:"cost_usd"`
+}
+
+// UsageSummary is a grouped LLM usage report.
+type UsageSummary struct {
+	TenantID string      `json:"tenant_id,omitempty"`
+	From     time.Time   `json:"from"`
+	To       time.Time   `json:"to"`
+	GroupBy  string      `json:"group_by"`
+	Lines    []UsageLine `json:"lines"`
+	Total    UsageLine   `json:"total"`
+}
+
+// BudgetStatus reports a tenant's LLM spend against its monthly budget.
+// Level is "ok", "soft" or "hard"; RemainingUSD is -1 without a hard limit.
+type BudgetStatus struct {
+	TenantID     string    `json:"tenant_id"`
+	Period       time.Time `json:"period"`
+	SpentUSD     float64   `json:"spent_usd"`
+	SoftLimitUSD float64   `json:"soft_limit_usd"`
+	HardLimitUSD float64   `json:"hard_limit_usd"`
+	RemainingUSD float64   `json:"remaining_usd"`
+	Level        string    `json:"level"`
+	Source       string    `json:"source"`
+}
+
+// SetBudgetRequest sets a tenant's monthly budget in USD; zero is unlimited.
+type SetBudgetRequest struct {
+	SoftLimitUSD float64 `json:"soft_limit_usd"`
+	HardLimitUSD float64 `json:"hard_limit_usd"`
+}
+
+// ModelPrice is a model's price in USD per 1K tokens.
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+type usageSummaryResp struct {
+	Data UsageSummary `json:"data"`
+}
+
+type budgetStatusResp struct {
+	Data BudgetStatus `json:"data"`
+}
+
+type modelPricesResp struct {
+	Data struct {
+		Prices map[string]ModelPrice `json:"prices"`
+	} `json:"data"`
+}
+
+// ---------------------------------------------------------------------------
+// UsageClient
+// ---------------------------------------------------------------------------
+
+// UsageClient provides access to the LLM usage endpoints. Reading the
+// caller's own tenant needs tenant:read; other tenants and setting budgets
+// need system:config.
+type UsageClient struct {
+	client *Client
+}
+
+// Summary returns LLM usage grouped by q.GroupBy. A nil q reports the
+// caller's tenant for the current month by feature.
+// GET /api/v1/usage
+func (uc *UsageClient) Summary(ctx context.Context, q *UsageQuery) (*UsageSummary, error) {
+	if q == nil {
+		q = &UsageQuery{}
+	}
+	if q.From.IsZero() != q.To.IsZero() {
+		return nil, invalidArg("from and to must be given together")
+	}
+	if !q.From.IsZero() && !q.To.After(q.From) {
+		return nil, invalidArg("to must be after from")
+	}
+	v := url.Values{}
+	for key, val := range map[string]string{
+		"tenant_id": q.TenantID, "user_id": q.UserID, "feature": q.Feature, "group_by": q.GroupBy,
+	} {
+		if val != "" {
+			v.Set(key, val)
+		}
+	}
+	if !q.From.IsZero() {
+		v.Set("from", q.From.Format(time.RFC3339))
+		v.Set("to", q.To.Format(time.RFC3339))
+	}
+	path := "/api/v1/usage"
+	if len(v) > 0 {
+		path += "?" + v.Encode()
+	}
+
+	var resp usageSummaryResp
+	if err := uc.client.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Lines == nil {
+		resp.Data.Lines = []UsageLine{}
+	}
+	return &resp.Data, nil
+}
+
+// Budget returns the budget status of tenantID, or of the caller's tenant
+// when empty.
+// GET /api/v1/usage/budget
+func (uc *UsageClient) Budget(ctx context.Context, tenantID string) (*BudgetStatus, error) {
+	var resp budgetStatusResp
+	if err := uc.client.get(ctx, budgetPath(tenantID), &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// SetBudget sets the monthly budget of tenantID, or of the caller's tenant
+// when empty.
+// PUT /api/v1/usage/budget
+func (uc *UsageClient) SetBudget(ctx context.Context, tenantID string, req *SetBudgetRequest) (*BudgetStatus, error) {
+	if req == nil {
+		return nil, invalidArg("request is required")
+	}
+	if req.SoftLimitUSD < 0 || req.HardLimitUSD < 0 {
+		return nil, invalidArg("limits cannot be negative")
+	}
+	if req.HardLimitUSD > 0 && req.SoftLimitUSD > req.HardLimitUSD {
+		return nil, invalidArg("soft limit cannot exceed the hard limit")
+	}
+	var resp budgetStatusResp
+	if err := uc.client.put(ctx, budgetPath(tenantID), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+// Prices returns the configured model price table.
+// GET /api/v1/usage/prices
+func (uc *UsageClient) Prices(ctx context.Context) (map[string]ModelPrice, error) {
+	var resp modelPricesResp
+	if err := uc.client.get(ctx, "/api/v1/usage/prices", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Prices == nil {
+		return map[string]ModelPrice{}, nil
+	}
+	return resp.Data.Prices, nil
+}
+
+func budgetPath(tenantID string) string {
+	if tenantID == "" {
+		return "/api/v1/usage/budget"
+	}
+	return "/api/v1/usage/budget?" + url.Values{"tenant_id": {tenantID}}.Encode()
+}
+
+//Personal.AI order the ending
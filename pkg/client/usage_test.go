@@ -0,0 +1,137 @@
+// SDK LLM Usage Sub-Client Test
+// File: pkg/client/usage_test.go
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	kerrors "github.com/turtacn/KeyIP-Intelligence/pkg/errors"
+)
+
+func newTestUsageClient(t *testing.T, handler http.HandlerFunc) *UsageClient {
+	t.Helper()
+	return newTestLifecycleClient(t, handler).client.Usage()
+}
+
+func TestUsageSummary_Query(t *testing.T) {
+	uc := newTestUsageClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/usage" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("tenant_id") != "acme" || q.Get("group_by") != "model" || q.Get("user_id") != "" ||
+			q.Get("from") != "2026-09-01T00:00:00Z" || q.Get("to") != "2026-10-01T00:00:00Z" {
+			t.Errorf("unexpected query %v", q)
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"group_by": "model",
+				"lines":    []map[string]interface{}{{"key": "claude-sonnet-4", "requests": 3, "cost_usd": 0.42}},
+				"total":    map[string]interface{}{"requests": 3, "cost_usd": 0.42},
+			},
+		})
+	})
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	sum, err := uc.Summary(context.Background(), &UsageQuery{TenantID: "acme", GroupBy: "model", From: from, To: from.AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sum.Lines) != 1 || sum.Lines[0].Key != "claude-sonnet-4" || sum.Total.CostUSD != 0.42 {
+		t.Errorf("unexpected summary %+v", sum)
+	}
+}
+
+func TestUsageSummary_Defaults(t *testing.T) {
+	uc := newTestUsageClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"group_by": "feature"}})
+	})
+
+	sum, err := uc.Summary(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.Lines == nil || sum.GroupBy != "feature" {
+		t.Errorf("unexpected summary %+v", sum)
+	}
+}
+
+func TestUsageBudget(t *testing.T) {
+	uc := newTestUsageClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/usage/budget" || r.URL.Query().Get("tenant_id") != "globex" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		status := map[string]interface{}{"tenant_id": "globex", "spent_usd": 12.5, "hard_limit_usd": 500, "remaining_usd": 487.5, "level": "ok"}
+		if r.Method == http.MethodPut {
+			body := lcReadBody(t, r)
+			if body["soft_limit_usd"] != 400.0 || body["hard_limit_usd"] != 500.0 {
+				t.Errorf("unexpected body %v", body)
+			}
+			status["source"] = "tenant"
+		}
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{"data": status})
+	})
+
+	ctx := context.Background()
+	st, err := uc.Budget(ctx, "globex")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.RemainingUSD != 487.5 || st.Level != "ok" {
+		t.Errorf("unexpected status %+v", st)
+	}
+	st, err = uc.SetBudget(ctx, "globex", &SetBudgetRequest{SoftLimitUSD: 400, HardLimitUSD: 500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Source != "tenant" {
+		t.Errorf("unexpected status %+v", st)
+	}
+}
+
+func TestUsagePrices(t *testing.T) {
+	uc := newTestUsageClient(t, func(w http.ResponseWriter, r *http.Request) {
+		lcWriteJSON(t, w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"prices": map[string]interface{}{"default": map[string]interface{}{"input_per_1k": 0.003, "output_per_1k": 0.015}},
+			},
+		})
+	})
+
+	prices, err := uc.Prices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prices["default"].OutputPer1K != 0.015 {
+		t.Errorf("unexpected prices %+v", prices)
+	}
+}
+
+func TestUsage_InvalidArgs(t *testing.T) {
+	uc := newTestUsageClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	})
+	ctx := context.Background()
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := uc.Summary(ctx, &UsageQuery{From: from}); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected invalid argument for open range, got %v", err)
+	}
+	if _, err := uc.Summary(ctx, &UsageQuery{From: from, To: from}); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected invalid argument for empty range, got %v", err)
+	}
+	if _, err := uc.SetBudget(ctx, "", nil); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected invalid argument for nil request, got %v", err)
+	}
+	if _, err := uc.SetBudget(ctx, "", &SetBudgetRequest{SoftLimitUSD: 600, HardLimitUSD: 500}); !errors.Is(err, kerrors.ErrInvalidArgument) {
+		t.Errorf("expected invalid argument for soft above hard, got %v", err)
+	}
+}